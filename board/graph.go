@@ -0,0 +1,157 @@
+package board
+
+import (
+	"errors"
+	"fmt"
+)
+
+// QueryStyle is how a query panel displays its results.
+type QueryStyle string
+
+// Query panel styles.
+const (
+	// StyleGraph shows the results as graphs
+	StyleGraph QueryStyle = "graph"
+
+	// StyleTable shows the results as a table
+	StyleTable QueryStyle = "table"
+
+	// StyleCombo shows graphs above a table
+	StyleCombo QueryStyle = "combo"
+)
+
+// ChartType is how a query panel graphs a calculation.
+type ChartType string
+
+// Chart types. The empty ChartType is Honeycomb's default, a line graph
+// chosen by the calculation.
+const (
+	ChartDefault ChartType = "default"
+	ChartLine    ChartType = "line"
+	ChartStacked ChartType = "stacked"
+	ChartBar     ChartType = "tsbar"
+	ChartStat    ChartType = "stat"
+	ChartPie     ChartType = "cpie"
+	ChartColumn  ChartType = "cbar"
+)
+
+// ChartTypes lists the valid chart types.
+var ChartTypes = []ChartType{ChartDefault, ChartLine, ChartStacked, ChartBar, ChartStat, ChartPie, ChartColumn}
+
+// IsTimeSeries reports whether c graphs values over time, so that it has a
+// value axis and gaps for missing values.
+func (c ChartType) IsTimeSeries() bool {
+	switch c {
+	case "", ChartDefault, ChartLine, ChartStacked, ChartBar:
+		return true
+	}
+	return false
+}
+
+// GraphSettings are the display settings of a query panel, applied to the
+// graph of every calculation of its query.
+type GraphSettings struct {
+	// Style is the display style; the empty Style is Honeycomb's default,
+	// graphs
+	Style QueryStyle
+
+	// ChartType is the type of the graphs
+	ChartType ChartType
+
+	// OmitMissingValues draws no point, rather than zero, for time buckets
+	// without events
+	OmitMissingValues bool
+
+	// LogScale uses a logarithmic value axis
+	LogScale bool
+
+	// Stacked stacks the graphs of the groups of a breakdown, as the
+	// ChartStacked chart type does
+	Stacked bool
+}
+
+// IsZero reports whether s sets nothing, leaving Honeycomb's defaults.
+func (s GraphSettings) IsZero() bool {
+	return s == GraphSettings{}
+}
+
+// Chart returns the chart type s draws: ChartStacked when Stacked is set
+// with a default or line chart, otherwise ChartType.
+func (s GraphSettings) Chart() ChartType {
+	if s.Stacked && (s.ChartType == "" || s.ChartType == ChartDefault || s.ChartType == ChartLine) {
+		return ChartStacked
+	}
+	return s.ChartType
+}
+
+// Validate checks s for unknown values and settings that cannot be combined.
+// All problems found are returned, joined.
+func (s GraphSettings) Validate() error {
+	var errs []error
+	switch s.Style {
+	case "", StyleGraph, StyleTable, StyleCombo:
+	default:
+		errs = append(errs, fmt.Errorf("unknown query style %q (want graph, table or combo)", s.Style))
+	}
+	known := s.ChartType == ""
+	for _, c := range ChartTypes {
+		known = known || s.ChartType == c
+	}
+	if !known {
+		errs = append(errs, fmt.Errorf("unknown chart type %q", s.ChartType))
+	}
+
+	if s.Style == StyleTable && s != (GraphSettings{Style: StyleTable}) {
+		errs = append(errs, errors.New("graph settings have no effect on a table: use StyleGraph or StyleCombo"))
+	}
+	chart := s.Chart()
+	if s.Stacked && chart != ChartStacked && chart != ChartBar {
+		errs = append(errs, fmt.Errorf("a %s chart cannot be stacked", chart))
+	}
+	if s.LogScale && !chart.IsTimeSeries() && known {
+		errs = append(errs, fmt.Errorf("a %s chart has no value axis for a log scale", chart))
+	}
+	if s.LogScale && (chart == ChartStacked || chart == ChartBar && s.Stacked) {
+		errs = append(errs, errors.New("stacked graphs cannot use a log scale: stacking sums values a log scale distorts"))
+	}
+	if s.OmitMissingValues && !chart.IsTimeSeries() && known {
+		errs = append(errs, fmt.Errorf("a %s chart has no time buckets to omit missing values from", chart))
+	}
+	return errors.Join(errs...)
+}
+
+// WithQueryStyle sets how a query panel displays its results.
+func WithQueryStyle(style QueryStyle) PanelOption {
+	return func(c *PanelConfig) {
+		c.Graph.Style = style
+	}
+}
+
+// WithChartType sets the chart type of the graphs of a query panel.
+func WithChartType(chart ChartType) PanelOption {
+	return func(c *PanelConfig) {
+		c.Graph.ChartType = chart
+	}
+}
+
+// OmitMissingValues leaves gaps in the graphs of a query panel for time
+// buckets without events, instead of drawing zero.
+func OmitMissingValues() PanelOption {
+	return func(c *PanelConfig) {
+		c.Graph.OmitMissingValues = true
+	}
+}
+
+// LogScale graphs a query panel on a logarithmic value axis.
+func LogScale() PanelOption {
+	return func(c *PanelConfig) {
+		c.Graph.LogScale = true
+	}
+}
+
+// Stacked stacks the graphs of the groups of a query panel's breakdowns.
+func Stacked() PanelOption {
+	return func(c *PanelConfig) {
+		c.Graph.Stacked = true
+	}
+}
@@ -0,0 +1,91 @@
+package board
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+func TestQueryPanel_GraphOptions(t *testing.T) {
+	panel := QueryPanel(query.Query{Dataset: "production"},
+		WithTitle("Traffic"),
+		WithQueryStyle(StyleCombo),
+		WithChartType(ChartBar),
+		OmitMissingValues(),
+		LogScale(),
+		Stacked(),
+	)
+
+	qp, ok := panel.(*queryPanel)
+	require.True(t, ok)
+	assert.Equal(t, GraphSettings{
+		Style:             StyleCombo,
+		ChartType:         ChartBar,
+		OmitMissingValues: true,
+		LogScale:          true,
+		Stacked:           true,
+	}, qp.Config().Graph)
+}
+
+func TestGraphSettings_Chart(t *testing.T) {
+	assert.Equal(t, ChartType(""), GraphSettings{}.Chart())
+	assert.Equal(t, ChartStacked, GraphSettings{Stacked: true}.Chart())
+	assert.Equal(t, ChartStacked, GraphSettings{ChartType: ChartLine, Stacked: true}.Chart())
+	assert.Equal(t, ChartBar, GraphSettings{ChartType: ChartBar, Stacked: true}.Chart())
+	assert.Equal(t, ChartStat, GraphSettings{ChartType: ChartStat, Stacked: true}.Chart())
+}
+
+func TestGraphSettings_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings GraphSettings
+		wantErr  []string
+	}{
+		{name: "defaults", settings: GraphSettings{}},
+		{name: "line on a log scale", settings: GraphSettings{ChartType: ChartLine, LogScale: true, OmitMissingValues: true}},
+		{name: "stacked bars", settings: GraphSettings{Style: StyleCombo, ChartType: ChartBar, Stacked: true}},
+		{name: "table", settings: GraphSettings{Style: StyleTable}},
+		{
+			name:     "unknown values",
+			settings: GraphSettings{Style: "heatmap", ChartType: "area"},
+			wantErr:  []string{`unknown query style "heatmap"`, `unknown chart type "area"`},
+		},
+		{
+			name:     "settings of a table",
+			settings: GraphSettings{Style: StyleTable, LogScale: true},
+			wantErr:  []string{"graph settings have no effect on a table"},
+		},
+		{
+			name:     "stacked stat",
+			settings: GraphSettings{ChartType: ChartStat, Stacked: true},
+			wantErr:  []string{"a stat chart cannot be stacked"},
+		},
+		{
+			name:     "pie on a log scale",
+			settings: GraphSettings{ChartType: ChartPie, LogScale: true, OmitMissingValues: true},
+			wantErr:  []string{"a cpie chart has no value axis", "a cpie chart has no time buckets"},
+		},
+		{
+			name:     "stacked on a log scale",
+			settings: GraphSettings{Stacked: true, LogScale: true},
+			wantErr:  []string{"stacked graphs cannot use a log scale"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.settings.Validate()
+			if len(tt.wantErr) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			for _, want := range tt.wantErr {
+				assert.Contains(t, err.Error(), want)
+			}
+		})
+	}
+}
@@ -21,6 +21,10 @@ type Position struct {
 type PanelConfig struct {
 	Title    string
 	Position Position
+
+	// Graph holds the display settings of a query panel, set by
+	// WithQueryStyle, WithChartType, OmitMissingValues, LogScale and Stacked
+	Graph GraphSettings
 }
 
 // PanelOption is a function that configures a panel.
@@ -138,3 +142,33 @@ func SLOPanelByID(id string, opts ...PanelOption) Panel {
 	}
 	return p
 }
+
+// sectionPanel represents a titled group of panels.
+type sectionPanel struct {
+	panels []Panel
+	config PanelConfig
+}
+
+func (p *sectionPanel) panelType() string {
+	return "section"
+}
+
+// Panels returns the panels grouped in this section.
+func (p *sectionPanel) Panels() []Panel {
+	return p.panels
+}
+
+// Config returns the panel configuration. Its Title is the section title.
+func (p *sectionPanel) Config() PanelConfig {
+	return p.config
+}
+
+// Section groups panels under a titled section of a flexible board, such as
+// "Latency" or "Errors". Sections are listed in Board.Panels like any other
+// panel and serialize as a "section" panel holding its panels.
+func Section(title string, panels ...Panel) Panel {
+	return &sectionPanel{
+		panels: panels,
+		config: PanelConfig{Title: title},
+	}
+}
@@ -111,6 +111,26 @@ func TestSLOPanelByID_WithOptions(t *testing.T) {
 	assert.Equal(t, "API SLO", sp.config.Title)
 }
 
+func TestSection(t *testing.T) {
+	q := query.Query{
+		Dataset:   "production",
+		TimeRange: query.Hours(1),
+	}
+
+	panel := Section("Latency", QueryPanel(q), TextPanel("notes"))
+	require.NotNil(t, panel)
+	assert.Equal(t, "section", panel.panelType())
+
+	sp, ok := panel.(*sectionPanel)
+	require.True(t, ok)
+	assert.Equal(t, "Latency", sp.Config().Title)
+	require.Len(t, sp.Panels(), 2)
+	assert.Equal(t, "query", sp.Panels()[0].panelType())
+	assert.Equal(t, "text", sp.Panels()[1].panelType())
+
+	assert.Empty(t, Section("Empty").(*sectionPanel).Panels())
+}
+
 func TestPanel_Interface(t *testing.T) {
 	q := query.Query{
 		Dataset:   "production",
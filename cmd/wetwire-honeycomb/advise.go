@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/advise"
+	"github.com/lex00/wetwire-honeycomb-go/internal/atomicfile"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/spf13/cobra"
+)
+
+// adviseFlags holds the flags of the advise command.
+type adviseFlags struct {
+	retentionDays int
+	eventsPerDay  []string
+	maxEvents     int64
+	maxBuckets    int
+	maxBreakdowns int
+	report        string
+}
+
+// newAdviseCmd creates the "advise" command.
+func newAdviseCmd() *cobra.Command {
+	var flags adviseFlags
+
+	cmd := &cobra.Command{
+		Use:   "advise [path...]",
+		Short: "Suggest cheaper time ranges, granularity and breakdowns for queries",
+		Long: `Check each query's time range, granularity and breakdowns against the
+retention and ingest volume of its dataset, and suggest cheaper alternatives:
+
+  retention    The time range reaches past the dataset's retention
+  time_range   The query scans more than --max-events events
+  granularity  The query returns more than --max-buckets points per series
+  breakdowns   The query has more than --max-breakdowns breakdowns
+
+Retention and volume come from dataset.Schema declarations (RetentionDays,
+EventsPerDay), overridden by --events-per-day. Datasets without a declared
+retention use --retention-days, HONEYCOMB_RETENTION_DAYS, or 60 days.
+Honeycomb's API does not report retention or ingest volume, so the number of
+events scanned is only estimated for datasets whose volume is configured.
+
+Paths accept the same package patterns as build.
+
+Examples:
+    wetwire-honeycomb advise ./queries/...
+    wetwire-honeycomb advise --events-per-day api=2000000000 ./queries
+    wetwire-honeycomb advise --report advice.json ./...`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			patterns := args
+			if len(patterns) == 0 {
+				patterns = []string{"."}
+			}
+			format, _ := cmd.Flags().GetString("format")
+
+			opts, err := flags.options()
+			if err != nil {
+				return err
+			}
+			return runAdvise(os.Stdout, patterns, opts, format, flags.report)
+		},
+	}
+
+	cmd.Flags().IntVar(&flags.retentionDays, "retention-days", 0, "Retention of datasets without a declared one (default: HONEYCOMB_RETENTION_DAYS or 60)")
+	cmd.Flags().StringArrayVar(&flags.eventsPerDay, "events-per-day", nil, "Ingest volume of a dataset, e.g. api=2000000000 (repeatable)")
+	cmd.Flags().Int64Var(&flags.maxEvents, "max-events", advise.DefaultMaxEventsScanned, "Events a query may scan before a shorter time range is suggested")
+	cmd.Flags().IntVar(&flags.maxBuckets, "max-buckets", advise.DefaultMaxBuckets, "Points per series before a coarser granularity is suggested")
+	cmd.Flags().IntVar(&flags.maxBreakdowns, "max-breakdowns", advise.DefaultMaxBreakdowns, "Breakdowns before fewer are suggested")
+	cmd.Flags().StringVar(&flags.report, "report", "", "Also write the JSON report to this file")
+
+	return cmd
+}
+
+// options converts the flags to advisor options.
+func (f adviseFlags) options() (advise.Options, error) {
+	opts := advise.Options{
+		RetentionDays:    f.retentionDays,
+		MaxEventsScanned: f.maxEvents,
+		MaxBuckets:       f.maxBuckets,
+		MaxBreakdowns:    f.maxBreakdowns,
+	}
+
+	if opts.RetentionDays < 0 {
+		return opts, fmt.Errorf("--retention-days must be a positive number of days")
+	}
+	if opts.RetentionDays == 0 {
+		if days := os.Getenv(domain.EnvRetentionDays); days != "" {
+			n, err := strconv.Atoi(days)
+			if err != nil || n <= 0 {
+				return opts, fmt.Errorf("%s must be a positive number of days, got %q", domain.EnvRetentionDays, days)
+			}
+			opts.RetentionDays = n
+		}
+	}
+
+	for _, v := range f.eventsPerDay {
+		name, count, ok := strings.Cut(v, "=")
+		n, err := strconv.ParseInt(count, 10, 64)
+		if !ok || name == "" || err != nil || n < 0 {
+			return opts, fmt.Errorf("invalid --events-per-day %q: expected dataset=N", v)
+		}
+		if opts.EventsPerDay == nil {
+			opts.EventsPerDay = make(map[string]int64)
+		}
+		opts.EventsPerDay[name] = n
+	}
+	return opts, nil
+}
+
+// runAdvise advises on the queries selected by patterns and writes the report
+// as text or JSON, and as JSON to reportFile when set.
+func runAdvise(w io.Writer, patterns []string, opts advise.Options, format, reportFile string) error {
+	resources, err := discovery.DiscoverPatterns(patterns)
+	if err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+
+	report := advise.Advise(resources, opts)
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if reportFile != "" {
+		if err := atomicfile.WriteFile(reportFile, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+
+	if format == "json" {
+		fmt.Fprintln(w, string(data))
+		return nil
+	}
+
+	for _, q := range report.Queries {
+		if len(q.Suggestions) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s:%d: query %s (%s)\n", displayPath(q.File), q.Line, q.Name, q.Dataset)
+		for _, s := range q.Suggestions {
+			fmt.Fprintf(w, "    %s: %s\n", s.Kind, s.Message)
+		}
+	}
+
+	sum := report.Summary
+	if sum.WithSuggestions == 0 {
+		fmt.Fprintf(w, "No suggestions for %d queries\n", sum.Queries)
+		return nil
+	}
+	fmt.Fprintf(w, "\n%d suggestions for %d of %d queries", sum.Suggestions, sum.WithSuggestions, sum.Queries)
+	if sum.EventsSaved > 0 {
+		fmt.Fprintf(w, ", saving about %s of %s events scanned", advise.FormatCount(sum.EventsSaved), advise.FormatCount(sum.EventsScanned))
+	}
+	fmt.Fprintln(w)
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/advise"
+)
+
+const adviseSource = `package queries
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/dataset"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+var API = dataset.Schema{
+	Dataset:       "api",
+	RetentionDays: 30,
+}
+
+var Requests = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Days(60),
+	Granularity:  60,
+	Calculations: []query.Calculation{query.Count()},
+}
+
+var Recent = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Calculations: []query.Calculation{query.Count()},
+}
+`
+
+func TestRunAdvise(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(adviseSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	opts := advise.Options{EventsPerDay: map[string]int64{"api": 2_000_000_000}}
+
+	var out bytes.Buffer
+	if err := runAdvise(&out, []string{dir}, opts, "text", ""); err != nil {
+		t.Fatalf("runAdvise failed: %v", err)
+	}
+	text := out.String()
+	for _, want := range []string{
+		"queries.go:13: query Requests (api)",
+		"    retention: time range 60d exceeds the 30d retention of api",
+		"    time_range: scans about 60B events over 30d; 12h would scan 1B",
+		"    granularity: granularity 1m over 60d",
+		"3 suggestions for 1 of 2 queries, saving about 59B of",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "Recent") {
+		t.Errorf("expected only queries with suggestions, got:\n%s", text)
+	}
+
+	out.Reset()
+	report := filepath.Join(t.TempDir(), "advice.json")
+	if err := runAdvise(&out, []string{dir}, opts, "json", report); err != nil {
+		t.Fatalf("runAdvise failed: %v", err)
+	}
+	var printed advise.Report
+	if err := json.Unmarshal(out.Bytes(), &printed); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out.String())
+	}
+	if printed.Summary.Queries != 2 || printed.Summary.Suggestions != 3 {
+		t.Errorf("unexpected summary: %+v", printed.Summary)
+	}
+
+	data, err := os.ReadFile(report)
+	if err != nil {
+		t.Fatalf("report not written: %v", err)
+	}
+	if strings.TrimSpace(string(data)) != strings.TrimSpace(out.String()) {
+		t.Errorf("expected the report file to match the JSON output, got:\n%s", data)
+	}
+}
+
+func TestRunAdvise_NoSuggestions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(searchSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runAdvise(&out, []string{dir}, advise.Options{}, "text", ""); err != nil {
+		t.Fatalf("runAdvise failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "No suggestions for") {
+		t.Errorf("expected no suggestions, got:\n%s", out.String())
+	}
+}
+
+func TestAdviseFlags_Options(t *testing.T) {
+	t.Setenv("HONEYCOMB_RETENTION_DAYS", "14")
+
+	opts, err := adviseFlags{eventsPerDay: []string{"api=1000", "web=5"}}.options()
+	if err != nil {
+		t.Fatalf("options failed: %v", err)
+	}
+	if opts.RetentionDays != 14 {
+		t.Errorf("expected retention from the environment, got %d", opts.RetentionDays)
+	}
+	if opts.EventsPerDay["api"] != 1000 || opts.EventsPerDay["web"] != 5 {
+		t.Errorf("unexpected events per day: %v", opts.EventsPerDay)
+	}
+
+	opts, err = adviseFlags{retentionDays: 7}.options()
+	if err != nil || opts.RetentionDays != 7 {
+		t.Errorf("expected --retention-days to win, got %d, %v", opts.RetentionDays, err)
+	}
+
+	for _, v := range []string{"api", "=5", "api=lots", "api=-1"} {
+		if _, err := (adviseFlags{eventsPerDay: []string{v}}).options(); err == nil {
+			t.Errorf("expected an error for --events-per-day %q", v)
+		}
+	}
+}
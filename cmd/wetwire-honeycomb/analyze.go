@@ -0,0 +1,407 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/advise"
+	"github.com/lex00/wetwire-honeycomb-go/internal/analyze"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/spf13/cobra"
+)
+
+// newAnalyzeCmd creates the "analyze" command group.
+func newAnalyzeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "analyze",
+		Short: "Find structure across discovered resources",
+	}
+
+	cmd.AddCommand(newAnalyzeClustersCmd())
+	cmd.AddCommand(newAnalyzeTriggersCmd())
+	cmd.AddCommand(newAnalyzeSamplingCmd())
+
+	return cmd
+}
+
+// newAnalyzeClustersCmd creates the "analyze clusters" subcommand.
+func newAnalyzeClustersCmd() *cobra.Command {
+	var opts analyze.Options
+
+	cmd := &cobra.Command{
+		Use:   "clusters [path...]",
+		Short: "Group similar queries and propose a shared canonical query",
+		Long: `Group queries on the same dataset whose calculations and filters overlap,
+and print a refactor plan for each group: the canonical query made of what
+all members share, and what each member adds to it as a per-use-case variant.
+
+Two queries are grouped when they share a calculation and the Jaccard
+similarity of their calculations and filters is at least --threshold.
+Breakdowns are not compared, as variants usually differ by them. When no
+member already is the canonical query, the plan includes a Go declaration
+for one; members that repeat another are marked as duplicates.
+
+Paths accept the same package patterns as build.
+
+Examples:
+    wetwire-honeycomb analyze clusters ./queries/...
+    wetwire-honeycomb analyze clusters --threshold 0.8 -f json ./...`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			patterns := args
+			if len(patterns) == 0 {
+				patterns = []string{"."}
+			}
+			format, _ := cmd.Flags().GetString("format")
+
+			return runAnalyzeClusters(os.Stdout, patterns, opts, format)
+		},
+	}
+
+	cmd.Flags().Float64Var(&opts.Threshold, "threshold", analyze.DefaultThreshold, "Similarity (0-1) from which queries are grouped")
+
+	return cmd
+}
+
+// runAnalyzeClusters clusters the queries selected by patterns and writes the
+// refactor plan as text or JSON.
+func runAnalyzeClusters(w io.Writer, patterns []string, opts analyze.Options, format string) error {
+	if opts.Threshold < 0 || opts.Threshold > 1 {
+		return fmt.Errorf("--threshold must be between 0 and 1, got %g", opts.Threshold)
+	}
+
+	resources, err := discovery.DiscoverPatterns(patterns)
+	if err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+
+	report := analyze.Clusters(resources, opts)
+	for i := range report.Clusters {
+		c := &report.Clusters[i]
+		if c.Canonical.Existing {
+			continue
+		}
+		doc := fmt.Sprintf("%s is shared by %s.", c.Canonical.Name, variantNames(c.Variants))
+		c.Canonical.Code, err = domain.QueryDeclaration(c.Canonical.Name, doc, c.Canonical.Query.Query())
+		if err != nil {
+			return err
+		}
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	}
+
+	if len(report.Clusters) == 0 {
+		fmt.Fprintf(w, "No similar queries among %d queries\n", report.Queries)
+		return nil
+	}
+
+	for _, c := range report.Clusters {
+		status := "new"
+		if c.Canonical.Existing {
+			status = "existing"
+		}
+		shared := append(append([]string{}, c.Canonical.Calculations...), c.Canonical.Filters...)
+		if len(c.Canonical.Breakdowns) > 0 {
+			shared = append(shared, "by "+strings.Join(c.Canonical.Breakdowns, ", "))
+		}
+		fmt.Fprintf(w, "Cluster on %s: canonical %s (%s): %s\n", c.Dataset, c.Canonical.Name, status, strings.Join(shared, "; "))
+
+		for _, v := range c.Variants {
+			fmt.Fprintf(w, "    %s:%d: %s", displayPath(v.File), v.Line, v.Name)
+			switch {
+			case v.DuplicateOf != "":
+				fmt.Fprintf(w, " duplicates %s", v.DuplicateOf)
+			case v.Name == c.Canonical.Name:
+				fmt.Fprint(w, " is the canonical query")
+			default:
+				fmt.Fprintf(w, " (%.0f%% shared)", v.Similarity*100)
+				for _, extra := range []struct {
+					label  string
+					values []string
+				}{{"calculations", v.Calculations}, {"filters", v.Filters}, {"breakdowns", v.Breakdowns}} {
+					if len(extra.values) > 0 {
+						fmt.Fprintf(w, " + %s: %s", extra.label, strings.Join(extra.values, ", "))
+					}
+				}
+			}
+			fmt.Fprintln(w)
+		}
+
+		if c.Canonical.Code != "" {
+			fmt.Fprintln(w)
+			for _, line := range strings.Split(strings.TrimRight(c.Canonical.Code, "\n"), "\n") {
+				fmt.Fprintf(w, "    %s\n", line)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "%d clusters covering %d of %d queries\n", len(report.Clusters), report.Clustered, report.Queries)
+	return nil
+}
+
+// variantNames lists the names of variants in prose.
+func variantNames(variants []analyze.Variant) string {
+	names := make([]string, len(variants))
+	for i, v := range variants {
+		names[i] = v.Name
+	}
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
+
+// newAnalyzeTriggersCmd creates the "analyze triggers" subcommand.
+func newAnalyzeTriggersCmd() *cobra.Command {
+	var opts analyze.TriggerOptions
+
+	cmd := &cobra.Command{
+		Use:   "triggers [path...]",
+		Short: "Find overlapping triggers and suggest how to consolidate them",
+		Long: `Group triggers that alert on the same dataset and calculation, in the same
+direction, at near-identical thresholds, and print a diff that reduces each
+group to fewer alerts:
+
+    duplicate   the triggers evaluate the same query: keep the most
+                sensitive one and remove the others
+    breakdown   the triggers differ only by the value of one column, e.g.
+                service.name: replace them with one trigger on a query
+                grouped by that column, which fires per value
+    threshold   the queries overlap: align the thresholds on the most
+                sensitive one
+
+Two thresholds are near-identical when they differ by at most --tolerance
+of the larger one. Disabled triggers are left out.
+
+Paths accept the same package patterns as build.
+
+Examples:
+    wetwire-honeycomb analyze triggers ./triggers/...
+    wetwire-honeycomb analyze triggers --tolerance 0.2 -f json ./...`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			patterns := args
+			if len(patterns) == 0 {
+				patterns = []string{"."}
+			}
+			format, _ := cmd.Flags().GetString("format")
+
+			return runAnalyzeTriggers(os.Stdout, patterns, opts, format)
+		},
+	}
+
+	cmd.Flags().Float64Var(&opts.Tolerance, "tolerance", analyze.DefaultTolerance, "Relative threshold difference (0-1) up to which triggers overlap")
+
+	return cmd
+}
+
+// runAnalyzeTriggers groups the overlapping triggers selected by patterns and
+// writes the consolidation plan as text or JSON.
+func runAnalyzeTriggers(w io.Writer, patterns []string, opts analyze.TriggerOptions, format string) error {
+	if opts.Tolerance < 0 || opts.Tolerance > 1 {
+		return fmt.Errorf("--tolerance must be between 0 and 1, got %g", opts.Tolerance)
+	}
+
+	resources, err := discovery.DiscoverPatterns(patterns)
+	if err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+
+	report := analyze.Triggers(resources, opts)
+	for i := range report.Groups {
+		if report.Groups[i].Diff, err = triggerGroupDiff(report.Groups[i]); err != nil {
+			return err
+		}
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	}
+
+	if len(report.Groups) == 0 {
+		fmt.Fprintf(w, "No overlapping triggers among %d triggers\n", report.Triggers)
+		return nil
+	}
+
+	for _, g := range report.Groups {
+		scope := g.Calculation
+		if len(g.Filters) > 0 {
+			scope += " where " + strings.Join(g.Filters, ", ")
+		}
+		fmt.Fprintf(w, "Triggers on %s: %s (%s)\n", g.Dataset, scope, g.Suggestion)
+		for _, m := range g.Members {
+			fmt.Fprintf(w, "    %s:%d: %s %s %g", displayPath(m.File), m.Line, m.Name, m.Op, m.Threshold)
+			if len(m.Filters) > 0 {
+				fmt.Fprintf(w, " + filters: %s", strings.Join(m.Filters, ", "))
+			}
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w)
+		for _, line := range g.Diff {
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "%d groups covering %d of %d triggers\n", len(report.Groups), report.Grouped, report.Triggers)
+	return nil
+}
+
+// thresholdFuncs are the trigger package functions building each threshold
+// operator.
+var thresholdFuncs = map[string]string{
+	">":  "GreaterThan",
+	">=": "GreaterThanOrEqual",
+	"<":  "LessThan",
+	"<=": "LessThanOrEqual",
+}
+
+// triggerGroupDiff returns the diff, in Go source lines, applying the
+// suggestion of a trigger group.
+func triggerGroupDiff(g analyze.TriggerGroup) ([]string, error) {
+	threshold := fmt.Sprintf("\tThreshold: trigger.%s(%g),", thresholdFuncs[g.Op], g.Threshold)
+
+	var diff []string
+	switch g.Suggestion {
+	case analyze.SuggestDuplicate:
+		for _, m := range g.Members {
+			if m.Name != g.Keep {
+				diff = append(diff, fmt.Sprintf("// %s:%d: remove, %s fires first", displayPath(m.File), m.Line, g.Keep),
+					fmt.Sprintf("-var %s = trigger.Trigger{ ... }", m.Name))
+			}
+		}
+
+	case analyze.SuggestThreshold:
+		for _, m := range g.Members {
+			if m.Op != g.Op || m.Threshold != g.Threshold {
+				diff = append(diff, fmt.Sprintf("// %s:%d: %s", displayPath(m.File), m.Line, m.Name),
+					fmt.Sprintf("-\tThreshold: trigger.%s(%g),", thresholdFuncs[m.Op], m.Threshold),
+					"+"+threshold)
+			}
+		}
+
+	case analyze.SuggestBreakdown:
+		for _, m := range g.Members {
+			diff = append(diff, fmt.Sprintf("// %s:%d: replaced by %s", displayPath(m.File), m.Line, g.Name),
+				fmt.Sprintf("-var %s = trigger.Trigger{ ... }", m.Name))
+		}
+
+		queryName := g.Name + "Query"
+		doc := fmt.Sprintf("%s is %s grouped by %s.", queryName, g.Calculation, g.Column)
+		code, err := domain.QueryDeclaration(queryName, doc, g.Query.Query())
+		if err != nil {
+			return nil, err
+		}
+		diff = append(diff, "// added")
+		for _, line := range strings.Split(strings.TrimRight(code, "\n"), "\n") {
+			diff = append(diff, "+"+line)
+		}
+		diff = append(diff,
+			"+",
+			fmt.Sprintf("+// %s fires for each %s on its own.", g.Name, g.Column),
+			fmt.Sprintf("+var %s = trigger.Trigger{", g.Name),
+			fmt.Sprintf("+\tName:      %q,", g.Name),
+			fmt.Sprintf("+\tDataset:   %q,", g.Dataset),
+			fmt.Sprintf("+\tQuery:     %s,", queryName),
+			"+"+threshold,
+			fmt.Sprintf("+\t// Frequency, Recipients and Tags as in %s", g.Keep),
+			"+}")
+	}
+	return diff, nil
+}
+
+// newAnalyzeSamplingCmd creates the "analyze sampling" subcommand.
+func newAnalyzeSamplingCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "sampling [path...]",
+		Short: "Check trigger frequencies against query durations and granularity",
+		Long: `Compute the evaluation window of each trigger, the query duration it
+evaluates every frequency, and advise on combinations that miss spikes, with
+the values to use instead:
+
+    gap          the duration is shorter than the frequency, so events
+                 between two windows are never evaluated
+    resolution   the query granularity is finer than the frequency, e.g. 1m
+                 buckets evaluated every 5m over 15m: a 1m spike is averaged
+                 over the window
+    granularity  the buckets are longer than the frequency, so consecutive
+                 evaluations compare the same partial bucket
+    alignment    the duration is not a whole number of buckets
+
+Recommended durations stay within what Honeycomb accepts for the frequency.
+Disabled triggers, and triggers whose query has no relative time range, are
+left out.
+
+Paths accept the same package patterns as build.
+
+Examples:
+    wetwire-honeycomb analyze sampling ./triggers/...
+    wetwire-honeycomb analyze sampling -f json ./...`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			patterns := args
+			if len(patterns) == 0 {
+				patterns = []string{"."}
+			}
+			format, _ := cmd.Flags().GetString("format")
+
+			return runAnalyzeSampling(os.Stdout, patterns, format)
+		},
+	}
+}
+
+// runAnalyzeSampling computes the evaluation windows of the triggers
+// selected by patterns and writes them with their advice as text or JSON.
+func runAnalyzeSampling(w io.Writer, patterns []string, format string) error {
+	resources, err := discovery.DiscoverPatterns(patterns)
+	if err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+
+	report := analyze.Sampling(resources)
+
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	}
+
+	for _, t := range report.Triggers {
+		fmt.Fprintf(w, "%s:%d: %s every %s over %s", displayPath(t.File), t.Line, t.Name, advise.FormatSeconds(t.Frequency), advise.FormatSeconds(t.Duration))
+		if t.Granularity > 0 {
+			fmt.Fprintf(w, " at %s granularity", advise.FormatSeconds(t.Granularity))
+		}
+		if t.Gap > 0 {
+			fmt.Fprintf(w, " (%s unevaluated per interval)\n", advise.FormatSeconds(t.Gap))
+		} else {
+			fmt.Fprintf(w, " (each event evaluated %d times)\n", t.Evaluations)
+		}
+		for _, a := range t.Advice {
+			fmt.Fprintf(w, "    %s: %s\n", a.Kind, a.Message)
+		}
+	}
+
+	if report.Advised == 0 {
+		fmt.Fprintf(w, "No advice for %d triggers\n", len(report.Triggers))
+		return nil
+	}
+	fmt.Fprintf(w, "\n%d of %d triggers can miss spikes\n", report.Advised, len(report.Triggers))
+	return nil
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/analyze"
+)
+
+const analyzeSource = `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var CheckoutErrors = query.Query{
+	Dataset:           "checkout",
+	TimeRange:         query.Hours(2),
+	Granularity:       60,
+	Calculations:      []query.Calculation{query.Count()},
+	Filters:           []query.Filter{query.Equals("error", true), query.Equals("service.name", "checkout-api")},
+	FilterCombination: "OR",
+}
+
+var CheckoutErrorsEU = query.Query{
+	Dataset:           "checkout",
+	TimeRange:         query.Hours(2),
+	Calculations:      []query.Calculation{query.Count()},
+	Filters:           []query.Filter{query.Equals("error", true), query.Equals("region", "eu")},
+	FilterCombination: "OR",
+	Breakdowns:        []string{"endpoint"},
+}
+
+var Latency = query.Query{
+	Dataset:      "checkout",
+	TimeRange:    query.Hours(2),
+	Calculations: []query.Calculation{query.P99("duration_ms")},
+}
+`
+
+func TestRunAnalyzeClusters(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(analyzeSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runAnalyzeClusters(&out, []string{dir}, analyze.Options{Threshold: analyze.DefaultThreshold}, "text"); err != nil {
+		t.Fatalf("runAnalyzeClusters failed: %v", err)
+	}
+	text := out.String()
+	for _, want := range []string{
+		"Cluster on checkout: canonical CheckoutErrorsBase (new): COUNT; error = true",
+		"queries.go:5: CheckoutErrors (67% shared) + filters: service.name = checkout-api",
+		"queries.go:14: CheckoutErrorsEU (67% shared) + filters: region = eu + breakdowns: endpoint",
+		"    // CheckoutErrorsBase is shared by CheckoutErrors and CheckoutErrorsEU.",
+		"    var CheckoutErrorsBase = query.Query{",
+		"1 clusters covering 2 of 3 queries",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "Latency") {
+		t.Errorf("expected Latency not to be clustered, got:\n%s", text)
+	}
+
+	out.Reset()
+	if err := runAnalyzeClusters(&out, []string{dir}, analyze.Options{Threshold: analyze.DefaultThreshold}, "json"); err != nil {
+		t.Fatalf("runAnalyzeClusters failed: %v", err)
+	}
+	var report analyze.ClusterReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out.String())
+	}
+	if len(report.Clusters) != 1 || len(report.Clusters[0].Variants) != 2 {
+		t.Fatalf("expected one cluster of two queries, got %+v", report.Clusters)
+	}
+	for _, want := range []string{`query.Equals("error", true)`, `FilterCombination: "OR",`, "Granularity:       60,"} {
+		if !strings.Contains(report.Clusters[0].Canonical.Code, want) {
+			t.Errorf("expected %q in the canonical code, got:\n%s", want, report.Clusters[0].Canonical.Code)
+		}
+	}
+}
+
+func TestRunAnalyzeClusters_None(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(analyzeSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runAnalyzeClusters(&out, []string{dir}, analyze.Options{Threshold: 1}, "text"); err != nil {
+		t.Fatalf("runAnalyzeClusters failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "No similar queries among 3 queries") {
+		t.Errorf("expected no clusters, got:\n%s", out.String())
+	}
+
+	if err := runAnalyzeClusters(&out, []string{dir}, analyze.Options{Threshold: 2}, "text"); err == nil {
+		t.Error("expected an error for a threshold above 1")
+	}
+}
+
+const analyzeTriggersSource = `package triggers
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var CheckoutSlow = trigger.Trigger{
+	Name:    "Checkout slow",
+	Dataset: "checkout",
+	Query: query.Query{
+		Dataset:      "checkout",
+		TimeRange:    query.Minutes(15),
+		Granularity:  60,
+		Calculations: []query.Calculation{query.P99("duration_ms")},
+		Filters:      []query.Filter{query.Equals("service.name", "checkout")},
+	},
+	Threshold: trigger.GreaterThan(500),
+	Frequency: trigger.Minutes(5),
+}
+
+var CartSlow = trigger.Trigger{
+	Name:    "Cart slow",
+	Dataset: "checkout",
+	Query: query.Query{
+		Dataset:      "checkout",
+		TimeRange:    query.Minutes(15),
+		Granularity:  60,
+		Calculations: []query.Calculation{query.P99("duration_ms")},
+		Filters:      []query.Filter{query.Equals("service.name", "cart")},
+	},
+	Threshold: trigger.GreaterThan(520),
+	Frequency: trigger.Minutes(5),
+}
+`
+
+func TestRunAnalyzeTriggers(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "triggers.go"), []byte(analyzeTriggersSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runAnalyzeTriggers(&out, []string{dir}, analyze.TriggerOptions{}, "text"); err != nil {
+		t.Fatalf("runAnalyzeTriggers failed: %v", err)
+	}
+	text := out.String()
+	for _, want := range []string{
+		"Triggers on checkout: P99(duration_ms) (breakdown)",
+		"triggers.go:8: CheckoutSlow > 500 + filters: service.name = checkout",
+		"-var CartSlow = trigger.Trigger{ ... }",
+		"+var CheckoutSlowByServiceName = trigger.Trigger{",
+		"+\tBreakdowns:",
+		"+\tGranularity: 60,",
+		"+\tThreshold: trigger.GreaterThan(500),",
+		"1 groups covering 2 of 2 triggers",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, text)
+		}
+	}
+
+	out.Reset()
+	if err := runAnalyzeTriggers(&out, []string{dir}, analyze.TriggerOptions{Tolerance: 0.01}, "json"); err != nil {
+		t.Fatalf("runAnalyzeTriggers failed: %v", err)
+	}
+	var report analyze.TriggerReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out.String())
+	}
+	if len(report.Groups) != 0 || report.Triggers != 2 {
+		t.Errorf("expected no groups among 2 triggers, got %+v", report)
+	}
+
+	if err := runAnalyzeTriggers(&out, []string{dir}, analyze.TriggerOptions{Tolerance: -1}, "text"); err == nil {
+		t.Error("expected an error for a negative tolerance")
+	}
+}
+
+const analyzeSamplingSource = `package triggers
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var CheckoutSlow = trigger.Trigger{
+	Name: "Checkout slow",
+	Query: query.Query{
+		Dataset:      "checkout",
+		TimeRange:    query.Minutes(15),
+		Granularity:  60,
+		Calculations: []query.Calculation{query.P99("duration_ms")},
+	},
+	Threshold: trigger.GreaterThan(500),
+	Frequency: trigger.Minutes(5),
+}
+
+var CheckoutErrors = trigger.Trigger{
+	Name: "Checkout errors",
+	Query: query.Query{
+		Dataset:      "checkout",
+		TimeRange:    query.Minutes(15),
+		Calculations: []query.Calculation{query.Count()},
+	},
+	Threshold: trigger.GreaterThan(10),
+	Frequency: trigger.Minutes(5),
+}
+`
+
+func TestRunAnalyzeSampling(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "triggers.go"), []byte(analyzeSamplingSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runAnalyzeSampling(&out, []string{dir}, "text"); err != nil {
+		t.Fatalf("runAnalyzeSampling failed: %v", err)
+	}
+	text := out.String()
+	for _, want := range []string{
+		"triggers.go:8: CheckoutSlow every 5m over 15m at 1m granularity (each event evaluated 3 times)",
+		"    resolution: the query resolves 1m but the trigger evaluates every 5m over 15m",
+		"use Frequency: trigger.Minutes(1) with TimeRange: query.Minutes(4)",
+		"triggers.go:20: CheckoutErrors every 5m over 15m (each event evaluated 3 times)",
+		"1 of 2 triggers can miss spikes",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, text)
+		}
+	}
+
+	out.Reset()
+	if err := runAnalyzeSampling(&out, []string{dir}, "json"); err != nil {
+		t.Fatalf("runAnalyzeSampling failed: %v", err)
+	}
+	var report analyze.SamplingReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out.String())
+	}
+	if len(report.Triggers) != 2 || report.Advised != 1 || report.Triggers[0].Advice[0].Frequency != 60 {
+		t.Errorf("expected advice for one of 2 triggers, got %+v", report)
+	}
+}
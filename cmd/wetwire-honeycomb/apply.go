@@ -0,0 +1,630 @@
+// Command apply synchronizes built resources to a Honeycomb environment.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/apply"
+	"github.com/lex00/wetwire-honeycomb-go/internal/atomicfile"
+	"github.com/lex00/wetwire-honeycomb-go/internal/audit"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	api "github.com/lex00/wetwire-honeycomb-go/internal/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lock"
+	"github.com/lex00/wetwire-honeycomb-go/internal/progress"
+	"github.com/spf13/cobra"
+)
+
+// applyOptions holds the flags of the apply command.
+type applyOptions struct {
+	only        []string
+	dryRun      bool
+	noRollback  bool
+	prune       bool
+	pruneDryRun bool
+	yes         bool
+	source      string
+	noAnnotate  bool
+	envs        []string
+	concurrency int
+	rateLimit   float64
+	reportDir   string
+	format      string
+
+	// build holds the options of the build applied: --allow-env and the
+	// flags of addResourceFlags
+	build domain.Options
+
+	// audit, when set, is completed with the plan and outcome of the apply
+	// and recorder collects the API calls of every target
+	audit    *audit.Record
+	recorder *audit.Recorder
+
+	// stdin and stderr are used to confirm pruning; stderr also lists the
+	// triggers muted by maintenance windows
+	stdin  io.Reader
+	stderr io.Writer
+
+	// progress counts the applied resources of every target; none when nil
+	progress progress.Tracker
+}
+
+// applyTarget is an environment to apply to. The default environment (from
+// HONEYCOMB_API_KEY) has an empty name.
+type applyTarget struct {
+	env    string
+	client apply.Client
+}
+
+// newApplyCmd creates the "apply" command.
+func newApplyCmd() *cobra.Command {
+	var opts applyOptions
+
+	cmd := &cobra.Command{
+		Use:   "apply [path]",
+		Short: "Create or update resources in Honeycomb",
+		Long: `Build the resources in path and apply them to Honeycomb in dependency order:
+queries first, then SLOs, their burn alerts and triggers, then boards.
+
+Existing boards, SLOs, burn alerts and triggers are matched by name and
+updated only when a generated field differs. If a resource fails, apply stops
+and rolls back the resources it created or updated (use --no-rollback to keep
+them).
+
+Use --only to apply a subset; dependencies of selected resources are included.
+
+A successful full apply records the applied resources in wetwire.lock. With
+--prune, boards, SLOs and triggers recorded in the lockfile that no longer
+exist in code are deleted from Honeycomb after confirmation (--yes skips the
+prompt). --prune-dry-run only lists them.
+
+Applied boards, SLOs and triggers get a footer in their description marking
+them as managed by wetwire-honeycomb, naming the source repository (the git
+"origin" remote of path, or --source) so edits in the Honeycomb UI are known
+to be overwritten. Use --no-annotate to leave descriptions as written.
+
+Requires HONEYCOMB_API_KEY (and optionally HONEYCOMB_API_URL). With --env,
+the same resources are applied to each named environment in parallel, using
+HONEYCOMB_API_KEY_<ENV> (and optionally HONEYCOMB_API_URL_<ENV>). Independent
+resources are applied --concurrency at a time per environment, and each
+environment's API calls are limited to --rate-limit requests per second.
+--report-dir writes one apply-<env>.json report per environment.
+
+Triggers selected by a mute.Window maintenance window are applied disabled
+while the window is active; the first apply after it ends enables them
+again. Honeycomb cannot schedule this, so run apply when windows start and
+end.
+
+With --allow-env, ${VAR} references in datasets and trigger recipient targets
+are expanded from the environment, as for build.
+
+With --audit-log (or WETWIRE_HONEYCOMB_AUDIT_LOG), a JSON record of the apply
+is appended to the given file: who ran it and when, the git commit of path,
+the environments, resource and change counts, and every API call made
+(method, path and status; never the API key).
+
+Example:
+    wetwire-honeycomb apply ./observability
+    wetwire-honeycomb apply --only type=slo --dry-run ./observability
+    wetwire-honeycomb apply --prune ./observability
+    wetwire-honeycomb apply --env prod --env staging --report-dir reports ./observability`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+			opts.format, _ = cmd.Flags().GetString("format")
+			opts.stdin = cmd.InOrStdin()
+			opts.stderr = cmd.ErrOrStderr()
+
+			auditFile := auditLog(cmd)
+			if auditFile == "" {
+				return runApplyCmd(cmd, path, opts)
+			}
+			opts.audit = audit.NewRecord("apply", path, path, domain.Version)
+			opts.recorder = &audit.Recorder{}
+			err := runApplyCmd(cmd, path, opts)
+			opts.audit.APICalls = opts.recorder.Calls()
+			return finishAudit(auditFile, opts.audit, err)
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&opts.only, "only", nil, "Apply only matching resources, e.g. type=slo or name=HighLatency (repeatable)")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "Show what would change without applying")
+	cmd.Flags().BoolVar(&opts.noRollback, "no-rollback", false, "Keep applied resources when a later resource fails")
+	cmd.Flags().BoolVar(&opts.prune, "prune", false, "Delete resources recorded in wetwire.lock that were removed from code")
+	cmd.Flags().BoolVar(&opts.pruneDryRun, "prune-dry-run", false, "List the resources --prune would delete")
+	cmd.Flags().BoolVarP(&opts.yes, "yes", "y", false, "Prune without asking for confirmation")
+	cmd.Flags().StringVar(&opts.source, "source", "", "Source named in the managed annotation (default: git origin remote of path)")
+	cmd.Flags().BoolVar(&opts.noAnnotate, "no-annotate", false, "Do not mark applied resources as managed in their description")
+	cmd.Flags().StringArrayVar(&opts.envs, "env", nil, "Apply to a named environment using HONEYCOMB_API_KEY_<ENV> (repeatable)")
+	cmd.Flags().IntVar(&opts.concurrency, "concurrency", 4, "Independent resources applied at once per environment")
+	cmd.Flags().Float64Var(&opts.rateLimit, "rate-limit", 5, "Maximum API requests per second per environment (0 for no limit)")
+	cmd.Flags().StringVar(&opts.reportDir, "report-dir", "", "Write a JSON apply report per environment to this directory")
+	cmd.Flags().BoolVar(&opts.build.AllowEnv, "allow-env", false, "Expand ${VAR} references in datasets and recipient targets from the environment")
+	addResourceFlags(cmd, &opts.build)
+	addAuditLogFlag(cmd)
+
+	return cmd
+}
+
+// runApplyCmd creates the clients of the apply command and applies path.
+func runApplyCmd(cmd *cobra.Command, path string, opts applyOptions) error {
+	targets, err := applyTargets(opts)
+	if err != nil {
+		return err
+	}
+
+	cmd.SilenceUsage = true
+	opts.progress = progress.For(os.Stderr, "Applying", "resources")
+	return runApplyTargets(cmd.Context(), targets, path, opts)
+}
+
+// applyTargets creates a rate-limited client for each environment in opts, or
+// for the default environment when none is given. When auditing, the clients
+// record their calls with opts.recorder.
+func applyTargets(opts applyOptions) ([]applyTarget, error) {
+	if len(opts.envs) == 0 {
+		client, err := api.NewClientFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		return []applyTarget{{client: configureClient(client, "", opts)}}, nil
+	}
+
+	seen := make(map[string]bool)
+	var targets []applyTarget
+	for _, env := range opts.envs {
+		if seen[env] {
+			return nil, fmt.Errorf("environment %q given more than once", env)
+		}
+		seen[env] = true
+
+		client, err := api.NewClientForEnvironment(env)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, applyTarget{env: env, client: configureClient(client, env, opts)})
+	}
+	return targets, nil
+}
+
+// configureClient applies the rate limit of opts to the client of env and,
+// when auditing, records its calls.
+func configureClient(client *api.Client, env string, opts applyOptions) *api.Client {
+	if opts.recorder != nil {
+		client.WithTransport(opts.recorder.Transport(env, nil))
+	}
+	return client.WithRateLimit(opts.rateLimit)
+}
+
+// runApply plans and executes an apply of the resources in path to the
+// default environment.
+func runApply(ctx context.Context, client apply.Client, path string, opts applyOptions) error {
+	return runApplyTargets(ctx, []applyTarget{{client: client}}, path, opts)
+}
+
+// runApplyTargets plans the resources in path once and applies them to every
+// target in parallel.
+func runApplyTargets(ctx context.Context, targets []applyTarget, path string, opts applyOptions) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	pruning := opts.prune || opts.pruneDryRun
+	if pruning && len(opts.only) > 0 {
+		return fmt.Errorf("--prune cannot be combined with --only")
+	}
+	if opts.prune && len(targets) > 1 && !opts.yes && !opts.dryRun {
+		return fmt.Errorf("--prune with several environments requires --yes")
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolve path: %w", err)
+	}
+
+	var selectors []apply.Selector
+	for _, s := range opts.only {
+		sel, err := apply.ParseSelector(s)
+		if err != nil {
+			return err
+		}
+		selectors = append(selectors, sel)
+	}
+
+	output, resources, err := loadApplyResources(absPath, opts.build)
+	if err != nil {
+		return err
+	}
+
+	// Honeycomb cannot schedule maintenance windows, so their triggers are
+	// sent disabled while one is active
+	muted, err := apply.Mute(resources, output.Section(apply.TypeMute), time.Now())
+	if err != nil {
+		return err
+	}
+	if opts.stderr != nil {
+		for _, m := range muted {
+			fmt.Fprintf(opts.stderr, "Muting %s until %s (maintenance window %s)\n", m.Trigger, m.End, m.Window)
+		}
+	}
+
+	plan, err := apply.Plan(resources, selectors)
+	if err != nil {
+		return err
+	}
+	if len(plan) == 0 {
+		return fmt.Errorf("no resources selected")
+	}
+	if opts.audit != nil {
+		var types []string
+		for _, r := range plan {
+			types = append(types, r.Type)
+		}
+		opts.audit.Resources = audit.CountKeys(types)
+		opts.audit.DryRun = opts.dryRun
+		for _, target := range targets {
+			opts.audit.Environments = append(opts.audit.Environments, targetName(target.env))
+		}
+	}
+
+	lockPath := filepath.Join(absPath, lock.FileName)
+	locked, err := lock.Read(lockPath)
+	if errors.Is(err, os.ErrNotExist) {
+		if pruning {
+			return fmt.Errorf("--prune requires %s in %s", lock.FileName, path)
+		}
+		locked, err = nil, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	source := opts.source
+	if source == "" && !opts.noAnnotate {
+		source = detectSource(absPath)
+	}
+	execOpts := apply.Options{
+		DryRun:      opts.dryRun,
+		NoRollback:  opts.noRollback,
+		Annotate:    !opts.noAnnotate,
+		Source:      source,
+		Concurrency: opts.concurrency,
+		Progress:    opts.progress,
+	}
+	if execOpts.Progress != nil {
+		execOpts.Progress.Start(len(plan) * len(targets))
+	}
+
+	reports := make([]*apply.Report, len(targets))
+	errs := make([]error, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			report := apply.Execute(ctx, target.client, plan, execOpts)
+			report.Environment = target.env
+			if pruning && !report.Failed() {
+				results, err := runPrune(ctx, target.client, locked, output, opts)
+				if err != nil {
+					errs[i] = fmt.Errorf("%s: %w", targetName(target.env), err)
+				}
+				report.Results = append(report.Results, results...)
+			}
+			reports[i] = report
+		}()
+	}
+	wg.Wait()
+	if execOpts.Progress != nil {
+		execOpts.Progress.Finish()
+	}
+	if opts.audit != nil {
+		opts.audit.Changes = applyChanges(reports)
+	}
+	// A failed prune still leaves applied changes to report and record, so
+	// its error is returned last
+	pruneErr := errors.Join(errs...)
+
+	if opts.reportDir != "" {
+		if err := writeApplyReports(opts.reportDir, reports); err != nil {
+			return err
+		}
+	}
+
+	if err := printApplyReports(reports, opts.format); err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, report := range reports {
+		if report.Failed() {
+			failed = append(failed, targetName(report.Environment))
+		}
+	}
+	if len(failed) > 0 {
+		if len(targets) == 1 {
+			return fmt.Errorf("apply failed")
+		}
+		return errors.Join(fmt.Errorf("apply failed in %s", strings.Join(failed, ", ")), pruneErr)
+	}
+
+	// Record what is now managed so later prunes know what was removed
+	if !opts.dryRun && len(selectors) == 0 {
+		lf, err := lock.Generate(output)
+		if err != nil {
+			return fmt.Errorf("generate lockfile: %w", err)
+		}
+		keepUnpruned(lf, locked, reports)
+		if err := lock.Write(lockPath, lf); err != nil {
+			return fmt.Errorf("write lockfile: %w", err)
+		}
+	}
+	return pruneErr
+}
+
+// targetName returns the display name of an environment.
+func targetName(env string) string {
+	if env == "" {
+		return "default"
+	}
+	return env
+}
+
+// writeApplyReports writes each report to <dir>/apply-<env>.json.
+func writeApplyReports(dir string, reports []*apply.Report) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("create report directory: %w", err)
+	}
+	for _, report := range reports {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		file := filepath.Join(dir, "apply-"+targetName(report.Environment)+".json")
+		if err := atomicfile.WriteFile(file, append(data, '\n'), 0644); err != nil {
+			return fmt.Errorf("write apply report: %w", err)
+		}
+	}
+	return nil
+}
+
+// printApplyReports prints the reports as text or JSON. A single report for
+// the default environment is printed on its own; several are printed as a
+// list (JSON) or under a heading per environment (text).
+func printApplyReports(reports []*apply.Report, format string) error {
+	single := len(reports) == 1 && reports[0].Environment == ""
+
+	if format == "json" {
+		var v any = reports
+		if single {
+			v = reports[0]
+		}
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	for i, report := range reports {
+		if !single {
+			if i > 0 {
+				fmt.Println()
+			}
+			fmt.Printf("== %s ==\n", report.Environment)
+		}
+		printApplyReport(report)
+	}
+	return nil
+}
+
+// detectSource returns the origin remote of the git repository containing
+// path, without scheme, credentials or ".git" suffix, falling back to the name
+// of the directory.
+func detectSource(absPath string) string {
+	for dir := absPath; ; dir = filepath.Dir(dir) {
+		if url := originURL(filepath.Join(dir, ".git", "config")); url != "" {
+			return normalizeRemote(url)
+		}
+		if filepath.Dir(dir) == dir {
+			break
+		}
+	}
+	return filepath.Base(absPath)
+}
+
+// originURL reads the url of the "origin" remote from a git config file.
+func originURL(configPath string) string {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return ""
+	}
+
+	inOrigin := false
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			inOrigin = line == `[remote "origin"]`
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok && inOrigin && strings.TrimSpace(key) == "url" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// normalizeRemote turns a git remote URL such as "git@github.com:acme/obs.git"
+// or "https://user@github.com/acme/obs" into "github.com/acme/obs".
+func normalizeRemote(url string) string {
+	if _, rest, ok := strings.Cut(url, "://"); ok {
+		url = rest
+	} else if host, path, ok := strings.Cut(url, ":"); ok {
+		url = host + "/" + path
+	}
+	if at := strings.LastIndex(url, "@"); at >= 0 && at < strings.Index(url+"/", "/") {
+		url = url[at+1:]
+	}
+	return strings.TrimSuffix(url, ".git")
+}
+
+// keepUnpruned carries over lockfile entries for resources removed from code
+// that were not deleted from every environment in this run, so a later --prune
+// can still find them.
+func keepUnpruned(lf, previous *lock.Lockfile, reports []*apply.Report) {
+	if previous == nil {
+		return
+	}
+
+	deletions := make(map[string]int)
+	for _, report := range reports {
+		for _, r := range report.Results {
+			if r.Action == apply.ActionDelete && r.Status == apply.StatusApplied {
+				deletions[r.Key]++
+			}
+		}
+	}
+	deleted := make(map[string]bool)
+	for key, n := range deletions {
+		deleted[key] = n == len(reports)
+	}
+
+	for key, entry := range previous.Resources {
+		resourceType, _ := lock.SplitKey(key)
+		if _, ok := lf.Resources[key]; ok || deleted[key] || resourceType == apply.TypeQuery {
+			continue
+		}
+		lf.Resources[key] = entry
+	}
+}
+
+// loadApplyResources builds the resources in path with opts, returning the
+// build output and the resources to apply.
+func loadApplyResources(absPath string, opts domain.Options) (*honeycomb.Manifest, []apply.Resource, error) {
+	discovered, err := discovery.DiscoverAll(absPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("discovery failed: %w", err)
+	}
+	if errs := domain.ExpandEnv(discovered, opts.AllowEnv); len(errs) > 0 {
+		return nil, nil, fmt.Errorf("%s:%d: %s", errs[0].Path, errs[0].Line, errs[0].Message)
+	}
+
+	output, err := buildOutput(absPath, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resources, err := apply.FromBuild(output, discovered)
+	if err != nil {
+		return nil, nil, err
+	}
+	return output, resources, nil
+}
+
+// runPrune finds resources removed from code and deletes them once confirmed.
+// Live resources without the managed annotation are reported as skipped.
+func runPrune(ctx context.Context, client apply.Client, locked *lock.Lockfile, output *honeycomb.Manifest, opts applyOptions) ([]apply.Result, error) {
+	prunable, unmanaged, err := apply.FindPrunable(ctx, client, locked, output)
+	if err != nil {
+		return nil, fmt.Errorf("find prunable resources: %w", err)
+	}
+	var skipped []apply.Result
+	for _, r := range unmanaged {
+		skipped = append(skipped, apply.Result{Key: r.Key(), Type: r.Type, Name: r.Name, Action: apply.ActionDelete, Status: apply.StatusSkipped,
+			Error: fmt.Sprintf("%q is not managed by wetwire-honeycomb; delete it by hand if it is no longer needed", r.Title)})
+	}
+	if len(prunable) == 0 {
+		return skipped, nil
+	}
+
+	listOnly := opts.pruneDryRun || opts.dryRun
+	if !listOnly && !opts.yes && !confirmPrune(prunable, opts.stdin, opts.stderr) {
+		for _, r := range prunable {
+			skipped = append(skipped, apply.Result{Key: r.Key(), Type: r.Type, Name: r.Name, Action: apply.ActionDelete, Status: apply.StatusSkipped})
+		}
+		return skipped, nil
+	}
+
+	return append(apply.Prune(ctx, client, prunable, listOnly), skipped...), nil
+}
+
+// confirmPrune lists the resources to delete and asks for confirmation.
+func confirmPrune(prunable []apply.Resource, in io.Reader, out io.Writer) bool {
+	if in == nil || out == nil {
+		return false
+	}
+
+	fmt.Fprintln(out, "The following resources were removed from code and will be deleted from Honeycomb:")
+	for _, r := range prunable {
+		fmt.Fprintf(out, "  %s (%s)\n", r.Key(), r.Title)
+	}
+	fmt.Fprintf(out, "Delete %d resource(s)? [y/N] ", len(prunable))
+
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// printApplyReport prints the per-resource outcome of an apply.
+func printApplyReport(report *apply.Report) {
+	if report.DryRun {
+		fmt.Println("Apply plan (dry run):")
+	} else {
+		fmt.Println("Apply:")
+	}
+
+	counts := make(map[string]int)
+	for _, r := range report.Results {
+		label := applyLabel(r)
+		counts[label]++
+
+		line := fmt.Sprintf("  %-12s %s", label, r.Key)
+		if r.ID != "" {
+			line += fmt.Sprintf(" (id %s)", r.ID)
+		}
+		if r.Error != "" {
+			line += ": " + r.Error
+		}
+		fmt.Println(line)
+	}
+
+	fmt.Printf("\n%d resource(s):", len(report.Results))
+	sep := " "
+	for _, label := range []string{"create", "update", "unchanged", "delete", "failed", "skipped", "rolled back"} {
+		if counts[label] > 0 {
+			fmt.Printf("%s%d %s", sep, counts[label], label)
+			sep = ", "
+		}
+	}
+	fmt.Println()
+
+	if report.RolledBack {
+		fmt.Println("Changes were rolled back")
+	}
+}
+
+// applyLabel describes a result by its action, or by its status when the
+// action did not take effect.
+func applyLabel(r apply.Result) string {
+	switch {
+	case r.Status == apply.StatusFailed || r.Status == apply.StatusSkipped || r.Status == apply.StatusRolledBack:
+		return string(r.Status)
+	case r.Action == apply.ActionNone:
+		return "unchanged"
+	}
+	return string(r.Action)
+}
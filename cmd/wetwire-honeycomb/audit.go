@@ -0,0 +1,69 @@
+// Command audit records build, apply and import operations in an audit log.
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/apply"
+	"github.com/lex00/wetwire-honeycomb-go/internal/audit"
+	"github.com/spf13/cobra"
+)
+
+// addAuditLogFlag adds --audit-log to cmd.
+func addAuditLogFlag(cmd *cobra.Command) {
+	cmd.Flags().String("audit-log", "", "Append a JSON record of this operation to this file (default $"+audit.EnvLog+")")
+}
+
+// auditLog returns the audit log file of cmd: --audit-log, or
+// WETWIRE_HONEYCOMB_AUDIT_LOG. Operations are not audited when it is empty.
+func auditLog(cmd *cobra.Command) string {
+	if file, _ := cmd.Flags().GetString("audit-log"); file != "" {
+		return file
+	}
+	return os.Getenv(audit.EnvLog)
+}
+
+// finishAudit completes rec with the outcome of the operation and appends it
+// to file. An audit log that cannot be written fails the command, so an
+// operation is never left unrecorded silently.
+func finishAudit(file string, rec *audit.Record, err error) error {
+	rec.Finish(err)
+	if aerr := audit.Append(file, rec); aerr != nil {
+		return errors.Join(err, aerr)
+	}
+	return err
+}
+
+// patternDir returns the directory a package pattern such as ./queries/...
+// starts in.
+func patternDir(pattern string) string {
+	if i := strings.Index(pattern, "..."); i >= 0 {
+		pattern = pattern[:i]
+	}
+	if pattern == "" {
+		return "."
+	}
+	return filepath.Clean(pattern)
+}
+
+// applyChanges counts the results of apply reports by action and status,
+// e.g. "create/applied" or "update/failed".
+func applyChanges(reports []*apply.Report) map[string]int {
+	var keys []string
+	for _, report := range reports {
+		if report == nil {
+			continue
+		}
+		for _, r := range report.Results {
+			key := string(r.Status)
+			if r.Action != "" {
+				key = string(r.Action) + "/" + key
+			}
+			keys = append(keys, key)
+		}
+	}
+	return audit.CountKeys(keys)
+}
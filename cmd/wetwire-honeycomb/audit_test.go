@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/apply"
+	"github.com/lex00/wetwire-honeycomb-go/internal/audit"
+)
+
+func TestBuildAuditLog(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "boards.go"), []byte(grafanaSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	logFile := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	// The flag and the environment variable append to the same log
+	d := &domain.HoneycombDomain{}
+	rootCmd := domain.CreateRootCommand(d)
+	extendBuildCmd(rootCmd, d)
+	rootCmd.SetArgs([]string{"build", "-f", "json", "--audit-log", logFile, dir})
+	rootCmd.SetOut(io.Discard)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+
+	t.Setenv(audit.EnvLog, logFile)
+	d = &domain.HoneycombDomain{}
+	rootCmd = domain.CreateRootCommand(d)
+	extendBuildCmd(rootCmd, d)
+	rootCmd.SetArgs([]string{"build", "-f", "json", dir})
+	rootCmd.SetOut(io.Discard)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+
+	records, err := audit.Read(logFile)
+	if err != nil {
+		t.Fatalf("read audit log: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	for _, r := range records {
+		if r.Operation != "build" || r.Path != dir || !r.Success {
+			t.Errorf("unexpected record: %+v", r)
+		}
+		if r.Resources["boards"] != 1 || r.Resources["queries"] != 1 {
+			t.Errorf("unexpected resource counts: %v", r.Resources)
+		}
+	}
+}
+
+func TestRunApply_Audit(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "triggers.go"), []byte(simulateSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := audit.NewRecord("apply", dir, dir, "dev")
+	if err := runApply(context.Background(), &recordingClient{}, dir, applyOptions{audit: rec}); err != nil {
+		t.Fatalf("apply failed: %v", err)
+	}
+
+	if rec.Resources[apply.TypeQuery] != 2 || rec.Resources[apply.TypeTrigger] != 2 {
+		t.Errorf("unexpected resource counts: %v", rec.Resources)
+	}
+	if rec.Changes["create/applied"] != 4 {
+		t.Errorf("expected 4 creations, got %v", rec.Changes)
+	}
+	if len(rec.Environments) != 1 || rec.Environments[0] != "default" {
+		t.Errorf("unexpected environments: %v", rec.Environments)
+	}
+}
+
+func TestPatternDir(t *testing.T) {
+	tests := map[string]string{
+		"./queries/...":        "queries",
+		"./services/.../slos":  "services",
+		"./...":                ".",
+		"...":                  ".",
+		"observability/boards": "observability/boards",
+	}
+	for pattern, want := range tests {
+		if got := patternDir(pattern); got != filepath.FromSlash(want) {
+			t.Errorf("patternDir(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+// Command build adds the grafana export format to the build command.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/apiversion"
+	"github.com/lex00/wetwire-honeycomb-go/internal/atomicfile"
+	"github.com/lex00/wetwire-honeycomb-go/internal/audit"
+	"github.com/lex00/wetwire-honeycomb-go/internal/grafana"
+	"github.com/lex00/wetwire-honeycomb-go/internal/i18n"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lock"
+	"github.com/lex00/wetwire-honeycomb-go/internal/render"
+	"github.com/lex00/wetwire-honeycomb-go/internal/serialize"
+	"github.com/spf13/cobra"
+)
+
+// extendBuildCmd adds --format grafana, --report, --stats, --ids, --refs, --query-mode, --api-version, --strict-api, --keep-going, --rename-on-conflict, --allow-env, --describe, --audit-log and --debug-bundle to the domain build
+// command, accepts several package patterns and writes bare JSON when stdout is
+// piped. Other formats are handled by the domain build command unchanged. The
+// flags set the Options of d, the domain the build command runs.
+func extendBuildCmd(rootCmd *cobra.Command, d *domain.HoneycombDomain) {
+	cmd, _, err := rootCmd.Find([]string{"build"})
+	if err != nil || cmd == rootCmd {
+		return
+	}
+
+	build := cmd.RunE
+
+	cmd.Use = "build [path...]"
+	cmd.Args = cobra.ArbitraryArgs
+	cmd.Long += `
+
+Several paths and Go-style package patterns may be given, as with the go tool:
+"./queries/..." selects queries and every package below it, and
+"./services/.../slos" selects each slos package under services. A plain
+directory includes its subdirectories. The resources of all selected packages
+are built together, and wetwire.lock is written to the directory containing
+them.
+
+With --format grafana, boards are exported as Grafana dashboards whose panels
+query a Honeycomb data source, for teams running both systems during a
+migration. With -o the output is a directory: one <Board>.json per board plus
+` + domain.GrafanaReportFile + `, which lists the board features that could not be
+mapped exactly (SLO panels, preset filters, absolute time ranges).
+
+When stdout is piped and no --format is given, the JSON is written without the
+result summary, so it can be read by another command:
+
+  wetwire-honeycomb build ./queries | wetwire-honeycomb validate -
+
+With --allow-env, ${VAR} references in Dataset fields and trigger recipient
+Target fields are replaced with environment variable values, so the same code
+can target per-team datasets and Slack channels. Without it, any reference is
+a build error.
+
+With --normalize, queries are written in a canonical form: breakdowns and
+filters sorted, the filter combination defaulted to AND and zero limits
+dropped. Reordering breakdowns or filters in Go then leaves the output, and
+wetwire.lock, unchanged. Use the same flag with diff.
+
+With --normalize-windows, the time range of each query a trigger or SLO uses
+is fitted to them, as lint rules WHC055, WHC067 and WHC068 check: a trigger
+query gets a relative range between the trigger's frequency and the longest
+duration it can evaluate, and an SLI query the SLO time period, at most 7
+days. Each query changed is reported as a warning.
+
+With --describe, triggers and SLOs without a Description get one generated
+from their metadata, e.g. "P99(duration_ms) > 500 over 15m on api (query
+HighLatency), checked every 5m", so alerts carry context. --trigger-description
+and --slo-description replace the default Go templates (and imply
+--describe); see the CLI reference for the available fields.
+
+With --stats, the payload sizes of the built resources are printed to stderr
+after the build: count, total bytes and the largest resource of each type.
+Honeycomb rejects oversized API requests, so watch boards with many panels.
+
+With --ids, the content hash ID of each built resource is printed to stderr:
+the first 12 hex digits of the sha256 recorded in wetwire.lock. IDs do not
+change when code is moved or reformatted, only when the JSON does, so they
+can key caches of built output.
+
+A trigger that references a query by name gets a query_id placeholder naming
+it, e.g. "wetwire-ref:queries/Latency", which apply replaces with the ID of
+the saved query it creates. With --refs, a "references" section lists every
+link between the built resources: placeholders with their field, and the
+queries boards and SLOs depend on, so other tools can link resources too.
+With --query-mode inline, triggers embed the spec of their query instead, for
+consumers that cannot resolve references, such as Terraform.
+
+With --api-version, queries are written for a variant of the Query API that
+an older proxy or tool expects. A variant is only added with the Honeycomb
+documentation of each difference it makes; the only one is the default,
+"latest", which writes the current API.
+
+Queries, including those of triggers, SLOs and board panels, are checked
+against a compatibility table of the fields and values the Honeycomb API
+deprecated, as announced by the Honeycomb documentation: each field found
+is reported as a warning naming its replacement and linking that
+documentation. With --strict-api, they fail the build instead, so generated
+configs stay forward-compatible.
+
+A resource that fails to build, e.g. an unset ${VAR} or an invalid time
+range, fails the whole build. With --keep-going, every other resource is
+still built, and the failed ones are listed with their file and line in an
+"` + domain.ErrorsSection + `" section of the output and as errors; the build still exits 1.
+No lockfile is written when a resource failed.
+
+Honeycomb rejects two triggers, or two SLOs, with the same name on a dataset,
+so such duplicates fail the build. With --rename-on-conflict, the later ones
+are renamed instead: "High Latency" becomes "High Latency (2)".
+
+With --only and --exclude, only the resources whose Go variable name matches
+one of the --only globs and none of the --exclude globs are built, across
+resource types: --only 'Checkout*' --exclude '*Debug*'. The queries the
+selected boards, SLOs and triggers reference are built with them. Both flags
+are repeatable and shared with list and graph. A filtered build writes no
+lockfile, since it does not hold every resource.
+
+With --audit-log (or ` + audit.EnvLog + `), a JSON record of the build (user,
+time, git commit, resource counts and outcome) is appended to the given file.`
+
+	var report string
+	var stats bool
+	var ids bool
+	opts := &d.Options
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) > 0 {
+			path = domain.JoinPatterns(args)
+		}
+
+		var rec *audit.Record
+		auditFile := auditLog(cmd)
+		if auditFile != "" {
+			dir := "."
+			if len(args) > 0 {
+				dir = patternDir(args[0])
+			}
+			rec = audit.NewRecord("build", path, dir, domain.Version)
+			rec.Resources, _ = domain.ResourceCounts(path, opts.Filter)
+		}
+
+		var err error
+		format, _ := cmd.Flags().GetString("format")
+		switch {
+		case format == domain.FormatGrafana:
+			output, _ := cmd.Flags().GetString("output")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			err = runGrafanaBuild(os.Stdout, os.Stderr, d, path, output, dryRun)
+		case piped(cmd):
+			err = runPipedBuild(cmd, os.Stdout, os.Stderr, d, path)
+		default:
+			err = build(cmd, []string{path})
+		}
+
+		// The report is written even when the build fails, so CI can track it
+		if report != "" {
+			if rerr := writeBuildReport(report, path, *opts); rerr != nil && err == nil {
+				err = rerr
+			}
+		}
+		if stats && err == nil {
+			err = writePayloadStats(os.Stderr, path, *opts)
+		}
+		if ids && err == nil {
+			err = writeResourceIDs(render.New(os.Stderr), path, *opts)
+		}
+		if perr := reportPanics(cmd, os.Stderr); perr != nil && err == nil {
+			err = perr
+		}
+		if rec != nil {
+			err = finishAudit(auditFile, rec, err)
+		}
+		return err
+	}
+
+	cmd.Flags().BoolVar(&opts.AllowEnv, "allow-env", false, "Expand ${VAR} references in datasets and recipient targets from the environment")
+	cmd.Flags().BoolVar(&opts.Normalize, "normalize", false, "Sort breakdowns and filters, default the filter combination and drop zero limits")
+	cmd.Flags().BoolVar(&opts.NormalizeWindows, "normalize-windows", false, "Fit the time ranges of trigger and SLI queries to their trigger frequency and SLO time period")
+	cmd.Flags().StringVar(&report, "report", "", "Write a JSON build report with per-resource status and totals to this file")
+	cmd.Flags().BoolVar(&stats, "stats", false, "Print the serialized payload sizes of the built resources to stderr")
+	cmd.Flags().BoolVar(&ids, "ids", false, "Print the content hash ID of each built resource to stderr")
+	cmd.Flags().BoolVar(&opts.WriteLock, "write-lock", false, "Write "+lock.FileName+", the hash of each built resource, next to the sources for diff --lock")
+	cmd.Flags().BoolVar(&opts.Refs, "refs", false, "Add a references manifest of the links between resources to the output")
+	cmd.Flags().BoolVar(&opts.KeepGoing, "keep-going", false, "Build every resource that can be built, listing the failed ones in an errors section")
+	cmd.Flags().BoolVar(&opts.RenameOnConflict, "rename-on-conflict", false, "Suffix triggers and SLOs whose name is already used on their dataset instead of failing")
+	cmd.Flags().StringVar(&opts.APIVersion, "api-version", "", "Query API variant to write: "+strings.Join(apiversion.Names(), ", ")+" (default latest)")
+	cmd.Flags().BoolVar(&opts.StrictAPI, "strict-api", false, fmt.Sprintf("Fail on query fields deprecated by the Honeycomb API (compatibility table v%d) instead of warning", serialize.CompatibilityVersion))
+	addResourceFlags(cmd, opts)
+	addNameFilterFlags(cmd, "build", &opts.Filter)
+	addAuditLogFlag(cmd)
+	addDebugBundleFlag(cmd)
+}
+
+// addResourceFlags adds the build flags that change the built resources, and
+// so their hashes in wetwire.lock, to cmd: --describe, --trigger-description,
+// --slo-description and --query-mode. apply and diff take them too, so they
+// see the resources build wrote.
+func addResourceFlags(cmd *cobra.Command, opts *domain.Options) {
+	cmd.Flags().BoolVar(&opts.Describe, "describe", false, "Generate descriptions for triggers and SLOs that have none")
+	cmd.Flags().StringVar(&opts.TriggerDescription, "trigger-description", "", "Go template for generated trigger descriptions (implies --describe)")
+	cmd.Flags().StringVar(&opts.SLODescription, "slo-description", "", "Go template for generated SLO descriptions (implies --describe)")
+	cmd.Flags().StringVar(&opts.QueryMode, "query-mode", "", "How triggers name their query: reference (query_id resolved by apply) or inline (embedded query spec)")
+}
+
+// writePayloadStats prints the payload sizes of the resources in path, built
+// with opts, to w.
+func writePayloadStats(w io.Writer, path string, opts domain.Options) error {
+	stats, err := domain.GeneratePayloadStats(path, opts)
+	if err != nil {
+		return fmt.Errorf("payload stats: %w", err)
+	}
+
+	count, total := 0, 0
+	fmt.Fprintln(w, i18n.T("Payload sizes:"))
+	for _, s := range stats {
+		fmt.Fprintf(w, "  %-10s %4d  %s\n", s.Section, s.Count, i18n.Sprintf("%8d bytes  largest %s (%d bytes)", s.Bytes, s.Largest, s.LargestBytes))
+		count += s.Count
+		total += s.Bytes
+	}
+	fmt.Fprintf(w, "  %-10s %4d  %s\n", "total", count, i18n.Sprintf("%8d bytes", total))
+	return nil
+}
+
+// writeResourceIDs prints the content hash ID of each resource in path, built
+// with opts.
+func writeResourceIDs(r *render.Renderer, path string, opts domain.Options) error {
+	ids, err := domain.GenerateResourceIDs(path, opts)
+	if err != nil {
+		return fmt.Errorf("resource IDs: %w", err)
+	}
+
+	r.Printf("%s\n", i18n.T("Resource IDs:"))
+	rows := make([][]render.Cell, len(ids))
+	for i, id := range ids {
+		rows[i] = []render.Cell{{Text: id.ID, Color: render.Cyan}, {Text: lock.Key(id.Section, id.Name)}}
+	}
+	r.Table("  ", rows)
+	return nil
+}
+
+// writeBuildReport writes the build report for path, built with opts, to file.
+func writeBuildReport(file, path string, opts domain.Options) error {
+	ctx := coredomain.NewContext(context.Background(), path)
+	report, err := domain.GenerateBuildReport(ctx, path, opts)
+	if err != nil {
+		return fmt.Errorf("build report: %w", err)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("build report: %w", err)
+	}
+	if err := atomicfile.WriteFile(file, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write build report: %w", err)
+	}
+	return nil
+}
+
+// runGrafanaBuild exports boards as Grafana dashboards with the builder of d.
+// Dashboards go to stdout (or the output directory); the mapping report goes
+// to stderr.
+func runGrafanaBuild(stdout, stderr io.Writer, d *domain.HoneycombDomain, path, output string, dryRun bool) error {
+	ctx := coredomain.NewContext(context.Background(), path)
+	result, err := d.Builder().Build(ctx, path, domain.BuildOpts{
+		Format: domain.FormatGrafana,
+		Output: output,
+		DryRun: dryRun,
+	})
+	if err != nil {
+		return fmt.Errorf("build failed: %w", err)
+	}
+	if !result.Success {
+		writeText(render.New(stderr), result)
+		return fmt.Errorf("build failed: %s", result.Message)
+	}
+
+	data, ok := result.Data.(string)
+	if !ok {
+		fmt.Fprintln(stderr, result.Message)
+		return nil
+	}
+	fmt.Fprintln(stdout, data)
+
+	var out struct {
+		MappingReport []grafana.Note `json:"mapping_report"`
+	}
+	if err := json.Unmarshal([]byte(data), &out); err == nil && len(out.MappingReport) > 0 {
+		fmt.Fprintln(stderr, i18n.Sprintf("%d board feature(s) could not be mapped exactly:", len(out.MappingReport)))
+		for _, n := range out.MappingReport {
+			fmt.Fprintf(stderr, "  %s\n", n)
+		}
+	}
+	return nil
+}
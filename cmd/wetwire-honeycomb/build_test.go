@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/render"
+)
+
+const grafanaSource = `package boards
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+var Latency = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Calculations: []query.Calculation{query.P99("duration_ms")},
+}
+
+var Overview = board.Board{
+	Name: "API Overview",
+	Panels: []board.Panel{
+		board.QueryPanel(Latency, board.WithTitle("Latency")),
+		board.SLOPanelByID("slo-123", board.WithTitle("Availability")),
+	},
+}
+`
+
+func TestRunGrafanaBuild(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "boards.go"), []byte(grafanaSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := runGrafanaBuild(&stdout, &stderr, &domain.HoneycombDomain{}, dir, "", false); err != nil {
+		t.Fatalf("runGrafanaBuild failed: %v", err)
+	}
+
+	var out struct {
+		Dashboards map[string]struct {
+			Title  string `json:"title"`
+			Panels []struct {
+				Type string `json:"type"`
+			} `json:"panels"`
+		} `json:"dashboards"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		t.Fatalf("invalid output: %v\n%s", err, stdout.String())
+	}
+	overview, ok := out.Dashboards["Overview"]
+	if !ok || overview.Title != "API Overview" || len(overview.Panels) != 2 {
+		t.Errorf("unexpected dashboard: %+v", out.Dashboards)
+	}
+	if !strings.Contains(stderr.String(), "slo panel") {
+		t.Errorf("expected SLO panel in mapping report, got %q", stderr.String())
+	}
+
+	// With an output directory, one file per board plus the report
+	outDir := filepath.Join(t.TempDir(), "grafana")
+	stdout.Reset()
+	if err := runGrafanaBuild(&stdout, &stderr, &domain.HoneycombDomain{}, dir, outDir, false); err != nil {
+		t.Fatalf("runGrafanaBuild with output failed: %v", err)
+	}
+	for _, name := range []string{"Overview.json", domain.GrafanaReportFile} {
+		if _, err := os.Stat(filepath.Join(outDir, name)); err != nil {
+			t.Errorf("expected %s: %v", name, err)
+		}
+	}
+}
+
+func TestExtendBuildCmd(t *testing.T) {
+	d := &domain.HoneycombDomain{}
+	rootCmd := domain.CreateRootCommand(d)
+	extendBuildCmd(rootCmd, d)
+
+	cmd, _, err := rootCmd.Find([]string{"build"})
+	if err != nil {
+		t.Fatalf("build command not found: %v", err)
+	}
+	if !strings.Contains(cmd.Long, "--format grafana") {
+		t.Error("expected build help to document --format grafana")
+	}
+}
+
+func TestBuildReportFlag(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "boards.go"), []byte(grafanaSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reportFile := filepath.Join(t.TempDir(), "report.json")
+
+	d := &domain.HoneycombDomain{}
+	rootCmd := domain.CreateRootCommand(d)
+	extendBuildCmd(rootCmd, d)
+	rootCmd.SetArgs([]string{"build", "-f", "json", "--report", reportFile, dir})
+	rootCmd.SetOut(io.Discard)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+
+	data, err := os.ReadFile(reportFile)
+	if err != nil {
+		t.Fatalf("report not written: %v", err)
+	}
+	var report domain.BuildReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid report: %v", err)
+	}
+	if !report.Success || report.Stats.Resources != 2 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if report.Stats.ByType["board"] != 1 || report.Stats.ByType["query"] != 1 {
+		t.Errorf("unexpected counts: %+v", report.Stats.ByType)
+	}
+}
+
+func TestBuildPatterns(t *testing.T) {
+	root := t.TempDir()
+	source := "package p\n\nimport \"github.com/lex00/wetwire-honeycomb-go/query\"\n\n" +
+		"var %s = query.Query{Dataset: \"api\", TimeRange: query.Hours(1), Calculations: []query.Calculation{query.Count()}}\n"
+	for _, pkg := range []string{"queries/api", "services/payment/slos", "services/payment/web"} {
+		dir := filepath.Join(root, filepath.FromSlash(pkg))
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		name := strings.ToUpper(filepath.Base(pkg))
+		if err := os.WriteFile(filepath.Join(dir, "q.go"), []byte(fmt.Sprintf(source, name)), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	out := filepath.Join(t.TempDir(), "out.json")
+
+	d := &domain.HoneycombDomain{}
+	rootCmd := domain.CreateRootCommand(d)
+	extendBuildCmd(rootCmd, d)
+	rootCmd.SetArgs([]string{"build", "-f", "json", "-o", out, "--write-lock",
+		filepath.Join(root, "queries", "..."), filepath.Join(root, "services", "...", "slos")})
+	rootCmd.SetOut(io.Discard)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var output map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(data, &output); err != nil {
+		t.Fatal(err)
+	}
+	if len(output["queries"]) != 2 || output["queries"]["API"] == nil || output["queries"]["SLOS"] == nil {
+		t.Errorf("expected API and SLOS queries, got %s", data)
+	}
+	if _, err := os.Stat(filepath.Join(root, "wetwire.lock")); err != nil {
+		t.Errorf("expected lockfile in the common directory: %v", err)
+	}
+}
+
+func TestWritePayloadStats(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "boards.go"), []byte(grafanaSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := writePayloadStats(&out, dir, domain.Options{}); err != nil {
+		t.Fatalf("writePayloadStats failed: %v", err)
+	}
+	for _, want := range []string{"boards", "largest Overview", "queries", "largest Latency", "total         2"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestWriteResourceIDs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "boards.go"), []byte(grafanaSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := writeResourceIDs(render.NewColor(&out, false), dir, domain.Options{}); err != nil {
+		t.Fatalf("writeResourceIDs failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 || lines[0] != "Resource IDs:" {
+		t.Fatalf("expected a header and 2 IDs, got:\n%s", out.String())
+	}
+	for i, key := range []string{"boards/Overview", "queries/Latency"} {
+		if !regexp.MustCompile(`^  [0-9a-f]{12}  ` + key + `$`).MatchString(lines[i+1]) {
+			t.Errorf("expected the ID of %s, got %q", key, lines[i+1])
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	api "github.com/lex00/wetwire-honeycomb-go/internal/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lock"
+	"github.com/spf13/cobra"
+)
+
+// envGitHubStepSummary names the file GitHub Actions renders as the job
+// summary.
+const envGitHubStepSummary = "GITHUB_STEP_SUMMARY"
+
+// errCIFailed is returned by runCI when lint reports errors or the code
+// drifted from the lockfile.
+var errCIFailed = errors.New("ci checks failed")
+
+// newCICmd creates the "ci" command.
+func newCICmd() *cobra.Command {
+	var github, live bool
+
+	cmd := &cobra.Command{
+		Use:   "ci [path]",
+		Short: "Lint and check drift against wetwire.lock in CI",
+		Long: `Run the checks of a pull request on path: lint (which validate runs too)
+and, when path has a wetwire.lock, the reconciliation of diff --lock. The
+command fails when lint reports an error or a resource is not in sync.
+
+With --github, lint findings are written as GitHub Actions workflow
+commands (::error file=...,line=...::), which annotate the pull request,
+and the drift table is written as Markdown to the job summary
+($GITHUB_STEP_SUMMARY, or stdout outside Actions).
+
+With --live, the reconciliation includes live Honeycomb resources
+(requires HONEYCOMB_API_KEY).
+
+Example:
+    - run: wetwire-honeycomb ci --github ./observability`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			var lister lock.ResourceLister
+			if live {
+				client, err := api.NewClientFromEnv()
+				if err != nil {
+					return err
+				}
+				lister = client
+			}
+
+			// Failures are reported by main, with the exit status
+			cmd.SilenceUsage, cmd.SilenceErrors = true, true
+			summary := ""
+			if github {
+				summary = os.Getenv(envGitHubStepSummary)
+			}
+			return runCI(cmd.Context(), os.Stdout, lister, path, github, summary)
+		},
+	}
+
+	cmd.Flags().BoolVar(&github, "github", false, "Write GitHub Actions annotations and a job summary")
+	cmd.Flags().BoolVar(&live, "live", false, "Include live Honeycomb resources in the drift check")
+
+	return cmd
+}
+
+// ciReport is the outcome of the checks of runCI.
+type ciReport struct {
+	// Issues are the lint findings
+	Issues []coredomain.Error
+
+	// Drift is the lockfile reconciliation, nil when there is no lockfile
+	Drift *lock.Report
+}
+
+// failed reports whether lint found an error or a resource is not in sync.
+func (r *ciReport) failed() bool {
+	for _, issue := range r.Issues {
+		if issue.Severity == "error" {
+			return true
+		}
+	}
+	return r.Drift != nil && r.Drift.HasDrift()
+}
+
+// runCI lints path and reconciles it with its lockfile, writing the findings
+// to w as text or, with github, as workflow commands followed by the job
+// summary, which is appended to summaryFile when set.
+func runCI(ctx context.Context, w io.Writer, lister lock.ResourceLister, path string, github bool, summaryFile string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolve path: %w", err)
+	}
+
+	result, err := (&domain.HoneycombDomain{}).Linter().Lint(coredomain.NewContext(ctx, path), path, domain.LintOpts{Format: "text"})
+	if err != nil {
+		return fmt.Errorf("lint failed: %w", err)
+	}
+	report := &ciReport{Issues: result.Errors}
+
+	report.Drift, err = reconcileLock(ctx, lister, absPath, domain.Options{})
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if !github {
+		result.Data = nil
+		_ = writeResult(w, result, "text")
+		fmt.Fprintln(w)
+		if report.Drift == nil {
+			fmt.Fprintf(w, "No %s in %s; drift not checked\n", lock.FileName, path)
+		} else {
+			printLockReport(w, report.Drift, filepath.Join(absPath, lock.FileName))
+		}
+	} else {
+		writeAnnotations(w, report.Issues)
+		summary := ciSummary(report)
+		if summaryFile == "" {
+			fmt.Fprint(w, summary)
+		} else if err := appendFile(summaryFile, summary); err != nil {
+			return fmt.Errorf("write job summary: %w", err)
+		}
+	}
+
+	if report.failed() {
+		return &exitError{code: 1, err: errCIFailed}
+	}
+	return nil
+}
+
+// writeAnnotations writes each issue as a GitHub Actions workflow command:
+// ::error for errors, ::warning for warnings and ::notice for the others.
+func writeAnnotations(w io.Writer, issues []coredomain.Error) {
+	for _, issue := range issues {
+		command := "notice"
+		switch issue.Severity {
+		case "error":
+			command = "error"
+		case "warning":
+			command = "warning"
+		}
+
+		var props []string
+		if issue.Path != "" {
+			props = append(props, "file="+escapeProperty(filepath.ToSlash(displayPath(issue.Path))))
+			if issue.Line > 0 {
+				props = append(props, fmt.Sprintf("line=%d", issue.Line))
+			}
+			if issue.Column > 0 {
+				props = append(props, fmt.Sprintf("col=%d", issue.Column))
+			}
+		}
+		if issue.Code != "" {
+			props = append(props, "title="+escapeProperty(issue.Code))
+		}
+
+		fmt.Fprintf(w, "::%s", command)
+		if len(props) > 0 {
+			fmt.Fprintf(w, " %s", strings.Join(props, ","))
+		}
+		fmt.Fprintf(w, "::%s\n", escapeData(issue.Message))
+	}
+}
+
+// escapeData escapes the message of a workflow command.
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a property value of a workflow command.
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// ciSummary returns the job summary of a report as Markdown: lint counts by
+// severity, then the resources that are not in sync.
+func ciSummary(report *ciReport) string {
+	var b strings.Builder
+	b.WriteString("## wetwire-honeycomb\n\n")
+
+	counts := make(map[string]int)
+	for _, issue := range report.Issues {
+		counts[issue.Severity]++
+	}
+	if len(report.Issues) == 0 {
+		b.WriteString("**Lint:** no issues\n\n")
+	} else {
+		fmt.Fprintf(&b, "**Lint:** %d error(s), %d warning(s), %d other\n\n",
+			counts["error"], counts["warning"], len(report.Issues)-counts["error"]-counts["warning"])
+	}
+
+	if report.Drift == nil {
+		fmt.Fprintf(&b, "**Drift:** not checked, no `%s`\n", lock.FileName)
+		return b.String()
+	}
+
+	mode := "code vs lockfile"
+	if report.Drift.LiveChecked {
+		mode = "code vs lockfile vs live"
+	}
+	var drifted []lock.Drift
+	for _, d := range report.Drift.Resources {
+		if d.Status != lock.StatusInSync {
+			drifted = append(drifted, d)
+		}
+	}
+	if len(drifted) == 0 {
+		fmt.Fprintf(&b, "**Drift** (%s): all %d resource(s) in sync\n", mode, len(report.Drift.Resources))
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "**Drift** (%s): %d of %d resource(s) not in sync\n\n", mode, len(drifted), len(report.Drift.Resources))
+	b.WriteString("| Resource | Type | Status |\n|----------|------|--------|\n")
+	for _, d := range drifted {
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", markdownCell(d.Name), d.Type, d.Status)
+	}
+	return b.String()
+}
+
+// appendFile appends data to the file at path, creating it when missing.
+func appendFile(path, data string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+)
+
+func TestRunCI_GitHub(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "triggers.go")
+	if err := os.WriteFile(file, []byte(simulateSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	summary := filepath.Join(t.TempDir(), "summary.md")
+
+	// The triggers have no recipients, a lint error (WHC053)
+	var out bytes.Buffer
+	err := runCI(context.Background(), &out, nil, dir, true, summary)
+	var exit *exitError
+	if !errors.As(err, &exit) || exit.code != 1 {
+		t.Fatalf("expected exit status 1, got %v", err)
+	}
+	if !strings.Contains(out.String(), "::error file=") || !strings.Contains(out.String(), ",title=WHC053::") {
+		t.Errorf("expected a WHC053 error annotation, got %q", out.String())
+	}
+	data, err := os.ReadFile(summary)
+	if err != nil || !strings.Contains(string(data), "**Drift:** not checked") {
+		t.Errorf("expected the drift check to be skipped without a lockfile: %s %v", data, err)
+	}
+
+	ctx := coredomain.NewContext(context.Background(), dir)
+	if _, err := (&domain.HoneycombDomain{Options: domain.Options{WriteLock: true}}).Builder().Build(ctx, dir, domain.BuildOpts{Output: filepath.Join(t.TempDir(), "out.json")}); err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+	modified := strings.Replace(simulateSource, "trigger.Minutes(5)", "trigger.Minutes(10)", 1)
+	if err := os.WriteFile(file, []byte(modified), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a summary file the summary follows the annotations, and is
+	// appended to an existing one
+	out.Reset()
+	_ = runCI(context.Background(), &out, nil, dir, true, "")
+	if !strings.Contains(out.String(), "| HighErrors | triggers | modified in code |") {
+		t.Errorf("expected the modified trigger in the drift table, got %q", out.String())
+	}
+	_ = runCI(context.Background(), &out, nil, dir, true, summary)
+	if data, _ := os.ReadFile(summary); strings.Count(string(data), "## wetwire-honeycomb") != 2 {
+		t.Errorf("expected the summary to be appended: %s", data)
+	}
+}
+
+func TestWriteAnnotations(t *testing.T) {
+	var out bytes.Buffer
+	writeAnnotations(&out, []coredomain.Error{
+		{Path: "a,b.go", Line: 3, Severity: "warning", Code: "WHC004", Message: "50% of\nqueries"},
+		{Severity: "info", Message: "no location"},
+	})
+
+	want := "::warning file=a%2Cb.go,line=3,title=WHC004::50%25 of%0Aqueries\n::notice::no location\n"
+	if out.String() != want {
+		t.Errorf("annotations = %q, want %q", out.String(), want)
+	}
+}
@@ -0,0 +1,43 @@
+// Command diagnostics reports files discovery skipped after a panic and
+// writes them to a bug report bundle.
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/diagnostics"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/i18n"
+	"github.com/spf13/cobra"
+)
+
+// addDebugBundleFlag adds --debug-bundle to cmd.
+func addDebugBundleFlag(cmd *cobra.Command) {
+	cmd.Flags().String("debug-bundle", "", "Write a zip bundle for bug reports when discovery skips a file after a panic")
+}
+
+// reportPanics warns on w about each file discovery skipped because
+// extracting it panicked, and writes them to the --debug-bundle of cmd, if
+// one was given. It does nothing when discovery did not panic.
+func reportPanics(cmd *cobra.Command, w io.Writer) error {
+	recovered := discovery.Panics()
+	if len(recovered) == 0 {
+		return nil
+	}
+
+	for _, p := range recovered {
+		fmt.Fprintln(w, i18n.Sprintf("warning: skipped %s in %s: discovery panicked: %s", p.Kind, displayPath(p.File), p.Value))
+	}
+	bundle, _ := cmd.Flags().GetString("debug-bundle")
+	if bundle == "" {
+		fmt.Fprintln(w, i18n.T("Rerun with --debug-bundle FILE.zip and attach the bundle to a bug report"))
+		return nil
+	}
+	if err := diagnostics.WriteBundle(bundle, recovered, domain.Version); err != nil {
+		return err
+	}
+	fmt.Fprintln(w, i18n.Sprintf("Wrote diagnostics bundle %s", bundle))
+	return nil
+}
@@ -1,110 +1,249 @@
-// Command diff provides semantic comparison of Honeycomb Query JSON files.
+// Command diff compares Honeycomb build outputs, or generated output against
+// an existing file, with the semantic differ.
 package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"reflect"
+	"regexp"
 	"strings"
 
-	"github.com/lex00/wetwire-honeycomb-go/internal/builder"
-	"github.com/lex00/wetwire-honeycomb-go/internal/serialize"
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/differ"
+	api "github.com/lex00/wetwire-honeycomb-go/internal/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/i18n"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lock"
+	"github.com/lex00/wetwire-honeycomb-go/internal/normalize"
+	"github.com/lex00/wetwire-honeycomb-go/internal/render"
 	"github.com/spf13/cobra"
 )
 
-func newDiffCmd() *cobra.Command {
+// Exit codes of the diff command; 0 means no differences.
+const (
+	diffExitDifferent = 1
+	diffExitError     = 2
+)
+
+// errDifferences is returned when compared outputs differ.
+var errDifferences = errors.New("differences found")
+
+// extendDiffCmd replaces the domain diff command's comparison with the
+// Honeycomb differ and adds --output (generated output against a file), --lock
+// reconciliation, comparison options and -q. Every mode exits 0 when
+// identical, 1 when different and 2 on error.
+func extendDiffCmd(rootCmd *cobra.Command) {
+	cmd, _, err := rootCmd.Find([]string{"diff"})
+	if err != nil || cmd == rootCmd {
+		return
+	}
+
+	var useLock bool
+	var live bool
 	var outputFile string
 	var semantic bool
-	var verbose bool
-
-	cmd := &cobra.Command{
-		Use:   "diff [packages]",
-		Short: "Compare generated output vs existing config",
-		Args:  cobra.MaximumNArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			if outputFile == "" {
-				return fmt.Errorf("--output flag is required")
-			}
+	var quiet bool
+	var diffOpts diffFlags
+
+	fileArgs := cmd.Args
+
+	cmd.Use = "diff <file1> <file2> | diff --output FILE [path...] | diff --lock [path]"
+	cmd.Long += `
+
+With --output, build the packages in path (default ".", Go-style patterns are
+accepted) and compare the result against FILE, a previous "build" output or -
+for stdin. The comparison is line by line unless --semantic is given.
+
+With --lock, regenerate the resources in path (default ".") and reconcile them
+against the wetwire.lock written by "build --write-lock". Add --live to also fetch the
+live resources from Honeycomb (requires HONEYCOMB_API_KEY) for a three-way
+report.
+
+For semantic comparisons, --ignore leaves fields such as time_range out of the
+comparison, --tolerance accepts small numeric differences (e.g. in
+thresholds), and --include/--exclude select resources by name with regular
+expressions:
+
+  wetwire-honeycomb diff --ignore time_range --exclude '^Debug' old.json new.json
+
+With --normalize, both sides are compared after the normalization pass of
+"build --normalize", so sorted breakdowns and filters, the default AND filter
+combination and zero limits are not reported as differences.
+
+Exit status is 0 when there are no differences, 1 when there are and 2 on
+errors, including unknown flags and wrong arguments, in every mode. With -q nothing is printed, for scripts that only need
+the exit status.`
+
+	cmd.Args = func(cmd *cobra.Command, args []string) error {
+		var err error
+		switch {
+		case useLock:
+			err = cobra.MaximumNArgs(1)(cmd, args)
+		case outputFile != "":
+			err = nil
+		default:
+			err = fileArgs(cmd, args)
+		}
+		if err != nil {
+			return &exitError{code: diffExitError, err: err}
+		}
+		return nil
+	}
 
-			path := "."
-			if len(args) > 0 {
-				path = args[0]
-			}
+	// Unknown or malformed flags are usage errors too, not differences
+	cmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &exitError{code: diffExitError, err: err}
+	})
 
-			// Build queries
-			b, err := builder.NewBuilder(path)
-			if err != nil {
-				return fmt.Errorf("error: %w", err)
-			}
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		// Differences are reported through the exit status, not as usage errors
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
 
-			result, err := b.Build()
-			if err != nil {
-				return fmt.Errorf("build failed: %w", err)
-			}
+		w := cmd.OutOrStdout()
+		if quiet {
+			w = io.Discard
+		}
 
-			if result.QueryCount() == 0 {
-				return fmt.Errorf("no queries found")
-			}
+		var err error
+		switch {
+		case live && !useLock:
+			err = fmt.Errorf("--live requires --lock")
+		case useLock && outputFile != "":
+			err = fmt.Errorf("--lock and --output cannot be combined")
+		case useLock:
+			err = runLockDiffCmd(cmd, w, args, live, diffOpts)
+		case outputFile != "":
+			err = runGeneratedDiff(cmd, w, args, outputFile, semantic, diffOpts)
+		default:
+			err = runFileDiff(cmd, w, args[0], args[1], diffOpts)
+		}
+		return diffExit(err, quiet)
+	}
 
-			// Generate current output
-			queries := result.Queries()
-			var currentJSON []byte
-
-			if len(queries) == 1 {
-				q := discoveredToQuery(queries[0])
-				currentJSON, err = serialize.ToJSONPretty(q)
-			} else {
-				queryMap := make(map[string]json.RawMessage)
-				for _, dq := range queries {
-					q := discoveredToQuery(dq)
-					data, e := serialize.ToJSON(q)
-					if e != nil {
-						err = e
-						break
-					}
-					queryMap[dq.Name] = data
-				}
-				if err == nil {
-					currentJSON, err = json.MarshalIndent(queryMap, "", "  ")
-				}
-			}
+	cmd.Flags().StringVar(&outputFile, "output", "", "Compare the build of path against this file (- for stdin)")
+	cmd.Flags().BoolVar(&semantic, "semantic", false, "With --output, compare resources semantically instead of line by line")
+	cmd.Flags().BoolVar(&useLock, "lock", false, "Reconcile code against wetwire.lock instead of comparing two files")
+	cmd.Flags().BoolVar(&live, "live", false, "With --lock, include live Honeycomb resources in the reconciliation")
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "Print nothing; report differences through the exit status only")
+	cmd.Flags().StringSliceVar(&diffOpts.ignore, "ignore", nil, "Fields to leave out of the comparison, e.g. time_range (repeatable)")
+	cmd.Flags().Float64Var(&diffOpts.tolerance, "tolerance", 0, "Largest numeric difference not reported")
+	cmd.Flags().StringVar(&diffOpts.include, "include", "", "Only compare resources whose name matches this regular expression")
+	cmd.Flags().StringVar(&diffOpts.exclude, "exclude", "", "Skip resources whose name matches this regular expression")
+	cmd.Flags().BoolVar(&diffOpts.normalize, "normalize", false, "Compare resources after sorting breakdowns and filters, defaulting the filter combination and dropping zero limits")
+	addResourceFlags(cmd, &diffOpts.build)
+}
 
-			if err != nil {
-				return fmt.Errorf("serialization failed: %w", err)
-			}
+// diffExit converts the outcome of a diff to the command's exit status: 0
+// when identical, 1 when different and 2 on error. With quiet, differences
+// exit without a message.
+func diffExit(err error, quiet bool) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, errDifferences), errors.Is(err, errDrift):
+		if quiet {
+			return &exitError{code: diffExitDifferent}
+		}
+		return &exitError{code: diffExitDifferent, err: err}
+	default:
+		return &exitError{code: diffExitError, err: err}
+	}
+}
 
-			// Read existing file
-			existingJSON, err := os.ReadFile(outputFile)
-			if err != nil {
-				return fmt.Errorf("error reading %s: %w", outputFile, err)
-			}
+// runLockDiffCmd runs the --lock reconciliation for the diff command.
+func runLockDiffCmd(cmd *cobra.Command, w io.Writer, args []string, live bool, flags diffFlags) error {
+	path := "."
+	if len(args) > 0 {
+		path = args[0]
+	}
+	format, _ := cmd.Flags().GetString("format")
 
-			// Compare
-			if semantic {
-				return semanticDiff(currentJSON, existingJSON, verbose)
-			}
-			return textDiff(currentJSON, existingJSON, outputFile, verbose)
-		},
+	var lister lock.ResourceLister
+	if live {
+		client, err := api.NewClientFromEnv()
+		if err != nil {
+			return err
+		}
+		lister = client
+	}
+
+	return runLockDiff(cmd.Context(), w, lister, path, format, flags.buildOptions())
+}
+
+// runGeneratedDiff builds the packages in args and compares the output with
+// outputFile, line by line or, with semantic, resource by resource.
+func runGeneratedDiff(cmd *cobra.Command, w io.Writer, args []string, outputFile string, semantic bool, flags diffFlags) error {
+	format, _ := cmd.Flags().GetString("format")
+	semantic = semantic || format == "json"
+	if flags.set(cmd) && !semantic {
+		return fmt.Errorf("--ignore, --tolerance, --include and --exclude require --semantic")
 	}
 
-	cmd.Flags().StringVar(&outputFile, "output", "", "JSON file to compare against")
-	cmd.Flags().BoolVar(&semantic, "semantic", false, "Compare semantic structure instead of text")
-	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
+	path := "."
+	if len(args) > 0 {
+		path = domain.JoinPatterns(args)
+	}
+	output, err := buildOutput(path, flags.buildOptions())
+	if err != nil {
+		return err
+	}
+	generated, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serialization failed: %w", err)
+	}
+
+	// Read existing file, or stdin for "-"
+	var existing []byte
+	if outputFile == domain.StdinPath {
+		existing, err = io.ReadAll(domain.Stdin)
+	} else {
+		existing, err = os.ReadFile(outputFile)
+	}
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", outputFile, err)
+	}
+
+	if !semantic {
+		if flags.normalize {
+			existing = normalizeOutput(existing)
+		}
+		// Indent the existing JSON like the generated output, so files
+		// written by "build -o" compare line by line
+		var indented bytes.Buffer
+		if json.Indent(&indented, existing, "", "  ") == nil {
+			existing = indented.Bytes()
+		}
+		return textDiff(w, generated, existing, outputFile)
+	}
 
-	return cmd
+	opts, err := flags.options()
+	if err != nil {
+		return err
+	}
+	ignoreOrder, _ := cmd.Flags().GetBool("ignore-order")
+	result, err := differ.NewWithOptions(opts).DiffData(existing, generated, coredomain.DiffOpts{IgnoreOrder: ignoreOrder})
+	if err != nil {
+		return fmt.Errorf("diff failed: %w", err)
+	}
+	return reportDiff(w, result, format, outputFile, "generated output")
 }
 
-func textDiff(current, existing []byte, filename string, verbose bool) error {
-	// Normalize line endings
-	current = bytes.ReplaceAll(current, []byte("\r\n"), []byte("\n"))
-	existing = bytes.ReplaceAll(existing, []byte("\r\n"), []byte("\n"))
+// textDiff writes the lines that differ between the existing file and the
+// generated output.
+func textDiff(w io.Writer, current, existing []byte, filename string) error {
+	// Normalize line endings and the final newline
+	current = bytes.TrimRight(bytes.ReplaceAll(current, []byte("\r\n"), []byte("\n")), "\n")
+	existing = bytes.TrimRight(bytes.ReplaceAll(existing, []byte("\r\n"), []byte("\n")), "\n")
 
 	if bytes.Equal(current, existing) {
-		if verbose {
-			fmt.Println("Files are identical")
-		}
+		fmt.Fprintln(w, i18n.Sprintf("No differences between %s and generated output", filename))
 		return nil
 	}
 
@@ -112,8 +251,9 @@ func textDiff(current, existing []byte, filename string, verbose bool) error {
 	currentLines := strings.Split(string(current), "\n")
 	existingLines := strings.Split(string(existing), "\n")
 
-	fmt.Printf("--- %s (existing)\n", filename)
-	fmt.Println("+++ generated")
+	r := render.New(w)
+	fmt.Fprintf(w, "--- %s (existing)\n", filename)
+	fmt.Fprintln(w, "+++ generated")
 
 	maxLen := len(currentLines)
 	if len(existingLines) > maxLen {
@@ -131,102 +271,151 @@ func textDiff(current, existing []byte, filename string, verbose bool) error {
 
 		if currLine != existLine {
 			if existLine != "" {
-				fmt.Printf("-%s\n", existLine)
+				fmt.Fprintln(w, r.Paint(render.Red, "-"+existLine))
 			}
 			if currLine != "" {
-				fmt.Printf("+%s\n", currLine)
+				fmt.Fprintln(w, r.Paint(render.Green, "+"+currLine))
 			}
 		}
 	}
 
-	return fmt.Errorf("files differ")
+	return errDifferences
 }
 
-func semanticDiff(current, existing []byte, verbose bool) error {
-	var currData, existData interface{}
+// normalizeOutput returns a build output as "build --normalize" would write
+// it, or data unchanged if it is not a build output.
+func normalizeOutput(data []byte) []byte {
+	output, err := honeycomb.Unmarshal(data)
+	if err != nil || normalize.Output(output) != nil {
+		return data
+	}
+	normalized, err := output.Marshal()
+	if err != nil {
+		return data
+	}
+	return normalized
+}
 
-	if err := json.Unmarshal(current, &currData); err != nil {
-		return fmt.Errorf("error parsing generated JSON: %w", err)
+// diffFlags are the comparison options of the diff command.
+type diffFlags struct {
+	ignore    []string
+	tolerance float64
+	include   string
+	exclude   string
+	normalize bool
+
+	// build holds the options of the builds compared with --output and
+	// --lock: the flags of addResourceFlags
+	build domain.Options
+}
+
+// set reports whether any comparison option was given on cmd.
+func (f diffFlags) set(cmd *cobra.Command) bool {
+	for _, name := range []string{"ignore", "tolerance", "include", "exclude"} {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
 	}
+	return false
+}
 
-	if err := json.Unmarshal(existing, &existData); err != nil {
-		return fmt.Errorf("error parsing existing JSON: %w", err)
+// buildOptions returns the options of the builds compared with --output and
+// --lock, which are normalized like "build --normalize" with --normalize.
+func (f diffFlags) buildOptions() domain.Options {
+	opts := f.build
+	opts.Normalize = f.normalize
+	return opts
+}
+
+// options converts the flags to differ options.
+func (f diffFlags) options() (differ.Options, error) {
+	opts := differ.Options{Ignore: f.ignore, Tolerance: f.tolerance, Normalize: f.normalize}
+	if f.tolerance < 0 {
+		return opts, fmt.Errorf("--tolerance must not be negative")
 	}
 
-	if reflect.DeepEqual(currData, existData) {
-		if verbose {
-			fmt.Println("Semantically identical")
+	var err error
+	if f.include != "" {
+		if opts.Include, err = regexp.Compile(f.include); err != nil {
+			return opts, fmt.Errorf("invalid --include: %w", err)
 		}
-		return nil
 	}
+	if f.exclude != "" {
+		if opts.Exclude, err = regexp.Compile(f.exclude); err != nil {
+			return opts, fmt.Errorf("invalid --exclude: %w", err)
+		}
+	}
+	return opts, nil
+}
 
-	// Show structural differences
-	diffs := compareJSON(currData, existData, "")
-	for _, d := range diffs {
-		fmt.Println(d)
+// runFileDiff compares two build outputs with the comparison options in
+// flags.
+func runFileDiff(cmd *cobra.Command, w io.Writer, file1, file2 string, flags diffFlags) error {
+	opts, err := flags.options()
+	if err != nil {
+		return err
 	}
+	ignoreOrder, _ := cmd.Flags().GetBool("ignore-order")
+	format, _ := cmd.Flags().GetString("format")
 
-	return fmt.Errorf("semantic differences found")
+	ctx := coredomain.NewContext(context.Background(), ".")
+	result, err := differ.NewWithOptions(opts).Diff(ctx, file1, file2, coredomain.DiffOpts{IgnoreOrder: ignoreOrder})
+	if err != nil {
+		return fmt.Errorf("diff failed: %w", err)
+	}
+	return reportDiff(w, result, format, file1, file2)
 }
 
-func compareJSON(a, b interface{}, path string) []string {
-	var diffs []string
+// reportDiff prints a diff result and returns errDifferences when it has
+// any entries.
+func reportDiff(w io.Writer, result *coredomain.DiffResult, format, file1, file2 string) error {
+	if err := printDiffResult(w, result, format, file1, file2); err != nil {
+		return err
+	}
+	if result.Summary.Total > 0 {
+		return errDifferences
+	}
+	return nil
+}
 
-	switch aTyped := a.(type) {
-	case map[string]interface{}:
-		bTyped, ok := b.(map[string]interface{})
-		if !ok {
-			return []string{fmt.Sprintf("Type mismatch at %s: map vs %T", path, b)}
-		}
+// diffMarkers are the colored markers of diff entries by action.
+var diffMarkers = map[string]render.Cell{
+	"added":    {Text: "+", Color: render.Green},
+	"removed":  {Text: "-", Color: render.Red},
+	"modified": {Text: "~", Color: render.Yellow},
+}
 
-		// Check keys in a
-		for k, av := range aTyped {
-			newPath := path + "." + k
-			if path == "" {
-				newPath = k
-			}
-			if bv, ok := bTyped[k]; ok {
-				diffs = append(diffs, compareJSON(av, bv, newPath)...)
-			} else {
-				diffs = append(diffs, fmt.Sprintf("Key missing in existing: %s", newPath))
-			}
+// printDiffResult writes a diff result as JSON or text.
+func printDiffResult(w io.Writer, result *coredomain.DiffResult, format, file1, file2 string) error {
+	if format == "json" {
+		output, err := coredomain.FormatDiffResult(result, format)
+		if err != nil {
+			return err
 		}
+		fmt.Fprintln(w, output)
+		return nil
+	}
 
-		// Check keys in b not in a
-		for k := range bTyped {
-			newPath := path + "." + k
-			if path == "" {
-				newPath = k
-			}
-			if _, ok := aTyped[k]; !ok {
-				diffs = append(diffs, fmt.Sprintf("Extra key in existing: %s", newPath))
-			}
-		}
+	if result.Summary.Total == 0 {
+		fmt.Fprintln(w, i18n.Sprintf("No differences between %s and %s", file1, file2))
+		return nil
+	}
 
-	case []interface{}:
-		bTyped, ok := b.([]interface{})
+	fmt.Fprintf(w, "%s\n\n", i18n.Sprintf("Comparing %s vs %s", file1, file2))
+	r := render.New(w)
+	var rows [][]render.Cell
+	for _, entry := range result.Entries {
+		marker, ok := diffMarkers[entry.Action]
 		if !ok {
-			return []string{fmt.Sprintf("Type mismatch at %s: array vs %T", path, b)}
-		}
-
-		if len(aTyped) != len(bTyped) {
-			diffs = append(diffs, fmt.Sprintf("Array length mismatch at %s: %d vs %d", path, len(aTyped), len(bTyped)))
-		}
-
-		minLen := len(aTyped)
-		if len(bTyped) < minLen {
-			minLen = len(bTyped)
+			continue
 		}
-
-		for i := 0; i < minLen; i++ {
-			diffs = append(diffs, compareJSON(aTyped[i], bTyped[i], fmt.Sprintf("%s[%d]", path, i))...)
-		}
-
-	default:
-		if !reflect.DeepEqual(a, b) {
-			diffs = append(diffs, fmt.Sprintf("Value mismatch at %s: %v vs %v", path, a, b))
+		rows = append(rows, []render.Cell{{Text: marker.Text + " " + entry.Resource, Color: marker.Color}, {Text: entry.Type}})
+		for _, change := range entry.Changes {
+			rows = append(rows, []render.Cell{{Text: "    " + change}})
 		}
 	}
-
-	return diffs
+	r.Table("  ", rows)
+	fmt.Fprintf(w, "\n%s\n", i18n.Sprintf("Summary: %d added, %d removed, %d modified",
+		result.Summary.Added, result.Summary.Removed, result.Summary.Modified))
+	return nil
 }
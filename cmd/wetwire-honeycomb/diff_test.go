@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+)
+
+// runDiff runs the diff command with args and returns its output and exit
+// status.
+func runDiff(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	rootCmd := domain.CreateRootCommand(&domain.HoneycombDomain{})
+	extendDiffCmd(rootCmd)
+
+	var out strings.Builder
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(&out)
+	rootCmd.SetArgs(append([]string{"diff"}, args...))
+
+	err := rootCmd.Execute()
+	if err == nil {
+		return out.String(), 0
+	}
+	var exit *exitError
+	if !errors.As(err, &exit) {
+		t.Fatalf("expected an exit status, got %v", err)
+	}
+	return out.String() + err.Error(), exit.code
+}
+
+func TestDiffExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	same := filepath.Join(dir, "same.json")
+	other := filepath.Join(dir, "other.json")
+	if err := os.WriteFile(same, []byte(`{"queries":{"Latency":{"time_range":3600}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(other, []byte(`{"queries":{"Latency":{"time_range":7200}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"identical", []string{same, same}, 0},
+		{"different", []string{same, other}, 1},
+		{"missing file", []string{same, filepath.Join(dir, "missing.json")}, 2},
+		{"wrong arguments", []string{same}, 2},
+		{"unknown flag", []string{"--bogus", same, same}, 2},
+		{"quiet different", []string{"-q", same, other}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, code := runDiff(t, tt.args...); code != tt.want {
+				t.Errorf("exit status = %d, want %d", code, tt.want)
+			}
+		})
+	}
+
+	if out, _ := runDiff(t, "-q", same, other); out != "" {
+		t.Errorf("expected no output with -q, got %q", out)
+	}
+}
+
+func TestDiffOutput(t *testing.T) {
+	dir := t.TempDir()
+	source := "package queries\n\nimport \"github.com/lex00/wetwire-honeycomb-go/query\"\n\n" +
+		"var Latency = query.Query{Dataset: \"api\", TimeRange: query.Hours(%d), Calculations: []query.Calculation{query.Count()}}\n"
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(fmt.Sprintf(source, 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	existing := filepath.Join(t.TempDir(), "deployed.json")
+	output, err := buildOutput(dir, domain.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(output)
+	if err := os.WriteFile(existing, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, mode := range [][]string{nil, {"--semantic"}} {
+		args := append(append([]string{}, mode...), "--output", existing, dir)
+		if out, code := runDiff(t, args...); code != 0 {
+			t.Errorf("%v: expected no differences, got %d: %s", mode, code, out)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(fmt.Sprintf(source, 2)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, code := runDiff(t, "--output", existing, dir)
+	if code != 1 || !strings.Contains(out, `+      "time_range": 7200`) {
+		t.Errorf("text diff: status %d, output %s", code, out)
+	}
+
+	out, code = runDiff(t, "--semantic", "--output", existing, dir)
+	if code != 1 || !strings.Contains(out, "time_range: 3600 -> 7200") {
+		t.Errorf("semantic diff: status %d, output %s", code, out)
+	}
+
+	if _, code := runDiff(t, "--semantic", "--ignore", "time_range", "--output", existing, dir); code != 0 {
+		t.Errorf("expected --ignore to hide the change, got %d", code)
+	}
+	if _, code := runDiff(t, "--ignore", "time_range", "--output", existing, dir); code != 2 {
+		t.Errorf("expected comparison options without --semantic to fail, got %d", code)
+	}
+}
+
+func TestDiffComparisonOptions(t *testing.T) {
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "old.json")
+	file2 := filepath.Join(dir, "new.json")
+	old := `{"queries":{"Latency":{"time_range":3600},"DebugSampling":{"time_range":60}}}`
+	new := `{"queries":{"Latency":{"time_range":7200},"DebugSampling":{"time_range":120}}}`
+	if err := os.WriteFile(file1, []byte(old), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file2, []byte(new), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, error) {
+		rootCmd := domain.CreateRootCommand(&domain.HoneycombDomain{})
+		extendDiffCmd(rootCmd)
+		var out strings.Builder
+		rootCmd.SetOut(&out)
+		rootCmd.SetErr(&out)
+		rootCmd.SetArgs(append(append([]string{"diff"}, args...), file1, file2))
+		err := rootCmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := run("--ignore", "time_range")
+	if err != nil {
+		t.Fatalf("expected no differences with --ignore, got %v: %s", err, out)
+	}
+	if !strings.Contains(out, "No differences") {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	out, err = run("--exclude", "^Debug")
+	if err == nil {
+		t.Fatal("expected differences")
+	}
+	if !strings.Contains(out, "~ Latency") || strings.Contains(out, "DebugSampling") {
+		t.Errorf("expected only Latency to be compared, got: %s", out)
+	}
+
+	if _, err := run("--include", "("); err == nil || !strings.Contains(err.Error(), "--include") {
+		t.Errorf("expected invalid --include error, got %v", err)
+	}
+}
+
+func TestDiffNormalize(t *testing.T) {
+	dir := t.TempDir()
+	source := "package queries\n\nimport \"github.com/lex00/wetwire-honeycomb-go/query\"\n\n" +
+		"var Latency = query.Query{Dataset: \"api\", TimeRange: query.Hours(1), Breakdowns: []string{%s}, Calculations: []query.Calculation{query.Count()}}\n"
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(fmt.Sprintf(source, `"service", "endpoint"`)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	existing := filepath.Join(t.TempDir(), "deployed.json")
+	output, err := buildOutput(dir, domain.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(output)
+	if err := os.WriteFile(existing, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reordering the breakdowns is only a difference without --normalize
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(fmt.Sprintf(source, `"endpoint", "service"`)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, code := runDiff(t, "--semantic", "--output", existing, dir); code != 1 {
+		t.Errorf("expected reordered breakdowns to differ, got %d", code)
+	}
+	for _, mode := range [][]string{nil, {"--semantic"}} {
+		args := append(append([]string{"--normalize"}, mode...), "--output", existing, dir)
+		if out, code := runDiff(t, args...); code != 0 {
+			t.Errorf("%v: expected no differences with --normalize, got %d: %s", mode, code, out)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/serialize"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/spf13/cobra"
+)
+
+// newExprCmd creates the "expr" command.
+func newExprCmd() *cobra.Command {
+	var name, dataset string
+
+	cmd := &cobra.Command{
+		Use:   "expr <expression>",
+		Short: "Convert a query expression to a Go declaration or query JSON",
+		Long: `Convert a compact query expression to the Go declaration of a query.Query,
+or with --format json to the query JSON build would produce:
+
+    P99(duration_ms), COUNT WHERE status_code >= 500 GROUP BY service LAST 2h
+
+An expression is a comma-separated list of calculations, OP or OP(column),
+followed by any of the clauses FROM dataset, WHERE filter [AND|OR filter...],
+GROUP BY columns, ORDER BY calculation|column [ASC|DESC], LIMIT n,
+LAST duration (30m, 2h, 7d), BETWEEN start AND end and GRANULARITY duration.
+Filters are written as "column op [value]". Keywords are case-insensitive.
+query.Parse reads the same expressions in Go.
+
+Examples:
+    wetwire-honeycomb expr "P99(duration_ms), COUNT WHERE status_code >= 500 GROUP BY service LAST 2h"
+    wetwire-honeycomb expr --name SlowCheckout --dataset api "HEATMAP(duration_ms) WHERE service.name = checkout"
+    wetwire-honeycomb expr -f json "COUNT GROUP BY http.route ORDER BY COUNT DESC LIMIT 10"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, _ := cmd.Flags().GetString("format")
+			return runExpr(os.Stdout, args[0], name, dataset, format)
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "Query", "Variable name of the Go declaration")
+	cmd.Flags().StringVar(&dataset, "dataset", "", "Dataset to query when the expression has no FROM clause")
+
+	return cmd
+}
+
+// runExpr parses an expression and writes the query as Go code, or as JSON
+// when format is json.
+func runExpr(w io.Writer, expr, name, dataset, format string) error {
+	q, err := query.Parse(expr)
+	if err != nil {
+		return err
+	}
+	if q.Dataset == "" {
+		q.Dataset = dataset
+	}
+
+	if format == "json" {
+		data, err := serialize.ToJSONPretty(q)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	}
+
+	if !token.IsIdentifier(name) || !token.IsExported(name) {
+		return fmt.Errorf("--name %q is not an exported Go identifier", name)
+	}
+	code, err := domain.QueryDeclaration(name, fmt.Sprintf("%s is %s", name, strings.Join(strings.Fields(expr), " ")), q)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, code)
+	return err
+}
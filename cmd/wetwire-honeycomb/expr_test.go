@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+)
+
+func TestRunExpr(t *testing.T) {
+	expr := "P99(duration_ms), COUNT WHERE status_code >= 500 GROUP BY service LAST 2h"
+
+	var out bytes.Buffer
+	if err := runExpr(&out, expr, "SlowErrors", "api", "text"); err != nil {
+		t.Fatalf("expr failed: %v", err)
+	}
+	for _, want := range []string{
+		"// SlowErrors is " + expr,
+		"var SlowErrors = query.Query{",
+		`Dataset:    "api",`,
+		"TimeRange:  query.Hours(2),",
+		`query.GTE("status_code", 500),`,
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := runExpr(&out, "COUNT GROUP BY http.route LAST 30m", "Query", "api", "json"); err != nil {
+		t.Fatalf("expr -f json failed: %v", err)
+	}
+	for _, want := range []string{`"http.route"`, `"time_range": 1800`, `"op": "COUNT"`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("JSON output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	if err := runExpr(&out, "COUNT", "query", "", "text"); err == nil || !strings.Contains(err.Error(), "not an exported Go identifier") {
+		t.Errorf("expected an invalid name error, got %v", err)
+	}
+	if err := runExpr(&out, "COUNT WHERE", "Query", "", "text"); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+// TestRunExpr_BuildRoundTrip checks that -f json writes the query JSON build
+// produces from the Go declaration expr generates.
+func TestRunExpr_BuildRoundTrip(t *testing.T) {
+	expr := "COUNT, P99(duration_ms) WHERE service = api OR status_code >= 500 GROUP BY route ORDER BY COUNT DESC LIMIT 10 LAST 2h GRANULARITY 1m"
+
+	var code, want bytes.Buffer
+	if err := runExpr(&code, expr, "Errors", "api", "text"); err != nil {
+		t.Fatalf("expr failed: %v", err)
+	}
+	if err := runExpr(&want, expr, "Errors", "api", "json"); err != nil {
+		t.Fatalf("expr -f json failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	source := "package queries\n\nimport \"github.com/lex00/wetwire-honeycomb-go/query\"\n\n" + code.String()
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(t.TempDir(), "out.json")
+	d := &domain.HoneycombDomain{}
+	rootCmd := domain.CreateRootCommand(d)
+	extendBuildCmd(rootCmd, d)
+	rootCmd.SetArgs([]string{"build", "-f", "json", "-o", out, dir})
+	rootCmd.SetOut(io.Discard)
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var output map[string]map[string]any
+	if err := json.Unmarshal(data, &output); err != nil {
+		t.Fatal(err)
+	}
+	var expected any
+	if err := json.Unmarshal(want.Bytes(), &expected); err != nil {
+		t.Fatal(err)
+	}
+	if got := output["queries"]["Errors"]; !reflect.DeepEqual(got, expected) {
+		t.Errorf("build wrote\n%v\nexpr -f json wrote\n%v", got, expected)
+	}
+}
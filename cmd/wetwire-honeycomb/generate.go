@@ -0,0 +1,275 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/spf13/cobra"
+)
+
+// newGenerateCmd creates the "generate" command group.
+func newGenerateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Generate Go declarations for common observability patterns",
+	}
+
+	cmd.AddCommand(newGenerateServiceMapCmd())
+	cmd.AddCommand(newGenerateRatioCmd())
+	cmd.AddCommand(newGenerateSweepCmd())
+
+	return cmd
+}
+
+// newGenerateServiceMapCmd creates the "generate servicemap" subcommand.
+func newGenerateServiceMapCmd() *cobra.Command {
+	var dataset, target string
+
+	cmd := &cobra.Command{
+		Use:   "servicemap",
+		Short: "Generate service dependency queries for an OpenTelemetry dataset",
+		Long: `Generate Go declarations of the standard span-relationship queries for an
+OpenTelemetry trace dataset:
+
+    ServiceMapCalls         calls per edge (service.name to peer.service)
+    ServiceMapErrors        failed calls per edge
+    ServiceMapLatency       P50/P95/P99 and heatmap of duration_ms per edge
+    ServiceMapInbound       server and consumer spans per service
+    ServiceMapUnknownPeers  client spans without peer.service
+
+and a ServiceMapBoard with a panel for each. Without --target the code is
+printed; with --target it is written to that .go file, or to servicemap.go in
+that directory, in the package of the Go files there, or else one named
+after the directory.
+
+Examples:
+    wetwire-honeycomb generate servicemap --dataset otel-demo
+    wetwire-honeycomb generate servicemap --dataset otel-demo --target ./servicemap`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGenerateServiceMap(os.Stdout, dataset, target)
+		},
+	}
+
+	cmd.Flags().StringVar(&dataset, "dataset", "", "Trace dataset to query (required)")
+	cmd.Flags().StringVar(&target, "target", "", "Write to this .go file or directory instead of stdout")
+	_ = cmd.MarkFlagRequired("dataset")
+
+	return cmd
+}
+
+// runGenerateServiceMap prints the service map code or writes it to target.
+func runGenerateServiceMap(w io.Writer, dataset, target string) error {
+	if target == "" {
+		code, err := domain.GenerateServiceMap("servicemap", dataset)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(code)
+		return err
+	}
+
+	file, err := domain.WriteServiceMap(dataset, target)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Generated service map for %s in %s\n", dataset, displayPath(file))
+	return nil
+}
+
+// ratioFlags are the flags of "generate ratio".
+type ratioFlags struct {
+	dataset    string
+	alias      string
+	match      []string
+	where      []string
+	breakdowns []string
+	hours      int
+	target     string
+}
+
+// newGenerateRatioCmd creates the "generate ratio" subcommand.
+func newGenerateRatioCmd() *cobra.Command {
+	var flags ratioFlags
+
+	cmd := &cobra.Command{
+		Use:   "ratio <Name>",
+		Short: "Generate queries for a percentage such as an error rate",
+		Long: `Generate Go declarations expressing the percentage of events that match a
+condition, such as an error rate. Honeycomb has no ratio calculation, so two
+forms are generated:
+
+    <Name>Column    a derived column that is 100 for matching events and 0
+                    otherwise, to create in the dataset's settings
+    <Name>          AVG of the derived column: the percentage, in one query
+    <Name>Matching  COUNT of matching events
+    <Name>Total     COUNT of all events; divide <Name>Matching by it when the
+                    derived column is not available
+
+Filters are written as "column op [value]" and may be repeated: --match
+selects the events counted as matching, --where restricts both sides.
+
+Without --target the code is printed; with --target it is written to that
+.go file, or to <alias>.go in that directory.
+
+Examples:
+    wetwire-honeycomb generate ratio ErrorRate --dataset api --match "http.status_code >= 500"
+    wetwire-honeycomb generate ratio CheckoutErrors --dataset api \
+        --match "error = true" --where "service.name = checkout" --breakdown http.route`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runGenerateRatio(os.Stdout, args[0], flags)
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.dataset, "dataset", "", "Dataset to query (required)")
+	cmd.Flags().StringVar(&flags.alias, "alias", "", "Derived column alias (default: Name in snake_case)")
+	cmd.Flags().StringArrayVar(&flags.match, "match", nil, "Condition of matching events, e.g. \"error = true\" (required; repeatable)")
+	cmd.Flags().StringArrayVar(&flags.where, "where", nil, "Filter applied to all events, e.g. \"service.name = api\" (repeatable)")
+	cmd.Flags().StringSliceVar(&flags.breakdowns, "breakdown", nil, "Fields to group by")
+	cmd.Flags().IntVar(&flags.hours, "hours", 2, "Time range in hours")
+	cmd.Flags().StringVar(&flags.target, "target", "", "Write to this .go file or directory instead of stdout")
+	_ = cmd.MarkFlagRequired("dataset")
+	_ = cmd.MarkFlagRequired("match")
+
+	return cmd
+}
+
+// runGenerateRatio prints the ratio code or writes it to the target.
+func runGenerateRatio(w io.Writer, name string, flags ratioFlags) error {
+	if flags.hours <= 0 {
+		return fmt.Errorf("--hours must be positive")
+	}
+	r := query.Ratio{
+		Alias:      flags.alias,
+		Dataset:    flags.dataset,
+		TimeRange:  query.Hours(flags.hours),
+		Breakdowns: flags.breakdowns,
+	}
+	for _, s := range flags.match {
+		f, err := domain.ParseFilter(s)
+		if err != nil {
+			return fmt.Errorf("--match: %w", err)
+		}
+		r.Match = append(r.Match, f)
+	}
+	for _, s := range flags.where {
+		f, err := domain.ParseFilter(s)
+		if err != nil {
+			return fmt.Errorf("--where: %w", err)
+		}
+		r.Filters = append(r.Filters, f)
+	}
+
+	if flags.target == "" {
+		code, err := domain.GenerateRatio("queries", name, r)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(code)
+		return err
+	}
+
+	file, err := domain.WriteRatio(name, r, flags.target)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Generated %s in %s\n", name, displayPath(file))
+	return nil
+}
+
+// sweepFlags are the flags of "generate sweep".
+type sweepFlags struct {
+	query  string
+	params []string
+	board  bool
+	target string
+}
+
+// newGenerateSweepCmd creates the "generate sweep" subcommand.
+func newGenerateSweepCmd() *cobra.Command {
+	var flags sweepFlags
+
+	cmd := &cobra.Command{
+		Use:   "sweep [packages]",
+		Short: "Generate copies of a query for a range of parameter values",
+		Long: `Generate a copy of a declared query for each value of one or more
+parameters, to compare thresholds and settings without copying the query by
+hand. Parameters are written as "name=value,value,..." and may be repeated;
+with several, a copy is generated for every combination. Names are:
+
+    threshold    the value of the query's only >, >=, < or <= filter
+    limit        the result limit
+    hours        the relative time range, in hours
+    granularity  the time bucket size, in seconds
+    <column>     the value of the query's filters on that column
+
+Copies are named after the query and the values, e.g.
+SlowRequestsThreshold250. With --board, a <Query>SweepBoard with a panel for
+each copy is generated too.
+
+Without --target the code is printed, in the package of the query; with
+--target it is written to that .go file, or to <query>_sweep.go in that
+directory, in the package of the Go files there, or else one named after the
+directory.
+
+Examples:
+    wetwire-honeycomb generate sweep --query SlowRequests --param threshold=100,250,500
+    wetwire-honeycomb generate sweep ./queries --query SlowRequests \
+        --param threshold=100,250,500 --param hours=1,24 --board --target ./queries`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+			return runGenerateSweep(os.Stdout, path, flags)
+		},
+	}
+
+	cmd.Flags().StringVar(&flags.query, "query", "", "Name of the query to sweep (required)")
+	cmd.Flags().StringArrayVar(&flags.params, "param", nil, "Parameter and values, e.g. threshold=100,250,500 (required; repeatable)")
+	cmd.Flags().BoolVar(&flags.board, "board", false, "Also generate a board comparing the copies")
+	cmd.Flags().StringVar(&flags.target, "target", "", "Write to this .go file or directory instead of stdout")
+	_ = cmd.MarkFlagRequired("query")
+	_ = cmd.MarkFlagRequired("param")
+
+	return cmd
+}
+
+// runGenerateSweep prints the sweep of a query declared under path or writes
+// it to the target.
+func runGenerateSweep(w io.Writer, path string, flags sweepFlags) error {
+	var params []domain.SweepParam
+	for _, s := range flags.params {
+		p, err := domain.ParseSweepParam(s)
+		if err != nil {
+			return fmt.Errorf("--param: %w", err)
+		}
+		params = append(params, p)
+	}
+
+	q, pkg, err := domain.DiscoveredQuery(path, flags.query)
+	if err != nil {
+		return err
+	}
+
+	if flags.target == "" {
+		code, err := domain.GenerateSweep(pkg, flags.query, q, params, flags.board)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(code)
+		return err
+	}
+
+	file, err := domain.WriteSweep(flags.query, q, params, flags.board, flags.target)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Generated %s sweep in %s\n", flags.query, displayPath(file))
+	return nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunGenerateServiceMap(t *testing.T) {
+	var out bytes.Buffer
+	if err := runGenerateServiceMap(&out, "otel-demo", ""); err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "package servicemap") || !strings.Contains(out.String(), `Dataset:    "otel-demo",`) {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	out.Reset()
+	target := filepath.Join(t.TempDir(), "deps.go")
+	if err := runGenerateServiceMap(&out, "otel-demo", target); err != nil {
+		t.Fatalf("generate to target failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Generated service map for otel-demo in") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+	if _, err := os.Stat(target); err != nil {
+		t.Errorf("expected %s to be written: %v", target, err)
+	}
+
+	if err := runGenerateServiceMap(&out, "", ""); err == nil {
+		t.Error("expected an error without a dataset")
+	}
+}
+
+func TestRunGenerateRatio(t *testing.T) {
+	flags := ratioFlags{
+		dataset: "api",
+		match:   []string{"http.status_code >= 500"},
+		where:   []string{"service.name = checkout"},
+		hours:   2,
+	}
+
+	var out bytes.Buffer
+	if err := runGenerateRatio(&out, "ErrorRate", flags); err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	for _, want := range []string{"var ErrorRateColumn = query.DerivedColumn{", `query.Equals("service.name", "checkout"),`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	flags.target = t.TempDir()
+	if err := runGenerateRatio(&out, "ErrorRate", flags); err != nil {
+		t.Fatalf("generate to target failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(flags.target, "error_rate.go")); err != nil {
+		t.Errorf("expected error_rate.go to be written: %v", err)
+	}
+
+	flags.target = ""
+	flags.match = []string{"status_code"}
+	if err := runGenerateRatio(&out, "ErrorRate", flags); err == nil || !strings.Contains(err.Error(), "--match") {
+		t.Errorf("expected a --match error, got %v", err)
+	}
+}
+
+const sweepSource = `package latency
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var SlowRequests = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Calculations: []query.Calculation{query.Count()},
+	Filters:      []query.Filter{query.GT("duration_ms", 100)},
+}
+`
+
+func TestRunGenerateSweep(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(sweepSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	flags := sweepFlags{query: "SlowRequests", params: []string{"threshold=250,500"}, board: true}
+
+	var out bytes.Buffer
+	if err := runGenerateSweep(&out, dir, flags); err != nil {
+		t.Fatalf("generate failed: %v", err)
+	}
+	for _, want := range []string{"package latency", "var SlowRequestsThreshold250 = query.Query{", `query.GT("duration_ms", 500)`, "var SlowRequestsSweepBoard = board.Board{"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	flags.target = dir
+	if err := runGenerateSweep(&out, dir, flags); err != nil {
+		t.Fatalf("generate to target failed: %v", err)
+	}
+	written, err := os.ReadFile(filepath.Join(dir, "slow_requests_sweep.go"))
+	if err != nil {
+		t.Fatalf("expected slow_requests_sweep.go to be written: %v", err)
+	}
+	if !strings.HasPrefix(string(written), "package latency\n") {
+		t.Errorf("expected the package of queries.go, got:\n%s", written)
+	}
+
+	flags.target = ""
+	flags.params = []string{"threshold"}
+	if err := runGenerateSweep(&out, dir, flags); err == nil || !strings.Contains(err.Error(), "--param") {
+		t.Errorf("expected a --param error, got %v", err)
+	}
+
+	flags.params = []string{"limit=10"}
+	flags.query = "Missing"
+	if err := runGenerateSweep(&out, dir, flags); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
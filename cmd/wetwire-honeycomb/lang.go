@@ -0,0 +1,30 @@
+// Command lang adds the --lang flag selecting the output language.
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/i18n"
+	"github.com/spf13/cobra"
+)
+
+// addLangFlag adds the persistent --lang flag to the root command. The
+// language is set with i18n.SetLanguage; without the flag it is taken from
+// the locale (LC_ALL, LC_MESSAGES or LANG).
+func addLangFlag(rootCmd *cobra.Command) {
+	var lang string
+
+	rootCmd.PersistentFlags().StringVar(&lang, "lang", "", "Output language: "+strings.Join(i18n.Languages(), ", ")+" (default from LANG)")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if lang == "" {
+			return nil
+		}
+		parsed, ok := i18n.Parse(lang)
+		if !ok {
+			return fmt.Errorf("unsupported language %q (supported: %s)", lang, strings.Join(i18n.Languages(), ", "))
+		}
+		i18n.SetLanguage(parsed)
+		return nil
+	}
+}
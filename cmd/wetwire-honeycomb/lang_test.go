@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/i18n"
+	"github.com/lex00/wetwire-honeycomb-go/internal/render"
+	"github.com/spf13/cobra"
+)
+
+func TestAddLangFlag(t *testing.T) {
+	t.Cleanup(func() { i18n.SetLanguage(i18n.English) })
+	root := &cobra.Command{Use: "root", RunE: func(*cobra.Command, []string) error { return nil }}
+	addLangFlag(root)
+
+	root.SetArgs([]string{"--lang", "ja_JP.UTF-8"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got := i18n.Language(); got != "ja" {
+		t.Errorf("expected language ja, got %q", got)
+	}
+
+	root.SetArgs([]string{"--lang", "fr"})
+	root.SilenceErrors, root.SilenceUsage = true, true
+	err := root.Execute()
+	if err == nil || !strings.Contains(err.Error(), "supported: en, ja") {
+		t.Errorf("expected an unsupported language error, got %v", err)
+	}
+}
+
+func TestWriteText_Japanese(t *testing.T) {
+	result := coredomain.NewErrorResultMultiple("lint issues found", []coredomain.Error{
+		{Path: "queries.go", Line: 5, Severity: "warning", Message: "Query has no calculations", Code: "WHC001"},
+	})
+
+	i18n.SetLanguage("ja")
+	t.Cleanup(func() { i18n.SetLanguage(i18n.English) })
+	var out bytes.Buffer
+	writeText(render.New(&out), result)
+	text := out.String()
+	for _, want := range []string{"✗ 失敗: lint issues found", "エラー:", "WHC001"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Command lock adds lockfile-based drift detection to the diff command.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/i18n"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lock"
+)
+
+// errDrift is returned by runLockDiff when code, lockfile and live state
+// disagree.
+var errDrift = errors.New("drift detected")
+
+// runLockDiff reconciles the resources in path, built with opts, with its
+// lockfile and, when lister is non-nil, with the live environment, writing the
+// report to w.
+func runLockDiff(ctx context.Context, w io.Writer, lister lock.ResourceLister, path, format string, opts domain.Options) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolve path: %w", err)
+	}
+
+	lockPath := filepath.Join(absPath, lock.FileName)
+	report, err := reconcileLock(ctx, lister, absPath, opts)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("no %s in %s (run \"build --write-lock\" to create one)", lock.FileName, path)
+	}
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(data))
+	} else {
+		printLockReport(w, report, lockPath)
+	}
+
+	if report.HasDrift() {
+		return errDrift
+	}
+	return nil
+}
+
+// reconcileLock reconciles the build of absPath with opts with its lockfile
+// and, when lister is non-nil, with the live environment. The error wraps
+// os.ErrNotExist when there is no lockfile.
+func reconcileLock(ctx context.Context, lister lock.ResourceLister, absPath string, opts domain.Options) (*lock.Report, error) {
+	locked, err := lock.Read(filepath.Join(absPath, lock.FileName))
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := buildOutput(absPath, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var live map[string]json.RawMessage
+	if lister != nil {
+		live, err = lock.FetchLive(ctx, lister, output, locked)
+		if err != nil {
+			return nil, fmt.Errorf("fetch live resources: %w", err)
+		}
+	}
+
+	return lock.Reconcile(output, locked, live)
+}
+
+// buildOutput runs a dry-run build with opts and returns its output.
+func buildOutput(path string, opts domain.Options) (*honeycomb.Manifest, error) {
+	ctx := coredomain.NewContext(context.Background(), path)
+	result, err := (&domain.HoneycombDomain{Options: opts}).Builder().Build(ctx, path, domain.BuildOpts{DryRun: true})
+	if err != nil {
+		return nil, fmt.Errorf("build failed: %w", err)
+	}
+	if !result.Success {
+		return nil, fmt.Errorf("build failed: %s", result.Message)
+	}
+
+	data, ok := result.Data.(string)
+	if !ok {
+		return nil, fmt.Errorf("build returned no output")
+	}
+
+	output, err := honeycomb.Unmarshal([]byte(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse build output: %w", err)
+	}
+	return output, nil
+}
+
+// printLockReport writes a human-readable reconciliation report to w.
+func printLockReport(w io.Writer, report *lock.Report, lockPath string) {
+	mode := i18n.T("code vs lockfile")
+	if report.LiveChecked {
+		mode = i18n.T("code vs lockfile vs live")
+	}
+	fmt.Fprintf(w, "%s\n\n", i18n.Sprintf("Reconciling %s (%s)", lockPath, mode))
+
+	for _, d := range report.Resources {
+		fmt.Fprintf(w, "  %-18s %-9s %s\n", d.Status, d.Type, d.Name)
+	}
+
+	counts := report.Counts()
+	fmt.Fprintf(w, "\n%s", i18n.Sprintf("%d resource(s): %d in sync", len(report.Resources), counts[lock.StatusInSync]))
+	for _, status := range []lock.Status{
+		lock.StatusAdded, lock.StatusModified, lock.StatusRemoved,
+		lock.StatusDrifted, lock.StatusConflict, lock.StatusLockStale, lock.StatusMissingLive,
+	} {
+		if counts[status] > 0 {
+			fmt.Fprintf(w, ", %d %s", counts[status], status)
+		}
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+)
+
+func TestRunLockDiff(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "triggers.go")
+	if err := os.WriteFile(file, []byte(simulateSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runLockDiff(context.Background(), io.Discard, nil, dir, "text", domain.Options{}); err == nil || !strings.Contains(err.Error(), "wetwire.lock") {
+		t.Errorf("expected missing lockfile error, got %v", err)
+	}
+
+	ctx := coredomain.NewContext(context.Background(), dir)
+	if _, err := (&domain.HoneycombDomain{Options: domain.Options{WriteLock: true}}).Builder().Build(ctx, dir, domain.BuildOpts{Output: filepath.Join(t.TempDir(), "out.json")}); err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+
+	if err := runLockDiff(context.Background(), io.Discard, nil, dir, "json", domain.Options{}); err != nil {
+		t.Errorf("expected no drift after build, got %v", err)
+	}
+
+	modified := strings.Replace(simulateSource, "trigger.Minutes(5)", "trigger.Minutes(10)", 1)
+	if err := os.WriteFile(file, []byte(modified), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runLockDiff(context.Background(), io.Discard, nil, dir, "text", domain.Options{}); err == nil {
+		t.Error("expected drift after modifying a trigger")
+	}
+}
+
+func TestExtendDiffCmd(t *testing.T) {
+	rootCmd := domain.CreateRootCommand(&domain.HoneycombDomain{})
+	extendDiffCmd(rootCmd)
+
+	cmd, _, err := rootCmd.Find([]string{"diff"})
+	if err != nil {
+		t.Fatalf("diff command not found: %v", err)
+	}
+	if cmd.Flags().Lookup("lock") == nil {
+		t.Error("expected --lock flag on diff")
+	}
+
+	if err := cmd.ParseFlags([]string{"--lock"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Args(cmd, []string{"./queries"}); err != nil {
+		t.Errorf("--lock should accept a single path: %v", err)
+	}
+}
@@ -3,26 +3,43 @@
 // Usage:
 //
 //	wetwire-honeycomb build ./queries/...   Generate Query JSON
+//	wetwire-honeycomb build -f grafana ./boards Export boards as Grafana dashboards
 //	wetwire-honeycomb lint ./queries/...    Check for issues
 //	wetwire-honeycomb validate ./queries/...Validate queries
 //	wetwire-honeycomb list ./queries/...    List discovered queries
 //	wetwire-honeycomb graph ./queries/...   Generate dependency graph
 //	wetwire-honeycomb init myqueries        Create new queries directory
 //	wetwire-honeycomb import query.json     Import Query JSON to Go
+//	wetwire-honeycomb build | wetwire-honeycomb validate - Validate piped JSON
 //	wetwire-honeycomb design "prompt"       AI-assisted query design
 //	wetwire-honeycomb test "prompt"         Run persona-based testing
 //	wetwire-honeycomb diff old.json new.json Compare two query files
+//	wetwire-honeycomb diff --lock ./queries Detect drift against wetwire.lock
+//	wetwire-honeycomb ci --github ./queries Lint and check drift with GitHub annotations
+//	wetwire-honeycomb apply ./queries       Create or update resources in Honeycomb
 //	wetwire-honeycomb watch ./queries/...   Auto-rebuild on file changes
+//	wetwire-honeycomb slo backtest MySLO    Replay an SLO over historical data
+//	wetwire-honeycomb slo report -f markdown Summarize SLOs for ops reviews
+//	wetwire-honeycomb trigger simulate MyTrigger Replay a trigger over historical data
+//	wetwire-honeycomb tail SlowRequests     Stream recent events matching a query's filters
+//	wetwire-honeycomb search duration_ms    Find resources by name, column or value
+//	wetwire-honeycomb advise ./queries/...  Suggest cheaper time ranges and granularity
+//	wetwire-honeycomb analyze clusters ./queries/... Group similar queries
+//	wetwire-honeycomb report --html site ./... Generate an HTML catalog of resources
+//	wetwire-honeycomb rename query Old New  Rename a resource and its references
+//	wetwire-honeycomb mv query Name ./pkg   Move a resource to another package
+//	wetwire-honeycomb generate servicemap --dataset otel-demo Generate service map queries
+//	wetwire-honeycomb generate ratio ErrorRate --dataset api --match "error = true" Generate percentage queries
 //	wetwire-honeycomb version               Show version
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/lex00/wetwire-honeycomb-go/domain"
-	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
-	"github.com/lex00/wetwire-honeycomb-go/query"
 	"github.com/spf13/cobra"
 )
 
@@ -34,58 +51,93 @@ func main() {
 	domain.Version = version
 
 	// Use domain interface for auto-generated commands
-	rootCmd := domain.CreateRootCommand(&domain.HoneycombDomain{})
+	d := &domain.HoneycombDomain{}
+	rootCmd := domain.CreateRootCommand(d)
 
 	// Add domain-specific commands
-	addDomainSpecificCommands(rootCmd)
+	addDomainSpecificCommands(rootCmd, d)
 
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	usage := startUsage()
+	cmd, err := rootCmd.ExecuteC()
+	usage.finish(cmd, err)
+	if err != nil {
+		code := 1
+		var exit *exitError
+		if errors.As(err, &exit) {
+			code = exit.code
+		}
+		if msg := err.Error(); msg != "" {
+			fmt.Fprintln(os.Stderr, msg)
+		}
+		os.Exit(code)
 	}
 }
 
-// addDomainSpecificCommands adds Honeycomb-specific commands to the root command.
-func addDomainSpecificCommands(rootCmd *cobra.Command) {
+// exitError is an error that sets the exit status of the process. A nil err
+// exits without a message.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return ""
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// addDomainSpecificCommands adds Honeycomb-specific commands to the root
+// command, and flags setting the Options of d, the domain the generated
+// commands run.
+func addDomainSpecificCommands(rootCmd *cobra.Command, d *domain.HoneycombDomain) {
 	// Add custom commands not covered by domain interface
 	rootCmd.AddCommand(
-		newDiffCmd(),
 		newWatchCmd(),
 		newDesignCmd(),
 		newTestCmd(),
 		newMCPCmd(),
+		newSLOCmd(),
+		newTriggerCmd(),
+		newTailCmd(),
+		newApplyCmd(),
+		newCICmd(),
+		newSearchCmd(),
+		newRenameCmd(),
+		newMvCmd(),
+		newGenerateCmd(),
+		newAdviseCmd(),
+		newAnalyzeCmd(),
+		newReportCmd(),
+		newExprCmd(),
 	)
+
+	addLangFlag(rootCmd)
+	addNoColorFlag(rootCmd)
+	addOutputFlags(rootCmd, d)
+	addProgressFlag(rootCmd)
+	extendDiffCmd(rootCmd)
+	extendBuildCmd(rootCmd, d)
+	extendListCmd(rootCmd, d)
+	extendGraphCmd(rootCmd, d)
+	extendLintCmd(rootCmd, d)
+	extendImportCmd(rootCmd)
 }
 
 // Helper functions
 
-// discoveredToQuery converts a DiscoveredQuery to a query.Query
-func discoveredToQuery(dq discovery.DiscoveredQuery) query.Query {
-	q := query.Query{
-		Dataset: dq.Dataset,
-		TimeRange: query.TimeRange{
-			TimeRange: dq.TimeRange.TimeRange,
-			StartTime: dq.TimeRange.StartTime,
-			EndTime:   dq.TimeRange.EndTime,
-		},
-		Breakdowns: dq.Breakdowns,
-		Limit:      dq.Limit,
-	}
-
-	for _, c := range dq.Calculations {
-		q.Calculations = append(q.Calculations, query.Calculation{
-			Op:     c.Op,
-			Column: c.Column,
-		})
+// displayPath returns file relative to the working directory when possible.
+func displayPath(file string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return file
 	}
-
-	for _, f := range dq.Filters {
-		q.Filters = append(q.Filters, query.Filter{
-			Column: f.Column,
-			Op:     f.Op,
-			Value:  f.Value,
-		})
+	if rel, err := filepath.Rel(cwd, file); err == nil {
+		return rel
 	}
-
-	return q
+	return file
 }
@@ -7,8 +7,16 @@ import (
 	"testing"
 
 	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/i18n"
 )
 
+// TestMain runs the tests in English whatever the locale, since they check
+// command output.
+func TestMain(m *testing.M) {
+	i18n.SetLanguage(i18n.English)
+	os.Exit(m.Run())
+}
+
 func TestE2E_InitThenList(t *testing.T) {
 	// End-to-end test: init project → list finds resources
 	tmpDir := t.TempDir()
@@ -3,19 +3,36 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/lex00/wetwire-core-go/agent/agents"
+	"github.com/lex00/wetwire-core-go/agent/results"
 	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/agent"
+	"github.com/lex00/wetwire-honeycomb-go/internal/i18n"
 	"github.com/spf13/cobra"
 
 	coredomain "github.com/lex00/wetwire-core-go/domain"
+	coremcp "github.com/lex00/wetwire-core-go/mcp"
 )
 
 // newMCPCmd creates the "mcp" subcommand that runs the MCP server.
 func newMCPCmd() *cobra.Command {
-	return &cobra.Command{
+	var readOnly bool
+	var root string
+	var listen string
+	var token string
+	var noSandbox bool
+
+	cmd := &cobra.Command{
 		Use:   "mcp",
-		Short: "Run MCP server on stdio",
-		Long: `Run the Model Context Protocol (MCP) server on stdio transport.
+		Short: "Run MCP server on stdio or HTTP",
+		Long: `Run the Model Context Protocol (MCP) server on stdio transport, or
+over HTTP with --listen.
 
 This command starts an MCP server that exposes wetwire-honeycomb tools
 for AI assistants to use. The server provides the following tools:
@@ -24,16 +41,220 @@ for AI assistants to use. The server provides the following tools:
   - wetwire_build: Generate Query JSON from Go packages
   - wetwire_list: List discovered queries
   - wetwire_graph: Generate dependency graph (DOT/Mermaid)
+  - wetwire_design: Generate resources from a prompt with the design agent
+
+With --read-only, the tools that write files (wetwire_init,
+wetwire_design) are not exposed, builds are dry runs and lint fixes are
+refused. With --read-only or --root, tool paths are resolved relative to
+the workspace root and paths outside it, including through symbolic
+links, are rejected, so the server can be exposed to semi-trusted
+assistants.
+
+With --listen ADDR, the server answers JSON-RPC requests POSTed to /mcp,
+and serves HTTP+SSE clients at /sse, for remote agent platforms and IDE
+extensions that cannot spawn a subprocess. Requests must carry the token
+of --token (or WETWIRE_HONEYCOMB_MCP_TOKEN) as "Authorization: Bearer
+TOKEN"; without one, a token is generated and printed on stderr. An
+address without a host, such as :8787, binds to 127.0.0.1; give one, e.g.
+0.0.0.0:8787, to accept remote connections. Over HTTP the tools are
+sandboxed to --root, or the current directory, unless --no-sandbox is set.
 
 This is typically used by AI tools and should not be called directly.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runMCPServer()
+			if token == "" {
+				token = os.Getenv(envMCPToken)
+			}
+			root, err := mcpRoot(root, readOnly, listen, noSandbox)
+			if err != nil {
+				return err
+			}
+			return runMCPServer(root, readOnly, listen, token)
 		},
 	}
+
+	cmd.Flags().BoolVar(&readOnly, "read-only", false, "Do not expose tools that write files; confine paths to the workspace root")
+	cmd.Flags().StringVar(&root, "root", "", "Workspace root tool paths are confined to (default with --read-only: current directory)")
+	cmd.Flags().StringVar(&listen, "listen", "", "Serve over HTTP and SSE on this address, e.g. :8787, instead of stdio")
+	cmd.Flags().StringVar(&token, "token", "", "Bearer token HTTP clients must send (default: WETWIRE_HONEYCOMB_MCP_TOKEN, or generated)")
+	cmd.Flags().BoolVar(&noSandbox, "no-sandbox", false, "With --listen, do not confine tool paths to the current directory")
+
+	return cmd
+}
+
+// mcpRoot returns the workspace root the tools are sandboxed to: root, or
+// the current directory when the server listens on HTTP, unless noSandbox
+// opts out of it. noSandbox cannot be combined with a root or read-only
+// mode, which sandbox the tools.
+func mcpRoot(root string, readOnly bool, listen string, noSandbox bool) (string, error) {
+	if noSandbox {
+		if listen == "" {
+			return "", fmt.Errorf("--no-sandbox only applies with --listen")
+		}
+		if root != "" || readOnly {
+			return "", fmt.Errorf("--no-sandbox cannot be combined with --root or --read-only")
+		}
+		return "", nil
+	}
+	if root == "" && listen != "" {
+		return ".", nil
+	}
+	return root, nil
 }
 
-// runMCPServer starts the MCP server on stdio transport using domain.BuildMCPServer().
-func runMCPServer() error {
-	server := coredomain.BuildMCPServer(&domain.HoneycombDomain{})
+// runMCPServer starts the MCP server on stdio transport using domain.BuildMCPServer(),
+// or on HTTP when listen is set. With a root or in read-only mode, the tools
+// are sandboxed (see mcpSandbox).
+func runMCPServer(root string, readOnly bool, listen, token string) error {
+	// Tool results are read by agents, so they stay in English
+	i18n.SetLanguage(i18n.English)
+	server, err := newMCPServer(root, readOnly)
+	if err != nil {
+		return err
+	}
+	if listen != "" {
+		return serveMCPHTTP(server, (&domain.HoneycombDomain{}).Version(), listen, token)
+	}
 	return server.Start(context.Background())
 }
+
+// newMCPServer returns the MCP server with the domain tools and
+// wetwire_design, sandboxed to root when it is set or readOnly is.
+func newMCPServer(root string, readOnly bool) (*coremcp.Server, error) {
+	d := &domain.HoneycombDomain{}
+	server := coredomain.BuildMCPServer(d)
+	server.RegisterToolWithSchema("wetwire_design", designToolDescription, handleDesignTool, designToolSchema)
+	if root == "" && !readOnly {
+		return server, nil
+	}
+	sandbox, err := newMCPSandbox(root, readOnly)
+	if err != nil {
+		return nil, err
+	}
+	return sandbox.wrap(server, server.Name(), d.Version()), nil
+}
+
+const designToolDescription = `Design Honeycomb resources from a prompt: the design agent writes Go ` +
+	`queries, boards, SLOs and triggers to output_dir, lints them and fixes the issues ` +
+	`until lint passes or max_lint_cycles is reached. Returns the generated files and ` +
+	`the lint status. The agent does not ask questions; put requirements in the prompt ` +
+	`or in context.`
+
+// designToolSchema is the input schema of wetwire_design.
+var designToolSchema = map[string]any{
+	"type": "object",
+	"properties": map[string]any{
+		"prompt": map[string]any{
+			"type":        "string",
+			"description": "What to design, e.g. \"P99 latency by endpoint with an alert above 500ms\"",
+		},
+		"output_dir": map[string]any{
+			"type":        "string",
+			"description": "Directory to write the generated files to (default: current directory)",
+		},
+		"context": map[string]any{
+			"type":        "string",
+			"description": "Answers to the questions the agent would ask, such as dataset and column names",
+		},
+		"max_lint_cycles": map[string]any{
+			"type":        "integer",
+			"description": "Maximum lint/fix cycles (default: 5)",
+		},
+	},
+	"required": []string{"prompt"},
+}
+
+// DesignToolResult is the result of wetwire_design.
+type DesignToolResult struct {
+	Success    bool               `json:"success"`
+	OutputDir  string             `json:"output_dir"`
+	Files      []string           `json:"files"`
+	LintPassed bool               `json:"lint_passed"`
+	LintCycles int                `json:"lint_cycles"`
+	Questions  []results.Question `json:"questions,omitempty"`
+	Error      string             `json:"error,omitempty"`
+}
+
+// designRunner is the part of agents.RunnerAgent wetwire_design uses.
+type designRunner interface {
+	Run(ctx context.Context, prompt string) error
+	GetGeneratedFiles() []string
+	GetLintCycles() int
+	LintPassed() bool
+}
+
+// newDesignRunner creates the design agent of wetwire_design; tests replace
+// it to run without a provider.
+var newDesignRunner = func(config agents.RunnerConfig) (designRunner, error) {
+	return agents.NewRunnerAgent(config)
+}
+
+// handleDesignTool runs the design agent workflow of the design command
+// without a human: questions are answered with the context argument. The
+// result is a DesignToolResult; a run that fails after writing files still
+// returns them, with success false and the error.
+func handleDesignTool(ctx context.Context, args map[string]any) (string, error) {
+	prompt, _ := args["prompt"].(string)
+	if strings.TrimSpace(prompt) == "" {
+		return "", fmt.Errorf("prompt is required")
+	}
+	outputDir, _ := args["output_dir"].(string)
+	if outputDir == "" {
+		outputDir = "."
+	}
+	outputDir, err := filepath.Abs(outputDir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("creating output directory: %w", err)
+	}
+	maxLintCycles := 5
+	if n, ok := args["max_lint_cycles"].(float64); ok && n > 0 {
+		maxLintCycles = int(n)
+	}
+	answers, _ := args["context"].(string)
+
+	session := results.NewSession("mcp", "design")
+	runner, err := newDesignRunner(agents.RunnerConfig{
+		WorkDir:       outputDir,
+		MaxLintCycles: maxLintCycles,
+		Session:       session,
+		Developer:     contextDeveloper{context: answers},
+		Domain:        agent.HoneycombDomain(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("creating design agent: %w", err)
+	}
+
+	result := DesignToolResult{OutputDir: outputDir}
+	if err := runner.Run(ctx, prompt); err != nil {
+		result.Error = err.Error()
+	}
+	session.Complete()
+	result.Files = append([]string{}, runner.GetGeneratedFiles()...)
+	result.LintPassed = runner.LintPassed()
+	result.LintCycles = runner.GetLintCycles()
+	result.Questions = session.Questions
+	result.Success = result.Error == "" && result.LintPassed
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// contextDeveloper answers the design agent's questions for an orchestrating
+// agent, which cannot be asked mid-call: with the context it passed, and
+// otherwise by telling the agent to proceed on reasonable assumptions.
+type contextDeveloper struct {
+	context string
+}
+
+// Respond implements orchestrator.Developer.
+func (d contextDeveloper) Respond(ctx context.Context, message string) (string, error) {
+	if d.context != "" {
+		return d.context + "\n\nFor anything not covered above, make reasonable assumptions and proceed.", nil
+	}
+	return "No further details are available. Make reasonable assumptions and proceed.", nil
+}
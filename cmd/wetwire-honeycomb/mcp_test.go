@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/lex00/wetwire-core-go/agent/agents"
+)
+
+type stubDesignRunner struct {
+	config agents.RunnerConfig
+	err    error
+}
+
+func (r *stubDesignRunner) Run(ctx context.Context, prompt string) error {
+	if _, err := r.config.Developer.Respond(ctx, "Which dataset?"); err != nil {
+		return err
+	}
+	r.config.Session.AddQuestion("Which dataset?", "production")
+	return r.err
+}
+
+func (r *stubDesignRunner) GetGeneratedFiles() []string { return []string{"queries.go"} }
+func (r *stubDesignRunner) GetLintCycles() int          { return 2 }
+func (r *stubDesignRunner) LintPassed() bool            { return r.err == nil }
+
+func stubDesign(t *testing.T, runErr error) *stubDesignRunner {
+	t.Helper()
+	stub := &stubDesignRunner{err: runErr}
+	orig := newDesignRunner
+	newDesignRunner = func(config agents.RunnerConfig) (designRunner, error) {
+		stub.config = config
+		return stub, nil
+	}
+	t.Cleanup(func() { newDesignRunner = orig })
+	return stub
+}
+
+func TestHandleDesignTool(t *testing.T) {
+	stub := stubDesign(t, nil)
+	dir := filepath.Join(t.TempDir(), "out")
+
+	out, err := handleDesignTool(context.Background(), map[string]any{
+		"prompt":          "P99 latency by endpoint",
+		"output_dir":      dir,
+		"context":         "Dataset is production",
+		"max_lint_cycles": float64(3),
+	})
+	if err != nil {
+		t.Fatalf("handleDesignTool: %v", err)
+	}
+
+	var result DesignToolResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if !result.Success || !result.LintPassed || result.LintCycles != 2 {
+		t.Errorf("expected a passing run, got %+v", result)
+	}
+	if result.OutputDir != dir || len(result.Files) != 1 || result.Files[0] != "queries.go" {
+		t.Errorf("unexpected output: %+v", result)
+	}
+	if len(result.Questions) != 1 {
+		t.Errorf("expected 1 question, got %+v", result.Questions)
+	}
+	if stub.config.WorkDir != dir || stub.config.MaxLintCycles != 3 {
+		t.Errorf("unexpected runner config: %+v", stub.config)
+	}
+}
+
+func TestHandleDesignTool_RunError(t *testing.T) {
+	stubDesign(t, errors.New("API call failed"))
+
+	out, err := handleDesignTool(context.Background(), map[string]any{
+		"prompt":     "error rate",
+		"output_dir": t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("handleDesignTool: %v", err)
+	}
+	var result DesignToolResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if result.Success || result.Error != "API call failed" || len(result.Files) != 1 {
+		t.Errorf("expected a failed run with its files, got %+v", result)
+	}
+}
+
+func TestHandleDesignTool_MissingPrompt(t *testing.T) {
+	stubDesign(t, nil)
+	if _, err := handleDesignTool(context.Background(), map[string]any{"prompt": " "}); err == nil {
+		t.Error("expected an error without a prompt")
+	}
+}
+
+func TestContextDeveloper(t *testing.T) {
+	answer, _ := contextDeveloper{context: "Use dataset api"}.Respond(context.Background(), "Which dataset?")
+	if answer[:15] != "Use dataset api" {
+		t.Errorf("expected the context in the answer, got %q", answer)
+	}
+	answer, _ = contextDeveloper{}.Respond(context.Background(), "Which dataset?")
+	if answer == "" {
+		t.Error("expected an answer without context")
+	}
+}
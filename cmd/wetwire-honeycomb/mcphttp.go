@@ -0,0 +1,275 @@
+// HTTP and SSE transports for the MCP server.
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	coremcp "github.com/lex00/wetwire-core-go/mcp"
+)
+
+// envMCPToken is the bearer token of mcp --listen when --token is not set.
+const envMCPToken = "WETWIRE_HONEYCOMB_MCP_TOKEN"
+
+// maxMCPRequestBytes caps the size of a JSON-RPC request body.
+const maxMCPRequestBytes = 10 << 20
+
+// mcpHTTPHandler serves an MCP server over HTTP. JSON-RPC messages POSTed to
+// /mcp are answered in the response body (the streamable HTTP transport);
+// clients of the older HTTP+SSE transport open an event stream at /sse,
+// POST to the /messages endpoint it announces and get the answers as
+// events. Every request needs the bearer token.
+type mcpHTTPHandler struct {
+	server  *coremcp.Server
+	version string
+	token   string
+
+	mu       sync.Mutex
+	sessions map[string]chan []byte
+}
+
+// newMCPHTTPHandler returns the HTTP handler of server, which requires
+// token.
+func newMCPHTTPHandler(server *coremcp.Server, version, token string) *mcpHTTPHandler {
+	return &mcpHTTPHandler{server: server, version: version, token: token, sessions: make(map[string]chan []byte)}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *mcpHTTPHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="wetwire-honeycomb"`)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	switch {
+	case r.URL.Path == "/mcp" && r.Method == http.MethodPost:
+		h.serveRequest(w, r)
+	case r.URL.Path == "/sse" && r.Method == http.MethodGet:
+		h.serveEvents(w, r)
+	case r.URL.Path == "/messages" && r.Method == http.MethodPost:
+		h.serveSessionMessage(w, r)
+	case r.URL.Path == "/mcp" || r.URL.Path == "/sse" || r.URL.Path == "/messages":
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// authorized reports whether r carries the bearer token.
+func (h *mcpHTTPHandler) authorized(r *http.Request) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(h.token)) == 1
+}
+
+// serveRequest answers a JSON-RPC message POSTed to /mcp in the response
+// body, or with 202 Accepted for a notification.
+func (h *mcpHTTPHandler) serveRequest(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxMCPRequestBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp := h.dispatch(r.Context(), data)
+	if resp == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// serveEvents streams the answers to the messages of a new session. The
+// first event is the endpoint to POST the session's messages to.
+func (h *mcpHTTPHandler) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	id, err := randomToken()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	events := make(chan []byte, 16)
+	h.mu.Lock()
+	h.sessions[id] = events
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.sessions, id)
+		h.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	fmt.Fprintf(w, "event: endpoint\ndata: /messages?sessionId=%s\n\n", id)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(30 * time.Second)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case data := <-events:
+			fmt.Fprintf(w, "event: message\ndata: %s\n\n", data)
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		flusher.Flush()
+	}
+}
+
+// serveSessionMessage handles a JSON-RPC message of an SSE session, sending
+// the answer on the session's event stream.
+func (h *mcpHTTPHandler) serveSessionMessage(w http.ResponseWriter, r *http.Request) {
+	h.mu.Lock()
+	events, ok := h.sessions[r.URL.Query().Get("sessionId")]
+	h.mu.Unlock()
+	if !ok {
+		http.Error(w, "unknown session", http.StatusNotFound)
+		return
+	}
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxMCPRequestBytes))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if resp := h.dispatch(r.Context(), data); resp != nil {
+		out, err := json.Marshal(resp)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		select {
+		case events <- out:
+		case <-r.Context().Done():
+			return
+		}
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// dispatch answers a JSON-RPC message as the stdio server does, with the
+// tools of h.server. Notifications get no answer.
+func (h *mcpHTTPHandler) dispatch(ctx context.Context, data []byte) *coremcp.JSONRPCResponse {
+	var req struct {
+		JSONRPC string          `json:"jsonrpc"`
+		Method  string          `json:"method"`
+		Params  json.RawMessage `json:"params,omitempty"`
+		ID      any             `json:"id,omitempty"`
+	}
+	if err := json.Unmarshal(data, &req); err != nil {
+		return mcpError(nil, coremcp.ParseError, "Parse error")
+	}
+	if req.ID == nil && strings.HasPrefix(req.Method, "notifications/") {
+		return nil
+	}
+
+	switch req.Method {
+	case "initialize":
+		return &coremcp.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: coremcp.InitializeResult{
+			ProtocolVersion: "2024-11-05",
+			ServerInfo:      coremcp.ServerInfo{Name: h.server.Name(), Version: h.version},
+			Capabilities:    coremcp.ServerCapabilities{Tools: &coremcp.ToolsCapability{}},
+		}}
+	case "ping":
+		return &coremcp.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: map[string]any{}}
+	case "tools/list":
+		return &coremcp.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: coremcp.ToolsListResult{Tools: h.server.GetTools()}}
+	case "tools/call":
+		var params coremcp.ToolCallParams
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				return mcpError(req.ID, coremcp.InvalidParams, "Invalid params structure")
+			}
+		}
+		text, err := h.server.ExecuteTool(ctx, params.Name, params.Arguments)
+		result := coremcp.ToolCallResult{Content: []coremcp.ContentBlock{{Type: "text", Text: text}}}
+		if err != nil {
+			result = coremcp.ToolCallResult{Content: []coremcp.ContentBlock{{Type: "text", Text: fmt.Sprintf("Error: %v", err)}}, IsError: true}
+		}
+		return &coremcp.JSONRPCResponse{JSONRPC: "2.0", ID: req.ID, Result: result}
+	default:
+		return mcpError(req.ID, coremcp.MethodNotFound, fmt.Sprintf("Method not found: %s", req.Method))
+	}
+}
+
+// mcpError returns a JSON-RPC error response.
+func mcpError(id any, code int, message string) *coremcp.JSONRPCResponse {
+	return &coremcp.JSONRPCResponse{JSONRPC: "2.0", ID: id, Error: &coremcp.JSONRPCError{Code: code, Message: message}}
+}
+
+// randomToken returns a random hex token, for SSE session IDs and the
+// generated bearer token.
+func randomToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// listenAddr returns addr with 127.0.0.1 as its host when it has none, as
+// in ":8787" or "8787", so the server is only reachable remotely when a host
+// is given explicitly.
+func listenAddr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		host, port = "", addr
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port)
+}
+
+// serveMCPHTTP serves server on addr until interrupted. Without a token, one
+// is generated and printed on stderr.
+func serveMCPHTTP(server *coremcp.Server, version, addr, token string) error {
+	addr = listenAddr(addr)
+	if token == "" {
+		t, err := randomToken()
+		if err != nil {
+			return err
+		}
+		token = t
+		fmt.Fprintf(os.Stderr, "Generated token (set --token or %s to choose one): %s\n", envMCPToken, token)
+	}
+
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           newMCPHTTPHandler(server, version, token),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(os.Stderr, "MCP server listening on %s (POST /mcp, or GET /sse)\n", addr)
+	if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	coremcp "github.com/lex00/wetwire-core-go/mcp"
+)
+
+func mcpTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(newMCPHTTPHandler(echoServer("wetwire_list"), "1.0", "secret"))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func mcpPost(t *testing.T, url, token, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+func TestMCPHTTP_Auth(t *testing.T) {
+	ts := mcpTestServer(t)
+	for _, token := range []string{"", "wrong"} {
+		resp := mcpPost(t, ts.URL+"/mcp", token, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("token %q: expected 401, got %d", token, resp.StatusCode)
+		}
+	}
+}
+
+func TestMCPHTTP_Request(t *testing.T) {
+	ts := mcpTestServer(t)
+
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"jsonrpc":"2.0","id":1,"method":"initialize"}`, `"serverInfo":{"name":"test","version":"1.0"}`},
+		{`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`, `"name":"wetwire_list"`},
+		{`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"wetwire_list","arguments":{"package":"q"}}}`, `"text":"{\"package\":\"q\"}"`},
+		{`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"missing"}}`, `"isError":true`},
+		{`{"jsonrpc":"2.0","id":5,"method":"resources/list"}`, `"code":-32601`},
+		{`not json`, `"code":-32700`},
+	}
+	for _, tt := range tests {
+		resp := mcpPost(t, ts.URL+"/mcp", "secret", tt.body)
+		var out json.RawMessage
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			t.Fatalf("%s: %v", tt.body, err)
+		}
+		if !strings.Contains(string(out), tt.want) {
+			t.Errorf("%s: expected %s in %s", tt.body, tt.want, out)
+		}
+	}
+
+	resp := mcpPost(t, ts.URL+"/mcp", "secret", `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("notification: expected 202, got %d", resp.StatusCode)
+	}
+}
+
+func TestMCPHTTP_SSE(t *testing.T) {
+	ts := mcpTestServer(t)
+
+	req, _ := http.NewRequest(http.MethodGet, ts.URL+"/sse", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	events := bufio.NewReader(resp.Body)
+
+	event, endpoint, err := readSSEEvent(events)
+	if err != nil || event != "endpoint" || !strings.HasPrefix(endpoint, "/messages?sessionId=") {
+		t.Fatalf("expected the endpoint event, got %q %q %v", event, endpoint, err)
+	}
+
+	post := mcpPost(t, ts.URL+endpoint, "secret", `{"jsonrpc":"2.0","id":7,"method":"tools/list"}`)
+	if post.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", post.StatusCode)
+	}
+	event, data, err := readSSEEvent(events)
+	if err != nil || event != "message" {
+		t.Fatalf("expected a message event, got %q %v", event, err)
+	}
+	var msg coremcp.JSONRPCResponse
+	if err := json.Unmarshal([]byte(data), &msg); err != nil || msg.ID != float64(7) {
+		t.Errorf("expected the answer to request 7, got %s (%v)", data, err)
+	}
+
+	if resp := mcpPost(t, ts.URL+"/messages?sessionId=unknown", "secret", `{}`); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("unknown session: expected 404, got %d", resp.StatusCode)
+	}
+}
+
+// readSSEEvent reads the next event of an SSE stream: its name and data.
+func readSSEEvent(r *bufio.Reader) (event, data string, err error) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", "", err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		switch {
+		case line == "" && (event != "" || data != ""):
+			return event, data, nil
+		case strings.HasPrefix(line, "event: "):
+			event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestListenAddr(t *testing.T) {
+	tests := map[string]string{
+		":8787":          "127.0.0.1:8787",
+		"8787":           "127.0.0.1:8787",
+		"localhost:8787": "localhost:8787",
+		"0.0.0.0:8787":   "0.0.0.0:8787",
+		"[::1]:8787":     "[::1]:8787",
+	}
+	for addr, want := range tests {
+		if got := listenAddr(addr); got != want {
+			t.Errorf("listenAddr(%q) = %q, want %q", addr, got, want)
+		}
+	}
+}
+
+func TestMCPRoot(t *testing.T) {
+	tests := []struct {
+		name      string
+		root      string
+		readOnly  bool
+		listen    string
+		noSandbox bool
+		want      string
+		wantErr   bool
+	}{
+		{name: "stdio", want: ""},
+		{name: "stdio with root", root: "ws", want: "ws"},
+		{name: "listen", listen: ":8787", want: "."},
+		{name: "listen with root", root: "ws", listen: ":8787", want: "ws"},
+		{name: "listen without sandbox", listen: ":8787", noSandbox: true, want: ""},
+		{name: "no sandbox on stdio", noSandbox: true, wantErr: true},
+		{name: "no sandbox with root", root: "ws", listen: ":8787", noSandbox: true, wantErr: true},
+		{name: "no sandbox read-only", readOnly: true, listen: ":8787", noSandbox: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mcpRoot(tt.root, tt.readOnly, tt.listen, tt.noSandbox)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mcpRoot() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("mcpRoot() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
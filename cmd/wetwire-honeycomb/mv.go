@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/rename"
+	"github.com/spf13/cobra"
+)
+
+// newMvCmd creates the "mv" command.
+func newMvCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "mv <type> <Name> <target-package> [path]",
+		Short: "Move a resource definition to another package",
+		Long: `Move the declaration of a query, SLO, trigger or board to the package in the
+target-package directory, which is created if needed. The declaration goes to
+a file with the same name as its current file.
+
+References under path are rewritten: the old package refers to the resource
+as target.Name, other packages switch their import to the target package, and
+the target package refers to it unqualified. Imports are added and removed to
+match. Moves that would create an import cycle are refused.
+
+After moving, lint is run over path so new issues show up straight away.
+
+Examples:
+    wetwire-honeycomb mv query Latency ./queries/latency
+    wetwire-honeycomb mv trigger HighErrors ./triggers ./monitoring --dry-run`,
+		Args: cobra.RangeArgs(3, 4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 3 {
+				path = args[3]
+			}
+			return runMv(os.Stdout, path, args[0], args[1], args[2], dryRun)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the files that would change without writing them")
+
+	return cmd
+}
+
+// runMv plans the move, prints the files it touches, and applies it and
+// re-runs lint unless dryRun is set.
+func runMv(w io.Writer, path, typ, name, target string, dryRun bool) error {
+	plan, err := rename.NewMovePlan(path, typ, name, target)
+	if err != nil {
+		return err
+	}
+
+	verb := "Moved"
+	if dryRun {
+		verb = "Would move"
+	}
+	fmt.Fprintf(w, "%s %s %s to %s: %d file(s)\n", verb, plan.Type, name, displayPath(plan.To), len(plan.Files))
+	for _, f := range plan.Files {
+		fmt.Fprintf(w, "  %s\n", displayPath(f))
+	}
+
+	if dryRun {
+		return nil
+	}
+	if err := plan.Apply(); err != nil {
+		return err
+	}
+
+	// The move is done, so lint issues are printed but do not fail it
+	fmt.Fprintln(w)
+	_ = runLint(w, os.Stderr, &domain.HoneycombDomain{}, path, false, nil)
+	return nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunMv(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":             "module example.com/obs\n\ngo 1.23\n",
+		"queries/queries.go": renameSource,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	target := filepath.Join(dir, "latency")
+
+	var out bytes.Buffer
+	if err := runMv(&out, dir, "query", "Latency", target, true); err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Would move query Latency to") || !strings.Contains(out.String(), "2 file(s)") {
+		t.Errorf("unexpected dry run output:\n%s", out.String())
+	}
+	if _, err := os.Stat(target); !os.IsNotExist(err) {
+		t.Error("dry run created the target package")
+	}
+
+	out.Reset()
+	if err := runMv(&out, dir, "query", "Latency", target, false); err != nil {
+		t.Fatalf("move failed: %v", err)
+	}
+	moved, _ := os.ReadFile(filepath.Join(target, "queries.go"))
+	if !strings.Contains(string(moved), "package latency") || !strings.Contains(string(moved), "var Latency = query.Query{") {
+		t.Errorf("expected moved declaration, got:\n%s", moved)
+	}
+	source, _ := os.ReadFile(filepath.Join(dir, "queries", "queries.go"))
+	if !strings.Contains(string(source), "[]query.Query{latency.Latency}") || !strings.Contains(string(source), `"example.com/obs/latency"`) {
+		t.Errorf("expected qualified reference, got:\n%s", source)
+	}
+	// Lint runs after the move
+	if !strings.Contains(out.String(), "Moved query Latency") || !strings.Contains(out.String(), "(WHC024)") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	if err := runMv(&out, dir, "query", "Missing", target, false); err == nil {
+		t.Error("expected error moving a missing query")
+	}
+}
@@ -0,0 +1,180 @@
+// Command output adds --no-color, --no-progress, --output-mode and --fsync,
+// and writes results as colored, aligned text.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/atomicfile"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/i18n"
+	"github.com/lex00/wetwire-honeycomb-go/internal/progress"
+	"github.com/lex00/wetwire-honeycomb-go/internal/render"
+	"github.com/spf13/cobra"
+)
+
+// addNoColorFlag adds the persistent --no-color flag to the root command. It
+// turns color off with render.DisableColor, so every renderer writes plain
+// text.
+func addNoColorFlag(rootCmd *cobra.Command) {
+	var noColor bool
+	preRun := rootCmd.PersistentPreRunE
+
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also when "+render.EnvNoColor+" is set)")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		render.DisableColor(noColor)
+		if preRun != nil {
+			return preRun(cmd, args)
+		}
+		return nil
+	}
+}
+
+// addOutputFlags adds the persistent --output-mode and --fsync flags to the
+// root command. --output-mode sets the OutputMode option of d, and commands
+// building outside of d read the flag; --fsync applies to every file written,
+// through atomicfile.SetSync.
+func addOutputFlags(rootCmd *cobra.Command, d *domain.HoneycombDomain) {
+	var fsync bool
+	preRun := rootCmd.PersistentPreRunE
+
+	rootCmd.PersistentFlags().StringVar(&d.Options.OutputMode, "output-mode", "", "Where built output goes: "+strings.Join(domain.OutputModes, ", ")+" (default file with -o, stdout without)")
+	rootCmd.PersistentFlags().BoolVar(&fsync, "fsync", false, "Flush written files to disk before returning")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if mode := d.Options.OutputMode; mode != "" && !slices.Contains(domain.OutputModes, mode) {
+			return fmt.Errorf("unknown --output-mode %q (want %s)", mode, strings.Join(domain.OutputModes, ", "))
+		}
+		atomicfile.SetSync(fsync)
+		if preRun != nil {
+			return preRun(cmd, args)
+		}
+		return nil
+	}
+}
+
+// addProgressFlag adds the persistent --no-progress flag to the root command
+// and shows the progress of discovery on stderr, except for the MCP server,
+// whose stderr is not a user's terminal. Progress is only shown on a
+// terminal, and only for operations taking more than a moment.
+func addProgressFlag(rootCmd *cobra.Command) {
+	var noProgress bool
+	preRun := rootCmd.PersistentPreRunE
+
+	rootCmd.PersistentFlags().BoolVar(&noProgress, "no-progress", false, "Disable progress output")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		progress.Disable(noProgress)
+		if cmd.Name() != "mcp" {
+			discovery.SetProgress(progress.For(os.Stderr, "Discovering", "files"))
+		}
+		if preRun != nil {
+			return preRun(cmd, args)
+		}
+		return nil
+	}
+}
+
+// writeText writes a result as text in the output language, like
+// coredomain.FormatResult, with the status in color and the errors as an
+// aligned table.
+func writeText(r *render.Renderer, result *coredomain.Result) {
+	status := r.Paint(render.Green, i18n.T("✓ Success"))
+	if !result.Success {
+		status = r.Paint(render.Red, i18n.T("✗ Failed"))
+	}
+	if result.Message != "" {
+		status += ": " + result.Message
+	}
+	r.Printf("%s\n", status)
+
+	if len(result.Errors) > 0 {
+		r.Printf("\n%s\n", i18n.T("Errors:"))
+		rows := make([][]render.Cell, len(result.Errors))
+		for i, e := range result.Errors {
+			rows[i] = errorRow(i, e)
+		}
+		r.Table("  ", rows)
+	}
+
+	if result.Data != nil {
+		r.Printf("\n%s\n", i18n.T("Data:"))
+		if data, err := json.MarshalIndent(result.Data, "  ", "  "); err == nil {
+			r.Printf("  %s\n", data)
+		} else {
+			r.Printf("  %v\n", result.Data)
+		}
+	}
+}
+
+// errorRow returns the table row of the i-th error of a result: its number,
+// location relative to the working directory, severity and message with the
+// rule code.
+func errorRow(i int, e coredomain.Error) []render.Cell {
+	location := e.Path
+	if location != "" {
+		location = displayPath(location)
+	}
+	if location != "" && e.Line > 0 {
+		location += ":" + strconv.Itoa(e.Line)
+	}
+	message := e.Message
+	if e.Code != "" {
+		message += fmt.Sprintf(" (%s)", e.Code)
+	}
+	return []render.Cell{
+		{Text: strconv.Itoa(i+1) + "."},
+		{Text: location},
+		{Text: e.Severity, Color: render.SeverityColor(e.Severity)},
+		{Text: message},
+	}
+}
+
+// writeListTable writes the entries of a list result as a table of type,
+// name, file and the first line of the description, with the content hash ID
+// after the name when ids is set and a query column when entries have a
+// "dsl" expression (list --dsl).
+func writeListTable(r *render.Renderer, result *coredomain.Result, ids bool) {
+	entries, ok := result.Data.([]map[string]string)
+	if !ok || !result.Success {
+		writeText(r, result)
+		return
+	}
+
+	r.Printf("%s: %s\n\n", r.Paint(render.Green, i18n.T("✓ Success")), result.Message)
+	header := []render.Cell{
+		{Text: i18n.T("TYPE"), Color: render.Bold},
+		{Text: i18n.T("NAME"), Color: render.Bold},
+	}
+	if ids {
+		header = append(header, render.Cell{Text: i18n.T("ID"), Color: render.Bold})
+	}
+	header = append(header,
+		render.Cell{Text: i18n.T("FILE"), Color: render.Bold},
+		render.Cell{Text: i18n.T("DESCRIPTION"), Color: render.Bold},
+	)
+	dsl := slices.ContainsFunc(entries, func(entry map[string]string) bool { return entry["dsl"] != "" })
+	if dsl {
+		header = append(header, render.Cell{Text: i18n.T("QUERY"), Color: render.Bold})
+	}
+
+	rows := [][]render.Cell{header}
+	for _, entry := range entries {
+		row := render.Plain(entry["type"], entry["name"])
+		if ids {
+			row = append(row, render.Cell{Text: entry["id"], Color: render.Cyan})
+		}
+		description, _, _ := strings.Cut(entry["description"], "\n")
+		row = append(row, render.Plain(displayPath(entry["file"]), description)...)
+		if dsl {
+			row = append(row, render.Plain(entry["dsl"])...)
+		}
+		rows = append(rows, row)
+	}
+	r.Table("  ", rows)
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/atomicfile"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/progress"
+	"github.com/lex00/wetwire-honeycomb-go/internal/render"
+	"github.com/spf13/cobra"
+)
+
+func TestAddNoColorFlag(t *testing.T) {
+	t.Cleanup(func() { render.DisableColor(false) })
+
+	ran := false
+	root := &cobra.Command{Use: "root", RunE: func(*cobra.Command, []string) error { return nil }}
+	root.PersistentPreRunE = func(*cobra.Command, []string) error {
+		ran = true
+		return nil
+	}
+	addNoColorFlag(root)
+
+	root.SetArgs([]string{"--no-color"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, ok := os.LookupEnv(render.EnvNoColor); ok {
+		t.Errorf("expected --no-color to leave %s unset", render.EnvNoColor)
+	}
+	if !ran {
+		t.Error("expected the existing pre-run to run")
+	}
+}
+
+func TestAddOutputFlags(t *testing.T) {
+	t.Cleanup(func() { atomicfile.SetSync(false) })
+
+	d := &domain.HoneycombDomain{}
+	root := &cobra.Command{Use: "root", RunE: func(*cobra.Command, []string) error { return nil }}
+	addOutputFlags(root, d)
+
+	root.SetArgs([]string{"--output-mode", "dir", "--fsync"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got := d.Options.OutputMode; got != domain.OutputDir {
+		t.Errorf("OutputMode = %q, want dir", got)
+	}
+	if !atomicfile.Sync() {
+		t.Error("expected --fsync to turn sync on")
+	}
+
+	root.SetArgs([]string{"--output-mode", "tape"})
+	if err := root.Execute(); err == nil {
+		t.Error("expected error for an unknown output mode")
+	}
+}
+
+func TestAddProgressFlag(t *testing.T) {
+	t.Cleanup(func() {
+		progress.Disable(false)
+		discovery.SetProgress(progress.Nop)
+	})
+
+	root := &cobra.Command{Use: "root", RunE: func(*cobra.Command, []string) error { return nil }}
+	addProgressFlag(root)
+
+	root.SetArgs([]string{"--no-progress"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+}
+
+func TestWriteText_AlignsErrors(t *testing.T) {
+	result := coredomain.NewErrorResultMultiple("lint issues found", []coredomain.Error{
+		{Path: "queries.go", Line: 5, Severity: "warning", Message: "Query has breakdowns but no order specified", Code: "WHC004"},
+		{Path: "boards/overview.go", Line: 120, Severity: "error", Message: "Board has no panels", Code: "WHC020"},
+	})
+
+	var out bytes.Buffer
+	writeText(render.NewColor(&out, false), result)
+	want := `✗ Failed: lint issues found
+
+Errors:
+  1.  queries.go:5            warning  Query has breakdowns but no order specified (WHC004)
+  2.  boards/overview.go:120  error    Board has no panels (WHC020)
+`
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	writeText(render.NewColor(&out, true), result)
+	if !strings.Contains(out.String(), "\x1b[31merror\x1b[0m  ") || !strings.Contains(out.String(), "\x1b[31m✗ Failed\x1b[0m") {
+		t.Errorf("expected colored severity and status, got %q", out.String())
+	}
+}
+
+func TestWriteListTable(t *testing.T) {
+	result := coredomain.NewResultWithData("Discovered 2 resources", []map[string]string{
+		{"type": "query", "name": "Latency", "file": "queries.go", "description": "Latency tracks P99.\nMore detail."},
+		{"type": "trigger", "name": "HighLatency", "file": "triggers.go"},
+	})
+
+	var out bytes.Buffer
+	writeListTable(render.NewColor(&out, false), result, false)
+	want := `✓ Success: Discovered 2 resources
+
+  TYPE     NAME         FILE         DESCRIPTION
+  query    Latency      queries.go   Latency tracks P99.
+  trigger  HighLatency  triggers.go
+`
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestWriteListTable_DSL(t *testing.T) {
+	result := coredomain.NewResultWithData("Discovered 2 resources", []map[string]string{
+		{"type": "query", "name": "Latency", "file": "queries.go", "dsl": "P99(duration_ms) LAST 2h"},
+		{"type": "board", "name": "Overview", "file": "boards.go", "description": "Service overview."},
+	})
+
+	var out bytes.Buffer
+	writeListTable(render.NewColor(&out, false), result, false)
+	want := `✓ Success: Discovered 2 resources
+
+  TYPE   NAME      FILE        DESCRIPTION        QUERY
+  query  Latency   queries.go                     P99(duration_ms) LAST 2h
+  board  Overview  boards.go   Service overview.
+`
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
+
+func TestWriteListTable_IDs(t *testing.T) {
+	result := coredomain.NewResultWithData("Discovered 2 resources", []map[string]string{
+		{"type": "query", "name": "Latency", "file": "queries.go", "id": "3f2a9c1b7d4e"},
+		{"type": "trigger", "name": "HighLatency", "file": "triggers.go", "id": "a81c0d55e2f9"},
+	})
+
+	var out bytes.Buffer
+	writeListTable(render.NewColor(&out, false), result, true)
+	want := `✓ Success: Discovered 2 resources
+
+  TYPE     NAME         ID            FILE         DESCRIPTION
+  query    Latency      3f2a9c1b7d4e  queries.go
+  trigger  HighLatency  a81c0d55e2f9  triggers.go
+`
+	if out.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,243 @@
+// Command pipe lets build and import feed shell pipelines such as
+// `wetwire-honeycomb build | wetwire-honeycomb validate -`.
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/audit"
+	"github.com/lex00/wetwire-honeycomb-go/internal/render"
+	"github.com/spf13/cobra"
+)
+
+// piped reports whether cmd should write bare output: stdout is not a
+// terminal and no --format was chosen.
+func piped(cmd *cobra.Command) bool {
+	return !cmd.Flags().Changed("format") && !render.IsTerminal(os.Stdout)
+}
+
+// writePiped writes the data of a result bare to stdout, so it can be read by
+// the next command in a pipeline. Messages, warnings and failures go to
+// stderr; a failed result with data, such as a --keep-going build, writes
+// both.
+func writePiped(stdout, stderr io.Writer, result *coredomain.Result) error {
+	data, ok := result.Data.(string)
+	if !result.Success {
+		failure := *result
+		if ok {
+			writeData(stdout, data)
+			failure.Data = nil
+		}
+		writeText(render.New(stderr), &failure)
+		return fmt.Errorf("operation failed")
+	}
+
+	if !ok {
+		fmt.Fprintln(stderr, result.Message)
+		return nil
+	}
+	writeData(stdout, data)
+	if len(result.Errors) > 0 {
+		rows := make([][]render.Cell, len(result.Errors))
+		for i, e := range result.Errors {
+			rows[i] = errorRow(i, e)
+		}
+		render.New(stderr).Table("", rows)
+	}
+	return nil
+}
+
+// writeData writes data followed by a newline.
+func writeData(w io.Writer, data string) {
+	fmt.Fprint(w, data)
+	if !strings.HasSuffix(data, "\n") {
+		fmt.Fprintln(w)
+	}
+}
+
+// runPipedBuild builds path with the build command's flags and the builder of
+// d and writes the JSON bare.
+func runPipedBuild(cmd *cobra.Command, stdout, stderr io.Writer, d *domain.HoneycombDomain, path string) error {
+	buildType, _ := cmd.Flags().GetString("type")
+	output, _ := cmd.Flags().GetString("output")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	ctx := coredomain.NewContext(context.Background(), path)
+	result, err := d.Builder().Build(ctx, path, domain.BuildOpts{
+		Type:   buildType,
+		Output: output,
+		DryRun: dryRun,
+	})
+	if err != nil {
+		return fmt.Errorf("build failed: %w", err)
+	}
+	return writePiped(stdout, stderr, result)
+}
+
+// extendImportCmd documents reading Query JSON from stdin with "-", writes
+// the generated Go code bare when stdout is piped and adds --from datadog,
+// --from grafana and --audit-log.
+func extendImportCmd(rootCmd *cobra.Command) {
+	cmd, _, err := rootCmd.Find([]string{"import"})
+	if err != nil || cmd == rootCmd {
+		return
+	}
+
+	importQueries := cmd.RunE
+
+	cmd.Short = "Import Query JSON as Go declarations"
+	cmd.Long = `Import Query JSON as Go query declarations.
+
+The source is a Query JSON file, a JSON object of resources keyed by section
+like a build output, or "-" to read either from stdin. Of the latter, the
+queries are imported, and the triggers and boards embedding query specs; a
+spec equal to one already imported references its query variable instead of
+being declared again. Without --target the generated code is printed; with
+--target it is written to that .go file, or to a file in that directory.
+
+With --preset instead of a source, the queries behind a Honeycomb default
+view are generated, to be customized as code. The ` + domain.PresetServiceDashboard + ` preset
+reproduces the service home view of --service: latency percentiles, errors and
+throughput of its server spans by route. The dataset defaults to the service
+name; set it with --dataset.
+
+With --from ` + domain.ImportFromDatadog + `, the source is a Datadog dashboard export, a monitor
+export or a JSON array of monitors, converted best-effort to queries, a board
+and triggers: metric queries become queries on the column named after the
+metric, filtered by their scope and broken down by their "by" tags, and
+metric monitors become triggers. Each query uses the dataset of its service
+tag, or --dataset. Widgets, monitors and query parts that have no Honeycomb
+equivalent are listed as warnings, the conversion report.
+
+With --from ` + domain.ImportFromGrafana + `, the source is a Grafana dashboard JSON, converted
+best-effort to a query per panel and a board skeleton: PromQL and LogQL label
+matchers become filters, aggregation labels breakdowns, and rate, quantile
+and *_over_time functions a calculation. Where the mapping is lossy, the
+generated query carries TODO comments, also listed as warnings. Targets of
+the Honeycomb data source are imported exactly.
+
+With --audit-log (or ` + audit.EnvLog + `), a JSON record of the import is
+appended to the given file.`
+	cmd.Example = `  wetwire-honeycomb import slow-requests.json --target ./queries
+  cat query.json | wetwire-honeycomb import - > queries/imported.go
+  wetwire-honeycomb import --preset service-dashboard --service checkoutservice --target ./queries
+  wetwire-honeycomb import --from datadog dashboard.json --target ./boards
+  wetwire-honeycomb import --from grafana dash.json --dataset otel --target ./boards`
+	cmd.Args = cobra.MaximumNArgs(1)
+
+	var preset, from string
+	var opts domain.PresetOpts
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		run := func() error { return runImport(cmd, importQueries, args) }
+		source := ""
+		switch {
+		case preset != "":
+			if len(args) > 0 {
+				return fmt.Errorf("--preset takes no source, got %q", args[0])
+			}
+			opts.Target, _ = cmd.Flags().GetString("target")
+			run = func() error { return runPresetImport(cmd, os.Stdout, os.Stderr, preset, opts) }
+			source = "preset:" + preset
+		case len(args) == 0:
+			return fmt.Errorf("requires a source, or --preset")
+		case convertImports[from] != nil:
+			source = args[0]
+			convOpts := domain.ConvertOpts{Dataset: opts.Dataset}
+			convOpts.Target, _ = cmd.Flags().GetString("target")
+			run = func() error {
+				return runConvertImport(cmd, os.Stdout, os.Stderr, convertImports[from], source, convOpts)
+			}
+		case from != "" && from != importFromHoneycomb:
+			return fmt.Errorf("unknown --from %q: expected %s, %s or %s", from, importFromHoneycomb, domain.ImportFromDatadog, domain.ImportFromGrafana)
+		default:
+			source = args[0]
+		}
+
+		auditFile := auditLog(cmd)
+		if auditFile == "" {
+			return run()
+		}
+
+		rec := audit.NewRecord("import", source, ".", domain.Version)
+		if preset == "" && convertImports[from] == nil {
+			rec.Resources, _ = domain.ImportCounts(source)
+		}
+		return finishAudit(auditFile, rec, run())
+	}
+	cmd.Flags().StringVar(&preset, "preset", "", "Generate the queries of a Honeycomb default view: "+strings.Join(domain.Presets, ", "))
+	cmd.Flags().StringVar(&opts.Service, "service", "", "Service the preset queries are filtered on (service.name)")
+	cmd.Flags().StringVar(&opts.Dataset, "dataset", "", "Dataset the preset, Datadog or Grafana queries use (default: the service name)")
+	cmd.Flags().StringVar(&from, "from", importFromHoneycomb, "Format of the source: "+importFromHoneycomb+" (Query JSON), "+domain.ImportFromDatadog+" or "+domain.ImportFromGrafana)
+	addAuditLogFlag(cmd)
+}
+
+// importFromHoneycomb is the default import source format, Query JSON or a
+// build output.
+const importFromHoneycomb = "honeycomb"
+
+// convertImports are the --from formats converted from another monitoring
+// tool, with their domain import.
+var convertImports = map[string]func(string, domain.ConvertOpts) (*domain.Result, error){
+	domain.ImportFromDatadog: domain.ImportDatadog,
+	domain.ImportFromGrafana: domain.ImportGrafana,
+}
+
+// runConvertImport converts another tool's export with importFn, writing the
+// code bare when stdout is piped. The conversion report then goes to stderr.
+func runConvertImport(cmd *cobra.Command, stdout, stderr io.Writer, importFn func(string, domain.ConvertOpts) (*domain.Result, error), source string, opts domain.ConvertOpts) error {
+	result, err := importFn(source, opts)
+	if err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+	if piped(cmd) {
+		if result.Success {
+			for _, e := range result.Errors {
+				fmt.Fprintf(stderr, "warning: %s\n", e.Message)
+			}
+		}
+		return writePiped(stdout, stderr, result)
+	}
+	format, _ := cmd.Flags().GetString("format")
+	return writeResult(stdout, result, format)
+}
+
+// runPresetImport generates the queries of a preset, writing the code bare
+// when stdout is piped.
+func runPresetImport(cmd *cobra.Command, stdout, stderr io.Writer, preset string, opts domain.PresetOpts) error {
+	result, err := domain.ImportPreset(preset, opts)
+	if err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+	if piped(cmd) {
+		return writePiped(stdout, stderr, result)
+	}
+	format, _ := cmd.Flags().GetString("format")
+	return writeResult(stdout, result, format)
+}
+
+// runImport runs the domain import command, or imports bare when stdout is
+// piped.
+func runImport(cmd *cobra.Command, importQueries func(*cobra.Command, []string) error, args []string) error {
+	if !piped(cmd) {
+		return importQueries(cmd, args)
+	}
+	target, _ := cmd.Flags().GetString("target")
+	return runPipedImport(os.Stdout, os.Stderr, args[0], target)
+}
+
+// runPipedImport imports source and writes the generated code bare.
+func runPipedImport(stdout, stderr io.Writer, source, target string) error {
+	ctx := coredomain.NewContext(context.Background(), ".")
+	result, err := (&domain.HoneycombDomain{}).Importer().Import(ctx, source, domain.ImportOpts{Target: target})
+	if err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+	return writePiped(stdout, stderr, result)
+}
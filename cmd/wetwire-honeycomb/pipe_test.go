@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/spf13/cobra"
+)
+
+func TestRunPipedBuild(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "boards.go"), []byte(grafanaSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := newTestRootCmd(t, "build")
+	var stdout, stderr bytes.Buffer
+	if err := runPipedBuild(cmd, &stdout, &stderr, &domain.HoneycombDomain{}, dir); err != nil {
+		t.Fatalf("runPipedBuild failed: %v\n%s", err, stderr.String())
+	}
+
+	// stdout is the bare build output
+	var out map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, stdout.String())
+	}
+	if _, ok := out["queries"]["Latency"]; !ok {
+		t.Errorf("expected Latency query in output, got %s", stdout.String())
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("expected no stderr output, got %q", stderr.String())
+	}
+}
+
+func TestRunPipedBuild_Failure(t *testing.T) {
+	cmd := newTestRootCmd(t, "build")
+	var stdout, stderr bytes.Buffer
+	if err := runPipedBuild(cmd, &stdout, &stderr, &domain.HoneycombDomain{}, t.TempDir()); err == nil {
+		t.Fatal("expected an error for a directory without resources")
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("expected nothing on stdout, got %q", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "no resources found") {
+		t.Errorf("expected the failure on stderr, got %q", stderr.String())
+	}
+}
+
+func TestRunPipedBuild_KeepGoing(t *testing.T) {
+	dir := t.TempDir()
+	source := `package q
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Good = query.Query{Dataset: "api", TimeRange: query.Hours(1)}
+
+var Bad = query.Query{Dataset: "${MISSING_DATASET}", TimeRange: query.Hours(1)}
+`
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := newTestRootCmd(t, "build")
+	var stdout, stderr bytes.Buffer
+	d := &domain.HoneycombDomain{Options: domain.Options{KeepGoing: true}}
+	if err := runPipedBuild(cmd, &stdout, &stderr, d, dir); err == nil {
+		t.Fatal("expected the build to fail")
+	}
+
+	// The partial output still goes to stdout, the failure to stderr
+	var out struct {
+		Queries map[string]json.RawMessage `json:"queries"`
+		Errors  []domain.BuildError        `json:"errors"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		t.Fatalf("stdout is not JSON: %v\n%s", err, stdout.String())
+	}
+	if _, ok := out.Queries["Good"]; !ok || len(out.Queries) != 1 {
+		t.Errorf("expected only the Good query, got %s", stdout.String())
+	}
+	if len(out.Errors) != 1 || out.Errors[0].Resource != "queries/Bad" || out.Errors[0].Line != 7 {
+		t.Errorf("expected an error for queries/Bad at line 7, got %+v", out.Errors)
+	}
+	if !strings.Contains(stderr.String(), "1 resource(s) failed to build") || strings.Contains(stderr.String(), `"queries"`) {
+		t.Errorf("expected only the failure on stderr, got %q", stderr.String())
+	}
+}
+
+func TestRunPipedImport(t *testing.T) {
+	old := domain.Stdin
+	domain.Stdin = strings.NewReader(`{"time_range":3600,"calculations":[{"op":"COUNT"}]}`)
+	defer func() { domain.Stdin = old }()
+
+	var stdout, stderr bytes.Buffer
+	if err := runPipedImport(&stdout, &stderr, domain.StdinPath, ""); err != nil {
+		t.Fatalf("runPipedImport failed: %v\n%s", err, stderr.String())
+	}
+	if !strings.HasPrefix(stdout.String(), "package queries\n") {
+		t.Errorf("expected bare Go code on stdout, got %q", stdout.String())
+	}
+	if !strings.Contains(stdout.String(), "query.Count()") {
+		t.Errorf("expected the imported calculation, got %q", stdout.String())
+	}
+}
+
+// newTestRootCmd returns the named subcommand of a fully extended root
+// command, with flags at their defaults.
+func newTestRootCmd(t *testing.T, name string) *cobra.Command {
+	t.Helper()
+	d := &domain.HoneycombDomain{}
+	rootCmd := domain.CreateRootCommand(d)
+	addDomainSpecificCommands(rootCmd, d)
+	cmd, _, err := rootCmd.Find([]string{name})
+	if err != nil {
+		t.Fatalf("find %s: %v", name, err)
+	}
+	return cmd
+}
+
+func TestRunDatadogImport(t *testing.T) {
+	old := domain.Stdin
+	domain.Stdin = strings.NewReader(`{"title": "API", "widgets": [
+	  {"definition": {"type": "timeseries", "title": "Requests", "requests": [{"q": "sum:trace.http.request.hits{service:api}"}]}},
+	  {"definition": {"type": "slo", "title": "Availability"}}
+	]}`)
+	defer func() { domain.Stdin = old }()
+
+	// Tests do not run in a terminal, so the code is written bare
+	var stdout, stderr bytes.Buffer
+	cmd := newTestRootCmd(t, "import")
+	if err := runConvertImport(cmd, &stdout, &stderr, domain.ImportDatadog, domain.StdinPath, domain.ConvertOpts{}); err != nil {
+		t.Fatalf("runConvertImport failed: %v\n%s", err, stderr.String())
+	}
+	for _, want := range []string{"package queries\n", "var Requests = query.Query{", "var APIBoard = board.Board{"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("expected %q in the code, got:\n%s", want, stdout.String())
+		}
+	}
+	if !strings.Contains(stderr.String(), "warning: Availability: slo widget") {
+		t.Errorf("expected the conversion report on stderr, got %q", stderr.String())
+	}
+}
+
+func TestRunGrafanaImport(t *testing.T) {
+	old := domain.Stdin
+	domain.Stdin = strings.NewReader(`{"title": "API", "panels": [
+	  {"type": "timeseries", "title": "Requests", "datasource": {"type": "prometheus"},
+	   "targets": [{"refId": "A", "expr": "sum by (route) (rate(http_requests_total{service=\"api\"}[5m]))"}]}
+	]}`)
+	defer func() { domain.Stdin = old }()
+
+	var stdout, stderr bytes.Buffer
+	cmd := newTestRootCmd(t, "import")
+	if err := runConvertImport(cmd, &stdout, &stderr, domain.ImportGrafana, domain.StdinPath, domain.ConvertOpts{}); err != nil {
+		t.Fatalf("runConvertImport failed: %v\n%s", err, stderr.String())
+	}
+	for _, want := range []string{"var Requests = query.Query{", "// TODO: rate(http_requests_total)", "var APIBoard = board.Board{"} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("expected %q in the code, got:\n%s", want, stdout.String())
+		}
+	}
+	if !strings.Contains(stderr.String(), "warning: Requests: query:") {
+		t.Errorf("expected the conversion report on stderr, got %q", stderr.String())
+	}
+}
+
+func TestImportCmd_UnknownFrom(t *testing.T) {
+	cmd := newTestRootCmd(t, "import")
+	if err := cmd.Flags().Set("from", "newrelic"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.RunE(cmd, []string{"dashboard.json"}); err == nil || !strings.Contains(err.Error(), "unknown --from") {
+		t.Errorf("expected an unknown --from error, got %v", err)
+	}
+}
@@ -0,0 +1,489 @@
+// Command preview adds inline resource JSON to the list and lint commands.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/i18n"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lint"
+	"github.com/lex00/wetwire-honeycomb-go/internal/render"
+	"github.com/spf13/cobra"
+)
+
+// resourcePreview is the serialized JSON of a discovered resource.
+type resourcePreview struct {
+	Type string          `json:"type"`
+	Name string          `json:"name"`
+	File string          `json:"file"`
+	Line int             `json:"line"`
+	JSON json.RawMessage `json:"json"`
+}
+
+// previewTypes maps build output keys to the resource types used by list.
+var previewTypes = map[string]string{
+	"queries":  "query",
+	"boards":   "board",
+	"slos":     "slo",
+	"triggers": "trigger",
+}
+
+// loadPreviews serializes every resource discovered in path with opts.
+func loadPreviews(path string, opts domain.Options) ([]resourcePreview, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("resolve path: %w", err)
+	}
+
+	resources, err := discovery.DiscoverAll(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("discovery failed: %w", err)
+	}
+
+	output, err := domain.SerializeResources(resources, "", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	type location struct {
+		file string
+		line int
+	}
+	locations := make(map[string]location)
+	for _, q := range resources.Queries {
+		locations["query/"+q.Name] = location{q.File, q.Line}
+	}
+	for _, b := range resources.Boards {
+		locations["board/"+b.Name] = location{b.File, b.Line}
+	}
+	for _, s := range resources.SLOs {
+		locations["slo/"+s.Name] = location{s.File, s.Line}
+	}
+	for _, t := range resources.Triggers {
+		locations["trigger/"+t.Name] = location{t.File, t.Line}
+	}
+
+	var previews []resourcePreview
+	for key, resourceMap := range output.Resources() {
+		typ := previewTypes[key]
+		for name, data := range resourceMap {
+			loc := locations[typ+"/"+name]
+			previews = append(previews, resourcePreview{Type: typ, Name: name, File: loc.file, Line: loc.line, JSON: data})
+		}
+	}
+	return previews, nil
+}
+
+// extendListCmd adds --show-json, --ids, --dsl, --tag, --only and --exclude
+// to the domain list command, and writes its text output as a table. The
+// flags set the Options of d, the domain the list command runs.
+func extendListCmd(rootCmd *cobra.Command, d *domain.HoneycombDomain) {
+	cmd, _, err := rootCmd.Find([]string{"list"})
+	if err != nil || cmd == rootCmd {
+		return
+	}
+
+	var showJSON bool
+	var ids bool
+	list := cmd.RunE
+
+	run := func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		text := format == "" || format == "text"
+		if !showJSON && !ids && !text {
+			return list(cmd, args)
+		}
+
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+		if !showJSON && text {
+			return runListTable(os.Stdout, d, path, ids)
+		}
+
+		var result *coredomain.Result
+		var err error
+		if showJSON {
+			result, err = runListWithJSON(d, path, ids)
+		} else {
+			result, err = listResources(d, path, ids)
+		}
+		if err != nil {
+			return err
+		}
+		return writeResult(os.Stdout, result, format)
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		err := run(cmd, args)
+		if perr := reportPanics(cmd, os.Stderr); perr != nil && err == nil {
+			err = perr
+		}
+		return err
+	}
+
+	cmd.Flags().BoolVar(&showJSON, "show-json", false, "Include each resource's serialized JSON")
+	cmd.Flags().BoolVar(&ids, "ids", false, "Include each resource's content hash ID")
+	cmd.Flags().BoolVar(&d.Options.DSL, "dsl", false, "Include each query as a one-line expression, as expr reads it")
+	cmd.Flags().StringArrayVar(&d.Options.Tags, "tag", nil, "Only list boards and triggers with this tag (key=value or key; repeatable)")
+	addNameFilterFlags(cmd, "list", &d.Options.Filter)
+	addDebugBundleFlag(cmd)
+}
+
+// extendGraphCmd adds --tag, --only, --exclude, --direction and --cluster-by
+// to the domain graph command, and the dot and mermaid formats, written without a result wrapper.
+// The flags set the Options of d, the domain the graph command runs.
+func extendGraphCmd(rootCmd *cobra.Command, d *domain.HoneycombDomain) {
+	cmd, _, err := rootCmd.Find([]string{"graph"})
+	if err != nil || cmd == rootCmd {
+		return
+	}
+
+	graph := cmd.RunE
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("format")
+		if format == "dot" || format == "mermaid" {
+			path := "."
+			if len(args) > 0 {
+				path = args[0]
+			}
+			return runGraph(os.Stdout, d, path, format)
+		}
+		return graph(cmd, args)
+	}
+
+	cmd.Flags().StringArrayVar(&d.Options.Tags, "tag", nil, "Only graph boards and triggers with this tag, and their queries (key=value or key; repeatable)")
+	addNameFilterFlags(cmd, "graph", &d.Options.Filter)
+	cmd.Flags().StringVar(&d.Options.GraphDirection, "direction", "", "Direction of the graph: TB, LR, BT or RL (default TB)")
+	cmd.Flags().StringVar(&d.Options.GraphClusterBy, "cluster-by", "", "Group nodes by dataset, package or type, or none (default dataset)")
+}
+
+// runGraph writes the graph of path, drawn by the grapher of d, in a diagram
+// format, dot or mermaid, as is, so it can be piped to Graphviz or pasted into
+// Markdown.
+func runGraph(w io.Writer, d *domain.HoneycombDomain, path, format string) error {
+	ctx := coredomain.NewContext(context.Background(), path)
+	result, err := d.Grapher().Graph(ctx, path, domain.GraphOpts{Format: format})
+	if err != nil {
+		return fmt.Errorf("graph failed: %w", err)
+	}
+	fmt.Fprintln(w, result.Data)
+	return nil
+}
+
+// addNameFilterFlags adds --only and --exclude to cmd, setting the patterns
+// of filter, with help naming what cmd does to the resources they select:
+// verb is "build", "list" or "graph".
+func addNameFilterFlags(cmd *cobra.Command, verb string, filter *domain.NameFilter) {
+	cmd.Flags().StringArrayVar(&filter.Only, "only", nil, "Only "+verb+" resources whose name matches this glob, e.g. 'Checkout*' (repeatable)")
+	cmd.Flags().StringArrayVar(&filter.Exclude, "exclude", nil, "Do not "+verb+" resources whose name matches this glob, e.g. '*Debug*' (repeatable)")
+}
+
+// listResources lists the resources in path with the lister of d, with the
+// "id" and "hash" of each when ids is set.
+func listResources(d *domain.HoneycombDomain, path string, ids bool) (*coredomain.Result, error) {
+	ctx := coredomain.NewContext(context.Background(), path)
+	result, err := d.Lister().List(ctx, path, domain.ListOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("list failed: %w", err)
+	}
+
+	entries, ok := result.Data.([]map[string]string)
+	if !ids || !ok {
+		return result, nil
+	}
+	resourceIDs, err := domain.GenerateResourceIDs(path, d.Options)
+	if err != nil {
+		return nil, fmt.Errorf("resource IDs: %w", err)
+	}
+	byKey := make(map[string]domain.ResourceID, len(resourceIDs))
+	for _, id := range resourceIDs {
+		byKey[id.Type+"/"+id.Name] = id
+	}
+	for _, entry := range entries {
+		if id, ok := byKey[entry["type"]+"/"+entry["name"]]; ok {
+			entry["id"] = id.ID
+			entry["hash"] = id.Hash
+		}
+	}
+	return result, nil
+}
+
+// runListTable lists the resources in path with the lister of d as a table,
+// with an ID column when ids is set.
+func runListTable(w io.Writer, d *domain.HoneycombDomain, path string, ids bool) error {
+	result, err := listResources(d, path, ids)
+	if err != nil {
+		return err
+	}
+
+	writeListTable(render.New(w), result, ids)
+	if !result.Success {
+		return fmt.Errorf("operation failed")
+	}
+	return nil
+}
+
+// runListWithJSON lists discovered resources with the lister of d, with their
+// serialized JSON, and their content hash IDs when ids is set.
+func runListWithJSON(d *domain.HoneycombDomain, path string, ids bool) (*coredomain.Result, error) {
+	result, err := listResources(d, path, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, ok := result.Data.([]map[string]string)
+	if !ok {
+		return result, nil
+	}
+
+	previews, err := loadPreviews(path, d.Options)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]json.RawMessage, len(previews))
+	for _, p := range previews {
+		byKey[p.Type+"/"+p.Name] = p.JSON
+	}
+
+	list := make([]map[string]any, 0, len(entries))
+	for _, entry := range entries {
+		item := make(map[string]any, len(entry)+1)
+		for k, v := range entry {
+			item[k] = v
+		}
+		if data, ok := byKey[entry["type"]+"/"+entry["name"]]; ok {
+			item["json"] = data
+		}
+		list = append(list, item)
+	}
+	result.Data = list
+	return result, nil
+}
+
+// extendLintCmd adds --show-query, --debug, --profile and --allow-orphan to
+// the domain lint command, and a security section to its text output. The
+// flags set the Options of d, the domain the lint command runs.
+func extendLintCmd(rootCmd *cobra.Command, d *domain.HoneycombDomain) {
+	cmd, _, err := rootCmd.Find([]string{"lint"})
+	if err != nil || cmd == rootCmd {
+		return
+	}
+
+	var showQuery bool
+	lint := cmd.RunE
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		path := "."
+		if len(args) > 0 {
+			path = args[0]
+		}
+		format, _ := cmd.Flags().GetString("format")
+		disable, _ := cmd.Flags().GetStringSlice("disable")
+
+		if showQuery {
+			return runLintWithQueries(os.Stdout, d, path, format, disable)
+		}
+		if format != "" && format != "text" {
+			// The security report is part of the result data
+			return lint(cmd, args)
+		}
+		fix, _ := cmd.Flags().GetBool("fix")
+		return runLint(os.Stdout, os.Stderr, d, path, fix, disable)
+	}
+
+	cmd.Flags().BoolVar(&showQuery, "show-query", false, "Show the serialized JSON of the resource behind each finding")
+	cmd.Flags().BoolVar(&d.Options.Debug, "debug", false, "Print the time spent in each rule to stderr (in the result data with --format json)")
+	cmd.Flags().BoolVar(&d.Options.Profile, "profile", false, "Like --debug, with the allocations of each rule and rules slower than 1ms per resource marked; rules run one at a time")
+	cmd.Flags().StringArrayVar(&d.Options.AllowOrphans, "allow-orphan", nil, "Accept queries matching this name pattern without a board, trigger or SLO using them (WHC026; repeatable)")
+}
+
+// runLint lints path with the linter of d and writes the result as text,
+// followed by the security report when a security rule found something. Rule
+// timings, with the Debug or Profile option, go to stderr.
+func runLint(w, stderr io.Writer, d *domain.HoneycombDomain, path string, fix bool, disable []string) error {
+	ctx := coredomain.NewContext(context.Background(), path)
+	result, err := d.Linter().Lint(ctx, path, domain.LintOpts{Format: "text", Fix: fix, Disable: disable})
+	if err != nil {
+		return fmt.Errorf("lint failed: %w", err)
+	}
+
+	report, _ := result.Data.(*domain.LintReport)
+	result.Data = nil
+	err = writeResult(w, result, "text")
+	if report != nil {
+		printSecurityReport(w, report.Security)
+		printRuleTimings(stderr, report.Timings, d.Options.Profile)
+	}
+	return err
+}
+
+// printRuleTimings writes the time spent in each lint rule as a table, with
+// allocations when memory is set (they were recorded by lint --profile) and
+// slow rules marked.
+func printRuleTimings(w io.Writer, timings []lint.RuleTiming, memory bool) {
+	if len(timings) == 0 {
+		return
+	}
+
+	header := render.Plain(i18n.T("RULE"), i18n.T("CHECKS"), i18n.T("TIME"))
+	if memory {
+		header = append(header, render.Plain(i18n.T("ALLOCS"), i18n.T("BYTES"))...)
+	}
+	rows := [][]render.Cell{header}
+
+	var total time.Duration
+	var slow int
+	for _, t := range timings {
+		row := render.Plain(t.Rule, strconv.Itoa(t.Checks), t.Duration.String())
+		if memory {
+			row = append(row, render.Plain(strconv.FormatUint(t.Allocs, 10), strconv.FormatUint(t.Bytes, 10))...)
+		}
+		if t.Slow {
+			row = append(row, render.Cell{Text: i18n.T("slow"), Color: render.Yellow})
+			slow++
+		}
+		rows = append(rows, row)
+		total += t.Duration
+	}
+
+	r := render.New(w)
+	r.Printf("\n%s\n", i18n.Sprintf("Rule timings (%s in rules):", total))
+	r.Table("  ", rows)
+	if slow > 0 {
+		r.Printf("%s\n", i18n.Sprintf("%d rule(s) took more than %s per resource", slow, lint.SlowCheck))
+	}
+}
+
+// printSecurityReport writes the security section of text lint output.
+func printSecurityReport(w io.Writer, report *domain.SecurityReport) {
+	if report == nil {
+		return
+	}
+
+	rules := make([]string, 0, len(report.ByRule))
+	for rule := range report.ByRule {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+	counts := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		counts = append(counts, fmt.Sprintf("%s: %d", rule, report.ByRule[rule]))
+	}
+
+	fmt.Fprintf(w, "\n%s\n", i18n.Sprintf("Security: %d potential secret(s) or sensitive field(s) (%s)", report.Findings, strings.Join(counts, ", ")))
+	for i, e := range report.Issues {
+		fmt.Fprintf(w, "  %d. %s\n", i+1, e.String())
+	}
+}
+
+// lintFinding is a lint issue with the JSON of the resource it was found in.
+type lintFinding struct {
+	coredomain.Error
+	Resource string          `json:"resource,omitempty"`
+	JSON     json.RawMessage `json:"json,omitempty"`
+}
+
+// runLintWithQueries lints path with the linter of d and shows the serialized
+// resource behind each finding: inline after the finding in text output, and
+// as the result data in other formats.
+func runLintWithQueries(w io.Writer, d *domain.HoneycombDomain, path, format string, disable []string) error {
+	ctx := coredomain.NewContext(context.Background(), path)
+	result, err := d.Linter().Lint(ctx, path, domain.LintOpts{Format: format, Disable: disable})
+	if err != nil {
+		return fmt.Errorf("lint failed: %w", err)
+	}
+
+	previews, err := loadPreviews(path, d.Options)
+	if err != nil {
+		return err
+	}
+
+	// Findings point at the line a resource is declared on
+	byLocation := make(map[string]resourcePreview, len(previews))
+	for _, p := range previews {
+		byLocation[fmt.Sprintf("%s:%d", p.File, p.Line)] = p
+	}
+
+	findings := make([]lintFinding, 0, len(result.Errors))
+	for _, e := range result.Errors {
+		finding := lintFinding{Error: e}
+		if p, ok := byLocation[fmt.Sprintf("%s:%d", e.Path, e.Line)]; ok {
+			finding.Resource = p.Type + " " + p.Name
+			finding.JSON = p.JSON
+		}
+		findings = append(findings, finding)
+	}
+
+	if format != "" && format != "text" {
+		if len(findings) > 0 {
+			result.Data = findings
+		}
+		return writeResult(w, result, format)
+	}
+
+	r := render.New(w)
+	if result.Success {
+		r.Printf("%s: %s\n", r.Paint(render.Green, i18n.T("✓ Success")), result.Message)
+		return nil
+	}
+
+	r.Printf("%s: %s\n", r.Paint(render.Red, i18n.T("✗ Failed")), result.Message)
+	shown := make(map[string]bool)
+	for i, f := range findings {
+		fmt.Fprintln(w)
+		r.Table("  ", [][]render.Cell{errorRow(i, f.Error)})
+		if f.JSON == nil {
+			continue
+		}
+		if shown[f.Resource] {
+			fmt.Fprintf(w, "     %s\n", i18n.Sprintf("%s: see above", f.Resource))
+			continue
+		}
+		shown[f.Resource] = true
+
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, f.JSON, "       ", "  "); err != nil {
+			continue
+		}
+		fmt.Fprintf(w, "     %s:\n       %s\n", f.Resource, strings.TrimSpace(pretty.String()))
+	}
+	if report, ok := result.Data.(*domain.LintReport); ok {
+		printSecurityReport(w, report.Security)
+	}
+	return fmt.Errorf("operation failed")
+}
+
+// writeResult writes a result in the given format, returning an error when
+// the result indicates failure, like the domain commands. Text is written in
+// the output language.
+func writeResult(w io.Writer, result *coredomain.Result, format string) error {
+	if format == "" || format == "text" {
+		writeText(render.New(w), result)
+	} else {
+		output, err := coredomain.FormatResult(result, format)
+		if err != nil {
+			return fmt.Errorf("failed to format result: %w", err)
+		}
+		fmt.Fprint(w, output)
+	}
+
+	if !result.Success {
+		return fmt.Errorf("operation failed")
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/contenthash"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lock"
+)
+
+const previewSource = `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Slow = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Breakdowns:   []string{"endpoint"},
+	Calculations: []query.Calculation{query.P99("duration_ms")},
+}
+`
+
+func writePreviewSource(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(previewSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestRunListWithJSON(t *testing.T) {
+	dir := writePreviewSource(t)
+
+	result, err := runListWithJSON(&domain.HoneycombDomain{}, dir, false)
+	if err != nil {
+		t.Fatalf("runListWithJSON failed: %v", err)
+	}
+
+	list, ok := result.Data.([]map[string]any)
+	if !ok || len(list) != 1 {
+		t.Fatalf("unexpected list data: %#v", result.Data)
+	}
+	data, ok := list[0]["json"].(json.RawMessage)
+	if !ok || !strings.Contains(string(data), `"breakdowns":["endpoint"]`) {
+		t.Errorf("expected serialized query JSON, got %#v", list[0]["json"])
+	}
+	if _, ok := list[0]["id"]; ok {
+		t.Errorf("expected no ID without ids, got %#v", list[0])
+	}
+
+	result, err = runListWithJSON(&domain.HoneycombDomain{}, dir, true)
+	if err != nil {
+		t.Fatalf("runListWithJSON failed: %v", err)
+	}
+	list = result.Data.([]map[string]any)
+	hash, err := lock.Hash(list[0]["json"].(json.RawMessage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list[0]["hash"] != hash || list[0]["id"] != contenthash.ID(hash) {
+		t.Errorf("expected the lockfile hash %s, got %#v", hash, list[0])
+	}
+}
+
+func TestRunLintWithQueries_Text(t *testing.T) {
+	dir := writePreviewSource(t)
+
+	var out bytes.Buffer
+	if err := runLintWithQueries(&out, &domain.HoneycombDomain{}, dir, "text", nil); err == nil {
+		t.Error("expected lint failure for breakdown without order/limit")
+	}
+
+	text := out.String()
+	if !strings.Contains(text, "WHC004") || !strings.Contains(text, "query Slow:") {
+		t.Errorf("expected finding with inline query, got:\n%s", text)
+	}
+	// The query is printed once even with several findings
+	if strings.Count(text, `"duration_ms"`) != 1 || !strings.Contains(text, "query Slow: see above") {
+		t.Errorf("expected query JSON shown once, got:\n%s", text)
+	}
+
+	out.Reset()
+	if err := runLintWithQueries(&out, &domain.HoneycombDomain{}, dir, "text", []string{"WHC004", "WHC008", "WHC024"}); err != nil {
+		t.Errorf("expected no findings with rules disabled, got %v:\n%s", err, out.String())
+	}
+}
+
+func TestRunLintWithQueries_JSON(t *testing.T) {
+	dir := writePreviewSource(t)
+
+	var out bytes.Buffer
+	_ = runLintWithQueries(&out, &domain.HoneycombDomain{}, dir, "json", nil)
+
+	var result struct {
+		Data []lintFinding `json:"data"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, out.String())
+	}
+	if len(result.Data) == 0 {
+		t.Fatal("expected findings in data")
+	}
+	for _, f := range result.Data {
+		if f.Resource != "query Slow" || len(f.JSON) == 0 {
+			t.Errorf("finding missing query preview: %+v", f)
+		}
+	}
+}
+
+func TestExtendListAndLintCmds(t *testing.T) {
+	d := &domain.HoneycombDomain{}
+	rootCmd := domain.CreateRootCommand(d)
+	extendListCmd(rootCmd, d)
+	extendLintCmd(rootCmd, d)
+
+	for _, c := range []struct{ cmd, flag string }{{"list", "show-json"}, {"lint", "show-query"}} {
+		cmd, _, err := rootCmd.Find([]string{c.cmd})
+		if err != nil {
+			t.Fatalf("%s command not found: %v", c.cmd, err)
+		}
+		if cmd.Flags().Lookup(c.flag) == nil {
+			t.Errorf("expected --%s on %s", c.flag, c.cmd)
+		}
+	}
+}
+
+const securitySource = `package triggers
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var Errors = trigger.Trigger{
+	Name:      "Errors",
+	Dataset:   "api",
+	Query:     query.Query{Dataset: "api", TimeRange: query.Hours(1), Calculations: []query.Calculation{query.Count()}},
+	Threshold: trigger.GreaterThan(10),
+	Frequency: trigger.Minutes(5),
+	Recipients: []trigger.Recipient{
+		trigger.WebhookURL("https://hooks.example.com/alerts?token=abc123"),
+	},
+}
+`
+
+func TestRunLint_SecurityReport(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "triggers.go"), []byte(securitySource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runLint(&out, io.Discard, &domain.HoneycombDomain{}, dir, false, nil); err == nil {
+		t.Error("expected lint failure for secret in webhook URL")
+	}
+
+	text := out.String()
+	if !strings.Contains(text, "Security: 1 potential secret(s) or sensitive field(s) (WHC058: 1)") {
+		t.Errorf("expected security section, got:\n%s", text)
+	}
+	if strings.Contains(text, "Data:") {
+		t.Errorf("expected no raw data in text output, got:\n%s", text)
+	}
+
+	out.Reset()
+	_ = runLint(&out, io.Discard, &domain.HoneycombDomain{}, writePreviewSource(t), false, nil)
+	if strings.Contains(out.String(), "Security:") {
+		t.Errorf("expected no security section without findings, got:\n%s", out.String())
+	}
+}
+
+func TestRunLint_Debug(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "triggers.go"), []byte(securitySource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, stderr bytes.Buffer
+	_ = runLint(&out, &stderr, &domain.HoneycombDomain{Options: domain.Options{Debug: true}}, dir, false, nil)
+
+	if !strings.Contains(stderr.String(), "Rule timings") || !strings.Contains(stderr.String(), "WHC058") {
+		t.Errorf("expected rule timings on stderr, got:\n%s", stderr.String())
+	}
+	if strings.Contains(out.String(), "Rule timings") {
+		t.Errorf("expected no timings on stdout, got:\n%s", out.String())
+	}
+}
+
+func TestRunLint_Profile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "triggers.go"), []byte(securitySource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out, stderr bytes.Buffer
+	_ = runLint(&out, &stderr, &domain.HoneycombDomain{Options: domain.Options{Profile: true}}, dir, false, nil)
+
+	for _, want := range []string{"Rule timings", "ALLOCS", "BYTES", "WHC058"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("expected %q in profile, got:\n%s", want, stderr.String())
+		}
+	}
+}
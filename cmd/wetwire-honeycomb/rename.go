@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/rename"
+	"github.com/spf13/cobra"
+)
+
+// newRenameCmd creates the "rename" command.
+func newRenameCmd() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "rename <type> <OldName> <NewName> [path]",
+		Short: "Rename a resource's Go variable and every reference to it",
+		Long: `Rename the Go variable of a query, SLO, trigger or board and rewrite every
+reference to it in the Go files under path, including references from other
+packages such as queries.Latency in a trigger.
+
+The resource's entry in any wetwire.lock under path moves to the new name, so
+diff --lock and apply --prune do not see the rename as a deletion. Files are
+rewritten in place; gofmt-clean files stay clean.
+
+Examples:
+    wetwire-honeycomb rename query Latency APILatency
+    wetwire-honeycomb rename trigger HighErrors HighErrorRate ./monitoring --dry-run`,
+		Args: cobra.RangeArgs(3, 4),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 3 {
+				path = args[3]
+			}
+			return runRename(os.Stdout, path, args[0], args[1], args[2], dryRun)
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "List the files that would change without writing them")
+
+	return cmd
+}
+
+// runRename plans the rename, prints the files it touches and applies it
+// unless dryRun is set.
+func runRename(w io.Writer, path, typ, oldName, newName string, dryRun bool) error {
+	plan, err := rename.NewPlan(path, typ, oldName, newName)
+	if err != nil {
+		return err
+	}
+
+	verb := "Renamed"
+	if dryRun {
+		verb = "Would rename"
+	}
+	fmt.Fprintf(w, "%s %s %s to %s: %d reference(s) in %d file(s)\n", verb, plan.Type, oldName, newName, plan.References(), len(plan.Files))
+
+	for _, f := range plan.Files {
+		fmt.Fprintf(w, "  %s (%d)\n", displayPath(f.File), len(f.Offsets))
+	}
+	for _, lockfile := range plan.Lockfiles {
+		fmt.Fprintf(w, "  %s (%s/%s -> %s/%s)\n", displayPath(lockfile),
+			rename.LockTypes[plan.Type], oldName, rename.LockTypes[plan.Type], newName)
+	}
+
+	if dryRun {
+		return nil
+	}
+	return plan.Apply()
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const renameSource = `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Latency = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Calculations: []query.Calculation{query.P99("duration_ms")},
+}
+
+var All = []query.Query{Latency}
+`
+
+func TestRunRename(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "queries.go")
+	if err := os.WriteFile(file, []byte(renameSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runRename(&out, dir, "query", "Latency", "APILatency", true); err != nil {
+		t.Fatalf("dry run failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "Would rename query Latency to APILatency: 2 reference(s) in 1 file(s)") {
+		t.Errorf("unexpected dry run output:\n%s", out.String())
+	}
+	if data, _ := os.ReadFile(file); string(data) != renameSource {
+		t.Error("dry run modified the source")
+	}
+
+	out.Reset()
+	if err := runRename(&out, dir, "query", "Latency", "APILatency", false); err != nil {
+		t.Fatalf("rename failed: %v", err)
+	}
+	data, _ := os.ReadFile(file)
+	if !strings.Contains(string(data), "var APILatency = query.Query{") || !strings.Contains(string(data), "[]query.Query{APILatency}") {
+		t.Errorf("expected renamed source, got:\n%s", data)
+	}
+
+	if err := runRename(&out, dir, "query", "Latency", "Other", false); err == nil {
+		t.Error("expected error renaming a missing query")
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/htmlreport"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lint"
+	"github.com/spf13/cobra"
+)
+
+// newReportCmd creates the "report" command.
+func newReportCmd() *cobra.Command {
+	var htmlDir string
+	var opts htmlreport.Options
+	var allowOrphans []string
+
+	cmd := &cobra.Command{
+		Use:   "report --html DIR [path...]",
+		Short: "Generate a static HTML site summarizing all resources",
+		Long: `Generate a static HTML site summarizing the discovered resources, for
+publishing from CI as an observability catalog:
+
+  index.html            Resource counts, datasets, the dependency graph and
+                        the SLO, trigger and board inventories
+  datasets/<name>.html  One page per dataset with its queries, SLOs, triggers
+                        and boards, their dependency graph and lint issues
+  lint.html             Lint issues, counted by rule
+
+Dependency graphs are embedded as SVG and styles are inline, so the directory
+can be served by any static file host. Lint runs with all rules, honoring
+HONEYCOMB_RETENTION_DAYS and --allow-orphan as lint does.
+
+Paths accept the same package patterns as build.
+
+Examples:
+    wetwire-honeycomb report --html site ./...
+    wetwire-honeycomb report --html public --title "Checkout observability" ./checkout/...`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			patterns := args
+			if len(patterns) == 0 {
+				patterns = []string{"."}
+			}
+
+			if cwd, err := os.Getwd(); err == nil {
+				opts.Root = cwd
+			}
+			opts.Generated = time.Now()
+			return runReport(os.Stdout, patterns, htmlDir, allowOrphans, opts)
+		},
+	}
+
+	cmd.Flags().StringVar(&htmlDir, "html", "", "Directory to write the HTML site to")
+	cmd.Flags().StringVar(&opts.Title, "title", htmlreport.DefaultTitle, "Title of the site")
+	cmd.Flags().StringArrayVar(&allowOrphans, "allow-orphan", nil, "Accept queries matching this name pattern without a board, trigger or SLO using them (WHC026; repeatable)")
+	_ = cmd.MarkFlagRequired("html")
+
+	return cmd
+}
+
+// runReport lints the resources selected by patterns, accepting the orphan
+// queries matching allowOrphans, and writes the HTML site to dir.
+func runReport(w io.Writer, patterns []string, dir string, allowOrphans []string, opts htmlreport.Options) error {
+	resources, err := discovery.DiscoverPatterns(patterns)
+	if err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+
+	config := lint.LintConfig{OrphanAllowlist: allowOrphans}
+	if days := os.Getenv(domain.EnvRetentionDays); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("%s must be a positive number of days, got %q", domain.EnvRetentionDays, days)
+		}
+		config.RetentionDays = n
+	}
+	issues := lint.LintAllWithConfig(resources, config)
+
+	pages, err := htmlreport.Write(dir, resources, issues, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Wrote %d pages to %s\n", len(pages), dir)
+	return nil
+}
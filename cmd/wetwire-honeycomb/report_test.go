@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/htmlreport"
+)
+
+const reportSource = `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Requests = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Calculations: []query.Calculation{query.Count()},
+}
+`
+
+func TestRunReport(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(reportSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	site := filepath.Join(dir, "site")
+
+	var out bytes.Buffer
+	if err := runReport(&out, []string{dir}, site, nil, htmlreport.Options{Root: dir}); err != nil {
+		t.Fatalf("runReport failed: %v", err)
+	}
+	if want := "Wrote 3 pages to " + site; !strings.Contains(out.String(), want) {
+		t.Errorf("expected %q, got %q", want, out.String())
+	}
+
+	page, err := os.ReadFile(filepath.Join(site, "datasets", "api.html"))
+	if err != nil {
+		t.Fatalf("expected a dataset page: %v", err)
+	}
+	for _, want := range []string{`<tr id="query-Requests">`, "queries.go:5"} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("expected %q in the dataset page", want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(site, "lint.html")); err != nil {
+		t.Errorf("expected a lint page: %v", err)
+	}
+}
+
+func TestRunReport_InvalidRetention(t *testing.T) {
+	t.Setenv("HONEYCOMB_RETENTION_DAYS", "soon")
+
+	err := runReport(&bytes.Buffer{}, []string{t.TempDir()}, t.TempDir(), nil, htmlreport.Options{})
+	if err == nil || !strings.Contains(err.Error(), "HONEYCOMB_RETENTION_DAYS") {
+		t.Errorf("expected a retention error, got %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+// Read-only mode and workspace sandboxing for the MCP server.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	coremcp "github.com/lex00/wetwire-core-go/mcp"
+)
+
+// mcpWriteTools are the tools a read-only MCP server does not expose: they
+// create or overwrite files.
+var mcpWriteTools = map[string]bool{
+	"wetwire_init":   true,
+	"wetwire_import": true,
+	"wetwire_design": true,
+	"wetwire_write":  true,
+}
+
+// mcpPathArgs are the tool arguments holding a file or directory path:
+// wetwire_import reads source and writes Go code to target.
+var mcpPathArgs = []string{"package", "path", "output", "output_dir", "source", "target"}
+
+// mcpDefaultPathArgs are the path arguments that default to the current
+// directory, by tool.
+var mcpDefaultPathArgs = map[string]string{
+	"wetwire_build":    "package",
+	"wetwire_lint":     "package",
+	"wetwire_list":     "package",
+	"wetwire_graph":    "package",
+	"wetwire_init":     "path",
+	"wetwire_validate": "path",
+	"wetwire_design":   "output_dir",
+}
+
+// mcpSandbox confines the tools of an MCP server to a workspace root and, in
+// read-only mode, keeps them from writing files.
+type mcpSandbox struct {
+	// root is the absolute, symlink-free workspace root
+	root     string
+	readOnly bool
+}
+
+// newMCPSandbox returns the sandbox of a workspace root, the current
+// directory when root is empty.
+func newMCPSandbox(root string, readOnly bool) (*mcpSandbox, error) {
+	if root == "" {
+		root = "."
+	}
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	abs, err = filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, fmt.Errorf("workspace root: %w", err)
+	}
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("workspace root %s is not a directory", root)
+	}
+	return &mcpSandbox{root: abs, readOnly: readOnly}, nil
+}
+
+// wrap returns a server with the tools of inner, less the write tools in
+// read-only mode, whose handlers check their arguments first.
+func (s *mcpSandbox) wrap(inner *coremcp.Server, name, version string) *coremcp.Server {
+	server := coremcp.NewServer(coremcp.Config{Name: name, Version: version})
+	for _, tool := range inner.GetTools() {
+		if s.readOnly && mcpWriteTools[tool.Name] {
+			continue
+		}
+		toolName := tool.Name
+		server.RegisterToolWithSchema(toolName, tool.Description, func(ctx context.Context, args map[string]any) (string, error) {
+			checked, err := s.checkArgs(toolName, args)
+			if err != nil {
+				return "", err
+			}
+			return inner.ExecuteTool(ctx, toolName, checked)
+		}, tool.InputSchema)
+	}
+	return server
+}
+
+// checkArgs returns a copy of the arguments of a tool call with their paths
+// resolved inside the workspace root, or an error for a path outside it. In
+// read-only mode builds are dry runs, and fixes and output files are
+// rejected.
+func (s *mcpSandbox) checkArgs(tool string, args map[string]any) (map[string]any, error) {
+	checked := make(map[string]any, len(args)+1)
+	for k, v := range args {
+		checked[k] = v
+	}
+	if s.readOnly {
+		if fix, _ := args["fix"].(bool); fix {
+			return nil, fmt.Errorf("%s: fix is not allowed in read-only mode", tool)
+		}
+		if out, _ := args["output"].(string); out != "" {
+			return nil, fmt.Errorf("%s: output is not allowed in read-only mode", tool)
+		}
+		if tool == "wetwire_build" {
+			checked["dry_run"] = true
+		}
+	}
+	// Tools default to the current directory, which need not be the root
+	if key, ok := mcpDefaultPathArgs[tool]; ok {
+		if _, set := args[key]; !set {
+			checked[key] = s.root
+		}
+	}
+	for _, key := range mcpPathArgs {
+		v, ok := args[key]
+		if !ok {
+			continue
+		}
+		p, isString := v.(string)
+		if !isString {
+			return nil, fmt.Errorf("%s: %s must be a string", tool, key)
+		}
+		resolved, err := s.resolve(p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", tool, key, err)
+		}
+		checked[key] = resolved
+	}
+	if files, ok := args["files"].([]any); ok {
+		resolved := make([]any, len(files))
+		for i, f := range files {
+			p, _ := f.(string)
+			real, err := s.resolve(p)
+			if err != nil {
+				return nil, fmt.Errorf("%s: files: %w", tool, err)
+			}
+			resolved[i] = real
+		}
+		checked["files"] = resolved
+	}
+	return checked, nil
+}
+
+// resolve returns p with each of its package patterns, separated by
+// os.PathListSeparator, made absolute: relative to the workspace root
+// unless absolute. It returns an error if one is outside the root,
+// including through a symbolic link. The part of a pattern from its first
+// "..." is kept; discovery walks from the directory before it, which must
+// be inside the root.
+func (s *mcpSandbox) resolve(p string) (string, error) {
+	patterns := filepath.SplitList(p)
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	for i, pattern := range patterns {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(s.root, pattern)
+		}
+		pattern = filepath.Clean(pattern)
+		dir, wildcard := pattern, ""
+		if j := strings.Index(pattern, "..."); j >= 0 {
+			dir, wildcard = pattern[:j], pattern[j:]
+		}
+		real, err := s.within(dir)
+		if err != nil {
+			return "", err
+		}
+		if strings.HasSuffix(dir, string(filepath.Separator)) && real != string(filepath.Separator) {
+			real += string(filepath.Separator)
+		}
+		patterns[i] = real + wildcard
+	}
+	return strings.Join(patterns, string(os.PathListSeparator)), nil
+}
+
+// within returns the absolute path p with symbolic links resolved, or an
+// error if it is outside the workspace root. Links are resolved on the
+// longest existing prefix, so paths of files yet to be written are checked
+// too.
+func (s *mcpSandbox) within(p string) (string, error) {
+	real, rest := filepath.Clean(p), ""
+	for {
+		if r, err := filepath.EvalSymlinks(real); err == nil {
+			real = filepath.Join(r, rest)
+			break
+		}
+		parent := filepath.Dir(real)
+		if parent == real {
+			return "", fmt.Errorf("%s does not exist", p)
+		}
+		rest = filepath.Join(filepath.Base(real), rest)
+		real = parent
+	}
+	rel, err := filepath.Rel(s.root, real)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the workspace root %s", p, s.root)
+	}
+	return real, nil
+}
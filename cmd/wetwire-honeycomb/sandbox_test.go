@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	coremcp "github.com/lex00/wetwire-core-go/mcp"
+)
+
+// echoServer returns a server whose tools return their arguments as JSON.
+func echoServer(tools ...string) *coremcp.Server {
+	server := coremcp.NewServer(coremcp.Config{Name: "test"})
+	for _, name := range tools {
+		server.RegisterToolWithSchema(name, name, func(ctx context.Context, args map[string]any) (string, error) {
+			data, err := json.Marshal(args)
+			return string(data), err
+		}, map[string]any{"type": "object"})
+	}
+	return server
+}
+
+func sandboxRoot(t *testing.T) string {
+	t.Helper()
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "queries"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestMCPSandbox_Resolve(t *testing.T) {
+	root := sandboxRoot(t)
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(root, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	sandbox, err := newMCPSandbox(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{"", root, false},
+		{".", root, false},
+		{"queries", filepath.Join(root, "queries"), false},
+		{"./queries/...", filepath.Join(root, "queries") + "/...", false},
+		{"./...", root + "/...", false},
+		{"queries/new/out.json", filepath.Join(root, "queries", "new", "out.json"), false},
+		{filepath.Join(root, "queries"), filepath.Join(root, "queries"), false},
+		{"queries" + string(os.PathListSeparator) + "./...", filepath.Join(root, "queries") + string(os.PathListSeparator) + root + "/...", false},
+		{"..", "", true},
+		{"../...", "", true},
+		{"queries/../../x", "", true},
+		{outside, "", true},
+		{"escape", "", true},
+		{"escape/queries/...", "", true},
+		{"queries" + string(os.PathListSeparator) + "..", "", true},
+	}
+	for _, tt := range tests {
+		got, err := sandbox.resolve(tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resolve(%q): expected error %v, got %v", tt.path, tt.wantErr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resolve(%q): expected %q, got %q", tt.path, tt.want, got)
+		}
+	}
+}
+
+func TestMCPSandbox_ReadOnly(t *testing.T) {
+	root := sandboxRoot(t)
+	sandbox, err := newMCPSandbox(root, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := sandbox.wrap(echoServer("wetwire_build", "wetwire_lint", "wetwire_init", "wetwire_design"), "test", "1.0")
+
+	var names []string
+	for _, tool := range server.GetTools() {
+		names = append(names, tool.Name)
+	}
+	slices.Sort(names)
+	if strings.Join(names, ",") != "wetwire_build,wetwire_lint" {
+		t.Errorf("expected only build and lint, got %v", names)
+	}
+
+	out, err := server.ExecuteTool(context.Background(), "wetwire_build", map[string]any{})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	var args map[string]any
+	if err := json.Unmarshal([]byte(out), &args); err != nil {
+		t.Fatal(err)
+	}
+	if args["dry_run"] != true || args["package"] != root {
+		t.Errorf("expected a dry run of the root, got %v", args)
+	}
+
+	for _, tt := range []struct {
+		tool string
+		args map[string]any
+	}{
+		{"wetwire_lint", map[string]any{"fix": true}},
+		{"wetwire_build", map[string]any{"output": "out.json"}},
+		{"wetwire_lint", map[string]any{"package": "../"}},
+		{"wetwire_lint", map[string]any{"package": 1}},
+	} {
+		if _, err := server.ExecuteTool(context.Background(), tt.tool, tt.args); err == nil {
+			t.Errorf("%s %v: expected an error", tt.tool, tt.args)
+		}
+	}
+}
+
+func TestMCPSandbox_Root(t *testing.T) {
+	root := sandboxRoot(t)
+	sandbox, err := newMCPSandbox(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := sandbox.wrap(echoServer("wetwire_build", "wetwire_design", "wetwire_import"), "test", "1.0")
+	if len(server.GetTools()) != 3 {
+		t.Errorf("expected all tools outside read-only mode, got %d", len(server.GetTools()))
+	}
+
+	out, err := server.ExecuteTool(context.Background(), "wetwire_design", map[string]any{"prompt": "x"})
+	if err != nil {
+		t.Fatalf("design: %v", err)
+	}
+	if !strings.Contains(out, `"output_dir":"`+root+`"`) {
+		t.Errorf("expected output_dir to default to the root, got %s", out)
+	}
+
+	out, err = server.ExecuteTool(context.Background(), "wetwire_build", map[string]any{"package": "queries", "output": "build/out.json"})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if !strings.Contains(out, `"output":"`+filepath.Join(root, "build", "out.json")+`"`) {
+		t.Errorf("expected output inside the root, got %s", out)
+	}
+
+	if _, err := server.ExecuteTool(context.Background(), "wetwire_import", map[string]any{"files": []any{"/etc/passwd"}}); err == nil {
+		t.Error("expected an error for an import file outside the root")
+	}
+
+	out, err = server.ExecuteTool(context.Background(), "wetwire_import", map[string]any{"source": "export.json", "target": "queries/export.go"})
+	if err != nil {
+		t.Fatalf("import: %v", err)
+	}
+	if !strings.Contains(out, `"source":"`+filepath.Join(root, "export.json")+`"`) || !strings.Contains(out, `"target":"`+filepath.Join(root, "queries", "export.go")+`"`) {
+		t.Errorf("expected source and target inside the root, got %s", out)
+	}
+	outside := filepath.Join(t.TempDir(), "export.go")
+	for _, args := range []map[string]any{
+		{"source": "/etc/passwd", "target": "queries/export.go"},
+		{"source": "export.json", "target": outside},
+		{"source": "export.json", "target": "../export.go"},
+	} {
+		if _, err := server.ExecuteTool(context.Background(), "wetwire_import", args); err == nil {
+			t.Errorf("import %v: expected an error for a path outside the root", args)
+		}
+	}
+}
+
+func TestNewMCPSandbox_NotADirectory(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "f")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newMCPSandbox(file, true); err == nil {
+		t.Error("expected an error for a file root")
+	}
+}
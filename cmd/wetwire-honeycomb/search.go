@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/search"
+	"github.com/spf13/cobra"
+)
+
+// newSearchCmd creates the "search" command.
+func newSearchCmd() *cobra.Command {
+	var opts search.Options
+
+	cmd := &cobra.Command{
+		Use:   "search <term> [path...]",
+		Short: "Find resources whose metadata contains a term",
+		Long: `Search the discovered resources for a term and print each match with its
+file and line.
+
+Names, datasets, breakdown, calculation, filter and order columns, filter
+values, descriptions, recipient targets and board panels are searched, as
+resolved by discovery, so queries built with helper functions are found
+where grepping the Go source would miss them. Matching is case-insensitive
+unless --case-sensitive is given.
+
+Paths accept the same package patterns as build.
+
+Examples:
+    wetwire-honeycomb search duration_ms
+    wetwire-honeycomb search checkout --type trigger --type slo ./...`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			patterns := args[1:]
+			if len(patterns) == 0 {
+				patterns = []string{"."}
+			}
+			format, _ := cmd.Flags().GetString("format")
+
+			return runSearch(os.Stdout, args[0], patterns, opts, format)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&opts.Types, "type", nil, "Only search these resource types: query, slo, trigger, board")
+	cmd.Flags().BoolVar(&opts.CaseSensitive, "case-sensitive", false, "Match the term case-sensitively")
+
+	return cmd
+}
+
+// runSearch searches the resources selected by patterns and writes the
+// results as text or JSON.
+func runSearch(w io.Writer, term string, patterns []string, opts search.Options, format string) error {
+	if term == "" {
+		return fmt.Errorf("search term must not be empty")
+	}
+
+	resources, err := discovery.DiscoverPatterns(patterns)
+	if err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+
+	results, err := search.Search(resources, term, opts)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		if results == nil {
+			results = []search.Result{}
+		}
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	}
+
+	if len(results) == 0 {
+		fmt.Fprintf(w, "No resources match %q\n", term)
+		return nil
+	}
+
+	for _, r := range results {
+		fmt.Fprintf(w, "%s:%d: %s %s\n", displayPath(r.File), r.Line, r.Type, r.Name)
+		for _, m := range r.Matches {
+			fmt.Fprintf(w, "    %s: %s\n", m.Field, m.Value)
+		}
+	}
+	return nil
+}
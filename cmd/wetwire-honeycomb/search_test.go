@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/search"
+)
+
+const searchSource = `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+func latency(dataset string) query.Query {
+	return query.Query{
+		Dataset:      dataset,
+		TimeRange:    query.Hours(2),
+		Calculations: []query.Calculation{query.P99("duration_ms")},
+	}
+}
+
+var CheckoutLatency = query.Query{
+	Dataset:      "checkout",
+	TimeRange:    query.Hours(2),
+	Filters:      []query.Filter{query.Equals("service.name", "checkout-api")},
+	Calculations: []query.Calculation{query.P99("duration_ms")},
+}
+
+var Requests = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Calculations: []query.Calculation{query.Count()},
+}
+`
+
+func TestRunSearch(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(searchSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runSearch(&out, "checkout", []string{dir}, search.Options{}, "text"); err != nil {
+		t.Fatalf("runSearch failed: %v", err)
+	}
+	text := out.String()
+	if !strings.Contains(text, "queries.go:13: query CheckoutLatency") || !strings.Contains(text, "    filter value: checkout-api") {
+		t.Errorf("expected CheckoutLatency match with file and line, got:\n%s", text)
+	}
+	if strings.Contains(text, "Requests") {
+		t.Errorf("expected only matching resources, got:\n%s", text)
+	}
+
+	out.Reset()
+	if err := runSearch(&out, "duration_ms", []string{dir}, search.Options{}, "json"); err != nil {
+		t.Fatalf("runSearch failed: %v", err)
+	}
+	var results []search.Result
+	if err := json.Unmarshal(out.Bytes(), &results); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(results) != 1 || results[0].Name != "CheckoutLatency" {
+		t.Errorf("unexpected results: %+v", results)
+	}
+
+	out.Reset()
+	if err := runSearch(&out, "missing", []string{dir}, search.Options{}, "text"); err != nil {
+		t.Fatalf("runSearch failed: %v", err)
+	}
+	if !strings.Contains(out.String(), `No resources match "missing"`) {
+		t.Errorf("expected no match message, got:\n%s", out.String())
+	}
+
+	if err := runSearch(&out, "", []string{dir}, search.Options{}, "text"); err == nil {
+		t.Error("expected error for empty term")
+	}
+}
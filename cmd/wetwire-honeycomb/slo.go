@@ -0,0 +1,494 @@
+// Command slo provides SLO analysis against historical Honeycomb data.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/backtest"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/honeycomb"
+	"github.com/spf13/cobra"
+)
+
+// newSLOCmd creates the "slo" command group.
+func newSLOCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "slo",
+		Short: "Analyze SLOs against historical data",
+	}
+
+	cmd.AddCommand(newSLOBacktestCmd())
+	cmd.AddCommand(newSLOReportCmd())
+
+	return cmd
+}
+
+// newSLOBacktestCmd creates the "slo backtest" subcommand.
+func newSLOBacktestCmd() *cobra.Command {
+	var days int
+
+	cmd := &cobra.Command{
+		Use:   "backtest <SLOName> [path]",
+		Short: "Replay an SLO's SLI over historical data",
+		Long: `Run an SLO's good/total event queries against the Honeycomb Query Data API
+over a historical window and report what the SLO compliance, error budget
+and burn alerts would have been.
+
+Use this to choose targets and burn alert thresholds before rolling out an SLO.
+The SLO may be referenced by its Go variable name or its Name field.
+
+Requires HONEYCOMB_API_KEY (and optionally HONEYCOMB_API_URL).
+
+Example:
+    wetwire-honeycomb slo backtest APIAvailability --days 30 ./slos`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 1 {
+				path = args[1]
+			}
+			format, _ := cmd.Flags().GetString("format")
+
+			client, err := honeycomb.NewClientFromEnv()
+			if err != nil {
+				return err
+			}
+
+			return runSLOBacktest(cmd.Context(), client, args[0], path, days, format)
+		},
+	}
+
+	cmd.Flags().IntVar(&days, "days", 30, "Number of days of history to replay")
+
+	return cmd
+}
+
+// runSLOBacktest discovers the named SLO, replays its SLI and prints the report.
+func runSLOBacktest(ctx context.Context, runner backtest.QueryRunner, name, path string, days int, format string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if days <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolve path: %w", err)
+	}
+
+	resources, err := discovery.DiscoverAll(absPath)
+	if err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+
+	ds, err := findSLO(resources, name)
+	if err != nil {
+		return err
+	}
+
+	good, total, err := resolveSLIQueries(resources, ds)
+	if err != nil {
+		return err
+	}
+
+	step := time.Hour
+	end := time.Now().UTC().Truncate(step)
+	start := end.Add(-time.Duration(days) * 24 * time.Hour)
+
+	goodSeries, err := backtest.FetchSeries(ctx, runner, datasetFor(good, ds), good.Query(), start, end, step)
+	if err != nil {
+		return fmt.Errorf("good events query: %w", err)
+	}
+	totalSeries, err := backtest.FetchSeries(ctx, runner, datasetFor(total, ds), total.Query(), start, end, step)
+	if err != nil {
+		return fmt.Errorf("total events query: %w", err)
+	}
+
+	samples, err := backtest.MergeSLISeries(goodSeries, totalSeries)
+	if err != nil {
+		return err
+	}
+
+	report := backtest.BacktestSLO(samples, ds.TargetPercentage, ds.BurnAlerts, step)
+
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	printSLOBacktest(ds, report, days)
+	return nil
+}
+
+// findSLO looks up a discovered SLO by Go variable name or SLO name.
+func findSLO(resources *discovery.DiscoveredResources, name string) (discovery.DiscoveredSLO, error) {
+	for _, s := range resources.SLOs {
+		if s.Name == name || s.SLOName == name {
+			return s, nil
+		}
+	}
+	return discovery.DiscoveredSLO{}, fmt.Errorf("SLO %q not found", name)
+}
+
+// findQuery looks up a discovered query by Go variable name.
+func findQuery(resources *discovery.DiscoveredResources, name string) *discovery.DiscoveredQuery {
+	for i := range resources.Queries {
+		if resources.Queries[i].Name == name {
+			return &resources.Queries[i]
+		}
+	}
+	return nil
+}
+
+// resolveSLIQueries returns the good and total event queries for an SLO,
+// following variable references when the SLI is not declared inline.
+func resolveSLIQueries(resources *discovery.DiscoveredResources, ds discovery.DiscoveredSLO) (*discovery.DiscoveredQuery, *discovery.DiscoveredQuery, error) {
+	good := ds.GoodEventsQuery
+	if good == nil && ds.GoodEventsQueryRef != "" {
+		good = findQuery(resources, ds.GoodEventsQueryRef)
+	}
+	total := ds.TotalEventsQuery
+	if total == nil && ds.TotalEventsQueryRef != "" {
+		total = findQuery(resources, ds.TotalEventsQueryRef)
+	}
+
+	if good == nil || total == nil {
+		return nil, nil, fmt.Errorf("SLO %s: could not resolve SLI good/total event queries", ds.Name)
+	}
+	return good, total, nil
+}
+
+// datasetFor returns the query's dataset, falling back to the SLO dataset.
+func datasetFor(q *discovery.DiscoveredQuery, ds discovery.DiscoveredSLO) string {
+	if q.Dataset != "" {
+		return q.Dataset
+	}
+	return ds.Dataset
+}
+
+// printSLOBacktest prints a human-readable backtest report.
+func printSLOBacktest(ds discovery.DiscoveredSLO, report backtest.SLOReport, days int) {
+	title := ds.SLOName
+	if title == "" {
+		title = ds.Name
+	}
+
+	status := "met"
+	if !report.Met {
+		status = "missed"
+	}
+
+	fmt.Printf("SLO backtest: %s (%s)\n", title, ds.Name)
+	fmt.Printf("Window:     %s to %s (%d days)\n", report.Start.Format(time.RFC3339), report.End.Format(time.RFC3339), days)
+	fmt.Printf("Target:     %.3f%%\n", report.TargetPercentage)
+	fmt.Printf("Events:     %.0f good / %.0f total\n", report.GoodEvents, report.TotalEvents)
+	fmt.Printf("Compliance: %.3f%% (target %s)\n", report.Compliance, status)
+	fmt.Printf("Budget:     %.1f%% consumed, %.1f%% remaining\n", report.BudgetConsumed, report.BudgetRemaining)
+
+	if len(report.BurnAlerts) == 0 {
+		fmt.Println("\nNo burn alerts configured")
+		return
+	}
+
+	fmt.Println("\nBurn alerts:")
+	for _, alert := range report.BurnAlerts {
+		fmt.Printf("  %s (%s, threshold %.2f, window %dh): fired %d time(s)\n",
+			alert.Name, alert.AlertType, alert.Threshold, alert.WindowHours, len(alert.Firings))
+		for _, f := range alert.Firings {
+			fmt.Printf("    %s to %s (peak %.2f)\n", f.Start.Format(time.RFC3339), f.End.Format(time.RFC3339), f.Peak)
+		}
+	}
+}
+
+// sloReportOptions configures "slo report".
+type sloReportOptions struct {
+	path       string
+	days       int
+	slos       []string
+	breakdowns []string
+	top        int
+	format     string
+}
+
+// newSLOReportCmd creates the "slo report" subcommand.
+func newSLOReportCmd() *cobra.Command {
+	var opts sloReportOptions
+
+	cmd := &cobra.Command{
+		Use:   "report [path]",
+		Short: "Summarize SLO performance for ops reviews",
+		Long: `Summarize every SLO (or those selected with --slo) over the last --days
+(default 7) using the Honeycomb Query Data API.
+
+Each summary lists the compliance over the reporting window, the error budget
+remaining over the SLO's full time period, the burn alerts that fired during the
+window and the breakdown groups that contributed the most bad events. Offenders
+are grouped by --breakdown, or by the total events query's breakdowns when the
+flag is not set.
+
+Use --format markdown for output suitable for pasting into review documents.
+
+Requires HONEYCOMB_API_KEY (and optionally HONEYCOMB_API_URL).
+
+Example:
+    wetwire-honeycomb slo report --format markdown --breakdown service.name ./slos`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.path = "."
+			if len(args) > 0 {
+				opts.path = args[0]
+			}
+			opts.format, _ = cmd.Flags().GetString("format")
+
+			client, err := honeycomb.NewClientFromEnv()
+			if err != nil {
+				return err
+			}
+
+			return runSLOReport(cmd.Context(), client, os.Stdout, opts)
+		},
+	}
+
+	cmd.Flags().IntVar(&opts.days, "days", 7, "Number of days in the reporting window")
+	cmd.Flags().StringArrayVar(&opts.slos, "slo", nil, "Only report on this SLO (repeatable)")
+	cmd.Flags().StringSliceVar(&opts.breakdowns, "breakdown", nil, "Columns to rank worst offenders by")
+	cmd.Flags().IntVar(&opts.top, "top", 5, "Number of worst offenders to list per SLO")
+
+	return cmd
+}
+
+// runSLOReport discovers SLOs, summarizes each one and writes the report.
+func runSLOReport(ctx context.Context, runner backtest.QueryRunner, w io.Writer, opts sloReportOptions) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if opts.days <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+
+	absPath, err := filepath.Abs(opts.path)
+	if err != nil {
+		return fmt.Errorf("resolve path: %w", err)
+	}
+
+	resources, err := discovery.DiscoverAll(absPath)
+	if err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+
+	slos := resources.SLOs
+	if len(opts.slos) > 0 {
+		slos = nil
+		for _, name := range opts.slos {
+			ds, err := findSLO(resources, name)
+			if err != nil {
+				return err
+			}
+			slos = append(slos, ds)
+		}
+	}
+	if len(slos) == 0 {
+		return fmt.Errorf("no SLOs found in %s", opts.path)
+	}
+
+	step := time.Hour
+	end := time.Now().UTC().Truncate(step)
+	since := end.Add(-time.Duration(opts.days) * 24 * time.Hour)
+
+	summaries := make([]backtest.SLOSummary, 0, len(slos))
+	for _, ds := range slos {
+		summary, err := summarizeSLO(ctx, runner, resources, ds, opts, since, end, step)
+		if err != nil {
+			return fmt.Errorf("SLO %s: %w", ds.Name, err)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	switch opts.format {
+	case "json":
+		data, err := json.MarshalIndent(summaries, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, string(data))
+	case "markdown", "md":
+		writeSLOReportMarkdown(w, summaries, opts.days)
+	default:
+		writeSLOReportText(w, summaries, opts.days)
+	}
+	return nil
+}
+
+// summarizeSLO fetches an SLO's SLI over its time period and its worst
+// offenders over the reporting window.
+func summarizeSLO(ctx context.Context, runner backtest.QueryRunner, resources *discovery.DiscoveredResources, ds discovery.DiscoveredSLO, opts sloReportOptions, since, end time.Time, step time.Duration) (backtest.SLOSummary, error) {
+	good, total, err := resolveSLIQueries(resources, ds)
+	if err != nil {
+		return backtest.SLOSummary{}, err
+	}
+	goodQuery, totalQuery := good.Query(), total.Query()
+	goodDataset, totalDataset := datasetFor(good, ds), datasetFor(total, ds)
+
+	// The budget is evaluated over the SLO's rolling period, or the reporting
+	// window if that is longer
+	periodDays := ds.TimePeriodDays
+	if periodDays <= 0 {
+		periodDays = 30
+	}
+	start := end.Add(-time.Duration(periodDays) * 24 * time.Hour)
+	if since.Before(start) {
+		start = since
+	}
+
+	goodSeries, err := backtest.FetchSeries(ctx, runner, goodDataset, goodQuery, start, end, step)
+	if err != nil {
+		return backtest.SLOSummary{}, fmt.Errorf("good events query: %w", err)
+	}
+	totalSeries, err := backtest.FetchSeries(ctx, runner, totalDataset, totalQuery, start, end, step)
+	if err != nil {
+		return backtest.SLOSummary{}, fmt.Errorf("total events query: %w", err)
+	}
+
+	samples, err := backtest.MergeSLISeries(goodSeries, totalSeries)
+	if err != nil {
+		return backtest.SLOSummary{}, err
+	}
+
+	summary := backtest.SummarizeSLO(samples, ds.TargetPercentage, ds.BurnAlerts, step, since)
+	summary.Name = ds.Name
+	summary.Title = ds.SLOName
+	if summary.Title == "" {
+		summary.Title = ds.Name
+	}
+
+	breakdowns := opts.breakdowns
+	if len(breakdowns) == 0 {
+		breakdowns = totalQuery.Breakdowns
+	}
+	if len(breakdowns) == 0 {
+		return summary, nil
+	}
+
+	// Offenders sum their buckets per group. The series step keeps the
+	// granularity within every query window; the whole reporting window would
+	// exceed the MaxQueryWindow chunks FetchGroupedSeries splits it into
+	goodQuery.Breakdowns = breakdowns
+	totalQuery.Breakdowns = breakdowns
+	goodGroups, err := backtest.FetchGroupedSeries(ctx, runner, goodDataset, goodQuery, since, end, step)
+	if err != nil {
+		return backtest.SLOSummary{}, fmt.Errorf("good events breakdown query: %w", err)
+	}
+	totalGroups, err := backtest.FetchGroupedSeries(ctx, runner, totalDataset, totalQuery, since, end, step)
+	if err != nil {
+		return backtest.SLOSummary{}, fmt.Errorf("total events breakdown query: %w", err)
+	}
+
+	summary.Breakdowns = breakdowns
+	summary.WorstOffenders = backtest.WorstOffenders(goodGroups, totalGroups, opts.top)
+	return summary, nil
+}
+
+// writeSLOReportText writes a human-readable SLO report.
+func writeSLOReportText(w io.Writer, summaries []backtest.SLOSummary, days int) {
+	for i, s := range summaries {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "SLO report: %s (%s)\n", s.Title, s.Name)
+		fmt.Fprintf(w, "Window:     %s to %s (%d days)\n", s.Start.Format(time.RFC3339), s.End.Format(time.RFC3339), days)
+		fmt.Fprintf(w, "Target:     %.3f%%\n", s.Window.TargetPercentage)
+		fmt.Fprintf(w, "Compliance: %.3f%% (%.0f good / %.0f total)\n", s.Window.Compliance, s.Window.GoodEvents, s.Window.TotalEvents)
+		fmt.Fprintf(w, "Budget:     %.1f%% remaining\n", s.Budget.BudgetRemaining)
+
+		fmt.Fprintf(w, "Burn events: %d\n", len(s.BurnEvents))
+		for _, e := range s.BurnEvents {
+			fmt.Fprintf(w, "  %s: %s to %s (peak %.2f)\n", e.Alert, e.Start.Format(time.RFC3339), e.End.Format(time.RFC3339), e.Peak)
+		}
+
+		if len(s.WorstOffenders) > 0 {
+			fmt.Fprintf(w, "Worst offenders by %s:\n", strings.Join(s.Breakdowns, ", "))
+			for _, o := range s.WorstOffenders {
+				fmt.Fprintf(w, "  %s: %.0f bad events (%.1f%% of bad, %.2f%% error rate)\n", o.Group, o.BadEvents, o.Share, o.ErrorRate)
+			}
+		}
+	}
+}
+
+// writeSLOReportMarkdown writes an SLO report as Markdown for ops review docs.
+func writeSLOReportMarkdown(w io.Writer, summaries []backtest.SLOSummary, days int) {
+	fmt.Fprintln(w, "# SLO report")
+	fmt.Fprintln(w)
+	if len(summaries) > 0 {
+		fmt.Fprintf(w, "%s to %s (%d days)\n\n", summaries[0].Start.Format(time.DateOnly), summaries[0].End.Format(time.DateOnly), days)
+	}
+
+	fmt.Fprintln(w, "| SLO | Target | Compliance | Budget remaining | Burn events |")
+	fmt.Fprintln(w, "|-----|--------|------------|------------------|-------------|")
+	for _, s := range summaries {
+		fmt.Fprintf(w, "| %s | %.3f%% | %.3f%% | %.1f%% | %d |\n",
+			markdownCell(s.Title), s.Window.TargetPercentage, s.Window.Compliance, s.Budget.BudgetRemaining, len(s.BurnEvents))
+	}
+
+	for _, s := range summaries {
+		status := "met"
+		if !s.Window.Met {
+			status = "missed"
+		}
+		periodDays := int(s.Budget.End.Sub(s.Budget.Start).Hours() / 24)
+
+		fmt.Fprintf(w, "\n## %s\n\n", s.Title)
+		fmt.Fprintf(w, "- **Compliance:** %.3f%% against a %.3f%% target (%s), %.0f good / %.0f total events\n",
+			s.Window.Compliance, s.Window.TargetPercentage, status, s.Window.GoodEvents, s.Window.TotalEvents)
+		fmt.Fprintf(w, "- **Error budget:** %.1f%% remaining over %d days (%.1f%% consumed)\n",
+			s.Budget.BudgetRemaining, periodDays, s.Budget.BudgetConsumed)
+
+		fmt.Fprintln(w, "\n### Burn events")
+		fmt.Fprintln(w)
+		if len(s.BurnEvents) == 0 {
+			fmt.Fprintln(w, "No burn alerts fired.")
+		} else {
+			fmt.Fprintln(w, "| Alert | Type | Started | Ended | Peak |")
+			fmt.Fprintln(w, "|-------|------|---------|-------|------|")
+			for _, e := range s.BurnEvents {
+				fmt.Fprintf(w, "| %s | %s | %s | %s | %.2f |\n",
+					markdownCell(e.Alert), e.AlertType, e.Start.Format(time.RFC3339), e.End.Format(time.RFC3339), e.Peak)
+			}
+		}
+
+		if len(s.Breakdowns) == 0 {
+			continue
+		}
+		columns := strings.Join(s.Breakdowns, ", ")
+		fmt.Fprintf(w, "\n### Worst offenders by %s\n\n", markdownCell(columns))
+		if len(s.WorstOffenders) == 0 {
+			fmt.Fprintln(w, "No bad events.")
+			continue
+		}
+		fmt.Fprintf(w, "| %s | Bad events | Share of bad events | Error rate |\n", markdownCell(columns))
+		fmt.Fprintln(w, "|---|---|---|---|")
+		for _, o := range s.WorstOffenders {
+			group := o.Group
+			if strings.Trim(group, ", ") == "" {
+				group = "(none)"
+			}
+			fmt.Fprintf(w, "| %s | %.0f | %.1f%% | %.2f%% |\n", markdownCell(group), o.BadEvents, o.Share, o.ErrorRate)
+		}
+	}
+}
+
+// markdownCell escapes text for use inside a Markdown table cell.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
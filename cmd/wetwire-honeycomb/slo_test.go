@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+// constantRunner answers every query with one series row per bucket.
+type constantRunner struct {
+	value    float64
+	datasets []string
+
+	// combinations are the filter combinations of the queries run
+	combinations []string
+
+	// bad is subtracted from the value of queries with filters, the good
+	// events queries of backtestSource
+	bad float64
+
+	// queries are the queries run
+	queries []query.Query
+}
+
+func (r *constantRunner) RunQuery(ctx context.Context, dataset string, q query.Query) (*honeycomb.QueryResult, error) {
+	r.datasets = append(r.datasets, dataset)
+	r.combinations = append(r.combinations, q.FilterCombination)
+	r.queries = append(r.queries, q)
+	value := r.value
+	if len(q.Filters) > 0 {
+		value -= r.bad
+	}
+	res := &honeycomb.QueryResult{}
+	for t := q.TimeRange.StartTime; t < q.TimeRange.EndTime; t += q.Granularity {
+		res.Series = append(res.Series, honeycomb.ResultRow{
+			Time: time.Unix(int64(t), 0).UTC(),
+			Data: map[string]any{"COUNT": value},
+		})
+	}
+	return res, nil
+}
+
+const backtestSource = `package slos
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/slo"
+)
+
+var GoodRequests = query.Query{
+	Dataset:           "api",
+	Calculations:      []query.Calculation{query.Count()},
+	Filters:           []query.Filter{query.LT("status_code", 500), query.Equals("cached", true)},
+	FilterCombination: "OR",
+}
+
+var AllRequests = query.Query{
+	Dataset:      "api",
+	Calculations: []query.Calculation{query.Count()},
+}
+
+var APIAvailability = slo.SLO{
+	Name:    "API Availability",
+	Dataset: "api",
+	SLI: slo.SLI{
+		GoodEvents:  GoodRequests,
+		TotalEvents: AllRequests,
+	},
+	Target:     slo.Percentage(99.9),
+	TimePeriod: slo.Days(30),
+	BurnAlerts: []slo.BurnAlert{slo.FastBurn(10.0)},
+}
+`
+
+func TestResolveSLIQueries(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "slos.go"), []byte(backtestSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resources, err := discovery.DiscoverAll(dir)
+	if err != nil {
+		t.Fatalf("DiscoverAll failed: %v", err)
+	}
+
+	ds, err := findSLO(resources, "API Availability")
+	if err != nil {
+		t.Fatalf("findSLO failed: %v", err)
+	}
+	if ds.Name != "APIAvailability" {
+		t.Errorf("findSLO by display name returned %q", ds.Name)
+	}
+
+	good, total, err := resolveSLIQueries(resources, ds)
+	if err != nil {
+		t.Fatalf("resolveSLIQueries failed: %v", err)
+	}
+	if good.Name != "GoodRequests" || total.Name != "AllRequests" {
+		t.Errorf("resolved %s/%s, want GoodRequests/AllRequests", good.Name, total.Name)
+	}
+
+	if _, err := findSLO(resources, "Missing"); err == nil {
+		t.Error("expected error for unknown SLO")
+	}
+}
+
+func TestRunSLOBacktest(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "slos.go"), []byte(backtestSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := &constantRunner{value: 100}
+	if err := runSLOBacktest(context.Background(), runner, "APIAvailability", dir, 2, "json"); err != nil {
+		t.Fatalf("runSLOBacktest failed: %v", err)
+	}
+
+	// One query per SLI side for a window shorter than MaxQueryWindow
+	if len(runner.datasets) != 2 {
+		t.Errorf("expected 2 queries, got %d", len(runner.datasets))
+	}
+	for _, d := range runner.datasets {
+		if d != "api" {
+			t.Errorf("queried dataset %q, want api", d)
+		}
+	}
+	// The good events query keeps its OR filters
+	if len(runner.combinations) != 2 || runner.combinations[0] != "OR" {
+		t.Errorf("expected the good events query to combine its filters with OR, got %q", runner.combinations)
+	}
+
+	if err := runSLOBacktest(context.Background(), runner, "APIAvailability", dir, 0, "json"); err == nil {
+		t.Error("expected error for non-positive --days")
+	}
+}
+
+func TestRunSLOReport_Markdown(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "slos.go"), []byte(backtestSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := &constantRunner{value: 100}
+	var out bytes.Buffer
+	opts := sloReportOptions{path: dir, days: 7, breakdowns: []string{"service.name"}, top: 5, format: "markdown"}
+	if err := runSLOReport(context.Background(), runner, &out, opts); err != nil {
+		t.Fatalf("runSLOReport failed: %v", err)
+	}
+
+	// Good and total SLI queries over the 30 day period, plus one grouped
+	// query per side for the offenders
+	if len(runner.datasets) != 12 {
+		t.Errorf("expected 12 queries, got %d", len(runner.datasets))
+	}
+
+	for _, want := range []string{
+		"# SLO report",
+		"| API Availability | 99.900% | 100.000% | 100.0% | 0 |",
+		"## API Availability",
+		"- **Error budget:** 100.0% remaining over 30 days",
+		"No burn alerts fired.",
+		"### Worst offenders by service.name",
+		"No bad events.",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunSLOReport_Offenders(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "slos.go"), []byte(backtestSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A 14 day window is split into two MaxQueryWindow queries per side
+	runner := &constantRunner{value: 100, bad: 1}
+	var out bytes.Buffer
+	opts := sloReportOptions{path: dir, days: 14, breakdowns: []string{"service.name"}, top: 5, format: "json"}
+	if err := runSLOReport(context.Background(), runner, &out, opts); err != nil {
+		t.Fatalf("runSLOReport failed: %v", err)
+	}
+
+	// No query asks for a granularity longer than its time range
+	for _, q := range runner.queries {
+		if span := q.TimeRange.EndTime - q.TimeRange.StartTime; q.Granularity > span {
+			t.Errorf("granularity %ds exceeds the %ds time range of %+v", q.Granularity, span, q.TimeRange)
+		}
+	}
+
+	// The offender sums the hourly buckets of both weeks
+	var summaries []struct {
+		WorstOffenders []struct {
+			BadEvents   float64 `json:"bad_events"`
+			TotalEvents float64 `json:"total_events"`
+		} `json:"worst_offenders"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &summaries); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(summaries) != 1 || len(summaries[0].WorstOffenders) != 1 {
+		t.Fatalf("expected one offender, got %s", out.String())
+	}
+	if o := summaries[0].WorstOffenders[0]; o.BadEvents != 14*24 || o.TotalEvents != 14*24*100 {
+		t.Errorf("offender has %.0f bad of %.0f events, want %d of %d", o.BadEvents, o.TotalEvents, 14*24, 14*24*100)
+	}
+}
+
+func TestRunSLOReport_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "slos.go"), []byte(backtestSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runner := &constantRunner{value: 100}
+
+	if err := runSLOReport(context.Background(), runner, io.Discard, sloReportOptions{path: dir, days: 0}); err == nil {
+		t.Error("expected error for non-positive --days")
+	}
+	if err := runSLOReport(context.Background(), runner, io.Discard, sloReportOptions{path: dir, days: 7, slos: []string{"Missing"}}); err == nil {
+		t.Error("expected error for unknown --slo")
+	}
+	if err := runSLOReport(context.Background(), runner, io.Discard, sloReportOptions{path: t.TempDir(), days: 7}); err == nil {
+		t.Error("expected error when no SLOs are found")
+	}
+}
+
+func TestMarkdownCell(t *testing.T) {
+	if got := markdownCell("a|b"); got != `a\|b` {
+		t.Errorf("markdownCell = %q", got)
+	}
+}
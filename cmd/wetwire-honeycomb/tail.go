@@ -0,0 +1,273 @@
+// Command tail streams recent events matching a query's filters.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/backtest"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/spf13/cobra"
+)
+
+// tailLag is how far behind now each window ends, so that events still
+// being ingested are counted in the next window rather than missed.
+const tailLag = 5 * time.Second
+
+// tailQueryLimit is the number of groups each tail query returns, to count
+// the events of more groups than are printed.
+const tailQueryLimit = 1000
+
+// tailOptions configures "tail".
+type tailOptions struct {
+	path     string
+	columns  []string
+	interval time.Duration
+	window   time.Duration
+	limit    int
+	polls    int
+	format   string
+
+	// now returns the current time; time.Now when nil
+	now func() time.Time
+}
+
+// newTailCmd creates the "tail" command.
+func newTailCmd() *cobra.Command {
+	var opts tailOptions
+
+	cmd := &cobra.Command{
+		Use:   "tail <QueryName> [path]",
+		Short: "Stream recent events matching a query's filters",
+		Long: `Poll the Honeycomb Query Data API for the events since the last poll that
+match the filters of a declared query, to check that the
+filters match real events before committing them.
+
+Each poll counts the matching events of a short window grouped by --column,
+by default the filtered columns and the query's breakdowns, and prints the
+most frequent groups. The query's calculations and time range are ignored.
+Windows end ` + tailLag.String() + ` before now to let events finish arriving.
+
+The Query Data API is rate limited: keep --interval at 10s or more for
+long-running tails. Stop with Ctrl-C, or after --polls polls.
+
+Requires HONEYCOMB_API_KEY (and optionally HONEYCOMB_API_URL).
+
+Example:
+    wetwire-honeycomb tail SlowRequests ./queries
+    wetwire-honeycomb tail SlowRequests --column http.route --interval 30s --polls 4 ./queries`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.path = "."
+			if len(args) > 1 {
+				opts.path = args[1]
+			}
+			opts.format, _ = cmd.Flags().GetString("format")
+
+			client, err := honeycomb.NewClientFromEnv()
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return runTail(ctx, client, os.Stdout, args[0], opts)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&opts.columns, "column", nil, "Columns to group events by (default: the filtered columns and breakdowns)")
+	cmd.Flags().DurationVar(&opts.interval, "interval", 15*time.Second, "Time between polls")
+	cmd.Flags().DurationVar(&opts.window, "window", time.Minute, "How far back the first poll looks")
+	cmd.Flags().IntVar(&opts.limit, "limit", 10, "Number of groups to print per poll")
+	cmd.Flags().IntVar(&opts.polls, "polls", 0, "Stop after this many polls (0 polls until interrupted)")
+
+	return cmd
+}
+
+// tailPoll is the result of one poll, as printed with --format json.
+type tailPoll struct {
+	Start  time.Time   `json:"start"`
+	End    time.Time   `json:"end"`
+	Events float64     `json:"events"`
+	Groups []tailGroup `json:"groups"`
+}
+
+// tailGroup is the number of events sharing the values of the tail columns.
+type tailGroup struct {
+	Count  float64        `json:"count"`
+	Values map[string]any `json:"values,omitempty"`
+}
+
+// runTail discovers the named query and polls for its matching events until
+// ctx is done or opts.polls polls have run.
+func runTail(ctx context.Context, runner backtest.QueryRunner, w io.Writer, name string, opts tailOptions) error {
+	if opts.interval <= 0 || opts.window <= 0 {
+		return fmt.Errorf("--interval and --window must be positive")
+	}
+	if opts.now == nil {
+		opts.now = time.Now
+	}
+
+	absPath, err := filepath.Abs(opts.path)
+	if err != nil {
+		return fmt.Errorf("resolve path: %w", err)
+	}
+	resources, err := discovery.DiscoverAll(absPath)
+	if err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+	dq := findQuery(resources, name)
+	if dq == nil {
+		return fmt.Errorf("query %q not found", name)
+	}
+	if dq.Dataset == "" {
+		return fmt.Errorf("query %s has no dataset", name)
+	}
+
+	tq := tailQuery(*dq, opts)
+	if opts.format != "json" {
+		fmt.Fprintf(w, "Tailing %s every %s: %s\n", name, opts.interval, tq)
+	}
+
+	var total float64
+	polls := 0
+	start := opts.now().Add(-tailLag - opts.window).Truncate(time.Second)
+loop:
+	for {
+		end := opts.now().Add(-tailLag).Truncate(time.Second)
+		if end.After(start) {
+			poll, err := runTailPoll(ctx, runner, tq, start, end)
+			if err != nil {
+				if ctx.Err() != nil {
+					break loop
+				}
+				return err
+			}
+			if err := writeTailPoll(w, poll, tq.Breakdowns, opts.limit, opts.format); err != nil {
+				return err
+			}
+			total += poll.Events
+			polls++
+			start = end
+		}
+		if opts.polls > 0 && polls >= opts.polls {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-time.After(opts.interval):
+		}
+	}
+
+	if opts.format != "json" {
+		fmt.Fprintf(w, "\n%.0f matching events in %d polls\n", total, polls)
+		if total == 0 && polls > 0 {
+			fmt.Fprintf(w, "No events matched the filters of %s: check the column names and values against the dataset %s\n", name, dq.Dataset)
+		}
+	}
+	return nil
+}
+
+// tailQuery returns the query counting the events that match the filters of
+// dq, grouped by the tail columns and most frequent first.
+func tailQuery(dq discovery.DiscoveredQuery, opts tailOptions) query.Query {
+	q := dq.Query()
+
+	columns := opts.columns
+	if len(columns) == 0 {
+		for _, f := range q.Filters {
+			columns = append(columns, f.Column)
+		}
+		columns = append(columns, q.Breakdowns...)
+	}
+	var breakdowns []string
+	for _, c := range columns {
+		if !slices.Contains(breakdowns, c) {
+			breakdowns = append(breakdowns, c)
+		}
+	}
+
+	return query.Query{
+		Dataset:           q.Dataset,
+		Calculations:      []query.Calculation{query.Count()},
+		Filters:           q.Filters,
+		FilterCombination: dq.FilterCombination,
+		Breakdowns:        breakdowns,
+		Orders:            []query.Order{{Op: "COUNT", Order: "descending"}},
+		Limit:             tailQueryLimit,
+	}
+}
+
+// runTailPoll runs the tail query over [start, end).
+func runTailPoll(ctx context.Context, runner backtest.QueryRunner, tq query.Query, start, end time.Time) (tailPoll, error) {
+	tq.TimeRange = query.Absolute(start, end)
+	res, err := runner.RunQuery(ctx, tq.Dataset, tq)
+	if err != nil {
+		return tailPoll{}, fmt.Errorf("tail query: %w", err)
+	}
+
+	poll := tailPoll{Start: start, End: end, Groups: []tailGroup{}}
+	for _, row := range res.Results {
+		g := tailGroup{Count: row.Value("COUNT")}
+		for _, b := range tq.Breakdowns {
+			if g.Values == nil {
+				g.Values = make(map[string]any)
+			}
+			g.Values[b] = row.Data[b]
+		}
+		poll.Events += g.Count
+		poll.Groups = append(poll.Groups, g)
+	}
+	sort.SliceStable(poll.Groups, func(i, j int) bool { return poll.Groups[i].Count > poll.Groups[j].Count })
+	return poll, nil
+}
+
+// writeTailPoll prints a poll as a JSON line, or as its time and event count
+// followed by a line for each of the limit most frequent groups.
+func writeTailPoll(w io.Writer, poll tailPoll, columns []string, limit int, format string) error {
+	if format == "json" {
+		data, err := json.Marshal(poll)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	stamp := poll.End.Local().Format(time.TimeOnly)
+	if poll.Events == 0 {
+		fmt.Fprintf(w, "%s  no matching events\n", stamp)
+		return nil
+	}
+	fmt.Fprintf(w, "%s  %.0f events\n", stamp, poll.Events)
+	groups := poll.Groups
+	if limit > 0 && len(groups) > limit {
+		groups = groups[:limit]
+	}
+	for _, g := range groups {
+		values := make([]string, len(columns))
+		for i, c := range columns {
+			v := g.Values[c]
+			if v == nil {
+				v = "(none)"
+			}
+			values[i] = fmt.Sprintf("%s=%v", c, v)
+		}
+		fmt.Fprintf(w, "  %6.0f  %s\n", g.Count, strings.Join(values, "  "))
+	}
+	return nil
+}
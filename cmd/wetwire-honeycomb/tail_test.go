@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+const tailSource = `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var SlowRequests = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Calculations: []query.Calculation{query.P99("duration_ms")},
+	Filters:      []query.Filter{query.GT("duration_ms", 1000), query.Equals("service.name", "checkout")},
+	Breakdowns:   []string{"http.route"},
+}
+
+var NoDataset = query.Query{
+	Calculations: []query.Calculation{query.Count()},
+}
+`
+
+// tailRunner answers tail queries with fixed groups and records them.
+type tailRunner struct {
+	rows    []map[string]any
+	queries []query.Query
+}
+
+func (r *tailRunner) RunQuery(ctx context.Context, dataset string, q query.Query) (*honeycomb.QueryResult, error) {
+	r.queries = append(r.queries, q)
+	res := &honeycomb.QueryResult{}
+	for _, row := range r.rows {
+		res.Results = append(res.Results, honeycomb.ResultRow{Data: row})
+	}
+	return res, nil
+}
+
+// tailClock returns a clock that advances by step on every call.
+func tailClock(step time.Duration) func() time.Time {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func writeTailSource(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(tailSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestRunTail(t *testing.T) {
+	dir := writeTailSource(t)
+	runner := &tailRunner{rows: []map[string]any{
+		{"COUNT": 3.0, "duration_ms": 1200.0, "service.name": "checkout", "http.route": "/cart"},
+		{"COUNT": 9.0, "duration_ms": 2500.0, "service.name": "checkout", "http.route": "/pay"},
+	}}
+
+	var out bytes.Buffer
+	opts := tailOptions{path: dir, interval: time.Millisecond, window: time.Minute, limit: 1, polls: 2, now: tailClock(10 * time.Second)}
+	if err := runTail(context.Background(), runner, &out, "SlowRequests", opts); err != nil {
+		t.Fatalf("runTail failed: %v", err)
+	}
+
+	if len(runner.queries) != 2 {
+		t.Fatalf("expected 2 polls, got %d", len(runner.queries))
+	}
+	first, second := runner.queries[0], runner.queries[1]
+	if first.TimeRange.EndTime-first.TimeRange.StartTime != 70 {
+		t.Errorf("first poll should cover the window and the time since, got %+v", first.TimeRange)
+	}
+	if second.TimeRange.StartTime != first.TimeRange.EndTime {
+		t.Errorf("polls should not overlap: %+v then %+v", first.TimeRange, second.TimeRange)
+	}
+	if got := strings.Join(first.Breakdowns, ","); got != "duration_ms,service.name,http.route" {
+		t.Errorf("breakdowns = %s, want the filtered columns and breakdowns", got)
+	}
+	if len(first.Calculations) != 1 || first.Calculations[0].Op != "COUNT" || len(first.Filters) != 2 {
+		t.Errorf("unexpected tail query %s", first)
+	}
+
+	for _, want := range []string{
+		"Tailing SlowRequests every 1ms: COUNT FROM api WHERE duration_ms > 1000 AND service.name = checkout",
+		"12 events\n",
+		"       9  duration_ms=2500  service.name=checkout  http.route=/pay\n",
+		"24 matching events in 2 polls",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output missing %q:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "/cart") {
+		t.Errorf("expected only the most frequent group with --limit 1:\n%s", out.String())
+	}
+}
+
+func TestRunTail_NoEvents(t *testing.T) {
+	dir := writeTailSource(t)
+	runner := &tailRunner{}
+
+	var out bytes.Buffer
+	opts := tailOptions{path: dir, columns: []string{"http.route"}, interval: time.Millisecond, window: time.Minute, polls: 1, format: "json", now: tailClock(time.Second)}
+	if err := runTail(context.Background(), runner, &out, "SlowRequests", opts); err != nil {
+		t.Fatalf("runTail failed: %v", err)
+	}
+	var poll tailPoll
+	if err := json.Unmarshal(out.Bytes(), &poll); err != nil {
+		t.Fatalf("expected one JSON poll, got %q: %v", out.String(), err)
+	}
+	if poll.Events != 0 || len(poll.Groups) != 0 {
+		t.Errorf("unexpected poll %+v", poll)
+	}
+	if got := strings.Join(runner.queries[0].Breakdowns, ","); got != "http.route" {
+		t.Errorf("breakdowns = %s, want --column", got)
+	}
+
+	out.Reset()
+	opts.format = ""
+	if err := runTail(context.Background(), runner, &out, "SlowRequests", opts); err != nil {
+		t.Fatalf("runTail failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "No events matched the filters of SlowRequests") {
+		t.Errorf("expected a hint when nothing matches:\n%s", out.String())
+	}
+}
+
+func TestRunTail_Errors(t *testing.T) {
+	dir := writeTailSource(t)
+	runner := &tailRunner{}
+	opts := tailOptions{path: dir, interval: time.Second, window: time.Minute, polls: 1}
+
+	if err := runTail(context.Background(), runner, io.Discard, "Missing", opts); err == nil {
+		t.Error("expected error for an unknown query")
+	}
+	if err := runTail(context.Background(), runner, io.Discard, "NoDataset", opts); err == nil {
+		t.Error("expected error for a query without a dataset")
+	}
+	opts.interval = 0
+	if err := runTail(context.Background(), runner, io.Discard, "SlowRequests", opts); err == nil {
+		t.Error("expected error for a non-positive --interval")
+	}
+}
@@ -0,0 +1,84 @@
+// Usage metrics: when WETWIRE_HONEYCOMB_TELEMETRY_ENDPOINT is set, every
+// command reports its name, outcome, duration and resource counts to it.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+// usageRecorder counts the resources discovered while a command runs.
+type usageRecorder struct {
+	mu        sync.Mutex
+	start     time.Time
+	resources map[string]int
+}
+
+// startUsage starts recording the usage of a command run when telemetry is
+// on, and returns the recorder, nil when it is off. A telemetry
+// misconfiguration is reported on stderr and turns it off.
+func startUsage() *usageRecorder {
+	if _, ok, err := telemetry.FromEnv(); !ok {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: telemetry disabled: %v\n", err)
+		}
+		return nil
+	}
+	u := &usageRecorder{start: time.Now()}
+	discovery.SetObserver(u.observe)
+	return u
+}
+
+// observe records the counts of resources, the last discovery winning.
+func (u *usageRecorder) observe(resources *discovery.DiscoveredResources) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.resources = map[string]int{
+		"queries":  len(resources.Queries),
+		"slos":     len(resources.SLOs),
+		"triggers": len(resources.Triggers),
+		"boards":   len(resources.Boards),
+		"custom":   len(resources.Custom),
+	}
+}
+
+// finish sends the usage of cmd, which ended with err, ignoring failures:
+// telemetry never changes the outcome of a command. Runs of the root command
+// alone, such as --help, are not reported.
+func (u *usageRecorder) finish(cmd *cobra.Command, err error) {
+	if u == nil || cmd == nil || !cmd.HasParent() {
+		return
+	}
+	discovery.SetObserver(nil)
+	config, ok, _ := telemetry.FromEnv()
+	if !ok {
+		return
+	}
+
+	u.mu.Lock()
+	usage := telemetry.Usage{
+		Command:   commandName(cmd),
+		Success:   err == nil,
+		Start:     u.start,
+		Duration:  time.Since(u.start),
+		Resources: u.resources,
+		Version:   version,
+	}
+	u.mu.Unlock()
+	telemetry.Send(context.Background(), config, usage)
+}
+
+// commandName returns the command path of cmd without the program name,
+// e.g. "slo backtest".
+func commandName(cmd *cobra.Command) string {
+	_, name, _ := strings.Cut(cmd.CommandPath(), " ")
+	return name
+}
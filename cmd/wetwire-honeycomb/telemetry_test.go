@@ -0,0 +1,99 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/telemetry"
+)
+
+func TestUsageRecorder(t *testing.T) {
+	dir := t.TempDir()
+	src := `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var SecretTeamLatency = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(1),
+	Calculations: []query.Calculation{query.P99("duration_ms")},
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(data))
+	}))
+	defer server.Close()
+	t.Setenv(telemetry.EnvEndpoint, server.URL)
+	t.Setenv(telemetry.EnvDoNotTrack, "")
+
+	d := &domain.HoneycombDomain{}
+	rootCmd := domain.CreateRootCommand(d)
+	addDomainSpecificCommands(rootCmd, d)
+	rootCmd.SetArgs([]string{"list", "--no-progress", dir})
+	rootCmd.SetOut(io.Discard)
+
+	usage := startUsage()
+	if usage == nil {
+		t.Fatal("expected telemetry to be on with an endpoint set")
+	}
+	cmd, err := rootCmd.ExecuteC()
+	if err != nil {
+		t.Fatalf("list failed: %v", err)
+	}
+	usage.finish(cmd, err)
+
+	if len(bodies) != 1 {
+		t.Fatalf("expected one report, got %d", len(bodies))
+	}
+	body := bodies[0]
+	for _, want := range []string{
+		`{"key":"command","value":{"stringValue":"list"}}`,
+		`{"key":"outcome","value":{"stringValue":"success"}}`,
+		`{"key":"resource.type","value":{"stringValue":"queries"}}],"timeUnixNano":`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected %s in the report, got %s", want, body)
+		}
+	}
+	for _, identifier := range []string{"SecretTeamLatency", "api", filepath.Base(dir)} {
+		if strings.Contains(body, `"`+identifier+`"`) || strings.Contains(body, identifier+"/") {
+			t.Errorf("the report should not contain %q: %s", identifier, body)
+		}
+	}
+
+	// Runs of the root command alone, and runs with telemetry off, are not
+	// reported
+	usage.finish(rootCmd, nil)
+	t.Setenv(telemetry.EnvEndpoint, "")
+	if startUsage() != nil {
+		t.Error("expected telemetry to be off without an endpoint")
+	}
+	if len(bodies) != 1 {
+		t.Errorf("expected no further report, got %d", len(bodies))
+	}
+}
+
+func TestCommandName(t *testing.T) {
+	d := &domain.HoneycombDomain{}
+	rootCmd := domain.CreateRootCommand(d)
+	addDomainSpecificCommands(rootCmd, d)
+	cmd, _, err := rootCmd.Find([]string{"slo", "backtest"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := commandName(cmd); got != "slo backtest" {
+		t.Errorf("commandName() = %q, want %q", got, "slo backtest")
+	}
+}
@@ -3,16 +3,21 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 
 	"github.com/lex00/wetwire-core-go/agent/agents"
 	"github.com/lex00/wetwire-core-go/agent/orchestrator"
 	"github.com/lex00/wetwire-core-go/agent/personas"
 	"github.com/lex00/wetwire-core-go/agent/results"
+	"github.com/lex00/wetwire-honeycomb-go/domain"
 	"github.com/lex00/wetwire-honeycomb-go/internal/agent"
+	"github.com/lex00/wetwire-honeycomb-go/internal/atomicfile"
 	"github.com/lex00/wetwire-honeycomb-go/internal/kiro"
 	"github.com/spf13/cobra"
 )
@@ -27,6 +32,7 @@ func newTestCmd() *cobra.Command {
 	var stream bool
 	var allPersonas bool
 	var provider string
+	var expected string
 
 	cmd := &cobra.Command{
 		Use:   "test [prompt]",
@@ -47,17 +53,23 @@ Scoring (0-15 points):
   - Output Validity: Valid Query JSON produced?
   - Question Efficiency: Appropriate clarifications?
 
+With --expected, the generated resources are also compared with the
+expected resources of a scenario using the semantic differ: each expected
+resource scores a point for being generated and one for each matching key
+field, such as its dataset, calculations, trigger threshold or SLO target.
+
 Example:
     wetwire-honeycomb test --persona beginner "Create a query to find slow requests"
+    wetwire-honeycomb test --expected ./examples/tasks_api_scenario/expected/... "$(cat prompt.md)"
     wetwire-honeycomb test --persona expert "Build an SLI dashboard query set"
     wetwire-honeycomb test --all-personas "Create error tracking queries"`,
 		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			prompt := args[0]
 			if allPersonas {
-				return runTestAllPersonas(prompt, outputDir, scenario, maxLintCycles, stream, provider)
+				return runTestAllPersonas(prompt, outputDir, scenario, maxLintCycles, stream, provider, expected)
 			}
-			return runTestWithProvider(prompt, outputDir, personaName, scenario, maxLintCycles, stream, provider)
+			return runTestWithProvider(prompt, outputDir, personaName, scenario, maxLintCycles, stream, provider, expected)
 		},
 	}
 
@@ -68,21 +80,48 @@ Example:
 	cmd.Flags().BoolVarP(&stream, "stream", "s", false, "Stream AI responses")
 	cmd.Flags().BoolVar(&allPersonas, "all-personas", false, "Run test with all personas")
 	cmd.Flags().StringVar(&provider, "provider", "anthropic", "AI provider: 'anthropic' or 'kiro'")
+	cmd.Flags().StringVar(&expected, "expected", "", "Score the generated resources against the expected resources in this directory")
 
 	return cmd
 }
 
-// runTestWithProvider runs the test with the specified provider.
-func runTestWithProvider(prompt, outputDir, personaName, scenario string, maxLintCycles int, stream bool, provider string) error {
+// runTestWithProvider runs the test with the specified provider, then scores
+// the generated resources against expected when it is set.
+func runTestWithProvider(prompt, outputDir, personaName, scenario string, maxLintCycles int, stream bool, provider, expected string) error {
+	var err error
 	if provider == "kiro" {
-		return runTestKiro(prompt, outputDir, personaName)
+		err = runTestKiro(prompt, outputDir, personaName)
+	} else {
+		err = runTestAnthropic(prompt, outputDir, personaName, scenario, maxLintCycles, stream)
+	}
+	if err != nil || expected == "" {
+		return err
+	}
+	return printArtifactScore(os.Stdout, expected, outputDir)
+}
+
+// printArtifactScore prints the score of the resources generated in
+// outputDir against those in expected, and writes it to score.json in
+// outputDir.
+func printArtifactScore(w io.Writer, expected, outputDir string) error {
+	result, err := domain.ScoreArtifacts(expected, outputDir)
+	if err != nil {
+		return fmt.Errorf("scoring: %w", err)
+	}
+	rating, notes := result.Rating()
+	fmt.Fprintf(w, "\n%s", result)
+	fmt.Fprintf(w, "Completeness: %s (%s)\n", rating, notes)
+
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
 	}
-	return runTestAnthropic(prompt, outputDir, personaName, scenario, maxLintCycles, stream)
+	return atomicfile.WriteFile(filepath.Join(outputDir, "score.json"), append(data, '\n'), 0644)
 }
 
 // runTestAllPersonas runs the test with all available personas sequentially.
 // It aggregates results and reports which personas passed or failed.
-func runTestAllPersonas(prompt, outputDir, scenario string, maxLintCycles int, stream bool, provider string) error {
+func runTestAllPersonas(prompt, outputDir, scenario string, maxLintCycles int, stream bool, provider, expected string) error {
 	personaNames := personas.Names()
 	var failed []string
 
@@ -94,7 +133,7 @@ func runTestAllPersonas(prompt, outputDir, scenario string, maxLintCycles int, s
 
 		fmt.Printf("=== Running persona: %s ===\n", personaName)
 
-		err := runTestWithProvider(prompt, personaOutputDir, personaName, scenario, maxLintCycles, stream, provider)
+		err := runTestWithProvider(prompt, personaOutputDir, personaName, scenario, maxLintCycles, stream, provider, expected)
 		if err != nil {
 			fmt.Printf("Persona %s: FAILED - %v\n\n", personaName, err)
 			failed = append(failed, personaName)
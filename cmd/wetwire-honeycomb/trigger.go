@@ -0,0 +1,185 @@
+// Command trigger provides trigger analysis against historical Honeycomb data.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/backtest"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/honeycomb"
+	"github.com/spf13/cobra"
+)
+
+// newTriggerCmd creates the "trigger" command group.
+func newTriggerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trigger",
+		Short: "Analyze triggers against historical data",
+	}
+
+	cmd.AddCommand(newTriggerSimulateCmd())
+
+	return cmd
+}
+
+// newTriggerSimulateCmd creates the "trigger simulate" subcommand.
+func newTriggerSimulateCmd() *cobra.Command {
+	var days int
+
+	cmd := &cobra.Command{
+		Use:   "simulate <TriggerName> [path]",
+		Short: "Replay a trigger over historical data",
+		Long: `Replay a trigger's query against the Honeycomb Query Data API at its
+configured frequency and report how many times it would have fired.
+
+Use this to tune thresholds before enabling alerts. The trigger may be
+referenced by its Go variable name or its Name field.
+
+Requires HONEYCOMB_API_KEY (and optionally HONEYCOMB_API_URL).
+
+Example:
+    wetwire-honeycomb trigger simulate HighLatencyAlert --days 14 ./triggers`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := "."
+			if len(args) > 1 {
+				path = args[1]
+			}
+			format, _ := cmd.Flags().GetString("format")
+
+			client, err := honeycomb.NewClientFromEnv()
+			if err != nil {
+				return err
+			}
+
+			return runTriggerSimulate(cmd.Context(), client, args[0], path, days, format)
+		},
+	}
+
+	cmd.Flags().IntVar(&days, "days", 14, "Number of days of history to replay")
+
+	return cmd
+}
+
+// runTriggerSimulate discovers the named trigger, replays its query and prints the report.
+func runTriggerSimulate(ctx context.Context, runner backtest.QueryRunner, name, path string, days int, format string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if days <= 0 {
+		return fmt.Errorf("--days must be positive")
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("resolve path: %w", err)
+	}
+
+	resources, err := discovery.DiscoverAll(absPath)
+	if err != nil {
+		return fmt.Errorf("discovery failed: %w", err)
+	}
+
+	dt, err := findTrigger(resources, name)
+	if err != nil {
+		return err
+	}
+
+	dq := dt.InlineQuery
+	if dq == nil && dt.QueryRef != "" {
+		dq = findQuery(resources, dt.QueryRef)
+	}
+	if dq == nil {
+		return fmt.Errorf("trigger %s: could not resolve its query", dt.Name)
+	}
+	if dt.ThresholdOp == "" {
+		return fmt.Errorf("trigger %s: no threshold configured", dt.Name)
+	}
+
+	spec := backtest.TriggerSpec{
+		ThresholdOp:    dt.ThresholdOp,
+		ThresholdValue: dt.ThresholdValue,
+		Frequency:      time.Duration(dt.FrequencySeconds) * time.Second,
+		Duration:       time.Duration(dq.TimeRange.TimeRange) * time.Second,
+	}
+	if len(dq.Calculations) > 0 {
+		spec.Op = dq.Calculations[0].Op
+	}
+
+	dataset := dq.Dataset
+	if dataset == "" {
+		dataset = dt.Dataset
+	}
+
+	step := backtest.TriggerStep(spec)
+	end := time.Now().UTC().Truncate(step)
+	start := end.Add(-time.Duration(days) * 24 * time.Hour)
+
+	series, err := backtest.FetchGroupedSeries(ctx, runner, dataset, dq.Query(), start, end, step)
+	if err != nil {
+		return fmt.Errorf("trigger query: %w", err)
+	}
+
+	report := backtest.SimulateTrigger(series, spec, step)
+
+	if format == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	printTriggerSimulation(dt, spec, report, days)
+	return nil
+}
+
+// findTrigger looks up a discovered trigger by Go variable name or trigger name.
+func findTrigger(resources *discovery.DiscoveredResources, name string) (discovery.DiscoveredTrigger, error) {
+	for _, t := range resources.Triggers {
+		if t.Name == name || t.TriggerName == name {
+			return t, nil
+		}
+	}
+	return discovery.DiscoveredTrigger{}, fmt.Errorf("trigger %q not found", name)
+}
+
+// printTriggerSimulation prints a human-readable simulation report.
+func printTriggerSimulation(dt discovery.DiscoveredTrigger, spec backtest.TriggerSpec, report backtest.TriggerReport, days int) {
+	title := dt.TriggerName
+	if title == "" {
+		title = dt.Name
+	}
+
+	op := spec.Op
+	if op == "" {
+		op = "COUNT"
+	}
+
+	fmt.Printf("Trigger simulation: %s (%s)\n", title, dt.Name)
+	fmt.Printf("Window:      %s to %s (%d days)\n", report.Start.Format(time.RFC3339), report.End.Format(time.RFC3339), days)
+	fmt.Printf("Condition:   %s %s %g over %s, evaluated every %s\n",
+		op, report.ThresholdOp, report.ThresholdValue,
+		time.Duration(report.DurationSeconds)*time.Second, time.Duration(report.FrequencySeconds)*time.Second)
+	fmt.Printf("Evaluations: %d\n", report.Evaluations)
+
+	pct := 0.0
+	if report.Evaluations > 0 {
+		pct = float64(report.TriggeredEvaluations) / float64(report.Evaluations) * 100
+	}
+	fmt.Printf("Triggered:   %d evaluation(s) (%.1f%%)\n", report.TriggeredEvaluations, pct)
+	fmt.Printf("Fired:       %d time(s)\n", len(report.Firings))
+
+	for _, f := range report.Firings {
+		group := ""
+		if f.Group != "" {
+			group = fmt.Sprintf(" [%s]", f.Group)
+		}
+		fmt.Printf("  %s to %s%s (peak %.2f)\n", f.Start.Format(time.RFC3339), f.End.Format(time.RFC3339), group, f.Peak)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+const simulateSource = `package triggers
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var ErrorCount = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Minutes(10),
+	Calculations: []query.Calculation{query.Count()},
+}
+
+var HighErrors = trigger.Trigger{
+	Name:      "High Errors",
+	Dataset:   "api",
+	Query:     ErrorCount,
+	Threshold: trigger.GreaterThan(50),
+	Frequency: trigger.Minutes(5),
+}
+
+var InlineErrors = trigger.Trigger{
+	Name:    "Inline Errors",
+	Dataset: "api",
+	Query: query.Query{
+		Dataset:   "edge",
+		TimeRange: query.Minutes(5),
+	},
+	Threshold: trigger.GreaterThan(50),
+	Frequency: trigger.Minutes(5),
+}
+`
+
+func TestFindTrigger(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "triggers.go"), []byte(simulateSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	resources, err := discovery.DiscoverAll(dir)
+	if err != nil {
+		t.Fatalf("DiscoverAll failed: %v", err)
+	}
+
+	dt, err := findTrigger(resources, "High Errors")
+	if err != nil {
+		t.Fatalf("findTrigger failed: %v", err)
+	}
+	if dt.Name != "HighErrors" {
+		t.Errorf("findTrigger by display name returned %q", dt.Name)
+	}
+
+	if _, err := findTrigger(resources, "Missing"); err == nil {
+		t.Error("expected error for unknown trigger")
+	}
+}
+
+func TestRunTriggerSimulate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "triggers.go"), []byte(simulateSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := &constantRunner{value: 100}
+	if err := runTriggerSimulate(context.Background(), runner, "HighErrors", dir, 1, "json"); err != nil {
+		t.Fatalf("runTriggerSimulate failed: %v", err)
+	}
+	if len(runner.datasets) != 1 || runner.datasets[0] != "api" {
+		t.Errorf("queried datasets %v, want [api]", runner.datasets)
+	}
+
+	runner = &constantRunner{value: 100}
+	if err := runTriggerSimulate(context.Background(), runner, "InlineErrors", dir, 1, "text"); err != nil {
+		t.Fatalf("runTriggerSimulate with inline query failed: %v", err)
+	}
+	if len(runner.datasets) != 1 || runner.datasets[0] != "edge" {
+		t.Errorf("queried datasets %v, want [edge]", runner.datasets)
+	}
+
+	if err := runTriggerSimulate(context.Background(), runner, "HighErrors", dir, 0, "json"); err == nil {
+		t.Error("expected error for non-positive --days")
+	}
+}
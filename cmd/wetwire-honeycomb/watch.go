@@ -2,14 +2,22 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/atomicfile"
 	"github.com/lex00/wetwire-honeycomb-go/internal/builder"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
 	"github.com/lex00/wetwire-honeycomb-go/internal/serialize"
 	"github.com/spf13/cobra"
 )
@@ -18,16 +26,50 @@ func newWatchCmd() *cobra.Command {
 	var outputFile string
 	var interval int
 	var verbose bool
+	var applyChanges bool
+	var env string
 
 	cmd := &cobra.Command{
 		Use:   "watch [packages]",
 		Short: "Auto-rebuild on source file changes",
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Rebuild path whenever a Go file in it changes.
+
+With --apply, each successful rebuild is linted and, when lint reports no
+errors, applied to Honeycomb as by apply: resources that changed are
+updated, others are left alone. Use --env to apply to a named environment
+such as a staging one (HONEYCOMB_API_KEY_<ENV>) rather than the default
+(HONEYCOMB_API_KEY). Nothing is pruned.
+
+Example:
+    wetwire-honeycomb watch ./observability
+    wetwire-honeycomb watch --apply --env staging ./observability`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			path := "."
 			if len(args) > 0 {
 				path = args[0]
 			}
+			modeFlag, _ := cmd.Flags().GetString("output-mode")
+			mode, err := domain.OutputMode(modeFlag, outputFile)
+			if err != nil {
+				return err
+			}
+			if env != "" && !applyChanges {
+				return fmt.Errorf("--env requires --apply")
+			}
+
+			// Clients are created once, so a missing API key fails now
+			var targets []applyTarget
+			opts := applyOptions{concurrency: 4, rateLimit: 5, stderr: os.Stderr}
+			if applyChanges {
+				if env != "" {
+					opts.envs = []string{env}
+				}
+				if targets, err = applyTargets(opts); err != nil {
+					return err
+				}
+				cmd.SilenceUsage = true
+			}
 
 			fmt.Printf("Watching %s for changes (interval: %ds)\n", path, interval)
 			fmt.Println("Press Ctrl+C to stop")
@@ -54,6 +96,7 @@ func newWatchCmd() *cobra.Command {
 					}
 
 					// Build
+					discovery.ResetPanics()
 					b, err := builder.NewBuilder(path)
 					if err != nil {
 						fmt.Fprintf(os.Stderr, "  Error: %v\n", err)
@@ -66,47 +109,29 @@ func newWatchCmd() *cobra.Command {
 								fmt.Printf("  Found %d queries\n", result.QueryCount())
 							}
 
-							if result.QueryCount() > 0 && outputFile != "" {
-								// Write output
-								queries := result.Queries()
-								var jsonData []byte
-
-								if len(queries) == 1 {
-									q := discoveredToQuery(queries[0])
-									jsonData, err = serialize.ToJSONPretty(q)
-								} else {
-									queryMap := make(map[string]json.RawMessage)
-									for _, dq := range queries {
-										q := discoveredToQuery(dq)
-										data, e := serialize.ToJSON(q)
-										if e != nil {
-											err = e
-											break
-										}
-										queryMap[dq.Name] = data
-									}
-									if err == nil {
-										jsonData, err = json.MarshalIndent(queryMap, "", "  ")
-									}
-								}
-
-								if err != nil {
-									fmt.Fprintf(os.Stderr, "  Serialization failed: %v\n", err)
-								} else {
-									if err := os.WriteFile(outputFile, jsonData, 0644); err != nil {
-										fmt.Fprintf(os.Stderr, "  Failed to write output: %v\n", err)
-									} else {
-										fmt.Printf("  Wrote %s (%d bytes)\n", outputFile, len(jsonData))
-									}
+							// Without --output-mode, builds are only summarized
+							if result.QueryCount() > 0 && (outputFile != "" || modeFlag != "") {
+								if err := writeWatchOutput(os.Stdout, result.Queries(), outputFile, mode); err != nil {
+									fmt.Fprintf(os.Stderr, "  Failed to write output: %v\n", err)
 								}
 							} else if result.QueryCount() > 0 {
 								fmt.Printf("  Build succeeded (%d queries)\n", result.QueryCount())
 							} else {
 								fmt.Println("  No queries found")
 							}
+
+							if applyChanges {
+								if err := watchApply(cmd.Context(), os.Stdout, path, targets, opts); err != nil {
+									fmt.Fprintf(os.Stderr, "  Apply skipped: %v\n", err)
+								}
+							}
 						}
 					}
 
+					if err := reportPanics(cmd, os.Stderr); err != nil {
+						fmt.Fprintf(os.Stderr, "  Failed to write diagnostics bundle: %v\n", err)
+					}
+
 					lastModTime = currentModTime
 					lastHash = currentHash
 				}
@@ -116,13 +141,37 @@ func newWatchCmd() *cobra.Command {
 		},
 	}
 
-	cmd.Flags().StringVar(&outputFile, "output", "", "Output file")
+	cmd.Flags().StringVar(&outputFile, "output", "", "Output file, or directory with --output-mode dir")
 	cmd.Flags().IntVar(&interval, "interval", 2, "Polling interval in seconds")
 	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
+	cmd.Flags().BoolVar(&applyChanges, "apply", false, "Apply each build that passes lint to Honeycomb")
+	cmd.Flags().StringVar(&env, "env", "", "With --apply, apply to a named environment using HONEYCOMB_API_KEY_<ENV>")
+	addDebugBundleFlag(cmd)
 
 	return cmd
 }
 
+// watchApply lints path and, when lint reports no errors, applies it to the
+// targets; warnings and info findings do not hold the apply back. The lint
+// output is only written when lint fails.
+func watchApply(ctx context.Context, w io.Writer, path string, targets []applyTarget, opts applyOptions) error {
+	result, err := (&domain.HoneycombDomain{}).Linter().Lint(coredomain.NewContext(ctx, path), path, domain.LintOpts{Format: "text"})
+	if err != nil {
+		return fmt.Errorf("lint failed: %w", err)
+	}
+	if slices.ContainsFunc(result.Errors, func(e coredomain.Error) bool { return e.Severity == "error" }) {
+		report, _ := result.Data.(*domain.LintReport)
+		result.Data = nil
+		_ = writeResult(w, result, "text")
+		if report != nil {
+			printSecurityReport(w, report.Security)
+		}
+		return fmt.Errorf("lint failed")
+	}
+	fmt.Fprintln(w, "  Lint passed, applying")
+	return runApplyTargets(ctx, targets, path, opts)
+}
+
 func getDirectoryState(dir string) (time.Time, string, error) {
 	var latestTime time.Time
 	var fileList []string
@@ -160,3 +209,46 @@ func getDirectoryState(dir string) (time.Time, string, error) {
 	hash := strings.Join(fileList, "|")
 	return latestTime, hash, nil
 }
+
+// writeWatchOutput writes the built queries in the output mode: as JSON to w
+// or to outputFile (the query itself when there is one, otherwise keyed by
+// name), or to queries/<Name>.json files under the outputFile directory.
+func writeWatchOutput(w io.Writer, queries []discovery.DiscoveredQuery, outputFile, mode string) error {
+	queryMap := make(map[string]json.RawMessage)
+	for _, dq := range queries {
+		data, err := serialize.ToJSON(dq.Query())
+		if err != nil {
+			return fmt.Errorf("serialization failed: %w", err)
+		}
+		queryMap[dq.Name] = data
+	}
+
+	if mode == domain.OutputDir {
+		if err := domain.WriteOutputDir(outputFile, &honeycomb.Manifest{Queries: queryMap}, true); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "  Wrote %d queries to %s\n", len(queries), outputFile)
+		return nil
+	}
+
+	var jsonData []byte
+	var err error
+	if len(queries) == 1 {
+		jsonData, err = serialize.ToJSONPretty(queries[0].Query())
+	} else {
+		jsonData, err = json.MarshalIndent(queryMap, "", "  ")
+	}
+	if err != nil {
+		return fmt.Errorf("serialization failed: %w", err)
+	}
+
+	if mode == domain.OutputStdout {
+		_, err := fmt.Fprintln(w, string(jsonData))
+		return err
+	}
+	if err := atomicfile.WriteFile(outputFile, jsonData, 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "  Wrote %s (%d bytes)\n", outputFile, len(jsonData))
+	return nil
+}
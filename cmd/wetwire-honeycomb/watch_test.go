@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+func TestWriteWatchOutput(t *testing.T) {
+	queries := []discovery.DiscoveredQuery{
+		{Name: "Latency", Dataset: "api", Calculations: []discovery.Calculation{{Op: "P99", Column: "duration_ms"}}},
+		{Name: "Errors", Dataset: "api", Calculations: []discovery.Calculation{{Op: "COUNT"}}},
+	}
+	dir := t.TempDir()
+
+	var out bytes.Buffer
+	file := filepath.Join(dir, "out.json")
+	if err := writeWatchOutput(&out, queries, file, domain.OutputFile); err != nil {
+		t.Fatalf("writeWatchOutput failed: %v", err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil || !strings.Contains(string(data), `"Latency"`) || !strings.Contains(string(data), `"Errors"`) {
+		t.Errorf("expected the queries keyed by name in %s: %s %v", file, data, err)
+	}
+
+	out.Reset()
+	if err := writeWatchOutput(&out, queries, dir, domain.OutputDir); err != nil {
+		t.Fatalf("writeWatchOutput failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "queries", "Latency.json")); err != nil {
+		t.Errorf("expected a file per query: %v", err)
+	}
+	if !strings.Contains(out.String(), "Wrote 2 queries") {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	out.Reset()
+	if err := writeWatchOutput(&out, queries[:1], "", domain.OutputStdout); err != nil {
+		t.Fatalf("writeWatchOutput failed: %v", err)
+	}
+	if !strings.Contains(out.String(), `"op": "P99"`) {
+		t.Errorf("expected the query JSON on stdout, got %q", out.String())
+	}
+}
+
+func TestWatchApply(t *testing.T) {
+	source := `package triggers
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var ErrorCount = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Minutes(10),
+	Calculations: []query.Calculation{query.Count()},
+}
+
+var HighErrors = trigger.Trigger{
+	Name:       "High Errors",
+	Dataset:    "api",
+	Query:      ErrorCount,
+	Threshold:  trigger.GreaterThan(50),
+	Frequency:  trigger.Minutes(5),
+	AlertType:  ALERT_TYPE,
+	Recipients: []trigger.Recipient{trigger.SlackChannel("#alerts")},
+}
+`
+	dir := t.TempDir()
+	file := filepath.Join(dir, "triggers.go")
+	client := &recordingClient{}
+	targets := []applyTarget{{env: "staging", client: client}}
+
+	// A lint error skips the apply
+	if err := os.WriteFile(file, []byte(strings.Replace(source, "ALERT_TYPE", `"on_every_run"`, 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := watchApply(context.Background(), &out, dir, targets, applyOptions{}); err == nil {
+		t.Error("expected lint to fail")
+	}
+	if !strings.Contains(out.String(), "WHC051") {
+		t.Errorf("expected the lint error in the output, got %q", out.String())
+	}
+	if len(client.calls) != 0 {
+		t.Errorf("apply ran after a lint error: %v", client.calls)
+	}
+
+	if err := os.WriteFile(file, []byte(strings.Replace(source, "ALERT_TYPE", "trigger.OnTrue", 1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	out.Reset()
+	if err := watchApply(context.Background(), &out, dir, targets, applyOptions{}); err != nil {
+		t.Fatalf("watchApply failed: %v\n%s", err, out.String())
+	}
+	want := []string{"create queries", "create triggers"}
+	if fmt.Sprint(client.calls) != fmt.Sprint(want) {
+		t.Errorf("calls = %v, want %v", client.calls, want)
+	}
+}
+
+func TestWatchApply_Warnings(t *testing.T) {
+	// WHC004 (breakdowns without an order) is a warning, which does not hold
+	// the apply back
+	source := `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var ErrorsByService = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Minutes(10),
+	Breakdowns:   []string{"service"},
+	Calculations: []query.Calculation{query.Count()},
+}
+`
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	client := &recordingClient{}
+	targets := []applyTarget{{env: "staging", client: client}}
+
+	var out bytes.Buffer
+	if err := watchApply(context.Background(), &out, dir, targets, applyOptions{}); err != nil {
+		t.Fatalf("watchApply failed: %v\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "Lint passed, applying") {
+		t.Errorf("expected the apply to start, got %q", out.String())
+	}
+	want := []string{"create queries"}
+	if fmt.Sprint(client.calls) != fmt.Sprint(want) {
+		t.Errorf("calls = %v, want %v", client.calls, want)
+	}
+}
@@ -0,0 +1,108 @@
+// Package dataset provides type-safe declarations of Honeycomb dataset schemas.
+//
+// By convention, a package declares the columns of the datasets its queries
+// use in a schema.go file:
+//
+//	var Columns = dataset.Schema{
+//		Dataset: "production",
+//		Columns: []dataset.Column{
+//			dataset.String("service.name"),
+//			dataset.Float("duration_ms"),
+//			dataset.Integer("http.status_code"),
+//		},
+//	}
+//
+// Discovery picks up every dataset.Schema variable, and lint uses them to
+// check query column names and types without access to the Honeycomb API.
+// RetentionDays and EventsPerDay describe the dataset for the advise command,
+// and a column's Cardinality tells lint which columns are too varied to
+// break down by.
+package dataset
+
+// ColumnType is the type of a Honeycomb column.
+type ColumnType string
+
+// Column types supported by Honeycomb.
+const (
+	TypeString  ColumnType = "string"
+	TypeInteger ColumnType = "integer"
+	TypeFloat   ColumnType = "float"
+	TypeBoolean ColumnType = "boolean"
+)
+
+// Numeric reports whether the type holds numbers.
+func (t ColumnType) Numeric() bool {
+	return t == TypeInteger || t == TypeFloat
+}
+
+// Schema declares the columns of a dataset.
+type Schema struct {
+	// Dataset is the name of the Honeycomb dataset
+	Dataset string
+
+	// Columns are the dataset's known columns
+	Columns []Column
+
+	// RetentionDays is how long the dataset keeps events, when it differs
+	// from the team's retention. The advise command checks query time ranges
+	// against it.
+	RetentionDays int
+
+	// EventsPerDay is the dataset's approximate ingest volume, used by the
+	// advise command to estimate how many events queries scan
+	EventsPerDay int64
+}
+
+// Column declares a single column of a dataset.
+type Column struct {
+	// Name is the column name (e.g. "duration_ms")
+	Name string
+
+	// Type is the column type
+	Type ColumnType
+
+	// Description documents the column
+	Description string
+
+	// Cardinality is the approximate number of distinct values of the
+	// column, when known. Lint warns about breakdowns on columns with
+	// thousands of them (WHC005).
+	Cardinality int64
+}
+
+// Column returns the column with the given name.
+func (s Schema) Column(name string) (Column, bool) {
+	for _, c := range s.Columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Column{}, false
+}
+
+// WithCardinality returns c with its approximate number of distinct values
+// set, e.g. dataset.String("user.id").WithCardinality(2_000_000).
+func (c Column) WithCardinality(n int64) Column {
+	c.Cardinality = n
+	return c
+}
+
+// String declares a string column.
+func String(name string) Column {
+	return Column{Name: name, Type: TypeString}
+}
+
+// Integer declares an integer column.
+func Integer(name string) Column {
+	return Column{Name: name, Type: TypeInteger}
+}
+
+// Float declares a float column.
+func Float(name string) Column {
+	return Column{Name: name, Type: TypeFloat}
+}
+
+// Boolean declares a boolean column.
+func Boolean(name string) Column {
+	return Column{Name: name, Type: TypeBoolean}
+}
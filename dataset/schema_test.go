@@ -0,0 +1,39 @@
+package dataset
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnConstructors(t *testing.T) {
+	assert.Equal(t, Column{Name: "service.name", Type: TypeString}, String("service.name"))
+	assert.Equal(t, Column{Name: "http.status_code", Type: TypeInteger}, Integer("http.status_code"))
+	assert.Equal(t, Column{Name: "duration_ms", Type: TypeFloat}, Float("duration_ms"))
+	assert.Equal(t, Column{Name: "error", Type: TypeBoolean}, Boolean("error"))
+}
+
+func TestColumnWithCardinality(t *testing.T) {
+	assert.Equal(t, Column{Name: "user.id", Type: TypeString, Cardinality: 1000}, String("user.id").WithCardinality(1000))
+}
+
+func TestSchemaColumn(t *testing.T) {
+	s := Schema{
+		Dataset: "production",
+		Columns: []Column{String("service.name"), Float("duration_ms")},
+	}
+
+	c, ok := s.Column("duration_ms")
+	assert.True(t, ok)
+	assert.Equal(t, TypeFloat, c.Type)
+
+	_, ok = s.Column("missing")
+	assert.False(t, ok)
+}
+
+func TestColumnTypeNumeric(t *testing.T) {
+	assert.True(t, TypeInteger.Numeric())
+	assert.True(t, TypeFloat.Numeric())
+	assert.False(t, TypeString.Numeric())
+	assert.False(t, TypeBoolean.Numeric())
+}
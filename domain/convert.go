@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/annotate"
+	"github.com/lex00/wetwire-honeycomb-go/internal/convert"
+	"github.com/lex00/wetwire-honeycomb-go/internal/i18n"
+)
+
+// ConvertOpts are the options of the imports converting another tool's
+// dashboards and alerts, ImportDatadog and ImportGrafana.
+type ConvertOpts struct {
+	// Dataset is the dataset every query uses; when empty, each query uses
+	// its service tag or label
+	Dataset string
+
+	// Target is a .go file, or a directory the file is created in, to write
+	// the code to; when empty the code is returned as the result data
+	Target string
+}
+
+// importConversion generates the Go declarations of a conversion from
+// vendor's format. The file written to a target directory is named after
+// source, or stem for standard input. The conversion notes are returned as
+// warnings, the conversion report.
+func importConversion(source, vendor, stem string, conv *convert.Conversion, target string) (*Result, error) {
+	warnings := make([]Error, 0, len(conv.Notes))
+	for _, n := range conv.Notes {
+		warnings = append(warnings, Error{Path: sourceName(source), Severity: "warning", Message: n.String()})
+	}
+
+	if len(conv.Queries) == 0 {
+		result := NewErrorResult(i18n.T("no queries found"), Error{
+			Path:    sourceName(source),
+			Message: i18n.T("no panel or alert could be converted"),
+		})
+		result.Errors = append(result.Errors, warnings...)
+		return result, nil
+	}
+
+	generate := func(pkg string) ([]byte, error) {
+		return generateConvertedFile(pkg, vendor, conv)
+	}
+
+	boards := 0
+	if conv.Board != nil {
+		boards = 1
+	}
+	message := i18n.Sprintf("Imported %d queries, %d boards and %d triggers from %s", len(conv.Queries), boards, len(conv.Triggers), vendor)
+	if len(conv.Notes) > 0 {
+		message += " " + i18n.Sprintf("(%d features not converted exactly)", len(conv.Notes))
+	}
+
+	var result *Result
+	if target == "" {
+		code, err := generate("queries")
+		if err != nil {
+			return nil, err
+		}
+		result = NewResultWithData(message, string(code))
+	} else {
+		if source != StdinPath {
+			stem = strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+		}
+		file, err := writeGenerated(target, stem+".go", generate)
+		if err != nil {
+			return NewErrorResult(i18n.T("import failed"), Error{Path: target, Message: err.Error()}), nil
+		}
+		result = NewResult(i18n.Sprintf("%s to %s", message, file))
+	}
+	result.Errors = warnings
+	return result, nil
+}
+
+// generateConvertedFile generates a gofmt-formatted Go file declaring the
+// queries, board and triggers of a conversion from vendor's format.
+func generateConvertedFile(pkg, vendor string, conv *convert.Conversion) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "package %s\n\nimport (\n", pkg)
+	if conv.Board != nil {
+		b.WriteString("\"github.com/lex00/wetwire-honeycomb-go/board\"\n")
+	}
+	b.WriteString("\"github.com/lex00/wetwire-honeycomb-go/query\"\n")
+	if len(conv.Triggers) > 0 {
+		b.WriteString("\"github.com/lex00/wetwire-honeycomb-go/trigger\"\n")
+	}
+	b.WriteString(")\n")
+
+	for _, q := range conv.Queries {
+		doc := fmt.Sprintf("%s was converted from the %s query\n%s", q.Name, vendor, q.Source)
+		for _, todo := range q.TODO {
+			doc += "\nTODO: " + todo
+		}
+		writeQuery(&b, importedQuery{Name: q.Name, Doc: doc, Query: q.Query})
+	}
+
+	if bd := conv.Board; bd != nil {
+		fmt.Fprintf(&b, "\n// %s was converted from the %s dashboard %q.\n", bd.Name, vendor, bd.Title)
+		fmt.Fprintf(&b, "var %s = board.Board{\nName: %q,\n", bd.Name, bd.Title)
+		if d := annotate.Strip(bd.Description); d != "" {
+			fmt.Fprintf(&b, "Description: %q,\n", d)
+		}
+		b.WriteString("Panels: []board.Panel{\n")
+		writeConvertedPanels(&b, bd.Panels)
+		b.WriteString("},\n}\n")
+	}
+
+	for _, t := range conv.Triggers {
+		fmt.Fprintf(&b, "\n// %s was converted from the %s monitor %q.\n", t.Name, vendor, t.Title)
+		fmt.Fprintf(&b, "var %s = trigger.Trigger{\nName: %q,\n", t.Name, t.Title)
+		if d := annotate.Strip(t.Description); d != "" {
+			fmt.Fprintf(&b, "Description: %q,\n", d)
+		}
+		if t.Dataset != "" {
+			fmt.Fprintf(&b, "Dataset: %q,\n", t.Dataset)
+		} else {
+			b.WriteString("Dataset: \"production\", // TODO: Change to your dataset\n")
+		}
+		fmt.Fprintf(&b, "Query: %s,\n", t.Query)
+		fmt.Fprintf(&b, "Threshold: trigger.%s(%g),\n", thresholdFuncs[string(t.Threshold.Op)], t.Threshold.Value)
+		fmt.Fprintf(&b, "Frequency: trigger.Minutes(%d),\n", t.Frequency/60)
+		if len(t.Recipients) > 0 {
+			b.WriteString("Recipients: []trigger.Recipient{\n")
+			for _, r := range t.Recipients {
+				fmt.Fprintf(&b, "trigger.%s(%q),\n", recipientFuncs[string(r.Type)], r.Target)
+			}
+			b.WriteString("},\n")
+		}
+		if len(t.Tags) > 0 {
+			b.WriteString("Tags: []trigger.Tag{\n")
+			for _, tag := range t.Tags {
+				fmt.Fprintf(&b, "{Key: %q, Value: %q},\n", tag.Key, tag.Value)
+			}
+			b.WriteString("},\n")
+		}
+		b.WriteString("}\n")
+	}
+
+	code, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return code, nil
+}
+
+// writeConvertedPanels writes converted panels as board panel expressions.
+func writeConvertedPanels(b *bytes.Buffer, panels []convert.Panel) {
+	for _, p := range panels {
+		var title string
+		if p.Title != "" {
+			title = fmt.Sprintf(", board.WithTitle(%q)", p.Title)
+		}
+		switch {
+		case p.Query != "":
+			fmt.Fprintf(b, "board.QueryPanel(%s%s),\n", p.Query, title)
+		case p.Panels != nil:
+			fmt.Fprintf(b, "board.Section(%q,\n", p.Title)
+			writeConvertedPanels(b, p.Panels)
+			b.WriteString("),\n")
+		default:
+			fmt.Fprintf(b, "board.TextPanel(%q%s),\n", p.Text, title)
+		}
+	}
+}
+
+// thresholdFuncs maps threshold operators to trigger constructors.
+var thresholdFuncs = map[string]string{
+	">":  "GreaterThan",
+	">=": "GreaterThanOrEqual",
+	"<":  "LessThan",
+	"<=": "LessThanOrEqual",
+}
+
+// recipientFuncs maps recipient types to trigger constructors.
+var recipientFuncs = map[string]string{
+	"slack":     "SlackChannel",
+	"pagerduty": "PagerDutyService",
+	"email":     "EmailAddress",
+	"webhook":   "WebhookURL",
+}
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/internal/datadog"
+	"github.com/lex00/wetwire-honeycomb-go/internal/i18n"
+)
+
+// ImportFromDatadog is the import source format of Datadog dashboard and
+// monitor exports.
+const ImportFromDatadog = "datadog"
+
+// ImportDatadog converts a Datadog dashboard export, a monitor export or a
+// JSON array of monitors, read from source (StdinPath for standard input),
+// to Go declarations of queries, a board and triggers. What could not be
+// converted is returned as warnings, the conversion report.
+func ImportDatadog(source string, opts ConvertOpts) (*Result, error) {
+	data, err := readSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	conv, err := datadog.Convert(data, datadog.Options{Dataset: opts.Dataset})
+	if err != nil {
+		return NewErrorResult(i18n.T("import failed"), Error{Path: sourceName(source), Message: err.Error()}), nil
+	}
+	return importConversion(source, "Datadog", "datadog", conv, opts.Target)
+}
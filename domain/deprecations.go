@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"sort"
+
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/apiversion"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lock"
+	"github.com/lex00/wetwire-honeycomb-go/internal/serialize"
+)
+
+// deprecationErrors returns a warning, or with opts.StrictAPI an error, for
+// each field of the build output deprecated by the Honeycomb API, located at
+// the declaration of its resource. Output for another API variant (see
+// opts.APIVersion) targets older consumers on purpose and is not checked.
+func deprecationErrors(resources *discovery.DiscoveredResources, output *honeycomb.Manifest, opts Options) ([]Error, error) {
+	if version, _ := apiversion.Lookup(opts.APIVersion); version.Name != apiversion.Latest {
+		return nil, nil
+	}
+
+	type location struct {
+		file string
+		line int
+	}
+	locations := make(map[string]location)
+	for _, q := range resources.Queries {
+		locations[lock.Key("queries", q.Name)] = location{q.File, q.Line}
+	}
+	for _, b := range resources.Boards {
+		locations[lock.Key("boards", b.Name)] = location{b.File, b.Line}
+	}
+	for _, s := range resources.SLOs {
+		locations[lock.Key("slos", s.Name)] = location{s.File, s.Line}
+	}
+	for _, t := range resources.Triggers {
+		locations[lock.Key("triggers", t.Name)] = location{t.File, t.Line}
+	}
+
+	severity := "warning"
+	if opts.StrictAPI {
+		severity = "error"
+	}
+	var errs []Error
+	for _, section := range []string{"queries", "boards", "slos", "triggers"} {
+		bodies := output.Section(section)
+		names := make([]string, 0, len(bodies))
+		for name := range bodies {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			found, err := serialize.FindDeprecated(section, bodies[name])
+			if err != nil {
+				return nil, err
+			}
+			key := lock.Key(section, name)
+			for _, d := range found {
+				errs = append(errs, Error{
+					Path:     locations[key].file,
+					Line:     locations[key].line,
+					Severity: severity,
+					Message:  key + ": " + d.String(),
+				})
+			}
+		}
+	}
+	return errs, nil
+}
+
+// withWarnings adds warnings to the errors of result, which they do not fail.
+func withWarnings(result *Result, warnings []Error) *Result {
+	result.Errors = append(result.Errors, warnings...)
+	return result
+}
@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/serialize"
+)
+
+func TestBuild_DeprecatedFields(t *testing.T) {
+	// The table has no entries yet; check the build against one
+	saved := serialize.Deprecations
+	serialize.Deprecations = []serialize.Deprecation{{
+		Field:   "calculations[].op",
+		Applies: func(c map[string]any) bool { return c["op"] == "COUNTDISTINCT" },
+		Use:     "COUNT_DISTINCT",
+		URL:     "https://example.com/calculations",
+		Version: 1,
+	}}
+	t.Cleanup(func() { serialize.Deprecations = saved })
+
+	dir := t.TempDir()
+	content := `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Users = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(1),
+	Calculations: []query.Calculation{{Op: "COUNTDISTINCT", Column: "user.id"}},
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		strict      bool
+		wantSuccess bool
+		wantErrors  []string
+	}{
+		{"warning", false, true, []string{"warning"}},
+		{"strict", true, false, []string{"error"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &HoneycombDomain{Options: Options{StrictAPI: tt.strict}}
+			result, err := d.Builder().Build(&coredomain.Context{}, dir, BuildOpts{DryRun: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("expected success %v, got %+v", tt.wantSuccess, result)
+			}
+			var severities []string
+			for _, e := range result.Errors {
+				severities = append(severities, e.Severity)
+				if e.Line != 5 || !strings.Contains(e.Message, `queries/Users: calculations[0].op "COUNTDISTINCT" is deprecated`) {
+					t.Errorf("unexpected error %+v", e)
+				}
+			}
+			if strings.Join(severities, ",") != strings.Join(tt.wantErrors, ",") {
+				t.Errorf("expected %v, got %v", tt.wantErrors, result.Errors)
+			}
+		})
+	}
+}
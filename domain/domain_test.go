@@ -1,12 +1,26 @@
 package domain
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/i18n"
+	"github.com/lex00/wetwire-honeycomb-go/resource"
 )
 
+// TestMain runs the tests in English whatever the locale, since they check
+// result messages.
+func TestMain(m *testing.M) {
+	i18n.SetLanguage(i18n.English)
+	os.Exit(m.Run())
+}
+
 func TestHoneycombDomainImplementsInterface(t *testing.T) {
 	// Compile-time check that HoneycombDomain implements Domain
 	var _ coredomain.Domain = (*HoneycombDomain)(nil)
@@ -290,3 +304,1121 @@ var TestQuery = query.Query{
 		t.Fatal("Expected non-nil result")
 	}
 }
+
+func TestBuilderBuild_WritesLockfile(t *testing.T) {
+	d := &HoneycombDomain{}
+	builder := d.Builder()
+
+	tmpDir := t.TempDir()
+	queryContent := `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var TestQuery = query.Query{
+	Dataset:   "production",
+	TimeRange: query.Hours(1),
+}
+`
+	if err := os.WriteFile(tmpDir+"/queries.go", []byte(queryContent), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	ctx := &coredomain.Context{}
+	lockPath := tmpDir + "/wetwire.lock"
+
+	// Builds leave no lockfile behind unless asked to
+	if _, err := builder.Build(ctx, tmpDir, BuildOpts{Output: t.TempDir() + "/out.json"}); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Error("expected no lockfile without --write-lock")
+	}
+
+	d.Options.WriteLock = true
+	if _, err := builder.Build(ctx, tmpDir, BuildOpts{DryRun: true}); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Error("expected no lockfile after a dry run")
+	}
+
+	if _, err := builder.Build(ctx, tmpDir, BuildOpts{}); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		t.Fatalf("expected lockfile: %v", err)
+	}
+	if !strings.Contains(string(data), `"queries/TestQuery"`) {
+		t.Errorf("lockfile missing query entry:\n%s", data)
+	}
+}
+
+func TestBuilderBuild_TimeRanges(t *testing.T) {
+	builder := (&HoneycombDomain{}).Builder()
+	ctx := &coredomain.Context{}
+
+	tmpDir := t.TempDir()
+	valid := `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var BlackFriday = query.Query{
+	Dataset:   "production",
+	TimeRange: query.AbsoluteIn("America/New_York", "2024-11-29 00:00", "2024-11-30 00:00"),
+}
+`
+	if err := os.WriteFile(tmpDir+"/queries.go", []byte(valid), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	result, err := builder.Build(ctx, tmpDir, BuildOpts{DryRun: true})
+	if err != nil || !result.Success {
+		t.Fatalf("Build failed: %v %+v", err, result)
+	}
+	// Midnight in New York is 05:00 UTC
+	if !strings.Contains(result.Data.(string), `"start_time":1732856400`) {
+		t.Errorf("expected converted start_time, got %s", result.Data)
+	}
+
+	invalid := strings.Replace(valid, `"2024-11-30 00:00"`, `"2024-11-28 00:00"`, 1)
+	if err := os.WriteFile(tmpDir+"/queries.go", []byte(invalid), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	result, err = builder.Build(ctx, tmpDir, BuildOpts{DryRun: true})
+	if err != nil {
+		t.Fatalf("Build returned error: %v", err)
+	}
+	if result.Success || len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Message, "not before") {
+		t.Errorf("expected a time range error, got %+v", result)
+	}
+}
+
+func TestBuildAndLint_DefaultLimits(t *testing.T) {
+	d := &HoneycombDomain{}
+	ctx := &coredomain.Context{}
+
+	tmpDir := t.TempDir()
+	content := `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var ByEndpoint = query.Query{
+	Dataset:      "production",
+	TimeRange:    query.Hours(1),
+	Breakdowns:   []string{"endpoint"},
+	Calculations: []query.Calculation{query.Count()},
+}
+`
+	if err := os.WriteFile(tmpDir+"/queries.go", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	result, err := d.Linter().Lint(ctx, tmpDir, LintOpts{})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if !hasErrorCode(result, "WHC008") {
+		t.Errorf("expected WHC008 without a default limit, got %+v", result.Errors)
+	}
+
+	config := "datasets:\n  production:\n    limit: 100\n"
+	if err := os.WriteFile(tmpDir+"/.wetwire-honeycomb.yaml", []byte(config), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	result, err = d.Linter().Lint(ctx, tmpDir, LintOpts{})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if hasErrorCode(result, "WHC008") {
+		t.Errorf("unexpected WHC008 with a default limit: %+v", result.Errors)
+	}
+
+	result, err = d.Builder().Build(ctx, tmpDir, BuildOpts{DryRun: true})
+	if err != nil || !result.Success {
+		t.Fatalf("Build failed: %v %+v", err, result)
+	}
+	if !strings.Contains(result.Data.(string), `"limit":100`) {
+		t.Errorf("expected the default limit in build output, got %s", result.Data)
+	}
+}
+
+func TestBuilderBuild_TopN(t *testing.T) {
+	d := &HoneycombDomain{}
+	ctx := &coredomain.Context{}
+
+	tmpDir := t.TempDir()
+	content := `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var SlowRoutes = query.Query{
+	Dataset:    "production",
+	TimeRange:  query.Hours(1),
+	Breakdowns: []string{"http.route"},
+	Top:        query.TopN(10, query.P99("duration_ms")),
+}
+`
+	if err := os.WriteFile(tmpDir+"/queries.go", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	result, err := d.Builder().Build(ctx, tmpDir, BuildOpts{DryRun: true})
+	if err != nil || !result.Success {
+		t.Fatalf("Build failed: %v %+v", err, result)
+	}
+	want := `"calculations":[{"op":"P99","column":"duration_ms"}],"orders":[{"column":"duration_ms","op":"P99","order":"descending"}],"limit":10`
+	if !strings.Contains(result.Data.(string), want) {
+		t.Errorf("expected the top-N calculation, order and limit in build output, got %s", result.Data)
+	}
+
+	result, err = d.Linter().Lint(ctx, tmpDir, LintOpts{})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	for _, code := range []string{"WHC004", "WHC008", "WHC027"} {
+		if hasErrorCode(result, code) {
+			t.Errorf("unexpected %s for a top-N query: %+v", code, result.Errors)
+		}
+	}
+}
+
+func TestLinterLint_RetentionDaysEnv(t *testing.T) {
+	linter := (&HoneycombDomain{}).Linter()
+	ctx := &coredomain.Context{}
+
+	tmpDir := t.TempDir()
+	content := `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Old = query.Query{
+	Dataset:   "production",
+	TimeRange: query.AbsoluteIn("UTC", "2020-01-01", "2020-01-02"),
+}
+`
+	if err := os.WriteFile(tmpDir+"/queries.go", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	t.Setenv(EnvRetentionDays, "")
+	result, err := linter.Lint(ctx, tmpDir, LintOpts{})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if !hasErrorCode(result, "WHC016") {
+		t.Errorf("expected WHC016 for a range older than the retention, got %+v", result.Errors)
+	}
+
+	t.Setenv(EnvRetentionDays, "100000")
+	result, err = linter.Lint(ctx, tmpDir, LintOpts{})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if hasErrorCode(result, "WHC016") {
+		t.Errorf("unexpected WHC016 with a long retention: %+v", result.Errors)
+	}
+
+	t.Setenv(EnvRetentionDays, "forever")
+	if _, err := linter.Lint(ctx, tmpDir, LintOpts{}); err == nil {
+		t.Error("expected error for invalid retention")
+	}
+}
+
+func TestLinterLint_AllowOrphans(t *testing.T) {
+	d := &HoneycombDomain{}
+	linter := d.Linter()
+	ctx := &coredomain.Context{}
+
+	tmpDir := t.TempDir()
+	content := `package monitoring
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+var AdhocLatency = query.Query{
+	Dataset:      "production",
+	TimeRange:    query.Hours(1),
+	Calculations: []query.Calculation{query.P99("duration_ms")},
+}
+
+var Overview = board.Board{
+	Name: "Overview",
+}
+`
+	if err := os.WriteFile(tmpDir+"/monitoring.go", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	d.Options.AllowOrphans = nil
+	result, err := linter.Lint(ctx, tmpDir, LintOpts{})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if !hasErrorCode(result, "WHC026") {
+		t.Errorf("expected WHC026 for a query no board uses, got %+v", result.Errors)
+	}
+
+	d.Options.AllowOrphans = []string{"Other", "Adhoc*"}
+	result, err = linter.Lint(ctx, tmpDir, LintOpts{})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if hasErrorCode(result, "WHC026") {
+		t.Errorf("unexpected WHC026 for an allowed query: %+v", result.Errors)
+	}
+}
+
+func TestLinterLint_Debug(t *testing.T) {
+	d := &HoneycombDomain{}
+	linter := d.Linter()
+	ctx := &coredomain.Context{}
+
+	tmpDir := t.TempDir()
+	content := `package monitoring
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Latency = query.Query{
+	Dataset:      "production",
+	TimeRange:    query.Hours(1),
+	Calculations: []query.Calculation{query.P99("duration_ms"), query.Heatmap("duration_ms")},
+}
+`
+	if err := os.WriteFile(tmpDir+"/monitoring.go", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	result, err := linter.Lint(ctx, tmpDir, LintOpts{})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if result.Data != nil {
+		t.Errorf("expected no data without Debug, got %+v", result.Data)
+	}
+
+	d.Options.Debug = true
+	result, err = linter.Lint(ctx, tmpDir, LintOpts{})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	report, ok := result.Data.(*LintReport)
+	if !result.Success || !ok || len(report.Timings) == 0 {
+		t.Fatalf("expected rule timings in a successful result, got %+v", result)
+	}
+	if report.Security != nil {
+		t.Errorf("expected no security report, got %+v", report.Security)
+	}
+}
+
+func TestLinterLint_SecurityReport(t *testing.T) {
+	linter := (&HoneycombDomain{}).Linter()
+	ctx := &coredomain.Context{}
+
+	tmpDir := t.TempDir()
+	content := `package monitoring
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+var Leaky = query.Query{
+	Dataset:      "production",
+	TimeRange:    query.Hours(1),
+	Filters:      []query.Filter{query.Equals("auth", "token-abc")},
+	Calculations: []query.Calculation{query.Count()},
+}
+
+var Overview = board.Board{
+	Name:        "Overview",
+	Description: "Shared at https://ui.example.com/board?token=f00dbabe",
+}
+`
+	if err := os.WriteFile(tmpDir+"/monitoring.go", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	result, err := linter.Lint(ctx, tmpDir, LintOpts{})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	report, ok := result.Data.(*LintReport)
+	if !ok || report.Security == nil {
+		t.Fatalf("expected a security report, got %#v", result.Data)
+	}
+	if report.Security.ByRule["WHC012"] != 1 || report.Security.ByRule["WHC035"] != 1 {
+		t.Errorf("unexpected security counts: %v", report.Security.ByRule)
+	}
+	if report.Security.Findings != len(report.Security.Issues) {
+		t.Errorf("findings %d does not match %d issues", report.Security.Findings, len(report.Security.Issues))
+	}
+
+	result, err = linter.Lint(ctx, tmpDir, LintOpts{Disable: []string{"WHC012", "WHC035"}})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if result.Data != nil {
+		t.Errorf("expected no security report with the rules disabled, got %#v", result.Data)
+	}
+}
+
+// hasErrorCode reports whether result contains an error with the given code.
+func hasErrorCode(result *Result, code string) bool {
+	for _, e := range result.Errors {
+		if e.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBuilderBuild_TriggerThreshold(t *testing.T) {
+	builder := (&HoneycombDomain{}).Builder()
+
+	tmpDir := t.TempDir()
+	content := `package triggers
+
+import "github.com/lex00/wetwire-honeycomb-go/trigger"
+
+var LowThroughput = trigger.Trigger{
+	Name:      "Low Throughput",
+	Dataset:   "production",
+	Threshold: trigger.LessThanOrEqual(10),
+	Frequency: trigger.Minutes(5),
+}
+`
+	if err := os.WriteFile(tmpDir+"/triggers.go", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	result, err := builder.Build(&coredomain.Context{}, tmpDir, BuildOpts{DryRun: true})
+	if err != nil || !result.Success {
+		t.Fatalf("Build failed: %v %+v", err, result)
+	}
+
+	var out struct {
+		Triggers map[string]struct {
+			Threshold struct {
+				Op    string  `json:"op"`
+				Value float64 `json:"value"`
+			} `json:"threshold"`
+		} `json:"triggers"`
+	}
+	if err := json.Unmarshal([]byte(result.Data.(string)), &out); err != nil {
+		t.Fatalf("Failed to parse build output: %v", err)
+	}
+	got := out.Triggers["LowThroughput"].Threshold
+	if got.Op != "<=" || got.Value != 10 {
+		t.Errorf("expected threshold <= 10, got %+v in %s", got, result.Data)
+	}
+}
+
+func TestBuilderBuild_Grafana(t *testing.T) {
+	builder := (&HoneycombDomain{}).Builder()
+
+	tmpDir := t.TempDir()
+	content := `package boards
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+var Overview = board.Board{
+	Name: "Overview",
+	Panels: []board.Panel{
+		board.QueryPanel(Missing),
+		board.TextPanel("## Notes"),
+	},
+}
+
+var Unused = query.Query{Dataset: "api"}
+`
+	if err := os.WriteFile(tmpDir+"/boards.go", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	result, err := builder.Build(&coredomain.Context{}, tmpDir, BuildOpts{Format: FormatGrafana, DryRun: true})
+	if err != nil || !result.Success {
+		t.Fatalf("Build failed: %v %+v", err, result)
+	}
+
+	var out grafanaOutput
+	if err := json.Unmarshal([]byte(result.Data.(string)), &out); err != nil {
+		t.Fatalf("Failed to parse build output: %v", err)
+	}
+	if got := len(out.Dashboards["Overview"].Panels); got != 1 {
+		t.Errorf("expected the unresolved query panel to be skipped, got %d panels", got)
+	}
+	if len(out.MappingReport) != 1 || !strings.Contains(out.MappingReport[0].Message, `"Missing"`) {
+		t.Errorf("expected a note for the unresolved query, got %+v", out.MappingReport)
+	}
+
+	// Without boards there is nothing to export
+	queriesOnly := t.TempDir()
+	if err := os.WriteFile(queriesOnly+"/queries.go", []byte("package q\n\nimport \"github.com/lex00/wetwire-honeycomb-go/query\"\n\nvar Q = query.Query{Dataset: \"api\"}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	result, err = builder.Build(&coredomain.Context{}, queriesOnly, BuildOpts{Format: FormatGrafana, DryRun: true})
+	if err != nil || result.Success {
+		t.Errorf("expected failure without boards, got %v %+v", err, result)
+	}
+}
+
+func TestSerializeResources_Normalize(t *testing.T) {
+	resources := &discovery.DiscoveredResources{
+		Queries: []discovery.DiscoveredQuery{{
+			Name:         "Latency",
+			Breakdowns:   []string{"service", "endpoint"},
+			Calculations: []discovery.Calculation{{Op: "COUNT"}},
+		}},
+	}
+
+	output, err := SerializeResources(resources, "", Options{})
+	if err != nil {
+		t.Fatalf("SerializeResources failed: %v", err)
+	}
+	if !strings.Contains(string(output.Queries["Latency"]), `["service","endpoint"]`) {
+		t.Errorf("expected breakdowns in declaration order, got %s", output.Queries["Latency"])
+	}
+
+	output, err = SerializeResources(resources, "", Options{Normalize: true})
+	if err != nil {
+		t.Fatalf("SerializeResources failed: %v", err)
+	}
+	if !strings.Contains(string(output.Queries["Latency"]), `["endpoint","service"]`) {
+		t.Errorf("expected sorted breakdowns, got %s", output.Queries["Latency"])
+	}
+}
+
+func TestSerializeResources_Describe(t *testing.T) {
+	resources := &discovery.DiscoveredResources{
+		Queries: []discovery.DiscoveredQuery{{
+			Name:         "Errors",
+			Dataset:      "api",
+			TimeRange:    discovery.TimeRange{TimeRange: 600},
+			Calculations: []discovery.Calculation{{Op: "COUNT"}},
+		}},
+		Triggers: []discovery.DiscoveredTrigger{
+			{Name: "HighErrors", TriggerName: "High Errors", Dataset: "api", QueryRef: "Errors", ThresholdOp: ">", ThresholdValue: 50, FrequencySeconds: 300},
+			{Name: "Documented", TriggerName: "Documented", Description: "Written by hand", Dataset: "api", QueryRef: "Errors", ThresholdOp: ">", ThresholdValue: 5},
+		},
+		SLOs: []discovery.DiscoveredSLO{
+			{Name: "Availability", SLOName: "Availability", Dataset: "api", TargetPercentage: 99.9, TimePeriodDays: 30},
+		},
+	}
+
+	output, err := SerializeResources(resources, "", Options{})
+	if err != nil {
+		t.Fatalf("SerializeResources failed: %v", err)
+	}
+	if strings.Contains(string(output.Triggers["HighErrors"]), "description") {
+		t.Errorf("expected no description without Describe, got %s", output.Triggers["HighErrors"])
+	}
+
+	output, err = SerializeResources(resources, "", Options{Describe: true})
+	if err != nil {
+		t.Fatalf("SerializeResources failed: %v", err)
+	}
+	if !strings.Contains(string(output.Triggers["HighErrors"]), `"description":"COUNT \u003e 50 over 10m on api (query Errors), checked every 5m"`) {
+		t.Errorf("expected a generated trigger description, got %s", output.Triggers["HighErrors"])
+	}
+	if !strings.Contains(string(output.Triggers["Documented"]), `"description":"Written by hand"`) {
+		t.Errorf("expected declared descriptions to be kept, got %s", output.Triggers["Documented"])
+	}
+	if !strings.Contains(string(output.SLOs["Availability"]), `"description":"99.9% of events on api are good over a rolling 30d"`) {
+		t.Errorf("expected a generated SLO description, got %s", output.SLOs["Availability"])
+	}
+
+	// A custom template enables descriptions on its own
+	output, err = SerializeResources(resources, "triggers", Options{TriggerDescription: "{{.Name}} fired"})
+	if err != nil {
+		t.Fatalf("SerializeResources failed: %v", err)
+	}
+	if !strings.Contains(string(output.Triggers["HighErrors"]), `"description":"High Errors fired"`) {
+		t.Errorf("expected the custom template, got %s", output.Triggers["HighErrors"])
+	}
+
+	if _, err := SerializeResources(resources, "triggers", Options{TriggerDescription: "{{.Missing}}"}); err == nil || !strings.Contains(err.Error(), "trigger HighErrors") {
+		t.Errorf("expected a template error naming the trigger, got %v", err)
+	}
+}
+
+func TestDocComments(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `package obs
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+// Errors counts failed requests.
+var Errors = query.Query{Dataset: "api", TimeRange: query.Hours(1), Calculations: []query.Calculation{query.Count()}}
+
+// HighErrors pages the on-call engineer
+// when errors spike.
+var HighErrors = trigger.Trigger{
+	Name:  "High Errors",
+	Query: Errors,
+}
+
+// Documented has a doc comment.
+var Documented = trigger.Trigger{
+	Name:        "Documented",
+	Description: "Declared description",
+	Query:       Errors,
+}
+
+// Overview shows API health.
+var Overview = board.Board{Name: "Overview"}
+`
+	if err := os.WriteFile(tmpDir+"/obs.go", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	resources, err := discovery.DiscoverAll(tmpDir)
+	if err != nil {
+		t.Fatalf("DiscoverAll failed: %v", err)
+	}
+
+	output, err := SerializeResources(resources, "", Options{Describe: true})
+	if err != nil {
+		t.Fatalf("SerializeResources failed: %v", err)
+	}
+	if !strings.Contains(string(output.Triggers["HighErrors"]), `"description":"HighErrors pages the on-call engineer when errors spike."`) {
+		t.Errorf("expected the doc comment, not a generated description, got %s", output.Triggers["HighErrors"])
+	}
+	if !strings.Contains(string(output.Triggers["Documented"]), `"description":"Declared description"`) {
+		t.Errorf("expected the Description field to win, got %s", output.Triggers["Documented"])
+	}
+	if !strings.Contains(string(output.Boards["Overview"]), `"description":"Overview shows API health."`) {
+		t.Errorf("expected the board doc comment, got %s", output.Boards["Overview"])
+	}
+	if strings.Contains(string(output.Queries["Errors"]), "description") {
+		t.Errorf("query JSON has no description, got %s", output.Queries["Errors"])
+	}
+
+	result, err := (&honeycombLister{opts: &Options{}}).List(nil, tmpDir, ListOpts{})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	descriptions := make(map[string]string)
+	for _, entry := range result.Data.([]map[string]string) {
+		descriptions[entry["name"]] = entry["description"]
+	}
+	for name, want := range map[string]string{
+		"Errors":     "Errors counts failed requests.",
+		"HighErrors": "HighErrors pages the on-call engineer when errors spike.",
+		"Documented": "Declared description",
+		"Overview":   "Overview shows API health.",
+	} {
+		if descriptions[name] != want {
+			t.Errorf("list description of %s: expected %q, got %q", name, want, descriptions[name])
+		}
+	}
+}
+
+func TestListerAndGrapher_Tags(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `package obs
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var Latency = query.Query{Dataset: "api", TimeRange: query.Hours(1), Calculations: []query.Calculation{query.P99("duration_ms")}}
+
+var Errors = query.Query{Dataset: "api", TimeRange: query.Hours(1), Calculations: []query.Calculation{query.Count()}}
+
+var Platform = board.Board{
+	Name:   "Platform",
+	Panels: []board.Panel{board.QueryPanel(Latency)},
+	Tags:   []board.Tag{{Key: "team", Value: "platform"}},
+}
+
+var Checkout = board.Board{
+	Name: "Checkout",
+	Tags: []board.Tag{{Key: "team", Value: "checkout"}},
+}
+
+var HighErrors = trigger.Trigger{
+	Name:  "High Errors",
+	Query: Errors,
+	Tags:  []trigger.Tag{{Key: "team", Value: "platform"}},
+}
+`
+	if err := os.WriteFile(tmpDir+"/obs.go", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	opts := &Options{Tags: []string{"team=platform"}}
+
+	result, err := (&honeycombLister{opts: opts}).List(nil, tmpDir, ListOpts{})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var names []string
+	for _, entry := range result.Data.([]map[string]string) {
+		names = append(names, entry["type"]+"/"+entry["name"])
+	}
+	if strings.Join(names, ",") != "board/Platform,trigger/HighErrors" {
+		t.Errorf("expected the platform board and trigger, got %v", names)
+	}
+
+	result, err = (&honeycombGrapher{opts: opts}).Graph(nil, tmpDir, GraphOpts{})
+	if err != nil {
+		t.Fatalf("Graph failed: %v", err)
+	}
+	graph := result.Data.(string)
+	for _, want := range []string{"Latency [shape=box]", "Errors [shape=box]", "Platform [shape=folder]"} {
+		if !strings.Contains(graph, want) {
+			t.Errorf("graph missing %q:\n%s", want, graph)
+		}
+	}
+	if strings.Contains(graph, "Checkout") {
+		t.Errorf("graph includes an untagged board:\n%s", graph)
+	}
+}
+
+func TestLister_DSL(t *testing.T) {
+	tmpDir := t.TempDir()
+	content := `package obs
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var Latency = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Calculations: []query.Calculation{query.P99("duration_ms")},
+	Filters:      []query.Filter{query.GTE("status_code", 500)},
+	Breakdowns:   []string{"service"},
+}
+
+var HighErrors = trigger.Trigger{
+	Name: "High Errors",
+	Query: query.Query{
+		Dataset:      "api",
+		TimeRange:    query.Minutes(15),
+		Calculations: []query.Calculation{query.Count()},
+	},
+}
+
+var Failures = query.Query{
+	Dataset:           "api",
+	TimeRange:         query.Hours(2),
+	Calculations:      []query.Calculation{query.Count()},
+	Filters:           []query.Filter{query.Equals("a", 1), query.Equals("b", 2)},
+	FilterCombination: "OR",
+	Granularity:       60,
+}
+`
+	if err := os.WriteFile(tmpDir+"/obs.go", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	result, err := (&honeycombLister{opts: &Options{}}).List(nil, tmpDir, ListOpts{})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	for _, entry := range result.Data.([]map[string]string) {
+		if _, ok := entry["dsl"]; ok {
+			t.Errorf("expected no dsl without DSL, got %v", entry)
+		}
+	}
+
+	result, err = (&honeycombLister{opts: &Options{DSL: true}}).List(nil, tmpDir, ListOpts{})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	dsl := make(map[string]string)
+	for _, entry := range result.Data.([]map[string]string) {
+		dsl[entry["name"]] = entry["dsl"]
+	}
+	for name, want := range map[string]string{
+		"Latency":    "P99(duration_ms) FROM api WHERE status_code >= 500 GROUP BY service LAST 2h",
+		"HighErrors": "COUNT FROM api LAST 15m",
+		"Failures":   "COUNT FROM api WHERE a = 1 OR b = 2 LAST 2h GRANULARITY 1m",
+	} {
+		if dsl[name] != want {
+			t.Errorf("dsl of %s: expected %q, got %q", name, want, dsl[name])
+		}
+	}
+}
+
+func TestCustomResourceKind(t *testing.T) {
+	resource.Register(resource.Kind{
+		Name: "runbook",
+		Type: "runbook.Runbook",
+		Serialize: func(r resource.Resource) (any, error) {
+			return map[string]any{"title": r.String("Title"), "alert": r.String("Alert")}, nil
+		},
+		Lint: func(r resource.Resource) []resource.Finding {
+			if r.String("Owner") == "" {
+				return []resource.Finding{{Rule: "RUNBOOK001", Severity: resource.SeverityWarning, Message: "Runbook has no owner"}}
+			}
+			return nil
+		},
+	})
+	t.Cleanup(func() { resource.Unregister("runbook") })
+
+	tmpDir := t.TempDir()
+	content := `package obs
+
+import (
+	"example.com/runbook"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+var Errors = query.Query{Dataset: "api", TimeRange: query.Hours(1), Calculations: []query.Calculation{query.Count()}}
+
+var RestartAPI = runbook.Runbook{
+	Title: "Restart the API",
+	Alert: Errors,
+}
+`
+	if err := os.WriteFile(tmpDir+"/obs.go", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	result, err := (&honeycombLister{opts: &Options{}}).List(nil, tmpDir, ListOpts{})
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	var names []string
+	for _, entry := range result.Data.([]map[string]string) {
+		names = append(names, entry["type"]+"/"+entry["name"])
+	}
+	if strings.Join(names, ",") != "query/Errors,runbook/RestartAPI" {
+		t.Errorf("unexpected list: %v", names)
+	}
+
+	result, err = (&honeycombGrapher{opts: &Options{}}).Graph(nil, tmpDir, GraphOpts{})
+	if err != nil {
+		t.Fatalf("Graph failed: %v", err)
+	}
+	graph := result.Data.(string)
+	for _, want := range []string{"RestartAPI [shape=component]", "RestartAPI -> Errors"} {
+		if !strings.Contains(graph, want) {
+			t.Errorf("graph missing %q:\n%s", want, graph)
+		}
+	}
+
+	result, err = (&honeycombGrapher{opts: &Options{}}).Graph(nil, tmpDir, GraphOpts{Format: "json"})
+	if err != nil {
+		t.Fatalf("Graph failed: %v", err)
+	}
+	deps := result.Data.(*DependencyGraph)
+	if want := (GraphEdge{From: "runbooks/RestartAPI", To: "queries/Errors", Type: EdgeReference}); len(deps.Edges) != 1 || deps.Edges[0] != want {
+		t.Errorf("expected edge %+v, got %+v", want, deps.Edges)
+	}
+
+	result, err = (&honeycombBuilder{opts: &Options{}}).Build(nil, tmpDir, BuildOpts{Type: "runbook"})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	var output map[string]map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(result.Data.(string)), &output); err != nil {
+		t.Fatalf("invalid build output: %v", err)
+	}
+	if len(output) != 1 || string(output["runbooks"]["RestartAPI"]) != `{"alert":"Errors","title":"Restart the API"}` {
+		t.Errorf("unexpected build output: %s", result.Data)
+	}
+
+	counts, err := ResourceCounts(tmpDir, NameFilter{})
+	if err != nil {
+		t.Fatalf("ResourceCounts failed: %v", err)
+	}
+	if counts["runbooks"] != 1 || counts["queries"] != 1 {
+		t.Errorf("unexpected counts: %v", counts)
+	}
+
+	result, err = (&honeycombLinter{opts: &Options{}}).Lint(nil, tmpDir, LintOpts{})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	found := false
+	for _, e := range result.Errors {
+		found = found || e.Code == "RUNBOOK001"
+	}
+	if !found {
+		t.Errorf("expected RUNBOOK001, got %+v", result.Errors)
+	}
+}
+
+func TestBuilderBuild_References(t *testing.T) {
+	d := &HoneycombDomain{}
+	builder := d.Builder()
+
+	tmpDir := t.TempDir()
+	content := `package obs
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var Latency = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(1),
+	Calculations: []query.Calculation{query.P99("duration_ms")},
+}
+
+var HighLatency = trigger.Trigger{
+	Name:      "High Latency",
+	Dataset:   "api",
+	Query:     Latency,
+	Threshold: trigger.GreaterThan(500),
+	Frequency: trigger.Minutes(5),
+}
+
+var Overview = board.Board{
+	Name:   "Overview",
+	Panels: []board.Panel{board.QueryPanel(Latency)},
+}
+`
+	if err := os.WriteFile(tmpDir+"/obs.go", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	result, err := builder.Build(&coredomain.Context{}, tmpDir, BuildOpts{DryRun: true})
+	if err != nil || !result.Success {
+		t.Fatalf("Build failed: %v %+v", err, result)
+	}
+	data := result.Data.(string)
+	if !strings.Contains(data, `"query_id":"wetwire-ref:queries/Latency"`) {
+		t.Errorf("expected a query_id placeholder, got %s", data)
+	}
+	if strings.Contains(data, `"references"`) {
+		t.Errorf("expected no references manifest without Refs, got %s", data)
+	}
+
+	d.Options.Refs = true
+	result, err = builder.Build(&coredomain.Context{}, tmpDir, BuildOpts{DryRun: true})
+	if err != nil || !result.Success {
+		t.Fatalf("Build failed: %v %+v", err, result)
+	}
+
+	var out struct {
+		References []struct {
+			From  string `json:"from"`
+			To    string `json:"to"`
+			Field string `json:"field"`
+		} `json:"references"`
+	}
+	if err := json.Unmarshal([]byte(result.Data.(string)), &out); err != nil {
+		t.Fatalf("Failed to parse build output: %v", err)
+	}
+	want := []string{"boards/Overview -> queries/Latency ()", "triggers/HighLatency -> queries/Latency (query_id)"}
+	var got []string
+	for _, r := range out.References {
+		got = append(got, fmt.Sprintf("%s -> %s (%s)", r.From, r.To, r.Field))
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected references:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// The manifest is not a resource section
+	withStdin(t, result.Data.(string))
+	validation, err := (&honeycombValidator{opts: &Options{}}).Validate(nil, StdinPath, ValidateOpts{})
+	if err != nil || !validation.Success {
+		t.Errorf("expected build output with references to validate, got %v %+v", err, validation)
+	}
+}
+
+func TestSerializeResources_QueryMode(t *testing.T) {
+	resources := &discovery.DiscoveredResources{
+		Queries: []discovery.DiscoveredQuery{{
+			Name:         "Errors",
+			Dataset:      "api",
+			TimeRange:    discovery.TimeRange{TimeRange: 600},
+			Calculations: []discovery.Calculation{{Op: "COUNT"}},
+		}},
+		Triggers: []discovery.DiscoveredTrigger{
+			{Name: "HighErrors", TriggerName: "High Errors", Dataset: "api", QueryRef: "Errors"},
+			{Name: "Inline", TriggerName: "Inline", Dataset: "api", InlineQuery: &discovery.DiscoveredQuery{
+				Dataset:   "api",
+				TimeRange: discovery.TimeRange{TimeRange: 300},
+			}},
+		},
+	}
+
+	output, err := SerializeResources(resources, "triggers", Options{})
+	if err != nil {
+		t.Fatalf("SerializeResources failed: %v", err)
+	}
+	if got := string(output.Triggers["HighErrors"]); !strings.Contains(got, `"query_id":"wetwire-ref:queries/Errors"`) || strings.Contains(got, `"query":`) {
+		t.Errorf("expected a query_id placeholder by default, got %s", got)
+	}
+
+	output, err = SerializeResources(resources, "triggers", Options{QueryMode: QueryModeInline})
+	if err != nil {
+		t.Fatalf("SerializeResources failed: %v", err)
+	}
+	if got := string(output.Triggers["HighErrors"]); !strings.Contains(got, `"query":{`) || !strings.Contains(got, `"time_range":600`) || strings.Contains(got, "query_id") {
+		t.Errorf("expected the referenced query spec inlined, got %s", got)
+	}
+	if got := string(output.Triggers["Inline"]); !strings.Contains(got, `"time_range":300`) {
+		t.Errorf("expected the inline query spec, got %s", got)
+	}
+
+	if _, err := SerializeResources(resources, "triggers", Options{QueryMode: "embedded"}); err == nil || !strings.Contains(err.Error(), `unknown query mode "embedded"`) {
+		t.Errorf("expected an unknown mode error, got %v", err)
+	}
+}
+
+func TestSerializeResources_APIVersion(t *testing.T) {
+	resources := &discovery.DiscoveredResources{
+		Queries: []discovery.DiscoveredQuery{{
+			Name:         "Users",
+			Dataset:      "api",
+			TimeRange:    discovery.TimeRange{TimeRange: 600},
+			Calculations: []discovery.Calculation{{Op: "COUNT_DISTINCT", Column: "user.id"}},
+		}},
+	}
+
+	output, err := SerializeResources(resources, "", Options{})
+	if err != nil {
+		t.Fatalf("SerializeResources failed: %v", err)
+	}
+	if got := string(output.Queries["Users"]); !strings.Contains(got, `"op":"COUNT_DISTINCT"`) {
+		t.Errorf("expected the latest API by default, got %s", got)
+	}
+
+	if _, err := SerializeResources(resources, "", Options{APIVersion: "v0"}); err == nil || !strings.Contains(err.Error(), `unknown API version "v0"`) {
+		t.Errorf("expected an unknown version error, got %v", err)
+	}
+}
+
+func TestSerializeResources_CrossDatasetSLO(t *testing.T) {
+	resources := &discovery.DiscoveredResources{
+		Queries: []discovery.DiscoveredQuery{{Name: "AllCheckouts", Dataset: "checkout"}},
+		SLOs: []discovery.DiscoveredSLO{{
+			Name:                "CheckoutAvailability",
+			SLOName:             "Checkout Availability",
+			SLIAlias:            "sli.checkout_ok",
+			GoodEventsQuery:     &discovery.DiscoveredQuery{Dataset: "cdn-logs"},
+			TotalEventsQueryRef: "AllCheckouts",
+		}},
+	}
+
+	output, err := SerializeResources(resources, "slos", Options{})
+	if err != nil {
+		t.Fatalf("SerializeResources failed: %v", err)
+	}
+	got := string(output.SLOs["CheckoutAvailability"])
+	for _, want := range []string{`"dataset_slugs":["cdn-logs","checkout"]`, `"sli":{"alias":"sli.checkout_ok"}`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %s in %s", want, got)
+		}
+	}
+
+	resources.SLOs[0].SLIAlias = ""
+	if _, err := SerializeResources(resources, "slos", Options{}); err == nil || !strings.Contains(err.Error(), "SLI.Alias") {
+		t.Errorf("expected a missing alias error, got %v", err)
+	}
+}
+
+func TestSerializeResources_Mutes(t *testing.T) {
+	resources := &discovery.DiscoveredResources{
+		Triggers: []discovery.DiscoveredTrigger{
+			{Name: "HighLatency", TriggerName: "High Latency", Dataset: "api"},
+			{Name: "SlowQueries", TriggerName: "Slow Queries", Dataset: "db", Tags: []discovery.DiscoveredTag{{Key: "team", Value: "storage"}}},
+			{Name: "HighErrors", TriggerName: "High Errors", Dataset: "api"},
+		},
+		Mutes: []discovery.DiscoveredMute{{
+			Name:       "DatabaseUpgrade",
+			WindowName: "Database upgrade",
+			Start:      "2026-11-07T02:00:00Z",
+			End:        "2026-11-07T04:00:00Z",
+			Triggers:   []string{"High Lat*"},
+			Tags:       []string{"team=storage"},
+		}},
+	}
+
+	output, err := SerializeResources(resources, "mutes", Options{})
+	if err != nil {
+		t.Fatalf("SerializeResources failed: %v", err)
+	}
+	want := `{"name":"Database upgrade","start":"2026-11-07T02:00:00Z","end":"2026-11-07T04:00:00Z","triggers":["HighLatency","SlowQueries"]}`
+	if got := string(output.Section("mutes")["DatabaseUpgrade"]); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+	if output.Triggers != nil {
+		t.Errorf("expected only the mutes section, got %v", output)
+	}
+
+	resources.Mutes[0].End = "2026-11-07T01:00:00Z"
+	if _, err := SerializeResources(resources, "", Options{}); err == nil || !strings.Contains(err.Error(), "maintenance window DatabaseUpgrade: end") {
+		t.Errorf("expected an invalid window error, got %v", err)
+	}
+}
+
+func TestBuilderBuild_KeepGoing(t *testing.T) {
+	d := &HoneycombDomain{}
+	builder := d.Builder()
+
+	tmpDir := t.TempDir()
+	content := `package obs
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Good = query.Query{Dataset: "api", TimeRange: query.Hours(1)}
+
+var Bad = query.Query{Dataset: "api-${MISSING_TEAM}", TimeRange: query.Hours(1)}
+`
+	if err := os.WriteFile(tmpDir+"/obs.go", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	d.Options.AllowEnv = true
+
+	// Without it, one bad resource fails the whole build
+	result, err := builder.Build(&coredomain.Context{}, tmpDir, BuildOpts{DryRun: true})
+	if err != nil || result.Success || result.Data != nil {
+		t.Fatalf("expected the build to fail without output, got %v %+v", err, result)
+	}
+
+	d.Options.KeepGoing = true
+	d.Options.WriteLock = true
+	outFile := filepath.Join(tmpDir, "out.json")
+	result, err = builder.Build(&coredomain.Context{}, tmpDir, BuildOpts{Output: outFile})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.Success || len(result.Errors) != 1 {
+		t.Fatalf("expected a failed result with 1 error, got %+v", result)
+	}
+	if e := result.Errors[0]; e.Line != 7 || !strings.HasPrefix(e.Message, "queries/Bad: ") {
+		t.Errorf("unexpected error: %+v", e)
+	}
+
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("expected the partial output to be written: %v", err)
+	}
+	var out struct {
+		Queries map[string]json.RawMessage `json:"queries"`
+		Errors  []BuildError               `json:"errors"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Failed to parse build output: %v", err)
+	}
+	if _, ok := out.Queries["Good"]; !ok || len(out.Queries) != 1 {
+		t.Errorf("expected only the Good query, got %s", data)
+	}
+	if len(out.Errors) != 1 || out.Errors[0].Resource != "queries/Bad" || !strings.HasSuffix(out.Errors[0].File, "obs.go") {
+		t.Errorf("unexpected errors section: %+v", out.Errors)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "wetwire.lock")); !os.IsNotExist(err) {
+		t.Errorf("expected no lockfile after a partial build, got %v", err)
+	}
+}
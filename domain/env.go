@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/envsubst"
+)
+
+// envField is a resource field that may reference environment variables.
+type envField struct {
+	resource string
+	field    string
+	file     string
+	line     int
+	value    *string
+}
+
+// envFields returns the interpolatable fields of resources: every dataset,
+// including those of inline queries, and trigger, SLO and burn alert
+// recipient targets.
+func envFields(resources *discovery.DiscoveredResources) []envField {
+	var fields []envField
+	addQuery := func(resource string, q *discovery.DiscoveredQuery) {
+		if q != nil {
+			fields = append(fields, envField{resource, "Dataset", q.File, q.Line, &q.Dataset})
+		}
+	}
+
+	for i := range resources.Queries {
+		q := &resources.Queries[i]
+		addQuery("query "+q.Name, q)
+	}
+	for i := range resources.Boards {
+		b := &resources.Boards[i]
+		for j := range b.Panels {
+			addQuery("board "+b.Name, b.Panels[j].Query)
+		}
+	}
+	for i := range resources.SLOs {
+		s := &resources.SLOs[i]
+		fields = append(fields, envField{"slo " + s.Name, "Dataset", s.File, s.Line, &s.Dataset})
+		addQuery("slo "+s.Name, s.GoodEventsQuery)
+		addQuery("slo "+s.Name, s.TotalEventsQuery)
+		for j := range s.DefaultRecipients {
+			fields = append(fields, envField{"slo " + s.Name, "default recipient Target", s.File, s.Line, &s.DefaultRecipients[j].Target})
+		}
+		for j := range s.BurnAlerts {
+			for k := range s.BurnAlerts[j].Recipients {
+				fields = append(fields, envField{"slo " + s.Name, "burn alert recipient Target", s.File, s.Line, &s.BurnAlerts[j].Recipients[k].Target})
+			}
+		}
+	}
+	for i := range resources.Triggers {
+		t := &resources.Triggers[i]
+		fields = append(fields, envField{"trigger " + t.Name, "Dataset", t.File, t.Line, &t.Dataset})
+		addQuery("trigger "+t.Name, t.InlineQuery)
+		for j := range t.Recipients {
+			fields = append(fields, envField{"trigger " + t.Name, "recipient Target", t.File, t.Line, &t.Recipients[j].Target})
+		}
+	}
+	return fields
+}
+
+// ExpandEnv replaces ${VAR} references in the datasets and recipient targets
+// of resources with environment variable values, in place. Unset
+// variables and malformed references are returned as errors. Unless allowed
+// (Options.AllowEnv), any reference is an error, so unexpanded placeholders
+// are never sent to Honeycomb.
+func ExpandEnv(resources *discovery.DiscoveredResources, allowed bool) []Error {
+	return expandEnv(resources, allowed, os.LookupEnv)
+}
+
+func expandEnv(resources *discovery.DiscoveredResources, allowed bool, lookup func(string) (string, bool)) []Error {
+	var errs []Error
+	seen := make(map[Error]bool)
+	for _, f := range envFields(resources) {
+		if !envsubst.Contains(*f.value) {
+			continue
+		}
+
+		// Inline queries may also be discovered as queries; report once
+		fail := func(format string, args ...any) {
+			e := Error{
+				Path:     f.file,
+				Line:     f.line,
+				Severity: "error",
+				Message:  fmt.Sprintf("%s: %s %q: %s", f.resource, f.field, *f.value, fmt.Sprintf(format, args...)),
+			}
+			if !seen[e] {
+				seen[e] = true
+				errs = append(errs, e)
+			}
+		}
+
+		if !allowed {
+			fail("environment interpolation is disabled; build with --allow-env")
+			continue
+		}
+
+		expanded, missing, err := envsubst.Expand(*f.value, lookup)
+		switch {
+		case err != nil:
+			fail("%v", err)
+		case len(missing) > 0:
+			fail("environment variable %s is not set", strings.Join(missing, ", "))
+		default:
+			*f.value = expanded
+		}
+	}
+	return errs
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+func envResources() *discovery.DiscoveredResources {
+	return &discovery.DiscoveredResources{
+		Queries: []discovery.DiscoveredQuery{{Name: "Latency", Dataset: "${TEAM}-api"}},
+		Triggers: []discovery.DiscoveredTrigger{{
+			Name:       "HighErrors",
+			Dataset:    "production",
+			Recipients: []discovery.DiscoveredRecipient{{Type: "slack", Target: "#${TEAM}-alerts"}},
+		}},
+	}
+}
+
+func envLookup(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	resources := envResources()
+	errs := expandEnv(resources, true, envLookup(map[string]string{"TEAM": "payments"}))
+	if len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	if got := resources.Queries[0].Dataset; got != "payments-api" {
+		t.Errorf("Dataset = %q, want payments-api", got)
+	}
+	if got := resources.Triggers[0].Recipients[0].Target; got != "#payments-alerts" {
+		t.Errorf("Target = %q, want #payments-alerts", got)
+	}
+}
+
+func TestExpandEnv_Disabled(t *testing.T) {
+	resources := envResources()
+	errs := expandEnv(resources, false, envLookup(map[string]string{"TEAM": "payments"}))
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if !strings.Contains(errs[0].Message, "--allow-env") {
+		t.Errorf("expected --allow-env hint, got %q", errs[0].Message)
+	}
+	if got := resources.Queries[0].Dataset; got != "${TEAM}-api" {
+		t.Errorf("Dataset should be unchanged, got %q", got)
+	}
+}
+
+func TestExpandEnv_Missing(t *testing.T) {
+	errs := expandEnv(envResources(), true, envLookup(nil))
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", errs)
+	}
+	if !strings.Contains(errs[0].Message, "TEAM is not set") {
+		t.Errorf("expected missing variable error, got %q", errs[0].Message)
+	}
+}
+
+func TestExpandEnv_SLORecipients(t *testing.T) {
+	resources := &discovery.DiscoveredResources{
+		SLOs: []discovery.DiscoveredSLO{{
+			Name:              "Availability",
+			Dataset:           "production",
+			DefaultRecipients: []discovery.DiscoveredRecipient{{Type: "slack", Target: "#${TEAM}-slo"}},
+			BurnAlerts: []discovery.DiscoveredBurnAlert{{
+				Name:       "Fast burn",
+				Recipients: []discovery.DiscoveredRecipient{{Type: "pagerduty", Target: "${TEAM}-oncall"}},
+			}},
+		}},
+	}
+	if errs := expandEnv(resources, false, envLookup(nil)); len(errs) != 2 {
+		t.Fatalf("expected 2 errors without --allow-env, got %v", errs)
+	}
+
+	if errs := expandEnv(resources, true, envLookup(map[string]string{"TEAM": "payments"})); len(errs) != 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	s := resources.SLOs[0]
+	if got := s.DefaultRecipients[0].Target; got != "#payments-slo" {
+		t.Errorf("default recipient Target = %q, want #payments-slo", got)
+	}
+	if got := s.BurnAlerts[0].Recipients[0].Target; got != "payments-oncall" {
+		t.Errorf("burn alert recipient Target = %q, want payments-oncall", got)
+	}
+}
+
+func TestExpandEnv_Malformed(t *testing.T) {
+	resources := &discovery.DiscoveredResources{
+		Queries: []discovery.DiscoveredQuery{{Name: "Latency", Dataset: "${TEAM"}},
+	}
+	if errs := expandEnv(resources, true, envLookup(nil)); len(errs) != 1 {
+		t.Errorf("expected 1 error, got %v", errs)
+	}
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/atomicfile"
+)
+
+// writeGenerated writes the code generate returns for the package of target
+// to target, a .go file or a directory the file named file is created in, and
+// returns the path written. The package is that of the Go files in the
+// directory, or else named after it (see packageName). An existing file is
+// never overwritten.
+func writeGenerated(target, file string, generate func(pkg string) ([]byte, error)) (string, error) {
+	if filepath.Ext(target) == ".go" {
+		file = target
+	} else {
+		file = filepath.Join(target, file)
+	}
+	if _, err := os.Stat(file); err == nil {
+		return "", fmt.Errorf("%s already exists; remove it or choose another --target", file)
+	}
+
+	code, err := generate(packageName(filepath.Dir(file)))
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return "", fmt.Errorf("create target directory: %w", err)
+	}
+	if err := atomicfile.WriteFile(file, code, 0644); err != nil {
+		return "", fmt.Errorf("write %s: %w", file, err)
+	}
+	return file, nil
+}
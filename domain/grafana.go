@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/internal/atomicfile"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/grafana"
+	"github.com/lex00/wetwire-honeycomb-go/internal/i18n"
+)
+
+// FormatGrafana is the build format that exports boards as Grafana dashboards.
+const FormatGrafana = "grafana"
+
+// GrafanaReportFile is the mapping report written next to exported dashboards.
+const GrafanaReportFile = "grafana-mapping.json"
+
+// grafanaOutput is the build output for FormatGrafana.
+type grafanaOutput struct {
+	Dashboards    map[string]grafana.Dashboard `json:"dashboards"`
+	MappingReport []grafana.Note               `json:"mapping_report"`
+}
+
+// buildGrafana converts the discovered boards to Grafana dashboards. With an
+// output path, each dashboard is written to <output>/<BoardVar>.json together
+// with the mapping report; otherwise both are returned as the result data.
+// An explicit OutputFile mode is rejected, since the output is a directory.
+func buildGrafana(resources *discovery.DiscoveredResources, opts BuildOpts, mode string) (*Result, error) {
+	if mode == OutputFile {
+		return nil, fmt.Errorf("grafana output is a directory of dashboards; use output mode %s", OutputDir)
+	}
+	if len(resources.Boards) == 0 {
+		return NewErrorResult(i18n.T("no boards found"), Error{
+			Message: i18n.T("grafana export requires at least one board"),
+		}), nil
+	}
+
+	out := grafanaOutput{
+		Dashboards:    make(map[string]grafana.Dashboard),
+		MappingReport: []grafana.Note{},
+	}
+	for _, db := range resources.Boards {
+		b, notes := discoveredToGrafanaBoard(db, resources.Queries)
+		d, convertNotes, err := grafana.Convert(b)
+		if err != nil {
+			return nil, fmt.Errorf("board %s: %w", db.Name, err)
+		}
+		out.Dashboards[db.Name] = d
+		out.MappingReport = append(out.MappingReport, notes...)
+		out.MappingReport = append(out.MappingReport, convertNotes...)
+	}
+
+	if opts.DryRun || opts.Output == "" {
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("serialization failed: %w", err)
+		}
+		return NewResultWithData(i18n.T("Build completed"), string(data)), nil
+	}
+
+	if err := os.MkdirAll(opts.Output, 0755); err != nil {
+		return nil, fmt.Errorf("create output directory: %w", err)
+	}
+	for name, d := range out.Dashboards {
+		if err := writeJSONFile(filepath.Join(opts.Output, name+".json"), d); err != nil {
+			return nil, err
+		}
+	}
+	if err := writeJSONFile(filepath.Join(opts.Output, GrafanaReportFile), out.MappingReport); err != nil {
+		return nil, err
+	}
+
+	return NewResult(i18n.Sprintf("Wrote %d dashboard(s) to %s (%d mapping note(s))", len(out.Dashboards), opts.Output, len(out.MappingReport))), nil
+}
+
+// writeJSONFile writes v as indented JSON.
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Errorf("serialize %s: %w", filepath.Base(path), err)
+	}
+	if err := atomicfile.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("write output: %w", err)
+	}
+	return nil
+}
+
+// discoveredToGrafanaBoard converts a DiscoveredBoard to a board.Board with its
+// panels, resolving query references against the discovered queries. Panels
+// whose query cannot be resolved are skipped and reported.
+func discoveredToGrafanaBoard(db discovery.DiscoveredBoard, queries []discovery.DiscoveredQuery) (board.Board, []grafana.Note) {
+	b := discoveredToBoard(db)
+	var notes []grafana.Note
+
+	byName := make(map[string]discovery.DiscoveredQuery, len(queries))
+	for _, dq := range queries {
+		byName[dq.Name] = dq
+	}
+
+	for _, f := range db.PresetFilters {
+		b.PresetFilters = append(b.PresetFilters, board.Filter{Column: f.Column, Operation: f.Op, Value: f.Value})
+	}
+
+	// Consecutive panels of the same section are grouped in a board.Section
+	var section string
+	var sectionPanels []board.Panel
+	flush := func() {
+		if section != "" {
+			b.Panels = append(b.Panels, board.Section(section, sectionPanels...))
+		}
+		section, sectionPanels = "", nil
+	}
+	add := func(dp discovery.DiscoveredPanel, p board.Panel) {
+		if dp.Section != section {
+			flush()
+			section = dp.Section
+		}
+		if section == "" {
+			b.Panels = append(b.Panels, p)
+		} else {
+			sectionPanels = append(sectionPanels, p)
+		}
+	}
+
+	for i, dp := range db.Panels {
+		var opts []board.PanelOption
+		if dp.Title != "" {
+			opts = append(opts, board.WithTitle(dp.Title))
+		}
+		if dp.Width > 0 || dp.Height > 0 {
+			opts = append(opts, board.WithPosition(dp.X, dp.Y, dp.Width, dp.Height))
+		}
+
+		switch dp.Type {
+		case "query":
+			dq := dp.Query
+			if dq == nil {
+				if q, ok := byName[dp.QueryRef]; ok {
+					dq = &q
+				}
+			}
+			if dq == nil {
+				notes = append(notes, grafana.Note{
+					Board:   db.BoardName,
+					Panel:   fmt.Sprintf("panel %d", i+1),
+					Feature: "query panel",
+					Message: fmt.Sprintf("query %q could not be resolved; panel skipped", dp.QueryRef),
+				})
+				continue
+			}
+			add(dp, board.QueryPanel(dq.Query(), opts...))
+		case "text":
+			add(dp, board.TextPanel(dp.Content, opts...))
+		case "slo":
+			add(dp, board.SLOPanelByID(dp.SLOID, opts...))
+		}
+	}
+	flush()
+
+	return b, notes
+}
+
+// ImportFromGrafana is the import source format of Grafana dashboard JSON.
+const ImportFromGrafana = "grafana"
+
+// ImportGrafana converts a Grafana dashboard, read from source (StdinPath
+// for standard input), to Go declarations of a query per panel and a board
+// skeleton. PromQL and LogQL queries are approximated; how each differs is
+// listed as TODO comments and returned as warnings.
+func ImportGrafana(source string, opts ConvertOpts) (*Result, error) {
+	data, err := readSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	conv, err := grafana.Import(data, grafana.ImportOptions{Dataset: opts.Dataset})
+	if err != nil {
+		return NewErrorResult(i18n.T("import failed"), Error{Path: sourceName(source), Message: err.Error()}), nil
+	}
+	return importConversion(source, "Grafana", "grafana", conv, opts.Target)
+}
@@ -0,0 +1,319 @@
+package domain
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lock"
+	"github.com/lex00/wetwire-honeycomb-go/resource"
+)
+
+// DependencyGraph is the dependency graph of a set of resources: a node per
+// query, board, SLO, trigger and custom resource, and an edge from each
+// resource to every resource it uses. graph -f json and -f yaml return it as
+// the result data, for tools that consume the dependencies programmatically.
+type DependencyGraph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode is a resource of a DependencyGraph.
+type GraphNode struct {
+	// ID is the resource's lockfile key, e.g. "queries/Latency"
+	ID string `json:"id"`
+
+	// Type is "query", "board", "slo", "trigger" or the name of a custom kind
+	Type string `json:"type"`
+
+	// Name is the Go variable name
+	Name    string `json:"name"`
+	Package string `json:"package,omitempty"`
+
+	// Dataset is the dataset of a query, SLO or trigger, taken from its
+	// queries when it sets none
+	Dataset string `json:"dataset,omitempty"`
+
+	// File is relative to the graphed directory
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// GraphEdge is a dependency of one resource on another, by node ID.
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	// Type is how From uses To: "panel" for a board panel, "query" for a
+	// trigger's query, "good_events" and "total_events" for an SLO's SLI
+	// queries, and "reference" for a custom resource's reference
+	Type string `json:"type"`
+}
+
+// Graph edge types.
+const (
+	EdgePanel       = "panel"
+	EdgeQuery       = "query"
+	EdgeGoodEvents  = "good_events"
+	EdgeTotalEvents = "total_events"
+	EdgeReference   = "reference"
+)
+
+// NewDependencyGraph returns the dependency graph of resources, discovered
+// in dir. Inline queries are part of the resource holding them and get no
+// node; references to resources outside resources get no edge. Nodes are in
+// discovery order, queries first, and edges in the order of their source
+// node.
+func NewDependencyGraph(resources *discovery.DiscoveredResources, dir string) *DependencyGraph {
+	g := &DependencyGraph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	ids := make(map[string]bool)
+	addNode := func(section, typ, name, pkg, dataset, file string, line int) {
+		id := lock.Key(section, name)
+		if ids[id] {
+			return
+		}
+		ids[id] = true
+		if rel, err := filepath.Rel(dir, file); err == nil {
+			file = filepath.ToSlash(rel)
+		}
+		g.Nodes = append(g.Nodes, GraphNode{ID: id, Type: typ, Name: name, Package: pkg, Dataset: dataset, File: file, Line: line})
+	}
+
+	// Inline queries are discovered under the name of the resource holding
+	// them
+	holders := make(map[string]bool)
+	for _, b := range resources.Boards {
+		holders[b.Package+"."+b.Name] = true
+	}
+	for _, s := range resources.SLOs {
+		holders[s.Package+"."+s.Name] = true
+	}
+	for _, t := range resources.Triggers {
+		holders[t.Package+"."+t.Name] = true
+	}
+
+	for _, q := range resources.Queries {
+		if !holders[q.Package+"."+q.Name] {
+			addNode("queries", "query", q.Name, q.Package, q.Dataset, q.File, q.Line)
+		}
+	}
+	for _, b := range resources.Boards {
+		addNode("boards", "board", b.Name, b.Package, "", b.File, b.Line)
+	}
+	for _, s := range resources.SLOs {
+		addNode("slos", "slo", s.Name, s.Package, resources.SLODataset(s), s.File, s.Line)
+	}
+	for _, t := range resources.Triggers {
+		addNode("triggers", "trigger", t.Name, t.Package, resources.TriggerDataset(t), t.File, t.Line)
+	}
+	customSections := make(map[string]string)
+	for _, r := range resources.Custom {
+		section := r.Kind + "s"
+		if kind, ok := resource.Lookup(r.Kind); ok {
+			section = kind.Section
+		}
+		customSections[r.Name] = section
+		addNode(section, r.Kind, r.Name, r.Package, "", r.File, r.Line)
+	}
+
+	seen := make(map[GraphEdge]bool)
+	addEdge := func(from, to, typ string) {
+		e := GraphEdge{From: from, To: to, Type: typ}
+		if ids[from] && ids[to] && from != to && !seen[e] {
+			seen[e] = true
+			g.Edges = append(g.Edges, e)
+		}
+	}
+	for _, b := range resources.Boards {
+		from := lock.Key("boards", b.Name)
+		for _, ref := range b.QueryRefs {
+			addEdge(from, lock.Key("queries", ref), EdgePanel)
+		}
+		for _, ref := range b.SLORefs {
+			addEdge(from, lock.Key("slos", ref), EdgePanel)
+		}
+	}
+	for _, s := range resources.SLOs {
+		from := lock.Key("slos", s.Name)
+		addEdge(from, lock.Key("queries", s.GoodEventsQueryRef), EdgeGoodEvents)
+		addEdge(from, lock.Key("queries", s.TotalEventsQueryRef), EdgeTotalEvents)
+	}
+	for _, t := range resources.Triggers {
+		addEdge(lock.Key("triggers", t.Name), lock.Key("queries", t.QueryRef), EdgeQuery)
+	}
+	for _, r := range resources.Custom {
+		from := lock.Key(customSections[r.Name], r.Name)
+		for _, ref := range customRefs(r, resources) {
+			for _, section := range []string{"queries", "boards", "slos", "triggers", customSections[ref]} {
+				addEdge(from, lock.Key(section, ref), EdgeReference)
+			}
+		}
+	}
+	return g
+}
+
+// Graph directions and clusterings.
+var (
+	graphDirections = []string{"TB", "LR", "BT", "RL"}
+	graphClusterBys = []string{"dataset", "package", "type", "none"}
+)
+
+// graphStyle is how DOT and Mermaid graphs are drawn.
+type graphStyle struct {
+	direction string
+	clusterBy string
+}
+
+// graphStyle returns the graph style of GraphDirection and GraphClusterBy.
+func (o Options) graphStyle() (graphStyle, error) {
+	style := graphStyle{
+		direction: cmp.Or(strings.ToUpper(o.GraphDirection), "TB"),
+		clusterBy: cmp.Or(strings.ToLower(o.GraphClusterBy), "dataset"),
+	}
+	if style.direction == "TD" {
+		style.direction = "TB"
+	}
+	if !slices.Contains(graphDirections, style.direction) {
+		return style, fmt.Errorf("unknown graph direction %q: expected %s", style.direction, strings.Join(graphDirections, ", "))
+	}
+	if !slices.Contains(graphClusterBys, style.clusterBy) {
+		return style, fmt.Errorf("unknown graph clustering %q: expected %s", style.clusterBy, strings.Join(graphClusterBys, ", "))
+	}
+	return style, nil
+}
+
+// graphShapes are the DOT shape and the Mermaid shape delimiters of each
+// resource type. Custom kinds use "custom".
+var graphShapes = map[string][3]string{
+	"query":   {"box", "[", "]"},
+	"board":   {"folder", "{{", "}}"},
+	"slo":     {"ellipse", "([", "])"},
+	"trigger": {"diamond", ">", "]"},
+	"custom":  {"component", "[[", "]]"},
+}
+
+// edgeLabels are the labels of the edges whose type is not evident from
+// the nodes they link.
+var edgeLabels = map[string]string{EdgeGoodEvents: "good", EdgeTotalEvents: "total"}
+
+// DOT returns the graph in Graphviz DOT format.
+func (g *DependencyGraph) DOT(style graphStyle) string {
+	return g.render(style, 0)
+}
+
+// Mermaid returns the graph as a Mermaid flowchart.
+func (g *DependencyGraph) Mermaid(style graphStyle) string {
+	return g.render(style, 1)
+}
+
+// render writes the graph in DOT (format 0) or Mermaid (format 1): the
+// nodes of each cluster, in order of first appearance, then the nodes
+// outside any cluster, then the edges.
+func (g *DependencyGraph) render(style graphStyle, format int) string {
+	ids := g.nodeIDs()
+	node := func(n GraphNode) string {
+		shape, ok := graphShapes[n.Type]
+		if !ok {
+			shape = graphShapes["custom"]
+		}
+		switch {
+		case format == 1:
+			return ids[n.ID] + shape[1] + n.Name + shape[2]
+		case ids[n.ID] != n.Name:
+			return fmt.Sprintf("%s [label=%q, shape=%s];", ids[n.ID], n.Name, shape[0])
+		default:
+			return fmt.Sprintf("%s [shape=%s];", ids[n.ID], shape[0])
+		}
+	}
+
+	var clusters []string
+	members := make(map[string][]GraphNode)
+	var loose []GraphNode
+	for _, n := range g.Nodes {
+		key := n.clusterKey(style.clusterBy)
+		if key == "" {
+			loose = append(loose, n)
+			continue
+		}
+		if _, ok := members[key]; !ok {
+			clusters = append(clusters, key)
+		}
+		members[key] = append(members[key], n)
+	}
+
+	var b strings.Builder
+	if format == 0 {
+		fmt.Fprintf(&b, "digraph G {\n  rankdir=%s;\n", style.direction)
+	} else {
+		fmt.Fprintf(&b, "graph %s\n", style.direction)
+	}
+	for i, key := range clusters {
+		if format == 0 {
+			fmt.Fprintf(&b, "  subgraph cluster_%d {\n    label=%q;\n", i, key)
+		} else {
+			fmt.Fprintf(&b, "  subgraph cluster_%d [%q]\n", i, key)
+		}
+		for _, n := range members[key] {
+			fmt.Fprintf(&b, "    %s\n", node(n))
+		}
+		if format == 0 {
+			b.WriteString("  }\n")
+		} else {
+			b.WriteString("  end\n")
+		}
+	}
+	for _, n := range loose {
+		fmt.Fprintf(&b, "  %s\n", node(n))
+	}
+	for _, e := range g.Edges {
+		from, to := ids[e.From], ids[e.To]
+		label, labeled := edgeLabels[e.Type]
+		switch {
+		case format == 0 && labeled:
+			fmt.Fprintf(&b, "  %s -> %s [label=%q];\n", from, to, label)
+		case format == 0:
+			fmt.Fprintf(&b, "  %s -> %s;\n", from, to)
+		case labeled:
+			fmt.Fprintf(&b, "  %s -->|%s| %s\n", from, label, to)
+		default:
+			fmt.Fprintf(&b, "  %s --> %s\n", from, to)
+		}
+	}
+	if format == 0 {
+		b.WriteString("}")
+	}
+	return b.String()
+}
+
+// nodeIDs returns the DOT and Mermaid identifier of each node: its Go name,
+// or its type and name when another node has the same name.
+func (g *DependencyGraph) nodeIDs() map[string]string {
+	count := make(map[string]int)
+	for _, n := range g.Nodes {
+		count[n.Name]++
+	}
+	ids := make(map[string]string, len(g.Nodes))
+	for _, n := range g.Nodes {
+		ids[n.ID] = n.Name
+		if count[n.Name] > 1 {
+			ids[n.ID] = n.Type + "_" + n.Name
+		}
+	}
+	return ids
+}
+
+// clusterKey returns the cluster of n for a clustering, "" for none.
+func (n GraphNode) clusterKey(clusterBy string) string {
+	switch clusterBy {
+	case "dataset":
+		return n.Dataset
+	case "package":
+		return n.Package
+	case "type":
+		return n.Type
+	}
+	return ""
+}
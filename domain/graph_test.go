@@ -0,0 +1,136 @@
+package domain
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+func graphResources() *discovery.DiscoveredResources {
+	return &discovery.DiscoveredResources{
+		Queries: []discovery.DiscoveredQuery{
+			{Name: "Latency", Package: "obs", Dataset: "api", File: "/src/obs/queries.go", Line: 5},
+			{Name: "Errors", Package: "obs", Dataset: "api", File: "/src/obs/queries.go", Line: 12},
+			// Inline query of Slow
+			{Name: "Slow", Package: "obs", Dataset: "api", File: "/src/obs/triggers.go", Line: 9},
+		},
+		Boards: []discovery.DiscoveredBoard{
+			{Name: "Overview", Package: "obs", File: "/src/obs/boards.go", Line: 3, QueryRefs: []string{"Latency", "Latency", "Missing"}},
+		},
+		SLOs: []discovery.DiscoveredSLO{
+			{Name: "Availability", Package: "obs", File: "/src/obs/slos.go", Line: 3, GoodEventsQueryRef: "Errors", TotalEventsQueryRef: "Latency"},
+		},
+		Triggers: []discovery.DiscoveredTrigger{
+			{Name: "HighLatency", Package: "obs", File: "/src/obs/triggers.go", Line: 3, QueryRef: "Latency"},
+			{Name: "Slow", Package: "obs", File: "/src/obs/triggers.go", Line: 8},
+		},
+	}
+}
+
+func TestNewDependencyGraph(t *testing.T) {
+	g := NewDependencyGraph(graphResources(), "/src")
+
+	var ids []string
+	for _, n := range g.Nodes {
+		ids = append(ids, n.ID)
+	}
+	wantIDs := []string{"queries/Latency", "queries/Errors", "boards/Overview", "slos/Availability", "triggers/HighLatency", "triggers/Slow"}
+	if !reflect.DeepEqual(ids, wantIDs) {
+		t.Errorf("nodes: expected %v, got %v", wantIDs, ids)
+	}
+
+	wantNode := GraphNode{ID: "triggers/HighLatency", Type: "trigger", Name: "HighLatency", Package: "obs", Dataset: "api", File: "obs/triggers.go", Line: 3}
+	if g.Nodes[4] != wantNode {
+		t.Errorf("expected %+v, got %+v", wantNode, g.Nodes[4])
+	}
+
+	wantEdges := []GraphEdge{
+		{From: "boards/Overview", To: "queries/Latency", Type: EdgePanel},
+		{From: "slos/Availability", To: "queries/Errors", Type: EdgeGoodEvents},
+		{From: "slos/Availability", To: "queries/Latency", Type: EdgeTotalEvents},
+		{From: "triggers/HighLatency", To: "queries/Latency", Type: EdgeQuery},
+	}
+	if !reflect.DeepEqual(g.Edges, wantEdges) {
+		t.Errorf("edges: expected %v, got %v", wantEdges, g.Edges)
+	}
+}
+
+func TestNewDependencyGraph_Empty(t *testing.T) {
+	g := NewDependencyGraph(&discovery.DiscoveredResources{}, "/src")
+	if g.Nodes == nil || g.Edges == nil {
+		t.Errorf("expected empty, non-nil nodes and edges, got %+v", g)
+	}
+}
+
+func TestDependencyGraph_Mermaid(t *testing.T) {
+	g := NewDependencyGraph(graphResources(), "/src")
+	// Slow is both a trigger and a query name elsewhere
+	g.Nodes = append(g.Nodes, GraphNode{ID: "queries/Slow", Type: "query", Name: "Slow", Package: "obs"})
+
+	out := g.Mermaid(graphStyle{direction: "LR", clusterBy: "dataset"})
+	for _, want := range []string{
+		"graph LR\n",
+		"  subgraph cluster_0 [\"api\"]\n    Latency[Latency]\n",
+		"    Availability([Availability])\n",
+		"    HighLatency>HighLatency]\n",
+		"  Overview{{Overview}}\n",
+		"  query_Slow[Slow]\n",
+		"  Overview --> Latency\n",
+		"  Availability -->|good| Errors\n",
+		"  Availability -->|total| Latency\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestDependencyGraph_DOT(t *testing.T) {
+	g := NewDependencyGraph(graphResources(), "/src")
+
+	out := g.DOT(graphStyle{direction: "TB", clusterBy: "type"})
+	for _, want := range []string{
+		"digraph G {\n  rankdir=TB;\n",
+		"  subgraph cluster_0 {\n    label=\"query\";\n    Latency [shape=box];\n    Errors [shape=box];\n  }\n",
+		"    Overview [shape=folder];\n",
+		"    Availability [shape=ellipse];\n",
+		"    HighLatency [shape=diamond];\n",
+		"  Availability -> Errors [label=\"good\"];\n",
+		"  HighLatency -> Latency;\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+
+	out = g.DOT(graphStyle{direction: "TB", clusterBy: "none"})
+	if strings.Contains(out, "subgraph") {
+		t.Errorf("expected no clusters, got:\n%s", out)
+	}
+}
+
+func TestOptionsGraphStyle(t *testing.T) {
+	tests := []struct {
+		direction, clusterBy string
+		want                 graphStyle
+		wantErr              bool
+	}{
+		{"", "", graphStyle{direction: "TB", clusterBy: "dataset"}, false},
+		{"lr", "Package", graphStyle{direction: "LR", clusterBy: "package"}, false},
+		{"TD", "none", graphStyle{direction: "TB", clusterBy: "none"}, false},
+		{"XY", "", graphStyle{}, true},
+		{"", "owner", graphStyle{}, true},
+	}
+	for _, tt := range tests {
+		got, err := Options{GraphDirection: tt.direction, GraphClusterBy: tt.clusterBy}.graphStyle()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q/%q: expected error %v, got %v", tt.direction, tt.clusterBy, tt.wantErr, err)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("%q/%q: expected %+v, got %+v", tt.direction, tt.clusterBy, tt.want, got)
+		}
+	}
+}
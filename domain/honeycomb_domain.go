@@ -2,18 +2,29 @@
 package domain
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"time"
 
 	coredomain "github.com/lex00/wetwire-core-go/domain"
 	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/apiversion"
+	"github.com/lex00/wetwire-honeycomb-go/internal/atomicfile"
 	"github.com/lex00/wetwire-honeycomb-go/internal/differ"
 	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/i18n"
 	"github.com/lex00/wetwire-honeycomb-go/internal/lint"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lock"
+	"github.com/lex00/wetwire-honeycomb-go/internal/normalize"
+	"github.com/lex00/wetwire-honeycomb-go/internal/refs"
 	"github.com/lex00/wetwire-honeycomb-go/internal/serialize"
 	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/resource"
 	"github.com/lex00/wetwire-honeycomb-go/slo"
 	"github.com/lex00/wetwire-honeycomb-go/trigger"
 	"github.com/spf13/cobra"
@@ -22,6 +33,10 @@ import (
 // Version is set at build time
 var Version = "dev"
 
+// EnvRetentionDays overrides the data retention (in days) lint uses to check
+// absolute time ranges.
+const EnvRetentionDays = "HONEYCOMB_RETENTION_DAYS"
+
 // Re-export core types for convenience
 type (
 	Context      = coredomain.Context
@@ -31,6 +46,7 @@ type (
 	ValidateOpts = coredomain.ValidateOpts
 	ListOpts     = coredomain.ListOpts
 	GraphOpts    = coredomain.GraphOpts
+	ImportOpts   = coredomain.ImportOpts
 	Result       = coredomain.Result
 	Error        = coredomain.Error
 )
@@ -43,14 +59,20 @@ var (
 )
 
 // HoneycombDomain implements the Domain interface for Honeycomb observability.
-type HoneycombDomain struct{}
+type HoneycombDomain struct {
+	// Options apply to the builder, linter, validator, lister and grapher
+	// of the domain, also when set after they are returned, so the commands
+	// created from them can set Options from their flags
+	Options Options
+}
 
 // Compile-time checks
 var (
-	_ coredomain.Domain        = (*HoneycombDomain)(nil)
-	_ coredomain.ListerDomain  = (*HoneycombDomain)(nil)
-	_ coredomain.GrapherDomain = (*HoneycombDomain)(nil)
-	_ coredomain.DifferDomain  = (*HoneycombDomain)(nil)
+	_ coredomain.Domain         = (*HoneycombDomain)(nil)
+	_ coredomain.ListerDomain   = (*HoneycombDomain)(nil)
+	_ coredomain.GrapherDomain  = (*HoneycombDomain)(nil)
+	_ coredomain.DifferDomain   = (*HoneycombDomain)(nil)
+	_ coredomain.ImporterDomain = (*HoneycombDomain)(nil)
 )
 
 // Name returns "honeycomb"
@@ -65,12 +87,12 @@ func (d *HoneycombDomain) Version() string {
 
 // Builder returns the Honeycomb builder implementation
 func (d *HoneycombDomain) Builder() coredomain.Builder {
-	return &honeycombBuilder{}
+	return &honeycombBuilder{opts: &d.Options}
 }
 
 // Linter returns the Honeycomb linter implementation
 func (d *HoneycombDomain) Linter() coredomain.Linter {
-	return &honeycombLinter{}
+	return &honeycombLinter{opts: &d.Options}
 }
 
 // Initializer returns the Honeycomb initializer implementation
@@ -80,17 +102,22 @@ func (d *HoneycombDomain) Initializer() coredomain.Initializer {
 
 // Validator returns the Honeycomb validator implementation
 func (d *HoneycombDomain) Validator() coredomain.Validator {
-	return &honeycombValidator{}
+	return &honeycombValidator{opts: &d.Options}
 }
 
 // Lister returns the Honeycomb lister implementation
 func (d *HoneycombDomain) Lister() coredomain.Lister {
-	return &honeycombLister{}
+	return &honeycombLister{opts: &d.Options}
 }
 
 // Grapher returns the Honeycomb grapher implementation
 func (d *HoneycombDomain) Grapher() coredomain.Grapher {
-	return &honeycombGrapher{}
+	return &honeycombGrapher{opts: &d.Options}
+}
+
+// Importer returns the Honeycomb importer implementation
+func (d *HoneycombDomain) Importer() coredomain.Importer {
+	return &honeycombImporter{}
 }
 
 // Differ returns the Honeycomb differ implementation
@@ -104,117 +131,479 @@ func CreateRootCommand(d coredomain.Domain) *cobra.Command {
 }
 
 // honeycombBuilder implements domain.Builder
-type honeycombBuilder struct{}
+type honeycombBuilder struct {
+	opts *Options
+}
 
+// Build builds the resources in path, which may list several package
+// patterns (see JoinPatterns).
 func (b *honeycombBuilder) Build(ctx *Context, path string, opts BuildOpts) (*Result, error) {
-	absPath, err := filepath.Abs(path)
+	options := *b.opts
+	mode, err := OutputMode(options.OutputMode, opts.Output)
 	if err != nil {
-		return nil, fmt.Errorf("resolve path: %w", err)
+		return nil, err
 	}
 
 	// Discover all resources
-	resources, err := discovery.DiscoverAll(absPath)
+	filter := options.Filter
+	resources, dirs, err := discoverPath(path, filter)
 	if err != nil {
-		return nil, fmt.Errorf("discovery failed: %w", err)
+		return nil, err
 	}
 
-	if resources.TotalCount() == 0 {
-		return NewErrorResult("no resources found", Error{
+	// The lockfile is kept in the directory containing every package
+	absPath := discovery.CommonDir(dirs)
+
+	// Fields discovery cannot read would be silently left out
+	if errs := fieldMistakeErrors(resources); len(errs) > 0 {
+		return NewErrorResultMultiple(i18n.T("unknown or mistyped fields"), errs), nil
+	}
+
+	// discoverPath kept the resources selected by --only and --exclude
+	if resources.TotalCount() == 0 && !filter.IsZero() {
+		return NewErrorResult(i18n.T("no resources found"), Error{
 			Path:    absPath,
-			Message: "no queries, boards, SLOs, or triggers found",
+			Message: i18n.T("no resources match --only and --exclude"),
 		}), nil
 	}
 
-	// Build output structure
-	outputData := make(map[string]json.RawMessage)
+	if resources.TotalCount() == 0 {
+		return withWarnings(NewErrorResult(i18n.T("no resources found"), Error{
+			Path:    absPath,
+			Message: i18n.T("no queries, boards, SLOs, or triggers found"),
+		}), unsupportedWarnings(resources)), nil
+	}
+
+	// With --keep-going, resources failing the checks below are left out
+	keepGoing := options.KeepGoing && opts.Format != FormatGrafana
+	var failed []BuildError
+	if keepGoing {
+		failed = removeFailing(resources, options.AllowEnv)
+	}
+
+	// Expand ${VAR} in datasets and recipient targets
+	if errs := ExpandEnv(resources, options.AllowEnv); len(errs) > 0 {
+		return NewErrorResultMultiple(i18n.T("environment interpolation failed"), errs), nil
+	}
+
+	// Honeycomb rejects duplicate trigger and SLO names in a dataset
+	if errs := nameConflictErrors(resources, options.RenameOnConflict); len(errs) > 0 {
+		return NewErrorResultMultiple(i18n.T("duplicate trigger or SLO names"), errs), nil
+	}
+
+	// Reject time ranges that would serialize to nonsense
+	if errs := timeRangeErrors(resources.Queries); len(errs) > 0 {
+		return NewErrorResultMultiple(i18n.T("invalid time ranges"), errs), nil
+	}
+
+	// With --normalize-windows, fit query time ranges to their triggers and SLOs
+	var windowWarnings []Error
+	if options.NormalizeWindows {
+		windowWarnings = NormalizeWindows(resources)
+	}
+
+	if opts.Format == FormatGrafana {
+		return buildGrafana(resources, opts, options.OutputMode)
+	}
 
 	// Filter by type if specified
 	resourceType := opts.Type
 
+	options.KeepGoing = keepGoing
+	manifest, serializeErrs, err := serializeResources(resources, resourceType, options)
+	if err != nil {
+		return nil, err
+	}
+	failed = append(failed, serializeErrs...)
+	sortBuildErrors(failed)
+
+	// Warn about fields the Honeycomb API deprecated, or with --strict-api
+	// reject them
+	deprecated, err := deprecationErrors(resources, manifest, options)
+	if err != nil {
+		return nil, err
+	}
+	if len(deprecated) > 0 && options.StrictAPI {
+		return NewErrorResultMultiple(i18n.T("fields deprecated by the Honeycomb API"), deprecated), nil
+	}
+	warnings := append(append(deprecated, unsupportedWarnings(resources)...), windowWarnings...)
+
+	// Build output structure
+	manifest.Extra = make(map[string]json.RawMessage)
+	if len(failed) > 0 {
+		data, _ := json.Marshal(failed)
+		manifest.Extra[ErrorsSection] = data
+	}
+	if options.Refs {
+		references, err := References(resources, manifest)
+		if err != nil {
+			return nil, err
+		}
+		data, _ := json.Marshal(references)
+		manifest.Extra[refs.Section] = data
+	}
+
+	// Format output
+	var jsonData []byte
+	if opts.Format == "pretty" {
+		jsonData, err = json.MarshalIndent(manifest, "", "  ")
+	} else {
+		jsonData, err = manifest.Marshal()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("serialization failed: %w", err)
+	}
+
+	// Handle output file or directory
+	written := !opts.DryRun && mode != OutputStdout
+	if written {
+		if mode == OutputDir {
+			if err := WriteOutputDir(opts.Output, manifest, opts.Format == "pretty"); err != nil {
+				return nil, err
+			}
+		} else if err := atomicfile.WriteFile(opts.Output, jsonData, 0644); err != nil {
+			return nil, fmt.Errorf("write output: %w", err)
+		}
+	}
+
+	// A partial lockfile would record the failed resources as removed
+	if len(failed) > 0 {
+		if written {
+			return withWarnings(buildErrorResult(i18n.Sprintf("Wrote %s with %d failed resource(s)", opts.Output, len(failed)), "", failed), warnings), nil
+		}
+		return withWarnings(buildErrorResult(i18n.Sprintf("%d resource(s) failed to build", len(failed)), string(jsonData), failed), warnings), nil
+	}
+
+	// Record provenance of a full build for drift detection, next to the
+	// sources rather than the output
+	if options.WriteLock && !opts.DryRun && resourceType == "" && filter.IsZero() {
+		lf, lerr := lock.Generate(manifest)
+		if lerr != nil {
+			return nil, fmt.Errorf("generate lockfile: %w", lerr)
+		}
+		if lerr := lock.Write(filepath.Join(absPath, lock.FileName), lf); lerr != nil {
+			return nil, fmt.Errorf("write lockfile: %w", lerr)
+		}
+	}
+
+	if written {
+		return withWarnings(NewResult(i18n.Sprintf("Wrote %s", opts.Output)), warnings), nil
+	}
+	return withWarnings(NewResultWithData(i18n.T("Build completed"), string(jsonData)), warnings), nil
+}
+
+// SerializeResources serializes discovered resources to the build output
+// Manifest, the Honeycomb JSON of each resource keyed by resource type and
+// then Go variable name. resourceType optionally limits the output to
+// one type. Unlike Build, it does not validate the resources. Boards, SLOs
+// and triggers without a Description take their Go doc comment. The output is
+// normalized with opts.Normalize, and trigger and SLO descriptions still
+// empty are filled in with opts.Describe. A trigger referencing a query by
+// name gets a query_id placeholder naming it (see internal/refs), or with
+// opts.QueryMode set to QueryModeInline, the query spec itself. Queries are
+// then rewritten for the API variant of opts.APIVersion. Maintenance windows
+// are written to a "mutes" section listing the triggers they select; an
+// invalid window is an error.
+func SerializeResources(resources *discovery.DiscoveredResources, resourceType string, opts Options) (*honeycomb.Manifest, error) {
+	opts.KeepGoing = false
+	output, _, err := serializeResources(resources, resourceType, opts)
+	return output, err
+}
+
+// serializeResources is SerializeResources. With opts.KeepGoing, a resource
+// that fails to serialize is left out of the output and returned as a
+// BuildError instead of failing the whole build.
+func serializeResources(resources *discovery.DiscoveredResources, resourceType string, opts Options) (*honeycomb.Manifest, []BuildError, error) {
+	output := &honeycomb.Manifest{}
+	failed := &buildErrors{keepGoing: opts.KeepGoing}
+
+	descriptions, err := opts.descriptionTemplates()
+	if err != nil {
+		return nil, nil, err
+	}
+	mode, err := opts.queryMode()
+	if err != nil {
+		return nil, nil, err
+	}
+	version, err := apiversion.Lookup(opts.APIVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Serialize queries
 	if (resourceType == "" || resourceType == "query" || resourceType == "queries") && len(resources.Queries) > 0 {
 		queryMap := make(map[string]json.RawMessage)
 		for _, dq := range resources.Queries {
-			q := discoveredToQuery(dq)
-			data, serr := serialize.ToJSON(q)
-			if serr != nil {
-				return nil, fmt.Errorf("query serialization failed: %w", serr)
+			q := dq.Query()
+			data, err := serialize.ToJSON(q)
+			if err != nil {
+				if err := failed.add("queries", dq.Name, dq.File, dq.Line, fmt.Errorf("query serialization failed: %w", err)); err != nil {
+					return nil, nil, err
+				}
+				continue
 			}
 			queryMap[dq.Name] = data
 		}
-		data, _ := json.Marshal(queryMap)
-		outputData["queries"] = data
+		output.Queries = queryMap
 	}
 
 	// Serialize boards
 	if (resourceType == "" || resourceType == "board" || resourceType == "boards") && len(resources.Boards) > 0 {
 		boardMap := make(map[string]json.RawMessage)
 		for _, db := range resources.Boards {
+			if db.Description == "" {
+				db.Description = db.Doc
+			}
 			b := discoveredToBoard(db)
-			data, serr := serialize.BoardToJSON(b)
-			if serr != nil {
-				return nil, fmt.Errorf("board serialization failed: %w", serr)
+			data, err := serialize.BoardToJSON(b)
+			if err != nil {
+				if err := failed.add("boards", db.Name, db.File, db.Line, fmt.Errorf("board serialization failed: %w", err)); err != nil {
+					return nil, nil, err
+				}
+				continue
 			}
 			boardMap[db.Name] = data
 		}
-		data, _ := json.Marshal(boardMap)
-		outputData["boards"] = data
+		output.Boards = boardMap
 	}
 
 	// Serialize SLOs
 	if (resourceType == "" || resourceType == "slo" || resourceType == "slos") && len(resources.SLOs) > 0 {
 		sloMap := make(map[string]json.RawMessage)
 		for _, ds := range resources.SLOs {
+			if ds.Description == "" {
+				ds.Description = ds.Doc
+			}
+			if ds.Description == "" && descriptions != nil {
+				if ds.Description, err = descriptions.SLO(ds); err != nil {
+					if err := failed.add("slos", ds.Name, ds.File, ds.Line, fmt.Errorf("slo %s: %w", ds.Name, err)); err != nil {
+						return nil, nil, err
+					}
+					continue
+				}
+			}
 			s := discoveredToSLO(ds)
-			data, serr := serialize.SLOToJSON(s)
-			if serr != nil {
-				return nil, fmt.Errorf("SLO serialization failed: %w", serr)
+			s.SLI = discoveredSLI(ds, resources.Queries)
+			data, err := serialize.SLOToJSON(s)
+			if err != nil {
+				if err := failed.add("slos", ds.Name, ds.File, ds.Line, fmt.Errorf("SLO serialization failed: %w", err)); err != nil {
+					return nil, nil, err
+				}
+				continue
 			}
 			sloMap[ds.Name] = data
 		}
-		data, _ := json.Marshal(sloMap)
-		outputData["slos"] = data
+		output.SLOs = sloMap
 	}
 
 	// Serialize triggers
 	if (resourceType == "" || resourceType == "trigger" || resourceType == "triggers") && len(resources.Triggers) > 0 {
 		triggerMap := make(map[string]json.RawMessage)
 		for _, dt := range resources.Triggers {
+			if dt.Description == "" {
+				dt.Description = dt.Doc
+			}
+			if dt.Description == "" && descriptions != nil {
+				if dt.Description, err = descriptions.Trigger(dt, resources.Queries); err != nil {
+					if err := failed.add("triggers", dt.Name, dt.File, dt.Line, fmt.Errorf("trigger %s: %w", dt.Name, err)); err != nil {
+						return nil, nil, err
+					}
+					continue
+				}
+			}
 			t := discoveredToTrigger(dt)
-			data, serr := serialize.TriggerToJSON(t)
-			if serr != nil {
-				return nil, fmt.Errorf("trigger serialization failed: %w", serr)
+			switch q := dt.Query(resources.Queries); {
+			case q == nil:
+			case mode == QueryModeInline:
+				t.Query = q.Query()
+			case dt.InlineQuery == nil:
+				t.QueryID = refs.Placeholder(lock.Key("queries", q.Name))
+			}
+			data, err := serialize.TriggerToJSON(t)
+			if err != nil {
+				if err := failed.add("triggers", dt.Name, dt.File, dt.Line, fmt.Errorf("trigger serialization failed: %w", err)); err != nil {
+					return nil, nil, err
+				}
+				continue
 			}
 			triggerMap[dt.Name] = data
 		}
-		data, _ := json.Marshal(triggerMap)
-		outputData["triggers"] = data
+		output.Triggers = triggerMap
 	}
 
-	// Format output
-	var jsonData []byte
-	if opts.Format == "pretty" {
-		jsonData, err = json.MarshalIndent(outputData, "", "  ")
-	} else {
-		jsonData, err = json.Marshal(outputData)
+	if opts.Normalize {
+		if err := normalize.Output(output); err != nil {
+			return nil, nil, fmt.Errorf("normalization failed: %w", err)
+		}
 	}
-	if err != nil {
-		return nil, fmt.Errorf("serialization failed: %w", err)
+	if err := version.Output(output); err != nil {
+		return nil, nil, err
 	}
 
-	// Handle output file
-	if !opts.DryRun && opts.Output != "" {
-		if err := os.WriteFile(opts.Output, jsonData, 0644); err != nil {
-			return nil, fmt.Errorf("write output: %w", err)
+	// Serialize maintenance windows with the triggers they mute, for apply
+	if (resourceType == "" || resourceType == "mute" || resourceType == "mutes") && len(resources.Mutes) > 0 {
+		muteMap := make(map[string]json.RawMessage)
+		for _, dm := range resources.Mutes {
+			w := dm.Window()
+			if err := w.Validate(); err != nil {
+				if err := failed.add("mutes", dm.Name, dm.File, dm.Line, fmt.Errorf("maintenance window %s: %w", dm.Name, err)); err != nil {
+					return nil, nil, err
+				}
+				continue
+			}
+			data, err := serialize.MuteToJSON(w, resources.MutedTriggers(dm))
+			if err != nil {
+				if err := failed.add("mutes", dm.Name, dm.File, dm.Line, fmt.Errorf("maintenance window serialization failed: %w", err)); err != nil {
+					return nil, nil, err
+				}
+				continue
+			}
+			muteMap[dm.Name] = data
 		}
-		return NewResult(fmt.Sprintf("Wrote %s", opts.Output)), nil
+		output.SetSection("mutes", muteMap)
 	}
 
-	return NewResultWithData("Build completed", string(jsonData)), nil
+	// Serialize resources of registered kinds, each in its own section
+	for _, kind := range resource.Kinds() {
+		if resourceType != "" && resourceType != kind.Name && resourceType != kind.Section {
+			continue
+		}
+		section := make(map[string]json.RawMessage)
+		for _, r := range resources.Custom {
+			if r.Kind != kind.Name {
+				continue
+			}
+			data, err := serializeCustom(kind, r)
+			if err != nil {
+				if err := failed.add(kind.Section, r.Name, r.File, r.Line, fmt.Errorf("%s serialization failed: %w", kind.Name, err)); err != nil {
+					return nil, nil, err
+				}
+				continue
+			}
+			section[r.Name] = data
+		}
+		if len(section) > 0 {
+			output.SetSection(kind.Section, section)
+		}
+	}
+	return output, failed.errs, nil
+}
+
+// serializeCustom returns the build output of a resource of a registered
+// kind: the result of the kind's Serialize function, or the resource's fields.
+func serializeCustom(kind resource.Kind, r resource.Resource) (json.RawMessage, error) {
+	var v any = r.Fields
+	if r.Fields == nil {
+		v = map[string]any{}
+	}
+	if kind.Serialize != nil {
+		var err error
+		if v, err = kind.Serialize(r); err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Name, err)
+		}
+	}
+	return json.Marshal(v)
+}
+
+// filterByTags returns the boards and triggers of resources whose tags match
+// every selector. Queries and SLOs have no tags and are left out.
+func filterByTags(resources *discovery.DiscoveredResources, selectors []string) *discovery.DiscoveredResources {
+	filtered := &discovery.DiscoveredResources{}
+	for _, b := range resources.Boards {
+		if discovery.MatchTags(b.Tags, selectors) {
+			filtered.Boards = append(filtered.Boards, b)
+		}
+	}
+	for _, t := range resources.Triggers {
+		if discovery.MatchTags(t.Tags, selectors) {
+			filtered.Triggers = append(filtered.Triggers, t)
+		}
+	}
+	return filtered
+}
+
+// referencedQueries returns the queries referenced by name from the boards and
+// triggers of resources.
+func referencedQueries(queries []discovery.DiscoveredQuery, resources *discovery.DiscoveredResources) []discovery.DiscoveredQuery {
+	refs := make(map[string]bool)
+	for _, b := range resources.Boards {
+		for _, ref := range b.QueryRefs {
+			refs[ref] = true
+		}
+	}
+	for _, t := range resources.Triggers {
+		refs[t.QueryRef] = true
+	}
+
+	var result []discovery.DiscoveredQuery
+	for _, q := range queries {
+		if refs[q.Name] {
+			result = append(result, q)
+		}
+	}
+	return result
+}
+
+// customRefs returns the references of a custom resource that name another
+// resource, once each in order.
+func customRefs(r resource.Resource, resources *discovery.DiscoveredResources) []string {
+	names := make(map[string]bool)
+	for _, q := range resources.Queries {
+		names[q.Name] = true
+	}
+	for _, b := range resources.Boards {
+		names[b.Name] = true
+	}
+	for _, s := range resources.SLOs {
+		names[s.Name] = true
+	}
+	for _, t := range resources.Triggers {
+		names[t.Name] = true
+	}
+	for _, c := range resources.Custom {
+		names[c.Name] = true
+	}
+
+	var refs []string
+	seen := make(map[string]bool)
+	for _, ref := range r.Refs {
+		if names[ref] && ref != r.Name && !seen[ref] {
+			refs = append(refs, ref)
+			seen[ref] = true
+		}
+	}
+	return refs
+}
+
+// timeRangeErrors reports query time ranges that are malformed regardless of
+// when they are queried: unconvertible query.AbsoluteIn arguments, start not
+// before end, or negative values. Retention is checked by lint (WHC016).
+func timeRangeErrors(queries []discovery.DiscoveredQuery) []Error {
+	var errs []Error
+	for _, dq := range queries {
+		tr := dq.TimeRange
+		message := tr.Err
+		if message == "" {
+			if err := (query.TimeRange{TimeRange: tr.TimeRange, StartTime: tr.StartTime, EndTime: tr.EndTime}).ValidateAt(time.Time{}, 0); err != nil {
+				message = err.Error()
+			}
+		}
+		if message != "" {
+			errs = append(errs, Error{
+				Path:     dq.File,
+				Line:     dq.Line,
+				Severity: "error",
+				Message:  fmt.Sprintf("query %s: %s", dq.Name, message),
+			})
+		}
+	}
+	return errs
 }
 
 // honeycombLinter implements domain.Linter
-type honeycombLinter struct{}
+type honeycombLinter struct {
+	opts *Options
+}
 
 func (l *honeycombLinter) Lint(ctx *Context, path string, opts LintOpts) (*Result, error) {
 	absPath, err := filepath.Abs(path)
@@ -229,8 +618,15 @@ func (l *honeycombLinter) Lint(ctx *Context, path string, opts LintOpts) (*Resul
 	}
 
 	// Build lint config from opts
-	config := lint.LintConfig{
-		DisabledRules: opts.Disable,
+	config, err := lintConfig(absPath, opts.Disable, l.opts.AllowOrphans)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case l.opts.Profile:
+		config.Timings = &lint.Timings{Memory: true}
+	case l.opts.Debug:
+		config.Timings = &lint.Timings{}
 	}
 
 	// Run lint on all resources with config
@@ -239,7 +635,7 @@ func (l *honeycombLinter) Lint(ctx *Context, path string, opts LintOpts) (*Resul
 	// Handle Fix mode - auto-fix is not yet implemented
 	if opts.Fix {
 		if len(results) == 0 {
-			return NewResult("No lint issues found (fix mode requested, no fixable issues)"), nil
+			return NewResult(i18n.T("No lint issues found (fix mode requested, no fixable issues)")), nil
 		}
 		// Auto-fix not yet implemented, return message indicating this
 		errs := make([]Error, 0, len(results))
@@ -252,11 +648,15 @@ func (l *honeycombLinter) Lint(ctx *Context, path string, opts LintOpts) (*Resul
 				Code:     r.Rule,
 			})
 		}
-		return NewErrorResultMultiple("lint issues found (auto-fix not yet implemented)", errs), nil
+		return NewErrorResultMultiple(i18n.T("lint issues found (auto-fix not yet implemented)"), errs), nil
 	}
 
 	if len(results) == 0 {
-		return NewResult("No lint issues found"), nil
+		result := NewResult(i18n.T("No lint issues found"))
+		if config.Timings != nil {
+			result.Data = &LintReport{Timings: config.Timings.Rules()}
+		}
+		return result, nil
 	}
 
 	// Convert to domain errors
@@ -271,7 +671,35 @@ func (l *honeycombLinter) Lint(ctx *Context, path string, opts LintOpts) (*Resul
 		})
 	}
 
-	return NewErrorResultMultiple("lint issues found", errs), nil
+	result := NewErrorResultMultiple(i18n.T("lint issues found"), errs)
+	if security := newSecurityReport(results); security != nil || config.Timings != nil {
+		result.Data = &LintReport{Security: security, Timings: config.Timings.Rules()}
+	}
+	return result, nil
+}
+
+// lintConfig returns the lint configuration of the project containing dir,
+// with the disabled rules and the name patterns of queries accepted without
+// a user.
+func lintConfig(dir string, disabled, allowOrphans []string) (lint.LintConfig, error) {
+	projectConfig, err := discovery.LoadConfig(dir)
+	if err != nil {
+		return lint.LintConfig{}, fmt.Errorf("load config: %w", err)
+	}
+
+	config := lint.LintConfig{
+		DisabledRules:   disabled,
+		Naming:          projectConfig.Naming,
+		OrphanAllowlist: allowOrphans,
+	}
+	if days := os.Getenv(EnvRetentionDays); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return lint.LintConfig{}, fmt.Errorf("%s must be a positive number of days, got %q", EnvRetentionDays, days)
+		}
+		config.RetentionDays = n
+	}
+	return config, nil
 }
 
 // honeycombInitializer implements domain.Initializer
@@ -354,7 +782,7 @@ var RequestLatency = query.Query{
 	created = append(created, "expected/queries/queries.go")
 
 	return NewResultWithData(
-		fmt.Sprintf("Created scenario %s with %d files", name, len(created)),
+		i18n.Sprintf("Created scenario %s with %d files", name, len(created)),
 		created,
 	), nil
 }
@@ -421,22 +849,31 @@ var ErrorRate = query.Query{
 	}
 
 	return NewResultWithData(
-		fmt.Sprintf("Created %s with example queries", path),
+		i18n.Sprintf("Created %s with example queries", path),
 		[]string{"go.mod", "queries.go"},
 	), nil
 }
 
 // honeycombValidator implements domain.Validator
-type honeycombValidator struct{}
+type honeycombValidator struct {
+	opts *Options
+}
 
 func (v *honeycombValidator) Validate(ctx *Context, path string, opts ValidateOpts) (*Result, error) {
+	// Generated JSON, e.g. piped from build, is checked directly
+	if isJSONSource(path) {
+		return validateJSON(path)
+	}
+
 	// For now, validation is the same as lint
-	linter := &honeycombLinter{}
+	linter := &honeycombLinter{opts: v.opts}
 	return linter.Lint(ctx, path, LintOpts{})
 }
 
 // honeycombLister implements domain.Lister
-type honeycombLister struct{}
+type honeycombLister struct {
+	opts *Options
+}
 
 func (l *honeycombLister) List(ctx *Context, path string, opts ListOpts) (*Result, error) {
 	absPath, err := filepath.Abs(path)
@@ -449,43 +886,56 @@ func (l *honeycombLister) List(ctx *Context, path string, opts ListOpts) (*Resul
 	if err != nil {
 		return nil, fmt.Errorf("discovery failed: %w", err)
 	}
+	if selectors := l.opts.Tags; len(selectors) > 0 {
+		resources = filterByTags(resources, selectors)
+	}
+	if filter := l.opts.Filter; !filter.IsZero() {
+		if err := filter.Validate(); err != nil {
+			return nil, err
+		}
+		resources = filter.Apply(resources)
+	}
 
 	// Build list
+	dsl := l.opts.DSL
 	list := make([]map[string]string, 0)
+	add := func(name, typ, file, description string, q *discovery.DiscoveredQuery) {
+		entry := map[string]string{
+			"name": name,
+			"type": typ,
+			"file": file,
+		}
+		if description != "" {
+			entry["description"] = description
+		}
+		if dsl && q != nil {
+			entry["dsl"] = q.Query().String()
+		}
+		list = append(list, entry)
+	}
 	for _, q := range resources.Queries {
-		list = append(list, map[string]string{
-			"name": q.Name,
-			"type": "query",
-			"file": q.File,
-		})
+		add(q.Name, "query", q.File, q.Doc, &q)
 	}
 	for _, b := range resources.Boards {
-		list = append(list, map[string]string{
-			"name": b.Name,
-			"type": "board",
-			"file": b.File,
-		})
+		add(b.Name, "board", b.File, cmp.Or(b.Description, b.Doc), nil)
 	}
 	for _, s := range resources.SLOs {
-		list = append(list, map[string]string{
-			"name": s.Name,
-			"type": "slo",
-			"file": s.File,
-		})
+		add(s.Name, "slo", s.File, cmp.Or(s.Description, s.Doc), nil)
 	}
 	for _, t := range resources.Triggers {
-		list = append(list, map[string]string{
-			"name": t.Name,
-			"type": "trigger",
-			"file": t.File,
-		})
+		add(t.Name, "trigger", t.File, cmp.Or(t.Description, t.Doc), t.InlineQuery)
+	}
+	for _, r := range resources.Custom {
+		add(r.Name, r.Kind, r.File, "", nil)
 	}
 
-	return NewResultWithData(fmt.Sprintf("Discovered %d resources", len(list)), list), nil
+	return NewResultWithData(i18n.Sprintf("Discovered %d resources", len(list)), list), nil
 }
 
 // honeycombGrapher implements domain.Grapher
-type honeycombGrapher struct{}
+type honeycombGrapher struct {
+	opts *Options
+}
 
 func (g *honeycombGrapher) Graph(ctx *Context, path string, opts GraphOpts) (*Result, error) {
 	absPath, err := filepath.Abs(path)
@@ -498,77 +948,52 @@ func (g *honeycombGrapher) Graph(ctx *Context, path string, opts GraphOpts) (*Re
 	if err != nil {
 		return nil, fmt.Errorf("discovery failed: %w", err)
 	}
+	if selectors := g.opts.Tags; len(selectors) > 0 {
+		// Keep the queries the tagged boards and triggers use
+		tagged := filterByTags(resources, selectors)
+		tagged.Queries = referencedQueries(resources.Queries, tagged)
+		resources = tagged
+	}
+	if filter := g.opts.Filter; !filter.IsZero() {
+		if err := filter.Validate(); err != nil {
+			return nil, err
+		}
+		resources = filter.Apply(resources)
+	}
 
-	// Generate DOT format graph
+	deps := NewDependencyGraph(resources, absPath)
 	var graph string
 	switch opts.Format {
-	case "dot", "":
-		graph = "digraph G {\n"
-		for _, q := range resources.Queries {
-			graph += fmt.Sprintf("  %s [shape=box];\n", q.Name)
-		}
-		for _, b := range resources.Boards {
-			graph += fmt.Sprintf("  %s [shape=folder];\n", b.Name)
-			// Boards reference queries
-			for _, q := range resources.Queries {
-				graph += fmt.Sprintf("  %s -> %s;\n", b.Name, q.Name)
-			}
-		}
-		graph += "}"
-	case "mermaid":
-		graph = "graph TD\n"
-		for _, q := range resources.Queries {
-			graph += fmt.Sprintf("  %s[%s]\n", q.Name, q.Name)
+	case "json", "yaml":
+		return NewResultWithData(i18n.T("Graph generated"), deps), nil
+	case "dot", "text", "", "mermaid":
+		style, err := g.opts.graphStyle()
+		if err != nil {
+			return nil, err
 		}
-		for _, b := range resources.Boards {
-			graph += fmt.Sprintf("  %s{{%s}}\n", b.Name, b.Name)
+		graph = deps.DOT(style)
+		if opts.Format == "mermaid" {
+			graph = deps.Mermaid(style)
 		}
 	default:
 		return nil, fmt.Errorf("unknown format: %s", opts.Format)
 	}
 
-	return NewResultWithData("Graph generated", graph), nil
+	return NewResultWithData(i18n.T("Graph generated"), graph), nil
 }
 
 // Helper functions
 
-// discoveredToQuery converts a DiscoveredQuery to a query.Query
-func discoveredToQuery(dq discovery.DiscoveredQuery) query.Query {
-	q := query.Query{
-		Dataset: dq.Dataset,
-		TimeRange: query.TimeRange{
-			TimeRange: dq.TimeRange.TimeRange,
-			StartTime: dq.TimeRange.StartTime,
-			EndTime:   dq.TimeRange.EndTime,
-		},
-		Breakdowns: dq.Breakdowns,
-		Limit:      dq.Limit,
-	}
-
-	for _, c := range dq.Calculations {
-		q.Calculations = append(q.Calculations, query.Calculation{
-			Op:     c.Op,
-			Column: c.Column,
-		})
-	}
-
-	for _, f := range dq.Filters {
-		q.Filters = append(q.Filters, query.Filter{
-			Column: f.Column,
-			Op:     f.Op,
-			Value:  f.Value,
-		})
-	}
-
-	return q
-}
-
 // discoveredToBoard converts a DiscoveredBoard to a board.Board
 func discoveredToBoard(db discovery.DiscoveredBoard) board.Board {
-	return board.Board{
+	b := board.Board{
 		Name:        db.BoardName,
 		Description: db.Description,
 	}
+	for _, t := range db.Tags {
+		b.Tags = append(b.Tags, board.Tag{Key: t.Key, Value: t.Value})
+	}
+	return b
 }
 
 // discoveredToSLO converts a DiscoveredSLO to an slo.SLO
@@ -582,13 +1007,45 @@ func discoveredToSLO(ds discovery.DiscoveredSLO) slo.SLO {
 	}
 }
 
+// discoveredSLI returns the SLI of a DiscoveredSLO as build writes it: the
+// alias, and good and total events queries, inline or referenced by name,
+// reduced to their datasets. Honeycomb's SLO API takes the alias and, for an
+// SLO across datasets, the dataset slugs, not the queries.
+func discoveredSLI(ds discovery.DiscoveredSLO, queries []discovery.DiscoveredQuery) slo.SLI {
+	dataset := func(inline *discovery.DiscoveredQuery, ref string) string {
+		if inline != nil {
+			return inline.Dataset
+		}
+		for _, q := range queries {
+			if q.Name == ref {
+				return q.Dataset
+			}
+		}
+		return ""
+	}
+	return slo.SLI{
+		Alias:       ds.SLIAlias,
+		GoodEvents:  query.Query{Dataset: dataset(ds.GoodEventsQuery, ds.GoodEventsQueryRef)},
+		TotalEvents: query.Query{Dataset: dataset(ds.TotalEventsQuery, ds.TotalEventsQueryRef)},
+	}
+}
+
 // discoveredToTrigger converts a DiscoveredTrigger to a trigger.Trigger
 func discoveredToTrigger(dt discovery.DiscoveredTrigger) trigger.Trigger {
-	return trigger.Trigger{
+	t := trigger.Trigger{
 		Name:        dt.TriggerName,
 		Description: dt.Description,
 		Dataset:     dt.Dataset,
+		Threshold:   trigger.Threshold{Op: trigger.Op(dt.ThresholdOp), Value: dt.ThresholdValue},
 		Frequency:   trigger.Seconds(dt.FrequencySeconds),
+		AlertType:   trigger.AlertType(dt.AlertType),
 		Disabled:    dt.Disabled,
 	}
+	for _, r := range dt.Recipients {
+		t.Recipients = append(t.Recipients, trigger.Recipient{Type: trigger.RecipientType(r.Type), Target: r.Target})
+	}
+	for _, tag := range dt.Tags {
+		t.Tags = append(t.Tags, trigger.Tag{Key: tag.Key, Value: tag.Value})
+	}
+	return t
 }
@@ -0,0 +1,583 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/atomicfile"
+	"github.com/lex00/wetwire-honeycomb-go/internal/i18n"
+	"github.com/lex00/wetwire-honeycomb-go/internal/serialize"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+// honeycombImporter implements domain.Importer. It converts Query JSON, or
+// the queries of a build output and the triggers and boards embedding query
+// specs, to Go declarations.
+type honeycombImporter struct{}
+
+// importedQuery is a query parsed from JSON with the Go name it is declared as.
+type importedQuery struct {
+	Name  string
+	Query query.Query
+
+	// Doc is the declaration's doc comment, without the leading "//"
+	Doc string
+
+	// UsedBy are the imported triggers and boards referencing the query
+	UsedBy []string
+}
+
+// importSet is the resources parsed from an import source.
+type importSet struct {
+	Queries  []importedQuery
+	Triggers []importedTrigger
+	Boards   []importedBoard
+
+	// Shared is the number of embedded query specs that reference a query
+	// declared for another resource instead of being declared again
+	Shared int
+
+	// Skipped are the resource types that were not imported
+	Skipped []string
+}
+
+// Import reads Query JSON from source (StdinPath for standard input) and
+// generates Go code. The code is returned as the result data, or written to
+// opts.Target: a .go file, or a directory the file is created in.
+func (i *honeycombImporter) Import(ctx *Context, source string, opts ImportOpts) (*Result, error) {
+	data, err := readSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	set, err := parseImport(data, importName(source))
+	if err != nil {
+		return NewErrorResult(i18n.T("import failed"), Error{
+			Path:    sourceName(source),
+			Message: err.Error(),
+		}), nil
+	}
+	if len(set.Queries) == 0 {
+		return NewErrorResult(i18n.T("no queries found"), Error{
+			Path:    sourceName(source),
+			Message: i18n.T("input contains no Query JSON"),
+		}), nil
+	}
+
+	message := i18n.Sprintf("Imported %d queries", len(set.Queries))
+	if len(set.Triggers) > 0 || len(set.Boards) > 0 {
+		message = i18n.Sprintf("Imported %d queries, %d boards and %d triggers", len(set.Queries), len(set.Boards), len(set.Triggers))
+	}
+	if set.Shared > 0 {
+		message += " " + i18n.Sprintf("(%d duplicate query specs share a variable)", set.Shared)
+	}
+	if len(set.Skipped) > 0 {
+		message += " " + i18n.Sprintf("(skipped %s: only queries, and triggers and boards embedding queries, can be imported)", strings.Join(set.Skipped, ", "))
+	}
+
+	if opts.Target == "" {
+		code, err := generateImportFile("queries", sourceName(source), set)
+		if err != nil {
+			return nil, err
+		}
+		return NewResultWithData(message, string(code)), nil
+	}
+
+	file := opts.Target
+	if filepath.Ext(file) != ".go" {
+		stem := "imported"
+		if source != StdinPath {
+			stem = strings.TrimSuffix(filepath.Base(source), filepath.Ext(source))
+		}
+		file = filepath.Join(opts.Target, stem+".go")
+	}
+	if _, err := os.Stat(file); err == nil {
+		return NewErrorResult(i18n.T("target exists"), Error{
+			Path:    file,
+			Message: i18n.T("file already exists; remove it or choose another --target"),
+		}), nil
+	}
+
+	code, err := generateImportFile(packageName(filepath.Dir(file)), sourceName(source), set)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return nil, fmt.Errorf("create target directory: %w", err)
+	}
+	if err := atomicfile.WriteFile(file, code, 0644); err != nil {
+		return nil, fmt.Errorf("write %s: %w", file, err)
+	}
+
+	return NewResult(i18n.Sprintf("%s to %s", message, file)), nil
+}
+
+// ImportCounts returns the number of resources importing source would
+// generate, keyed by section as in build output. Standard input cannot be
+// read twice, so StdinPath returns nil.
+func ImportCounts(source string) (map[string]int, error) {
+	if source == StdinPath {
+		return nil, nil
+	}
+	data, err := readSource(source)
+	if err != nil {
+		return nil, err
+	}
+	set, err := parseImport(data, importName(source))
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{honeycomb.SectionQueries: len(set.Queries)}
+	if len(set.Boards) > 0 {
+		counts[honeycomb.SectionBoards] = len(set.Boards)
+	}
+	if len(set.Triggers) > 0 {
+		counts[honeycomb.SectionTriggers] = len(set.Triggers)
+	}
+	return counts, nil
+}
+
+// buildOutputKeys are the top-level keys of the build output.
+var buildOutputKeys = map[string]bool{
+	honeycomb.SectionQueries:  true,
+	honeycomb.SectionBoards:   true,
+	honeycomb.SectionSLOs:     true,
+	honeycomb.SectionTriggers: true,
+}
+
+// parseImport parses data as a single Query JSON object, a build output, or a
+// JSON-formatted command result wrapping either. Of a build output it imports
+// the queries, and the triggers and boards embedding query specs; an embedded
+// spec structurally equal to a query already imported references that query
+// rather than declaring a copy. The resources are sorted by name.
+func parseImport(data []byte, name string) (*importSet, error) {
+	if inner, ok := unwrapResult(data); ok {
+		data = inner
+	}
+
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	if !isBuildOutput(top) {
+		q, err := serialize.FromJSON(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Query JSON: %w", err)
+		}
+		return &importSet{Queries: []importedQuery{{Name: name, Query: q}}}, nil
+	}
+
+	manifest, err := honeycomb.Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid build output: %w", err)
+	}
+	set := &importSet{}
+	skipped := make(map[string]bool)
+	for _, section := range manifest.SectionNames() {
+		switch section {
+		case honeycomb.SectionQueries, honeycomb.SectionTriggers, honeycomb.SectionBoards:
+		default:
+			skipped[section] = true
+		}
+	}
+
+	// Saved queries come first, so embedded specs reference them
+	lib := newQueryLibrary()
+	for _, n := range sortedKeys(manifest.Queries) {
+		q, err := serialize.FromJSON(manifest.Queries[n])
+		if err != nil {
+			return nil, fmt.Errorf("query %s: %w", n, err)
+		}
+		lib.add(goName(n), q)
+	}
+
+	for _, n := range sortedKeys(manifest.Triggers) {
+		t, err := serialize.TriggerFromJSON(manifest.Triggers[n])
+		if err != nil {
+			return nil, fmt.Errorf("trigger %s: %w", n, err)
+		}
+		if !hasQuerySpec(t.Query) {
+			skipped[honeycomb.SectionTriggers] = true
+			continue
+		}
+		it := importedTrigger{Name: lib.declare(goName(n)), Trigger: t}
+		it.Query = lib.use(it.Name+"Query", it.Name, t.Query)
+		set.Triggers = append(set.Triggers, it)
+	}
+
+	for _, n := range sortedKeys(manifest.Boards) {
+		b, err := serialize.BoardFromJSON(manifest.Boards[n])
+		if err != nil {
+			return nil, fmt.Errorf("board %s: %w", n, err)
+		}
+		names, queries := boardQueries(goName(n), b.Panels)
+		if len(queries) == 0 {
+			skipped[honeycomb.SectionBoards] = true
+			continue
+		}
+		ib := importedBoard{Name: lib.declare(goName(n)), Board: b}
+		for i, q := range queries {
+			ib.Queries = append(ib.Queries, lib.use(names[i], ib.Name, q))
+		}
+		set.Boards = append(set.Boards, ib)
+	}
+
+	set.Queries = lib.sorted()
+	set.Shared = lib.shared
+	set.Skipped = sortedKeys(skipped)
+	sort.Slice(set.Triggers, func(i, j int) bool { return set.Triggers[i].Name < set.Triggers[j].Name })
+	sort.Slice(set.Boards, func(i, j int) bool { return set.Boards[i].Name < set.Boards[j].Name })
+	return set, nil
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isBuildOutput reports whether top is keyed by resource type, as the build
+// output is.
+func isBuildOutput(top map[string]json.RawMessage) bool {
+	if len(top) == 0 {
+		return false
+	}
+	for key := range top {
+		if !buildOutputKeys[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// unwrapResult returns the string data of a command result printed with
+// --format json, such as `build -f json`.
+func unwrapResult(data []byte) ([]byte, bool) {
+	var result struct {
+		Success *bool  `json:"success"`
+		Data    string `json:"data"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil || result.Success == nil || result.Data == "" {
+		return nil, false
+	}
+	return []byte(result.Data), true
+}
+
+// importName derives the Go name of a query imported from a single file.
+func importName(source string) string {
+	if source == StdinPath {
+		return "ImportedQuery"
+	}
+	return goName(strings.TrimSuffix(filepath.Base(source), filepath.Ext(source)))
+}
+
+// goName converts a file or resource name such as slow-requests to an
+// exported Go identifier such as SlowRequests.
+func goName(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "Query" + name
+	}
+	return name
+}
+
+// packageName returns the Go package name of a file generated in dir: the
+// package of the Go files already there, or else one derived from the
+// directory name.
+func packageName(dir string) string {
+	if pkg := sourcePackage(dir); pkg != "" {
+		return pkg
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(filepath.Base(dir)) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9' && b.Len() > 0) {
+			b.WriteRune(r)
+		}
+	}
+	if b.Len() == 0 {
+		return "queries"
+	}
+	return b.String()
+}
+
+// sourcePackage returns the package clause of the first Go file in dir,
+// leaving out tests, or "" when it has none.
+func sourcePackage(dir string) string {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		if f, err := parser.ParseFile(fset, file, nil, parser.PackageClauseOnly); err == nil {
+			return f.Name.Name
+		}
+	}
+	return ""
+}
+
+// calculationFuncs maps calculation operations to query constructors.
+var calculationFuncs = map[string]string{
+	"COUNT_DISTINCT": "CountDistinct",
+	"SUM":            "Sum",
+	"AVG":            "Avg",
+	"MAX":            "Max",
+	"MIN":            "Min",
+	"P50":            "P50",
+	"P75":            "P75",
+	"P90":            "P90",
+	"P95":            "P95",
+	"P99":            "P99",
+	"P999":           "P999",
+	"HEATMAP":        "Heatmap",
+	"RATE":           "Rate",
+	"RATE_SUM":       "RateSum",
+	"RATE_AVG":       "RateAvg",
+	"RATE_MAX":       "RateMax",
+}
+
+// filterFuncs maps filter operators to query constructors.
+var filterFuncs = map[string]string{
+	"=":                "Equals",
+	"!=":               "NotEquals",
+	">":                "GT",
+	">=":               "GTE",
+	"<":                "LT",
+	"<=":               "LTE",
+	"contains":         "Contains",
+	"does-not-contain": "DoesNotContain",
+	"starts-with":      "StartsWith",
+	"in":               "In",
+	"not-in":           "NotIn",
+}
+
+// generateQueryFile generates a gofmt-formatted Go file declaring queries.
+func generateQueryFile(pkg, source string, queries []importedQuery) ([]byte, error) {
+	return generateImportFile(pkg, source, &importSet{Queries: queries})
+}
+
+// generateImportFile generates a gofmt-formatted Go file declaring the
+// queries, triggers and boards of an import.
+func generateImportFile(pkg, source string, set *importSet) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "package %s\n\nimport (\n", pkg)
+	if len(set.Boards) > 0 {
+		b.WriteString("\"github.com/lex00/wetwire-honeycomb-go/board\"\n")
+	}
+	b.WriteString("\"github.com/lex00/wetwire-honeycomb-go/query\"\n")
+	if len(set.Triggers) > 0 {
+		b.WriteString("\"github.com/lex00/wetwire-honeycomb-go/trigger\"\n")
+	}
+	b.WriteString(")\n")
+
+	for _, iq := range set.Queries {
+		if iq.Doc == "" {
+			iq.Doc = fmt.Sprintf("%s was imported from %s.", iq.Name, source)
+			if len(iq.UsedBy) > 0 {
+				iq.Doc += fmt.Sprintf("\nIt is used by %s.", strings.Join(iq.UsedBy, ", "))
+			}
+		}
+		writeQuery(&b, iq)
+	}
+	for _, it := range set.Triggers {
+		writeTrigger(&b, it, source)
+	}
+	for _, ib := range set.Boards {
+		writeBoard(&b, ib, source)
+	}
+
+	code, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return code, nil
+}
+
+// QueryDeclaration returns the gofmt-formatted Go declaration of q as the
+// variable name, with doc as its comment.
+func QueryDeclaration(name, doc string, q query.Query) (string, error) {
+	var b bytes.Buffer
+	writeQuery(&b, importedQuery{Name: name, Query: q, Doc: doc})
+
+	code, err := format.Source(b.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("format generated code: %w", err)
+	}
+	return strings.TrimSpace(string(code)) + "\n", nil
+}
+
+// writeQuery writes the declaration of a query, unformatted, to b.
+func writeQuery(b *bytes.Buffer, iq importedQuery) {
+	q := iq.Query
+	b.WriteString("\n")
+	for _, line := range strings.Split(iq.Doc, "\n") {
+		fmt.Fprintf(b, "// %s\n", line)
+	}
+	fmt.Fprintf(b, "var %s = query.Query{\n", iq.Name)
+
+	if q.Dataset != "" {
+		fmt.Fprintf(b, "Dataset: %q,\n", q.Dataset)
+	} else {
+		b.WriteString("Dataset: \"production\", // TODO: Change to your dataset\n")
+	}
+	if tr := timeRangeCode(q.TimeRange); tr != "" {
+		fmt.Fprintf(b, "TimeRange: %s,\n", tr)
+	}
+	if len(q.Breakdowns) > 0 {
+		fmt.Fprintf(b, "Breakdowns: []string{%s},\n", quoteAll(q.Breakdowns))
+	}
+	if len(q.Calculations) > 0 {
+		b.WriteString("Calculations: []query.Calculation{\n")
+		for _, c := range q.Calculations {
+			fmt.Fprintf(b, "%s,\n", calculationCode(c))
+		}
+		b.WriteString("},\n")
+	}
+	if len(q.Filters) > 0 {
+		b.WriteString("Filters: []query.Filter{\n")
+		for _, f := range q.Filters {
+			fmt.Fprintf(b, "%s,\n", filterCode(f))
+		}
+		b.WriteString("},\n")
+	}
+	if q.FilterCombination != "" {
+		fmt.Fprintf(b, "FilterCombination: %q,\n", q.FilterCombination)
+	}
+	if len(q.Orders) > 0 {
+		b.WriteString("Orders: []query.Order{\n")
+		for _, o := range q.Orders {
+			var fields []string
+			if o.Column != "" {
+				fields = append(fields, fmt.Sprintf("Column: %q", o.Column))
+			}
+			if o.Op != "" {
+				fields = append(fields, fmt.Sprintf("Op: %q", o.Op))
+			}
+			fields = append(fields, fmt.Sprintf("Order: %q", o.Order))
+			fmt.Fprintf(b, "{%s},\n", strings.Join(fields, ", "))
+		}
+		b.WriteString("},\n")
+	}
+	if q.Limit > 0 {
+		fmt.Fprintf(b, "Limit: %d,\n", q.Limit)
+	}
+	if q.Granularity > 0 {
+		fmt.Fprintf(b, "Granularity: %d,\n", q.Granularity)
+	}
+	b.WriteString("}\n")
+}
+
+// timeRangeCode returns the Go expression for a time range.
+func timeRangeCode(tr query.TimeRange) string {
+	switch {
+	case tr.StartTime != 0 || tr.EndTime != 0:
+		return fmt.Sprintf("query.TimeRange{StartTime: %d, EndTime: %d}", tr.StartTime, tr.EndTime)
+	case tr.TimeRange <= 0:
+		return ""
+	case tr.TimeRange%86400 == 0:
+		return fmt.Sprintf("query.Days(%d)", tr.TimeRange/86400)
+	case tr.TimeRange%3600 == 0:
+		return fmt.Sprintf("query.Hours(%d)", tr.TimeRange/3600)
+	case tr.TimeRange%60 == 0:
+		return fmt.Sprintf("query.Minutes(%d)", tr.TimeRange/60)
+	}
+	return fmt.Sprintf("query.Seconds(%d)", tr.TimeRange)
+}
+
+// calculationCode returns the Go expression for a calculation.
+func calculationCode(c query.Calculation) string {
+	switch {
+	case c.Op == "COUNT" && c.Column == "":
+		return "query.Count()"
+	case c.Op == "CONCURRENCY" && c.Column == "":
+		return "query.Concurrency()"
+	}
+	if fn, ok := calculationFuncs[c.Op]; ok && c.Column != "" {
+		return fmt.Sprintf("query.%s(%q)", fn, c.Column)
+	}
+	if c.Column == "" {
+		return fmt.Sprintf("{Op: %q}", c.Op)
+	}
+	return fmt.Sprintf("{Op: %q, Column: %q}", c.Op, c.Column)
+}
+
+// filterCode returns the Go expression for a filter.
+func filterCode(f query.Filter) string {
+	switch f.Op {
+	case "exists":
+		return fmt.Sprintf("query.Exists(%q)", f.Column)
+	case "does-not-exist":
+		return fmt.Sprintf("query.DoesNotExist(%q)", f.Column)
+	}
+	if fn, ok := filterFuncs[f.Op]; ok && f.Value != nil {
+		return fmt.Sprintf("query.%s(%q, %s)", fn, f.Column, valueCode(f.Value))
+	}
+	return fmt.Sprintf("{Column: %q, Op: %q, Value: %s}", f.Column, f.Op, valueCode(f.Value))
+}
+
+// valueCode returns the Go literal for a JSON filter value.
+func valueCode(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "nil"
+	case string:
+		return strconv.Quote(val)
+	case bool:
+		return strconv.FormatBool(val)
+	case float64:
+		if val == float64(int64(val)) {
+			return strconv.FormatInt(int64(val), 10)
+		}
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	case []any:
+		elems := make([]string, len(val))
+		for i, e := range val {
+			elems[i] = valueCode(e)
+		}
+		return "[]any{" + strings.Join(elems, ", ") + "}"
+	}
+	return fmt.Sprintf("%#v", v)
+}
+
+// quoteAll quotes and joins strings for a Go slice literal.
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = strconv.Quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
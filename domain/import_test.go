@@ -0,0 +1,515 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/annotate"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+// withStdin replaces Stdin for the duration of a test.
+func withStdin(t *testing.T, input string) {
+	t.Helper()
+	old := Stdin
+	Stdin = strings.NewReader(input)
+	t.Cleanup(func() { Stdin = old })
+}
+
+func TestHoneycombDomainImplementsImporterDomain(t *testing.T) {
+	// Compile-time check that HoneycombDomain implements ImporterDomain
+	var _ coredomain.ImporterDomain = (*HoneycombDomain)(nil)
+}
+
+func TestImport_Stdin(t *testing.T) {
+	withStdin(t, `{"time_range":7200,"calculations":[{"op":"P99","column":"duration_ms"}],"filters":[{"column":"status_code","op":">=","value":500}]}`)
+
+	result, err := (&honeycombImporter{}).Import(nil, StdinPath, ImportOpts{})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result)
+	}
+
+	code, _ := result.Data.(string)
+	for _, want := range []string{
+		"package queries",
+		"var ImportedQuery = query.Query{",
+		"query.Hours(2)",
+		`query.P99("duration_ms")`,
+		`query.GTE("status_code", 500)`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q:\n%s", want, code)
+		}
+	}
+}
+
+func TestImport_BuildOutputToTarget(t *testing.T) {
+	withStdin(t, `{"queries":{"SlowRequests":{"time_range":86400,"calculations":[{"op":"COUNT"}]},"Errors":{"start_time":100,"end_time":200}},"slos":{"Availability":{"name":"a"}}}`)
+
+	dir := filepath.Join(t.TempDir(), "imported")
+	result, err := (&honeycombImporter{}).Import(nil, StdinPath, ImportOpts{Target: dir})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result)
+	}
+	if !strings.Contains(result.Message, "skipped slos") {
+		t.Errorf("expected skipped slos in message, got %q", result.Message)
+	}
+
+	// The generated file is discoverable
+	queries, err := discovery.DiscoverQueries(dir)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if len(queries) != 2 {
+		t.Fatalf("expected 2 queries, got %d", len(queries))
+	}
+	for _, q := range queries {
+		switch q.Name {
+		case "SlowRequests":
+			if q.TimeRange.TimeRange != 86400 {
+				t.Errorf("SlowRequests time range = %d", q.TimeRange.TimeRange)
+			}
+		case "Errors":
+			if q.TimeRange.StartTime != 100 || q.TimeRange.EndTime != 200 {
+				t.Errorf("Errors time range = %+v", q.TimeRange)
+			}
+		default:
+			t.Errorf("unexpected query %s", q.Name)
+		}
+		if q.Package != "imported" {
+			t.Errorf("expected package imported, got %q", q.Package)
+		}
+	}
+
+	// Existing files are not overwritten
+	withStdin(t, `{"time_range":60}`)
+	result, err = (&honeycombImporter{}).Import(nil, StdinPath, ImportOpts{Target: dir})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if result.Success {
+		t.Error("expected failure when the target file exists")
+	}
+}
+
+func TestImport_SharedQueries(t *testing.T) {
+	// The trigger and the first panel of Overview embed the spec of the
+	// saved query Errors; the P99 panel of Overview and the panel of Service
+	// embed the same latency spec
+	spec := `{"time_range":3600,"calculations":[{"op":"COUNT"}],"filters":[{"column":"error","op":"exists"}]}`
+	latency := `{"time_range":7200,"calculations":[{"op":"P99","column":"duration_ms"}]}`
+	withStdin(t, `{
+		"queries":{"errors":`+spec+`},
+		"triggers":{"high-errors":{"name":"High Errors","dataset":"api","query":`+spec+`,"threshold":{"op":">","value":10},"frequency":300,"recipients":[{"type":"slack","target":"#alerts"}]},
+			"no-query":{"name":"No Query","query_id":"q1"}},
+		"boards":{"overview":{"name":"Overview","panels":[
+			{"type":"query","title":"Errors","query":`+spec+`},
+			{"type":"section","title":"Latency","panels":[{"type":"query","title":"P99","query":`+latency+`,"position":{"x":0,"y":4,"width":6,"height":4}}]},
+			{"type":"text","content":"## Runbook"}]},
+			"service":{"name":"Service","panels":[{"type":"query","query":`+latency+`,"query_style":"combo","visualization_settings":{"charts":[{"chart_index":0,"chart_type":"line","log_scale":true}]}}]}},
+		"slos":{"availability":{"name":"a"}}
+	}`)
+
+	dir := filepath.Join(t.TempDir(), "imported")
+	result, err := (&honeycombImporter{}).Import(nil, StdinPath, ImportOpts{Target: dir})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("expected success, got %+v", result)
+	}
+	for _, want := range []string{"Imported 2 queries, 2 boards and 1 triggers", "(3 duplicate query specs share a variable)", "skipped slos, triggers"} {
+		if !strings.Contains(result.Message, want) {
+			t.Errorf("expected %q in message, got %q", want, result.Message)
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "imported.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := string(data)
+	if n := strings.Count(code, "= query.Query{"); n != 2 {
+		t.Errorf("expected 2 query declarations, got %d:\n%s", n, code)
+	}
+	for _, want := range []string{
+		"var Errors = query.Query{\n\tDataset:   \"api\",",
+		"It is used by HighErrors, Overview.",
+		"var OverviewP99 = query.Query{",
+		"Query:     Errors,",
+		"board.QueryPanel(Errors, board.WithTitle(\"Errors\")),",
+		"board.Section(\"Latency\",\n\t\t\tboard.QueryPanel(OverviewP99, board.WithTitle(\"P99\"), board.WithPosition(0, 4, 6, 4)),",
+		"board.TextPanel(\"## Runbook\"),",
+		"board.QueryPanel(OverviewP99, board.WithQueryStyle(board.StyleCombo), board.WithChartType(board.ChartLine), board.LogScale()),",
+		"Threshold: trigger.GreaterThan(10),",
+		"Frequency: trigger.Minutes(5),",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q:\n%s", want, code)
+		}
+	}
+
+	// The generated package builds
+	out := filepath.Join(t.TempDir(), "out.json")
+	build, err := (&HoneycombDomain{}).Builder().Build(coredomain.NewContext(context.Background(), dir), dir, BuildOpts{Output: out})
+	if err != nil || !build.Success {
+		t.Fatalf("build of the imported package failed: %v %+v", err, build)
+	}
+	counts, err := ImportCounts(out)
+	if err != nil {
+		t.Fatalf("ImportCounts failed: %v", err)
+	}
+	if counts["queries"] != 2 {
+		t.Errorf("expected the 2 queries in the build output, got %v", counts)
+	}
+}
+
+func TestImport_StripsAnnotation(t *testing.T) {
+	// Resources applied by wetwire-honeycomb carry the managed annotation in
+	// their description; importing them back must not copy it into the code
+	spec := `{"time_range":3600,"calculations":[{"op":"COUNT"}]}`
+	trigger, err := json.Marshal(annotate.Description("Page the on-call engineer", "github.com/acme/observability"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	board, err := json.Marshal(annotate.Footer(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	withStdin(t, `{
+		"triggers":{"errors":{"name":"Errors","dataset":"api","description":`+string(trigger)+`,"query":`+spec+`,"threshold":{"op":">","value":10}}},
+		"boards":{"overview":{"name":"Overview","description":`+string(board)+`,"panels":[{"type":"query","query":`+spec+`}]}}
+	}`)
+
+	result, err := (&honeycombImporter{}).Import(nil, StdinPath, ImportOpts{})
+	if err != nil || !result.Success {
+		t.Fatalf("Import failed: %v %+v", err, result)
+	}
+	code, _ := result.Data.(string)
+	if strings.Contains(code, annotate.Marker) {
+		t.Errorf("generated code keeps the managed annotation:\n%s", code)
+	}
+	if !strings.Contains(code, `Description: "Page the on-call engineer",`) {
+		t.Errorf("generated code missing the trigger description:\n%s", code)
+	}
+	if strings.Contains(code, "Description: \"\"") {
+		t.Errorf("generated code keeps an empty board description:\n%s", code)
+	}
+}
+
+func TestImportPreset_ServiceDashboard(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "checkout")
+	result, err := ImportPreset(PresetServiceDashboard, PresetOpts{Service: "checkoutservice", Target: dir})
+	if err != nil || !result.Success {
+		t.Fatalf("ImportPreset failed: %v %+v", err, result)
+	}
+	if !strings.HasSuffix(result.Message, filepath.Join(dir, "checkoutservice.go")) {
+		t.Errorf("unexpected message %q", result.Message)
+	}
+
+	queries, err := discovery.DiscoverQueries(dir)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	got := make(map[string]discovery.DiscoveredQuery)
+	for _, q := range queries {
+		got[q.Name] = q
+	}
+	for _, name := range []string{"CheckoutserviceLatency", "CheckoutserviceErrors", "CheckoutserviceThroughput"} {
+		q, ok := got[name]
+		if !ok {
+			t.Errorf("expected query %s, got %v", name, got)
+			continue
+		}
+		if q.Dataset != "checkoutservice" || strings.Join(q.Breakdowns, ",") != "http.route" || q.Limit != 100 {
+			t.Errorf("%s: unexpected query %+v", name, q)
+		}
+		if len(q.Filters) == 0 || q.Filters[0].Column != "service.name" || q.Filters[0].Value != "checkoutservice" {
+			t.Errorf("%s: expected a service.name filter, got %+v", name, q.Filters)
+		}
+	}
+	if n := len(got["CheckoutserviceErrors"].Filters); n != 3 {
+		t.Errorf("expected an error filter on CheckoutserviceErrors, got %d filters", n)
+	}
+
+	// The generated queries lint without errors
+	lint, err := (&honeycombLinter{opts: &Options{}}).Lint(&coredomain.Context{}, dir, LintOpts{})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	for _, e := range lint.Errors {
+		if e.Severity == "error" {
+			t.Errorf("unexpected lint error: %+v", e)
+		}
+	}
+
+	// The dataset can differ from the service, and the code can be printed
+	result, err = ImportPreset(PresetServiceDashboard, PresetOpts{Service: "checkout-service", Dataset: "prod"})
+	if err != nil || !result.Success {
+		t.Fatalf("ImportPreset failed: %v %+v", err, result)
+	}
+	if code := result.Data.(string); !strings.Contains(code, `Dataset:    "prod",`) || !strings.Contains(code, "var CheckoutServiceThroughput = query.Query{") {
+		t.Errorf("unexpected code:\n%s", code)
+	}
+
+	if _, err := ImportPreset("home", PresetOpts{Service: "x"}); err == nil {
+		t.Error("expected an error for an unknown preset")
+	}
+	if _, err := ImportPreset(PresetServiceDashboard, PresetOpts{}); err == nil {
+		t.Error("expected an error without a service")
+	}
+}
+
+func TestImport_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slow-requests.json")
+	if err := os.WriteFile(path, []byte(`{"dataset":"api","time_range":90}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := (&honeycombImporter{}).Import(nil, path, ImportOpts{})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	code, _ := result.Data.(string)
+	if !strings.Contains(code, "var SlowRequests = query.Query{") {
+		t.Errorf("expected SlowRequests declaration:\n%s", code)
+	}
+	if !strings.Contains(code, `Dataset:   "api"`) || !strings.Contains(code, "query.Seconds(90)") {
+		t.Errorf("unexpected generated code:\n%s", code)
+	}
+}
+
+func TestImportCounts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "build.json")
+	if err := os.WriteFile(path, []byte(`{"queries":{"A":{"time_range":60},"B":{"time_range":60}},"boards":{"C":{"name":"c"}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	counts, err := ImportCounts(path)
+	if err != nil {
+		t.Fatalf("ImportCounts failed: %v", err)
+	}
+	if counts["queries"] != 2 || len(counts) != 1 {
+		t.Errorf("expected 2 queries, got %v", counts)
+	}
+
+	if counts, err := ImportCounts(StdinPath); err != nil || counts != nil {
+		t.Errorf("expected no counts for stdin, got %v, %v", counts, err)
+	}
+}
+
+func TestImport_InvalidJSON(t *testing.T) {
+	withStdin(t, `not json`)
+
+	result, err := (&honeycombImporter{}).Import(nil, StdinPath, ImportOpts{})
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if result.Success {
+		t.Error("expected failure for invalid JSON")
+	}
+}
+
+func TestGoName(t *testing.T) {
+	tests := map[string]string{
+		"slow-requests":   "SlowRequests",
+		"LatencyByRegion": "LatencyByRegion",
+		"p99_latency":     "P99Latency",
+		"5xx":             "Query5xx",
+		"---":             "Query",
+	}
+	for in, want := range tests {
+		if got := goName(in); got != want {
+			t.Errorf("goName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestQueryDeclaration(t *testing.T) {
+	code, err := QueryDeclaration("CheckoutBase", "CheckoutBase is shared.", query.Query{
+		Dataset:      "checkout",
+		TimeRange:    query.Hours(2),
+		Calculations: []query.Calculation{query.P99("duration_ms")},
+		Filters:      []query.Filter{query.Equals("service.name", "checkout-api")},
+	})
+	if err != nil {
+		t.Fatalf("QueryDeclaration failed: %v", err)
+	}
+	for _, want := range []string{
+		"// CheckoutBase is shared.\nvar CheckoutBase = query.Query{",
+		"\tDataset:   \"checkout\",",
+		"query.P99(\"duration_ms\")",
+		"query.Equals(\"service.name\", \"checkout-api\")",
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("expected %q in declaration, got:\n%s", want, code)
+		}
+	}
+}
+
+func TestValidate_StdinBuildOutput(t *testing.T) {
+	withStdin(t, `{"queries":{"Good":{"time_range":3600}},"boards":{"Overview":{"name":"Overview","panels":[{"type":"query","query":{"time_range":-1}},{"type":"text","content":"x"}]}}}`)
+
+	result, err := (&honeycombValidator{opts: &Options{}}).Validate(nil, StdinPath, ValidateOpts{})
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if result.Success {
+		t.Fatal("expected failure for the negative board panel time range")
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("expected 1 error, got %+v", result.Errors)
+	}
+	if e := result.Errors[0]; e.Path != "<stdin>" || !strings.HasPrefix(e.Message, "boards.Overview.panels[0]: time_range") {
+		t.Errorf("unexpected error: %+v", e)
+	}
+}
+
+func TestValidate_StdinBoardSections(t *testing.T) {
+	withStdin(t, `{"boards":{"Overview":{"name":"Overview","panels":[{"type":"text","content":"x"},{"type":"section","title":"Latency","panels":[{"type":"query","query":{"time_range":-1}}]}]}}}`)
+
+	result, err := (&honeycombValidator{opts: &Options{}}).Validate(nil, StdinPath, ValidateOpts{})
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if result.Success || len(result.Errors) != 1 {
+		t.Fatalf("expected 1 error for the query in the section, got %+v", result)
+	}
+	if e := result.Errors[0]; !strings.HasPrefix(e.Message, "boards.Overview.panels[1].panels[0]: time_range") {
+		t.Errorf("unexpected error: %+v", e)
+	}
+}
+
+func TestValidate_JSONResultEnvelope(t *testing.T) {
+	withStdin(t, `{"success":true,"message":"Build completed","data":"{\"queries\":{\"Good\":{\"time_range\":3600}}}"}`)
+
+	result, err := (&honeycombValidator{opts: &Options{}}).Validate(nil, StdinPath, ValidateOpts{})
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("expected success, got %+v", result.Errors)
+	}
+	if !strings.Contains(result.Message, "1 queries") {
+		t.Errorf("unexpected message %q", result.Message)
+	}
+}
+
+func TestValidate_InvalidJSON(t *testing.T) {
+	withStdin(t, `{`)
+
+	result, err := (&honeycombValidator{opts: &Options{}}).Validate(nil, StdinPath, ValidateOpts{})
+	if err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	if result.Success {
+		t.Error("expected failure for invalid JSON")
+	}
+}
+
+func TestImportDatadog(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "checkout-monitors.json")
+	monitors := `[
+	  {"name": "Checkout latency high", "type": "metric alert",
+	   "query": "avg(last_10m):avg:trace.http.request.duration{service:checkout} > 0.5",
+	   "message": "@oncall@example.com", "tags": ["team:payments"]},
+	  {"name": "Checkout logs", "type": "log alert", "query": "logs(\"service:checkout\").last(\"5m\") > 10"}
+	]`
+	if err := os.WriteFile(source, []byte(monitors), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "alerts")
+	result, err := ImportDatadog(source, ConvertOpts{Target: dir})
+	if err != nil || !result.Success {
+		t.Fatalf("ImportDatadog failed: %v %+v", err, result)
+	}
+	if !strings.HasSuffix(result.Message, filepath.Join(dir, "checkout-monitors.go")) {
+		t.Errorf("unexpected message %q", result.Message)
+	}
+	if len(result.Errors) != 2 || result.Errors[0].Severity != "warning" {
+		t.Errorf("expected the log monitor and the unit change as warnings, got %+v", result.Errors)
+	}
+
+	resources, err := discovery.DiscoverAll(dir)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if len(resources.Triggers) != 1 || len(resources.Queries) != 1 {
+		t.Fatalf("expected one trigger and its query, got %+v", resources)
+	}
+	tr := resources.Triggers[0]
+	if tr.Name != "CheckoutLatencyHigh" || tr.QueryRef != "CheckoutLatencyHighQuery" || tr.ThresholdOp != ">" || tr.ThresholdValue != 500 {
+		t.Errorf("unexpected trigger %+v", tr)
+	}
+
+	// The generated code lints without errors
+	lint, err := (&honeycombLinter{opts: &Options{}}).Lint(&coredomain.Context{}, dir, LintOpts{})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	for _, e := range lint.Errors {
+		if e.Severity == "error" {
+			t.Errorf("unexpected lint error: %+v", e)
+		}
+	}
+
+	result, err = ImportDatadog(source, ConvertOpts{Target: dir})
+	if err != nil || result.Success {
+		t.Errorf("expected an existing file to fail the import, got %v %+v", err, result)
+	}
+}
+
+func TestImportGrafana(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "checkout.json")
+	dashboard := `{"title": "Checkout", "time": {"from": "now-3h"}, "panels": [
+	  {"type": "timeseries", "title": "Latency", "datasource": {"type": "prometheus"},
+	   "targets": [{"refId": "A", "expr": "histogram_quantile(0.95, sum by (le) (rate(http_server_duration_milliseconds_bucket{service=\"checkout\"}[5m])))"}]},
+	  {"type": "row", "title": "Logs", "collapsed": true, "panels": [
+	    {"type": "logs", "title": "Errors", "datasource": {"type": "loki"},
+	     "targets": [{"refId": "A", "expr": "{service_name=\"checkout\"} | json | level=\"error\""}]}
+	  ]}
+	]}`
+	if err := os.WriteFile(source, []byte(dashboard), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "boards")
+	result, err := ImportGrafana(source, ConvertOpts{Target: dir})
+	if err != nil || !result.Success {
+		t.Fatalf("ImportGrafana failed: %v %+v", err, result)
+	}
+	if !strings.HasSuffix(result.Message, filepath.Join(dir, "checkout.go")) {
+		t.Errorf("unexpected message %q", result.Message)
+	}
+
+	resources, err := discovery.DiscoverAll(dir)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if len(resources.Queries) != 2 || len(resources.Boards) != 1 {
+		t.Fatalf("expected two queries and a board, got %+v", resources)
+	}
+
+	lint, err := (&honeycombLinter{opts: &Options{}}).Lint(&coredomain.Context{}, dir, LintOpts{})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	for _, e := range lint.Errors {
+		if e.Severity == "error" {
+			t.Errorf("unexpected lint error: %+v", e)
+		}
+	}
+}
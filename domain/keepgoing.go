@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"sort"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lock"
+)
+
+// ErrorsSection is the key of the failed resources in the output of a
+// --keep-going build.
+const ErrorsSection = "errors"
+
+// BuildError is a resource a --keep-going build left out of its output.
+type BuildError struct {
+	// Resource is the lockfile key of the resource, e.g. "triggers/HighLatency"
+	Resource string `json:"resource"`
+
+	// File and Line locate the declaration, or the field, that failed
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+
+	Message string `json:"message"`
+}
+
+// buildErrors collects the resources a build fails on.
+type buildErrors struct {
+	keepGoing bool
+	errs      []BuildError
+}
+
+// add records that a resource failed with err, or returns err when the build
+// does not keep going.
+func (b *buildErrors) add(section, name, file string, line int, err error) error {
+	if !b.keepGoing {
+		return err
+	}
+	b.errs = append(b.errs, BuildError{Resource: lock.Key(section, name), File: file, Line: line, Message: err.Error()})
+	return nil
+}
+
+// removeFailing expands environment references (see ExpandEnv) and checks
+// query time ranges one resource at a time, removing the resources that fail
+// either from resources and returning their errors.
+func removeFailing(resources *discovery.DiscoveredResources, allowEnv bool) []BuildError {
+	var failed []BuildError
+	check := func(section, name string, single *discovery.DiscoveredResources) bool {
+		errs := append(ExpandEnv(single, allowEnv), timeRangeErrors(single.Queries)...)
+		for _, e := range errs {
+			failed = append(failed, BuildError{Resource: lock.Key(section, name), File: e.Path, Line: e.Line, Message: e.Message})
+		}
+		return len(errs) == 0
+	}
+
+	var queries []discovery.DiscoveredQuery
+	for _, q := range resources.Queries {
+		single := &discovery.DiscoveredResources{Queries: []discovery.DiscoveredQuery{q}}
+		if check("queries", q.Name, single) {
+			queries = append(queries, single.Queries[0])
+		}
+	}
+	var boards []discovery.DiscoveredBoard
+	for _, b := range resources.Boards {
+		single := &discovery.DiscoveredResources{Boards: []discovery.DiscoveredBoard{b}}
+		if check("boards", b.Name, single) {
+			boards = append(boards, single.Boards[0])
+		}
+	}
+	var slos []discovery.DiscoveredSLO
+	for _, s := range resources.SLOs {
+		single := &discovery.DiscoveredResources{SLOs: []discovery.DiscoveredSLO{s}}
+		if check("slos", s.Name, single) {
+			slos = append(slos, single.SLOs[0])
+		}
+	}
+	var triggers []discovery.DiscoveredTrigger
+	for _, t := range resources.Triggers {
+		single := &discovery.DiscoveredResources{Triggers: []discovery.DiscoveredTrigger{t}}
+		if check("triggers", t.Name, single) {
+			triggers = append(triggers, single.Triggers[0])
+		}
+	}
+	resources.Queries, resources.Boards, resources.SLOs, resources.Triggers = queries, boards, slos, triggers
+	return failed
+}
+
+// sortBuildErrors orders failed resources by file, line and resource.
+func sortBuildErrors(errs []BuildError) {
+	sort.SliceStable(errs, func(i, j int) bool {
+		a, b := errs[i], errs[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Resource < b.Resource
+	})
+}
+
+// buildErrorResult returns the failed result of a --keep-going build: one
+// error per failed resource, with the output written to a file (message) or
+// carried as the result data.
+func buildErrorResult(message string, data string, failed []BuildError) *Result {
+	errs := make([]Error, len(failed))
+	for i, f := range failed {
+		errs[i] = Error{Path: f.File, Line: f.Line, Severity: "error", Message: f.Resource + ": " + f.Message}
+	}
+	result := NewErrorResultMultiple(message, errs)
+	if data != "" {
+		result.Data = data
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lint"
+)
+
+// LintFiles runs the lint rules, configured as the lint command configures
+// them, on the Go files of one package and returns the issues found in those
+// files. It backs the wetwirehoneycomb analyzer, which reports the issues in
+// go vet and golangci-lint. The rules comparing resources, such as WHC026 and
+// WHC062, see the resources of the package directory and of those below it,
+// as lint of that directory does.
+func LintFiles(files []string, disabled []string) ([]lint.Issue, error) {
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	selected := make(map[string]bool, len(files))
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return nil, fmt.Errorf("resolve path: %w", err)
+		}
+		selected[abs] = true
+	}
+	dir, err := filepath.Abs(filepath.Dir(files[0]))
+	if err != nil {
+		return nil, fmt.Errorf("resolve path: %w", err)
+	}
+
+	resources, err := discovery.DiscoverAll(dir)
+	if err != nil {
+		return nil, fmt.Errorf("discovery failed: %w", err)
+	}
+	config, err := lintConfig(dir, disabled, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []lint.Issue
+	for _, issue := range lint.LintAllWithConfig(resources, config) {
+		if selected[issue.File] {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
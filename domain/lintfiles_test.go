@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLintFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	queries := filepath.Join(tmpDir, "queries.go")
+	content := `package obs
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Latency = query.Query{
+	TimeRange:    query.Hours(1),
+	Calculations: []query.Calculation{query.P99("duration_ms")},
+}
+`
+	if err := os.WriteFile(queries, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	sub := filepath.Join(tmpDir, "debug")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	content = `package debug
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Spans = query.Query{Calculations: []query.Calculation{query.Count()}}
+`
+	if err := os.WriteFile(filepath.Join(sub, "debug.go"), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	issues, err := LintFiles([]string{queries}, nil)
+	if err != nil {
+		t.Fatalf("LintFiles failed: %v", err)
+	}
+	found := make(map[string]int)
+	for _, issue := range issues {
+		if issue.File != queries {
+			t.Errorf("expected issues in %s only, got one in %s", queries, issue.File)
+		}
+		found[issue.Rule] = issue.Line
+	}
+	if found["WHC001"] != 5 {
+		t.Errorf("expected WHC001 on line 5, got %+v", issues)
+	}
+
+	issues, err = LintFiles([]string{queries}, []string{"WHC001"})
+	if err != nil {
+		t.Fatalf("LintFiles failed: %v", err)
+	}
+	for _, issue := range issues {
+		if issue.Rule == "WHC001" {
+			t.Errorf("expected WHC001 to be disabled, got %+v", issue)
+		}
+	}
+
+	if issues, err := LintFiles(nil, nil); err != nil || len(issues) != 0 {
+		t.Errorf("expected no issues without files, got %v %v", issues, err)
+	}
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// NameFilter selects resources by the glob patterns their Go variable names
+// match, across resource types. The patterns are in path.Match syntax, such
+// as "Checkout*".
+type NameFilter struct {
+	// Only holds the patterns of which a name must match one; empty
+	// selects every name
+	Only []string
+
+	// Exclude holds the patterns of which a name must match none
+	Exclude []string
+}
+
+// IsZero reports whether f selects every resource.
+func (f NameFilter) IsZero() bool {
+	return len(f.Only) == 0 && len(f.Exclude) == 0
+}
+
+// Validate reports the first malformed pattern of f.
+func (f NameFilter) Validate() error {
+	for flag, patterns := range map[string][]string{"--only": f.Only, "--exclude": f.Exclude} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("%s %q: %w", flag, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Match reports whether f selects the resource named name.
+func (f NameFilter) Match(name string) bool {
+	return (len(f.Only) == 0 || matchAny(f.Only, name)) && !matchAny(f.Exclude, name)
+}
+
+// matchAny reports whether name matches one of patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns the resources f selects: queries, boards, SLOs, triggers,
+// maintenance windows and custom resources. The queries the selected boards,
+// SLOs and triggers reference by name are kept too, whatever their names, so
+// the selection builds and applies on its own. Schemas, derived columns,
+// unsupported declarations and field mistakes are kept as they are.
+func (f NameFilter) Apply(resources *discovery.DiscoveredResources) *discovery.DiscoveredResources {
+	filtered := &discovery.DiscoveredResources{
+		Schemas:        resources.Schemas,
+		DerivedColumns: resources.DerivedColumns,
+		Unsupported:    resources.Unsupported,
+		FieldMistakes:  resources.FieldMistakes,
+	}
+	refs := make(map[string]bool)
+	for _, b := range resources.Boards {
+		if f.Match(b.Name) {
+			filtered.Boards = append(filtered.Boards, b)
+			for _, ref := range b.QueryRefs {
+				refs[ref] = true
+			}
+		}
+	}
+	for _, s := range resources.SLOs {
+		if f.Match(s.Name) {
+			filtered.SLOs = append(filtered.SLOs, s)
+			refs[s.GoodEventsQueryRef] = true
+			refs[s.TotalEventsQueryRef] = true
+		}
+	}
+	for _, t := range resources.Triggers {
+		if f.Match(t.Name) {
+			filtered.Triggers = append(filtered.Triggers, t)
+			refs[t.QueryRef] = true
+		}
+	}
+	for _, q := range resources.Queries {
+		if f.Match(q.Name) || refs[q.Name] {
+			filtered.Queries = append(filtered.Queries, q)
+		}
+	}
+	for _, m := range resources.Mutes {
+		if f.Match(m.Name) {
+			filtered.Mutes = append(filtered.Mutes, m)
+		}
+	}
+	for _, r := range resources.Custom {
+		if f.Match(r.Name) {
+			filtered.Custom = append(filtered.Custom, r)
+		}
+	}
+	return filtered
+}
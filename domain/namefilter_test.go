@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+func TestNameFilter_Match(t *testing.T) {
+	tests := []struct {
+		filter NameFilter
+		name   string
+		want   bool
+	}{
+		{NameFilter{}, "Anything", true},
+		{NameFilter{Only: []string{"Checkout*"}}, "CheckoutLatency", true},
+		{NameFilter{Only: []string{"Checkout*"}}, "SearchLatency", false},
+		{NameFilter{Only: []string{"Checkout*", "Search*"}}, "SearchLatency", true},
+		{NameFilter{Exclude: []string{"*Debug*"}}, "CheckoutDebugSpans", false},
+		{NameFilter{Only: []string{"Checkout*"}, Exclude: []string{"*Debug*"}}, "CheckoutDebugSpans", false},
+		{NameFilter{Only: []string{"Checkout?atency"}}, "CheckoutLatency", true},
+	}
+
+	for _, tt := range tests {
+		if got := tt.filter.Match(tt.name); got != tt.want {
+			t.Errorf("%+v.Match(%q) = %v, want %v", tt.filter, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNameFilter_Validate(t *testing.T) {
+	if err := (NameFilter{Only: []string{"Checkout*"}, Exclude: []string{"*[Dd]ebug*"}}).Validate(); err != nil {
+		t.Errorf("expected valid globs, got %v", err)
+	}
+	err := NameFilter{Exclude: []string{"[Debug"}}.Validate()
+	if err == nil || !strings.Contains(err.Error(), `--exclude "[Debug"`) {
+		t.Errorf("expected the malformed --exclude glob to be reported, got %v", err)
+	}
+}
+
+func TestNameFilter_Apply(t *testing.T) {
+	resources := &discovery.DiscoveredResources{
+		Queries: []discovery.DiscoveredQuery{
+			{Name: "CheckoutLatency"}, {Name: "SharedErrors"}, {Name: "SharedTraffic"}, {Name: "SearchLatency"},
+		},
+		Boards: []discovery.DiscoveredBoard{
+			{Name: "CheckoutOverview", QueryRefs: []string{"SharedTraffic"}},
+			{Name: "SearchOverview", QueryRefs: []string{"SearchLatency"}},
+		},
+		Triggers: []discovery.DiscoveredTrigger{
+			{Name: "CheckoutErrors", QueryRef: "SharedErrors"},
+			{Name: "CheckoutDebugErrors", QueryRef: "SearchLatency"},
+		},
+		SLOs: []discovery.DiscoveredSLO{{Name: "SearchAvailability"}},
+	}
+
+	filtered := NameFilter{Only: []string{"Checkout*"}, Exclude: []string{"*Debug*"}}.Apply(resources)
+
+	var names []string
+	for _, q := range filtered.Queries {
+		names = append(names, q.Name)
+	}
+	if got := strings.Join(names, ","); got != "CheckoutLatency,SharedErrors,SharedTraffic" {
+		t.Errorf("expected the selected query and those referenced by selected resources, got %s", got)
+	}
+	if len(filtered.Boards) != 1 || filtered.Boards[0].Name != "CheckoutOverview" {
+		t.Errorf("expected only the CheckoutOverview board, got %+v", filtered.Boards)
+	}
+	if len(filtered.Triggers) != 1 || filtered.Triggers[0].Name != "CheckoutErrors" {
+		t.Errorf("expected only the CheckoutErrors trigger, got %+v", filtered.Triggers)
+	}
+	if len(filtered.SLOs) != 0 {
+		t.Errorf("expected no SLOs, got %+v", filtered.SLOs)
+	}
+}
+
+func TestBuilderBuild_NameFilter(t *testing.T) {
+	d := &HoneycombDomain{}
+	builder := d.Builder()
+
+	tmpDir := t.TempDir()
+	content := `package obs
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var Errors = query.Query{Dataset: "api", TimeRange: query.Minutes(15), Calculations: []query.Calculation{query.Count()}}
+
+var CheckoutLatency = query.Query{Dataset: "checkout", TimeRange: query.Hours(1), Calculations: []query.Calculation{query.P99("duration_ms")}}
+
+var CheckoutDebugSpans = query.Query{Dataset: "checkout", TimeRange: query.Hours(1)}
+
+var SearchLatency = query.Query{Dataset: "search", TimeRange: query.Hours(1), Calculations: []query.Calculation{query.P99("duration_ms")}}
+
+var CheckoutErrors = trigger.Trigger{
+	Name:      "Checkout Errors",
+	Query:     Errors,
+	Threshold: trigger.GreaterThan(10),
+	Frequency: trigger.Minutes(5),
+}
+`
+	if err := os.WriteFile(tmpDir+"/obs.go", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	d.Options.Filter = NameFilter{Only: []string{"Checkout*"}, Exclude: []string{"*Debug*"}}
+	d.Options.WriteLock = true
+
+	outFile := filepath.Join(t.TempDir(), "out.json")
+	result, err := builder.Build(&coredomain.Context{}, tmpDir, BuildOpts{Output: outFile})
+	if err != nil || !result.Success {
+		t.Fatalf("Build failed: %v %+v", err, result)
+	}
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("Failed to read build output: %v", err)
+	}
+	for _, want := range []string{`"CheckoutLatency"`, `"Errors"`, `"CheckoutErrors"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected %s in the build output, got %s", want, data)
+		}
+	}
+	for _, unwanted := range []string{"CheckoutDebugSpans", "SearchLatency"} {
+		if strings.Contains(string(data), unwanted) {
+			t.Errorf("expected %s to be filtered out, got %s", unwanted, data)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "wetwire.lock")); !os.IsNotExist(err) {
+		t.Errorf("expected no lockfile after a filtered build, got %v", err)
+	}
+
+	d.Options.Filter = NameFilter{Only: []string{"Billing*"}}
+	result, err = builder.Build(&coredomain.Context{}, tmpDir, BuildOpts{DryRun: true})
+	if err != nil || result.Success {
+		t.Fatalf("expected the build to fail when nothing matches, got %v %+v", err, result)
+	}
+	if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Message, "--only and --exclude") {
+		t.Errorf("expected an error naming the filters, got %+v", result.Errors)
+	}
+
+	d.Options.Filter = NameFilter{Only: []string{"[Checkout"}}
+	if _, err := builder.Build(&coredomain.Context{}, tmpDir, BuildOpts{DryRun: true}); err == nil || !strings.Contains(err.Error(), "--only") {
+		t.Errorf("expected the malformed glob to fail the build, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"fmt"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// nameConflictErrors returns an error for each trigger and SLO sharing its
+// display name with an earlier one on its dataset, which Honeycomb would
+// reject. With rename (Options.RenameOnConflict), the duplicates are renamed
+// instead.
+func nameConflictErrors(resources *discovery.DiscoveredResources, rename bool) []Error {
+	if rename {
+		renameConflicts(resources)
+		return nil
+	}
+
+	var errs []Error
+	for _, c := range resources.NameConflicts() {
+		errs = append(errs, Error{
+			Path:     c.File,
+			Line:     c.Line,
+			Severity: "error",
+			Message: fmt.Sprintf("%s %s: name %q is already used on dataset %s by %s; rename it or build with --rename-on-conflict",
+				c.Kind, c.Name, c.DisplayName, c.Dataset, c.First),
+		})
+	}
+	return errs
+}
+
+// renameConflicts suffixes the display names of the triggers and SLOs that
+// share one with an earlier resource on their dataset, in discovery order:
+// "High Latency" becomes "High Latency (2)", skipping names in use.
+func renameConflicts(resources *discovery.DiscoveredResources) {
+	used := make(map[string]bool)
+	rename := func(kind, dataset string, name *string) {
+		if *name == "" || dataset == "" {
+			return
+		}
+		key := func(name string) string { return kind + "\x00" + dataset + "\x00" + name }
+		if !used[key(*name)] {
+			used[key(*name)] = true
+			return
+		}
+		for n := 2; ; n++ {
+			candidate := fmt.Sprintf("%s (%d)", *name, n)
+			if !used[key(candidate)] {
+				*name = candidate
+				used[key(candidate)] = true
+				return
+			}
+		}
+	}
+
+	// Names are claimed in order, so a later "X (2)" declared in code is
+	// itself renamed if a rename took it
+	for i := range resources.Triggers {
+		t := &resources.Triggers[i]
+		rename("trigger", resources.TriggerDataset(*t), &t.TriggerName)
+	}
+	for i := range resources.SLOs {
+		s := &resources.SLOs[i]
+		rename("slo", resources.SLODataset(*s), &s.SLOName)
+	}
+}
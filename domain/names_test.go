@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+func conflictingResources() *discovery.DiscoveredResources {
+	return &discovery.DiscoveredResources{
+		Queries: []discovery.DiscoveredQuery{{Name: "Latency", Dataset: "api"}},
+		Triggers: []discovery.DiscoveredTrigger{
+			{Name: "HighLatency", TriggerName: "High Latency", QueryRef: "Latency"},
+			{Name: "HighLatencyCopy", File: "/b/triggers.go", Line: 7, TriggerName: "High Latency", QueryRef: "Latency"},
+			{Name: "Declared", TriggerName: "High Latency (2)", Dataset: "api"},
+		},
+		SLOs: []discovery.DiscoveredSLO{
+			{Name: "Availability", SLOName: "High Latency", Dataset: "api"},
+		},
+	}
+}
+
+func TestNameConflictErrors(t *testing.T) {
+	errs := nameConflictErrors(conflictingResources(), false)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %v", errs)
+	}
+	if errs[0].Path != "/b/triggers.go" || errs[0].Line != 7 || !strings.Contains(errs[0].Message, `trigger HighLatencyCopy: name "High Latency" is already used on dataset api by HighLatency`) {
+		t.Errorf("unexpected error %+v", errs[0])
+	}
+}
+
+func TestNameConflictErrors_Rename(t *testing.T) {
+	resources := conflictingResources()
+	if errs := nameConflictErrors(resources, true); len(errs) != 0 {
+		t.Fatalf("expected the conflicts to be renamed, got %v", errs)
+	}
+	var names []string
+	for _, tr := range resources.Triggers {
+		names = append(names, tr.TriggerName)
+	}
+	if got := strings.Join(names, ", "); got != "High Latency, High Latency (2), High Latency (2) (2)" {
+		t.Errorf("trigger names = %s", got)
+	}
+	if resources.SLOs[0].SLOName != "High Latency" {
+		t.Errorf("an SLO may share a trigger's name, got %q", resources.SLOs[0].SLOName)
+	}
+	if conflicts := resources.NameConflicts(); len(conflicts) != 0 {
+		t.Errorf("expected no conflicts left, got %v", conflicts)
+	}
+}
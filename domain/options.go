@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"fmt"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/describe"
+)
+
+// Options are the settings of build, lint, list and graph that BuildOpts,
+// LintOpts, ListOpts and GraphOpts have no field for. The commands set them
+// from their flags on the HoneycombDomain they run; the zero value is the
+// default of each.
+type Options struct {
+	// AllowEnv enables ${VAR} interpolation of datasets and recipient
+	// targets at build time (see ExpandEnv). Set by --allow-env.
+	AllowEnv bool
+
+	// Normalize writes queries in a canonical form: sorted breakdowns and
+	// filters, the default filter combination and no zero limits. Set by
+	// the --normalize flags of build and diff.
+	Normalize bool
+
+	// NormalizeWindows fits the time ranges of queries to the triggers and
+	// SLOs using them (see NormalizeWindows). Set by build
+	// --normalize-windows.
+	NormalizeWindows bool
+
+	// Describe fills the empty descriptions of triggers and SLOs from their
+	// metadata (see internal/describe). Set by build --describe.
+	Describe bool
+
+	// TriggerDescription and SLODescription replace the default
+	// text/template descriptions are rendered with, and imply Describe. Set
+	// by build --trigger-description and --slo-description.
+	TriggerDescription string
+	SLODescription     string
+
+	// QueryMode selects how triggers name the query they evaluate in build
+	// output: QueryModeReference (the default when empty) or
+	// QueryModeInline. Set by build --query-mode.
+	QueryMode string
+
+	// APIVersion selects the Query API variant build output targets (see
+	// internal/apiversion), apiversion.Latest when empty. Set by build
+	// --api-version.
+	APIVersion string
+
+	// StrictAPI fails the build on query fields deprecated by the Honeycomb
+	// API (see serialize.Deprecations) instead of warning about them. Set
+	// by build --strict-api.
+	StrictAPI bool
+
+	// KeepGoing makes build serialize every resource it can: resources
+	// that fail are left out of the output and listed in its
+	// ErrorsSection, and the build still fails. Set by build --keep-going.
+	KeepGoing bool
+
+	// RenameOnConflict renames triggers and SLOs whose display name is
+	// already used on their dataset instead of failing the build: the later
+	// ones get a " (2)", " (3)"... suffix. Set by build --rename-on-conflict.
+	RenameOnConflict bool
+
+	// WriteLock writes wetwire.lock, the hash of each resource of a full
+	// build, to the directory containing the packages built, for diff --lock
+	// and apply --prune. Builds limited by --type, --only or --exclude,
+	// dry runs and builds with failed resources write none. Set by build
+	// --write-lock.
+	WriteLock bool
+
+	// Refs adds the references manifest (see References) to the build
+	// output, in its "references" section. Set by build --refs.
+	Refs bool
+
+	// OutputMode selects where build puts its output: OutputStdout,
+	// OutputFile or OutputDir. When empty, the output goes to the -o path
+	// when one is given and to stdout otherwise. Set by --output-mode.
+	OutputMode string
+
+	// Filter limits build, with its reports, list and graph to the
+	// resources whose Go variable names it selects. Set by the --only and
+	// --exclude flags of build, list and graph.
+	Filter NameFilter
+
+	// Tags limits list and graph to boards and triggers with the given
+	// tags: "key=value" or "key" selectors that must all match. Set by the
+	// --tag flags of list and graph.
+	Tags []string
+
+	// DSL adds a "dsl" entry to list output with each query, and each
+	// inline trigger query, written as a one-line expression (see
+	// query.Query.String). Set by list --dsl.
+	DSL bool
+
+	// AllowOrphans are name patterns (path.Match syntax) of queries lint
+	// accepts without a board, trigger or SLO using them (WHC026). Set by
+	// lint --allow-orphan.
+	AllowOrphans []string
+
+	// Debug makes lint time each rule and return the timings in the
+	// LintReport. Set by lint --debug.
+	Debug bool
+
+	// Profile is Debug with the allocations of each rule, which makes lint
+	// run its rules one at a time. Set by lint --profile.
+	Profile bool
+
+	// GraphDirection is the direction graph draws dependencies in: TB (top
+	// to bottom, the default when empty), LR, BT or RL. Set by graph
+	// --direction.
+	GraphDirection string
+
+	// GraphClusterBy groups the nodes of DOT and Mermaid graphs into
+	// clusters by "dataset" (the default when empty), "package" or "type",
+	// or not at all with "none". Set by graph --cluster-by.
+	GraphClusterBy string
+}
+
+// Query modes of Options.QueryMode.
+const (
+	// QueryModeReference writes a query_id placeholder apply resolves to the
+	// ID of the saved query, for the Honeycomb API
+	QueryModeReference = "reference"
+
+	// QueryModeInline embeds the query spec in each trigger, for consumers
+	// that cannot resolve references, such as Terraform
+	QueryModeInline = "inline"
+)
+
+// descriptionTemplates returns the templates of TriggerDescription and
+// SLODescription, or nil when descriptions are not filled in.
+func (o Options) descriptionTemplates() (*describe.Templates, error) {
+	if !o.Describe && o.TriggerDescription == "" && o.SLODescription == "" {
+		return nil, nil
+	}
+	return describe.Parse(o.TriggerDescription, o.SLODescription)
+}
+
+// queryMode returns the query mode of QueryMode, QueryModeReference when it
+// is empty.
+func (o Options) queryMode() (string, error) {
+	switch o.QueryMode {
+	case "":
+		return QueryModeReference, nil
+	case QueryModeReference, QueryModeInline:
+		return o.QueryMode, nil
+	default:
+		return "", fmt.Errorf("unknown query mode %q: expected %s or %s", o.QueryMode, QueryModeReference, QueryModeInline)
+	}
+}
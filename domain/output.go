@@ -0,0 +1,130 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/atomicfile"
+	"github.com/lex00/wetwire-honeycomb-go/internal/refs"
+)
+
+// Output modes of Options.OutputMode: where commands that write built output
+// put it.
+const (
+	// OutputStdout prints the output; -o must not be given
+	OutputStdout = "stdout"
+
+	// OutputFile writes the output to the -o file
+	OutputFile = "file"
+
+	// OutputDir writes one file per resource under the -o directory:
+	// <section>/<Name>.json, e.g. triggers/HighLatency.json
+	OutputDir = "dir"
+)
+
+// OutputModes lists the valid output modes.
+var OutputModes = []string{OutputStdout, OutputFile, OutputDir}
+
+// OutputMode returns the output mode for mode, as given by --output-mode,
+// and the -o path output, checking that the two agree. An empty mode is
+// OutputFile with an output path and OutputStdout without.
+func OutputMode(mode, output string) (string, error) {
+	switch mode {
+	case "":
+		if output == "" {
+			return OutputStdout, nil
+		}
+		return OutputFile, nil
+	case OutputStdout:
+		if output != "" {
+			return "", fmt.Errorf("output mode %s writes to stdout, but -o %s is given", mode, output)
+		}
+	case OutputFile, OutputDir:
+		if output == "" {
+			return "", fmt.Errorf("output mode %s needs an -o path", mode)
+		}
+	default:
+		return "", fmt.Errorf("unknown output mode %q (want %s)", mode, strings.Join(OutputModes, ", "))
+	}
+	return mode, nil
+}
+
+// WriteOutputDir writes build output to dir in OutputDir mode: each
+// resource of the manifest to <section>/<Name>.json, and its errors and
+// references sections to <section>.json. Files of resources no longer
+// built are removed, so the directory mirrors the build. Every file is
+// written atomically.
+func WriteOutputDir(dir string, manifest *honeycomb.Manifest, pretty bool) error {
+	encode := func(data json.RawMessage) ([]byte, error) {
+		if !pretty {
+			return append(data[:len(data):len(data)], '\n'), nil
+		}
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, "", "  "); err != nil {
+			return nil, err
+		}
+		buf.WriteByte('\n')
+		return buf.Bytes(), nil
+	}
+
+	for section, resources := range manifest.Resources() {
+		sectionDir := filepath.Join(dir, section)
+		if err := os.MkdirAll(sectionDir, 0755); err != nil {
+			return fmt.Errorf("create output directory: %w", err)
+		}
+		for name, data := range resources {
+			content, err := encode(data)
+			if err != nil {
+				return fmt.Errorf("serialize %s/%s: %w", section, name, err)
+			}
+			if err := atomicfile.WriteFile(filepath.Join(sectionDir, name+".json"), content, 0644); err != nil {
+				return fmt.Errorf("write output: %w", err)
+			}
+		}
+		if err := removeStale(sectionDir, resources); err != nil {
+			return err
+		}
+	}
+
+	for _, section := range []string{ErrorsSection, refs.Section} {
+		file := filepath.Join(dir, section+".json")
+		data, ok := manifest.Extra[section]
+		if !ok {
+			if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("remove stale output: %w", err)
+			}
+			continue
+		}
+		content, err := encode(data)
+		if err != nil {
+			return fmt.Errorf("serialize %s: %w", section, err)
+		}
+		if err := atomicfile.WriteFile(file, content, 0644); err != nil {
+			return fmt.Errorf("write output: %w", err)
+		}
+	}
+	return nil
+}
+
+// removeStale removes the .json files of dir not named after a resource.
+func removeStale(dir string, resources map[string]json.RawMessage) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read output directory: %w", err)
+	}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() || resources[name] != nil {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, e.Name())); err != nil {
+			return fmt.Errorf("remove stale output: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+)
+
+func TestOutputMode(t *testing.T) {
+	tests := []struct {
+		mode, output, want string
+		wantErr            bool
+	}{
+		{"", "", OutputStdout, false},
+		{"", "out.json", OutputFile, false},
+		{OutputStdout, "", OutputStdout, false},
+		{OutputStdout, "out.json", "", true},
+		{OutputFile, "out.json", OutputFile, false},
+		{OutputFile, "", "", true},
+		{OutputDir, "out", OutputDir, false},
+		{OutputDir, "", "", true},
+		{"tape", "out", "", true},
+	}
+	for _, tt := range tests {
+		got, err := OutputMode(tt.mode, tt.output)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("OutputMode(%q, %q) = %q, %v; want %q", tt.mode, tt.output, got, err, tt.want)
+		}
+	}
+}
+
+func TestWriteOutputDir(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "queries", "Removed.json")
+	if err := os.MkdirAll(filepath.Dir(stale), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{stale, filepath.Join(dir, ErrorsSection+".json")} {
+		if err := os.WriteFile(file, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	manifest := &honeycomb.Manifest{
+		Queries:  map[string]json.RawMessage{"Latency": json.RawMessage(`{"time_range":3600}`)},
+		Triggers: map[string]json.RawMessage{"HighLatency": json.RawMessage(`{"name":"High Latency"}`)},
+		Extra:    map[string]json.RawMessage{"references": json.RawMessage(`[]`)},
+	}
+	if err := WriteOutputDir(dir, manifest, true); err != nil {
+		t.Fatalf("WriteOutputDir failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "triggers", "HighLatency.json"))
+	if err != nil {
+		t.Fatalf("expected a file per resource: %v", err)
+	}
+	if string(data) != "{\n  \"name\": \"High Latency\"\n}\n" {
+		t.Errorf("unexpected trigger file %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "references.json")); err != nil {
+		t.Errorf("expected the references section: %v", err)
+	}
+	for _, file := range []string{stale, filepath.Join(dir, ErrorsSection+".json")} {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("expected stale %s to be removed", filepath.Base(file))
+		}
+	}
+}
+
+func TestBuilderBuild_OutputModes(t *testing.T) {
+	tmpDir := t.TempDir()
+	queryContent := `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var TestQuery = query.Query{
+	Dataset:   "production",
+	TimeRange: query.Hours(1),
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "queries.go"), []byte(queryContent), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	d := &HoneycombDomain{}
+	builder := d.Builder()
+	ctx := &coredomain.Context{}
+
+	d.Options.OutputMode = OutputDir
+	out := filepath.Join(t.TempDir(), "out")
+	result, err := builder.Build(ctx, tmpDir, BuildOpts{Output: out})
+	if err != nil || !result.Success {
+		t.Fatalf("Build failed: %v %+v", err, result)
+	}
+	data, err := os.ReadFile(filepath.Join(out, "queries", "TestQuery.json"))
+	if err != nil {
+		t.Fatalf("expected queries/TestQuery.json: %v", err)
+	}
+	if !strings.Contains(string(data), `"time_range":3600`) {
+		t.Errorf("unexpected query file %s", data)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "wetwire.lock")); !os.IsNotExist(err) {
+		t.Errorf("expected no lockfile in the sources, got %v", err)
+	}
+
+	d.Options.OutputMode = OutputStdout
+	if _, err := builder.Build(ctx, tmpDir, BuildOpts{Output: out}); err == nil {
+		t.Error("expected an error for -o in stdout mode")
+	}
+
+	d.Options.OutputMode = OutputFile
+	if _, err := builder.Build(ctx, tmpDir, BuildOpts{Format: FormatGrafana, Output: out}); err == nil {
+		t.Error("expected an error for grafana output to a file")
+	}
+}
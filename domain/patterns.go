@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// JoinPatterns combines Go-style package patterns such as "./queries/..." into
+// one build path, separated by os.PathListSeparator. The build command passes
+// its arguments to the Builder this way.
+func JoinPatterns(patterns []string) string {
+	return strings.Join(patterns, string(os.PathListSeparator))
+}
+
+// discoverPath discovers the resources selected by a build path of one or
+// more package patterns (see discovery.ExpandPatterns) and filter, and the
+// selected package directories.
+func discoverPath(path string, filter NameFilter) (*discovery.DiscoveredResources, []string, error) {
+	patterns := filepath.SplitList(path)
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	dirs, err := discovery.ExpandPatterns(patterns)
+	if err != nil {
+		return nil, nil, fmt.Errorf("discovery failed: %w", err)
+	}
+
+	resources, err := discovery.DiscoverDirs(dirs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("discovery failed: %w", err)
+	}
+	if !filter.IsZero() {
+		if err := filter.Validate(); err != nil {
+			return nil, nil, err
+		}
+		resources = filter.Apply(resources)
+	}
+	return resources, dirs, nil
+}
@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/i18n"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+// PresetServiceDashboard is the import preset of the queries behind
+// Honeycomb's service home view: latency, errors and throughput by route.
+const PresetServiceDashboard = "service-dashboard"
+
+// Presets are the names of the import presets.
+var Presets = []string{PresetServiceDashboard}
+
+// PresetOpts are the options of ImportPreset.
+type PresetOpts struct {
+	// Service is the service.name the queries are filtered on (required)
+	Service string
+
+	// Dataset is the dataset queried; defaults to Service, the dataset
+	// Honeycomb creates for each service
+	Dataset string
+
+	// Target is a .go file, or a directory the file is created in, to write
+	// the code to; when empty the code is returned as the result data
+	Target string
+}
+
+// ImportPreset generates the queries of a preset as Go declarations, to be
+// customized instead of the Honeycomb defaults they reproduce.
+func ImportPreset(preset string, opts PresetOpts) (*Result, error) {
+	if opts.Service == "" {
+		return nil, fmt.Errorf("preset %s requires a service", preset)
+	}
+	if opts.Dataset == "" {
+		opts.Dataset = opts.Service
+	}
+
+	var queries []importedQuery
+	switch preset {
+	case PresetServiceDashboard:
+		queries = serviceDashboardQueries(opts.Service, opts.Dataset)
+	default:
+		return nil, fmt.Errorf("unknown preset %q: expected one of %s", preset, strings.Join(Presets, ", "))
+	}
+
+	source := fmt.Sprintf("the %s preset", preset)
+	message := i18n.Sprintf("Imported %d queries", len(queries))
+	if opts.Target == "" {
+		code, err := generateQueryFile("queries", source, queries)
+		if err != nil {
+			return nil, err
+		}
+		return NewResultWithData(message, string(code)), nil
+	}
+
+	file, err := writeGenerated(opts.Target, strings.ToLower(goName(opts.Service))+".go", func(pkg string) ([]byte, error) {
+		return generateQueryFile(pkg, source, queries)
+	})
+	if err != nil {
+		return NewErrorResult(i18n.T("import failed"), Error{Path: opts.Target, Message: err.Error()}), nil
+	}
+	return NewResult(i18n.Sprintf("%s to %s", message, file)), nil
+}
+
+// serviceDashboardQueries returns the queries of Honeycomb's service home
+// view for a service: its server spans over the last 2 hours, broken down by
+// route.
+func serviceDashboardQueries(service, dataset string) []importedQuery {
+	name := goName(service)
+	spans := func(filters ...query.Filter) []query.Filter {
+		return append([]query.Filter{
+			query.Equals("service.name", service),
+			query.Equals("span.kind", "server"),
+		}, filters...)
+	}
+	byRoute := func(calculations []query.Calculation, filters []query.Filter, order query.Order) query.Query {
+		return query.Query{
+			Dataset:      dataset,
+			TimeRange:    query.Hours(2),
+			Breakdowns:   []string{"http.route"},
+			Calculations: calculations,
+			Filters:      filters,
+			Orders:       []query.Order{order},
+			Limit:        100,
+		}
+	}
+	count := query.Order{Op: "COUNT", Order: "descending"}
+
+	return []importedQuery{
+		{
+			Name: name + "Latency",
+			Doc:  fmt.Sprintf("%sLatency is the latency distribution and percentiles of %s by route.", name, service),
+			Query: byRoute([]query.Calculation{
+				query.Heatmap("duration_ms"),
+				query.P50("duration_ms"),
+				query.P95("duration_ms"),
+				query.P99("duration_ms"),
+			}, spans(), query.Order{Column: "duration_ms", Op: "P99", Order: "descending"}),
+		},
+		{
+			Name:  name + "Errors",
+			Doc:   fmt.Sprintf("%sErrors counts the failed requests of %s by route.", name, service),
+			Query: byRoute([]query.Calculation{query.Count()}, spans(query.Equals("error", true)), count),
+		},
+		{
+			Name:  name + "Throughput",
+			Doc:   fmt.Sprintf("%sThroughput counts the requests of %s by route.", name, service),
+			Query: byRoute([]query.Calculation{query.Count()}, spans(), count),
+		},
+	}
+}
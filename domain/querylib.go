@@ -0,0 +1,324 @@
+package domain
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/internal/annotate"
+	"github.com/lex00/wetwire-honeycomb-go/internal/serialize"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+// importedTrigger is a trigger parsed from JSON with the Go name it is
+// declared as and the query variable it evaluates.
+type importedTrigger struct {
+	Name    string
+	Query   string
+	Trigger trigger.Trigger
+}
+
+// importedBoard is a board parsed from JSON with the Go name it is declared
+// as and the query variables of its query panels, depth first.
+type importedBoard struct {
+	Name    string
+	Queries []string
+	Board   board.Board
+}
+
+// Panel accessor interfaces, matching the unexported board panel types.
+type queryPanelAccessor interface {
+	Query() query.Query
+	Config() board.PanelConfig
+}
+
+type textPanelAccessor interface {
+	Content() string
+	Config() board.PanelConfig
+}
+
+type sloPanelAccessor interface {
+	SLOID() string
+	Config() board.PanelConfig
+}
+
+type sectionPanelAccessor interface {
+	Panels() []board.Panel
+	Config() board.PanelConfig
+}
+
+// queryLibrary collects the queries of an import, declaring each structurally
+// distinct query spec once: a trigger or board panel whose query has the spec
+// of a query already in the library references that query's variable.
+type queryLibrary struct {
+	queries []*importedQuery
+
+	// bySpec indexes queries by their spec without the dataset
+	bySpec map[string][]*importedQuery
+
+	// names are the Go names declared so far
+	names map[string]bool
+
+	// shared counts the query specs that reused a variable
+	shared int
+}
+
+func newQueryLibrary() *queryLibrary {
+	return &queryLibrary{
+		bySpec: make(map[string][]*importedQuery),
+		names:  make(map[string]bool),
+	}
+}
+
+// declare reserves a Go name based on name, numbering it when taken.
+func (l *queryLibrary) declare(name string) string {
+	unique := name
+	for i := 2; l.names[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	l.names[unique] = true
+	return unique
+}
+
+// add declares q as a saved query named name, even when its spec is already
+// in the library: saved queries are resources of their own.
+func (l *queryLibrary) add(name string, q query.Query) {
+	iq := &importedQuery{Name: l.declare(name), Query: q}
+	l.queries = append(l.queries, iq)
+	key := specKey(q)
+	l.bySpec[key] = append(l.bySpec[key], iq)
+}
+
+// use returns the variable of the query with the spec of q, used by user,
+// declaring it named name when there is none. Queries match when their
+// datasets are equal or one is empty; an empty dataset takes the other's.
+func (l *queryLibrary) use(name, user string, q query.Query) string {
+	for _, iq := range l.bySpec[specKey(q)] {
+		if iq.Query.Dataset != "" && q.Dataset != "" && iq.Query.Dataset != q.Dataset {
+			continue
+		}
+		if iq.Query.Dataset == "" {
+			iq.Query.Dataset = q.Dataset
+		}
+		if n := len(iq.UsedBy); n == 0 || iq.UsedBy[n-1] != user {
+			iq.UsedBy = append(iq.UsedBy, user)
+		}
+		l.shared++
+		return iq.Name
+	}
+
+	l.add(name, q)
+	iq := l.queries[len(l.queries)-1]
+	iq.UsedBy = []string{user}
+	return iq.Name
+}
+
+// sorted returns the queries sorted by name.
+func (l *queryLibrary) sorted() []importedQuery {
+	queries := make([]importedQuery, len(l.queries))
+	for i, iq := range l.queries {
+		queries[i] = *iq
+	}
+	sort.Slice(queries, func(i, j int) bool { return queries[i].Name < queries[j].Name })
+	return queries
+}
+
+// specKey returns the Query JSON of q without its dataset, the same for
+// structurally equal queries.
+func specKey(q query.Query) string {
+	q.Dataset = ""
+	data, err := serialize.ToJSON(q)
+	if err != nil {
+		return fmt.Sprintf("%#v", q)
+	}
+	return string(data)
+}
+
+// hasQuerySpec reports whether q sets anything but its dataset.
+func hasQuerySpec(q query.Query) bool {
+	return specKey(q) != "{}"
+}
+
+// boardQueries returns the queries of the query panels of panels, depth
+// first, with the Go name each would be declared as: the board name followed
+// by the panel title, or by the panel number.
+func boardQueries(boardName string, panels []board.Panel) (names []string, queries []query.Query) {
+	var walk func(panels []board.Panel)
+	walk = func(panels []board.Panel) {
+		for _, p := range panels {
+			switch panel := p.(type) {
+			case queryPanelAccessor:
+				name := boardName + "Panel" + strconv.Itoa(len(queries)+1)
+				if title := panel.Config().Title; title != "" {
+					name = boardName + goName(title)
+				}
+				names = append(names, name)
+				queries = append(queries, panel.Query())
+			case sectionPanelAccessor:
+				walk(panel.Panels())
+			}
+		}
+	}
+	walk(panels)
+	return names, queries
+}
+
+// writeTrigger writes the declaration of a trigger, unformatted, to b.
+func writeTrigger(b *bytes.Buffer, it importedTrigger, source string) {
+	t := it.Trigger
+	fmt.Fprintf(b, "\n// %s was imported from %s.\n", it.Name, source)
+	fmt.Fprintf(b, "var %s = trigger.Trigger{\nName: %q,\n", it.Name, t.Name)
+	if d := annotate.Strip(t.Description); d != "" {
+		fmt.Fprintf(b, "Description: %q,\n", d)
+	}
+	if t.Dataset != "" {
+		fmt.Fprintf(b, "Dataset: %q,\n", t.Dataset)
+	} else {
+		b.WriteString("Dataset: \"production\", // TODO: Change to your dataset\n")
+	}
+	fmt.Fprintf(b, "Query: %s,\n", it.Query)
+	if fn, ok := thresholdFuncs[string(t.Threshold.Op)]; ok {
+		fmt.Fprintf(b, "Threshold: trigger.%s(%g),\n", fn, t.Threshold.Value)
+	} else if t.Threshold.Op != "" {
+		fmt.Fprintf(b, "Threshold: trigger.Threshold{Op: %q, Value: %g},\n", t.Threshold.Op, t.Threshold.Value)
+	}
+	switch s := t.Frequency.Seconds; {
+	case s > 0 && s%60 == 0:
+		fmt.Fprintf(b, "Frequency: trigger.Minutes(%d),\n", s/60)
+	case s > 0:
+		fmt.Fprintf(b, "Frequency: trigger.Seconds(%d),\n", s)
+	}
+	switch t.AlertType {
+	case "":
+	case trigger.OnChange:
+		b.WriteString("AlertType: trigger.OnChange,\n")
+	case trigger.OnTrue:
+		b.WriteString("AlertType: trigger.OnTrue,\n")
+	default:
+		fmt.Fprintf(b, "AlertType: %q,\n", t.AlertType)
+	}
+	if len(t.Recipients) > 0 {
+		b.WriteString("Recipients: []trigger.Recipient{\n")
+		for _, r := range t.Recipients {
+			if fn, ok := recipientFuncs[string(r.Type)]; ok {
+				fmt.Fprintf(b, "trigger.%s(%q),\n", fn, r.Target)
+			} else {
+				fmt.Fprintf(b, "{Type: %q, Target: %q},\n", r.Type, r.Target)
+			}
+		}
+		b.WriteString("},\n")
+	}
+	if t.Disabled {
+		b.WriteString("Disabled: true,\n")
+	}
+	if len(t.Tags) > 0 {
+		b.WriteString("Tags: []trigger.Tag{\n")
+		for _, tag := range t.Tags {
+			fmt.Fprintf(b, "{Key: %q, Value: %q},\n", tag.Key, tag.Value)
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("}\n")
+}
+
+// writeBoard writes the declaration of a board, unformatted, to b.
+func writeBoard(b *bytes.Buffer, ib importedBoard, source string) {
+	bd := ib.Board
+	fmt.Fprintf(b, "\n// %s was imported from %s.\n", ib.Name, source)
+	fmt.Fprintf(b, "var %s = board.Board{\nName: %q,\n", ib.Name, bd.Name)
+	if d := annotate.Strip(bd.Description); d != "" {
+		fmt.Fprintf(b, "Description: %q,\n", d)
+	}
+	if len(bd.Panels) > 0 {
+		b.WriteString("Panels: []board.Panel{\n")
+		queries := ib.Queries
+		writePanels(b, bd.Panels, &queries)
+		b.WriteString("},\n")
+	}
+	if len(bd.PresetFilters) > 0 {
+		b.WriteString("PresetFilters: []board.Filter{\n")
+		for _, f := range bd.PresetFilters {
+			fmt.Fprintf(b, "{Column: %q, Operation: %q, Value: %s},\n", f.Column, f.Operation, valueCode(f.Value))
+		}
+		b.WriteString("},\n")
+	}
+	if len(bd.Tags) > 0 {
+		b.WriteString("Tags: []board.Tag{\n")
+		for _, tag := range bd.Tags {
+			fmt.Fprintf(b, "{Key: %q, Value: %q},\n", tag.Key, tag.Value)
+		}
+		b.WriteString("},\n")
+	}
+	b.WriteString("}\n")
+}
+
+// writePanels writes panels as board panel expressions, taking the query
+// variables of query panels from queries in order.
+func writePanels(b *bytes.Buffer, panels []board.Panel, queries *[]string) {
+	for _, p := range panels {
+		switch panel := p.(type) {
+		case queryPanelAccessor:
+			fmt.Fprintf(b, "board.QueryPanel(%s%s),\n", (*queries)[0], panelOptions(panel.Config()))
+			*queries = (*queries)[1:]
+		case textPanelAccessor:
+			fmt.Fprintf(b, "board.TextPanel(%q%s),\n", panel.Content(), panelOptions(panel.Config()))
+		case sloPanelAccessor:
+			fmt.Fprintf(b, "board.SLOPanelByID(%q%s),\n", panel.SLOID(), panelOptions(panel.Config()))
+		case sectionPanelAccessor:
+			fmt.Fprintf(b, "board.Section(%q,\n", panel.Config().Title)
+			writePanels(b, panel.Panels(), queries)
+			b.WriteString("),\n")
+		}
+	}
+}
+
+// panelOptions returns the panel options of config, each preceded by a comma.
+func panelOptions(config board.PanelConfig) string {
+	var opts strings.Builder
+	if config.Title != "" {
+		fmt.Fprintf(&opts, ", board.WithTitle(%q)", config.Title)
+	}
+	if pos := config.Position; pos.Width > 0 || pos.Height > 0 {
+		fmt.Fprintf(&opts, ", board.WithPosition(%d, %d, %d, %d)", pos.X, pos.Y, pos.Width, pos.Height)
+	}
+	graph := config.Graph
+	if name, ok := graphConstantNames[string(graph.Style)]; ok {
+		fmt.Fprintf(&opts, ", board.WithQueryStyle(board.%s)", name)
+	} else if graph.Style != "" {
+		fmt.Fprintf(&opts, ", board.WithQueryStyle(%q)", graph.Style)
+	}
+	if name, ok := graphConstantNames[string(graph.ChartType)]; ok {
+		fmt.Fprintf(&opts, ", board.WithChartType(board.%s)", name)
+	} else if graph.ChartType != "" {
+		fmt.Fprintf(&opts, ", board.WithChartType(%q)", graph.ChartType)
+	}
+	if graph.OmitMissingValues {
+		opts.WriteString(", board.OmitMissingValues()")
+	}
+	if graph.LogScale {
+		opts.WriteString(", board.LogScale()")
+	}
+	if graph.Stacked {
+		opts.WriteString(", board.Stacked()")
+	}
+	return opts.String()
+}
+
+// graphConstantNames maps the values of the board.QueryStyle and
+// board.ChartType constants to their names.
+var graphConstantNames = map[string]string{
+	string(board.StyleGraph):   "StyleGraph",
+	string(board.StyleTable):   "StyleTable",
+	string(board.StyleCombo):   "StyleCombo",
+	string(board.ChartDefault): "ChartDefault",
+	string(board.ChartLine):    "ChartLine",
+	string(board.ChartStacked): "ChartStacked",
+	string(board.ChartBar):     "ChartBar",
+	string(board.ChartStat):    "ChartStat",
+	string(board.ChartPie):     "ChartPie",
+	string(board.ChartColumn):  "ChartColumn",
+}
@@ -0,0 +1,151 @@
+package domain
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+// ParseFilter parses a filter written as "column op [value]", such as
+// "http.status_code >= 500", "error = true" or "region in us-east-1,eu-west-1".
+// Numbers and true/false are typed; other values are strings and may be
+// quoted. Values of in and not-in are comma-separated.
+func ParseFilter(s string) (query.Filter, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 2 {
+		return query.Filter{}, fmt.Errorf("filter %q: expected \"column op [value]\"", s)
+	}
+
+	f := query.Filter{Column: fields[0], Op: fields[1]}
+	if len(fields) > 2 {
+		raw := strings.TrimSpace(s)[len(fields[0]):]
+		raw = strings.TrimSpace(strings.TrimSpace(raw)[len(fields[1]):])
+		if f.Op == "in" || f.Op == "not-in" {
+			var values []any
+			for _, v := range strings.Split(raw, ",") {
+				values = append(values, parseFilterValue(strings.TrimSpace(v)))
+			}
+			f.Value = values
+		} else {
+			f.Value = parseFilterValue(raw)
+		}
+	}
+
+	if err := f.Validate(); err != nil {
+		return query.Filter{}, err
+	}
+	return f, nil
+}
+
+// parseFilterValue types a filter value written on the command line.
+func parseFilterValue(s string) any {
+	if unquoted, err := strconv.Unquote(s); err == nil {
+		return unquoted
+	}
+	if b, err := strconv.ParseBool(s); err == nil && (s == "true" || s == "false") {
+		return b
+	}
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return n
+	}
+	return s
+}
+
+// GenerateRatio returns a gofmt-formatted Go file in package pkg that
+// expresses r as queries named after name: the derived column r needs
+// (<name>Column), the query averaging it (<name>) and the pair of COUNT
+// queries to divide when the derived column is not available (<name>Matching
+// and <name>Total).
+func GenerateRatio(pkg, name string, r query.Ratio) ([]byte, error) {
+	if !token.IsIdentifier(name) || !token.IsExported(name) {
+		return nil, fmt.Errorf("%q is not an exported Go identifier", name)
+	}
+	if r.Alias == "" {
+		r.Alias = aliasName(name)
+	}
+	column, err := r.DerivedColumn()
+	if err != nil {
+		return nil, err
+	}
+
+	condition := describeMatch(r.Match)
+	matching, total := r.Queries()
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import \"github.com/lex00/wetwire-honeycomb-go/query\"\n\n")
+
+	fmt.Fprintf(&b, "// %sColumn is the derived column %s averages.\n", name, name)
+	fmt.Fprintf(&b, "// Create it in the %s dataset's settings, or through the Honeycomb API.\n", r.Dataset)
+	fmt.Fprintf(&b, "var %sColumn = query.DerivedColumn{\n", name)
+	fmt.Fprintf(&b, "Alias: %q,\n", column.Alias)
+	fmt.Fprintf(&b, "Expression: %q,\n", column.Expression)
+	fmt.Fprintf(&b, "Description: %q,\n", column.Description)
+	b.WriteString("}\n")
+
+	writeQuery(&b, importedQuery{
+		Name:  name,
+		Doc:   fmt.Sprintf("%s is the percentage of events where %s.", name, condition),
+		Query: r.Query(),
+	})
+	writeQuery(&b, importedQuery{
+		Name:  name + "Matching",
+		Doc:   fmt.Sprintf("%sMatching counts the events where %s.\nDivide by %sTotal for the percentage without the derived column.", name, condition, name),
+		Query: matching,
+	})
+	writeQuery(&b, importedQuery{
+		Name:  name + "Total",
+		Doc:   fmt.Sprintf("%sTotal counts the events %s is a percentage of.", name, name),
+		Query: total,
+	})
+
+	code, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return code, nil
+}
+
+// aliasName converts a Go name such as ErrorRate to a column alias such as
+// error_rate.
+func aliasName(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// describeMatch returns a short human-readable form of filters.
+func describeMatch(filters []query.Filter) string {
+	parts := make([]string, len(filters))
+	for i, f := range filters {
+		parts[i] = f.Column + " " + f.Op
+		if f.Value != nil {
+			parts[i] += " " + valueCode(f.Value)
+		}
+	}
+	return strings.Join(parts, " and ")
+}
+
+// WriteRatio generates the ratio queries of r named after name into target,
+// a .go file or a directory the file <alias>.go is created in, and returns
+// the path of the file. An existing file is never overwritten.
+func WriteRatio(name string, r query.Ratio, target string) (string, error) {
+	if r.Alias == "" {
+		r.Alias = aliasName(name)
+	}
+	return writeGenerated(target, r.Alias+".go", func(pkg string) ([]byte, error) {
+		return GenerateRatio(pkg, name, r)
+	})
+}
@@ -0,0 +1,111 @@
+package domain
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		input string
+		want  query.Filter
+	}{
+		{"http.status_code >= 500", query.GTE("http.status_code", float64(500))},
+		{"error = true", query.Equals("error", true)},
+		{"service.name = checkout", query.Equals("service.name", "checkout")},
+		{`message contains "timed out"`, query.Contains("message", "timed out")},
+		{"trace.parent_id does-not-exist", query.DoesNotExist("trace.parent_id")},
+		{"region in us-east-1, eu-west-1", query.In("region", []any{"us-east-1", "eu-west-1"})},
+	}
+	for _, tt := range tests {
+		got, err := ParseFilter(tt.input)
+		if err != nil {
+			t.Errorf("ParseFilter(%q) failed: %v", tt.input, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseFilter(%q) = %#v, want %#v", tt.input, got, tt.want)
+		}
+	}
+
+	for _, input := range []string{"error", "status matches 5..", "error exists true"} {
+		if _, err := ParseFilter(input); err == nil {
+			t.Errorf("ParseFilter(%q): expected an error", input)
+		}
+	}
+}
+
+func TestGenerateRatio(t *testing.T) {
+	r := query.Ratio{
+		Dataset:   "api",
+		TimeRange: query.Hours(2),
+		Match:     []query.Filter{query.GTE("http.status_code", 500)},
+	}
+	code, err := GenerateRatio("queries", "ErrorRate", r)
+	if err != nil {
+		t.Fatalf("GenerateRatio failed: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "ratio.go", code, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, code)
+	}
+	for _, want := range []string{
+		"var ErrorRateColumn = query.DerivedColumn{",
+		`Alias:       "error_rate",`,
+		`Expression:  "IF(GTE($http.status_code, 500), 100, 0)",`,
+		`query.Avg("error_rate"),`,
+		"var ErrorRateMatching = query.Query{",
+		"var ErrorRateTotal = query.Query{",
+	} {
+		if !strings.Contains(string(code), want) {
+			t.Errorf("generated code missing %q:\n%s", want, code)
+		}
+	}
+
+	if _, err := GenerateRatio("queries", "errorRate", r); err == nil {
+		t.Error("expected an error for an unexported name")
+	}
+	r.Match = nil
+	if _, err := GenerateRatio("queries", "ErrorRate", r); err == nil {
+		t.Error("expected an error without Match filters")
+	}
+}
+
+func TestWriteRatio(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "ratios")
+	r := query.Ratio{
+		Dataset:   "api",
+		TimeRange: query.Hours(2),
+		Match:     []query.Filter{query.Equals("error", true)},
+	}
+	file, err := WriteRatio("HTTPErrorRate", r, dir)
+	if err != nil {
+		t.Fatalf("WriteRatio failed: %v", err)
+	}
+	if file != filepath.Join(dir, "http_error_rate.go") {
+		t.Errorf("unexpected file %s", file)
+	}
+
+	// The queries are discovered; the derived column is not a query
+	queries, err := discovery.DiscoverQueries(dir)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	var names []string
+	for _, q := range queries {
+		names = append(names, q.Name)
+	}
+	if strings.Join(names, ",") != "HTTPErrorRate,HTTPErrorRateMatching,HTTPErrorRateTotal" {
+		t.Errorf("unexpected queries %v", names)
+	}
+
+	if _, err := WriteRatio("HTTPErrorRate", r, dir); err == nil {
+		t.Error("expected an error when the file exists")
+	}
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"fmt"
+
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lock"
+	"github.com/lex00/wetwire-honeycomb-go/internal/refs"
+)
+
+// References returns the references manifest of build output: a reference
+// for every placeholder in output, and an ordering-only reference (without
+// Field) from each board to the queries it shows and from each SLO to its
+// event queries. References to resources not in output are left out.
+func References(resources *discovery.DiscoveredResources, output *honeycomb.Manifest) ([]refs.Reference, error) {
+	var references []refs.Reference
+	for section, bodies := range output.Resources() {
+		for name, body := range bodies {
+			found, err := refs.Find(body)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", lock.Key(section, name), err)
+			}
+			for field, to := range found {
+				references = append(references, refs.Reference{From: lock.Key(section, name), To: to, Field: field})
+			}
+		}
+	}
+
+	built := func(key string) bool {
+		section, name := lock.SplitKey(key)
+		_, ok := output.Get(section, name)
+		return ok
+	}
+	depend := func(from, query string) {
+		to := lock.Key("queries", query)
+		if query != "" && built(from) && built(to) {
+			references = append(references, refs.Reference{From: from, To: to})
+		}
+	}
+	for _, b := range resources.Boards {
+		for _, ref := range b.QueryRefs {
+			depend(lock.Key("boards", b.Name), ref)
+		}
+	}
+	for _, s := range resources.SLOs {
+		depend(lock.Key("slos", s.Name), s.GoodEventsQueryRef)
+		depend(lock.Key("slos", s.Name), s.TotalEventsQueryRef)
+	}
+	return refs.Sort(references), nil
+}
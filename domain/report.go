@@ -0,0 +1,342 @@
+package domain
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/contenthash"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/resource"
+)
+
+// Resource statuses in a build report.
+const (
+	statusOK      = "ok"
+	statusWarning = "warning"
+	statusError   = "error"
+)
+
+// BuildReport is the machine-readable summary written by build --report, for
+// CI dashboards tracking resource counts and growth over time.
+type BuildReport struct {
+	// Version is the wetwire-honeycomb version that produced the report
+	Version string `json:"version"`
+
+	// Path is the package path that was built
+	Path string `json:"path"`
+
+	// Success is false when the build itself failed
+	Success bool `json:"success"`
+
+	Stats     BuildStats       `json:"stats"`
+	Resources []ResourceReport `json:"resources"`
+}
+
+// BuildStats are the totals of a build report.
+type BuildStats struct {
+	Resources int            `json:"resources"`
+	ByType    map[string]int `json:"by_type"`
+	Bytes     int            `json:"bytes"`
+	Warnings  int            `json:"warnings"`
+	Errors    int            `json:"errors"`
+}
+
+// ResourceReport is the status of one resource in a build report.
+type ResourceReport struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Status string `json:"status"`
+
+	// Size is the length in bytes of the serialized JSON
+	Size int `json:"size"`
+
+	// Hash is the content hash of the serialized JSON, as in wetwire.lock
+	Hash string `json:"hash"`
+
+	// Warnings are the lint findings and build errors for the resource
+	Warnings []Error `json:"warnings,omitempty"`
+}
+
+// reportTypes maps build output keys to report resource types.
+var reportTypes = map[string]string{
+	"queries":  "query",
+	"boards":   "board",
+	"slos":     "slo",
+	"triggers": "trigger",
+}
+
+// outputTypes maps the sections of the build output, including those of
+// registered kinds, to report resource types.
+func outputTypes() map[string]string {
+	types := make(map[string]string)
+	for key, typ := range reportTypes {
+		types[key] = typ
+	}
+	for _, kind := range resource.Kinds() {
+		types[kind.Section] = kind.Name
+	}
+	return types
+}
+
+// ResourceCounts returns the number of resources in path by build output
+// section (queries, boards, slos, triggers and the sections of registered
+// kinds), omitting sections with none. Only the resources filter selects are
+// counted.
+func ResourceCounts(path string, filter NameFilter) (map[string]int, error) {
+	resources, _, err := discoverPath(path, filter)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	for key, n := range map[string]int{
+		"queries":  len(resources.Queries),
+		"boards":   len(resources.Boards),
+		"slos":     len(resources.SLOs),
+		"triggers": len(resources.Triggers),
+	} {
+		if n > 0 {
+			counts[key] = n
+		}
+	}
+	for _, r := range resources.Custom {
+		if kind, ok := resource.Lookup(r.Kind); ok {
+			counts[kind.Section]++
+		}
+	}
+	return counts, nil
+}
+
+// PayloadStats are the serialized sizes of one section of the build output,
+// as printed by build --stats.
+type PayloadStats struct {
+	// Section is the build output key: queries, boards, slos, triggers or the
+	// section of a registered kind
+	Section string `json:"section"`
+
+	Count int `json:"count"`
+
+	// Bytes is the total length of the compact JSON payloads
+	Bytes int `json:"bytes"`
+
+	// Largest is the name of the largest resource, of LargestBytes
+	Largest      string `json:"largest"`
+	LargestBytes int    `json:"largest_bytes"`
+}
+
+// GeneratePayloadStats serializes the resources in path as build does with
+// opts and returns the payload sizes of each output section, sorted by
+// section.
+func GeneratePayloadStats(path string, opts Options) ([]PayloadStats, error) {
+	resources, _, err := discoverPath(path, opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+	ExpandEnv(resources, opts.AllowEnv)
+
+	output, err := SerializeResources(resources, "", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := []PayloadStats{}
+	for section, resourceMap := range output.Resources() {
+		s := PayloadStats{Section: section}
+		for name, data := range resourceMap {
+			s.Count++
+			s.Bytes += len(data)
+			if len(data) > s.LargestBytes || (len(data) == s.LargestBytes && name < s.Largest) {
+				s.Largest, s.LargestBytes = name, len(data)
+			}
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Section < stats[j].Section })
+	return stats, nil
+}
+
+// ResourceID is the content hash of one built resource, as printed by list and
+// build --ids.
+type ResourceID struct {
+	// Type is the resource type as listed (query, board, slo, trigger or the
+	// name of a registered kind) and Section its section of the build output
+	Type    string `json:"type"`
+	Section string `json:"section"`
+	Name    string `json:"name"`
+
+	// Hash is the sha256 of the resource's canonical JSON, as in wetwire.lock,
+	// and ID its first contenthash.IDLength hex digits
+	Hash string `json:"hash"`
+	ID   string `json:"id"`
+}
+
+// GenerateResourceIDs serializes the resources in path as build does with
+// opts and returns their content hashes, sorted by section and name.
+// Resources with the same JSON have the same hash, whatever their names.
+func GenerateResourceIDs(path string, opts Options) ([]ResourceID, error) {
+	resources, _, err := discoverPath(path, opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+	ExpandEnv(resources, opts.AllowEnv)
+
+	output, err := SerializeResources(resources, "", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	types := outputTypes()
+	ids := []ResourceID{}
+	for section, resourceMap := range output.Resources() {
+		for name, data := range resourceMap {
+			hash, err := contenthash.Hash(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", section, name, err)
+			}
+			ids = append(ids, ResourceID{Type: types[section], Section: section, Name: name, Hash: hash, ID: contenthash.ID(hash)})
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		if ids[i].Section != ids[j].Section {
+			return ids[i].Section < ids[j].Section
+		}
+		return ids[i].Name < ids[j].Name
+	})
+	return ids, nil
+}
+
+// GenerateBuildReport builds the resources in path with opts, lints them and
+// reports the status of each. Findings are attributed to the closest resource declared
+// above them in the same file. File paths are relative to the directory
+// containing every built package.
+func GenerateBuildReport(ctx *Context, path string, opts Options) (*BuildReport, error) {
+	resources, dirs, err := discoverPath(path, opts.Filter)
+	if err != nil {
+		return nil, err
+	}
+	absPath := discovery.CommonDir(dirs)
+
+	buildErrors := append(ExpandEnv(resources, opts.AllowEnv), timeRangeErrors(resources.Queries)...)
+
+	output, err := SerializeResources(resources, "", opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Error
+	for _, dir := range dirs {
+		lintResult, err := (&honeycombLinter{opts: &opts}).Lint(ctx, dir, LintOpts{})
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, lintResult.Errors...)
+	}
+
+	report := &BuildReport{
+		Version:   Version,
+		Path:      path,
+		Success:   resources.TotalCount() > 0 && len(buildErrors) == 0,
+		Stats:     BuildStats{ByType: make(map[string]int)},
+		Resources: []ResourceReport{},
+	}
+
+	type location struct {
+		file string
+		line int
+	}
+	locations := make(map[string]location)
+	for _, q := range resources.Queries {
+		locations["query/"+q.Name] = location{q.File, q.Line}
+	}
+	for _, b := range resources.Boards {
+		locations["board/"+b.Name] = location{b.File, b.Line}
+	}
+	for _, s := range resources.SLOs {
+		locations["slo/"+s.Name] = location{s.File, s.Line}
+	}
+	for _, t := range resources.Triggers {
+		locations["trigger/"+t.Name] = location{t.File, t.Line}
+	}
+	types := outputTypes()
+	for _, r := range resources.Custom {
+		locations[r.Kind+"/"+r.Name] = location{r.File, r.Line}
+	}
+
+	for key, resourceMap := range output.Resources() {
+		typ := types[key]
+		for name, data := range resourceMap {
+			loc := locations[typ+"/"+name]
+			hash, err := contenthash.Hash(data)
+			if err != nil {
+				return nil, err
+			}
+			report.Resources = append(report.Resources, ResourceReport{
+				Name:   name,
+				Type:   typ,
+				File:   loc.file,
+				Line:   loc.line,
+				Status: statusOK,
+				Size:   len(data),
+				Hash:   hash,
+			})
+			report.Stats.Resources++
+			report.Stats.ByType[typ]++
+			report.Stats.Bytes += len(data)
+		}
+	}
+
+	// Sort by file and line so findings can be attributed
+	sort.Slice(report.Resources, func(i, j int) bool {
+		a, b := report.Resources[i], report.Resources[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+
+	for _, e := range append(findings, buildErrors...) {
+		if e.Severity == "error" {
+			report.Stats.Errors++
+		} else {
+			report.Stats.Warnings++
+		}
+
+		owner := -1
+		for i, r := range report.Resources {
+			if r.File == e.Path && r.Line <= e.Line {
+				owner = i
+			}
+		}
+		if owner < 0 {
+			continue
+		}
+
+		r := &report.Resources[owner]
+		e.Path = ""
+		r.Warnings = append(r.Warnings, e)
+		switch {
+		case e.Severity == "error":
+			r.Status = statusError
+		case r.Status == statusOK:
+			r.Status = statusWarning
+		}
+	}
+
+	for i := range report.Resources {
+		if rel, err := filepath.Rel(absPath, report.Resources[i].File); err == nil {
+			report.Resources[i].File = rel
+		}
+	}
+
+	sort.SliceStable(report.Resources, func(i, j int) bool {
+		a, b := report.Resources[i], report.Resources[j]
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Name < b.Name
+	})
+
+	return report, nil
+}
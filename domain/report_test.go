@@ -0,0 +1,199 @@
+package domain
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const reportSource = `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Latency = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Calculations: []query.Calculation{query.P99("duration_ms"), query.Heatmap("duration_ms")},
+}
+
+var ByService = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Breakdowns:   []string{"service"},
+	Calculations: []query.Calculation{query.Count()},
+}
+`
+
+func TestGenerateBuildReport(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(reportSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := GenerateBuildReport(nil, dir, Options{})
+	if err != nil {
+		t.Fatalf("GenerateBuildReport failed: %v", err)
+	}
+
+	if !report.Success {
+		t.Error("expected success")
+	}
+	if report.Stats.Resources != 2 || report.Stats.ByType["query"] != 2 {
+		t.Errorf("unexpected stats: %+v", report.Stats)
+	}
+	if len(report.Resources) != 2 {
+		t.Fatalf("expected 2 resources, got %d", len(report.Resources))
+	}
+
+	total := 0
+	for _, r := range report.Resources {
+		total += r.Size
+		if r.File != "queries.go" {
+			t.Errorf("%s: expected relative file, got %q", r.Name, r.File)
+		}
+	}
+	if total != report.Stats.Bytes {
+		t.Errorf("resource sizes sum to %d, stats report %d", total, report.Stats.Bytes)
+	}
+
+	// Sorted by type, then name; breakdowns without an order are a lint warning
+	byService, latency := report.Resources[0], report.Resources[1]
+	if byService.Name != "ByService" || latency.Name != "Latency" {
+		t.Fatalf("unexpected order: %s, %s", byService.Name, latency.Name)
+	}
+	if byService.Status != statusWarning || len(byService.Warnings) == 0 {
+		t.Errorf("expected ByService warnings, got %+v", byService)
+	}
+	if byService.Warnings[0].Path != "" {
+		t.Errorf("expected warning path to be omitted, got %q", byService.Warnings[0].Path)
+	}
+	if latency.Status != statusOK || latency.Line != 5 {
+		t.Errorf("unexpected Latency report: %+v", latency)
+	}
+	if report.Stats.Warnings != len(byService.Warnings) {
+		t.Errorf("expected %d warnings in stats, got %d", len(byService.Warnings), report.Stats.Warnings)
+	}
+}
+
+func TestGenerateBuildReport_BuildError(t *testing.T) {
+	dir := t.TempDir()
+	src := `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Broken = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.TimeRange{StartTime: 200, EndTime: 100},
+	Calculations: []query.Calculation{query.Count()},
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := GenerateBuildReport(nil, dir, Options{})
+	if err != nil {
+		t.Fatalf("GenerateBuildReport failed: %v", err)
+	}
+	if report.Success {
+		t.Error("expected failure for an inverted time range")
+	}
+	if len(report.Resources) != 1 || report.Resources[0].Status != statusError {
+		t.Errorf("expected Broken to have error status, got %+v", report.Resources)
+	}
+	if report.Stats.Errors == 0 {
+		t.Error("expected errors in stats")
+	}
+}
+
+func TestResourceCounts(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(reportSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	counts, err := ResourceCounts(dir, NameFilter{})
+	if err != nil {
+		t.Fatalf("ResourceCounts failed: %v", err)
+	}
+	if len(counts) != 1 || counts["queries"] != 2 {
+		t.Errorf("expected 2 queries only, got %v", counts)
+	}
+}
+
+func TestGeneratePayloadStats(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(reportSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := GeneratePayloadStats(dir, Options{})
+	if err != nil {
+		t.Fatalf("GeneratePayloadStats failed: %v", err)
+	}
+	if len(stats) != 1 || stats[0].Section != "queries" || stats[0].Count != 2 {
+		t.Fatalf("expected 2 queries only, got %+v", stats)
+	}
+
+	report, err := GenerateBuildReport(nil, dir, Options{})
+	if err != nil {
+		t.Fatalf("GenerateBuildReport failed: %v", err)
+	}
+	if stats[0].Bytes != report.Stats.Bytes {
+		t.Errorf("expected %d bytes as in the build report, got %d", report.Stats.Bytes, stats[0].Bytes)
+	}
+	if stats[0].Largest != "Latency" || stats[0].LargestBytes >= stats[0].Bytes {
+		t.Errorf("expected Latency to be the largest query, got %+v", stats[0])
+	}
+}
+
+func TestGenerateResourceIDs(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(reportSource), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := GenerateResourceIDs(dir, Options{})
+	if err != nil {
+		t.Fatalf("GenerateResourceIDs failed: %v", err)
+	}
+	if len(ids) != 2 || ids[0].Name != "ByService" || ids[1].Name != "Latency" {
+		t.Fatalf("expected ByService and Latency, got %+v", ids)
+	}
+	for _, id := range ids {
+		if id.Type != "query" || id.Section != "queries" {
+			t.Errorf("unexpected type of %+v", id)
+		}
+		if !strings.HasPrefix(id.Hash, "sha256:"+id.ID) || len(id.ID) != 12 {
+			t.Errorf("ID %q is not a prefix of hash %q", id.ID, id.Hash)
+		}
+	}
+	if ids[0].Hash == ids[1].Hash {
+		t.Error("expected different queries to have different hashes")
+	}
+
+	report, err := GenerateBuildReport(nil, dir, Options{})
+	if err != nil {
+		t.Fatalf("GenerateBuildReport failed: %v", err)
+	}
+	for _, r := range report.Resources {
+		if r.Name == "Latency" && r.Hash != ids[1].Hash {
+			t.Errorf("expected the report hash %q to match %q", r.Hash, ids[1].Hash)
+		}
+	}
+
+	// Moving a declaration and reformatting the file keep the hashes
+	moved := strings.Replace(reportSource, "var Latency", "var Placeholder = 1\n\nvar Latency", 1)
+	other := t.TempDir()
+	if err := os.WriteFile(filepath.Join(other, "queries.go"), []byte(moved), 0644); err != nil {
+		t.Fatal(err)
+	}
+	again, err := GenerateResourceIDs(other, Options{})
+	if err != nil {
+		t.Fatalf("GenerateResourceIDs failed: %v", err)
+	}
+	if len(again) != 2 || again[0].Hash != ids[0].Hash || again[1].Hash != ids[1].Hash {
+		t.Errorf("expected unchanged hashes, got %+v and %+v", ids, again)
+	}
+}
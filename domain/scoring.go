@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/scoring"
+)
+
+// ScoreArtifacts scores the resources generated in actualDir against the
+// expected resources of a scenario in expectedDir, such as
+// examples/tasks_api_scenario/expected, by comparing their build outputs
+// (see internal/scoring). Both may be package patterns.
+func ScoreArtifacts(expectedDir, actualDir string) (*scoring.Result, error) {
+	expected, err := scoringOutput(expectedDir)
+	if err != nil {
+		return nil, fmt.Errorf("expected resources: %w", err)
+	}
+	actual, err := scoringOutput(actualDir)
+	if err != nil {
+		return nil, fmt.Errorf("generated resources: %w", err)
+	}
+	return scoring.Compare(expected, actual)
+}
+
+// scoringOutput returns the build output of the resources in path with the
+// dataset of each query, which its JSON leaves out, added. Inline queries
+// are part of the resource holding them and left out.
+func scoringOutput(path string) ([]byte, error) {
+	resources, _, err := discoverPath(path, NameFilter{})
+	if err != nil {
+		return nil, err
+	}
+	output, err := SerializeResources(resources, "", Options{})
+	if err != nil {
+		return nil, err
+	}
+
+	holders := make(map[string]bool)
+	for _, b := range resources.Boards {
+		holders[b.Package+"."+b.Name] = true
+	}
+	for _, s := range resources.SLOs {
+		holders[s.Package+"."+s.Name] = true
+	}
+	for _, t := range resources.Triggers {
+		holders[t.Package+"."+t.Name] = true
+	}
+	for _, q := range resources.Queries {
+		raw, ok := output.Queries[q.Name]
+		if !ok {
+			continue
+		}
+		if holders[q.Package+"."+q.Name] {
+			delete(output.Queries, q.Name)
+			continue
+		}
+		var fields map[string]any
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			return nil, err
+		}
+		fields["dataset"] = q.Dataset
+		if output.Queries[q.Name], err = json.Marshal(fields); err != nil {
+			return nil, err
+		}
+	}
+	return output.Marshal()
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const scenarioExpected = "../examples/tasks_api_scenario/expected/..."
+
+func TestScoreArtifacts_Expected(t *testing.T) {
+	result, err := ScoreArtifacts(scenarioExpected, scenarioExpected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.MaxPoints == 0 || result.Points != result.MaxPoints || len(result.Extra) != 0 {
+		t.Errorf("expected full points, got:\n%s", result)
+	}
+}
+
+func TestScoreArtifacts_Generated(t *testing.T) {
+	dir := t.TempDir()
+	generated := `package triggers
+
+import "github.com/lex00/wetwire-honeycomb-go/trigger"
+
+var HighLatency = trigger.Trigger{
+	Name:      "High Latency",
+	Dataset:   "tasks-api",
+	Threshold: trigger.GreaterThan(500),
+	Frequency: trigger.Minutes(15),
+	AlertType: trigger.OnChange,
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "triggers.go"), []byte(generated), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ScoreArtifacts(scenarioExpected, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, rs := range result.Resources {
+		if rs.Type != "trigger" || rs.Expected != "HighLatency" {
+			continue
+		}
+		found = true
+		for _, c := range rs.Checks {
+			if c.Match == (c.Name == "threshold") {
+				t.Errorf("check %s: unexpected match %v: %v", c.Name, c.Match, c.Changes)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected a score for HighLatency, got:\n%s", result)
+	}
+	if !strings.Contains(result.String(), "query RequestLatency: missing") {
+		t.Errorf("expected missing queries in the report:\n%s", result)
+	}
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"sort"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/lint"
+)
+
+// LintReport is the data of a lint result.
+type LintReport struct {
+	// Security consolidates the secret and sensitive data findings
+	Security *SecurityReport `json:"security,omitempty"`
+
+	// Timings is the time spent in each rule, slowest first, with EnvDebug or
+	// EnvProfile
+	Timings []lint.RuleTiming `json:"timings,omitempty"`
+}
+
+// SecurityReport consolidates the findings of the security rules (secrets in
+// filters, datasets, descriptions and recipient targets, and PII columns)
+// across all resource types.
+type SecurityReport struct {
+	Findings int            `json:"findings"`
+	ByRule   map[string]int `json:"by_rule"`
+	Issues   []Error        `json:"issues"`
+}
+
+// newSecurityReport returns the security report for lint results, or nil if
+// no security rule reported anything.
+func newSecurityReport(results []lint.Issue) *SecurityReport {
+	security := lint.FilterSecurity(results)
+	if len(security) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(security, func(i, j int) bool {
+		if security[i].File != security[j].File {
+			return security[i].File < security[j].File
+		}
+		return security[i].Line < security[j].Line
+	})
+
+	report := &SecurityReport{
+		Findings: len(security),
+		ByRule:   lint.CountByRule(security),
+		Issues:   make([]Error, 0, len(security)),
+	}
+	for _, r := range security {
+		report.Issues = append(report.Issues, Error{
+			Path:     r.File,
+			Line:     r.Line,
+			Severity: r.Severity.String(),
+			Message:  r.Message,
+			Code:     r.Rule,
+		})
+	}
+	return report
+}
@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+// serviceMapEdge are the breakdowns of a dependency edge: the calling service
+// and the service it calls, as recorded on OpenTelemetry client spans.
+var serviceMapEdge = []string{"service.name", "peer.service"}
+
+// serviceMapQueries returns the span-relationship queries of a service map
+// over dataset.
+func serviceMapQueries(dataset string) []importedQuery {
+	byCount := []query.Order{{Op: "COUNT", Order: "descending"}}
+	return []importedQuery{
+		{
+			Name: "ServiceMapCalls",
+			Doc:  "ServiceMapCalls counts calls along each dependency edge, from\nservice.name to peer.service.",
+			Query: query.Query{
+				Dataset:      dataset,
+				TimeRange:    query.Hours(2),
+				Breakdowns:   serviceMapEdge,
+				Calculations: []query.Calculation{query.Count()},
+				Filters:      []query.Filter{query.Exists("peer.service")},
+				Orders:       byCount,
+				Limit:        100,
+			},
+		},
+		{
+			Name: "ServiceMapErrors",
+			Doc:  "ServiceMapErrors counts failed calls along each dependency edge.\nCompare with ServiceMapCalls for the error rate of an edge.",
+			Query: query.Query{
+				Dataset:      dataset,
+				TimeRange:    query.Hours(2),
+				Breakdowns:   serviceMapEdge,
+				Calculations: []query.Calculation{query.Count()},
+				Filters:      []query.Filter{query.Exists("peer.service"), query.Equals("error", true)},
+				Orders:       byCount,
+				Limit:        100,
+			},
+		},
+		{
+			Name: "ServiceMapLatency",
+			Doc:  "ServiceMapLatency shows the latency of each dependency edge, slowest\nfirst.",
+			Query: query.Query{
+				Dataset:    dataset,
+				TimeRange:  query.Hours(2),
+				Breakdowns: serviceMapEdge,
+				Calculations: []query.Calculation{
+					query.P50("duration_ms"),
+					query.P95("duration_ms"),
+					query.P99("duration_ms"),
+					query.Heatmap("duration_ms"),
+				},
+				Filters: []query.Filter{query.Exists("peer.service")},
+				Orders:  []query.Order{{Op: "P99", Column: "duration_ms", Order: "descending"}},
+				Limit:   100,
+			},
+		},
+		{
+			Name: "ServiceMapInbound",
+			Doc:  "ServiceMapInbound shows the requests and messages each service\nreceives.",
+			Query: query.Query{
+				Dataset:    dataset,
+				TimeRange:  query.Hours(2),
+				Breakdowns: []string{"service.name"},
+				Calculations: []query.Calculation{
+					query.Count(),
+					query.P99("duration_ms"),
+					query.Heatmap("duration_ms"),
+				},
+				Filters: []query.Filter{query.In("span.kind", []any{"server", "consumer"})},
+				Orders:  byCount,
+				Limit:   50,
+			},
+		},
+		{
+			Name: "ServiceMapUnknownPeers",
+			Doc:  "ServiceMapUnknownPeers finds outgoing calls without peer.service,\nwhich the service map cannot place. Set peer.service on these spans.",
+			Query: query.Query{
+				Dataset:      dataset,
+				TimeRange:    query.Hours(2),
+				Breakdowns:   []string{"service.name", "server.address"},
+				Calculations: []query.Calculation{query.Count()},
+				Filters:      []query.Filter{query.Equals("span.kind", "client"), query.DoesNotExist("peer.service")},
+				Orders:       byCount,
+				Limit:        50,
+			},
+		},
+	}
+}
+
+// serviceMapPanels are the titles of the service map board's panels, by query.
+var serviceMapPanels = map[string]string{
+	"ServiceMapCalls":        "Calls per edge",
+	"ServiceMapErrors":       "Errors per edge",
+	"ServiceMapLatency":      "Latency per edge",
+	"ServiceMapInbound":      "Inbound traffic per service",
+	"ServiceMapUnknownPeers": "Calls without peer.service",
+}
+
+// GenerateServiceMap returns a gofmt-formatted Go file in package pkg that
+// declares the standard service map queries over an OpenTelemetry trace
+// dataset: calls, errors and latency per dependency edge (service.name to
+// peer.service), inbound traffic per service and calls with no known peer,
+// plus a ServiceMapBoard showing them.
+func GenerateServiceMap(pkg, dataset string) ([]byte, error) {
+	if dataset == "" {
+		return nil, fmt.Errorf("dataset is required")
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Package %s maps the dependencies between services in the %s\n", pkg, dataset)
+	b.WriteString("// dataset from OpenTelemetry spans.\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import (\n\"github.com/lex00/wetwire-honeycomb-go/board\"\n\"github.com/lex00/wetwire-honeycomb-go/query\"\n)\n")
+
+	queries := serviceMapQueries(dataset)
+	for _, iq := range queries {
+		writeQuery(&b, iq)
+	}
+
+	b.WriteString("\n// ServiceMapBoard is a starting dashboard for the service map.\n")
+	b.WriteString("var ServiceMapBoard = board.Board{\n")
+	b.WriteString("Name: \"Service Map\",\n")
+	fmt.Fprintf(&b, "Description: %q,\n", "Calls, errors and latency between services in "+dataset)
+	b.WriteString("Panels: []board.Panel{\n")
+	for _, iq := range queries {
+		fmt.Fprintf(&b, "board.QueryPanel(%s, board.WithTitle(%q)),\n", iq.Name, serviceMapPanels[iq.Name])
+	}
+	b.WriteString("},\n}\n")
+
+	code, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return code, nil
+}
+
+// WriteServiceMap generates the service map for dataset into target, a .go
+// file or a directory the file servicemap.go is created in, and returns the
+// path of the file. The package is named after the target directory. An
+// existing file is never overwritten.
+func WriteServiceMap(dataset, target string) (string, error) {
+	return writeGenerated(target, "servicemap.go", func(pkg string) ([]byte, error) {
+		return GenerateServiceMap(pkg, dataset)
+	})
+}
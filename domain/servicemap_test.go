@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"context"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+func TestGenerateServiceMap(t *testing.T) {
+	code, err := GenerateServiceMap("servicemap", "otel-demo")
+	if err != nil {
+		t.Fatalf("GenerateServiceMap failed: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "servicemap.go", code, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, code)
+	}
+	for _, want := range []string{
+		"package servicemap",
+		`Breakdowns: []string{"service.name", "peer.service"},`,
+		`query.Equals("error", true),`,
+		`query.DoesNotExist("peer.service"),`,
+		"board.QueryPanel(ServiceMapErrors, board.WithTitle(\"Errors per edge\")),",
+	} {
+		if !strings.Contains(string(code), want) {
+			t.Errorf("generated code missing %q:\n%s", want, code)
+		}
+	}
+
+	if _, err := GenerateServiceMap("servicemap", ""); err == nil {
+		t.Error("expected an error without a dataset")
+	}
+}
+
+func TestWriteServiceMap(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "deps")
+	file, err := WriteServiceMap("otel-demo", dir)
+	if err != nil {
+		t.Fatalf("WriteServiceMap failed: %v", err)
+	}
+	if file != filepath.Join(dir, "servicemap.go") {
+		t.Errorf("unexpected file %s", file)
+	}
+
+	resources, err := discovery.DiscoverAll(dir)
+	if err != nil {
+		t.Fatalf("discover: %v", err)
+	}
+	if len(resources.Queries) != 5 || len(resources.Boards) != 1 {
+		t.Fatalf("expected 5 queries and 1 board, got %d and %d", len(resources.Queries), len(resources.Boards))
+	}
+	for _, q := range resources.Queries {
+		if q.Package != "deps" || q.Dataset != "otel-demo" {
+			t.Errorf("%s: package %q, dataset %q", q.Name, q.Package, q.Dataset)
+		}
+	}
+
+	// The generated queries follow the lint rules. WHC004 is skipped as it
+	// reports every query with breakdowns, ordered or not.
+	ctx := coredomain.NewContext(context.Background(), dir)
+	result, err := (&honeycombLinter{opts: &Options{}}).Lint(ctx, dir, LintOpts{Disable: []string{"WHC004"}})
+	if err != nil {
+		t.Fatalf("Lint failed: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("expected no lint issues, got %+v", result.Errors)
+	}
+
+	// Existing files are not overwritten
+	if _, err := WriteServiceMap("otel-demo", dir); err == nil {
+		t.Error("expected an error when the file exists")
+	}
+}
@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// StdinPath is the file argument that reads from standard input, so commands
+// can be chained in shell pipelines:
+//
+//	wetwire-honeycomb build ./queries | wetwire-honeycomb validate -
+const StdinPath = "-"
+
+// Stdin is read when a command is given StdinPath. Tests may replace it.
+var Stdin io.Reader = os.Stdin
+
+// readSource reads the file at path, or standard input for StdinPath.
+func readSource(path string) ([]byte, error) {
+	if path == StdinPath {
+		data, err := io.ReadAll(Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("read stdin: %w", err)
+		}
+		return data, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+	return data, nil
+}
+
+// sourceName names path in messages.
+func sourceName(path string) string {
+	if path == StdinPath {
+		return "<stdin>"
+	}
+	return path
+}
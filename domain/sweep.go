@@ -0,0 +1,267 @@
+package domain
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+// SweepParam is a parameter of a query sweep and the values to try.
+// Supported names are:
+//
+//	threshold    the value of the query's only >, >=, < or <= filter
+//	limit        the result limit
+//	hours        the relative time range, in hours
+//	granularity  the time bucket size, in seconds
+//
+// Any other name is a column the query filters on; the value of every filter
+// on it is replaced.
+type SweepParam struct {
+	Name   string
+	Values []string
+}
+
+// ParseSweepParam parses a sweep parameter written as "name=v1,v2,...".
+func ParseSweepParam(s string) (SweepParam, error) {
+	name, values, ok := strings.Cut(s, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || strings.TrimSpace(values) == "" {
+		return SweepParam{}, fmt.Errorf("parameter %q: expected \"name=value,value,...\"", s)
+	}
+
+	p := SweepParam{Name: name}
+	for _, v := range strings.Split(values, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return SweepParam{}, fmt.Errorf("parameter %q: empty value", s)
+		}
+		p.Values = append(p.Values, v)
+	}
+	return p, nil
+}
+
+// sweepVariant is one query of a sweep: the source query with one value of
+// each parameter.
+type sweepVariant struct {
+	name     string
+	settings []string
+	query    query.Query
+}
+
+// sweepVariants returns a query for each combination of parameter values,
+// the first parameter varying slowest, named after name and the values.
+func sweepVariants(name string, q query.Query, params []SweepParam) ([]sweepVariant, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("at least one parameter is required")
+	}
+
+	variants := []sweepVariant{{name: name, query: q}}
+	for _, p := range params {
+		var next []sweepVariant
+		for _, v := range variants {
+			for _, value := range p.Values {
+				swept, err := setSweepParam(cloneQuery(v.query), p.Name, value)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", name, err)
+				}
+				next = append(next, sweepVariant{
+					name:     v.name + sweepNamePart(p.Name) + sweepNamePart(value),
+					settings: append(slices.Clone(v.settings), p.Name+"="+value),
+					query:    swept,
+				})
+			}
+		}
+		variants = next
+	}
+
+	seen := make(map[string]bool)
+	for _, v := range variants {
+		if seen[v.name] {
+			return nil, fmt.Errorf("parameter values give two queries the name %s", v.name)
+		}
+		seen[v.name] = true
+	}
+	return variants, nil
+}
+
+// setSweepParam returns q with the parameter name set to value.
+func setSweepParam(q query.Query, name, value string) (query.Query, error) {
+	switch name {
+	case "limit", "hours", "granularity":
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return q, fmt.Errorf("%s must be a positive integer, got %q", name, value)
+		}
+		switch name {
+		case "limit":
+			q.Limit = n
+		case "hours":
+			q.TimeRange = query.Hours(n)
+		case "granularity":
+			q.Granularity = n
+		}
+		return q, nil
+
+	case "threshold":
+		var matched []int
+		for i, f := range q.Filters {
+			switch f.Op {
+			case ">", ">=", "<", "<=":
+				matched = append(matched, i)
+			}
+		}
+		if len(matched) != 1 {
+			return q, fmt.Errorf("threshold needs exactly one >, >=, < or <= filter, found %d; name the filtered column instead", len(matched))
+		}
+		return setFilterValue(q, matched, value)
+
+	default:
+		var matched []int
+		for i, f := range q.Filters {
+			if f.Column == name {
+				matched = append(matched, i)
+			}
+		}
+		if len(matched) == 0 {
+			return q, fmt.Errorf("no filter on column %q; parameters are threshold, limit, hours, granularity or a filtered column", name)
+		}
+		return setFilterValue(q, matched, value)
+	}
+}
+
+// setFilterValue sets the value of the filters at indexes to value, typed as
+// on the command line.
+func setFilterValue(q query.Query, indexes []int, value string) (query.Query, error) {
+	for _, i := range indexes {
+		f := q.Filters[i]
+		if f.Op == "in" || f.Op == "not-in" {
+			f.Value = []any{parseFilterValue(value)}
+		} else {
+			f.Value = parseFilterValue(value)
+		}
+		if err := f.Validate(); err != nil {
+			return q, err
+		}
+		q.Filters[i] = f
+	}
+	return q, nil
+}
+
+// cloneQuery copies the slices of q, so variants can be changed
+// independently.
+func cloneQuery(q query.Query) query.Query {
+	q.Calculations = slices.Clone(q.Calculations)
+	q.Filters = slices.Clone(q.Filters)
+	q.Breakdowns = slices.Clone(q.Breakdowns)
+	q.Orders = slices.Clone(q.Orders)
+	return q
+}
+
+// sweepNamePart converts a parameter name or value to part of a Go name:
+// words are capitalized, a decimal point becomes "p" and a minus sign
+// "Minus", so 0.5 gives 0p5 and http.status_code gives HttpStatusCode.
+func sweepNamePart(s string) string {
+	var b strings.Builder
+	upper := true
+	for i, r := range s {
+		switch {
+		case r == '.' && i > 0 && unicode.IsDigit(rune(s[i-1])):
+			b.WriteRune('p')
+		case r == '-' && i == 0:
+			b.WriteString("Minus")
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if upper {
+				r = unicode.ToUpper(r)
+				upper = false
+			}
+			b.WriteRune(r)
+			continue
+		}
+		upper = true
+	}
+	return b.String()
+}
+
+// GenerateSweep returns a gofmt-formatted Go file in package pkg declaring a
+// copy of the query q, named name, for each combination of the parameter
+// values, e.g. SlowRequestsThreshold250 for threshold=250. With board, it
+// also declares <name>SweepBoard with a panel for each copy, to compare them.
+func GenerateSweep(pkg, name string, q query.Query, params []SweepParam, board bool) ([]byte, error) {
+	if !token.IsIdentifier(name) || !token.IsExported(name) {
+		return nil, fmt.Errorf("%q is not an exported Go identifier", name)
+	}
+	variants, err := sweepVariants(name, q, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	if board {
+		b.WriteString("import (\n\"github.com/lex00/wetwire-honeycomb-go/board\"\n\"github.com/lex00/wetwire-honeycomb-go/query\"\n)\n")
+	} else {
+		b.WriteString("import \"github.com/lex00/wetwire-honeycomb-go/query\"\n")
+	}
+
+	for _, v := range variants {
+		writeQuery(&b, importedQuery{
+			Name:  v.name,
+			Doc:   fmt.Sprintf("%s is %s with %s.", v.name, name, strings.Join(v.settings, ", ")),
+			Query: v.query,
+		})
+	}
+
+	if board {
+		var names []string
+		for _, p := range params {
+			names = append(names, p.Name)
+		}
+		fmt.Fprintf(&b, "\n// %sSweepBoard compares the %s sweep side by side.\n", name, name)
+		fmt.Fprintf(&b, "var %sSweepBoard = board.Board{\n", name)
+		fmt.Fprintf(&b, "Name: %q,\n", name+" sweep")
+		fmt.Fprintf(&b, "Description: %q,\n", fmt.Sprintf("%s for each value of %s", name, strings.Join(names, " and ")))
+		b.WriteString("Panels: []board.Panel{\n")
+		for _, v := range variants {
+			fmt.Fprintf(&b, "board.QueryPanel(%s, board.WithTitle(%q)),\n", v.name, strings.Join(v.settings, " "))
+		}
+		b.WriteString("},\n}\n")
+	}
+
+	code, err := format.Source(b.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return code, nil
+}
+
+// WriteSweep generates the sweep of query q named name into target, a .go
+// file or a directory the file <name>_sweep.go is created in, and returns
+// the path of the file. An existing file is never overwritten.
+func WriteSweep(name string, q query.Query, params []SweepParam, board bool, target string) (string, error) {
+	return writeGenerated(target, aliasName(name)+"_sweep.go", func(pkg string) ([]byte, error) {
+		return GenerateSweep(pkg, name, q, params, board)
+	})
+}
+
+// DiscoveredQuery returns the query declared as name in the packages
+// selected by path, as a query.Query, and the name of the package declaring
+// it.
+func DiscoveredQuery(path, name string) (query.Query, string, error) {
+	resources, _, err := discoverPath(path, NameFilter{})
+	if err != nil {
+		return query.Query{}, "", err
+	}
+	for _, dq := range resources.Queries {
+		if dq.Name == name {
+			return dq.Query(), dq.Package, nil
+		}
+	}
+	return query.Query{}, "", fmt.Errorf("query %s not found in %s", name, path)
+}
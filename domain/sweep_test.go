@@ -0,0 +1,152 @@
+package domain
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+var sweepQuery = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Calculations: []query.Calculation{query.P99("duration_ms")},
+	Filters:      []query.Filter{query.GT("duration_ms", 100), query.Equals("service.name", "checkout")},
+}
+
+func TestParseSweepParam(t *testing.T) {
+	got, err := ParseSweepParam("threshold=100, 250,500")
+	if err != nil {
+		t.Fatalf("ParseSweepParam failed: %v", err)
+	}
+	want := SweepParam{Name: "threshold", Values: []string{"100", "250", "500"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseSweepParam = %#v, want %#v", got, want)
+	}
+
+	for _, input := range []string{"threshold", "=1,2", "threshold=", "threshold=1,,2"} {
+		if _, err := ParseSweepParam(input); err == nil {
+			t.Errorf("ParseSweepParam(%q): expected an error", input)
+		}
+	}
+}
+
+func TestSweepVariants(t *testing.T) {
+	params := []SweepParam{
+		{Name: "threshold", Values: []string{"250", "0.5"}},
+		{Name: "service.name", Values: []string{"cart"}},
+		{Name: "hours", Values: []string{"24"}},
+	}
+	variants, err := sweepVariants("Slow", sweepQuery, params)
+	if err != nil {
+		t.Fatalf("sweepVariants failed: %v", err)
+	}
+	if len(variants) != 2 {
+		t.Fatalf("expected 2 variants, got %d", len(variants))
+	}
+
+	v := variants[1]
+	if v.name != "SlowThreshold0p5ServiceNameCartHours24" {
+		t.Errorf("unexpected name %s", v.name)
+	}
+	if v.query.Filters[0].Value != 0.5 || v.query.Filters[1].Value != "cart" {
+		t.Errorf("unexpected filters %#v", v.query.Filters)
+	}
+	if v.query.TimeRange != query.Hours(24) {
+		t.Errorf("unexpected time range %#v", v.query.TimeRange)
+	}
+	if sweepQuery.Filters[0].Value != 100 {
+		t.Error("sweeping changed the source query")
+	}
+}
+
+func TestSweepVariants_Errors(t *testing.T) {
+	tests := []struct {
+		params []SweepParam
+		want   string
+	}{
+		{nil, "at least one parameter"},
+		{[]SweepParam{{Name: "limit", Values: []string{"ten"}}}, "positive integer"},
+		{[]SweepParam{{Name: "region", Values: []string{"eu"}}}, `no filter on column "region"`},
+		{[]SweepParam{{Name: "limit", Values: []string{"10", "10"}}}, "two queries the name"},
+	}
+	for _, tt := range tests {
+		if _, err := sweepVariants("Slow", sweepQuery, tt.params); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("sweepVariants(%v): expected error containing %q, got %v", tt.params, tt.want, err)
+		}
+	}
+
+	noThreshold := sweepQuery
+	noThreshold.Filters = []query.Filter{query.Equals("error", true)}
+	if _, err := sweepVariants("Errors", noThreshold, []SweepParam{{Name: "threshold", Values: []string{"1"}}}); err == nil {
+		t.Error("expected a threshold error for a query without a comparison filter")
+	}
+}
+
+func TestGenerateSweep(t *testing.T) {
+	params := []SweepParam{{Name: "threshold", Values: []string{"100", "250"}}}
+	code, err := GenerateSweep("queries", "SlowRequests", sweepQuery, params, true)
+	if err != nil {
+		t.Fatalf("GenerateSweep failed: %v", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "sweep.go", code, 0); err != nil {
+		t.Fatalf("generated code does not parse: %v\n%s", err, code)
+	}
+	for _, want := range []string{
+		"// SlowRequestsThreshold250 is SlowRequests with threshold=250.",
+		`query.GT("duration_ms", 250)`,
+		`board.QueryPanel(SlowRequestsThreshold100, board.WithTitle("threshold=100")),`,
+	} {
+		if !strings.Contains(string(code), want) {
+			t.Errorf("generated code missing %q:\n%s", want, code)
+		}
+	}
+
+	if _, err := GenerateSweep("queries", "slow", sweepQuery, params, false); err == nil {
+		t.Error("expected an error for an unexported name")
+	}
+}
+
+func TestWriteSweep(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "experiments")
+	params := []SweepParam{{Name: "limit", Values: []string{"10", "100"}}}
+	file, err := WriteSweep("SlowRequests", sweepQuery, params, false, dir)
+	if err != nil {
+		t.Fatalf("WriteSweep failed: %v", err)
+	}
+	if filepath.Base(file) != "slow_requests_sweep.go" {
+		t.Errorf("unexpected file %s", file)
+	}
+	if _, err := WriteSweep("SlowRequests", sweepQuery, params, false, dir); err == nil {
+		t.Error("expected an error when the file exists")
+	}
+}
+
+func TestPackageName(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "slo-experiments")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if got := packageName(dir); got != "sloexperiments" {
+		t.Errorf("empty directory: got %q, want sloexperiments", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "alerts_test.go"), []byte("package alerts_test\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := packageName(dir); got != "sloexperiments" {
+		t.Errorf("tests only: got %q, want sloexperiments", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "alerts.go"), []byte("package alerts\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got := packageName(dir); got != "alerts" {
+		t.Errorf("Go files: got %q, want alerts", got)
+	}
+}
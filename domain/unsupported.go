@@ -0,0 +1,39 @@
+package domain
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// unsupportedWarnings returns a warning for each resource literal discovery
+// cannot extract (see discovery.UnsupportedDeclaration), so that a resource
+// built in an init function or a loop is not silently missing from the
+// build output. Lint reports them as WHC066 errors.
+func unsupportedWarnings(resources *discovery.DiscoveredResources) []Error {
+	var warnings []Error
+	for _, u := range resources.Unsupported {
+		warnings = append(warnings, Error{
+			Path:     u.File,
+			Line:     u.Line,
+			Severity: "warning",
+			Message:  u.String(),
+		})
+	}
+	return warnings
+}
+
+// fieldMistakeErrors returns an error for each field of a resource literal
+// discovery cannot read (see discovery.FieldMistake), such as Breakdown for
+// Breakdowns or a string TimeRange, with the field or value likely meant.
+// Lint reports them as WHC069 errors.
+func fieldMistakeErrors(resources *discovery.DiscoveredResources) []Error {
+	var errs []Error
+	for _, m := range resources.FieldMistakes {
+		errs = append(errs, Error{
+			Path:     m.File,
+			Line:     m.Line,
+			Severity: "error",
+			Message:  m.String(),
+		})
+	}
+	return errs
+}
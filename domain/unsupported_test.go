@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+)
+
+func TestBuild_UnsupportedDeclarations(t *testing.T) {
+	dir := t.TempDir()
+	content := `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Latency = query.Query{Dataset: "api", TimeRange: query.Hours(1)}
+
+var Errors query.Query
+
+func init() {
+	Errors = query.Query{Dataset: "api", TimeRange: query.Hours(1)}
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := (&HoneycombDomain{}).Builder().Build(&coredomain.Context{}, dir, BuildOpts{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Success {
+		t.Errorf("expected success, got %+v", result)
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("expected 1 warning, got %v", result.Errors)
+	}
+	e := result.Errors[0]
+	if e.Severity != "warning" || e.Line != 10 || !strings.Contains(e.Message, "query Errors in init() is not discovered") {
+		t.Errorf("unexpected warning %+v", e)
+	}
+}
+
+func TestBuild_FieldMistakes(t *testing.T) {
+	dir := t.TempDir()
+	content := `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Latency = query.Query{
+	Dataset:   "api",
+	TimeRange: query.Hours(1),
+	Breakdown: []string{"endpoint"},
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "queries.go"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := (&HoneycombDomain{}).Builder().Build(&coredomain.Context{}, dir, BuildOpts{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Success {
+		t.Errorf("expected the build to fail, got %+v", result)
+	}
+	if len(result.Errors) != 1 {
+		t.Fatalf("expected 1 error, got %v", result.Errors)
+	}
+	e := result.Errors[0]
+	if e.Severity != "error" || e.Line != 8 || e.Message != "Latency: query.Query has no field Breakdown; did you mean Breakdowns?" {
+		t.Errorf("unexpected error %+v", e)
+	}
+}
@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/i18n"
+	"github.com/lex00/wetwire-honeycomb-go/internal/refs"
+	"github.com/lex00/wetwire-honeycomb-go/internal/serialize"
+)
+
+// isJSONSource reports whether validate should check path as generated JSON
+// rather than lint Go source.
+func isJSONSource(path string) bool {
+	return path == StdinPath || filepath.Ext(path) == ".json"
+}
+
+// validateJSON checks generated JSON: a single Query JSON object, a build
+// output, or a JSON-formatted command result wrapping either. Every query,
+// including those embedded in board panels and triggers, is checked with
+// query.Query.Validate.
+func validateJSON(path string) (*Result, error) {
+	data, err := readSource(path)
+	if err != nil {
+		return nil, err
+	}
+	name := sourceName(path)
+
+	if inner, ok := unwrapResult(data); ok {
+		data = inner
+	}
+
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return NewErrorResult(i18n.T("invalid JSON"), Error{
+			Path:     name,
+			Severity: "error",
+			Message:  err.Error(),
+		}), nil
+	}
+
+	// Queries to validate, keyed by where they were found
+	queries := make(map[string]json.RawMessage)
+	var errs []Error
+	if isBuildOutput(top) {
+		errs = collectQueries(top, queries)
+	} else {
+		queries["query"] = data
+	}
+
+	keys := make([]string, 0, len(queries))
+	for key := range queries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		q, err := serialize.FromJSON(queries[key])
+		if err == nil {
+			err = q.Validate()
+		}
+		if err == nil {
+			continue
+		}
+
+		// Query.Validate joins every problem found; report each one
+		problems := []error{err}
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			problems = joined.Unwrap()
+		}
+		for _, p := range problems {
+			errs = append(errs, Error{
+				Severity: "error",
+				Message:  fmt.Sprintf("%s: %v", key, p),
+			})
+		}
+	}
+
+	for i := range errs {
+		errs[i].Path = name
+	}
+	if len(errs) > 0 {
+		return NewErrorResultMultiple(i18n.Sprintf("%d problem(s) in %s", len(errs), name), errs), nil
+	}
+	return NewResult(i18n.Sprintf("Validated %d queries in %s", len(queries), name)), nil
+}
+
+// collectQueries gathers the queries of a build output: top-level queries,
+// board query panels (including those in sections) and trigger queries. Resources that are not JSON objects
+// are reported as errors.
+func collectQueries(top map[string]json.RawMessage, queries map[string]json.RawMessage) []Error {
+	var errs []Error
+	for typ, raw := range top {
+		if typ == refs.Section || typ == ErrorsSection {
+			continue
+		}
+		var resources map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &resources); err != nil {
+			errs = append(errs, Error{Severity: "error", Message: fmt.Sprintf("%s: %v", typ, err)})
+			continue
+		}
+
+		for resName, data := range resources {
+			key := typ + "." + resName
+			var resource struct {
+				Query  json.RawMessage `json:"query"`
+				Panels []boardPanel    `json:"panels"`
+			}
+			if err := json.Unmarshal(data, &resource); err != nil {
+				errs = append(errs, Error{Severity: "error", Message: fmt.Sprintf("%s: %v", key, err)})
+				continue
+			}
+
+			switch typ {
+			case "queries":
+				queries[key] = data
+			case "boards":
+				collectPanelQueries(key, resource.Panels, queries)
+			case "triggers":
+				if resource.Query != nil {
+					queries[key+".query"] = resource.Query
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// boardPanel is a board panel in build output. Sections hold panels.
+type boardPanel struct {
+	Type   string          `json:"type"`
+	Query  json.RawMessage `json:"query"`
+	Panels []boardPanel    `json:"panels"`
+}
+
+// collectPanelQueries gathers the queries of board panels, keyed by their
+// path below prefix, such as "boards.Overview.panels[1].panels[0]".
+func collectPanelQueries(prefix string, panels []boardPanel, queries map[string]json.RawMessage) {
+	for i, p := range panels {
+		key := fmt.Sprintf("%s.panels[%d]", prefix, i)
+		switch {
+		case p.Type == "query" && p.Query != nil:
+			queries[key] = p.Query
+		case p.Type == "section":
+			collectPanelQueries(key, p.Panels, queries)
+		}
+	}
+}
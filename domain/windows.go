@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/slo"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+// window is the range of relative time ranges, in seconds, that fit every
+// trigger and SLO using a query.
+type window struct {
+	shortest, longest int
+
+	// users names the triggers and SLOs
+	users []string
+}
+
+// NormalizeWindows fits the time range of each query a trigger or SLO uses,
+// inline or by reference, to all of them, as lint rules WHC055, WHC067 and
+// WHC068 check: a trigger query gets a relative range between the trigger's
+// frequency and the longest duration it can evaluate (see
+// trigger.QueryWindow), and an SLI query a relative range of at most the time
+// period of its SLO (see slo.TimePeriod.QueryRange), the same as the other
+// SLI query, the longer of the two. Absolute ranges become relative ones of the
+// same length, fitted the same way. A query whose users need windows that do
+// not overlap is left unchanged. It returns a warning for each query changed
+// or left unchanged that way, naming its users.
+func NormalizeWindows(resources *discovery.DiscoveredResources) []Error {
+	var order []*discovery.DiscoveredQuery
+	windows := make(map[*discovery.DiscoveredQuery]*window)
+	add := func(q *discovery.DiscoveredQuery, shortest, longest int, user string) {
+		if q == nil {
+			return
+		}
+		w, ok := windows[q]
+		if !ok {
+			w = &window{shortest: shortest, longest: longest}
+			windows[q] = w
+			order = append(order, q)
+		}
+		w.shortest, w.longest = max(w.shortest, shortest), min(w.longest, longest)
+		w.users = append(w.users, user)
+	}
+
+	for i := range resources.Triggers {
+		t := &resources.Triggers[i]
+		shortest, longest := trigger.QueryWindow(t.FrequencySeconds)
+		add(t.Query(resources.Queries), shortest, longest, "trigger "+t.Name)
+	}
+	for i := range resources.SLOs {
+		s := &resources.SLOs[i]
+		good, total := s.SLIQueries(resources.Queries)
+		longest := query.MaxTimeRange
+		if s.TimePeriodDays > 0 {
+			longest = slo.TimePeriod{Days: s.TimePeriodDays}.QueryRange()
+		}
+		shortest := 0
+		if good != nil && total != nil && good != total && windowLength(good.TimeRange) > 0 && windowLength(total.TimeRange) > 0 {
+			// good and total events get the same range, the longer of the two
+			shortest = min(max(windowLength(good.TimeRange), windowLength(total.TimeRange)), longest)
+			longest = shortest
+		}
+		add(good, shortest, longest, "SLO "+s.Name)
+		if total != good {
+			add(total, shortest, longest, "SLO "+s.Name)
+		}
+	}
+
+	var warnings []Error
+	for _, q := range order {
+		w := windows[q]
+		tr := q.TimeRange
+		if tr.Err != "" || tr == (discovery.TimeRange{}) {
+			continue
+		}
+		users := strings.Join(w.users, ", ")
+		if w.shortest > w.longest {
+			warnings = append(warnings, Error{
+				Path:     q.File,
+				Line:     q.Line,
+				Severity: "warning",
+				Message:  fmt.Sprintf("query %s: time range left unchanged, %s need windows that do not overlap", queryLabel(q), users),
+			})
+			continue
+		}
+
+		fitted := min(max(windowLength(tr), w.shortest), w.longest)
+		if fitted == tr.TimeRange && tr.StartTime == 0 && tr.EndTime == 0 {
+			continue
+		}
+		q.TimeRange = discovery.TimeRange{TimeRange: fitted}
+		warnings = append(warnings, Error{
+			Path:     q.File,
+			Line:     q.Line,
+			Severity: "warning",
+			Message:  fmt.Sprintf("query %s: time range %s set to %s to fit %s", queryLabel(q), describeTimeRange(tr), formatWindow(fitted), users),
+		})
+	}
+	return warnings
+}
+
+// windowLength returns the length of a time range in seconds, whether
+// relative or absolute, or 0 for an invalid one.
+func windowLength(tr discovery.TimeRange) int {
+	if tr.Err != "" {
+		return 0
+	}
+	if tr.StartTime > 0 || tr.EndTime > 0 {
+		return tr.EndTime - tr.StartTime
+	}
+	return tr.TimeRange
+}
+
+// queryLabel names q in warnings: its name, or "(inline)" for an inline
+// query without one.
+func queryLabel(q *discovery.DiscoveredQuery) string {
+	if q.Name == "" {
+		return "(inline)"
+	}
+	return q.Name
+}
+
+// describeTimeRange describes a time range: its length, or "absolute".
+func describeTimeRange(tr discovery.TimeRange) string {
+	if tr.StartTime > 0 || tr.EndTime > 0 {
+		return "absolute"
+	}
+	return formatWindow(tr.TimeRange)
+}
+
+// formatWindow formats a duration in seconds in its largest whole unit, e.g.
+// "7d", "6h" or "15m".
+func formatWindow(seconds int) string {
+	for _, unit := range []struct {
+		size   int
+		suffix string
+	}{{86400, "d"}, {3600, "h"}, {60, "m"}} {
+		if seconds%unit.size == 0 {
+			return fmt.Sprintf("%d%s", seconds/unit.size, unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+func TestNormalizeWindows(t *testing.T) {
+	resources := &discovery.DiscoveredResources{
+		Queries: []discovery.DiscoveredQuery{
+			{Name: "Errors", TimeRange: discovery.TimeRange{TimeRange: 2 * 86400}},
+			{Name: "Fits", TimeRange: discovery.TimeRange{TimeRange: 900}},
+			{Name: "Good", TimeRange: discovery.TimeRange{TimeRange: 3600}},
+			{Name: "Total", TimeRange: discovery.TimeRange{StartTime: 1700000000, EndTime: 1700003600}},
+			{Name: "Shared", TimeRange: discovery.TimeRange{TimeRange: 3600}},
+			{Name: "Other", TimeRange: discovery.TimeRange{TimeRange: 2 * 86400}},
+		},
+		Triggers: []discovery.DiscoveredTrigger{
+			{Name: "HighErrors", QueryRef: "Errors", FrequencySeconds: 900},
+			{Name: "Fitting", QueryRef: "Fits", FrequencySeconds: 300},
+			{Name: "Gap", FrequencySeconds: 1800,
+				InlineQuery: &discovery.DiscoveredQuery{Name: "Gap", TimeRange: discovery.TimeRange{TimeRange: 600}}},
+			{Name: "OnShared", QueryRef: "Shared", FrequencySeconds: 900},
+		},
+		SLOs: []discovery.DiscoveredSLO{
+			{Name: "Availability", TimePeriodDays: 30, GoodEventsQueryRef: "Good", TotalEventsQueryRef: "Total"},
+			{Name: "SharedSLO", TimePeriodDays: 1, GoodEventsQueryRef: "Shared", TotalEventsQueryRef: "Other"},
+		},
+	}
+
+	warnings := NormalizeWindows(resources)
+
+	ranges := make(map[string]discovery.TimeRange)
+	for _, q := range resources.Queries {
+		ranges[q.Name] = q.TimeRange
+	}
+	for name, want := range map[string]int{"Errors": 3600, "Fits": 900, "Good": 3600, "Total": 3600, "Shared": 3600, "Other": 86400} {
+		if got := ranges[name]; got != (discovery.TimeRange{TimeRange: want}) {
+			t.Errorf("query %s: expected a relative range of %ds, got %+v", name, want, got)
+		}
+	}
+	if got := resources.Triggers[2].InlineQuery.TimeRange.TimeRange; got != 1800 {
+		t.Errorf("expected the inline query of Gap to cover its frequency, got %ds", got)
+	}
+
+	var messages []string
+	for _, w := range warnings {
+		messages = append(messages, w.Message)
+	}
+	want := []string{
+		"query Errors: time range 2d set to 1h to fit trigger HighErrors",
+		"query Gap: time range 10m set to 30m to fit trigger Gap",
+		"query Shared: time range left unchanged, trigger OnShared, SLO SharedSLO need windows that do not overlap",
+		"query Total: time range absolute set to 1h to fit SLO Availability",
+		"query Other: time range 2d set to 1d to fit SLO SharedSLO",
+	}
+	if strings.Join(messages, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected warnings:\n%s", strings.Join(messages, "\n"))
+	}
+}
+
+func TestBuilderBuild_NormalizeWindows(t *testing.T) {
+	builder := (&HoneycombDomain{Options: Options{NormalizeWindows: true}}).Builder()
+
+	tmpDir := t.TempDir()
+	content := `package obs
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var Errors = query.Query{Dataset: "api", TimeRange: query.Days(2), Calculations: []query.Calculation{query.Count()}}
+
+var HighErrors = trigger.Trigger{
+	Name:      "High Errors",
+	Query:     Errors,
+	Threshold: trigger.GreaterThan(10),
+	Frequency: trigger.Minutes(15),
+}
+`
+	if err := os.WriteFile(tmpDir+"/obs.go", []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	outFile := filepath.Join(t.TempDir(), "out.json")
+	result, err := builder.Build(&coredomain.Context{}, tmpDir, BuildOpts{Output: outFile})
+	if err != nil || !result.Success {
+		t.Fatalf("Build failed: %v %+v", err, result)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Severity != "warning" || result.Errors[0].Line != 8 {
+		t.Errorf("expected a warning for the adjusted query, got %+v", result.Errors)
+	}
+
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		t.Fatalf("Failed to read build output: %v", err)
+	}
+	var out struct {
+		Queries map[string]struct {
+			TimeRange int `json:"time_range"`
+		} `json:"queries"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Failed to parse build output: %v", err)
+	}
+	if got := out.Queries["Errors"].TimeRange; got != 3600 {
+		t.Errorf("expected the query to cover 4 times the frequency, 3600s, got %d", got)
+	}
+}
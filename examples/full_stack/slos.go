@@ -29,7 +29,7 @@ var APIAvailability = slo.SLO{
 			Window:     slo.TimePeriod{Hours: 1},
 			Recipients: []slo.Recipient{
 				{Type: "slack", Target: "#oncall"},
-				{Type: "pagerduty", Target: "api-oncall-service"},
+				{Type: "pagerduty", Target: "a0384d8d9957b2493e22c5f1336c60a4"},
 			},
 		},
 		{
@@ -6,7 +6,8 @@ import (
 )
 
 // HighLatencyAlert triggers when P99 latency exceeds 2 seconds.
-// Evaluates every 5 minutes and sends notifications to the performance team.
+// Evaluates every 15 minutes, a quarter of the query's hour, and sends
+// notifications to the performance team.
 //
 // References:
 //   - Query: SlowRequests (queries.go)
@@ -16,7 +17,8 @@ var HighLatencyAlert = trigger.Trigger{
 	Dataset:     "production",
 	Query:       SlowRequests,
 	Threshold:   trigger.GreaterThan(2000),
-	Frequency:   trigger.Minutes(5),
+	Frequency:   trigger.Minutes(15),
+	AlertType:   trigger.OnChange,
 	Recipients: []trigger.Recipient{
 		trigger.SlackChannel("#performance"),
 		trigger.EmailAddress("performance-team@example.com"),
@@ -25,7 +27,7 @@ var HighLatencyAlert = trigger.Trigger{
 }
 
 // ErrorRateAlert triggers when error rate exceeds 5% of total traffic.
-// Evaluates every 2 minutes for rapid incident detection.
+// Evaluates every 15 minutes, the shortest frequency its one-hour query allows.
 //
 // References:
 //   - Query: ErrorRate (queries.go)
@@ -35,16 +37,18 @@ var ErrorRateAlert = trigger.Trigger{
 	Dataset:     "production",
 	Query:       ErrorRate,
 	Threshold:   trigger.GreaterThan(50),
-	Frequency:   trigger.Minutes(2),
+	Frequency:   trigger.Minutes(15),
+	AlertType:   trigger.OnChange,
 	Recipients: []trigger.Recipient{
 		trigger.SlackChannel("#oncall"),
-		trigger.PagerDutyService("api-oncall-service"),
+		trigger.PagerDutyService("a0384d8d9957b2493e22c5f1336c60a4"),
 	},
 	Disabled: false,
 }
 
 // LowTrafficAlert triggers when request volume drops unexpectedly.
-// Helps detect upstream issues or traffic routing problems.
+// Helps detect upstream issues or traffic routing problems. Evaluates every
+// 6 hours, the shortest frequency its one-day query allows.
 //
 // References:
 //   - Query: RequestThroughput (queries.go)
@@ -54,7 +58,7 @@ var LowTrafficAlert = trigger.Trigger{
 	Dataset:     "production",
 	Query:       RequestThroughput,
 	Threshold:   trigger.LessThan(100),
-	Frequency:   trigger.Minutes(10),
+	Frequency:   trigger.Minutes(360),
 	Recipients: []trigger.Recipient{
 		trigger.SlackChannel("#infrastructure"),
 	},
@@ -89,10 +93,11 @@ var CriticalEndpointLatency = trigger.Trigger{
 		Limit: 10,
 	},
 	Threshold: trigger.GreaterThan(500),
-	Frequency: trigger.Minutes(3),
+	Frequency: trigger.Minutes(4),
+	AlertType: trigger.OnChange,
 	Recipients: []trigger.Recipient{
 		trigger.SlackChannel("#oncall"),
-		trigger.PagerDutyService("critical-api-service"),
+		trigger.PagerDutyService("718cafa48e3cc5d2abb1f2466ac1762a"),
 	},
 	Disabled: false,
 }
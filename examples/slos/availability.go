@@ -43,7 +43,7 @@ var APIAvailability = slo.SLO{
 			Threshold: 10.0,
 			Window:    slo.TimePeriod{Hours: 1},
 			Recipients: []slo.Recipient{
-				{Type: "pagerduty", Target: "platform-oncall"},
+				{Type: "pagerduty", Target: "be94223bbce77c2bc630080b2695a85d"},
 				{Type: "slack", Target: "#alerts-critical"},
 			},
 		},
@@ -130,7 +130,7 @@ var AuthAvailability = slo.SLO{
 			Threshold: 20.0,
 			Window:    slo.TimePeriod{Hours: 1},
 			Recipients: []slo.Recipient{
-				{Type: "pagerduty", Target: "auth-oncall"},
+				{Type: "pagerduty", Target: "e69d59b185112befb1ddfd96408b5116"},
 				{Type: "slack", Target: "#alerts-auth-critical"},
 			},
 		},
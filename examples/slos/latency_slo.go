@@ -76,7 +76,7 @@ var DatabaseQueryLatency = slo.SLO{
 			Threshold: 15.0,
 			Window:    slo.TimePeriod{Hours: 1},
 			Recipients: []slo.Recipient{
-				{Type: "pagerduty", Target: "backend-oncall"},
+				{Type: "pagerduty", Target: "52ca3ad8f4b0cc83c8e72ee171e72482"},
 			},
 		},
 		{
@@ -137,7 +137,7 @@ var CheckoutLatency = slo.SLO{
 			Threshold: 20.0,
 			Window:    slo.TimePeriod{Hours: 1},
 			Recipients: []slo.Recipient{
-				{Type: "pagerduty", Target: "checkout-oncall"},
+				{Type: "pagerduty", Target: "6b4bc98ff42a0c57f6643d7d93cf7d0f"},
 				{Type: "slack", Target: "#alerts-checkout"},
 			},
 		},
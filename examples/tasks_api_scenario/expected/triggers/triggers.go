@@ -7,14 +7,15 @@ import (
 )
 
 // HighErrorRate triggers when error rate exceeds 1%.
-// Evaluates every 2 minutes to provide rapid detection of service failures.
+// Evaluates every 15 minutes, the shortest frequency its one-hour query allows.
 var HighErrorRate = trigger.Trigger{
 	Name:        "High Error Rate",
 	Description: "Error rate exceeds 1% threshold",
 	Dataset:     "tasks-api",
 	Query:       queries.ErrorRate,
 	Threshold:   trigger.GreaterThan(1.0),
-	Frequency:   trigger.Minutes(2),
+	Frequency:   trigger.Minutes(15),
+	AlertType:   trigger.OnChange,
 	Recipients: []trigger.Recipient{
 		trigger.SlackChannel("#alerts"),
 	},
@@ -29,7 +30,8 @@ var HighLatency = trigger.Trigger{
 	Dataset:     "tasks-api",
 	Query:       queries.RequestLatency,
 	Threshold:   trigger.GreaterThan(1000),
-	Frequency:   trigger.Minutes(2),
+	Frequency:   trigger.Minutes(15),
+	AlertType:   trigger.OnChange,
 	Recipients: []trigger.Recipient{
 		trigger.SlackChannel("#alerts"),
 	},
@@ -27,7 +27,7 @@ var HighLatencyTrigger = trigger.Trigger{
 	Threshold: trigger.GreaterThan(1000),
 	Frequency: trigger.Minutes(2),
 	Recipients: []trigger.Recipient{
-		trigger.PagerDutyService("P123ABC"),
+		trigger.PagerDutyService("113cece1c4828db4e6b643fb91002d49"),
 		trigger.SlackChannel("#alerts-latency"),
 	},
 	Disabled: false,
@@ -83,9 +83,10 @@ var SlowDatabaseTrigger = trigger.Trigger{
 	},
 	Threshold: trigger.GreaterThan(500),
 	Frequency: trigger.Minutes(5),
+	AlertType: trigger.OnChange,
 	Recipients: []trigger.Recipient{
 		trigger.SlackChannel("#database-team"),
-		trigger.PagerDutyService("DB456XYZ"),
+		trigger.PagerDutyService("678f79c47dd37f28d6de6ddca91e4e1b"),
 	},
 	Disabled: false,
 }
@@ -104,9 +105,9 @@ var LowTrafficTrigger = trigger.Trigger{
 		},
 	},
 	Threshold: trigger.LessThan(100),
-	Frequency: trigger.Minutes(1),
+	Frequency: trigger.Minutes(2),
 	Recipients: []trigger.Recipient{
-		trigger.PagerDutyService("P789DEF"),
+		trigger.PagerDutyService("c9327e0d6e92c00164017b823c03d182"),
 		trigger.SlackChannel("#ops-alerts"),
 	},
 	Disabled: false,
@@ -135,6 +136,7 @@ var HighMemoryUsageTrigger = trigger.Trigger{
 	},
 	Threshold: trigger.GreaterThanOrEqual(85),
 	Frequency: trigger.Minutes(5),
+	AlertType: trigger.OnChange,
 	Recipients: []trigger.Recipient{
 		trigger.SlackChannel("#infrastructure"),
 		trigger.EmailAddress("infra-team@company.com"),
@@ -166,8 +168,9 @@ var AuthenticationFailuresTrigger = trigger.Trigger{
 	},
 	Threshold: trigger.GreaterThan(50),
 	Frequency: trigger.Minutes(2),
+	AlertType: trigger.OnTrue,
 	Recipients: []trigger.Recipient{
-		trigger.PagerDutyService("SEC123"),
+		trigger.PagerDutyService("c3fc0fcc688dd59db08f3dfb3c7b64e7"),
 		trigger.SlackChannel("#security-alerts"),
 		trigger.WebhookURL("https://security-system.company.com/webhook"),
 	},
@@ -198,6 +201,7 @@ var ApiQuotaExceededTrigger = trigger.Trigger{
 	},
 	Threshold: trigger.GreaterThan(100),
 	Frequency: trigger.Minutes(5),
+	AlertType: trigger.OnTrue,
 	Recipients: []trigger.Recipient{
 		trigger.SlackChannel("#api-team"),
 		trigger.EmailAddress("api-team@company.com"),
@@ -229,9 +233,10 @@ var DeploymentErrorSpikeTrigger = trigger.Trigger{
 		Limit: 15,
 	},
 	Threshold: trigger.GreaterThan(25),
-	Frequency: trigger.Minutes(3),
+	Frequency: trigger.Minutes(4),
+	AlertType: trigger.OnChange,
 	Recipients: []trigger.Recipient{
-		trigger.PagerDutyService("DEPLOY456"),
+		trigger.PagerDutyService("aa787a1964e4950e80fc457bbbd3d97b"),
 		trigger.SlackChannel("#deployments"),
 	},
 	Disabled: false,
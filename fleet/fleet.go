@@ -0,0 +1,64 @@
+// Package fleet stamps out the same resources for every service of a list,
+// instead of copying a query, SLO or trigger once per service:
+//
+//	var Services = []string{"checkout", "cart", "search"}
+//
+//	var Latency = fleet.Each(Services, func(service string) trigger.Trigger {
+//		return trigger.Trigger{
+//			Name:    "High latency: " + service,
+//			Dataset: "production",
+//			Query: query.Query{
+//				Dataset:      "production",
+//				Calculations: []query.Calculation{query.P90("duration_ms")},
+//				Filters:      []query.Filter{query.Equals("service.name", service)},
+//				TimeRange:    query.Minutes(15),
+//			},
+//			Threshold:  trigger.GreaterThan(500),
+//			Recipients: []trigger.Recipient{trigger.SlackChannel("#" + service + "-alerts")},
+//		}
+//	})
+//
+// Discovery reads source code rather than running it, so build expands the
+// template itself: it substitutes each service for the function's parameter
+// in the returned literal and discovers one resource per service, named by
+// Name: CheckoutLatency, CartLatency and SearchLatency. For that to work the
+// function must consist of a single return statement of a literal, the
+// parameter may only be used as a string value, concatenated with string
+// literals by +, and the service list must be a []string literal, written
+// in place or as a package-level variable of the same package.
+package fleet
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Each returns the resource fn builds for each service, keyed by service.
+func Each[T any](services []string, fn func(service string) T) map[string]T {
+	resources := make(map[string]T, len(services))
+	for _, service := range services {
+		resources[service] = fn(service)
+	}
+	return resources
+}
+
+// Name returns the Go name build gives the resource of a service stamped out
+// by the fleet.Each declaration named name: the service in CamelCase
+// followed by name, so "checkout" and Latency give CheckoutLatency, and
+// "user-api" gives UserApiLatency.
+func Name(service, name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range service {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String() + name
+}
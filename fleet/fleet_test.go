@@ -0,0 +1,31 @@
+package fleet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEach(t *testing.T) {
+	got := Each([]string{"checkout", "cart"}, func(service string) string {
+		return "service.name = " + service
+	})
+	assert.Equal(t, map[string]string{
+		"checkout": "service.name = checkout",
+		"cart":     "service.name = cart",
+	}, got)
+}
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		service, name, want string
+	}{
+		{"checkout", "Latency", "CheckoutLatency"},
+		{"user-api", "Latency", "UserApiLatency"},
+		{"search_v2", "Errors", "SearchV2Errors"},
+		{"Cart", "Latency", "CartLatency"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Name(tt.service, tt.name))
+	}
+}
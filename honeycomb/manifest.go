@@ -0,0 +1,182 @@
+// Package honeycomb defines Manifest, the build output of wetwire-honeycomb:
+// the Honeycomb JSON of every resource, keyed by resource type and then Go
+// variable name.
+//
+//	{
+//	  "queries":  {"HighLatency": {...}},
+//	  "triggers": {"HighLatency": {...}}
+//	}
+//
+// Build writes a Manifest, and diff, import and apply read one, so programs
+// consuming the build output can use the same type:
+//
+//	m, err := honeycomb.Unmarshal(data)
+//	for name, body := range m.Triggers {
+//		...
+//	}
+package honeycomb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Sections of the resource types every Manifest has fields for.
+const (
+	SectionQueries  = "queries"
+	SectionBoards   = "boards"
+	SectionSLOs     = "slos"
+	SectionTriggers = "triggers"
+)
+
+// Manifest is a build output. A nil section is left out of the JSON; an
+// empty one is written as {}.
+type Manifest struct {
+	// Queries are the query specs of the "queries" section
+	Queries map[string]json.RawMessage
+
+	// Boards are the boards of the "boards" section
+	Boards map[string]json.RawMessage
+
+	// SLOs are the SLOs of the "slos" section
+	SLOs map[string]json.RawMessage
+
+	// Triggers are the triggers of the "triggers" section
+	Triggers map[string]json.RawMessage
+
+	// Other are the other sections of resources keyed by name, such as
+	// maintenance windows ("mutes") and resources of kinds registered with
+	// package resource
+	Other map[string]map[string]json.RawMessage
+
+	// Extra are the sections that do not hold resources, such as the failed
+	// resources ("errors") and references ("refs") of a build, as written
+	Extra map[string]json.RawMessage
+}
+
+// Unmarshal parses a build output. A top-level value that is a JSON object
+// is read as a section of resources, any other value into Extra.
+func Unmarshal(data []byte) (*Manifest, error) {
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Marshal returns the JSON of the manifest, with sections and resources
+// sorted by name.
+func (m *Manifest) Marshal() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (m *Manifest) MarshalJSON() ([]byte, error) {
+	top := make(map[string]json.RawMessage, len(m.Extra)+len(m.Other)+4)
+	for section, data := range m.Extra {
+		top[section] = data
+	}
+	for section, resources := range m.Resources() {
+		data, err := json.Marshal(resources)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", section, err)
+		}
+		top[section] = data
+	}
+	return json.Marshal(top)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (m *Manifest) UnmarshalJSON(data []byte) error {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return err
+	}
+
+	*m = Manifest{}
+	for section, raw := range top {
+		if trimmed := bytes.TrimSpace(raw); len(trimmed) == 0 || trimmed[0] != '{' {
+			if m.Extra == nil {
+				m.Extra = make(map[string]json.RawMessage)
+			}
+			m.Extra[section] = raw
+			continue
+		}
+		var resources map[string]json.RawMessage
+		if err := json.Unmarshal(raw, &resources); err != nil {
+			return fmt.Errorf("%s: %w", section, err)
+		}
+		m.SetSection(section, resources)
+	}
+	return nil
+}
+
+// Section returns the resources of a section, or nil when the manifest does
+// not have it.
+func (m *Manifest) Section(section string) map[string]json.RawMessage {
+	switch section {
+	case SectionQueries:
+		return m.Queries
+	case SectionBoards:
+		return m.Boards
+	case SectionSLOs:
+		return m.SLOs
+	case SectionTriggers:
+		return m.Triggers
+	}
+	return m.Other[section]
+}
+
+// SetSection replaces the resources of a section.
+func (m *Manifest) SetSection(section string, resources map[string]json.RawMessage) {
+	switch section {
+	case SectionQueries:
+		m.Queries = resources
+	case SectionBoards:
+		m.Boards = resources
+	case SectionSLOs:
+		m.SLOs = resources
+	case SectionTriggers:
+		m.Triggers = resources
+	default:
+		if m.Other == nil {
+			m.Other = make(map[string]map[string]json.RawMessage)
+		}
+		m.Other[section] = resources
+	}
+}
+
+// Resources returns the sections of resources the manifest has, keyed by
+// section. The sections are shared with the manifest, so resources changed
+// in place are changed in the manifest.
+func (m *Manifest) Resources() map[string]map[string]json.RawMessage {
+	sections := make(map[string]map[string]json.RawMessage, len(m.Other)+4)
+	for section, resources := range m.Other {
+		sections[section] = resources
+	}
+	for _, section := range []string{SectionQueries, SectionBoards, SectionSLOs, SectionTriggers} {
+		if resources := m.Section(section); resources != nil {
+			sections[section] = resources
+		}
+	}
+	return sections
+}
+
+// SectionNames returns the names of the sections of resources the manifest
+// has, sorted.
+func (m *Manifest) SectionNames() []string {
+	names := make([]string, 0, len(m.Other)+4)
+	for section := range m.Resources() {
+		names = append(names, section)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the resource of a section named name.
+func (m *Manifest) Get(section, name string) (json.RawMessage, bool) {
+	body, ok := m.Section(section)[name]
+	return body, ok
+}
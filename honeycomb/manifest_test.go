@@ -0,0 +1,65 @@
+package honeycomb
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshal(t *testing.T) {
+	data := `{
+		"queries":  {"Latency": {"time_range": 3600}},
+		"triggers": {"HighLatency": {"name": "High Latency"}},
+		"mutes":    {"Upgrade": {"triggers": ["HighLatency"]}},
+		"errors":   [{"resource": "slos/Availability"}]
+	}`
+
+	m, err := Unmarshal([]byte(data))
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"time_range": 3600}`, string(m.Queries["Latency"]))
+	assert.JSONEq(t, `{"name": "High Latency"}`, string(m.Triggers["HighLatency"]))
+	assert.Nil(t, m.Boards)
+	assert.JSONEq(t, `{"triggers": ["HighLatency"]}`, string(m.Section("mutes")["Upgrade"]))
+	assert.JSONEq(t, `[{"resource": "slos/Availability"}]`, string(m.Extra["errors"]))
+	assert.Equal(t, []string{"mutes", "queries", "triggers"}, m.SectionNames())
+
+	body, ok := m.Get(SectionTriggers, "HighLatency")
+	assert.True(t, ok)
+	assert.JSONEq(t, `{"name": "High Latency"}`, string(body))
+	_, ok = m.Get(SectionBoards, "Overview")
+	assert.False(t, ok)
+}
+
+func TestUnmarshal_Invalid(t *testing.T) {
+	_, err := Unmarshal([]byte(`[]`))
+	assert.Error(t, err)
+}
+
+func TestManifest_Marshal(t *testing.T) {
+	m := &Manifest{
+		Queries: map[string]json.RawMessage{"Latency": json.RawMessage(`{"time_range":3600}`)},
+		SLOs:    map[string]json.RawMessage{},
+		Extra:   map[string]json.RawMessage{"refs": json.RawMessage(`[]`)},
+	}
+	m.SetSection("runbooks", map[string]json.RawMessage{"Restart": json.RawMessage(`{"title":"Restart"}`)})
+
+	data, err := m.Marshal()
+	require.NoError(t, err)
+	assert.Equal(t, `{"queries":{"Latency":{"time_range":3600}},"refs":[],"runbooks":{"Restart":{"title":"Restart"}},"slos":{}}`, string(data))
+
+	roundTrip, err := Unmarshal(data)
+	require.NoError(t, err)
+	assert.Equal(t, m.Resources(), roundTrip.Resources())
+	assert.Equal(t, m.Extra, roundTrip.Extra)
+}
+
+func TestManifest_Resources(t *testing.T) {
+	m := &Manifest{Queries: map[string]json.RawMessage{"Latency": json.RawMessage(`{}`)}}
+
+	// Sections are shared, so changes made through Resources are kept
+	m.Resources()[SectionQueries]["Latency"] = json.RawMessage(`{"time_range":600}`)
+	assert.Equal(t, `{"time_range":600}`, string(m.Queries["Latency"]))
+}
@@ -0,0 +1,336 @@
+// Package advise checks query time ranges, granularity and breakdowns against
+// dataset retention and ingest volume, and suggests cheaper alternatives.
+package advise
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// Suggestion kinds.
+const (
+	KindRetention   = "retention"
+	KindTimeRange   = "time_range"
+	KindGranularity = "granularity"
+	KindBreakdowns  = "breakdowns"
+)
+
+// Defaults used when Options leave a limit unset.
+const (
+	DefaultRetentionDays    = 60
+	DefaultMaxEventsScanned = 1_000_000_000
+	DefaultMaxBuckets       = 300
+	DefaultMaxBreakdowns    = 3
+)
+
+const day = 24 * 60 * 60
+
+// rangeSteps are the time ranges suggested instead of a longer one, in seconds.
+var rangeSteps = []int{3600, 2 * 3600, 6 * 3600, 12 * 3600, day, 2 * day, 7 * day, 14 * day, 28 * day}
+
+// granularitySteps are the granularities suggested instead of a finer one, in
+// seconds.
+var granularitySteps = []int{60, 5 * 60, 15 * 60, 30 * 60, 3600, 2 * 3600, 6 * 3600, 12 * 3600, day}
+
+// Options configure the advisor.
+type Options struct {
+	// RetentionDays is the retention of datasets whose schema declares none
+	RetentionDays int
+
+	// EventsPerDay sets the ingest volume of datasets by name, overriding
+	// their schemas
+	EventsPerDay map[string]int64
+
+	// MaxEventsScanned is the number of events a query may scan before a
+	// shorter time range is suggested
+	MaxEventsScanned int64
+
+	// MaxBuckets is the number of time buckets a query may return before a
+	// coarser granularity is suggested
+	MaxBuckets int
+
+	// MaxBreakdowns is the number of breakdowns a query may have before
+	// fewer are suggested
+	MaxBreakdowns int
+}
+
+// withDefaults returns opts with unset limits replaced by defaults.
+func (o Options) withDefaults() Options {
+	if o.RetentionDays <= 0 {
+		o.RetentionDays = DefaultRetentionDays
+	}
+	if o.MaxEventsScanned <= 0 {
+		o.MaxEventsScanned = DefaultMaxEventsScanned
+	}
+	if o.MaxBuckets <= 0 {
+		o.MaxBuckets = DefaultMaxBuckets
+	}
+	if o.MaxBreakdowns <= 0 {
+		o.MaxBreakdowns = DefaultMaxBreakdowns
+	}
+	return o
+}
+
+// Dataset is the retention and ingest volume the advice for a dataset is
+// based on.
+type Dataset struct {
+	Name          string `json:"name"`
+	RetentionDays int    `json:"retention_days"`
+
+	// EventsPerDay is 0 when the volume is unknown
+	EventsPerDay int64 `json:"events_per_day,omitempty"`
+}
+
+// Suggestion is a cheaper alternative for one aspect of a query.
+type Suggestion struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+
+	// Current and Suggested are the current and suggested time range or
+	// granularity in seconds, or number of breakdowns
+	Current   int `json:"current"`
+	Suggested int `json:"suggested"`
+
+	// EventsSaved estimates the reduction in events scanned (0 when unknown)
+	EventsSaved int64 `json:"events_saved,omitempty"`
+}
+
+// QueryAdvice is the advice for one query.
+type QueryAdvice struct {
+	Name    string `json:"name"`
+	Dataset string `json:"dataset"`
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+
+	// TimeRange and Granularity are in seconds
+	TimeRange   int `json:"time_range"`
+	Granularity int `json:"granularity,omitempty"`
+	Breakdowns  int `json:"breakdowns"`
+
+	// EventsScanned estimates the events the query reads (0 when the
+	// dataset's volume is unknown)
+	EventsScanned int64 `json:"events_scanned,omitempty"`
+
+	Suggestions []Suggestion `json:"suggestions"`
+}
+
+// Summary totals a report.
+type Summary struct {
+	Queries         int   `json:"queries"`
+	WithSuggestions int   `json:"with_suggestions"`
+	Suggestions     int   `json:"suggestions"`
+	EventsScanned   int64 `json:"events_scanned"`
+	EventsSaved     int64 `json:"events_saved"`
+}
+
+// Report is the advice for a set of queries.
+type Report struct {
+	Datasets []Dataset     `json:"datasets"`
+	Queries  []QueryAdvice `json:"queries"`
+	Summary  Summary       `json:"summary"`
+}
+
+// Advise returns advice for every query in resources. Dataset retention and
+// ingest volume come from the dataset schemas of resources, overridden by
+// opts.
+func Advise(resources *discovery.DiscoveredResources, opts Options) *Report {
+	opts = opts.withDefaults()
+	report := &Report{Datasets: []Dataset{}, Queries: []QueryAdvice{}}
+
+	datasets := make(map[string]Dataset)
+	for _, q := range resources.Queries {
+		if _, ok := datasets[q.Dataset]; ok {
+			continue
+		}
+		ds := Dataset{Name: q.Dataset, RetentionDays: opts.RetentionDays}
+		if schema, ok := resources.Schema(q.Dataset); ok {
+			if schema.RetentionDays > 0 {
+				ds.RetentionDays = schema.RetentionDays
+			}
+			ds.EventsPerDay = schema.EventsPerDay
+		}
+		if n, ok := opts.EventsPerDay[q.Dataset]; ok {
+			ds.EventsPerDay = n
+		}
+		datasets[q.Dataset] = ds
+		report.Datasets = append(report.Datasets, ds)
+	}
+	sort.Slice(report.Datasets, func(i, j int) bool { return report.Datasets[i].Name < report.Datasets[j].Name })
+
+	seen := make(map[string]bool)
+	for _, q := range resources.Queries {
+		// Inline queries can be discovered more than once
+		key := fmt.Sprintf("%s:%d:%s", q.File, q.Line, q.Name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		advice := adviseQuery(q, datasets[q.Dataset], opts)
+		report.Queries = append(report.Queries, advice)
+
+		report.Summary.Queries++
+		report.Summary.EventsScanned += advice.EventsScanned
+		if len(advice.Suggestions) > 0 {
+			report.Summary.WithSuggestions++
+		}
+		for _, s := range advice.Suggestions {
+			report.Summary.Suggestions++
+			report.Summary.EventsSaved += s.EventsSaved
+		}
+	}
+
+	sort.SliceStable(report.Queries, func(i, j int) bool {
+		a, b := report.Queries[i], report.Queries[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return report
+}
+
+// adviseQuery returns the advice for a single query.
+func adviseQuery(q discovery.DiscoveredQuery, ds Dataset, opts Options) QueryAdvice {
+	advice := QueryAdvice{
+		Name:        q.Name,
+		Dataset:     q.Dataset,
+		File:        q.File,
+		Line:        q.Line,
+		TimeRange:   rangeSeconds(q.TimeRange),
+		Granularity: q.Granularity,
+		Breakdowns:  len(q.Breakdowns),
+		Suggestions: []Suggestion{},
+	}
+	if advice.TimeRange <= 0 {
+		return advice
+	}
+
+	// Events older than the retention are gone, so only the retained part is scanned
+	retention := ds.RetentionDays * day
+	scanned := min(advice.TimeRange, retention)
+	advice.EventsScanned = events(ds.EventsPerDay, scanned)
+
+	if advice.TimeRange > retention {
+		advice.Suggestions = append(advice.Suggestions, Suggestion{
+			Kind:      KindRetention,
+			Message:   fmt.Sprintf("time range %s exceeds the %s retention of %s; older events no longer exist", FormatSeconds(advice.TimeRange), FormatSeconds(retention), q.Dataset),
+			Current:   advice.TimeRange,
+			Suggested: retention,
+		})
+	}
+
+	if ds.EventsPerDay > 0 && advice.EventsScanned > opts.MaxEventsScanned {
+		allowed := int(opts.MaxEventsScanned * day / ds.EventsPerDay)
+		if suggested := stepBelow(rangeSteps, allowed, scanned); suggested > 0 {
+			advice.Suggestions = append(advice.Suggestions, Suggestion{
+				Kind: KindTimeRange,
+				Message: fmt.Sprintf("scans about %s events over %s; %s would scan %s",
+					FormatCount(advice.EventsScanned), FormatSeconds(scanned), FormatSeconds(suggested), FormatCount(events(ds.EventsPerDay, suggested))),
+				Current:     advice.TimeRange,
+				Suggested:   suggested,
+				EventsSaved: advice.EventsScanned - events(ds.EventsPerDay, suggested),
+			})
+		}
+	}
+
+	if q.Granularity > 0 {
+		if buckets := advice.TimeRange / q.Granularity; buckets > opts.MaxBuckets {
+			minimum := (advice.TimeRange + opts.MaxBuckets - 1) / opts.MaxBuckets
+			suggested := stepAbove(granularitySteps, minimum)
+			if suggested == 0 {
+				suggested = minimum
+			}
+			advice.Suggestions = append(advice.Suggestions, Suggestion{
+				Kind: KindGranularity,
+				Message: fmt.Sprintf("granularity %s over %s returns %d points per series; %s returns %d",
+					FormatSeconds(q.Granularity), FormatSeconds(advice.TimeRange), buckets, FormatSeconds(suggested), advice.TimeRange/suggested),
+				Current:   q.Granularity,
+				Suggested: suggested,
+			})
+		}
+	}
+
+	if advice.Breakdowns > opts.MaxBreakdowns {
+		advice.Suggestions = append(advice.Suggestions, Suggestion{
+			Kind:      KindBreakdowns,
+			Message:   fmt.Sprintf("%d breakdowns multiply the result groups; keep at most %d", advice.Breakdowns, opts.MaxBreakdowns),
+			Current:   advice.Breakdowns,
+			Suggested: opts.MaxBreakdowns,
+		})
+	}
+
+	return advice
+}
+
+// rangeSeconds returns the length of a time range in seconds.
+func rangeSeconds(tr discovery.TimeRange) int {
+	if tr.TimeRange > 0 {
+		return tr.TimeRange
+	}
+	if tr.EndTime > tr.StartTime {
+		return tr.EndTime - tr.StartTime
+	}
+	return 0
+}
+
+// events estimates the events a dataset ingests in seconds.
+func events(perDay int64, seconds int) int64 {
+	return perDay * int64(seconds) / day
+}
+
+// stepBelow returns the largest step that is at most limit and below current,
+// or 0 when there is none.
+func stepBelow(steps []int, limit, current int) int {
+	result := 0
+	for _, s := range steps {
+		if s <= limit && s < current {
+			result = s
+		}
+	}
+	return result
+}
+
+// stepAbove returns the smallest step of at least minimum, or 0 when there is
+// none.
+func stepAbove(steps []int, minimum int) int {
+	for _, s := range steps {
+		if s >= minimum {
+			return s
+		}
+	}
+	return 0
+}
+
+// FormatSeconds formats a duration in seconds in its largest whole unit, e.g.
+// "7d", "6h", "15m" or "90s".
+func FormatSeconds(seconds int) string {
+	for _, unit := range []struct {
+		size   int
+		suffix string
+	}{{day, "d"}, {3600, "h"}, {60, "m"}} {
+		if seconds >= unit.size && seconds%unit.size == 0 {
+			return fmt.Sprintf("%d%s", seconds/unit.size, unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
+
+// FormatCount formats a number of events compactly, e.g. "4.2B" or "350M".
+func FormatCount(n int64) string {
+	for _, unit := range []struct {
+		size   float64
+		suffix string
+	}{{1e12, "T"}, {1e9, "B"}, {1e6, "M"}, {1e3, "K"}} {
+		if float64(n) >= unit.size {
+			v := float64(n) / unit.size
+			if v >= 100 {
+				return fmt.Sprintf("%.0f%s", v, unit.suffix)
+			}
+			return fmt.Sprintf("%.3g%s", v, unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%d", n)
+}
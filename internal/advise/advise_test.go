@@ -0,0 +1,134 @@
+package advise
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+func testResources() *discovery.DiscoveredResources {
+	expensive := discovery.DiscoveredQuery{
+		Name:        "AllRequests",
+		File:        "queries.go",
+		Line:        10,
+		Dataset:     "api",
+		TimeRange:   discovery.TimeRange{TimeRange: 60 * day},
+		Granularity: 60,
+		Breakdowns:  []string{"service.name", "endpoint", "region", "status"},
+	}
+	return &discovery.DiscoveredResources{
+		// Inline queries can be discovered more than once
+		Queries: []discovery.DiscoveredQuery{
+			expensive,
+			expensive,
+			{Name: "PageViews", File: "queries.go", Line: 3, Dataset: "web", TimeRange: discovery.TimeRange{TimeRange: 2 * 3600}},
+			{Name: "Incident", File: "incident.go", Line: 5, Dataset: "web", TimeRange: discovery.TimeRange{StartTime: 1000, EndTime: 1000 + 7200}},
+		},
+		Schemas: []discovery.DiscoveredSchema{
+			{Dataset: "api", RetentionDays: 30, EventsPerDay: 2_000_000_000},
+		},
+	}
+}
+
+func TestAdvise(t *testing.T) {
+	report := Advise(testResources(), Options{EventsPerDay: map[string]int64{"web": 12_000_000}})
+
+	assert.Equal(t, []Dataset{
+		{Name: "api", RetentionDays: 30, EventsPerDay: 2_000_000_000},
+		{Name: "web", RetentionDays: DefaultRetentionDays, EventsPerDay: 12_000_000},
+	}, report.Datasets)
+
+	require.Len(t, report.Queries, 3)
+	// Sorted by file and line
+	assert.Equal(t, []string{"Incident", "PageViews", "AllRequests"},
+		[]string{report.Queries[0].Name, report.Queries[1].Name, report.Queries[2].Name})
+
+	incident := report.Queries[0]
+	assert.Equal(t, 7200, incident.TimeRange)
+	assert.Equal(t, int64(1_000_000), incident.EventsScanned)
+	assert.Empty(t, incident.Suggestions)
+
+	all := report.Queries[2]
+	assert.Equal(t, int64(60_000_000_000), all.EventsScanned, "only the retained 30 days are scanned")
+	assert.Equal(t, []Suggestion{
+		{
+			Kind:      KindRetention,
+			Message:   "time range 60d exceeds the 30d retention of api; older events no longer exist",
+			Current:   60 * day,
+			Suggested: 30 * day,
+		},
+		{
+			Kind:        KindTimeRange,
+			Message:     "scans about 60B events over 30d; 12h would scan 1B",
+			Current:     60 * day,
+			Suggested:   12 * 3600,
+			EventsSaved: 59_000_000_000,
+		},
+		{
+			Kind:      KindGranularity,
+			Message:   "granularity 1m over 60d returns 86400 points per series; 6h returns 240",
+			Current:   60,
+			Suggested: 6 * 3600,
+		},
+		{
+			Kind:      KindBreakdowns,
+			Message:   "4 breakdowns multiply the result groups; keep at most 3",
+			Current:   4,
+			Suggested: 3,
+		},
+	}, all.Suggestions)
+
+	assert.Equal(t, Summary{
+		Queries:         3,
+		WithSuggestions: 1,
+		Suggestions:     4,
+		EventsScanned:   60_000_000_000 + 1_000_000 + 1_000_000,
+		EventsSaved:     59_000_000_000,
+	}, report.Summary)
+}
+
+func TestAdvise_Options(t *testing.T) {
+	// A shorter default retention applies to datasets without a declared one
+	report := Advise(testResources(), Options{RetentionDays: 7, MaxBreakdowns: 4, MaxBuckets: 100_000})
+	require.Len(t, report.Queries, 3)
+	assert.Empty(t, report.Queries[0].Suggestions)
+
+	kinds := []string{}
+	for _, s := range report.Queries[2].Suggestions {
+		kinds = append(kinds, s.Kind)
+	}
+	assert.Equal(t, []string{KindRetention, KindTimeRange}, kinds)
+
+	// Without ingest volumes only retention, granularity and breakdowns are checked
+	report = Advise(&discovery.DiscoveredResources{Queries: []discovery.DiscoveredQuery{
+		{Name: "Q", Dataset: "unknown", TimeRange: discovery.TimeRange{TimeRange: 90 * day}},
+	}}, Options{})
+	require.Len(t, report.Queries, 1)
+	require.Len(t, report.Queries[0].Suggestions, 1)
+	assert.Equal(t, KindRetention, report.Queries[0].Suggestions[0].Kind)
+	assert.Zero(t, report.Queries[0].EventsScanned)
+}
+
+func TestAdvise_Empty(t *testing.T) {
+	report := Advise(&discovery.DiscoveredResources{}, Options{})
+	assert.NotNil(t, report.Datasets)
+	assert.NotNil(t, report.Queries)
+	assert.Zero(t, report.Summary)
+}
+
+func TestFormatSeconds(t *testing.T) {
+	assert.Equal(t, "7d", FormatSeconds(7*day))
+	assert.Equal(t, "36h", FormatSeconds(36*3600))
+	assert.Equal(t, "15m", FormatSeconds(900))
+	assert.Equal(t, "90s", FormatSeconds(90))
+}
+
+func TestFormatCount(t *testing.T) {
+	assert.Equal(t, "950", FormatCount(950))
+	assert.Equal(t, "4.2B", FormatCount(4_200_000_000))
+	assert.Equal(t, "350M", FormatCount(350_000_000))
+	assert.Equal(t, "12.5K", FormatCount(12_500))
+}
@@ -0,0 +1,452 @@
+// Package analyze finds structure across discovered resources, such as
+// clusters of similar queries that could share a canonical declaration.
+package analyze
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// DefaultThreshold is the similarity from which two queries are clustered.
+const DefaultThreshold = 0.5
+
+// Options configure clustering.
+type Options struct {
+	// Threshold is the Jaccard similarity of two queries' calculations and
+	// filters from which they are clustered (default: DefaultThreshold)
+	Threshold float64
+}
+
+// Canonical is the query shared by the members of a cluster: the
+// calculations, filters and breakdowns they all have in common.
+type Canonical struct {
+	// Name is the suggested Go variable name. When Existing is set it is the
+	// member that already is the canonical query.
+	Name     string `json:"name"`
+	Existing bool   `json:"existing"`
+
+	Calculations []string `json:"calculations"`
+	Filters      []string `json:"filters"`
+	Breakdowns   []string `json:"breakdowns"`
+
+	// Query is the canonical query, with the time range, granularity and
+	// filter combination of the first member
+	Query discovery.DiscoveredQuery `json:"-"`
+
+	// Code is the Go declaration of a canonical query that does not exist
+	// yet, left for the caller to generate
+	Code string `json:"code,omitempty"`
+}
+
+// Variant is a member of a cluster, described by what it adds to the
+// canonical query.
+type Variant struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+
+	// Similarity is the share of the member's calculations and filters that
+	// are canonical
+	Similarity float64 `json:"similarity"`
+
+	Calculations []string `json:"calculations,omitempty"`
+	Filters      []string `json:"filters,omitempty"`
+	Breakdowns   []string `json:"breakdowns,omitempty"`
+
+	// DuplicateOf names an earlier member with the same calculations,
+	// filters and breakdowns, time range, granularity, limit and orders
+	DuplicateOf string `json:"duplicate_of,omitempty"`
+}
+
+// Cluster is a group of similar queries on the same dataset.
+type Cluster struct {
+	Dataset   string    `json:"dataset"`
+	Canonical Canonical `json:"canonical"`
+	Variants  []Variant `json:"variants"`
+}
+
+// ClusterReport is the refactor plan for a set of queries.
+type ClusterReport struct {
+	Clusters []Cluster `json:"clusters"`
+
+	// Queries is the number of queries compared
+	Queries int `json:"queries"`
+
+	// Clustered is the number of queries in a cluster
+	Clustered int `json:"clustered"`
+}
+
+// Clusters groups the queries of resources by complete linkage: every two
+// queries of a group are at least opts.Threshold similar, and all of them
+// share a calculation, so a chain of similar queries does not join queries
+// with little in common. Queries are only compared within a dataset and
+// filter combination, as the same filters select different events under AND
+// and OR, and only groups of two or more are reported, ordered by the
+// position of their first member.
+func Clusters(resources *discovery.DiscoveredResources, opts Options) *ClusterReport {
+	if opts.Threshold <= 0 {
+		opts.Threshold = DefaultThreshold
+	}
+
+	var queries []discovery.DiscoveredQuery
+	seen := make(map[string]bool)
+	for _, q := range resources.Queries {
+		// Inline queries can be discovered more than once
+		key := fmt.Sprintf("%s:%d:%s", q.File, q.Line, q.Name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		queries = append(queries, q)
+	}
+	sort.SliceStable(queries, func(i, j int) bool {
+		if queries[i].File != queries[j].File {
+			return queries[i].File < queries[j].File
+		}
+		return queries[i].Line < queries[j].Line
+	})
+
+	features := make([]featureSet, len(queries))
+	for i, q := range queries {
+		features[i] = newFeatureSet(q)
+	}
+
+	byScope := make(map[string][]int)
+	var scopes []string
+	for i, q := range queries {
+		scope := q.Dataset + "\x00" + filterCombination(q)
+		if _, ok := byScope[scope]; !ok {
+			scopes = append(scopes, scope)
+		}
+		byScope[scope] = append(byScope[scope], i)
+	}
+	var groups [][]int
+	for _, scope := range scopes {
+		groups = append(groups, completeLinkage(features, byScope[scope], opts.Threshold)...)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i][0] < groups[j][0] })
+
+	report := &ClusterReport{Clusters: []Cluster{}, Queries: len(queries)}
+	for _, members := range groups {
+		if len(members) < 2 {
+			continue
+		}
+		report.Clusters = append(report.Clusters, newCluster(queries, features, members))
+		report.Clustered += len(members)
+	}
+	return report
+}
+
+// completeLinkage groups the queries at indexes by agglomerative clustering
+// with complete linkage: starting from one group per query, it merges the
+// two groups whose least similar queries are the most similar, while that
+// similarity is at least threshold and the merged group still shares a
+// calculation. Each group is returned as ascending indexes.
+func completeLinkage(features []featureSet, indexes []int, threshold float64) [][]int {
+	n := len(indexes)
+	groups := make([][]int, n)
+	shared := make([]keySet, n) // the calculations of every member
+	link := make([][]float64, n)
+	for i, x := range indexes {
+		groups[i] = []int{x}
+		shared[i] = features[x].calculations
+		link[i] = make([]float64, n)
+		for j, y := range indexes {
+			link[i][j] = features[x].similarity(features[y])
+		}
+	}
+
+	for {
+		a, b := -1, -1
+		best := threshold
+		for i := range groups {
+			if groups[i] == nil {
+				continue
+			}
+			for j := i + 1; j < n; j++ {
+				// The first of equally similar pairs is merged first
+				if groups[j] == nil || link[i][j] < best || (a >= 0 && link[i][j] == best) {
+					continue
+				}
+				if len(shared[i].intersect(shared[j]).keys) == 0 {
+					continue
+				}
+				a, b, best = i, j, link[i][j]
+			}
+		}
+		if a < 0 {
+			break
+		}
+
+		groups[a] = append(groups[a], groups[b]...)
+		groups[b] = nil
+		shared[a] = shared[a].intersect(shared[b])
+		for k := range groups {
+			link[a][k] = min(link[a][k], link[b][k])
+			link[k][a] = link[a][k]
+		}
+	}
+
+	var result [][]int
+	for _, g := range groups {
+		if g != nil {
+			sort.Ints(g)
+			result = append(result, g)
+		}
+	}
+	return result
+}
+
+// newCluster describes the queries at indexes members as a canonical query
+// and variants.
+func newCluster(queries []discovery.DiscoveredQuery, features []featureSet, members []int) Cluster {
+	first := queries[members[0]]
+	canon := features[members[0]]
+	for _, m := range members[1:] {
+		canon = canon.intersect(features[m])
+	}
+
+	c := Cluster{
+		Dataset: first.Dataset,
+		Canonical: Canonical{
+			Calculations: canon.calculations.keys,
+			Filters:      canon.filters.keys,
+			Breakdowns:   canon.breakdowns.keys,
+			Query:        canonicalQuery(first, canon),
+		},
+	}
+
+	signatures := make(map[string]string)
+	for _, m := range members {
+		q, f := queries[m], features[m]
+		v := Variant{
+			Name:         q.Name,
+			File:         q.File,
+			Line:         q.Line,
+			Similarity:   f.coverage(canon),
+			Calculations: f.calculations.minus(canon.calculations),
+			Filters:      f.filters.minus(canon.filters),
+			Breakdowns:   f.breakdowns.minus(canon.breakdowns),
+		}
+		sig := f.signature()
+		if name, ok := signatures[sig]; ok {
+			v.DuplicateOf = name
+		} else {
+			signatures[sig] = q.Name
+		}
+		if !c.Canonical.Existing && len(v.Calculations)+len(v.Filters)+len(v.Breakdowns) == 0 {
+			c.Canonical.Name = q.Name
+			c.Canonical.Existing = true
+		}
+		c.Variants = append(c.Variants, v)
+	}
+
+	if !c.Canonical.Existing {
+		names := make([]string, len(c.Variants))
+		for i, v := range c.Variants {
+			names[i] = v.Name
+		}
+		c.Canonical.Name = commonPrefix(names) + "Base"
+	}
+	c.Canonical.Query.Name = c.Canonical.Name
+	return c
+}
+
+// canonicalQuery returns first reduced to the calculations, filters and
+// breakdowns of canon.
+func canonicalQuery(first discovery.DiscoveredQuery, canon featureSet) discovery.DiscoveredQuery {
+	q := discovery.DiscoveredQuery{
+		Package:           first.Package,
+		Dataset:           first.Dataset,
+		TimeRange:         first.TimeRange,
+		Granularity:       first.Granularity,
+		FilterCombination: first.FilterCombination,
+	}
+	for _, calc := range first.Calculations {
+		if canon.calculations.has(calculationKey(calc)) {
+			q.Calculations = append(q.Calculations, calc)
+		}
+	}
+	for _, f := range first.Filters {
+		if canon.filters.has(filterKey(f)) {
+			q.Filters = append(q.Filters, f)
+		}
+	}
+	for _, b := range first.Breakdowns {
+		if canon.breakdowns.has(b) {
+			q.Breakdowns = append(q.Breakdowns, b)
+		}
+	}
+	return q
+}
+
+// commonPrefix returns the longest run of whole words, split at upper-case
+// letters, that starts every name. When there is none, the first name is
+// returned.
+func commonPrefix(names []string) string {
+	prefix := names[0]
+	for _, name := range names[1:] {
+		n := 0
+		for n < len(prefix) && n < len(name) && prefix[n] == name[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	for _, name := range names {
+		if name == prefix {
+			return prefix
+		}
+	}
+	// Cut back to where the next word of the first name starts
+	for len(prefix) > 0 && !unicode.IsUpper(rune(names[0][len(prefix)])) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	if prefix == "" {
+		return names[0]
+	}
+	return prefix
+}
+
+// calculationKey describes a calculation, e.g. "P99(duration_ms)" or "COUNT".
+func calculationKey(c discovery.Calculation) string {
+	if c.Column == "" {
+		return c.Op
+	}
+	return fmt.Sprintf("%s(%s)", c.Op, c.Column)
+}
+
+// filterCombination returns the filter combination of q, AND when it sets
+// none.
+func filterCombination(q discovery.DiscoveredQuery) string {
+	return strings.ToUpper(cmp.Or(q.FilterCombination, "AND"))
+}
+
+// filterKey describes a filter, e.g. "service.name = checkout".
+func filterKey(f discovery.Filter) string {
+	if !f.HasValue {
+		return fmt.Sprintf("%s %s", f.Column, f.Op)
+	}
+	return fmt.Sprintf("%s %s %v", f.Column, f.Op, f.Value)
+}
+
+// keySet is an ordered set of strings.
+type keySet struct {
+	keys []string
+	set  map[string]bool
+}
+
+func newKeySet(keys []string) keySet {
+	s := keySet{keys: []string{}, set: make(map[string]bool)}
+	for _, k := range keys {
+		if !s.set[k] {
+			s.set[k] = true
+			s.keys = append(s.keys, k)
+		}
+	}
+	return s
+}
+
+func (s keySet) has(k string) bool { return s.set[k] }
+
+func (s keySet) intersect(other keySet) keySet {
+	var keys []string
+	for _, k := range s.keys {
+		if other.has(k) {
+			keys = append(keys, k)
+		}
+	}
+	return newKeySet(keys)
+}
+
+// minus returns the keys of s missing from other, or nil.
+func (s keySet) minus(other keySet) []string {
+	var keys []string
+	for _, k := range s.keys {
+		if !other.has(k) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// featureSet is what queries are compared by.
+type featureSet struct {
+	calculations, filters, breakdowns keySet
+
+	// settings describes the filter combination, time range, granularity,
+	// limit and orders, which tell duplicates apart but not similar queries
+	settings string
+}
+
+func newFeatureSet(q discovery.DiscoveredQuery) featureSet {
+	var calcs, filters []string
+	for _, c := range q.Calculations {
+		calcs = append(calcs, calculationKey(c))
+	}
+	for _, f := range q.Filters {
+		filters = append(filters, filterKey(f))
+	}
+	settings := []string{
+		filterCombination(q),
+		fmt.Sprintf("%d %d %d", q.TimeRange.TimeRange, q.TimeRange.StartTime, q.TimeRange.EndTime),
+		fmt.Sprintf("%d %d", q.Granularity, q.Limit),
+	}
+	for _, o := range q.Orders {
+		settings = append(settings, fmt.Sprintf("%s %s %s", o.Op, o.Column, o.Order))
+	}
+	return featureSet{
+		calculations: newKeySet(calcs),
+		filters:      newKeySet(filters),
+		breakdowns:   newKeySet(q.Breakdowns),
+		settings:     strings.Join(settings, "\x00"),
+	}
+}
+
+func (f featureSet) intersect(other featureSet) featureSet {
+	return featureSet{
+		calculations: f.calculations.intersect(other.calculations),
+		filters:      f.filters.intersect(other.filters),
+		breakdowns:   f.breakdowns.intersect(other.breakdowns),
+	}
+}
+
+func (f featureSet) size() int {
+	return len(f.calculations.keys) + len(f.filters.keys)
+}
+
+// similarity returns the Jaccard similarity of the calculations and filters
+// of f and other. Breakdowns are left out, as they are what per-use-case
+// variants of a query usually differ by.
+func (f featureSet) similarity(other featureSet) float64 {
+	shared := f.intersect(other).size()
+	union := f.size() + other.size() - shared
+	if union == 0 {
+		return 1
+	}
+	return float64(shared) / float64(union)
+}
+
+// coverage returns the share of f's calculations and filters in canon.
+func (f featureSet) coverage(canon featureSet) float64 {
+	if f.size() == 0 {
+		return 1
+	}
+	return float64(canon.size()) / float64(f.size())
+}
+
+// signature identifies the calculations, filters and breakdowns of f
+// regardless of their order, and its settings.
+func (f featureSet) signature() string {
+	parts := []string{f.settings}
+	for _, s := range []keySet{f.calculations, f.filters, f.breakdowns} {
+		keys := append([]string(nil), s.keys...)
+		sort.Strings(keys)
+		parts = append(parts, strings.Join(keys, "\x00"))
+	}
+	return strings.Join(parts, "\x01")
+}
@@ -0,0 +1,176 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+var (
+	p99      = discovery.Calculation{Op: "P99", Column: "duration_ms"}
+	p50      = discovery.Calculation{Op: "P50", Column: "duration_ms"}
+	count    = discovery.Calculation{Op: "COUNT"}
+	checkout = discovery.Filter{Column: "service.name", Op: "=", Value: "checkout-api", HasValue: true}
+	errors   = discovery.Filter{Column: "error", Op: "=", Value: true, HasValue: true}
+	eu       = discovery.Filter{Column: "region", Op: "=", Value: "eu", HasValue: true}
+)
+
+func TestClusters(t *testing.T) {
+	latency := discovery.DiscoveredQuery{Name: "CheckoutLatency", File: "a.go", Line: 3, Dataset: "checkout",
+		TimeRange: discovery.TimeRange{TimeRange: 7200}, Calculations: []discovery.Calculation{p99}, Filters: []discovery.Filter{checkout}}
+	resources := &discovery.DiscoveredResources{Queries: []discovery.DiscoveredQuery{
+		{Name: "Copy", File: "b.go", Line: 1, Dataset: "checkout", TimeRange: discovery.TimeRange{TimeRange: 7200},
+			Calculations: []discovery.Calculation{p99}, Filters: []discovery.Filter{checkout}},
+		latency,
+		// Inline queries can be discovered more than once
+		latency,
+		{Name: "CheckoutLatencyByEndpoint", File: "a.go", Line: 10, Dataset: "checkout",
+			Calculations: []discovery.Calculation{p99}, Filters: []discovery.Filter{checkout}, Breakdowns: []string{"endpoint"}},
+		{Name: "CheckoutLatencyEU", File: "a.go", Line: 20, Dataset: "checkout",
+			Calculations: []discovery.Calculation{p99, p50}, Filters: []discovery.Filter{checkout, eu}},
+		// Shares no calculation
+		{Name: "CheckoutErrors", File: "a.go", Line: 30, Dataset: "checkout",
+			Calculations: []discovery.Calculation{count}, Filters: []discovery.Filter{checkout}},
+		// Another dataset
+		{Name: "APILatency", File: "a.go", Line: 40, Dataset: "api",
+			Calculations: []discovery.Calculation{p99}, Filters: []discovery.Filter{checkout}},
+	}}
+
+	report := Clusters(resources, Options{})
+	assert.Equal(t, 6, report.Queries)
+	assert.Equal(t, 4, report.Clustered)
+	require.Len(t, report.Clusters, 1)
+
+	c := report.Clusters[0]
+	assert.Equal(t, "checkout", c.Dataset)
+	assert.Equal(t, "CheckoutLatency", c.Canonical.Name)
+	assert.True(t, c.Canonical.Existing)
+	assert.Equal(t, []string{"P99(duration_ms)"}, c.Canonical.Calculations)
+	assert.Equal(t, []string{"service.name = checkout-api"}, c.Canonical.Filters)
+	assert.Empty(t, c.Canonical.Breakdowns)
+
+	assert.Equal(t, []Variant{
+		{Name: "CheckoutLatency", File: "a.go", Line: 3, Similarity: 1},
+		{Name: "CheckoutLatencyByEndpoint", File: "a.go", Line: 10, Similarity: 1, Breakdowns: []string{"endpoint"}},
+		{Name: "CheckoutLatencyEU", File: "a.go", Line: 20, Similarity: 0.5,
+			Calculations: []string{"P50(duration_ms)"}, Filters: []string{"region = eu"}},
+		{Name: "Copy", File: "b.go", Line: 1, Similarity: 1, DuplicateOf: "CheckoutLatency"},
+	}, c.Variants)
+
+	// A higher threshold leaves out the EU variant
+	report = Clusters(resources, Options{Threshold: 0.9})
+	require.Len(t, report.Clusters, 1)
+	assert.Len(t, report.Clusters[0].Variants, 3)
+}
+
+func TestClusters_NewCanonical(t *testing.T) {
+	resources := &discovery.DiscoveredResources{Queries: []discovery.DiscoveredQuery{
+		{Name: "CheckoutErrors", File: "a.go", Line: 1, Dataset: "checkout", Granularity: 60,
+			Calculations: []discovery.Calculation{count}, Filters: []discovery.Filter{errors, checkout}, Breakdowns: []string{"endpoint"}},
+		{Name: "CheckoutErrorsEU", File: "a.go", Line: 9, Dataset: "checkout",
+			Calculations: []discovery.Calculation{count}, Filters: []discovery.Filter{eu, errors}, Breakdowns: []string{"endpoint", "region"}},
+	}}
+
+	report := Clusters(resources, Options{})
+	require.Len(t, report.Clusters, 1)
+
+	canon := report.Clusters[0].Canonical
+	assert.Equal(t, "CheckoutErrorsBase", canon.Name)
+	assert.False(t, canon.Existing)
+	assert.Equal(t, discovery.DiscoveredQuery{
+		Name:         "CheckoutErrorsBase",
+		Dataset:      "checkout",
+		Granularity:  60,
+		Calculations: []discovery.Calculation{count},
+		Filters:      []discovery.Filter{errors},
+		Breakdowns:   []string{"endpoint"},
+	}, canon.Query)
+
+	variants := report.Clusters[0].Variants
+	require.Len(t, variants, 2)
+	assert.Equal(t, []string{"service.name = checkout-api"}, variants[0].Filters)
+	assert.Equal(t, []string{"region = eu"}, variants[1].Filters)
+	assert.Equal(t, []string{"region"}, variants[1].Breakdowns)
+}
+
+func TestClusters_Settings(t *testing.T) {
+	query := func(name string, line int) discovery.DiscoveredQuery {
+		return discovery.DiscoveredQuery{Name: name, File: "a.go", Line: line, Dataset: "checkout",
+			TimeRange: discovery.TimeRange{TimeRange: 7200}, Calculations: []discovery.Calculation{count}, Filters: []discovery.Filter{checkout, errors}}
+	}
+	and := query("CheckoutErrors", 1)
+	or := query("CheckoutOrErrors", 2)
+	or.FilterCombination = "OR"
+	explicit := query("CheckoutErrorsAnd", 3)
+	explicit.FilterCombination = "AND"
+	top := query("CheckoutErrorsTop", 4)
+	top.Limit = 10
+	week := query("CheckoutErrorsWeek", 5)
+	week.TimeRange.TimeRange = 604800
+
+	report := Clusters(&discovery.DiscoveredResources{Queries: []discovery.DiscoveredQuery{and, or, explicit, top, week}}, Options{})
+	require.Len(t, report.Clusters, 1)
+	c := report.Clusters[0]
+	assert.Equal(t, "CheckoutErrors", c.Canonical.Name)
+	assert.Len(t, c.Variants, 4)
+
+	// The OR query selects other events, and the limit and time range tell
+	// the others apart from duplicates
+	duplicates := make(map[string]string)
+	for _, v := range c.Variants {
+		duplicates[v.Name] = v.DuplicateOf
+	}
+	assert.Equal(t, map[string]string{
+		"CheckoutErrors":     "",
+		"CheckoutErrorsAnd":  "CheckoutErrors",
+		"CheckoutErrorsTop":  "",
+		"CheckoutErrorsWeek": "",
+	}, duplicates)
+}
+
+func TestClusters_Chain(t *testing.T) {
+	// A~B and B~C, but A and C are less similar than the threshold
+	resources := &discovery.DiscoveredResources{Queries: []discovery.DiscoveredQuery{
+		{Name: "CheckoutErrorLatency", File: "a.go", Line: 1, Dataset: "checkout",
+			Calculations: []discovery.Calculation{p99}, Filters: []discovery.Filter{checkout, errors}},
+		{Name: "CheckoutLatency", File: "a.go", Line: 2, Dataset: "checkout",
+			Calculations: []discovery.Calculation{p99}, Filters: []discovery.Filter{checkout}},
+		{Name: "CheckoutLatencyEU", File: "a.go", Line: 3, Dataset: "checkout",
+			Calculations: []discovery.Calculation{p99}, Filters: []discovery.Filter{checkout, eu}},
+	}}
+
+	report := Clusters(resources, Options{Threshold: 0.6})
+	assert.Equal(t, 2, report.Clustered)
+	require.Len(t, report.Clusters, 1)
+	variants := report.Clusters[0].Variants
+	require.Len(t, variants, 2)
+	assert.Equal(t, "CheckoutErrorLatency", variants[0].Name)
+	assert.Equal(t, "CheckoutLatency", variants[1].Name)
+
+	// Every two of these share a calculation, but all three share none
+	resources = &discovery.DiscoveredResources{Queries: []discovery.DiscoveredQuery{
+		{Name: "A", File: "a.go", Line: 1, Dataset: "checkout", Calculations: []discovery.Calculation{p99, p50}},
+		{Name: "B", File: "a.go", Line: 2, Dataset: "checkout", Calculations: []discovery.Calculation{p50, count}},
+		{Name: "C", File: "a.go", Line: 3, Dataset: "checkout", Calculations: []discovery.Calculation{count, p99}},
+	}}
+	report = Clusters(resources, Options{Threshold: 0.3})
+	require.Len(t, report.Clusters, 1)
+	assert.Len(t, report.Clusters[0].Variants, 2)
+	assert.NotEmpty(t, report.Clusters[0].Canonical.Calculations)
+}
+
+func TestClusters_Empty(t *testing.T) {
+	report := Clusters(&discovery.DiscoveredResources{}, Options{})
+	assert.NotNil(t, report.Clusters)
+	assert.Zero(t, report.Queries)
+}
+
+func TestCommonPrefix(t *testing.T) {
+	assert.Equal(t, "Checkout", commonPrefix([]string{"CheckoutErrors", "CheckoutErrorRate"}))
+	assert.Equal(t, "CheckoutErrors", commonPrefix([]string{"CheckoutErrors", "CheckoutErrorsEU"}))
+	assert.Equal(t, "Latency", commonPrefix([]string{"Latency", "Errors"}))
+	assert.Equal(t, "API", commonPrefix([]string{"APILatency", "APIErrors"}))
+}
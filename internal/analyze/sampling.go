@@ -0,0 +1,221 @@
+package analyze
+
+import (
+	"fmt"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/advise"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+// Kinds of sampling advice.
+const (
+	// AdviseGap: the query duration is shorter than the frequency, so events
+	// between two windows are never evaluated
+	AdviseGap = "gap"
+
+	// AdviseResolution: the query resolves finer buckets than the trigger
+	// evaluates, so a spike lasting a bucket is averaged over the window
+	AdviseResolution = "resolution"
+
+	// AdviseGranularity: the query buckets are longer than the frequency, so
+	// consecutive evaluations compare the same partial bucket
+	AdviseGranularity = "granularity"
+
+	// AdviseAlignment: the duration is not a whole number of buckets, so
+	// each window starts in a partial bucket
+	AdviseAlignment = "alignment"
+)
+
+// minFrequency is the shortest trigger frequency, in seconds. Frequencies
+// are whole minutes.
+const minFrequency = 60
+
+// SamplingAdvice is a change to a trigger's frequency, query duration or
+// granularity.
+type SamplingAdvice struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+
+	// Frequency, Duration and Granularity are the recommended values in
+	// seconds, 0 where the current one is kept
+	Frequency   int `json:"frequency,omitempty"`
+	Duration    int `json:"duration,omitempty"`
+	Granularity int `json:"granularity,omitempty"`
+}
+
+// TriggerWindow is the effective evaluation window of a trigger: every
+// Frequency seconds, it evaluates its query over the last Duration seconds.
+type TriggerWindow struct {
+	Name  string `json:"name"`
+	Query string `json:"query,omitempty"`
+	File  string `json:"file"`
+	Line  int    `json:"line"`
+
+	// Frequency, Duration and Granularity are in seconds, Granularity 0
+	// when the query sets none
+	Frequency   int `json:"frequency"`
+	Duration    int `json:"duration"`
+	Granularity int `json:"granularity,omitempty"`
+
+	// Evaluations is the number of evaluations that see each event, 0 when
+	// there is a gap
+	Evaluations int `json:"evaluations"`
+
+	// Gap is the number of seconds of each interval no evaluation sees
+	Gap int `json:"gap,omitempty"`
+
+	Advice []SamplingAdvice `json:"advice"`
+}
+
+// SamplingReport is the sampling advice for a set of triggers.
+type SamplingReport struct {
+	Triggers []TriggerWindow `json:"triggers"`
+
+	// Advised is the number of triggers with advice
+	Advised int `json:"advised"`
+}
+
+// Sampling computes the evaluation window of each trigger of resources and
+// advises on frequency, duration and granularity combinations that miss
+// spikes: a duration shorter than the frequency, a granularity finer than
+// the frequency, whose buckets the trigger never compares on their own, or
+// coarser than it, and a duration that is not a whole number of buckets.
+// Each piece of advice carries the recommended values, which stay within
+// the durations Honeycomb accepts (see trigger.QueryWindow). Disabled
+// triggers, and triggers without a frequency or a query with a relative time
+// range, are left out.
+func Sampling(resources *discovery.DiscoveredResources) *SamplingReport {
+	report := &SamplingReport{Triggers: []TriggerWindow{}}
+	for _, t := range resources.Triggers {
+		q := t.Query(resources.Queries)
+		if t.Disabled || q == nil || t.FrequencySeconds <= 0 || q.TimeRange.TimeRange <= 0 {
+			continue
+		}
+		w := TriggerWindow{
+			Name:        t.Name,
+			Query:       t.QueryRef,
+			File:        t.File,
+			Line:        t.Line,
+			Frequency:   t.FrequencySeconds,
+			Duration:    q.TimeRange.TimeRange,
+			Granularity: q.Granularity,
+			Advice:      []SamplingAdvice{},
+		}
+		if w.Duration < w.Frequency {
+			w.Gap = w.Frequency - w.Duration
+		} else {
+			w.Evaluations = w.Duration / w.Frequency
+		}
+		w.Advice = samplingAdvice(w)
+		if len(w.Advice) > 0 {
+			report.Advised++
+		}
+		report.Triggers = append(report.Triggers, w)
+	}
+	return report
+}
+
+// samplingAdvice returns the advice for a trigger window.
+func samplingAdvice(w TriggerWindow) []SamplingAdvice {
+	var advice []SamplingAdvice
+	freq, duration, granularity := w.Frequency, w.Duration, w.Granularity
+
+	if duration < freq {
+		advice = append(advice, SamplingAdvice{
+			Kind: AdviseGap,
+			Message: fmt.Sprintf("the %s duration leaves %s of every %s interval unevaluated, so spikes between windows are missed; use TimeRange: %s",
+				advise.FormatSeconds(duration), advise.FormatSeconds(freq-duration), advise.FormatSeconds(freq), timeRangeExpr(freq)),
+			Duration: freq,
+		})
+		duration = freq
+	}
+
+	switch {
+	case granularity <= 0:
+
+	case granularity < freq:
+		// Evaluate as often as the query resolves, or resolve no finer than
+		// the trigger evaluates
+		every := max((granularity+minFrequency-1)/minFrequency*minFrequency, minFrequency)
+		if every >= freq {
+			advice = append(advice, SamplingAdvice{
+				Kind: AdviseResolution,
+				Message: fmt.Sprintf("the query resolves %s but the trigger evaluates every %s, the shortest frequency, over %s; use Granularity: %d",
+					advise.FormatSeconds(granularity), advise.FormatSeconds(freq), advise.FormatSeconds(duration), freq),
+				Granularity: freq,
+			})
+			break
+		}
+		shortest, longest := trigger.QueryWindow(every)
+		fitted := min(max(duration/every*every, shortest), longest)
+		advice = append(advice, SamplingAdvice{
+			Kind: AdviseResolution,
+			Message: fmt.Sprintf("the query resolves %s but the trigger evaluates every %s over %s, so a spike lasting %s is averaged over the window and can be missed; use Frequency: %s with TimeRange: %s, or Granularity: %d if %s resolution is enough",
+				advise.FormatSeconds(granularity), advise.FormatSeconds(freq), advise.FormatSeconds(duration), advise.FormatSeconds(granularity),
+				frequencyExpr(every), timeRangeExpr(fitted), freq, advise.FormatSeconds(freq)),
+			Frequency: every,
+			Duration:  fitted,
+		})
+
+	case granularity > freq:
+		suggested := gcd(duration, freq)
+		advice = append(advice, SamplingAdvice{
+			Kind: AdviseGranularity,
+			Message: fmt.Sprintf("the %s buckets are longer than the %s frequency, so consecutive evaluations compare the same partial bucket and a spike shows up late; use Granularity: %d",
+				advise.FormatSeconds(granularity), advise.FormatSeconds(freq), suggested),
+			Granularity: suggested,
+		})
+
+	case duration%granularity != 0:
+		_, longest := trigger.QueryWindow(freq)
+		if whole := (duration + granularity - 1) / granularity * granularity; whole <= longest {
+			advice = append(advice, SamplingAdvice{
+				Kind: AdviseAlignment,
+				Message: fmt.Sprintf("the %s duration is not a whole number of %s buckets, so each window starts in a partial bucket; use TimeRange: %s",
+					advise.FormatSeconds(duration), advise.FormatSeconds(granularity), timeRangeExpr(whole)),
+				Duration: whole,
+			})
+			break
+		}
+		suggested := gcd(duration, granularity)
+		advice = append(advice, SamplingAdvice{
+			Kind: AdviseAlignment,
+			Message: fmt.Sprintf("the %s duration is not a whole number of %s buckets, so each window starts in a partial bucket; use Granularity: %d",
+				advise.FormatSeconds(duration), advise.FormatSeconds(granularity), suggested),
+			Granularity: suggested,
+		})
+	}
+	return advice
+}
+
+// frequencyExpr returns the trigger package call for a frequency in seconds,
+// e.g. "trigger.Minutes(5)".
+func frequencyExpr(seconds int) string {
+	if seconds%60 == 0 {
+		return fmt.Sprintf("trigger.Minutes(%d)", seconds/60)
+	}
+	return fmt.Sprintf("trigger.Seconds(%d)", seconds)
+}
+
+// timeRangeExpr returns the query package call for a relative time range in
+// seconds, e.g. "query.Minutes(15)".
+func timeRangeExpr(seconds int) string {
+	for _, unit := range []struct {
+		size int
+		name string
+	}{{86400, "Days"}, {3600, "Hours"}, {60, "Minutes"}} {
+		if seconds%unit.size == 0 {
+			return fmt.Sprintf("query.%s(%d)", unit.name, seconds/unit.size)
+		}
+	}
+	return fmt.Sprintf("query.Seconds(%d)", seconds)
+}
+
+// gcd returns the greatest common divisor of a and b.
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,94 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+func sampledTrigger(name string, frequency, duration, granularity int) discovery.DiscoveredTrigger {
+	return discovery.DiscoveredTrigger{
+		Name: name, File: "triggers.go", Line: 1, FrequencySeconds: frequency,
+		InlineQuery: &discovery.DiscoveredQuery{
+			Dataset: "checkout", TimeRange: discovery.TimeRange{TimeRange: duration}, Granularity: granularity,
+		},
+	}
+}
+
+func TestSampling(t *testing.T) {
+	resources := &discovery.DiscoveredResources{Triggers: []discovery.DiscoveredTrigger{
+		sampledTrigger("Fine", 300, 900, 0),
+		sampledTrigger("Gap", 900, 300, 0),
+		sampledTrigger("Resolution", 300, 900, 60),
+		sampledTrigger("Shortest", 60, 120, 30),
+		sampledTrigger("Coarse", 300, 900, 600),
+		sampledTrigger("Partial", 300, 700, 300),
+		sampledTrigger("Unaligned", 300, 1200, 300),
+	}}
+	disabled := sampledTrigger("Disabled", 900, 300, 0)
+	disabled.Disabled = true
+	resources.Triggers = append(resources.Triggers, disabled)
+
+	report := Sampling(resources)
+	require.Len(t, report.Triggers, 7)
+	assert.Equal(t, 5, report.Advised)
+
+	fine := report.Triggers[0]
+	assert.Equal(t, 3, fine.Evaluations)
+	assert.Empty(t, fine.Advice)
+
+	gap := report.Triggers[1]
+	assert.Equal(t, 0, gap.Evaluations)
+	assert.Equal(t, 600, gap.Gap)
+	require.Len(t, gap.Advice, 1)
+	assert.Equal(t, SamplingAdvice{
+		Kind:     AdviseGap,
+		Message:  "the 5m duration leaves 10m of every 15m interval unevaluated, so spikes between windows are missed; use TimeRange: query.Minutes(15)",
+		Duration: 900,
+	}, gap.Advice[0])
+
+	resolution := report.Triggers[2]
+	require.Len(t, resolution.Advice, 1)
+	assert.Equal(t, SamplingAdvice{
+		Kind:      AdviseResolution,
+		Message:   "the query resolves 1m but the trigger evaluates every 5m over 15m, so a spike lasting 1m is averaged over the window and can be missed; use Frequency: trigger.Minutes(1) with TimeRange: query.Minutes(4), or Granularity: 300 if 5m resolution is enough",
+		Frequency: 60,
+		Duration:  240,
+	}, resolution.Advice[0])
+
+	shortest := report.Triggers[3]
+	require.Len(t, shortest.Advice, 1)
+	assert.Equal(t, AdviseResolution, shortest.Advice[0].Kind)
+	assert.Equal(t, 60, shortest.Advice[0].Granularity)
+	assert.Zero(t, shortest.Advice[0].Frequency)
+
+	coarse := report.Triggers[4]
+	require.Len(t, coarse.Advice, 1)
+	assert.Equal(t, AdviseGranularity, coarse.Advice[0].Kind)
+	assert.Equal(t, 300, coarse.Advice[0].Granularity)
+
+	partial := report.Triggers[5]
+	require.Len(t, partial.Advice, 1)
+	assert.Equal(t, AdviseAlignment, partial.Advice[0].Kind)
+	assert.Equal(t, 900, partial.Advice[0].Duration)
+	assert.Contains(t, partial.Advice[0].Message, "use TimeRange: query.Minutes(15)")
+
+	assert.Empty(t, report.Triggers[6].Advice)
+}
+
+func TestSampling_AlignmentOverLongest(t *testing.T) {
+	// A duration over 4 times the frequency (WHC055) cannot be rounded up
+	resources := &discovery.DiscoveredResources{Triggers: []discovery.DiscoveredTrigger{
+		sampledTrigger("Edge", 300, 1250, 300),
+	}}
+
+	report := Sampling(resources)
+	require.Len(t, report.Triggers, 1)
+	require.Len(t, report.Triggers[0].Advice, 1)
+	advice := report.Triggers[0].Advice[0]
+	assert.Equal(t, AdviseAlignment, advice.Kind)
+	assert.Equal(t, 50, advice.Granularity)
+}
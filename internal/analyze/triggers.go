@@ -0,0 +1,342 @@
+package analyze
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// DefaultTolerance is the relative difference from which two trigger
+// thresholds are no longer near-identical.
+const DefaultTolerance = 0.1
+
+// TriggerOptions configure the trigger analysis.
+type TriggerOptions struct {
+	// Tolerance is the largest difference between two thresholds, relative
+	// to the larger one, for which they count as near-identical (default:
+	// DefaultTolerance)
+	Tolerance float64
+}
+
+// Ways to reduce a group of overlapping triggers.
+const (
+	// SuggestDuplicate: the triggers evaluate the same query; keep the most
+	// sensitive one and remove the others
+	SuggestDuplicate = "duplicate"
+
+	// SuggestBreakdown: the triggers differ only by the value of one column;
+	// replace them with one trigger grouped by that column
+	SuggestBreakdown = "breakdown"
+
+	// SuggestThreshold: the triggers watch overlapping queries; align their
+	// thresholds on the most sensitive one
+	SuggestThreshold = "threshold"
+)
+
+// TriggerMember is a trigger of a group, described by what its query adds
+// to what the group shares.
+type TriggerMember struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+
+	Op        string  `json:"op"`
+	Threshold float64 `json:"threshold"`
+
+	// Filters are the filters of the trigger's query the other members do
+	// not all have
+	Filters []string `json:"filters,omitempty"`
+}
+
+// TriggerGroup is a set of triggers on the same dataset and calculation,
+// firing in the same direction at near-identical thresholds.
+type TriggerGroup struct {
+	Dataset     string `json:"dataset"`
+	Calculation string `json:"calculation"`
+
+	// Filters are the filters all members share
+	Filters []string `json:"filters"`
+
+	Members []TriggerMember `json:"members"`
+
+	// Suggestion is SuggestDuplicate, SuggestBreakdown or SuggestThreshold
+	Suggestion string `json:"suggestion"`
+
+	// Keep is the most sensitive member, whose threshold the suggestion uses
+	Keep string `json:"keep"`
+
+	// Op and Threshold are the suggested threshold
+	Op        string  `json:"op"`
+	Threshold float64 `json:"threshold"`
+
+	// Column is the column a SuggestBreakdown trigger is grouped by
+	Column string `json:"column,omitempty"`
+
+	// Name is the suggested Go variable name of a SuggestBreakdown trigger
+	Name string `json:"name,omitempty"`
+
+	// Query is the query of the consolidated trigger of a SuggestBreakdown
+	// group: the shared filters, the column's values and a breakdown by it
+	Query *discovery.DiscoveredQuery `json:"-"`
+
+	// Diff is the change that applies the suggestion, left for the caller to
+	// render
+	Diff []string `json:"diff,omitempty"`
+}
+
+// TriggerReport is the consolidation plan for a set of triggers.
+type TriggerReport struct {
+	Groups []TriggerGroup `json:"groups"`
+
+	// Triggers is the number of triggers compared
+	Triggers int `json:"triggers"`
+
+	// Grouped is the number of triggers in a group
+	Grouped int `json:"grouped"`
+}
+
+// analyzedTrigger is a trigger with its resolved query.
+type analyzedTrigger struct {
+	trigger discovery.DiscoveredTrigger
+	query   discovery.DiscoveredQuery
+	filters keySet
+}
+
+// Triggers groups the triggers of resources that alert on the same dataset
+// and calculation, in the same direction, at thresholds within
+// opts.Tolerance of each other, and suggests how to consolidate each group.
+// Disabled triggers and triggers whose query cannot be resolved are left
+// out. Groups are ordered by the position of their first member.
+func Triggers(resources *discovery.DiscoveredResources, opts TriggerOptions) *TriggerReport {
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = DefaultTolerance
+	}
+
+	buckets := make(map[string][]analyzedTrigger)
+	var keys []string
+	report := &TriggerReport{Groups: []TriggerGroup{}}
+	for _, t := range resources.Triggers {
+		q := t.Query(resources.Queries)
+		if t.Disabled || q == nil || len(q.Calculations) == 0 || direction(t.ThresholdOp) == "" {
+			continue
+		}
+		report.Triggers++
+
+		var filters []string
+		for _, f := range q.Filters {
+			filters = append(filters, filterKey(f))
+		}
+		key := strings.Join([]string{cmp.Or(q.Dataset, t.Dataset), calculationKey(q.Calculations[0]), direction(t.ThresholdOp)}, "\x00")
+		if _, ok := buckets[key]; !ok {
+			keys = append(keys, key)
+		}
+		buckets[key] = append(buckets[key], analyzedTrigger{trigger: t, query: *q, filters: newKeySet(filters)})
+	}
+
+	for _, key := range keys {
+		bucket := buckets[key]
+		sort.SliceStable(bucket, func(i, j int) bool {
+			return bucket[i].trigger.ThresholdValue < bucket[j].trigger.ThresholdValue
+		})
+
+		// Chain thresholds that are near-identical to their neighbor
+		start := 0
+		for i := 1; i <= len(bucket); i++ {
+			if i < len(bucket) && near(bucket[i-1].trigger.ThresholdValue, bucket[i].trigger.ThresholdValue, opts.Tolerance) {
+				continue
+			}
+			if i-start > 1 {
+				report.Groups = append(report.Groups, newTriggerGroup(bucket[start:i]))
+				report.Grouped += i - start
+			}
+			start = i
+		}
+	}
+
+	sort.SliceStable(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i].Members[0], report.Groups[j].Members[0]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return report
+}
+
+// newTriggerGroup describes overlapping triggers and how to consolidate them.
+func newTriggerGroup(triggers []analyzedTrigger) TriggerGroup {
+	sort.SliceStable(triggers, func(i, j int) bool {
+		a, b := triggers[i].trigger, triggers[j].trigger
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+
+	shared := triggers[0].filters
+	for _, t := range triggers[1:] {
+		shared = shared.intersect(t.filters)
+	}
+
+	first := triggers[0]
+	g := TriggerGroup{
+		Dataset:     cmp.Or(first.query.Dataset, first.trigger.Dataset),
+		Calculation: calculationKey(first.query.Calculations[0]),
+		Filters:     shared.keys,
+	}
+
+	keep := first
+	for _, t := range triggers {
+		g.Members = append(g.Members, TriggerMember{
+			Name:      t.trigger.Name,
+			File:      t.trigger.File,
+			Line:      t.trigger.Line,
+			Op:        t.trigger.ThresholdOp,
+			Threshold: t.trigger.ThresholdValue,
+			Filters:   t.filters.minus(shared),
+		})
+		if moreSensitive(t.trigger, keep.trigger) {
+			keep = t
+		}
+	}
+	g.Keep = keep.trigger.Name
+	g.Op = keep.trigger.ThresholdOp
+	g.Threshold = keep.trigger.ThresholdValue
+
+	switch column, values, ok := splitColumn(triggers, shared); {
+	case sameQueries(triggers):
+		g.Suggestion = SuggestDuplicate
+	case ok:
+		g.Suggestion = SuggestBreakdown
+		g.Column = column
+		g.Query = consolidatedQuery(keep.query, shared, column, values)
+
+		names := make([]string, len(g.Members))
+		for i, m := range g.Members {
+			names[i] = m.Name
+		}
+		g.Name = commonPrefix(names) + "By" + exportedName(column)
+	default:
+		g.Suggestion = SuggestThreshold
+	}
+	return g
+}
+
+// sameQueries reports whether the triggers' queries have the same filters
+// and breakdowns.
+func sameQueries(triggers []analyzedTrigger) bool {
+	first := newFeatureSet(triggers[0].query).signature()
+	for _, t := range triggers[1:] {
+		if newFeatureSet(t.query).signature() != first {
+			return false
+		}
+	}
+	return true
+}
+
+// splitColumn returns the column and values when each trigger adds exactly
+// one equality filter on the same column to the shared filters, with a value
+// of its own. Queries combining their filters with OR cannot be split: the
+// in filter replacing the equality filters would match every event.
+func splitColumn(triggers []analyzedTrigger, shared keySet) (string, []any, bool) {
+	var column string
+	var values []any
+	seen := make(map[string]bool)
+	for _, t := range triggers {
+		if !strings.EqualFold(cmp.Or(t.query.FilterCombination, "AND"), "AND") {
+			return "", nil, false
+		}
+		var extra []discovery.Filter
+		for _, f := range t.query.Filters {
+			if !shared.has(filterKey(f)) {
+				extra = append(extra, f)
+			}
+		}
+		if len(extra) != 1 || extra[0].Op != "=" || !extra[0].HasValue {
+			return "", nil, false
+		}
+		f := extra[0]
+		if column == "" {
+			column = f.Column
+		}
+		value := fmt.Sprint(f.Value)
+		if f.Column != column || seen[value] || len(t.query.Breakdowns) > 0 {
+			return "", nil, false
+		}
+		seen[value] = true
+		values = append(values, f.Value)
+	}
+	return column, values, true
+}
+
+// consolidatedQuery returns base reduced to the shared filters, with an in
+// filter on the column's values and a breakdown by it.
+func consolidatedQuery(base discovery.DiscoveredQuery, shared keySet, column string, values []any) *discovery.DiscoveredQuery {
+	q := discovery.DiscoveredQuery{
+		Package:           base.Package,
+		Dataset:           base.Dataset,
+		TimeRange:         base.TimeRange,
+		Granularity:       base.Granularity,
+		FilterCombination: base.FilterCombination,
+		Calculations:      base.Calculations,
+		Breakdowns:        []string{column},
+	}
+	for _, f := range base.Filters {
+		if shared.has(filterKey(f)) {
+			q.Filters = append(q.Filters, f)
+		}
+	}
+	q.Filters = append(q.Filters, discovery.Filter{Column: column, Op: "in", Value: values, HasValue: true})
+	return &q
+}
+
+// exportedName converts a column to an exported Go name part, e.g.
+// service.name to ServiceName.
+func exportedName(column string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(column, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
+
+// direction returns "above" for > and >= thresholds, "below" for < and <=,
+// and "" otherwise.
+func direction(op string) string {
+	switch op {
+	case ">", ">=":
+		return "above"
+	case "<", "<=":
+		return "below"
+	}
+	return ""
+}
+
+// moreSensitive reports whether a fires before b: at a lower threshold for
+// triggers above it, a higher one below it, or inclusively at the same one.
+func moreSensitive(a, b discovery.DiscoveredTrigger) bool {
+	if a.ThresholdValue != b.ThresholdValue {
+		if direction(a.ThresholdOp) == "above" {
+			return a.ThresholdValue < b.ThresholdValue
+		}
+		return a.ThresholdValue > b.ThresholdValue
+	}
+	return strings.HasSuffix(a.ThresholdOp, "=") && !strings.HasSuffix(b.ThresholdOp, "=")
+}
+
+// near reports whether a and b differ by at most tolerance relative to the
+// larger of them.
+func near(a, b, tolerance float64) bool {
+	largest := math.Max(math.Abs(a), math.Abs(b))
+	if largest == 0 {
+		return true
+	}
+	return math.Abs(a-b) <= tolerance*largest
+}
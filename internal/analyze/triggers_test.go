@@ -0,0 +1,118 @@
+package analyze
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+func latencyTrigger(name string, line int, op string, threshold float64, filters ...discovery.Filter) discovery.DiscoveredTrigger {
+	return discovery.DiscoveredTrigger{
+		Name: name, File: "triggers.go", Line: line, Dataset: "checkout",
+		ThresholdOp: op, ThresholdValue: threshold,
+		InlineQuery: &discovery.DiscoveredQuery{
+			Dataset: "checkout", TimeRange: discovery.TimeRange{TimeRange: 900},
+			Calculations: []discovery.Calculation{p99}, Filters: filters,
+		},
+	}
+}
+
+func service(name string) discovery.Filter {
+	return discovery.Filter{Column: "service.name", Op: "=", Value: name, HasValue: true}
+}
+
+func TestTriggers_Breakdown(t *testing.T) {
+	resources := &discovery.DiscoveredResources{Triggers: []discovery.DiscoveredTrigger{
+		latencyTrigger("CartSlow", 10, ">", 520, eu, service("cart")),
+		latencyTrigger("CheckoutSlow", 1, ">", 500, eu, service("checkout")),
+		// Too far from the others
+		latencyTrigger("SearchSlow", 20, ">", 900, eu, service("search")),
+		// Fires in the other direction
+		latencyTrigger("CartFast", 30, "<", 510, eu, service("cart")),
+	}}
+
+	report := Triggers(resources, TriggerOptions{})
+	assert.Equal(t, 4, report.Triggers)
+	assert.Equal(t, 2, report.Grouped)
+	require.Len(t, report.Groups, 1)
+
+	g := report.Groups[0]
+	assert.Equal(t, "checkout", g.Dataset)
+	assert.Equal(t, "P99(duration_ms)", g.Calculation)
+	assert.Equal(t, []string{"region = eu"}, g.Filters)
+	assert.Equal(t, SuggestBreakdown, g.Suggestion)
+	assert.Equal(t, "service.name", g.Column)
+	assert.Equal(t, "CheckoutSlowByServiceName", g.Name)
+	assert.Equal(t, "CheckoutSlow", g.Keep)
+	assert.Equal(t, ">", g.Op)
+	assert.Equal(t, 500.0, g.Threshold)
+	assert.Equal(t, []TriggerMember{
+		{Name: "CheckoutSlow", File: "triggers.go", Line: 1, Op: ">", Threshold: 500, Filters: []string{"service.name = checkout"}},
+		{Name: "CartSlow", File: "triggers.go", Line: 10, Op: ">", Threshold: 520, Filters: []string{"service.name = cart"}},
+	}, g.Members)
+
+	require.NotNil(t, g.Query)
+	assert.Equal(t, []string{"service.name"}, g.Query.Breakdowns)
+	require.Len(t, g.Query.Filters, 2)
+	assert.Equal(t, discovery.Filter{Column: "service.name", Op: "in", Value: []any{"checkout", "cart"}, HasValue: true}, g.Query.Filters[1])
+}
+
+func TestTriggers_BreakdownOr(t *testing.T) {
+	cart := latencyTrigger("CartSlow", 10, ">", 520, eu, service("cart"))
+	checkout := latencyTrigger("CheckoutSlow", 1, ">", 500, eu, service("checkout"))
+	cart.InlineQuery.FilterCombination = "OR"
+	checkout.InlineQuery.FilterCombination = "OR"
+	resources := &discovery.DiscoveredResources{Triggers: []discovery.DiscoveredTrigger{cart, checkout}}
+
+	report := Triggers(resources, TriggerOptions{})
+	require.Len(t, report.Groups, 1)
+	assert.Equal(t, SuggestThreshold, report.Groups[0].Suggestion)
+	assert.Nil(t, report.Groups[0].Query)
+}
+
+func TestTriggers_Duplicate(t *testing.T) {
+	errorsQuery := discovery.DiscoveredQuery{Name: "CheckoutErrors", Dataset: "checkout",
+		Calculations: []discovery.Calculation{count}, Filters: []discovery.Filter{checkout}}
+	resources := &discovery.DiscoveredResources{
+		Queries: []discovery.DiscoveredQuery{errorsQuery},
+		Triggers: []discovery.DiscoveredTrigger{
+			{Name: "ErrorsPage", File: "a.go", Line: 1, QueryRef: "CheckoutErrors", ThresholdOp: ">", ThresholdValue: 100},
+			{Name: "ErrorsSlack", File: "a.go", Line: 9, QueryRef: "CheckoutErrors", ThresholdOp: ">=", ThresholdValue: 100},
+			{Name: "ErrorsOff", File: "a.go", Line: 20, QueryRef: "CheckoutErrors", ThresholdOp: ">", ThresholdValue: 100, Disabled: true},
+		},
+	}
+
+	report := Triggers(resources, TriggerOptions{})
+	assert.Equal(t, 2, report.Triggers)
+	require.Len(t, report.Groups, 1)
+	assert.Equal(t, SuggestDuplicate, report.Groups[0].Suggestion)
+	assert.Equal(t, "ErrorsSlack", report.Groups[0].Keep, "inclusive thresholds fire first")
+	assert.Nil(t, report.Groups[0].Query)
+}
+
+func TestTriggers_Threshold(t *testing.T) {
+	resources := &discovery.DiscoveredResources{Triggers: []discovery.DiscoveredTrigger{
+		latencyTrigger("EUSlow", 1, "<", 95, eu),
+		latencyTrigger("CheckoutSlow", 5, "<", 99, checkout),
+	}}
+
+	report := Triggers(resources, TriggerOptions{})
+	require.Len(t, report.Groups, 1)
+	g := report.Groups[0]
+	assert.Equal(t, SuggestThreshold, g.Suggestion)
+	assert.Equal(t, "CheckoutSlow", g.Keep)
+	assert.Empty(t, g.Filters)
+
+	report = Triggers(resources, TriggerOptions{Tolerance: 0.01})
+	assert.Empty(t, report.Groups)
+}
+
+func TestNear(t *testing.T) {
+	assert.True(t, near(0, 0, 0.1))
+	assert.True(t, near(100, 110, 0.1))
+	assert.False(t, near(100, 112, 0.1))
+	assert.True(t, near(-100, -95, 0.1))
+}
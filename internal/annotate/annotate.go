@@ -0,0 +1,104 @@
+// Package annotate marks resources applied by wetwire-honeycomb as managed, so
+// that people editing them in the Honeycomb UI know their changes will be
+// overwritten and tools can tell managed resources from unmanaged ones.
+//
+// The annotation is a footer appended to the resource description:
+//
+//	Managed by wetwire-honeycomb (source: github.com/acme/observability).
+//	Changes made in the Honeycomb UI will be overwritten.
+package annotate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Marker starts every managed annotation.
+const Marker = "Managed by wetwire-honeycomb"
+
+// separator divides the user description from the annotation footer.
+const separator = "\n\n---\n"
+
+// Footer returns the annotation for resources applied from source.
+func Footer(source string) string {
+	footer := Marker
+	if source != "" {
+		footer += fmt.Sprintf(" (source: %s)", source)
+	}
+	return footer + ".\nChanges made in the Honeycomb UI will be overwritten."
+}
+
+// Description returns description with the annotation for source appended,
+// replacing any existing annotation.
+func Description(description, source string) string {
+	description = Strip(description)
+	if description == "" {
+		return Footer(source)
+	}
+	return description + separator + Footer(source)
+}
+
+// Strip removes the annotation from a description.
+func Strip(description string) string {
+	if strings.HasPrefix(description, Marker) {
+		return ""
+	}
+	if i := strings.LastIndex(description, separator+Marker); i >= 0 {
+		return description[:i]
+	}
+	return description
+}
+
+// Source returns the source recorded in a description's annotation and whether
+// the description is annotated at all.
+func Source(description string) (string, bool) {
+	i := strings.LastIndex(description, Marker)
+	if i < 0 || (i > 0 && !strings.HasSuffix(description[:i], separator)) {
+		return "", false
+	}
+
+	rest := description[i+len(Marker):]
+	if source, ok := strings.CutPrefix(rest, " (source: "); ok {
+		if end := strings.Index(source, ")."); end >= 0 {
+			return source[:end], true
+		}
+	}
+	return "", true
+}
+
+// Resource returns a copy of a resource JSON object with its "description"
+// field annotated as managed from source.
+func Resource(body json.RawMessage, source string) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("parse resource: %w", err)
+	}
+
+	var description string
+	if raw, ok := fields["description"]; ok {
+		if err := json.Unmarshal(raw, &description); err != nil {
+			return nil, fmt.Errorf("parse description: %w", err)
+		}
+	}
+
+	annotated, err := json.Marshal(Description(description, source))
+	if err != nil {
+		return nil, err
+	}
+	fields["description"] = annotated
+	return json.Marshal(fields)
+}
+
+// IsManaged reports whether a resource JSON object carries the managed
+// annotation in its description.
+func IsManaged(body json.RawMessage) bool {
+	var meta struct {
+		Description string `json:"description"`
+	}
+	if json.Unmarshal(body, &meta) != nil {
+		return false
+	}
+	_, ok := Source(meta.Description)
+	return ok
+}
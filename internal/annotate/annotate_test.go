@@ -0,0 +1,65 @@
+package annotate
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDescription(t *testing.T) {
+	got := Description("Alerts on p99 latency", "github.com/acme/obs")
+	assert.Equal(t, "Alerts on p99 latency\n\n---\nManaged by wetwire-honeycomb (source: github.com/acme/obs).\nChanges made in the Honeycomb UI will be overwritten.", got)
+
+	// Re-annotating replaces the footer instead of stacking another one
+	assert.Equal(t, Description("Alerts on p99 latency", "other"), Description(got, "other"))
+
+	assert.Equal(t, Footer("x"), Description("", "x"))
+	assert.Equal(t, "Managed by wetwire-honeycomb.\nChanges made in the Honeycomb UI will be overwritten.", Footer(""))
+}
+
+func TestStrip(t *testing.T) {
+	assert.Equal(t, "Alerts", Strip(Description("Alerts", "repo")))
+	assert.Equal(t, "", Strip(Footer("repo")))
+	assert.Equal(t, "Plain description", Strip("Plain description"))
+	assert.Equal(t, "Mentions Managed by wetwire-honeycomb inline", Strip("Mentions Managed by wetwire-honeycomb inline"))
+}
+
+func TestSource(t *testing.T) {
+	source, ok := Source(Description("Alerts", "github.com/acme/obs"))
+	assert.True(t, ok)
+	assert.Equal(t, "github.com/acme/obs", source)
+
+	source, ok = Source(Footer(""))
+	assert.True(t, ok)
+	assert.Empty(t, source)
+
+	_, ok = Source("Mentions Managed by wetwire-honeycomb inline")
+	assert.False(t, ok)
+}
+
+func TestResource(t *testing.T) {
+	body, err := Resource(json.RawMessage(`{"name":"High Latency","description":"p99"}`), "repo")
+	require.NoError(t, err)
+
+	var fields map[string]string
+	require.NoError(t, json.Unmarshal(body, &fields))
+	assert.Equal(t, "High Latency", fields["name"])
+	assert.Equal(t, Description("p99", "repo"), fields["description"])
+	assert.True(t, IsManaged(body))
+
+	// Resources without a description get the footer alone
+	body, err = Resource(json.RawMessage(`{"name":"Board"}`), "repo")
+	require.NoError(t, err)
+	assert.True(t, IsManaged(body))
+
+	_, err = Resource(json.RawMessage(`[`), "repo")
+	assert.Error(t, err)
+}
+
+func TestIsManaged(t *testing.T) {
+	assert.False(t, IsManaged(json.RawMessage(`{"name":"Manual board","description":"made by hand"}`)))
+	assert.False(t, IsManaged(json.RawMessage(`{"name":"No description"}`)))
+	assert.False(t, IsManaged(json.RawMessage(`not json`)))
+}
@@ -0,0 +1,174 @@
+// Package apiversion rewrites build output for a variant of the Honeycomb
+// Query API, for proxies and tooling that only accept an older payload
+// shape: fields the variant does not know are dropped and calculation
+// operators it spells differently are renamed.
+//
+// The rewrite is optional: build applies it with the APIVersion option of the
+// domain package. The default variant, Latest, leaves output unchanged. A
+// variant is only added with the Honeycomb documentation of each difference
+// it makes; there is none yet.
+package apiversion
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+)
+
+// Latest is the current Query API, and the default.
+const Latest = "latest"
+
+// Version is a variant of the Query API that build output can target.
+type Version struct {
+	// Name selects the variant, e.g. in build --api-version
+	Name string
+
+	// Description says which consumers the variant is for
+	Description string
+
+	// OmitFields are query fields the variant does not accept
+	OmitFields []string
+
+	// Ops maps calculation operators to the variant's spelling
+	Ops map[string]string
+
+	// UnsupportedOps are calculation operators the variant does not have.
+	// Rewriting a query that uses one fails.
+	UnsupportedOps []string
+}
+
+// versions are the variants build can target, by name.
+var versions = map[string]Version{
+	Latest: {
+		Name:        Latest,
+		Description: "current Query API; output is unchanged",
+	},
+}
+
+// Names returns the names of the known variants, sorted.
+func Names() []string {
+	names := make([]string, 0, len(versions))
+	for name := range versions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup returns the variant with the given name; an empty name is Latest.
+func Lookup(name string) (Version, error) {
+	if name == "" {
+		name = Latest
+	}
+	v, ok := versions[name]
+	if !ok {
+		return Version{}, fmt.Errorf("unknown API version %q: expected one of %v", name, Names())
+	}
+	return v, nil
+}
+
+// Query rewrites a decoded query in place for the variant.
+func (v Version) Query(q map[string]any) error {
+	for _, field := range v.OmitFields {
+		delete(q, field)
+	}
+
+	for _, field := range []string{"calculations", "orders"} {
+		items, _ := q[field].([]any)
+		for _, item := range items {
+			m, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			op, _ := m["op"].(string)
+			if slices.Contains(v.UnsupportedOps, op) {
+				return fmt.Errorf("calculation %s is not supported by API version %s", op, v.Name)
+			}
+			if renamed, ok := v.Ops[op]; ok {
+				m["op"] = renamed
+			}
+		}
+	}
+	return nil
+}
+
+// Resource rewrites a decoded resource in place, including the queries it
+// embeds. resourceType is a build output key such as "boards", or its
+// singular form.
+func (v Version) Resource(resourceType string, r map[string]any) error {
+	switch resourceType {
+	case "queries", "query":
+		return v.Query(r)
+	case "boards", "board":
+		return v.panels(r)
+	case "slos", "slo":
+		if sli, ok := r["sli"].(map[string]any); ok {
+			if err := v.embedded(sli, "good_events"); err != nil {
+				return err
+			}
+			return v.embedded(sli, "total_events")
+		}
+	case "triggers", "trigger":
+		return v.embedded(r, "query")
+	}
+	return nil
+}
+
+// panels rewrites the queries of the panels of a board or section.
+func (v Version) panels(parent map[string]any) error {
+	panels, _ := parent["panels"].([]any)
+	for _, p := range panels {
+		panel, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		if err := v.embedded(panel, "query"); err != nil {
+			return err
+		}
+		if err := v.panels(panel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// embedded rewrites the query in field of parent, if there is one.
+func (v Version) embedded(parent map[string]any, field string) error {
+	if q, ok := parent[field].(map[string]any); ok {
+		return v.Query(q)
+	}
+	return nil
+}
+
+// JSON returns the rewritten JSON of one resource of resourceType.
+func (v Version) JSON(resourceType string, data []byte) ([]byte, error) {
+	var r map[string]any
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parse JSON: %w", err)
+	}
+	if err := v.Resource(resourceType, r); err != nil {
+		return nil, err
+	}
+	return json.Marshal(r)
+}
+
+// Output rewrites every resource of a build output in place. Latest leaves
+// the output untouched.
+func (v Version) Output(output *honeycomb.Manifest) error {
+	if v.Name == Latest {
+		return nil
+	}
+	for resourceType, resources := range output.Resources() {
+		for name, data := range resources {
+			rewritten, err := v.JSON(resourceType, data)
+			if err != nil {
+				return fmt.Errorf("%s %s: %w", resourceType, name, err)
+			}
+			resources[name] = rewritten
+		}
+	}
+	return nil
+}
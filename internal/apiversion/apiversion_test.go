@@ -0,0 +1,81 @@
+package apiversion
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, s string) map[string]any {
+	t.Helper()
+	var m map[string]any
+	require.NoError(t, json.Unmarshal([]byte(s), &m))
+	return m
+}
+
+func TestLookup(t *testing.T) {
+	v, err := Lookup("")
+	require.NoError(t, err)
+	assert.Equal(t, Latest, v.Name)
+
+	_, err = Lookup("v0")
+	assert.EqualError(t, err, `unknown API version "v0": expected one of [latest]`)
+}
+
+// older is a variant exercising every kind of rewrite; no such variant is
+// registered
+var older = Version{
+	Name:           "older",
+	OmitFields:     []string{"havings", "compare_time_offset_seconds"},
+	Ops:            map[string]string{"COUNT_DISTINCT": "COUNTDISTINCT"},
+	UnsupportedOps: []string{"RATE_SUM", "RATE_AVG", "RATE_MAX"},
+}
+
+func TestQuery(t *testing.T) {
+	v := older
+
+	q := decode(t, `{
+		"calculations": [{"op": "COUNT_DISTINCT", "column": "user.id"}, {"op": "P99", "column": "duration_ms"}],
+		"orders": [{"op": "COUNT_DISTINCT", "column": "user.id", "order": "descending"}],
+		"havings": [{"calculate_op": "COUNT", "op": ">", "value": 10}],
+		"compare_time_offset_seconds": 86400,
+		"time_range": 7200
+	}`)
+	require.NoError(t, v.Query(q))
+	assert.Equal(t, decode(t, `{
+		"calculations": [{"op": "COUNTDISTINCT", "column": "user.id"}, {"op": "P99", "column": "duration_ms"}],
+		"orders": [{"op": "COUNTDISTINCT", "column": "user.id", "order": "descending"}],
+		"time_range": 7200
+	}`), q)
+
+	err := v.Query(decode(t, `{"calculations": [{"op": "RATE_SUM", "column": "bytes"}]}`))
+	assert.EqualError(t, err, "calculation RATE_SUM is not supported by API version older")
+}
+
+func TestOutput(t *testing.T) {
+	output := &honeycomb.Manifest{
+		Boards:   map[string]json.RawMessage{"Overview": json.RawMessage(`{"name":"Overview","panels":[{"type":"section","panels":[{"type":"query","query":{"calculations":[{"op":"COUNT_DISTINCT","column":"trace.trace_id"}]}}]}]}`)},
+		SLOs:     map[string]json.RawMessage{"Availability": json.RawMessage(`{"sli":{"good_events":{"calculations":[{"op":"COUNT_DISTINCT","column":"a"}]},"total_events":{"calculations":[{"op":"COUNT"}]}}}`)},
+		Triggers: map[string]json.RawMessage{"Errors": json.RawMessage(`{"name":"Errors","query":{"calculations":[{"op":"COUNT_DISTINCT","column":"b"}]}}`)},
+	}
+
+	latest, err := Lookup(Latest)
+	require.NoError(t, err)
+	require.NoError(t, latest.Output(output))
+	assert.Contains(t, string(output.Triggers["Errors"]), "COUNT_DISTINCT")
+
+	require.NoError(t, older.Output(output))
+	for section, resources := range output.Resources() {
+		for name, data := range resources {
+			assert.NotContains(t, string(data), "COUNT_DISTINCT", "%s %s", section, name)
+			assert.Contains(t, string(data), "COUNTDISTINCT", "%s %s", section, name)
+		}
+	}
+
+	output.Queries = map[string]json.RawMessage{"Rate": json.RawMessage(`{"calculations":[{"op":"RATE_MAX","column":"c"}]}`)}
+	err = older.Output(output)
+	assert.EqualError(t, err, "queries Rate: calculation RATE_MAX is not supported by API version older")
+}
@@ -0,0 +1,468 @@
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/annotate"
+	"github.com/lex00/wetwire-honeycomb-go/internal/contenthash"
+	"github.com/lex00/wetwire-honeycomb-go/internal/progress"
+	"github.com/lex00/wetwire-honeycomb-go/internal/refs"
+)
+
+// Client is the subset of the Honeycomb API used to apply resources.
+// It is satisfied by *honeycomb.Client.
+type Client interface {
+	ListResources(ctx context.Context, resourceType, dataset string) ([]json.RawMessage, error)
+	ListBurnAlerts(ctx context.Context, dataset, sloID string) ([]json.RawMessage, error)
+	CreateResource(ctx context.Context, resourceType, dataset string, body json.RawMessage) (json.RawMessage, error)
+	UpdateResource(ctx context.Context, resourceType, dataset, id string, body json.RawMessage) (json.RawMessage, error)
+	DeleteResource(ctx context.Context, resourceType, dataset, id string) error
+}
+
+// Action is the change apply makes (or would make) to a resource.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionNone   Action = "none"
+)
+
+// Status is the outcome of applying a resource.
+type Status string
+
+const (
+	// StatusPlanned means the action was computed but not performed (dry run)
+	StatusPlanned Status = "planned"
+
+	// StatusApplied means the action succeeded
+	StatusApplied Status = "applied"
+
+	// StatusFailed means the action returned an error
+	StatusFailed Status = "failed"
+
+	// StatusSkipped means the resource was not applied because an earlier one
+	// failed, or, for a delete, that pruning was declined or the live
+	// resource is not managed
+	StatusSkipped Status = "skipped"
+
+	// StatusRolledBack means the resource was applied and then reverted
+	StatusRolledBack Status = "rolled back"
+)
+
+// Result is the per-resource outcome of an apply.
+type Result struct {
+	Key    string `json:"key"`
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Action Action `json:"action,omitempty"`
+	Status Status `json:"status"`
+	ID     string `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+
+	// Hash is the content hash of the generated resource, before IDs and the
+	// managed annotation are filled in, so it is the same in every
+	// environment and matches wetwire.lock
+	Hash string `json:"hash,omitempty"`
+}
+
+// Report is the outcome of an apply, in plan order.
+type Report struct {
+	// Environment names the target environment of a multi-environment apply
+	Environment string `json:"environment,omitempty"`
+
+	DryRun     bool     `json:"dry_run"`
+	RolledBack bool     `json:"rolled_back"`
+	Results    []Result `json:"results"`
+}
+
+// Failed reports whether any resource failed to apply.
+func (r *Report) Failed() bool {
+	for _, res := range r.Results {
+		if res.Status == StatusFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// Options configures Execute.
+type Options struct {
+	// DryRun computes each resource's action without changing anything
+	DryRun bool
+
+	// NoRollback leaves already-applied resources in place after a failure
+	NoRollback bool
+
+	// Annotate marks boards, SLOs and triggers as managed by appending the
+	// annotate footer, naming Source, to their description
+	Annotate bool
+
+	// Source identifies where the resources are defined, e.g. a repository URL
+	Source string
+
+	// Concurrency is the number of independent resources applied at once.
+	// Values below 2 apply resources one at a time in plan order.
+	Concurrency int
+
+	// Progress, when set, gets Add(1) as each resource is applied or
+	// skipped. The caller starts and finishes it, so the Executes of several
+	// environments can share one.
+	Progress progress.Tracker
+}
+
+// applied records what is needed to revert a resource.
+type applied struct {
+	index    int
+	resource Resource
+	previous json.RawMessage
+}
+
+// Execute applies the planned resources in order. Each resource is matched to
+// an existing live resource by its title (queries are always created); matching
+// resources are updated only when a generated field differs. IDs of applied
+// resources are injected into the resources that reference them. With
+// opts.Annotate, descriptions are annotated before comparing, so an unchanged
+// annotated resource is left alone.
+//
+// With opts.Concurrency above 1, resources whose dependencies have all been
+// applied are applied in parallel; the client must then be safe for
+// concurrent use.
+//
+// Apply stops at the first failure and marks the remaining resources skipped.
+// Unless opts.NoRollback is set, resources created in this run are then deleted
+// and updated resources restored, in reverse order. Queries are immutable in
+// Honeycomb and are never rolled back.
+func Execute(ctx context.Context, client Client, plan []Resource, opts Options) *Report {
+	report := &Report{DryRun: opts.DryRun, Results: make([]Result, len(plan))}
+	ids := make(map[string]string)
+	live := newLiveIndex(client)
+
+	var mu sync.Mutex
+	var done []applied
+	failed := false
+
+	run := func(i int) {
+		r := plan[i]
+		result := Result{Key: r.Key(), Type: r.Type, Name: r.Name}
+		result.Hash, _ = contenthash.Hash(r.Body)
+
+		mu.Lock()
+		skip := failed
+		mu.Unlock()
+		if skip {
+			result.Status = StatusSkipped
+			report.Results[i] = result
+			if opts.Progress != nil {
+				opts.Progress.Add(1)
+			}
+			return
+		}
+
+		mu.Lock()
+		body, err := injectIDs(r, ids)
+		sloID := ids[r.IDRefs["slo.id"]]
+		mu.Unlock()
+		if err == nil && opts.Annotate && annotated(r.Type) {
+			body, err = annotate.Resource(body, opts.Source)
+		}
+		var existing json.RawMessage
+		if err == nil {
+			existing, err = live.find(ctx, r, sloID)
+		}
+		if err == nil {
+			result, err = applyOne(ctx, client, r, body, existing, opts.DryRun, result)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			result.Status = StatusFailed
+			result.Error = err.Error()
+			failed = true
+		} else if !opts.DryRun && result.Action != ActionNone {
+			done = append(done, applied{index: i, resource: r, previous: existing})
+		}
+		if result.ID != "" {
+			ids[r.Key()] = result.ID
+		}
+		report.Results[i] = result
+		if opts.Progress != nil {
+			opts.Progress.Add(1)
+		}
+	}
+
+	for _, wave := range waves(plan, opts.Concurrency) {
+		runWave(wave, opts.Concurrency, run)
+	}
+
+	if failed && !opts.NoRollback && !opts.DryRun {
+		report.RolledBack = true
+		for i := len(done) - 1; i >= 0; i-- {
+			a := done[i]
+			res := &report.Results[a.index]
+			if err := rollback(ctx, client, a, res.ID); err != nil {
+				res.Error = fmt.Sprintf("rollback failed: %v", err)
+				report.RolledBack = false
+				continue
+			}
+			if a.resource.Type != TypeQuery {
+				res.Status = StatusRolledBack
+			}
+		}
+	}
+
+	return report
+}
+
+// waves groups plan indexes into batches that can be applied in parallel:
+// every resource comes in a later wave than its dependencies. Without
+// concurrency each resource is its own wave, preserving plan order.
+func waves(plan []Resource, concurrency int) [][]int {
+	var result [][]int
+	if concurrency < 2 {
+		for i := range plan {
+			result = append(result, []int{i})
+		}
+		return result
+	}
+
+	level := make(map[string]int, len(plan))
+	for i, r := range plan {
+		l := 0
+		for _, dep := range r.DependsOn {
+			if dl, ok := level[dep]; ok && dl+1 > l {
+				l = dl + 1
+			}
+		}
+		level[r.Key()] = l
+		for len(result) <= l {
+			result = append(result, nil)
+		}
+		result[l] = append(result[l], i)
+	}
+	return result
+}
+
+// runWave calls run for each index using up to concurrency goroutines and
+// waits for all of them.
+func runWave(wave []int, concurrency int, run func(int)) {
+	if concurrency < 2 || len(wave) == 1 {
+		for _, i := range wave {
+			run(i)
+		}
+		return
+	}
+
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency && w < len(wave); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				run(i)
+			}
+		}()
+	}
+	for _, i := range wave {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+}
+
+// applyOne creates, updates or leaves a single resource and fills in result.
+func applyOne(ctx context.Context, client Client, r Resource, body, existing json.RawMessage, dryRun bool, result Result) (Result, error) {
+	result.Status = StatusApplied
+	if dryRun {
+		result.Status = StatusPlanned
+	}
+
+	if existing != nil {
+		result.ID = resourceID(existing)
+		same, err := sameFields(existing, body)
+		if err != nil {
+			return result, err
+		}
+		if same {
+			result.Action = ActionNone
+			return result, nil
+		}
+		result.Action = ActionUpdate
+		if !dryRun {
+			if _, err := client.UpdateResource(ctx, r.Type, r.Dataset, result.ID, body); err != nil {
+				return result, err
+			}
+		}
+		return result, nil
+	}
+
+	result.Action = ActionCreate
+	if dryRun {
+		return result, nil
+	}
+	created, err := client.CreateResource(ctx, r.Type, r.Dataset, body)
+	if err != nil {
+		return result, err
+	}
+	result.ID = resourceID(created)
+	return result, nil
+}
+
+// rollback reverts a resource applied in this run.
+func rollback(ctx context.Context, client Client, a applied, id string) error {
+	switch {
+	case a.resource.Type == TypeQuery:
+		return nil
+	case a.previous != nil:
+		_, err := client.UpdateResource(ctx, a.resource.Type, a.resource.Dataset, id, a.previous)
+		return err
+	default:
+		return client.DeleteResource(ctx, a.resource.Type, a.resource.Dataset, id)
+	}
+}
+
+// annotated reports whether resources of a type carry the managed annotation.
+// Queries have no description and burn alerts are matched by theirs.
+func annotated(resourceType string) bool {
+	return resourceType == TypeBoard || resourceType == TypeSLO || resourceType == TypeTrigger
+}
+
+// injectIDs returns the resource body with dependency IDs filled in, both
+// at its IDRefs and in place of its placeholders (see internal/refs).
+func injectIDs(r Resource, ids map[string]string) (json.RawMessage, error) {
+	resolved, err := refs.Resolve(r.Body, ids)
+	if err != nil {
+		return nil, fmt.Errorf("resolve references: %w", err)
+	}
+	if len(r.IDRefs) == 0 {
+		return resolved, nil
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(resolved, &body); err != nil {
+		return nil, fmt.Errorf("parse body: %w", err)
+	}
+
+	for field, depKey := range r.IDRefs {
+		id := ids[depKey]
+		if id == "" {
+			// Dependency not created yet (dry run); leave the reference unset
+			continue
+		}
+		setField(body, strings.Split(field, "."), id)
+	}
+
+	return json.Marshal(body)
+}
+
+// setField sets a nested field, creating intermediate objects as needed.
+func setField(obj map[string]any, path []string, value any) {
+	for _, part := range path[:len(path)-1] {
+		next, ok := obj[part].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			obj[part] = next
+		}
+		obj = next
+	}
+	obj[path[len(path)-1]] = value
+}
+
+// resourceID extracts the "id" field of a resource.
+func resourceID(data json.RawMessage) string {
+	var meta struct {
+		ID any `json:"id"`
+	}
+	if json.Unmarshal(data, &meta) != nil || meta.ID == nil {
+		return ""
+	}
+	return fmt.Sprint(meta.ID)
+}
+
+// sameFields reports whether every field of body has the same value in live.
+// Server-side fields present only in live are ignored.
+func sameFields(live, body json.RawMessage) (bool, error) {
+	var liveFields, bodyFields map[string]any
+	if err := json.Unmarshal(live, &liveFields); err != nil {
+		return false, fmt.Errorf("parse live resource: %w", err)
+	}
+	if err := json.Unmarshal(body, &bodyFields); err != nil {
+		return false, fmt.Errorf("parse body: %w", err)
+	}
+
+	for k, v := range bodyFields {
+		if !reflect.DeepEqual(liveFields[k], v) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// liveIndex caches live resources by collection and title. It is safe for
+// concurrent use.
+type liveIndex struct {
+	client Client
+
+	mu    sync.Mutex
+	cache map[string]map[string]json.RawMessage
+}
+
+func newLiveIndex(client Client) *liveIndex {
+	return &liveIndex{client: client, cache: make(map[string]map[string]json.RawMessage)}
+}
+
+// find returns the live resource matching r, or nil when there is none.
+// Burn alerts are looked up under the SLO with the given id.
+func (l *liveIndex) find(ctx context.Context, r Resource, sloID string) (json.RawMessage, error) {
+	if r.Type == TypeQuery || r.Title == "" {
+		return nil, nil
+	}
+
+	titleField := "name"
+	collection := r.Type + "/" + r.Dataset
+	list := func() ([]json.RawMessage, error) {
+		return l.client.ListResources(ctx, r.Type, r.Dataset)
+	}
+
+	if r.Type == TypeBurnAlert {
+		if sloID == "" {
+			return nil, nil
+		}
+		titleField = "description"
+		collection += "/" + sloID
+		list = func() ([]json.RawMessage, error) {
+			return l.client.ListBurnAlerts(ctx, r.Dataset, sloID)
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	byTitle, ok := l.cache[collection]
+	if !ok {
+		resources, err := list()
+		if err != nil {
+			return nil, err
+		}
+		byTitle = make(map[string]json.RawMessage, len(resources))
+		for _, res := range resources {
+			var fields map[string]any
+			if json.Unmarshal(res, &fields) != nil {
+				continue
+			}
+			if title, ok := fields[titleField].(string); ok && title != "" {
+				byTitle[title] = res
+			}
+		}
+		l.cache[collection] = byTitle
+	}
+
+	return byTitle[r.Title], nil
+}
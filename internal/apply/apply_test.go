@@ -0,0 +1,352 @@
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/annotate"
+	"github.com/lex00/wetwire-honeycomb-go/internal/contenthash"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryClient is an in-memory Honeycomb API, safe for concurrent use.
+type memoryClient struct {
+	mu        sync.Mutex
+	resources map[string]map[string]json.RawMessage // collection -> id -> JSON
+	nextID    int
+	queryIDs  map[string]string // query body -> id
+	failOn    string            // resource type whose creation fails
+	calls     []string
+}
+
+func newMemoryClient() *memoryClient {
+	return &memoryClient{
+		resources: make(map[string]map[string]json.RawMessage),
+		queryIDs:  make(map[string]string),
+	}
+}
+
+func (m *memoryClient) put(collection, id string, body json.RawMessage) json.RawMessage {
+	var fields map[string]any
+	_ = json.Unmarshal(body, &fields)
+	fields["id"] = id
+	data, _ := json.Marshal(fields)
+	if m.resources[collection] == nil {
+		m.resources[collection] = make(map[string]json.RawMessage)
+	}
+	m.resources[collection][id] = data
+	return data
+}
+
+func (m *memoryClient) list(collection string) []json.RawMessage {
+	var result []json.RawMessage
+	for _, r := range m.resources[collection] {
+		result = append(result, r)
+	}
+	return result
+}
+
+func (m *memoryClient) ListResources(ctx context.Context, resourceType, dataset string) ([]json.RawMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.list(resourceType + "/" + dataset), nil
+}
+
+func (m *memoryClient) ListBurnAlerts(ctx context.Context, dataset, sloID string) ([]json.RawMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []json.RawMessage
+	for _, r := range m.list("burn_alerts/" + dataset) {
+		if strings.Contains(string(r), `"id":"`+sloID+`"`) {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryClient) CreateResource(ctx context.Context, resourceType, dataset string, body json.RawMessage) (json.RawMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, "create "+resourceType)
+	if resourceType == m.failOn {
+		return nil, fmt.Errorf("boom")
+	}
+	// Like Honeycomb, identical queries share an id
+	if resourceType == "queries" {
+		if id, ok := m.queryIDs[string(body)]; ok {
+			return m.put(resourceType+"/"+dataset, id, body), nil
+		}
+	}
+	m.nextID++
+	if resourceType == "queries" {
+		m.queryIDs[string(body)] = fmt.Sprintf("id%d", m.nextID)
+	}
+	return m.put(resourceType+"/"+dataset, fmt.Sprintf("id%d", m.nextID), body), nil
+}
+
+func (m *memoryClient) UpdateResource(ctx context.Context, resourceType, dataset, id string, body json.RawMessage) (json.RawMessage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, "update "+resourceType)
+	return m.put(resourceType+"/"+dataset, id, body), nil
+}
+
+func (m *memoryClient) DeleteResource(ctx context.Context, resourceType, dataset, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, "delete "+resourceType)
+	delete(m.resources[resourceType+"/"+dataset], id)
+	return nil
+}
+
+func testPlan(t *testing.T) []Resource {
+	t.Helper()
+	output, discovered := testBuild()
+	resources, err := FromBuild(output, discovered)
+	require.NoError(t, err)
+	plan, err := Plan(resources, nil)
+	require.NoError(t, err)
+	return plan
+}
+
+func TestExecute_CreatesThenNoop(t *testing.T) {
+	client := newMemoryClient()
+	plan := testPlan(t)
+
+	report := Execute(context.Background(), client, plan, Options{})
+	require.False(t, report.Failed())
+	for _, r := range report.Results {
+		assert.Equal(t, ActionCreate, r.Action, r.Key)
+		assert.Equal(t, StatusApplied, r.Status, r.Key)
+		assert.NotEmpty(t, r.ID, r.Key)
+	}
+
+	// Dependency IDs were injected
+	trigger := client.list("triggers/api")[0]
+	assert.Contains(t, string(trigger), `"query_id":"id1"`)
+	alert := client.list("burn_alerts/api")[0]
+	assert.Contains(t, string(alert), `"slo":{"id":"id3"}`)
+
+	// Re-applying the same plan leaves named resources untouched
+	client.calls = nil
+	report = Execute(context.Background(), client, plan, Options{})
+	require.False(t, report.Failed())
+	assert.Equal(t, []string{"create queries", "create queries", "create queries"}, client.calls)
+	for _, r := range report.Results {
+		if r.Type != TypeQuery {
+			assert.Equal(t, ActionNone, r.Action, r.Key)
+		}
+	}
+}
+
+func TestExecute_ResolvesPlaceholders(t *testing.T) {
+	output := &honeycomb.Manifest{
+		Queries: map[string]json.RawMessage{"Latency": json.RawMessage(`{"time_range": 3600}`)},
+		Boards:  map[string]json.RawMessage{"Overview": json.RawMessage(`{"name": "Overview", "panels": [{"type": "query", "query_id": "wetwire-ref:queries/Latency"}]}`)},
+	}
+	discovered := &discovery.DiscoveredResources{
+		Queries: []discovery.DiscoveredQuery{{Name: "Latency", Dataset: "api"}},
+		Boards:  []discovery.DiscoveredBoard{{Name: "Overview", BoardName: "Overview"}},
+	}
+	resources, err := FromBuild(output, discovered)
+	require.NoError(t, err)
+	plan, err := Plan(resources, nil)
+	require.NoError(t, err)
+	require.Len(t, plan, 2)
+	assert.Equal(t, []string{"queries/Latency"}, plan[1].DependsOn)
+
+	// A dry run plans the board before its query exists
+	client := newMemoryClient()
+	report := Execute(context.Background(), client, plan, Options{DryRun: true})
+	require.False(t, report.Failed())
+
+	report = Execute(context.Background(), client, plan, Options{})
+	require.False(t, report.Failed())
+	board := client.list("boards/")[0]
+	assert.Contains(t, string(board), `"query_id":"id1"`)
+	assert.NotContains(t, string(board), "wetwire-ref:")
+}
+
+func TestExecute_UpdatesChangedResources(t *testing.T) {
+	client := newMemoryClient()
+	client.put("triggers/api", "t1", json.RawMessage(`{"name": "High Errors", "dataset": "api", "disabled": true}`))
+
+	plan := []Resource{{Type: TypeTrigger, Name: "HighErrors", Title: "High Errors", Dataset: "api",
+		Body: json.RawMessage(`{"name": "High Errors", "dataset": "api", "disabled": false}`)}}
+
+	report := Execute(context.Background(), client, plan, Options{DryRun: true})
+	assert.Equal(t, ActionUpdate, report.Results[0].Action)
+	assert.Equal(t, StatusPlanned, report.Results[0].Status)
+	assert.Empty(t, client.calls)
+
+	report = Execute(context.Background(), client, plan, Options{})
+	assert.Equal(t, ActionUpdate, report.Results[0].Action)
+	assert.Equal(t, "t1", report.Results[0].ID)
+	assert.Contains(t, string(client.resources["triggers/api"]["t1"]), `"disabled":false`)
+}
+
+func TestExecute_Concurrent(t *testing.T) {
+	plan := testPlan(t)
+
+	serial := Execute(context.Background(), newMemoryClient(), plan, Options{DryRun: true})
+	client := newMemoryClient()
+	report := Execute(context.Background(), client, plan, Options{Concurrency: 4})
+	require.False(t, report.Failed())
+
+	// Results stay in plan order
+	require.Len(t, report.Results, len(serial.Results))
+	for i, r := range report.Results {
+		assert.Equal(t, serial.Results[i].Key, r.Key)
+		assert.Equal(t, ActionCreate, r.Action, r.Key)
+	}
+
+	// Dependency IDs are still injected
+	trigger := client.list("triggers/api")[0]
+	assert.Contains(t, string(trigger), `"query_id":"`+report.Results[0].ID+`"`)
+	alert := client.list("burn_alerts/api")[0]
+	assert.Contains(t, string(alert), `"slo":{"id":"`)
+}
+
+func TestWaves(t *testing.T) {
+	plan := testPlan(t)
+
+	serial := waves(plan, 1)
+	assert.Len(t, serial, len(plan))
+
+	var levels [][]string
+	for _, wave := range waves(plan, 4) {
+		var wkeys []string
+		for _, i := range wave {
+			wkeys = append(wkeys, plan[i].Key())
+		}
+		levels = append(levels, wkeys)
+	}
+	assert.Equal(t, [][]string{
+		{"queries/Errors", "queries/Good", "queries/Latency"},
+		{"slos/Availability", "triggers/HighErrors"},
+		{"burn_alerts/Availability/0", "boards/Overview"},
+	}, levels)
+}
+
+func TestExecute_Annotate(t *testing.T) {
+	client := newMemoryClient()
+	plan := testPlan(t)
+	opts := Options{Annotate: true, Source: "github.com/acme/obs"}
+
+	report := Execute(context.Background(), client, plan, opts)
+	require.False(t, report.Failed())
+
+	board := client.list("boards/")[0]
+	assert.True(t, annotate.IsManaged(board))
+	trigger := client.list("triggers/api")[0]
+	assert.True(t, annotate.IsManaged(trigger))
+	assert.Contains(t, string(trigger), "github.com/acme/obs")
+
+	// Burn alerts are matched by description and keep it unannotated
+	alert := client.list("burn_alerts/api")[0]
+	assert.False(t, annotate.IsManaged(alert))
+
+	// Annotated resources compare equal on re-apply
+	report = Execute(context.Background(), client, plan, opts)
+	for _, r := range report.Results {
+		if r.Type != TypeQuery {
+			assert.Equal(t, ActionNone, r.Action, r.Key)
+		}
+	}
+
+	// A new source updates the annotation
+	opts.Source = "github.com/acme/other"
+	report = Execute(context.Background(), client, plan, opts)
+	for _, r := range report.Results {
+		if annotated(r.Type) {
+			assert.Equal(t, ActionUpdate, r.Action, r.Key)
+		}
+	}
+}
+
+func TestExecute_Hash(t *testing.T) {
+	plan := testPlan(t)
+
+	// The hash is of the generated body, so IDs filled in by the environment
+	// and annotations do not change it
+	first := Execute(context.Background(), newMemoryClient(), plan, Options{})
+	second := Execute(context.Background(), newMemoryClient(), plan, Options{Annotate: true, Source: "github.com/acme/obs"})
+	require.False(t, first.Failed())
+
+	for i, r := range first.Results {
+		want, err := contenthash.Hash(plan[i].Body)
+		require.NoError(t, err)
+		assert.Equal(t, want, r.Hash, r.Key)
+		assert.Equal(t, r.Hash, second.Results[i].Hash, r.Key)
+	}
+}
+
+func TestExecute_RollbackOnFailure(t *testing.T) {
+	client := newMemoryClient()
+	client.failOn = TypeTrigger
+	client.put("slos/api", "s1", json.RawMessage(`{"name": "Availability", "dataset": "api", "time_period_days": 7}`))
+
+	report := Execute(context.Background(), client, testPlan(t), Options{})
+	require.True(t, report.Failed())
+	assert.True(t, report.RolledBack)
+
+	statuses := make(map[string]Status)
+	for _, r := range report.Results {
+		statuses[r.Key] = r.Status
+	}
+	assert.Equal(t, StatusApplied, statuses["queries/Errors"])
+	assert.Equal(t, StatusRolledBack, statuses["slos/Availability"])
+	assert.Equal(t, StatusRolledBack, statuses["burn_alerts/Availability/0"])
+	assert.Equal(t, StatusFailed, statuses["triggers/HighErrors"])
+	assert.Equal(t, StatusSkipped, statuses["boards/Overview"])
+
+	// The created burn alert is deleted and the updated SLO restored
+	assert.Empty(t, client.resources["burn_alerts/api"])
+	assert.Contains(t, string(client.resources["slos/api"]["s1"]), `"time_period_days":7`)
+}
+
+// countingTracker counts the steps it is given.
+type countingTracker struct {
+	mu   sync.Mutex
+	done int
+}
+
+func (c *countingTracker) Start(int) {}
+func (c *countingTracker) Finish()   {}
+func (c *countingTracker) Add(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.done += n
+}
+
+func TestExecute_Progress(t *testing.T) {
+	plan := testPlan(t)
+
+	tracker := &countingTracker{}
+	Execute(context.Background(), newMemoryClient(), plan, Options{Concurrency: 4, Progress: tracker})
+	assert.Equal(t, len(plan), tracker.done)
+
+	// Skipped resources count too
+	client := newMemoryClient()
+	client.failOn = TypeTrigger
+	tracker = &countingTracker{}
+	Execute(context.Background(), client, plan, Options{Progress: tracker})
+	assert.Equal(t, len(plan), tracker.done)
+}
+
+func TestExecute_NoRollback(t *testing.T) {
+	client := newMemoryClient()
+	client.failOn = TypeBoard
+
+	report := Execute(context.Background(), client, testPlan(t), Options{NoRollback: true})
+	require.True(t, report.Failed())
+	assert.False(t, report.RolledBack)
+	assert.Len(t, client.resources["triggers/api"], 1)
+}
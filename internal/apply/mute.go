@@ -0,0 +1,81 @@
+package apply
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/lock"
+	"github.com/lex00/wetwire-honeycomb-go/mute"
+)
+
+// TypeMute is the build output section of maintenance windows. Honeycomb has
+// no API for them: Mute applies them by disabling the triggers they select.
+const TypeMute = "mutes"
+
+// Muted is a trigger disabled by a maintenance window.
+type Muted struct {
+	// Trigger is the key of the trigger, e.g. "triggers/HighLatency"
+	Trigger string
+
+	// Window is the Go variable name of the maintenance window
+	Window string
+
+	// End is when the window ends, in RFC 3339 format
+	End string
+}
+
+// Mute disables the triggers selected by the maintenance windows of the
+// mutes build output section that are active at now, by setting "disabled"
+// in their bodies, and returns them in resource order. A trigger outside
+// every active window is sent as built, so the first apply after a window
+// ends enables its triggers again.
+func Mute(resources []Resource, mutes map[string]json.RawMessage, now time.Time) ([]Muted, error) {
+	names := make([]string, 0, len(mutes))
+	for name := range mutes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	windows := make(map[string]Muted)
+	for _, name := range names {
+		var w struct {
+			Start    string   `json:"start"`
+			End      string   `json:"end"`
+			Triggers []string `json:"triggers"`
+		}
+		if err := json.Unmarshal(mutes[name], &w); err != nil {
+			return nil, fmt.Errorf("maintenance window %s: %w", name, err)
+		}
+		if !(mute.Window{Start: w.Start, End: w.End}).Active(now) {
+			continue
+		}
+		for _, t := range w.Triggers {
+			key := lock.Key(TypeTrigger, t)
+			if _, ok := windows[key]; !ok {
+				windows[key] = Muted{Trigger: key, Window: name, End: w.End}
+			}
+		}
+	}
+
+	var muted []Muted
+	for i, r := range resources {
+		m, ok := windows[r.Key()]
+		if r.Type != TypeTrigger || !ok {
+			continue
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(r.Body, &fields); err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Key(), err)
+		}
+		fields["disabled"] = json.RawMessage("true")
+		body, err := json.Marshal(fields)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Key(), err)
+		}
+		resources[i].Body = body
+		muted = append(muted, m)
+	}
+	return muted, nil
+}
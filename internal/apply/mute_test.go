@@ -0,0 +1,48 @@
+package apply
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMute(t *testing.T) {
+	resources := []Resource{
+		{Type: TypeQuery, Name: "HighLatency", Body: json.RawMessage(`{}`)},
+		{Type: TypeTrigger, Name: "HighLatency", Body: json.RawMessage(`{"name": "High Latency", "disabled": false}`)},
+		{Type: TypeTrigger, Name: "HighErrors", Body: json.RawMessage(`{"name": "High Errors", "disabled": false}`)},
+		{Type: TypeTrigger, Name: "SlowQueries", Body: json.RawMessage(`{"name": "Slow Queries", "disabled": false}`)},
+	}
+	mutes := map[string]json.RawMessage{
+		"Upgrade":  json.RawMessage(`{"start": "2026-11-07T02:00:00Z", "end": "2026-11-07T04:00:00Z", "triggers": ["HighLatency", "SlowQueries"]}`),
+		"Failover": json.RawMessage(`{"start": "2026-11-07T03:00:00Z", "end": "2026-11-07T05:00:00Z", "triggers": ["SlowQueries"]}`),
+		"Later":    json.RawMessage(`{"start": "2026-12-01T00:00:00Z", "end": "2026-12-02T00:00:00Z", "triggers": ["HighErrors"]}`),
+	}
+
+	muted, err := Mute(resources, mutes, time.Date(2026, 11, 7, 3, 30, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, []Muted{
+		{Trigger: "triggers/HighLatency", Window: "Upgrade", End: "2026-11-07T04:00:00Z"},
+		{Trigger: "triggers/SlowQueries", Window: "Failover", End: "2026-11-07T05:00:00Z"},
+	}, muted)
+
+	assert.JSONEq(t, `{}`, string(resources[0].Body))
+	assert.JSONEq(t, `{"name": "High Latency", "disabled": true}`, string(resources[1].Body))
+	assert.JSONEq(t, `{"name": "High Errors", "disabled": false}`, string(resources[2].Body))
+	assert.JSONEq(t, `{"name": "Slow Queries", "disabled": true}`, string(resources[3].Body))
+}
+
+func TestMute_NoActiveWindow(t *testing.T) {
+	resources := []Resource{{Type: TypeTrigger, Name: "HighLatency", Body: json.RawMessage(`{"disabled": false}`)}}
+	mutes := map[string]json.RawMessage{
+		"Upgrade": json.RawMessage(`{"start": "2026-11-07T02:00:00Z", "end": "2026-11-07T04:00:00Z", "triggers": ["HighLatency"]}`),
+	}
+
+	muted, err := Mute(resources, mutes, time.Date(2026, 11, 7, 4, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Empty(t, muted)
+	assert.JSONEq(t, `{"disabled": false}`, string(resources[0].Body))
+}
@@ -0,0 +1,391 @@
+// Package apply plans and executes the synchronization of built resources to a
+// Honeycomb environment.
+package apply
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lock"
+	"github.com/lex00/wetwire-honeycomb-go/internal/refs"
+)
+
+// Resource types in the order they are applied when no explicit dependency
+// decides. Burn alerts are split out of their SLO because Honeycomb manages
+// them as separate resources.
+const (
+	TypeQuery     = "queries"
+	TypeSLO       = "slos"
+	TypeBurnAlert = "burn_alerts"
+	TypeTrigger   = "triggers"
+	TypeBoard     = "boards"
+)
+
+// EnvironmentDataset is the dataset slug of environment-wide resources, such
+// as SLOs whose SLI spans several datasets.
+const EnvironmentDataset = "__all__"
+
+// typeRank orders resource types so that referenced resources come first.
+var typeRank = map[string]int{
+	TypeQuery:     0,
+	TypeSLO:       1,
+	TypeBurnAlert: 2,
+	TypeTrigger:   2,
+	TypeBoard:     3,
+}
+
+// Resource is a single resource to apply.
+type Resource struct {
+	// Type is the resource type (queries, slos, burn_alerts, triggers, boards)
+	Type string
+
+	// Name is the Go variable name (for burn alerts, "<SLO>/<alert>")
+	Name string
+
+	// Title is the Honeycomb name used to match an existing live resource.
+	// Queries have no title and are always created.
+	Title string
+
+	// Dataset is the dataset the resource belongs to (empty for boards)
+	Dataset string
+
+	// Body is the resource JSON sent to the API
+	Body json.RawMessage
+
+	// DependsOn lists the keys of resources that must be applied first
+	DependsOn []string
+
+	// IDRefs maps a body field (dotted for nested objects) to the key of the
+	// dependency whose Honeycomb ID fills it
+	IDRefs map[string]string
+}
+
+// Key returns the resource's lockfile-style key, e.g. "triggers/HighLatency".
+func (r Resource) Key() string {
+	return lock.Key(r.Type, r.Name)
+}
+
+// FromBuild converts build output into resources, using the discovered
+// definitions to wire references.
+func FromBuild(output *honeycomb.Manifest, resources *discovery.DiscoveredResources) ([]Resource, error) {
+	var result []Resource
+
+	queryKeys := make(map[string]bool)
+	for _, dq := range resources.Queries {
+		// Inline queries share their parent's name; the build keeps one of them
+		body, ok := output.Queries[dq.Name]
+		if !ok || queryKeys[dq.Name] {
+			continue
+		}
+		result = append(result, Resource{Type: TypeQuery, Name: dq.Name, Dataset: dq.Dataset, Body: body})
+		queryKeys[dq.Name] = true
+	}
+
+	queryDep := func(name string) []string {
+		if name != "" && queryKeys[name] {
+			return []string{lock.Key(TypeQuery, name)}
+		}
+		return nil
+	}
+
+	sloKeys := make(map[string]bool)
+	for _, ds := range resources.SLOs {
+		body, ok := output.SLOs[ds.Name]
+		if !ok {
+			continue
+		}
+		// An SLO across datasets, and its burn alerts, live in the environment
+		if hasField(body, "dataset_slugs") {
+			ds.Dataset = EnvironmentDataset
+		}
+		r := Resource{Type: TypeSLO, Name: ds.Name, Title: ds.SLOName, Dataset: ds.Dataset}
+		r.DependsOn = append(queryDep(ds.GoodEventsQueryRef), queryDep(ds.TotalEventsQueryRef)...)
+
+		// Burn alerts are applied separately once the SLO exists
+		var err error
+		if r.Body, err = withoutField(body, "burn_alerts"); err != nil {
+			return nil, fmt.Errorf("slo %s: %w", ds.Name, err)
+		}
+		result = append(result, r)
+		sloKeys[ds.Name] = true
+
+		for i, alert := range ds.BurnAlerts {
+			result = append(result, burnAlertResource(ds, i, alert))
+		}
+	}
+
+	for _, dt := range resources.Triggers {
+		body, ok := output.Triggers[dt.Name]
+		if !ok {
+			continue
+		}
+		r := Resource{Type: TypeTrigger, Name: dt.Name, Title: dt.TriggerName, Dataset: dt.Dataset, Body: body}
+		// A trigger built with --query-mode inline carries its query spec
+		if dep := queryDep(dt.QueryRef); dep != nil && !hasField(body, "query") {
+			r.DependsOn = dep
+			r.IDRefs = map[string]string{"query_id": dep[0]}
+		}
+		result = append(result, r)
+	}
+
+	for _, db := range resources.Boards {
+		body, ok := output.Boards[db.Name]
+		if !ok {
+			continue
+		}
+		r := Resource{Type: TypeBoard, Name: db.Name, Title: db.BoardName, Body: body}
+		for _, ref := range db.QueryRefs {
+			r.DependsOn = append(r.DependsOn, queryDep(ref)...)
+		}
+		for _, ref := range db.SLORefs {
+			if sloKeys[ref] {
+				r.DependsOn = append(r.DependsOn, lock.Key(TypeSLO, ref))
+			}
+		}
+		result = append(result, r)
+	}
+
+	// Placeholders in bodies are resolved once their resource exists
+	planned := make(map[string]bool)
+	for _, r := range result {
+		planned[r.Key()] = true
+	}
+	for i, r := range result {
+		found, err := refs.Find(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Key(), err)
+		}
+		for _, key := range found {
+			if planned[key] && !slices.Contains(result[i].DependsOn, key) {
+				result[i].DependsOn = append(result[i].DependsOn, key)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// burnAlertResource builds the burn alert resource for an SLO's i-th alert.
+func burnAlertResource(ds discovery.DiscoveredSLO, i int, alert discovery.DiscoveredBurnAlert) Resource {
+	name := alert.Name
+	if name == "" {
+		name = fmt.Sprintf("%s %dh", alert.AlertType, alert.WindowHours)
+	}
+	sloTitle := ds.SLOName
+	if sloTitle == "" {
+		sloTitle = ds.Name
+	}
+	title := sloTitle + ": " + name
+
+	body := map[string]any{
+		"alert_type":  alert.AlertType,
+		"description": title,
+	}
+	switch alert.AlertType {
+	case "exhaustion_time":
+		body["exhaustion_minutes"] = int(alert.Threshold * 60)
+	default:
+		body["budget_rate_window_minutes"] = alert.WindowHours * 60
+		body["budget_rate_decrease_threshold_per_million"] = int(math.Round(alert.Threshold * 10000))
+	}
+	var recipients []map[string]string
+	for _, r := range ds.AlertRecipients(alert) {
+		recipients = append(recipients, map[string]string{"type": r.Type, "target": r.Target})
+	}
+	if len(recipients) > 0 {
+		body["recipients"] = recipients
+	}
+	data, _ := json.Marshal(body)
+
+	sloKey := lock.Key(TypeSLO, ds.Name)
+	return Resource{
+		Type:      TypeBurnAlert,
+		Name:      fmt.Sprintf("%s/%d", ds.Name, i),
+		Title:     title,
+		Dataset:   ds.Dataset,
+		Body:      data,
+		DependsOn: []string{sloKey},
+		IDRefs:    map[string]string{"slo.id": sloKey},
+	}
+}
+
+// hasField reports whether a JSON object has the given top-level field.
+func hasField(body json.RawMessage, field string) bool {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil {
+		return false
+	}
+	_, ok := fields[field]
+	return ok
+}
+
+// withoutField returns a copy of a JSON object without the given top-level field.
+func withoutField(body json.RawMessage, field string) (json.RawMessage, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, field)
+	return json.Marshal(fields)
+}
+
+// Selector restricts a plan to matching resources, e.g. "type=slo" or
+// "name=HighLatency".
+type Selector struct {
+	Field string
+	Value string
+}
+
+// ParseSelector parses a "field=value" selector. Supported fields are type
+// (singular or plural resource type) and name (Go variable name).
+func ParseSelector(s string) (Selector, error) {
+	field, value, ok := strings.Cut(s, "=")
+	if !ok || value == "" {
+		return Selector{}, fmt.Errorf("invalid selector %q (expected field=value)", s)
+	}
+
+	switch field {
+	case "type":
+		t, err := normalizeType(value)
+		if err != nil {
+			return Selector{}, err
+		}
+		return Selector{Field: field, Value: t}, nil
+	case "name":
+		return Selector{Field: field, Value: value}, nil
+	}
+	return Selector{}, fmt.Errorf("invalid selector %q (supported fields: type, name)", s)
+}
+
+// normalizeType maps singular and plural type names to resource types.
+func normalizeType(t string) (string, error) {
+	switch t {
+	case "query", "queries":
+		return TypeQuery, nil
+	case "slo", "slos":
+		return TypeSLO, nil
+	case "burn_alert", "burn_alerts":
+		return TypeBurnAlert, nil
+	case "trigger", "triggers":
+		return TypeTrigger, nil
+	case "board", "boards":
+		return TypeBoard, nil
+	}
+	return "", fmt.Errorf("unknown resource type %q", t)
+}
+
+// matches reports whether the selector matches a resource. Burn alerts are
+// selected together with their SLO.
+func (s Selector) matches(r Resource) bool {
+	switch s.Field {
+	case "type":
+		return r.Type == s.Value || (s.Value == TypeSLO && r.Type == TypeBurnAlert)
+	case "name":
+		if r.Type == TypeBurnAlert {
+			slo, _, _ := strings.Cut(r.Name, "/")
+			return slo == s.Value
+		}
+		return r.Name == s.Value
+	}
+	return false
+}
+
+// Plan selects the resources matching any selector (all resources when none
+// are given) together with everything they depend on, and orders them so
+// that every resource comes after its dependencies. Ties are broken by type
+// (queries, SLOs, burn alerts and triggers, boards) and then key.
+func Plan(resources []Resource, selectors []Selector) ([]Resource, error) {
+	byKey := make(map[string]Resource, len(resources))
+	for _, r := range resources {
+		if _, dup := byKey[r.Key()]; dup {
+			return nil, fmt.Errorf("duplicate resource %s", r.Key())
+		}
+		byKey[r.Key()] = r
+	}
+
+	// Select resources and pull in their dependencies
+	selected := make(map[string]bool)
+	var include func(key string)
+	include = func(key string) {
+		r, ok := byKey[key]
+		if !ok || selected[key] {
+			return
+		}
+		selected[key] = true
+		for _, dep := range r.DependsOn {
+			include(dep)
+		}
+	}
+	for _, r := range resources {
+		if len(selectors) == 0 {
+			include(r.Key())
+			continue
+		}
+		for _, s := range selectors {
+			if s.matches(r) {
+				include(r.Key())
+				break
+			}
+		}
+	}
+
+	// Topological sort (Kahn's algorithm) with deterministic tie-breaking
+	pending := make(map[string]int)
+	dependents := make(map[string][]string)
+	for key := range selected {
+		for _, dep := range byKey[key].DependsOn {
+			if selected[dep] {
+				pending[key]++
+				dependents[dep] = append(dependents[dep], key)
+			}
+		}
+	}
+
+	var ready []string
+	for key := range selected {
+		if pending[key] == 0 {
+			ready = append(ready, key)
+		}
+	}
+
+	var plan []Resource
+	for len(ready) > 0 {
+		sort.Slice(ready, func(i, j int) bool {
+			ri, rj := typeRank[byKey[ready[i]].Type], typeRank[byKey[ready[j]].Type]
+			if ri != rj {
+				return ri < rj
+			}
+			return ready[i] < ready[j]
+		})
+
+		key := ready[0]
+		ready = ready[1:]
+		plan = append(plan, byKey[key])
+
+		for _, dependent := range dependents[key] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(plan) != len(selected) {
+		var cyclic []string
+		for key := range selected {
+			if pending[key] > 0 {
+				cyclic = append(cyclic, key)
+			}
+		}
+		sort.Strings(cyclic)
+		return nil, fmt.Errorf("dependency cycle between %s", strings.Join(cyclic, ", "))
+	}
+
+	return plan, nil
+}
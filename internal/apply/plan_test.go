@@ -0,0 +1,209 @@
+package apply
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keys returns the keys of resources in order.
+func keys(resources []Resource) []string {
+	result := make([]string, len(resources))
+	for i, r := range resources {
+		result[i] = r.Key()
+	}
+	return result
+}
+
+func testBuild() (*honeycomb.Manifest, *discovery.DiscoveredResources) {
+	output := &honeycomb.Manifest{
+		Queries: map[string]json.RawMessage{
+			"Errors":  json.RawMessage(`{"time_range": 3600}`),
+			"Good":    json.RawMessage(`{"time_range": 3600}`),
+			"Latency": json.RawMessage(`{"time_range": 900}`),
+		},
+		SLOs: map[string]json.RawMessage{
+			"Availability": json.RawMessage(`{"name": "Availability", "dataset": "api", "time_period_days": 30, "burn_alerts": [{"alert_type": "budget_rate"}]}`),
+		},
+		Triggers: map[string]json.RawMessage{
+			"HighErrors": json.RawMessage(`{"name": "High Errors", "dataset": "api"}`),
+		},
+		Boards: map[string]json.RawMessage{
+			"Overview": json.RawMessage(`{"name": "Overview"}`),
+		},
+	}
+
+	resources := &discovery.DiscoveredResources{
+		Queries: []discovery.DiscoveredQuery{
+			{Name: "Errors", Dataset: "api"},
+			{Name: "Good", Dataset: "api"},
+			{Name: "Latency", Dataset: "api"},
+		},
+		SLOs: []discovery.DiscoveredSLO{{
+			Name:               "Availability",
+			SLOName:            "Availability",
+			Dataset:            "api",
+			GoodEventsQueryRef: "Good",
+			BurnAlerts:         []discovery.DiscoveredBurnAlert{{AlertType: "budget_rate", Threshold: 2, WindowHours: 1}},
+		}},
+		Triggers: []discovery.DiscoveredTrigger{
+			{Name: "HighErrors", TriggerName: "High Errors", Dataset: "api", QueryRef: "Errors"},
+		},
+		Boards: []discovery.DiscoveredBoard{
+			{Name: "Overview", BoardName: "Overview", QueryRefs: []string{"Latency"}, SLORefs: []string{"Availability"}},
+		},
+	}
+
+	return output, resources
+}
+
+func TestFromBuild(t *testing.T) {
+	output, discovered := testBuild()
+
+	resources, err := FromBuild(output, discovered)
+	require.NoError(t, err)
+
+	byKey := make(map[string]Resource)
+	for _, r := range resources {
+		byKey[r.Key()] = r
+	}
+
+	slo := byKey["slos/Availability"]
+	assert.Equal(t, []string{"queries/Good"}, slo.DependsOn)
+	assert.NotContains(t, string(slo.Body), "burn_alerts")
+
+	alert := byKey["burn_alerts/Availability/0"]
+	assert.Equal(t, "Availability: budget_rate 1h", alert.Title)
+	assert.Equal(t, map[string]string{"slo.id": "slos/Availability"}, alert.IDRefs)
+	assert.JSONEq(t, `{
+		"alert_type": "budget_rate",
+		"description": "Availability: budget_rate 1h",
+		"budget_rate_window_minutes": 60,
+		"budget_rate_decrease_threshold_per_million": 20000
+	}`, string(alert.Body))
+
+	trigger := byKey["triggers/HighErrors"]
+	assert.Equal(t, map[string]string{"query_id": "queries/Errors"}, trigger.IDRefs)
+
+	board := byKey["boards/Overview"]
+	assert.Equal(t, []string{"queries/Latency", "slos/Availability"}, board.DependsOn)
+}
+
+func TestFromBuild_InlineTriggerQuery(t *testing.T) {
+	output, discovered := testBuild()
+	output.Triggers["HighErrors"] = json.RawMessage(`{"name": "High Errors", "dataset": "api", "query": {"time_range": 900}}`)
+
+	resources, err := FromBuild(output, discovered)
+	require.NoError(t, err)
+
+	for _, r := range resources {
+		if r.Key() == "triggers/HighErrors" {
+			assert.Empty(t, r.IDRefs)
+			assert.Empty(t, r.DependsOn)
+			return
+		}
+	}
+	t.Fatal("trigger not planned")
+}
+
+func TestFromBuild_CrossDatasetSLO(t *testing.T) {
+	output, discovered := testBuild()
+	output.SLOs["Availability"] = json.RawMessage(`{"name": "Availability", "dataset_slugs": ["api", "cdn"], "sli": {"alias": "sli.ok"}}`)
+
+	resources, err := FromBuild(output, discovered)
+	require.NoError(t, err)
+
+	byKey := make(map[string]Resource)
+	for _, r := range resources {
+		byKey[r.Key()] = r
+	}
+	assert.Equal(t, EnvironmentDataset, byKey["slos/Availability"].Dataset)
+	assert.Equal(t, EnvironmentDataset, byKey["burn_alerts/Availability/0"].Dataset)
+}
+
+func TestFromBuild_BurnAlertRecipients(t *testing.T) {
+	output, discovered := testBuild()
+	ds := &discovered.SLOs[0]
+	ds.BurnAlerts = append(ds.BurnAlerts, discovery.DiscoveredBurnAlert{
+		AlertType:   "budget_rate",
+		Threshold:   5,
+		WindowHours: 24,
+		Recipients:  []discovery.DiscoveredRecipient{{Type: "pagerduty", Target: "api-oncall"}},
+	})
+	ds.DefaultRecipients = []discovery.DiscoveredRecipient{{Type: "slack", Target: "#api"}}
+
+	resources, err := FromBuild(output, discovered)
+	require.NoError(t, err)
+
+	byKey := make(map[string]Resource)
+	for _, r := range resources {
+		byKey[r.Key()] = r
+	}
+	assert.Contains(t, string(byKey["burn_alerts/Availability/0"].Body), `"recipients":[{"target":"#api","type":"slack"}]`)
+	assert.Contains(t, string(byKey["burn_alerts/Availability/1"].Body), `"recipients":[{"target":"api-oncall","type":"pagerduty"}]`)
+}
+
+func TestPlan_DependencyOrder(t *testing.T) {
+	output, discovered := testBuild()
+	resources, err := FromBuild(output, discovered)
+	require.NoError(t, err)
+
+	plan, err := Plan(resources, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"queries/Errors",
+		"queries/Good",
+		"queries/Latency",
+		"slos/Availability",
+		"burn_alerts/Availability/0",
+		"triggers/HighErrors",
+		"boards/Overview",
+	}, keys(plan))
+}
+
+func TestPlan_OnlyIncludesDependencies(t *testing.T) {
+	output, discovered := testBuild()
+	resources, err := FromBuild(output, discovered)
+	require.NoError(t, err)
+
+	sel, err := ParseSelector("type=slo")
+	require.NoError(t, err)
+
+	plan, err := Plan(resources, []Selector{sel})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"queries/Good", "slos/Availability", "burn_alerts/Availability/0"}, keys(plan))
+
+	sel, err = ParseSelector("name=HighErrors")
+	require.NoError(t, err)
+
+	plan, err = Plan(resources, []Selector{sel})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"queries/Errors", "triggers/HighErrors"}, keys(plan))
+}
+
+func TestPlan_Cycle(t *testing.T) {
+	resources := []Resource{
+		{Type: TypeQuery, Name: "A", DependsOn: []string{"queries/B"}},
+		{Type: TypeQuery, Name: "B", DependsOn: []string{"queries/A"}},
+	}
+
+	_, err := Plan(resources, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cycle")
+}
+
+func TestParseSelector(t *testing.T) {
+	sel, err := ParseSelector("type=triggers")
+	require.NoError(t, err)
+	assert.Equal(t, Selector{Field: "type", Value: TypeTrigger}, sel)
+
+	for _, invalid := range []string{"slo", "type=", "type=dashboard", "kind=slo"} {
+		_, err := ParseSelector(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
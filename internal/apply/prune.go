@@ -0,0 +1,89 @@
+package apply
+
+import (
+	"context"
+	"sort"
+
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/annotate"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lock"
+)
+
+// ActionDelete is the action taken on pruned resources.
+const ActionDelete Action = "delete"
+
+// FindPrunable returns the live resources recorded in the lockfile whose
+// definitions no longer exist in the build output. Only boards, SLOs and
+// triggers can be pruned (queries are immutable; burn alerts are deleted
+// with their SLO). The lockfile records names, not IDs, so a live resource
+// is only prunable when it carries the managed annotation; the others with
+// a recorded name, such as a board made in the UI under a name the code
+// used to have, are returned as unmanaged. The prunable resources are in
+// deletion order: boards, then triggers, then SLOs.
+func FindPrunable(ctx context.Context, client Client, locked *lock.Lockfile, output *honeycomb.Manifest) (prunable, unmanaged []Resource, err error) {
+	live := newLiveIndex(client)
+
+	for _, key := range locked.Keys() {
+		resourceType, name := lock.SplitKey(key)
+		if _, inCode := output.Get(resourceType, name); inCode {
+			continue
+		}
+
+		entry := locked.Resources[key]
+		r := Resource{Type: resourceType, Name: name, Title: entry.Name, Dataset: entry.Dataset}
+		if !prunableType(resourceType) || r.Title == "" || (r.Dataset == "" && resourceType != TypeBoard) {
+			continue
+		}
+
+		existing, err := live.find(ctx, r, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		if existing == nil {
+			continue
+		}
+		r.Body = existing
+		if !annotate.IsManaged(existing) {
+			unmanaged = append(unmanaged, r)
+			continue
+		}
+		prunable = append(prunable, r)
+	}
+
+	sort.SliceStable(prunable, func(i, j int) bool {
+		return typeRank[prunable[i].Type] > typeRank[prunable[j].Type]
+	})
+
+	return prunable, unmanaged, nil
+}
+
+// prunableType reports whether resources of the given type can be pruned.
+func prunableType(resourceType string) bool {
+	return resourceType == TypeBoard || resourceType == TypeSLO || resourceType == TypeTrigger
+}
+
+// Prune deletes the given live resources, as returned by FindPrunable.
+// Deletion continues past failures so that one bad resource does not block
+// the rest.
+func Prune(ctx context.Context, client Client, resources []Resource, dryRun bool) []Result {
+	var results []Result
+	for _, r := range resources {
+		result := Result{
+			Key:    r.Key(),
+			Type:   r.Type,
+			Name:   r.Name,
+			Action: ActionDelete,
+			Status: StatusPlanned,
+			ID:     resourceID(r.Body),
+		}
+		if !dryRun {
+			result.Status = StatusApplied
+			if err := client.DeleteResource(ctx, r.Type, r.Dataset, result.ID); err != nil {
+				result.Status = StatusFailed
+				result.Error = err.Error()
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,60 @@
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/annotate"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindPrunable(t *testing.T) {
+	client := newMemoryClient()
+	managed := func(name string) json.RawMessage {
+		body, err := annotate.Resource(json.RawMessage(`{"name": "`+name+`"}`), "")
+		require.NoError(t, err)
+		return body
+	}
+	client.put("triggers/api", "t1", managed("Old Trigger"))
+	client.put("slos/api", "s1", managed("Old SLO"))
+	client.put("boards/", "b1", managed("Old Board"))
+	client.put("triggers/api", "t2", json.RawMessage(`{"name": "Unmanaged"}`))
+	// Made in the UI under the name of a removed board
+	client.put("boards/", "b2", json.RawMessage(`{"name": "Reused Board", "description": "Made by hand"}`))
+
+	locked := &lock.Lockfile{Version: lock.Version, Resources: map[string]lock.Entry{
+		"triggers/OldTrigger": {Name: "Old Trigger", Dataset: "api", Hash: "x"},
+		"slos/OldSLO":         {Name: "Old SLO", Dataset: "api", Hash: "x"},
+		"boards/OldBoard":     {Name: "Old Board", Hash: "x"},
+		"triggers/Kept":       {Name: "Kept", Dataset: "api", Hash: "x"},
+		"triggers/Gone":       {Name: "Already Deleted", Dataset: "api", Hash: "x"},
+		"queries/OldQuery":    {Hash: "x"},
+		"boards/Reused":       {Name: "Reused Board", Hash: "x"},
+	}}
+	output := &honeycomb.Manifest{
+		Triggers: map[string]json.RawMessage{"Kept": json.RawMessage(`{"name": "Kept"}`)},
+	}
+
+	prunable, unmanaged, err := FindPrunable(context.Background(), client, locked, output)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"boards/OldBoard", "triggers/OldTrigger", "slos/OldSLO"}, keys(prunable))
+	assert.Equal(t, []string{"boards/Reused"}, keys(unmanaged))
+
+	results := Prune(context.Background(), client, prunable, true)
+	require.Len(t, results, 3)
+	assert.Equal(t, StatusPlanned, results[0].Status)
+	assert.Equal(t, "b1", results[0].ID)
+	assert.Empty(t, client.calls)
+
+	results = Prune(context.Background(), client, prunable, false)
+	for _, r := range results {
+		assert.Equal(t, StatusApplied, r.Status, r.Key)
+		assert.Equal(t, ActionDelete, r.Action, r.Key)
+	}
+	assert.Len(t, client.resources["triggers/api"], 1)
+	assert.Empty(t, client.resources["slos/api"])
+}
@@ -0,0 +1,91 @@
+// Package atomicfile writes files atomically: the data goes to a temporary
+// file in the same directory, which is then renamed over the target. Readers
+// of the target, such as an applier polling build output, see its old content
+// or the new one, never a partial write, even when the writer crashes.
+package atomicfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// fsync is whether WriteFile flushes to disk (see SetSync).
+var fsync bool
+
+// SetSync makes WriteFile flush the file, and the directory entry of the
+// rename, to disk before returning, so written output also survives a power
+// loss or kernel crash. It is off by default: fsync is slow on some
+// filesystems. The --fsync flag sets it.
+func SetSync(enabled bool) {
+	fsync = enabled
+}
+
+// Sync reports whether WriteFile flushes to disk.
+func Sync() bool {
+	return fsync
+}
+
+// WriteFile writes data to path atomically, fsyncing when Sync is on. Like
+// os.WriteFile, an existing file keeps its permissions and a new one gets
+// perm. A symlink at path is followed, so its target is replaced rather than
+// the link.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := os.CreateTemp(dir, "."+name+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	// Once renamed, the remove fails harmlessly
+	defer os.Remove(tmp)
+
+	sync := Sync()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if sync {
+		if err := f.Sync(); err != nil {
+			f.Close()
+			return fmt.Errorf("fsync %s: %w", path, err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+
+	if sync {
+		return syncDir(dir)
+	}
+	return nil
+}
+
+// syncDir flushes the entries of dir, recording a rename into it.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("fsync %s: %w", dir, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.json")
+
+	require.NoError(t, WriteFile(path, []byte(`{"a": 1}`), 0640))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{"a": 1}`, string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "the temporary file should be renamed")
+}
+
+func TestWriteFile_Replace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.json")
+	require.NoError(t, os.WriteFile(path, []byte("old content, longer than the new"), 0600))
+
+	SetSync(true)
+	t.Cleanup(func() { SetSync(false) })
+	require.NoError(t, WriteFile(path, []byte("new"), 0644))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "an existing file keeps its permissions")
+}
+
+func TestWriteFile_Symlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.json")
+	link := filepath.Join(dir, "link.json")
+	require.NoError(t, os.WriteFile(target, []byte("old"), 0644))
+	require.NoError(t, os.Symlink(target, link))
+
+	require.NoError(t, WriteFile(link, []byte("new"), 0644))
+
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode().Type(), "the link should be kept")
+}
+
+func TestWriteFile_MissingDirectory(t *testing.T) {
+	err := WriteFile(filepath.Join(t.TempDir(), "missing", "out.json"), []byte("x"), 0644)
+	assert.Error(t, err)
+}
+
+func TestSetSync(t *testing.T) {
+	t.Cleanup(func() { SetSync(false) })
+	assert.False(t, Sync())
+	SetSync(true)
+	assert.True(t, Sync())
+}
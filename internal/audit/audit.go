@@ -0,0 +1,254 @@
+// Package audit appends records of build, apply and import operations to a
+// JSON Lines audit log, so organizations can show who changed their Honeycomb
+// resources, when, from which commit and with which API calls.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// EnvLog names the audit log file when no --audit-log flag is given, so a
+// CI environment can audit every run without changing its commands.
+const EnvLog = "WETWIRE_HONEYCOMB_AUDIT_LOG"
+
+// Record is one line of the audit log.
+type Record struct {
+	// Time is when the operation started
+	Time time.Time `json:"time"`
+
+	// User is the operating system user running the command
+	User string `json:"user"`
+
+	// Host is the name of the machine running the command
+	Host string `json:"host,omitempty"`
+
+	// Operation is the command: build, apply or import
+	Operation string `json:"operation"`
+
+	// Path is the package path or import source the operation read
+	Path string `json:"path"`
+
+	// GitSHA is the commit checked out in the repository containing Path
+	GitSHA string `json:"git_sha,omitempty"`
+
+	// Version is the wetwire-honeycomb version
+	Version string `json:"version"`
+
+	// Environments are the named environments of an apply ("default" for
+	// HONEYCOMB_API_KEY)
+	Environments []string `json:"environments,omitempty"`
+
+	// DryRun is true when no change was made in Honeycomb
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// Resources counts the resources of the operation by type
+	Resources map[string]int `json:"resources,omitempty"`
+
+	// Changes counts the results of an apply by action and status, e.g.
+	// "create/applied"
+	Changes map[string]int `json:"changes,omitempty"`
+
+	// APICalls are the Honeycomb API requests made, in order
+	APICalls []APICall `json:"api_calls,omitempty"`
+
+	// DurationMS is how long the operation took
+	DurationMS int64 `json:"duration_ms"`
+
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// NewRecord starts a record of operation on path. The git commit is looked up
+// from dir.
+func NewRecord(operation, path, dir, version string) *Record {
+	host, _ := os.Hostname()
+	return &Record{
+		Time:      time.Now().UTC(),
+		User:      currentUser(),
+		Host:      host,
+		Operation: operation,
+		Path:      path,
+		GitSHA:    GitSHA(dir),
+		Version:   version,
+	}
+}
+
+// Finish records the duration and the outcome of the operation.
+func (r *Record) Finish(err error) {
+	r.DurationMS = time.Since(r.Time).Milliseconds()
+	r.Success = err == nil
+	if err != nil {
+		r.Error = err.Error()
+	}
+}
+
+// Append writes r as a line at the end of file, creating the file (readable
+// only by its owner) and its directory when needed.
+func Append(file string, r *Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("audit log: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("audit log: %w", err)
+	}
+
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("audit log: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("audit log: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("audit log: %w", err)
+	}
+	return nil
+}
+
+// Read returns the records of an audit log, oldest first.
+func Read(file string) ([]Record, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for i, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var r Record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, i+1, err)
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// CountKeys counts the occurrences of each key, or returns nil for none.
+func CountKeys(keys []string) map[string]int {
+	if len(keys) == 0 {
+		return nil
+	}
+	counts := make(map[string]int)
+	for _, k := range keys {
+		counts[k]++
+	}
+	return counts
+}
+
+// currentUser returns the login name of the user running the process.
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	for _, env := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(env); name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// GitSHA returns the commit checked out in the git repository containing dir,
+// or "" when dir is not in a repository. The repository files are read
+// directly, so git does not need to be installed.
+func GitSHA(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for d := abs; ; d = filepath.Dir(d) {
+		if gitDir := findGitDir(d); gitDir != "" {
+			return resolveHead(gitDir)
+		}
+		if filepath.Dir(d) == d {
+			return ""
+		}
+	}
+}
+
+// findGitDir returns the git directory of a repository rooted at dir: dir/.git,
+// or the directory a worktree's .git file points to.
+func findGitDir(dir string) string {
+	gitPath := filepath.Join(dir, ".git")
+	info, err := os.Stat(gitPath)
+	if err != nil {
+		return ""
+	}
+	if info.IsDir() {
+		return gitPath
+	}
+
+	data, err := os.ReadFile(gitPath)
+	if err != nil {
+		return ""
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return ""
+	}
+	target = strings.TrimSpace(target)
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, target)
+	}
+	return target
+}
+
+// resolveHead returns the commit HEAD of gitDir points to.
+func resolveHead(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	head := strings.TrimSpace(string(data))
+	ref, ok := strings.CutPrefix(head, "ref:")
+	if !ok {
+		return head
+	}
+	ref = strings.TrimSpace(ref)
+
+	// Worktrees keep their HEAD locally and share refs with the main repository
+	dirs := []string{gitDir}
+	if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		c := strings.TrimSpace(string(common))
+		if !filepath.IsAbs(c) {
+			c = filepath.Join(gitDir, c)
+		}
+		dirs = append(dirs, c)
+	}
+
+	for _, d := range dirs {
+		if sha, err := os.ReadFile(filepath.Join(d, filepath.FromSlash(ref))); err == nil {
+			return strings.TrimSpace(string(sha))
+		}
+		if sha := packedRef(filepath.Join(d, "packed-refs"), ref); sha != "" {
+			return sha
+		}
+	}
+	return ""
+}
+
+// packedRef looks up ref in a packed-refs file.
+func packedRef(file, ref string) string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if sha, name, ok := strings.Cut(strings.TrimSpace(line), " "); ok && name == ref {
+			return sha
+		}
+	}
+	return ""
+}
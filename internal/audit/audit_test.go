@@ -0,0 +1,148 @@
+package audit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sha = "0123456789abcdef0123456789abcdef01234567"
+
+func TestAppendAndRead(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "logs", "audit.jsonl")
+
+	first := NewRecord("build", "./queries", t.TempDir(), "v1.2.3")
+	first.Resources = map[string]int{"queries": 3}
+	first.Finish(nil)
+	require.NoError(t, Append(file, first))
+
+	second := NewRecord("apply", "./queries", t.TempDir(), "v1.2.3")
+	second.Finish(errors.New("apply failed"))
+	require.NoError(t, Append(file, second))
+
+	info, err := os.Stat(file)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	records, err := Read(file)
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+
+	assert.Equal(t, "build", records[0].Operation)
+	assert.Equal(t, map[string]int{"queries": 3}, records[0].Resources)
+	assert.True(t, records[0].Success)
+	assert.NotEmpty(t, records[0].User)
+	assert.Equal(t, "v1.2.3", records[0].Version)
+
+	assert.Equal(t, "apply", records[1].Operation)
+	assert.False(t, records[1].Success)
+	assert.Equal(t, "apply failed", records[1].Error)
+}
+
+func TestRead_Invalid(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "audit.jsonl")
+	require.NoError(t, os.WriteFile(file, []byte("{}\nnot json\n"), 0600))
+
+	_, err := Read(file)
+	assert.ErrorContains(t, err, ":2:")
+}
+
+func TestCountKeys(t *testing.T) {
+	assert.Nil(t, CountKeys(nil))
+	assert.Equal(t, map[string]int{"queries": 2, "boards": 1}, CountKeys([]string{"queries", "boards", "queries"}))
+}
+
+// writeFile writes data to dir/name, creating parent directories.
+func writeFile(t *testing.T, dir, name, data string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+}
+
+func TestGitSHA(t *testing.T) {
+	t.Run("loose ref", func(t *testing.T) {
+		repo := t.TempDir()
+		writeFile(t, repo, ".git/HEAD", "ref: refs/heads/main\n")
+		writeFile(t, repo, ".git/refs/heads/main", sha+"\n")
+		sub := filepath.Join(repo, "observability", "queries")
+		require.NoError(t, os.MkdirAll(sub, 0755))
+
+		assert.Equal(t, sha, GitSHA(sub))
+	})
+
+	t.Run("packed ref", func(t *testing.T) {
+		repo := t.TempDir()
+		writeFile(t, repo, ".git/HEAD", "ref: refs/heads/main\n")
+		writeFile(t, repo, ".git/packed-refs", "# pack-refs with: peeled fully-peeled sorted\n"+sha+" refs/heads/main\n")
+
+		assert.Equal(t, sha, GitSHA(repo))
+	})
+
+	t.Run("detached", func(t *testing.T) {
+		repo := t.TempDir()
+		writeFile(t, repo, ".git/HEAD", sha+"\n")
+
+		assert.Equal(t, sha, GitSHA(repo))
+	})
+
+	t.Run("worktree", func(t *testing.T) {
+		main := t.TempDir()
+		writeFile(t, main, ".git/refs/heads/feature", sha+"\n")
+		writeFile(t, main, ".git/worktrees/wt/HEAD", "ref: refs/heads/feature\n")
+		writeFile(t, main, ".git/worktrees/wt/commondir", "../..\n")
+
+		wt := t.TempDir()
+		writeFile(t, wt, ".git", "gitdir: "+filepath.Join(main, ".git", "worktrees", "wt")+"\n")
+
+		assert.Equal(t, sha, GitSHA(wt))
+	})
+}
+
+func TestRecorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/1/boards" {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	var recorder Recorder
+	client := &http.Client{Transport: recorder.Transport("prod", nil)}
+
+	var wg sync.WaitGroup
+	for _, path := range []string{"/1/boards", "/1/slos/api"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodPost, server.URL+path, nil)
+			req.Header.Set("X-Honeycomb-Team", "secret")
+			if resp, err := client.Do(req); assert.NoError(t, err) {
+				resp.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	calls := recorder.Calls()
+	assert.ElementsMatch(t, []APICall{
+		{Environment: "prod", Method: http.MethodPost, Path: "/1/boards", Status: http.StatusCreated},
+		{Environment: "prod", Method: http.MethodPost, Path: "/1/slos/api", Status: http.StatusNotFound},
+	}, calls)
+
+	// A request without a response is recorded with status 0
+	_, err := (&http.Client{Transport: recorder.Transport("", nil)}).Get("http://127.0.0.1:0/1/auth")
+	require.Error(t, err)
+	calls = recorder.Calls()
+	require.Len(t, calls, 3)
+	assert.Equal(t, APICall{Method: http.MethodGet, Path: "/1/auth"}, calls[2])
+}
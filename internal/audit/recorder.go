@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"net/http"
+	"sync"
+)
+
+// APICall is a Honeycomb API request made during an operation. Only the
+// method, path and status are recorded; headers, and so API keys, never are.
+type APICall struct {
+	// Environment names the environment of a multi-environment apply
+	Environment string `json:"environment,omitempty"`
+
+	Method string `json:"method"`
+	Path   string `json:"path"`
+
+	// Status is the HTTP status code, or 0 when no response was received
+	Status int `json:"status"`
+}
+
+// Recorder collects the API calls made through its transports. It is safe
+// for concurrent use.
+type Recorder struct {
+	mu    sync.Mutex
+	calls []APICall
+}
+
+// Transport returns an http.RoundTripper that sends requests through base
+// (http.DefaultTransport when nil) and records them as calls to environment.
+func (r *Recorder) Transport(environment string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &recordingTransport{recorder: r, environment: environment, base: base}
+}
+
+// Calls returns the recorded calls in the order they completed.
+func (r *Recorder) Calls() []APICall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]APICall(nil), r.calls...)
+}
+
+func (r *Recorder) record(call APICall) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+// recordingTransport records each request it sends.
+type recordingTransport struct {
+	recorder    *Recorder
+	environment string
+	base        http.RoundTripper
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+
+	call := APICall{Environment: t.environment, Method: req.Method, Path: req.URL.Path}
+	if resp != nil {
+		call.Status = resp.StatusCode
+	}
+	t.recorder.record(call)
+	return resp, err
+}
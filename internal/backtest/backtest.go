@@ -0,0 +1,139 @@
+// Package backtest replays Honeycomb queries over historical data to evaluate
+// how SLOs and triggers would have behaved.
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+// MaxQueryWindow is the longest time range requested in a single query.
+// Longer backtests are split into consecutive windows.
+const MaxQueryWindow = 7 * 24 * time.Hour
+
+// QueryRunner executes a query against the Honeycomb Query Data API.
+// It is satisfied by *honeycomb.Client.
+type QueryRunner interface {
+	RunQuery(ctx context.Context, dataset string, q query.Query) (*honeycomb.QueryResult, error)
+}
+
+// Bucket is the aggregated value of a query over one time bucket.
+type Bucket struct {
+	// Start is the beginning of the bucket
+	Start time.Time
+
+	// Value is the first calculation's value for the bucket
+	Value float64
+}
+
+// maxBucketsPerQuery is the most time buckets Honeycomb returns for one query.
+const maxBucketsPerQuery = 1000
+
+// FetchSeries runs q over [start, end) with the given bucket size and returns one
+// Bucket per step. Breakdowns, orders and limits are dropped so each bucket holds
+// the total for the first calculation (COUNT when none is declared).
+func FetchSeries(ctx context.Context, runner QueryRunner, dataset string, q query.Query, start, end time.Time, step time.Duration) ([]Bucket, error) {
+	q.Breakdowns = nil
+
+	groups, err := FetchGroupedSeries(ctx, runner, dataset, q, start, end, step)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[int64]float64)
+	for _, buckets := range groups {
+		for _, b := range buckets {
+			totals[b.Start.Unix()] += b.Value
+		}
+	}
+
+	var buckets []Bucket
+	for t := start; t.Before(end); t = t.Add(step) {
+		buckets = append(buckets, Bucket{Start: t, Value: totals[t.Unix()]})
+	}
+
+	return buckets, nil
+}
+
+// FetchGroupedSeries runs q over [start, end) with the given bucket size and
+// returns one series per breakdown group, keyed by the group's breakdown values
+// joined with ", " (the empty string when q has no breakdowns). Orders and
+// limits are dropped and only the first calculation is kept.
+func FetchGroupedSeries(ctx context.Context, runner QueryRunner, dataset string, q query.Query, start, end time.Time, step time.Duration) (map[string][]Bucket, error) {
+	if step <= 0 {
+		return nil, fmt.Errorf("step must be positive")
+	}
+	if !end.After(start) {
+		return nil, fmt.Errorf("end must be after start")
+	}
+
+	if len(q.Calculations) == 0 {
+		q.Calculations = []query.Calculation{query.Count()}
+	}
+	q.Calculations = q.Calculations[:1]
+	q.Orders = nil
+	q.Limit = 0
+	q.Granularity = int(step.Seconds())
+	key := honeycomb.CalculationKey(q.Calculations[0])
+
+	window := MaxQueryWindow
+	if limit := step * maxBucketsPerQuery; limit < window {
+		window = limit
+	}
+
+	totals := make(map[string]map[int64]float64)
+	for windowStart := start; windowStart.Before(end); windowStart = windowStart.Add(window) {
+		windowEnd := windowStart.Add(window)
+		if windowEnd.After(end) {
+			windowEnd = end
+		}
+
+		q.TimeRange = query.TimeRange{
+			StartTime: int(windowStart.Unix()),
+			EndTime:   int(windowEnd.Unix()),
+		}
+
+		res, err := runner.RunQuery(ctx, dataset, q)
+		if err != nil {
+			return nil, fmt.Errorf("query %s to %s: %w", windowStart.Format(time.RFC3339), windowEnd.Format(time.RFC3339), err)
+		}
+
+		for _, row := range res.Series {
+			group := groupKey(row, q.Breakdowns)
+			if totals[group] == nil {
+				totals[group] = make(map[int64]float64)
+			}
+			bucket := start.Add(row.Time.Sub(start) / step * step)
+			totals[group][bucket.Unix()] += row.Value(key)
+		}
+	}
+
+	if len(totals) == 0 {
+		totals[""] = nil
+	}
+
+	series := make(map[string][]Bucket, len(totals))
+	for group, values := range totals {
+		for t := start; t.Before(end); t = t.Add(step) {
+			series[group] = append(series[group], Bucket{Start: t, Value: values[t.Unix()]})
+		}
+	}
+
+	return series, nil
+}
+
+// groupKey joins a row's breakdown values into a single group name.
+func groupKey(row honeycomb.ResultRow, breakdowns []string) string {
+	values := make([]string, len(breakdowns))
+	for i, b := range breakdowns {
+		if v, ok := row.Data[b]; ok && v != nil {
+			values[i] = fmt.Sprint(v)
+		}
+	}
+	return strings.Join(values, ", ")
+}
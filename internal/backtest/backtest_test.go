@@ -0,0 +1,72 @@
+package backtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner returns one series row per granularity step with a fixed value
+// and records the queries it receives.
+type fakeRunner struct {
+	value   float64
+	queries []query.Query
+}
+
+func (f *fakeRunner) RunQuery(ctx context.Context, dataset string, q query.Query) (*honeycomb.QueryResult, error) {
+	f.queries = append(f.queries, q)
+
+	key := honeycomb.CalculationKey(q.Calculations[0])
+	res := &honeycomb.QueryResult{}
+	for t := q.TimeRange.StartTime; t < q.TimeRange.EndTime; t += q.Granularity {
+		res.Series = append(res.Series, honeycomb.ResultRow{
+			Time: time.Unix(int64(t), 0).UTC(),
+			Data: map[string]any{key: f.value},
+		})
+	}
+	return res, nil
+}
+
+func TestFetchSeries_SplitsLongWindows(t *testing.T) {
+	runner := &fakeRunner{value: 5}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(10 * 24 * time.Hour)
+
+	q := query.Query{
+		Dataset:      "production",
+		Breakdowns:   []string{"service"},
+		Calculations: []query.Calculation{query.Count(), query.P99("duration_ms")},
+		Limit:        100,
+	}
+
+	buckets, err := FetchSeries(context.Background(), runner, "production", q, start, end, time.Hour)
+	require.NoError(t, err)
+
+	assert.Len(t, buckets, 240)
+	assert.Equal(t, 5.0, buckets[0].Value)
+	assert.Equal(t, 5.0, buckets[239].Value)
+
+	require.Len(t, runner.queries, 2)
+	first := runner.queries[0]
+	assert.Equal(t, int(start.Unix()), first.TimeRange.StartTime)
+	assert.Equal(t, int(start.Add(MaxQueryWindow).Unix()), first.TimeRange.EndTime)
+	assert.Equal(t, 3600, first.Granularity)
+	assert.Nil(t, first.Breakdowns)
+	assert.Zero(t, first.Limit)
+	assert.Len(t, first.Calculations, 1)
+}
+
+func TestFetchSeries_InvalidRange(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, err := FetchSeries(context.Background(), &fakeRunner{}, "production", query.Query{}, start, start, time.Hour)
+	assert.Error(t, err)
+
+	_, err = FetchSeries(context.Background(), &fakeRunner{}, "production", query.Query{}, start, start.Add(time.Hour), 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,137 @@
+package backtest
+
+import (
+	"sort"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// SLOSummary is a periodic (typically weekly) summary of an SLO for ops reviews.
+type SLOSummary struct {
+	// Name is the SLO's Go variable name and Title its Name field
+	Name  string `json:"name"`
+	Title string `json:"title"`
+
+	// Start and End bound the reporting window
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	// Window is the SLO evaluated over the reporting window alone
+	Window SLOReport `json:"window"`
+
+	// Budget is the SLO evaluated over its full time period ending at End,
+	// which determines the error budget remaining
+	Budget SLOReport `json:"budget"`
+
+	// BurnEvents lists the burn alert firings that ended inside the reporting window
+	BurnEvents []BurnEvent `json:"burn_events,omitempty"`
+
+	// Breakdowns are the columns WorstOffenders are grouped by
+	Breakdowns []string `json:"breakdowns,omitempty"`
+
+	// WorstOffenders ranks breakdown groups by bad events in the reporting window
+	WorstOffenders []Offender `json:"worst_offenders,omitempty"`
+}
+
+// BurnEvent is a single burn alert firing.
+type BurnEvent struct {
+	Alert     string `json:"alert"`
+	AlertType string `json:"alert_type"`
+	Firing
+}
+
+// Offender is a breakdown group's contribution to an SLO's bad events.
+type Offender struct {
+	// Group is the group's breakdown values joined with ", "
+	Group string `json:"group"`
+
+	BadEvents   float64 `json:"bad_events"`
+	TotalEvents float64 `json:"total_events"`
+
+	// Share is the percentage of all bad events attributed to the group
+	Share float64 `json:"share"`
+
+	// ErrorRate is the percentage of the group's own events that were bad
+	ErrorRate float64 `json:"error_rate"`
+}
+
+// SummarizeSLO summarizes an SLO over the reporting window starting at since.
+// The samples must cover the SLO's full time period so that the error budget
+// and burn alerts are evaluated the way Honeycomb does; only samples from since
+// onwards count towards the window compliance and burn events.
+func SummarizeSLO(samples []SLOSample, target float64, alerts []discovery.DiscoveredBurnAlert, step time.Duration, since time.Time) SLOSummary {
+	summary := SLOSummary{
+		Budget: BacktestSLO(samples, target, alerts, step),
+	}
+
+	var window []SLOSample
+	for _, s := range samples {
+		if !s.Start.Before(since) {
+			window = append(window, s)
+		}
+	}
+	summary.Window = BacktestSLO(window, target, nil, step)
+	summary.Start = since
+	summary.End = summary.Budget.End
+
+	for _, alert := range summary.Budget.BurnAlerts {
+		for _, f := range alert.Firings {
+			if f.End.After(since) {
+				summary.BurnEvents = append(summary.BurnEvents, BurnEvent{
+					Alert:     alert.Name,
+					AlertType: alert.AlertType,
+					Firing:    f,
+				})
+			}
+		}
+	}
+	sort.SliceStable(summary.BurnEvents, func(i, j int) bool {
+		return summary.BurnEvents[i].Start.Before(summary.BurnEvents[j].Start)
+	})
+
+	return summary
+}
+
+// WorstOffenders ranks breakdown groups by bad events (total minus good) and
+// returns at most limit groups that had any. good and total are grouped series
+// as returned by FetchGroupedSeries.
+func WorstOffenders(good, total map[string][]Bucket, limit int) []Offender {
+	var offenders []Offender
+	var allBad float64
+	for group, buckets := range total {
+		o := Offender{Group: group}
+		for _, b := range buckets {
+			o.TotalEvents += b.Value
+		}
+		var goodEvents float64
+		for _, b := range good[group] {
+			goodEvents += b.Value
+		}
+
+		o.BadEvents = o.TotalEvents - goodEvents
+		if o.BadEvents <= 0 {
+			continue
+		}
+		if o.TotalEvents > 0 {
+			o.ErrorRate = o.BadEvents / o.TotalEvents * 100
+		}
+		allBad += o.BadEvents
+		offenders = append(offenders, o)
+	}
+
+	sort.Slice(offenders, func(i, j int) bool {
+		if offenders[i].BadEvents != offenders[j].BadEvents {
+			return offenders[i].BadEvents > offenders[j].BadEvents
+		}
+		return offenders[i].Group < offenders[j].Group
+	})
+	if limit > 0 && len(offenders) > limit {
+		offenders = offenders[:limit]
+	}
+	for i := range offenders {
+		offenders[i].Share = offenders[i].BadEvents / allBad * 100
+	}
+
+	return offenders
+}
@@ -0,0 +1,78 @@
+package backtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeSLO(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// 10 hours at 99% target => 100 bad events allowed over the period.
+	// An early spike falls before the reporting window, a later one inside it.
+	samples := hourlySamples(start, []float64{40, 0, 0, 0, 0, 0, 30, 0, 0, 0})
+	since := start.Add(5 * time.Hour)
+
+	alerts := []discovery.DiscoveredBurnAlert{
+		{AlertType: "budget_rate", Threshold: 25, WindowHours: 1},
+	}
+	summary := SummarizeSLO(samples, 99.0, alerts, time.Hour, since)
+
+	assert.Equal(t, since, summary.Start)
+	assert.Equal(t, start.Add(10*time.Hour), summary.End)
+
+	// Budget covers the full period: 70 of 100 allowed bad events
+	assert.InDelta(t, 30.0, summary.Budget.BudgetRemaining, 0.0001)
+
+	// Window covers the last 5 hours only
+	assert.Equal(t, 5000.0, summary.Window.TotalEvents)
+	assert.InDelta(t, 99.4, summary.Window.Compliance, 0.0001)
+
+	require.Len(t, summary.BurnEvents, 1)
+	assert.Equal(t, "budget_rate 1h", summary.BurnEvents[0].Alert)
+	assert.Equal(t, start.Add(7*time.Hour), summary.BurnEvents[0].Start)
+}
+
+func TestWorstOffenders(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	series := func(values ...float64) []Bucket {
+		buckets := make([]Bucket, len(values))
+		for i, v := range values {
+			buckets[i] = Bucket{Start: start.Add(time.Duration(i) * time.Hour), Value: v}
+		}
+		return buckets
+	}
+
+	total := map[string][]Bucket{
+		"checkout": series(100, 100),
+		"search":   series(500, 500),
+		"login":    series(50, 50),
+		"health":   series(10, 10),
+	}
+	good := map[string][]Bucket{
+		"checkout": series(70, 90),
+		"search":   series(495, 495),
+		"login":    series(50, 50),
+		// health has no good events at all
+	}
+
+	offenders := WorstOffenders(good, total, 2)
+	require.Len(t, offenders, 2)
+
+	assert.Equal(t, "checkout", offenders[0].Group)
+	assert.Equal(t, 40.0, offenders[0].BadEvents)
+	assert.InDelta(t, 20.0, offenders[0].ErrorRate, 0.0001)
+	// 40 + 20 + 10 = 70 bad events in total
+	assert.InDelta(t, 40.0/70*100, offenders[0].Share, 0.0001)
+
+	assert.Equal(t, "health", offenders[1].Group)
+	assert.Equal(t, 20.0, offenders[1].BadEvents)
+
+	// Groups without bad events are never offenders
+	for _, o := range WorstOffenders(good, total, 0) {
+		assert.NotEqual(t, "login", o.Group)
+	}
+}
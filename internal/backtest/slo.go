@@ -0,0 +1,201 @@
+package backtest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// SLOSample holds the good and total event counts for one time bucket.
+type SLOSample struct {
+	Start time.Time
+	Good  float64
+	Total float64
+}
+
+// SLOReport summarizes how an SLO would have performed over the backtest window.
+type SLOReport struct {
+	// Start and End bound the backtest window
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	// TargetPercentage is the SLO target (e.g. 99.9)
+	TargetPercentage float64 `json:"target_percentage"`
+
+	// GoodEvents and TotalEvents are the event counts over the window
+	GoodEvents  float64 `json:"good_events"`
+	TotalEvents float64 `json:"total_events"`
+
+	// Compliance is the percentage of good events over the window
+	Compliance float64 `json:"compliance"`
+
+	// Met reports whether compliance reached the target
+	Met bool `json:"met"`
+
+	// BudgetConsumed is the percentage of the error budget burned
+	BudgetConsumed float64 `json:"budget_consumed"`
+
+	// BudgetRemaining is the percentage of the error budget left (negative when overspent)
+	BudgetRemaining float64 `json:"budget_remaining"`
+
+	// BurnAlerts reports when each configured burn alert would have fired
+	BurnAlerts []BurnAlertResult `json:"burn_alerts,omitempty"`
+}
+
+// BurnAlertResult lists the firings of a single burn alert.
+type BurnAlertResult struct {
+	Name        string   `json:"name"`
+	AlertType   string   `json:"alert_type"`
+	Threshold   float64  `json:"threshold"`
+	WindowHours int      `json:"window_hours"`
+	Firings     []Firing `json:"firings,omitempty"`
+}
+
+// Firing is a contiguous period during which an alert condition held.
+type Firing struct {
+	// Start is the end of the first bucket (or evaluation) in which the condition held
+	Start time.Time `json:"start"`
+
+	// End is the end of the last bucket (or evaluation) in which the condition held
+	End time.Time `json:"end"`
+
+	// Peak is the most severe value observed while firing: percent of budget
+	// burned for budget_rate alerts, hours to exhaustion for exhaustion_time alerts,
+	// and the query value furthest past the threshold for triggers
+	Peak float64 `json:"peak"`
+}
+
+// MergeSLISeries combines good and total buckets into SLO samples.
+// Both series must have been fetched with the same start and step.
+func MergeSLISeries(good, total []Bucket) ([]SLOSample, error) {
+	if len(good) != len(total) {
+		return nil, fmt.Errorf("good and total series differ in length (%d vs %d)", len(good), len(total))
+	}
+
+	samples := make([]SLOSample, len(total))
+	for i := range total {
+		if !good[i].Start.Equal(total[i].Start) {
+			return nil, fmt.Errorf("good and total series are misaligned at %s", total[i].Start.Format(time.RFC3339))
+		}
+		samples[i] = SLOSample{
+			Start: total[i].Start,
+			Good:  good[i].Value,
+			Total: total[i].Value,
+		}
+	}
+	return samples, nil
+}
+
+// BacktestSLO evaluates an SLO target and its burn alerts against historical samples.
+//
+// The error budget is the number of bad events allowed over the whole backtest window
+// ((100 - target)% of total events). Budget rate alerts fire when the bad events in
+// their trailing window exceed Threshold percent of that budget. Exhaustion time alerts
+// fire when the burn rate over their trailing window would exhaust the remaining budget
+// within Threshold hours.
+func BacktestSLO(samples []SLOSample, target float64, alerts []discovery.DiscoveredBurnAlert, step time.Duration) SLOReport {
+	report := SLOReport{TargetPercentage: target}
+	if len(samples) == 0 {
+		return report
+	}
+
+	report.Start = samples[0].Start
+	report.End = samples[len(samples)-1].Start.Add(step)
+
+	for _, s := range samples {
+		report.GoodEvents += s.Good
+		report.TotalEvents += s.Total
+	}
+
+	if report.TotalEvents > 0 {
+		report.Compliance = report.GoodEvents / report.TotalEvents * 100
+	} else {
+		report.Compliance = 100
+	}
+	report.Met = report.Compliance >= target
+
+	allowedBad := (100 - target) / 100 * report.TotalEvents
+	bad := report.TotalEvents - report.GoodEvents
+	if allowedBad > 0 {
+		report.BudgetConsumed = bad / allowedBad * 100
+	} else if bad > 0 {
+		report.BudgetConsumed = 100
+	}
+	report.BudgetRemaining = 100 - report.BudgetConsumed
+
+	for _, alert := range alerts {
+		report.BurnAlerts = append(report.BurnAlerts, evaluateBurnAlert(samples, alert, allowedBad, step))
+	}
+
+	return report
+}
+
+// evaluateBurnAlert replays a single burn alert over the samples.
+func evaluateBurnAlert(samples []SLOSample, alert discovery.DiscoveredBurnAlert, allowedBad float64, step time.Duration) BurnAlertResult {
+	result := BurnAlertResult{
+		Name:        alert.Name,
+		AlertType:   alert.AlertType,
+		Threshold:   alert.Threshold,
+		WindowHours: alert.WindowHours,
+	}
+	if result.Name == "" {
+		result.Name = fmt.Sprintf("%s %dh", alert.AlertType, alert.WindowHours)
+	}
+	if allowedBad <= 0 || alert.WindowHours <= 0 {
+		return result
+	}
+
+	windowBuckets := int(time.Duration(alert.WindowHours) * time.Hour / step)
+	if windowBuckets < 1 {
+		windowBuckets = 1
+	}
+
+	var current *Firing
+	var windowBad, cumulativeBad float64
+	for i, s := range samples {
+		badInBucket := s.Total - s.Good
+		windowBad += badInBucket
+		cumulativeBad += badInBucket
+		if i >= windowBuckets {
+			windowBad -= samples[i-windowBuckets].Total - samples[i-windowBuckets].Good
+		}
+
+		var firing bool
+		var value float64
+		switch alert.AlertType {
+		case "exhaustion_time":
+			ratePerHour := windowBad / float64(alert.WindowHours)
+			if ratePerHour > 0 {
+				value = (allowedBad - cumulativeBad) / ratePerHour
+				if value < 0 {
+					value = 0
+				}
+				firing = value <= alert.Threshold
+			}
+		default:
+			value = windowBad / allowedBad * 100
+			firing = value >= alert.Threshold
+		}
+
+		bucketEnd := s.Start.Add(step)
+		switch {
+		case firing && current == nil:
+			current = &Firing{Start: bucketEnd, End: bucketEnd, Peak: value}
+		case firing:
+			current.End = bucketEnd
+			if (alert.AlertType == "exhaustion_time" && value < current.Peak) ||
+				(alert.AlertType != "exhaustion_time" && value > current.Peak) {
+				current.Peak = value
+			}
+		case current != nil:
+			result.Firings = append(result.Firings, *current)
+			current = nil
+		}
+	}
+	if current != nil {
+		result.Firings = append(result.Firings, *current)
+	}
+
+	return result
+}
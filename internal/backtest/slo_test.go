@@ -0,0 +1,124 @@
+package backtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hourlySamples builds hourly samples with 1000 total events each and the
+// given number of bad events per hour.
+func hourlySamples(start time.Time, bad []float64) []SLOSample {
+	samples := make([]SLOSample, len(bad))
+	for i, b := range bad {
+		samples[i] = SLOSample{
+			Start: start.Add(time.Duration(i) * time.Hour),
+			Good:  1000 - b,
+			Total: 1000,
+		}
+	}
+	return samples
+}
+
+func TestMergeSLISeries(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	good := []Bucket{{Start: start, Value: 9}, {Start: start.Add(time.Hour), Value: 8}}
+	total := []Bucket{{Start: start, Value: 10}, {Start: start.Add(time.Hour), Value: 10}}
+
+	samples, err := MergeSLISeries(good, total)
+	require.NoError(t, err)
+	assert.Equal(t, []SLOSample{
+		{Start: start, Good: 9, Total: 10},
+		{Start: start.Add(time.Hour), Good: 8, Total: 10},
+	}, samples)
+
+	_, err = MergeSLISeries(good[:1], total)
+	assert.Error(t, err)
+}
+
+func TestBacktestSLO_Compliance(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// 10 hours, 10000 events, 5 bad events total
+	samples := hourlySamples(start, []float64{1, 1, 1, 1, 1, 0, 0, 0, 0, 0})
+
+	report := BacktestSLO(samples, 99.9, nil, time.Hour)
+
+	assert.Equal(t, start, report.Start)
+	assert.Equal(t, start.Add(10*time.Hour), report.End)
+	assert.Equal(t, 10000.0, report.TotalEvents)
+	assert.Equal(t, 9995.0, report.GoodEvents)
+	assert.InDelta(t, 99.95, report.Compliance, 0.0001)
+	assert.True(t, report.Met)
+	// 10 bad events allowed, 5 consumed
+	assert.InDelta(t, 50.0, report.BudgetConsumed, 0.0001)
+	assert.InDelta(t, 50.0, report.BudgetRemaining, 0.0001)
+}
+
+func TestBacktestSLO_MissedTarget(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	samples := hourlySamples(start, []float64{50, 50})
+
+	report := BacktestSLO(samples, 99.0, nil, time.Hour)
+
+	assert.False(t, report.Met)
+	assert.InDelta(t, 95.0, report.Compliance, 0.0001)
+	assert.InDelta(t, 500.0, report.BudgetConsumed, 0.0001)
+	assert.InDelta(t, -400.0, report.BudgetRemaining, 0.0001)
+}
+
+func TestBacktestSLO_NoEvents(t *testing.T) {
+	report := BacktestSLO(nil, 99.9, nil, time.Hour)
+	assert.Zero(t, report.TotalEvents)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	report = BacktestSLO([]SLOSample{{Start: start}}, 99.9, nil, time.Hour)
+	assert.Equal(t, 100.0, report.Compliance)
+	assert.True(t, report.Met)
+}
+
+func TestBacktestSLO_BudgetRateAlert(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// 10 hours of 1000 events at 99% target => 100 bad events allowed.
+	// A spike of 30 bad events at hours 3-4 burns 30% of budget per hour.
+	samples := hourlySamples(start, []float64{0, 0, 0, 30, 30, 0, 0, 0, 0, 0})
+
+	alerts := []discovery.DiscoveredBurnAlert{
+		{AlertType: "budget_rate", Threshold: 25, WindowHours: 1},
+		{Name: "Slow", AlertType: "budget_rate", Threshold: 90, WindowHours: 24},
+	}
+	report := BacktestSLO(samples, 99.0, alerts, time.Hour)
+
+	require.Len(t, report.BurnAlerts, 2)
+
+	fast := report.BurnAlerts[0]
+	assert.Equal(t, "budget_rate 1h", fast.Name)
+	require.Len(t, fast.Firings, 1)
+	assert.Equal(t, start.Add(4*time.Hour), fast.Firings[0].Start)
+	assert.Equal(t, start.Add(5*time.Hour), fast.Firings[0].End)
+	assert.InDelta(t, 30.0, fast.Firings[0].Peak, 0.0001)
+
+	slow := report.BurnAlerts[1]
+	assert.Equal(t, "Slow", slow.Name)
+	assert.Empty(t, slow.Firings)
+}
+
+func TestBacktestSLO_ExhaustionTimeAlert(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// 100 bad events allowed; 40 bad per hour for two hours leaves 20,
+	// which the current rate exhausts in half an hour.
+	samples := hourlySamples(start, []float64{0, 0, 40, 40, 0, 0, 0, 0, 0, 0})
+
+	alerts := []discovery.DiscoveredBurnAlert{
+		{AlertType: "exhaustion_time", Threshold: 1, WindowHours: 1},
+	}
+	report := BacktestSLO(samples, 99.0, alerts, time.Hour)
+
+	require.Len(t, report.BurnAlerts, 1)
+	firings := report.BurnAlerts[0].Firings
+	require.Len(t, firings, 1)
+	assert.Equal(t, start.Add(4*time.Hour), firings[0].Start)
+	assert.InDelta(t, 0.5, firings[0].Peak, 0.0001)
+}
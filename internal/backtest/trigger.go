@@ -0,0 +1,234 @@
+package backtest
+
+import (
+	"sort"
+	"time"
+)
+
+// DefaultTriggerFrequency is the evaluation frequency Honeycomb uses when a
+// trigger does not declare one.
+const DefaultTriggerFrequency = 15 * time.Minute
+
+// TriggerSpec describes how a trigger evaluates its query.
+type TriggerSpec struct {
+	// Op is the calculation operation of the trigger query (e.g. COUNT, P99)
+	Op string
+
+	// ThresholdOp is the threshold operator (>, >=, <, <=)
+	ThresholdOp string
+
+	// ThresholdValue is the threshold value
+	ThresholdValue float64
+
+	// Frequency is how often the trigger is evaluated
+	Frequency time.Duration
+
+	// Duration is the query time range covered by each evaluation
+	Duration time.Duration
+}
+
+// TriggerReport summarizes how often a trigger would have fired over the
+// simulation window.
+type TriggerReport struct {
+	// Start and End bound the simulation window
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	// ThresholdOp and ThresholdValue are the simulated threshold
+	ThresholdOp    string  `json:"threshold_op"`
+	ThresholdValue float64 `json:"threshold_value"`
+
+	// FrequencySeconds and DurationSeconds describe each evaluation
+	FrequencySeconds int `json:"frequency_seconds"`
+	DurationSeconds  int `json:"duration_seconds"`
+
+	// Evaluations is the number of times the trigger would have run
+	Evaluations int `json:"evaluations"`
+
+	// TriggeredEvaluations is the number of evaluations that crossed the threshold
+	TriggeredEvaluations int `json:"triggered_evaluations"`
+
+	// Firings lists each transition into the triggered state
+	Firings []TriggerFiring `json:"firings,omitempty"`
+}
+
+// TriggerFiring is a contiguous run of triggered evaluations.
+type TriggerFiring struct {
+	Firing
+
+	// Group is the breakdown group that produced the peak value
+	Group string `json:"group,omitempty"`
+}
+
+// TriggerStep returns the bucket size needed to evaluate spec exactly: the
+// largest duration that divides both its frequency and its query duration.
+func TriggerStep(spec TriggerSpec) time.Duration {
+	spec = spec.withDefaults()
+	a, b := spec.Frequency, spec.Duration
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// withDefaults fills in Honeycomb's defaults for unset frequency and duration.
+func (s TriggerSpec) withDefaults() TriggerSpec {
+	if s.Frequency <= 0 {
+		s.Frequency = DefaultTriggerFrequency
+	}
+	if s.Duration <= 0 {
+		s.Duration = s.Frequency
+	}
+	return s
+}
+
+// SimulateTrigger replays a trigger over bucketed query results, one series per
+// breakdown group, all fetched with the same start and step.
+//
+// The trigger is evaluated every Frequency, starting once a full Duration of data
+// is available. Each evaluation aggregates the buckets in its trailing Duration
+// and is triggered when any group crosses the threshold. COUNT and SUM are summed,
+// MIN takes the minimum, AVG and RATE_AVG average the buckets and every other
+// operation (MAX, percentiles, ...) takes the maximum, so results are exact for
+// COUNT, SUM, MAX and MIN and an approximation otherwise.
+func SimulateTrigger(series map[string][]Bucket, spec TriggerSpec, step time.Duration) TriggerReport {
+	spec = spec.withDefaults()
+	report := TriggerReport{
+		ThresholdOp:      spec.ThresholdOp,
+		ThresholdValue:   spec.ThresholdValue,
+		FrequencySeconds: int(spec.Frequency.Seconds()),
+		DurationSeconds:  int(spec.Duration.Seconds()),
+	}
+
+	groups := make([]string, 0, len(series))
+	var length int
+	for group, buckets := range series {
+		groups = append(groups, group)
+		if len(buckets) > length {
+			length = len(buckets)
+		}
+	}
+	if length == 0 || step <= 0 {
+		return report
+	}
+	sort.Strings(groups)
+
+	var start time.Time
+	for _, group := range groups {
+		if len(series[group]) > 0 {
+			start = series[group][0].Start
+			break
+		}
+	}
+	report.Start = start
+	report.End = start.Add(time.Duration(length) * step)
+
+	windowBuckets := int(spec.Duration / step)
+	if windowBuckets < 1 {
+		windowBuckets = 1
+	}
+	strideBuckets := int(spec.Frequency / step)
+	if strideBuckets < 1 {
+		strideBuckets = 1
+	}
+
+	var current *TriggerFiring
+	for end := windowBuckets; end <= length; end += strideBuckets {
+		report.Evaluations++
+		evalTime := start.Add(time.Duration(end) * step)
+
+		var triggered bool
+		var peak float64
+		var peakGroup string
+		for _, group := range groups {
+			buckets := series[group]
+			if end > len(buckets) {
+				continue
+			}
+			value := aggregate(spec.Op, buckets[end-windowBuckets:end])
+			if !crosses(spec.ThresholdOp, value, spec.ThresholdValue) {
+				continue
+			}
+			if !triggered || moreSevere(spec.ThresholdOp, value, peak) {
+				peak, peakGroup = value, group
+			}
+			triggered = true
+		}
+
+		switch {
+		case triggered && current == nil:
+			report.TriggeredEvaluations++
+			current = &TriggerFiring{Firing: Firing{Start: evalTime, End: evalTime, Peak: peak}, Group: peakGroup}
+		case triggered:
+			report.TriggeredEvaluations++
+			current.End = evalTime
+			if moreSevere(spec.ThresholdOp, peak, current.Peak) {
+				current.Peak, current.Group = peak, peakGroup
+			}
+		case current != nil:
+			report.Firings = append(report.Firings, *current)
+			current = nil
+		}
+	}
+	if current != nil {
+		report.Firings = append(report.Firings, *current)
+	}
+
+	return report
+}
+
+// aggregate combines bucket values the way the calculation would over the whole window.
+func aggregate(op string, buckets []Bucket) float64 {
+	if len(buckets) == 0 {
+		return 0
+	}
+
+	result := buckets[0].Value
+	switch op {
+	case "", "COUNT", "SUM":
+		for _, b := range buckets[1:] {
+			result += b.Value
+		}
+	case "MIN":
+		for _, b := range buckets[1:] {
+			if b.Value < result {
+				result = b.Value
+			}
+		}
+	case "AVG", "RATE_AVG":
+		for _, b := range buckets[1:] {
+			result += b.Value
+		}
+		result /= float64(len(buckets))
+	default:
+		for _, b := range buckets[1:] {
+			if b.Value > result {
+				result = b.Value
+			}
+		}
+	}
+	return result
+}
+
+// crosses reports whether value satisfies the threshold condition.
+func crosses(op string, value, threshold float64) bool {
+	switch op {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	}
+	return false
+}
+
+// moreSevere reports whether a is further past the threshold than b.
+func moreSevere(op string, a, b float64) bool {
+	if op == "<" || op == "<=" {
+		return a < b
+	}
+	return a > b
+}
@@ -0,0 +1,132 @@
+package backtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// minuteBuckets builds one bucket per minute with the given values.
+func minuteBuckets(start time.Time, values []float64) []Bucket {
+	buckets := make([]Bucket, len(values))
+	for i, v := range values {
+		buckets[i] = Bucket{Start: start.Add(time.Duration(i) * time.Minute), Value: v}
+	}
+	return buckets
+}
+
+func TestTriggerStep(t *testing.T) {
+	assert.Equal(t, 5*time.Minute, TriggerStep(TriggerSpec{Frequency: 5 * time.Minute, Duration: 15 * time.Minute}))
+	assert.Equal(t, 2*time.Minute, TriggerStep(TriggerSpec{Frequency: 4 * time.Minute, Duration: 10 * time.Minute}))
+	assert.Equal(t, DefaultTriggerFrequency, TriggerStep(TriggerSpec{}))
+}
+
+func TestSimulateTrigger_CountsFirings(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	series := map[string][]Bucket{
+		"": minuteBuckets(start, []float64{1, 1, 9, 9, 1, 1, 1, 1, 9, 1}),
+	}
+	spec := TriggerSpec{
+		Op:             "COUNT",
+		ThresholdOp:    ">",
+		ThresholdValue: 9,
+		Frequency:      time.Minute,
+		Duration:       2 * time.Minute,
+	}
+
+	report := SimulateTrigger(series, spec, time.Minute)
+
+	assert.Equal(t, start, report.Start)
+	assert.Equal(t, start.Add(10*time.Minute), report.End)
+	assert.Equal(t, 9, report.Evaluations)
+	// windows ending at minutes 3, 4, 5 and 9, 10 exceed 9 events
+	assert.Equal(t, 5, report.TriggeredEvaluations)
+	require.Len(t, report.Firings, 2)
+	assert.Equal(t, start.Add(3*time.Minute), report.Firings[0].Start)
+	assert.Equal(t, start.Add(5*time.Minute), report.Firings[0].End)
+	assert.Equal(t, 18.0, report.Firings[0].Peak)
+	assert.Equal(t, start.Add(9*time.Minute), report.Firings[1].Start)
+}
+
+func TestSimulateTrigger_GroupsAndLessThan(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	series := map[string][]Bucket{
+		"api": minuteBuckets(start, []float64{5, 5, 5, 5}),
+		"web": minuteBuckets(start, []float64{5, 0, 5, 5}),
+	}
+	spec := TriggerSpec{
+		Op:             "COUNT",
+		ThresholdOp:    "<",
+		ThresholdValue: 1,
+		Frequency:      time.Minute,
+		Duration:       time.Minute,
+	}
+
+	report := SimulateTrigger(series, spec, time.Minute)
+
+	assert.Equal(t, 4, report.Evaluations)
+	require.Len(t, report.Firings, 1)
+	assert.Equal(t, "web", report.Firings[0].Group)
+	assert.Equal(t, 0.0, report.Firings[0].Peak)
+}
+
+func TestSimulateTrigger_Aggregation(t *testing.T) {
+	buckets := minuteBuckets(time.Time{}, []float64{2, 8, 4})
+
+	assert.Equal(t, 14.0, aggregate("COUNT", buckets))
+	assert.Equal(t, 2.0, aggregate("MIN", buckets))
+	assert.InDelta(t, 4.6667, aggregate("AVG", buckets), 0.001)
+	assert.Equal(t, 8.0, aggregate("P99", buckets))
+}
+
+func TestSimulateTrigger_Empty(t *testing.T) {
+	report := SimulateTrigger(nil, TriggerSpec{ThresholdOp: ">", ThresholdValue: 1}, time.Minute)
+	assert.Zero(t, report.Evaluations)
+	assert.Equal(t, 900, report.FrequencySeconds)
+}
+
+// groupedRunner returns one series row per step for each group.
+type groupedRunner struct {
+	values map[string]float64
+}
+
+func (g *groupedRunner) RunQuery(ctx context.Context, dataset string, q query.Query) (*honeycomb.QueryResult, error) {
+	key := honeycomb.CalculationKey(q.Calculations[0])
+	res := &honeycomb.QueryResult{}
+	for t := q.TimeRange.StartTime; t < q.TimeRange.EndTime; t += q.Granularity {
+		for group, v := range g.values {
+			res.Series = append(res.Series, honeycomb.ResultRow{
+				Time: time.Unix(int64(t), 0).UTC(),
+				Data: map[string]any{key: v, "service": group},
+			})
+		}
+	}
+	return res, nil
+}
+
+func TestFetchGroupedSeries(t *testing.T) {
+	runner := &groupedRunner{values: map[string]float64{"api": 1, "web": 2}}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(2 * 24 * time.Hour)
+
+	q := query.Query{
+		Breakdowns:   []string{"service"},
+		Calculations: []query.Calculation{query.P99("duration_ms")},
+	}
+
+	series, err := FetchGroupedSeries(context.Background(), runner, "production", q, start, end, time.Minute)
+	require.NoError(t, err)
+
+	require.Len(t, series, 2)
+	assert.Len(t, series["api"], 2880)
+	assert.Equal(t, 2.0, series["web"][100].Value)
+
+	total, err := FetchSeries(context.Background(), runner, "production", q, start, end, time.Minute)
+	require.NoError(t, err)
+	assert.Len(t, total, 2880)
+}
@@ -0,0 +1,169 @@
+// Package contenthash computes stable content hashes of resource JSON, used as
+// lockfile hashes, caching keys and the resource IDs printed by list and build
+// --ids.
+//
+// A document is hashed in canonical form, so formatting does not change its
+// hash:
+//
+//   - insignificant whitespace is removed
+//   - object keys are sorted by byte order; of duplicate keys, the last wins
+//   - strings are written as encoding/json writes them, with <, > and &
+//     escaped and any other escape sequence decoded
+//   - integers are written in decimal without exponent or fraction, so 1e3,
+//     1000.0 and 1000 are equal; integers written without fraction or
+//     exponent are exact at any size, so 9007199254740993 and
+//     9007199254740992 differ
+//   - other numbers are rounded to float64 and written as encoding/json
+//     writes them, so 0.50 and 5e-1 are equal; -0 is written as 0
+//   - null, empty arrays and empty objects are kept, so {"a": null} and {}
+//     differ
+//
+// For documents whose integers are below 2^53, the canonical form is the
+// output of json.Marshal on the document decoded into an any, which earlier
+// lockfiles were hashed with, so their hashes are unchanged.
+package contenthash
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Prefix starts every hash, naming the digest algorithm.
+const Prefix = "sha256:"
+
+// IDLength is the number of hex digits of a hash kept in an ID. 48 bits make a
+// collision unlikely below millions of resources.
+const IDLength = 12
+
+// Hash returns the sha256 of the canonical form of a JSON document, as
+// Prefix followed by 64 hex digits.
+func Hash(data []byte) (string, error) {
+	canonical, err := Canonical(data)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(canonical)
+	return Prefix + hex.EncodeToString(sum[:]), nil
+}
+
+// ID returns the short resource ID of a hash: its first IDLength hex digits.
+func ID(hash string) string {
+	digest := strings.TrimPrefix(hash, Prefix)
+	if len(digest) > IDLength {
+		digest = digest[:IDLength]
+	}
+	return digest
+}
+
+// Canonical returns the canonical form of a JSON document, following the rules
+// in the package documentation.
+func Canonical(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("parse JSON: %w", err)
+	}
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parse JSON: unexpected data after the document")
+	}
+
+	var buf bytes.Buffer
+	if err := write(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// write appends the canonical form of a decoded value to buf.
+func write(buf *bytes.Buffer, v any) error {
+	switch v := v.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		n, err := number(v)
+		if err != nil {
+			return err
+		}
+		buf.WriteString(n)
+	case string:
+		s, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(s)
+	case []any:
+		buf.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := write(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := write(buf, k); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := write(buf, v[k]); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("unexpected JSON value %T", v)
+	}
+	return nil
+}
+
+// number returns the canonical form of a JSON number.
+func number(n json.Number) (string, error) {
+	s := n.String()
+	if !strings.ContainsAny(s, ".eE") {
+		i, ok := new(big.Int).SetString(s, 10)
+		if !ok {
+			return "", fmt.Errorf("invalid number %s", s)
+		}
+		return i.String(), nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid number %s: %w", s, err)
+	}
+	if f == math.Trunc(f) && math.Abs(f) < 1<<63 {
+		return strconv.FormatInt(int64(f), 10), nil
+	}
+	out, err := json.Marshal(f)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
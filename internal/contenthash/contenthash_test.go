@@ -0,0 +1,115 @@
+package contenthash
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"whitespace", " { \"a\" : [ 1 , 2 ] } \n", `{"a":[1,2]}`},
+		{"key order", `{"b":1,"a":{"d":2,"c":3}}`, `{"a":{"c":3,"d":2},"b":1}`},
+		{"duplicate keys", `{"a":1,"a":2}`, `{"a":2}`},
+		{"escapes", `{"s":"\u0041\/<&>"}`, `{"s":"A/\u003c\u0026\u003e"}`},
+		{"integral float", `[1.0, 1e3, -0, -0.0, 2.50e1]`, `[1,1000,0,0,25]`},
+		{"fraction", `[0.50, 5e-1, 1e-7]`, `[0.5,0.5,1e-7]`},
+		{"big integer", `9007199254740993`, `9007199254740993`},
+		{"null and empty", `{"a":null,"b":[],"c":{}}`, `{"a":null,"b":[],"c":{}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Canonical([]byte(tt.in))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+		})
+	}
+}
+
+func TestCanonical_Invalid(t *testing.T) {
+	for _, in := range []string{``, `{`, `{"a":1} {"b":2}`, `[1,]`} {
+		_, err := Canonical([]byte(in))
+		assert.Error(t, err, in)
+	}
+}
+
+func TestCanonical_MatchesMarshal(t *testing.T) {
+	// Earlier lockfiles hashed json.Marshal of the decoded document
+	docs := []string{
+		`{"name":"High Latency","frequency":300,"threshold":{"op":">","value":500.5}}`,
+		`{"calculations":[{"op":"P99","column":"duration_ms"}],"time_range":3600,"filters":[]}`,
+		`{"description":"a <b> & c","tags":null,"ratio":0.001,"big":1e20}`,
+	}
+
+	for _, doc := range docs {
+		var v any
+		require.NoError(t, json.Unmarshal([]byte(doc), &v))
+		want, err := json.Marshal(v)
+		require.NoError(t, err)
+
+		got, err := Canonical([]byte(doc))
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got))
+	}
+}
+
+func TestHash(t *testing.T) {
+	a, err := Hash([]byte(`{"name": "x", "frequency": 300}`))
+	require.NoError(t, err)
+	b, err := Hash([]byte(`{"frequency":300.0,"name":"x"}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, a, b)
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, a)
+	assert.Equal(t, a[len(Prefix):len(Prefix)+IDLength], ID(a))
+	assert.Equal(t, "abc", ID("sha256:abc"))
+}
+
+func TestHash_Collisions(t *testing.T) {
+	// Documents that differ only in type or structure must not collide
+	pairs := [][2]string{
+		{`{"a":"1"}`, `{"a":1}`},
+		{`{"a":true}`, `{"a":"true"}`},
+		{`{"a":null}`, `{}`},
+		{`[]`, `{}`},
+		{`""`, `null`},
+		{`["ab"]`, `["a","b"]`},
+		{`{"ab":"c"}`, `{"a":"bc"}`},
+		{`{"a":{"b":1}}`, `{"a.b":1}`},
+		{`[[1],2]`, `[1,[2]]`},
+		{`9007199254740993`, `9007199254740992`},
+		{`0.1`, `0.10000000000000002`},
+	}
+
+	for _, pair := range pairs {
+		a, err := Hash([]byte(pair[0]))
+		require.NoError(t, err)
+		b, err := Hash([]byte(pair[1]))
+		require.NoError(t, err)
+		assert.NotEqual(t, a, b, "%s and %s", pair[0], pair[1])
+	}
+}
+
+func TestID_Unique(t *testing.T) {
+	// Near-identical resources, as produced by generated code, get distinct IDs
+	ids := make(map[string]string)
+	for i := range 20000 {
+		doc := fmt.Sprintf(`{"name":"Latency %d","dataset":"api","frequency":%d,"threshold":{"op":">","value":%d}}`, i%100, 60*(i%7+1), i)
+		hash, err := Hash([]byte(doc))
+		require.NoError(t, err)
+
+		id := ID(hash)
+		if other, ok := ids[id]; ok {
+			t.Fatalf("ID %s of %s collides with %s", id, doc, other)
+		}
+		ids[id] = doc
+	}
+}
@@ -0,0 +1,154 @@
+// Package convert holds the vendor-neutral result of converting dashboards
+// and alerts of another monitoring tool, such as Datadog or Grafana, to
+// Honeycomb resources. The import command generates Go declarations from a
+// Conversion.
+package convert
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+// Conversion is the Honeycomb equivalent of another tool's export.
+type Conversion struct {
+	Queries  []Query
+	Board    *Board
+	Triggers []Trigger
+
+	// Notes are the features that were approximated or dropped
+	Notes []Note
+}
+
+// Query is a converted query with the Go name it is declared as.
+type Query struct {
+	Name  string
+	Query query.Query
+
+	// Source is the query it was converted from
+	Source string
+
+	// TODO lists how the query differs from its source, to be reviewed
+	TODO []string
+}
+
+// Board is a converted dashboard.
+type Board struct {
+	Name        string
+	Title       string
+	Description string
+	Panels      []Panel
+}
+
+// Panel is a board panel: a query panel when Query is set, a section when
+// Panels is set, or else a text panel.
+type Panel struct {
+	Title string
+
+	// Query is the Go name of the panel's query
+	Query string
+
+	Text string
+
+	Panels []Panel
+}
+
+// Trigger is a converted alert. Its query is declared as Query.
+type Trigger struct {
+	Name        string
+	Title       string
+	Description string
+	Dataset     string
+	Query       string
+	Threshold   trigger.Threshold
+
+	// Frequency is how often the trigger runs, in seconds
+	Frequency  int
+	Recipients []trigger.Recipient
+	Tags       []trigger.Tag
+}
+
+// Note records a feature that could not be converted exactly.
+type Note struct {
+	// Source is the dashboard, panel or alert the feature belongs to
+	Source string `json:"source"`
+
+	// Feature names the feature, e.g. "hostmap widget"
+	Feature string `json:"feature"`
+
+	// Message explains how the feature was handled
+	Message string `json:"message"`
+}
+
+// String formats the note for display.
+func (n Note) String() string {
+	return fmt.Sprintf("%s: %s: %s", n.Source, n.Feature, n.Message)
+}
+
+// Names hands out Go names, each once.
+type Names map[string]bool
+
+// New returns the Go name of s, followed by a number when it is taken.
+func (n Names) New(s string) string {
+	base := GoName(s)
+	name := base
+	for i := 2; n[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	n[name] = true
+	return name
+}
+
+// GoName converts a title such as "p99 latency (checkout)" to an exported Go
+// identifier such as P99LatencyCheckout.
+func GoName(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) || r > unicode.MaxASCII {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "Imported" + name
+	}
+	return name
+}
+
+// Mergeable reports whether b can be shown as more calculations of a, in one
+// query.
+func Mergeable(a, b query.Query) bool {
+	return a.Dataset == b.Dataset &&
+		a.TimeRange == b.TimeRange &&
+		fmt.Sprint(a.Filters) == fmt.Sprint(b.Filters) &&
+		fmt.Sprint(a.Breakdowns) == fmt.Sprint(b.Breakdowns) &&
+		a.Granularity == b.Granularity
+}
+
+// AppendCalculations appends the calculations not in calcs yet.
+func AppendCalculations(calcs []query.Calculation, more ...query.Calculation) []query.Calculation {
+	for _, m := range more {
+		found := false
+		for _, c := range calcs {
+			if c == m {
+				found = true
+			}
+		}
+		if !found {
+			calcs = append(calcs, m)
+		}
+	}
+	return calcs
+}
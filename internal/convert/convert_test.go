@@ -0,0 +1,34 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+func TestGoName(t *testing.T) {
+	assert.Equal(t, "P99LatencyCheckout", GoName("p99 latency (checkout)"))
+	assert.Equal(t, "Imported5xxErrors", GoName("5xx errors"))
+	assert.Equal(t, "Imported", GoName("—"))
+}
+
+func TestNames(t *testing.T) {
+	names := make(Names)
+	assert.Equal(t, "Errors", names.New("errors"))
+	assert.Equal(t, "Errors2", names.New("Errors"))
+	assert.Equal(t, "Errors3", names.New("errors!"))
+}
+
+func TestMergeable(t *testing.T) {
+	a := query.Query{Dataset: "api", Calculations: []query.Calculation{query.Count()}, Breakdowns: []string{"route"}}
+	b := query.Query{Dataset: "api", Calculations: []query.Calculation{query.P99("duration_ms")}, Breakdowns: []string{"route"}}
+	assert.True(t, Mergeable(a, b))
+
+	b.Filters = []query.Filter{query.Equals("error", true)}
+	assert.False(t, Mergeable(a, b))
+
+	assert.Equal(t, []query.Calculation{query.Count(), query.P99("duration_ms")},
+		AppendCalculations(a.Calculations, query.Count(), query.P99("duration_ms")))
+}
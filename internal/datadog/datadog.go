@@ -0,0 +1,615 @@
+// Package datadog converts Datadog dashboard and monitor exports into
+// Honeycomb queries, a board and triggers, for teams moving to Honeycomb.
+//
+// The conversion is best-effort. A Datadog metric query such as
+//
+//	avg:http.request.duration{service:checkout,env:prod} by {resource_name}
+//
+// becomes a Honeycomb query with the space aggregator as its calculation on
+// the column named after the metric, the scope as filters and the "by" tags
+// as breakdowns. Widgets and monitors Honeycomb cannot express, and the parts
+// of a query that were dropped, are recorded as Notes in the conversion
+// report.
+package datadog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/convert"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+// Options configure the conversion.
+type Options struct {
+	// Dataset is the dataset every query uses. When empty, the value of a
+	// query's service tag is used, as Honeycomb creates a dataset per
+	// service; queries without one are left without a dataset.
+	Dataset string
+}
+
+// Convert converts data, a Datadog dashboard export, a monitor export or a
+// JSON array of monitor exports.
+func Convert(data []byte, opts Options) (*convert.Conversion, error) {
+	c := &converter{opts: opts, names: make(convert.Names)}
+
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		var monitors []monitorJSON
+		if err := json.Unmarshal(data, &monitors); err != nil {
+			return nil, fmt.Errorf("invalid monitor list: %w", err)
+		}
+		for _, m := range monitors {
+			c.monitor(m)
+		}
+		return &c.out, nil
+	}
+
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	switch {
+	case top["widgets"] != nil:
+		var d dashboardJSON
+		if err := json.Unmarshal(data, &d); err != nil {
+			return nil, fmt.Errorf("invalid dashboard: %w", err)
+		}
+		c.dashboard(d)
+	case top["query"] != nil && top["type"] != nil:
+		var m monitorJSON
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("invalid monitor: %w", err)
+		}
+		c.monitor(m)
+	default:
+		return nil, fmt.Errorf("not a Datadog dashboard or monitor export: expected \"widgets\", or \"type\" and \"query\"")
+	}
+	return &c.out, nil
+}
+
+// dashboardJSON is a Datadog dashboard export.
+type dashboardJSON struct {
+	Title             string       `json:"title"`
+	Description       string       `json:"description"`
+	Widgets           []widgetJSON `json:"widgets"`
+	TemplateVariables []struct {
+		Name string `json:"name"`
+	} `json:"template_variables"`
+}
+
+// widgetJSON is a dashboard widget.
+type widgetJSON struct {
+	Definition struct {
+		Type     string          `json:"type"`
+		Title    string          `json:"title"`
+		Requests json.RawMessage `json:"requests"`
+		Widgets  []widgetJSON    `json:"widgets"`
+		Content  string          `json:"content"`
+		Text     string          `json:"text"`
+		Time     struct {
+			LiveSpan string `json:"live_span"`
+		} `json:"time"`
+	} `json:"definition"`
+}
+
+// requestJSON is a widget request, in the legacy form with a metric query
+// in Q, or with named queries combined by formulas.
+type requestJSON struct {
+	Q       string `json:"q"`
+	Queries []struct {
+		Name       string `json:"name"`
+		DataSource string `json:"data_source"`
+		Query      string `json:"query"`
+	} `json:"queries"`
+	Formulas []struct {
+		Formula string `json:"formula"`
+	} `json:"formulas"`
+}
+
+// monitorJSON is a Datadog monitor export.
+type monitorJSON struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Query   string   `json:"query"`
+	Message string   `json:"message"`
+	Tags    []string `json:"tags"`
+	Options struct {
+		Thresholds map[string]*float64 `json:"thresholds"`
+	} `json:"options"`
+}
+
+// queryWidgets are the widget types converted to query panels, with the
+// calculation that replaces the query's aggregator, if any.
+var queryWidgets = map[string]string{
+	"timeseries":   "",
+	"query_value":  "",
+	"toplist":      "",
+	"query_table":  "",
+	"change":       "",
+	"heatmap":      "HEATMAP",
+	"distribution": "HEATMAP",
+}
+
+// converter accumulates a conversion.
+type converter struct {
+	opts  Options
+	out   convert.Conversion
+	names convert.Names
+}
+
+// note records a feature of source that was approximated or dropped.
+func (c *converter) note(source, feature, format string, args ...any) {
+	c.out.Notes = append(c.out.Notes, convert.Note{Source: source, Feature: feature, Message: fmt.Sprintf(format, args...)})
+}
+
+// dashboard converts a dashboard to a board and its queries.
+func (c *converter) dashboard(d dashboardJSON) {
+	title := d.Title
+	if title == "" {
+		title = "Datadog dashboard"
+	}
+	b := &convert.Board{Name: c.names.New(title + " Board"), Title: title, Description: d.Description}
+	for _, v := range d.TemplateVariables {
+		c.note(title, "template variable", "$%s dropped with the scope terms using it; add a board preset filter instead", v.Name)
+	}
+	b.Panels = c.widgets(d.Widgets)
+	c.out.Board = b
+}
+
+// widgets converts widgets to panels.
+func (c *converter) widgets(widgets []widgetJSON) []convert.Panel {
+	var panels []convert.Panel
+	for i, w := range widgets {
+		def := w.Definition
+		source := def.Title
+		if source == "" {
+			source = fmt.Sprintf("widget %d", i+1)
+		}
+
+		switch {
+		case def.Type == "group":
+			if inner := c.widgets(def.Widgets); len(inner) > 0 {
+				panels = append(panels, convert.Panel{Title: def.Title, Panels: inner})
+			}
+		case def.Type == "note" || def.Type == "free_text":
+			panels = append(panels, convert.Panel{Title: def.Title, Text: def.Content + def.Text})
+		case isQueryWidget(def.Type):
+			panels = append(panels, c.queryWidget(source, def.Type, def.Title, def.Requests, def.Time.LiveSpan)...)
+		default:
+			c.note(source, def.Type+" widget", "has no Honeycomb equivalent; skipped")
+		}
+	}
+	return panels
+}
+
+// isQueryWidget reports whether widgets of type t become query panels.
+func isQueryWidget(t string) bool {
+	_, ok := queryWidgets[t]
+	return ok
+}
+
+// queryWidget converts the requests of a graph widget to query panels. The
+// metric queries of all requests are merged into one Honeycomb query when
+// they share a dataset, filters and breakdowns; otherwise each gets a panel.
+func (c *converter) queryWidget(source, kind, title string, raw json.RawMessage, liveSpan string) []convert.Panel {
+	var requests []requestJSON
+	if err := json.Unmarshal(raw, &requests); err != nil {
+		c.note(source, kind+" widget", "requests could not be read; skipped")
+		return nil
+	}
+	if kind == "change" {
+		c.note(source, "change widget", "the comparison with the previous period is dropped; the current value is shown")
+	}
+
+	var timeRange query.TimeRange
+	if liveSpan != "" {
+		seconds, ok := parseDuration(liveSpan)
+		if ok {
+			timeRange = query.Seconds(seconds)
+		} else {
+			c.note(source, "time", "live span %q is not a fixed duration; the board time range is used", liveSpan)
+		}
+	}
+
+	var converted []convert.Query
+	for _, r := range requests {
+		var sources []string
+		if r.Q != "" {
+			sources = append(sources, r.Q)
+		}
+		for _, q := range r.Queries {
+			if q.DataSource != "" && q.DataSource != "metrics" {
+				c.note(source, q.DataSource+" query", "%s queries are not converted; skipped", q.DataSource)
+				continue
+			}
+			sources = append(sources, q.Query)
+		}
+		for _, f := range r.Formulas {
+			if !isQueryName(f.Formula, r) {
+				c.note(source, "formula", "%q dropped; each query is shown on its own", f.Formula)
+			}
+		}
+
+		for _, s := range sources {
+			q, _, notes := c.metricQuery(s)
+			for _, n := range notes {
+				c.note(source, "query", "%s", n)
+			}
+			if q == nil {
+				continue
+			}
+			if calc := queryWidgets[kind]; calc != "" {
+				for i := range q.Calculations {
+					q.Calculations[i] = query.Calculation{Op: calc, Column: q.Calculations[i].Column}
+				}
+			}
+			q.TimeRange = timeRange
+
+			if n := len(converted); n > 0 && convert.Mergeable(converted[n-1].Query, *q) {
+				last := &converted[n-1]
+				last.Query.Calculations = convert.AppendCalculations(last.Query.Calculations, q.Calculations...)
+				last.Source += "; " + s
+				continue
+			}
+			converted = append(converted, convert.Query{Query: *q, Source: s})
+		}
+	}
+
+	var panels []convert.Panel
+	for i := range converted {
+		base := title
+		if base == "" {
+			base = source
+		}
+		converted[i].Name = c.names.New(base)
+		c.out.Queries = append(c.out.Queries, converted[i])
+		panels = append(panels, convert.Panel{Title: title, Query: converted[i].Name})
+	}
+	return panels
+}
+
+// isQueryName reports whether formula only shows one of the request's
+// queries, as the default formula does.
+func isQueryName(formula string, r requestJSON) bool {
+	for _, q := range r.Queries {
+		if strings.TrimSpace(formula) == q.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// monitorQuery matches a metric monitor query: the time aggregation over
+// the window, the metric query, the comparator and the threshold.
+var monitorQuery = regexp.MustCompile(`^\s*(\w+)\(last_(\w+)\):(.*?)\s*(>=|<=|>|<)\s*(-?[0-9.eE+]+)\s*$`)
+
+// monitorTypes are the monitor types converted to triggers.
+var monitorTypes = map[string]bool{
+	"metric alert": true,
+	"query alert":  true,
+}
+
+// thresholds maps comparators to trigger thresholds.
+var thresholds = map[string]func(float64) trigger.Threshold{
+	">":  trigger.GreaterThan,
+	">=": trigger.GreaterThanOrEqual,
+	"<":  trigger.LessThan,
+	"<=": trigger.LessThanOrEqual,
+}
+
+// monitor converts a metric monitor to a trigger and its query.
+func (c *converter) monitor(m monitorJSON) {
+	source := m.Name
+	if source == "" {
+		source = "monitor"
+	}
+	if !monitorTypes[m.Type] {
+		c.note(source, m.Type+" monitor", "only metric monitors are converted; skipped")
+		return
+	}
+
+	match := monitorQuery.FindStringSubmatch(m.Query)
+	if match == nil {
+		c.note(source, "monitor query", "%q could not be read; skipped", m.Query)
+		return
+	}
+	timeAgg, window, metric, op := match[1], match[2], match[3], match[4]
+	value, err := strconv.ParseFloat(match[5], 64)
+	if err != nil {
+		c.note(source, "monitor query", "threshold %q is not a number; skipped", match[5])
+		return
+	}
+	if timeAgg == "change" || timeAgg == "pct_change" {
+		c.note(source, timeAgg+" monitor", "change alerts have no Honeycomb equivalent; skipped")
+		return
+	}
+	if critical := m.Options.Thresholds["critical"]; critical != nil {
+		value = *critical
+	}
+	if m.Options.Thresholds["warning"] != nil {
+		c.note(source, "warning threshold", "dropped; declare a second trigger for it")
+	}
+
+	q, scale, notes := c.metricQuery(metric)
+	for _, n := range notes {
+		c.note(source, "query", "%s", n)
+	}
+	if q == nil {
+		return
+	}
+	if len(q.Calculations) > 1 {
+		c.note(source, "monitor query", "triggers evaluate one calculation; only %s is kept", q.Calculations[0].Op)
+		q.Calculations = q.Calculations[:1]
+	}
+	if scale != 1 {
+		value *= scale
+	}
+
+	seconds, ok := parseDuration(window)
+	if !ok {
+		c.note(source, "evaluation window", "last_%s is not a fixed duration; 5 minutes are used", window)
+		seconds = 300
+	}
+	// Honeycomb triggers run every 1 to 1440 minutes
+	frequency := min(max((seconds+59)/60*60, 60), 86400)
+	duration := min(max(seconds, frequency), trigger.MaxDurationFactor*frequency)
+	if duration != seconds {
+		c.note(source, "evaluation window", "last_%s became %d minutes to fit the trigger frequency", window, duration/60)
+	}
+	q.TimeRange = query.Seconds(duration)
+
+	t := convert.Trigger{
+		Name:        c.names.New(source),
+		Title:       source,
+		Description: strings.TrimSpace(mentions.ReplaceAllString(m.Message, "")),
+		Dataset:     q.Dataset,
+		Threshold:   thresholds[op](value),
+		Frequency:   frequency,
+	}
+	t.Query = c.names.New(t.Name + " Query")
+	t.Recipients = c.recipients(source, m.Message)
+
+	for _, tag := range m.Tags {
+		key, val, ok := strings.Cut(tag, ":")
+		if !ok {
+			c.note(source, "tag", "%q has no value; dropped", tag)
+			continue
+		}
+		t.Tags = append(t.Tags, trigger.Tag{Key: key, Value: val})
+	}
+
+	c.out.Queries = append(c.out.Queries, convert.Query{Name: t.Query, Query: *q, Source: metric})
+	c.out.Triggers = append(c.out.Triggers, t)
+}
+
+// mentions matches the @-notifications of a monitor message.
+var mentions = regexp.MustCompile(`@[\w.+-]+(@[\w.-]+)?`)
+
+// recipients converts the @-notifications of a monitor message to trigger
+// recipients: email addresses and Slack channels.
+func (c *converter) recipients(source, message string) []trigger.Recipient {
+	var recipients []trigger.Recipient
+	for _, mention := range mentions.FindAllString(message, -1) {
+		target := mention[1:]
+		switch {
+		case strings.Contains(target, "@"):
+			recipients = append(recipients, trigger.EmailAddress(target))
+		case strings.HasPrefix(target, "slack-"):
+			channel := strings.TrimPrefix(target, "slack-")
+			recipients = append(recipients, trigger.SlackChannel("#"+channel))
+			c.note(source, "notification", "%s became Slack channel #%s; remove the workspace name if the mention includes one", mention, channel)
+		default:
+			c.note(source, "notification", "%s dropped; add the recipient to the trigger", mention)
+		}
+	}
+	return recipients
+}
+
+// metricPattern matches a metric query: the space aggregator, the metric,
+// the scope, the "by" tags and the functions applied to it.
+var metricPattern = regexp.MustCompile(`(\w+):([\w.]+)\{([^}]*)\}(?:\s*by\s*\{([^}]*)\})?((?:\.\w+\([^)]*\))*)`)
+
+// functionPattern matches a function applied to a metric query, such as
+// .rollup(sum, 60).
+var functionPattern = regexp.MustCompile(`\.(\w+)\(([^)]*)\)`)
+
+// aggregators maps Datadog space aggregators to Honeycomb calculations.
+var aggregators = map[string]string{
+	"avg":   "AVG",
+	"sum":   "SUM",
+	"min":   "MIN",
+	"max":   "MAX",
+	"count": "COUNT",
+	"p50":   "P50",
+	"p75":   "P75",
+	"p90":   "P90",
+	"p95":   "P95",
+	"p99":   "P99",
+}
+
+// tagColumns maps Datadog unified service tags to OpenTelemetry columns.
+var tagColumns = map[string]string{
+	"service": "service.name",
+	"env":     "deployment.environment",
+	"version": "service.version",
+	"host":    "host.name",
+}
+
+// metricQuery converts the first metric query in s. It returns the factor
+// thresholds on the metric are multiplied by to use the converted column's
+// unit, and what could not be converted. The query is nil when s holds no
+// metric query.
+func (c *converter) metricQuery(s string) (*query.Query, float64, []string) {
+	var notes []string
+	loc := metricPattern.FindStringSubmatchIndex(s)
+	if loc == nil {
+		return nil, 1, []string{fmt.Sprintf("%q is not a metric query; skipped", s)}
+	}
+	if rest := strings.TrimSpace(s[:loc[0]] + s[loc[1]:]); rest != "" {
+		notes = append(notes, fmt.Sprintf("functions and arithmetic around %s dropped: %s", s[loc[0]:loc[1]], s))
+	}
+	group := func(i int) string {
+		if loc[2*i] < 0 {
+			return ""
+		}
+		return s[loc[2*i]:loc[2*i+1]]
+	}
+	agg, metric, scope, by, functions := group(1), group(2), group(3), group(4), group(5)
+
+	q := &query.Query{}
+	scale := 1.0
+	column := metric
+	var filters []query.Filter
+
+	// APM trace metrics are computed from spans, which Honeycomb queries directly
+	if op, ok := strings.CutPrefix(metric, "trace."); ok {
+		switch {
+		case strings.HasSuffix(op, ".hits"):
+			agg, column = "count", ""
+		case strings.HasSuffix(op, ".errors"):
+			agg, column = "count", ""
+			filters = append(filters, query.Equals("error", true))
+		case strings.Contains(op, ".duration"):
+			column, scale = "duration_ms", 1000
+			notes = append(notes, fmt.Sprintf("%s is in seconds; duration_ms is in milliseconds", metric))
+		}
+	}
+
+	calc, ok := aggregators[agg]
+	if !ok {
+		notes = append(notes, fmt.Sprintf("aggregator %s has no Honeycomb equivalent; AVG is used", agg))
+		calc = "AVG"
+	}
+	if calc == "COUNT" {
+		column = ""
+	}
+	q.Calculations = []query.Calculation{{Op: calc, Column: column}}
+
+	var service string
+	for _, term := range splitScope(scope) {
+		f, ok := scopeFilter(term)
+		if !ok {
+			notes = append(notes, fmt.Sprintf("scope %q dropped", term))
+			continue
+		}
+		if f.Column == "service.name" && f.Op == "=" {
+			service, _ = f.Value.(string)
+		}
+		filters = append(filters, f)
+	}
+	q.Filters = filters
+
+	for _, tag := range strings.Split(by, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			q.Breakdowns = append(q.Breakdowns, tagColumn(tag))
+		}
+	}
+
+	for _, fn := range functionPattern.FindAllStringSubmatch(functions, -1) {
+		switch name, args := fn[1], fn[2]; name {
+		case "rollup":
+			parts := strings.Split(args, ",")
+			if seconds, err := strconv.Atoi(strings.TrimSpace(parts[len(parts)-1])); err == nil && len(parts) > 1 {
+				q.Granularity = seconds
+			}
+		case "as_count", "fill":
+			// Honeycomb counts events and does not interpolate
+		default:
+			notes = append(notes, fmt.Sprintf(".%s() dropped", name))
+		}
+	}
+
+	q.Dataset = c.opts.Dataset
+	if q.Dataset == "" {
+		q.Dataset = service
+	}
+	if q.Dataset == "" {
+		notes = append(notes, fmt.Sprintf("no dataset for %s; set one with --dataset", s[loc[0]:loc[1]]))
+	}
+	return q, scale, notes
+}
+
+// splitScope splits a metric query scope into its terms, separated by
+// commas or AND.
+func splitScope(scope string) []string {
+	var terms []string
+	for _, part := range strings.Split(scope, ",") {
+		for _, term := range strings.Split(part, " AND ") {
+			if term = strings.TrimSpace(term); term != "" && term != "*" {
+				terms = append(terms, term)
+			}
+		}
+	}
+	return terms
+}
+
+// scopeFilter converts a scope term such as service:checkout, !env:staging
+// or host:web-* to a filter. Bare tags, template variables and boolean
+// expressions are not converted.
+func scopeFilter(term string) (query.Filter, bool) {
+	negated := strings.HasPrefix(term, "!")
+	term = strings.TrimPrefix(term, "!")
+	key, value, ok := strings.Cut(term, ":")
+	if !ok || strings.HasPrefix(term, "$") || strings.Contains(value, "$") || strings.ContainsAny(term, " ()") {
+		return query.Filter{}, false
+	}
+	column := tagColumn(key)
+
+	switch {
+	case value == "*":
+		if negated {
+			return query.DoesNotExist(column), true
+		}
+		return query.Exists(column), true
+	case strings.Count(value, "*") == 2 && len(value) > 2 && value[0] == '*' && value[len(value)-1] == '*' && !negated:
+		return query.Contains(column, value[1:len(value)-1]), true
+	case strings.Count(value, "*") == 1 && strings.HasSuffix(value, "*") && !negated:
+		return query.StartsWith(column, strings.TrimSuffix(value, "*")), true
+	case strings.Contains(value, "*"):
+		return query.Filter{}, false
+	case negated:
+		return query.NotEquals(column, scopeValue(value)), true
+	}
+	return query.Equals(column, scopeValue(value)), true
+}
+
+// scopeValue returns a scope value as a number when it is one, as tags are
+// strings in Datadog but numeric columns are compared as numbers.
+func scopeValue(value string) any {
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return n
+	}
+	return value
+}
+
+// tagColumn returns the column a Datadog tag is stored as.
+func tagColumn(tag string) string {
+	if column, ok := tagColumns[tag]; ok {
+		return column
+	}
+	return tag
+}
+
+// durationPattern matches a Datadog duration such as 5m, 4h, 1w or 3mo.
+var durationPattern = regexp.MustCompile(`^(\d+)(m|h|d|w|mo)$`)
+
+// parseDuration returns a Datadog duration in seconds.
+func parseDuration(s string) (int, bool) {
+	match := durationPattern.FindStringSubmatch(s)
+	if match == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil || n <= 0 || n > math.MaxInt32 {
+		return 0, false
+	}
+	unit := map[string]int{"m": 60, "h": 3600, "d": 86400, "w": 7 * 86400, "mo": 30 * 86400}[match[2]]
+	return n * unit, true
+}
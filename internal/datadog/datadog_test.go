@@ -0,0 +1,153 @@
+package datadog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/convert"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+const dashboard = `{
+  "title": "Checkout Service",
+  "description": "Checkout health",
+  "template_variables": [{"name": "env"}],
+  "widgets": [
+    {"definition": {"type": "timeseries", "title": "Latency",
+      "requests": [{"q": "p99:trace.http.request.duration{service:checkout,$env} by {resource_name}"},
+                   {"q": "p50:trace.http.request.duration{service:checkout} by {resource_name}"}],
+      "time": {"live_span": "4h"}}},
+    {"definition": {"type": "group", "title": "Infrastructure", "widgets": [
+      {"definition": {"type": "heatmap", "title": "CPU",
+        "requests": [{"queries": [{"name": "a", "data_source": "metrics", "query": "avg:system.cpu.user{host:web-*,!env:staging} by {host}.rollup(avg, 60)"}],
+                      "formulas": [{"formula": "a"}]}]}},
+      {"definition": {"type": "hostmap", "title": "Hosts", "requests": {"fill": {"q": "avg:system.cpu.user{*}"}}}}
+    ]}},
+    {"definition": {"type": "note", "content": "Owned by payments"}}
+  ]
+}`
+
+func TestConvert_Dashboard(t *testing.T) {
+	conv, err := Convert([]byte(dashboard), Options{})
+	require.NoError(t, err)
+
+	require.NotNil(t, conv.Board)
+	assert.Equal(t, "CheckoutServiceBoard", conv.Board.Name)
+	assert.Equal(t, "Checkout Service", conv.Board.Title)
+	assert.Equal(t, []convert.Panel{
+		{Title: "Latency", Query: "Latency"},
+		{Title: "Infrastructure", Panels: []convert.Panel{{Title: "CPU", Query: "CPU"}}},
+		{Text: "Owned by payments"},
+	}, conv.Board.Panels)
+
+	require.Len(t, conv.Queries, 2)
+	latency := conv.Queries[0].Query
+	assert.Equal(t, "checkout", latency.Dataset)
+	assert.Equal(t, query.Hours(4), latency.TimeRange)
+	assert.Equal(t, []query.Calculation{query.P99("duration_ms"), query.P50("duration_ms")}, latency.Calculations)
+	assert.Equal(t, []query.Filter{query.Equals("service.name", "checkout")}, latency.Filters)
+	assert.Equal(t, []string{"resource_name"}, latency.Breakdowns)
+
+	cpu := conv.Queries[1].Query
+	assert.Empty(t, cpu.Dataset)
+	assert.Equal(t, []query.Calculation{query.Heatmap("system.cpu.user")}, cpu.Calculations)
+	assert.Equal(t, []query.Filter{query.StartsWith("host.name", "web-"), query.NotEquals("deployment.environment", "staging")}, cpu.Filters)
+	assert.Equal(t, []string{"host.name"}, cpu.Breakdowns)
+	assert.Equal(t, 60, cpu.Granularity)
+
+	var features []string
+	for _, n := range conv.Notes {
+		features = append(features, n.Feature)
+	}
+	assert.ElementsMatch(t, []string{"template variable", "query", "query", "query", "query", "hostmap widget"}, features)
+}
+
+func TestConvert_Dataset(t *testing.T) {
+	conv, err := Convert([]byte(dashboard), Options{Dataset: "otel"})
+	require.NoError(t, err)
+	for _, q := range conv.Queries {
+		assert.Equal(t, "otel", q.Query.Dataset)
+	}
+	for _, n := range conv.Notes {
+		assert.NotContains(t, n.Message, "no dataset")
+	}
+}
+
+func TestConvert_Monitors(t *testing.T) {
+	monitors := `[
+	  {"name": "Checkout latency high", "type": "metric alert",
+	   "query": "avg(last_10m):avg:trace.http.request.duration{service:checkout} > 0.5",
+	   "message": "Latency is high. @slack-checkout-alerts @oncall@example.com @pagerduty-checkout",
+	   "tags": ["team:payments", "critical"],
+	   "options": {"thresholds": {"critical": 0.4, "warning": 0.3}}},
+	  {"name": "Checkout errors", "type": "query alert",
+	   "query": "sum(last_1w):sum:trace.http.request.errors{service:checkout}.as_count() >= 10"},
+	  {"name": "Checkout logs", "type": "log alert", "query": "logs(\"service:checkout\").last(\"5m\") > 10"},
+	  {"name": "Checkout change", "type": "metric alert", "query": "pct_change(avg(last_5m),last_5m):avg:hits{*} > 10"}
+	]`
+
+	conv, err := Convert([]byte(monitors), Options{})
+	require.NoError(t, err)
+	assert.Nil(t, conv.Board)
+	require.Len(t, conv.Triggers, 2)
+	require.Len(t, conv.Queries, 2)
+
+	latency := conv.Triggers[0]
+	assert.Equal(t, "CheckoutLatencyHigh", latency.Name)
+	assert.Equal(t, "CheckoutLatencyHighQuery", latency.Query)
+	assert.Equal(t, "Latency is high.", latency.Description)
+	assert.Equal(t, "checkout", latency.Dataset)
+	assert.Equal(t, trigger.GreaterThan(400), latency.Threshold, "critical threshold in milliseconds")
+	assert.Equal(t, 600, latency.Frequency)
+	assert.Equal(t, []trigger.Recipient{trigger.SlackChannel("#checkout-alerts"), trigger.EmailAddress("oncall@example.com")}, latency.Recipients)
+	assert.Equal(t, []trigger.Tag{{Key: "team", Value: "payments"}}, latency.Tags)
+	assert.Equal(t, query.Minutes(10), conv.Queries[0].Query.TimeRange)
+
+	errors := conv.Triggers[1]
+	assert.Equal(t, trigger.GreaterThanOrEqual(10), errors.Threshold)
+	assert.Equal(t, 86400, errors.Frequency)
+	assert.Equal(t, query.Days(4), conv.Queries[1].Query.TimeRange, "window shortened to the longest duration the frequency allows")
+	assert.Equal(t, []query.Calculation{query.Count()}, conv.Queries[1].Query.Calculations)
+
+	var features []string
+	for _, n := range conv.Notes {
+		features = append(features, n.Feature)
+	}
+	assert.Contains(t, features, "warning threshold")
+	assert.Contains(t, features, "log alert monitor")
+	assert.Contains(t, features, "monitor query")
+	assert.Contains(t, features, "evaluation window")
+}
+
+func TestConvert_Invalid(t *testing.T) {
+	for _, data := range []string{`nope`, `{"title": "x"}`, `[1]`} {
+		_, err := Convert([]byte(data), Options{})
+		assert.Error(t, err, data)
+	}
+}
+
+func TestScopeFilter(t *testing.T) {
+	tests := map[string]query.Filter{
+		"service:checkout": query.Equals("service.name", "checkout"),
+		"!env:staging":     query.NotEquals("deployment.environment", "staging"),
+		"status_code:500":  query.Equals("status_code", int64(500)),
+		"region:*":         query.Exists("region"),
+		"!region:*":        query.DoesNotExist("region"),
+		"host:web-*":       query.StartsWith("host.name", "web-"),
+		"route:*cart*":     query.Contains("route", "cart"),
+	}
+	for term, want := range tests {
+		got, ok := scopeFilter(term)
+		if assert.True(t, ok, term) {
+			assert.Equal(t, want, got, term)
+		}
+	}
+
+	for _, term := range []string{"production", "$env", "env:$env.value", "host:*web-*-1", "env IN (a,b)"} {
+		_, ok := scopeFilter(term)
+		assert.False(t, ok, term)
+	}
+}
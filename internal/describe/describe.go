@@ -0,0 +1,190 @@
+// Package describe generates descriptions for triggers and SLOs declared
+// without one, from their structured metadata, so alerts arriving in Slack or
+// PagerDuty carry context even when authors skip the Description field.
+//
+// Descriptions are rendered with text/template. Trigger templates receive a
+// TriggerData, SLO templates an SLOData.
+package describe
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// DefaultTriggerTemplate renders e.g. "P99(duration_ms) > 500 over 15m on
+// api (query HighLatency), checked every 5m".
+const DefaultTriggerTemplate = `{{.Calculation}} {{.Threshold}}{{with .Window}} over {{.}}{{end}} on {{.Dataset}}{{with .Query}} (query {{.}}){{end}}{{with .Frequency}}, checked every {{.}}{{end}}`
+
+// DefaultSLOTemplate renders e.g. "99.9% of AllRequests on api are
+// SuccessfulRequests over a rolling 30d".
+const DefaultSLOTemplate = `{{.Target}} of {{or .TotalEvents "events"}} on {{.Dataset}} are {{or .GoodEvents "good"}} over a rolling {{.Window}}`
+
+// TriggerData is the metadata available to trigger templates.
+type TriggerData struct {
+	// Name is the trigger's Name, or its Go variable name when unset
+	Name string
+
+	// Variable is the Go variable name
+	Variable string
+
+	Dataset string
+
+	// Query is the name of the referenced query ("" for inline queries)
+	Query string
+
+	// Calculation is the query's first calculation, e.g. "P99(duration_ms)"
+	Calculation string
+
+	// Threshold is the threshold operator and value, e.g. "> 500"
+	Threshold      string
+	ThresholdOp    string
+	ThresholdValue float64
+
+	// Window is the query's time range, e.g. "15m"
+	Window string
+
+	// Frequency is the evaluation frequency, e.g. "5m"
+	Frequency string
+}
+
+// SLOData is the metadata available to SLO templates.
+type SLOData struct {
+	// Name is the SLO's Name, or its Go variable name when unset
+	Name string
+
+	// Variable is the Go variable name
+	Variable string
+
+	Dataset string
+
+	// Target is the target percentage, e.g. "99.9%"
+	Target           string
+	TargetPercentage float64
+
+	// Window is the rolling time period, e.g. "30d"
+	Window string
+
+	// GoodEvents and TotalEvents are the names of the referenced queries
+	// ("" for inline queries)
+	GoodEvents  string
+	TotalEvents string
+
+	// BurnAlerts is the number of burn alerts
+	BurnAlerts int
+}
+
+// Templates renders descriptions.
+type Templates struct {
+	trigger *template.Template
+	slo     *template.Template
+}
+
+// Parse parses the trigger and SLO templates. An empty text selects the
+// default template.
+func Parse(triggerText, sloText string) (*Templates, error) {
+	if triggerText == "" {
+		triggerText = DefaultTriggerTemplate
+	}
+	if sloText == "" {
+		sloText = DefaultSLOTemplate
+	}
+
+	t := &Templates{}
+	var err error
+	if t.trigger, err = template.New("trigger").Parse(triggerText); err != nil {
+		return nil, fmt.Errorf("invalid trigger description template: %w", err)
+	}
+	if t.slo, err = template.New("slo").Parse(sloText); err != nil {
+		return nil, fmt.Errorf("invalid SLO description template: %w", err)
+	}
+	return t, nil
+}
+
+// Trigger renders the description of a trigger. queries are searched for the
+// query the trigger references by name.
+func (t *Templates) Trigger(dt discovery.DiscoveredTrigger, queries []discovery.DiscoveredQuery) (string, error) {
+	data := TriggerData{
+		Name:           dt.TriggerName,
+		Variable:       dt.Name,
+		Dataset:        dt.Dataset,
+		Query:          dt.QueryRef,
+		Threshold:      fmt.Sprintf("%s %g", dt.ThresholdOp, dt.ThresholdValue),
+		ThresholdOp:    dt.ThresholdOp,
+		ThresholdValue: dt.ThresholdValue,
+		Frequency:      formatSeconds(dt.FrequencySeconds),
+	}
+	if data.Name == "" {
+		data.Name = dt.Name
+	}
+
+	if q := dt.Query(queries); q != nil {
+		if len(q.Calculations) > 0 {
+			data.Calculation = calculationName(q.Calculations[0])
+		}
+		data.Window = formatSeconds(q.TimeRange.TimeRange)
+		if data.Dataset == "" {
+			data.Dataset = q.Dataset
+		}
+	}
+
+	return render(t.trigger, data)
+}
+
+// SLO renders the description of an SLO.
+func (t *Templates) SLO(ds discovery.DiscoveredSLO) (string, error) {
+	data := SLOData{
+		Name:             ds.SLOName,
+		Variable:         ds.Name,
+		Dataset:          ds.Dataset,
+		Target:           fmt.Sprintf("%g%%", ds.TargetPercentage),
+		TargetPercentage: ds.TargetPercentage,
+		Window:           fmt.Sprintf("%dd", ds.TimePeriodDays),
+		GoodEvents:       ds.GoodEventsQueryRef,
+		TotalEvents:      ds.TotalEventsQueryRef,
+		BurnAlerts:       ds.BurnAlertCount,
+	}
+	if data.Name == "" {
+		data.Name = ds.Name
+	}
+
+	return render(t.slo, data)
+}
+
+// render executes tmpl with data and collapses whitespace, as descriptions
+// are single lines in notifications.
+func render(tmpl *template.Template, data any) (string, error) {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("render %s description: %w", tmpl.Name(), err)
+	}
+	return strings.Join(strings.Fields(b.String()), " "), nil
+}
+
+// calculationName describes a calculation, e.g. "P99(duration_ms)" or "COUNT".
+func calculationName(c discovery.Calculation) string {
+	if c.Column == "" {
+		return c.Op
+	}
+	return fmt.Sprintf("%s(%s)", c.Op, c.Column)
+}
+
+// formatSeconds formats a duration in seconds in its largest whole unit, e.g.
+// "7d", "6h" or "15m", or returns "" for zero.
+func formatSeconds(seconds int) string {
+	if seconds <= 0 {
+		return ""
+	}
+	for _, unit := range []struct {
+		size   int
+		suffix string
+	}{{86400, "d"}, {3600, "h"}, {60, "m"}} {
+		if seconds%unit.size == 0 {
+			return fmt.Sprintf("%d%s", seconds/unit.size, unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
@@ -0,0 +1,96 @@
+package describe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+var latency = discovery.DiscoveredQuery{
+	Name:         "HighLatency",
+	Package:      "alerts",
+	Dataset:      "api",
+	TimeRange:    discovery.TimeRange{TimeRange: 900},
+	Calculations: []discovery.Calculation{{Op: "P99", Column: "duration_ms"}, {Op: "COUNT"}},
+}
+
+func TestTrigger(t *testing.T) {
+	templates, err := Parse("", "")
+	require.NoError(t, err)
+
+	dt := discovery.DiscoveredTrigger{
+		Name:             "LatencyAlert",
+		Package:          "alerts",
+		Dataset:          "api",
+		QueryRef:         "HighLatency",
+		ThresholdOp:      ">",
+		ThresholdValue:   500,
+		FrequencySeconds: 300,
+	}
+	other := latency
+	other.Package = "other"
+	other.Calculations = []discovery.Calculation{{Op: "COUNT"}}
+
+	desc, err := templates.Trigger(dt, []discovery.DiscoveredQuery{other, latency})
+	require.NoError(t, err)
+	assert.Equal(t, "P99(duration_ms) > 500 over 15m on api (query HighLatency), checked every 5m", desc)
+
+	// Inline queries have no name, and unknown windows and frequencies are left out
+	dt = discovery.DiscoveredTrigger{
+		Name:           "Errors",
+		ThresholdOp:    ">=",
+		ThresholdValue: 0.5,
+		InlineQuery:    &discovery.DiscoveredQuery{Dataset: "web", Calculations: []discovery.Calculation{{Op: "COUNT"}}},
+	}
+	desc, err = templates.Trigger(dt, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "COUNT >= 0.5 on web", desc)
+}
+
+func TestSLO(t *testing.T) {
+	templates, err := Parse("", "")
+	require.NoError(t, err)
+
+	desc, err := templates.SLO(discovery.DiscoveredSLO{
+		Name:                "Availability",
+		Dataset:             "api",
+		TargetPercentage:    99.9,
+		TimePeriodDays:      30,
+		GoodEventsQueryRef:  "SuccessfulRequests",
+		TotalEventsQueryRef: "AllRequests",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "99.9% of AllRequests on api are SuccessfulRequests over a rolling 30d", desc)
+
+	desc, err = templates.SLO(discovery.DiscoveredSLO{Name: "Inline", Dataset: "api", TargetPercentage: 99, TimePeriodDays: 7})
+	require.NoError(t, err)
+	assert.Equal(t, "99% of events on api are good over a rolling 7d", desc)
+}
+
+func TestParse_Custom(t *testing.T) {
+	templates, err := Parse("[{{.Name}}] {{.Calculation}} {{.Threshold}}\n  (owner: on-call)", "{{.Name}}: {{.Target}}")
+	require.NoError(t, err)
+
+	desc, err := templates.Trigger(discovery.DiscoveredTrigger{
+		Name: "LatencyAlert", TriggerName: "High latency", QueryRef: "HighLatency", ThresholdOp: ">", ThresholdValue: 500,
+	}, []discovery.DiscoveredQuery{latency})
+	require.NoError(t, err)
+	assert.Equal(t, "[High latency] P99(duration_ms) > 500 (owner: on-call)", desc, "whitespace is collapsed")
+
+	desc, err = templates.SLO(discovery.DiscoveredSLO{Name: "Availability", TargetPercentage: 99.5})
+	require.NoError(t, err)
+	assert.Equal(t, "Availability: 99.5%", desc)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse("{{.Name", "")
+	assert.ErrorContains(t, err, "invalid trigger description template")
+
+	templates, err := Parse("{{.Owner}}", "")
+	require.NoError(t, err)
+	_, err = templates.Trigger(discovery.DiscoveredTrigger{Name: "T"}, nil)
+	assert.ErrorContains(t, err, "render trigger description")
+}
@@ -0,0 +1,119 @@
+// Package diagnostics writes bug report bundles: a zip archive describing
+// the panics discovery recovered from, with an AST dump of each offending
+// file, so a report can be filed without sharing the whole project.
+package diagnostics
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/atomicfile"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// Info describes the environment a bundle was written in.
+type Info struct {
+	Version   string    `json:"version"`
+	GoVersion string    `json:"go_version"`
+	OS        string    `json:"os"`
+	Arch      string    `json:"arch"`
+	Created   time.Time `json:"created"`
+}
+
+// WriteBundle writes a zip archive to path holding:
+//
+//	info.json        the wetwire-honeycomb version, Go version and platform
+//	panics.json      the recovered panics, with their stacks
+//	ast/NN-FILE.txt  the AST dump of each file that panicked
+//
+// The AST dump includes every literal of the file, so review it before
+// sharing the bundle outside the team.
+func WriteBundle(path string, panics []discovery.Panic, version string) error {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	info := Info{
+		Version:   version,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		Created:   time.Now().UTC(),
+	}
+	if err := writeJSON(zw, "info.json", info); err != nil {
+		return err
+	}
+	if panics == nil {
+		panics = []discovery.Panic{}
+	}
+	if err := writeJSON(zw, "panics.json", panics); err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	for _, p := range panics {
+		if seen[p.File] {
+			continue
+		}
+		seen[p.File] = true
+
+		name := fmt.Sprintf("ast/%02d-%s.txt", len(seen), filepath.Base(p.File))
+		w, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(DumpAST(p.File)); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := atomicfile.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("write diagnostics bundle: %w", err)
+	}
+	return nil
+}
+
+// DumpAST returns the AST of the Go file at path as printed by ast.Fprint,
+// without nil fields, preceded by the file name. A file that cannot be read
+// or parsed yields the error instead.
+func DumpAST(path string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// %s\n", path)
+
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		fmt.Fprintf(&b, "// parse error: %v\n", err)
+		if node == nil {
+			return b.Bytes()
+		}
+	}
+	if err := ast.Fprint(&b, fset, node, ast.NotNilFilter); err != nil {
+		fmt.Fprintf(&b, "// print error: %v\n", err)
+	}
+	return b.Bytes()
+}
+
+// writeJSON adds v to the archive as indented JSON.
+func writeJSON(zw *zip.Writer, name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,65 @@
+package diagnostics
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteBundle(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "queries.go")
+	require.NoError(t, os.WriteFile(file, []byte("package obs\n\nvar Latency = 1\n"), 0o644))
+
+	panics := []discovery.Panic{
+		{File: file, Kind: "queries", Value: "boom", Stack: "goroutine 1"},
+		{File: file, Kind: "triggers", Value: "boom", Stack: "goroutine 1"},
+	}
+	bundle := filepath.Join(dir, "out.zip")
+	require.NoError(t, WriteBundle(bundle, panics, "1.2.3"))
+
+	zr, err := zip.OpenReader(bundle)
+	require.NoError(t, err)
+	defer zr.Close()
+
+	contents := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		require.NoError(t, err)
+		contents[f.Name] = string(data)
+	}
+	assert.Len(t, contents, 3)
+
+	var info Info
+	require.NoError(t, json.Unmarshal([]byte(contents["info.json"]), &info))
+	assert.Equal(t, "1.2.3", info.Version)
+
+	var recorded []discovery.Panic
+	require.NoError(t, json.Unmarshal([]byte(contents["panics.json"]), &recorded))
+	assert.Equal(t, panics, recorded)
+
+	assert.Contains(t, contents["ast/01-queries.go.txt"], "// "+file)
+	assert.Contains(t, contents["ast/01-queries.go.txt"], `Name: "Latency"`)
+}
+
+func TestDumpAST_ParseError(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "broken.go")
+	require.NoError(t, os.WriteFile(file, []byte("package obs\n\nvar = \n"), 0o644))
+
+	dump := string(DumpAST(file))
+	assert.Contains(t, dump, "// parse error:")
+	assert.Contains(t, dump, `Name: "obs"`)
+
+	dump = string(DumpAST(filepath.Join(t.TempDir(), "missing.go")))
+	assert.Contains(t, dump, "// parse error:")
+}
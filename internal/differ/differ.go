@@ -4,17 +4,56 @@ package differ
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"sort"
 	"strings"
 
 	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/normalize"
 )
 
 // HoneycombDiffer implements semantic comparison for Honeycomb Query JSON.
-type HoneycombDiffer struct{}
+type HoneycombDiffer struct {
+	// Options refine the comparison beyond coredomain.DiffOpts
+	Options Options
+}
+
+// Options control which differences HoneycombDiffer reports.
+type Options struct {
+	// Ignore lists fields to leave out of the comparison, such as
+	// "time_range" or "threshold.value". A field matches wherever it ends
+	// the path of a value, with array indexes and panel identities removed,
+	// so "query.time_range" matches "panels[2].query.time_range" and
+	// "panels[\"Latency\"].query.time_range".
+	Ignore []string
+
+	// Tolerance is the largest difference between two numbers that is not
+	// reported, for example for floating point thresholds
+	Tolerance float64
+
+	// Include, when set, limits the comparison to resources whose name matches
+	Include *regexp.Regexp
+
+	// Exclude leaves out resources whose name matches
+	Exclude *regexp.Regexp
+
+	// Normalize compares resources after the normalization pass, so sorted
+	// breakdowns and filters, the default filter combination and zero limits
+	// are not reported
+	Normalize bool
+}
+
+// diffOptions are the core and Honeycomb options of one comparison.
+type diffOptions struct {
+	coredomain.DiffOpts
+	Options
+}
 
 // Compile-time interface check
 var _ coredomain.Differ = (*HoneycombDiffer)(nil)
@@ -24,8 +63,21 @@ func New() *HoneycombDiffer {
 	return &HoneycombDiffer{}
 }
 
+// NewWithOptions creates a HoneycombDiffer that applies opts.
+func NewWithOptions(opts Options) *HoneycombDiffer {
+	return &HoneycombDiffer{Options: opts}
+}
+
+// stdin is read for the file argument "-". Tests may replace it.
+var stdin io.Reader = os.Stdin
+
 // Diff compares two Honeycomb query configuration files or directories.
+// Either file, but not both, may be "-" to read standard input.
 func (d *HoneycombDiffer) Diff(ctx *coredomain.Context, file1, file2 string, opts coredomain.DiffOpts) (*coredomain.DiffResult, error) {
+	if file1 == "-" && file2 == "-" {
+		return nil, fmt.Errorf("only one side of a diff can be read from stdin")
+	}
+
 	// Load configurations
 	config1, err := loadConfig(file1)
 	if err != nil {
@@ -38,19 +90,35 @@ func (d *HoneycombDiffer) Diff(ctx *coredomain.Context, file1, file2 string, opt
 	}
 
 	// Compare configurations
-	return compare(config1, config2, opts)
+	return compare(config1, config2, diffOptions{opts, d.Options})
 }
 
-// HoneycombConfig represents the structure of Honeycomb configuration output.
-type HoneycombConfig struct {
-	Queries  map[string]json.RawMessage `json:"queries,omitempty"`
-	Boards   map[string]json.RawMessage `json:"boards,omitempty"`
-	SLOs     map[string]json.RawMessage `json:"slos,omitempty"`
-	Triggers map[string]json.RawMessage `json:"triggers,omitempty"`
+// DiffData compares two build outputs held in memory, such as a fresh build
+// and a file read elsewhere.
+func (d *HoneycombDiffer) DiffData(data1, data2 []byte, opts coredomain.DiffOpts) (*coredomain.DiffResult, error) {
+	config1, err := parseConfig(data1)
+	if err != nil {
+		return nil, err
+	}
+
+	config2, err := parseConfig(data2)
+	if err != nil {
+		return nil, err
+	}
+
+	return compare(config1, config2, diffOptions{opts, d.Options})
 }
 
 // loadConfig loads a Honeycomb configuration from a file.
-func loadConfig(path string) (*HoneycombConfig, error) {
+func loadConfig(path string) (*honeycomb.Manifest, error) {
+	if path == "-" {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, err
+		}
+		return parseConfig(data)
+	}
+
 	info, err := os.Stat(path)
 	if err != nil {
 		return nil, err
@@ -64,23 +132,28 @@ func loadConfig(path string) (*HoneycombConfig, error) {
 }
 
 // loadConfigFromFile loads configuration from a single JSON file.
-func loadConfigFromFile(path string) (*HoneycombConfig, error) {
+func loadConfigFromFile(path string) (*honeycomb.Manifest, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	var config HoneycombConfig
-	if err := json.Unmarshal(data, &config); err != nil {
+	return parseConfig(data)
+}
+
+// parseConfig parses a build output.
+func parseConfig(data []byte) (*honeycomb.Manifest, error) {
+	config, err := honeycomb.Unmarshal(data)
+	if err != nil {
 		return nil, fmt.Errorf("parse JSON: %w", err)
 	}
 
-	return &config, nil
+	return config, nil
 }
 
 // loadConfigFromDir loads configuration from a directory of JSON files.
-func loadConfigFromDir(dir string) (*HoneycombConfig, error) {
-	config := &HoneycombConfig{
+func loadConfigFromDir(dir string) (*honeycomb.Manifest, error) {
+	config := &honeycomb.Manifest{
 		Queries:  make(map[string]json.RawMessage),
 		Boards:   make(map[string]json.RawMessage),
 		SLOs:     make(map[string]json.RawMessage),
@@ -100,8 +173,8 @@ func loadConfigFromDir(dir string) (*HoneycombConfig, error) {
 			return err
 		}
 
-		var fileConfig HoneycombConfig
-		if err := json.Unmarshal(data, &fileConfig); err != nil {
+		fileConfig, err := honeycomb.Unmarshal(data)
+		if err != nil {
 			// Try to parse as a single resource type
 			return nil
 		}
@@ -127,7 +200,7 @@ func loadConfigFromDir(dir string) (*HoneycombConfig, error) {
 }
 
 // compare compares two Honeycomb configurations and returns the differences.
-func compare(config1, config2 *HoneycombConfig, opts coredomain.DiffOpts) (*coredomain.DiffResult, error) {
+func compare(config1, config2 *honeycomb.Manifest, opts diffOptions) (*coredomain.DiffResult, error) {
 	result := &coredomain.DiffResult{
 		Entries: []coredomain.DiffEntry{},
 		Summary: coredomain.DiffSummary{},
@@ -152,7 +225,7 @@ func compare(config1, config2 *HoneycombConfig, opts coredomain.DiffOpts) (*core
 }
 
 // compareResourceMap compares two maps of resources.
-func compareResourceMap(map1, map2 map[string]json.RawMessage, resourceType string, result *coredomain.DiffResult, opts coredomain.DiffOpts) {
+func compareResourceMap(map1, map2 map[string]json.RawMessage, resourceType string, result *coredomain.DiffResult, opts diffOptions) {
 	// Get all keys from both maps
 	allKeys := make(map[string]bool)
 	for k := range map1 {
@@ -170,8 +243,16 @@ func compareResourceMap(map1, map2 map[string]json.RawMessage, resourceType stri
 	sort.Strings(keys)
 
 	for _, name := range keys {
+		if !opts.includes(name) {
+			continue
+		}
+
 		raw1, exists1 := map1[name]
 		raw2, exists2 := map2[name]
+		if opts.Normalize {
+			raw1 = normalizeRaw(resourceType, raw1)
+			raw2 = normalizeRaw(resourceType, raw2)
+		}
 
 		if !exists1 && exists2 {
 			// Added
@@ -191,7 +272,12 @@ func compareResourceMap(map1, map2 map[string]json.RawMessage, resourceType stri
 			result.Summary.Removed++
 		} else {
 			// Both exist, compare content
-			changes := compareJSON(raw1, raw2, opts)
+			var changes []string
+			if resourceType == "board" {
+				changes = compareBoard(raw1, raw2, opts)
+			} else {
+				changes = compareJSON(raw1, raw2, opts)
+			}
 			if len(changes) > 0 {
 				result.Entries = append(result.Entries, coredomain.DiffEntry{
 					Resource: name,
@@ -205,8 +291,21 @@ func compareResourceMap(map1, map2 map[string]json.RawMessage, resourceType stri
 	}
 }
 
+// normalizeRaw returns the normalized JSON of a resource, or raw when it is
+// missing or cannot be parsed, leaving the comparison to report it.
+func normalizeRaw(resourceType string, raw json.RawMessage) json.RawMessage {
+	if raw == nil {
+		return nil
+	}
+	normalized, err := normalize.JSON(resourceType, raw)
+	if err != nil {
+		return raw
+	}
+	return normalized
+}
+
 // compareJSON compares two JSON values and returns a list of changes.
-func compareJSON(raw1, raw2 json.RawMessage, opts coredomain.DiffOpts) []string {
+func compareJSON(raw1, raw2 json.RawMessage, opts diffOptions) []string {
 	var val1, val2 interface{}
 	if err := json.Unmarshal(raw1, &val1); err != nil {
 		return []string{"parse error in first value"}
@@ -219,7 +318,7 @@ func compareJSON(raw1, raw2 json.RawMessage, opts coredomain.DiffOpts) []string
 }
 
 // compareValues recursively compares two values.
-func compareValues(v1, v2 interface{}, path string, opts coredomain.DiffOpts) []string {
+func compareValues(v1, v2 interface{}, path string, opts diffOptions) []string {
 	var changes []string
 
 	// Handle nil cases
@@ -249,6 +348,11 @@ func compareValues(v1, v2 interface{}, path string, opts coredomain.DiffOpts) []
 		val2 := v2.([]interface{})
 		changes = append(changes, compareSlices(val1, val2, path, opts)...)
 
+	case float64:
+		if math.Abs(val1-v2.(float64)) > opts.Tolerance {
+			changes = append(changes, formatChange(path, v1, v2))
+		}
+
 	default:
 		if !reflect.DeepEqual(v1, v2) {
 			changes = append(changes, formatChange(path, v1, v2))
@@ -259,7 +363,7 @@ func compareValues(v1, v2 interface{}, path string, opts coredomain.DiffOpts) []
 }
 
 // compareMaps compares two maps.
-func compareMaps(m1, m2 map[string]interface{}, path string, opts coredomain.DiffOpts) []string {
+func compareMaps(m1, m2 map[string]interface{}, path string, opts diffOptions) []string {
 	var changes []string
 
 	// Get all keys
@@ -286,6 +390,9 @@ func compareMaps(m1, m2 map[string]interface{}, path string, opts coredomain.Dif
 		val1, exists1 := m1[k]
 		val2, exists2 := m2[k]
 
+		if opts.ignores(keyPath) {
+			continue
+		}
 		if !exists1 {
 			changes = append(changes, fmt.Sprintf("%s: added", keyPath))
 		} else if !exists2 {
@@ -299,7 +406,7 @@ func compareMaps(m1, m2 map[string]interface{}, path string, opts coredomain.Dif
 }
 
 // compareSlices compares two slices.
-func compareSlices(s1, s2 []interface{}, path string, opts coredomain.DiffOpts) []string {
+func compareSlices(s1, s2 []interface{}, path string, opts diffOptions) []string {
 	var changes []string
 
 	if opts.IgnoreOrder {
@@ -329,6 +436,32 @@ func compareSlices(s1, s2 []interface{}, path string, opts coredomain.DiffOpts)
 	return changes
 }
 
+// includes reports whether the resource called name is compared.
+func (o Options) includes(name string) bool {
+	if o.Include != nil && !o.Include.MatchString(name) {
+		return false
+	}
+	return o.Exclude == nil || !o.Exclude.MatchString(name)
+}
+
+// arrayIndex matches the array indexes of a value path, including panel
+// identities such as panels["Latency"].
+var arrayIndex = regexp.MustCompile(`\[(?:"(?:[^"\\]|\\.)*"|[^\]"])*\]`)
+
+// ignores reports whether the value at path is left out of the comparison.
+func (o Options) ignores(path string) bool {
+	if len(o.Ignore) == 0 || path == "" {
+		return false
+	}
+	path = arrayIndex.ReplaceAllString(path, "")
+	for _, field := range o.Ignore {
+		if path == field || strings.HasSuffix(path, "."+field) {
+			return true
+		}
+	}
+	return false
+}
+
 // slicesEqualIgnoreOrder checks if two slices have the same elements regardless of order.
 func slicesEqualIgnoreOrder(s1, s2 []interface{}) bool {
 	if len(s1) != len(s2) {
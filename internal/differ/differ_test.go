@@ -0,0 +1,116 @@
+package differ
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const oldOutput = `{
+  "queries": {
+    "Latency": {"time_range": 3600, "calculations": [{"op": "P99", "column": "duration_ms"}]},
+    "DebugSampling": {"time_range": 60, "calculations": [{"op": "COUNT"}]}
+  },
+  "boards": {
+    "Overview": {"name": "Overview", "panels": [{"type": "query", "query": {"time_range": 3600}}]}
+  },
+  "triggers": {
+    "HighErrors": {"threshold": {"op": ">", "value": 0.1}, "frequency": 300}
+  }
+}`
+
+const newOutput = `{
+  "queries": {
+    "Latency": {"time_range": 7200, "calculations": [{"op": "P99", "column": "duration_ms"}]},
+    "DebugSampling": {"time_range": 120, "calculations": [{"op": "COUNT"}]}
+  },
+  "boards": {
+    "Overview": {"name": "Overview", "panels": [{"type": "query", "query": {"time_range": 7200}}]}
+  },
+  "triggers": {
+    "HighErrors": {"threshold": {"op": ">", "value": 0.1000001}, "frequency": 300}
+  }
+}`
+
+func diffOutputs(t *testing.T, opts Options) *coredomain.DiffResult {
+	dir := t.TempDir()
+	file1 := filepath.Join(dir, "old.json")
+	file2 := filepath.Join(dir, "new.json")
+	require.NoError(t, os.WriteFile(file1, []byte(oldOutput), 0644))
+	require.NoError(t, os.WriteFile(file2, []byte(newOutput), 0644))
+
+	result, err := NewWithOptions(opts).Diff(nil, file1, file2, coredomain.DiffOpts{})
+	require.NoError(t, err)
+	return result
+}
+
+func modified(result *coredomain.DiffResult) []string {
+	var names []string
+	for _, e := range result.Entries {
+		names = append(names, e.Resource)
+	}
+	return names
+}
+
+func TestDiff_NoOptions(t *testing.T) {
+	result := diffOutputs(t, Options{})
+	assert.Equal(t, 4, result.Summary.Modified)
+}
+
+func TestDiff_Ignore(t *testing.T) {
+	result := diffOutputs(t, Options{Ignore: []string{"time_range"}})
+	assert.Equal(t, []string{"HighErrors"}, modified(result))
+
+	// A qualified field only matches under its parent
+	result = diffOutputs(t, Options{Ignore: []string{"query.time_range"}})
+	assert.NotContains(t, modified(result), "Overview")
+	assert.Contains(t, modified(result), "Latency")
+}
+
+func TestDiff_Tolerance(t *testing.T) {
+	result := diffOutputs(t, Options{Tolerance: 0.001})
+	assert.NotContains(t, modified(result), "HighErrors")
+
+	result = diffOutputs(t, Options{Tolerance: 1e-9})
+	assert.Contains(t, modified(result), "HighErrors")
+}
+
+func TestDiff_NameFilters(t *testing.T) {
+	result := diffOutputs(t, Options{Exclude: regexp.MustCompile(`^Debug`)})
+	assert.NotContains(t, modified(result), "DebugSampling")
+	assert.Equal(t, 3, result.Summary.Total)
+
+	result = diffOutputs(t, Options{Include: regexp.MustCompile(`^(Latency|Overview)$`)})
+	assert.ElementsMatch(t, []string{"Latency", "Overview"}, modified(result))
+}
+
+func TestOptions_Ignores(t *testing.T) {
+	opts := Options{Ignore: []string{"time_range", "threshold.value"}}
+
+	assert.True(t, opts.ignores("time_range"))
+	assert.True(t, opts.ignores("panels[2].query.time_range"))
+	assert.True(t, opts.ignores(`panels["Latency [p99]"].query.time_range`))
+	assert.True(t, opts.ignores("threshold.value"))
+	assert.False(t, opts.ignores("value"))
+	assert.False(t, opts.ignores("start_time_range"))
+}
+
+func TestDiff_Normalize(t *testing.T) {
+	data1 := []byte(`{"queries": {"Latency": {"breakdowns": ["service", "endpoint"], "limit": 0,
+		"filters": [{"column": "b", "op": "exists"}, {"column": "a", "op": "exists"}]}}}`)
+	data2 := []byte(`{"queries": {"Latency": {"breakdowns": ["endpoint", "service"], "filter_combination": "AND",
+		"filters": [{"column": "a", "op": "exists"}, {"column": "b", "op": "exists"}]}}}`)
+
+	result, err := New().DiffData(data1, data2, coredomain.DiffOpts{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Summary.Modified)
+
+	result, err = NewWithOptions(Options{Normalize: true}).DiffData(data1, data2, coredomain.DiffOpts{})
+	require.NoError(t, err)
+	assert.Empty(t, result.Entries)
+}
@@ -0,0 +1,160 @@
+package differ
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// compareBoard compares two boards like compareJSON, except that panels are
+// matched by identity rather than by position, so inserting or reordering
+// panels is reported as such instead of as changes to every later index.
+func compareBoard(raw1, raw2 json.RawMessage, opts diffOptions) []string {
+	var b1, b2 map[string]interface{}
+	if json.Unmarshal(raw1, &b1) != nil || json.Unmarshal(raw2, &b2) != nil {
+		return compareJSON(raw1, raw2, opts)
+	}
+	p1, ok1 := b1["panels"].([]interface{})
+	p2, ok2 := b2["panels"].([]interface{})
+	if (!ok1 && b1["panels"] != nil) || (!ok2 && b2["panels"] != nil) {
+		return compareJSON(raw1, raw2, opts)
+	}
+	delete(b1, "panels")
+	delete(b2, "panels")
+
+	changes := compareMaps(b1, b2, "", opts)
+	if !opts.ignores("panels") {
+		changes = append(changes, comparePanels(p1, p2, opts)...)
+	}
+	return changes
+}
+
+// comparePanels matches the panels of two boards and reports the panels
+// removed, added, moved and modified. Panels are matched by title, then by
+// SLO, then untitled panels with identical content, and finally the
+// remaining untitled panels of the same type in order.
+func comparePanels(old, new []interface{}, opts diffOptions) []string {
+	keys1, keys2 := panelKeys(old), panelKeys(new)
+
+	match := make([]int, len(old))
+	for i := range match {
+		match[i] = -1
+	}
+	matched := make([]bool, len(new))
+	pair := func(ok func(i, j int) bool) {
+		for i := range old {
+			if match[i] >= 0 {
+				continue
+			}
+			for j := range new {
+				if !matched[j] && ok(i, j) {
+					match[i], matched[j] = j, true
+					break
+				}
+			}
+		}
+	}
+	pair(func(i, j int) bool { return keys1[i] != "" && keys1[i] == keys2[j] })
+	pair(func(i, j int) bool { return keys1[i] == "" && keys2[j] == "" && reflect.DeepEqual(old[i], new[j]) })
+	pair(func(i, j int) bool {
+		return keys1[i] == "" && keys2[j] == "" && panelField(old[i], "type") == panelField(new[j], "type")
+	})
+
+	var changes []string
+	for i, j := range match {
+		if j < 0 {
+			changes = append(changes, fmt.Sprintf("%s: removed", panelLabel(keys1[i], i)))
+		}
+	}
+
+	// Panels whose old order is not kept among the matched panels moved
+	from := make(map[int]int)
+	var order []int
+	for i, j := range match {
+		if j >= 0 {
+			from[j] = i
+		}
+	}
+	for j := range new {
+		if i, ok := from[j]; ok {
+			order = append(order, i)
+		}
+	}
+	kept := inOrder(order)
+
+	for j := range new {
+		i, ok := from[j]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%s: added", panelLabel(keys2[j], j)))
+			continue
+		}
+		label := panelLabel(keys2[j], j)
+		if !kept[i] && !opts.IgnoreOrder {
+			changes = append(changes, fmt.Sprintf("%s: moved from %d to %d", label, i, j))
+		}
+		changes = append(changes, compareValues(old[i], new[j], label, opts)...)
+	}
+	return changes
+}
+
+// panelKeys returns the identity of each panel: its title, or the SLO an
+// untitled SLO panel shows, or "" for other untitled panels.
+func panelKeys(panels []interface{}) []string {
+	keys := make([]string, len(panels))
+	for i, p := range panels {
+		if title := panelField(p, "title"); title != "" {
+			keys[i] = fmt.Sprintf("%q", title)
+		} else if id := panelField(p, "slo_id"); id != "" {
+			keys[i] = fmt.Sprintf("slo_id=%q", id)
+		}
+	}
+	return keys
+}
+
+// panelLabel returns the value path of a panel, using its identity when it
+// has one and its index otherwise.
+func panelLabel(key string, index int) string {
+	if key == "" {
+		return fmt.Sprintf("panels[%d]", index)
+	}
+	return "panels[" + key + "]"
+}
+
+// panelField returns the string field name of a panel, or "".
+func panelField(panel interface{}, name string) string {
+	m, _ := panel.(map[string]interface{})
+	s, _ := m[name].(string)
+	return s
+}
+
+// inOrder returns the values of seq in a longest increasing subsequence:
+// the panels that kept their relative order.
+func inOrder(seq []int) map[int]bool {
+	// tails[k] is the index in seq of the smallest tail of an increasing
+	// subsequence of length k+1
+	var tails []int
+	prev := make([]int, len(seq))
+	for n, v := range seq {
+		k := sort.Search(len(tails), func(k int) bool { return seq[tails[k]] >= v })
+		if k > 0 {
+			prev[n] = tails[k-1]
+		} else {
+			prev[n] = -1
+		}
+		if k == len(tails) {
+			tails = append(tails, n)
+		} else {
+			tails[k] = n
+		}
+	}
+
+	kept := make(map[int]bool)
+	if len(tails) == 0 {
+		return kept
+	}
+	for n := tails[len(tails)-1]; n >= 0; n = prev[n] {
+		kept[seq[n]] = true
+	}
+	return kept
+}
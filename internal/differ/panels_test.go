@@ -0,0 +1,93 @@
+package differ
+
+import (
+	"testing"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func boardChanges(t *testing.T, old, new string, opts Options) []string {
+	t.Helper()
+	data1 := `{"boards": {"Overview": ` + old + `}}`
+	data2 := `{"boards": {"Overview": ` + new + `}}`
+	result, err := NewWithOptions(opts).DiffData([]byte(data1), []byte(data2), coredomain.DiffOpts{})
+	require.NoError(t, err)
+	if len(result.Entries) == 0 {
+		return nil
+	}
+	require.Len(t, result.Entries, 1)
+	return result.Entries[0].Changes
+}
+
+const panelsBoard = `{"name": "Overview", "panels": [
+	{"type": "query", "title": "Latency", "query": {"time_range": 3600}},
+	{"type": "query", "title": "Errors", "query": {"time_range": 3600}},
+	{"type": "text", "content": "Notes"}
+]}`
+
+func TestDiff_BoardPanelInserted(t *testing.T) {
+	changes := boardChanges(t, panelsBoard, `{"name": "Overview", "panels": [
+		{"type": "query", "title": "Throughput", "query": {"time_range": 3600}},
+		{"type": "query", "title": "Latency", "query": {"time_range": 3600}},
+		{"type": "query", "title": "Errors", "query": {"time_range": 7200}},
+		{"type": "text", "content": "Notes"}
+	]}`, Options{})
+
+	assert.Equal(t, []string{
+		`panels["Throughput"]: added`,
+		`panels["Errors"].query.time_range: 3600 -> 7200`,
+	}, changes)
+}
+
+func TestDiff_BoardPanelReordered(t *testing.T) {
+	reordered := `{"name": "Overview", "panels": [
+		{"type": "query", "title": "Errors", "query": {"time_range": 3600}},
+		{"type": "query", "title": "Latency", "query": {"time_range": 3600}},
+		{"type": "text", "content": "Notes"}
+	]}`
+	assert.Equal(t, []string{`panels["Errors"]: moved from 1 to 0`}, boardChanges(t, panelsBoard, reordered, Options{}))
+
+	result, err := New().DiffData(
+		[]byte(`{"boards": {"Overview": `+panelsBoard+`}}`),
+		[]byte(`{"boards": {"Overview": `+reordered+`}}`),
+		coredomain.DiffOpts{IgnoreOrder: true})
+	require.NoError(t, err)
+	assert.Empty(t, result.Entries)
+}
+
+func TestDiff_BoardPanelRemoved(t *testing.T) {
+	changes := boardChanges(t, panelsBoard, `{"name": "Overview Board", "panels": [
+		{"type": "query", "title": "Errors", "query": {"time_range": 3600}},
+		{"type": "text", "content": "More notes"}
+	]}`, Options{})
+
+	assert.Equal(t, []string{
+		`name: "Overview" -> "Overview Board"`,
+		`panels["Latency"]: removed`,
+		`panels[1].content: "Notes" -> "More notes"`,
+	}, changes)
+}
+
+func TestDiff_BoardPanelIgnore(t *testing.T) {
+	changed := `{"name": "Overview", "panels": [
+		{"type": "query", "title": "Latency", "query": {"time_range": 7200}},
+		{"type": "query", "title": "Errors", "query": {"time_range": 7200}},
+		{"type": "text", "content": "Notes"}
+	]}`
+	assert.Empty(t, boardChanges(t, panelsBoard, changed, Options{Ignore: []string{"query.time_range"}}))
+	assert.Empty(t, boardChanges(t, panelsBoard, `{"name": "Overview"}`, Options{Ignore: []string{"panels"}}))
+}
+
+func TestDiff_BoardSLOPanels(t *testing.T) {
+	changes := boardChanges(t,
+		`{"name": "SLOs", "panels": [{"type": "slo", "slo_id": "a"}, {"type": "slo", "slo_id": "b"}]}`,
+		`{"name": "SLOs", "panels": [{"type": "slo", "slo_id": "b"}]}`, Options{})
+	assert.Equal(t, []string{`panels[slo_id="a"]: removed`}, changes)
+}
+
+func TestInOrder(t *testing.T) {
+	assert.Equal(t, map[int]bool{0: true, 2: true, 3: true}, inOrder([]int{1, 0, 2, 3}))
+	assert.Empty(t, inOrder(nil))
+}
@@ -81,6 +81,72 @@ var PerformanceBoard = board.Board{
 	assert.Len(t, resources.Boards, 1)
 }
 
+func TestDiscoverAll_DocComments(t *testing.T) {
+	dir := t.TempDir()
+	content := `package observability
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/slo"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+// Latency is the p99 latency of
+// checkout requests.
+//
+// Owned by the payments team.
+var Latency = query.Query{Dataset: "checkout"}
+
+// Requests groups the request queries.
+var (
+	// Errors counts failed requests.
+	Errors = query.Query{Dataset: "checkout"}
+
+	Throughput = query.Query{Dataset: "checkout"}
+)
+
+// Recent returns the last hour of checkout requests.
+func Recent() query.Query {
+	return query.Query{Dataset: "checkout"}
+}
+
+// SlowCheckout pages when checkout is slow.
+//
+//go:generate echo directives are dropped
+var SlowCheckout = trigger.Trigger{Query: Latency}
+
+// Availability is the checkout availability SLO.
+var Availability = slo.SLO{Description: "Declared description"}
+
+// Overview shows checkout health.
+var Overview = board.Board{Name: "Checkout"}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "checkout.go"), []byte(content), 0644))
+
+	resources, err := DiscoverAll(dir)
+	require.NoError(t, err)
+
+	docs := make(map[string]string)
+	for _, q := range resources.Queries {
+		docs[q.Name] = q.Doc
+	}
+	assert.Equal(t, map[string]string{
+		"Latency":    "Latency is the p99 latency of checkout requests.\n\nOwned by the payments team.",
+		"Errors":     "Errors counts failed requests.",
+		"Throughput": "",
+		"Recent":     "Recent returns the last hour of checkout requests.",
+	}, docs)
+
+	require.Len(t, resources.Triggers, 1)
+	assert.Equal(t, "SlowCheckout pages when checkout is slow.", resources.Triggers[0].Doc)
+	require.Len(t, resources.SLOs, 1)
+	assert.Equal(t, "Availability is the checkout availability SLO.", resources.SLOs[0].Doc)
+	assert.Equal(t, "Declared description", resources.SLOs[0].Description)
+	require.Len(t, resources.Boards, 1)
+	assert.Equal(t, "Overview shows checkout health.", resources.Boards[0].Doc)
+}
+
 func TestDiscoverAll_EmptyDirectory(t *testing.T) {
 	dir := t.TempDir()
 
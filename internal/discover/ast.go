@@ -5,6 +5,8 @@ import (
 	"go/token"
 	"strconv"
 	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/query"
 )
 
 // extractStringLiteral extracts a string value from an expression.
@@ -16,11 +18,12 @@ func extractStringLiteral(expr ast.Expr) string {
 	return ""
 }
 
-// extractIntLiteral extracts an int value from an expression.
+// extractIntLiteral extracts an int value from an expression. Literals are
+// read with Go syntax, so 1_000_000 and 0x10 are accepted.
 func extractIntLiteral(expr ast.Expr) int {
 	if lit, ok := expr.(*ast.BasicLit); ok && lit.Kind == token.INT {
-		val, _ := strconv.Atoi(lit.Value)
-		return val
+		val, _ := strconv.ParseInt(lit.Value, 0, 64)
+		return int(val)
 	}
 	return 0
 }
@@ -116,7 +119,7 @@ func extractCalculation(expr ast.Expr) Calculation {
 
 				// Extract column argument if present
 				if len(call.Args) > 0 {
-					calc.Column = extractStringLiteral(call.Args[0])
+					calc.Column, calc.ColumnRef = extractCalculationColumn(call.Args[0])
 				}
 			}
 		}
@@ -128,7 +131,7 @@ func extractCalculation(expr ast.Expr) Calculation {
 			calc.Op = normalizeCalculationOp(extractStringLiteral(op))
 		}
 		if col := extractFieldValue(comp, "Column"); col != nil {
-			calc.Column = extractStringLiteral(col)
+			calc.Column, calc.ColumnRef = extractCalculationColumn(col)
 		}
 		if alias := extractFieldValue(comp, "Alias"); alias != nil {
 			calc.Alias = extractStringLiteral(alias)
@@ -138,6 +141,19 @@ func extractCalculation(expr ast.Expr) Calculation {
 	return calc
 }
 
+// extractCalculationColumn extracts the column of a calculation: a string
+// literal, or a reference to the Alias of a derived column variable declared
+// in the same package, such as ErrorRateColumn.Alias, which is returned as
+// ref for discovery to resolve.
+func extractCalculationColumn(expr ast.Expr) (column, ref string) {
+	if sel, ok := expr.(*ast.SelectorExpr); ok && sel.Sel.Name == "Alias" {
+		if ident, ok := sel.X.(*ast.Ident); ok {
+			return "", ident.Name
+		}
+	}
+	return extractStringLiteral(expr), ""
+}
+
 // normalizeCalculationOp normalizes calculation operation names to uppercase Honeycomb format.
 func normalizeCalculationOp(funcName string) string {
 	mapping := map[string]string{
@@ -175,6 +191,9 @@ func extractTimeRange(expr ast.Expr) TimeRange {
 		if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
 			if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == "query" {
 				funcName := sel.Sel.Name
+				if funcName == "AbsoluteIn" {
+					return extractAbsoluteIn(call)
+				}
 				if len(call.Args) > 0 {
 					n := extractIntLiteral(call.Args[0])
 					switch funcName {
@@ -216,6 +235,56 @@ func extractTimeRange(expr ast.Expr) TimeRange {
 	return tr
 }
 
+// extractAbsoluteIn converts a query.AbsoluteIn(zone, start, end) call with
+// string literal arguments to Unix times, as the call would at run time.
+func extractAbsoluteIn(call *ast.CallExpr) TimeRange {
+	if len(call.Args) != 3 {
+		return TimeRange{Err: "query.AbsoluteIn requires a zone, start and end"}
+	}
+
+	var args [3]string
+	for i, arg := range call.Args {
+		lit, ok := arg.(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return TimeRange{Err: "query.AbsoluteIn arguments must be string literals"}
+		}
+		args[i], _ = strconv.Unquote(lit.Value)
+	}
+
+	tr, err := query.ParseAbsolute(args[0], args[1], args[2])
+	if err != nil {
+		return TimeRange{Zone: args[0], Err: err.Error()}
+	}
+	return TimeRange{StartTime: tr.StartTime, EndTime: tr.EndTime, Zone: args[0]}
+}
+
+// declDoc returns the doc comment of a variable declaration: the comment
+// above its spec, or above the declaration when it is not grouped.
+func declDoc(decl *ast.GenDecl, spec *ast.ValueSpec) string {
+	if spec.Doc != nil {
+		return docText(spec.Doc)
+	}
+	if !decl.Lparen.IsValid() {
+		return docText(decl.Doc)
+	}
+	return ""
+}
+
+// docText returns the text of a doc comment with the lines of each paragraph
+// joined, as descriptions are shown as flowing text.
+func docText(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	var paragraphs []string
+	for _, p := range strings.Split(doc.Text(), "\n\n") {
+		if p = strings.Join(strings.Fields(p), " "); p != "" {
+			paragraphs = append(paragraphs, p)
+		}
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
 // getIdentifierName extracts the name from a variable/const declaration.
 func getIdentifierName(spec *ast.ValueSpec) string {
 	if len(spec.Names) > 0 {
@@ -232,6 +301,25 @@ func getFunctionName(decl *ast.FuncDecl) string {
 	return ""
 }
 
+// queryRefName returns the name of the query an expression refers to: a
+// variable (Latency), a variable of another package (queries.Latency) or a
+// call of a function without arguments (Recent(), queries.Recent()).
+// Anything else refers to no discovered query and returns "".
+func queryRefName(expr ast.Expr) string {
+	if call, ok := expr.(*ast.CallExpr); ok && len(call.Args) == 0 {
+		expr = call.Fun
+	}
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name
+	case *ast.SelectorExpr:
+		if _, ok := e.X.(*ast.Ident); ok {
+			return e.Sel.Name
+		}
+	}
+	return ""
+}
+
 // findQueryComposites recursively finds all query.Query composite literals in an expression.
 func findQueryComposites(expr ast.Expr) []*ast.CompositeLit {
 	var result []*ast.CompositeLit
@@ -324,13 +412,10 @@ func extractFilter(expr ast.Expr) Filter {
 				// Map function name to operator
 				filter.Op = mapFilterFuncToOp(funcName)
 
-				// Extract value argument (second arg) - can be string or int
+				// Extract value argument (second arg)
 				if len(call.Args) > 1 {
-					if s := extractStringLiteral(call.Args[1]); s != "" {
-						filter.Value = s
-					} else {
-						filter.Value = extractIntLiteral(call.Args[1])
-					}
+					filter.Value, _ = extractFilterValue(call.Args[1])
+					filter.HasValue = true
 				}
 			}
 		}
@@ -345,17 +430,111 @@ func extractFilter(expr ast.Expr) Filter {
 			filter.Op = extractStringLiteral(op)
 		}
 		if val := extractFieldValue(comp, "Value"); val != nil {
-			if s := extractStringLiteral(val); s != "" {
-				filter.Value = s
-			} else {
-				filter.Value = extractIntLiteral(val)
-			}
+			filter.Value, _ = extractFilterValue(val)
+			filter.HasValue = !isNilIdent(val)
 		}
 	}
 
 	return filter
 }
 
+// extractFilterValue extracts a literal filter value: a string, int, float or
+// bool literal (optionally negated), a slice literal of those, or a call to one
+// of the query value constructors (StringValue, NumberValue, BoolValue,
+// ListValue). It returns false when the value is not a literal, e.g. a
+// variable or function call.
+func extractFilterValue(expr ast.Expr) (any, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.STRING:
+			v, err := strconv.Unquote(e.Value)
+			return v, err == nil
+		case token.INT:
+			v, err := strconv.Atoi(e.Value)
+			return v, err == nil
+		case token.FLOAT:
+			v, err := strconv.ParseFloat(e.Value, 64)
+			return v, err == nil
+		}
+
+	case *ast.Ident:
+		switch e.Name {
+		case "true", "false":
+			return e.Name == "true", true
+		case "nil":
+			return nil, true
+		}
+
+	case *ast.UnaryExpr:
+		if e.Op != token.SUB {
+			break
+		}
+		switch v, ok := extractFilterValue(e.X); n := v.(type) {
+		case int:
+			return -n, ok
+		case float64:
+			return -n, ok
+		}
+
+	case *ast.CompositeLit:
+		if _, ok := e.Type.(*ast.ArrayType); !ok {
+			// Structs and maps are not valid filter values; keep their kind
+			return map[string]any{}, true
+		}
+		list := make([]any, 0, len(e.Elts))
+		for _, elt := range e.Elts {
+			v, ok := extractFilterValue(elt)
+			if !ok {
+				return nil, false
+			}
+			list = append(list, v)
+		}
+		return list, true
+
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok {
+			break
+		}
+		if ident, ok := sel.X.(*ast.Ident); !ok || ident.Name != "query" {
+			break
+		}
+		switch sel.Sel.Name {
+		case "StringValue", "BoolValue":
+			if len(e.Args) == 1 {
+				return extractFilterValue(e.Args[0])
+			}
+		case "NumberValue":
+			if len(e.Args) == 1 {
+				v, ok := extractFilterValue(e.Args[0])
+				if n, isInt := v.(int); isInt {
+					return float64(n), ok
+				}
+				return v, ok
+			}
+		case "ListValue":
+			list := make([]any, 0, len(e.Args))
+			for _, arg := range e.Args {
+				v, ok := extractFilterValue(arg)
+				if !ok {
+					return nil, false
+				}
+				list = append(list, v)
+			}
+			return list, true
+		}
+	}
+
+	return nil, false
+}
+
+// isNilIdent reports whether expr is the identifier nil.
+func isNilIdent(expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	return ok && ident.Name == "nil"
+}
+
 // mapFilterFuncToOp maps filter function names to operators.
 func mapFilterFuncToOp(funcName string) string {
 	mapping := map[string]string{
@@ -423,6 +602,54 @@ func extractOrder(expr ast.Expr) Order {
 	return order
 }
 
+// extractTop extracts a top-N selection: query.TopN(10, query.P99("duration_ms"))
+// or a query.Top composite literal.
+func extractTop(expr ast.Expr) *Top {
+	if call, ok := expr.(*ast.CallExpr); ok && len(call.Args) == 2 {
+		if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "TopN" {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == "query" {
+				return &Top{N: extractIntLiteral(call.Args[0]), Calculation: extractCalculation(call.Args[1])}
+			}
+		}
+	}
+	if comp, ok := expr.(*ast.CompositeLit); ok {
+		var top Top
+		if n := extractFieldValue(comp, "N"); n != nil {
+			top.N = extractIntLiteral(n)
+		}
+		if c := extractFieldValue(comp, "Calculation"); c != nil {
+			top.Calculation = extractCalculation(c)
+		}
+		return &top
+	}
+	return nil
+}
+
+// applyTop applies the top-N selection of q to its calculations, orders and
+// limit, as query.Query.ExpandTop does.
+func applyTop(q *DiscoveredQuery) {
+	if q.Top == nil {
+		return
+	}
+	c := q.Top.Calculation
+	found := false
+	for _, calc := range q.Calculations {
+		if calc.Op == c.Op && calc.Column == c.Column {
+			found = true
+			break
+		}
+	}
+	if !found {
+		q.Calculations = append(q.Calculations, c)
+	}
+	if len(q.Orders) == 0 {
+		q.Orders = []Order{{Op: c.Op, Column: c.Column, Order: "descending"}}
+	}
+	if q.Limit == 0 {
+		q.Limit = q.Top.N
+	}
+}
+
 // extractStyleMetadata extracts style metadata from a query composite literal.
 func extractStyleMetadata(comp *ast.CompositeLit) StyleMetadata {
 	var meta StyleMetadata
@@ -4,6 +4,7 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"reflect"
 	"testing"
 )
 
@@ -155,6 +156,31 @@ var i = 42`
 	}
 }
 
+func TestQueryRefName(t *testing.T) {
+	tests := []struct {
+		expr     string
+		expected string
+	}{
+		{"Latency", "Latency"},
+		{"queries.Latency", "Latency"},
+		{"Recent()", "Recent"},
+		{"queries.Recent()", "Recent"},
+		{"Window(7)", ""},
+		{"a.b.Latency", ""},
+		{`"Latency"`, ""},
+	}
+
+	for _, tt := range tests {
+		expr, err := parser.ParseExpr(tt.expr)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", tt.expr, err)
+		}
+		if result := queryRefName(expr); result != tt.expected {
+			t.Errorf("queryRefName(%s) = %q, want %q", tt.expr, result, tt.expected)
+		}
+	}
+}
+
 func TestExtractIntLiteral(t *testing.T) {
 	src := `package test
 var i = 42`
@@ -499,6 +525,91 @@ var f = query.Filter{Column: "count", Op: ">", Value: 100}`
 	}
 }
 
+func TestExtractFilterValue(t *testing.T) {
+	tests := []struct {
+		expr   string
+		want   interface{}
+		wantOK bool
+	}{
+		{`"error"`, "error", true},
+		{`500`, 500, true},
+		{`-1`, -1, true},
+		{`0.25`, 0.25, true},
+		{`true`, true, true},
+		{`nil`, nil, true},
+		{`[]any{"a", 1}`, []interface{}{"a", 1}, true},
+		{`query.NumberValue(500)`, 500.0, true},
+		{`query.BoolValue(false)`, false, true},
+		{`query.ListValue("us", "eu")`, []interface{}{"us", "eu"}, true},
+		{`threshold`, nil, false},
+		{`[]string{region}`, nil, false},
+		{`fmt.Sprint(1)`, nil, false},
+	}
+
+	for _, tt := range tests {
+		expr, err := parser.ParseExpr(tt.expr)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", tt.expr, err)
+		}
+		got, ok := extractFilterValue(expr)
+		if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("extractFilterValue(%s) = %#v, %v; want %#v, %v", tt.expr, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestExtractFilter_HasValue(t *testing.T) {
+	tests := map[string]bool{
+		`query.Exists("trace.id")`:                        false,
+		`query.Equals("service", serviceName)`:            true,
+		`query.Filter{Column: "a", Op: "exists"}`:         false,
+		`query.Filter{Column: "a", Op: "=", Value: nil}`:  false,
+		`query.Filter{Column: "a", Op: "=", Value: true}`: true,
+	}
+
+	for src, want := range tests {
+		expr, err := parser.ParseExpr(src)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", src, err)
+		}
+		if got := extractFilter(expr).HasValue; got != want {
+			t.Errorf("%s: HasValue = %v, want %v", src, got, want)
+		}
+	}
+}
+
+func TestExtractQueryFromComposite_Top(t *testing.T) {
+	tests := map[string]DiscoveredQuery{
+		// The calculation, order and limit are applied
+		`query.Query{Breakdowns: []string{"route"}, Calculations: []query.Calculation{query.Count()}, Top: query.TopN(10, query.P99("duration_ms"))}`: {
+			Calculations: []Calculation{{Op: "COUNT"}, {Op: "P99", Column: "duration_ms"}},
+			Orders:       []Order{{Op: "P99", Column: "duration_ms", Order: "descending"}},
+			Limit:        10,
+			Top:          &Top{N: 10, Calculation: Calculation{Op: "P99", Column: "duration_ms"}},
+		},
+		// An explicit limit is kept, for lint to report
+		`query.Query{Limit: 20, Top: query.Top{N: 5, Calculation: query.Count()}}`: {
+			Calculations: []Calculation{{Op: "COUNT"}},
+			Orders:       []Order{{Op: "COUNT", Order: "descending"}},
+			Limit:        20,
+			Top:          &Top{N: 5, Calculation: Calculation{Op: "COUNT"}},
+		},
+	}
+
+	for src, want := range tests {
+		expr, err := parser.ParseExpr(src)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", src, err)
+		}
+		got := extractQueryFromComposite(expr.(*ast.CompositeLit), token.NewFileSet(), "", "", "Q")
+		if !reflect.DeepEqual(got.Calculations, want.Calculations) || !reflect.DeepEqual(got.Orders, want.Orders) ||
+			got.Limit != want.Limit || !reflect.DeepEqual(got.Top, want.Top) {
+			t.Errorf("%s:\ngot  %+v %+v %d %+v\nwant %+v %+v %d %+v", src, got.Calculations, got.Orders, got.Limit, got.Top,
+				want.Calculations, want.Orders, want.Limit, want.Top)
+		}
+	}
+}
+
 func TestGetIdentifierName(t *testing.T) {
 	src := `package test
 var TestVar = 1`
@@ -8,6 +8,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/board"
 )
 
 // DiscoveredBoard represents a discovered board definition with metadata.
@@ -30,7 +32,12 @@ type DiscoveredBoard struct {
 	// Description is the Board.Description field value
 	Description string
 
-	// PanelCount is the number of panels in the board
+	// Doc is the Go doc comment of the declaration, used as the description
+	// when Description is empty
+	Doc string
+
+	// PanelCount is the number of panels in the board, counting the panels
+	// of sections rather than the sections themselves
 	PanelCount int
 
 	// QueryRefs are the names of queries referenced by QueryPanel
@@ -39,10 +46,72 @@ type DiscoveredBoard struct {
 	// SLORefs are the IDs of SLOs referenced by SLOPanelByID
 	SLORefs []string
 
+	// Panels describes each panel in declaration order, with the panels of
+	// sections in place of the section
+	Panels []DiscoveredPanel
+
+	// Sections are the board.Section groups in declaration order
+	Sections []DiscoveredSection
+
+	// PresetFilters are the board-level filters
+	PresetFilters []Filter
+
+	// Tags are the board's key-value tags
+	Tags []DiscoveredTag
+
 	// IsTemplate indicates if the board is generated from a function call
 	IsTemplate bool
 }
 
+// DiscoveredPanel describes a single board panel.
+type DiscoveredPanel struct {
+	// Type is the panel type: "query", "text" or "slo"
+	Type string
+
+	// Title is set by board.WithTitle
+	Title string
+
+	// X, Y, Width and Height are set by board.WithPosition
+	X, Y, Width, Height int
+
+	// QueryRef is the name of the query shown by a query panel
+	QueryRef string
+
+	// Query is the inline query of a query panel (nil when referenced by name)
+	Query *DiscoveredQuery
+
+	// Content is the markdown content of a text panel
+	Content string
+
+	// SLOID is the ID of the SLO shown by an SLO panel
+	SLOID string
+
+	// Section is the title of the board.Section the panel is grouped in, if
+	// any (the innermost one for nested sections)
+	Section string
+
+	// Graph holds the display settings of a query panel, set by
+	// board.WithQueryStyle, board.WithChartType, board.OmitMissingValues,
+	// board.LogScale and board.Stacked
+	Graph board.GraphSettings
+
+	// Line is the line number of the panel call
+	Line int
+}
+
+// DiscoveredSection describes a board.Section call.
+type DiscoveredSection struct {
+	// Title is the section title
+	Title string
+
+	// Line is the line number of the board.Section call
+	Line int
+
+	// PanelCount is the number of panels in the section, including those of
+	// nested sections
+	PanelCount int
+}
+
 // DiscoverBoards discovers all Board definitions in the specified directory.
 func DiscoverBoards(dir string) ([]DiscoveredBoard, error) {
 	info, err := os.Stat(dir)
@@ -64,7 +133,7 @@ func DiscoverBoards(dir string) ([]DiscoveredBoard, error) {
 			return nil
 		}
 
-		boards, err := discoverBoardsInFile(path)
+		boards, err := extractFile("boards", path, discoverBoardsInFile)
 		if err != nil {
 			return nil
 		}
@@ -103,6 +172,9 @@ func discoverBoardsInFile(path string) ([]DiscoveredBoard, error) {
 				for _, spec := range decl.Specs {
 					if valueSpec, ok := spec.(*ast.ValueSpec); ok {
 						boards := extractBoardsFromValueSpec(valueSpec, fset, absPath, packageName)
+						for i := range boards {
+							boards[i].Doc = declDoc(decl, valueSpec)
+						}
 						discovered = append(discovered, boards...)
 					}
 				}
@@ -111,6 +183,14 @@ func discoverBoardsInFile(path string) ([]DiscoveredBoard, error) {
 		return true
 	})
 
+	// Loops of init functions over a service list are unrolled
+	for _, e := range initLoopExpansions(node, fset, absPath) {
+		for _, b := range extractBoardsFromValueSpec(e.spec, e.fset, absPath, packageName) {
+			b.Line = e.line
+			discovered = append(discovered, b)
+		}
+	}
+
 	return discovered, nil
 }
 
@@ -123,6 +203,17 @@ func extractBoardsFromValueSpec(spec *ast.ValueSpec, fset *token.FileSet, file s
 		return discovered
 	}
 
+	// A fleet.Each template declares a resource per service
+	if expansions, ok, _ := expandFleet(spec, fset, file); ok {
+		for _, e := range expansions {
+			for _, b := range extractBoardsFromValueSpec(e.spec, e.fset, file, pkg) {
+				b.Line = e.line
+				discovered = append(discovered, b)
+			}
+		}
+		return discovered
+	}
+
 	for _, value := range spec.Values {
 		composites := findBoardComposites(value)
 		for _, comp := range composites {
@@ -189,6 +280,11 @@ func extractBoardFromComposite(comp *ast.CompositeLit, fset *token.FileSet, file
 			board.Description = extractStringLiteral(kv.Value)
 		case "Panels":
 			board.PanelCount, board.QueryRefs, board.SLORefs = extractPanelInfo(kv.Value)
+			board.Panels, board.Sections = extractPanels(kv.Value, fset, file, pkg, name)
+		case "PresetFilters":
+			board.PresetFilters = extractBoardFilters(kv.Value)
+		case "Tags":
+			board.Tags = extractTags(kv.Value)
 		}
 	}
 
@@ -197,16 +293,31 @@ func extractBoardFromComposite(comp *ast.CompositeLit, fset *token.FileSet, file
 
 // extractPanelInfo extracts panel count and references from a Panels field.
 func extractPanelInfo(expr ast.Expr) (int, []string, []string) {
-	var panelCount int
-	var queryRefs []string
-	var sloRefs []string
-
 	comp, ok := expr.(*ast.CompositeLit)
 	if !ok {
 		return 0, nil, nil
 	}
 
-	for _, elt := range comp.Elts {
+	return panelInfo(comp.Elts)
+}
+
+// panelInfo counts the panels in elts, including the panels of sections, and
+// collects their query and SLO references.
+func panelInfo(elts []ast.Expr) (int, []string, []string) {
+	var panelCount int
+	var queryRefs []string
+	var sloRefs []string
+
+	for _, elt := range elts {
+		if call, ok := elt.(*ast.CallExpr); ok && isSectionCall(call) {
+			if len(call.Args) > 1 {
+				n, queries, slos := panelInfo(call.Args[1:])
+				panelCount += n
+				queryRefs = append(queryRefs, queries...)
+				sloRefs = append(sloRefs, slos...)
+			}
+			continue
+		}
 		panelCount++
 
 		// Check for board.QueryPanel(SomeQuery) or board.SLOPanelByID("id")
@@ -217,8 +328,8 @@ func extractPanelInfo(expr ast.Expr) (int, []string, []string) {
 					case "QueryPanel":
 						// Extract query reference
 						if len(call.Args) > 0 {
-							if ident, ok := call.Args[0].(*ast.Ident); ok {
-								queryRefs = append(queryRefs, ident.Name)
+							if ref := queryRefName(call.Args[0]); ref != "" {
+								queryRefs = append(queryRefs, ref)
 							}
 						}
 					case "SLOPanelByID":
@@ -236,3 +347,192 @@ func extractPanelInfo(expr ast.Expr) (int, []string, []string) {
 
 	return panelCount, queryRefs, sloRefs
 }
+
+// extractPanels extracts the details of each board.QueryPanel, board.TextPanel
+// and board.SLOPanelByID call in a Panels field, and of each board.Section
+// grouping them.
+func extractPanels(expr ast.Expr, fset *token.FileSet, file string, pkg string, name string) ([]DiscoveredPanel, []DiscoveredSection) {
+	comp, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil, nil
+	}
+
+	e := &panelExtractor{fset: fset, file: file, pkg: pkg, name: name}
+	e.extract(comp.Elts, "")
+	return e.panels, e.sections
+}
+
+// panelExtractor collects the panels and sections of a board.
+type panelExtractor struct {
+	fset            *token.FileSet
+	file, pkg, name string
+
+	panels   []DiscoveredPanel
+	sections []DiscoveredSection
+}
+
+// extract records the panels in elts, grouped in section.
+func (e *panelExtractor) extract(elts []ast.Expr, section string) {
+	for _, elt := range elts {
+		call, ok := elt.(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || len(call.Args) == 0 {
+			continue
+		}
+		if ident, ok := sel.X.(*ast.Ident); !ok || ident.Name != "board" {
+			continue
+		}
+
+		if sel.Sel.Name == "Section" {
+			i := len(e.sections)
+			title := extractStringLiteral(call.Args[0])
+			e.sections = append(e.sections, DiscoveredSection{
+				Title: title,
+				Line:  e.fset.Position(call.Pos()).Line,
+			})
+			before := len(e.panels)
+			e.extract(call.Args[1:], title)
+			e.sections[i].PanelCount = len(e.panels) - before
+			continue
+		}
+
+		panel := DiscoveredPanel{Section: section, Line: e.fset.Position(call.Pos()).Line}
+		switch sel.Sel.Name {
+		case "QueryPanel":
+			panel.Type = "query"
+			if arg, ok := call.Args[0].(*ast.CompositeLit); ok {
+				if isQueryCompositeLit(arg) {
+					q := extractQueryFromComposite(arg, e.fset, e.file, e.pkg, e.name)
+					panel.Query = &q
+				}
+			} else {
+				panel.QueryRef = queryRefName(call.Args[0])
+			}
+		case "TextPanel":
+			panel.Type = "text"
+			panel.Content = extractStringLiteral(call.Args[0])
+		case "SLOPanelByID":
+			panel.Type = "slo"
+			panel.SLOID = extractStringLiteral(call.Args[0])
+		default:
+			continue
+		}
+
+		for _, opt := range call.Args[1:] {
+			applyPanelOption(&panel, opt)
+		}
+		e.panels = append(e.panels, panel)
+	}
+}
+
+// isSectionCall reports whether call is a board.Section call.
+func isSectionCall(call *ast.CallExpr) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == "board" && sel.Sel.Name == "Section"
+}
+
+// graphConstants maps the names of the board.QueryStyle and board.ChartType
+// constants to their values.
+var graphConstants = map[string]string{
+	"StyleGraph":   string(board.StyleGraph),
+	"StyleTable":   string(board.StyleTable),
+	"StyleCombo":   string(board.StyleCombo),
+	"ChartDefault": string(board.ChartDefault),
+	"ChartLine":    string(board.ChartLine),
+	"ChartStacked": string(board.ChartStacked),
+	"ChartBar":     string(board.ChartBar),
+	"ChartStat":    string(board.ChartStat),
+	"ChartPie":     string(board.ChartPie),
+	"ChartColumn":  string(board.ChartColumn),
+}
+
+// extractGraphConstant maps a board.QueryStyle or board.ChartType constant,
+// or a string literal, to its value.
+func extractGraphConstant(expr ast.Expr) string {
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == "board" {
+			return graphConstants[sel.Sel.Name]
+		}
+	}
+	return extractStringLiteral(expr)
+}
+
+// applyPanelOption records board.WithTitle, board.WithPosition and graph
+// settings options.
+func applyPanelOption(panel *DiscoveredPanel, expr ast.Expr) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok {
+		return
+	}
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return
+	}
+
+	switch sel.Sel.Name {
+	case "WithTitle":
+		if len(call.Args) == 1 {
+			panel.Title = extractStringLiteral(call.Args[0])
+		}
+	case "WithPosition":
+		if len(call.Args) == 4 {
+			panel.X = extractIntLiteral(call.Args[0])
+			panel.Y = extractIntLiteral(call.Args[1])
+			panel.Width = extractIntLiteral(call.Args[2])
+			panel.Height = extractIntLiteral(call.Args[3])
+		}
+	case "WithQueryStyle":
+		if len(call.Args) == 1 {
+			panel.Graph.Style = board.QueryStyle(extractGraphConstant(call.Args[0]))
+		}
+	case "WithChartType":
+		if len(call.Args) == 1 {
+			panel.Graph.ChartType = board.ChartType(extractGraphConstant(call.Args[0]))
+		}
+	case "OmitMissingValues":
+		panel.Graph.OmitMissingValues = true
+	case "LogScale":
+		panel.Graph.LogScale = true
+	case "Stacked":
+		panel.Graph.Stacked = true
+	}
+}
+
+// extractBoardFilters extracts board.Filter{Column, Operation, Value} literals
+// from a PresetFilters field.
+func extractBoardFilters(expr ast.Expr) []Filter {
+	comp, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+
+	var filters []Filter
+	for _, elt := range comp.Elts {
+		fc, ok := elt.(*ast.CompositeLit)
+		if !ok {
+			continue
+		}
+
+		var filter Filter
+		if col := extractFieldValue(fc, "Column"); col != nil {
+			filter.Column = extractStringLiteral(col)
+		}
+		if op := extractFieldValue(fc, "Operation"); op != nil {
+			filter.Op = extractStringLiteral(op)
+		}
+		if val := extractFieldValue(fc, "Value"); val != nil {
+			filter.Value, _ = extractFilterValue(val)
+			filter.HasValue = !isNilIdent(val)
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/board"
 )
 
 func TestDiscoverBoards_BasicBoard(t *testing.T) {
@@ -92,6 +94,38 @@ var Dashboard = board.Board{
 	assert.Contains(t, board.QueryRefs, "ErrorRates")
 }
 
+func TestDiscoverBoards_QueryRefsAcrossPackages(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "boards.go")
+
+	content := `package boards
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"example.com/app/queries"
+)
+
+var Dashboard = board.Board{
+	Name: "Dashboard",
+	Panels: []board.Panel{
+		board.QueryPanel(queries.SlowRequests),
+		board.QueryPanel(queries.Recent()),
+	},
+}
+`
+	err := os.WriteFile(testFile, []byte(content), 0644)
+	require.NoError(t, err)
+
+	boards, err := DiscoverBoards(dir)
+	require.NoError(t, err)
+	require.Len(t, boards, 1)
+
+	assert.Equal(t, []string{"SlowRequests", "Recent"}, boards[0].QueryRefs)
+	require.Len(t, boards[0].Panels, 2)
+	assert.Equal(t, "SlowRequests", boards[0].Panels[0].QueryRef)
+	assert.Equal(t, "Recent", boards[0].Panels[1].QueryRef)
+}
+
 func TestDiscoverBoards_WithSLORefs(t *testing.T) {
 	dir := t.TempDir()
 	testFile := filepath.Join(dir, "boards.go")
@@ -172,3 +206,135 @@ var TestBoard = board.Board{
 	require.NoError(t, err)
 	assert.Empty(t, boards)
 }
+
+func TestDiscoverBoards_Panels(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "boards.go")
+
+	content := `package boards
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+var SlowRequests = query.Query{
+	Dataset: "production",
+}
+
+var Dashboard = board.Board{
+	Name: "Dashboard",
+	Panels: []board.Panel{
+		board.QueryPanel(SlowRequests, board.WithTitle("Slow"), board.WithPosition(0, 0, 12, 6)),
+		board.QueryPanel(query.Query{Dataset: "api", Breakdowns: []string{"endpoint"}}),
+		board.TextPanel("## Runbook", board.WithTitle("Notes")),
+		board.SLOPanelByID("slo-123"),
+	},
+	PresetFilters: []board.Filter{
+		{Column: "service", Operation: "=", Value: "checkout"},
+	},
+}
+`
+	err := os.WriteFile(testFile, []byte(content), 0644)
+	require.NoError(t, err)
+
+	boards, err := DiscoverBoards(dir)
+	require.NoError(t, err)
+	require.Len(t, boards, 1)
+
+	panels := boards[0].Panels
+	require.Len(t, panels, 4)
+
+	assert.Equal(t, DiscoveredPanel{Type: "query", Title: "Slow", Width: 12, Height: 6, QueryRef: "SlowRequests", Line: 15}, panels[0])
+
+	assert.Equal(t, "query", panels[1].Type)
+	require.NotNil(t, panels[1].Query)
+	assert.Equal(t, "api", panels[1].Query.Dataset)
+	assert.Equal(t, []string{"endpoint"}, panels[1].Query.Breakdowns)
+
+	assert.Equal(t, DiscoveredPanel{Type: "text", Title: "Notes", Content: "## Runbook", Line: 17}, panels[2])
+	assert.Equal(t, DiscoveredPanel{Type: "slo", SLOID: "slo-123", Line: 18}, panels[3])
+
+	require.Len(t, boards[0].PresetFilters, 1)
+	assert.Equal(t, Filter{Column: "service", Op: "=", Value: "checkout", HasValue: true}, boards[0].PresetFilters[0])
+}
+
+func TestDiscoverBoards_Sections(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "boards.go")
+
+	content := `package boards
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+var SlowRequests = query.Query{
+	Dataset: "production",
+}
+
+var Dashboard = board.Board{
+	Name: "Dashboard",
+	Panels: []board.Panel{
+		board.TextPanel("## Overview"),
+		board.Section("Latency",
+			board.QueryPanel(SlowRequests, board.WithTitle("Slow")),
+			board.SLOPanelByID("slo-123"),
+		),
+		board.Section("Errors"),
+	},
+}
+`
+	err := os.WriteFile(testFile, []byte(content), 0644)
+	require.NoError(t, err)
+
+	boards, err := DiscoverBoards(dir)
+	require.NoError(t, err)
+	require.Len(t, boards, 1)
+	b := boards[0]
+
+	// Panels of sections are counted and referenced like top-level panels
+	assert.Equal(t, 3, b.PanelCount)
+	assert.Equal(t, []string{"SlowRequests"}, b.QueryRefs)
+	assert.Equal(t, []string{"slo-123"}, b.SLORefs)
+
+	require.Len(t, b.Panels, 3)
+	assert.Equal(t, DiscoveredPanel{Type: "text", Content: "## Overview", Line: 15}, b.Panels[0])
+	assert.Equal(t, DiscoveredPanel{Type: "query", Title: "Slow", QueryRef: "SlowRequests", Section: "Latency", Line: 17}, b.Panels[1])
+	assert.Equal(t, DiscoveredPanel{Type: "slo", SLOID: "slo-123", Section: "Latency", Line: 18}, b.Panels[2])
+
+	assert.Equal(t, []DiscoveredSection{
+		{Title: "Latency", Line: 16, PanelCount: 2},
+		{Title: "Errors", Line: 20, PanelCount: 0},
+	}, b.Sections)
+}
+
+func TestDiscoverBoards_GraphSettings(t *testing.T) {
+	dir := t.TempDir()
+	content := `package boards
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+var Traffic = query.Query{Dataset: "production"}
+
+var Dashboard = board.Board{
+	Name: "Dashboard",
+	Panels: []board.Panel{
+		board.QueryPanel(Traffic, board.WithQueryStyle(board.StyleCombo), board.WithChartType(board.ChartBar), board.Stacked()),
+		board.QueryPanel(Traffic, board.WithChartType("line"), board.LogScale(), board.OmitMissingValues()),
+	},
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "boards.go"), []byte(content), 0644))
+
+	boards, err := DiscoverBoards(dir)
+	require.NoError(t, err)
+	require.Len(t, boards, 1)
+	require.Len(t, boards[0].Panels, 2)
+	assert.Equal(t, board.GraphSettings{Style: board.StyleCombo, ChartType: board.ChartBar, Stacked: true}, boards[0].Panels[0].Graph)
+	assert.Equal(t, board.GraphSettings{ChartType: board.ChartLine, LogScale: true, OmitMissingValues: true}, boards[0].Panels[1].Graph)
+}
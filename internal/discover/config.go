@@ -0,0 +1,127 @@
+package discovery
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFile is the name of the project configuration file.
+const ConfigFile = ".wetwire-honeycomb.yaml"
+
+// Config is the part of ConfigFile that discovery applies to the resources it
+// finds, so that build, lint and every other command see the same values,
+// and the naming policy lint checks them against. Other sections of the file
+// are ignored.
+type Config struct {
+	// Datasets holds per-dataset defaults, keyed by dataset name
+	Datasets map[string]DatasetConfig `yaml:"datasets"`
+
+	// Naming is the naming policy of the resources (WHC063)
+	Naming NamingPolicy `yaml:"naming"`
+}
+
+// DatasetConfig holds the defaults of the queries on a dataset.
+type DatasetConfig struct {
+	// Limit is the result limit of queries that set none
+	Limit int `yaml:"limit"`
+}
+
+// NamingPolicy holds a regular expression per resource type that the Go
+// variable names of resources of that type must match in full, e.g.
+// ".*Alert" for triggers. An empty pattern accepts any name.
+type NamingPolicy struct {
+	Queries  string `yaml:"queries"`
+	SLOs     string `yaml:"slos"`
+	Triggers string `yaml:"triggers"`
+	Boards   string `yaml:"boards"`
+}
+
+// Patterns returns the non-empty patterns of p keyed by their ConfigFile
+// key: "queries", "slos", "triggers" and "boards".
+func (p NamingPolicy) Patterns() map[string]string {
+	patterns := make(map[string]string)
+	for key, pattern := range map[string]string{"queries": p.Queries, "slos": p.SLOs, "triggers": p.Triggers, "boards": p.Boards} {
+		if pattern != "" {
+			patterns[key] = pattern
+		}
+	}
+	return patterns
+}
+
+// CompileNamingPattern compiles a NamingPolicy pattern so that it matches
+// whole names only.
+func CompileNamingPattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + pattern + ")$")
+}
+
+// LoadConfig reads the ConfigFile nearest to dir: in dir itself or the
+// closest parent, up to the root of its Go module (the directory holding
+// go.mod). Without a file the Config is empty.
+func LoadConfig(dir string) (*Config, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		path := filepath.Join(dir, ConfigFile)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return parseConfig(path, data)
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		parent := filepath.Dir(dir)
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil || parent == dir {
+			return &Config{}, nil
+		}
+		dir = parent
+	}
+}
+
+// parseConfig parses and validates the ConfigFile at path.
+func parseConfig(path string, data []byte) (*Config, error) {
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for name, ds := range config.Datasets {
+		if ds.Limit < 0 {
+			return nil, fmt.Errorf("%s: datasets.%s.limit must be positive, got %d", path, name, ds.Limit)
+		}
+	}
+	for key, pattern := range config.Naming.Patterns() {
+		if _, err := CompileNamingPattern(pattern); err != nil {
+			return nil, fmt.Errorf("%s: naming.%s: %w", path, key, err)
+		}
+	}
+	return &config, nil
+}
+
+// Apply fills in the defaults of the dataset of each query of resources,
+// including the inline queries of board panels, that leaves them unset.
+func (c *Config) Apply(resources *DiscoveredResources) {
+	for i := range resources.Queries {
+		c.applyQuery(&resources.Queries[i])
+	}
+	for i := range resources.Boards {
+		for _, p := range resources.Boards[i].Panels {
+			if p.Query != nil {
+				c.applyQuery(p.Query)
+			}
+		}
+	}
+}
+
+func (c *Config) applyQuery(q *DiscoveredQuery) {
+	if q.Limit == 0 {
+		q.Limit = c.Datasets[q.Dataset].Limit
+	}
+}
@@ -0,0 +1,128 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const configLimits = `lint:
+  disabled_rules:
+    - WHC003
+datasets:
+  production:
+    limit: 100
+  dev:
+    limit: 1000
+`
+
+func TestLoadConfig(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, ConfigFile), []byte(configLimits), 0644))
+	dir := filepath.Join(root, "services", "api")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+
+	config, err := LoadConfig(dir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]DatasetConfig{"production": {Limit: 100}, "dev": {Limit: 1000}}, config.Datasets)
+}
+
+func TestLoadConfig_StopsAtModuleRoot(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, ConfigFile), []byte(configLimits), 0644))
+	module := filepath.Join(root, "module")
+	require.NoError(t, os.MkdirAll(module, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(module, "go.mod"), []byte("module example.com/m\n"), 0644))
+
+	config, err := LoadConfig(module)
+	require.NoError(t, err)
+	assert.Empty(t, config.Datasets)
+}
+
+func TestLoadConfig_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ConfigFile)
+
+	require.NoError(t, os.WriteFile(path, []byte("datasets: [\n"), 0644))
+	_, err := LoadConfig(dir)
+	assert.ErrorContains(t, err, ConfigFile)
+
+	require.NoError(t, os.WriteFile(path, []byte("datasets:\n  production:\n    limit: -1\n"), 0644))
+	_, err = LoadConfig(dir)
+	assert.ErrorContains(t, err, "datasets.production.limit must be positive")
+}
+
+func TestLoadConfig_Naming(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ConfigFile)
+
+	require.NoError(t, os.WriteFile(path, []byte("naming:\n  queries: '[A-Z][A-Za-z0-9]*'\n  triggers: '.*Alert'\n"), 0644))
+	config, err := LoadConfig(dir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"queries": "[A-Z][A-Za-z0-9]*", "triggers": ".*Alert"}, config.Naming.Patterns())
+
+	re, err := CompileNamingPattern(config.Naming.Triggers)
+	require.NoError(t, err)
+	assert.True(t, re.MatchString("HighLatencyAlert"))
+	assert.False(t, re.MatchString("HighLatencyAlertTrigger"))
+
+	require.NoError(t, os.WriteFile(path, []byte("naming:\n  boards: '[A-Z'\n"), 0644))
+	_, err = LoadConfig(dir)
+	assert.ErrorContains(t, err, "naming.boards")
+}
+
+func TestDiscoverAll_DefaultLimits(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/m\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ConfigFile), []byte(configLimits), 0644))
+
+	content := `package queries
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+var ByEndpoint = query.Query{
+	Dataset:    "production",
+	Breakdowns: []string{"endpoint"},
+}
+
+var Top5 = query.Query{
+	Dataset:    "production",
+	Breakdowns: []string{"endpoint"},
+	Limit:      5,
+}
+
+var Staging = query.Query{
+	Dataset:    "staging",
+	Breakdowns: []string{"endpoint"},
+}
+
+var Overview = board.Board{
+	Name: "Overview",
+	Panels: []board.Panel{
+		board.QueryPanel(query.Query{Dataset: "dev"}),
+	},
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "queries.go"), []byte(content), 0644))
+
+	resources, err := DiscoverAll(dir)
+	require.NoError(t, err)
+
+	limits := make(map[string]int)
+	for _, q := range resources.Queries {
+		limits[q.Name+" "+q.Dataset] = q.Limit
+	}
+	assert.Equal(t, 100, limits["ByEndpoint production"])
+	assert.Equal(t, 5, limits["Top5 production"], "explicit limits are kept")
+	assert.Equal(t, 0, limits["Staging staging"], "datasets without defaults are left alone")
+
+	require.Len(t, resources.Boards, 1)
+	require.Len(t, resources.Boards[0].Panels, 1)
+	assert.Equal(t, 1000, resources.Boards[0].Panels[0].Query.Limit)
+}
@@ -0,0 +1,223 @@
+package discovery
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/resource"
+)
+
+// DiscoverCustom discovers the declarations of every kind registered with
+// resource.Register in the specified directory.
+func DiscoverCustom(dir string) ([]resource.Resource, error) {
+	types := make(map[string]string)
+	for _, k := range resource.Kinds() {
+		types[k.Type] = k.Name
+	}
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("path is not a directory: %s", dir)
+	}
+
+	var discovered []resource.Resource
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		resources, err := extractFile("custom resources", path, func(path string) ([]resource.Resource, error) {
+			return discoverCustomInFile(path, types)
+		})
+		if err != nil {
+			return nil
+		}
+		discovered = append(discovered, resources...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	return discovered, nil
+}
+
+// discoverCustomInFile discovers custom resources in a single Go source file.
+// types maps qualified Go type names to kind names.
+func discoverCustomInFile(path string, types map[string]string) ([]resource.Resource, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+
+	var discovered []resource.Resource
+	for _, decl := range node.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			for i, name := range valueSpec.Names {
+				if i >= len(valueSpec.Values) || !isExportedName(name.Name) {
+					continue
+				}
+				comp := customComposite(valueSpec.Values[i])
+				if comp == nil {
+					continue
+				}
+				kind, ok := types[qualifyTypeName(comp.Type)]
+				if !ok {
+					continue
+				}
+
+				r := resource.Resource{
+					Kind:    kind,
+					Name:    name.Name,
+					Package: node.Name.Name,
+					File:    absPath,
+					Line:    fset.Position(name.Pos()).Line,
+				}
+				r.Fields, _ = customValue(comp, &r.Refs).(map[string]any)
+				discovered = append(discovered, r)
+			}
+		}
+	}
+
+	return discovered, nil
+}
+
+// customComposite returns the composite literal a declaration is initialized
+// with, looking through &.
+func customComposite(expr ast.Expr) *ast.CompositeLit {
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		expr = unary.X
+	}
+	comp, _ := expr.(*ast.CompositeLit)
+	return comp
+}
+
+// customValue returns the value of a literal expression as described by
+// resource.Resource.Fields, appending referenced identifiers to refs. It
+// returns nil for expressions that are not literals.
+func customValue(expr ast.Expr, refs *[]string) any {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		switch e.Kind {
+		case token.STRING:
+			s, _ := strconv.Unquote(e.Value)
+			return s
+		case token.INT:
+			n, _ := strconv.ParseInt(e.Value, 0, 64)
+			return n
+		case token.FLOAT:
+			f, _ := strconv.ParseFloat(e.Value, 64)
+			return f
+		}
+	case *ast.Ident:
+		switch e.Name {
+		case "true":
+			return true
+		case "false":
+			return false
+		case "nil":
+			return nil
+		}
+		*refs = append(*refs, e.Name)
+		return e.Name
+	case *ast.SelectorExpr:
+		if pkg, ok := e.X.(*ast.Ident); ok {
+			*refs = append(*refs, e.Sel.Name)
+			return pkg.Name + "." + e.Sel.Name
+		}
+	case *ast.UnaryExpr:
+		switch e.Op {
+		case token.AND:
+			return customValue(e.X, refs)
+		case token.SUB:
+			switch v := customValue(e.X, refs).(type) {
+			case int64:
+				return -v
+			case float64:
+				return -v
+			}
+		}
+	case *ast.ParenExpr:
+		return customValue(e.X, refs)
+	case *ast.CompositeLit:
+		if len(e.Elts) > 0 {
+			if _, keyed := e.Elts[0].(*ast.KeyValueExpr); keyed {
+				fields := make(map[string]any)
+				for _, elt := range e.Elts {
+					kv, ok := elt.(*ast.KeyValueExpr)
+					if !ok {
+						continue
+					}
+					key := customKey(kv.Key)
+					if key == "" {
+						continue
+					}
+					if v := customValue(kv.Value, refs); v != nil {
+						fields[key] = v
+					}
+				}
+				return fields
+			}
+		}
+		if _, isMap := e.Type.(*ast.MapType); isMap || e.Type != nil && !isSliceType(e.Type) {
+			return map[string]any{}
+		}
+		values := []any{}
+		for _, elt := range e.Elts {
+			if v := customValue(elt, refs); v != nil {
+				values = append(values, v)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// customKey returns the key of a struct field or map entry.
+func customKey(expr ast.Expr) string {
+	switch k := expr.(type) {
+	case *ast.Ident:
+		return k.Name
+	case *ast.BasicLit:
+		if k.Kind == token.STRING {
+			s, _ := strconv.Unquote(k.Value)
+			return s
+		}
+	}
+	return ""
+}
+
+// isSliceType reports whether a composite literal type is a slice or array.
+func isSliceType(expr ast.Expr) bool {
+	_, ok := expr.(*ast.ArrayType)
+	return ok
+}
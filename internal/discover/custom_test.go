@@ -0,0 +1,88 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const runbookSource = `package ops
+
+import (
+	"example.com/runbook"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+var HighLatency = query.Query{Dataset: "api", TimeRange: query.Hours(1)}
+
+var RestartAPI = runbook.Runbook{
+	Title:    "Restart the API",
+	Owner:    "platform",
+	Priority: 2,
+	Weight:   -0.5,
+	Paged:    true,
+	Alert:    HighLatency,
+	Related:  []string{"deploy", "rollback"},
+	Steps: []runbook.Step{
+		{Command: "kubectl rollout restart deploy/api"},
+	},
+	Labels:   map[string]string{"team": "platform"},
+	Timeout:  time.Minute,
+}
+
+var Shared = &runbook.Runbook{Title: "Shared", Board: dashboards.Overview}
+
+var notExported = runbook.Runbook{Title: "hidden"}
+
+var Other = runbook.Step{Command: "ls"}
+`
+
+func TestDiscoverCustom(t *testing.T) {
+	resource.Register(resource.Kind{Name: "runbook", Type: "runbook.Runbook"})
+	t.Cleanup(func() { resource.Unregister("runbook") })
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "runbooks.go"), []byte(runbookSource), 0644))
+
+	resources, err := DiscoverAll(dir)
+	require.NoError(t, err)
+	require.Len(t, resources.Custom, 2)
+	assert.Equal(t, 3, resources.TotalCount())
+
+	r := resources.Custom[0]
+	assert.Equal(t, "runbook", r.Kind)
+	assert.Equal(t, "RestartAPI", r.Name)
+	assert.Equal(t, "ops", r.Package)
+	assert.Equal(t, 10, r.Line)
+	assert.Equal(t, map[string]any{
+		"Title":    "Restart the API",
+		"Owner":    "platform",
+		"Priority": int64(2),
+		"Weight":   -0.5,
+		"Paged":    true,
+		"Alert":    "HighLatency",
+		"Related":  []any{"deploy", "rollback"},
+		"Steps":    []any{map[string]any{"Command": "kubectl rollout restart deploy/api"}},
+		"Labels":   map[string]any{"team": "platform"},
+		"Timeout":  "time.Minute",
+	}, r.Fields)
+	assert.Equal(t, []string{"HighLatency", "Minute"}, r.Refs)
+
+	shared := resources.Custom[1]
+	assert.Equal(t, "Shared", shared.Name)
+	assert.Equal(t, "dashboards.Overview", shared.Fields["Board"])
+	assert.Equal(t, []string{"Overview"}, shared.Refs)
+}
+
+func TestDiscoverCustom_NoKinds(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "runbooks.go"), []byte(runbookSource), 0644))
+
+	custom, err := DiscoverCustom(dir)
+	require.NoError(t, err)
+	assert.Empty(t, custom)
+}
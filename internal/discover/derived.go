@@ -0,0 +1,168 @@
+package discovery
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DiscoveredDerivedColumn represents a discovered query.DerivedColumn
+// declaration, which calculations refer to by its Alias.
+type DiscoveredDerivedColumn struct {
+	// Name is the identifier of the derived column (variable name)
+	Name string
+
+	// Package is the package name where the derived column is defined
+	Package string
+
+	// File is the absolute path to the file containing the derived column
+	File string
+
+	// Line is the line number where the derived column is defined
+	Line int
+
+	// Alias is the column name queries refer to
+	Alias string
+
+	// Expression is the derived column expression
+	Expression string
+}
+
+// DiscoverDerivedColumns discovers all query.DerivedColumn definitions in the
+// specified directory.
+func DiscoverDerivedColumns(dir string) ([]DiscoveredDerivedColumn, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("path is not a directory: %s", dir)
+	}
+
+	var discovered []DiscoveredDerivedColumn
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		columns, err := extractFile("derived columns", path, discoverDerivedColumnsInFile)
+		if err != nil {
+			return nil
+		}
+
+		discovered = append(discovered, columns...)
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	return discovered, nil
+}
+
+// discoverDerivedColumnsInFile discovers derived columns in a single Go
+// source file.
+func discoverDerivedColumnsInFile(path string) ([]DiscoveredDerivedColumn, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+
+	var discovered []DiscoveredDerivedColumn
+	for _, decl := range node.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			for i, name := range valueSpec.Names {
+				if i >= len(valueSpec.Values) {
+					continue
+				}
+				comp, ok := valueSpec.Values[i].(*ast.CompositeLit)
+				if !ok || !isDerivedColumnType(comp.Type) {
+					continue
+				}
+
+				column := DiscoveredDerivedColumn{
+					Name:    name.Name,
+					Package: node.Name.Name,
+					File:    absPath,
+					Line:    fset.Position(name.Pos()).Line,
+				}
+				if alias := extractFieldValue(comp, "Alias"); alias != nil {
+					column.Alias = extractStringLiteral(alias)
+				}
+				if expr := extractFieldValue(comp, "Expression"); expr != nil {
+					column.Expression = extractStringLiteral(expr)
+				}
+				discovered = append(discovered, column)
+			}
+		}
+	}
+
+	return discovered, nil
+}
+
+// isDerivedColumnType checks if a type expression refers to query.DerivedColumn.
+func isDerivedColumnType(expr ast.Expr) bool {
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if ident, ok := sel.X.(*ast.Ident); ok {
+			return ident.Name == "query" && sel.Sel.Name == "DerivedColumn"
+		}
+	}
+	return false
+}
+
+// resolveDerivedColumns sets the Column of calculations referring to a
+// derived column to its alias. The derived column must be declared in the
+// same package, that is, with the same package name in the same directory.
+func resolveDerivedColumns(queries []DiscoveredQuery, columns []DiscoveredDerivedColumn) {
+	if len(columns) == 0 {
+		return
+	}
+
+	aliases := make(map[string]string)
+	for _, c := range columns {
+		aliases[derivedColumnKey(c.File, c.Package, c.Name)] = c.Alias
+	}
+
+	for i := range queries {
+		q := &queries[i]
+		for j := range q.Calculations {
+			calc := &q.Calculations[j]
+			if calc.ColumnRef == "" {
+				continue
+			}
+			if alias, ok := aliases[derivedColumnKey(q.File, q.Package, calc.ColumnRef)]; ok {
+				calc.Column = alias
+			}
+		}
+	}
+}
+
+// derivedColumnKey identifies a variable by package directory, package name
+// and name.
+func derivedColumnKey(file, pkg, name string) string {
+	return filepath.Dir(file) + "\x00" + pkg + "\x00" + name
+}
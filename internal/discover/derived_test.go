@@ -0,0 +1,102 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverDerivedColumns(t *testing.T) {
+	dir := t.TempDir()
+
+	columns := `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var ErrorRateColumn = query.DerivedColumn{
+	Alias:      "error_rate",
+	Expression: "IF(EQUALS($error, true), 100, 0)",
+}
+
+var slowColumn = query.DerivedColumn{Alias: "slow", Expression: "IF(GT($duration_ms, 500), 1, 0)"}
+`
+	queries := `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var ErrorRate = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Calculations: []query.Calculation{query.Avg(ErrorRateColumn.Alias), query.Count()},
+}
+
+var SlowShare = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Calculations: []query.Calculation{{Op: "SUM", Column: slowColumn.Alias}},
+}
+
+var Missing = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Calculations: []query.Calculation{query.Avg(LatencyColumn.Alias)},
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "columns.go"), []byte(columns), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "queries.go"), []byte(queries), 0644))
+
+	derived, err := DiscoverDerivedColumns(dir)
+	require.NoError(t, err)
+	require.Len(t, derived, 2)
+	assert.Equal(t, "ErrorRateColumn", derived[0].Name)
+	assert.Equal(t, "queries", derived[0].Package)
+	assert.Equal(t, 5, derived[0].Line)
+	assert.Equal(t, "error_rate", derived[0].Alias)
+	assert.Equal(t, "IF(EQUALS($error, true), 100, 0)", derived[0].Expression)
+	assert.Equal(t, "slow", derived[1].Alias)
+
+	resources, err := DiscoverAll(dir)
+	require.NoError(t, err)
+	assert.Len(t, resources.DerivedColumns, 2)
+
+	byName := make(map[string]DiscoveredQuery)
+	for _, q := range resources.Queries {
+		byName[q.Name] = q
+	}
+	assert.Equal(t, []Calculation{
+		{Op: "AVG", Column: "error_rate", ColumnRef: "ErrorRateColumn"},
+		{Op: "COUNT"},
+	}, byName["ErrorRate"].Calculations)
+	assert.Equal(t, []Calculation{{Op: "SUM", Column: "slow", ColumnRef: "slowColumn"}}, byName["SlowShare"].Calculations)
+	assert.Equal(t, []Calculation{{Op: "AVG", ColumnRef: "LatencyColumn"}}, byName["Missing"].Calculations)
+}
+
+func TestDiscoverQueries_DerivedColumnInOtherPackage(t *testing.T) {
+	dir := t.TempDir()
+	other := filepath.Join(dir, "columns")
+	require.NoError(t, os.MkdirAll(other, 0755))
+
+	require.NoError(t, os.WriteFile(filepath.Join(other, "columns.go"), []byte(`package columns
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var ErrorRateColumn = query.DerivedColumn{Alias: "error_rate"}
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "queries.go"), []byte(`package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var ErrorRate = query.Query{
+	Dataset:      "api",
+	Calculations: []query.Calculation{query.Avg(ErrorRateColumn.Alias)},
+}
+`), 0644))
+
+	queries, err := DiscoverQueries(dir)
+	require.NoError(t, err)
+	require.Len(t, queries, 1)
+	assert.Empty(t, queries[0].Calculations[0].Column, "only derived columns of the same package are resolved")
+}
@@ -8,6 +8,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/resource"
 )
 
 // DiscoveredQuery represents a discovered query definition with metadata.
@@ -51,8 +54,65 @@ type DiscoveredQuery struct {
 	// Limit restricts the number of results
 	Limit int
 
+	// Top is the query.TopN selection of the query, nil when it has none.
+	// Its calculation, order and limit are applied to Calculations, Orders
+	// and Limit as query.Query.ExpandTop does
+	Top *Top
+
 	// Style contains metadata for style linting
 	Style StyleMetadata
+
+	// Doc is the Go doc comment of the declaration
+	Doc string
+}
+
+// Query returns the query.Query the discovered query declares, for the code
+// that builds, evaluates or prints it.
+func (dq DiscoveredQuery) Query() query.Query {
+	q := query.Query{
+		Dataset: dq.Dataset,
+		TimeRange: query.TimeRange{
+			TimeRange: dq.TimeRange.TimeRange,
+			StartTime: dq.TimeRange.StartTime,
+			EndTime:   dq.TimeRange.EndTime,
+		},
+		Breakdowns:        dq.Breakdowns,
+		FilterCombination: dq.FilterCombination,
+		Limit:             dq.Limit,
+		Granularity:       dq.Granularity,
+	}
+
+	for _, c := range dq.Calculations {
+		q.Calculations = append(q.Calculations, query.Calculation{
+			Op:     c.Op,
+			Column: c.Column,
+		})
+	}
+
+	for _, f := range dq.Filters {
+		q.Filters = append(q.Filters, query.Filter{
+			Column: f.Column,
+			Op:     f.Op,
+			Value:  f.Value,
+		})
+	}
+
+	for _, o := range dq.Orders {
+		q.Orders = append(q.Orders, query.Order{
+			Column: o.Column,
+			Op:     o.Op,
+			Order:  o.Order,
+		})
+	}
+
+	if dq.Top != nil {
+		q.Top = query.TopN(dq.Top.N, query.Calculation{
+			Op:     dq.Top.Calculation.Op,
+			Column: dq.Top.Calculation.Column,
+		})
+	}
+
+	return q
 }
 
 // TimeRange represents a time window for a query.
@@ -66,6 +126,12 @@ type TimeRange struct {
 
 	// EndTime is absolute end time in Unix epoch seconds
 	EndTime int
+
+	// Zone is the IANA time zone of a query.AbsoluteIn range
+	Zone string
+
+	// Err describes why a query.AbsoluteIn range could not be converted
+	Err string
 }
 
 // Calculation represents an aggregation to compute.
@@ -78,6 +144,12 @@ type Calculation struct {
 
 	// Alias is an optional name for the calculation result
 	Alias string
+
+	// ColumnRef is the Go variable of the query.DerivedColumn whose Alias
+	// the calculation's column is, e.g. ErrorRateColumn for
+	// query.Avg(ErrorRateColumn.Alias). Discovery sets Column to the alias
+	// when the derived column is declared in the same package.
+	ColumnRef string
 }
 
 // Filter represents a condition to filter data.
@@ -88,8 +160,13 @@ type Filter struct {
 	// Op is the filter operator (e.g., "=", ">", "<")
 	Op string
 
-	// Value is the value to compare against
+	// Value is the value to compare against: a string, int, float64, bool or
+	// []interface{} of those. It is nil when no value is given or the value is
+	// not a literal (see HasValue).
 	Value interface{}
+
+	// HasValue reports whether a value was given, literal or not
+	HasValue bool
 }
 
 // Order represents a sort specification for query results.
@@ -104,6 +181,16 @@ type Order struct {
 	Order string
 }
 
+// Top represents a query.TopN selection: the N groups with the highest value
+// of a calculation.
+type Top struct {
+	// N is the number of groups to keep
+	N int
+
+	// Calculation ranks the groups, in descending order
+	Calculation Calculation
+}
+
 // StyleMetadata contains metadata for style linting.
 type StyleMetadata struct {
 	// InlineCalculationCount is the number of calculations defined inline
@@ -123,6 +210,8 @@ type StyleMetadata struct {
 
 // DiscoverQueries discovers all Query definitions in the specified directory.
 // It parses Go source files and extracts query metadata using AST analysis.
+// Calculations on the Alias of a query.DerivedColumn declared in the same
+// package are resolved to the alias.
 func DiscoverQueries(dir string) ([]DiscoveredQuery, error) {
 	// Check if directory exists
 	info, err := os.Stat(dir)
@@ -147,7 +236,7 @@ func DiscoverQueries(dir string) ([]DiscoveredQuery, error) {
 		}
 
 		// Parse the file
-		queries, err := discoverQueriesInFile(path)
+		queries, err := extractFile("queries", path, discoverQueriesInFile)
 		if err != nil {
 			// Log but don't fail on individual file errors
 			return nil
@@ -161,6 +250,13 @@ func DiscoverQueries(dir string) ([]DiscoveredQuery, error) {
 		return nil, fmt.Errorf("failed to walk directory: %w", err)
 	}
 
+	// Resolve calculations on derived columns declared next to the queries
+	columns, err := DiscoverDerivedColumns(dir)
+	if err != nil {
+		return nil, err
+	}
+	resolveDerivedColumns(discovered, columns)
+
 	return discovered, nil
 }
 
@@ -191,6 +287,9 @@ func discoverQueriesInFile(path string) ([]DiscoveredQuery, error) {
 				for _, spec := range decl.Specs {
 					if valueSpec, ok := spec.(*ast.ValueSpec); ok {
 						queries := extractQueriesFromValueSpec(valueSpec, fset, absPath, packageName)
+						for i := range queries {
+							queries[i].Doc = declDoc(decl, valueSpec)
+						}
 						discovered = append(discovered, queries...)
 					}
 				}
@@ -200,6 +299,9 @@ func discoverQueriesInFile(path string) ([]DiscoveredQuery, error) {
 			// Handle function-scoped queries
 			if decl.Body != nil {
 				queries := extractQueriesFromFunction(decl, fset, absPath, packageName)
+				for i := range queries {
+					queries[i].Doc = docText(decl.Doc)
+				}
 				discovered = append(discovered, queries...)
 			}
 		}
@@ -207,6 +309,14 @@ func discoverQueriesInFile(path string) ([]DiscoveredQuery, error) {
 		return true
 	})
 
+	// Loops of init functions over a service list are unrolled
+	for _, e := range initLoopExpansions(node, fset, absPath) {
+		for _, q := range extractQueriesFromValueSpec(e.spec, e.fset, absPath, packageName) {
+			q.Line = e.line
+			discovered = append(discovered, q)
+		}
+	}
+
 	return discovered, nil
 }
 
@@ -219,6 +329,17 @@ func extractQueriesFromValueSpec(spec *ast.ValueSpec, fset *token.FileSet, file
 		return discovered
 	}
 
+	// A fleet.Each template declares a resource per service
+	if expansions, ok, _ := expandFleet(spec, fset, file); ok {
+		for _, e := range expansions {
+			for _, q := range extractQueriesFromValueSpec(e.spec, e.fset, file, pkg) {
+				q.Line = e.line
+				discovered = append(discovered, q)
+			}
+		}
+		return discovered
+	}
+
 	// Check each value in the spec
 	for _, value := range spec.Values {
 		// Find all query composites in this value
@@ -311,8 +432,12 @@ func extractQueryFromComposite(comp *ast.CompositeLit, fset *token.FileSet, file
 
 		case "Limit":
 			query.Limit = extractIntLiteral(kv.Value)
+
+		case "Top":
+			query.Top = extractTop(kv.Value)
 		}
 	}
+	applyTop(&query)
 
 	// Extract style metadata for linting
 	query.Style = extractStyleMetadata(comp)
@@ -379,15 +504,84 @@ type DiscoveredResources struct {
 
 	// Boards are discovered board definitions
 	Boards []DiscoveredBoard
+
+	// Schemas are discovered dataset schemas. They describe datasets rather
+	// than Honeycomb resources and are not included in TotalCount.
+	Schemas []DiscoveredSchema
+
+	// Mutes are discovered maintenance windows. They are applied by
+	// disabling the triggers they select and are not included in TotalCount.
+	Mutes []DiscoveredMute
+
+	// Custom are the discovered resources of kinds registered with
+	// resource.Register
+	Custom []resource.Resource
+
+	// DerivedColumns are the query.DerivedColumn declarations calculations
+	// can refer to
+	DerivedColumns []DiscoveredDerivedColumn
+
+	// Unsupported are the resource literals discovery cannot extract, such
+	// as those built in init functions, which build leaves out
+	Unsupported []UnsupportedDeclaration
+
+	// FieldMistakes are the fields of resource literals discovery cannot
+	// read, such as misspelled keys, which build rejects
+	FieldMistakes []FieldMistake
+}
+
+// Schema returns the schema declared for a dataset. When several packages
+// declare the same dataset their columns are merged, the first declaration
+// of a column, retention or ingest volume winning.
+func (r *DiscoveredResources) Schema(dataset string) (DiscoveredSchema, bool) {
+	var merged DiscoveredSchema
+	found := false
+	for _, s := range r.Schemas {
+		if s.Dataset != dataset {
+			continue
+		}
+		if !found {
+			merged = s
+			merged.Columns = append([]DiscoveredColumn(nil), s.Columns...)
+			found = true
+			continue
+		}
+		for _, c := range s.Columns {
+			if _, ok := merged.Column(c.Name); !ok {
+				merged.Columns = append(merged.Columns, c)
+			}
+		}
+		if merged.RetentionDays == 0 {
+			merged.RetentionDays = s.RetentionDays
+		}
+		if merged.EventsPerDay == 0 {
+			merged.EventsPerDay = s.EventsPerDay
+		}
+	}
+	return merged, found
 }
 
 // TotalCount returns the total number of discovered resources.
 func (r *DiscoveredResources) TotalCount() int {
-	return len(r.Queries) + len(r.SLOs) + len(r.Triggers) + len(r.Boards)
+	return len(r.Queries) + len(r.SLOs) + len(r.Triggers) + len(r.Boards) + len(r.Custom)
 }
 
-// DiscoverAll discovers all resource types in the specified directory.
+// DiscoverAll discovers all resource types in the specified directory and
+// applies the defaults of the nearest ConfigFile (see LoadConfig). Progress
+// is reported to the Tracker of SetProgress, and the resources found to the
+// function of SetObserver.
 func DiscoverAll(dir string) (*DiscoveredResources, error) {
+	defer startProgress([]string{dir})()
+	resources, err := discoverAll(dir)
+	if err != nil {
+		return nil, err
+	}
+	observe(resources)
+	return resources, nil
+}
+
+// discoverAll is DiscoverAll without progress reporting.
+func discoverAll(dir string) (*DiscoveredResources, error) {
 	resources := &DiscoveredResources{}
 
 	queries, err := DiscoverQueries(dir)
@@ -414,5 +608,47 @@ func DiscoverAll(dir string) (*DiscoveredResources, error) {
 	}
 	resources.Boards = boards
 
+	schemas, err := DiscoverSchemas(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover schemas: %w", err)
+	}
+	resources.Schemas = schemas
+
+	mutes, err := DiscoverMutes(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover maintenance windows: %w", err)
+	}
+	resources.Mutes = mutes
+
+	custom, err := DiscoverCustom(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover custom resources: %w", err)
+	}
+	resources.Custom = custom
+
+	derived, err := DiscoverDerivedColumns(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover derived columns: %w", err)
+	}
+	resources.DerivedColumns = derived
+
+	unsupported, err := DiscoverUnsupported(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover unsupported declarations: %w", err)
+	}
+	resources.Unsupported = unsupported
+
+	mistakes, err := DiscoverFieldMistakes(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover field mistakes: %w", err)
+	}
+	resources.FieldMistakes = mistakes
+
+	config, err := LoadConfig(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	config.Apply(resources)
+
 	return resources, nil
 }
@@ -3,7 +3,10 @@ package discovery
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/query"
 )
 
 func TestDiscoverQueries_SimplePackageLevel(t *testing.T) {
@@ -222,6 +225,38 @@ func TestDiscoverQueries_AdvancedFeatures(t *testing.T) {
 	}
 }
 
+func TestDiscoveredQuery_Query(t *testing.T) {
+	dq := DiscoveredQuery{
+		Name:              "Errors",
+		Dataset:           "api",
+		TimeRange:         TimeRange{TimeRange: 7200},
+		Breakdowns:        []string{"service"},
+		Calculations:      []Calculation{{Op: "COUNT"}, {Op: "P99", Column: "duration_ms"}},
+		Filters:           []Filter{{Column: "a", Op: "=", Value: 1, HasValue: true}, {Column: "b", Op: "exists"}},
+		FilterCombination: "OR",
+		Orders:            []Order{{Op: "COUNT", Order: "descending"}},
+		Granularity:       60,
+		Limit:             10,
+		Top:               &Top{N: 10, Calculation: Calculation{Op: "COUNT"}},
+	}
+
+	want := query.Query{
+		Dataset:           "api",
+		TimeRange:         query.Hours(2),
+		Breakdowns:        []string{"service"},
+		Calculations:      []query.Calculation{query.Count(), query.P99("duration_ms")},
+		Filters:           []query.Filter{query.Equals("a", 1), query.Exists("b")},
+		FilterCombination: "OR",
+		Orders:            []query.Order{{Op: "COUNT", Order: "descending"}},
+		Granularity:       60,
+		Limit:             10,
+		Top:               query.TopN(10, query.Count()),
+	}
+	if got := dq.Query(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Query() = %+v, want %+v", got, want)
+	}
+}
+
 func TestGroupByDataset(t *testing.T) {
 	// Test grouping queries by dataset
 	testDir := filepath.Join(getRepoRoot(t), "testdata", "queries")
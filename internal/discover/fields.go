@@ -0,0 +1,463 @@
+package discovery
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/mute"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/slo"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+// FieldMistake is a field of a resource literal that discovery cannot read:
+// a key the type does not have, such as Breakdown for Breakdowns, or a
+// literal of the wrong kind, such as a string TimeRange. The Go compiler
+// rejects both, but discovery reads the source without type checking, so
+// the field would otherwise be silently left out of the build output. Build
+// fails on them; lint reports them (WHC069).
+type FieldMistake struct {
+	// Type is the type of the literal, e.g. "query.Query"
+	Type string
+
+	// Field is the key of the field
+	Field string
+
+	// Name is the variable or function the literal is declared in
+	Name string
+
+	// Package is the package name where the literal is written
+	Package string
+
+	// File is the absolute path to the file containing the literal
+	File string
+
+	// Line is the line number of the field
+	Line int
+
+	// Problem says what is wrong, e.g. "has no field Breakdown"
+	Problem string
+
+	// Suggestion is the field or value meant, e.g. "Breakdowns" or
+	// "query.Hours(1)", or "" when there is no likely one
+	Suggestion string
+}
+
+// String describes the mistake and the suggestion, e.g. "Latency:
+// query.Query has no field Breakdown; did you mean Breakdowns?".
+func (m FieldMistake) String() string {
+	msg := m.Type + " " + m.Problem
+	if m.Name != "" {
+		msg = m.Name + ": " + msg
+	}
+	if m.Suggestion != "" {
+		msg += "; did you mean " + m.Suggestion + "?"
+	}
+	return msg
+}
+
+// resourceTypes are the struct types whose literals DiscoverFieldMistakes
+// checks, keyed by their name in source, e.g. "query.Query".
+var resourceTypes = func() map[string]reflect.Type {
+	types := make(map[string]reflect.Type)
+	for _, v := range []any{
+		query.Query{}, query.Calculation{}, query.Filter{}, query.Order{}, query.DerivedColumn{},
+		query.Ratio{}, query.TimeRange{}, query.Top{},
+		trigger.Trigger{}, trigger.Threshold{}, trigger.Frequency{}, trigger.Recipient{}, trigger.Tag{},
+		trigger.Escalation{}, trigger.Level{},
+		slo.SLO{}, slo.SLI{}, slo.Target{}, slo.TimePeriod{}, slo.BurnAlert{}, slo.Recipient{},
+		board.Board{}, board.Filter{}, board.Tag{}, board.GraphSettings{}, board.Position{}, board.PanelConfig{},
+		mute.Window{},
+	} {
+		t := reflect.TypeOf(v)
+		types[t.String()] = t
+	}
+	return types
+}()
+
+// fieldAliases are field names from other query languages, mapped to the
+// field meant.
+var fieldAliases = map[string]string{
+	"GroupBy":      "Breakdowns",
+	"Where":        "Filters",
+	"OrderBy":      "Orders",
+	"Aggregations": "Calculations",
+}
+
+// DiscoverFieldMistakes finds the fields of resource literals in the
+// specified directory that discovery cannot read (see FieldMistake).
+func DiscoverFieldMistakes(dir string) ([]FieldMistake, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("path is not a directory: %s", dir)
+	}
+
+	var discovered []FieldMistake
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		mistakes, err := extractFile("field mistakes", path, discoverFieldMistakesInFile)
+		if err != nil {
+			return nil
+		}
+
+		discovered = append(discovered, mistakes...)
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	return discovered, nil
+}
+
+// discoverFieldMistakesInFile finds the field mistakes of the resource
+// literals in a single Go source file.
+func discoverFieldMistakesInFile(path string) ([]FieldMistake, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+	pkg := node.Name.Name
+
+	var found []FieldMistake
+	check := func(root ast.Node, name string) {
+		ast.Inspect(root, func(n ast.Node) bool {
+			comp, ok := n.(*ast.CompositeLit)
+			if !ok {
+				return true
+			}
+			for _, m := range compositeMistakes(comp) {
+				m.Name, m.Package, m.File, m.Line = name, pkg, absPath, fset.Position(m.pos).Line
+				found = append(found, m.FieldMistake)
+			}
+			return true
+		})
+	}
+	for _, decl := range node.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				if valueSpec, ok := spec.(*ast.ValueSpec); ok {
+					check(valueSpec, getIdentifierName(valueSpec))
+				}
+			}
+
+		case *ast.FuncDecl:
+			if decl.Body != nil {
+				check(decl.Body, getFunctionName(decl))
+			}
+		}
+	}
+	// Nested literals are visited after the fields around them
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Line < found[j].Line })
+	return found, nil
+}
+
+// positionedMistake is a FieldMistake with the position of its field.
+type positionedMistake struct {
+	FieldMistake
+	pos token.Pos
+}
+
+// compositeMistakes returns the field mistakes of a literal of a resource
+// type, or of the elements of a slice literal of one written without their
+// type.
+func compositeMistakes(comp *ast.CompositeLit) []positionedMistake {
+	switch t := comp.Type.(type) {
+	case *ast.SelectorExpr:
+		if typ, ok := resourceTypes[selectorTypeName(t)]; ok {
+			return fieldMistakes(typ, comp)
+		}
+
+	case *ast.ArrayType:
+		elt := t.Elt
+		if star, ok := elt.(*ast.StarExpr); ok {
+			elt = star.X
+		}
+		sel, ok := elt.(*ast.SelectorExpr)
+		if !ok {
+			return nil
+		}
+		typ, ok := resourceTypes[selectorTypeName(sel)]
+		if !ok {
+			return nil
+		}
+		var mistakes []positionedMistake
+		for _, e := range comp.Elts {
+			if inner, ok := e.(*ast.CompositeLit); ok && inner.Type == nil {
+				mistakes = append(mistakes, fieldMistakes(typ, inner)...)
+			}
+		}
+		return mistakes
+	}
+	return nil
+}
+
+// selectorTypeName returns the name of a type written pkg.Type, or "".
+func selectorTypeName(sel *ast.SelectorExpr) string {
+	if ident, ok := sel.X.(*ast.Ident); ok {
+		return ident.Name + "." + sel.Sel.Name
+	}
+	return ""
+}
+
+// fieldMistakes checks the keyed fields of a literal of typ.
+func fieldMistakes(typ reflect.Type, comp *ast.CompositeLit) []positionedMistake {
+	var mistakes []positionedMistake
+	for _, e := range comp.Elts {
+		kv, ok := e.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		m := positionedMistake{FieldMistake: FieldMistake{Type: typ.String(), Field: key.Name}, pos: kv.Pos()}
+		if field, ok := typ.FieldByName(key.Name); ok && field.IsExported() {
+			if problem, suggestion := valueMistake(field, kv.Value); problem != "" {
+				m.Problem, m.Suggestion = problem, suggestion
+				mistakes = append(mistakes, m)
+			}
+			continue
+		}
+		m.Problem = "has no field " + key.Name
+		m.Suggestion = suggestField(typ, key.Name)
+		mistakes = append(mistakes, m)
+	}
+	return mistakes
+}
+
+// suggestField returns the field of typ most likely meant by name: the
+// field an alias from another query language stands for, one differing only
+// in case or by at most two edits, or one name ends or starts with, such as
+// TimePeriod for Period. It returns "" when there is none.
+func suggestField(typ reflect.Type, name string) string {
+	var fields []string
+	for i := 0; i < typ.NumField(); i++ {
+		if f := typ.Field(i); f.IsExported() {
+			fields = append(fields, f.Name)
+		}
+	}
+	if alias, ok := fieldAliases[name]; ok {
+		if _, ok := typ.FieldByName(alias); ok {
+			return alias
+		}
+	}
+
+	lower := strings.ToLower(name)
+	best, bestDistance := "", 3
+	for _, f := range fields {
+		d := editDistance(lower, strings.ToLower(f))
+		if d < bestDistance && d < len(name)/2+1 {
+			best, bestDistance = f, d
+		}
+	}
+	if best != "" {
+		return best
+	}
+	if len(name) >= 4 {
+		for _, f := range fields {
+			lf := strings.ToLower(f)
+			if strings.HasSuffix(lf, lower) || strings.HasPrefix(lf, lower) {
+				return f
+			}
+		}
+	}
+	return ""
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// valueMistake checks the value of a field against its type. Only literals
+// are checked: a string or number where a struct, a slice or another kind of
+// basic value is expected, or a single element where a slice is. It returns
+// the problem and the value likely meant.
+func valueMistake(field reflect.StructField, value ast.Expr) (problem, suggestion string) {
+	typ := field.Type
+	if comp, ok := value.(*ast.CompositeLit); ok {
+		sel, ok := comp.Type.(*ast.SelectorExpr)
+		if ok && typ.Kind() == reflect.Slice && typ.Elem().String() == selectorTypeName(sel) {
+			return fmt.Sprintf("field %s is a %s, not a single %s", field.Name, typ, typ.Elem()),
+				fmt.Sprintf("%s{{...}}", typ)
+		}
+		return "", ""
+	}
+
+	lit, ok := value.(*ast.BasicLit)
+	if !ok {
+		return "", ""
+	}
+	kind := "a number"
+	if lit.Kind == token.STRING {
+		kind = "a string"
+	}
+	want := "a " + typ.String()
+	switch typ.Kind() {
+	case reflect.Struct, reflect.Slice:
+	case reflect.Int, reflect.Int64:
+		want = "an integer"
+	case reflect.Float64:
+		want = "a number"
+	default:
+		want = "a " + typ.Kind().String()
+	}
+	problem = fmt.Sprintf("field %s is %s, not %s", field.Name, want, kind)
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		return problem, structSuggestion(typ, lit)
+
+	case reflect.Slice:
+		if lit.Kind == token.STRING && typ.Elem().Kind() == reflect.String {
+			return problem, fmt.Sprintf("%s{%s}", typ, lit.Value)
+		}
+		return problem, ""
+
+	case reflect.String:
+		if lit.Kind != token.STRING {
+			return problem, strconv.Quote(lit.Value)
+		}
+
+	case reflect.Int, reflect.Int64, reflect.Float64:
+		if lit.Kind == token.STRING {
+			s, _ := strconv.Unquote(lit.Value)
+			if _, err := strconv.ParseFloat(s, 64); err == nil {
+				return problem, s
+			}
+			return problem, ""
+		}
+		if lit.Kind == token.FLOAT && typ.Kind() != reflect.Float64 {
+			return problem, ""
+		}
+
+	case reflect.Bool:
+		s, _ := strconv.Unquote(lit.Value)
+		if b, err := strconv.ParseBool(s); err == nil {
+			return problem, strconv.FormatBool(b)
+		}
+		return problem, ""
+	}
+	return "", ""
+}
+
+// structSuggestion returns the constructor call likely meant by a literal
+// given for a struct field: query.Hours(2) for "2h" or 7200 as a
+// query.TimeRange, trigger.Minutes(5) for "5m" or 300 as a
+// trigger.Frequency, slo.Days(30) for "30d" or 30 as an slo.TimePeriod and
+// slo.Percentage(99.9) for 99.9 or "99.9%" as an slo.Target. It returns ""
+// for other types and literals.
+func structSuggestion(typ reflect.Type, lit *ast.BasicLit) string {
+	text := lit.Value
+	if lit.Kind == token.STRING {
+		text, _ = strconv.Unquote(lit.Value)
+	}
+
+	switch typ {
+	case reflect.TypeOf(slo.Target{}):
+		p, err := strconv.ParseFloat(strings.TrimSuffix(text, "%"), 64)
+		if err != nil {
+			return ""
+		}
+		return fmt.Sprintf("slo.Percentage(%s)", strconv.FormatFloat(p, 'f', -1, 64))
+
+	case reflect.TypeOf(slo.TimePeriod{}):
+		if lit.Kind == token.INT {
+			return "slo.Days(" + text + ")"
+		}
+		seconds, ok := literalSeconds(text)
+		if !ok {
+			return ""
+		}
+		if seconds%86400 == 0 {
+			return fmt.Sprintf("slo.Days(%d)", seconds/86400)
+		}
+		if seconds%3600 == 0 {
+			return fmt.Sprintf("slo.TimePeriod{Hours: %d}", seconds/3600)
+		}
+
+	case reflect.TypeOf(query.TimeRange{}), reflect.TypeOf(trigger.Frequency{}):
+		seconds, ok := literalSeconds(text)
+		if lit.Kind == token.INT {
+			n, err := strconv.Atoi(text)
+			seconds, ok = n, err == nil
+		}
+		if !ok || seconds <= 0 {
+			return ""
+		}
+		pkg, units := "query", []struct {
+			size int
+			name string
+		}{{86400, "Days"}, {3600, "Hours"}, {60, "Minutes"}, {1, "Seconds"}}
+		if typ == reflect.TypeOf(trigger.Frequency{}) {
+			pkg, units = "trigger", units[2:]
+		}
+		for _, u := range units {
+			if seconds%u.size == 0 {
+				return fmt.Sprintf("%s.%s(%d)", pkg, u.name, seconds/u.size)
+			}
+		}
+	}
+	return ""
+}
+
+// literalSeconds parses a duration written as a string, such as "2h",
+// "90s" or "7d", in seconds.
+func literalSeconds(s string) (int, bool) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		return n * 86400, err == nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d%time.Second != 0 {
+		return 0, false
+	}
+	return int(d / time.Second), true
+}
@@ -0,0 +1,97 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverFieldMistakes(t *testing.T) {
+	dir := t.TempDir()
+
+	content := `package obs
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/slo"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var Latency = query.Query{
+	Dataset:      "api",
+	TimeRange:    "2h",
+	Breakdown:    []string{"endpoint"},
+	Calculations: []query.Calculation{{Op: "P99", Colum: "duration_ms"}},
+	Where:        []query.Filter{query.GT("duration_ms", 500)},
+	Orders:       query.Order{Op: "P99"},
+	Limit:        "10",
+	Unknown:      true,
+}
+
+func Slow() trigger.Trigger {
+	return trigger.Trigger{
+		Name:      "Slow",
+		Frequency: 300,
+		Disabled:  "false",
+	}
+}
+
+var Availability = slo.SLO{
+	Name:   "Availability",
+	Target: "99.9%",
+	Period: slo.Days(30),
+}
+
+var Weekly = slo.SLO{TimePeriod: "7d"}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "obs.go"), []byte(content), 0644))
+
+	mistakes, err := DiscoverFieldMistakes(dir)
+	require.NoError(t, err)
+
+	var messages []string
+	for _, m := range mistakes {
+		messages = append(messages, m.String())
+	}
+	assert.Equal(t, []string{
+		`Latency: query.Query field TimeRange is a query.TimeRange, not a string; did you mean query.Hours(2)?`,
+		`Latency: query.Query has no field Breakdown; did you mean Breakdowns?`,
+		`Latency: query.Calculation has no field Colum; did you mean Column?`,
+		`Latency: query.Query has no field Where; did you mean Filters?`,
+		`Latency: query.Query field Orders is a []query.Order, not a single query.Order; did you mean []query.Order{{...}}?`,
+		`Latency: query.Query field Limit is an integer, not a string; did you mean 10?`,
+		`Latency: query.Query has no field Unknown`,
+		`Slow: trigger.Trigger field Frequency is a trigger.Frequency, not a number; did you mean trigger.Minutes(5)?`,
+		`Slow: trigger.Trigger field Disabled is a bool, not a string; did you mean false?`,
+		`Availability: slo.SLO field Target is a slo.Target, not a string; did you mean slo.Percentage(99.9)?`,
+		`Availability: slo.SLO has no field Period; did you mean TimePeriod?`,
+		`Weekly: slo.SLO field TimePeriod is a slo.TimePeriod, not a string; did you mean slo.Days(7)?`,
+	}, messages)
+
+	assert.Equal(t, "obs", mistakes[0].Package)
+	assert.Equal(t, 11, mistakes[0].Line)
+	assert.Equal(t, "Breakdown", mistakes[1].Field)
+	assert.Equal(t, "Breakdowns", mistakes[1].Suggestion)
+}
+
+func TestDiscoverAll_FieldMistakes(t *testing.T) {
+	dir := t.TempDir()
+
+	content := `package obs
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Latency = query.Query{Dataset: "api", TimeRange: query.Hours(1), Filter: []query.Filter{}}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "obs.go"), []byte(content), 0644))
+
+	resources, err := DiscoverAll(dir)
+	require.NoError(t, err)
+
+	require.Len(t, resources.Queries, 1)
+	require.Len(t, resources.FieldMistakes, 1)
+	assert.Equal(t, "Filters", resources.FieldMistakes[0].Suggestion)
+}
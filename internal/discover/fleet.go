@@ -0,0 +1,231 @@
+package discovery
+
+import (
+	"bytes"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/scanner"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/fleet"
+)
+
+// fleetExpansion is the resource of one service of a fleet.Each template, or
+// of an unrolled init loop: a declaration of the literal with the service
+// substituted for the template parameter, named as fleet.Name names it.
+type fleetExpansion struct {
+	// spec declares the literal; its positions belong to fset
+	spec *ast.ValueSpec
+	fset *token.FileSet
+
+	// line is the line of the fleet.Each call, or loop assignment, in the
+	// declaring file
+	line int
+}
+
+// expandFleet returns the expansions of a declaration such as
+//
+//	var Latency = fleet.Each(Services, func(service string) T { return T{...} })
+//
+// and whether spec declares a fleet.Each template at all. A template that
+// cannot be expanded statically (see package fleet) has no expansions, so
+// that its literal is not discovered with the parameter unresolved, and an
+// error saying why.
+func expandFleet(spec *ast.ValueSpec, fset *token.FileSet, file string) ([]fleetExpansion, bool, error) {
+	name := getIdentifierName(spec)
+	if len(spec.Values) != 1 {
+		return nil, false, nil
+	}
+	call, ok := spec.Values[0].(*ast.CallExpr)
+	if !ok || !isFleetEach(call.Fun) {
+		return nil, false, nil
+	}
+	if len(call.Args) != 2 {
+		return nil, true, errors.New("fleet.Each takes a service list and a function")
+	}
+
+	services, ok := fleetServices(call.Args[0], file)
+	if !ok {
+		return nil, true, errors.New("the service list is not a []string literal or a package-level variable holding one")
+	}
+	param, result, ok := fleetTemplate(call.Args[1])
+	if !ok {
+		return nil, true, errors.New("the function must have one parameter and consist of a single return statement")
+	}
+	return expandTemplate(name, services, param, result, fset, file, fset.Position(call.Pos()).Line), true, nil
+}
+
+// expandTemplate returns an expansion of the expression result for each
+// service, with the service substituted for param and named after it and
+// name, located at line.
+func expandTemplate(name string, services []string, param string, result ast.Expr, fset *token.FileSet, file string, line int) []fleetExpansion {
+	var src bytes.Buffer
+	if err := printer.Fprint(&src, fset, result); err != nil {
+		return nil
+	}
+
+	var expansions []fleetExpansion
+	for _, service := range services {
+		expanded := token.NewFileSet()
+		expr, err := parser.ParseExprFrom(expanded, file, substituteParam(src.Bytes(), param, service), 0)
+		if err != nil {
+			continue
+		}
+		expansions = append(expansions, fleetExpansion{
+			spec: &ast.ValueSpec{
+				Names:  []*ast.Ident{ast.NewIdent(fleet.Name(service, name))},
+				Values: []ast.Expr{expr},
+			},
+			fset: expanded,
+			line: line,
+		})
+	}
+	return expansions
+}
+
+// isFleetEach reports whether a call's function is fleet.Each, with or
+// without explicit type arguments.
+func isFleetEach(fun ast.Expr) bool {
+	if index, ok := fun.(*ast.IndexExpr); ok {
+		fun = index.X
+	}
+	sel, ok := fun.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := sel.X.(*ast.Ident)
+	return ok && ident.Name == "fleet" && sel.Sel.Name == "Each"
+}
+
+// fleetTemplate returns the parameter name and returned expression of a
+// fleet.Each template function: a function literal with one parameter
+// whose body is a single return statement.
+func fleetTemplate(expr ast.Expr) (string, ast.Expr, bool) {
+	fn, ok := expr.(*ast.FuncLit)
+	if !ok || len(fn.Type.Params.List) != 1 || len(fn.Type.Params.List[0].Names) != 1 {
+		return "", nil, false
+	}
+	if len(fn.Body.List) != 1 {
+		return "", nil, false
+	}
+	ret, ok := fn.Body.List[0].(*ast.ReturnStmt)
+	if !ok || len(ret.Results) != 1 {
+		return "", nil, false
+	}
+	return fn.Type.Params.List[0].Names[0].Name, ret.Results[0], true
+}
+
+// fleetServices returns the services of a fleet.Each call: a []string
+// literal, or the name of a package-level variable holding one, declared in
+// any file of the directory of file.
+func fleetServices(expr ast.Expr, file string) ([]string, bool) {
+	switch e := expr.(type) {
+	case *ast.CompositeLit:
+		return extractStringSlice(e), true
+	case *ast.Ident:
+		return lookupStringSlice(filepath.Dir(file), e.Name)
+	}
+	return nil, false
+}
+
+// lookupStringSlice returns the value of the package-level []string
+// variable named name declared in the Go files of dir.
+func lookupStringSlice(dir, name string) ([]string, bool) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, false
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".go") || strings.HasSuffix(entry.Name(), "_test.go") {
+			continue
+		}
+		node, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, entry.Name()), nil, 0)
+		if err != nil {
+			continue
+		}
+		for _, decl := range node.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				valueSpec, ok := spec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+				for i, ident := range valueSpec.Names {
+					if ident.Name != name || i >= len(valueSpec.Values) {
+						continue
+					}
+					if comp, ok := valueSpec.Values[i].(*ast.CompositeLit); ok {
+						return extractStringSlice(comp), true
+					}
+					return nil, false
+				}
+			}
+		}
+	}
+	return nil, false
+}
+
+// substituteParam returns the source of an expression with each use of the
+// identifier param replaced by the string literal of value, and string
+// literals joined by + folded into one, so "p99: " + service reads as a
+// single literal.
+func substituteParam(src []byte, param, value string) []byte {
+	type tok struct {
+		tok token.Token
+		lit string
+	}
+
+	fset := token.NewFileSet()
+	var s scanner.Scanner
+	s.Init(fset.AddFile("", fset.Base(), len(src)), src, nil, 0)
+
+	var toks []tok
+	for {
+		_, t, lit := s.Scan()
+		if t == token.EOF {
+			break
+		}
+		if t == token.SEMICOLON && lit == "\n" {
+			continue
+		}
+		if t == token.IDENT && lit == param && (len(toks) == 0 || toks[len(toks)-1].tok != token.PERIOD) {
+			t, lit = token.STRING, strconv.Quote(value)
+		}
+		toks = append(toks, tok{t, lit})
+	}
+
+	var folded []tok
+	for _, t := range toks {
+		n := len(folded)
+		if t.tok == token.STRING && n >= 2 && folded[n-1].tok == token.ADD && folded[n-2].tok == token.STRING {
+			left, err1 := strconv.Unquote(folded[n-2].lit)
+			right, err2 := strconv.Unquote(t.lit)
+			if err1 == nil && err2 == nil {
+				folded[n-2].lit = strconv.Quote(left + right)
+				folded = folded[:n-1]
+				continue
+			}
+		}
+		folded = append(folded, t)
+	}
+
+	var out bytes.Buffer
+	for _, t := range folded {
+		if t.lit != "" {
+			out.WriteString(t.lit)
+		} else {
+			out.WriteString(t.tok.String())
+		}
+		out.WriteByte(' ')
+	}
+	return out.Bytes()
+}
@@ -0,0 +1,92 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverAll_Fleet(t *testing.T) {
+	dir := t.TempDir()
+
+	services := `package alerts
+
+var Services = []string{"checkout", "cart"}
+`
+	content := `package alerts
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/fleet"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/slo"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var Latency = fleet.Each(Services, func(service string) trigger.Trigger {
+	return trigger.Trigger{
+		Name:    "High latency: " + service + " (p99)",
+		Dataset: "production",
+		Query: query.Query{
+			Dataset:      "production",
+			Calculations: []query.Calculation{query.P99("duration_ms")},
+			Filters:      []query.Filter{query.Equals("service.name", service)},
+		},
+		Threshold: trigger.GreaterThan(500),
+		Tags:      []trigger.Tag{{Key: "service", Value: service}},
+	}
+})
+
+var Availability = fleet.Each([]string{"search"}, func(svc string) slo.SLO {
+	return slo.SLO{Name: svc + " availability", Dataset: svc}
+})
+
+var Unresolved = fleet.Each(services(), func(service string) query.Query {
+	return query.Query{Dataset: service}
+})
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "services.go"), []byte(services), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "alerts.go"), []byte(content), 0644))
+
+	resources, err := DiscoverAll(dir)
+	require.NoError(t, err)
+
+	require.Len(t, resources.Triggers, 2)
+	checkout := resources.Triggers[0]
+	assert.Equal(t, "CheckoutLatency", checkout.Name)
+	assert.Equal(t, "High latency: checkout (p99)", checkout.TriggerName)
+	assert.Equal(t, 10, checkout.Line)
+	assert.Equal(t, ">", checkout.ThresholdOp)
+	assert.Equal(t, []DiscoveredTag{{Key: "service", Value: "checkout"}}, checkout.Tags)
+	require.NotNil(t, checkout.InlineQuery)
+	assert.Equal(t, []Filter{{Column: "service.name", Op: "=", Value: "checkout", HasValue: true}}, checkout.InlineQuery.Filters)
+	assert.Equal(t, "CartLatency", resources.Triggers[1].Name)
+	assert.Equal(t, "High latency: cart (p99)", resources.Triggers[1].TriggerName)
+
+	// Inline queries are discovered under the name of their trigger
+	queries := make(map[string]string)
+	for _, q := range resources.Queries {
+		queries[q.Name] = q.Filters[0].Value.(string)
+	}
+	assert.Equal(t, map[string]string{"CheckoutLatency": "checkout", "CartLatency": "cart"}, queries)
+
+	require.Len(t, resources.SLOs, 1)
+	assert.Equal(t, "SearchAvailability", resources.SLOs[0].Name)
+	assert.Equal(t, "search availability", resources.SLOs[0].SLOName)
+	assert.Equal(t, "search", resources.SLOs[0].Dataset)
+}
+
+func TestSubstituteParam(t *testing.T) {
+	tests := []struct {
+		src, want string
+	}{
+		{`x{Name: service}`, `x { Name : "api" } `},
+		{`x{Name: "p99 " + service + "!", Other: s.service}`, `x { Name : "p99 api!" , Other : s . service } `},
+		{"`raw ` + service", `"raw api" `},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, string(substituteParam([]byte(tt.src), "service", "api")))
+	}
+}
@@ -0,0 +1,193 @@
+package discovery
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/mute"
+)
+
+// DiscoveredMute represents a discovered mute.Window declaration: a
+// maintenance window muting the triggers it selects.
+type DiscoveredMute struct {
+	// Name is the identifier of the window (variable name)
+	Name string
+
+	// Package is the package name where the window is defined
+	Package string
+
+	// File is the absolute path to the file containing the window
+	File string
+
+	// Line is the line number where the window is defined
+	Line int
+
+	// WindowName is the Window.Name field value
+	WindowName string
+
+	// Reason is the Window.Reason field value
+	Reason string
+
+	// Start and End are the window bounds as written, in RFC 3339 format
+	Start string
+	End   string
+
+	// Triggers are the trigger name patterns of the window
+	Triggers []string
+
+	// Tags are the tag selectors of the window
+	Tags []string
+}
+
+// Window returns the mute.Window the discovered window declares.
+func (m DiscoveredMute) Window() mute.Window {
+	return mute.Window{
+		Name:     m.WindowName,
+		Reason:   m.Reason,
+		Start:    m.Start,
+		End:      m.End,
+		Triggers: m.Triggers,
+		Tags:     m.Tags,
+	}
+}
+
+// MutedTriggers returns the Go variable names of the triggers the window
+// selects, in discovery order.
+func (r *DiscoveredResources) MutedTriggers(m DiscoveredMute) []string {
+	window := m.Window()
+	var names []string
+	for _, t := range r.Triggers {
+		tags := make([]string, len(t.Tags))
+		for i, tag := range t.Tags {
+			tags[i] = tag.Key + "=" + tag.Value
+		}
+		if window.Selects(t.Name, t.TriggerName, tags) {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+// DiscoverMutes discovers all mute.Window definitions in the specified directory.
+func DiscoverMutes(dir string) ([]DiscoveredMute, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("path is not a directory: %s", dir)
+	}
+
+	var discovered []DiscoveredMute
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		mutes, err := extractFile("mutes", path, discoverMutesInFile)
+		if err != nil {
+			return nil
+		}
+
+		discovered = append(discovered, mutes...)
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	return discovered, nil
+}
+
+// discoverMutesInFile discovers maintenance windows in a single Go source file.
+func discoverMutesInFile(path string) ([]DiscoveredMute, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+
+	var discovered []DiscoveredMute
+	for _, decl := range node.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			name := getIdentifierName(valueSpec)
+			if name == "" {
+				continue
+			}
+			for _, value := range valueSpec.Values {
+				comp, ok := value.(*ast.CompositeLit)
+				if !ok || !isMuteType(comp.Type) {
+					continue
+				}
+				discovered = append(discovered, extractMuteFromComposite(comp, fset, absPath, node.Name.Name, name))
+			}
+		}
+	}
+
+	return discovered, nil
+}
+
+// isMuteType checks if a type expression refers to mute.Window.
+func isMuteType(expr ast.Expr) bool {
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if ident, ok := sel.X.(*ast.Ident); ok {
+			return ident.Name == "mute" && sel.Sel.Name == "Window"
+		}
+	}
+	return false
+}
+
+// extractMuteFromComposite extracts a maintenance window from a composite literal.
+func extractMuteFromComposite(comp *ast.CompositeLit, fset *token.FileSet, file string, pkg string, name string) DiscoveredMute {
+	m := DiscoveredMute{
+		Name:    name,
+		Package: pkg,
+		File:    file,
+		Line:    fset.Position(comp.Pos()).Line,
+	}
+
+	if v := extractFieldValue(comp, "Name"); v != nil {
+		m.WindowName = extractStringLiteral(v)
+	}
+	if v := extractFieldValue(comp, "Reason"); v != nil {
+		m.Reason = extractStringLiteral(v)
+	}
+	if v := extractFieldValue(comp, "Start"); v != nil {
+		m.Start = extractStringLiteral(v)
+	}
+	if v := extractFieldValue(comp, "End"); v != nil {
+		m.End = extractStringLiteral(v)
+	}
+	if v := extractFieldValue(comp, "Triggers"); v != nil {
+		m.Triggers = extractStringSlice(v)
+	}
+	if v := extractFieldValue(comp, "Tags"); v != nil {
+		m.Tags = extractStringSlice(v)
+	}
+
+	return m
+}
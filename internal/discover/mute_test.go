@@ -0,0 +1,61 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverMutes(t *testing.T) {
+	dir := t.TempDir()
+
+	content := `package alerts
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/mute"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var DatabaseUpgrade = mute.Window{
+	Name:     "Database upgrade",
+	Reason:   "CHG-1234",
+	Start:    "2026-11-07T02:00:00Z",
+	End:      "2026-11-07T04:00:00Z",
+	Triggers: []string{"HighLatency"},
+	Tags:     []string{"team=storage"},
+}
+
+var HighLatency = trigger.Trigger{Name: "High Latency", Dataset: "api"}
+
+var SlowQueries = trigger.Trigger{
+	Name:    "Slow Queries",
+	Dataset: "db",
+	Tags:    []trigger.Tag{{Key: "team", Value: "storage"}},
+}
+
+var HighErrors = trigger.Trigger{Name: "High Errors", Dataset: "api"}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "alerts.go"), []byte(content), 0644))
+
+	resources, err := DiscoverAll(dir)
+	require.NoError(t, err)
+	require.Len(t, resources.Mutes, 1)
+	assert.Equal(t, 3, resources.TotalCount())
+
+	m := resources.Mutes[0]
+	assert.Equal(t, "DatabaseUpgrade", m.Name)
+	assert.Equal(t, "alerts", m.Package)
+	assert.Equal(t, 8, m.Line)
+	assert.Equal(t, "Database upgrade", m.WindowName)
+	assert.Equal(t, "CHG-1234", m.Reason)
+	assert.Equal(t, "2026-11-07T02:00:00Z", m.Start)
+	assert.Equal(t, "2026-11-07T04:00:00Z", m.End)
+	assert.Equal(t, []string{"HighLatency"}, m.Triggers)
+	assert.Equal(t, []string{"team=storage"}, m.Tags)
+	assert.NoError(t, m.Window().Validate())
+
+	assert.Equal(t, []string{"HighLatency", "SlowQueries"}, resources.MutedTriggers(m))
+}
@@ -0,0 +1,83 @@
+package discovery
+
+import "cmp"
+
+// NameConflict is a trigger or SLO whose display name is already used by an
+// earlier one of the same kind on its dataset. Honeycomb rejects duplicate
+// trigger and SLO names within a dataset, so such resources cannot all be
+// applied.
+type NameConflict struct {
+	// Kind is "trigger" or "slo"
+	Kind string
+
+	// Name, File and Line identify the duplicate by its variable
+	Name string
+	File string
+	Line int
+
+	// DisplayName is the shared TriggerName or SLOName
+	DisplayName string
+
+	// Dataset is the dataset the resources share
+	Dataset string
+
+	// First, FirstFile and FirstLine identify the earlier resource, which
+	// keeps the name
+	First     string
+	FirstFile string
+	FirstLine int
+}
+
+// NameConflicts returns the triggers and SLOs of r sharing their display
+// name with an earlier one on the same dataset, in discovery order. A
+// trigger's dataset is its own or its query's, and an SLO's is its own or
+// its good events query's. Resources without a display name or dataset are
+// left out.
+func (r *DiscoveredResources) NameConflicts() []NameConflict {
+	type first struct {
+		name, file string
+		line       int
+	}
+	var conflicts []NameConflict
+	seen := make(map[string]first)
+	check := func(kind, name, file string, line int, displayName, dataset string) {
+		if displayName == "" || dataset == "" {
+			return
+		}
+		key := kind + "\x00" + dataset + "\x00" + displayName
+		f, ok := seen[key]
+		if !ok {
+			seen[key] = first{name, file, line}
+			return
+		}
+		conflicts = append(conflicts, NameConflict{
+			Kind: kind, Name: name, File: file, Line: line,
+			DisplayName: displayName, Dataset: dataset,
+			First: f.name, FirstFile: f.file, FirstLine: f.line,
+		})
+	}
+
+	for _, t := range r.Triggers {
+		check("trigger", t.Name, t.File, t.Line, t.TriggerName, r.TriggerDataset(t))
+	}
+	for _, s := range r.SLOs {
+		check("slo", s.Name, s.File, s.Line, s.SLOName, r.SLODataset(s))
+	}
+	return conflicts
+}
+
+// TriggerDataset returns the dataset of t: its own, or its query's.
+func (r *DiscoveredResources) TriggerDataset(t DiscoveredTrigger) string {
+	if q := t.Query(r.Queries); q != nil {
+		return cmp.Or(t.Dataset, q.Dataset)
+	}
+	return t.Dataset
+}
+
+// SLODataset returns the dataset of s: its own, or its good events query's.
+func (r *DiscoveredResources) SLODataset(s DiscoveredSLO) string {
+	if good, _ := s.SLIQueries(r.Queries); good != nil {
+		return cmp.Or(s.Dataset, good.Dataset)
+	}
+	return s.Dataset
+}
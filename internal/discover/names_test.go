@@ -0,0 +1,54 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNameConflicts(t *testing.T) {
+	r := &DiscoveredResources{
+		Queries: []DiscoveredQuery{
+			{Name: "Latency", Dataset: "api"},
+			{Name: "WebLatency", Dataset: "web"},
+		},
+		Triggers: []DiscoveredTrigger{
+			{Name: "HighLatency", File: "/a/triggers.go", Line: 3, TriggerName: "High Latency", QueryRef: "Latency"},
+			{Name: "HighLatencyCopy", File: "/b/triggers.go", Line: 7, TriggerName: "High Latency", Dataset: "api"},
+			// Another dataset
+			{Name: "WebHighLatency", TriggerName: "High Latency", QueryRef: "WebLatency"},
+			// No dataset or no name
+			{Name: "Unresolved", TriggerName: "High Latency", QueryRef: "Missing"},
+			{Name: "Unnamed", QueryRef: "Latency"},
+			{Name: "UnnamedToo", QueryRef: "Latency"},
+		},
+		SLOs: []DiscoveredSLO{
+			{Name: "Availability", SLOName: "High Latency", GoodEventsQueryRef: "Latency"},
+			{Name: "AvailabilityCopy", File: "/b/slos.go", Line: 9, SLOName: "High Latency", Dataset: "api"},
+		},
+	}
+
+	conflicts := r.NameConflicts()
+	require.Len(t, conflicts, 2, "a trigger and an SLO may share a name")
+	assert.Equal(t, NameConflict{
+		Kind: "trigger", Name: "HighLatencyCopy", File: "/b/triggers.go", Line: 7,
+		DisplayName: "High Latency", Dataset: "api",
+		First: "HighLatency", FirstFile: "/a/triggers.go", FirstLine: 3,
+	}, conflicts[0])
+	assert.Equal(t, "slo", conflicts[1].Kind)
+	assert.Equal(t, "AvailabilityCopy", conflicts[1].Name)
+	assert.Equal(t, "Availability", conflicts[1].First)
+}
+
+func TestResourceDatasets(t *testing.T) {
+	r := &DiscoveredResources{Queries: []DiscoveredQuery{{Name: "Latency", Dataset: "api"}}}
+
+	assert.Equal(t, "api", r.TriggerDataset(DiscoveredTrigger{QueryRef: "Latency"}))
+	assert.Equal(t, "web", r.TriggerDataset(DiscoveredTrigger{Dataset: "web", QueryRef: "Latency"}))
+	assert.Equal(t, "inline", r.TriggerDataset(DiscoveredTrigger{InlineQuery: &DiscoveredQuery{Dataset: "inline"}}))
+	assert.Equal(t, "", r.TriggerDataset(DiscoveredTrigger{QueryRef: "Missing"}))
+
+	assert.Equal(t, "api", r.SLODataset(DiscoveredSLO{GoodEventsQueryRef: "Latency"}))
+	assert.Equal(t, "web", r.SLODataset(DiscoveredSLO{Dataset: "web"}))
+}
@@ -0,0 +1,28 @@
+package discovery
+
+import "sync"
+
+// observer receives the resources found by each DiscoverAll and DiscoverDirs
+// call, e.g. to count them for the CLI's usage metrics.
+var observer = struct {
+	sync.Mutex
+	f func(*DiscoveredResources)
+}{}
+
+// SetObserver sets the function called with the resources found by each
+// successful DiscoverAll and DiscoverDirs call. nil removes it.
+func SetObserver(f func(*DiscoveredResources)) {
+	observer.Lock()
+	defer observer.Unlock()
+	observer.f = f
+}
+
+// observe passes resources to the observer, if any.
+func observe(resources *DiscoveredResources) {
+	observer.Lock()
+	f := observer.f
+	observer.Unlock()
+	if f != nil {
+		f(resources)
+	}
+}
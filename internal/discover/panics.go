@@ -0,0 +1,80 @@
+package discovery
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime/debug"
+	"sort"
+	"sync"
+)
+
+// Panic is a panic recovered while extracting resources from one file. The
+// file is skipped for that kind of resource, so one pathological file cannot
+// crash list, build or watch.
+type Panic struct {
+	// File is the absolute path of the Go file being extracted
+	File string `json:"file"`
+
+	// Kind is what was being extracted, e.g. "queries" or "boards"
+	Kind string `json:"kind"`
+
+	// Value is the recovered panic value
+	Value string `json:"panic"`
+
+	// Stack is the goroutine stack at the panic
+	Stack string `json:"stack"`
+}
+
+// panics are the panics recovered since the last ResetPanics, keyed by file
+// and kind.
+var panics = struct {
+	sync.Mutex
+	byKey map[string]Panic
+}{byKey: make(map[string]Panic)}
+
+// Panics returns the panics recovered by discovery since the process started
+// or ResetPanics was last called, sorted by file and kind.
+func Panics() []Panic {
+	panics.Lock()
+	defer panics.Unlock()
+
+	recovered := make([]Panic, 0, len(panics.byKey))
+	for _, p := range panics.byKey {
+		recovered = append(recovered, p)
+	}
+	sort.Slice(recovered, func(i, j int) bool {
+		if recovered[i].File != recovered[j].File {
+			return recovered[i].File < recovered[j].File
+		}
+		return recovered[i].Kind < recovered[j].Kind
+	})
+	return recovered
+}
+
+// ResetPanics forgets the recovered panics, e.g. before watch rebuilds.
+func ResetPanics() {
+	panics.Lock()
+	defer panics.Unlock()
+	panics.byKey = make(map[string]Panic)
+}
+
+// extractFile runs extract on the file at path, recording a panic as a Panic
+// of kind and returning it as an error, which the directory walks treat like
+// a file that does not parse. Each call is a step of the discovery progress.
+func extractFile[T any](kind, path string, extract func(string) ([]T, error)) (found []T, err error) {
+	defer fileExtracted()
+	defer func() {
+		if r := recover(); r != nil {
+			file, absErr := filepath.Abs(path)
+			if absErr != nil {
+				file = path
+			}
+			p := Panic{File: file, Kind: kind, Value: fmt.Sprint(r), Stack: string(debug.Stack())}
+			panics.Lock()
+			panics.byKey[p.File+"\x00"+p.Kind] = p
+			panics.Unlock()
+			found, err = nil, fmt.Errorf("%s: panic extracting %s: %v", path, kind, r)
+		}
+	}()
+	return extract(path)
+}
@@ -0,0 +1,44 @@
+package discovery
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractFile_RecoversPanic(t *testing.T) {
+	ResetPanics()
+	t.Cleanup(ResetPanics)
+
+	found, err := extractFile("queries", "bad.go", func(string) ([]DiscoveredQuery, error) {
+		var q *DiscoveredQuery
+		return []DiscoveredQuery{*q}, nil
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "bad.go: panic extracting queries: runtime error: invalid memory address")
+	assert.Nil(t, found)
+
+	recovered := Panics()
+	require.Len(t, recovered, 1)
+	abs, _ := filepath.Abs("bad.go")
+	assert.Equal(t, abs, recovered[0].File)
+	assert.Equal(t, "queries", recovered[0].Kind)
+	assert.Contains(t, recovered[0].Stack, "panics_test.go")
+
+	ResetPanics()
+	assert.Empty(t, Panics())
+}
+
+func TestExtractFile_NoPanic(t *testing.T) {
+	ResetPanics()
+	t.Cleanup(ResetPanics)
+
+	found, err := extractFile("queries", "good.go", func(string) ([]DiscoveredQuery, error) {
+		return []DiscoveredQuery{{Name: "Latency"}}, nil
+	})
+	require.NoError(t, err)
+	assert.Len(t, found, 1)
+	assert.Empty(t, Panics())
+}
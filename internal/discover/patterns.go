@@ -0,0 +1,201 @@
+package discovery
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ExpandPatterns resolves Go-style package patterns to the absolute
+// directories to discover. A plain path names a directory, which (as with
+// DiscoverAll) includes its subdirectories. A pattern containing "..."
+// matches every directory with Go source files whose path matches, where
+// "..." stands for any string, so "./services/.../slos" selects each slos
+// package under services. As with the go tool, "x/..." also matches x, and
+// testdata, vendor and directories starting with "." or "_" are skipped.
+//
+// Directories inside another selected directory are dropped, since
+// discovery already includes them. Patterns that match nothing are an error.
+func ExpandPatterns(patterns []string) ([]string, error) {
+	var dirs []string
+	for _, pattern := range patterns {
+		matched, err := expandPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(matched) == 0 {
+			return nil, fmt.Errorf("pattern %s matched no packages", pattern)
+		}
+		dirs = append(dirs, matched...)
+	}
+
+	// Keep only the outermost directories; sorting puts parents first
+	sort.Strings(dirs)
+	var roots []string
+	for _, dir := range dirs {
+		if !withinAny(roots, dir) {
+			roots = append(roots, dir)
+		}
+	}
+	return roots, nil
+}
+
+// DiscoverPatterns discovers all resources in the directories selected by
+// patterns. See ExpandPatterns.
+func DiscoverPatterns(patterns []string) (*DiscoveredResources, error) {
+	dirs, err := ExpandPatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+	return DiscoverDirs(dirs)
+}
+
+// DiscoverDirs discovers all resources in each of dirs, as DiscoverAll does
+// for one directory, reporting their progress as one operation and the
+// resources found together.
+func DiscoverDirs(dirs []string) (*DiscoveredResources, error) {
+	defer startProgress(dirs)()
+
+	resources := &DiscoveredResources{}
+	for _, dir := range dirs {
+		found, err := discoverAll(dir)
+		if err != nil {
+			return nil, err
+		}
+		resources.Queries = append(resources.Queries, found.Queries...)
+		resources.SLOs = append(resources.SLOs, found.SLOs...)
+		resources.Triggers = append(resources.Triggers, found.Triggers...)
+		resources.Boards = append(resources.Boards, found.Boards...)
+		resources.Schemas = append(resources.Schemas, found.Schemas...)
+		resources.Mutes = append(resources.Mutes, found.Mutes...)
+		resources.Custom = append(resources.Custom, found.Custom...)
+		resources.DerivedColumns = append(resources.DerivedColumns, found.DerivedColumns...)
+		resources.Unsupported = append(resources.Unsupported, found.Unsupported...)
+		resources.FieldMistakes = append(resources.FieldMistakes, found.FieldMistakes...)
+	}
+	observe(resources)
+	return resources, nil
+}
+
+// CommonDir returns the deepest directory containing every one of dirs.
+func CommonDir(dirs []string) string {
+	if len(dirs) == 0 {
+		return ""
+	}
+	common := dirs[0]
+	for _, dir := range dirs[1:] {
+		for !within(common, dir) {
+			parent := filepath.Dir(common)
+			if parent == common {
+				break
+			}
+			common = parent
+		}
+	}
+	return common
+}
+
+// expandPattern returns the absolute directories one pattern selects.
+func expandPattern(pattern string) ([]string, error) {
+	clean := filepath.Clean(pattern)
+	i := strings.Index(clean, "...")
+	if i < 0 {
+		dir, err := filepath.Abs(clean)
+		if err != nil {
+			return nil, fmt.Errorf("resolve path: %w", err)
+		}
+		info, err := os.Stat(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to access directory: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("path is not a directory: %s", pattern)
+		}
+		return []string{dir}, nil
+	}
+
+	// Walk from the directory before the first "..."
+	root := clean[:i]
+	if !strings.HasSuffix(root, string(filepath.Separator)) {
+		root = filepath.Dir(root)
+	}
+	root = filepath.Clean(root)
+
+	match := patternRegexp(filepath.ToSlash(clean))
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && skipDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		if !match.MatchString(filepath.ToSlash(path)) || !hasGoFiles(path) {
+			return nil
+		}
+
+		dir, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		dirs = append(dirs, dir)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+	return dirs, nil
+}
+
+// patternRegexp converts a slash-separated pattern to a regexp in which
+// "..." matches any string and a trailing "/..." may match nothing.
+func patternRegexp(pattern string) *regexp.Regexp {
+	re := regexp.QuoteMeta(pattern)
+	if strings.HasSuffix(re, `/\.\.\.`) {
+		re = strings.TrimSuffix(re, `/\.\.\.`) + `(/.*)?`
+	}
+	re = strings.ReplaceAll(re, `\.\.\.`, `.*`)
+	return regexp.MustCompile(`^` + re + `$`)
+}
+
+// skipDir reports whether a "..." pattern ignores directories named name.
+func skipDir(name string) bool {
+	return name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+// hasGoFiles reports whether dir contains non-test Go source files.
+func hasGoFiles(dir string) bool {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			return true
+		}
+	}
+	return false
+}
+
+// withinAny reports whether path is inside any of dirs.
+func withinAny(dirs []string, path string) bool {
+	for _, dir := range dirs {
+		if within(dir, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// within reports whether path is dir or inside it.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,116 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// patternTree creates a monorepo-like tree of query packages in a temp
+// directory.
+func patternTree(t *testing.T) string {
+	dir := t.TempDir()
+	source := `package p
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var %s = query.Query{
+	Dataset:      "production",
+	TimeRange:    query.Hours(1),
+	Calculations: []query.Calculation{query.Count()},
+}
+`
+	packages := map[string]string{
+		"queries":                "Root",
+		"queries/api":            "API",
+		"services/payment/slos":  "Payment",
+		"services/checkout/slos": "Checkout",
+		"services/checkout/web":  "Web",
+		"services/testdata/slos": "Fixture",
+	}
+	for pkg, name := range packages {
+		path := filepath.Join(dir, filepath.FromSlash(pkg))
+		require.NoError(t, os.MkdirAll(path, 0755))
+		content := []byte(strings.Replace(source, "%s", name, 1))
+		require.NoError(t, os.WriteFile(filepath.Join(path, "q.go"), content, 0644))
+	}
+
+	return dir
+}
+
+// inDir makes relative patterns relative to dir.
+func inDir(dir string, patterns []string) []string {
+	var out []string
+	for _, p := range patterns {
+		out = append(out, filepath.Join(dir, p))
+	}
+	return out
+}
+
+func TestExpandPatterns(t *testing.T) {
+	dir := patternTree(t)
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{"plain directory", []string{"./queries"}, []string{"queries"}},
+		{"recursive", []string{"./queries/..."}, []string{"queries"}},
+		{"wildcard segment", []string{"./services/.../slos"}, []string{"services/checkout/slos", "services/payment/slos"}},
+		{"multiple patterns", []string{"./queries/api", "./services/payment/slos"}, []string{"queries/api", "services/payment/slos"}},
+		{"nested patterns collapse", []string{"./queries/api", "./queries/..."}, []string{"queries"}},
+		{"prefix wildcard", []string{"./services/check..."}, []string{"services/checkout/slos", "services/checkout/web"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dirs, err := ExpandPatterns(inDir(dir, tt.patterns))
+			require.NoError(t, err)
+
+			var want []string
+			for _, pkg := range tt.want {
+				want = append(want, filepath.Join(dir, filepath.FromSlash(pkg)))
+			}
+			assert.Equal(t, want, dirs)
+		})
+	}
+}
+
+func TestExpandPatterns_Errors(t *testing.T) {
+	dir := patternTree(t)
+
+	_, err := ExpandPatterns(inDir(dir, []string{"./missing"}))
+	assert.Error(t, err)
+
+	_, err = ExpandPatterns(inDir(dir, []string{"./queries/.../nothing"}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "matched no packages")
+}
+
+func TestDiscoverPatterns(t *testing.T) {
+	dir := patternTree(t)
+
+	resources, err := DiscoverPatterns(inDir(dir, []string{"./queries/api", "./services/.../slos"}))
+	require.NoError(t, err)
+
+	var names []string
+	for _, q := range resources.Queries {
+		names = append(names, q.Name)
+	}
+	assert.ElementsMatch(t, []string{"API", "Checkout", "Payment"}, names)
+}
+
+func TestCommonDir(t *testing.T) {
+	sep := string(filepath.Separator)
+	a := sep + filepath.Join("repo", "queries", "api")
+	b := sep + filepath.Join("repo", "services", "slos")
+
+	assert.Equal(t, sep+"repo", CommonDir([]string{a, b}))
+	assert.Equal(t, a, CommonDir([]string{a}))
+	assert.Equal(t, "", CommonDir(nil))
+}
@@ -0,0 +1,89 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/progress"
+	"github.com/lex00/wetwire-honeycomb-go/resource"
+)
+
+// passes returns the number of times DiscoverAll extracts each Go file:
+// queries, derived columns for the queries, SLOs, triggers, boards, schemas,
+// maintenance windows, derived columns, unsupported declarations, and custom
+// resources when a kind is registered.
+func passes() int {
+	if len(resource.Kinds()) == 0 {
+		return 9
+	}
+	return 10
+}
+
+// tracker receives the progress of DiscoverAll and DiscoverDirs in files: a
+// step for every passes files extracted. active is set while one runs, so
+// that the single-kind Discover functions called on their own report nothing.
+var tracker = struct {
+	sync.Mutex
+	t      progress.Tracker
+	active bool
+	passes int
+	steps  int
+}{t: progress.Nop}
+
+// SetProgress sets the Tracker of DiscoverAll and DiscoverDirs, e.g. a bar on
+// stderr for the CLI. The total is known up front, from a count of the Go
+// files, so large trees show an ETA.
+func SetProgress(t progress.Tracker) {
+	tracker.Lock()
+	defer tracker.Unlock()
+	tracker.t = t
+}
+
+// startProgress starts tracking the discovery of dirs and returns the
+// function finishing it.
+func startProgress(dirs []string) func() {
+	files := 0
+	for _, dir := range dirs {
+		files += countGoFiles(dir)
+	}
+
+	tracker.Lock()
+	defer tracker.Unlock()
+	tracker.active = true
+	tracker.passes, tracker.steps = passes(), 0
+	tracker.t.Start(files)
+	return func() {
+		tracker.Lock()
+		defer tracker.Unlock()
+		tracker.active = false
+		tracker.t.Finish()
+	}
+}
+
+// fileExtracted records the extraction of one file.
+func fileExtracted() {
+	tracker.Lock()
+	defer tracker.Unlock()
+	if !tracker.active {
+		return
+	}
+	tracker.steps++
+	if tracker.steps%tracker.passes == 0 {
+		tracker.t.Add(1)
+	}
+}
+
+// countGoFiles returns the number of files under dir the Discover functions
+// extract: Go files other than tests.
+func countGoFiles(dir string) int {
+	count := 0
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && strings.HasSuffix(path, ".go") && !strings.HasSuffix(path, "_test.go") {
+			count++
+		}
+		return nil
+	})
+	return count
+}
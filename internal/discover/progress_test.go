@@ -0,0 +1,62 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/progress"
+)
+
+// recorder is a progress.Tracker recording what it receives.
+type recorder struct {
+	total, done, finished int
+}
+
+func (r *recorder) Start(total int) { r.total, r.done = total, 0 }
+func (r *recorder) Add(n int)       { r.done += n }
+func (r *recorder) Finish()         { r.finished++ }
+
+func TestDiscoverDirs_Progress(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"queries.go", "sub/triggers.go", "queries_test.go", "README.md"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("package queries\n"), 0644))
+	}
+
+	r := &recorder{}
+	SetProgress(r)
+	t.Cleanup(func() { SetProgress(progress.Nop) })
+
+	_, err := DiscoverDirs([]string{dir, filepath.Join(dir, "sub")})
+	require.NoError(t, err)
+	assert.Equal(t, 3, r.total, "two files, one of them in both directories")
+	assert.Equal(t, r.total, r.done, "every pass should extract every counted file")
+	assert.Equal(t, 1, r.finished)
+
+	_, err = DiscoverQueries(dir)
+	require.NoError(t, err)
+	assert.Equal(t, r.total, r.done, "discovering one kind reports nothing")
+}
+
+func TestSetObserver(t *testing.T) {
+	dir := t.TempDir()
+	src := "package queries\n\nimport \"github.com/lex00/wetwire-honeycomb-go/query\"\n\nvar Errors = query.Query{Dataset: \"api\", Calculations: []query.Calculation{query.Count()}}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "queries.go"), []byte(src), 0644))
+
+	var observed []int
+	SetObserver(func(r *DiscoveredResources) { observed = append(observed, len(r.Queries)) })
+	t.Cleanup(func() { SetObserver(nil) })
+
+	_, err := DiscoverAll(dir)
+	require.NoError(t, err)
+	_, err = DiscoverDirs([]string{dir, dir})
+	require.NoError(t, err)
+	_, err = DiscoverQueries(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, observed, "DiscoverDirs should report its dirs together, DiscoverQueries nothing")
+}
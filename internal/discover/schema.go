@@ -0,0 +1,231 @@
+package discovery
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DiscoveredSchema represents a discovered dataset.Schema declaration,
+// conventionally `var Columns = dataset.Schema{...}` in schema.go.
+type DiscoveredSchema struct {
+	// Name is the identifier of the schema (variable name)
+	Name string
+
+	// Package is the package name where the schema is defined
+	Package string
+
+	// File is the absolute path to the file containing the schema
+	File string
+
+	// Line is the line number where the schema is defined
+	Line int
+
+	// Dataset is the Honeycomb dataset the schema describes
+	Dataset string
+
+	// Columns are the declared columns
+	Columns []DiscoveredColumn
+
+	// RetentionDays is the dataset's declared retention (0 when not set)
+	RetentionDays int
+
+	// EventsPerDay is the dataset's declared ingest volume (0 when not set)
+	EventsPerDay int64
+}
+
+// DiscoveredColumn is a column declared in a dataset schema.
+type DiscoveredColumn struct {
+	// Name is the column name
+	Name string
+
+	// Type is the column type ("string", "integer", "float" or "boolean")
+	Type string
+
+	// Cardinality is the declared number of distinct values (0 when not set)
+	Cardinality int64
+}
+
+// Column returns the column with the given name.
+func (s DiscoveredSchema) Column(name string) (DiscoveredColumn, bool) {
+	for _, c := range s.Columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return DiscoveredColumn{}, false
+}
+
+// DiscoverSchemas discovers all dataset.Schema definitions in the specified directory.
+func DiscoverSchemas(dir string) ([]DiscoveredSchema, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("path is not a directory: %s", dir)
+	}
+
+	var discovered []DiscoveredSchema
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		schemas, err := extractFile("schemas", path, discoverSchemasInFile)
+		if err != nil {
+			return nil
+		}
+
+		discovered = append(discovered, schemas...)
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	return discovered, nil
+}
+
+// discoverSchemasInFile discovers schemas in a single Go source file.
+func discoverSchemasInFile(path string) ([]DiscoveredSchema, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+
+	var discovered []DiscoveredSchema
+	for _, decl := range node.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			name := getIdentifierName(valueSpec)
+			if name == "" {
+				continue
+			}
+			for _, value := range valueSpec.Values {
+				comp, ok := value.(*ast.CompositeLit)
+				if !ok || !isSchemaType(comp.Type) {
+					continue
+				}
+				discovered = append(discovered, extractSchemaFromComposite(comp, fset, absPath, node.Name.Name, name))
+			}
+		}
+	}
+
+	return discovered, nil
+}
+
+// isSchemaType checks if a type expression refers to dataset.Schema.
+func isSchemaType(expr ast.Expr) bool {
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if ident, ok := sel.X.(*ast.Ident); ok {
+			return ident.Name == "dataset" && sel.Sel.Name == "Schema"
+		}
+	}
+	return false
+}
+
+// extractSchemaFromComposite extracts schema metadata from a composite literal.
+func extractSchemaFromComposite(comp *ast.CompositeLit, fset *token.FileSet, file string, pkg string, name string) DiscoveredSchema {
+	schema := DiscoveredSchema{
+		Name:    name,
+		Package: pkg,
+		File:    file,
+		Line:    fset.Position(comp.Pos()).Line,
+	}
+
+	if ds := extractFieldValue(comp, "Dataset"); ds != nil {
+		schema.Dataset = extractStringLiteral(ds)
+	}
+	if days := extractFieldValue(comp, "RetentionDays"); days != nil {
+		schema.RetentionDays = extractIntLiteral(days)
+	}
+	if events := extractFieldValue(comp, "EventsPerDay"); events != nil {
+		schema.EventsPerDay = int64(extractIntLiteral(events))
+	}
+	if cols, ok := extractFieldValue(comp, "Columns").(*ast.CompositeLit); ok {
+		for _, elt := range cols.Elts {
+			if col, ok := extractColumn(elt); ok {
+				schema.Columns = append(schema.Columns, col)
+			}
+		}
+	}
+
+	return schema
+}
+
+// columnTypes maps dataset column constructors and type constants to column types.
+var columnTypes = map[string]string{
+	"String":      "string",
+	"Integer":     "integer",
+	"Float":       "float",
+	"Boolean":     "boolean",
+	"TypeString":  "string",
+	"TypeInteger": "integer",
+	"TypeFloat":   "float",
+	"TypeBoolean": "boolean",
+}
+
+// extractColumn extracts a column from dataset.String("name") style calls or
+// dataset.Column{Name: "name", Type: dataset.TypeFloat} literals, either
+// optionally followed by .WithCardinality(n).
+func extractColumn(expr ast.Expr) (DiscoveredColumn, bool) {
+	switch e := expr.(type) {
+	case *ast.CallExpr:
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok || len(e.Args) != 1 {
+			return DiscoveredColumn{}, false
+		}
+		if sel.Sel.Name == "WithCardinality" {
+			col, ok := extractColumn(sel.X)
+			col.Cardinality = int64(extractIntLiteral(e.Args[0]))
+			return col, ok
+		}
+		typ, ok := columnTypes[sel.Sel.Name]
+		if !ok {
+			return DiscoveredColumn{}, false
+		}
+		name := extractStringLiteral(e.Args[0])
+		return DiscoveredColumn{Name: name, Type: typ}, name != ""
+
+	case *ast.CompositeLit:
+		var col DiscoveredColumn
+		if name := extractFieldValue(e, "Name"); name != nil {
+			col.Name = extractStringLiteral(name)
+		}
+		switch typ := extractFieldValue(e, "Type").(type) {
+		case *ast.SelectorExpr:
+			col.Type = columnTypes[typ.Sel.Name]
+		case *ast.BasicLit:
+			col.Type = extractStringLiteral(typ)
+		}
+		if cardinality := extractFieldValue(e, "Cardinality"); cardinality != nil {
+			col.Cardinality = int64(extractIntLiteral(cardinality))
+		}
+		return col, col.Name != ""
+	}
+	return DiscoveredColumn{}, false
+}
@@ -0,0 +1,120 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverSchemas(t *testing.T) {
+	dir := t.TempDir()
+
+	content := `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/dataset"
+
+var Columns = dataset.Schema{
+	Dataset: "production",
+	Columns: []dataset.Column{
+		dataset.String("service.name"),
+		dataset.Float("duration_ms"),
+		dataset.Integer("http.status_code"),
+		{Name: "error", Type: dataset.TypeBoolean, Description: "request failed"},
+	},
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schema.go"), []byte(content), 0644))
+
+	schemas, err := DiscoverSchemas(dir)
+	require.NoError(t, err)
+	require.Len(t, schemas, 1)
+
+	s := schemas[0]
+	assert.Equal(t, "Columns", s.Name)
+	assert.Equal(t, "queries", s.Package)
+	assert.Equal(t, "production", s.Dataset)
+	assert.Equal(t, []DiscoveredColumn{
+		{Name: "service.name", Type: "string"},
+		{Name: "duration_ms", Type: "float"},
+		{Name: "http.status_code", Type: "integer"},
+		{Name: "error", Type: "boolean"},
+	}, s.Columns)
+
+	col, ok := s.Column("duration_ms")
+	assert.True(t, ok)
+	assert.Equal(t, "float", col.Type)
+}
+
+func TestDiscoverSchemas_Volume(t *testing.T) {
+	dir := t.TempDir()
+
+	content := `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/dataset"
+
+var API = dataset.Schema{
+	Dataset:       "api",
+	RetentionDays: 30,
+	EventsPerDay:  2_000_000_000,
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schema.go"), []byte(content), 0644))
+
+	schemas, err := DiscoverSchemas(dir)
+	require.NoError(t, err)
+	require.Len(t, schemas, 1)
+	assert.Equal(t, 30, schemas[0].RetentionDays)
+	assert.Equal(t, int64(2_000_000_000), schemas[0].EventsPerDay)
+}
+
+func TestDiscoverSchemas_Cardinality(t *testing.T) {
+	dir := t.TempDir()
+
+	content := `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/dataset"
+
+var API = dataset.Schema{
+	Dataset: "api",
+	Columns: []dataset.Column{
+		dataset.String("user.id").WithCardinality(2_000_000),
+		{Name: "region", Type: dataset.TypeString, Cardinality: 12},
+		dataset.String("service.name"),
+	},
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "schema.go"), []byte(content), 0644))
+
+	schemas, err := DiscoverSchemas(dir)
+	require.NoError(t, err)
+	require.Len(t, schemas, 1)
+	assert.Equal(t, []DiscoveredColumn{
+		{Name: "user.id", Type: "string", Cardinality: 2_000_000},
+		{Name: "region", Type: "string", Cardinality: 12},
+		{Name: "service.name", Type: "string"},
+	}, schemas[0].Columns)
+}
+
+func TestDiscoveredResources_Schema(t *testing.T) {
+	resources := &DiscoveredResources{
+		Schemas: []DiscoveredSchema{
+			{Dataset: "production", Columns: []DiscoveredColumn{{Name: "a", Type: "string"}}},
+			{Dataset: "staging", Columns: []DiscoveredColumn{{Name: "b", Type: "string"}}},
+			{Dataset: "production", Columns: []DiscoveredColumn{{Name: "a", Type: "float"}, {Name: "c", Type: "integer"}}, RetentionDays: 30},
+		},
+	}
+
+	s, ok := resources.Schema("production")
+	require.True(t, ok)
+	assert.Equal(t, []DiscoveredColumn{{Name: "a", Type: "string"}, {Name: "c", Type: "integer"}}, s.Columns)
+	assert.Equal(t, 30, s.RetentionDays)
+
+	// Merging does not modify the declared schemas
+	assert.Len(t, resources.Schemas[0].Columns, 1)
+
+	_, ok = resources.Schema("missing")
+	assert.False(t, ok)
+}
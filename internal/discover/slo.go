@@ -31,10 +31,15 @@ type DiscoveredSLO struct {
 	// Description is the SLO.Description field value
 	Description string
 
+	// Doc is the Go doc comment of the declaration, used as the description
+	// when Description is empty
+	Doc string
+
 	// Dataset is the Honeycomb dataset
 	Dataset string
 
-	// TargetPercentage is the target SLO percentage
+	// TargetPercentage is the target SLO percentage; targets declared with
+	// slo.PerMillion are converted
 	TargetPercentage float64
 
 	// TimePeriodDays is the rolling window in days
@@ -46,8 +51,67 @@ type DiscoveredSLO struct {
 	// TotalEventsQueryRef is the name of the total events query
 	TotalEventsQueryRef string
 
+	// GoodEventsQuery is the inline good events query (nil when referenced by name)
+	GoodEventsQuery *DiscoveredQuery
+
+	// TotalEventsQuery is the inline total events query (nil when referenced by name)
+	TotalEventsQuery *DiscoveredQuery
+
+	// SLIAlias is the SLI.Alias field value: the derived column Honeycomb
+	// evaluates the SLI with
+	SLIAlias string
+
 	// BurnAlertCount is the number of burn alerts configured
 	BurnAlertCount int
+
+	// BurnAlerts are the burn alert definitions that could be resolved statically
+	BurnAlerts []DiscoveredBurnAlert
+
+	// DefaultRecipients are the SLO's default burn alert recipients whose type
+	// and target could be resolved
+	DefaultRecipients []DiscoveredRecipient
+}
+
+// AlertRecipients returns the recipients of a burn alert: its own, or the
+// SLO's defaults when it has none.
+func (s DiscoveredSLO) AlertRecipients(alert DiscoveredBurnAlert) []DiscoveredRecipient {
+	if len(alert.Recipients) > 0 {
+		return alert.Recipients
+	}
+	return s.DefaultRecipients
+}
+
+// SLIQueries returns the good and total events queries of the SLO's SLI:
+// inline, or referenced queries, preferring those in the SLO's package. A
+// query is nil when its reference matches none of queries.
+func (s DiscoveredSLO) SLIQueries(queries []DiscoveredQuery) (good, total *DiscoveredQuery) {
+	good, total = s.GoodEventsQuery, s.TotalEventsQuery
+	if good == nil {
+		good = lookupQuery(queries, s.GoodEventsQueryRef, s.Package)
+	}
+	if total == nil {
+		total = lookupQuery(queries, s.TotalEventsQueryRef, s.Package)
+	}
+	return good, total
+}
+
+// DiscoveredBurnAlert represents a burn alert configured on an SLO.
+type DiscoveredBurnAlert struct {
+	// Name is the BurnAlert.Name field value
+	Name string
+
+	// AlertType is the burn rate calculation method ("budget_rate" or "exhaustion_time")
+	AlertType string
+
+	// Threshold is the trigger threshold value
+	Threshold float64
+
+	// WindowHours is the burn rate window in hours
+	WindowHours int
+
+	// Recipients are the alert's own recipients whose type and target could
+	// be resolved
+	Recipients []DiscoveredRecipient
 }
 
 // DiscoverSLOs discovers all SLO definitions in the specified directory.
@@ -71,7 +135,7 @@ func DiscoverSLOs(dir string) ([]DiscoveredSLO, error) {
 			return nil
 		}
 
-		slos, err := discoverSLOsInFile(path)
+		slos, err := extractFile("slos", path, discoverSLOsInFile)
 		if err != nil {
 			return nil
 		}
@@ -110,6 +174,9 @@ func discoverSLOsInFile(path string) ([]DiscoveredSLO, error) {
 				for _, spec := range decl.Specs {
 					if valueSpec, ok := spec.(*ast.ValueSpec); ok {
 						slos := extractSLOsFromValueSpec(valueSpec, fset, absPath, packageName)
+						for i := range slos {
+							slos[i].Doc = declDoc(decl, valueSpec)
+						}
 						discovered = append(discovered, slos...)
 					}
 				}
@@ -118,6 +185,14 @@ func discoverSLOsInFile(path string) ([]DiscoveredSLO, error) {
 		return true
 	})
 
+	// Loops of init functions over a service list are unrolled
+	for _, e := range initLoopExpansions(node, fset, absPath) {
+		for _, s := range extractSLOsFromValueSpec(e.spec, e.fset, absPath, packageName) {
+			s.Line = e.line
+			discovered = append(discovered, s)
+		}
+	}
+
 	return discovered, nil
 }
 
@@ -130,6 +205,17 @@ func extractSLOsFromValueSpec(spec *ast.ValueSpec, fset *token.FileSet, file str
 		return discovered
 	}
 
+	// A fleet.Each template declares a resource per service
+	if expansions, ok, _ := expandFleet(spec, fset, file); ok {
+		for _, e := range expansions {
+			for _, s := range extractSLOsFromValueSpec(e.spec, e.fset, file, pkg) {
+				s.Line = e.line
+				discovered = append(discovered, s)
+			}
+		}
+		return discovered
+	}
+
 	for _, value := range spec.Values {
 		composites := findSLOComposites(value)
 		for _, comp := range composites {
@@ -202,8 +288,13 @@ func extractSLOFromComposite(comp *ast.CompositeLit, fset *token.FileSet, file s
 			slo.TimePeriodDays = extractTimePeriodDays(kv.Value)
 		case "SLI":
 			slo.GoodEventsQueryRef, slo.TotalEventsQueryRef = extractSLIQueryRefs(kv.Value)
+			slo.GoodEventsQuery, slo.TotalEventsQuery = extractSLIInlineQueries(kv.Value, fset, file, pkg, name)
+			slo.SLIAlias = extractSLIAlias(kv.Value)
 		case "BurnAlerts":
 			slo.BurnAlertCount = extractBurnAlertCount(kv.Value)
+			slo.BurnAlerts = extractBurnAlerts(kv.Value)
+		case "DefaultRecipients":
+			slo.DefaultRecipients = extractRecipients(kv.Value)
 		}
 	}
 
@@ -212,12 +303,15 @@ func extractSLOFromComposite(comp *ast.CompositeLit, fset *token.FileSet, file s
 
 // extractTargetPercentage extracts the percentage from a Target field.
 func extractTargetPercentage(expr ast.Expr) float64 {
-	// Handle slo.Percentage(99.9)
+	// Handle slo.Percentage(99.9) and slo.PerMillion(999000)
 	if call, ok := expr.(*ast.CallExpr); ok {
 		if sel, ok := call.Fun.(*ast.SelectorExpr); ok {
-			if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == "slo" {
-				if sel.Sel.Name == "Percentage" && len(call.Args) > 0 {
+			if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == "slo" && len(call.Args) > 0 {
+				switch sel.Sel.Name {
+				case "Percentage":
 					return extractFloatLiteral(call.Args[0])
+				case "PerMillion":
+					return float64(extractIntLiteral(call.Args[0])) / 10000
 				}
 			}
 		}
@@ -262,19 +356,173 @@ func extractSLIQueryRefs(expr ast.Expr) (string, string) {
 
 		switch key.Name {
 		case "GoodEvents":
-			if ident, ok := kv.Value.(*ast.Ident); ok {
-				goodRef = ident.Name
-			}
+			goodRef = queryRefName(kv.Value)
 		case "TotalEvents":
-			if ident, ok := kv.Value.(*ast.Ident); ok {
-				totalRef = ident.Name
-			}
+			totalRef = queryRefName(kv.Value)
 		}
 	}
 
 	return goodRef, totalRef
 }
 
+// extractSLIAlias extracts the Alias of an SLI field.
+func extractSLIAlias(expr ast.Expr) string {
+	comp, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return ""
+	}
+
+	for _, elt := range comp.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := kv.Key.(*ast.Ident); ok && key.Name == "Alias" {
+			return extractStringLiteral(kv.Value)
+		}
+	}
+
+	return ""
+}
+
+// extractSLIInlineQueries extracts inline query.Query literals from an SLI field.
+func extractSLIInlineQueries(expr ast.Expr, fset *token.FileSet, file string, pkg string, name string) (*DiscoveredQuery, *DiscoveredQuery) {
+	var good, total *DiscoveredQuery
+
+	comp, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil, nil
+	}
+
+	for _, elt := range comp.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+
+		key, ok := kv.Key.(*ast.Ident)
+		if !ok {
+			continue
+		}
+
+		inner, ok := kv.Value.(*ast.CompositeLit)
+		if !ok || !isQueryCompositeLit(inner) {
+			continue
+		}
+
+		q := extractQueryFromComposite(inner, fset, file, pkg, name)
+		switch key.Name {
+		case "GoodEvents":
+			good = &q
+		case "TotalEvents":
+			total = &q
+		}
+	}
+
+	return good, total
+}
+
+// extractBurnAlerts extracts burn alert definitions from a BurnAlerts field.
+func extractBurnAlerts(expr ast.Expr) []DiscoveredBurnAlert {
+	var result []DiscoveredBurnAlert
+
+	comp, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+
+	for _, elt := range comp.Elts {
+		if alert, ok := extractBurnAlert(elt); ok {
+			result = append(result, alert)
+		}
+	}
+
+	return result
+}
+
+// extractBurnAlert extracts a single burn alert from an expression.
+func extractBurnAlert(expr ast.Expr) (DiscoveredBurnAlert, bool) {
+	var alert DiscoveredBurnAlert
+
+	switch e := expr.(type) {
+	case *ast.CallExpr:
+		// Handle slo.FastBurn(10.0) and slo.SlowBurn(5.0)
+		sel, ok := e.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return alert, false
+		}
+		if ident, ok := sel.X.(*ast.Ident); !ok || ident.Name != "slo" || len(e.Args) == 0 {
+			return alert, false
+		}
+		alert.AlertType = "budget_rate"
+		alert.Threshold = extractFloatLiteral(e.Args[0])
+		switch sel.Sel.Name {
+		case "FastBurn":
+			alert.WindowHours = 1
+		case "SlowBurn":
+			alert.WindowHours = 24
+		default:
+			return alert, false
+		}
+		return alert, true
+
+	case *ast.CompositeLit:
+		// Handle slo.BurnAlert{Name: ..., AlertType: slo.BudgetRate, ...}
+		if name := extractFieldValue(e, "Name"); name != nil {
+			alert.Name = extractStringLiteral(name)
+		}
+		if alertType := extractFieldValue(e, "AlertType"); alertType != nil {
+			alert.AlertType = extractAlertType(alertType)
+		}
+		if threshold := extractFieldValue(e, "Threshold"); threshold != nil {
+			alert.Threshold = extractFloatLiteral(threshold)
+		}
+		if window := extractFieldValue(e, "Window"); window != nil {
+			alert.WindowHours = extractWindowHours(window)
+		}
+		if recipients := extractFieldValue(e, "Recipients"); recipients != nil {
+			alert.Recipients = extractRecipients(recipients)
+		}
+		return alert, true
+	}
+
+	return alert, false
+}
+
+// extractAlertType maps slo.BudgetRate / slo.ExhaustionTime to their string values.
+func extractAlertType(expr ast.Expr) string {
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		switch sel.Sel.Name {
+		case "BudgetRate":
+			return "budget_rate"
+		case "ExhaustionTime":
+			return "exhaustion_time"
+		}
+	}
+	return extractStringLiteral(expr)
+}
+
+// extractWindowHours extracts the window size in hours from a TimePeriod expression.
+func extractWindowHours(expr ast.Expr) int {
+	// Handle slo.Days(1)
+	if days := extractTimePeriodDays(expr); days > 0 {
+		return days * 24
+	}
+
+	// Handle slo.TimePeriod{Hours: 1} and slo.TimePeriod{Days: 1}
+	if comp, ok := expr.(*ast.CompositeLit); ok {
+		hours := 0
+		if h := extractFieldValue(comp, "Hours"); h != nil {
+			hours += extractIntLiteral(h)
+		}
+		if d := extractFieldValue(comp, "Days"); d != nil {
+			hours += extractIntLiteral(d) * 24
+		}
+		return hours
+	}
+	return 0
+}
+
 // extractBurnAlertCount counts burn alerts from a BurnAlerts field.
 func extractBurnAlertCount(expr ast.Expr) int {
 	comp, ok := expr.(*ast.CompositeLit)
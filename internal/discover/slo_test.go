@@ -95,6 +95,61 @@ var LatencySLO = slo.SLO{
 	assert.Contains(t, s.TotalEventsQueryRef, "AllRequests")
 }
 
+func TestDiscoverSLOs_SLIAlias(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "slos.go")
+
+	content := `package slos
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/slo"
+)
+
+var CheckoutAvailability = slo.SLO{
+	Name: "Checkout Availability",
+	SLI: slo.SLI{
+		Alias:       "sli.checkout_ok",
+		GoodEvents:  query.Query{Dataset: "cdn-logs"},
+		TotalEvents: query.Query{Dataset: "checkout"},
+	},
+}
+`
+	err := os.WriteFile(testFile, []byte(content), 0644)
+	require.NoError(t, err)
+
+	slos, err := DiscoverSLOs(dir)
+	require.NoError(t, err)
+	require.Len(t, slos, 1)
+
+	s := slos[0]
+	assert.Equal(t, "sli.checkout_ok", s.SLIAlias)
+	require.NotNil(t, s.GoodEventsQuery)
+	assert.Equal(t, "cdn-logs", s.GoodEventsQuery.Dataset)
+}
+
+func TestDiscoverSLOs_TargetPerMillion(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "slos.go")
+
+	content := `package slos
+
+import "github.com/lex00/wetwire-honeycomb-go/slo"
+
+var APIAvailability = slo.SLO{
+	Name:   "API Availability",
+	Target: slo.PerMillion(999500),
+}
+`
+	err := os.WriteFile(testFile, []byte(content), 0644)
+	require.NoError(t, err)
+
+	slos, err := DiscoverSLOs(dir)
+	require.NoError(t, err)
+	require.Len(t, slos, 1)
+	assert.Equal(t, 99.95, slos[0].TargetPercentage)
+}
+
 func TestDiscoverSLOs_WithBurnAlerts(t *testing.T) {
 	dir := t.TempDir()
 	testFile := filepath.Join(dir, "slos.go")
@@ -150,3 +205,120 @@ var SlowRequests = query.Query{
 	require.NoError(t, err)
 	assert.Empty(t, slos)
 }
+
+func TestDiscoverSLOs_InlineSLIAndBurnAlerts(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "slos.go")
+
+	content := `package slos
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/slo"
+)
+
+var APILatency = slo.SLO{
+	Name: "API Latency",
+	SLI: slo.SLI{
+		GoodEvents: query.Query{
+			Dataset:      "production",
+			Calculations: []query.Calculation{query.Count()},
+			Filters:      []query.Filter{query.LT("duration_ms", 500)},
+		},
+		TotalEvents: query.Query{
+			Dataset:      "production",
+			Calculations: []query.Calculation{query.Count()},
+		},
+	},
+	Target: slo.Percentage(99.5),
+	BurnAlerts: []slo.BurnAlert{
+		slo.FastBurn(10.0),
+		{
+			Name:      "Exhaustion",
+			AlertType: slo.ExhaustionTime,
+			Threshold: 4,
+			Window:    slo.TimePeriod{Days: 1},
+		},
+	},
+}
+`
+	err := os.WriteFile(testFile, []byte(content), 0644)
+	require.NoError(t, err)
+
+	slos, err := DiscoverSLOs(dir)
+	require.NoError(t, err)
+	require.Len(t, slos, 1)
+
+	s := slos[0]
+	require.NotNil(t, s.GoodEventsQuery)
+	require.NotNil(t, s.TotalEventsQuery)
+	assert.Equal(t, "production", s.GoodEventsQuery.Dataset)
+	require.Len(t, s.GoodEventsQuery.Filters, 1)
+	assert.Equal(t, "<", s.GoodEventsQuery.Filters[0].Op)
+	assert.Empty(t, s.TotalEventsQuery.Filters)
+
+	require.Len(t, s.BurnAlerts, 2)
+	assert.Equal(t, DiscoveredBurnAlert{AlertType: "budget_rate", Threshold: 10, WindowHours: 1}, s.BurnAlerts[0])
+	assert.Equal(t, DiscoveredBurnAlert{Name: "Exhaustion", AlertType: "exhaustion_time", Threshold: 4, WindowHours: 24}, s.BurnAlerts[1])
+}
+
+func TestDiscoverSLOs_DefaultRecipients(t *testing.T) {
+	dir := t.TempDir()
+	content := `package slos
+
+import "github.com/lex00/wetwire-honeycomb-go/slo"
+
+var APIAvailability = slo.SLO{
+	Name:   "API Availability",
+	Target: slo.Percentage(99.9),
+	BurnAlerts: []slo.BurnAlert{
+		{
+			Name:       "Page",
+			AlertType:  slo.BudgetRate,
+			Threshold:  2,
+			Window:     slo.TimePeriod{Hours: 1},
+			Recipients: []slo.Recipient{{Type: "pagerduty", Target: "api-oncall"}},
+		},
+		slo.SlowBurn(5.0),
+	},
+	DefaultRecipients: []slo.Recipient{
+		{Type: "slack", Target: "#api"},
+	},
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "slos.go"), []byte(content), 0644))
+
+	slos, err := DiscoverSLOs(dir)
+	require.NoError(t, err)
+	require.Len(t, slos, 1)
+
+	s := slos[0]
+	page := []DiscoveredRecipient{{Type: "pagerduty", Target: "api-oncall"}}
+	team := []DiscoveredRecipient{{Type: "slack", Target: "#api"}}
+	assert.Equal(t, team, s.DefaultRecipients)
+	require.Len(t, s.BurnAlerts, 2)
+	assert.Equal(t, page, s.BurnAlerts[0].Recipients)
+	assert.Empty(t, s.BurnAlerts[1].Recipients)
+
+	assert.Equal(t, page, s.AlertRecipients(s.BurnAlerts[0]))
+	assert.Equal(t, team, s.AlertRecipients(s.BurnAlerts[1]))
+}
+
+func TestDiscoveredSLO_SLIQueries(t *testing.T) {
+	queries := []DiscoveredQuery{
+		{Name: "Good", Package: "shared", Dataset: "shared"},
+		{Name: "Good", Package: "slos", Dataset: "local"},
+		{Name: "Total", Package: "queries", Dataset: "api"},
+	}
+
+	good, total := DiscoveredSLO{Package: "slos", GoodEventsQueryRef: "Good", TotalEventsQueryRef: "Total"}.SLIQueries(queries)
+	require.NotNil(t, good)
+	require.NotNil(t, total)
+	assert.Equal(t, "local", good.Dataset, "queries of the SLO's package are preferred")
+	assert.Equal(t, "api", total.Dataset)
+
+	inline := &DiscoveredQuery{Dataset: "inline"}
+	good, total = DiscoveredSLO{GoodEventsQuery: inline, TotalEventsQueryRef: "Missing"}.SLIQueries(queries)
+	assert.Same(t, inline, good)
+	assert.Nil(t, total)
+}
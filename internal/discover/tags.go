@@ -0,0 +1,65 @@
+package discovery
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// DiscoveredTag is a key-value tag on a board or trigger.
+type DiscoveredTag struct {
+	// Key is the tag name
+	Key string
+
+	// Value is the tag value
+	Value string
+}
+
+// extractTags extracts tags from a Tags field, such as
+// []board.Tag{{Key: "team", Value: "platform"}}. Tags whose key is not a
+// string literal are skipped.
+func extractTags(expr ast.Expr) []DiscoveredTag {
+	comp, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+
+	var tags []DiscoveredTag
+	for _, elt := range comp.Elts {
+		tc, ok := elt.(*ast.CompositeLit)
+		if !ok {
+			continue
+		}
+
+		var tag DiscoveredTag
+		if key := extractFieldValue(tc, "Key"); key != nil {
+			tag.Key = extractStringLiteral(key)
+		}
+		if value := extractFieldValue(tc, "Value"); value != nil {
+			tag.Value = extractStringLiteral(value)
+		}
+		if tag.Key != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// MatchTags reports whether tags satisfy every selector. A selector is
+// "key=value", matching a tag with that key and value, or "key", matching any
+// tag with that key.
+func MatchTags(tags []DiscoveredTag, selectors []string) bool {
+	for _, selector := range selectors {
+		key, value, hasValue := strings.Cut(selector, "=")
+		found := false
+		for _, tag := range tags {
+			if tag.Key == key && (!hasValue || tag.Value == value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverTags(t *testing.T) {
+	dir := t.TempDir()
+	content := `package alerts
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var Overview = board.Board{
+	Name: "Overview",
+	Tags: []board.Tag{
+		{Key: "team", Value: "platform"},
+		{Key: "env", Value: "production"},
+	},
+}
+
+var HighLatency = trigger.Trigger{
+	Name: "High Latency",
+	Tags: []trigger.Tag{
+		trigger.Tag{Key: "team", Value: "checkout"},
+		{Key: team, Value: "dynamic"},
+	},
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "alerts.go"), []byte(content), 0644))
+
+	boards, err := DiscoverBoards(dir)
+	require.NoError(t, err)
+	require.Len(t, boards, 1)
+	assert.Equal(t, []DiscoveredTag{{"team", "platform"}, {"env", "production"}}, boards[0].Tags)
+
+	triggers, err := DiscoverTriggers(dir)
+	require.NoError(t, err)
+	require.Len(t, triggers, 1)
+	// Tags with unresolved keys are skipped
+	assert.Equal(t, []DiscoveredTag{{"team", "checkout"}}, triggers[0].Tags)
+}
+
+func TestMatchTags(t *testing.T) {
+	tags := []DiscoveredTag{{"team", "platform"}, {"env", "production"}}
+
+	tests := []struct {
+		selectors []string
+		want      bool
+	}{
+		{nil, true},
+		{[]string{"team=platform"}, true},
+		{[]string{"team"}, true},
+		{[]string{"team=platform", "env=production"}, true},
+		{[]string{"team=checkout"}, false},
+		{[]string{"team=platform", "owner"}, false},
+		{[]string{"env="}, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchTags(tags, tt.selectors), "%v", tt.selectors)
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode"
+
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
 )
 
 // DiscoveredTrigger represents a discovered trigger definition with metadata.
@@ -30,12 +33,19 @@ type DiscoveredTrigger struct {
 	// Description is the Trigger.Description field value
 	Description string
 
+	// Doc is the Go doc comment of the declaration, used as the description
+	// when Description is empty
+	Doc string
+
 	// Dataset is the Honeycomb dataset
 	Dataset string
 
 	// QueryRef is the name of the referenced query
 	QueryRef string
 
+	// InlineQuery is the inline query definition (nil when referenced by name)
+	InlineQuery *DiscoveredQuery
+
 	// ThresholdOp is the threshold operator (>, >=, <, <=)
 	ThresholdOp string
 
@@ -45,11 +55,69 @@ type DiscoveredTrigger struct {
 	// FrequencySeconds is the evaluation frequency in seconds
 	FrequencySeconds int
 
+	// AlertType is the alert type ("on_change" or "on_true"), empty when
+	// the trigger leaves it to Honeycomb's default
+	AlertType string
+
 	// RecipientCount is the number of recipients configured
 	RecipientCount int
 
+	// Recipients are the recipients whose type and target could be resolved
+	Recipients []DiscoveredRecipient
+
 	// Disabled indicates if the trigger is disabled
 	Disabled bool
+
+	// Tags are the trigger's key-value tags
+	Tags []DiscoveredTag
+
+	// Escalation is the variable name of the trigger.Escalation the trigger
+	// was expanded from, "" for a trigger.Trigger
+	Escalation string
+
+	// Level is the name of the escalation level of the trigger
+	Level string
+}
+
+// DiscoveredRecipient is a trigger notification recipient.
+type DiscoveredRecipient struct {
+	// Type is the recipient type (slack, pagerduty, email, webhook)
+	Type string
+
+	// Target is the destination (channel, service ID, email address, URL)
+	Target string
+}
+
+// Query returns the query the trigger evaluates: its inline query, or the
+// query it references, preferring one in the trigger's package. It returns
+// nil when the reference matches none of queries.
+func (t DiscoveredTrigger) Query(queries []DiscoveredQuery) *DiscoveredQuery {
+	if t.InlineQuery != nil {
+		return t.InlineQuery
+	}
+	return lookupQuery(queries, t.QueryRef, t.Package)
+}
+
+// lookupQuery returns the query of queries named name, preferring one in
+// package pkg, or nil when there is none.
+func lookupQuery(queries []DiscoveredQuery, name, pkg string) *DiscoveredQuery {
+	if name == "" {
+		return nil
+	}
+	var found *DiscoveredQuery
+	for i := range queries {
+		q := &queries[i]
+		if q.Name != name {
+			continue
+		}
+		if q.Package == pkg {
+			return q
+		}
+		if found == nil {
+			found = q
+		}
+	}
+	return found
 }
 
 // DiscoverTriggers discovers all Trigger definitions in the specified directory.
@@ -73,7 +141,7 @@ func DiscoverTriggers(dir string) ([]DiscoveredTrigger, error) {
 			return nil
 		}
 
-		triggers, err := discoverTriggersInFile(path)
+		triggers, err := extractFile("triggers", path, discoverTriggersInFile)
 		if err != nil {
 			return nil
 		}
@@ -112,6 +180,9 @@ func discoverTriggersInFile(path string) ([]DiscoveredTrigger, error) {
 				for _, spec := range decl.Specs {
 					if valueSpec, ok := spec.(*ast.ValueSpec); ok {
 						triggers := extractTriggersFromValueSpec(valueSpec, fset, absPath, packageName)
+						for i := range triggers {
+							triggers[i].Doc = declDoc(decl, valueSpec)
+						}
 						discovered = append(discovered, triggers...)
 					}
 				}
@@ -120,6 +191,14 @@ func discoverTriggersInFile(path string) ([]DiscoveredTrigger, error) {
 		return true
 	})
 
+	// Loops of init functions over a service list are unrolled
+	for _, e := range initLoopExpansions(node, fset, absPath) {
+		for _, t := range extractTriggersFromValueSpec(e.spec, e.fset, absPath, packageName) {
+			t.Line = e.line
+			discovered = append(discovered, t)
+		}
+	}
+
 	return discovered, nil
 }
 
@@ -132,6 +211,17 @@ func extractTriggersFromValueSpec(spec *ast.ValueSpec, fset *token.FileSet, file
 		return discovered
 	}
 
+	// A fleet.Each template declares a resource per service
+	if expansions, ok, _ := expandFleet(spec, fset, file); ok {
+		for _, e := range expansions {
+			for _, t := range extractTriggersFromValueSpec(e.spec, e.fset, file, pkg) {
+				t.Line = e.line
+				discovered = append(discovered, t)
+			}
+		}
+		return discovered
+	}
+
 	for _, value := range spec.Values {
 		composites := findTriggerComposites(value)
 		for _, comp := range composites {
@@ -140,11 +230,103 @@ func extractTriggersFromValueSpec(spec *ast.ValueSpec, fset *token.FileSet, file
 				discovered = append(discovered, trigger)
 			}
 		}
+		for _, comp := range findEscalationComposites(value) {
+			discovered = append(discovered, expandEscalation(comp, fset, file, pkg, name)...)
+		}
 	}
 
 	return discovered
 }
 
+// findEscalationComposites finds all trigger.Escalation composite literals in
+// an expression.
+func findEscalationComposites(expr ast.Expr) []*ast.CompositeLit {
+	var result []*ast.CompositeLit
+	ast.Inspect(expr, func(n ast.Node) bool {
+		if comp, ok := n.(*ast.CompositeLit); ok {
+			if sel, ok := comp.Type.(*ast.SelectorExpr); ok {
+				if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == "trigger" && sel.Sel.Name == "Escalation" {
+					result = append(result, comp)
+				}
+			}
+		}
+		return true
+	})
+	return result
+}
+
+// expandEscalation returns a trigger for each level of a trigger.Escalation
+// literal, as trigger.Escalation.Triggers does: the trigger of level
+// "critical" of escalation HighLatency is named HighLatencyCritical, with
+// the display name "<Name> (critical)" and a severity tag. An inline query
+// is discovered as a query named after the escalation, which every level
+// references.
+func expandEscalation(comp *ast.CompositeLit, fset *token.FileSet, file string, pkg string, name string) []DiscoveredTrigger {
+	// The fields shared by every level have the names of trigger fields
+	base := extractTriggerFromComposite(comp, fset, file, pkg, name)
+	if base.InlineQuery != nil {
+		base.QueryRef, base.InlineQuery = name, nil
+	}
+
+	levels, ok := extractFieldValue(comp, "Levels").(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+	var discovered []DiscoveredTrigger
+	for _, elt := range levels.Elts {
+		level, ok := elt.(*ast.CompositeLit)
+		if !ok {
+			continue
+		}
+		levelName := ""
+		if expr := extractFieldValue(level, "Name"); expr != nil {
+			levelName = extractStringLiteral(expr)
+		}
+		if levelName == "" {
+			continue
+		}
+
+		t := base
+		t.Name = name + levelIdentifier(levelName)
+		t.Line = fset.Position(level.Pos()).Line
+		if base.TriggerName != "" {
+			t.TriggerName = trigger.EscalationName(base.TriggerName, levelName)
+		}
+		t.Escalation, t.Level = name, levelName
+		t.ThresholdOp, t.ThresholdValue = "", 0
+		if expr := extractFieldValue(level, "Threshold"); expr != nil {
+			t.ThresholdOp, t.ThresholdValue = extractThreshold(expr)
+		}
+		t.RecipientCount, t.Recipients = 0, nil
+		if expr := extractFieldValue(level, "Recipients"); expr != nil {
+			t.RecipientCount = extractRecipientCount(expr)
+			t.Recipients = extractRecipients(expr)
+		}
+		t.Tags = append([]DiscoveredTag{{Key: trigger.SeverityTag, Value: levelName}}, base.Tags...)
+		discovered = append(discovered, t)
+	}
+	return discovered
+}
+
+// levelIdentifier returns an escalation level name as a Go identifier
+// suffix: "critical" becomes "Critical" and "p1-page" "P1Page".
+func levelIdentifier(level string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range level {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
 // findTriggerComposites finds all trigger.Trigger composite literals in an expression.
 func findTriggerComposites(expr ast.Expr) []*ast.CompositeLit {
 	var result []*ast.CompositeLit
@@ -199,17 +381,25 @@ func extractTriggerFromComposite(comp *ast.CompositeLit, fset *token.FileSet, fi
 		case "Dataset":
 			trigger.Dataset = extractStringLiteral(kv.Value)
 		case "Query":
-			if ident, ok := kv.Value.(*ast.Ident); ok {
-				trigger.QueryRef = ident.Name
+			if inner, ok := kv.Value.(*ast.CompositeLit); ok && isQueryCompositeLit(inner) {
+				q := extractQueryFromComposite(inner, fset, file, pkg, name)
+				trigger.InlineQuery = &q
+			} else {
+				trigger.QueryRef = queryRefName(kv.Value)
 			}
 		case "Threshold":
 			trigger.ThresholdOp, trigger.ThresholdValue = extractThreshold(kv.Value)
 		case "Frequency":
 			trigger.FrequencySeconds = extractFrequencySeconds(kv.Value)
+		case "AlertType":
+			trigger.AlertType = extractTriggerAlertType(kv.Value)
 		case "Recipients":
 			trigger.RecipientCount = extractRecipientCount(kv.Value)
+			trigger.Recipients = extractRecipients(kv.Value)
 		case "Disabled":
 			trigger.Disabled = extractBoolLiteral(kv.Value)
+		case "Tags":
+			trigger.Tags = extractTags(kv.Value)
 		}
 	}
 
@@ -234,14 +424,74 @@ func extractThreshold(expr ast.Expr) (string, float64) {
 					op = "<="
 				}
 				if op != "" && len(call.Args) > 0 {
-					return op, extractFloatLiteral(call.Args[0])
+					return op, extractSignedFloat(call.Args[0])
 				}
 			}
 		}
 	}
+
+	// Handle composite literal: trigger.Threshold{Op: trigger.GTE, Value: 5}
+	if comp, ok := expr.(*ast.CompositeLit); ok {
+		var op string
+		var value float64
+		if opExpr := extractFieldValue(comp, "Op"); opExpr != nil {
+			op = extractThresholdOp(opExpr)
+		}
+		if valueExpr := extractFieldValue(comp, "Value"); valueExpr != nil {
+			value = extractSignedFloat(valueExpr)
+		}
+		return op, value
+	}
+
 	return "", 0
 }
 
+// extractTriggerAlertType maps trigger.OnChange / trigger.OnTrue, or a string
+// literal, to the alert type value.
+func extractTriggerAlertType(expr ast.Expr) string {
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == "trigger" {
+			switch sel.Sel.Name {
+			case "OnChange":
+				return "on_change"
+			case "OnTrue":
+				return "on_true"
+			}
+		}
+	}
+	return extractStringLiteral(expr)
+}
+
+// extractThresholdOp extracts a threshold operator from trigger.GT, trigger.GTE,
+// trigger.LT, trigger.LTE or a string literal such as ">=".
+func extractThresholdOp(expr ast.Expr) string {
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if ident, ok := sel.X.(*ast.Ident); ok && ident.Name == "trigger" {
+			switch sel.Sel.Name {
+			case "GT":
+				return ">"
+			case "GTE":
+				return ">="
+			case "LT":
+				return "<"
+			case "LTE":
+				return "<="
+			}
+		}
+		return ""
+	}
+	return extractStringLiteral(expr)
+}
+
+// extractSignedFloat extracts a float64 from a numeric literal, allowing a
+// leading minus sign.
+func extractSignedFloat(expr ast.Expr) float64 {
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.SUB {
+		return -extractFloatLiteral(unary.X)
+	}
+	return extractFloatLiteral(expr)
+}
+
 // extractFrequencySeconds extracts seconds from a Frequency field.
 func extractFrequencySeconds(expr ast.Expr) int {
 	// Handle trigger.Minutes(5), trigger.Seconds(30)
@@ -272,6 +522,67 @@ func extractRecipientCount(expr ast.Expr) int {
 	return len(comp.Elts)
 }
 
+// recipientFuncs maps trigger recipient constructors to recipient types.
+var recipientFuncs = map[string]string{
+	"SlackChannel":     "slack",
+	"PagerDutyService": "pagerduty",
+	"EmailAddress":     "email",
+	"WebhookURL":       "webhook",
+}
+
+// recipientTypes maps trigger recipient type constants to recipient types.
+var recipientTypes = map[string]string{
+	"Slack":     "slack",
+	"PagerDuty": "pagerduty",
+	"Email":     "email",
+	"Webhook":   "webhook",
+}
+
+// extractRecipients extracts recipients from a Recipients field, such as
+// trigger.SlackChannel("#alerts") or trigger.Recipient{Type: trigger.Slack,
+// Target: "#alerts"}. Recipients whose target is not a string literal are
+// skipped.
+func extractRecipients(expr ast.Expr) []DiscoveredRecipient {
+	comp, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return nil
+	}
+
+	var recipients []DiscoveredRecipient
+	for _, elt := range comp.Elts {
+		switch e := elt.(type) {
+		case *ast.CallExpr:
+			sel, ok := e.Fun.(*ast.SelectorExpr)
+			if !ok || len(e.Args) != 1 {
+				continue
+			}
+			typ, ok := recipientFuncs[sel.Sel.Name]
+			if !ok {
+				continue
+			}
+			if target := extractStringLiteral(e.Args[0]); target != "" {
+				recipients = append(recipients, DiscoveredRecipient{Type: typ, Target: target})
+			}
+		case *ast.CompositeLit:
+			var r DiscoveredRecipient
+			if t := extractFieldValue(e, "Type"); t != nil {
+				if sel, ok := t.(*ast.SelectorExpr); ok {
+					r.Type = recipientTypes[sel.Sel.Name]
+				} else {
+					r.Type = extractStringLiteral(t)
+				}
+			}
+			if target := extractFieldValue(e, "Target"); target != nil {
+				r.Target = extractStringLiteral(target)
+			}
+			if r.Type != "" && r.Target != "" {
+				recipients = append(recipients, r)
+			}
+		}
+	}
+	return recipients
+}
+
 // extractBoolLiteral extracts a bool value from an expression.
 func extractBoolLiteral(expr ast.Expr) bool {
 	if ident, ok := expr.(*ast.Ident); ok {
@@ -98,6 +98,8 @@ var HighLatencyAlert = trigger.Trigger{
 		trigger.SlackChannel("#alerts"),
 		trigger.PagerDutyService("api-team"),
 		trigger.EmailAddress("team@example.com"),
+		trigger.Recipient{Type: trigger.Webhook, Target: "https://hooks.example.com/${TEAM}"},
+		trigger.SlackChannel(channel),
 	},
 }
 `
@@ -109,7 +111,15 @@ var HighLatencyAlert = trigger.Trigger{
 	require.Len(t, triggers, 1)
 
 	tr := triggers[0]
-	assert.Equal(t, 3, tr.RecipientCount)
+	assert.Equal(t, 5, tr.RecipientCount)
+
+	// The recipient with a non-literal target is not resolved
+	assert.Equal(t, []DiscoveredRecipient{
+		{Type: "slack", Target: "#alerts"},
+		{Type: "pagerduty", Target: "api-team"},
+		{Type: "email", Target: "team@example.com"},
+		{Type: "webhook", Target: "https://hooks.example.com/${TEAM}"},
+	}, tr.Recipients)
 }
 
 func TestDiscoverTriggers_Disabled(t *testing.T) {
@@ -164,3 +174,180 @@ var SlowRequests = query.Query{
 	require.NoError(t, err)
 	assert.Empty(t, triggers)
 }
+
+func TestDiscoverTriggers_InlineQuery(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "triggers.go")
+
+	content := `package triggers
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var InlineAlert = trigger.Trigger{
+	Name:    "Inline",
+	Dataset: "production",
+	Query: query.Query{
+		Dataset:      "production",
+		TimeRange:    query.Minutes(15),
+		Calculations: []query.Calculation{query.P99("duration_ms")},
+	},
+	Threshold: trigger.GreaterThan(500),
+}
+`
+	err := os.WriteFile(testFile, []byte(content), 0644)
+	require.NoError(t, err)
+
+	triggers, err := DiscoverTriggers(dir)
+	require.NoError(t, err)
+	require.Len(t, triggers, 1)
+
+	tr := triggers[0]
+	assert.Empty(t, tr.QueryRef)
+	require.NotNil(t, tr.InlineQuery)
+	assert.Equal(t, "production", tr.InlineQuery.Dataset)
+	assert.Equal(t, 900, tr.InlineQuery.TimeRange.TimeRange)
+	require.Len(t, tr.InlineQuery.Calculations, 1)
+	assert.Equal(t, "P99", tr.InlineQuery.Calculations[0].Op)
+}
+
+func TestDiscoverTriggers_ThresholdOps(t *testing.T) {
+	dir := t.TempDir()
+	content := `package triggers
+
+import "github.com/lex00/wetwire-honeycomb-go/trigger"
+
+var AtLeast = trigger.Trigger{Name: "a", Threshold: trigger.GreaterThanOrEqual(100)}
+var Below = trigger.Trigger{Name: "b", Threshold: trigger.LessThan(0.5)}
+var AtMost = trigger.Trigger{Name: "c", Threshold: trigger.LessThanOrEqual(-10)}
+var Literal = trigger.Trigger{Name: "d", Threshold: trigger.Threshold{Op: trigger.GTE, Value: 5}}
+var StringOp = trigger.Trigger{Name: "e", Threshold: trigger.Threshold{Op: "<", Value: 2.5}}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "triggers.go"), []byte(content), 0644))
+
+	triggers, err := DiscoverTriggers(dir)
+	require.NoError(t, err)
+
+	type threshold struct {
+		op    string
+		value float64
+	}
+	got := make(map[string]threshold)
+	for _, tr := range triggers {
+		got[tr.Name] = threshold{tr.ThresholdOp, tr.ThresholdValue}
+	}
+	assert.Equal(t, map[string]threshold{
+		"AtLeast":  {">=", 100},
+		"Below":    {"<", 0.5},
+		"AtMost":   {"<=", -10},
+		"Literal":  {">=", 5},
+		"StringOp": {"<", 2.5},
+	}, got)
+}
+
+func TestDiscoverTriggers_AlertType(t *testing.T) {
+	dir := t.TempDir()
+	content := `package triggers
+
+import "github.com/lex00/wetwire-honeycomb-go/trigger"
+
+var Change = trigger.Trigger{Name: "a", AlertType: trigger.OnChange}
+var True = trigger.Trigger{Name: "b", AlertType: trigger.OnTrue}
+var Literal = trigger.Trigger{Name: "c", AlertType: "on_true"}
+var Default = trigger.Trigger{Name: "d"}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "triggers.go"), []byte(content), 0644))
+
+	triggers, err := DiscoverTriggers(dir)
+	require.NoError(t, err)
+
+	got := make(map[string]string)
+	for _, tr := range triggers {
+		got[tr.Name] = tr.AlertType
+	}
+	assert.Equal(t, map[string]string{"Change": "on_change", "True": "on_true", "Literal": "on_true", "Default": ""}, got)
+}
+
+func TestDiscoveredTrigger_Query(t *testing.T) {
+	queries := []DiscoveredQuery{
+		{Name: "Latency", Package: "shared", Dataset: "shared"},
+		{Name: "Latency", Package: "alerts", Dataset: "local"},
+		{Name: "Errors", Package: "queries", Dataset: "api"},
+	}
+
+	inline := &DiscoveredQuery{Dataset: "inline"}
+	assert.Same(t, inline, DiscoveredTrigger{InlineQuery: inline, QueryRef: "Errors"}.Query(queries))
+
+	q := DiscoveredTrigger{Package: "alerts", QueryRef: "Latency"}.Query(queries)
+	require.NotNil(t, q)
+	assert.Equal(t, "local", q.Dataset, "queries of the trigger's package are preferred")
+
+	q = DiscoveredTrigger{Package: "alerts", QueryRef: "Errors"}.Query(queries)
+	require.NotNil(t, q)
+	assert.Equal(t, "api", q.Dataset)
+
+	assert.Nil(t, DiscoveredTrigger{QueryRef: "Missing"}.Query(queries))
+	assert.Nil(t, DiscoveredTrigger{}.Query(queries))
+}
+
+func TestDiscoverTriggers_Escalation(t *testing.T) {
+	dir := t.TempDir()
+	testFile := filepath.Join(dir, "triggers.go")
+
+	content := `package triggers
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var HighLatency = trigger.Escalation{
+	Name:    "High Latency",
+	Dataset: "api",
+	Query: query.Query{
+		Dataset:   "api",
+		TimeRange: query.Minutes(15),
+	},
+	Frequency: trigger.Minutes(5),
+	Tags:      []trigger.Tag{{Key: "team", Value: "api"}},
+	Levels: []trigger.Level{
+		{Name: "warning", Threshold: trigger.GreaterThan(500), Recipients: []trigger.Recipient{trigger.SlackChannel("#alerts")}},
+		{
+			Name:       "p1-page",
+			Threshold:  trigger.GreaterThan(1000),
+			Recipients: []trigger.Recipient{trigger.PagerDutyService("key")},
+		},
+	},
+}
+`
+	require.NoError(t, os.WriteFile(testFile, []byte(content), 0644))
+
+	triggers, err := DiscoverTriggers(dir)
+	require.NoError(t, err)
+	require.Len(t, triggers, 2)
+
+	warning, page := triggers[0], triggers[1]
+	assert.Equal(t, "HighLatencyWarning", warning.Name)
+	assert.Equal(t, "High Latency (warning)", warning.TriggerName)
+	assert.Equal(t, 18, warning.Line)
+	assert.Equal(t, 500.0, warning.ThresholdValue)
+	assert.Equal(t, []DiscoveredRecipient{{Type: "slack", Target: "#alerts"}}, warning.Recipients)
+
+	assert.Equal(t, "HighLatencyP1Page", page.Name)
+	assert.Equal(t, "High Latency (p1-page)", page.TriggerName)
+	assert.Equal(t, 19, page.Line)
+	assert.Equal(t, 1000.0, page.ThresholdValue)
+	assert.Equal(t, []DiscoveredRecipient{{Type: "pagerduty", Target: "key"}}, page.Recipients)
+	assert.Equal(t, []DiscoveredTag{{Key: "severity", Value: "p1-page"}, {Key: "team", Value: "api"}}, page.Tags)
+
+	for _, tr := range triggers {
+		assert.Equal(t, "HighLatency", tr.Escalation)
+		assert.Equal(t, "api", tr.Dataset)
+		assert.Equal(t, 300, tr.FrequencySeconds)
+		// The inline query is discovered as a query named after the escalation
+		assert.Equal(t, "HighLatency", tr.QueryRef)
+		assert.Nil(t, tr.InlineQuery)
+	}
+}
@@ -0,0 +1,339 @@
+package discovery
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// UnsupportedDeclaration is a resource literal discovery cannot extract,
+// because it is built by code that only runs when the program does: in an
+// init function, in a loop, or in a fleet.Each template that cannot be
+// expanded. Build leaves such resources out; lint reports them (WHC066).
+type UnsupportedDeclaration struct {
+	// Kind is the resource type of the literal ("query", "trigger", "SLO",
+	// "board" or "maintenance window"), or "fleet.Each template"
+	Kind string
+
+	// Name is the variable the resource is declared or stored in, when known
+	Name string
+
+	// Package is the package name where the literal is written
+	Package string
+
+	// File is the absolute path to the file containing the literal
+	File string
+
+	// Line is the line number of the literal
+	Line int
+
+	// Context says where the literal is, e.g. "in a loop in init()"
+	Context string
+
+	// Reason says why it cannot be discovered
+	Reason string
+}
+
+// String describes the declaration and why build leaves it out, e.g.
+// "query Checkout in init() is not discovered: discovery reads declarations
+// without running code".
+func (u UnsupportedDeclaration) String() string {
+	subject := u.Kind
+	if u.Name != "" {
+		subject += " " + u.Name
+	}
+	if u.Context != "" {
+		subject += " " + u.Context
+	}
+	return subject + " is not discovered: " + u.Reason
+}
+
+// errRuntimeOnly is the Reason of literals built by functions.
+var errRuntimeOnly = errors.New("discovery reads declarations without running code")
+
+// DiscoverUnsupported finds the resource literals in the specified directory
+// that discovery cannot extract. Loops of init functions that discovery
+// unrolls (see initLoopExpansions) are not reported.
+func DiscoverUnsupported(dir string) ([]UnsupportedDeclaration, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("path is not a directory: %s", dir)
+	}
+
+	var discovered []UnsupportedDeclaration
+
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+
+		unsupported, err := extractFile("unsupported declarations", path, discoverUnsupportedInFile)
+		if err != nil {
+			return nil
+		}
+
+		discovered = append(discovered, unsupported...)
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	return discovered, nil
+}
+
+// discoverUnsupportedInFile finds undiscoverable resource literals in a
+// single Go source file.
+func discoverUnsupportedInFile(path string) ([]UnsupportedDeclaration, error) {
+	fset := token.NewFileSet()
+	node, err := parser.ParseFile(fset, path, nil, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		absPath = path
+	}
+	pkg := node.Name.Name
+
+	var found []UnsupportedDeclaration
+	for _, decl := range node.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			if decl.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				valueSpec, ok := spec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+				if _, isFleet, err := expandFleet(valueSpec, fset, absPath); isFleet && err != nil {
+					found = append(found, UnsupportedDeclaration{
+						Kind:    "fleet.Each template",
+						Name:    getIdentifierName(valueSpec),
+						Package: pkg,
+						File:    absPath,
+						Line:    fset.Position(valueSpec.Pos()).Line,
+						Reason:  err.Error(),
+					})
+				}
+			}
+
+		case *ast.FuncDecl:
+			if decl.Body != nil {
+				found = append(found, unsupportedInFunction(decl, fset, absPath, pkg)...)
+			}
+		}
+	}
+	return found, nil
+}
+
+// unsupportedInFunction returns the resource literals of an init function,
+// and those in the loops of any function, except the ones discovered: the
+// literals of variable declarations, of queries returned by exported
+// functions and of the loops initLoopExpansions unrolls. Literals nested in a reported one are not reported again.
+func unsupportedInFunction(decl *ast.FuncDecl, fset *token.FileSet, file, pkg string) []UnsupportedDeclaration {
+	isInit := decl.Recv == nil && decl.Name.Name == "init"
+	funcName := decl.Name.Name + "()"
+
+	var found []UnsupportedDeclaration
+	var stack []ast.Node
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+
+		if rs, ok := n.(*ast.RangeStmt); ok && isInit {
+			if _, _, _, _, err := unrollableLoop(rs, file); err == nil {
+				return false
+			} else if !errors.Is(err, errNotUnrollable) {
+				found = append(found, UnsupportedDeclaration{
+					Kind:    "loop",
+					Package: pkg,
+					File:    file,
+					Line:    fset.Position(rs.Pos()).Line,
+					Context: "in " + funcName,
+					Reason:  err.Error(),
+				})
+				return false
+			}
+		}
+
+		if comp, ok := n.(*ast.CompositeLit); ok {
+			if kind := resourceKind(comp); kind != "" {
+				inLoop, declared := false, false
+				for _, parent := range stack {
+					switch parent.(type) {
+					case *ast.ForStmt, *ast.RangeStmt:
+						inLoop = true
+					case *ast.ValueSpec:
+						declared = true
+					case *ast.ReturnStmt:
+						// Queries returned by exported functions are discovered
+						declared = declared || kind == "query" && isExportedName(decl.Name.Name)
+					}
+				}
+				if !declared && (isInit || inLoop) {
+					context := "in " + funcName
+					if inLoop {
+						context = "in a loop " + context
+					}
+					found = append(found, UnsupportedDeclaration{
+						Kind:    kind,
+						Name:    assignedName(stack),
+						Package: pkg,
+						File:    file,
+						Line:    fset.Position(comp.Pos()).Line,
+						Context: context,
+						Reason:  errRuntimeOnly.Error(),
+					})
+					return false
+				}
+			}
+		}
+
+		stack = append(stack, n)
+		return true
+	})
+	return found
+}
+
+// resourceKind returns the resource type of a composite literal, or "" when
+// it is not a resource.
+func resourceKind(comp *ast.CompositeLit) string {
+	switch {
+	case isQueryCompositeLit(comp):
+		return "query"
+	case isTriggerType(comp.Type), isEscalationType(comp.Type):
+		return "trigger"
+	case isSLOType(comp.Type):
+		return "SLO"
+	case isBoardType(comp.Type):
+		return "board"
+	case isMuteType(comp.Type):
+		return "maintenance window"
+	}
+	return ""
+}
+
+// isEscalationType checks if a type expression refers to trigger.Escalation.
+func isEscalationType(expr ast.Expr) bool {
+	if sel, ok := expr.(*ast.SelectorExpr); ok {
+		if ident, ok := sel.X.(*ast.Ident); ok {
+			return ident.Name == "trigger" && sel.Sel.Name == "Escalation"
+		}
+	}
+	return false
+}
+
+// assignedName returns the variable a literal is assigned to, or stored in
+// by index, from the nodes enclosing it, or "" when it is not assigned.
+func assignedName(stack []ast.Node) string {
+	if len(stack) == 0 {
+		return ""
+	}
+	assign, ok := stack[len(stack)-1].(*ast.AssignStmt)
+	if !ok || len(assign.Lhs) != 1 {
+		return ""
+	}
+	lhs := assign.Lhs[0]
+	if index, ok := lhs.(*ast.IndexExpr); ok {
+		lhs = index.X
+	}
+	if ident, ok := lhs.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+// errNotUnrollable is returned by unrollableLoop for a loop that does not
+// have the shape of one discovery unrolls.
+var errNotUnrollable = errors.New("loop is not a range over services assigning a map entry")
+
+// unrollableLoop matches a loop of an init function that discovery unrolls:
+//
+//	for _, service := range Services {
+//		Latency[service] = query.Query{...}
+//	}
+//
+// a range over a []string literal, or a package-level variable holding one,
+// whose body only assigns a resource literal to the entry of a map indexed
+// by the loop variable. It returns the map name, the loop variable, the
+// services and the literal. A loop of that shape whose services cannot be
+// resolved returns an error other than errNotUnrollable.
+func unrollableLoop(rs *ast.RangeStmt, file string) (string, string, []string, ast.Expr, error) {
+	param, ok := rs.Value.(*ast.Ident)
+	if !ok || len(rs.Body.List) != 1 {
+		return "", "", nil, nil, errNotUnrollable
+	}
+	if key, ok := rs.Key.(*ast.Ident); !ok || key.Name != "_" {
+		return "", "", nil, nil, errNotUnrollable
+	}
+	assign, ok := rs.Body.List[0].(*ast.AssignStmt)
+	if !ok || assign.Tok != token.ASSIGN || len(assign.Lhs) != 1 || len(assign.Rhs) != 1 {
+		return "", "", nil, nil, errNotUnrollable
+	}
+	index, ok := assign.Lhs[0].(*ast.IndexExpr)
+	if !ok {
+		return "", "", nil, nil, errNotUnrollable
+	}
+	mapName, ok := index.X.(*ast.Ident)
+	if !ok {
+		return "", "", nil, nil, errNotUnrollable
+	}
+	if key, ok := index.Index.(*ast.Ident); !ok || key.Name != param.Name {
+		return "", "", nil, nil, errNotUnrollable
+	}
+	comp, ok := assign.Rhs[0].(*ast.CompositeLit)
+	if !ok || resourceKind(comp) == "" {
+		return "", "", nil, nil, errNotUnrollable
+	}
+
+	services, ok := fleetServices(rs.X, file)
+	if !ok {
+		return "", "", nil, nil, errors.New("the loop does not range over a []string literal or a package-level variable holding one, so it cannot be unrolled")
+	}
+	return mapName.Name, param.Name, services, comp, nil
+}
+
+// initLoopExpansions returns the resources of the loops of the init
+// functions of a file that discovery unrolls (see unrollableLoop), one per
+// service, named as fleet.Each resources are.
+func initLoopExpansions(node *ast.File, fset *token.FileSet, file string) []fleetExpansion {
+	var expansions []fleetExpansion
+	for _, decl := range node.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv != nil || fn.Name.Name != "init" || fn.Body == nil {
+			continue
+		}
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			rs, ok := n.(*ast.RangeStmt)
+			if !ok {
+				return true
+			}
+			name, param, services, result, err := unrollableLoop(rs, file)
+			if err == nil {
+				line := fset.Position(rs.Body.List[0].Pos()).Line
+				expansions = append(expansions, expandTemplate(name, services, param, result, fset, file, line)...)
+			}
+			return false
+		})
+	}
+	return expansions
+}
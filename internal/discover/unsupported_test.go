@@ -0,0 +1,111 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverAll_InitLoop(t *testing.T) {
+	dir := t.TempDir()
+
+	content := `package alerts
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Services = []string{"checkout", "cart"}
+
+var Latency = map[string]query.Query{}
+
+func init() {
+	for _, service := range Services {
+		Latency[service] = query.Query{
+			Dataset: "production",
+			Filters: []query.Filter{query.Equals("service.name", service)},
+		}
+	}
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "alerts.go"), []byte(content), 0644))
+
+	resources, err := DiscoverAll(dir)
+	require.NoError(t, err)
+
+	require.Len(t, resources.Queries, 2)
+	assert.Equal(t, "CheckoutLatency", resources.Queries[0].Name)
+	assert.Equal(t, 11, resources.Queries[0].Line)
+	assert.Equal(t, "checkout", resources.Queries[0].Filters[0].Value)
+	assert.Equal(t, "CartLatency", resources.Queries[1].Name)
+	assert.Equal(t, "cart", resources.Queries[1].Filters[0].Value)
+	assert.Empty(t, resources.Unsupported)
+}
+
+func TestDiscoverUnsupported(t *testing.T) {
+	dir := t.TempDir()
+
+	content := `package alerts
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/fleet"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var Checkout query.Query
+
+var Latency = map[string]trigger.Trigger{}
+
+var Unresolved = fleet.Each(services(), func(service string) query.Query {
+	return query.Query{Dataset: service}
+})
+
+func init() {
+	Checkout = query.Query{Dataset: "checkout"}
+
+	for _, service := range services() {
+		Latency[service] = trigger.Trigger{Name: service}
+	}
+}
+
+func Register(services []string) {
+	for _, service := range services {
+		register(trigger.Trigger{Name: service, Query: query.Query{Dataset: service}})
+	}
+}
+
+func Errors() query.Query {
+	for {
+		return query.Query{Dataset: "production"}
+	}
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "alerts.go"), []byte(content), 0644))
+
+	unsupported, err := DiscoverUnsupported(dir)
+	require.NoError(t, err)
+
+	require.Len(t, unsupported, 4)
+	assert.Equal(t, "fleet.Each template", unsupported[0].Kind)
+	assert.Equal(t, "Unresolved", unsupported[0].Name)
+	assert.Equal(t, 13, unsupported[0].Line)
+	assert.Contains(t, unsupported[0].Reason, "service list")
+
+	assert.Equal(t, "query", unsupported[1].Kind)
+	assert.Equal(t, "Checkout", unsupported[1].Name)
+	assert.Equal(t, 18, unsupported[1].Line)
+	assert.Equal(t, "in init()", unsupported[1].Context)
+	assert.Equal(t, "alerts", unsupported[1].Package)
+	assert.Equal(t, "query Checkout in init() is not discovered: discovery reads declarations without running code", unsupported[1].String())
+
+	assert.Equal(t, "loop", unsupported[2].Kind)
+	assert.Equal(t, 20, unsupported[2].Line)
+	assert.Contains(t, unsupported[2].Reason, "cannot be unrolled")
+
+	// The literal nested in the trigger is not reported again
+	assert.Equal(t, "trigger", unsupported[3].Kind)
+	assert.Equal(t, 27, unsupported[3].Line)
+	assert.Equal(t, "in a loop in Register()", unsupported[3].Context)
+}
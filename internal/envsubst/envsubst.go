@@ -0,0 +1,62 @@
+// Package envsubst expands ${VAR} environment variable references in resource
+// fields such as datasets and recipient targets, so the same declarations can
+// be built for different teams or environments.
+package envsubst
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// reference matches ${...} with any content, so malformed names are reported
+// rather than silently left in place.
+var reference = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// validName matches environment variable names.
+var validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Contains reports whether s appears to use interpolation.
+func Contains(s string) bool {
+	return strings.Contains(s, "${")
+}
+
+// References returns the variable names referenced in s, in order. Empty or
+// invalid names and unterminated references are returned as an error.
+func References(s string) ([]string, error) {
+	var names []string
+	for _, m := range reference.FindAllStringSubmatch(s, -1) {
+		if !validName.MatchString(m[1]) {
+			return nil, fmt.Errorf("invalid variable name %q in %q", m[1], m[0])
+		}
+		names = append(names, m[1])
+	}
+
+	if rest := reference.ReplaceAllString(s, ""); strings.Contains(rest, "${") {
+		return nil, fmt.Errorf("unterminated ${ in %q", s)
+	}
+	return names, nil
+}
+
+// Expand replaces each ${VAR} in s with its value from lookup. Variables
+// lookup does not find are returned as missing, and s is returned unchanged.
+func Expand(s string, lookup func(string) (string, bool)) (string, []string, error) {
+	names, err := References(s)
+	if err != nil || len(names) == 0 {
+		return s, nil, err
+	}
+
+	var missing []string
+	expanded := reference.ReplaceAllStringFunc(s, func(m string) string {
+		name := m[2 : len(m)-1]
+		value, ok := lookup(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return s, missing, nil
+	}
+	return expanded, nil, nil
+}
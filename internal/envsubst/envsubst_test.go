@@ -0,0 +1,62 @@
+package envsubst
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lookup(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+}
+
+func TestReferences(t *testing.T) {
+	names, err := References("#alerts-${TEAM}-${ENV}")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TEAM", "ENV"}, names)
+
+	names, err = References("production")
+	require.NoError(t, err)
+	assert.Empty(t, names)
+
+	_, err = References("${}")
+	assert.Error(t, err)
+
+	_, err = References("${team-name}")
+	assert.Error(t, err)
+
+	_, err = References("#alerts-${TEAM")
+	assert.Error(t, err)
+}
+
+func TestExpand(t *testing.T) {
+	env := lookup(map[string]string{"TEAM": "payments", "EMPTY": ""})
+
+	s, missing, err := Expand("#alerts-${TEAM}", env)
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+	assert.Equal(t, "#alerts-payments", s)
+
+	s, _, err = Expand("api${EMPTY}", env)
+	require.NoError(t, err)
+	assert.Equal(t, "api", s)
+
+	s, missing, err = Expand("${TEAM}-${REGION}", env)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"REGION"}, missing)
+	assert.Equal(t, "${TEAM}-${REGION}", s)
+
+	s, missing, err = Expand("no references", env)
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+	assert.Equal(t, "no references", s)
+}
+
+func TestContains(t *testing.T) {
+	assert.True(t, Contains("${TEAM}"))
+	assert.False(t, Contains("$TEAM"))
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Features are the optional constructs a generated package can use.
+var Features = []string{
+	"filters",     // query filters
+	"breakdowns",  // query breakdowns
+	"orders",      // query orders and limits
+	"sections",    // board sections grouping panels
+	"tags",        // board and trigger tags
+	"burn-alerts", // SLO burn alerts
+	"recipients",  // trigger and burn alert recipients
+	"inline",      // trigger queries declared inline instead of by reference
+}
+
+// Config sizes a generated package.
+type Config struct {
+	// Package is the Go package name
+	Package string
+
+	// Queries, Boards, SLOs and Triggers are the number of each resource
+	Queries  int
+	Boards   int
+	SLOs     int
+	Triggers int
+
+	// Datasets is the number of datasets the queries are spread over
+	Datasets int
+
+	// PanelsPerBoard is the number of query panels on each board
+	PanelsPerBoard int
+
+	// PerFile is the number of declarations in each file; 0 puts each kind
+	// of resource in one file
+	PerFile int
+
+	// Features are the enabled Features
+	Features []string
+
+	// Seed makes the output reproducible: the same Config gives the same
+	// files
+	Seed int64
+}
+
+// has reports whether the feature is enabled.
+func (c Config) has(feature string) bool {
+	return slices.Contains(c.Features, feature)
+}
+
+// validate checks the sizes and features.
+func (c Config) validate() error {
+	if c.Package == "" {
+		return fmt.Errorf("package name is required")
+	}
+	for name, n := range map[string]int{"queries": c.Queries, "boards": c.Boards, "slos": c.SLOs, "triggers": c.Triggers, "per-file": c.PerFile} {
+		if n < 0 {
+			return fmt.Errorf("%s must not be negative", name)
+		}
+	}
+	if c.Datasets <= 0 {
+		return fmt.Errorf("datasets must be positive")
+	}
+	if c.Queries == 0 && (c.Boards > 0 || c.SLOs > 0) {
+		return fmt.Errorf("boards and SLOs need queries to reference")
+	}
+	for _, f := range c.Features {
+		if !slices.Contains(Features, f) {
+			return fmt.Errorf("unknown feature %q: expected one of %s", f, strings.Join(Features, ", "))
+		}
+	}
+	return nil
+}
+
+// Generation pools; values are picked from them at random.
+var (
+	services     = []string{"api", "checkout", "cart", "search", "auth", "payments", "inventory", "shipping"}
+	routes       = []string{"/", "/login", "/cart", "/checkout", "/search", "/orders/:id", "/items/:id"}
+	numericCols  = []string{"duration_ms", "db.duration_ms", "response.size", "retries"}
+	stringCols   = []string{"service.name", "http.route", "http.method", "region", "deployment.version"}
+	percentiles  = []string{"P50", "P90", "P95", "P99"}
+	aggregations = []string{"Avg", "Max", "Sum"}
+	thresholdOps = []string{"GreaterThan", "GreaterThanOrEqual", "LessThan"}
+	channels     = []string{"#alerts", "#oncall", "#sre", "#payments"}
+)
+
+// generator holds the state of one Generate run.
+type generator struct {
+	cfg     Config
+	rand    *rand.Rand
+	queries []string
+}
+
+// Generate returns the files of a synthetic package, keyed by file name.
+func Generate(cfg Config) (map[string][]byte, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	g := &generator{cfg: cfg, rand: rand.New(rand.NewSource(cfg.Seed))}
+
+	files := make(map[string][]byte)
+	kinds := []struct {
+		name    string
+		count   int
+		imports []string
+		decl    func(b *bytes.Buffer, i int)
+	}{
+		{"queries", cfg.Queries, []string{"query"}, g.query},
+		{"boards", cfg.Boards, []string{"board"}, g.board},
+		{"slos", cfg.SLOs, []string{"slo"}, g.slo},
+		{"triggers", cfg.Triggers, []string{"query", "trigger"}, g.trigger},
+	}
+	for _, kind := range kinds {
+		perFile := cfg.PerFile
+		if perFile == 0 {
+			perFile = max(kind.count, 1)
+		}
+		for start := 0; start < kind.count; start += perFile {
+			var b bytes.Buffer
+			fmt.Fprintf(&b, "// Code generated by gentestdata. DO NOT EDIT.\n\npackage %s\n\nimport (\n", cfg.Package)
+			for _, imp := range kind.imports {
+				fmt.Fprintf(&b, "%q\n", "github.com/lex00/wetwire-honeycomb-go/"+imp)
+			}
+			b.WriteString(")\n")
+			for i := start; i < min(start+perFile, kind.count); i++ {
+				kind.decl(&b, i)
+			}
+
+			code, err := format.Source(b.Bytes())
+			if err != nil {
+				return nil, fmt.Errorf("format %s: %w", kind.name, err)
+			}
+			name := kind.name + ".go"
+			if kind.count > perFile {
+				name = fmt.Sprintf("%s_%03d.go", kind.name, start/perFile)
+			}
+			files[name] = code
+		}
+	}
+	return files, nil
+}
+
+// pick returns a random element of values.
+func pick[T any](g *generator, values []T) T {
+	return values[g.rand.Intn(len(values))]
+}
+
+// dataset returns the dataset of the i-th query.
+func (g *generator) dataset(i int) string {
+	return fmt.Sprintf("dataset-%02d", i%g.cfg.Datasets)
+}
+
+// queryName returns a random declared query.
+func (g *generator) queryName() string {
+	return pick(g, g.queries)
+}
+
+// query writes the i-th query declaration.
+func (g *generator) query(b *bytes.Buffer, i int) {
+	name := fmt.Sprintf("Query%04d", i)
+	g.queries = append(g.queries, name)
+	fmt.Fprintf(b, "\n// %s is a generated %s query.\nvar %s = ", name, g.dataset(i), name)
+	g.queryLiteral(b, g.dataset(i), fmt.Sprintf("query.Hours(%d)", pick(g, []int{1, 2, 6, 24})))
+	b.WriteString("\n")
+}
+
+// queryLiteral writes a query.Query literal on dataset over timeRange, a
+// query.TimeRange expression.
+func (g *generator) queryLiteral(b *bytes.Buffer, dataset, timeRange string) {
+	fmt.Fprintf(b, "query.Query{\nDataset: %q,\nTimeRange: %s,\n", dataset, timeRange)
+
+	b.WriteString("Calculations: []query.Calculation{\nquery.Count(),\n")
+	if g.rand.Intn(2) == 0 {
+		fmt.Fprintf(b, "query.%s(%q),\n", pick(g, percentiles), pick(g, numericCols))
+	} else {
+		fmt.Fprintf(b, "query.%s(%q),\n", pick(g, aggregations), pick(g, numericCols))
+	}
+	b.WriteString("},\n")
+
+	if g.cfg.has("filters") {
+		b.WriteString("Filters: []query.Filter{\n")
+		fmt.Fprintf(b, "query.Equals(\"service.name\", %q),\n", pick(g, services))
+		if g.rand.Intn(2) == 0 {
+			fmt.Fprintf(b, "query.GTE(%q, %d),\n", pick(g, numericCols), 100*(1+g.rand.Intn(10)))
+		}
+		if g.rand.Intn(3) == 0 {
+			fmt.Fprintf(b, "query.In(\"http.route\", []any{%q, %q}),\n", pick(g, routes), pick(g, routes))
+		}
+		b.WriteString("},\n")
+	}
+	if g.cfg.has("breakdowns") {
+		columns := slices.Clone(stringCols)
+		g.rand.Shuffle(len(columns), func(i, j int) { columns[i], columns[j] = columns[j], columns[i] })
+		columns = columns[:1+g.rand.Intn(2)]
+		sort.Strings(columns)
+		fmt.Fprintf(b, "Breakdowns: %#v,\n", columns)
+	}
+	if g.cfg.has("orders") {
+		b.WriteString("Orders: []query.Order{{Op: \"COUNT\", Order: \"descending\"}},\n")
+		fmt.Fprintf(b, "Limit: %d,\n", pick(g, []int{10, 50, 100}))
+	}
+	b.WriteString("}")
+}
+
+// board writes the i-th board declaration.
+func (g *generator) board(b *bytes.Buffer, i int) {
+	name := fmt.Sprintf("Board%04d", i)
+	fmt.Fprintf(b, "\n// %s is a generated board.\nvar %s = board.Board{\nName: %q,\n", name, name, fmt.Sprintf("Generated board %d", i))
+
+	panels := make([]string, g.cfg.PanelsPerBoard)
+	for p := range panels {
+		q := g.queryName()
+		panels[p] = fmt.Sprintf("board.QueryPanel(%s, board.WithTitle(%q))", q, q)
+	}
+	b.WriteString("Panels: []board.Panel{\n")
+	if g.cfg.has("sections") && len(panels) > 1 {
+		half := len(panels) / 2
+		fmt.Fprintf(b, "board.Section(\"Overview\",\n%s,\n),\n", strings.Join(panels[:half], ",\n"))
+		fmt.Fprintf(b, "board.Section(\"Details\",\n%s,\n),\n", strings.Join(panels[half:], ",\n"))
+	} else {
+		for _, p := range panels {
+			b.WriteString(p + ",\n")
+		}
+	}
+	b.WriteString("},\n")
+
+	if g.cfg.has("tags") {
+		fmt.Fprintf(b, "Tags: []board.Tag{{Key: \"team\", Value: %q}},\n", pick(g, services))
+	}
+	b.WriteString("}\n")
+}
+
+// slo writes the i-th SLO declaration.
+func (g *generator) slo(b *bytes.Buffer, i int) {
+	name := fmt.Sprintf("SLO%04d", i)
+
+	// Good and total events come from the same dataset
+	good := g.rand.Intn(len(g.queries))
+	dataset := good % g.cfg.Datasets
+	total := dataset + g.cfg.Datasets*g.rand.Intn((len(g.queries)-dataset+g.cfg.Datasets-1)/g.cfg.Datasets)
+	fmt.Fprintf(b, "\n// %s is a generated SLO.\nvar %s = slo.SLO{\nName: %q,\nDataset: %q,\n", name, name, fmt.Sprintf("Generated SLO %d", i), g.dataset(good))
+	fmt.Fprintf(b, "SLI: slo.SLI{\nGoodEvents: %s,\nTotalEvents: %s,\n},\n", g.queries[good], g.queries[total])
+	fmt.Fprintf(b, "Target: slo.Percentage(%s),\nTimePeriod: slo.Days(%d),\n", pick(g, []string{"99", "99.5", "99.9", "99.95"}), pick(g, []int{7, 14, 30}))
+
+	if g.cfg.has("burn-alerts") {
+		b.WriteString("BurnAlerts: []slo.BurnAlert{\nslo.FastBurn(2),\nslo.SlowBurn(5),\n},\n")
+	}
+	if g.cfg.has("recipients") {
+		fmt.Fprintf(b, "DefaultRecipients: []slo.Recipient{{Type: \"slack\", Target: %q}},\n", pick(g, channels))
+	}
+	b.WriteString("}\n")
+}
+
+// trigger writes the i-th trigger declaration. Its query is declared next to
+// it, or inline, with a duration Honeycomb accepts for the frequency.
+func (g *generator) trigger(b *bytes.Buffer, i int) {
+	name := fmt.Sprintf("Trigger%04d", i)
+	frequency := pick(g, []int{5, 10, 15})
+	timeRange := fmt.Sprintf("query.Minutes(%d)", frequency*pick(g, []int{1, 2, 4}))
+
+	if !g.cfg.has("inline") {
+		fmt.Fprintf(b, "\n// %sQuery is the query of %s.\nvar %sQuery = ", name, name, name)
+		g.queryLiteral(b, g.dataset(i), timeRange)
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(b, "\n// %s is a generated trigger.\nvar %s = trigger.Trigger{\nName: %q,\nDataset: %q,\n", name, name, fmt.Sprintf("Generated trigger %d", i), g.dataset(i))
+	if g.cfg.has("inline") {
+		b.WriteString("Query: ")
+		g.queryLiteral(b, g.dataset(i), timeRange)
+		b.WriteString(",\n")
+	} else {
+		fmt.Fprintf(b, "Query: %sQuery,\n", name)
+	}
+	fmt.Fprintf(b, "Threshold: trigger.%s(%d),\n", pick(g, thresholdOps), 10*(1+g.rand.Intn(50)))
+	fmt.Fprintf(b, "Frequency: trigger.Minutes(%d),\n", frequency)
+
+	if g.cfg.has("recipients") {
+		fmt.Fprintf(b, "Recipients: []trigger.Recipient{trigger.SlackChannel(%q)},\n", pick(g, channels))
+	}
+	if g.cfg.has("tags") {
+		fmt.Fprintf(b, "Tags: []trigger.Tag{{Key: \"team\", Value: %q}},\n", pick(g, services))
+	}
+	b.WriteString("}\n")
+}
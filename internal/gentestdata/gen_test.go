@@ -0,0 +1,126 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+func testConfig() Config {
+	return Config{
+		Package:        "large",
+		Queries:        120,
+		Boards:         6,
+		SLOs:           5,
+		Triggers:       7,
+		Datasets:       4,
+		PanelsPerBoard: 4,
+		PerFile:        50,
+		Features:       Features,
+		Seed:           7,
+	}
+}
+
+func TestGenerate_Discoverable(t *testing.T) {
+	files, err := Generate(testConfig())
+	require.NoError(t, err)
+	assert.Contains(t, files, "queries_002.go")
+	assert.Contains(t, files, "boards.go")
+
+	dir := t.TempDir()
+	for name, code := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), code, 0644))
+	}
+
+	resources, err := discovery.DiscoverAll(dir)
+	require.NoError(t, err)
+	assert.NotEmpty(t, queryDataset(resources, "Query0119"))
+	assert.Len(t, resources.Boards, 6)
+	assert.Len(t, resources.SLOs, 5)
+	assert.Len(t, resources.Triggers, 7)
+
+	for _, s := range resources.SLOs {
+		good, total := queryDataset(resources, s.GoodEventsQueryRef), queryDataset(resources, s.TotalEventsQueryRef)
+		assert.Equal(t, s.Dataset, good, s.Name)
+		assert.Equal(t, s.Dataset, total, s.Name)
+	}
+}
+
+func queryDataset(resources *discovery.DiscoveredResources, name string) string {
+	for _, q := range resources.Queries {
+		if q.Name == name {
+			return q.Dataset
+		}
+	}
+	return ""
+}
+
+func TestGenerate_Reproducible(t *testing.T) {
+	first, err := Generate(testConfig())
+	require.NoError(t, err)
+	second, err := Generate(testConfig())
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+
+	cfg := testConfig()
+	cfg.Seed = 8
+	other, err := Generate(cfg)
+	require.NoError(t, err)
+	assert.NotEqual(t, first["queries_000.go"], other["queries_000.go"])
+}
+
+func TestGenerate_Features(t *testing.T) {
+	cfg := testConfig()
+	cfg.Features = nil
+	cfg.PerFile = 0
+	files, err := Generate(cfg)
+	require.NoError(t, err)
+
+	assert.Len(t, files, 4)
+	assert.NotContains(t, string(files["queries.go"]), "Filters:")
+	assert.NotContains(t, string(files["boards.go"]), "board.Section(")
+	assert.Contains(t, string(files["triggers.go"]), "Query:     Trigger0000Query,")
+
+	cfg.Features = []string{"inline", "sections"}
+	files, err = Generate(cfg)
+	require.NoError(t, err)
+	assert.NotContains(t, string(files["triggers.go"]), "Trigger0000Query")
+	assert.Contains(t, string(files["boards.go"]), "board.Section(")
+}
+
+func TestGenerate_Invalid(t *testing.T) {
+	tests := map[string]func(*Config){
+		"package name":    func(c *Config) { c.Package = "" },
+		"negative":        func(c *Config) { c.Boards = -1 },
+		"datasets":        func(c *Config) { c.Datasets = 0 },
+		"need queries":    func(c *Config) { c.Queries = 0 },
+		"unknown feature": func(c *Config) { c.Features = []string{"havings"} },
+	}
+	for want, change := range tests {
+		cfg := testConfig()
+		change(&cfg)
+		_, err := Generate(cfg)
+		assert.Error(t, err, want)
+	}
+}
+
+func TestRun(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "perf-corpus")
+	require.NoError(t, run(io.Discard, []string{"-out", out, "-queries", "20", "-boards", "2", "-slos", "2", "-triggers", "2"}))
+
+	code, err := os.ReadFile(filepath.Join(out, "queries.go"))
+	require.NoError(t, err)
+	assert.True(t, strings.Contains(string(code), "package perfcorpus"))
+
+	err = run(io.Discard, []string{"-out", out})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "-force")
+	assert.NoError(t, run(io.Discard, []string{"-out", out, "-force"}))
+}
@@ -0,0 +1,111 @@
+// Command gentestdata writes a large synthetic package of queries, boards,
+// SLOs and triggers, of configurable size and feature mix, for benchmarks,
+// fuzzing corpora and manual performance testing of build, lint and watch.
+//
+// Usage:
+//
+//	go run ./internal/gentestdata -out /tmp/large -queries 5000 -boards 200 -slos 100 -triggers 500
+//	go run ./internal/gentestdata -out /tmp/plain -queries 1000 -features filters,breakdowns
+//
+// The same flags and -seed always produce the same files. The output
+// directory must not already contain Go files unless -force is given.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func main() {
+	if err := run(os.Stdout, os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "gentestdata:", err)
+		os.Exit(1)
+	}
+}
+
+// run parses the flags, generates the package, writes it and reports to w.
+func run(w io.Writer, args []string) error {
+	fs := flag.NewFlagSet("gentestdata", flag.ContinueOnError)
+	cfg := Config{}
+	var out, features string
+	var force bool
+	fs.StringVar(&out, "out", "", "Directory to write the package to (required)")
+	fs.StringVar(&cfg.Package, "package", "", "Package name (default: the directory name)")
+	fs.IntVar(&cfg.Queries, "queries", 100, "Number of queries")
+	fs.IntVar(&cfg.Boards, "boards", 10, "Number of boards")
+	fs.IntVar(&cfg.SLOs, "slos", 10, "Number of SLOs")
+	fs.IntVar(&cfg.Triggers, "triggers", 10, "Number of triggers")
+	fs.IntVar(&cfg.Datasets, "datasets", 5, "Number of datasets the queries are spread over")
+	fs.IntVar(&cfg.PanelsPerBoard, "panels", 8, "Query panels per board")
+	fs.IntVar(&cfg.PerFile, "per-file", 100, "Declarations per file (0: one file per resource kind)")
+	fs.StringVar(&features, "features", strings.Join(Features, ","), "Comma-separated features to use: "+strings.Join(Features, ", "))
+	fs.Int64Var(&cfg.Seed, "seed", 1, "Random seed")
+	fs.BoolVar(&force, "force", false, "Write into a directory that already contains Go files")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if out == "" {
+		return fmt.Errorf("-out is required")
+	}
+
+	if cfg.Package == "" {
+		abs, err := filepath.Abs(out)
+		if err != nil {
+			return err
+		}
+		cfg.Package = packageName(filepath.Base(abs))
+	}
+	for _, f := range strings.Split(features, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			cfg.Features = append(cfg.Features, f)
+		}
+	}
+
+	files, err := Generate(cfg)
+	if err != nil {
+		return err
+	}
+
+	if existing, _ := filepath.Glob(filepath.Join(out, "*.go")); len(existing) > 0 && !force {
+		return fmt.Errorf("%s already contains Go files; use -force to write into it", out)
+	}
+	if err := os.MkdirAll(out, 0o755); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(out, name), files[name], 0o644); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(w, "Generated %d queries, %d boards, %d SLOs and %d triggers in %d files in %s\n",
+		cfg.Queries, cfg.Boards, cfg.SLOs, cfg.Triggers, len(files), out)
+	return nil
+}
+
+// packageName derives a Go package name from a directory name.
+func packageName(dir string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		}
+		return -1
+	}, dir)
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		name = "testdata" + name
+	}
+	return name
+}
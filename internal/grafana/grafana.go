@@ -0,0 +1,386 @@
+// Package grafana converts Honeycomb boards into Grafana dashboard JSON for
+// organizations running both systems during a migration, and imports Grafana
+// dashboards back as Honeycomb queries and boards.
+//
+// Query panels become Grafana panels backed by a Honeycomb data source whose
+// targets carry the Honeycomb query JSON unchanged, and sections become rows. Features Grafana cannot
+// express are approximated or dropped, and each one is recorded as a Note in
+// the mapping report. Import is described at its declaration.
+package grafana
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/internal/serialize"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+// DatasourcePlugin is the Grafana plugin ID of the Honeycomb data source.
+const DatasourcePlugin = "grafana-honeycomb-datasource"
+
+// DatasourceInput is the import variable Grafana prompts for to pick the
+// Honeycomb data source when the dashboard is imported.
+const DatasourceInput = "DS_HONEYCOMB"
+
+// schemaVersion is the Grafana dashboard schema version emitted.
+const schemaVersion = 39
+
+// gridColumns is the width of the Grafana dashboard grid.
+const gridColumns = 24
+
+// Default panel size for panels without an explicit position.
+const (
+	defaultWidth  = 12
+	defaultHeight = 8
+)
+
+// Dashboard is a Grafana dashboard in the import (export for sharing) format.
+type Dashboard struct {
+	Inputs        []Input    `json:"__inputs"`
+	UID           string     `json:"uid"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description,omitempty"`
+	Tags          []string   `json:"tags,omitempty"`
+	Timezone      string     `json:"timezone"`
+	Editable      bool       `json:"editable"`
+	SchemaVersion int        `json:"schemaVersion"`
+	Time          TimeWindow `json:"time"`
+	Panels        []Panel    `json:"panels"`
+}
+
+// Input is a value Grafana asks for when importing a dashboard.
+type Input struct {
+	Name       string `json:"name"`
+	Label      string `json:"label"`
+	Type       string `json:"type"`
+	PluginID   string `json:"pluginId"`
+	PluginName string `json:"pluginName"`
+}
+
+// TimeWindow is a Grafana time range such as now-2h to now.
+type TimeWindow struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Panel is a Grafana dashboard panel.
+type Panel struct {
+	ID         int            `json:"id"`
+	Type       string         `json:"type"`
+	Title      string         `json:"title,omitempty"`
+	GridPos    GridPos        `json:"gridPos"`
+	Datasource *Datasource    `json:"datasource,omitempty"`
+	Targets    []Target       `json:"targets,omitempty"`
+	TimeFrom   string         `json:"timeFrom,omitempty"`
+	Options    map[string]any `json:"options,omitempty"`
+}
+
+// GridPos places a panel on the 24 column dashboard grid.
+type GridPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+// Datasource references a Grafana data source.
+type Datasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+// Target is a Honeycomb data source query.
+type Target struct {
+	RefID      string          `json:"refId"`
+	Datasource *Datasource     `json:"datasource"`
+	Dataset    string          `json:"dataset"`
+	Query      json.RawMessage `json:"query"`
+}
+
+// Note records a board feature that could not be mapped exactly.
+type Note struct {
+	// Board is the board name
+	Board string `json:"board"`
+
+	// Panel is the panel title or position (empty for board-level features)
+	Panel string `json:"panel,omitempty"`
+
+	// Feature names the Honeycomb feature, e.g. "slo panel"
+	Feature string `json:"feature"`
+
+	// Message explains how the feature was handled
+	Message string `json:"message"`
+}
+
+// String formats the note for display.
+func (n Note) String() string {
+	if n.Panel != "" {
+		return fmt.Sprintf("%s / %s: %s: %s", n.Board, n.Panel, n.Feature, n.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", n.Board, n.Feature, n.Message)
+}
+
+// Panel accessor interfaces, matching the unexported board panel types.
+type queryPanelAccessor interface {
+	Query() query.Query
+	Config() board.PanelConfig
+}
+
+type textPanelAccessor interface {
+	Content() string
+	Config() board.PanelConfig
+}
+
+type sloPanelAccessor interface {
+	SLOID() string
+	Config() board.PanelConfig
+}
+
+type sectionPanelAccessor interface {
+	Panels() []board.Panel
+	Config() board.PanelConfig
+}
+
+// Convert maps a board to a Grafana dashboard and reports every feature that
+// was approximated or dropped.
+func Convert(b board.Board) (Dashboard, []Note, error) {
+	c := &converter{board: b}
+	d, err := c.convert()
+	return d, c.notes, err
+}
+
+// converter accumulates the panels and notes of a single board.
+type converter struct {
+	board board.Board
+	notes []Note
+
+	// cursorX and cursorY place panels that have no explicit position
+	cursorX, cursorY int
+	rowHeight        int
+}
+
+func (c *converter) note(panel, feature, format string, args ...any) {
+	c.notes = append(c.notes, Note{
+		Board:   c.board.Name,
+		Panel:   panel,
+		Feature: feature,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (c *converter) convert() (Dashboard, error) {
+	ds := &Datasource{Type: DatasourcePlugin, UID: "${" + DatasourceInput + "}"}
+
+	d := Dashboard{
+		Inputs: []Input{{
+			Name:       DatasourceInput,
+			Label:      "Honeycomb",
+			Type:       "datasource",
+			PluginID:   DatasourcePlugin,
+			PluginName: "Honeycomb",
+		}},
+		UID:           uid(c.board.Name),
+		Title:         c.board.Name,
+		Description:   c.board.Description,
+		Timezone:      "browser",
+		Editable:      true,
+		SchemaVersion: schemaVersion,
+		Time:          TimeWindow{From: "now-2h", To: "now"},
+		Panels:        []Panel{},
+	}
+
+	for _, tag := range c.board.Tags {
+		d.Tags = append(d.Tags, tag.Key+":"+tag.Value)
+	}
+
+	if len(c.board.PresetFilters) > 0 {
+		c.note("", "preset filters", "Grafana has no board-level query filters; %d preset filter(s) were added to every query panel", len(c.board.PresetFilters))
+	}
+
+	panels := flatten(c.board.Panels)
+
+	// The first relative query time range becomes the dashboard time; panels
+	// with a different range override it
+	dashboardRange := 0
+	for _, p := range panels {
+		if qp, ok := p.(queryPanelAccessor); ok && qp.Query().TimeRange.TimeRange > 0 {
+			dashboardRange = qp.Query().TimeRange.TimeRange
+			d.Time.From = "now-" + duration(dashboardRange)
+			break
+		}
+	}
+
+	for i, p := range panels {
+		panel := Panel{ID: i + 1}
+
+		switch p := p.(type) {
+		case queryPanelAccessor:
+			config := p.Config()
+			label := panelLabel(config, i)
+			q := p.Query()
+
+			for _, f := range c.board.PresetFilters {
+				q.Filters = append(q.Filters, query.Filter{Column: f.Column, Op: f.Operation, Value: f.Value})
+			}
+
+			switch {
+			case q.TimeRange.StartTime != 0 || q.TimeRange.EndTime != 0:
+				c.note(label, "absolute time range", "Grafana panels only support relative time overrides; the panel uses the dashboard time range")
+				q.TimeRange = query.TimeRange{}
+			case q.TimeRange.TimeRange > 0 && q.TimeRange.TimeRange != dashboardRange:
+				panel.TimeFrom = duration(q.TimeRange.TimeRange)
+			}
+			// Grafana supplies the time range when the query runs
+			q.TimeRange.TimeRange = 0
+
+			data, err := serialize.ToJSON(q)
+			if err != nil {
+				return Dashboard{}, fmt.Errorf("panel %s: %w", label, err)
+			}
+
+			panel.Type = visualization(q)
+			panel.Title = config.Title
+			panel.Datasource = ds
+			panel.Targets = []Target{{RefID: "A", Datasource: ds, Dataset: q.Dataset, Query: data}}
+			panel.GridPos = c.place(config.Position, label)
+
+		case textPanelAccessor:
+			config := p.Config()
+			panel.Type = "text"
+			panel.Title = config.Title
+			panel.Options = map[string]any{"mode": "markdown", "content": p.Content()}
+			panel.GridPos = c.place(config.Position, panelLabel(config, i))
+
+		case sloPanelAccessor:
+			config := p.Config()
+			label := panelLabel(config, i)
+			c.note(label, "slo panel", "Grafana cannot display Honeycomb SLOs; replaced with a text panel linking SLO %s", p.SLOID())
+			panel.Type = "text"
+			panel.Title = config.Title
+			panel.Options = map[string]any{
+				"mode":    "markdown",
+				"content": fmt.Sprintf("Honeycomb SLO `%s` is not available in Grafana. View it in Honeycomb.", p.SLOID()),
+			}
+			panel.GridPos = c.place(config.Position, label)
+
+		case sectionPanelAccessor:
+			panel.Type = "row"
+			panel.Title = p.Config().Title
+			panel.GridPos = c.row()
+
+		default:
+			c.note(fmt.Sprintf("panel %d", i+1), "unknown panel", "panel type %T is not supported and was skipped", p)
+			continue
+		}
+
+		d.Panels = append(d.Panels, panel)
+	}
+
+	return d, nil
+}
+
+// place converts a Honeycomb panel position to a Grafana grid position.
+// Panels without a position are laid out two per row.
+func (c *converter) place(pos board.Position, label string) GridPos {
+	if pos.Width > 0 || pos.Height > 0 {
+		g := GridPos{X: pos.X, Y: pos.Y, W: pos.Width, H: pos.Height}
+		if g.W <= 0 {
+			g.W = defaultWidth
+		}
+		if g.H <= 0 {
+			g.H = defaultHeight
+		}
+		if g.X+g.W > gridColumns {
+			c.note(label, "position", "panel extends past the %d column Grafana grid and was narrowed", gridColumns)
+			if g.X >= gridColumns {
+				g.X = 0
+			}
+			g.W = gridColumns - g.X
+		}
+		if bottom := g.Y + g.H; bottom > c.cursorY {
+			c.cursorX, c.cursorY, c.rowHeight = 0, bottom, 0
+		}
+		return g
+	}
+
+	if c.cursorX+defaultWidth > gridColumns {
+		c.cursorX = 0
+		c.cursorY += c.rowHeight
+		c.rowHeight = 0
+	}
+	g := GridPos{X: c.cursorX, Y: c.cursorY, W: defaultWidth, H: defaultHeight}
+	c.cursorX += defaultWidth
+	if defaultHeight > c.rowHeight {
+		c.rowHeight = defaultHeight
+	}
+	return g
+}
+
+// row places a full-width section row below the panels placed so far.
+func (c *converter) row() GridPos {
+	y := c.cursorY + c.rowHeight
+	c.cursorX, c.cursorY, c.rowHeight = 0, y+1, 0
+	return GridPos{X: 0, Y: y, W: gridColumns, H: 1}
+}
+
+// flatten returns panels with the panels of each section following it.
+func flatten(panels []board.Panel) []board.Panel {
+	var flat []board.Panel
+	for _, p := range panels {
+		flat = append(flat, p)
+		if s, ok := p.(sectionPanelAccessor); ok {
+			flat = append(flat, flatten(s.Panels())...)
+		}
+	}
+	return flat
+}
+
+// visualization picks the Grafana panel type for a query.
+func visualization(q query.Query) string {
+	for _, calc := range q.Calculations {
+		if calc.Op == "HEATMAP" {
+			return "heatmap"
+		}
+	}
+	return "timeseries"
+}
+
+// panelLabel identifies a panel in notes.
+func panelLabel(config board.PanelConfig, index int) string {
+	if config.Title != "" {
+		return config.Title
+	}
+	return fmt.Sprintf("panel %d", index+1)
+}
+
+// duration formats seconds as a Grafana duration such as 2h or 90m.
+func duration(seconds int) string {
+	switch {
+	case seconds%86400 == 0:
+		return fmt.Sprintf("%dd", seconds/86400)
+	case seconds%3600 == 0:
+		return fmt.Sprintf("%dh", seconds/3600)
+	case seconds%60 == 0:
+		return fmt.Sprintf("%dm", seconds/60)
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// uid derives a stable dashboard UID from the board name. Grafana UIDs are
+// limited to 40 characters.
+func uid(name string) string {
+	slug := strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if slug == "" {
+		slug = "board"
+	}
+	if len(slug) > 40 {
+		slug = strings.TrimRight(slug[:40], "-")
+	}
+	return slug
+}
@@ -0,0 +1,174 @@
+package grafana
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	latency := query.Query{
+		Dataset:      "api",
+		TimeRange:    query.Hours(2),
+		Calculations: []query.Calculation{query.P99("duration_ms")},
+	}
+	errors := query.Query{
+		Dataset:      "api",
+		TimeRange:    query.Hours(24),
+		Calculations: []query.Calculation{query.Count()},
+		Filters:      []query.Filter{query.GTE("status_code", 500)},
+	}
+
+	b := board.Board{
+		Name:        "API Overview",
+		Description: "Service health",
+		Tags:        []board.Tag{{Key: "team", Value: "platform"}},
+		Panels: []board.Panel{
+			board.QueryPanel(latency, board.WithTitle("Latency")),
+			board.QueryPanel(errors, board.WithTitle("Errors")),
+			board.TextPanel("## Notes"),
+		},
+	}
+
+	d, notes, err := Convert(b)
+	require.NoError(t, err)
+	assert.Empty(t, notes)
+
+	assert.Equal(t, "api-overview", d.UID)
+	assert.Equal(t, "API Overview", d.Title)
+	assert.Equal(t, []string{"team:platform"}, d.Tags)
+	assert.Equal(t, TimeWindow{From: "now-2h", To: "now"}, d.Time)
+	require.Len(t, d.Panels, 3)
+
+	latencyPanel := d.Panels[0]
+	assert.Equal(t, "timeseries", latencyPanel.Type)
+	assert.Empty(t, latencyPanel.TimeFrom)
+	assert.Equal(t, GridPos{X: 0, Y: 0, W: 12, H: 8}, latencyPanel.GridPos)
+	require.Len(t, latencyPanel.Targets, 1)
+	assert.Equal(t, "api", latencyPanel.Targets[0].Dataset)
+	assert.Equal(t, "${DS_HONEYCOMB}", latencyPanel.Targets[0].Datasource.UID)
+
+	var q map[string]any
+	require.NoError(t, json.Unmarshal(latencyPanel.Targets[0].Query, &q))
+	assert.NotContains(t, q, "time_range")
+	assert.Contains(t, q, "calculations")
+
+	// A different time range overrides the dashboard time
+	assert.Equal(t, "1d", d.Panels[1].TimeFrom)
+	assert.Equal(t, GridPos{X: 12, Y: 0, W: 12, H: 8}, d.Panels[1].GridPos)
+
+	text := d.Panels[2]
+	assert.Equal(t, "text", text.Type)
+	assert.Equal(t, "## Notes", text.Options["content"])
+	assert.Equal(t, GridPos{X: 0, Y: 8, W: 12, H: 8}, text.GridPos)
+}
+
+func TestConvert_Sections(t *testing.T) {
+	latency := query.Query{
+		Dataset:      "api",
+		TimeRange:    query.Hours(2),
+		Calculations: []query.Calculation{query.P99("duration_ms")},
+	}
+
+	b := board.Board{
+		Name: "API Overview",
+		Panels: []board.Panel{
+			board.TextPanel("## Notes"),
+			board.Section("Latency",
+				board.QueryPanel(latency, board.WithTitle("P99")),
+				board.QueryPanel(latency, board.WithTitle("P99 again")),
+				board.TextPanel("## Latency notes"),
+			),
+			board.Section("Errors"),
+		},
+	}
+
+	d, notes, err := Convert(b)
+	require.NoError(t, err)
+	assert.Empty(t, notes)
+	assert.Equal(t, TimeWindow{From: "now-2h", To: "now"}, d.Time)
+	require.Len(t, d.Panels, 6)
+
+	var types []string
+	for _, p := range d.Panels {
+		types = append(types, p.Type)
+	}
+	assert.Equal(t, []string{"text", "row", "timeseries", "timeseries", "text", "row"}, types)
+
+	// Rows start below the panels before them and span the grid
+	assert.Equal(t, "Latency", d.Panels[1].Title)
+	assert.Equal(t, GridPos{X: 0, Y: 8, W: 24, H: 1}, d.Panels[1].GridPos)
+	assert.Equal(t, GridPos{X: 0, Y: 9, W: 12, H: 8}, d.Panels[2].GridPos)
+	assert.Equal(t, GridPos{X: 12, Y: 9, W: 12, H: 8}, d.Panels[3].GridPos)
+	assert.Equal(t, GridPos{X: 0, Y: 17, W: 12, H: 8}, d.Panels[4].GridPos)
+	assert.Equal(t, GridPos{X: 0, Y: 25, W: 24, H: 1}, d.Panels[5].GridPos)
+}
+
+func TestConvert_UnsupportedFeatures(t *testing.T) {
+	absolute := query.Query{
+		Dataset:   "api",
+		TimeRange: query.Absolute(time.Unix(1700000000, 0), time.Unix(1700003600, 0)),
+	}
+
+	b := board.Board{
+		Name:          "Checkout",
+		PresetFilters: []board.Filter{{Column: "service", Operation: "=", Value: "checkout"}},
+		Panels: []board.Panel{
+			board.QueryPanel(absolute, board.WithTitle("Incident"), board.WithPosition(16, 0, 12, 6)),
+			board.SLOPanelByID("slo-123", board.WithTitle("Availability")),
+		},
+	}
+
+	d, notes, err := Convert(b)
+	require.NoError(t, err)
+
+	features := make(map[string]Note)
+	for _, n := range notes {
+		features[n.Feature] = n
+	}
+	assert.Contains(t, features, "preset filters")
+	assert.Equal(t, "Incident", features["absolute time range"].Panel)
+	assert.Equal(t, "Incident", features["position"].Panel)
+	assert.Equal(t, "Availability", features["slo panel"].Panel)
+
+	require.Len(t, d.Panels, 2)
+
+	// Preset filters are merged into the panel query, absolute times dropped
+	var q struct {
+		Filters   []map[string]any `json:"filters"`
+		StartTime int              `json:"start_time"`
+	}
+	require.NoError(t, json.Unmarshal(d.Panels[0].Targets[0].Query, &q))
+	require.Len(t, q.Filters, 1)
+	assert.Equal(t, "service", q.Filters[0]["column"])
+	assert.Zero(t, q.StartTime)
+
+	// Narrowed to fit the grid
+	assert.Equal(t, GridPos{X: 16, Y: 0, W: 8, H: 6}, d.Panels[0].GridPos)
+
+	assert.Equal(t, "text", d.Panels[1].Type)
+	assert.Contains(t, d.Panels[1].Options["content"], "slo-123")
+}
+
+func TestNoteString(t *testing.T) {
+	assert.Equal(t, "B / P: slo panel: msg", Note{Board: "B", Panel: "P", Feature: "slo panel", Message: "msg"}.String())
+	assert.Equal(t, "B: preset filters: msg", Note{Board: "B", Feature: "preset filters", Message: "msg"}.String())
+}
+
+func TestUID(t *testing.T) {
+	assert.Equal(t, "api-overview", uid("API Overview"))
+	assert.Equal(t, "board", uid("!!!"))
+	assert.LessOrEqual(t, len(uid("a very long board name that keeps going and going past forty")), 40)
+}
+
+func TestDuration(t *testing.T) {
+	assert.Equal(t, "2h", duration(7200))
+	assert.Equal(t, "7d", duration(7*86400))
+	assert.Equal(t, "90m", duration(5400))
+	assert.Equal(t, "45s", duration(45))
+}
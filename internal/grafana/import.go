@@ -0,0 +1,761 @@
+package grafana
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/convert"
+	"github.com/lex00/wetwire-honeycomb-go/internal/serialize"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+// ImportOptions configure Import.
+type ImportOptions struct {
+	// Dataset is the dataset every query uses. When empty, the value of a
+	// query's service label is used, as Honeycomb creates a dataset per
+	// service; queries without one are left without a dataset.
+	Dataset string
+}
+
+// Import converts a Grafana dashboard, in the export format or wrapped in
+// {"dashboard": ...} as the HTTP API returns it, to Honeycomb queries and a
+// board skeleton with a panel for each converted panel.
+//
+// Prometheus (PromQL) and Loki (LogQL) targets are approximated: the
+// selector's label matchers become filters, aggregation labels breakdowns,
+// and the functions and aggregations applied to it a calculation. Targets of
+// the Honeycomb data source, as Convert writes them, are imported exactly.
+// What a query loses is listed in its TODO, and every approximation is a
+// note of the conversion report.
+func Import(data []byte, opts ImportOptions) (*convert.Conversion, error) {
+	var wrapped struct {
+		Dashboard *importDashboard `json:"dashboard"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	d := wrapped.Dashboard
+	if d == nil {
+		d = &importDashboard{}
+		if err := json.Unmarshal(data, d); err != nil {
+			return nil, fmt.Errorf("invalid dashboard: %w", err)
+		}
+	}
+	if d.Panels == nil {
+		return nil, fmt.Errorf("not a Grafana dashboard: expected \"panels\"")
+	}
+
+	im := &importer{opts: opts, names: make(convert.Names)}
+	title := d.Title
+	if title == "" {
+		title = "Grafana dashboard"
+	}
+	b := &convert.Board{Name: im.names.New(title + " Board"), Title: title, Description: d.Description}
+
+	if seconds, ok := relativeTime(d.Time.From); ok {
+		im.timeRange = query.Seconds(seconds)
+	} else if d.Time.From != "" {
+		im.note(title, "time", "%q is not a relative time range; queries use the Honeycomb default", d.Time.From)
+	}
+	for _, v := range d.Templating.List {
+		im.note(title, "template variable", "$%s dropped with the filters using it; add a board preset filter instead", v.Name)
+	}
+
+	b.Panels = im.panels(rows(d.Panels))
+	im.out.Board = b
+	return &im.out, nil
+}
+
+// importDashboard is a Grafana dashboard as exported.
+type importDashboard struct {
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Panels      []importPanel `json:"panels"`
+	Time        struct {
+		From string `json:"from"`
+	} `json:"time"`
+	Templating struct {
+		List []struct {
+			Name string `json:"name"`
+		} `json:"list"`
+	} `json:"templating"`
+}
+
+// importPanel is a dashboard panel. Rows hold their panels when collapsed.
+type importPanel struct {
+	Type       string          `json:"type"`
+	Title      string          `json:"title"`
+	TimeFrom   string          `json:"timeFrom"`
+	Datasource json.RawMessage `json:"datasource"`
+	Targets    []importTarget  `json:"targets"`
+	Panels     []importPanel   `json:"panels"`
+	Options    struct {
+		Content string `json:"content"`
+	} `json:"options"`
+}
+
+// importTarget is a panel query: a PromQL or LogQL expression, or the query
+// JSON of the Honeycomb data source.
+type importTarget struct {
+	RefID      string          `json:"refId"`
+	Expr       string          `json:"expr"`
+	Hide       bool            `json:"hide"`
+	Datasource json.RawMessage `json:"datasource"`
+	Dataset    string          `json:"dataset"`
+	Query      json.RawMessage `json:"query"`
+}
+
+// rows nests the panels following an expanded row in it, as collapsed rows
+// already hold theirs.
+func rows(panels []importPanel) []importPanel {
+	var out []importPanel
+	current := -1
+	for _, p := range panels {
+		switch {
+		case p.Type == "row":
+			out = append(out, p)
+			current = len(out) - 1
+		case current >= 0:
+			out[current].Panels = append(out[current].Panels, p)
+		default:
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// queryPanels are the panel types converted to query panels, with the
+// calculation that replaces the query's, if any.
+var queryPanels = map[string]string{
+	"timeseries": "",
+	"graph":      "",
+	"stat":       "",
+	"singlestat": "",
+	"gauge":      "",
+	"bargauge":   "",
+	"table":      "",
+	"barchart":   "",
+	"piechart":   "",
+	"logs":       "",
+	"heatmap":    "HEATMAP",
+}
+
+// importer accumulates the conversion of a dashboard.
+type importer struct {
+	opts      ImportOptions
+	out       convert.Conversion
+	names     convert.Names
+	timeRange query.TimeRange
+}
+
+// note records a feature of source that was approximated or dropped.
+func (im *importer) note(source, feature, format string, args ...any) {
+	im.out.Notes = append(im.out.Notes, convert.Note{Source: source, Feature: feature, Message: fmt.Sprintf(format, args...)})
+}
+
+// panels converts panels to board panels.
+func (im *importer) panels(panels []importPanel) []convert.Panel {
+	var out []convert.Panel
+	for i, p := range panels {
+		source := p.Title
+		if source == "" {
+			source = fmt.Sprintf("panel %d", i+1)
+		}
+
+		_, isQuery := queryPanels[p.Type]
+		switch {
+		case p.Type == "row":
+			if inner := im.panels(p.Panels); len(inner) > 0 {
+				out = append(out, convert.Panel{Title: p.Title, Panels: inner})
+			}
+		case p.Type == "text":
+			out = append(out, convert.Panel{Title: p.Title, Text: p.Options.Content})
+		case isQuery:
+			out = append(out, im.queryPanel(source, p)...)
+		default:
+			im.note(source, p.Type+" panel", "has no Honeycomb equivalent; skipped")
+		}
+	}
+	return out
+}
+
+// queryPanel converts the targets of a panel to query panels, merging those
+// that share a dataset, filters and breakdowns into one query.
+func (im *importer) queryPanel(source string, p importPanel) []convert.Panel {
+	timeRange := im.timeRange
+	if p.TimeFrom != "" {
+		if seconds, ok := relativeTime("now-" + p.TimeFrom); ok {
+			timeRange = query.Seconds(seconds)
+		}
+	}
+
+	var converted []convert.Query
+	for _, t := range p.Targets {
+		if t.Hide {
+			continue
+		}
+		q, from, todo := im.target(t, p.Datasource)
+		if q == nil {
+			for _, msg := range todo {
+				im.note(source, "target "+t.RefID, "%s", msg)
+			}
+			continue
+		}
+		if calc := queryPanels[p.Type]; calc != "" {
+			for i := range q.Calculations {
+				q.Calculations[i] = query.Calculation{Op: calc, Column: q.Calculations[i].Column}
+			}
+		}
+		if p.Type == "logs" {
+			todo = append(todo, "the log lines panel became an event count; open the query in Honeycomb to see the events")
+		}
+		if q.TimeRange == (query.TimeRange{}) {
+			q.TimeRange = timeRange
+		}
+		for _, msg := range todo {
+			im.note(source, "query", "%s", msg)
+		}
+
+		if n := len(converted); n > 0 && convert.Mergeable(converted[n-1].Query, *q) {
+			last := &converted[n-1]
+			last.Query.Calculations = convert.AppendCalculations(last.Query.Calculations, q.Calculations...)
+			last.Source += "\n" + from
+			last.TODO = append(last.TODO, todo...)
+			continue
+		}
+		converted = append(converted, convert.Query{Query: *q, Source: from, TODO: todo})
+	}
+
+	var panels []convert.Panel
+	for i := range converted {
+		converted[i].Name = im.names.New(source)
+		im.out.Queries = append(im.out.Queries, converted[i])
+		panels = append(panels, convert.Panel{Title: p.Title, Query: converted[i].Name})
+	}
+	return panels
+}
+
+// target converts a panel target, with the panel's data source as default.
+// It returns the query, nil when it cannot be converted, the text it was
+// converted from and what the conversion lost.
+func (im *importer) target(t importTarget, panelSource json.RawMessage) (*query.Query, string, []string) {
+	kind := datasourceType(t.Datasource)
+	if kind == "" {
+		kind = datasourceType(panelSource)
+	}
+
+	switch {
+	case kind == "honeycomb" || kind == "" && len(t.Query) > 0 && t.Expr == "":
+		q, err := serialize.FromJSON(t.Query)
+		if err != nil {
+			return nil, "", []string{fmt.Sprintf("invalid Honeycomb query: %v; skipped", err)}
+		}
+		if q.Dataset == "" {
+			q.Dataset = t.Dataset
+		}
+		return &q, "Honeycomb query JSON", nil
+	case t.Expr == "":
+		return nil, "", []string{fmt.Sprintf("%s queries are not converted; skipped", cmp.Or(kind, "unknown data source"))}
+	case kind != "" && kind != "prometheus" && kind != "loki":
+		return nil, "", []string{fmt.Sprintf("%s queries are not converted; skipped", kind)}
+	}
+
+	n, err := parseExpr(t.Expr)
+	if err != nil {
+		return nil, "", []string{fmt.Sprintf("%q could not be parsed: %v; skipped", t.Expr, err)}
+	}
+	r := &exprQuery{}
+	if !r.convert(n) {
+		return nil, "", append(r.todo, fmt.Sprintf("%q selects no series; skipped", t.Expr))
+	}
+	q := r.query()
+
+	q.Dataset = im.opts.Dataset
+	if q.Dataset == "" {
+		q.Dataset = r.service
+	}
+	if q.Dataset == "" {
+		r.lossy("no dataset; set one with --dataset")
+	}
+	return &q, t.Expr, r.todo
+}
+
+// datasourceType returns the plugin type of a data source reference: an
+// object with a type, or a name, which usually names the type.
+func datasourceType(raw json.RawMessage) string {
+	var ref struct {
+		Type string `json:"type"`
+		UID  string `json:"uid"`
+	}
+	var name string
+	if err := json.Unmarshal(raw, &ref); err != nil {
+		if err := json.Unmarshal(raw, &name); err != nil {
+			return ""
+		}
+	}
+	for _, s := range []string{ref.Type, ref.UID, name} {
+		s = strings.ToLower(s)
+		switch {
+		case strings.Contains(s, "honeycomb"):
+			return "honeycomb"
+		case strings.Contains(s, "loki"):
+			return "loki"
+		case strings.Contains(s, "prom"):
+			return "prometheus"
+		case ref.Type != "" && s == strings.ToLower(ref.Type):
+			return s
+		}
+	}
+	return ""
+}
+
+// exprQuery accumulates the conversion of a PromQL or LogQL expression.
+type exprQuery struct {
+	calc       string
+	column     string
+	filters    []query.Filter
+	breakdowns []string
+	orders     []query.Order
+	limit      int
+
+	// metric is the selected metric, empty for a log stream
+	metric string
+
+	// service is the value of the service label, naming the dataset
+	service string
+
+	todo []string
+}
+
+// lossy records how the query differs from the expression, once.
+func (r *exprQuery) lossy(format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if !slices.Contains(r.todo, msg) {
+		r.todo = append(r.todo, msg)
+	}
+}
+
+// query returns the converted query.
+func (r *exprQuery) query() query.Query {
+	if r.calc == "" {
+		// A bare selector: the value of a gauge, or the events of a stream
+		switch {
+		case r.metric == "":
+			r.calc = "COUNT"
+		case isCounter(r.metric):
+			r.calc = "COUNT"
+			r.lossy("the running total %s became an event count", r.metric)
+		default:
+			r.calc, r.column = "AVG", r.metric
+		}
+	}
+	c := query.Calculation{Op: r.calc, Column: r.column}
+	if r.calc == "COUNT" {
+		c.Column = ""
+	}
+	return query.Query{
+		Calculations: []query.Calculation{c},
+		Filters:      r.filters,
+		Breakdowns:   r.breakdowns,
+		Orders:       r.orders,
+		Limit:        r.limit,
+	}
+}
+
+// convert converts n, reporting whether it selects series.
+func (r *exprQuery) convert(n exprNode) bool {
+	switch n := n.(type) {
+	case selectorNode:
+		r.selector(n)
+		return true
+
+	case binaryNode:
+		_, lhsScalar := n.LHS.(numberNode)
+		_, rhsScalar := n.RHS.(numberNode)
+		switch {
+		case lhsScalar && rhsScalar:
+			return false
+		case rhsScalar:
+			r.lossy("%s %g dropped", n.Op, n.RHS.(numberNode).Value)
+			return r.convert(n.LHS)
+		case lhsScalar:
+			r.lossy("%g %s dropped", n.LHS.(numberNode).Value, n.Op)
+			return r.convert(n.RHS)
+		}
+		r.lossy("the right-hand side of %s dropped; ratios between series are not converted, use a calculated field", n.Op)
+		return r.convert(n.LHS)
+
+	case callNode:
+		return r.call(n)
+	}
+	return false
+}
+
+// call converts a function call or aggregation.
+func (r *exprQuery) call(c callNode) bool {
+	vector, param := lastVector(c.Args)
+	if vector == nil {
+		return false
+	}
+
+	switch name := c.Name; {
+	case aggregations[name]:
+		if !r.convert(vector) {
+			return false
+		}
+		r.aggregate(c, param)
+
+	case name == "histogram_quantile":
+		if !r.convert(vector) {
+			return false
+		}
+		r.calc = r.percentile(param)
+		r.column = r.unitColumn(strings.TrimSuffix(r.metric, "_bucket"))
+		r.breakdowns = slices.DeleteFunc(r.breakdowns, func(b string) bool { return b == "le" })
+
+	case name == "rate" || name == "irate" || name == "increase" || name == "delta" || name == "count_over_time":
+		if !r.convert(vector) {
+			return false
+		}
+		r.counter(name)
+
+	case name == "bytes_rate" || name == "bytes_over_time":
+		if !r.convert(vector) {
+			return false
+		}
+		r.calc = "COUNT"
+		r.lossy("%s became an event count; Honeycomb does not measure log volume in bytes", name)
+
+	case strings.HasSuffix(name, "_over_time"):
+		if !r.convert(vector) {
+			return false
+		}
+		column := cmp.Or(r.column, r.metric)
+		switch strings.TrimSuffix(name, "_over_time") {
+		case "avg":
+			r.calc = "AVG"
+		case "sum":
+			r.calc = "SUM"
+		case "min":
+			r.calc = "MIN"
+		case "max":
+			r.calc = "MAX"
+		case "quantile":
+			r.calc = r.percentile(param)
+		default:
+			r.calc = "AVG"
+			r.lossy("%s became AVG", name)
+		}
+		r.column = r.unitColumn(column)
+
+	default:
+		if !r.convert(vector) {
+			return false
+		}
+		r.lossy("%s() dropped", name)
+	}
+	return true
+}
+
+// aggregate applies an aggregation to the converted query.
+func (r *exprQuery) aggregate(c callNode, param exprNode) {
+	if len(c.Without) > 0 {
+		r.lossy("%s without (%s) became no breakdown; list the columns to group by", c.Name, strings.Join(c.Without, ", "))
+	}
+	r.breakdowns = nil
+	for _, label := range c.By {
+		if label != "le" {
+			r.breakdowns = append(r.breakdowns, labelColumn(label))
+		}
+	}
+	if slices.Contains(c.By, "le") && r.calc == "COUNT" {
+		// Buckets of a histogram, for histogram_quantile or a heatmap
+		r.calc = "HEATMAP"
+		r.column = r.unitColumn(strings.TrimSuffix(r.metric, "_bucket"))
+	}
+
+	gauge := r.calc == ""
+	switch c.Name {
+	case "sum":
+		if gauge {
+			r.calc, r.column = "SUM", r.metric
+		}
+	case "avg", "min", "max":
+		if gauge {
+			r.calc, r.column = strings.ToUpper(c.Name), r.metric
+		} else if r.calc == "COUNT" {
+			r.lossy("%s of per-series rates became the total count", c.Name)
+		}
+	case "count":
+		r.calc, r.column = "COUNT", ""
+		r.lossy("count of series became a count of events; use COUNT_DISTINCT on the column that identifies a series")
+	case "quantile":
+		if gauge {
+			r.calc, r.column = r.percentile(param), r.metric
+		}
+	case "topk", "bottomk":
+		k, _ := param.(numberNode)
+		r.limit = int(k.Value)
+		order := "descending"
+		if c.Name == "bottomk" {
+			order = "ascending"
+		}
+		q := r.query()
+		col := q.Calculations[0].Column
+		r.orders = []query.Order{{Op: q.Calculations[0].Op, Column: col, Order: order}}
+	default:
+		r.lossy("%s aggregation dropped", c.Name)
+	}
+}
+
+// counter converts the rate or increase of a counter, or the count of log
+// lines, to a calculation over events.
+func (r *exprQuery) counter(name string) {
+	if r.metric == "" {
+		if unwrapped := r.column; unwrapped != "" {
+			r.calc = "SUM"
+			r.lossy("%s of unwrapped %s became its SUM", name, unwrapped)
+			return
+		}
+		r.calc = "COUNT"
+		return
+	}
+
+	switch {
+	case strings.HasSuffix(r.metric, "_bucket"):
+		r.calc = "COUNT"
+	case strings.HasSuffix(r.metric, "_sum"):
+		r.calc = "SUM"
+		r.column = r.unitColumn(strings.TrimSuffix(r.metric, "_sum"))
+		r.lossy("%s(%s) became the SUM of %s per time bucket", name, r.metric, r.column)
+	default:
+		r.calc = "COUNT"
+		r.lossy("%s(%s) became an event count per time bucket; check that each event is one increment", name, r.metric)
+	}
+}
+
+// selector converts the label matchers and pipeline of a selector to
+// filters.
+func (r *exprQuery) selector(s selectorNode) {
+	r.metric = s.Metric
+	for _, m := range s.Matchers {
+		f, ok := matcherFilter(m)
+		if !ok {
+			r.lossy("filter %s%s%q dropped", m.Label, m.Op, m.Value)
+			continue
+		}
+		if f.Column == "service.name" && f.Op == "=" {
+			r.service, _ = f.Value.(string)
+		}
+		r.filters = append(r.filters, f)
+	}
+
+	for _, st := range s.Stages {
+		switch st.Op {
+		case "|=", "!=":
+			op := query.Contains
+			if st.Op == "!=" {
+				op = query.DoesNotContain
+			}
+			r.filters = append(r.filters, op("body", st.Value))
+			r.lossy("line filter %s %q searches the body column", st.Op, st.Value)
+		case "|~", "!~":
+			r.lossy("regular expression line filter %s %q dropped", st.Op, st.Value)
+		case "unwrap":
+			r.column = labelColumn(st.Label)
+		default:
+			if st.Label == "" {
+				// A parser: Honeycomb events are already structured
+				continue
+			}
+			f, ok := matcherFilter(labelMatcher{Label: st.Label, Op: st.Op, Value: st.Value})
+			if !ok {
+				r.lossy("label filter %s %s %s dropped", st.Label, st.Op, st.Value)
+				continue
+			}
+			r.filters = append(r.filters, f)
+		}
+	}
+}
+
+// percentile returns the percentile calculation for the quantile param,
+// the nearest one Honeycomb has.
+func (r *exprQuery) percentile(param exprNode) string {
+	n, ok := param.(numberNode)
+	if !ok {
+		r.lossy("quantile is not a number; P99 is used")
+		return "P99"
+	}
+	percentiles := []struct {
+		q  float64
+		op string
+	}{{0.5, "P50"}, {0.75, "P75"}, {0.9, "P90"}, {0.95, "P95"}, {0.99, "P99"}, {0.999, "P999"}}
+	best := percentiles[0]
+	for _, p := range percentiles {
+		if math.Abs(p.q-n.Value) < math.Abs(best.q-n.Value) {
+			best = p
+		}
+	}
+	if best.q != n.Value {
+		r.lossy("quantile %g became %s", n.Value, best.op)
+	}
+	return best.op
+}
+
+// unitColumn returns the column of a metric, duration_ms for durations.
+func (r *exprQuery) unitColumn(metric string) string {
+	if strings.Contains(metric, "duration") || strings.Contains(metric, "latency") {
+		if strings.HasSuffix(metric, "_seconds") {
+			r.lossy("%s is in seconds; duration_ms is in milliseconds", metric)
+			return "duration_ms"
+		}
+		if strings.HasSuffix(metric, "_milliseconds") || strings.HasSuffix(metric, "_ms") {
+			return "duration_ms"
+		}
+	}
+	return metric
+}
+
+// lastVector returns the last argument that is not a literal, and the
+// literal before it, such as the quantile of histogram_quantile.
+func lastVector(args []exprNode) (exprNode, exprNode) {
+	var vector, param exprNode
+	for _, a := range args {
+		switch a.(type) {
+		case numberNode, stringNode:
+			if vector == nil {
+				param = a
+			}
+		default:
+			vector = a
+		}
+	}
+	return vector, param
+}
+
+// isCounter reports whether a metric is a counter, by Prometheus naming
+// conventions.
+func isCounter(metric string) bool {
+	return strings.HasSuffix(metric, "_total") || strings.HasSuffix(metric, "_count")
+}
+
+// labelColumns maps Prometheus and Loki labels to OpenTelemetry columns.
+var labelColumns = map[string]string{
+	"service":      "service.name",
+	"service_name": "service.name",
+	"namespace":    "k8s.namespace.name",
+	"pod":          "k8s.pod.name",
+}
+
+// labelColumn returns the column a label is stored as.
+func labelColumn(label string) string {
+	if column, ok := labelColumns[label]; ok {
+		return column
+	}
+	return label
+}
+
+// literalAlternation matches a regular expression that only lists
+// alternatives, such as GET|POST.
+var literalAlternation = regexp.MustCompile(`^[\w\-/ :]+(\|[\w\-/ :]+)*$`)
+
+// literalPart matches a regular expression without metacharacters.
+var literalPart = regexp.MustCompile(`^[\w\-/ :]+$`)
+
+// matcherFilter converts a label matcher to a filter. Regular expressions
+// are converted when they are a list of values, a prefix or a substring.
+func matcherFilter(m labelMatcher) (query.Filter, bool) {
+	if strings.Contains(m.Value, "$") {
+		return query.Filter{}, false
+	}
+	column := labelColumn(m.Label)
+	value := m.Value
+
+	switch m.Op {
+	case "=":
+		if value == "" {
+			return query.DoesNotExist(column), true
+		}
+		return query.Equals(column, filterValue(value)), true
+	case "!=":
+		if value == "" {
+			return query.Exists(column), true
+		}
+		return query.NotEquals(column, filterValue(value)), true
+	case ">", ">=", "<", "<=":
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return query.Filter{}, false
+		}
+		return query.Filter{Column: column, Op: m.Op, Value: n}, true
+	case "==":
+		return query.Equals(column, filterValue(value)), true
+	}
+
+	negated := m.Op == "!~"
+	if m.Op != "=~" && !negated {
+		return query.Filter{}, false
+	}
+	switch {
+	case value == ".+" && !negated:
+		return query.Exists(column), true
+	case len(value) > 4 && strings.HasPrefix(value, ".*") && strings.HasSuffix(value, ".*") && literalPart.MatchString(value[2:len(value)-2]):
+		if negated {
+			return query.DoesNotContain(column, value[2:len(value)-2]), true
+		}
+		return query.Contains(column, value[2:len(value)-2]), true
+	case strings.HasSuffix(value, ".*") && literalPart.MatchString(strings.TrimSuffix(value, ".*")) && !negated:
+		return query.StartsWith(column, strings.TrimSuffix(value, ".*")), true
+	case literalAlternation.MatchString(value):
+		values := strings.Split(value, "|")
+		slices.Sort(values)
+		if len(values) == 1 {
+			if negated {
+				return query.NotEquals(column, filterValue(values[0])), true
+			}
+			return query.Equals(column, filterValue(values[0])), true
+		}
+		list := make([]any, len(values))
+		for i, v := range values {
+			list[i] = filterValue(v)
+		}
+		if negated {
+			return query.NotIn(column, list), true
+		}
+		return query.In(column, list), true
+	}
+	return query.Filter{}, false
+}
+
+// filterValue returns a label value as a number when it is one, as labels
+// are strings but numeric columns are compared as numbers.
+func filterValue(value string) any {
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return n
+	}
+	return value
+}
+
+// relativeTimePattern matches a Grafana relative time such as now-6h.
+var relativeTimePattern = regexp.MustCompile(`^now-(\d+)([smhdwMy])$`)
+
+// relativeTime returns the length in seconds of a Grafana relative time
+// range starting at from.
+func relativeTime(from string) (int, bool) {
+	match := relativeTimePattern.FindStringSubmatch(from)
+	if match == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil || n <= 0 || n > math.MaxInt32 {
+		return 0, false
+	}
+	unit := map[string]int{"s": 1, "m": 60, "h": 3600, "d": 86400, "w": 7 * 86400, "M": 30 * 86400, "y": 365 * 86400}[match[2]]
+	return n * unit, true
+}
@@ -0,0 +1,154 @@
+package grafana
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/internal/convert"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+const dashboard = `{
+  "dashboard": {
+    "title": "Checkout Service",
+    "description": "Checkout health",
+    "time": {"from": "now-6h", "to": "now"},
+    "templating": {"list": [{"name": "env"}]},
+    "panels": [
+      {"type": "timeseries", "title": "Latency", "datasource": {"type": "prometheus", "uid": "prom"},
+       "targets": [
+         {"refId": "A", "expr": "histogram_quantile(0.99, sum by (le, route) (rate(http_request_duration_seconds_bucket{service=\"checkout\", env=\"$env\"}[5m])))"},
+         {"refId": "B", "expr": "histogram_quantile(0.5, sum by (le, route) (rate(http_request_duration_seconds_bucket{service=\"checkout\", env=\"$env\"}[5m])))"},
+         {"refId": "C", "expr": "up", "hide": true}
+       ]},
+      {"type": "row", "title": "Errors", "collapsed": false},
+      {"type": "stat", "title": "Error rate", "timeFrom": "1h", "datasource": "Prometheus",
+       "targets": [{"refId": "A", "expr": "sum(rate(http_requests_total{service=\"checkout\", code=~\"5..\"}[5m])) / sum(rate(http_requests_total{service=\"checkout\"}[5m]))"}]},
+      {"type": "logs", "title": "Error logs", "datasource": {"type": "loki"},
+       "targets": [{"refId": "A", "expr": "{service_name=\"checkout\", level=~\"error|fatal\"} |= \"timeout\""}]},
+      {"type": "row", "title": "Collapsed", "collapsed": true, "panels": [
+        {"type": "text", "title": "Runbook", "options": {"content": "See the runbook"}},
+        {"type": "nodeGraph", "title": "Dependencies"}
+      ]}
+    ]
+  }
+}`
+
+func TestImport(t *testing.T) {
+	conv, err := Import([]byte(dashboard), ImportOptions{})
+	require.NoError(t, err)
+
+	require.NotNil(t, conv.Board)
+	assert.Equal(t, "CheckoutServiceBoard", conv.Board.Name)
+	assert.Equal(t, "Checkout health", conv.Board.Description)
+	assert.Equal(t, []convert.Panel{
+		{Title: "Latency", Query: "Latency"},
+		{Title: "Errors", Panels: []convert.Panel{
+			{Title: "Error rate", Query: "ErrorRate"},
+			{Title: "Error logs", Query: "ErrorLogs"},
+		}},
+		{Title: "Collapsed", Panels: []convert.Panel{{Title: "Runbook", Text: "See the runbook"}}},
+	}, conv.Board.Panels)
+
+	require.Len(t, conv.Queries, 3)
+	latency := conv.Queries[0]
+	assert.Equal(t, "checkout", latency.Query.Dataset)
+	assert.Equal(t, query.Hours(6), latency.Query.TimeRange)
+	assert.Equal(t, []query.Calculation{query.P99("duration_ms"), query.P50("duration_ms")}, latency.Query.Calculations)
+	assert.Equal(t, []query.Filter{query.Equals("service.name", "checkout")}, latency.Query.Filters)
+	assert.Equal(t, []string{"route"}, latency.Query.Breakdowns)
+	assert.Contains(t, latency.Source, "histogram_quantile(0.5")
+	assert.NotEmpty(t, latency.TODO, "dropped $env filter and seconds to milliseconds")
+
+	errorRate := conv.Queries[1]
+	assert.Equal(t, query.Hours(1), errorRate.Query.TimeRange)
+	assert.Equal(t, []query.Calculation{query.Count()}, errorRate.Query.Calculations)
+	assert.Equal(t, []query.Filter{query.Equals("service.name", "checkout")}, errorRate.Query.Filters, "5.. is not a list of values")
+	assert.Contains(t, strings.Join(errorRate.TODO, "\n"), "right-hand side of /")
+
+	logs := conv.Queries[2].Query
+	assert.Equal(t, []query.Calculation{query.Count()}, logs.Calculations)
+	assert.Equal(t, []query.Filter{
+		query.Equals("service.name", "checkout"),
+		query.In("level", []any{"error", "fatal"}),
+		query.Contains("body", "timeout"),
+	}, logs.Filters)
+
+	var features []string
+	for _, n := range conv.Notes {
+		features = append(features, n.Feature)
+	}
+	assert.Contains(t, features, "template variable")
+	assert.Contains(t, features, "nodeGraph panel")
+}
+
+func TestImport_Dataset(t *testing.T) {
+	conv, err := Import([]byte(dashboard), ImportOptions{Dataset: "otel"})
+	require.NoError(t, err)
+	for _, q := range conv.Queries {
+		assert.Equal(t, "otel", q.Query.Dataset)
+	}
+}
+
+func TestImport_Honeycomb(t *testing.T) {
+	latency := query.Query{
+		Dataset:      "api",
+		TimeRange:    query.Hours(2),
+		Calculations: []query.Calculation{query.P99("duration_ms")},
+		Breakdowns:   []string{"route"},
+	}
+	d, _, err := Convert(board.Board{Name: "API", Panels: []board.Panel{board.QueryPanel(latency, board.WithTitle("Latency"))}})
+	require.NoError(t, err)
+	data, err := json.Marshal(d)
+	require.NoError(t, err)
+
+	conv, err := Import(data, ImportOptions{})
+	require.NoError(t, err)
+	require.Len(t, conv.Queries, 1)
+	assert.Equal(t, latency, conv.Queries[0].Query)
+	assert.Empty(t, conv.Queries[0].TODO)
+}
+
+func TestImport_Invalid(t *testing.T) {
+	for _, data := range []string{`nope`, `{"title": "x"}`, `[1]`} {
+		_, err := Import([]byte(data), ImportOptions{})
+		assert.Error(t, err, data)
+	}
+}
+
+func TestMatcherFilter(t *testing.T) {
+	tests := map[labelMatcher]query.Filter{
+		{Label: "service", Op: "=", Value: "checkout"}:       query.Equals("service.name", "checkout"),
+		{Label: "code", Op: "=", Value: "500"}:               query.Equals("code", int64(500)),
+		{Label: "region", Op: "=", Value: ""}:                query.DoesNotExist("region"),
+		{Label: "region", Op: "!=", Value: ""}:               query.Exists("region"),
+		{Label: "method", Op: "=~", Value: "POST|GET"}:       query.In("method", []any{"GET", "POST"}),
+		{Label: "method", Op: "!~", Value: "GET|HEAD"}:       query.NotIn("method", []any{"GET", "HEAD"}),
+		{Label: "pod", Op: "=~", Value: "web-.*"}:            query.StartsWith("k8s.pod.name", "web-"),
+		{Label: "route", Op: "=~", Value: ".*cart.*"}:        query.Contains("route", "cart"),
+		{Label: "route", Op: "!~", Value: ".*health.*"}:      query.DoesNotContain("route", "health"),
+		{Label: "status", Op: ">=", Value: "500"}:            {Column: "status", Op: ">=", Value: float64(500)},
+		{Label: "namespace", Op: "!=", Value: "kube-system"}: query.NotEquals("k8s.namespace.name", "kube-system"),
+	}
+	for m, want := range tests {
+		got, ok := matcherFilter(m)
+		if assert.True(t, ok, m) {
+			assert.Equal(t, want, got, m)
+		}
+	}
+
+	for _, m := range []labelMatcher{
+		{Label: "env", Op: "=", Value: "$env"},
+		{Label: "code", Op: "=~", Value: "5.."},
+		{Label: "route", Op: "=~", Value: ".*"},
+		{Label: "status", Op: ">", Value: "high"},
+	} {
+		_, ok := matcherFilter(m)
+		assert.False(t, ok, m)
+	}
+}
@@ -0,0 +1,544 @@
+package grafana
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// This file parses the subset of PromQL and LogQL that import converts:
+// selectors with label matchers, LogQL pipelines, function calls,
+// aggregations with by and without, and binary operators. Ranges, offsets
+// and vector matching modifiers are read and discarded.
+
+// exprNode is a node of a parsed PromQL or LogQL expression.
+type exprNode interface{}
+
+// selectorNode is a metric or log stream selector.
+type selectorNode struct {
+	// Metric is empty for a log stream selector
+	Metric   string
+	Matchers []labelMatcher
+
+	// Stages are the LogQL pipeline stages applied to a log stream
+	Stages []logStage
+}
+
+// labelMatcher is a label matcher such as job="api" or status=~"5..".
+type labelMatcher struct {
+	Label string
+	Op    string
+	Value string
+}
+
+// logStage is a LogQL pipeline stage: a line filter (Op |=, !=, |~ or !~
+// with the text in Value), a label filter (Label, Op and Value), an unwrap
+// (Op "unwrap" of Label) or a parser or formatter (Op is its name).
+type logStage struct {
+	Op    string
+	Label string
+	Value string
+}
+
+// callNode is a function call or an aggregation.
+type callNode struct {
+	Name string
+	Args []exprNode
+
+	// By and Without are the grouping labels of an aggregation
+	By      []string
+	Without []string
+}
+
+// binaryNode is a binary operation.
+type binaryNode struct {
+	Op       string
+	LHS, RHS exprNode
+}
+
+// numberNode is a number literal.
+type numberNode struct {
+	Value float64
+}
+
+// stringNode is a string literal.
+type stringNode struct {
+	Value string
+}
+
+// aggregations are the PromQL and LogQL aggregation operators.
+var aggregations = map[string]bool{
+	"sum": true, "avg": true, "min": true, "max": true, "count": true,
+	"topk": true, "bottomk": true, "quantile": true, "stddev": true,
+	"stdvar": true, "count_values": true, "group": true,
+}
+
+// binaryPrecedence is the precedence of each binary operator, lowest first.
+var binaryPrecedence = map[string]int{
+	"or": 1, "and": 2, "unless": 2,
+	"==": 3, "!=": 3, ">": 3, "<": 3, ">=": 3, "<=": 3,
+	"+": 4, "-": 4,
+	"*": 5, "/": 5, "%": 5,
+	"^": 6,
+}
+
+// token kinds of the expression lexer.
+const (
+	tokEOF = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokRange
+	tokOp
+)
+
+type exprToken struct {
+	kind int
+	text string
+}
+
+// lexExpr splits a PromQL or LogQL expression into tokens. Ranges such as
+// [5m] are one token.
+func lexExpr(s string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '#':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case c == '"' || c == '\'' || c == '`':
+			j := i + 1
+			var b strings.Builder
+			for ; j < len(s) && s[j] != c; j++ {
+				if s[j] == '\\' && c != '`' && j+1 < len(s) {
+					j++
+				}
+				b.WriteByte(s[j])
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			tokens = append(tokens, exprToken{tokString, b.String()})
+			i = j + 1
+		case c == '[':
+			j := strings.IndexByte(s[i:], ']')
+			if j < 0 {
+				return nil, fmt.Errorf("unterminated range at %d", i)
+			}
+			tokens = append(tokens, exprToken{tokRange, s[i+1 : i+j]})
+			i += j + 1
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+			j := i
+			for j < len(s) && (isIdentByte(s[j]) || s[j] == '.') {
+				j++
+			}
+			kind := tokNumber
+			if _, err := strconv.ParseFloat(s[i:j], 64); err != nil {
+				// A duration such as 5m
+				kind = tokIdent
+			}
+			tokens = append(tokens, exprToken{kind, s[i:j]})
+			i = j
+		case isIdentByte(c) || c == '$':
+			j := i + 1
+			for j < len(s) && (isIdentByte(s[j]) || s[j] == '.' || s[j] == '{' && c == '$' && j == i+1) {
+				j++
+			}
+			if c == '$' && j < len(s) && s[j-1] == '{' {
+				end := strings.IndexByte(s[j:], '}')
+				if end < 0 {
+					return nil, fmt.Errorf("unterminated variable at %d", i)
+				}
+				j += end + 1
+			}
+			tokens = append(tokens, exprToken{tokIdent, s[i:j]})
+			i = j
+		default:
+			op := string(c)
+			if i+1 < len(s) {
+				switch two := s[i : i+2]; two {
+				case "==", "!=", ">=", "<=", "=~", "!~", "|=", "|~":
+					op = two
+				}
+			}
+			if !strings.Contains("(){},=!<>+-*/%^|@~", string(c)) {
+				return nil, fmt.Errorf("unexpected %q at %d", c, i)
+			}
+			tokens = append(tokens, exprToken{tokOp, op})
+			i += len(op)
+		}
+	}
+	return append(tokens, exprToken{kind: tokEOF}), nil
+}
+
+// isIdentByte reports whether c can be part of an identifier.
+func isIdentByte(c byte) bool {
+	return c == '_' || c == ':' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// exprParser is a recursive descent parser over the tokens of an expression.
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+// parseExpr parses a PromQL or LogQL expression.
+func parseExpr(s string) (exprNode, error) {
+	tokens, err := lexExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{tokens: tokens}
+	n, err := p.binary(1)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q", t.text)
+	}
+	return n, nil
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token when it is the operator op.
+func (p *exprParser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expect(op string) error {
+	if !p.accept(op) {
+		return fmt.Errorf("expected %q, got %q", op, p.peek().text)
+	}
+	return nil
+}
+
+// binaryOp returns the binary operator at the current position, if any.
+func (p *exprParser) binaryOp() (string, bool) {
+	t := p.peek()
+	if t.kind != tokOp && t.kind != tokIdent {
+		return "", false
+	}
+	_, ok := binaryPrecedence[t.text]
+	return t.text, ok
+}
+
+// binary parses operations of at least precedence min.
+func (p *exprParser) binary(min int) (exprNode, error) {
+	lhs, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.binaryOp()
+		if !ok || binaryPrecedence[op] < min {
+			return lhs, nil
+		}
+		p.next()
+		p.skipModifiers()
+
+		// ^ is right-associative
+		next := binaryPrecedence[op] + 1
+		if op == "^" {
+			next--
+		}
+		rhs, err := p.binary(next)
+		if err != nil {
+			return nil, err
+		}
+		lhs = binaryNode{Op: op, LHS: lhs, RHS: rhs}
+	}
+}
+
+// skipModifiers skips the bool and vector matching modifiers of a binary
+// operator.
+func (p *exprParser) skipModifiers() {
+	for {
+		t := p.peek()
+		if t.kind != tokIdent {
+			return
+		}
+		switch t.text {
+		case "bool":
+			p.next()
+		case "on", "ignoring", "group_left", "group_right":
+			p.next()
+			if p.peek().text == "(" {
+				_, _ = p.labelList()
+			}
+		default:
+			return
+		}
+	}
+}
+
+// unary parses a unary minus or a primary expression with its range,
+// offset and LogQL pipeline.
+func (p *exprParser) unary() (exprNode, error) {
+	if p.accept("-") {
+		n, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if num, ok := n.(numberNode); ok {
+			return numberNode{Value: -num.Value}, nil
+		}
+		return binaryNode{Op: "*", LHS: numberNode{Value: -1}, RHS: n}, nil
+	}
+	p.accept("+")
+
+	n, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch t := p.peek(); {
+		case t.kind == tokRange:
+			p.next()
+		case t.kind == tokIdent && t.text == "offset":
+			p.next()
+			p.accept("-")
+			p.next()
+		case t.kind == tokOp && t.text == "@":
+			p.next()
+			p.next()
+		default:
+			return n, nil
+		}
+	}
+}
+
+// primary parses a parenthesized expression, a literal, a call, an
+// aggregation or a selector.
+func (p *exprParser) primary() (exprNode, error) {
+	t := p.peek()
+	switch {
+	case t.kind == tokOp && t.text == "(":
+		p.next()
+		n, err := p.binary(1)
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expect(")")
+	case t.kind == tokNumber:
+		p.next()
+		v, _ := strconv.ParseFloat(t.text, 64)
+		return numberNode{Value: v}, nil
+	case t.kind == tokString:
+		p.next()
+		return stringNode{Value: t.text}, nil
+	case t.kind == tokOp && t.text == "{":
+		return p.selector("")
+	case t.kind == tokIdent:
+		p.next()
+		name := t.text
+		next := p.peek()
+		if aggregations[name] && next.kind == tokIdent && (next.text == "by" || next.text == "without") {
+			return p.call(name)
+		}
+		if next.kind == tokOp && next.text == "(" {
+			return p.call(name)
+		}
+		return p.selector(name)
+	}
+	return nil, fmt.Errorf("unexpected %q", t.text)
+}
+
+// call parses the arguments and grouping of a call to name.
+func (p *exprParser) call(name string) (exprNode, error) {
+	c := callNode{Name: name}
+	if err := p.grouping(&c); err != nil {
+		return nil, err
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	for !p.accept(")") {
+		arg, err := p.binary(1)
+		if err != nil {
+			return nil, err
+		}
+		c.Args = append(c.Args, arg)
+		if !p.accept(",") {
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+	if err := p.grouping(&c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// grouping parses the by or without clause of an aggregation, if any.
+func (p *exprParser) grouping(c *callNode) error {
+	t := p.peek()
+	if t.kind != tokIdent || t.text != "by" && t.text != "without" {
+		return nil
+	}
+	p.next()
+	labels, err := p.labelList()
+	if err != nil {
+		return err
+	}
+	if t.text == "by" {
+		c.By = labels
+	} else {
+		c.Without = labels
+	}
+	return nil
+}
+
+// labelList parses a parenthesized list of labels.
+func (p *exprParser) labelList() ([]string, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var labels []string
+	for !p.accept(")") {
+		t := p.next()
+		if t.kind != tokIdent {
+			return nil, fmt.Errorf("expected a label, got %q", t.text)
+		}
+		labels = append(labels, t.text)
+		p.accept(",")
+	}
+	return labels, nil
+}
+
+// selector parses the label matchers and LogQL pipeline of a selector.
+func (p *exprParser) selector(metric string) (exprNode, error) {
+	s := selectorNode{Metric: metric}
+	if p.accept("{") {
+		for !p.accept("}") {
+			label := p.next()
+			op := p.next()
+			value := p.next()
+			if label.kind != tokIdent || op.kind != tokOp || value.kind != tokString {
+				return nil, fmt.Errorf("invalid label matcher near %q", label.text)
+			}
+			if label.text == "__name__" && op.text == "=" {
+				s.Metric = value.text
+			} else {
+				s.Matchers = append(s.Matchers, labelMatcher{Label: label.text, Op: op.text, Value: value.text})
+			}
+			p.accept(",")
+		}
+	}
+	if metric == "" {
+		stages, err := p.pipeline()
+		if err != nil {
+			return nil, err
+		}
+		s.Stages = stages
+	}
+	return s, nil
+}
+
+// logParsers are the LogQL stages that parse or reformat log lines, with
+// whether they take a string argument.
+var logParsers = map[string]bool{
+	"json": false, "logfmt": false, "unpack": false,
+	"pattern": true, "regexp": true, "line_format": true,
+	"label_format": false, "drop": false, "keep": false, "decolorize": false,
+}
+
+// pipeline parses the stages of a LogQL log pipeline.
+func (p *exprParser) pipeline() ([]logStage, error) {
+	var stages []logStage
+	for {
+		t := p.peek()
+		if t.kind != tokOp {
+			return stages, nil
+		}
+		switch t.text {
+		case "|=", "!=", "|~", "!~":
+			if p.tokens[p.pos+1].kind != tokString {
+				return stages, nil
+			}
+			p.next()
+			stages = append(stages, logStage{Op: t.text, Value: p.next().text})
+		case "|":
+			p.next()
+			name := p.next()
+			if name.kind != tokIdent {
+				return nil, fmt.Errorf("expected a pipeline stage, got %q", name.text)
+			}
+			switch takesArg, known := logParsers[name.text]; {
+			case name.text == "unwrap":
+				label := p.next()
+				if label.kind == tokIdent && p.peek().text == "(" {
+					// A conversion function such as duration(latency)
+					p.next()
+					label = p.next()
+					_ = p.expect(")")
+				}
+				stages = append(stages, logStage{Op: "unwrap", Label: label.text})
+			case known:
+				stages = append(stages, logStage{Op: name.text})
+				if takesArg && p.peek().kind == tokString {
+					p.next()
+				}
+				// Skip the arguments, up to the next stage or the range
+				for t := p.peek(); t.kind != tokEOF && t.kind != tokRange && t.text != "|" && t.text != ")"; t = p.peek() {
+					p.next()
+				}
+			default:
+				stage, err := p.labelFilter(name.text)
+				if err != nil {
+					return nil, err
+				}
+				stages = append(stages, stage...)
+			}
+		default:
+			return stages, nil
+		}
+	}
+}
+
+// labelFilter parses a LogQL label filter on label, with its and-ed
+// conditions.
+func (p *exprParser) labelFilter(label string) ([]logStage, error) {
+	var stages []logStage
+	for {
+		op := p.next()
+		value := p.next()
+		if op.kind != tokOp || value.kind != tokString && value.kind != tokNumber && value.kind != tokIdent {
+			return nil, fmt.Errorf("invalid label filter on %s", label)
+		}
+		stages = append(stages, logStage{Op: op.text, Label: label, Value: value.text})
+
+		t := p.peek()
+		if t.kind == tokOp && t.text == "," || t.kind == tokIdent && t.text == "and" {
+			p.next()
+			next := p.next()
+			if next.kind != tokIdent {
+				return nil, fmt.Errorf("invalid label filter after %s", label)
+			}
+			label = next.text
+			continue
+		}
+		return stages, nil
+	}
+}
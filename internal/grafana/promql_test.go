@@ -0,0 +1,61 @@
+package grafana
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpr(t *testing.T) {
+	tests := map[string]exprNode{
+		`up`: selectorNode{Metric: "up"},
+		`http_requests_total{job="api", code=~"5.."}`: selectorNode{
+			Metric:   "http_requests_total",
+			Matchers: []labelMatcher{{Label: "job", Op: "=", Value: "api"}, {Label: "code", Op: "=~", Value: "5.."}},
+		},
+		`{__name__="up"}`: selectorNode{Metric: "up"},
+		`sum by (route) (rate(http_requests_total[5m] offset 1h))`: callNode{
+			Name: "sum",
+			By:   []string{"route"},
+			Args: []exprNode{callNode{Name: "rate", Args: []exprNode{selectorNode{Metric: "http_requests_total"}}}},
+		},
+		`topk(5, sum(rate(x[$__rate_interval])) without (pod))`: callNode{
+			Name: "topk",
+			Args: []exprNode{
+				numberNode{Value: 5},
+				callNode{Name: "sum", Without: []string{"pod"}, Args: []exprNode{callNode{Name: "rate", Args: []exprNode{selectorNode{Metric: "x"}}}}},
+			},
+		},
+		`a / b * 100`: binaryNode{
+			Op:  "*",
+			LHS: binaryNode{Op: "/", LHS: selectorNode{Metric: "a"}, RHS: selectorNode{Metric: "b"}},
+			RHS: numberNode{Value: 100},
+		},
+		`a > bool -1`: binaryNode{Op: ">", LHS: selectorNode{Metric: "a"}, RHS: numberNode{Value: -1}},
+		`{app="web"} |= "error" != "debug" | json | status >= 500, level="error" | unwrap duration(latency) [1m]`: selectorNode{
+			Matchers: []labelMatcher{{Label: "app", Op: "=", Value: "web"}},
+			Stages: []logStage{
+				{Op: "|=", Value: "error"},
+				{Op: "!=", Value: "debug"},
+				{Op: "json"},
+				{Op: ">=", Label: "status", Value: "500"},
+				{Op: "=", Label: "level", Value: "error"},
+				{Op: "unwrap", Label: "latency"},
+			},
+		},
+	}
+	for expr, want := range tests {
+		got, err := parseExpr(expr)
+		if assert.NoError(t, err, expr) {
+			assert.Equal(t, want, got, expr)
+		}
+	}
+}
+
+func TestParseExpr_Invalid(t *testing.T) {
+	for _, expr := range []string{``, `sum(`, `up{job=}`, `a +`, `"unterminated`, `rate(x[5m]) )`} {
+		_, err := parseExpr(expr)
+		require.Error(t, err, expr)
+	}
+}
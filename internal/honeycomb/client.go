@@ -0,0 +1,298 @@
+// Package honeycomb provides a minimal client for the Honeycomb REST API.
+package honeycomb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/serialize"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+// DefaultBaseURL is the Honeycomb API endpoint used when none is configured.
+const DefaultBaseURL = "https://api.honeycomb.io"
+
+// Environment variables read by NewClientFromEnv.
+const (
+	EnvAPIKey = "HONEYCOMB_API_KEY"
+	EnvAPIURL = "HONEYCOMB_API_URL"
+)
+
+// Client talks to the Honeycomb API.
+type Client struct {
+	baseURL      string
+	apiKey       string
+	httpClient   *http.Client
+	pollInterval time.Duration
+	limiter      *rateLimiter
+}
+
+// NewClient creates a new Client authenticated with the given API key.
+func NewClient(apiKey string) *Client {
+	return &Client{
+		baseURL:      DefaultBaseURL,
+		apiKey:       apiKey,
+		httpClient:   &http.Client{Timeout: 30 * time.Second},
+		pollInterval: time.Second,
+	}
+}
+
+// NewClientFromEnv creates a new Client from HONEYCOMB_API_KEY and,
+// optionally, HONEYCOMB_API_URL.
+func NewClientFromEnv() (*Client, error) {
+	apiKey := os.Getenv(EnvAPIKey)
+	if apiKey == "" {
+		return nil, fmt.Errorf("%s is not set", EnvAPIKey)
+	}
+	c := NewClient(apiKey)
+	if baseURL := os.Getenv(EnvAPIURL); baseURL != "" {
+		c.WithBaseURL(baseURL)
+	}
+	return c, nil
+}
+
+// NewClientForEnvironment creates a new Client for a named environment from
+// HONEYCOMB_API_KEY_<NAME> and, optionally, HONEYCOMB_API_URL_<NAME> (falling
+// back to HONEYCOMB_API_URL). The name is upper-cased and every character
+// other than a letter or digit is replaced by "_", so "prod-eu" reads
+// HONEYCOMB_API_KEY_PROD_EU.
+func NewClientForEnvironment(name string) (*Client, error) {
+	suffix := EnvSuffix(name)
+	apiKey := os.Getenv(EnvAPIKey + "_" + suffix)
+	if apiKey == "" {
+		return nil, fmt.Errorf("%s_%s is not set (API key for environment %q)", EnvAPIKey, suffix, name)
+	}
+	c := NewClient(apiKey)
+	if baseURL := os.Getenv(EnvAPIURL + "_" + suffix); baseURL != "" {
+		c.WithBaseURL(baseURL)
+	} else if baseURL := os.Getenv(EnvAPIURL); baseURL != "" {
+		c.WithBaseURL(baseURL)
+	}
+	return c, nil
+}
+
+// EnvSuffix returns the environment variable suffix for an environment name.
+func EnvSuffix(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// WithBaseURL overrides the API endpoint (e.g. for EU instances or tests).
+func (c *Client) WithBaseURL(baseURL string) *Client {
+	c.baseURL = strings.TrimRight(baseURL, "/")
+	return c
+}
+
+// WithHTTPClient overrides the underlying HTTP client.
+func (c *Client) WithHTTPClient(httpClient *http.Client) *Client {
+	c.httpClient = httpClient
+	return c
+}
+
+// WithTransport sends requests through transport, keeping the HTTP client's
+// other settings such as its timeout.
+func (c *Client) WithTransport(transport http.RoundTripper) *Client {
+	httpClient := *c.httpClient
+	httpClient.Transport = transport
+	c.httpClient = &httpClient
+	return c
+}
+
+// WithPollInterval sets how often pending query results are polled.
+func (c *Client) WithPollInterval(interval time.Duration) *Client {
+	c.pollInterval = interval
+	return c
+}
+
+// WithRateLimit limits the client to the given number of requests per second
+// across all goroutines using it. A non-positive rate removes the limit.
+func (c *Client) WithRateLimit(requestsPerSecond float64) *Client {
+	c.limiter = nil
+	if requestsPerSecond > 0 {
+		c.limiter = newRateLimiter(requestsPerSecond)
+	}
+	return c
+}
+
+// QueryResult is the data returned by the Query Data API.
+type QueryResult struct {
+	// Series contains one point per time bucket (and breakdown group)
+	Series []ResultRow
+
+	// Results contains the aggregated rows for the whole time range
+	Results []ResultRow
+}
+
+// ResultRow is a single row of query result data.
+type ResultRow struct {
+	// Time is the start of the bucket (zero for aggregated results)
+	Time time.Time
+
+	// Data maps calculation and breakdown names to values
+	Data map[string]any
+}
+
+// Value returns the numeric value stored under key, or 0 if missing.
+func (r ResultRow) Value(key string) float64 {
+	switch v := r.Data[key].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	}
+	return 0
+}
+
+// CalculationKey returns the key Honeycomb uses for a calculation in result data.
+// COUNT and CONCURRENCY are bare; all other operations are formatted as OP(column).
+func CalculationKey(c query.Calculation) string {
+	if c.Column == "" {
+		return c.Op
+	}
+	return fmt.Sprintf("%s(%s)", c.Op, c.Column)
+}
+
+// RunQuery creates the query in the given dataset, requests its results and
+// polls until they are complete.
+func (c *Client) RunQuery(ctx context.Context, dataset string, q query.Query) (*QueryResult, error) {
+	spec, err := serialize.ToJSON(q)
+	if err != nil {
+		return nil, fmt.Errorf("serialize query: %w", err)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/1/queries/"+url.PathEscape(dataset), json.RawMessage(spec), &created); err != nil {
+		return nil, fmt.Errorf("create query: %w", err)
+	}
+
+	request := map[string]any{
+		"query_id":       created.ID,
+		"disable_series": false,
+		"limit":          10000,
+	}
+	var pending struct {
+		ID string `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/1/query_results/"+url.PathEscape(dataset), request, &pending); err != nil {
+		return nil, fmt.Errorf("create query result: %w", err)
+	}
+
+	path := "/1/query_results/" + url.PathEscape(dataset) + "/" + url.PathEscape(pending.ID)
+	for {
+		var res queryResultJSON
+		if err := c.do(ctx, http.MethodGet, path, nil, &res); err != nil {
+			return nil, fmt.Errorf("get query result: %w", err)
+		}
+		if res.Complete {
+			return res.toQueryResult(), nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(c.pollInterval):
+		}
+	}
+}
+
+// queryResultJSON mirrors the Query Data API result payload.
+type queryResultJSON struct {
+	Complete bool `json:"complete"`
+	Data     struct {
+		Series []struct {
+			Time time.Time      `json:"time"`
+			Data map[string]any `json:"data"`
+		} `json:"series"`
+		Results []struct {
+			Data map[string]any `json:"data"`
+		} `json:"results"`
+	} `json:"data"`
+}
+
+func (r queryResultJSON) toQueryResult() *QueryResult {
+	result := &QueryResult{}
+	for _, s := range r.Data.Series {
+		result.Series = append(result.Series, ResultRow{Time: s.Time, Data: s.Data})
+	}
+	for _, row := range r.Data.Results {
+		result.Results = append(result.Results, ResultRow{Data: row.Data})
+	}
+	return result
+}
+
+// APIError is returned when the Honeycomb API responds with a non-2xx status.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("honeycomb API returned %d: %s", e.StatusCode, e.Message)
+}
+
+// do performs an authenticated JSON request and decodes the response into out.
+func (c *Client) do(ctx context.Context, method, path string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	if c.limiter != nil {
+		if err := c.limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Honeycomb-Team", c.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,171 @@
+package honeycomb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_RunQuery(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.Header.Get("X-Honeycomb-Team"))
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/1/queries/production":
+			var spec map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&spec))
+			assert.Equal(t, float64(3600), spec["time_range"])
+			w.Write([]byte(`{"id": "q1"}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/1/query_results/production":
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, "q1", body["query_id"])
+			w.Write([]byte(`{"id": "r1", "complete": false}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/1/query_results/production/r1":
+			polls++
+			if polls < 2 {
+				w.Write([]byte(`{"id": "r1", "complete": false}`))
+				return
+			}
+			w.Write([]byte(`{
+				"id": "r1",
+				"complete": true,
+				"data": {
+					"series": [
+						{"time": "2024-01-01T00:00:00Z", "data": {"COUNT": 10}},
+						{"time": "2024-01-01T01:00:00Z", "data": {"COUNT": 20}}
+					],
+					"results": [{"data": {"COUNT": 30}}]
+				}
+			}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient("test-key").WithBaseURL(server.URL).WithPollInterval(time.Millisecond)
+	res, err := c.RunQuery(context.Background(), "production", query.Query{
+		Dataset:      "production",
+		TimeRange:    query.Hours(1),
+		Calculations: []query.Calculation{query.Count()},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, res.Series, 2)
+	assert.Equal(t, 10.0, res.Series[0].Value("COUNT"))
+	assert.Equal(t, time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), res.Series[1].Time)
+	require.Len(t, res.Results, 1)
+	assert.Equal(t, 30.0, res.Results[0].Value("COUNT"))
+	assert.Equal(t, 2, polls)
+}
+
+func TestClient_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error": "unknown API key"}`))
+	}))
+	defer server.Close()
+
+	c := NewClient("bad").WithBaseURL(server.URL)
+	_, err := c.RunQuery(context.Background(), "production", query.Query{})
+	require.Error(t, err)
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+}
+
+func TestNewClientFromEnv(t *testing.T) {
+	t.Setenv(EnvAPIKey, "")
+	_, err := NewClientFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv(EnvAPIKey, "key")
+	t.Setenv(EnvAPIURL, "https://api.eu1.honeycomb.io/")
+	c, err := NewClientFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.eu1.honeycomb.io", c.baseURL)
+}
+
+func TestNewClientForEnvironment(t *testing.T) {
+	assert.Equal(t, "PROD_EU", EnvSuffix("prod-eu"))
+
+	t.Setenv(EnvAPIKey+"_STAGING", "")
+	_, err := NewClientForEnvironment("staging")
+	assert.ErrorContains(t, err, "HONEYCOMB_API_KEY_STAGING")
+
+	t.Setenv(EnvAPIKey+"_STAGING", "staging-key")
+	t.Setenv(EnvAPIURL, "https://api.eu1.honeycomb.io")
+	t.Setenv(EnvAPIURL+"_STAGING", "")
+	c, err := NewClientForEnvironment("staging")
+	require.NoError(t, err)
+	assert.Equal(t, "staging-key", c.apiKey)
+	assert.Equal(t, "https://api.eu1.honeycomb.io", c.baseURL)
+
+	t.Setenv(EnvAPIURL+"_STAGING", "http://localhost:8080")
+	c, err = NewClientForEnvironment("staging")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080", c.baseURL)
+}
+
+func TestClient_RateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	c := NewClient("key").WithBaseURL(server.URL).WithRateLimit(50)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		_, err := c.ListResources(context.Background(), ResourceBoards, "")
+		require.NoError(t, err)
+	}
+	// Four requests at 50/s are spaced at least 3 * 20ms apart
+	assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c.WithRateLimit(0.1)
+	_, _ = c.ListResources(context.Background(), ResourceBoards, "")
+	_, err := c.ListResources(ctx, ResourceBoards, "")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// countingTransport counts the requests it forwards.
+type countingTransport struct {
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.requests++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClient_WithTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	transport := &countingTransport{}
+	c := NewClient("key").WithBaseURL(server.URL).WithTransport(transport)
+	_, err := c.ListResources(context.Background(), ResourceBoards, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, transport.requests)
+	assert.Equal(t, 30*time.Second, c.httpClient.Timeout)
+}
+
+func TestCalculationKey(t *testing.T) {
+	assert.Equal(t, "COUNT", CalculationKey(query.Count()))
+	assert.Equal(t, "P99(duration_ms)", CalculationKey(query.P99("duration_ms")))
+}
@@ -0,0 +1,104 @@
+package honeycomb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Resource types accepted by the resource management methods. They match the
+// keys used in build output.
+const (
+	ResourceQueries    = "queries"
+	ResourceBoards     = "boards"
+	ResourceSLOs       = "slos"
+	ResourceBurnAlerts = "burn_alerts"
+	ResourceTriggers   = "triggers"
+)
+
+// resourcePath returns the collection path for a resource type. Boards are
+// environment-wide; all other resources are scoped to a dataset.
+func resourcePath(resourceType, dataset string) (string, error) {
+	switch resourceType {
+	case ResourceBoards:
+		return "/1/boards", nil
+	case ResourceQueries, ResourceSLOs, ResourceBurnAlerts, ResourceTriggers:
+		if dataset == "" {
+			return "", fmt.Errorf("%s require a dataset", resourceType)
+		}
+		return "/1/" + resourceType + "/" + url.PathEscape(dataset), nil
+	}
+	return "", fmt.Errorf("unsupported resource type %q", resourceType)
+}
+
+// ListResources returns the raw JSON of every resource of the given type.
+// The dataset is ignored for boards.
+func (c *Client) ListResources(ctx context.Context, resourceType, dataset string) ([]json.RawMessage, error) {
+	path, err := resourcePath(resourceType, dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []json.RawMessage
+	if err := c.do(ctx, http.MethodGet, path, nil, &resources); err != nil {
+		return nil, fmt.Errorf("list %s: %w", resourceType, err)
+	}
+	return resources, nil
+}
+
+// ListBurnAlerts returns the raw JSON of every burn alert attached to an SLO.
+func (c *Client) ListBurnAlerts(ctx context.Context, dataset, sloID string) ([]json.RawMessage, error) {
+	path, err := resourcePath(ResourceBurnAlerts, dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []json.RawMessage
+	if err := c.do(ctx, http.MethodGet, path+"?slo_id="+url.QueryEscape(sloID), nil, &resources); err != nil {
+		return nil, fmt.Errorf("list burn_alerts: %w", err)
+	}
+	return resources, nil
+}
+
+// CreateResource creates a resource and returns the created resource's JSON.
+func (c *Client) CreateResource(ctx context.Context, resourceType, dataset string, body json.RawMessage) (json.RawMessage, error) {
+	path, err := resourcePath(resourceType, dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	var created json.RawMessage
+	if err := c.do(ctx, http.MethodPost, path, body, &created); err != nil {
+		return nil, fmt.Errorf("create %s: %w", resourceType, err)
+	}
+	return created, nil
+}
+
+// UpdateResource replaces the resource with the given id and returns its new JSON.
+func (c *Client) UpdateResource(ctx context.Context, resourceType, dataset, id string, body json.RawMessage) (json.RawMessage, error) {
+	path, err := resourcePath(resourceType, dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated json.RawMessage
+	if err := c.do(ctx, http.MethodPut, path+"/"+url.PathEscape(id), body, &updated); err != nil {
+		return nil, fmt.Errorf("update %s %s: %w", resourceType, id, err)
+	}
+	return updated, nil
+}
+
+// DeleteResource deletes the resource with the given id.
+func (c *Client) DeleteResource(ctx context.Context, resourceType, dataset, id string) error {
+	path, err := resourcePath(resourceType, dataset)
+	if err != nil {
+		return err
+	}
+
+	if err := c.do(ctx, http.MethodDelete, path+"/"+url.PathEscape(id), nil, nil); err != nil {
+		return fmt.Errorf("delete %s %s: %w", resourceType, id, err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package honeycomb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ListResources(t *testing.T) {
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		assert.Equal(t, http.MethodGet, r.Method)
+		w.Write([]byte(`[{"id": "abc", "name": "High Latency"}]`))
+	}))
+	defer server.Close()
+
+	c := NewClient("key").WithBaseURL(server.URL)
+
+	triggers, err := c.ListResources(context.Background(), ResourceTriggers, "production")
+	require.NoError(t, err)
+	require.Len(t, triggers, 1)
+	assert.JSONEq(t, `{"id": "abc", "name": "High Latency"}`, string(triggers[0]))
+
+	_, err = c.ListResources(context.Background(), ResourceBoards, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"/1/triggers/production", "/1/boards"}, paths)
+}
+
+func TestClient_ListResources_Invalid(t *testing.T) {
+	c := NewClient("key")
+
+	_, err := c.ListResources(context.Background(), ResourceSLOs, "")
+	assert.Error(t, err)
+
+	_, err = c.ListResources(context.Background(), "columns", "production")
+	assert.Error(t, err)
+}
+
+func TestClient_ResourceCRUD(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI())
+		switch r.Method {
+		case http.MethodPost:
+			w.Write([]byte(`{"id": "new"}`))
+		case http.MethodPut:
+			w.Write([]byte(`{"id": "t1"}`))
+		case http.MethodGet:
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient("key").WithBaseURL(server.URL)
+	ctx := context.Background()
+
+	created, err := c.CreateResource(ctx, ResourceTriggers, "api", []byte(`{"name": "x"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id": "new"}`, string(created))
+
+	_, err = c.UpdateResource(ctx, ResourceTriggers, "api", "t1", []byte(`{"name": "x"}`))
+	require.NoError(t, err)
+
+	require.NoError(t, c.DeleteResource(ctx, ResourceTriggers, "api", "t1"))
+
+	_, err = c.ListBurnAlerts(ctx, "api", "slo1")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"POST /1/triggers/api",
+		"PUT /1/triggers/api/t1",
+		"DELETE /1/triggers/api/t1",
+		"GET /1/burn_alerts/api?slo_id=slo1",
+	}, requests)
+}
@@ -0,0 +1,160 @@
+package htmlreport
+
+import (
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// Resource kinds, also used as graph node CSS classes and anchor prefixes.
+const (
+	kindQuery   = "query"
+	kindSLO     = "slo"
+	kindTrigger = "trigger"
+	kindBoard   = "board"
+)
+
+// Graph layout, in pixels.
+const (
+	nodeHeight   = 28
+	nodeGap      = 12
+	columnGap    = 80
+	graphPadding = 16
+	charWidth    = 7
+	minNodeWidth = 120
+)
+
+// graph is a dependency graph laid out in columns: boards, then SLOs and
+// triggers, then queries, with edges pointing from users to what they use.
+type graph struct {
+	columns [][]*node
+	edges   []edge
+}
+
+type node struct {
+	kind, name string
+
+	// link is the page of the resource, relative to the site root
+	link string
+
+	x, y, width int
+}
+
+type edge struct {
+	from, to *node
+}
+
+// graph returns the dependency graph of the given resources. References to
+// resources outside them are left out.
+func (c *catalog) graph(queries []discovery.DiscoveredQuery, slos []discovery.DiscoveredSLO, triggers []discovery.DiscoveredTrigger, boards []discovery.DiscoveredBoard) *graph {
+	nodes := make(map[string]*node)
+	add := func(column *[]*node, kind, name, dataset string) {
+		key := kind + ":" + name
+		if _, ok := nodes[key]; ok {
+			return
+		}
+		n := &node{kind: kind, name: name, link: "index.html#" + kind + "-" + name}
+		if link := c.datasetLink(dataset, ""); link != "" {
+			n.link = link + "#" + kind + "-" + name
+		}
+		nodes[key] = n
+		*column = append(*column, n)
+	}
+
+	var boardColumn, alertColumn, queryColumn []*node
+	for _, b := range boards {
+		add(&boardColumn, kindBoard, b.Name, "")
+	}
+	for _, s := range slos {
+		add(&alertColumn, kindSLO, s.Name, c.sloDataset(s))
+	}
+	for _, t := range triggers {
+		add(&alertColumn, kindTrigger, t.Name, c.triggerDataset(t))
+	}
+	for _, q := range queries {
+		add(&queryColumn, kindQuery, q.Name, q.Dataset)
+	}
+
+	g := &graph{}
+	link := func(fromKind, from, toKind, to string) {
+		a, b := nodes[fromKind+":"+from], nodes[toKind+":"+to]
+		if a != nil && b != nil {
+			g.edges = append(g.edges, edge{a, b})
+		}
+	}
+	for _, b := range boards {
+		for _, ref := range b.QueryRefs {
+			link(kindBoard, b.Name, kindQuery, ref)
+		}
+		for _, ref := range b.SLORefs {
+			link(kindBoard, b.Name, kindSLO, ref)
+		}
+	}
+	for _, s := range slos {
+		link(kindSLO, s.Name, kindQuery, s.GoodEventsQueryRef)
+		if s.TotalEventsQueryRef != s.GoodEventsQueryRef {
+			link(kindSLO, s.Name, kindQuery, s.TotalEventsQueryRef)
+		}
+	}
+	for _, t := range triggers {
+		link(kindTrigger, t.Name, kindQuery, t.QueryRef)
+	}
+
+	for _, column := range [][]*node{boardColumn, alertColumn, queryColumn} {
+		if len(column) > 0 {
+			g.columns = append(g.columns, column)
+		}
+	}
+	return g
+}
+
+// layout positions the nodes, returning the size of the drawing.
+func (g *graph) layout() (width, height int) {
+	x := graphPadding
+	for _, column := range g.columns {
+		w := minNodeWidth
+		for _, n := range column {
+			w = max(w, len(n.name)*charWidth+2*graphPadding)
+		}
+		for i, n := range column {
+			n.x, n.y, n.width = x, graphPadding+i*(nodeHeight+nodeGap), w
+		}
+		x += w + columnGap
+		height = max(height, graphPadding+len(column)*(nodeHeight+nodeGap)-nodeGap+graphPadding)
+	}
+	return x - columnGap + graphPadding, height
+}
+
+// SVG renders the graph as an inline SVG element. base is the path from the
+// page to the site root. An empty graph renders as nothing.
+func (g *graph) SVG(base string) template.HTML {
+	if len(g.columns) == 0 {
+		return ""
+	}
+	width, height := g.layout()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg class="graph" xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" role="img" aria-label="Dependency graph">`, width, height, width, height)
+	b.WriteString(`<defs><marker id="arrow" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="6" markerHeight="6" orient="auto-start-reverse"><path d="M 0 0 L 10 5 L 0 10 z"/></marker></defs>`)
+
+	for _, e := range g.edges {
+		x1, y1 := e.from.x+e.from.width, e.from.y+nodeHeight/2
+		x2, y2 := e.to.x, e.to.y+nodeHeight/2
+		mid := (x1 + x2) / 2
+		fmt.Fprintf(&b, `<path class="edge" d="M %d %d C %d %d, %d %d, %d %d" marker-end="url(#arrow)"/>`, x1, y1, mid, y1, mid, y2, x2, y2)
+	}
+
+	for _, column := range g.columns {
+		for _, n := range column {
+			fmt.Fprintf(&b, `<a href="%s"><g class="node %s"><title>%s %s</title>`,
+				template.HTMLEscapeString(base+n.link), n.kind, n.kind, template.HTMLEscapeString(n.name))
+			fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%d" height="%d" rx="4"/>`, n.x, n.y, n.width, nodeHeight)
+			fmt.Fprintf(&b, `<text x="%d" y="%d">%s</text></g></a>`, n.x+n.width/2, n.y+nodeHeight/2+4, template.HTMLEscapeString(n.name))
+		}
+	}
+
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
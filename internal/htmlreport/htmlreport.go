@@ -0,0 +1,698 @@
+// Package htmlreport writes a static HTML site summarizing discovered
+// resources, to be published from CI as an observability catalog: an index
+// with the dependency graph and the SLO and trigger inventories, a page per
+// dataset and a lint summary.
+//
+// Pages are self-contained, with styles inline and dependency graphs embedded
+// as SVG, so the output directory can be served by any static file host.
+package htmlreport
+
+import (
+	"cmp"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/advise"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lint"
+)
+
+// DefaultTitle heads the pages when Options.Title is empty.
+const DefaultTitle = "Honeycomb resources"
+
+// Options configure the generated site.
+type Options struct {
+	// Title heads every page (default: DefaultTitle)
+	Title string
+
+	// Root is the directory source locations are shown relative to. Empty
+	// shows absolute paths.
+	Root string
+
+	// Generated is the time shown in page footers. Zero leaves it out, which
+	// keeps the output reproducible.
+	Generated time.Time
+}
+
+// Write writes the site for resources and their lint issues to dir, creating
+// it when needed, and returns the paths of the written files relative to dir.
+func Write(dir string, resources *discovery.DiscoveredResources, issues []lint.Issue, opts Options) ([]string, error) {
+	if opts.Title == "" {
+		opts.Title = DefaultTitle
+	}
+	c := newCatalog(resources, issues, opts)
+
+	if err := os.MkdirAll(filepath.Join(dir, "datasets"), 0755); err != nil {
+		return nil, fmt.Errorf("create output directory: %w", err)
+	}
+
+	pages := []struct {
+		path string
+		name string
+		data any
+	}{
+		{"index.html", "index", c.indexPage()},
+		{"lint.html", "lint", c.lintPage()},
+	}
+	for _, ds := range c.datasets {
+		pages = append(pages, struct {
+			path string
+			name string
+			data any
+		}{"datasets/" + ds.Slug + ".html", "dataset", c.datasetPage(ds)})
+	}
+
+	var written []string
+	for _, p := range pages {
+		f, err := os.Create(filepath.Join(dir, filepath.FromSlash(p.path)))
+		if err != nil {
+			return written, fmt.Errorf("write %s: %w", p.path, err)
+		}
+		err = pageTemplates.ExecuteTemplate(f, p.name, p.data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return written, fmt.Errorf("write %s: %w", p.path, err)
+		}
+		written = append(written, p.path)
+	}
+	return written, nil
+}
+
+// catalog is the resources arranged for the pages.
+type catalog struct {
+	opts      Options
+	resources *discovery.DiscoveredResources
+
+	queries  []discovery.DiscoveredQuery
+	datasets []*dataset
+	issues   []lint.Issue
+
+	// issueCounts counts issues by resource location
+	issueCounts map[string]int
+}
+
+// dataset groups the resources of one dataset.
+type dataset struct {
+	Name string
+	Slug string
+
+	Queries  []discovery.DiscoveredQuery
+	SLOs     []discovery.DiscoveredSLO
+	Triggers []discovery.DiscoveredTrigger
+	Boards   []discovery.DiscoveredBoard
+
+	Schema    discovery.DiscoveredSchema
+	HasSchema bool
+}
+
+func newCatalog(resources *discovery.DiscoveredResources, issues []lint.Issue, opts Options) *catalog {
+	c := &catalog{
+		opts:        opts,
+		resources:   resources,
+		issues:      issues,
+		issueCounts: make(map[string]int),
+	}
+	for _, issue := range issues {
+		c.issueCounts[location(issue.File, issue.Line)]++
+	}
+
+	// Inline queries are discovered under the name of the resource holding
+	// them, and can be discovered more than once
+	holders := make(map[string]bool)
+	for _, s := range resources.SLOs {
+		holders[s.Package+"."+s.Name] = true
+	}
+	for _, t := range resources.Triggers {
+		holders[t.Package+"."+t.Name] = true
+	}
+	for _, b := range resources.Boards {
+		holders[b.Package+"."+b.Name] = true
+	}
+	seen := make(map[string]bool)
+	for _, q := range resources.Queries {
+		key := fmt.Sprintf("%s:%d:%s", q.File, q.Line, q.Name)
+		if seen[key] || holders[q.Package+"."+q.Name] {
+			continue
+		}
+		seen[key] = true
+		c.queries = append(c.queries, q)
+	}
+	sort.SliceStable(c.queries, func(i, j int) bool { return c.queries[i].Name < c.queries[j].Name })
+
+	byName := make(map[string]*dataset)
+	get := func(name string) *dataset {
+		if name == "" {
+			return nil
+		}
+		if ds, ok := byName[name]; ok {
+			return ds
+		}
+		ds := &dataset{Name: name}
+		byName[name] = ds
+		c.datasets = append(c.datasets, ds)
+		return ds
+	}
+
+	for _, q := range c.queries {
+		if ds := get(q.Dataset); ds != nil {
+			ds.Queries = append(ds.Queries, q)
+		}
+	}
+	for _, s := range resources.SLOs {
+		if ds := get(c.sloDataset(s)); ds != nil {
+			ds.SLOs = append(ds.SLOs, s)
+		}
+	}
+	for _, t := range resources.Triggers {
+		if ds := get(c.triggerDataset(t)); ds != nil {
+			ds.Triggers = append(ds.Triggers, t)
+		}
+	}
+	for _, b := range resources.Boards {
+		for _, name := range c.boardDatasets(b) {
+			ds := get(name)
+			ds.Boards = append(ds.Boards, b)
+		}
+	}
+	for _, s := range resources.Schemas {
+		get(s.Dataset)
+	}
+
+	sort.Slice(c.datasets, func(i, j int) bool { return c.datasets[i].Name < c.datasets[j].Name })
+	slugs := make(map[string]bool)
+	for _, ds := range c.datasets {
+		ds.Schema, ds.HasSchema = resources.Schema(ds.Name)
+		slug := slugify(ds.Name)
+		for n := 2; slugs[slug]; n++ {
+			slug = fmt.Sprintf("%s-%d", slugify(ds.Name), n)
+		}
+		slugs[slug] = true
+		ds.Slug = slug
+	}
+	return c
+}
+
+// query returns the query named name.
+func (c *catalog) query(name string) (discovery.DiscoveredQuery, bool) {
+	for _, q := range c.queries {
+		if q.Name == name {
+			return q, true
+		}
+	}
+	return discovery.DiscoveredQuery{}, false
+}
+
+// triggerDataset returns the dataset of a trigger, falling back to that of
+// its query.
+func (c *catalog) triggerDataset(t discovery.DiscoveredTrigger) string {
+	if t.Dataset != "" {
+		return t.Dataset
+	}
+	if t.InlineQuery != nil {
+		return t.InlineQuery.Dataset
+	}
+	q, _ := c.query(t.QueryRef)
+	return q.Dataset
+}
+
+// sloDataset returns the dataset of an SLO, falling back to that of its
+// queries.
+func (c *catalog) sloDataset(s discovery.DiscoveredSLO) string {
+	if s.Dataset != "" {
+		return s.Dataset
+	}
+	for _, q := range []*discovery.DiscoveredQuery{s.TotalEventsQuery, s.GoodEventsQuery} {
+		if q != nil && q.Dataset != "" {
+			return q.Dataset
+		}
+	}
+	for _, ref := range []string{s.TotalEventsQueryRef, s.GoodEventsQueryRef} {
+		if q, ok := c.query(ref); ok && q.Dataset != "" {
+			return q.Dataset
+		}
+	}
+	return ""
+}
+
+// boardDatasets returns the datasets of the queries and SLOs on a board, once
+// each in order.
+func (c *catalog) boardDatasets(b discovery.DiscoveredBoard) []string {
+	var names []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, ref := range b.QueryRefs {
+		q, _ := c.query(ref)
+		add(q.Dataset)
+	}
+	for _, ref := range b.SLORefs {
+		for _, s := range c.resources.SLOs {
+			if s.Name == ref {
+				add(c.sloDataset(s))
+			}
+		}
+	}
+	return names
+}
+
+// datasetLink returns the path of a dataset's page relative to base, or ""
+// for datasets without one.
+func (c *catalog) datasetLink(name, base string) string {
+	for _, ds := range c.datasets {
+		if ds.Name == name {
+			return base + "datasets/" + ds.Slug + ".html"
+		}
+	}
+	return ""
+}
+
+// page is what every page template receives.
+type page struct {
+	Title     string
+	SiteTitle string
+
+	// Base is the path from the page to the site root
+	Base string
+
+	Generated string
+}
+
+func (c *catalog) page(title, base string) page {
+	p := page{Title: title, SiteTitle: c.opts.Title, Base: base}
+	if !c.opts.Generated.IsZero() {
+		p.Generated = c.opts.Generated.UTC().Format(time.RFC3339)
+	}
+	return p
+}
+
+// datasetSummary is a row of the index's dataset table.
+type datasetSummary struct {
+	Name, Slug                      string
+	Queries, SLOs, Triggers, Boards int
+	Retention                       string
+	Issues                          int
+}
+
+type indexPage struct {
+	Page page
+
+	Queries, SLOs, Triggers, Boards int
+	Errors, Warnings, Infos         int
+
+	Datasets []datasetSummary
+	Graph    template.HTML
+
+	SLORows     []sloRow
+	TriggerRows []triggerRow
+	BoardRows   []boardRow
+}
+
+func (c *catalog) indexPage() indexPage {
+	p := indexPage{
+		Page:     c.page(c.opts.Title, ""),
+		Queries:  len(c.queries),
+		SLOs:     len(c.resources.SLOs),
+		Triggers: len(c.resources.Triggers),
+		Boards:   len(c.resources.Boards),
+		Graph:    c.graph(c.queries, c.resources.SLOs, c.resources.Triggers, c.resources.Boards).SVG(""),
+	}
+	p.Errors, p.Warnings, p.Infos = countSeverities(c.issues)
+
+	for _, ds := range c.datasets {
+		summary := datasetSummary{
+			Name:     ds.Name,
+			Slug:     ds.Slug,
+			Queries:  len(ds.Queries),
+			SLOs:     len(ds.SLOs),
+			Triggers: len(ds.Triggers),
+			Boards:   len(ds.Boards),
+			Issues:   len(c.datasetIssues(ds)),
+		}
+		if ds.Schema.RetentionDays > 0 {
+			summary.Retention = fmt.Sprintf("%dd", ds.Schema.RetentionDays)
+		}
+		p.Datasets = append(p.Datasets, summary)
+	}
+
+	p.SLORows = c.sloRows(c.resources.SLOs, "")
+	p.TriggerRows = c.triggerRows(c.resources.Triggers, "")
+	p.BoardRows = c.boardRows(c.resources.Boards, "")
+	return p
+}
+
+type datasetPage struct {
+	Page page
+
+	Name      string
+	Columns   int
+	Retention string
+	Volume    string
+
+	Graph template.HTML
+
+	QueryRows   []queryRow
+	SLORows     []sloRow
+	TriggerRows []triggerRow
+	BoardRows   []boardRow
+	Issues      []issueRow
+}
+
+func (c *catalog) datasetPage(ds *dataset) datasetPage {
+	p := datasetPage{
+		Page:        c.page(ds.Name, "../"),
+		Name:        ds.Name,
+		Columns:     len(ds.Schema.Columns),
+		Graph:       c.graph(ds.Queries, ds.SLOs, ds.Triggers, ds.Boards).SVG("../"),
+		QueryRows:   c.queryRows(ds.Queries),
+		SLORows:     c.sloRows(ds.SLOs, "../"),
+		TriggerRows: c.triggerRows(ds.Triggers, "../"),
+		BoardRows:   c.boardRows(ds.Boards, "../"),
+		Issues:      c.issueRows(c.datasetIssues(ds)),
+	}
+	if ds.Schema.RetentionDays > 0 {
+		p.Retention = fmt.Sprintf("%dd", ds.Schema.RetentionDays)
+	}
+	if ds.Schema.EventsPerDay > 0 {
+		p.Volume = advise.FormatCount(ds.Schema.EventsPerDay) + " events/day"
+	}
+	return p
+}
+
+// datasetIssues returns the issues reported at the declaration of one of a
+// dataset's resources.
+func (c *catalog) datasetIssues(ds *dataset) []lint.Issue {
+	locations := make(map[string]bool)
+	for _, q := range ds.Queries {
+		locations[location(q.File, q.Line)] = true
+	}
+	for _, s := range ds.SLOs {
+		locations[location(s.File, s.Line)] = true
+	}
+	for _, t := range ds.Triggers {
+		locations[location(t.File, t.Line)] = true
+	}
+
+	var issues []lint.Issue
+	for _, issue := range c.issues {
+		if locations[location(issue.File, issue.Line)] {
+			issues = append(issues, issue)
+		}
+	}
+	return issues
+}
+
+// ruleCount is a row of the lint page's rule table.
+type ruleCount struct {
+	Rule     string
+	Severity string
+	Count    int
+}
+
+type lintPage struct {
+	Page page
+
+	Errors, Warnings, Infos int
+
+	Rules  []ruleCount
+	Issues []issueRow
+}
+
+func (c *catalog) lintPage() lintPage {
+	p := lintPage{
+		Page:   c.page("Lint", ""),
+		Issues: c.issueRows(c.issues),
+	}
+	p.Errors, p.Warnings, p.Infos = countSeverities(c.issues)
+
+	byRule := make(map[string]*ruleCount)
+	for _, issue := range c.issues {
+		rc, ok := byRule[issue.Rule]
+		if !ok {
+			rc = &ruleCount{Rule: issue.Rule, Severity: issue.Severity.String()}
+			byRule[issue.Rule] = rc
+		}
+		rc.Count++
+	}
+	for _, rc := range byRule {
+		p.Rules = append(p.Rules, *rc)
+	}
+	sort.Slice(p.Rules, func(i, j int) bool {
+		if p.Rules[i].Count != p.Rules[j].Count {
+			return p.Rules[i].Count > p.Rules[j].Count
+		}
+		return p.Rules[i].Rule < p.Rules[j].Rule
+	})
+	return p
+}
+
+type queryRow struct {
+	Name         string
+	Location     string
+	Description  string
+	Calculations string
+	Filters      string
+	Breakdowns   string
+	TimeRange    string
+	Issues       int
+}
+
+func (c *catalog) queryRows(queries []discovery.DiscoveredQuery) []queryRow {
+	var rows []queryRow
+	for _, q := range queries {
+		row := queryRow{
+			Name:         q.Name,
+			Location:     c.location(q.File, q.Line),
+			Description:  q.Doc,
+			Calculations: calculations(q),
+			Breakdowns:   strings.Join(q.Breakdowns, ", "),
+			TimeRange:    timeRange(q.TimeRange),
+			Issues:       c.issueCounts[location(q.File, q.Line)],
+		}
+		var filters []string
+		for _, f := range q.Filters {
+			if f.HasValue {
+				filters = append(filters, fmt.Sprintf("%s %s %v", f.Column, f.Op, f.Value))
+			} else {
+				filters = append(filters, fmt.Sprintf("%s %s", f.Column, f.Op))
+			}
+		}
+		row.Filters = strings.Join(filters, ", ")
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+type sloRow struct {
+	Name        string
+	Title       string
+	Location    string
+	Dataset     string
+	DatasetLink string
+	Target      string
+	Window      string
+	GoodEvents  string
+	TotalEvents string
+	BurnAlerts  int
+	Description string
+	Issues      int
+}
+
+func (c *catalog) sloRows(slos []discovery.DiscoveredSLO, base string) []sloRow {
+	var rows []sloRow
+	for _, s := range slos {
+		row := sloRow{
+			Name:        s.Name,
+			Title:       s.SLOName,
+			Location:    c.location(s.File, s.Line),
+			Target:      fmt.Sprintf("%g%%", s.TargetPercentage),
+			Window:      fmt.Sprintf("%dd", s.TimePeriodDays),
+			GoodEvents:  refOrInline(s.GoodEventsQueryRef, s.GoodEventsQuery),
+			TotalEvents: refOrInline(s.TotalEventsQueryRef, s.TotalEventsQuery),
+			BurnAlerts:  s.BurnAlertCount,
+			Description: cmp.Or(s.Description, s.Doc),
+			Issues:      c.issueCounts[location(s.File, s.Line)],
+		}
+		row.Dataset = c.sloDataset(s)
+		row.DatasetLink = c.datasetLink(row.Dataset, base)
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+	return rows
+}
+
+type triggerRow struct {
+	Name        string
+	Title       string
+	Location    string
+	Dataset     string
+	DatasetLink string
+	Query       string
+	Threshold   string
+	Frequency   string
+	Recipients  int
+	Disabled    bool
+	Description string
+	Issues      int
+}
+
+func (c *catalog) triggerRows(triggers []discovery.DiscoveredTrigger, base string) []triggerRow {
+	var rows []triggerRow
+	for _, t := range triggers {
+		row := triggerRow{
+			Name:        t.Name,
+			Title:       t.TriggerName,
+			Location:    c.location(t.File, t.Line),
+			Query:       refOrInline(t.QueryRef, t.InlineQuery),
+			Threshold:   fmt.Sprintf("%s %g", t.ThresholdOp, t.ThresholdValue),
+			Recipients:  t.RecipientCount,
+			Disabled:    t.Disabled,
+			Description: cmp.Or(t.Description, t.Doc),
+			Issues:      c.issueCounts[location(t.File, t.Line)],
+		}
+		if t.FrequencySeconds > 0 {
+			row.Frequency = advise.FormatSeconds(t.FrequencySeconds)
+		}
+		row.Dataset = c.triggerDataset(t)
+		row.DatasetLink = c.datasetLink(row.Dataset, base)
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+	return rows
+}
+
+type boardRow struct {
+	Name        string
+	Title       string
+	Location    string
+	Description string
+	Panels      int
+	Datasets    []datasetLink
+}
+
+type datasetLink struct {
+	Name, Link string
+}
+
+func (c *catalog) boardRows(boards []discovery.DiscoveredBoard, base string) []boardRow {
+	var rows []boardRow
+	for _, b := range boards {
+		row := boardRow{
+			Name:        b.Name,
+			Title:       b.BoardName,
+			Location:    c.location(b.File, b.Line),
+			Description: cmp.Or(b.Description, b.Doc),
+			Panels:      b.PanelCount,
+		}
+		for _, name := range c.boardDatasets(b) {
+			row.Datasets = append(row.Datasets, datasetLink{name, c.datasetLink(name, base)})
+		}
+		rows = append(rows, row)
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+	return rows
+}
+
+type issueRow struct {
+	Severity   string
+	Rule       string
+	Location   string
+	Message    string
+	Suggestion string
+}
+
+func (c *catalog) issueRows(issues []lint.Issue) []issueRow {
+	var rows []issueRow
+	for _, issue := range issues {
+		rows = append(rows, issueRow{
+			Severity:   issue.Severity.String(),
+			Rule:       issue.Rule,
+			Location:   c.location(issue.File, issue.Line),
+			Message:    issue.Message,
+			Suggestion: issue.Suggestion,
+		})
+	}
+	return rows
+}
+
+// location returns file:line with file relative to Options.Root.
+func (c *catalog) location(file string, line int) string {
+	if c.opts.Root != "" {
+		if rel, err := filepath.Rel(c.opts.Root, file); err == nil && !strings.HasPrefix(rel, "..") {
+			file = filepath.ToSlash(rel)
+		}
+	}
+	return location(file, line)
+}
+
+func location(file string, line int) string {
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// countSeverities counts issues by severity.
+func countSeverities(issues []lint.Issue) (errors, warnings, infos int) {
+	counts := lint.CountBySeverity(issues)
+	return counts[lint.SeverityError.String()], counts[lint.SeverityWarning.String()], counts[lint.SeverityInfo.String()]
+}
+
+// calculations describes the calculations of a query, e.g.
+// "P99(duration_ms), COUNT".
+func calculations(q discovery.DiscoveredQuery) string {
+	var calcs []string
+	for _, calc := range q.Calculations {
+		if calc.Column == "" {
+			calcs = append(calcs, calc.Op)
+		} else {
+			calcs = append(calcs, fmt.Sprintf("%s(%s)", calc.Op, calc.Column))
+		}
+	}
+	return strings.Join(calcs, ", ")
+}
+
+// timeRange describes a relative or absolute time range.
+func timeRange(tr discovery.TimeRange) string {
+	switch {
+	case tr.StartTime != 0 && tr.EndTime != 0:
+		return time.Unix(int64(tr.StartTime), 0).UTC().Format("2006-01-02 15:04") + " – " + time.Unix(int64(tr.EndTime), 0).UTC().Format("2006-01-02 15:04")
+	case tr.TimeRange > 0:
+		return advise.FormatSeconds(tr.TimeRange)
+	}
+	return ""
+}
+
+// refOrInline returns the referenced query's name, or "inline" for an inline
+// query.
+func refOrInline(ref string, inline *discovery.DiscoveredQuery) string {
+	if ref == "" && inline != nil {
+		return "inline"
+	}
+	return ref
+}
+
+// slugify turns a dataset name into a file name.
+func slugify(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('-')
+		}
+	}
+	slug := strings.Trim(b.String(), ".")
+	if slug == "" {
+		return "dataset"
+	}
+	return slug
+}
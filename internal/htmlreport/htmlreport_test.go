@@ -0,0 +1,186 @@
+package htmlreport
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lint"
+)
+
+func testResources() *discovery.DiscoveredResources {
+	latency := discovery.DiscoveredQuery{
+		Name: "Latency", Package: "queries", File: "/src/queries/api.go", Line: 5, Dataset: "api",
+		TimeRange:    discovery.TimeRange{TimeRange: 7200},
+		Calculations: []discovery.Calculation{{Op: "P99", Column: "duration_ms"}},
+		Filters:      []discovery.Filter{{Column: "error", Op: "exists"}},
+	}
+	return &discovery.DiscoveredResources{
+		Queries: []discovery.DiscoveredQuery{
+			latency,
+			// Queries can be discovered more than once
+			latency,
+			// The inline query of the Errors trigger, discovered under its name
+			{Name: "Errors", Package: "alerts", File: "/src/alerts/alerts.go", Line: 12, Dataset: "web app"},
+			{Name: "Logins", Package: "queries", File: "/src/queries/auth.go", Line: 3, Dataset: "auth", Doc: "Logins counts sign-ins."},
+		},
+		SLOs: []discovery.DiscoveredSLO{{
+			Name: "Availability", SLOName: "API availability", Package: "slos", File: "/src/slos/slos.go", Line: 8,
+			TargetPercentage: 99.9, TimePeriodDays: 30, GoodEventsQueryRef: "Latency", TotalEventsQueryRef: "Latency",
+		}},
+		Triggers: []discovery.DiscoveredTrigger{
+			{Name: "SlowAPI", TriggerName: "Slow API", Package: "alerts", File: "/src/alerts/alerts.go", Line: 3,
+				QueryRef: "Latency", ThresholdOp: ">", ThresholdValue: 500, FrequencySeconds: 300, RecipientCount: 1,
+				Doc: "SlowAPI pages on slow requests."},
+			{Name: "Errors", Package: "alerts", File: "/src/alerts/alerts.go", Line: 10,
+				InlineQuery: &discovery.DiscoveredQuery{Dataset: "web app"}, ThresholdOp: ">", ThresholdValue: 0},
+		},
+		Boards: []discovery.DiscoveredBoard{{
+			Name: "Overview", BoardName: "<Overview>", Package: "boards", File: "/src/boards/boards.go", Line: 4,
+			PanelCount: 2, QueryRefs: []string{"Latency", "Logins"}, SLORefs: []string{"Availability"},
+		}},
+		Schemas: []discovery.DiscoveredSchema{{Dataset: "api", RetentionDays: 30, EventsPerDay: 2_000_000_000}},
+	}
+}
+
+func testIssues() []lint.Issue {
+	return []lint.Issue{
+		{Rule: "WHC001", Severity: lint.SeverityError, File: "/src/queries/api.go", Line: 5, Message: "bad <query>"},
+		{Rule: "WHC050", Severity: lint.SeverityWarning, File: "/src/alerts/alerts.go", Line: 10, Message: "no recipients"},
+		{Rule: "WHC050", Severity: lint.SeverityWarning, File: "/src/alerts/other.go", Line: 1, Message: "no recipients"},
+	}
+}
+
+func readPage(t *testing.T, dir, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, path))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestWrite(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "site")
+	pages, err := Write(dir, testResources(), testIssues(), Options{Root: "/src"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"index.html",
+		"lint.html",
+		"datasets/api.html",
+		"datasets/auth.html",
+		"datasets/web-app.html",
+	}, pages)
+
+	index := readPage(t, dir, "index.html")
+	assert.Contains(t, index, "<title>Honeycomb resources</title>")
+	assert.Contains(t, index, `<b>2</b>queries`, "inline queries are not counted")
+	assert.Contains(t, index, `<a href="datasets/web-app.html">web app</a>`)
+	assert.Contains(t, index, `<b class="error">1 / 2 / 0</b>`)
+	assert.Contains(t, index, `<tr id="board-Overview">`)
+	assert.Contains(t, index, "&lt;Overview&gt;", "names are escaped")
+	assert.Contains(t, index, `<span class="location">alerts/alerts.go:3</span>`)
+	assert.Contains(t, index, "<td>Latency / Latency</td>")
+	assert.Contains(t, index, "<td>inline</td>")
+	assert.Contains(t, index, "<td>SlowAPI pages on slow requests.</td>", "doc comments describe resources without a description")
+	assert.Contains(t, index, `<svg class="graph"`)
+	assert.Contains(t, index, `<a href="datasets/api.html#trigger-SlowAPI">`)
+	assert.NotContains(t, index, "Generated by wetwire-honeycomb at")
+
+	api := readPage(t, dir, "datasets/api.html")
+	assert.Contains(t, api, "<title>api · Honeycomb resources</title>")
+	assert.Contains(t, api, "30d retention · 2B events/day")
+	assert.Contains(t, api, `<tr id="query-Latency">`)
+	assert.Contains(t, api, "<code>P99(duration_ms)</code>")
+	assert.Contains(t, api, "<code>error exists</code>")
+	assert.Contains(t, api, "<td>2h</td>")
+	assert.Contains(t, api, `<a href="../datasets/api.html#slo-Availability">`)
+	assert.Contains(t, api, `<a href="../index.html#board-Overview">`)
+	assert.Contains(t, api, "bad &lt;query&gt;")
+	assert.NotContains(t, api, "no recipients")
+	assert.NotContains(t, api, "Logins", "queries of other datasets are left out")
+
+	assert.Contains(t, readPage(t, dir, "datasets/auth.html"), "<br>Logins counts sign-ins.</td>")
+
+	webApp := readPage(t, dir, "datasets/web-app.html")
+	assert.Contains(t, webApp, `<tr id="trigger-Errors">`)
+	assert.Contains(t, webApp, `<span class="warning">none</span>`)
+	assert.Contains(t, webApp, "no recipients")
+
+	lintPage := readPage(t, dir, "lint.html")
+	assert.Contains(t, lintPage, `<tr><td>WHC050</td><td class="warning">warning</td><td>2</td></tr>`)
+	assert.Contains(t, lintPage, `<td class="location">alerts/other.go:1</td>`)
+}
+
+func TestWrite_Options(t *testing.T) {
+	dir := t.TempDir()
+	generated := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	_, err := Write(dir, testResources(), nil, Options{Title: "Checkout", Generated: generated})
+	require.NoError(t, err)
+
+	index := readPage(t, dir, "index.html")
+	assert.Contains(t, index, "<title>Checkout</title>")
+	assert.Contains(t, index, "Generated by wetwire-honeycomb at 2026-03-01T12:00:00Z")
+	assert.Contains(t, index, "/src/slos/slos.go:8", "locations are absolute without a root")
+
+	assert.Contains(t, readPage(t, dir, "lint.html"), "No lint issues.")
+}
+
+func TestWrite_Empty(t *testing.T) {
+	dir := t.TempDir()
+	pages, err := Write(dir, &discovery.DiscoveredResources{}, nil, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"index.html", "lint.html"}, pages)
+
+	index := readPage(t, dir, "index.html")
+	assert.Contains(t, index, "No datasets.")
+	assert.Contains(t, index, "No resources.")
+	assert.NotContains(t, index, "<svg")
+}
+
+func TestGraph(t *testing.T) {
+	c := newCatalog(testResources(), nil, Options{})
+	g := c.graph(c.queries, c.resources.SLOs, c.resources.Triggers, c.resources.Boards)
+
+	require.Len(t, g.columns, 3)
+	var names [][]string
+	for _, column := range g.columns {
+		var col []string
+		for _, n := range column {
+			col = append(col, n.kind+" "+n.name)
+		}
+		names = append(names, col)
+	}
+	assert.Equal(t, [][]string{
+		{"board Overview"},
+		{"slo Availability", "trigger SlowAPI", "trigger Errors"},
+		{"query Latency", "query Logins"},
+	}, names)
+
+	var edges []string
+	for _, e := range g.edges {
+		edges = append(edges, e.from.name+" -> "+e.to.name)
+	}
+	assert.Equal(t, []string{
+		"Overview -> Latency",
+		"Overview -> Logins",
+		"Overview -> Availability",
+		"Availability -> Latency",
+		"SlowAPI -> Latency",
+	}, edges)
+
+	// Only the given resources are drawn
+	g = c.graph(nil, nil, c.resources.Triggers[:1], nil)
+	require.Len(t, g.columns, 1)
+	assert.Empty(t, g.edges)
+}
+
+func TestSlugify(t *testing.T) {
+	assert.Equal(t, "api", slugify("api"))
+	assert.Equal(t, "web-app", slugify("Web App"))
+	assert.Equal(t, "otel-demo.v2", slugify("otel-demo.v2"))
+	assert.Equal(t, "dataset", slugify(".."))
+}
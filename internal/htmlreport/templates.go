@@ -0,0 +1,192 @@
+package htmlreport
+
+import "html/template"
+
+// pageTemplates holds the "index", "dataset" and "lint" page templates.
+var pageTemplates = template.Must(template.New("site").Parse(layoutTemplate + tablesTemplate + indexTemplate + datasetTemplate + lintTemplate))
+
+const layoutTemplate = `
+{{define "header"}}<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{if ne .Title .SiteTitle}}{{.Title}} · {{end}}{{.SiteTitle}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 0; color: #1f2328; }
+header { background: #1f2328; padding: 12px 24px; }
+header a { color: #fff; text-decoration: none; margin-right: 16px; }
+main { padding: 8px 24px 24px; }
+footer { padding: 12px 24px; color: #656d76; font-size: 12px; }
+h2 { margin-top: 32px; }
+table { border-collapse: collapse; width: 100%; font-size: 14px; }
+th, td { text-align: left; padding: 6px 8px; border-bottom: 1px solid #d0d7de; vertical-align: top; }
+th { background: #f6f8fa; }
+tr:target { background: #fff8c5; }
+code, .location { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 12px; }
+.location { color: #656d76; }
+.cards { display: flex; flex-wrap: wrap; gap: 12px; }
+.card { border: 1px solid #d0d7de; border-radius: 6px; padding: 12px 16px; min-width: 100px; }
+.card b { display: block; font-size: 24px; }
+.error { color: #cf222e; }
+.warning { color: #9a6700; }
+.info { color: #0969da; }
+.muted { color: #656d76; }
+.graph-wrap { overflow-x: auto; border: 1px solid #d0d7de; border-radius: 6px; }
+.graph .edge { fill: none; stroke: #8c959f; stroke-width: 1.5; }
+.graph marker path { fill: #8c959f; }
+.graph rect { stroke-width: 1.5; }
+.graph text { font-size: 12px; text-anchor: middle; fill: #1f2328; }
+.graph .query rect { fill: #ddf4ff; stroke: #0969da; }
+.graph .slo rect { fill: #dafbe1; stroke: #1a7f37; }
+.graph .trigger rect { fill: #fff8c5; stroke: #9a6700; }
+.graph .board rect { fill: #fbefff; stroke: #8250df; }
+</style>
+</head>
+<body>
+<header><a href="{{.Base}}index.html"><b>{{.SiteTitle}}</b></a><a href="{{.Base}}lint.html">Lint</a></header>
+<main>
+<h1>{{.Title}}</h1>
+{{end}}
+
+{{define "footer"}}</main>
+<footer>Generated by wetwire-honeycomb{{with .Generated}} at {{.}}{{end}}</footer>
+</body>
+</html>
+{{end}}
+
+{{define "issues"}}{{if .}}<span class="error">{{.}}</span>{{end}}{{end}}
+
+{{define "graph"}}{{if .}}<div class="graph-wrap">{{.}}</div>{{else}}<p class="muted">No resources.</p>{{end}}{{end}}
+`
+
+const tablesTemplate = `
+{{define "slos"}}{{if .}}<table>
+<tr><th>SLO</th><th>Dataset</th><th>Target</th><th>Window</th><th>Good / total events</th><th>Burn alerts</th><th>Description</th><th>Issues</th></tr>
+{{range .}}<tr id="slo-{{.Name}}">
+<td>{{with .Title}}{{.}}<br>{{end}}<code>{{.Name}}</code><br><span class="location">{{.Location}}</span></td>
+<td>{{if .DatasetLink}}<a href="{{.DatasetLink}}">{{.Dataset}}</a>{{end}}</td>
+<td>{{.Target}}</td><td>{{.Window}}</td>
+<td>{{.GoodEvents}} / {{.TotalEvents}}</td>
+<td>{{.BurnAlerts}}</td>
+<td>{{.Description}}</td>
+<td>{{template "issues" .Issues}}</td>
+</tr>
+{{end}}</table>{{else}}<p class="muted">No SLOs.</p>{{end}}{{end}}
+
+{{define "triggers"}}{{if .}}<table>
+<tr><th>Trigger</th><th>Dataset</th><th>Query</th><th>Threshold</th><th>Frequency</th><th>Recipients</th><th>Description</th><th>Issues</th></tr>
+{{range .}}<tr id="trigger-{{.Name}}">
+<td>{{with .Title}}{{.}}<br>{{end}}<code>{{.Name}}</code>{{if .Disabled}} <span class="muted">(disabled)</span>{{end}}<br><span class="location">{{.Location}}</span></td>
+<td>{{if .DatasetLink}}<a href="{{.DatasetLink}}">{{.Dataset}}</a>{{end}}</td>
+<td>{{.Query}}</td><td><code>{{.Threshold}}</code></td><td>{{.Frequency}}</td>
+<td>{{if .Recipients}}{{.Recipients}}{{else}}<span class="warning">none</span>{{end}}</td>
+<td>{{.Description}}</td>
+<td>{{template "issues" .Issues}}</td>
+</tr>
+{{end}}</table>{{else}}<p class="muted">No triggers.</p>{{end}}{{end}}
+
+{{define "boards"}}{{if .}}<table>
+<tr><th>Board</th><th>Datasets</th><th>Panels</th><th>Description</th></tr>
+{{range .}}<tr id="board-{{.Name}}">
+<td>{{with .Title}}{{.}}<br>{{end}}<code>{{.Name}}</code><br><span class="location">{{.Location}}</span></td>
+<td>{{range $i, $ds := .Datasets}}{{if $i}}, {{end}}<a href="{{$ds.Link}}">{{$ds.Name}}</a>{{end}}</td>
+<td>{{.Panels}}</td>
+<td>{{.Description}}</td>
+</tr>
+{{end}}</table>{{else}}<p class="muted">No boards.</p>{{end}}{{end}}
+
+{{define "issueTable"}}{{if .}}<table>
+<tr><th>Severity</th><th>Rule</th><th>Location</th><th>Message</th></tr>
+{{range .}}<tr>
+<td class="{{.Severity}}">{{.Severity}}</td><td>{{.Rule}}</td>
+<td class="location">{{.Location}}</td>
+<td>{{.Message}}{{with .Suggestion}}<br><span class="muted">{{.}}</span>{{end}}</td>
+</tr>
+{{end}}</table>{{else}}<p class="muted">No lint issues.</p>{{end}}{{end}}
+`
+
+const indexTemplate = `
+{{define "index"}}{{template "header" .Page}}
+<div class="cards">
+<div class="card"><b>{{len .Datasets}}</b>datasets</div>
+<div class="card"><b>{{.Queries}}</b>queries</div>
+<div class="card"><b>{{.SLOs}}</b>SLOs</div>
+<div class="card"><b>{{.Triggers}}</b>triggers</div>
+<div class="card"><b>{{.Boards}}</b>boards</div>
+<div class="card"><a href="lint.html"><b class="{{if .Errors}}error{{else if .Warnings}}warning{{end}}">{{.Errors}} / {{.Warnings}} / {{.Infos}}</b></a>lint errors / warnings / info</div>
+</div>
+
+<h2>Datasets</h2>
+{{if .Datasets}}<table>
+<tr><th>Dataset</th><th>Queries</th><th>SLOs</th><th>Triggers</th><th>Boards</th><th>Retention</th><th>Issues</th></tr>
+{{range .Datasets}}<tr>
+<td><a href="datasets/{{.Slug}}.html">{{.Name}}</a></td>
+<td>{{.Queries}}</td><td>{{.SLOs}}</td><td>{{.Triggers}}</td><td>{{.Boards}}</td>
+<td>{{.Retention}}</td>
+<td>{{template "issues" .Issues}}</td>
+</tr>
+{{end}}</table>{{else}}<p class="muted">No datasets.</p>{{end}}
+
+<h2>Dependencies</h2>
+{{template "graph" .Graph}}
+
+<h2>SLOs</h2>
+{{template "slos" .SLORows}}
+
+<h2>Triggers</h2>
+{{template "triggers" .TriggerRows}}
+
+<h2>Boards</h2>
+{{template "boards" .BoardRows}}
+{{template "footer" .Page}}{{end}}
+`
+
+const datasetTemplate = `
+{{define "dataset"}}{{template "header" .Page}}
+<p>{{if .Columns}}{{.Columns}} schema columns{{else}}No schema declared{{end}}{{with .Retention}} · {{.}} retention{{end}}{{with .Volume}} · {{.}}{{end}}</p>
+
+<h2>Dependencies</h2>
+{{template "graph" .Graph}}
+
+<h2>Queries</h2>
+{{if .QueryRows}}<table>
+<tr><th>Query</th><th>Calculations</th><th>Filters</th><th>Breakdowns</th><th>Time range</th><th>Issues</th></tr>
+{{range .QueryRows}}<tr id="query-{{.Name}}">
+<td><code>{{.Name}}</code><br><span class="location">{{.Location}}</span>{{with .Description}}<br>{{.}}{{end}}</td>
+<td>{{with .Calculations}}<code>{{.}}</code>{{end}}</td>
+<td>{{with .Filters}}<code>{{.}}</code>{{end}}</td>
+<td>{{.Breakdowns}}</td>
+<td>{{.TimeRange}}</td>
+<td>{{template "issues" .Issues}}</td>
+</tr>
+{{end}}</table>{{else}}<p class="muted">No queries.</p>{{end}}
+
+<h2>SLOs</h2>
+{{template "slos" .SLORows}}
+
+<h2>Triggers</h2>
+{{template "triggers" .TriggerRows}}
+
+<h2>Boards</h2>
+{{template "boards" .BoardRows}}
+
+<h2>Lint</h2>
+{{template "issueTable" .Issues}}
+{{template "footer" .Page}}{{end}}
+`
+
+const lintTemplate = `
+{{define "lint"}}{{template "header" .Page}}
+<p><span class="error">{{.Errors}} errors</span> · <span class="warning">{{.Warnings}} warnings</span> · <span class="info">{{.Infos}} info</span></p>
+
+{{if .Rules}}<h2>By rule</h2>
+<table>
+<tr><th>Rule</th><th>Severity</th><th>Issues</th></tr>
+{{range .Rules}}<tr><td>{{.Rule}}</td><td class="{{.Severity}}">{{.Severity}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>{{end}}
+
+<h2>Issues</h2>
+{{template "issueTable" .Issues}}
+{{template "footer" .Page}}{{end}}
+`
@@ -0,0 +1,88 @@
+// Package i18n translates user-facing CLI messages. Messages are looked up by
+// their English format string, so untranslated messages, and every message
+// in English, are printed as written.
+package i18n
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// English is the language messages are written in.
+const English = "en"
+
+// catalogs holds the translations of each language other than English,
+// keyed by English format string.
+var catalogs = map[string]map[string]string{
+	"ja": ja,
+}
+
+// Languages returns the supported languages, English first.
+func Languages() []string {
+	return []string{English, "ja"}
+}
+
+// Parse returns the supported language of a language name or POSIX locale
+// such as "ja", "ja_JP.UTF-8" or "en_US". The C and POSIX locales are English.
+func Parse(locale string) (string, bool) {
+	lang := strings.ToLower(locale)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	switch {
+	case lang == English, lang == "c", lang == "posix":
+		return English, true
+	case catalogs[lang] != nil:
+		return lang, true
+	}
+	return "", false
+}
+
+// language is the language set with SetLanguage, empty when none is.
+var language string
+
+// SetLanguage sets the output language, a value returned by Parse, in place
+// of the locale's. The --lang flag sets it. An empty lang restores the
+// locale's.
+func SetLanguage(lang string) {
+	language = lang
+}
+
+// Language returns the output language: the one set with SetLanguage, or
+// otherwise the first of LC_ALL, LC_MESSAGES and LANG that is set, as for
+// other POSIX programs. Unsupported languages fall back to English.
+func Language() string {
+	if language != "" {
+		return language
+	}
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(key); value != "" {
+			if lang, ok := Parse(value); ok {
+				return lang
+			}
+			return English
+		}
+	}
+	return English
+}
+
+// T returns the translation of msg in the output language.
+func T(msg string) string {
+	if translated, ok := catalogs[Language()][msg]; ok {
+		return translated
+	}
+	return msg
+}
+
+// Sprintf formats the translation of format in the output language.
+// Translations may reorder arguments with explicit indexes such as %[2]s.
+func Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(T(format), args...)
+}
+
+// Fprintf writes the translation of format in the output language to w.
+func Fprintf(w io.Writer, format string, args ...any) {
+	fmt.Fprint(w, Sprintf(format, args...))
+}
@@ -0,0 +1,86 @@
+package i18n
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// clearLocale unsets every variable Language reads, and the language set
+// with SetLanguage until the test ends.
+func clearLocale(t *testing.T) {
+	for _, key := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		t.Setenv(key, "")
+	}
+	SetLanguage("")
+	t.Cleanup(func() { SetLanguage("") })
+}
+
+func TestParse(t *testing.T) {
+	for locale, want := range map[string]string{
+		"ja":          "ja",
+		"ja_JP.UTF-8": "ja",
+		"JA-jp":       "ja",
+		"en_US.UTF-8": English,
+		"C":           English,
+		"POSIX":       English,
+	} {
+		lang, ok := Parse(locale)
+		assert.True(t, ok, locale)
+		assert.Equal(t, want, lang, locale)
+	}
+
+	_, ok := Parse("fr_FR.UTF-8")
+	assert.False(t, ok)
+}
+
+func TestLanguage(t *testing.T) {
+	clearLocale(t)
+	assert.Equal(t, English, Language())
+
+	t.Setenv("LANG", "ja_JP.UTF-8")
+	assert.Equal(t, "ja", Language())
+
+	t.Setenv("LC_ALL", "C")
+	assert.Equal(t, English, Language(), "LC_ALL takes precedence over LANG")
+
+	SetLanguage("ja")
+	assert.Equal(t, "ja", Language(), "SetLanguage takes precedence over the locale")
+
+	SetLanguage("")
+	t.Setenv("LC_ALL", "fr_FR.UTF-8")
+	assert.Equal(t, English, Language(), "unsupported languages fall back to English")
+}
+
+func TestSprintf(t *testing.T) {
+	clearLocale(t)
+	assert.Equal(t, "Discovered 3 resources", Sprintf("Discovered %d resources", 3))
+	assert.Equal(t, "Not in the catalog 3", Sprintf("Not in the catalog %d", 3))
+
+	SetLanguage("ja")
+	assert.Equal(t, "3 件のリソースを検出しました", Sprintf("Discovered %d resources", 3))
+	assert.Equal(t, "api.json に 2 件の問題があります", Sprintf("%d problem(s) in %s", 2, "api.json"))
+	assert.Equal(t, "Not in the catalog 3", Sprintf("Not in the catalog %d", 3))
+}
+
+// verb matches the formatting verbs of catalog keys.
+var verb = regexp.MustCompile(`%[-+# 0-9]*[a-z]`)
+
+func TestCatalogs_MatchVerbs(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for msg, translated := range catalog {
+			var args []any
+			for _, v := range verb.FindAllString(msg, -1) {
+				if strings.HasSuffix(v, "d") {
+					args = append(args, 1)
+				} else {
+					args = append(args, "x")
+				}
+			}
+			assert.NotContains(t, fmt.Sprintf(translated, args...), "%!", "%s: %q", lang, msg)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package i18n
+
+// ja is the Japanese catalog.
+var ja = map[string]string{
+	// Result status
+	"✓ Success": "✓ 成功",
+	"✗ Failed":  "✗ 失敗",
+	"Errors:":   "エラー:",
+	"Data:":     "データ:",
+
+	// build
+	"Build completed":    "ビルドが完了しました",
+	"Wrote %s":           "%s に書き込みました",
+	"no resources found": "リソースが見つかりません",
+	"no queries, boards, SLOs, or triggers found":      "クエリ、ボード、SLO、トリガーが見つかりません",
+	"environment interpolation failed":                 "環境変数の展開に失敗しました",
+	"invalid time ranges":                              "無効な時間範囲があります",
+	"unknown or mistyped fields":                       "不明なフィールドまたは型の誤ったフィールドがあります",
+	"duplicate trigger or SLO names":                   "トリガーまたは SLO の名前が重複しています",
+	"fields deprecated by the Honeycomb API":           "Honeycomb API で非推奨のフィールドがあります",
+	"no boards found":                                  "ボードが見つかりません",
+	"grafana export requires at least one board":       "Grafana へのエクスポートには 1 つ以上のボードが必要です",
+	"Wrote %d dashboard(s) to %s (%d mapping note(s))": "%[2]s にダッシュボード %[1]d 件を書き込みました (変換メモ %[3]d 件)",
+	"%d board feature(s) could not be mapped exactly:": "%d 件のボード機能を正確に変換できませんでした:",
+	"Payload sizes:":                                   "ペイロードサイズ:",
+	"%8d bytes  largest %s (%d bytes)":                 "%8d バイト  最大 %s (%d バイト)",
+	"%8d bytes":                                        "%8d バイト",
+	"Resource IDs:":                                    "リソース ID:",
+	"%d resource(s) failed to build":                   "%d 件のリソースのビルドに失敗しました",
+	"Wrote %s with %d failed resource(s)":              "%s に書き込みました (失敗したリソース %d 件)",
+
+	// lint
+	"No lint issues found": "lint の問題は見つかりませんでした",
+	"No lint issues found (fix mode requested, no fixable issues)": "lint の問題は見つかりませんでした (修正モード: 修正できる問題はありません)",
+	"lint issues found": "lint の問題が見つかりました",
+	"lint issues found (auto-fix not yet implemented)":            "lint の問題が見つかりました (自動修正は未実装です)",
+	"Security: %d potential secret(s) or sensitive field(s) (%s)": "セキュリティ: 秘密情報または機密フィールドの可能性 %d 件 (%s)",
+	"%s: see above":               "%s: 上記を参照",
+	"Rule timings (%s in rules):": "ルールごとの所要時間 (ルール合計 %s):",
+	"RULE":                        "ルール",
+	"CHECKS":                      "チェック数",
+	"TIME":                        "時間",
+	"ALLOCS":                      "割り当て数",
+	"BYTES":                       "バイト数",
+	"slow":                        "低速",
+	"%d rule(s) took more than %s per resource": "%d 件のルールがリソースあたり %s を超えました",
+
+	// list, graph, validate, import, init
+	"Discovered %d resources":      "%d 件のリソースを検出しました",
+	"Graph generated":              "グラフを生成しました",
+	"invalid JSON":                 "無効な JSON です",
+	"%d problem(s) in %s":          "%[2]s に %[1]d 件の問題があります",
+	"Validated %d queries in %s":   "%[2]s のクエリ %[1]d 件を検証しました",
+	"import failed":                "インポートに失敗しました",
+	"no queries found":             "クエリが見つかりません",
+	"input contains no Query JSON": "入力に Query JSON が含まれていません",
+	"Imported %d queries":          "%d 件のクエリをインポートしました",
+	"target exists":                "出力先が既に存在します",
+	"file already exists; remove it or choose another --target": "ファイルが既に存在します。削除するか、別の --target を指定してください",
+	"Imported %d queries, %d boards and %d triggers from %s":    "%[4]s から %[1]d 件のクエリ、%[2]d 件のボード、%[3]d 件のトリガーをインポートしました",
+	"(%d features not converted exactly)":                       "(%d 件の機能は正確に変換されていません)",
+	"no panel or alert could be converted":                      "変換できるパネルまたはアラートがありません",
+	"%s to %s":                                                  "%s: %s",
+	"Created scenario %s with %d files":                         "シナリオ %s を作成しました (%d ファイル)",
+	"Created %s with example queries":                           "サンプルクエリ付きの %s を作成しました",
+
+	// import of triggers and boards
+	"Imported %d queries, %d boards and %d triggers":                                         "%d 件のクエリ、%d 件のボード、%d 件のトリガーをインポートしました",
+	"(%d duplicate query specs share a variable)":                                            "(重複する %d 件のクエリ仕様は変数を共有しています)",
+	"(skipped %s: only queries, and triggers and boards embedding queries, can be imported)": "(%s をスキップしました: インポートできるのはクエリと、クエリを含むトリガーおよびボードのみです)",
+
+	// build, list and graph --only and --exclude
+	"no resources match --only and --exclude": "--only と --exclude に一致するリソースがありません",
+
+	// list
+	"TYPE":        "種類",
+	"NAME":        "名前",
+	"FILE":        "ファイル",
+	"DESCRIPTION": "説明",
+	"QUERY":       "クエリ",
+
+	// diff, lock
+	"No differences between %s and generated output": "%s と生成された出力に差分はありません",
+	"No differences between %s and %s":               "%s と %s に差分はありません",
+	"Comparing %s vs %s":                             "%s と %s を比較しています",
+	"Summary: %d added, %d removed, %d modified":     "概要: 追加 %d、削除 %d、変更 %d",
+	"code vs lockfile":                               "コードとロックファイル",
+	"code vs lockfile vs live":                       "コード、ロックファイル、本番環境",
+	"Reconciling %s (%s)":                            "%s を照合しています (%s)",
+	"%d resource(s): %d in sync":                     "リソース %d 件: 同期済み %d 件",
+
+	// discovery diagnostics
+	"warning: skipped %s in %s: discovery panicked: %s":                        "警告: %[2]s の %[1]s をスキップしました: 検出中にパニックが発生しました: %[3]s",
+	"Rerun with --debug-bundle FILE.zip and attach the bundle to a bug report": "--debug-bundle FILE.zip を付けて再実行し、バンドルをバグ報告に添付してください",
+	"Wrote diagnostics bundle %s":                                              "診断バンドル %s を書き込みました",
+}
@@ -101,7 +101,7 @@ var APIAvailability = slo.SLO{
 }
 ` + "```" + `
 
-**Target functions**: Percentage(n) where n is 0-100
+**Target functions**: Percentage(n) where n is 0-100, PerMillion(n) where n is 0-1000000
 **TimePeriod functions**: Days(n)
 **BurnAlert helpers**: FastBurn(), SlowBurn()
 
@@ -119,7 +119,7 @@ var HighLatencyAlert = trigger.Trigger{
     Frequency:   trigger.Minutes(5),
     Recipients: []trigger.Recipient{
         trigger.SlackChannel("#alerts"),
-        trigger.PagerDutyService("production-oncall"),
+        trigger.PagerDutyService("fc2331008374007fbfeb0c72cb750cb4"),
         trigger.EmailAddress("oncall@example.com"),
     },
 }
@@ -127,7 +127,7 @@ var HighLatencyAlert = trigger.Trigger{
 
 **Threshold functions**: GreaterThan(n), GreaterThanOrEqual(n), LessThan(n), LessThanOrEqual(n)
 **Frequency functions**: Minutes(n), Seconds(n)
-**Recipient functions**: SlackChannel(channel), PagerDutyService(service), EmailAddress(email), WebhookURL(url)
+**Recipient functions**: SlackChannel(channel), PagerDutyService(routingKey), EmailAddress(email), WebhookURL(url)
 
 ## Board Syntax
 
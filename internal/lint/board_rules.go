@@ -2,6 +2,7 @@ package lint
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
 )
@@ -18,7 +19,10 @@ type BoardRule struct {
 func AllBoardRules() []BoardRule {
 	return []BoardRule{
 		WHC030BoardHasNoPanels(),
+		WHC031BoardSectionHasNoPanels(),
+		WHC032InvalidGraphSettings(),
 		WHC034BoardExceedsPanelLimit(),
+		WHC035BoardSecret(),
 	}
 }
 
@@ -45,6 +49,75 @@ func WHC030BoardHasNoPanels() BoardRule {
 	}
 }
 
+// WHC031BoardSectionHasNoPanels checks if a board section groups no panels.
+func WHC031BoardSectionHasNoPanels() BoardRule {
+	return BoardRule{
+		Code:     "WHC031",
+		Severity: SeverityWarning,
+		Message:  "Board section has no panels",
+		Check: func(board discovery.DiscoveredBoard) []Issue {
+			var issues []Issue
+			for _, section := range board.Sections {
+				if section.PanelCount == 0 {
+					issues = append(issues, Issue{
+						Rule:     "WHC031",
+						Severity: SeverityWarning,
+						Message:  fmt.Sprintf("Board section %q has no panels", section.Title),
+						File:     board.File,
+						Line:     section.Line,
+					})
+				}
+			}
+			return issues
+		},
+	}
+}
+
+// WHC032InvalidGraphSettings checks the graph settings of board panels:
+// unknown query styles and chart types, settings that cannot be combined,
+// such as a log scale on a pie chart, and settings on panels other than
+// query panels, which ignore them.
+func WHC032InvalidGraphSettings() BoardRule {
+	return BoardRule{
+		Code:     "WHC032",
+		Severity: SeverityError,
+		Message:  "Invalid panel graph settings",
+		Check: func(board discovery.DiscoveredBoard) []Issue {
+			var issues []Issue
+			for i, panel := range board.Panels {
+				if panel.Graph.IsZero() {
+					continue
+				}
+				label := fmt.Sprintf("panel %d", i+1)
+				if panel.Title != "" {
+					label = fmt.Sprintf("panel %q", panel.Title)
+				}
+				line := panel.Line
+				if line == 0 {
+					line = board.Line
+				}
+
+				var messages []string
+				if panel.Type != "query" {
+					messages = append(messages, fmt.Sprintf("graph settings only apply to query panels, not %s panels", panel.Type))
+				} else if err := panel.Graph.Validate(); err != nil {
+					messages = strings.Split(err.Error(), "\n")
+				}
+				for _, message := range messages {
+					issues = append(issues, Issue{
+						Rule:     "WHC032",
+						Severity: SeverityError,
+						Message:  fmt.Sprintf("Invalid graph settings of %s: %s", label, message),
+						File:     board.File,
+						Line:     line,
+					})
+				}
+			}
+			return issues
+		},
+	}
+}
+
 // WHC034BoardExceedsPanelLimit checks if a board exceeds the recommended panel limit.
 func WHC034BoardExceedsPanelLimit() BoardRule {
 	return BoardRule{
@@ -68,3 +141,15 @@ func WHC034BoardExceedsPanelLimit() BoardRule {
 		},
 	}
 }
+
+// WHC035BoardSecret checks a board's description for potential secrets.
+func WHC035BoardSecret() BoardRule {
+	return BoardRule{
+		Code:     "WHC035",
+		Severity: SeverityError,
+		Message:  "Potential secret in board",
+		Check: func(board discovery.DiscoveredBoard) []Issue {
+			return secretIssues("WHC035", "Description", board.Description, board.File, board.Line)
+		},
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/lex00/wetwire-honeycomb-go/board"
 	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
 )
 
@@ -50,6 +52,58 @@ func TestWHC030BoardHasNoPanels(t *testing.T) {
 	}
 }
 
+func TestWHC031BoardSectionHasNoPanels(t *testing.T) {
+	rule := WHC031BoardSectionHasNoPanels()
+
+	board := discovery.DiscoveredBoard{
+		Name:       "Dashboard",
+		PanelCount: 2,
+		File:       "test.go",
+		Line:       10,
+		Sections: []discovery.DiscoveredSection{
+			{Title: "Latency", Line: 13, PanelCount: 2},
+			{Title: "Errors", Line: 17},
+			{Title: "Saturation", Line: 18},
+		},
+	}
+
+	results := rule.Check(board)
+	require.Len(t, results, 2)
+	assert.Equal(t, "WHC031", results[0].Rule)
+	assert.Equal(t, SeverityWarning, results[0].Severity)
+	assert.Equal(t, 17, results[0].Line)
+	assert.Contains(t, results[0].Message, `"Errors"`)
+	assert.Equal(t, 18, results[1].Line)
+
+	board.Sections = board.Sections[:1]
+	assert.Empty(t, rule.Check(board))
+}
+
+func TestWHC032InvalidGraphSettings(t *testing.T) {
+	rule := WHC032InvalidGraphSettings()
+
+	b := discovery.DiscoveredBoard{
+		File: "boards.go",
+		Line: 10,
+		Panels: []discovery.DiscoveredPanel{
+			{Type: "query", Title: "Traffic", Line: 12, Graph: board.GraphSettings{ChartType: board.ChartBar, Stacked: true}},
+			{Type: "query", Title: "Routes", Line: 13, Graph: board.GraphSettings{ChartType: board.ChartPie, LogScale: true}},
+			{Type: "query", Line: 14, Graph: board.GraphSettings{Stacked: true, LogScale: true}},
+			{Type: "text", Title: "Runbook", Line: 15, Graph: board.GraphSettings{LogScale: true}},
+			{Type: "query", Line: 16},
+		},
+	}
+
+	results := rule.Check(b)
+	require.Len(t, results, 3)
+	assert.Equal(t, "WHC032", results[0].Rule)
+	assert.Equal(t, SeverityError, results[0].Severity)
+	assert.Equal(t, 13, results[0].Line)
+	assert.Equal(t, `Invalid graph settings of panel "Routes": a cpie chart has no value axis for a log scale`, results[0].Message)
+	assert.Contains(t, results[1].Message, "panel 3: stacked graphs cannot use a log scale")
+	assert.Equal(t, `Invalid graph settings of panel "Runbook": graph settings only apply to query panels, not text panels`, results[2].Message)
+}
+
 func TestWHC034BoardExceedsPanelLimit(t *testing.T) {
 	rule := WHC034BoardExceedsPanelLimit()
 
@@ -102,6 +156,38 @@ func TestWHC034BoardExceedsPanelLimit(t *testing.T) {
 	}
 }
 
+func TestWHC035BoardSecret(t *testing.T) {
+	rule := WHC035BoardSecret()
+
+	tests := []struct {
+		name      string
+		board     discovery.DiscoveredBoard
+		wantCount int
+	}{
+		{
+			name:      "plain description",
+			board:     discovery.DiscoveredBoard{Description: "Service health and API tokens issued"},
+			wantCount: 0,
+		},
+		{
+			name:      "token in description",
+			board:     discovery.DiscoveredBoard{Description: "Share link: https://ui.example.com/board?token=f00dbabe"},
+			wantCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := rule.Check(tt.board)
+			assert.Len(t, results, tt.wantCount)
+			if tt.wantCount > 0 {
+				assert.Equal(t, "WHC035", results[0].Rule)
+				assert.Equal(t, SeverityError, results[0].Severity)
+			}
+		})
+	}
+}
+
 func TestAllBoardRules(t *testing.T) {
 	rules := AllBoardRules()
 	assert.GreaterOrEqual(t, len(rules), 2) // At least WHC030 and WHC034
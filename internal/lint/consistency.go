@@ -0,0 +1,160 @@
+package lint
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC060 and WHC061 check SLOs and triggers against each other. They need
+// every resource of the project, so they run from LintAllWithConfig rather
+// than AllSLORules and AllTriggerRules.
+const (
+	// WHC060 flags SLOs that nothing alerts on: no burn alert and no
+	// latency or error trigger on the SLI's datasets
+	WHC060 = "WHC060"
+
+	// WHC061 flags triggers on a query with breakdowns that leave the
+	// alert type to Honeycomb's default
+	WHC061 = "WHC061"
+)
+
+// LintUnalertedSLOs returns a WHC060 warning for each SLO of resources with
+// no burn alert and no enabled latency or error trigger on one of its
+// datasets: the SLO's, or those of its good and total events queries.
+//
+// A latency trigger calculates a percentile, or over a duration or latency
+// column. An error trigger filters on a column named like an error or
+// status, or on a column the SLI's good events query filters on. The
+// message lists the SLI queries and the triggers on the datasets that are
+// neither.
+func LintUnalertedSLOs(resources *discovery.DiscoveredResources) []Issue {
+	var results []Issue
+	for _, s := range resources.SLOs {
+		if s.BurnAlertCount > 0 {
+			continue
+		}
+
+		good, total := s.SLIQueries(resources.Queries)
+		datasets := []string{s.Dataset}
+		var sliColumns []string
+		var linked []string
+		for _, q := range []*discovery.DiscoveredQuery{good, total} {
+			if q == nil {
+				continue
+			}
+			datasets = append(datasets, q.Dataset)
+			if q.Name != s.Name {
+				linked = append(linked, "query "+resourceLocation(q.Name, q.File, q.Line))
+			}
+		}
+		if good != nil {
+			for _, f := range good.Filters {
+				sliColumns = append(sliColumns, f.Column)
+			}
+		}
+
+		alerted := false
+		var others []string
+		for _, t := range resources.Triggers {
+			q := t.Query(resources.Queries)
+			if t.Disabled || q == nil || !slices.Contains(datasets, cmp.Or(q.Dataset, t.Dataset)) {
+				continue
+			}
+			if latencyQuery(*q) || errorQuery(*q, sliColumns) {
+				alerted = true
+				break
+			}
+			others = append(others, "trigger "+resourceLocation(t.Name, t.File, t.Line))
+		}
+		if alerted {
+			continue
+		}
+
+		msg := fmt.Sprintf("SLO %s has no burn alert and no latency or error trigger on its dataset; add a burn alert or a trigger", s.Name)
+		if len(linked) > 0 {
+			msg += " (SLI: " + strings.Join(linked, ", ") + ")"
+		}
+		if len(others) > 0 {
+			msg += "; neither latency nor error: " + strings.Join(others, ", ")
+		}
+		results = append(results, Issue{
+			Rule:     WHC060,
+			Severity: SeverityWarning,
+			Message:  msg,
+			File:     s.File,
+			Line:     s.Line,
+		})
+	}
+	return results
+}
+
+// LintTriggerAlertTypes returns a WHC061 warning for each trigger whose
+// query has breakdowns and that does not set its alert type. With
+// breakdowns, the default on_change notifies once while any group crosses
+// the threshold, so other groups crossing later go unnoticed; the choice
+// between that and on_true should be explicit.
+func LintTriggerAlertTypes(triggers []discovery.DiscoveredTrigger, queries []discovery.DiscoveredQuery) []Issue {
+	var results []Issue
+	for _, t := range triggers {
+		q := t.Query(queries)
+		if t.AlertType != "" || q == nil || len(q.Breakdowns) == 0 {
+			continue
+		}
+		source := "its inline query"
+		if t.InlineQuery == nil {
+			source = "query " + resourceLocation(q.Name, q.File, q.Line)
+		}
+		results = append(results, Issue{
+			Rule:     WHC061,
+			Severity: SeverityWarning,
+			Message: fmt.Sprintf("Trigger %s evaluates %s, grouped by %s; set AlertType to trigger.OnChange or trigger.OnTrue explicitly",
+				t.Name, source, strings.Join(q.Breakdowns, ", ")),
+			File: t.File,
+			Line: t.Line,
+		})
+	}
+	return results
+}
+
+// resourceLocation returns name followed by its file name and line.
+func resourceLocation(name, file string, line int) string {
+	if file == "" {
+		return name
+	}
+	return fmt.Sprintf("%s (%s:%d)", name, filepath.Base(file), line)
+}
+
+// latencyQuery reports whether q measures latency: a percentile, or a
+// calculation over a duration or latency column.
+func latencyQuery(q discovery.DiscoveredQuery) bool {
+	for _, c := range q.Calculations {
+		column := strings.ToLower(c.Column)
+		if isPercentile(c.Op) || strings.Contains(column, "duration") || strings.Contains(column, "latency") {
+			return true
+		}
+	}
+	return false
+}
+
+// errorQuery reports whether q selects errors: it filters on a column named
+// like an error or status, or on one of the SLI's columns.
+func errorQuery(q discovery.DiscoveredQuery, sliColumns []string) bool {
+	for _, f := range q.Filters {
+		column := strings.ToLower(f.Column)
+		if strings.Contains(column, "error") || strings.Contains(column, "status") || strings.Contains(column, "exception") ||
+			slices.Contains(sliColumns, f.Column) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPercentile reports whether op is a percentile calculation, P50 to P999.
+func isPercentile(op string) bool {
+	return len(op) > 1 && op[0] == 'P' && strings.Trim(op[1:], "0123456789") == ""
+}
@@ -0,0 +1,59 @@
+package lint
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/resource"
+)
+
+// LintCustom runs the Lint function of each resource's kind, skipping
+// findings of disabled rules. Results are in resource order.
+func LintCustom(resources []resource.Resource, disabled map[string]bool) []Issue {
+	return lintCustom(resources, disabled, nil)
+}
+
+// lintCustom is LintCustom recording the time spent in the Lint function of
+// each kind in timings, under CustomRule(kind).
+func lintCustom(resources []resource.Resource, disabled map[string]bool, timings *Timings) []Issue {
+	var results []Issue
+	for _, r := range resources {
+		kind, ok := resource.Lookup(r.Kind)
+		if !ok || kind.Lint == nil {
+			continue
+		}
+		var findings []resource.Finding
+		timings.time(CustomRule(kind.Name), func() []Issue {
+			findings = kind.Lint(r)
+			return nil
+		})
+		for _, f := range findings {
+			if disabled[f.Rule] {
+				continue
+			}
+			results = append(results, Issue{
+				Rule:     f.Rule,
+				Severity: customSeverity(f.Severity),
+				Message:  f.Message,
+				File:     r.File,
+				Line:     r.Line,
+			})
+		}
+	}
+	return results
+}
+
+// CustomRule is the name timings of the Lint function of a registered kind
+// are recorded under, since one function may report several rules.
+func CustomRule(kind string) string {
+	return kind + " (custom)"
+}
+
+// customSeverity converts the severity of a custom finding, defaulting to a
+// warning.
+func customSeverity(s resource.Severity) Severity {
+	switch s {
+	case resource.SeverityError:
+		return SeverityError
+	case resource.SeverityInfo:
+		return SeverityInfo
+	}
+	return SeverityWarning
+}
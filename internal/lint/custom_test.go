@@ -0,0 +1,77 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/resource"
+)
+
+func TestLintCustom(t *testing.T) {
+	resource.Register(resource.Kind{
+		Name: "runbook",
+		Type: "runbook.Runbook",
+		Lint: func(r resource.Resource) []resource.Finding {
+			var findings []resource.Finding
+			if r.String("Owner") == "" {
+				findings = append(findings, resource.Finding{Rule: "RUNBOOK001", Severity: resource.SeverityError, Message: "Runbook has no owner"})
+			}
+			findings = append(findings, resource.Finding{Rule: "RUNBOOK002", Message: "Runbook is not linked from an alert"})
+			return findings
+		},
+	})
+	t.Cleanup(func() { resource.Unregister("runbook") })
+
+	resources := &discovery.DiscoveredResources{
+		Custom: []resource.Resource{
+			{Kind: "runbook", Name: "Restart", File: "runbooks.go", Line: 12, Fields: map[string]any{"Owner": "platform"}},
+			{Kind: "runbook", Name: "Failover", File: "runbooks.go", Line: 3},
+			{Kind: "unregistered", Name: "Other", File: "runbooks.go", Line: 1},
+		},
+	}
+
+	results := LintAllWithConfig(resources, LintConfig{})
+	assert.ElementsMatch(t, []Issue{
+		{Rule: "RUNBOOK001", Severity: SeverityError, Message: "Runbook has no owner", File: "runbooks.go", Line: 3},
+		{Rule: "RUNBOOK002", Severity: SeverityWarning, Message: "Runbook is not linked from an alert", File: "runbooks.go", Line: 3},
+		{Rule: "RUNBOOK002", Severity: SeverityWarning, Message: "Runbook is not linked from an alert", File: "runbooks.go", Line: 12},
+	}, results)
+
+	results = LintAllWithConfig(resources, LintConfig{DisabledRules: []string{"RUNBOOK002"}})
+	require.Len(t, results, 1)
+	assert.Equal(t, "RUNBOOK001", results[0].Rule)
+}
+
+func TestLintCustom_Timings(t *testing.T) {
+	resource.Register(resource.Kind{
+		Name: "runbook",
+		Type: "runbook.Runbook",
+		Lint: func(r resource.Resource) []resource.Finding {
+			return []resource.Finding{{Rule: "RUNBOOK001", Message: "Runbook is not linked from an alert"}}
+		},
+	})
+	t.Cleanup(func() { resource.Unregister("runbook") })
+
+	resources := &discovery.DiscoveredResources{
+		Custom: []resource.Resource{
+			{Kind: "runbook", Name: "Restart", File: "runbooks.go", Line: 12},
+			{Kind: "runbook", Name: "Failover", File: "runbooks.go", Line: 3},
+		},
+	}
+
+	timings := &Timings{}
+	results := LintAllWithConfig(resources, LintConfig{Timings: timings})
+	assert.Len(t, results, 2)
+
+	var custom *RuleTiming
+	for _, rt := range timings.Rules() {
+		if rt.Rule == CustomRule("runbook") {
+			custom = &rt
+		}
+	}
+	require.NotNil(t, custom, "expected the runbook Lint function to be timed")
+	assert.Equal(t, 2, custom.Checks)
+}
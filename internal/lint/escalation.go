@@ -0,0 +1,56 @@
+package lint
+
+import (
+	"fmt"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+// WHC064 flags trigger escalations whose levels do not escalate. It needs
+// every level of an escalation, so it runs from LintAllWithConfig.
+const WHC064 = "WHC064"
+
+// LintEscalations returns a WHC064 error for each trigger.Escalation whose
+// levels fail trigger.Escalation.Validate: levels comparing differently, a
+// threshold not crossed after the previous level's, or a repeated level
+// name. The issue is reported at the first level.
+func LintEscalations(triggers []discovery.DiscoveredTrigger) []Issue {
+	type escalation struct {
+		first  discovery.DiscoveredTrigger
+		levels []trigger.Level
+	}
+	var order []string
+	escalations := make(map[string]*escalation)
+	for _, t := range triggers {
+		if t.Escalation == "" {
+			continue
+		}
+		key := t.Package + "." + t.Escalation
+		e, ok := escalations[key]
+		if !ok {
+			e = &escalation{first: t}
+			escalations[key] = e
+			order = append(order, key)
+		}
+		e.levels = append(e.levels, trigger.Level{
+			Name:      t.Level,
+			Threshold: trigger.Threshold{Op: trigger.Op(t.ThresholdOp), Value: t.ThresholdValue},
+		})
+	}
+
+	var results []Issue
+	for _, key := range order {
+		e := escalations[key]
+		if err := (trigger.Escalation{Levels: e.levels}).Validate(); err != nil {
+			results = append(results, Issue{
+				Rule:     WHC064,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("Escalation %s: %v", e.first.Escalation, err),
+				File:     e.first.File,
+				Line:     e.first.Line,
+			})
+		}
+	}
+	return results
+}
@@ -2,7 +2,7 @@
 package lint
 
 import (
-	"sort"
+	"time"
 
 	corelint "github.com/lex00/wetwire-core-go/lint"
 	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
@@ -29,23 +29,17 @@ func LintQueries(queries []discovery.DiscoveredQuery) []Issue {
 // LintQueriesWithRules runs specific lint rules against the provided queries.
 // Results are sorted by file and line number.
 func LintQueriesWithRules(queries []discovery.DiscoveredQuery, rules []Rule) []Issue {
-	var results []Issue
-
-	for _, query := range queries {
-		for _, rule := range rules {
-			ruleResults := rule.Check(query)
-			results = append(results, ruleResults...)
-		}
-	}
-
-	// Sort results by file, then line
-	sort.Slice(results, func(i, j int) bool {
-		if results[i].File != results[j].File {
-			return results[i].File < results[j].File
-		}
-		return results[i].Line < results[j].Line
-	})
+	return lintQueries(queries, rules, nil)
+}
 
+// lintQueries runs rules against queries in parallel, recording the time
+// spent in each rule in timings.
+func lintQueries(queries []discovery.DiscoveredQuery, rules []Rule, timings *Timings) []Issue {
+	results := runChecks(len(queries), len(rules),
+		func(i, j int) []Issue { return rules[j].Check(queries[i]) },
+		func(j int) string { return rules[j].Code },
+		timings)
+	sortIssues(results)
 	return results
 }
 
@@ -56,12 +50,56 @@ type LintConfig struct {
 
 	// SeverityOverrides maps rule codes to custom severity levels
 	SeverityOverrides map[string]Severity
+
+	// RetentionDays is the team's data retention used to check absolute time
+	// ranges (WHC016). Zero means the Honeycomb default of 60 days.
+	RetentionDays int
+
+	// UngroupedCountDays is the shortest time range, in days, for which
+	// WHC019 flags COUNT-only queries without breakdowns. Zero means
+	// DefaultUngroupedCountDays.
+	UngroupedCountDays int
+
+	// Schemas are the declared dataset schemas used to check column names
+	// (WHC017) and types (WHC006). LintAllWithConfig uses the discovered
+	// schemas when this is nil.
+	Schemas []discovery.DiscoveredSchema
+
+	// Naming is the naming policy WHC063 checks resource names against
+	Naming discovery.NamingPolicy
+
+	// OrphanAllowlist holds name patterns (path.Match syntax) of queries
+	// WHC026 accepts without a board, trigger or SLO using them
+	OrphanAllowlist []string
+
+	// Timings, when set, receives the time spent in each rule
+	Timings *Timings
+}
+
+// queryRules returns AllRules with rule options taken from config.
+func queryRules(config LintConfig) []Rule {
+	rules := AllRules()
+	for i, rule := range rules {
+		switch {
+		case rule.Code == "WHC016" && config.RetentionDays > 0:
+			rules[i] = WHC016InvalidTimeRangeWithRetention(time.Duration(config.RetentionDays) * 24 * time.Hour)
+		case rule.Code == "WHC019" && config.UngroupedCountDays > 0:
+			rules[i] = WHC019UngroupedCountWithMinDays(config.UngroupedCountDays)
+		case rule.Code == "WHC005" && len(config.Schemas) > 0:
+			rules[i] = WHC005HighCardinalityBreakdownWithSchemas(config.Schemas)
+		case rule.Code == "WHC006" && len(config.Schemas) > 0:
+			rules[i] = WHC006InvalidCalculationForColumnTypeWithSchemas(config.Schemas)
+		case rule.Code == "WHC017" && len(config.Schemas) > 0:
+			rules[i] = WHC017UnknownColumnWithSchemas(config.Schemas)
+		}
+	}
+	return rules
 }
 
 // LintQueriesWithConfig runs lint rules with the specified configuration.
 func LintQueriesWithConfig(queries []discovery.DiscoveredQuery, config LintConfig) []Issue {
 	// Get all rules
-	rules := AllRules()
+	rules := queryRules(config)
 
 	// Filter out disabled rules
 	disabledSet := make(map[string]bool)
@@ -158,22 +196,17 @@ func LintBoards(boards []discovery.DiscoveredBoard) []Issue {
 // LintBoardsWithRules runs specific board lint rules against the provided boards.
 // Results are sorted by file and line number.
 func LintBoardsWithRules(boards []discovery.DiscoveredBoard, rules []BoardRule) []Issue {
-	var results []Issue
-
-	for _, board := range boards {
-		for _, rule := range rules {
-			ruleResults := rule.Check(board)
-			results = append(results, ruleResults...)
-		}
-	}
-
-	sort.Slice(results, func(i, j int) bool {
-		if results[i].File != results[j].File {
-			return results[i].File < results[j].File
-		}
-		return results[i].Line < results[j].Line
-	})
+	return lintBoards(boards, rules, nil)
+}
 
+// lintBoards runs rules against boards in parallel, recording the time
+// spent in each rule in timings.
+func lintBoards(boards []discovery.DiscoveredBoard, rules []BoardRule, timings *Timings) []Issue {
+	results := runChecks(len(boards), len(rules),
+		func(i, j int) []Issue { return rules[j].Check(boards[i]) },
+		func(j int) string { return rules[j].Code },
+		timings)
+	sortIssues(results)
 	return results
 }
 
@@ -186,22 +219,17 @@ func LintSLOs(slos []discovery.DiscoveredSLO) []Issue {
 // LintSLOsWithRules runs specific SLO lint rules against the provided SLOs.
 // Results are sorted by file and line number.
 func LintSLOsWithRules(slos []discovery.DiscoveredSLO, rules []SLORule) []Issue {
-	var results []Issue
-
-	for _, slo := range slos {
-		for _, rule := range rules {
-			ruleResults := rule.Check(slo)
-			results = append(results, ruleResults...)
-		}
-	}
-
-	sort.Slice(results, func(i, j int) bool {
-		if results[i].File != results[j].File {
-			return results[i].File < results[j].File
-		}
-		return results[i].Line < results[j].Line
-	})
+	return lintSLOs(slos, rules, nil)
+}
 
+// lintSLOs runs rules against slos in parallel, recording the time
+// spent in each rule in timings.
+func lintSLOs(slos []discovery.DiscoveredSLO, rules []SLORule, timings *Timings) []Issue {
+	results := runChecks(len(slos), len(rules),
+		func(i, j int) []Issue { return rules[j].Check(slos[i]) },
+		func(j int) string { return rules[j].Code },
+		timings)
+	sortIssues(results)
 	return results
 }
 
@@ -214,22 +242,17 @@ func LintTriggers(triggers []discovery.DiscoveredTrigger) []Issue {
 // LintTriggersWithRules runs specific trigger lint rules against the provided triggers.
 // Results are sorted by file and line number.
 func LintTriggersWithRules(triggers []discovery.DiscoveredTrigger, rules []TriggerRule) []Issue {
-	var results []Issue
-
-	for _, trigger := range triggers {
-		for _, rule := range rules {
-			ruleResults := rule.Check(trigger)
-			results = append(results, ruleResults...)
-		}
-	}
-
-	sort.Slice(results, func(i, j int) bool {
-		if results[i].File != results[j].File {
-			return results[i].File < results[j].File
-		}
-		return results[i].Line < results[j].Line
-	})
+	return lintTriggers(triggers, rules, nil)
+}
 
+// lintTriggers runs rules against triggers in parallel, recording the time
+// spent in each rule in timings.
+func lintTriggers(triggers []discovery.DiscoveredTrigger, rules []TriggerRule, timings *Timings) []Issue {
+	results := runChecks(len(triggers), len(rules),
+		func(i, j int) []Issue { return rules[j].Check(triggers[i]) },
+		func(j int) string { return rules[j].Code },
+		timings)
+	sortIssues(results)
 	return results
 }
 
@@ -241,10 +264,16 @@ func LintAll(resources *discovery.DiscoveredResources) []Issue {
 
 // LintAllWithConfig runs all lint rules against all discovered resources with configuration.
 // It respects DisabledRules and SeverityOverrides from the config.
-// Results are sorted by file and line number.
+// Rules are evaluated in parallel; results are sorted by file and line
+// number, and findings on the same line keep resource and rule order, so the
+// output is the same on every run.
 func LintAllWithConfig(resources *discovery.DiscoveredResources, config LintConfig) []Issue {
 	var results []Issue
 
+	if config.Schemas == nil {
+		config.Schemas = resources.Schemas
+	}
+
 	// Build disabled rules set
 	disabledSet := make(map[string]bool)
 	for _, code := range config.DisabledRules {
@@ -252,14 +281,13 @@ func LintAllWithConfig(resources *discovery.DiscoveredResources, config LintConf
 	}
 
 	// Filter query rules
-	queryRules := AllRules()
 	var enabledQueryRules []Rule
-	for _, rule := range queryRules {
+	for _, rule := range queryRules(config) {
 		if !disabledSet[rule.Code] {
 			enabledQueryRules = append(enabledQueryRules, rule)
 		}
 	}
-	results = append(results, LintQueriesWithRules(resources.Queries, enabledQueryRules)...)
+	results = append(results, lintQueries(resources.Queries, enabledQueryRules, config.Timings)...)
 
 	// Filter board rules
 	boardRules := AllBoardRules()
@@ -269,7 +297,7 @@ func LintAllWithConfig(resources *discovery.DiscoveredResources, config LintConf
 			enabledBoardRules = append(enabledBoardRules, rule)
 		}
 	}
-	results = append(results, LintBoardsWithRules(resources.Boards, enabledBoardRules)...)
+	results = append(results, lintBoards(resources.Boards, enabledBoardRules, config.Timings)...)
 
 	// Filter SLO rules
 	sloRules := AllSLORules()
@@ -279,7 +307,7 @@ func LintAllWithConfig(resources *discovery.DiscoveredResources, config LintConf
 			enabledSLORules = append(enabledSLORules, rule)
 		}
 	}
-	results = append(results, LintSLOsWithRules(resources.SLOs, enabledSLORules)...)
+	results = append(results, lintSLOs(resources.SLOs, enabledSLORules, config.Timings)...)
 
 	// Filter trigger rules
 	triggerRules := AllTriggerRules()
@@ -289,7 +317,89 @@ func LintAllWithConfig(resources *discovery.DiscoveredResources, config LintConf
 			enabledTriggerRules = append(enabledTriggerRules, rule)
 		}
 	}
-	results = append(results, LintTriggersWithRules(resources.Triggers, enabledTriggerRules)...)
+	results = append(results, lintTriggers(resources.Triggers, enabledTriggerRules, config.Timings)...)
+	if !disabledSet[WHC055] {
+		results = append(results, config.Timings.time(WHC055, func() []Issue {
+			return LintTriggerDurations(resources.Triggers, resources.Queries)
+		})...)
+	}
+
+	if !disabledSet[WHC061] {
+		results = append(results, config.Timings.time(WHC061, func() []Issue {
+			return LintTriggerAlertTypes(resources.Triggers, resources.Queries)
+		})...)
+	}
+
+	// Check query time ranges against the triggers and SLOs using them
+	if !disabledSet[WHC067] {
+		results = append(results, config.Timings.time(WHC067, func() []Issue {
+			return LintTriggerWindows(resources.Triggers, resources.Queries)
+		})...)
+	}
+	if !disabledSet[WHC068] {
+		results = append(results, config.Timings.time(WHC068, func() []Issue {
+			return LintSLIWindows(resources.SLOs, resources.Queries)
+		})...)
+	}
+
+	// Check that escalation levels escalate
+	if !disabledSet[WHC064] {
+		results = append(results, config.Timings.time(WHC064, func() []Issue {
+			return LintEscalations(resources.Triggers)
+		})...)
+	}
+
+	// Check that maintenance windows are valid and mute something
+	if !disabledSet[WHC065] {
+		results = append(results, config.Timings.time(WHC065, func() []Issue {
+			return LintMutes(resources)
+		})...)
+	}
+
+	// Check for resources built by code discovery does not run
+	if !disabledSet[WHC066] {
+		results = append(results, config.Timings.time(WHC066, func() []Issue {
+			return LintUnsupported(resources)
+		})...)
+	}
+
+	// Check for fields discovery cannot read, such as misspelled keys
+	if !disabledSet[WHC069] {
+		results = append(results, config.Timings.time(WHC069, func() []Issue {
+			return LintFieldMistakes(resources)
+		})...)
+	}
+
+	// Check that every SLO is alerted on, by a burn alert or a trigger
+	if !disabledSet[WHC060] {
+		results = append(results, config.Timings.time(WHC060, func() []Issue {
+			return LintUnalertedSLOs(resources)
+		})...)
+	}
+
+	// Check that trigger and SLO names are unique in their datasets
+	if !disabledSet[WHC062] {
+		results = append(results, config.Timings.time(WHC062, func() []Issue {
+			return LintDuplicateNames(resources)
+		})...)
+	}
+
+	// Check resource names against the project naming policy
+	if !disabledSet[WHC063] {
+		results = append(results, config.Timings.time(WHC063, func() []Issue {
+			return LintNamingPolicy(resources, config.Naming)
+		})...)
+	}
+
+	// Run the checks of registered resource kinds
+	results = append(results, lintCustom(resources.Custom, disabledSet, config.Timings)...)
+
+	// Check the queries against the references of all other resources
+	if !disabledSet[WHC026] {
+		results = append(results, config.Timings.time(WHC026, func() []Issue {
+			return LintOrphanedQueries(resources, config.OrphanAllowlist)
+		})...)
+	}
 
 	// Apply severity overrides
 	for i := range results {
@@ -299,12 +409,7 @@ func LintAllWithConfig(resources *discovery.DiscoveredResources, config LintConf
 	}
 
 	// Sort all results together by file, then line
-	sort.Slice(results, func(i, j int) bool {
-		if results[i].File != results[j].File {
-			return results[i].File < results[j].File
-		}
-		return results[i].Line < results[j].Line
-	})
+	sortIssues(results)
 
 	return results
 }
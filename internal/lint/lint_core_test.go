@@ -1,6 +1,7 @@
 package lint
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
@@ -114,13 +115,18 @@ func TestLintQueries_WHC004_BreakdownWithoutOrder(t *testing.T) {
 	}
 }
 
-func TestLintQueries_WHC005_HighCardinalityBreakdown(t *testing.T) {
-	// Create a query with more than 100 breakdown groups
-	breakdowns := make([]string, 150)
-	for i := 0; i < 150; i++ {
-		breakdowns[i] = "field"
+func TestLintQueries_WHC004_BreakdownWithOrder(t *testing.T) {
+	q := testQuery("TestQuery", discovery.Calculation{Op: "COUNT"})
+	q.Breakdowns = []string{"endpoint"}
+	q.Orders = []discovery.Order{{Op: "COUNT", Order: "descending"}}
+	q.Limit = 10
+
+	if results := LintQueries([]discovery.DiscoveredQuery{q}); hasResult(results, "WHC004") {
+		t.Errorf("Unexpected WHC004 warning for an ordered breakdown: %v", findResult(results, "WHC004").Message)
 	}
+}
 
+func TestLintQueries_WHC005_HighCardinalityBreakdown(t *testing.T) {
 	queries := []discovery.DiscoveredQuery{
 		{
 			Name:       "TestQuery",
@@ -129,23 +135,67 @@ func TestLintQueries_WHC005_HighCardinalityBreakdown(t *testing.T) {
 			Line:       10,
 			Dataset:    "production",
 			TimeRange:  discovery.TimeRange{TimeRange: 3600},
-			Breakdowns: breakdowns,
+			Breakdowns: []string{"service.name", "trace.trace_id"},
 			Calculations: []discovery.Calculation{
 				{Op: "COUNT"},
 			},
-			Limit: 150, // Limit indicates high cardinality
+			Limit: 150,
 		},
 	}
 
-	results := LintQueries(queries)
+	results := FilterByRule(LintQueries(queries), "WHC005")
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 WHC005 warning for the trace.trace_id breakdown, got %v", results)
+	}
+	if results[0].Severity != SeverityWarning {
+		t.Errorf("Expected warning severity, got %s", results[0].Severity)
+	}
+	if !strings.Contains(results[0].Message, "COUNT_DISTINCT(trace.trace_id)") {
+		t.Errorf("Expected a COUNT_DISTINCT suggestion, got %q", results[0].Message)
+	}
+}
 
-	if !hasResult(results, "WHC005") {
-		t.Error("Expected WHC005 warning for high cardinality breakdown")
+func TestLintQueries_WHC005_LimitAlone(t *testing.T) {
+	queries := []discovery.DiscoveredQuery{
+		{
+			Name:       "TestQuery",
+			Dataset:    "production",
+			TimeRange:  discovery.TimeRange{TimeRange: 3600},
+			Breakdowns: []string{"endpoint"},
+			Calculations: []discovery.Calculation{
+				{Op: "COUNT"},
+			},
+			Limit: 500,
+		},
 	}
 
-	result := findResult(results, "WHC005")
-	if result.Severity != SeverityWarning {
-		t.Errorf("Expected warning severity, got %s", result.Severity)
+	if hasResult(LintQueries(queries), "WHC005") {
+		t.Error("Expected no WHC005 warning for a high limit on a low cardinality column")
+	}
+}
+
+func TestLintQueries_WHC005_SchemaCardinality(t *testing.T) {
+	schemas := []discovery.DiscoveredSchema{{
+		Name:    "Columns",
+		Dataset: "production",
+		Columns: []discovery.DiscoveredColumn{
+			{Name: "customer", Type: "string", Cardinality: 50_000},
+			{Name: "user.id", Type: "string", Cardinality: 200},
+			{Name: "region", Type: "string", Cardinality: 12},
+		},
+	}}
+	query := discovery.DiscoveredQuery{
+		Name:       "TestQuery",
+		Dataset:    "production",
+		Breakdowns: []string{"customer", "user.id", "region"},
+	}
+
+	results := WHC005HighCardinalityBreakdownWithSchemas(schemas).Check(query)
+	if len(results) != 1 {
+		t.Fatalf("Expected 1 WHC005 warning, got %v", results)
+	}
+	if !strings.Contains(results[0].Message, "'customer' (about 50000 distinct values in the production schema)") {
+		t.Errorf("Unexpected message %q", results[0].Message)
 	}
 }
 
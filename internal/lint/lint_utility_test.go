@@ -351,6 +351,21 @@ func TestLintQueries_RealWorldExample(t *testing.T) {
 
 // Helper functions
 
+// testQuery returns a query of package queries on the production dataset
+// over the last two hours, with calcs. Rule tests change the fields of the
+// case they cover.
+func testQuery(name string, calcs ...discovery.Calculation) discovery.DiscoveredQuery {
+	return discovery.DiscoveredQuery{
+		Name:         name,
+		Package:      "queries",
+		File:         "/test/file.go",
+		Line:         10,
+		Dataset:      "production",
+		TimeRange:    discovery.TimeRange{TimeRange: 7200},
+		Calculations: calcs,
+	}
+}
+
 func hasResult(results []Issue, rule string) bool {
 	for _, r := range results {
 		if r.Rule == rule {
@@ -360,6 +375,43 @@ func hasResult(results []Issue, rule string) bool {
 	return false
 }
 
+// testTrigger returns a trigger of package alerts evaluating the query named
+// queryRef.
+func testTrigger(name, queryRef string) discovery.DiscoveredTrigger {
+	return discovery.DiscoveredTrigger{
+		Name:     name,
+		Package:  "alerts",
+		File:     "/test/file.go",
+		Line:     10,
+		QueryRef: queryRef,
+	}
+}
+
+// testSLO returns an SLO of package slos on the production dataset whose
+// SLI divides the events of query good by those of query total.
+func testSLO(name, good, total string) discovery.DiscoveredSLO {
+	return discovery.DiscoveredSLO{
+		Name:                name,
+		Package:             "slos",
+		File:                "/test/file.go",
+		Line:                10,
+		Dataset:             "production",
+		GoodEventsQueryRef:  good,
+		TotalEventsQueryRef: total,
+	}
+}
+
+// countRule returns the number of issues of rule.
+func countRule(issues []Issue, rule string) int {
+	n := 0
+	for _, issue := range issues {
+		if issue.Rule == rule {
+			n++
+		}
+	}
+	return n
+}
+
 func findResult(results []Issue, rule string) *Issue {
 	for i, r := range results {
 		if r.Rule == rule {
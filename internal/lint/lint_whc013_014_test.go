@@ -452,8 +452,8 @@ func TestAllRules_IncludesSecretDetectionRules(t *testing.T) {
 
 func TestAllRules_Count(t *testing.T) {
 	rules := AllRules()
-	// Should have 18 rules now (WHC001-WHC014, WHC020-WHC023)
-	if len(rules) != 18 {
-		t.Errorf("Expected 18 rules, got %d", len(rules))
+	// Should have 26 rules now (WHC001-WHC025 and WHC027)
+	if len(rules) != 26 {
+		t.Errorf("Expected 26 rules, got %d", len(rules))
 	}
 }
@@ -0,0 +1,125 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC015 Filter Value Tests
+
+// whc015Query returns a COUNT query with filters, ready for LintQueries.
+func whc015Query(filters ...discovery.Filter) []discovery.DiscoveredQuery {
+	q := testQuery("TestQuery", discovery.Calculation{Op: "COUNT"})
+	q.Filters = filters
+	return []discovery.DiscoveredQuery{q}
+}
+
+func TestLintQueries_WHC015_ValidValues(t *testing.T) {
+	results := LintQueries(whc015Query(
+		discovery.Filter{Column: "status", Op: "=", Value: "error", HasValue: true},
+		discovery.Filter{Column: "duration_ms", Op: ">", Value: 500, HasValue: true},
+		discovery.Filter{Column: "error", Op: "=", Value: true, HasValue: true},
+		discovery.Filter{Column: "trace.parent_id", Op: "exists"},
+		discovery.Filter{Column: "region", Op: "in", Value: []interface{}{"us-east-1", "eu-west-1"}, HasValue: true},
+		// Non-literal values are not type-checked
+		discovery.Filter{Column: "service", Op: "=", HasValue: true},
+	))
+
+	if hasResult(results, "WHC015") {
+		t.Errorf("Unexpected WHC015 issue: %v", findResult(results, "WHC015").Message)
+	}
+}
+
+func TestLintQueries_WHC015_InvalidValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter discovery.Filter
+		want   string
+	}{
+		{"exists with value", discovery.Filter{Column: "user.id", Op: "exists", Value: "x", HasValue: true}, "takes no value"},
+		{"exists with variable", discovery.Filter{Column: "user.id", Op: "exists", HasValue: true}, "takes no value"},
+		{"in without list", discovery.Filter{Column: "region", Op: "in", Value: "us-east-1", HasValue: true}, "does not accept a string"},
+		{"contains number", discovery.Filter{Column: "message", Op: "contains", Value: 42, HasValue: true}, "does not accept a number"},
+		{"equals without value", discovery.Filter{Column: "status", Op: "="}, "requires a value"},
+		{"equals list", discovery.Filter{Column: "status", Op: "=", Value: []interface{}{"a"}, HasValue: true}, "does not accept a list"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := LintQueries(whc015Query(tt.filter))
+			if !hasResult(results, "WHC015") {
+				t.Fatal("Expected WHC015 error")
+			}
+			result := findResult(results, "WHC015")
+			if result.Severity != SeverityError {
+				t.Errorf("Expected error severity, got %s", result.Severity)
+			}
+			if !strings.Contains(result.Message, tt.want) {
+				t.Errorf("Message = %q, want it to contain %q", result.Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintQueries_WHC015_SkipsUnknownOperator(t *testing.T) {
+	results := LintQueries(whc015Query(discovery.Filter{Column: "status", Op: "like", Value: "x", HasValue: true}))
+
+	if hasResult(results, "WHC015") {
+		t.Error("Unknown operators are reported by WHC007, not WHC015")
+	}
+}
+
+// WHC016 Time Range Tests
+
+func whc016Query(tr discovery.TimeRange) []discovery.DiscoveredQuery {
+	queries := whc015Query()
+	queries[0].TimeRange = tr
+	return queries
+}
+
+func TestLintQueries_WHC016_InvalidTimeRange(t *testing.T) {
+	now := int(time.Now().Unix())
+	day := 86400
+
+	tests := []struct {
+		name string
+		tr   discovery.TimeRange
+		want string
+	}{
+		{"start after end", discovery.TimeRange{StartTime: now - day, EndTime: now - 2*day}, "not before"},
+		{"outside retention", discovery.TimeRange{StartTime: now - 90*day, EndTime: now - 89*day}, "retention"},
+		{"bad zone", discovery.TimeRange{Zone: "Mars/Base", Err: `unknown time zone "Mars/Base"`}, "unknown time zone"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := LintQueries(whc016Query(tt.tr))
+			if !hasResult(results, "WHC016") {
+				t.Fatal("Expected WHC016 error")
+			}
+			if msg := findResult(results, "WHC016").Message; !strings.Contains(msg, tt.want) {
+				t.Errorf("Message = %q, want it to contain %q", msg, tt.want)
+			}
+		})
+	}
+
+	results := LintQueries(whc016Query(discovery.TimeRange{StartTime: now - 2*day, EndTime: now - day}))
+	if hasResult(results, "WHC016") {
+		t.Errorf("Unexpected WHC016 for a recent range: %s", findResult(results, "WHC016").Message)
+	}
+}
+
+func TestLintQueriesWithConfig_WHC016_Retention(t *testing.T) {
+	now := int(time.Now().Unix())
+	queries := whc016Query(discovery.TimeRange{StartTime: now - 90*86400, EndTime: now - 89*86400})
+
+	if !hasResult(LintQueriesWithConfig(queries, LintConfig{}), "WHC016") {
+		t.Error("Expected WHC016 with the default 60 day retention")
+	}
+	if hasResult(LintQueriesWithConfig(queries, LintConfig{RetentionDays: 400}), "WHC016") {
+		t.Error("Unexpected WHC016 with a 400 day retention")
+	}
+}
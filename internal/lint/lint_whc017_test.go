@@ -0,0 +1,115 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC017 Schema Column Tests
+
+var whc017Schemas = []discovery.DiscoveredSchema{
+	{
+		Name:    "Columns",
+		Dataset: "production",
+		Columns: []discovery.DiscoveredColumn{
+			{Name: "duration_ms", Type: "float"},
+			{Name: "service.name", Type: "string"},
+			{Name: "status_code", Type: "integer"},
+		},
+	},
+}
+
+// whc017Query returns a query of dataset using the columns of whc017Schemas.
+func whc017Query(dataset string) discovery.DiscoveredQuery {
+	q := testQuery("TestQuery", discovery.Calculation{Op: "P99", Column: "duration_ms"})
+	q.Dataset = dataset
+	q.Filters = []discovery.Filter{{Column: "status_code", Op: ">=", Value: 500, HasValue: true}}
+	q.Breakdowns = []string{"service.name"}
+	return q
+}
+
+func TestWHC017_DeclaredColumns(t *testing.T) {
+	issues := WHC017UnknownColumnWithSchemas(whc017Schemas).Check(whc017Query("production"))
+	if len(issues) != 0 {
+		t.Errorf("Expected no issues for declared columns, got %v", issues)
+	}
+}
+
+func TestWHC017_UnknownColumns(t *testing.T) {
+	q := whc017Query("production")
+	q.Breakdowns = []string{"service.name", "endpoint"}
+	q.Orders = []discovery.Order{{Column: "latency", Op: "MAX"}, {Column: "endpoint"}}
+
+	issues := WHC017UnknownColumnWithSchemas(whc017Schemas).Check(q)
+	if len(issues) != 2 {
+		t.Fatalf("Expected 2 issues (each unknown column once), got %v", issues)
+	}
+	if !strings.Contains(issues[0].Message, "'endpoint'") || !strings.Contains(issues[1].Message, "'latency'") {
+		t.Errorf("Unexpected messages: %v", issues)
+	}
+	if issues[0].Severity != SeverityError {
+		t.Errorf("Expected error severity, got %v", issues[0].Severity)
+	}
+}
+
+func TestWHC017_DatasetWithoutSchema(t *testing.T) {
+	q := whc017Query("staging")
+	q.Breakdowns = []string{"anything"}
+	if issues := WHC017UnknownColumnWithSchemas(whc017Schemas).Check(q); len(issues) != 0 {
+		t.Errorf("Expected datasets without a schema to be skipped, got %v", issues)
+	}
+	if issues := WHC017UnknownColumn().Check(q); len(issues) != 0 {
+		t.Errorf("Expected no issues without schemas, got %v", issues)
+	}
+}
+
+func TestWHC006_UsesSchemaTypes(t *testing.T) {
+	rule := WHC006InvalidCalculationForColumnTypeWithSchemas(whc017Schemas)
+
+	// Declared numeric column whose name matches the string heuristic
+	q := whc017Query("production")
+	q.Calculations = []discovery.Calculation{{Op: "MAX", Column: "status_code"}}
+	if issues := rule.Check(q); len(issues) != 0 {
+		t.Errorf("Expected declared integer column to pass, got %v", issues)
+	}
+	if issues := WHC006InvalidCalculationForColumnType().Check(q); len(issues) != 1 {
+		t.Errorf("Expected heuristic to flag status_code without a schema, got %v", issues)
+	}
+
+	// Declared string column
+	q.Calculations = []discovery.Calculation{{Op: "AVG", Column: "service.name"}}
+	issues := rule.Check(q)
+	if len(issues) != 1 || !strings.Contains(issues[0].Message, "declared as string") {
+		t.Errorf("Expected declared string column to be flagged, got %v", issues)
+	}
+}
+
+func TestLintAllWithConfig_DiscoveredSchemas(t *testing.T) {
+	q := whc017Query("production")
+	q.Breakdowns = []string{"endpoint"}
+	q.Orders = []discovery.Order{{Op: "COUNT", Order: "descending"}}
+	q.Limit = 100
+
+	resources := &discovery.DiscoveredResources{
+		Queries: []discovery.DiscoveredQuery{q},
+		Schemas: whc017Schemas,
+	}
+
+	found := false
+	for _, issue := range LintAll(resources) {
+		if issue.Rule == "WHC017" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("Expected LintAll to check columns against discovered schemas")
+	}
+
+	for _, issue := range LintAllWithConfig(resources, LintConfig{DisabledRules: []string{"WHC017"}}) {
+		if issue.Rule == "WHC017" {
+			t.Error("Expected WHC017 to be disabled")
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC018 Environment Reference Tests
+
+func TestWHC018_PlainDataset(t *testing.T) {
+	issues := WHC018EnvReference().Check(discovery.DiscoveredQuery{Dataset: "production"})
+	if len(issues) != 0 {
+		t.Errorf("Expected no issues for a plain dataset, got %v", issues)
+	}
+}
+
+func TestWHC018_Reference(t *testing.T) {
+	issues := WHC018EnvReference().Check(discovery.DiscoveredQuery{
+		Dataset: "${TEAM}-api",
+		File:    "/test/file.go",
+		Line:    10,
+	})
+	if len(issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d", len(issues))
+	}
+	if issues[0].Severity != SeverityInfo {
+		t.Errorf("Expected info severity, got %v", issues[0].Severity)
+	}
+	if !strings.Contains(issues[0].Message, "--allow-env") || !strings.Contains(issues[0].Message, "TEAM") {
+		t.Errorf("Expected guidance naming --allow-env and TEAM, got %q", issues[0].Message)
+	}
+}
+
+func TestWHC018_MalformedReference(t *testing.T) {
+	for _, dataset := range []string{"${}", "${1TEAM}", "${TEAM"} {
+		issues := WHC018EnvReference().Check(discovery.DiscoveredQuery{Dataset: dataset})
+		if len(issues) != 1 || issues[0].Severity != SeverityError {
+			t.Errorf("Dataset %q: expected 1 error, got %v", dataset, issues)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC019 Ungrouped COUNT Tests
+
+// whc019Query returns an ungrouped COUNT query over the last days days.
+func whc019Query(days int) discovery.DiscoveredQuery {
+	q := testQuery("Requests", discovery.Calculation{Op: "COUNT"})
+	q.TimeRange = discovery.TimeRange{TimeRange: days * 86400}
+	return q
+}
+
+func TestWHC019_UngroupedCount(t *testing.T) {
+	issues := WHC019UngroupedCount().Check(whc019Query(7))
+	if len(issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %d", len(issues))
+	}
+	if issues[0].Severity != SeverityWarning {
+		t.Errorf("Expected warning, got %v", issues[0].Severity)
+	}
+}
+
+func TestWHC019_NotFlagged(t *testing.T) {
+	shortRange := whc019Query(1)
+
+	withBreakdown := whc019Query(7)
+	withBreakdown.Breakdowns = []string{"service.name"}
+
+	withGranularity := whc019Query(7)
+	withGranularity.Granularity = 3600
+
+	withOtherCalculation := whc019Query(7)
+	withOtherCalculation.Calculations = append(withOtherCalculation.Calculations, discovery.Calculation{Op: "P99", Column: "duration_ms"})
+
+	tests := map[string]discovery.DiscoveredQuery{
+		"short range":       shortRange,
+		"breakdown":         withBreakdown,
+		"granularity":       withGranularity,
+		"other calculation": withOtherCalculation,
+	}
+	for name, q := range tests {
+		if issues := WHC019UngroupedCount().Check(q); len(issues) != 0 {
+			t.Errorf("%s: expected no issues, got %v", name, issues)
+		}
+	}
+}
+
+func TestWHC019_AbsoluteTimeRange(t *testing.T) {
+	q := whc019Query(0)
+	q.TimeRange = discovery.TimeRange{StartTime: 1700000000, EndTime: 1700000000 + 3*86400}
+	if issues := WHC019UngroupedCount().Check(q); len(issues) != 1 {
+		t.Errorf("Expected 1 issue for a 3 day absolute range, got %v", issues)
+	}
+}
+
+func TestWHC019_ConfiguredThreshold(t *testing.T) {
+	q := whc019Query(3)
+
+	results := LintQueriesWithConfig([]discovery.DiscoveredQuery{q}, LintConfig{UngroupedCountDays: 5})
+	if hasResult(results, "WHC019") {
+		t.Error("3 day range should not be flagged with a 5 day threshold")
+	}
+
+	results = LintQueriesWithConfig([]discovery.DiscoveredQuery{q}, LintConfig{})
+	if !hasResult(results, "WHC019") {
+		t.Error("3 day range should be flagged with the default threshold")
+	}
+}
@@ -0,0 +1,59 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC024 Percentile Without Heatmap Tests
+
+func TestWHC024_PercentileWithoutHeatmap(t *testing.T) {
+	q := testQuery("Latency",
+		discovery.Calculation{Op: "P99", Column: "duration_ms"},
+		discovery.Calculation{Op: "P95", Column: "duration_ms"},
+	)
+	issues := WHC024PercentileWithoutHeatmap().Check(q)
+	if len(issues) != 1 {
+		t.Fatalf("Expected 1 issue per column, got %d", len(issues))
+	}
+	if issues[0].Severity != SeverityInfo {
+		t.Errorf("Expected info, got %v", issues[0].Severity)
+	}
+}
+
+func TestWHC024_NotFlagged(t *testing.T) {
+	tests := map[string]discovery.DiscoveredQuery{
+		"with heatmap": testQuery("Latency",
+			discovery.Calculation{Op: "P99", Column: "duration_ms"},
+			discovery.Calculation{Op: "HEATMAP", Column: "duration_ms"},
+		),
+		"non-duration column": testQuery("Latency", discovery.Calculation{Op: "P99", Column: "payload_size"}),
+		"other percentile":    testQuery("Latency", discovery.Calculation{Op: "P50", Column: "latency"}),
+	}
+	for name, q := range tests {
+		if issues := WHC024PercentileWithoutHeatmap().Check(q); len(issues) != 0 {
+			t.Errorf("%s: expected no issues, got %v", name, issues)
+		}
+	}
+}
+
+func TestWHC024_HeatmapOnOtherColumn(t *testing.T) {
+	q := testQuery("Latency",
+		discovery.Calculation{Op: "P99", Column: "duration_ms"},
+		discovery.Calculation{Op: "HEATMAP", Column: "db.latency"},
+	)
+	if issues := WHC024PercentileWithoutHeatmap().Check(q); len(issues) != 1 {
+		t.Errorf("Expected 1 issue, got %d", len(issues))
+	}
+}
+
+func TestWHC024_Disabled(t *testing.T) {
+	q := testQuery("Latency", discovery.Calculation{Op: "P99", Column: "duration_ms"})
+	issues := LintQueriesWithConfig([]discovery.DiscoveredQuery{q}, LintConfig{DisabledRules: []string{"WHC024"}})
+	for _, issue := range issues {
+		if issue.Rule == "WHC024" {
+			t.Errorf("Expected WHC024 to be disabled, got %v", issue)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC025 Unknown Derived Column Tests
+
+func TestWHC025_UnknownDerivedColumn(t *testing.T) {
+	q := testQuery("ErrorRate",
+		discovery.Calculation{Op: "AVG", ColumnRef: "ErrorRateColumn"},
+		discovery.Calculation{Op: "COUNT"},
+	)
+	issues := WHC025UnknownDerivedColumn().Check(q)
+	if len(issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %v", issues)
+	}
+	if issues[0].Severity != SeverityError {
+		t.Errorf("Expected error, got %v", issues[0].Severity)
+	}
+	if !strings.Contains(issues[0].Message, "ErrorRateColumn.Alias") || !strings.Contains(issues[0].Message, "package queries") {
+		t.Errorf("Unexpected message: %s", issues[0].Message)
+	}
+}
+
+func TestWHC025_ResolvedDerivedColumn(t *testing.T) {
+	q := testQuery("ErrorRate", discovery.Calculation{Op: "AVG", Column: "error_rate", ColumnRef: "ErrorRateColumn"})
+	if issues := WHC025UnknownDerivedColumn().Check(q); len(issues) != 0 {
+		t.Errorf("Expected no issues for a resolved derived column, got %v", issues)
+	}
+}
+
+func TestDerivedColumns_SkippedBySchemaRules(t *testing.T) {
+	q := testQuery("ErrorRate", discovery.Calculation{Op: "AVG", Column: "error_rate", ColumnRef: "ErrorRateColumn"})
+
+	if issues := WHC017UnknownColumnWithSchemas(whc017Schemas).Check(q); len(issues) != 0 {
+		t.Errorf("Expected derived columns not to be checked against the schema, got %v", issues)
+	}
+	if issues := WHC006InvalidCalculationForColumnType().Check(q); len(issues) != 0 {
+		t.Errorf("Expected derived columns not to be checked as string columns, got %v", issues)
+	}
+}
@@ -0,0 +1,96 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/resource"
+)
+
+// WHC026 Orphaned Query Tests
+
+func whc026Resources() *discovery.DiscoveredResources {
+	var queries []discovery.DiscoveredQuery
+	for i, name := range []string{"Latency", "Errors", "Success", "Total", "Unused"} {
+		q := testQuery(name)
+		q.Line = i + 1
+		queries = append(queries, q)
+	}
+	// Unused is discovered twice, reported once
+	queries = append(queries, queries[4])
+	// The inline query of the Inline trigger
+	inline := testQuery("Inline")
+	inline.Package = "alerts"
+	queries = append(queries, inline)
+
+	return &discovery.DiscoveredResources{
+		Queries: queries,
+		Boards: []discovery.DiscoveredBoard{{
+			Name: "Overview", Package: "boards", QueryRefs: []string{"Latency"},
+		}},
+		Triggers: []discovery.DiscoveredTrigger{
+			{Name: "HighErrors", Package: "alerts", QueryRef: "Errors"},
+			{Name: "Inline", Package: "alerts", InlineQuery: &discovery.DiscoveredQuery{Dataset: "production"}},
+		},
+		SLOs: []discovery.DiscoveredSLO{{
+			Name: "Availability", Package: "slos", GoodEventsQueryRef: "Success", TotalEventsQueryRef: "Total",
+		}},
+	}
+}
+
+func TestWHC026_OrphanedQuery(t *testing.T) {
+	issues := LintOrphanedQueries(whc026Resources(), nil)
+	if len(issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %v", issues)
+	}
+	if issues[0].Rule != "WHC026" || issues[0].Severity != SeverityWarning {
+		t.Errorf("Expected a WHC026 warning, got %s %v", issues[0].Rule, issues[0].Severity)
+	}
+	if issues[0].Line != 5 || !strings.Contains(issues[0].Message, "Query Unused") {
+		t.Errorf("Unexpected issue: %+v", issues[0])
+	}
+}
+
+func TestWHC026_Allowlist(t *testing.T) {
+	if issues := LintOrphanedQueries(whc026Resources(), []string{"Un*"}); len(issues) != 0 {
+		t.Errorf("Expected allowed queries to be skipped, got %v", issues)
+	}
+	if issues := LintOrphanedQueries(whc026Resources(), []string{"Adhoc*", "["}); len(issues) != 1 {
+		t.Errorf("Expected non-matching and invalid patterns to allow nothing, got %v", issues)
+	}
+}
+
+func TestWHC026_CustomRefs(t *testing.T) {
+	resources := whc026Resources()
+	resources.Custom = []resource.Resource{{Kind: "runbook", Name: "Oncall", Refs: []string{"Unused"}}}
+	if issues := LintOrphanedQueries(resources, nil); len(issues) != 0 {
+		t.Errorf("Expected queries referenced by custom resources to be used, got %v", issues)
+	}
+}
+
+func TestWHC026_QueriesOnly(t *testing.T) {
+	resources := &discovery.DiscoveredResources{
+		Queries: []discovery.DiscoveredQuery{testQuery("Unused")},
+	}
+	if issues := LintOrphanedQueries(resources, nil); len(issues) != 0 {
+		t.Errorf("Expected no issues without boards, triggers or SLOs, got %v", issues)
+	}
+}
+
+func TestWHC026_LintAllWithConfig(t *testing.T) {
+	issues := LintAllWithConfig(whc026Resources(), LintConfig{})
+	if countRule(issues, "WHC026") != 1 {
+		t.Errorf("Expected LintAllWithConfig to report WHC026, got %v", issues)
+	}
+
+	issues = LintAllWithConfig(whc026Resources(), LintConfig{OrphanAllowlist: []string{"Unused"}})
+	if countRule(issues, "WHC026") != 0 {
+		t.Errorf("Expected the allowlist to be honored, got %v", issues)
+	}
+
+	issues = LintAllWithConfig(whc026Resources(), LintConfig{DisabledRules: []string{"WHC026"}})
+	if countRule(issues, "WHC026") != 0 {
+		t.Errorf("Expected WHC026 to be disabled, got %v", issues)
+	}
+}
@@ -0,0 +1,76 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC027 Order and Limit Tests
+
+// whc027Query returns a query of the ten routes with the slowest P99.
+func whc027Query() discovery.DiscoveredQuery {
+	q := testQuery("SlowRoutes", discovery.Calculation{Op: "P99", Column: "duration_ms"})
+	q.Breakdowns = []string{"http.route"}
+	q.Orders = []discovery.Order{{Op: "P99", Column: "duration_ms", Order: "descending"}}
+	q.Limit = 10
+	return q
+}
+
+func TestWHC027_ValidTopN(t *testing.T) {
+	q := whc027Query()
+	q.Top = &discovery.Top{N: 10, Calculation: discovery.Calculation{Op: "P99", Column: "duration_ms"}}
+	if issues := WHC027InvalidOrder().Check(q); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %v", issues)
+	}
+}
+
+func TestWHC027_InvalidOrders(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(q *discovery.DiscoveredQuery)
+		want   string
+	}{
+		{
+			name:   "order on a missing calculation",
+			modify: func(q *discovery.DiscoveredQuery) { q.Orders[0].Op = "AVG" },
+			want:   "AVG(duration_ms) is not a calculation of the query",
+		},
+		{
+			name: "order on a column that is not a breakdown",
+			modify: func(q *discovery.DiscoveredQuery) {
+				q.Orders = []discovery.Order{{Column: "service.name", Order: "ascending"}}
+			},
+			want: `column "service.name" is not a breakdown`,
+		},
+		{
+			name: "limit contradicting TopN",
+			modify: func(q *discovery.DiscoveredQuery) {
+				q.Top = &discovery.Top{N: 5, Calculation: discovery.Calculation{Op: "P99", Column: "duration_ms"}}
+			},
+			want: "limit 10 contradicts TopN(5, ...)",
+		},
+		{
+			name:   "limit above the maximum",
+			modify: func(q *discovery.DiscoveredQuery) { q.Limit = 10000 },
+			want:   "must be between 1 and 1000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := whc027Query()
+			q.Orders = append([]discovery.Order(nil), q.Orders...)
+			tt.modify(&q)
+
+			issues := WHC027InvalidOrder().Check(q)
+			if len(issues) != 1 {
+				t.Fatalf("Expected 1 issue, got %v", issues)
+			}
+			if issues[0].Severity != SeverityError || !strings.Contains(issues[0].Message, tt.want) {
+				t.Errorf("Expected an error containing %q, got %s: %s", tt.want, issues[0].Severity, issues[0].Message)
+			}
+		})
+	}
+}
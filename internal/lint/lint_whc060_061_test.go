@@ -0,0 +1,142 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC060 Unalerted SLO and WHC061 Trigger Alert Type Tests
+
+func whc060Resources() *discovery.DiscoveredResources {
+	good := testQuery("GoodRequests")
+	good.Line = 3
+	good.Filters = []discovery.Filter{{Column: "http.response.code", Op: "<", Value: 500}}
+	all := testQuery("AllRequests")
+	all.Line = 9
+	failures := testQuery("Failures", discovery.Calculation{Op: "COUNT"})
+	failures.Filters = []discovery.Filter{{Column: "http.response.code", Op: ">=", Value: 500}}
+	latency := testQuery("Latency", discovery.Calculation{Op: "P99", Column: "duration_ms"})
+	latency.Line = 15
+	latency.Breakdowns = []string{"http.route"}
+
+	availability := testSLO("Availability", "GoodRequests", "AllRequests")
+	availability.Line = 5
+	lowTraffic := testTrigger("LowTraffic", "Traffic")
+	lowTraffic.Line = 3
+
+	return &discovery.DiscoveredResources{
+		Queries:  []discovery.DiscoveredQuery{good, all, testQuery("Traffic", discovery.Calculation{Op: "COUNT"}), failures, latency},
+		SLOs:     []discovery.DiscoveredSLO{availability},
+		Triggers: []discovery.DiscoveredTrigger{lowTraffic},
+	}
+}
+
+func TestWHC060_SLOWithoutAlerting(t *testing.T) {
+	issues := LintUnalertedSLOs(whc060Resources())
+	if len(issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %v", issues)
+	}
+	issue := issues[0]
+	if issue.Rule != WHC060 || issue.Severity != SeverityWarning || issue.Line != 5 {
+		t.Errorf("Unexpected issue: %+v", issue)
+	}
+	for _, want := range []string{
+		"SLO Availability",
+		"query GoodRequests (file.go:3), query AllRequests (file.go:9)",
+		"neither latency nor error: trigger LowTraffic (file.go:3)",
+	} {
+		if !strings.Contains(issue.Message, want) {
+			t.Errorf("Expected %q in %q", want, issue.Message)
+		}
+	}
+}
+
+func TestWHC060_Alerted(t *testing.T) {
+	tests := map[string]func(r *discovery.DiscoveredResources){
+		"burn alert": func(r *discovery.DiscoveredResources) {
+			r.SLOs[0].BurnAlertCount = 1
+		},
+		"latency trigger": func(r *discovery.DiscoveredResources) {
+			r.Triggers = append(r.Triggers, testTrigger("Slow", "Latency"))
+		},
+		"error trigger on an SLI column": func(r *discovery.DiscoveredResources) {
+			r.Triggers = append(r.Triggers, testTrigger("Failing", "Failures"))
+		},
+		"inline error trigger": func(r *discovery.DiscoveredResources) {
+			r.Triggers = append(r.Triggers, discovery.DiscoveredTrigger{Name: "Errors", InlineQuery: &discovery.DiscoveredQuery{
+				Dataset: "production", Filters: []discovery.Filter{{Column: "error", Op: "=", Value: true}},
+			}})
+		},
+	}
+	for name, alert := range tests {
+		r := whc060Resources()
+		alert(r)
+		if issues := LintUnalertedSLOs(r); len(issues) != 0 {
+			t.Errorf("%s: expected no issue, got %v", name, issues)
+		}
+	}
+}
+
+func TestWHC060_IgnoredTriggers(t *testing.T) {
+	disabled := testTrigger("Slow", "Latency")
+	disabled.Disabled = true
+	otherDataset := testTrigger("Other", "")
+	otherDataset.InlineQuery = &discovery.DiscoveredQuery{
+		Dataset: "web", Calculations: []discovery.Calculation{{Op: "P95", Column: "duration_ms"}},
+	}
+
+	r := whc060Resources()
+	r.Triggers = append(r.Triggers, disabled, otherDataset)
+	if issues := LintUnalertedSLOs(r); len(issues) != 1 {
+		t.Errorf("Expected 1 issue, got %v", issues)
+	}
+}
+
+func TestWHC061_TriggerWithBreakdownsWithoutAlertType(t *testing.T) {
+	slow := testTrigger("Slow", "Latency")
+	slow.Line = 9
+	explicit := testTrigger("SlowExplicit", "Latency")
+	explicit.AlertType = "on_true"
+	inline := testTrigger("Inline", "")
+	inline.Line = 20
+	inline.InlineQuery = &discovery.DiscoveredQuery{Breakdowns: []string{"db.name"}}
+	triggers := []discovery.DiscoveredTrigger{slow, explicit, inline, testTrigger("Ungrouped", "Traffic"), testTrigger("Unresolved", "Missing")}
+
+	r := whc060Resources()
+
+	issues := LintTriggerAlertTypes(triggers, r.Queries)
+	if len(issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %v", issues)
+	}
+	if issues[0].Rule != WHC061 || issues[0].Severity != SeverityWarning || issues[0].Line != 9 {
+		t.Errorf("Unexpected issue: %+v", issues[0])
+	}
+	if want := "Trigger Slow evaluates query Latency (file.go:15), grouped by http.route"; !strings.Contains(issues[0].Message, want) {
+		t.Errorf("Expected %q in %q", want, issues[0].Message)
+	}
+	if !strings.Contains(issues[1].Message, "its inline query, grouped by db.name") {
+		t.Errorf("Unexpected message %q", issues[1].Message)
+	}
+}
+
+func TestLintAll_ConsistencyRules(t *testing.T) {
+	r := whc060Resources()
+	r.Triggers = append(r.Triggers, testTrigger("Slow", "Latency"))
+	r.SLOs[0].BurnAlertCount = 0
+
+	rules := func(issues []Issue) map[string]bool {
+		found := make(map[string]bool)
+		for _, issue := range issues {
+			found[issue.Rule] = true
+		}
+		return found
+	}
+	if found := rules(LintAll(r)); !found[WHC061] || found[WHC060] {
+		t.Errorf("Expected WHC061 and no WHC060 (the latency trigger alerts on the SLO), got %v", found)
+	}
+	if found := rules(LintAllWithConfig(r, LintConfig{DisabledRules: []string{WHC061}})); found[WHC061] {
+		t.Error("Expected WHC061 to be disabled")
+	}
+}
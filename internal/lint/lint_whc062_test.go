@@ -0,0 +1,57 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC062 Duplicate Name Tests
+
+func whc062Resources() *discovery.DiscoveredResources {
+	first := testTrigger("HighLatency", "Latency")
+	first.Line = 3
+	second := testTrigger("HighLatencyAgain", "Latency")
+	second.Line = 8
+	for _, t := range []*discovery.DiscoveredTrigger{&first, &second} {
+		t.TriggerName = "High Latency"
+		t.ThresholdOp, t.ThresholdValue, t.FrequencySeconds = ">", 1, 300
+	}
+	return &discovery.DiscoveredResources{
+		Queries:  []discovery.DiscoveredQuery{testQuery("Latency")},
+		Triggers: []discovery.DiscoveredTrigger{first, second},
+	}
+}
+
+func TestWHC062_DuplicateTriggerName(t *testing.T) {
+	issues := LintDuplicateNames(whc062Resources())
+	if len(issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %v", issues)
+	}
+	issue := issues[0]
+	if issue.Rule != WHC062 || issue.Severity != SeverityError || issue.File != "/test/file.go" || issue.Line != 8 {
+		t.Errorf("Unexpected issue: %+v", issue)
+	}
+	want := `Trigger HighLatencyAgain is named "High Latency", like HighLatency (file.go:3) on dataset production`
+	if !strings.Contains(issue.Message, want) {
+		t.Errorf("Expected %q in %q", want, issue.Message)
+	}
+}
+
+func TestWHC062_UniqueNames(t *testing.T) {
+	r := whc062Resources()
+	r.Triggers[1].TriggerName = "Very High Latency"
+	if issues := LintDuplicateNames(r); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %v", issues)
+	}
+}
+
+func TestLintAll_WHC062(t *testing.T) {
+	if countRule(LintAll(whc062Resources()), WHC062) != 1 {
+		t.Error("Expected WHC062 from LintAll")
+	}
+	if countRule(LintAllWithConfig(whc062Resources(), LintConfig{DisabledRules: []string{WHC062}}), WHC062) != 0 {
+		t.Error("Expected WHC062 to be disabled")
+	}
+}
@@ -0,0 +1,94 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC063 Naming Policy Tests
+
+func whc063Resources() *discovery.DiscoveredResources {
+	slow := testQuery("Slow_requests")
+	slow.Line = 9
+	// Inline query of HighLatencyTrigger
+	inline := testQuery("HighLatencyTrigger")
+	inline.Package = "alerts"
+	return &discovery.DiscoveredResources{
+		Queries:  []discovery.DiscoveredQuery{testQuery("Latency"), slow, inline},
+		Triggers: []discovery.DiscoveredTrigger{testTrigger("HighLatencyTrigger", ""), testTrigger("ErrorsAlert", "")},
+	}
+}
+
+func TestWHC063_NamingPolicy(t *testing.T) {
+	policy := discovery.NamingPolicy{Queries: "[A-Z][A-Za-z0-9]*", Triggers: ".*Alert"}
+	issues := LintNamingPolicy(whc063Resources(), policy)
+	if len(issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %v", issues)
+	}
+
+	query := issues[0]
+	if query.Rule != WHC063 || query.Severity != SeverityWarning || query.Line != 9 {
+		t.Errorf("Unexpected issue: %+v", query)
+	}
+	if query.Suggestion != "wetwire-honeycomb rename query Slow_requests SlowRequests" {
+		t.Errorf("Unexpected suggestion %q", query.Suggestion)
+	}
+
+	trigger := issues[1]
+	want := "Trigger HighLatencyTrigger does not match the naming policy for triggers (.*Alert); rename it to HighLatencyAlert"
+	if !strings.Contains(trigger.Message, want) {
+		t.Errorf("Expected %q in %q", want, trigger.Message)
+	}
+}
+
+func TestWHC063_NoPolicy(t *testing.T) {
+	if issues := LintNamingPolicy(whc063Resources(), discovery.NamingPolicy{}); len(issues) != 0 {
+		t.Errorf("Expected no issues without a policy, got %v", issues)
+	}
+}
+
+func TestWHC063_NoSuggestion(t *testing.T) {
+	issues := LintNamingPolicy(whc063Resources(), discovery.NamingPolicy{Queries: "Q[0-9]+"})
+	if len(issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %v", issues)
+	}
+	for _, issue := range issues {
+		if issue.Suggestion != "" || strings.Contains(issue.Message, "rename it") {
+			t.Errorf("Expected no suggestion, got %+v", issue)
+		}
+	}
+}
+
+func TestSuggestName(t *testing.T) {
+	tests := []struct {
+		name, typ, pattern, want string
+	}{
+		{"HighLatency", "trigger", ".*Alert", "HighLatencyAlert"},
+		{"HighLatencyTrigger", "trigger", ".*Alert", "HighLatencyAlert"},
+		{"high_latency", "trigger", "[A-Z].*Alert", "HighLatencyAlert"},
+		{"Latency", "board", "Team[A-Z].*", "TeamLatency"},
+		{"Latency", "query", "[a-z]+", ""},
+	}
+	for _, tt := range tests {
+		re, err := discovery.CompileNamingPattern(tt.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := suggestName(tt.name, tt.typ, re); got != tt.want {
+			t.Errorf("suggestName(%q, %q) = %q, want %q", tt.name, tt.pattern, got, tt.want)
+		}
+	}
+}
+
+func TestLintAllWithConfig_NamingPolicy(t *testing.T) {
+	config := LintConfig{Naming: discovery.NamingPolicy{Triggers: ".*Alert"}}
+	if issues := FilterByRule(LintAllWithConfig(whc063Resources(), config), WHC063); len(issues) != 1 {
+		t.Errorf("Expected 1 WHC063 issue, got %v", issues)
+	}
+	config.DisabledRules = []string{WHC063}
+	if issues := FilterByRule(LintAllWithConfig(whc063Resources(), config), WHC063); len(issues) != 0 {
+		t.Errorf("Expected no WHC063 issues when disabled, got %v", issues)
+	}
+}
@@ -0,0 +1,48 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC064 Escalation Tests
+
+// whc064Triggers returns the levels of the HighLatency escalation, above
+// 500 and above critical, and a trigger of no escalation.
+func whc064Triggers(critical float64) []discovery.DiscoveredTrigger {
+	triggers := []discovery.DiscoveredTrigger{
+		testTrigger("HighLatencyWarning", "Latency"),
+		testTrigger("HighLatencyCritical", "Latency"),
+		testTrigger("Errors", "Errors"),
+	}
+	for i, threshold := range []float64{500, critical, 1} {
+		triggers[i].Line = 12 + i
+		triggers[i].ThresholdOp, triggers[i].ThresholdValue = ">", threshold
+	}
+	triggers[0].Escalation, triggers[0].Level = "HighLatency", "warning"
+	triggers[1].Escalation, triggers[1].Level = "HighLatency", "critical"
+	return triggers
+}
+
+func TestWHC064_NotEscalating(t *testing.T) {
+	issues := LintEscalations(whc064Triggers(400))
+	if len(issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %v", issues)
+	}
+	issue := issues[0]
+	if issue.Rule != WHC064 || issue.Severity != SeverityError || issue.Line != 12 {
+		t.Errorf("Unexpected issue: %+v", issue)
+	}
+	want := `Escalation HighLatency: level "critical" threshold > 400 does not escalate level "warning" threshold > 500`
+	if !strings.Contains(issue.Message, want) {
+		t.Errorf("Expected %q in %q", want, issue.Message)
+	}
+}
+
+func TestWHC064_Escalating(t *testing.T) {
+	if issues := LintEscalations(whc064Triggers(1000)); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %v", issues)
+	}
+}
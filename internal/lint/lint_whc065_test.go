@@ -0,0 +1,54 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC065 Maintenance Window Tests
+
+func whc065Resources(m discovery.DiscoveredMute) *discovery.DiscoveredResources {
+	m.Name, m.File, m.Line = "Upgrade", "/test/alerts/mutes.go", 7
+	highLatency := testTrigger("HighLatency", "Latency")
+	highLatency.TriggerName = "High Latency"
+	slowQueries := testTrigger("SlowQueries", "Queries")
+	slowQueries.Tags = []discovery.DiscoveredTag{{Key: "team", Value: "storage"}}
+	return &discovery.DiscoveredResources{
+		Triggers: []discovery.DiscoveredTrigger{highLatency, slowQueries},
+		Mutes:    []discovery.DiscoveredMute{m},
+	}
+}
+
+func TestWHC065_Valid(t *testing.T) {
+	resources := whc065Resources(discovery.DiscoveredMute{Start: "2026-11-07T02:00:00Z", End: "2026-11-07T04:00:00Z", Tags: []string{"team=storage"}})
+	if issues := LintMutes(resources); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %v", issues)
+	}
+}
+
+func TestWHC065_Invalid(t *testing.T) {
+	resources := whc065Resources(discovery.DiscoveredMute{Start: "2026-11-07T04:00:00Z", End: "2026-11-07T02:00:00Z", Triggers: []string{"HighLatency"}})
+	issues := LintMutes(resources)
+	if len(issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %v", issues)
+	}
+	if issues[0].Rule != WHC065 || issues[0].Severity != SeverityError || issues[0].Line != 7 {
+		t.Errorf("Unexpected issue: %+v", issues[0])
+	}
+	if !strings.Contains(issues[0].Message, "is not after start") {
+		t.Errorf("Unexpected message: %s", issues[0].Message)
+	}
+}
+
+func TestWHC065_SelectsNothing(t *testing.T) {
+	resources := whc065Resources(discovery.DiscoveredMute{Start: "2026-11-07T02:00:00Z", End: "2026-11-07T04:00:00Z", Triggers: []string{"HighLatencey"}})
+	issues := LintMutes(resources)
+	if len(issues) != 1 || issues[0].Severity != SeverityWarning {
+		t.Fatalf("Expected 1 warning, got %v", issues)
+	}
+	if !strings.Contains(issues[0].Message, "Maintenance window Upgrade selects no triggers") {
+		t.Errorf("Unexpected message: %s", issues[0].Message)
+	}
+}
@@ -0,0 +1,56 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC066 Unsupported Declaration Tests
+
+func TestWHC066_None(t *testing.T) {
+	if issues := LintUnsupported(&discovery.DiscoveredResources{}); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %v", issues)
+	}
+}
+
+func TestWHC066_InitFunction(t *testing.T) {
+	resources := &discovery.DiscoveredResources{
+		Unsupported: []discovery.UnsupportedDeclaration{{
+			Kind:    "query",
+			Name:    "Checkout",
+			File:    "/test/alerts/queries.go",
+			Line:    12,
+			Context: "in init()",
+			Reason:  "discovery reads declarations without running code",
+		}},
+	}
+	issues := LintUnsupported(resources)
+	if len(issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %v", issues)
+	}
+	if issues[0].Rule != WHC066 || issues[0].Severity != SeverityError || issues[0].Line != 12 {
+		t.Errorf("Unexpected issue: %+v", issues[0])
+	}
+	if !strings.Contains(issues[0].Message, "The query Checkout in init() is not discovered") || !strings.Contains(issues[0].Message, "package-level variable") {
+		t.Errorf("Unexpected message: %s", issues[0].Message)
+	}
+}
+
+func TestWHC066_FleetTemplate(t *testing.T) {
+	resources := &discovery.DiscoveredResources{
+		Unsupported: []discovery.UnsupportedDeclaration{{
+			Kind:   "fleet.Each template",
+			Name:   "Latency",
+			Reason: "the service list is not a []string literal or a package-level variable holding one",
+		}},
+	}
+	issues := LintUnsupported(resources)
+	if len(issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %v", issues)
+	}
+	if !strings.Contains(issues[0].Message, "list the services in a []string literal") {
+		t.Errorf("Unexpected message: %s", issues[0].Message)
+	}
+}
@@ -0,0 +1,98 @@
+package lint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC067 Trigger Window and WHC068 SLI Window Tests
+
+func TestWHC067_TriggerWindows(t *testing.T) {
+	queries := []discovery.DiscoveredQuery{
+		{Name: "Errors", File: "/test/queries.go", Line: 3, TimeRange: discovery.TimeRange{TimeRange: 900}},
+		{Name: "Weekly", File: "/test/queries.go", Line: 9, TimeRange: discovery.TimeRange{TimeRange: 2 * 86400}},
+		{Name: "Incident", File: "/test/queries.go", Line: 15, TimeRange: discovery.TimeRange{StartTime: 1700000000, EndTime: 1700003600}},
+	}
+	triggers := []discovery.DiscoveredTrigger{
+		{Name: "HighErrors", File: "/test/triggers.go", Line: 3, QueryRef: "Errors", FrequencySeconds: 300},
+		{Name: "WeeklyErrors", File: "/test/triggers.go", Line: 9, QueryRef: "Weekly", FrequencySeconds: 86400},
+		{Name: "IncidentErrors", File: "/test/triggers.go", Line: 15, QueryRef: "Incident", FrequencySeconds: 900},
+		{Name: "Inline", File: "/test/triggers.go", Line: 21, FrequencySeconds: 3600,
+			InlineQuery: &discovery.DiscoveredQuery{TimeRange: discovery.TimeRange{TimeRange: 3 * 86400}}},
+	}
+
+	issues := LintTriggerWindows(triggers, queries)
+	if len(issues) != 3 {
+		t.Fatalf("Expected 3 issues, got %v", issues)
+	}
+	for i, want := range []string{
+		"Trigger WeeklyErrors evaluates query Weekly (queries.go:9) over 2d, more than the 1d a trigger can evaluate; shorten it to at most 1d",
+		"Trigger IncidentErrors evaluates query Incident (queries.go:15) over an absolute time range; triggers evaluate the last N minutes, use a relative range of at most 1h",
+		"Trigger Inline evaluates its inline query over 3d, more than the 1d a trigger can evaluate; shorten it to at most 4h",
+	} {
+		if issues[i].Rule != WHC067 || issues[i].Severity != SeverityError || issues[i].Message != want {
+			t.Errorf("Expected %q, got %+v", want, issues[i])
+		}
+	}
+}
+
+func TestWHC068_SLIWindows(t *testing.T) {
+	queries := []discovery.DiscoveredQuery{
+		{Name: "GoodRequests", File: "/test/queries.go", Line: 3, TimeRange: discovery.TimeRange{TimeRange: 3600}},
+		{Name: "AllRequests", File: "/test/queries.go", Line: 9, TimeRange: discovery.TimeRange{TimeRange: 3600}},
+		{Name: "WeeklyRequests", File: "/test/queries.go", Line: 15, TimeRange: discovery.TimeRange{TimeRange: 7 * 86400}},
+		{Name: "Untimed", File: "/test/queries.go", Line: 21},
+	}
+	slos := []discovery.DiscoveredSLO{
+		{Name: "Consistent", File: "/test/slos.go", Line: 3, TimePeriodDays: 30,
+			GoodEventsQueryRef: "GoodRequests", TotalEventsQueryRef: "AllRequests"},
+		{Name: "Mismatched", File: "/test/slos.go", Line: 9, TimePeriodDays: 1,
+			GoodEventsQueryRef: "GoodRequests", TotalEventsQueryRef: "WeeklyRequests"},
+		{Name: "Untimed", File: "/test/slos.go", Line: 15, TimePeriodDays: 1,
+			GoodEventsQueryRef: "Untimed", TotalEventsQueryRef: "Untimed"},
+		{Name: "Weekly", File: "/test/slos.go", Line: 21, TimePeriodDays: 1,
+			GoodEventsQueryRef: "WeeklyRequests", TotalEventsQueryRef: "WeeklyRequests"},
+		{Name: "Inline", File: "/test/slos.go", Line: 27, TimePeriodDays: 1,
+			GoodEventsQuery:  &discovery.DiscoveredQuery{TimeRange: discovery.TimeRange{TimeRange: 86400}},
+			TotalEventsQuery: &discovery.DiscoveredQuery{TimeRange: discovery.TimeRange{StartTime: 1700000000, EndTime: 1700086400}}},
+	}
+
+	issues := LintSLIWindows(slos, queries)
+	if len(issues) != 5 {
+		t.Fatalf("Expected 5 issues, got %v", issues)
+	}
+	for i, want := range []string{
+		"SLO Mismatched: good events query GoodRequests (queries.go:3) covers 1h but total events query WeeklyRequests (queries.go:15) covers 7d; the SLI compares different windows",
+		"SLO Mismatched: total events query WeeklyRequests (queries.go:15) covers 7d, more than the 1d time period of the SLO; use a relative range of at most 1d",
+		"SLO Weekly: good and total events query WeeklyRequests (queries.go:15) covers 7d, more than the 1d time period of the SLO",
+		"SLO Inline: good events inline query covers 1d but total events inline query covers an absolute time range",
+		"SLO Inline: total events inline query covers an absolute time range, which does not roll with the 1d time period of the SLO",
+	} {
+		if issues[i].Rule != WHC068 || issues[i].Severity != SeverityWarning || !strings.HasPrefix(issues[i].Message, want) {
+			t.Errorf("Expected %q, got %+v", want, issues[i])
+		}
+	}
+}
+
+func TestWHC067_Disabled(t *testing.T) {
+	resources := &discovery.DiscoveredResources{
+		Triggers: []discovery.DiscoveredTrigger{{Name: "Weekly", FrequencySeconds: 86400,
+			InlineQuery: &discovery.DiscoveredQuery{TimeRange: discovery.TimeRange{TimeRange: 2 * 86400}}}},
+	}
+	found := func(issues []Issue) bool {
+		for _, issue := range issues {
+			if issue.Rule == WHC067 {
+				return true
+			}
+		}
+		return false
+	}
+	if !found(LintAll(resources)) {
+		t.Error("Expected WHC067 from LintAll")
+	}
+	if found(LintAllWithConfig(resources, LintConfig{DisabledRules: []string{WHC067}})) {
+		t.Error("Expected WHC067 to be disabled")
+	}
+}
@@ -0,0 +1,39 @@
+package lint
+
+import (
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC069 Field Mistake Tests
+
+func TestWHC069_None(t *testing.T) {
+	if issues := LintFieldMistakes(&discovery.DiscoveredResources{}); len(issues) != 0 {
+		t.Errorf("Expected no issues, got %v", issues)
+	}
+}
+
+func TestWHC069_FieldMistake(t *testing.T) {
+	resources := &discovery.DiscoveredResources{
+		FieldMistakes: []discovery.FieldMistake{{
+			Type:       "query.Query",
+			Field:      "Breakdown",
+			Name:       "Latency",
+			File:       "/test/queries.go",
+			Line:       7,
+			Problem:    "has no field Breakdown",
+			Suggestion: "Breakdowns",
+		}},
+	}
+	issues := LintFieldMistakes(resources)
+	if len(issues) != 1 {
+		t.Fatalf("Expected 1 issue, got %v", issues)
+	}
+	if issues[0].Rule != WHC069 || issues[0].Severity != SeverityError || issues[0].Line != 7 {
+		t.Errorf("Unexpected issue: %+v", issues[0])
+	}
+	if want := "Latency: query.Query has no field Breakdown; did you mean Breakdowns?"; issues[0].Message != want {
+		t.Errorf("Expected %q, got %q", want, issues[0].Message)
+	}
+}
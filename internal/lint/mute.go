@@ -0,0 +1,33 @@
+package lint
+
+import (
+	"fmt"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC065 flags maintenance windows that are invalid or mute nothing. It
+// matches windows against every trigger, so it runs from LintAllWithConfig.
+const WHC065 = "WHC065"
+
+// LintMutes returns a WHC065 error for each mute.Window failing
+// mute.Window.Validate, such as an unparsable start or an end before its
+// start, and a warning for each valid window selecting no discovered
+// trigger, usually a misspelled name or tag.
+func LintMutes(resources *discovery.DiscoveredResources) []Issue {
+	var results []Issue
+	for _, m := range resources.Mutes {
+		issue := Issue{Rule: WHC065, File: m.File, Line: m.Line}
+		if err := m.Window().Validate(); err != nil {
+			issue.Severity = SeverityError
+			issue.Message = fmt.Sprintf("Maintenance window %s: %v", m.Name, err)
+		} else if len(resources.MutedTriggers(m)) == 0 {
+			issue.Severity = SeverityWarning
+			issue.Message = fmt.Sprintf("Maintenance window %s selects no triggers; check its trigger names and tags", m.Name)
+		} else {
+			continue
+		}
+		results = append(results, issue)
+	}
+	return results
+}
@@ -0,0 +1,36 @@
+package lint
+
+import (
+	"fmt"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC062 flags triggers and SLOs whose display name is already used by
+// another of the same kind on their dataset, which Honeycomb rejects. Like
+// WHC026 it needs every resource of the project, so it runs from
+// LintAllWithConfig.
+const WHC062 = "WHC062"
+
+// LintDuplicateNames returns a WHC062 error for each trigger and SLO of
+// resources sharing its Name field with an earlier one on the same dataset
+// (see discovery.DiscoveredResources.NameConflicts). The message names the
+// earlier resource, which keeps the name.
+func LintDuplicateNames(resources *discovery.DiscoveredResources) []Issue {
+	var results []Issue
+	for _, c := range resources.NameConflicts() {
+		kind := "Trigger"
+		if c.Kind == "slo" {
+			kind = "SLO"
+		}
+		results = append(results, Issue{
+			Rule:     WHC062,
+			Severity: SeverityError,
+			Message: fmt.Sprintf("%s %s is named %q, like %s on dataset %s; Honeycomb rejects duplicate names in a dataset, so rename it or build with --rename-on-conflict",
+				kind, c.Name, c.DisplayName, resourceLocation(c.First, c.FirstFile, c.FirstLine), c.Dataset),
+			File: c.File,
+			Line: c.Line,
+		})
+	}
+	return results
+}
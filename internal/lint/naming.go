@@ -0,0 +1,180 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC063 flags resources whose Go variable name does not match the naming
+// policy of their type in the ConfigFile (see discovery.NamingPolicy). Like
+// WHC026 it needs the project configuration, so it runs from
+// LintAllWithConfig.
+const WHC063 = "WHC063"
+
+// LintNamingPolicy returns a WHC063 warning for each query, SLO, trigger and
+// board of resources whose name does not match the pattern of policy for its
+// type. When a rename would match, e.g. HighLatency to HighLatencyAlert for
+// ".*Alert", the issue suggests the rename command that applies it.
+// Inline queries take the name of the resource holding them and are left
+// out; so are types with an empty or invalid pattern.
+func LintNamingPolicy(resources *discovery.DiscoveredResources, policy discovery.NamingPolicy) []Issue {
+	policyPatterns := policy.Patterns()
+	patterns := make(map[string]*regexp.Regexp)
+	for key, pattern := range policyPatterns {
+		if re, err := discovery.CompileNamingPattern(pattern); err == nil {
+			patterns[key] = re
+		}
+	}
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	var results []Issue
+	check := func(key, typ, name, file string, line int) {
+		re := patterns[key]
+		if re == nil || re.MatchString(name) {
+			return
+		}
+		issue := Issue{
+			Rule:     WHC063,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("%s %s does not match the naming policy for %s (%s)", namingLabels[typ], name, key, policyPatterns[key]),
+			File:     file,
+			Line:     line,
+		}
+		if suggested := suggestName(name, typ, re); suggested != "" {
+			issue.Suggestion = fmt.Sprintf("wetwire-honeycomb rename %s %s %s", typ, name, suggested)
+			issue.Message += fmt.Sprintf("; rename it to %s with %q", suggested, issue.Suggestion)
+		}
+		results = append(results, issue)
+	}
+
+	holders := make(map[string]bool)
+	for _, b := range resources.Boards {
+		holders[b.Package+"."+b.Name] = true
+	}
+	for _, t := range resources.Triggers {
+		holders[t.Package+"."+t.Name] = true
+	}
+	for _, s := range resources.SLOs {
+		holders[s.Package+"."+s.Name] = true
+	}
+
+	for _, q := range resources.Queries {
+		if !holders[q.Package+"."+q.Name] {
+			check("queries", "query", q.Name, q.File, q.Line)
+		}
+	}
+	for _, s := range resources.SLOs {
+		check("slos", "slo", s.Name, s.File, s.Line)
+	}
+	for _, t := range resources.Triggers {
+		check("triggers", "trigger", t.Name, t.File, t.Line)
+	}
+	for _, b := range resources.Boards {
+		check("boards", "board", b.Name, b.File, b.Line)
+	}
+	return results
+}
+
+// namingLabels are the labels of the rename command's resource types in
+// messages.
+var namingLabels = map[string]string{"query": "Query", "slo": "SLO", "trigger": "Trigger", "board": "Board"}
+
+// suggestName returns a name close to name that re matches, or "" if none
+// is found. Candidates are name in CamelCase, without a trailing type word
+// such as "Trigger", and with the literal prefix and suffix of the pattern
+// added, so "high_latency_trigger" becomes "HighLatencyAlert" for ".*Alert".
+func suggestName(name, typ string, re *regexp.Regexp) string {
+	prefix, suffix := literalAffixes(re.String())
+
+	var bases []string
+	for _, base := range []string{name, camelCase(name)} {
+		for _, word := range []string{namingLabels[typ], "Alert", "Trigger", "Query", "Board"} {
+			if trimmed := strings.TrimSuffix(base, word); trimmed != base && trimmed != "" && word != suffix {
+				bases = append(bases, trimmed)
+			}
+		}
+		bases = append(bases, base)
+	}
+	for _, base := range bases {
+		for _, candidate := range []string{
+			base,
+			strings.TrimSuffix(base, suffix) + suffix,
+			prefix + strings.TrimPrefix(base, prefix),
+			prefix + strings.TrimPrefix(strings.TrimSuffix(base, suffix)+suffix, prefix),
+		} {
+			if candidate != name && re.MatchString(candidate) {
+				return candidate
+			}
+		}
+	}
+	return ""
+}
+
+// literalAffixes returns the literal text a pattern requires at the start
+// and at the end of a name, e.g. "" and "Alert" for "^(?:.*Alert)$".
+func literalAffixes(pattern string) (prefix, suffix string) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", ""
+	}
+	parts := flattenConcat(re.Simplify())
+	if len(parts) > 0 && parts[0].Op == syntax.OpBeginText {
+		parts = parts[1:]
+	}
+	if len(parts) > 0 && parts[len(parts)-1].Op == syntax.OpEndText {
+		parts = parts[:len(parts)-1]
+	}
+	if len(parts) < 2 {
+		return "", ""
+	}
+	if first := parts[0]; first.Op == syntax.OpLiteral && first.Flags&syntax.FoldCase == 0 {
+		prefix = string(first.Rune)
+	}
+	if last := parts[len(parts)-1]; last.Op == syntax.OpLiteral && last.Flags&syntax.FoldCase == 0 {
+		suffix = string(last.Rune)
+	}
+	return prefix, suffix
+}
+
+// flattenConcat returns the elements of re when it is a concatenation,
+// including those of nested concatenations and groups.
+func flattenConcat(re *syntax.Regexp) []*syntax.Regexp {
+	switch re.Op {
+	case syntax.OpConcat:
+		var parts []*syntax.Regexp
+		for _, sub := range re.Sub {
+			parts = append(parts, flattenConcat(sub)...)
+		}
+		return parts
+	case syntax.OpCapture:
+		return flattenConcat(re.Sub[0])
+	default:
+		return []*syntax.Regexp{re}
+	}
+}
+
+// camelCase joins the words of name, separated by underscores or dashes,
+// with each word capitalized: "high_latency" becomes "HighLatency".
+func camelCase(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '_' || r == '-' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
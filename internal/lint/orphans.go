@@ -0,0 +1,86 @@
+package lint
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC026 flags exported queries that no board, trigger, SLO or registered
+// resource kind refers to. Unlike the other query rules it needs every
+// resource of the project, so it runs from LintAllWithConfig rather than
+// AllRules.
+const WHC026 = "WHC026"
+
+// LintOrphanedQueries returns a WHC026 warning for each query of resources
+// that nothing references. References are resolved by name, as build
+// resolves them: variables, variables of other packages and functions
+// returning a query all count. Inline queries are used by the resource
+// holding them and are never flagged.
+//
+// Queries whose name matches one of the allow patterns (path.Match syntax,
+// e.g. "Adhoc*") are left out. Resources without any board, trigger, SLO or
+// registered resource are a package of queries linted on its own, for which
+// the rule reports nothing.
+func LintOrphanedQueries(resources *discovery.DiscoveredResources, allow []string) []Issue {
+	if len(resources.Boards) == 0 && len(resources.Triggers) == 0 && len(resources.SLOs) == 0 && len(resources.Custom) == 0 {
+		return nil
+	}
+
+	refs := make(map[string]bool)
+	// Inline queries are discovered under the name of the resource holding
+	// them
+	holders := make(map[string]bool)
+	for _, b := range resources.Boards {
+		holders[b.Package+"."+b.Name] = true
+		for _, ref := range b.QueryRefs {
+			refs[ref] = true
+		}
+		for _, p := range b.Panels {
+			refs[p.QueryRef] = true
+		}
+	}
+	for _, t := range resources.Triggers {
+		holders[t.Package+"."+t.Name] = true
+		refs[t.QueryRef] = true
+	}
+	for _, s := range resources.SLOs {
+		holders[s.Package+"."+s.Name] = true
+		refs[s.GoodEventsQueryRef] = true
+		refs[s.TotalEventsQueryRef] = true
+	}
+	for _, c := range resources.Custom {
+		for _, ref := range c.Refs {
+			refs[ref] = true
+		}
+	}
+
+	var results []Issue
+	seen := make(map[string]bool)
+	for _, q := range resources.Queries {
+		key := fmt.Sprintf("%s:%d:%s", q.File, q.Line, q.Name)
+		if seen[key] || refs[q.Name] || holders[q.Package+"."+q.Name] || allowed(q.Name, allow) {
+			continue
+		}
+		seen[key] = true
+		results = append(results, Issue{
+			Rule:     WHC026,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("Query %s is not used by any board, trigger or SLO", q.Name),
+			File:     q.File,
+			Line:     q.Line,
+		})
+	}
+	return results
+}
+
+// allowed reports whether name matches one of the patterns.
+func allowed(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,172 @@
+package lint
+
+import (
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Workers is the number of goroutines rules are evaluated on. Zero or less
+// means runtime.GOMAXPROCS.
+var Workers int
+
+// SlowCheck is the average time per resource above which a rule is reported
+// as slow.
+var SlowCheck = time.Millisecond
+
+// Timings accumulates the time spent in each rule, for lint --debug and
+// --profile. A nil *Timings records nothing. It is safe for concurrent use.
+type Timings struct {
+	// Memory also records the allocations of each rule. Allocation counters
+	// are process-wide, so rules then run one at a time.
+	Memory bool
+
+	mu    sync.Mutex
+	rules map[string]*RuleTiming
+}
+
+// RuleTiming is the time spent in one rule over every resource it checked.
+type RuleTiming struct {
+	Rule     string        `json:"rule"`
+	Checks   int           `json:"checks"`
+	Duration time.Duration `json:"duration_ns"`
+
+	// Allocs and Bytes are the heap allocations of the rule, with
+	// Timings.Memory
+	Allocs uint64 `json:"allocs,omitempty"`
+	Bytes  uint64 `json:"bytes,omitempty"`
+
+	// Slow is set when the rule took more than SlowCheck per resource
+	Slow bool `json:"slow,omitempty"`
+}
+
+// add records one check of rule that took d.
+func (t *Timings) add(rule string, d time.Duration, allocs, bytes uint64) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.rules == nil {
+		t.rules = make(map[string]*RuleTiming)
+	}
+	rt, ok := t.rules[rule]
+	if !ok {
+		rt = &RuleTiming{Rule: rule}
+		t.rules[rule] = rt
+	}
+	rt.Checks++
+	rt.Duration += d
+	rt.Allocs += allocs
+	rt.Bytes += bytes
+}
+
+// time runs check and records its duration, and allocations with Memory,
+// under rule.
+func (t *Timings) time(rule string, check func() []Issue) []Issue {
+	if t == nil {
+		return check()
+	}
+	if !t.Memory {
+		start := time.Now()
+		issues := check()
+		t.add(rule, time.Since(start), 0, 0)
+		return issues
+	}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	issues := check()
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	t.add(rule, elapsed, after.Mallocs-before.Mallocs, after.TotalAlloc-before.TotalAlloc)
+	return issues
+}
+
+// Rules returns the timing of each rule, slowest first and then by rule code.
+func (t *Timings) Rules() []RuleTiming {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rules := make([]RuleTiming, 0, len(t.rules))
+	for _, rt := range t.rules {
+		timing := *rt
+		timing.Slow = timing.Duration > SlowCheck*time.Duration(timing.Checks)
+		rules = append(rules, timing)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Duration != rules[j].Duration {
+			return rules[i].Duration > rules[j].Duration
+		}
+		return rules[i].Rule < rules[j].Rule
+	})
+	return rules
+}
+
+// runChecks evaluates rules rules against n resources on a pool of Workers.
+// check(i, j) runs rule j on resource i and code(j) names the rule for
+// timings. Findings are merged in resource order, then rule order, whatever
+// order the checks finished in, so output does not depend on scheduling.
+func runChecks(n, rules int, check func(i, j int) []Issue, code func(j int) string, timings *Timings) []Issue {
+	total := n * rules
+	if total == 0 {
+		return nil
+	}
+
+	slots := make([][]Issue, total)
+	run := func(k int) {
+		i, j := k/rules, k%rules
+		slots[k] = timings.time(code(j), func() []Issue { return check(i, j) })
+	}
+
+	workers := Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if timings != nil && timings.Memory {
+		workers = 1
+	}
+	if workers == 1 || total == 1 {
+		for k := range total {
+			run(k)
+		}
+	} else {
+		jobs := make(chan int)
+		var wg sync.WaitGroup
+		for range min(workers, total) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for k := range jobs {
+					run(k)
+				}
+			}()
+		}
+		for k := range total {
+			jobs <- k
+		}
+		close(jobs)
+		wg.Wait()
+	}
+
+	var results []Issue
+	for _, issues := range slots {
+		results = append(results, issues...)
+	}
+	return results
+}
+
+// sortIssues sorts issues by file, then line, keeping the order of issues on
+// the same line.
+func sortIssues(issues []Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].File != issues[j].File {
+			return issues[i].File < issues[j].File
+		}
+		return issues[i].Line < issues[j].Line
+	})
+}
@@ -0,0 +1,132 @@
+package lint
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// manyResources returns queries and triggers with several findings each,
+// spread over a few files so that many findings share a file and line.
+func manyResources() *discovery.DiscoveredResources {
+	resources := &discovery.DiscoveredResources{}
+	for i := range 200 {
+		file := fmt.Sprintf("/test/file%d.go", i%3)
+		resources.Queries = append(resources.Queries, discovery.DiscoveredQuery{
+			Name:       fmt.Sprintf("Query%d", i),
+			Package:    "test",
+			File:       file,
+			Line:       i % 7,
+			Breakdowns: []string{"endpoint"},
+		})
+		resources.Triggers = append(resources.Triggers, discovery.DiscoveredTrigger{
+			Name:    fmt.Sprintf("Trigger%d", i),
+			Package: "test",
+			File:    file,
+			Line:    i % 7,
+		})
+	}
+	return resources
+}
+
+func TestLintAllWithConfig_Deterministic(t *testing.T) {
+	defer func(workers int) { Workers = workers }(Workers)
+	resources := manyResources()
+
+	Workers = 1
+	serial := LintAllWithConfig(resources, LintConfig{})
+	if len(serial) == 0 {
+		t.Fatal("expected findings")
+	}
+
+	Workers = 8
+	for range 5 {
+		parallel := LintAllWithConfig(resources, LintConfig{})
+		if !reflect.DeepEqual(serial, parallel) {
+			t.Fatal("expected parallel lint to return the serial results in the same order")
+		}
+	}
+}
+
+func TestLintAllWithConfig_Timings(t *testing.T) {
+	resources := manyResources()
+	timings := &Timings{}
+	LintAllWithConfig(resources, LintConfig{Timings: timings, DisabledRules: []string{"WHC002"}})
+
+	checks := make(map[string]int)
+	for _, rt := range timings.Rules() {
+		checks[rt.Rule] = rt.Checks
+	}
+	if checks["WHC001"] != len(resources.Queries) {
+		t.Errorf("expected WHC001 to check %d queries, got %d", len(resources.Queries), checks["WHC001"])
+	}
+	if checks["WHC050"] != len(resources.Triggers) {
+		t.Errorf("expected WHC050 to check %d triggers, got %d", len(resources.Triggers), checks["WHC050"])
+	}
+	if checks[WHC026] != 1 || checks[WHC055] != 1 {
+		t.Errorf("expected cross-resource rules to run once, got %v", checks)
+	}
+	if _, ok := checks["WHC002"]; ok {
+		t.Error("expected no timing for a disabled rule")
+	}
+}
+
+func TestTimings_Rules(t *testing.T) {
+	var nilTimings *Timings
+	nilTimings.add("WHC001", 1, 0, 0)
+	if nilTimings.Rules() != nil {
+		t.Error("expected a nil Timings to record nothing")
+	}
+
+	timings := &Timings{}
+	timings.add("WHC002", 5, 0, 0)
+	timings.add("WHC001", 5, 0, 0)
+	timings.add("WHC003", 20, 0, 0)
+	timings.add("WHC001", 1, 0, 0)
+
+	var order []string
+	for _, rt := range timings.Rules() {
+		order = append(order, rt.Rule)
+	}
+	if want := []string{"WHC003", "WHC001", "WHC002"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("expected slowest first, then by rule, got %v", order)
+	}
+}
+
+func TestLintAllWithConfig_Memory(t *testing.T) {
+	resources := manyResources()
+	timings := &Timings{Memory: true}
+	profiled := LintAllWithConfig(resources, LintConfig{Timings: timings})
+
+	if !reflect.DeepEqual(profiled, LintAllWithConfig(resources, LintConfig{})) {
+		t.Error("expected profiling not to change the results")
+	}
+
+	var allocs uint64
+	for _, rt := range timings.Rules() {
+		allocs += rt.Allocs
+		if rt.Allocs > 0 && rt.Bytes == 0 {
+			t.Errorf("%s: expected bytes with %d allocations", rt.Rule, rt.Allocs)
+		}
+	}
+	if allocs == 0 {
+		t.Error("expected rules reporting findings to allocate")
+	}
+}
+
+func TestTimings_Slow(t *testing.T) {
+	timings := &Timings{}
+	timings.add("WHC001", 3*SlowCheck, 0, 0)
+	timings.add("WHC001", 0, 0, 0)
+	timings.add("WHC002", SlowCheck/2, 0, 0)
+
+	slow := make(map[string]bool)
+	for _, rt := range timings.Rules() {
+		slow[rt.Rule] = rt.Slow
+	}
+	if !slow["WHC001"] || slow["WHC002"] {
+		t.Errorf("expected only WHC001 to average over SlowCheck, got %v", slow)
+	}
+}
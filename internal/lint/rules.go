@@ -3,8 +3,11 @@ package lint
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/envsubst"
+	hcquery "github.com/lex00/wetwire-honeycomb-go/query"
 )
 
 // Rule represents a lint rule that can be applied to queries.
@@ -32,10 +35,18 @@ func AllRules() []Rule {
 		WHC012SecretInFilter(),
 		WHC013SensitiveColumnExposure(),
 		WHC014HardcodedCredentials(),
+		WHC015InvalidFilterValue(),
+		WHC016InvalidTimeRange(),
+		WHC017UnknownColumn(),
+		WHC018EnvReference(),
+		WHC019UngroupedCount(),
 		WHC020InlineCalculationDefinition(),
 		WHC021InlineFilterDefinition(),
 		WHC022RawMapLiteral(),
 		WHC023DeeplyNestedConfiguration(),
+		WHC024PercentileWithoutHeatmap(),
+		WHC025UnknownDerivedColumn(),
+		WHC027InvalidOrder(),
 	}
 }
 
@@ -116,12 +127,8 @@ func WHC004BreakdownWithoutOrder() Rule {
 		Severity: SeverityWarning,
 		Message:  "Query has breakdowns but no order specified",
 		Check: func(query discovery.DiscoveredQuery) []Issue {
-			// For now, we check if there are breakdowns present
-			// In the real implementation, we'd check if Orders field is empty
-			// Since DiscoveredQuery doesn't have Orders field, we assume missing
-			if len(query.Breakdowns) > 0 {
-				// This is a simplified check - in reality you'd need to verify
-				// that the query struct doesn't have orders
+			// Orders include those set by query.TopN
+			if len(query.Breakdowns) > 0 && len(query.Orders) == 0 {
 				return []Issue{
 					{
 						Rule:     "WHC004",
@@ -137,32 +144,85 @@ func WHC004BreakdownWithoutOrder() Rule {
 	}
 }
 
-// WHC005HighCardinalityBreakdown checks if a query has high cardinality breakdowns.
+// WHC005HighCardinalityBreakdown checks if a query breaks down by a column
+// known to have high cardinality, such as trace.trace_id or user.id.
 func WHC005HighCardinalityBreakdown() Rule {
+	return WHC005HighCardinalityBreakdownWithSchemas(nil)
+}
+
+// HighCardinalityThreshold is the declared column cardinality from which
+// WHC005 reports breakdowns on the column: Honeycomb returns at most 1000
+// groups, so such a breakdown leaves out most values.
+const HighCardinalityThreshold = 1000
+
+// highCardinalityColumns are identifier columns that have about as many
+// values as events. WHC005 reports breakdowns on them unless a schema
+// declares a lower cardinality.
+var highCardinalityColumns = map[string]bool{
+	"trace.trace_id": true, "trace.span_id": true, "trace.parent_id": true,
+	"trace_id": true, "span_id": true, "request.id": true, "request_id": true,
+	"user.id": true, "user_id": true, "session.id": true, "session_id": true,
+}
+
+// WHC005HighCardinalityBreakdownWithSchemas checks if a query breaks down by
+// a high cardinality column: one whose dataset schema declares a Cardinality
+// of HighCardinalityThreshold or more, or a well-known identifier column
+// whose cardinality is not declared. The issue suggests COUNT_DISTINCT or a
+// filter on the column instead.
+func WHC005HighCardinalityBreakdownWithSchemas(schemas []discovery.DiscoveredSchema) Rule {
+	resources := &discovery.DiscoveredResources{Schemas: schemas}
+
 	return Rule{
 		Code:     "WHC005",
 		Severity: SeverityWarning,
-		Message:  "Query has high cardinality breakdown (>100 groups)",
+		Message:  "Query breaks down by a high cardinality column",
 		Check: func(query discovery.DiscoveredQuery) []Issue {
-			// High cardinality is indicated by a limit > 100
-			if query.Limit > 100 {
-				return []Issue{
-					{
-						Rule:     "WHC005",
-						Severity: SeverityWarning,
-						Message:  fmt.Sprintf("Query has high cardinality breakdown (limit=%d > 100 groups)", query.Limit),
-						File:     query.File,
-						Line:     query.Line,
-					},
+			schema, _ := resources.Schema(query.Dataset)
+
+			var results []Issue
+			for _, column := range query.Breakdowns {
+				detail := "an identifier with about one value per event"
+				if col, ok := schema.Column(column); ok && col.Cardinality > 0 {
+					if col.Cardinality < HighCardinalityThreshold {
+						continue
+					}
+					detail = fmt.Sprintf("about %d distinct values in the %s schema", col.Cardinality, query.Dataset)
+				} else if !highCardinalityColumns[column] {
+					continue
 				}
+				results = append(results, Issue{
+					Rule:     "WHC005",
+					Severity: SeverityWarning,
+					Message: fmt.Sprintf("Breakdown on high cardinality column '%s' (%s) returns at most 1000 of its groups; use COUNT_DISTINCT(%s) or filter on specific values instead",
+						column, detail, column),
+					File: query.File,
+					Line: query.Line,
+				})
 			}
-			return nil
+			return results
 		},
 	}
 }
 
-// WHC006InvalidCalculationForColumnType checks if calculations are appropriate for column types.
+// numericCalculationOps are calculations that only make sense on numeric columns.
+var numericCalculationOps = map[string]bool{
+	"P50": true, "P75": true, "P90": true, "P95": true,
+	"P99": true, "P999": true, "SUM": true, "AVG": true,
+	"MIN": true, "MAX": true, "HEATMAP": true,
+}
+
+// WHC006InvalidCalculationForColumnType checks if calculations are appropriate
+// for column types, guessing the type from the column name.
 func WHC006InvalidCalculationForColumnType() Rule {
+	return WHC006InvalidCalculationForColumnTypeWithSchemas(nil)
+}
+
+// WHC006InvalidCalculationForColumnTypeWithSchemas checks if calculations are
+// appropriate for column types. Columns declared in a dataset schema are checked
+// against their declared type; other columns fall back to a name heuristic.
+func WHC006InvalidCalculationForColumnTypeWithSchemas(schemas []discovery.DiscoveredSchema) Rule {
+	resources := &discovery.DiscoveredResources{Schemas: schemas}
+
 	return Rule{
 		Code:     "WHC006",
 		Severity: SeverityError,
@@ -170,38 +230,45 @@ func WHC006InvalidCalculationForColumnType() Rule {
 		Check: func(query discovery.DiscoveredQuery) []Issue {
 			var results []Issue
 
-			// Numeric operations that shouldn't be used on string columns
-			numericOps := map[string]bool{
-				"P50": true, "P75": true, "P90": true, "P95": true,
-				"P99": true, "P999": true, "SUM": true, "AVG": true,
-				"MIN": true, "MAX": true, "HEATMAP": true,
-			}
-
 			// Common string field patterns (heuristic-based detection)
 			stringPatterns := []string{
 				"name", "message", "error", "status", "endpoint",
 				"path", "url", "type", "service", "env", "environment",
 			}
 
+			schema, hasSchema := resources.Schema(query.Dataset)
+
 			for _, calc := range query.Calculations {
-				if calc.Column == "" {
+				// Derived columns are expressions, not dataset columns
+				if calc.Column == "" || calc.ColumnRef != "" || !numericCalculationOps[calc.Op] {
 					continue
 				}
 
-				if numericOps[calc.Op] {
-					// Check if column name suggests it's a string field
-					columnLower := strings.ToLower(calc.Column)
-					for _, pattern := range stringPatterns {
-						if strings.Contains(columnLower, pattern) && !strings.Contains(columnLower, "_ms") && !strings.Contains(columnLower, "_bytes") && !strings.Contains(columnLower, "_count") {
-							results = append(results, Issue{
-								Rule:     "WHC006",
-								Severity: SeverityError,
-								Message:  fmt.Sprintf("Calculation %s should not be used on likely string column '%s'", calc.Op, calc.Column),
-								File:     query.File,
-								Line:     query.Line,
-							})
-							break
-						}
+				if col, ok := schema.Column(calc.Column); hasSchema && ok {
+					if col.Type != "" && col.Type != "integer" && col.Type != "float" {
+						results = append(results, Issue{
+							Rule:     "WHC006",
+							Severity: SeverityError,
+							Message:  fmt.Sprintf("Calculation %s requires a numeric column, but '%s' is declared as %s", calc.Op, calc.Column, col.Type),
+							File:     query.File,
+							Line:     query.Line,
+						})
+					}
+					continue
+				}
+
+				// Check if column name suggests it's a string field
+				columnLower := strings.ToLower(calc.Column)
+				for _, pattern := range stringPatterns {
+					if strings.Contains(columnLower, pattern) && !strings.Contains(columnLower, "_ms") && !strings.Contains(columnLower, "_bytes") && !strings.Contains(columnLower, "_count") {
+						results = append(results, Issue{
+							Rule:     "WHC006",
+							Severity: SeverityError,
+							Message:  fmt.Sprintf("Calculation %s should not be used on likely string column '%s'", calc.Op, calc.Column),
+							File:     query.File,
+							Line:     query.Line,
+						})
+						break
 					}
 				}
 			}
@@ -283,7 +350,7 @@ func WHC009TimeRangeExceeds7Days() Rule {
 		Severity: SeverityError,
 		Message:  "Time range exceeds 7 days",
 		Check: func(query discovery.DiscoveredQuery) []Issue {
-			const sevenDays = 7 * 86400 // 7 days in seconds
+			const sevenDays = hcquery.MaxTimeRange
 
 			if query.TimeRange.TimeRange > sevenDays {
 				days := query.TimeRange.TimeRange / 86400
@@ -575,6 +642,250 @@ func WHC014HardcodedCredentials() Rule {
 	}
 }
 
+// WHC015InvalidFilterValue checks that each filter value has a type its
+// operator accepts: exists/does-not-exist take no value, in/not-in take a
+// list, string operators take a string. Values that are not literals are only
+// checked for presence.
+func WHC015InvalidFilterValue() Rule {
+	return Rule{
+		Code:     "WHC015",
+		Severity: SeverityError,
+		Message:  "Invalid filter value for operator",
+		Check: func(query discovery.DiscoveredQuery) []Issue {
+			var results []Issue
+
+			for _, filter := range query.Filters {
+				var err error
+				switch {
+				case !isValidFilterOp(filter.Op):
+					// Reported by WHC007
+					continue
+				case filter.HasValue && filter.Value == nil:
+					// Not a literal; only the presence of a value is known
+					if filter.Op == "exists" || filter.Op == "does-not-exist" {
+						err = fmt.Errorf("filter on %q: operator %q takes no value", filter.Column, filter.Op)
+					}
+				default:
+					err = hcquery.Filter{Column: filter.Column, Op: filter.Op, Value: filter.Value}.Validate()
+				}
+
+				if err != nil {
+					results = append(results, Issue{
+						Rule:     "WHC015",
+						Severity: SeverityError,
+						Message:  fmt.Sprintf("Invalid filter value: %v", strings.TrimPrefix(err.Error(), "filter ")),
+						File:     query.File,
+						Line:     query.Line,
+					})
+				}
+			}
+
+			return results
+		},
+	}
+}
+
+// WHC017UnknownColumn checks query columns against declared dataset schemas.
+// Without schemas it reports nothing; see WHC017UnknownColumnWithSchemas.
+func WHC017UnknownColumn() Rule {
+	return WHC017UnknownColumnWithSchemas(nil)
+}
+
+// WHC017UnknownColumnWithSchemas checks that every column a query calculates
+// on, filters on, breaks down or orders by is declared in the schema of its
+// dataset. Queries on datasets without a schema are not checked.
+func WHC017UnknownColumnWithSchemas(schemas []discovery.DiscoveredSchema) Rule {
+	resources := &discovery.DiscoveredResources{Schemas: schemas}
+
+	return Rule{
+		Code:     "WHC017",
+		Severity: SeverityError,
+		Message:  "Column not declared in dataset schema",
+		Check: func(query discovery.DiscoveredQuery) []Issue {
+			schema, ok := resources.Schema(query.Dataset)
+			if !ok {
+				return nil
+			}
+
+			var columns []string
+			for _, calc := range query.Calculations {
+				// Derived columns are not declared in dataset schemas
+				if calc.ColumnRef == "" {
+					columns = append(columns, calc.Column)
+				}
+			}
+			for _, filter := range query.Filters {
+				columns = append(columns, filter.Column)
+			}
+			columns = append(columns, query.Breakdowns...)
+			for _, order := range query.Orders {
+				columns = append(columns, order.Column)
+			}
+
+			var results []Issue
+			seen := make(map[string]bool)
+			for _, column := range columns {
+				if column == "" || seen[column] {
+					continue
+				}
+				seen[column] = true
+
+				if _, ok := schema.Column(column); !ok {
+					results = append(results, Issue{
+						Rule:     "WHC017",
+						Severity: SeverityError,
+						Message:  fmt.Sprintf("Column '%s' is not declared in the %s schema (%s)", column, query.Dataset, schema.Name),
+						File:     query.File,
+						Line:     query.Line,
+					})
+				}
+			}
+
+			return results
+		},
+	}
+}
+
+// WHC018EnvReference checks ${VAR} references in a query's dataset. Malformed
+// references are errors; valid ones are reported as info, since they only
+// build with --allow-env and the variable set.
+func WHC018EnvReference() Rule {
+	return Rule{
+		Code:     "WHC018",
+		Severity: SeverityInfo,
+		Message:  "Dataset references an environment variable",
+		Check: func(query discovery.DiscoveredQuery) []Issue {
+			return envReferenceIssues("WHC018", "Dataset", query.Dataset, query.File, query.Line)
+		},
+	}
+}
+
+// DefaultUngroupedCountDays is the shortest time range, in days, WHC019
+// reports by default.
+const DefaultUngroupedCountDays = 2
+
+// WHC019UngroupedCount flags COUNT-only queries without breakdowns over
+// multi-day time ranges. See WHC019UngroupedCountWithMinDays.
+func WHC019UngroupedCount() Rule {
+	return WHC019UngroupedCountWithMinDays(DefaultUngroupedCountDays)
+}
+
+// WHC019UngroupedCountWithMinDays flags queries whose only calculations are
+// COUNT, with no breakdowns or granularity, over a time range of at least
+// minDays. Such a query produces a single number over several days, which is
+// rarely what was meant.
+func WHC019UngroupedCountWithMinDays(minDays int) Rule {
+	return Rule{
+		Code:     "WHC019",
+		Severity: SeverityWarning,
+		Message:  "Ungrouped COUNT over a multi-day time range",
+		Check: func(query discovery.DiscoveredQuery) []Issue {
+			if len(query.Calculations) == 0 || len(query.Breakdowns) > 0 || query.Granularity > 0 {
+				return nil
+			}
+			for _, calc := range query.Calculations {
+				if calc.Op != "COUNT" {
+					return nil
+				}
+			}
+
+			seconds := query.TimeRange.TimeRange
+			if query.TimeRange.StartTime > 0 && query.TimeRange.EndTime > 0 {
+				seconds = query.TimeRange.EndTime - query.TimeRange.StartTime
+			}
+			if seconds < minDays*86400 {
+				return nil
+			}
+
+			return []Issue{
+				{
+					Rule:     "WHC019",
+					Severity: SeverityWarning,
+					Message:  fmt.Sprintf("Query counts events over %d days without breakdowns; add Granularity or Breakdowns to see how the count changes", seconds/86400),
+					File:     query.File,
+					Line:     query.Line,
+				},
+			}
+		},
+	}
+}
+
+// envReferenceIssues reports the ${VAR} references in value, a field of the
+// resource at file:line, for the env interpolation rules.
+func envReferenceIssues(code, field, value, file string, line int) []Issue {
+	if !envsubst.Contains(value) {
+		return nil
+	}
+
+	names, err := envsubst.References(value)
+	if err != nil {
+		return []Issue{
+			{
+				Rule:     code,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("%s has a malformed environment reference: %v", field, err),
+				File:     file,
+				Line:     line,
+			},
+		}
+	}
+	return []Issue{
+		{
+			Rule:     code,
+			Severity: SeverityInfo,
+			Message:  fmt.Sprintf("%s %q is expanded at build time; build with --allow-env and set %s", field, value, strings.Join(names, ", ")),
+			File:     file,
+			Line:     line,
+		},
+	}
+}
+
+// isValidFilterOp reports whether op is a Honeycomb filter operator.
+func isValidFilterOp(op string) bool {
+	for _, valid := range hcquery.FilterOps {
+		if op == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// WHC016InvalidTimeRange checks that a query's time range is well-formed and
+// within Honeycomb's default retention. See WHC016InvalidTimeRangeWithRetention.
+func WHC016InvalidTimeRange() Rule {
+	return WHC016InvalidTimeRangeWithRetention(hcquery.DefaultRetention)
+}
+
+// WHC016InvalidTimeRangeWithRetention checks that a query's time range is
+// well-formed (start before end, query.AbsoluteIn arguments valid) and that
+// absolute ranges start within the retention window ending now.
+func WHC016InvalidTimeRangeWithRetention(retention time.Duration) Rule {
+	return Rule{
+		Code:     "WHC016",
+		Severity: SeverityError,
+		Message:  "Invalid time range",
+		Check: func(query discovery.DiscoveredQuery) []Issue {
+			tr := query.TimeRange
+			err := hcquery.TimeRange{TimeRange: tr.TimeRange, StartTime: tr.StartTime, EndTime: tr.EndTime}.ValidateAt(time.Now(), retention)
+			if tr.Err != "" {
+				err = fmt.Errorf("%s", tr.Err)
+			}
+			if err == nil {
+				return nil
+			}
+			return []Issue{
+				{
+					Rule:     "WHC016",
+					Severity: SeverityError,
+					Message:  fmt.Sprintf("Invalid time range: %v", err),
+					File:     query.File,
+					Line:     query.Line,
+				},
+			}
+		},
+	}
+}
+
 // WHC020InlineCalculationDefinition detects inline calculation definitions that should be
 // extracted to named variables for better readability and reusability.
 func WHC020InlineCalculationDefinition() Rule {
@@ -679,3 +990,125 @@ func WHC023DeeplyNestedConfiguration() Rule {
 		},
 	}
 }
+
+// durationPatterns are column name fragments that suggest a duration.
+var durationPatterns = []string{"duration", "latency", "elapsed", "response_time", "_ms", "_us", "_ns"}
+
+// isDurationColumn reports whether a column name looks like a duration.
+func isDurationColumn(column string) bool {
+	lower := strings.ToLower(column)
+	for _, pattern := range durationPatterns {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// WHC024PercentileWithoutHeatmap suggests adding a HEATMAP when a query
+// computes P95 or P99 of a duration-like column without one. Percentiles hide
+// the shape of the distribution, such as a bimodal latency, that a heatmap
+// shows. Disable the rule to opt out of the suggestion.
+func WHC024PercentileWithoutHeatmap() Rule {
+	return Rule{
+		Code:     "WHC024",
+		Severity: SeverityInfo,
+		Message:  "Latency percentile without a heatmap",
+		Check: func(query discovery.DiscoveredQuery) []Issue {
+			heatmaps := make(map[string]bool)
+			for _, calc := range query.Calculations {
+				if calc.Op == "HEATMAP" {
+					heatmaps[calc.Column] = true
+				}
+			}
+
+			var results []Issue
+			for _, calc := range query.Calculations {
+				if calc.Op != "P95" && calc.Op != "P99" {
+					continue
+				}
+				if heatmaps[calc.Column] || !isDurationColumn(calc.Column) {
+					continue
+				}
+				heatmaps[calc.Column] = true // suggest once per column
+
+				results = append(results, Issue{
+					Rule:     "WHC024",
+					Severity: SeverityInfo,
+					Message:  fmt.Sprintf("Query computes %s(%s) without HEATMAP(%s) - add a heatmap to see the full latency distribution", calc.Op, calc.Column, calc.Column),
+					File:     query.File,
+					Line:     query.Line,
+				})
+			}
+			return results
+		},
+	}
+}
+
+// WHC025UnknownDerivedColumn checks that calculations on a derived column's
+// Alias, such as query.Avg(ErrorRateColumn.Alias), refer to a
+// query.DerivedColumn declared in the query's package. Discovery resolves
+// such references, so an unresolved one has no column.
+func WHC025UnknownDerivedColumn() Rule {
+	return Rule{
+		Code:     "WHC025",
+		Severity: SeverityError,
+		Message:  "Calculation references an undeclared derived column",
+		Check: func(query discovery.DiscoveredQuery) []Issue {
+			var results []Issue
+			for _, calc := range query.Calculations {
+				if calc.ColumnRef == "" || calc.Column != "" {
+					continue
+				}
+				results = append(results, Issue{
+					Rule:     "WHC025",
+					Severity: SeverityError,
+					Message:  fmt.Sprintf("Calculation %s references %s.Alias, but no query.DerivedColumn %s is declared in package %s", calc.Op, calc.ColumnRef, calc.ColumnRef, query.Package),
+					File:     query.File,
+					Line:     query.Line,
+				})
+			}
+			return results
+		},
+	}
+}
+
+// WHC027InvalidOrder checks the orders and limit of a query, including those
+// set by query.TopN, with query.Query.ValidateOrders: orders on a calculation
+// the query does not compute or a column it does not break down by, limits
+// Honeycomb rejects, and orders or limits that contradict a TopN.
+func WHC027InvalidOrder() Rule {
+	return Rule{
+		Code:     "WHC027",
+		Severity: SeverityError,
+		Message:  "Order or limit is invalid",
+		Check: func(query discovery.DiscoveredQuery) []Issue {
+			q := hcquery.Query{Breakdowns: query.Breakdowns, Limit: query.Limit}
+			for _, calc := range query.Calculations {
+				q.Calculations = append(q.Calculations, hcquery.Calculation{Op: calc.Op, Column: calc.Column})
+			}
+			for _, order := range query.Orders {
+				q.Orders = append(q.Orders, hcquery.Order{Column: order.Column, Op: order.Op, Order: order.Order})
+			}
+			if top := query.Top; top != nil {
+				q.Top = hcquery.TopN(top.N, hcquery.Calculation{Op: top.Calculation.Op, Column: top.Calculation.Column})
+			}
+
+			err := q.ValidateOrders()
+			if err == nil {
+				return nil
+			}
+			var results []Issue
+			for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+				results = append(results, Issue{
+					Rule:     "WHC027",
+					Severity: SeverityError,
+					Message:  fmt.Sprintf("Invalid order: %v", e),
+					File:     query.File,
+					Line:     query.Line,
+				})
+			}
+			return results
+		},
+	}
+}
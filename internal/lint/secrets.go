@@ -0,0 +1,90 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/envsubst"
+)
+
+// secretPattern is a kind of credential recognised in free-form text.
+type secretPattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// secretPatterns match credential values rather than words, so that a
+// description mentioning "token refresh" is not reported.
+var secretPatterns = []secretPattern{
+	{"credentials in URL", regexp.MustCompile(`://[^/\s:@]+:[^/\s@]+@`)},
+	{"token query parameter", regexp.MustCompile(`(?i)[?&](access_token|api_key|apikey|auth|key|password|secret|sig|signature|token)=[^&\s]+`)},
+	{"Slack webhook URL", regexp.MustCompile(`hooks\.slack\.com/services/[A-Za-z0-9]+/`)},
+	{"Slack token", regexp.MustCompile(`\bxox[aboprs]-[A-Za-z0-9-]{10,}`)},
+	{"GitHub token", regexp.MustCompile(`\bgh[oprsu]_[A-Za-z0-9]{36}\b`)},
+	{"AWS access key", regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`)},
+	{"API key", regexp.MustCompile(`\bsk[-_](live_|test_)?[A-Za-z0-9]{16,}`)},
+	{"bearer token", regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]{16,}`)},
+	{"credential assignment", regexp.MustCompile(`(?i)\b(api[_-]?key|password|passwd|secret|token)\s*[:=]\s*[^\s${}]{8,}`)},
+}
+
+// findSecret returns the name of the first secret pattern value matches, or
+// "" if none does. ${VAR} references are ignored, since moving a credential
+// into the environment is the fix for these rules.
+func findSecret(value string) string {
+	if envsubst.Contains(value) {
+		if stripped, _, err := envsubst.Expand(value, func(string) (string, bool) { return "", true }); err == nil {
+			value = stripped
+		}
+	}
+	for _, p := range secretPatterns {
+		if p.re.MatchString(value) {
+			return p.name
+		}
+	}
+	return ""
+}
+
+// secretIssues reports a potential secret in value, a field of the resource
+// at file:line, for the secret scanning rules.
+func secretIssues(code, field, value, file string, line int) []Issue {
+	name := findSecret(value)
+	if name == "" {
+		return nil
+	}
+	return []Issue{
+		{
+			Rule:     code,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("%s contains a potential secret (%s); reference it with ${VAR} and build with --allow-env instead", field, name),
+			File:     file,
+			Line:     line,
+		},
+	}
+}
+
+// securityRules are the codes of the rules that look for secrets and
+// sensitive data, across all resource types.
+var securityRules = map[string]bool{
+	"WHC012": true,
+	"WHC013": true,
+	"WHC014": true,
+	"WHC035": true,
+	"WHC049": true,
+	"WHC058": true,
+}
+
+// IsSecurityRule reports whether code is a secret or sensitive data rule.
+func IsSecurityRule(code string) bool {
+	return securityRules[code]
+}
+
+// FilterSecurity returns the issues reported by security rules.
+func FilterSecurity(results []Issue) []Issue {
+	var filtered []Issue
+	for _, r := range results {
+		if IsSecurityRule(r.Rule) {
+			filtered = append(filtered, r)
+		}
+	}
+	return filtered
+}
@@ -2,8 +2,12 @@ package lint
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/slo"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
 )
 
 // SLORule represents a lint rule for SLOs.
@@ -19,7 +23,11 @@ func AllSLORules() []SLORule {
 	return []SLORule{
 		WHC040SLOMissingName(),
 		WHC044TargetOutOfRange(),
+		WHC045RepeatedBurnAlertRecipients(),
+		WHC046SLOInvalidRecipient(),
 		WHC047SLONoBurnAlerts(),
+		WHC048SLOEnvReference(),
+		WHC049SLOSecret(),
 	}
 }
 
@@ -46,29 +54,90 @@ func WHC040SLOMissingName() SLORule {
 	}
 }
 
-// WHC044TargetOutOfRange checks if the SLO target percentage is out of valid range (0-100).
+// WHC044TargetOutOfRange checks if the SLO target percentage is out of valid
+// range (0-100), or finer than the one part per million Honeycomb stores.
 func WHC044TargetOutOfRange() SLORule {
 	return SLORule{
 		Code:     "WHC044",
 		Severity: SeverityError,
 		Message:  "Target percentage out of range (0-100)",
-		Check: func(slo discovery.DiscoveredSLO) []Issue {
-			if slo.TargetPercentage < 0 || slo.TargetPercentage > 100 {
+		Check: func(ds discovery.DiscoveredSLO) []Issue {
+			if err := slo.Percentage(ds.TargetPercentage).Validate(); err != nil {
 				return []Issue{
 					{
 						Rule:     "WHC044",
 						Severity: SeverityError,
-						Message:  fmt.Sprintf("Target percentage out of range: %.2f (must be 0-100)", slo.TargetPercentage),
+						Message:  "Invalid target: " + err.Error(),
+						File:     ds.File,
+						Line:     ds.Line,
+					},
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// WHC045RepeatedBurnAlertRecipients provides info when burn alerts repeat
+// recipients that could be declared once in the SLO's DefaultRecipients.
+func WHC045RepeatedBurnAlertRecipients() SLORule {
+	return SLORule{
+		Code:     "WHC045",
+		Severity: SeverityInfo,
+		Message:  "Burn alerts repeat the same recipients",
+		Check: func(slo discovery.DiscoveredSLO) []Issue {
+			issue := func(message string) []Issue {
+				return []Issue{
+					{
+						Rule:     "WHC045",
+						Severity: SeverityInfo,
+						Message:  message,
 						File:     slo.File,
 						Line:     slo.Line,
 					},
 				}
 			}
+
+			if len(slo.DefaultRecipients) > 0 {
+				defaults := recipientsKey(slo.DefaultRecipients)
+				repeated := 0
+				for _, alert := range slo.BurnAlerts {
+					if len(alert.Recipients) > 0 && recipientsKey(alert.Recipients) == defaults {
+						repeated++
+					}
+				}
+				if repeated > 0 {
+					return issue(fmt.Sprintf("%d burn alert(s) repeat the SLO's DefaultRecipients - remove their Recipients to inherit them", repeated))
+				}
+				return nil
+			}
+
+			counts := make(map[string]int)
+			for _, alert := range slo.BurnAlerts {
+				if len(alert.Recipients) > 0 {
+					counts[recipientsKey(alert.Recipients)]++
+				}
+			}
+			for _, n := range counts {
+				if n > 1 {
+					return issue(fmt.Sprintf("%d burn alerts declare the same recipients - set them once in DefaultRecipients", n))
+				}
+			}
 			return nil
 		},
 	}
 }
 
+// recipientsKey identifies a list of recipients regardless of order.
+func recipientsKey(recipients []discovery.DiscoveredRecipient) string {
+	keys := make([]string, len(recipients))
+	for i, r := range recipients {
+		keys[i] = r.Type + ":" + r.Target
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "\n")
+}
+
 // WHC047SLONoBurnAlerts provides info when an SLO has no burn alerts configured.
 func WHC047SLONoBurnAlerts() SLORule {
 	return SLORule{
@@ -91,3 +160,73 @@ func WHC047SLONoBurnAlerts() SLORule {
 		},
 	}
 }
+
+// WHC048SLOEnvReference checks ${VAR} references in an SLO's dataset and
+// its default and burn alert recipient targets.
+func WHC048SLOEnvReference() SLORule {
+	return SLORule{
+		Code:     "WHC048",
+		Severity: SeverityInfo,
+		Message:  "SLO references an environment variable",
+		Check: func(slo discovery.DiscoveredSLO) []Issue {
+			results := envReferenceIssues("WHC048", "Dataset", slo.Dataset, slo.File, slo.Line)
+			for _, r := range slo.DefaultRecipients {
+				results = append(results, envReferenceIssues("WHC048", "Recipient target", r.Target, slo.File, slo.Line)...)
+			}
+			for _, alert := range slo.BurnAlerts {
+				for _, r := range alert.Recipients {
+					results = append(results, envReferenceIssues("WHC048", "Burn alert recipient target", r.Target, slo.File, slo.Line)...)
+				}
+			}
+			return results
+		},
+	}
+}
+
+// WHC049SLOSecret checks an SLO's description for potential secrets.
+func WHC049SLOSecret() SLORule {
+	return SLORule{
+		Code:     "WHC049",
+		Severity: SeverityError,
+		Message:  "Potential secret in SLO",
+		Check: func(slo discovery.DiscoveredSLO) []Issue {
+			return secretIssues("WHC049", "Description", slo.Description, slo.File, slo.Line)
+		},
+	}
+}
+
+// WHC046SLOInvalidRecipient checks the format of the SLO's default and burn
+// alert recipient targets, which follow the trigger recipient formats.
+func WHC046SLOInvalidRecipient() SLORule {
+	return SLORule{
+		Code:     "WHC046",
+		Severity: SeverityError,
+		Message:  "SLO recipient target is invalid",
+		Check: func(slo discovery.DiscoveredSLO) []Issue {
+			recipients := slo.DefaultRecipients
+			for _, alert := range slo.BurnAlerts {
+				recipients = append(recipients, alert.Recipients...)
+			}
+
+			var results []Issue
+			seen := make(map[discovery.DiscoveredRecipient]bool)
+			for _, r := range recipients {
+				if seen[r] {
+					continue
+				}
+				seen[r] = true
+				recipient := trigger.Recipient{Type: trigger.RecipientType(r.Type), Target: r.Target}
+				if err := recipient.Validate(); err != nil {
+					results = append(results, Issue{
+						Rule:     "WHC046",
+						Severity: SeverityError,
+						Message:  fmt.Sprintf("Invalid recipient: %v", err),
+						File:     slo.File,
+						Line:     slo.Line,
+					})
+				}
+			}
+			return results
+		},
+	}
+}
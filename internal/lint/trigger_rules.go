@@ -1,9 +1,11 @@
 package lint
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
 )
 
 // TriggerRule represents a lint rule for triggers.
@@ -18,9 +20,13 @@ type TriggerRule struct {
 func AllTriggerRules() []TriggerRule {
 	return []TriggerRule{
 		WHC050TriggerMissingName(),
+		WHC051TriggerInvalidAlertType(),
 		WHC053TriggerNoRecipients(),
 		WHC054TriggerFrequencyUnder1Minute(),
 		WHC056TriggerIsDisabled(),
+		WHC057TriggerEnvReference(),
+		WHC058TriggerSecret(),
+		WHC059TriggerInvalidRecipient(),
 	}
 }
 
@@ -47,6 +53,30 @@ func WHC050TriggerMissingName() TriggerRule {
 	}
 }
 
+// WHC051TriggerInvalidAlertType checks that the alert type is one the
+// Triggers API accepts (see trigger.AlertType.Validate).
+func WHC051TriggerInvalidAlertType() TriggerRule {
+	return TriggerRule{
+		Code:     "WHC051",
+		Severity: SeverityError,
+		Message:  "Trigger alert type is invalid",
+		Check: func(dt discovery.DiscoveredTrigger) []Issue {
+			if err := trigger.AlertType(dt.AlertType).Validate(); err != nil {
+				return []Issue{
+					{
+						Rule:     "WHC051",
+						Severity: SeverityError,
+						Message:  fmt.Sprintf("Invalid alert type: %v", err),
+						File:     dt.File,
+						Line:     dt.Line,
+					},
+				}
+			}
+			return nil
+		},
+	}
+}
+
 // WHC053TriggerNoRecipients checks if a trigger has no recipients configured.
 func WHC053TriggerNoRecipients() TriggerRule {
 	return TriggerRule{
@@ -115,3 +145,105 @@ func WHC056TriggerIsDisabled() TriggerRule {
 		},
 	}
 }
+
+// WHC057TriggerEnvReference checks ${VAR} references in a trigger's dataset
+// and recipient targets.
+func WHC057TriggerEnvReference() TriggerRule {
+	return TriggerRule{
+		Code:     "WHC057",
+		Severity: SeverityInfo,
+		Message:  "Trigger references an environment variable",
+		Check: func(trigger discovery.DiscoveredTrigger) []Issue {
+			results := envReferenceIssues("WHC057", "Dataset", trigger.Dataset, trigger.File, trigger.Line)
+			for _, r := range trigger.Recipients {
+				results = append(results, envReferenceIssues("WHC057", "Recipient target", r.Target, trigger.File, trigger.Line)...)
+			}
+			return results
+		},
+	}
+}
+
+// WHC058TriggerSecret checks a trigger's description and recipient targets,
+// such as webhook URLs carrying a token, for potential secrets.
+func WHC058TriggerSecret() TriggerRule {
+	return TriggerRule{
+		Code:     "WHC058",
+		Severity: SeverityError,
+		Message:  "Potential secret in trigger",
+		Check: func(trigger discovery.DiscoveredTrigger) []Issue {
+			results := secretIssues("WHC058", "Description", trigger.Description, trigger.File, trigger.Line)
+			for _, r := range trigger.Recipients {
+				results = append(results, secretIssues("WHC058", "Recipient target", r.Target, trigger.File, trigger.Line)...)
+			}
+			return results
+		},
+	}
+}
+
+// WHC059TriggerInvalidRecipient checks the format of each recipient target
+// for its type (see trigger.Recipient.Validate), catching typos Honeycomb
+// would only reject at apply time.
+func WHC059TriggerInvalidRecipient() TriggerRule {
+	return TriggerRule{
+		Code:     "WHC059",
+		Severity: SeverityError,
+		Message:  "Trigger recipient target is invalid",
+		Check: func(dt discovery.DiscoveredTrigger) []Issue {
+			var results []Issue
+			for _, r := range dt.Recipients {
+				recipient := trigger.Recipient{Type: trigger.RecipientType(r.Type), Target: r.Target}
+				if err := recipient.Validate(); err != nil {
+					results = append(results, Issue{
+						Rule:     "WHC059",
+						Severity: SeverityError,
+						Message:  fmt.Sprintf("Invalid recipient: %v", err),
+						File:     dt.File,
+						Line:     dt.Line,
+					})
+				}
+			}
+			return results
+		},
+	}
+}
+
+// WHC055 checks a trigger's frequency against the duration of the query it
+// evaluates. It needs the referenced queries, so it runs from
+// LintAllWithConfig rather than AllTriggerRules.
+const WHC055 = "WHC055"
+
+// LintTriggerDurations checks the query duration of each trigger, taken from
+// its inline query or the query it references, against its frequency (see
+// trigger.CheckQueryDuration). A duration over four times the frequency,
+// which Honeycomb rejects, is an error; one shorter than the frequency
+// leaves events unchecked and is a warning.
+func LintTriggerDurations(triggers []discovery.DiscoveredTrigger, queries []discovery.DiscoveredQuery) []Issue {
+	var results []Issue
+	for _, dt := range triggers {
+		q := dt.Query(queries)
+		if q == nil {
+			continue
+		}
+		err := trigger.CheckQueryDuration(q.TimeRange.TimeRange, dt.FrequencySeconds)
+		if err == nil {
+			continue
+		}
+
+		severity := SeverityError
+		if errors.Is(err, trigger.ErrDurationGap) {
+			severity = SeverityWarning
+		}
+		source := "inline query"
+		if dt.InlineQuery == nil {
+			source = "query " + dt.QueryRef
+		}
+		results = append(results, Issue{
+			Rule:     WHC055,
+			Severity: severity,
+			Message:  fmt.Sprintf("Trigger %s (%s): %v", dt.Name, source, err),
+			File:     dt.File,
+			Line:     dt.Line,
+		})
+	}
+	return results
+}
@@ -50,6 +50,38 @@ func TestWHC050TriggerMissingName(t *testing.T) {
 	}
 }
 
+func TestWHC051TriggerInvalidAlertType(t *testing.T) {
+	rule := WHC051TriggerInvalidAlertType()
+
+	tests := []struct {
+		name      string
+		alertType string
+		wantCount int
+	}{
+		{name: "unset", alertType: "", wantCount: 0},
+		{name: "on_change", alertType: "on_change", wantCount: 0},
+		{name: "on_true", alertType: "on_true", wantCount: 0},
+		{name: "unknown", alertType: "on_false", wantCount: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := rule.Check(discovery.DiscoveredTrigger{
+				Name:      "MyTrigger",
+				AlertType: tt.alertType,
+				File:      "test.go",
+				Line:      10,
+			})
+			assert.Len(t, results, tt.wantCount)
+			if tt.wantCount > 0 {
+				assert.Equal(t, "WHC051", results[0].Rule)
+				assert.Equal(t, SeverityError, results[0].Severity)
+				assert.Contains(t, results[0].Message, `"on_false"`)
+			}
+		})
+	}
+}
+
 func TestWHC053TriggerNoRecipients(t *testing.T) {
 	rule := WHC053TriggerNoRecipients()
 
@@ -196,6 +228,197 @@ func TestWHC056TriggerIsDisabled(t *testing.T) {
 	}
 }
 
+func TestWHC057TriggerEnvReference(t *testing.T) {
+	rule := WHC057TriggerEnvReference()
+
+	tests := []struct {
+		name      string
+		trigger   discovery.DiscoveredTrigger
+		wantCount int
+	}{
+		{
+			name: "no references",
+			trigger: discovery.DiscoveredTrigger{
+				Dataset:    "production",
+				Recipients: []discovery.DiscoveredRecipient{{Type: "slack", Target: "#alerts"}},
+			},
+			wantCount: 0,
+		},
+		{
+			name: "dataset and recipient references",
+			trigger: discovery.DiscoveredTrigger{
+				Dataset:    "${DATASET}",
+				Recipients: []discovery.DiscoveredRecipient{{Type: "slack", Target: "#${TEAM}-alerts"}},
+			},
+			wantCount: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := rule.Check(tt.trigger)
+			assert.Len(t, results, tt.wantCount)
+			for _, r := range results {
+				assert.Equal(t, "WHC057", r.Rule)
+				assert.Equal(t, SeverityInfo, r.Severity)
+			}
+		})
+	}
+}
+
+func TestWHC058TriggerSecret(t *testing.T) {
+	rule := WHC058TriggerSecret()
+
+	tests := []struct {
+		name      string
+		trigger   discovery.DiscoveredTrigger
+		wantCount int
+	}{
+		{
+			name: "plain targets",
+			trigger: discovery.DiscoveredTrigger{
+				Description: "Fires when token refresh fails",
+				Recipients:  []discovery.DiscoveredRecipient{{Type: "webhook", Target: "https://hooks.example.com/alerts"}},
+			},
+			wantCount: 0,
+		},
+		{
+			name: "webhook URL with token",
+			trigger: discovery.DiscoveredTrigger{
+				Recipients: []discovery.DiscoveredRecipient{
+					{Type: "webhook", Target: "https://hooks.example.com/alerts?token=abc123"},
+					{Type: "webhook", Target: "https://hooks.slack.com/services/T000/B000/XXXXXXXX"},
+				},
+			},
+			wantCount: 2,
+		},
+		{
+			name: "token moved to environment",
+			trigger: discovery.DiscoveredTrigger{
+				Recipients: []discovery.DiscoveredRecipient{{Type: "webhook", Target: "https://hooks.example.com/alerts?token=${ALERT_TOKEN}"}},
+			},
+			wantCount: 0,
+		},
+		{
+			name: "secret in description",
+			trigger: discovery.DiscoveredTrigger{
+				Description: "Escalate with api_key=0123456789abcdef",
+			},
+			wantCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := rule.Check(tt.trigger)
+			assert.Len(t, results, tt.wantCount)
+			for _, r := range results {
+				assert.Equal(t, "WHC058", r.Rule)
+				assert.Equal(t, SeverityError, r.Severity)
+			}
+		})
+	}
+}
+
+func TestWHC059TriggerInvalidRecipient(t *testing.T) {
+	rule := WHC059TriggerInvalidRecipient()
+
+	tests := []struct {
+		name      string
+		trigger   discovery.DiscoveredTrigger
+		wantCount int
+	}{
+		{
+			name: "valid targets",
+			trigger: discovery.DiscoveredTrigger{
+				Recipients: []discovery.DiscoveredRecipient{
+					{Type: "slack", Target: "#alerts"},
+					{Type: "email", Target: "oncall@example.com"},
+					{Type: "pagerduty", Target: "0123456789abcdef0123456789abcdef"},
+					{Type: "webhook", Target: "https://hooks.example.com/alerts"},
+				},
+			},
+			wantCount: 0,
+		},
+		{
+			name: "typos",
+			trigger: discovery.DiscoveredTrigger{
+				Recipients: []discovery.DiscoveredRecipient{
+					{Type: "slack", Target: "alerts"},
+					{Type: "email", Target: "oncall@"},
+					{Type: "pagerduty", Target: "api-team"},
+					{Type: "webhook", Target: "http://hooks.example.com/alerts"},
+				},
+			},
+			wantCount: 4,
+		},
+		{
+			name: "target from environment",
+			trigger: discovery.DiscoveredTrigger{
+				Recipients: []discovery.DiscoveredRecipient{{Type: "pagerduty", Target: "${PAGERDUTY_KEY}"}},
+			},
+			wantCount: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := rule.Check(tt.trigger)
+			assert.Len(t, results, tt.wantCount)
+			for _, r := range results {
+				assert.Equal(t, "WHC059", r.Rule)
+				assert.Equal(t, SeverityError, r.Severity)
+			}
+		})
+	}
+}
+
+func TestLintTriggerDurations(t *testing.T) {
+	queries := []discovery.DiscoveredQuery{
+		{Name: "Latency", Package: "queries", TimeRange: discovery.TimeRange{TimeRange: 3600}},
+		{Name: "Recent", Package: "queries", TimeRange: discovery.TimeRange{TimeRange: 300}},
+	}
+	triggers := []discovery.DiscoveredTrigger{
+		{Name: "Fits", QueryRef: "Latency", FrequencySeconds: 900},
+		{Name: "TooLong", QueryRef: "Latency", FrequencySeconds: 300, File: "alerts.go", Line: 7},
+		{Name: "Gap", QueryRef: "Recent", FrequencySeconds: 900},
+		{Name: "Inline", InlineQuery: &discovery.DiscoveredQuery{TimeRange: discovery.TimeRange{TimeRange: 900}}, FrequencySeconds: 180},
+		{Name: "Unresolved", QueryRef: "Missing", FrequencySeconds: 60},
+		{Name: "NoFrequency", QueryRef: "Latency"},
+	}
+
+	results := LintTriggerDurations(triggers, queries)
+	assert.Len(t, results, 3)
+	for _, r := range results {
+		assert.Equal(t, "WHC055", r.Rule)
+	}
+
+	assert.Equal(t, SeverityError, results[0].Severity)
+	assert.Equal(t, "alerts.go", results[0].File)
+	assert.Equal(t, 7, results[0].Line)
+	assert.Equal(t, "Trigger TooLong (query Latency): query duration 3600s is more than 4 times the frequency 300s (at most 1200s); lengthen the frequency to at least 900s or shorten the duration", results[0].Message)
+
+	assert.Equal(t, SeverityWarning, results[1].Severity)
+	assert.Contains(t, results[1].Message, "Trigger Gap (query Recent): query duration is shorter than the frequency (300s < 900s)")
+
+	assert.Equal(t, SeverityError, results[2].Severity)
+	assert.Contains(t, results[2].Message, "Trigger Inline (inline query): query duration 900s")
+	assert.Contains(t, results[2].Message, "at least 240s")
+}
+
+func TestLintAllWithConfig_TriggerDurations(t *testing.T) {
+	resources := &discovery.DiscoveredResources{
+		Queries:  []discovery.DiscoveredQuery{{Name: "Latency", TimeRange: discovery.TimeRange{TimeRange: 3600}}},
+		Triggers: []discovery.DiscoveredTrigger{{Name: "TooLong", TriggerName: "Too long", QueryRef: "Latency", FrequencySeconds: 300, RecipientCount: 1}},
+	}
+
+	issues := LintAllWithConfig(resources, LintConfig{DisabledRules: []string{"WHC026"}})
+	assert.Equal(t, 1, countRule(issues, "WHC055"))
+
+	issues = LintAllWithConfig(resources, LintConfig{DisabledRules: []string{"WHC055"}})
+	assert.Zero(t, countRule(issues, "WHC055"))
+}
+
 func TestAllTriggerRules(t *testing.T) {
 	rules := AllTriggerRules()
 	assert.GreaterOrEqual(t, len(rules), 4) // At least WHC050, WHC053, WHC054, WHC056
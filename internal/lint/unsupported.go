@@ -0,0 +1,54 @@
+package lint
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// WHC066 flags resource literals discovery cannot extract, which build
+// leaves out. They are found by a discovery pass of their own, so it runs
+// from LintAllWithConfig.
+const WHC066 = "WHC066"
+
+// LintUnsupported returns a WHC066 error for each resource literal built in
+// an init function or a loop, or by a fleet.Each template that cannot be
+// expanded. Discovery reads declarations without running code, so such a
+// resource would be silently missing from the build output.
+func LintUnsupported(resources *discovery.DiscoveredResources) []Issue {
+	var results []Issue
+	for _, u := range resources.Unsupported {
+		fix := "declare it as a package-level variable, or use fleet.Each for one per service"
+		if u.Kind == "fleet.Each template" || u.Kind == "loop" {
+			fix = "list the services in a []string literal or a package-level variable holding one"
+		}
+		results = append(results, Issue{
+			Rule:     WHC066,
+			Severity: SeverityError,
+			Message:  "The " + u.String() + ", so build leaves it out; " + fix,
+			File:     u.File,
+			Line:     u.Line,
+		})
+	}
+	return results
+}
+
+// WHC069 flags fields of resource literals discovery cannot read. Like
+// WHC066 they are found by a discovery pass of their own.
+const WHC069 = "WHC069"
+
+// LintFieldMistakes returns a WHC069 error for each key a resource type
+// does not have, such as Breakdown for Breakdowns, and each literal of the
+// wrong kind, such as a string TimeRange, with the field or value likely
+// meant. Build fails on them rather than leave the field out.
+func LintFieldMistakes(resources *discovery.DiscoveredResources) []Issue {
+	var results []Issue
+	for _, m := range resources.FieldMistakes {
+		results = append(results, Issue{
+			Rule:     WHC069,
+			Severity: SeverityError,
+			Message:  m.String(),
+			File:     m.File,
+			Line:     m.Line,
+		})
+	}
+	return results
+}
@@ -0,0 +1,154 @@
+package lint
+
+import (
+	"fmt"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/slo"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+// WHC067 and WHC068 check the time ranges of queries against the triggers
+// and SLOs using them. They need the referenced queries, so they run from
+// LintAllWithConfig rather than AllTriggerRules and AllSLORules.
+const (
+	// WHC067 flags trigger queries with an absolute time range, or a
+	// duration over trigger.MaxQueryDuration
+	WHC067 = "WHC067"
+
+	// WHC068 flags SLI queries whose time ranges differ from each other, are
+	// absolute, or cover more than the SLO time period
+	WHC068 = "WHC068"
+)
+
+// LintTriggerWindows returns a WHC067 error for each trigger whose query,
+// inline or referenced, has an absolute time range, which a trigger cannot
+// roll forward, or a duration over trigger.MaxQueryDuration, which Honeycomb
+// rejects whatever the frequency. Durations against the frequency are
+// checked by WHC055.
+func LintTriggerWindows(triggers []discovery.DiscoveredTrigger, queries []discovery.DiscoveredQuery) []Issue {
+	var results []Issue
+	for _, t := range triggers {
+		q := t.Query(queries)
+		if q == nil {
+			continue
+		}
+		source := "its inline query"
+		if t.InlineQuery == nil {
+			source = "query " + resourceLocation(q.Name, q.File, q.Line)
+		}
+
+		var msg string
+		_, longest := trigger.QueryWindow(t.FrequencySeconds)
+		switch {
+		case q.TimeRange.StartTime > 0 || q.TimeRange.EndTime > 0:
+			msg = fmt.Sprintf("Trigger %s evaluates %s over an absolute time range; triggers evaluate the last N minutes, use a relative range of at most %s",
+				t.Name, source, formatSeconds(longest))
+		case q.TimeRange.TimeRange > trigger.MaxQueryDuration:
+			msg = fmt.Sprintf("Trigger %s evaluates %s over %s, more than the %s a trigger can evaluate; shorten it to at most %s",
+				t.Name, source, formatSeconds(q.TimeRange.TimeRange), formatSeconds(trigger.MaxQueryDuration), formatSeconds(longest))
+		default:
+			continue
+		}
+		results = append(results, Issue{
+			Rule:     WHC067,
+			Severity: SeverityError,
+			Message:  msg,
+			File:     t.File,
+			Line:     t.Line,
+		})
+	}
+	return results
+}
+
+// LintSLIWindows returns a WHC068 warning for each SLO whose good and total
+// events queries cover different time ranges, so their ratio compares
+// different windows, and for each SLI query over an absolute time range,
+// which does not roll with the SLO, or over more than the SLO's time period
+// (see slo.TimePeriod.QueryRange). SLOs without a time period are only
+// checked for different ranges, and SLI queries without a time range are
+// not checked.
+func LintSLIWindows(slos []discovery.DiscoveredSLO, queries []discovery.DiscoveredQuery) []Issue {
+	var results []Issue
+	for _, s := range slos {
+		good, total := s.SLIQueries(queries)
+		report := func(msg string) {
+			results = append(results, Issue{
+				Rule:     WHC068,
+				Severity: SeverityWarning,
+				Message:  msg,
+				File:     s.File,
+				Line:     s.Line,
+			})
+		}
+
+		if good != nil && total != nil && good.TimeRange != total.TimeRange && good.TimeRange.Err == "" && total.TimeRange.Err == "" {
+			report(fmt.Sprintf("SLO %s: good events %s covers %s but total events %s covers %s; the SLI compares different windows",
+				s.Name, sliSource(s, good), describeRange(good.TimeRange), sliSource(s, total), describeRange(total.TimeRange)))
+		}
+
+		if s.TimePeriodDays <= 0 {
+			continue
+		}
+		longest := slo.TimePeriod{Days: s.TimePeriodDays}.QueryRange()
+		sli := []struct {
+			kind string
+			q    *discovery.DiscoveredQuery
+		}{{"good", good}, {"total", total}}
+		if good == total {
+			sli = sli[:1]
+			sli[0].kind = "good and total"
+		}
+		for _, e := range sli {
+			kind, q := e.kind, e.q
+			if q == nil {
+				continue
+			}
+			tr := q.TimeRange
+			switch {
+			case tr.StartTime > 0 || tr.EndTime > 0:
+				report(fmt.Sprintf("SLO %s: %s events %s covers an absolute time range, which does not roll with the %dd time period of the SLO; use a relative range of at most %s",
+					s.Name, kind, sliSource(s, q), s.TimePeriodDays, formatSeconds(longest)))
+			case tr.TimeRange > longest:
+				report(fmt.Sprintf("SLO %s: %s events %s covers %s, more than the %dd time period of the SLO; use a relative range of at most %s",
+					s.Name, kind, sliSource(s, q), formatSeconds(tr.TimeRange), s.TimePeriodDays, formatSeconds(longest)))
+			}
+		}
+	}
+	return results
+}
+
+// sliSource names an SLI query of s: "query" and its location, or "inline
+// query".
+func sliSource(s discovery.DiscoveredSLO, q *discovery.DiscoveredQuery) string {
+	if q == s.GoodEventsQuery || q == s.TotalEventsQuery {
+		return "inline query"
+	}
+	return "query " + resourceLocation(q.Name, q.File, q.Line)
+}
+
+// describeRange describes a query time range: its duration, "an absolute
+// time range" or "the default time range".
+func describeRange(tr discovery.TimeRange) string {
+	if tr.StartTime > 0 || tr.EndTime > 0 {
+		return "an absolute time range"
+	}
+	if tr.TimeRange == 0 {
+		return "the default time range"
+	}
+	return formatSeconds(tr.TimeRange)
+}
+
+// formatSeconds formats a duration in seconds in its largest whole unit, e.g.
+// "7d", "6h" or "15m".
+func formatSeconds(seconds int) string {
+	for _, unit := range []struct {
+		size   int
+		suffix string
+	}{{86400, "d"}, {3600, "h"}, {60, "m"}} {
+		if seconds%unit.size == 0 {
+			return fmt.Sprintf("%d%s", seconds/unit.size, unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
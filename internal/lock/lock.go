@@ -0,0 +1,145 @@
+// Package lock records build provenance in a wetwire.lock file and reconciles it
+// against the current code and the live Honeycomb environment to detect drift.
+package lock
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/atomicfile"
+	"github.com/lex00/wetwire-honeycomb-go/internal/contenthash"
+)
+
+// FileName is the name of the lockfile written next to the source packages.
+const FileName = "wetwire.lock"
+
+// Version is the current lockfile format version.
+const Version = 1
+
+// Lockfile records the hash of every resource produced by a build.
+type Lockfile struct {
+	// Version is the lockfile format version
+	Version int `json:"version"`
+
+	// Resources maps "<type>/<Name>" keys (e.g. "triggers/HighLatency") to entries
+	Resources map[string]Entry `json:"resources"`
+}
+
+// Entry is the recorded state of a single built resource.
+type Entry struct {
+	// Name is the resource's Honeycomb name (its "name" field), if any
+	Name string `json:"name,omitempty"`
+
+	// Dataset is the resource's dataset, if any
+	Dataset string `json:"dataset,omitempty"`
+
+	// Hash is the sha256 of the resource's canonical JSON (see contenthash)
+	Hash string `json:"hash"`
+}
+
+// Key returns the lockfile key for a resource type and Go variable name.
+func Key(resourceType, name string) string {
+	return resourceType + "/" + name
+}
+
+// SplitKey returns the resource type and Go variable name of a lockfile key.
+func SplitKey(key string) (string, string) {
+	resourceType, name, _ := strings.Cut(key, "/")
+	return resourceType, name
+}
+
+// Generate computes a lockfile from the resources of a build output.
+func Generate(output *honeycomb.Manifest) (*Lockfile, error) {
+	lf := &Lockfile{Version: Version, Resources: make(map[string]Entry)}
+
+	for resourceType, resources := range output.Resources() {
+		for name, data := range resources {
+			entry, err := newEntry(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", resourceType, name, err)
+			}
+			lf.Resources[Key(resourceType, name)] = entry
+		}
+	}
+
+	return lf, nil
+}
+
+// newEntry builds a lock entry from a resource's JSON.
+func newEntry(data json.RawMessage) (Entry, error) {
+	hash, err := Hash(data)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	var meta struct {
+		Name    string `json:"name"`
+		Dataset string `json:"dataset"`
+	}
+	_ = json.Unmarshal(data, &meta)
+
+	return Entry{Name: meta.Name, Dataset: meta.Dataset, Hash: hash}, nil
+}
+
+// Hash returns the sha256 of the canonical form of a JSON document, so that
+// key order and whitespace do not affect the result. It is contenthash.Hash.
+func Hash(data json.RawMessage) (string, error) {
+	return contenthash.Hash(data)
+}
+
+// Read loads a lockfile from disk.
+func Read(path string) (*Lockfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var lf Lockfile
+	if err := json.Unmarshal(data, &lf); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if lf.Version > Version {
+		return nil, fmt.Errorf("%s: unsupported lockfile version %d", path, lf.Version)
+	}
+	if lf.Resources == nil {
+		lf.Resources = make(map[string]Entry)
+	}
+
+	return &lf, nil
+}
+
+// Write saves a lockfile to disk with stable formatting.
+func Write(path string, lf *Lockfile) error {
+	data, err := json.MarshalIndent(lf, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Keys returns the lockfile's resource keys in sorted order.
+func (lf *Lockfile) Keys() []string {
+	keys := make([]string, 0, len(lf.Resources))
+	for k := range lf.Resources {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Rename moves the entry of a resource to its new Go variable name, so that a
+// renamed resource is not reported as removed and added. It reports whether
+// the lockfile had an entry for the old name.
+func (lf *Lockfile) Rename(resourceType, oldName, newName string) bool {
+	entry, ok := lf.Resources[Key(resourceType, oldName)]
+	if !ok {
+		return false
+	}
+	delete(lf.Resources, Key(resourceType, oldName))
+	lf.Resources[Key(resourceType, newName)] = entry
+	return true
+}
@@ -0,0 +1,85 @@
+package lock
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash_Canonical(t *testing.T) {
+	a, err := Hash(json.RawMessage(`{"name": "x", "frequency": 300}`))
+	require.NoError(t, err)
+	b, err := Hash(json.RawMessage(`{"frequency":300,"name":"x"}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, a, b)
+	assert.Contains(t, a, "sha256:")
+
+	_, err = Hash(json.RawMessage(`{`))
+	assert.Error(t, err)
+}
+
+func TestGenerate(t *testing.T) {
+	output := &honeycomb.Manifest{
+		Queries:  map[string]json.RawMessage{"SlowRequests": json.RawMessage(`{"time_range": 3600}`)},
+		Triggers: map[string]json.RawMessage{"HighLatency": json.RawMessage(`{"name": "High Latency", "dataset": "production"}`)},
+	}
+
+	lf, err := Generate(output)
+	require.NoError(t, err)
+
+	assert.Equal(t, Version, lf.Version)
+	assert.Equal(t, []string{"queries/SlowRequests", "triggers/HighLatency"}, lf.Keys())
+
+	entry := lf.Resources["triggers/HighLatency"]
+	assert.Equal(t, "High Latency", entry.Name)
+	assert.Equal(t, "production", entry.Dataset)
+	assert.NotEmpty(t, entry.Hash)
+}
+
+func TestReadWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	lf := &Lockfile{Version: Version, Resources: map[string]Entry{
+		"queries/A": {Hash: "sha256:abc"},
+	}}
+
+	require.NoError(t, Write(path, lf))
+
+	read, err := Read(path)
+	require.NoError(t, err)
+	assert.Equal(t, lf, read)
+
+	_, err = Read(filepath.Join(t.TempDir(), FileName))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestRead_UnsupportedVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 99, "resources": {}}`), 0644))
+
+	_, err := Read(path)
+	assert.Error(t, err)
+}
+
+func TestSplitKey(t *testing.T) {
+	resourceType, name := SplitKey(Key("slos", "APIAvailability"))
+	assert.Equal(t, "slos", resourceType)
+	assert.Equal(t, "APIAvailability", name)
+}
+
+func TestRename(t *testing.T) {
+	lf := &Lockfile{Version: Version, Resources: map[string]Entry{
+		"queries/Latency": {Name: "latency", Hash: "sha256:a"},
+	}}
+
+	assert.True(t, lf.Rename("queries", "Latency", "APILatency"))
+	assert.Equal(t, []string{"queries/APILatency"}, lf.Keys())
+	assert.Equal(t, "sha256:a", lf.Resources["queries/APILatency"].Hash)
+
+	assert.False(t, lf.Rename("queries", "Missing", "Other"))
+}
@@ -0,0 +1,284 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/annotate"
+	"github.com/lex00/wetwire-honeycomb-go/internal/refs"
+)
+
+// Status describes how a resource compares across code, lockfile and live state.
+type Status string
+
+const (
+	// StatusInSync means code, lockfile and (when checked) live state agree
+	StatusInSync Status = "in sync"
+
+	// StatusAdded means the resource is in code but not in the lockfile
+	StatusAdded Status = "added in code"
+
+	// StatusRemoved means the resource is in the lockfile but no longer in code
+	StatusRemoved Status = "removed from code"
+
+	// StatusModified means the code changed since the lockfile was written
+	StatusModified Status = "modified in code"
+
+	// StatusDrifted means the live resource no longer matches the lockfile
+	StatusDrifted Status = "drifted live"
+
+	// StatusConflict means both the code and the live resource changed
+	StatusConflict Status = "conflict"
+
+	// StatusLockStale means code and live agree but the lockfile is outdated
+	StatusLockStale Status = "lock stale"
+
+	// StatusMissingLive means the resource is locked but absent from Honeycomb
+	StatusMissingLive Status = "missing live"
+)
+
+// LiveTypes are the resource types whose live state can be fetched.
+// Queries are immutable in Honeycomb and cannot be listed.
+var LiveTypes = []string{"boards", "slos", "triggers"}
+
+// Drift is the reconciliation result for a single resource.
+type Drift struct {
+	Key      string `json:"key"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	Status   Status `json:"status"`
+	CodeHash string `json:"code_hash,omitempty"`
+	LockHash string `json:"lock_hash,omitempty"`
+	LiveHash string `json:"live_hash,omitempty"`
+}
+
+// Report is a three-way reconciliation of code, lockfile and live state.
+type Report struct {
+	// LiveChecked reports whether live state was included
+	LiveChecked bool `json:"live_checked"`
+
+	// Resources lists every resource in code or the lockfile, sorted by key
+	Resources []Drift `json:"resources"`
+}
+
+// HasDrift reports whether any resource is not in sync.
+func (r *Report) HasDrift() bool {
+	for _, d := range r.Resources {
+		if d.Status != StatusInSync {
+			return true
+		}
+	}
+	return false
+}
+
+// Counts returns the number of resources in each status.
+func (r *Report) Counts() map[Status]int {
+	counts := make(map[Status]int)
+	for _, d := range r.Resources {
+		counts[d.Status]++
+	}
+	return counts
+}
+
+// Reconcile compares build output with a lockfile and, when live is
+// non-nil, with live resources keyed by lockfile key.
+//
+// Live resources carry server-side fields such as ids and timestamps, so they
+// are compared only on the top-level fields present in the generated JSON.
+func Reconcile(output *honeycomb.Manifest, locked *Lockfile, live map[string]json.RawMessage) (*Report, error) {
+	code, err := Generate(output)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool)
+	for k := range code.Resources {
+		keys[k] = true
+	}
+	for k := range locked.Resources {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	report := &Report{LiveChecked: live != nil}
+	for _, key := range sorted {
+		resourceType, name := SplitKey(key)
+		codeEntry, inCode := code.Resources[key]
+		lockEntry, inLock := locked.Resources[key]
+
+		d := Drift{Key: key, Type: resourceType, Name: name, CodeHash: codeEntry.Hash, LockHash: lockEntry.Hash}
+
+		switch {
+		case !inLock:
+			d.Status = StatusAdded
+		case !inCode:
+			d.Status = StatusRemoved
+		default:
+			codeChanged := codeEntry.Hash != lockEntry.Hash
+
+			liveChanged := false
+			if live != nil && isLiveType(resourceType) {
+				liveData, ok := live[key]
+				if !ok {
+					d.Status = StatusMissingLive
+					break
+				}
+				d.LiveHash, err = projectedHash(liveData, output.Section(resourceType)[name])
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", key, err)
+				}
+				liveChanged = d.LiveHash != lockEntry.Hash
+			}
+
+			switch {
+			case codeChanged && liveChanged && d.LiveHash == codeEntry.Hash:
+				d.Status = StatusLockStale
+			case codeChanged && liveChanged:
+				d.Status = StatusConflict
+			case codeChanged:
+				d.Status = StatusModified
+			case liveChanged:
+				d.Status = StatusDrifted
+			default:
+				d.Status = StatusInSync
+			}
+		}
+
+		report.Resources = append(report.Resources, d)
+	}
+
+	return report, nil
+}
+
+// projectedHash hashes the live resource restricted to the generated fields.
+// The managed annotation added by apply is removed from the live description,
+// and live IDs at the generated placeholders (see internal/refs) are taken to
+// match them.
+func projectedHash(liveData, generated json.RawMessage) (string, error) {
+	var liveFields, genFields map[string]json.RawMessage
+	if err := json.Unmarshal(liveData, &liveFields); err != nil {
+		return "", fmt.Errorf("parse live JSON: %w", err)
+	}
+	if err := json.Unmarshal(generated, &genFields); err != nil {
+		return "", fmt.Errorf("parse generated JSON: %w", err)
+	}
+
+	projected := make(map[string]json.RawMessage, len(genFields))
+	for k, gen := range genFields {
+		v, ok := liveFields[k]
+		if !ok {
+			continue
+		}
+		var s string
+		if json.Unmarshal(gen, &s) == nil {
+			if _, placeholder := refs.Parse(s); placeholder {
+				v = gen
+			}
+		}
+		projected[k] = v
+	}
+
+	var description string
+	if raw, ok := projected["description"]; ok && json.Unmarshal(raw, &description) == nil {
+		data, err := json.Marshal(annotate.Strip(description))
+		if err != nil {
+			return "", err
+		}
+		projected["description"] = data
+	}
+
+	data, err := json.Marshal(projected)
+	if err != nil {
+		return "", err
+	}
+	return Hash(data)
+}
+
+// isLiveType reports whether live state is available for a resource type.
+func isLiveType(resourceType string) bool {
+	for _, t := range LiveTypes {
+		if t == resourceType {
+			return true
+		}
+	}
+	return false
+}
+
+// ResourceLister lists live resources. It is satisfied by *honeycomb.Client.
+type ResourceLister interface {
+	ListResources(ctx context.Context, resourceType, dataset string) ([]json.RawMessage, error)
+}
+
+// FetchLive retrieves the live counterpart of every locked or generated
+// resource that supports live state, matched by type, dataset and name.
+// The result is keyed by lockfile key; resources not found live are omitted.
+func FetchLive(ctx context.Context, lister ResourceLister, output *honeycomb.Manifest, locked *Lockfile) (map[string]json.RawMessage, error) {
+	code, err := Generate(output)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]Entry)
+	for k, e := range locked.Resources {
+		entries[k] = e
+	}
+	for k, e := range code.Resources {
+		entries[k] = e
+	}
+
+	type collection struct{ resourceType, dataset string }
+	listed := make(map[collection]map[string]json.RawMessage)
+	live := make(map[string]json.RawMessage)
+
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		entry := entries[key]
+		resourceType, _ := SplitKey(key)
+		if !isLiveType(resourceType) || entry.Name == "" {
+			continue
+		}
+
+		c := collection{resourceType: resourceType, dataset: entry.Dataset}
+		if resourceType == "boards" {
+			c.dataset = ""
+		} else if c.dataset == "" {
+			continue
+		}
+
+		byName, ok := listed[c]
+		if !ok {
+			resources, err := lister.ListResources(ctx, c.resourceType, c.dataset)
+			if err != nil {
+				return nil, err
+			}
+			byName = make(map[string]json.RawMessage, len(resources))
+			for _, r := range resources {
+				var meta struct {
+					Name string `json:"name"`
+				}
+				if json.Unmarshal(r, &meta) == nil && meta.Name != "" {
+					byName[meta.Name] = r
+				}
+			}
+			listed[c] = byName
+		}
+
+		if r, ok := byName[entry.Name]; ok {
+			live[key] = r
+		}
+	}
+
+	return live, nil
+}
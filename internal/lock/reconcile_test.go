@@ -0,0 +1,166 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lockedOutput returns build output and a lockfile generated from it.
+func lockedOutput(t *testing.T) (*honeycomb.Manifest, *Lockfile) {
+	t.Helper()
+	output := &honeycomb.Manifest{
+		Queries: map[string]json.RawMessage{
+			"Errors": json.RawMessage(`{"time_range": 3600}`),
+		},
+		Triggers: map[string]json.RawMessage{
+			"HighErrors": json.RawMessage(`{"name": "High Errors", "dataset": "api", "frequency": 300}`),
+			"LowTraffic": json.RawMessage(`{"name": "Low Traffic", "dataset": "api", "frequency": 900}`),
+		},
+	}
+	lf, err := Generate(output)
+	require.NoError(t, err)
+	return output, lf
+}
+
+// statuses maps lockfile keys to their reconciled status.
+func statuses(report *Report) map[string]Status {
+	result := make(map[string]Status)
+	for _, d := range report.Resources {
+		result[d.Key] = d.Status
+	}
+	return result
+}
+
+func TestReconcile_CodeVsLock(t *testing.T) {
+	output, lf := lockedOutput(t)
+
+	report, err := Reconcile(output, lf, nil)
+	require.NoError(t, err)
+	assert.False(t, report.HasDrift())
+	assert.False(t, report.LiveChecked)
+
+	output.Triggers["HighErrors"] = json.RawMessage(`{"name": "High Errors", "dataset": "api", "frequency": 60}`)
+	delete(output.Triggers, "LowTraffic")
+	output.Queries["Latency"] = json.RawMessage(`{"time_range": 900}`)
+
+	report, err = Reconcile(output, lf, nil)
+	require.NoError(t, err)
+	assert.True(t, report.HasDrift())
+	assert.Equal(t, map[string]Status{
+		"queries/Errors":      StatusInSync,
+		"queries/Latency":     StatusAdded,
+		"triggers/HighErrors": StatusModified,
+		"triggers/LowTraffic": StatusRemoved,
+	}, statuses(report))
+	assert.Equal(t, 1, report.Counts()[StatusModified])
+}
+
+func TestReconcile_ThreeWay(t *testing.T) {
+	output, lf := lockedOutput(t)
+
+	live := map[string]json.RawMessage{
+		// Server-side fields are ignored
+		"triggers/HighErrors": json.RawMessage(`{"id": "t1", "name": "High Errors", "dataset": "api", "frequency": 300}`),
+		"triggers/LowTraffic": json.RawMessage(`{"id": "t2", "name": "Low Traffic", "dataset": "api", "frequency": 60}`),
+	}
+
+	report, err := Reconcile(output, lf, live)
+	require.NoError(t, err)
+	assert.True(t, report.LiveChecked)
+	assert.Equal(t, map[string]Status{
+		"queries/Errors":      StatusInSync,
+		"triggers/HighErrors": StatusInSync,
+		"triggers/LowTraffic": StatusDrifted,
+	}, statuses(report))
+
+	// Code changes to match live: the lockfile is stale
+	output.Triggers["LowTraffic"] = json.RawMessage(`{"name": "Low Traffic", "dataset": "api", "frequency": 60}`)
+	// Code and live both change differently: conflict
+	output.Triggers["HighErrors"] = json.RawMessage(`{"name": "High Errors", "dataset": "api", "frequency": 120}`)
+	live["triggers/HighErrors"] = json.RawMessage(`{"id": "t1", "name": "High Errors", "dataset": "api", "frequency": 600}`)
+
+	report, err = Reconcile(output, lf, live)
+	require.NoError(t, err)
+	assert.Equal(t, StatusConflict, statuses(report)["triggers/HighErrors"])
+	assert.Equal(t, StatusLockStale, statuses(report)["triggers/LowTraffic"])
+
+	delete(live, "triggers/HighErrors")
+	report, err = Reconcile(output, lf, live)
+	require.NoError(t, err)
+	assert.Equal(t, StatusMissingLive, statuses(report)["triggers/HighErrors"])
+}
+
+func TestReconcile_IgnoresManagedAnnotation(t *testing.T) {
+	output := &honeycomb.Manifest{
+		Boards: map[string]json.RawMessage{"Overview": json.RawMessage(`{"name": "Overview", "description": "Service health"}`)},
+	}
+	lf, err := Generate(output)
+	require.NoError(t, err)
+
+	live := map[string]json.RawMessage{
+		"boards/Overview": json.RawMessage(`{"id": "b1", "name": "Overview", "description": "Service health\n\n---\nManaged by wetwire-honeycomb (source: repo).\nChanges made in the Honeycomb UI will be overwritten."}`),
+	}
+	report, err := Reconcile(output, lf, live)
+	require.NoError(t, err)
+	assert.Equal(t, StatusInSync, statuses(report)["boards/Overview"])
+
+	live["boards/Overview"] = json.RawMessage(`{"id": "b1", "name": "Overview", "description": "Edited in the UI"}`)
+	report, err = Reconcile(output, lf, live)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDrifted, statuses(report)["boards/Overview"])
+}
+
+func TestReconcile_Placeholders(t *testing.T) {
+	output := &honeycomb.Manifest{
+		Triggers: map[string]json.RawMessage{"HighErrors": json.RawMessage(`{"name": "High Errors", "query_id": "wetwire-ref:queries/Errors"}`)},
+	}
+	lf, err := Generate(output)
+	require.NoError(t, err)
+
+	// The live trigger holds the ID apply resolved the placeholder to
+	live := map[string]json.RawMessage{
+		"triggers/HighErrors": json.RawMessage(`{"id": "t1", "name": "High Errors", "query_id": "q1"}`),
+	}
+	report, err := Reconcile(output, lf, live)
+	require.NoError(t, err)
+	assert.Equal(t, StatusInSync, statuses(report)["triggers/HighErrors"])
+
+	live["triggers/HighErrors"] = json.RawMessage(`{"id": "t1", "name": "High Errors"}`)
+	report, err = Reconcile(output, lf, live)
+	require.NoError(t, err)
+	assert.Equal(t, StatusDrifted, statuses(report)["triggers/HighErrors"])
+}
+
+// fakeLister serves fixed resources per type and dataset.
+type fakeLister struct {
+	resources map[string][]json.RawMessage
+	calls     []string
+}
+
+func (f *fakeLister) ListResources(ctx context.Context, resourceType, dataset string) ([]json.RawMessage, error) {
+	f.calls = append(f.calls, resourceType+":"+dataset)
+	return f.resources[resourceType+":"+dataset], nil
+}
+
+func TestFetchLive(t *testing.T) {
+	output, lf := lockedOutput(t)
+
+	lister := &fakeLister{resources: map[string][]json.RawMessage{
+		"triggers:api": {
+			json.RawMessage(`{"id": "t1", "name": "High Errors"}`),
+			json.RawMessage(`{"id": "t3", "name": "Unmanaged"}`),
+		},
+	}}
+
+	live, err := FetchLive(context.Background(), lister, output, lf)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"triggers:api"}, lister.calls)
+	require.Len(t, live, 1)
+	assert.JSONEq(t, `{"id": "t1", "name": "High Errors"}`, string(live["triggers/HighErrors"]))
+}
@@ -0,0 +1,104 @@
+// Package normalize rewrites Honeycomb JSON into a canonical form, so that
+// cosmetic differences in Go code, such as the order breakdowns or filters are
+// listed in, do not show up as configuration changes.
+//
+// Normalization is optional: build applies it with the Normalize option of
+// the domain package, and the differ when Options.Normalize is set, so both sides
+// of a comparison are normalized the same way.
+package normalize
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+)
+
+// Query normalizes a decoded query in place:
+//   - breakdowns are sorted
+//   - filters are sorted by column, then operator and value
+//   - filter_combination defaults to "AND" when there are filters
+//   - a zero limit is removed
+func Query(q map[string]any) {
+	if breakdowns, ok := q["breakdowns"].([]any); ok {
+		sort.SliceStable(breakdowns, func(i, j int) bool {
+			return fmt.Sprint(breakdowns[i]) < fmt.Sprint(breakdowns[j])
+		})
+	}
+
+	if filters, ok := q["filters"].([]any); ok && len(filters) > 0 {
+		sort.SliceStable(filters, func(i, j int) bool {
+			return filterKey(filters[i]) < filterKey(filters[j])
+		})
+		if combination, _ := q["filter_combination"].(string); combination == "" {
+			q["filter_combination"] = "AND"
+		}
+	}
+
+	if limit, ok := q["limit"].(float64); ok && limit == 0 {
+		delete(q, "limit")
+	}
+}
+
+// filterKey orders filters by column, then operator, then value.
+func filterKey(filter any) string {
+	f, _ := filter.(map[string]any)
+	value, _ := json.Marshal(f["value"])
+	return fmt.Sprintf("%v\x00%v\x00%s", f["column"], f["op"], value)
+}
+
+// Resource normalizes a decoded resource in place, including the queries it
+// embeds. resourceType is a build output key such as "boards", or its
+// singular form.
+func Resource(resourceType string, r map[string]any) {
+	switch resourceType {
+	case "queries", "query":
+		Query(r)
+	case "boards", "board":
+		panels, _ := r["panels"].([]any)
+		for _, p := range panels {
+			if panel, ok := p.(map[string]any); ok {
+				embedded(panel, "query")
+			}
+		}
+	case "slos", "slo":
+		if sli, ok := r["sli"].(map[string]any); ok {
+			embedded(sli, "good_events")
+			embedded(sli, "total_events")
+		}
+	case "triggers", "trigger":
+		embedded(r, "query")
+	}
+}
+
+// embedded normalizes the query in field of parent, if there is one.
+func embedded(parent map[string]any, field string) {
+	if q, ok := parent[field].(map[string]any); ok {
+		Query(q)
+	}
+}
+
+// JSON returns the normalized JSON of one resource of resourceType.
+func JSON(resourceType string, data []byte) ([]byte, error) {
+	var r map[string]any
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("parse JSON: %w", err)
+	}
+	Resource(resourceType, r)
+	return json.Marshal(r)
+}
+
+// Output normalizes every resource of a build output in place.
+func Output(output *honeycomb.Manifest) error {
+	for resourceType, resources := range output.Resources() {
+		for name, data := range resources {
+			normalized, err := JSON(resourceType, data)
+			if err != nil {
+				return fmt.Errorf("%s %s: %w", resourceType, name, err)
+			}
+			resources[name] = normalized
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package normalize
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, s string) map[string]any {
+	t.Helper()
+	var m map[string]any
+	require.NoError(t, json.Unmarshal([]byte(s), &m))
+	return m
+}
+
+func TestQuery(t *testing.T) {
+	q := decode(t, `{
+		"breakdowns": ["service", "endpoint"],
+		"filters": [
+			{"column": "status", "op": ">=", "value": 500},
+			{"column": "duration_ms", "op": ">", "value": 100},
+			{"column": "duration_ms", "op": "<", "value": 5000}
+		],
+		"limit": 0
+	}`)
+	Query(q)
+
+	assert.Equal(t, decode(t, `{
+		"breakdowns": ["endpoint", "service"],
+		"filters": [
+			{"column": "duration_ms", "op": "<", "value": 5000},
+			{"column": "duration_ms", "op": ">", "value": 100},
+			{"column": "status", "op": ">=", "value": 500}
+		],
+		"filter_combination": "AND"
+	}`), q)
+}
+
+func TestQuery_KeepsSettings(t *testing.T) {
+	q := decode(t, `{"filters": [{"column": "a", "op": "exists"}], "filter_combination": "OR", "limit": 10}`)
+	Query(q)
+	assert.Equal(t, "OR", q["filter_combination"])
+	assert.Equal(t, float64(10), q["limit"])
+
+	// Without filters the combination is left unset
+	q = decode(t, `{"calculations": [{"op": "COUNT"}]}`)
+	Query(q)
+	assert.NotContains(t, q, "filter_combination")
+}
+
+func TestResource_EmbeddedQueries(t *testing.T) {
+	tests := map[string]struct {
+		resourceType string
+		json         string
+		path         func(map[string]any) map[string]any
+	}{
+		"board": {"boards", `{"panels": [{"type": "text"}, {"type": "query", "query": {"breakdowns": ["b", "a"]}}]}`,
+			func(r map[string]any) map[string]any {
+				return r["panels"].([]any)[1].(map[string]any)["query"].(map[string]any)
+			}},
+		"slo": {"slo", `{"sli": {"good_events": {"breakdowns": ["b", "a"]}}}`,
+			func(r map[string]any) map[string]any {
+				return r["sli"].(map[string]any)["good_events"].(map[string]any)
+			}},
+		"trigger": {"triggers", `{"query": {"breakdowns": ["b", "a"]}}`,
+			func(r map[string]any) map[string]any { return r["query"].(map[string]any) }},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := decode(t, tt.json)
+			Resource(tt.resourceType, r)
+			assert.Equal(t, []any{"a", "b"}, tt.path(r)["breakdowns"])
+		})
+	}
+}
+
+func TestOutput(t *testing.T) {
+	output := &honeycomb.Manifest{
+		Queries: map[string]json.RawMessage{"Latency": json.RawMessage(`{"breakdowns": ["b", "a"], "limit": 0}`)},
+	}
+	require.NoError(t, Output(output))
+	assert.JSONEq(t, `{"breakdowns": ["a", "b"]}`, string(output.Queries["Latency"]))
+
+	output.Queries["Broken"] = json.RawMessage(`[`)
+	assert.Error(t, Output(output))
+}
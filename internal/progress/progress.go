@@ -0,0 +1,152 @@
+// Package progress shows the progress of long operations, such as discovery
+// over a monorepo or apply, on a terminal: a count, percentage and ETA when
+// the total is known, otherwise a spinner. Operations report to a Tracker,
+// so they run the same without one.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/render"
+)
+
+// disabled is whether progress output is off (see Disable).
+var disabled bool
+
+// Disable turns progress output off, or with false back on. The
+// --no-progress flag sets it.
+func Disable(off bool) {
+	disabled = off
+}
+
+// Tracker receives the progress of an operation.
+type Tracker interface {
+	// Start begins an operation of total steps; 0 means unknown
+	Start(total int)
+
+	// Add records n more steps done
+	Add(n int)
+
+	// Finish ends the operation, clearing what was shown
+	Finish()
+}
+
+// Nop is a Tracker that shows nothing.
+var Nop Tracker = nop{}
+
+type nop struct{}
+
+func (nop) Start(int) {}
+func (nop) Add(int)   {}
+func (nop) Finish()   {}
+
+// Enabled reports whether progress is shown on w: w is a terminal, TERM is
+// not "dumb" and progress is not disabled.
+func Enabled(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok || !render.IsTerminal(f) || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return !disabled
+}
+
+// For returns a Bar writing to w when Enabled(w), and Nop otherwise.
+func For(w io.Writer, label, unit string) Tracker {
+	if !Enabled(w) {
+		return Nop
+	}
+	return New(w, label, unit)
+}
+
+// delay is how long an operation runs before it is shown, so that fast
+// operations do not flicker.
+const delay = 250 * time.Millisecond
+
+// interval is the minimum time between redraws.
+const interval = 100 * time.Millisecond
+
+// frames are the spinner frames shown while the total is unknown.
+var frames = []string{"|", "/", "-", "\\"}
+
+// Bar is a Tracker drawing one line, redrawn in place: "Discovering 120/480
+// files 25% ETA 12s", or "Applying | 12 resources" without a total.
+type Bar struct {
+	mu    sync.Mutex
+	w     io.Writer
+	label string
+	unit  string
+
+	total, done int
+	start       time.Time
+	drawn       time.Time
+	frame       int
+
+	// now returns the current time; time.Now when nil
+	now func() time.Time
+}
+
+// New returns a Bar writing to w, labeling the operation and counting its
+// steps in unit, e.g. "files".
+func New(w io.Writer, label, unit string) *Bar {
+	return &Bar{w: w, label: label, unit: unit}
+}
+
+// Start implements Tracker.
+func (b *Bar) Start(total int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.total, b.done, b.frame = total, 0, 0
+	b.start, b.drawn = b.clock(), time.Time{}
+}
+
+// Add implements Tracker, redrawing the line at most every interval.
+func (b *Bar) Add(n int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.done += n
+
+	now := b.clock()
+	if now.Sub(b.start) < delay || now.Sub(b.drawn) < interval {
+		return
+	}
+	b.drawn = now
+	b.frame++
+	fmt.Fprintf(b.w, "\r%s\x1b[K", b.line(now))
+}
+
+// Finish implements Tracker, clearing the line if it was drawn.
+func (b *Bar) Finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.drawn.IsZero() {
+		fmt.Fprint(b.w, "\r\x1b[K")
+	}
+	b.drawn = time.Time{}
+}
+
+// line returns the text of the bar at now.
+func (b *Bar) line(now time.Time) string {
+	if b.total <= 0 {
+		return fmt.Sprintf("%s %s %d %s", b.label, frames[b.frame%len(frames)], b.done, b.unit)
+	}
+
+	done := min(b.done, b.total)
+	line := fmt.Sprintf("%s %d/%d %s %3d%%", b.label, done, b.total, b.unit, done*100/b.total)
+	if done > 0 && done < b.total {
+		elapsed := now.Sub(b.start)
+		eta := elapsed * time.Duration(b.total-done) / time.Duration(done)
+		line += " ETA " + eta.Round(time.Second).String()
+	}
+	return line
+}
+
+func (b *Bar) clock() time.Time {
+	if b.now == nil {
+		return time.Now()
+	}
+	return b.now()
+}
@@ -0,0 +1,80 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// clock returns a clock that advances by step on every call.
+func clock(step time.Duration) func() time.Time {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
+
+func TestBar(t *testing.T) {
+	var out bytes.Buffer
+	b := New(&out, "Discovering", "files")
+	b.now = clock(time.Second)
+
+	b.Start(4)
+	b.Add(1)
+	assert.Equal(t, "\rDiscovering 1/4 files  25% ETA 3s\x1b[K", out.String())
+
+	out.Reset()
+	b.Add(3)
+	assert.Equal(t, "\rDiscovering 4/4 files 100%\x1b[K", out.String())
+
+	out.Reset()
+	b.Finish()
+	assert.Equal(t, "\r\x1b[K", out.String(), "the line should be cleared")
+}
+
+func TestBar_Spinner(t *testing.T) {
+	var out bytes.Buffer
+	b := New(&out, "Applying", "resources")
+	b.now = clock(time.Second)
+
+	b.Start(0)
+	b.Add(1)
+	b.Add(1)
+	assert.Equal(t, "\rApplying / 1 resources\x1b[K\rApplying - 2 resources\x1b[K", out.String())
+}
+
+func TestBar_Fast(t *testing.T) {
+	var out bytes.Buffer
+	b := New(&out, "Discovering", "files")
+	b.now = clock(time.Millisecond)
+
+	b.Start(100)
+	for range 100 {
+		b.Add(1)
+	}
+	b.Finish()
+	assert.Empty(t, out.String(), "operations faster than the delay should not be shown")
+}
+
+func TestBar_Throttled(t *testing.T) {
+	var out bytes.Buffer
+	b := New(&out, "Discovering", "files")
+	b.now = clock(30 * time.Millisecond)
+
+	b.Start(1000)
+	for range 1000 {
+		b.Add(1)
+	}
+	// 30s of work redrawn at most every 100ms
+	draws := strings.Count(out.String(), "\r")
+	assert.Greater(t, draws, 100)
+	assert.LessOrEqual(t, draws, 300)
+}
+
+func TestFor(t *testing.T) {
+	assert.Equal(t, Nop, For(&bytes.Buffer{}, "Discovering", "files"), "only terminals show progress")
+}
@@ -0,0 +1,139 @@
+// Package refs links resources in build output before they have Honeycomb
+// IDs. A field that holds the ID of another resource, such as a trigger's
+// query_id, is written as a placeholder naming the resource by its lockfile
+// key; apply replaces placeholders with the IDs it assigns.
+package refs
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Prefix starts every placeholder, e.g. "wetwire-ref:queries/SlowRequests".
+const Prefix = "wetwire-ref:"
+
+// Section is the key of the references manifest in build --refs output.
+const Section = "references"
+
+// Placeholder returns the placeholder for the ID of the resource with the
+// given lockfile key.
+func Placeholder(key string) string {
+	return Prefix + key
+}
+
+// Parse returns the resource key of a placeholder, and false for any other
+// string.
+func Parse(s string) (string, bool) {
+	key, ok := strings.CutPrefix(s, Prefix)
+	return key, ok && key != ""
+}
+
+// Reference is a link from one resource to another.
+type Reference struct {
+	// From and To are lockfile keys, e.g. "triggers/HighLatency" and
+	// "queries/Latency"
+	From string `json:"from"`
+	To   string `json:"to"`
+
+	// Field is the dotted path of the placeholder in From's JSON, with array
+	// elements by index. It is empty when From only has to be applied after
+	// To, e.g. a board showing a query.
+	Field string `json:"field,omitempty"`
+}
+
+// Find returns the placeholders in a resource's JSON, keyed by their dotted
+// field path.
+func Find(body json.RawMessage) (map[string]string, error) {
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, fmt.Errorf("parse JSON: %w", err)
+	}
+
+	found := make(map[string]string)
+	var walk func(path string, v any)
+	walk = func(path string, v any) {
+		switch v := v.(type) {
+		case string:
+			if key, ok := Parse(v); ok {
+				found[path] = key
+			}
+		case []any:
+			for i, elem := range v {
+				walk(join(path, strconv.Itoa(i)), elem)
+			}
+		case map[string]any:
+			for k, elem := range v {
+				walk(join(path, k), elem)
+			}
+		}
+	}
+	walk("", v)
+	return found, nil
+}
+
+// join appends a field to a dotted path.
+func join(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
+
+// Resolve returns body with every placeholder whose resource has an ID in ids
+// replaced by that ID. Placeholders of resources without an ID, e.g. not yet
+// created in a dry run, are left in place.
+func Resolve(body json.RawMessage, ids map[string]string) (json.RawMessage, error) {
+	if !strings.Contains(string(body), Prefix) {
+		return body, nil
+	}
+
+	var v any
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, fmt.Errorf("parse JSON: %w", err)
+	}
+
+	var resolve func(v any) any
+	resolve = func(v any) any {
+		switch v := v.(type) {
+		case string:
+			if key, ok := Parse(v); ok && ids[key] != "" {
+				return ids[key]
+			}
+		case []any:
+			for i, elem := range v {
+				v[i] = resolve(elem)
+			}
+		case map[string]any:
+			for k, elem := range v {
+				v[k] = resolve(elem)
+			}
+		}
+		return v
+	}
+	return json.Marshal(resolve(v))
+}
+
+// Sort orders references by source, target and field, dropping duplicates.
+func Sort(references []Reference) []Reference {
+	sort.Slice(references, func(i, j int) bool {
+		a, b := references[i], references[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Field < b.Field
+	})
+
+	var sorted []Reference
+	for i, r := range references {
+		if i == 0 || r != references[i-1] {
+			sorted = append(sorted, r)
+		}
+	}
+	return sorted
+}
@@ -0,0 +1,72 @@
+package refs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceholder(t *testing.T) {
+	p := Placeholder("queries/Latency")
+	assert.Equal(t, "wetwire-ref:queries/Latency", p)
+
+	key, ok := Parse(p)
+	assert.True(t, ok)
+	assert.Equal(t, "queries/Latency", key)
+
+	for _, s := range []string{"", "abc123", "wetwire-ref:", "queries/Latency"} {
+		_, ok := Parse(s)
+		assert.False(t, ok, s)
+	}
+}
+
+func TestFind(t *testing.T) {
+	body := json.RawMessage(`{
+		"name": "High Latency",
+		"query_id": "wetwire-ref:queries/Latency",
+		"panels": [{"query_id": "abc"}, {"query_id": "wetwire-ref:queries/Errors"}],
+		"slo": {"id": "wetwire-ref:slos/Availability"}
+	}`)
+
+	found, err := Find(body)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"query_id":          "queries/Latency",
+		"panels.1.query_id": "queries/Errors",
+		"slo.id":            "slos/Availability",
+	}, found)
+
+	_, err = Find(json.RawMessage(`{`))
+	assert.Error(t, err)
+}
+
+func TestResolve(t *testing.T) {
+	body := json.RawMessage(`{"query_id":"wetwire-ref:queries/Latency","panels":[{"query_id":"wetwire-ref:queries/Errors"}]}`)
+
+	resolved, err := Resolve(body, map[string]string{"queries/Latency": "q1"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"query_id":"q1","panels":[{"query_id":"wetwire-ref:queries/Errors"}]}`, string(resolved))
+
+	// Bodies without placeholders are returned unchanged
+	plain := json.RawMessage(`{ "name": "x" }`)
+	resolved, err = Resolve(plain, map[string]string{"queries/Latency": "q1"})
+	require.NoError(t, err)
+	assert.Equal(t, string(plain), string(resolved))
+}
+
+func TestSort(t *testing.T) {
+	sorted := Sort([]Reference{
+		{From: "triggers/B", To: "queries/Q", Field: "query_id"},
+		{From: "boards/A", To: "queries/Q"},
+		{From: "triggers/B", To: "queries/Q", Field: "query_id"},
+		{From: "boards/A", To: "queries/P"},
+	})
+
+	assert.Equal(t, []Reference{
+		{From: "boards/A", To: "queries/P"},
+		{From: "boards/A", To: "queries/Q"},
+		{From: "triggers/B", To: "queries/Q", Field: "query_id"},
+	}, sorted)
+}
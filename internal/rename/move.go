@@ -0,0 +1,729 @@
+package rename
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/atomicfile"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// MovePlan is the set of edits that moves a resource to another package.
+type MovePlan struct {
+	// Type is the resource type, e.g. "query"
+	Type string
+
+	// Name is the Go variable name, which does not change
+	Name string
+
+	// From and To are the source and target package directories
+	From, To string
+
+	// Target is the file the declaration moves to
+	Target string
+
+	// Files are the changed Go files, including Target, sorted by path
+	Files []string
+
+	// content holds the new source of each file in Files
+	content map[string][]byte
+}
+
+// NewMovePlan finds the resource of type typ declared as the Go variable name
+// under root and plans moving its declaration to the package in targetDir,
+// with references under root rewritten and imports added and removed to
+// match. The declaration moves to a file with the same base name as its
+// source file, which is created if needed. Moves that would leave a package
+// referring to an unexported name, or create an import cycle, are refused.
+func NewMovePlan(root, typ, name, targetDir string) (*MovePlan, error) {
+	typ = strings.ToLower(typ)
+	if _, ok := LockTypes[typ]; !ok {
+		return nil, fmt.Errorf("unknown resource type %q (want query, slo, trigger or board)", typ)
+	}
+
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolve path: %w", err)
+	}
+	targetDir, err = filepath.Abs(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("resolve path: %w", err)
+	}
+
+	resources, err := discovery.DiscoverAll(root)
+	if err != nil {
+		return nil, fmt.Errorf("discovery failed: %w", err)
+	}
+	file, line, err := findResource(resources, typ, name)
+	if err != nil {
+		return nil, err
+	}
+	decl, err := parseDecl(file, name, line)
+	if err != nil {
+		return nil, err
+	}
+	if targetDir == decl.dir {
+		return nil, fmt.Errorf("%s %s is already in %s", typ, name, targetDir)
+	}
+	if decl.importPath == "" {
+		return nil, fmt.Errorf("%s is not in a Go module", decl.dir)
+	}
+
+	target, err := newTargetPackage(targetDir)
+	if err != nil {
+		return nil, err
+	}
+	if target.scope[name] {
+		return nil, fmt.Errorf("package %s already declares %s", target.pkg, name)
+	}
+
+	m := &mover{decl: decl, target: target, file: file, edits: make(map[string]*fileEdits)}
+	if err := m.extract(); err != nil {
+		return nil, err
+	}
+	if err := m.rewriteReferences(root); err != nil {
+		return nil, err
+	}
+
+	plan := &MovePlan{
+		Type:    typ,
+		Name:    name,
+		From:    decl.dir,
+		To:      targetDir,
+		Target:  filepath.Join(targetDir, filepath.Base(file)),
+		content: make(map[string][]byte),
+	}
+	if err := m.placeDeclaration(plan.Target); err != nil {
+		return nil, err
+	}
+
+	imports := make(map[string]map[string]bool)
+	for p, fe := range m.edits {
+		out, err := fe.finish()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		plan.content[p] = out
+		plan.Files = append(plan.Files, p)
+
+		f, err := parser.ParseFile(token.NewFileSet(), p, out, parser.ImportsOnly)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		dir := filepath.Dir(p)
+		if imports[dir] == nil {
+			imports[dir] = make(map[string]bool)
+		}
+		for _, imp := range f.Imports {
+			imports[dir][importSpecPath(imp)] = true
+		}
+	}
+	sort.Strings(plan.Files)
+
+	// Files that were not edited keep their imports
+	for _, dir := range []string{decl.dir, targetDir} {
+		if err := addPackageImports(imports, dir, plan.content); err != nil {
+			return nil, err
+		}
+	}
+	if imports[decl.dir][target.importPath] && imports[targetDir][decl.importPath] {
+		return nil, fmt.Errorf("moving %s to %s would create an import cycle between %s and %s", name, target.pkg, decl.pkg, target.pkg)
+	}
+	return plan, nil
+}
+
+// Apply writes the files of the plan, creating the target directory if needed.
+func (p *MovePlan) Apply() error {
+	if err := os.MkdirAll(p.To, 0755); err != nil {
+		return err
+	}
+	for _, file := range p.Files {
+		if err := atomicfile.WriteFile(file, p.content[file], 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// targetPackage is the package a declaration moves to.
+type targetPackage struct {
+	dir        string
+	pkg        string
+	importPath string
+	scope      map[string]bool
+}
+
+// newTargetPackage describes the package in dir, which need not exist yet;
+// a new package is named after its directory.
+func newTargetPackage(dir string) (*targetPackage, error) {
+	t := &targetPackage{dir: dir, importPath: importPath(dir), scope: make(map[string]bool)}
+	if t.importPath == "" {
+		return nil, fmt.Errorf("%s is not in a Go module", dir)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") || strings.HasSuffix(e.Name(), "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, e.Name()), nil, parser.PackageClauseOnly)
+		if err == nil {
+			t.pkg = f.Name.Name
+			break
+		}
+	}
+	if t.pkg == "" {
+		t.pkg = filepath.Base(dir)
+		if !token.IsIdentifier(t.pkg) {
+			return nil, fmt.Errorf("directory name %q is not a valid package name", t.pkg)
+		}
+		return t, nil
+	}
+
+	scope, err := packageScope(dir, t.pkg)
+	if err != nil {
+		return nil, err
+	}
+	t.scope = scope
+	return t, nil
+}
+
+// mover builds the edits of a move.
+type mover struct {
+	decl   *declaration
+	target *targetPackage
+
+	// file declares the resource
+	file string
+
+	// text is the declaration as it will appear in the target package,
+	// before its references to the source package are qualified
+	text string
+
+	// qualify are the offsets in text of identifiers of the source package
+	qualify []int
+
+	// unqualify are the offsets in text of selectors of the target package,
+	// whose qualifier goes away after the move
+	unqualify []int
+
+	// imports are the imports of the source file the declaration uses,
+	// by the name they are referred to by
+	imports map[string]string
+
+	edits map[string]*fileEdits
+}
+
+// extract records the declaration's text and dependencies and removes it
+// from its file.
+func (m *mover) extract() error {
+	src, err := os.ReadFile(m.file)
+	if err != nil {
+		return err
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, m.file, src, parser.ParseComments)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", m.file, err)
+	}
+	offset := func(p token.Pos) int { return fset.Position(p).Offset }
+
+	var gen *ast.GenDecl
+	var spec *ast.ValueSpec
+	for _, d := range f.Decls {
+		g, ok := d.(*ast.GenDecl)
+		if !ok || g.Tok != token.VAR {
+			continue
+		}
+		for _, s := range g.Specs {
+			vs := s.(*ast.ValueSpec)
+			for _, ident := range vs.Names {
+				if offset(ident.Pos()) == m.decl.offset {
+					gen, spec = g, vs
+				}
+			}
+		}
+	}
+	if spec == nil {
+		return fmt.Errorf("%s: declaration of %s not found", m.file, m.decl.name)
+	}
+	if len(spec.Names) > 1 {
+		return fmt.Errorf("%s is declared together with other variables; split the declaration first", m.decl.name)
+	}
+
+	// The declaration's text, with its doc comment, as a var declaration
+	var start, end int
+	if gen.Lparen.IsValid() {
+		doc := spec.Doc
+		start, end = offset(spec.Pos()), offset(spec.End())
+		if spec.Comment != nil {
+			end = offset(spec.Comment.End())
+		}
+		if len(gen.Specs) == 1 {
+			// A group of one goes away entirely
+			if doc == nil {
+				doc = gen.Doc
+			}
+			start, end = offset(gen.Pos()), offset(gen.End())
+			if gen.Doc != nil {
+				start = offset(gen.Doc.Pos())
+			}
+		}
+		if doc != nil && offset(doc.Pos()) < start {
+			start = offset(doc.Pos())
+		}
+
+		var docText string
+		if doc != nil {
+			docText = string(src[offset(doc.Pos()):offset(doc.End())]) + "\n"
+		}
+		textEnd := offset(spec.End())
+		if spec.Comment != nil {
+			textEnd = offset(spec.Comment.End())
+		}
+		m.text = docText + "var " + string(src[offset(spec.Pos()):textEnd])
+		m.shiftQualify(f, fset, spec, offset(spec.Pos())-len(docText)-len("var "))
+	} else {
+		start, end = offset(gen.Pos()), offset(gen.End())
+		if gen.Doc != nil {
+			start = offset(gen.Doc.Pos())
+		}
+		if spec.Comment != nil && offset(spec.Comment.End()) > end {
+			end = offset(spec.Comment.End())
+		}
+		m.text = string(src[start:end])
+		m.shiftQualify(f, fset, spec, start)
+	}
+
+	// Remove whole lines, and one of the blank lines around the declaration
+	start, end = lineStart(src, start), lineEnd(src, end)
+	if start > 1 && src[start-1] == '\n' && src[start-2] == '\n' && end < len(src) && src[end] == '\n' {
+		end++
+	}
+	fe := m.edit(m.file, src)
+	fe.add(start, end, "")
+	for _, p := range m.imports {
+		fe.unused = append(fe.unused, p)
+	}
+	if len(m.unqualify) > 0 {
+		fe.unused = append(fe.unused, m.target.importPath)
+	}
+	return nil
+}
+
+// shiftQualify records the source package identifiers and imports the
+// declaration uses, with offsets relative to base.
+func (m *mover) shiftQualify(f *ast.File, fset *token.FileSet, spec *ast.ValueSpec, base int) {
+	topLevel := make(map[int]bool)
+	for _, d := range f.Decls {
+		switch d := d.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil {
+				topLevel[fset.Position(d.Name.Pos()).Offset] = true
+			}
+		case *ast.GenDecl:
+			for _, s := range d.Specs {
+				switch s := s.(type) {
+				case *ast.ValueSpec:
+					for _, ident := range s.Names {
+						topLevel[fset.Position(ident.Pos()).Offset] = true
+					}
+				case *ast.TypeSpec:
+					topLevel[fset.Position(s.Name.Pos()).Offset] = true
+				}
+			}
+		}
+	}
+
+	importNames := make(map[string]string)
+	for _, imp := range f.Imports {
+		p := importSpecPath(imp)
+		importNames[importName(imp, path.Base(p))] = p
+	}
+
+	m.imports = make(map[string]string)
+	skip := nonReferences(spec)
+	ast.Inspect(spec, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok && x.Obj == nil {
+				if p, ok := importNames[x.Name]; ok {
+					if p == m.target.importPath {
+						m.unqualify = append(m.unqualify, fset.Position(x.Pos()).Offset-base)
+					} else {
+						m.imports[x.Name] = p
+					}
+					skip[x] = true
+				}
+			}
+		}
+
+		ident, ok := n.(*ast.Ident)
+		if !ok || skip[ident] || !m.decl.scope[ident.Name] {
+			return true
+		}
+		if fset.Position(ident.Pos()).Offset == m.decl.offset {
+			return true
+		}
+		if ident.Obj != nil && !topLevel[fset.Position(ident.Obj.Pos()).Offset] {
+			return true
+		}
+		m.qualify = append(m.qualify, fset.Position(ident.Pos()).Offset-base)
+		return true
+	})
+	sort.Ints(m.qualify)
+	sort.Ints(m.unqualify)
+}
+
+// rewriteReferences rewrites the references to the resource in the Go files
+// under root.
+func (m *mover) rewriteReferences(root string) error {
+	return filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != root && skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") {
+			return nil
+		}
+
+		src, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, p, src, 0)
+		if err != nil {
+			return nil
+		}
+		offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+
+		dir := filepath.Dir(p)
+		if dir == m.decl.dir && f.Name.Name == m.decl.pkg {
+			name := localName(f, m.target.importPath, m.target.pkg)
+			for _, off := range packageReferences(fset, f, m.decl, p == m.file) {
+				if p == m.file && off == m.decl.offset {
+					continue
+				}
+				if !token.IsExported(m.decl.name) {
+					return fmt.Errorf("%s is used by package %s, so it must be exported to move", m.decl.name, m.decl.pkg)
+				}
+				fe := m.edit(p, src)
+				fe.add(off, off, name+".")
+				fe.need(m.target.importPath, name)
+			}
+			return nil
+		}
+
+		selectors := importedSelectors(f, m.decl)
+		if len(selectors) == 0 {
+			return nil
+		}
+		fe := m.edit(p, src)
+		fe.unused = append(fe.unused, m.decl.importPath)
+		// The target package refers to the moved declaration unqualified
+		inTarget := dir == m.target.dir && f.Name.Name == m.target.pkg
+		name := localName(f, m.target.importPath, m.target.pkg)
+		for _, sel := range selectors {
+			if inTarget {
+				fe.add(offset(sel.Pos()), offset(sel.End()), m.decl.name)
+			} else {
+				fe.add(offset(sel.Pos()), offset(sel.End()), name+"."+m.decl.name)
+				fe.need(m.target.importPath, name)
+			}
+		}
+		return nil
+	})
+}
+
+// placeDeclaration adds the declaration to the target file, creating it if
+// it does not exist.
+func (m *mover) placeDeclaration(target string) error {
+	fe, ok := m.edits[target]
+	if !ok {
+		src, err := os.ReadFile(target)
+		switch {
+		case os.IsNotExist(err):
+			src = []byte("package " + m.target.pkg + "\n")
+		case err != nil:
+			return err
+		}
+		fe = m.edit(target, src)
+		fe.format = true
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), target, fe.src, parser.ImportsOnly)
+	if err != nil {
+		return fmt.Errorf("parse %s: %w", target, err)
+	}
+	if f.Name.Name != m.target.pkg {
+		return fmt.Errorf("%s is in package %s, not %s", target, f.Name.Name, m.target.pkg)
+	}
+
+	for name, p := range m.imports {
+		if have := localName(f, p, name); have != name {
+			return fmt.Errorf("%s imports %s as %s, but %s refers to it as %s", target, p, have, m.decl.name, name)
+		}
+		fe.need(p, name)
+	}
+
+	// Qualify the source package's identifiers, and drop the qualifier of
+	// the target package's
+	var spans []span
+	if len(m.qualify) > 0 {
+		source := localName(f, m.decl.importPath, m.decl.pkg)
+		for _, off := range m.qualify {
+			ident := identAt(m.text, off)
+			if !token.IsExported(ident) {
+				return fmt.Errorf("%s uses %s, which is not exported by package %s", m.decl.name, ident, m.decl.pkg)
+			}
+			spans = append(spans, span{off, off, source + "."})
+		}
+		fe.need(m.decl.importPath, source)
+	}
+	for _, off := range m.unqualify {
+		spans = append(spans, span{off, off + len(identAt(m.text, off)) + len("."), ""})
+	}
+	text := applySpans([]byte(m.text), spans)
+
+	fe.add(len(fe.src), len(fe.src), "\n"+string(text)+"\n")
+	return nil
+}
+
+// edit returns the edits of file, whose current source is src.
+func (m *mover) edit(file string, src []byte) *fileEdits {
+	fe, ok := m.edits[file]
+	if !ok {
+		fe = &fileEdits{src: src, imports: make(map[string]string)}
+		if formatted, err := format.Source(src); err == nil && bytes.Equal(formatted, src) {
+			fe.format = true
+		}
+		m.edits[file] = fe
+	}
+	return fe
+}
+
+// fileEdits are the changes to one file.
+type fileEdits struct {
+	src   []byte
+	spans []span
+
+	// imports are the imports to add, by path, with the name they are
+	// referred to by
+	imports map[string]string
+
+	// unused are import paths to remove if nothing uses them after the edit
+	unused []string
+
+	// format is set for gofmt-clean files and new files
+	format bool
+}
+
+// span replaces src[start:end] with text.
+type span struct {
+	start, end int
+	text       string
+}
+
+func (fe *fileEdits) add(start, end int, text string) {
+	fe.spans = append(fe.spans, span{start, end, text})
+}
+
+func (fe *fileEdits) need(importPath, name string) {
+	fe.imports[importPath] = name
+}
+
+// finish applies the edits, then fixes the imports of the result.
+func (fe *fileEdits) finish() ([]byte, error) {
+	out := applySpans(fe.src, fe.spans)
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "", out, 0)
+	if err != nil {
+		return nil, err
+	}
+	offset := func(p token.Pos) int { return fset.Position(p).Offset }
+
+	used := make(map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if x, ok := sel.X.(*ast.Ident); ok && x.Obj == nil {
+				used[x.Name] = true
+			}
+		}
+		return true
+	})
+
+	var spans []span
+	have := make(map[string]bool)
+	names := make(map[string]string)
+	for _, imp := range f.Imports {
+		p := importSpecPath(imp)
+		have[p] = true
+		names[importName(imp, path.Base(p))] = p
+	}
+	for _, gen := range f.Decls {
+		gen, ok := gen.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		var removed []span
+		for _, s := range gen.Specs {
+			imp := s.(*ast.ImportSpec)
+			p := importSpecPath(imp)
+			if !contains(fe.unused, p) || used[importName(imp, path.Base(p))] {
+				continue
+			}
+			removed = append(removed, span{lineStart(out, offset(imp.Pos())), lineEnd(out, offset(imp.End())), ""})
+		}
+		if len(removed) == len(gen.Specs) && len(removed) > 0 {
+			// Nothing is left of the declaration
+			removed = []span{{lineStart(out, offset(gen.Pos())), lineEnd(out, offset(gen.End())), ""}}
+		}
+		spans = append(spans, removed...)
+	}
+
+	var missing []string
+	for p, name := range fe.imports {
+		if have[p] {
+			continue
+		}
+		if other, ok := names[name]; ok && other != p {
+			return nil, fmt.Errorf("cannot import %s as %s: the name is used by %s", p, name, other)
+		}
+		spec := fmt.Sprintf("%q", p)
+		if name != path.Base(p) {
+			spec = name + " " + spec
+		}
+		missing = append(missing, spec)
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 {
+		var group *ast.GenDecl
+		for _, d := range f.Decls {
+			if gen, ok := d.(*ast.GenDecl); ok && gen.Tok == token.IMPORT && gen.Lparen.IsValid() {
+				group = gen
+				break
+			}
+		}
+		switch {
+		case group != nil:
+			at := lineStart(out, offset(group.Rparen))
+			spans = append(spans, span{at, at, "\t" + strings.Join(missing, "\n\t") + "\n"})
+		case len(missing) == 1 && len(f.Imports) == 0:
+			at := offset(f.Name.End())
+			spans = append(spans, span{at, at, "\n\nimport " + missing[0]})
+		default:
+			at := offset(f.Name.End())
+			spans = append(spans, span{at, at, "\n\nimport (\n\t" + strings.Join(missing, "\n\t") + "\n)"})
+		}
+	}
+
+	out = applySpans(out, spans)
+	if fe.format {
+		formatted, err := format.Source(out)
+		if err != nil {
+			return nil, err
+		}
+		out = formatted
+	}
+	return out, nil
+}
+
+// applySpans applies non-overlapping spans to src.
+func applySpans(src []byte, spans []span) []byte {
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+	var out bytes.Buffer
+	last := 0
+	for _, s := range spans {
+		out.Write(src[last:s.start])
+		out.WriteString(s.text)
+		last = s.end
+	}
+	out.Write(src[last:])
+	return out.Bytes()
+}
+
+// addPackageImports records the imports of the unedited Go files in dir.
+func addPackageImports(imports map[string]map[string]bool, dir string, edited map[string][]byte) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	if imports[dir] == nil {
+		imports[dir] = make(map[string]bool)
+	}
+	for _, e := range entries {
+		p := filepath.Join(dir, e.Name())
+		if _, ok := edited[p]; ok || e.IsDir() || !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), p, nil, parser.ImportsOnly)
+		if err != nil {
+			continue
+		}
+		for _, imp := range f.Imports {
+			imports[dir][importSpecPath(imp)] = true
+		}
+	}
+	return nil
+}
+
+// localName returns the name f refers to the package importPath by, or def
+// if f does not import it.
+func localName(f *ast.File, importPath, def string) string {
+	for _, imp := range f.Imports {
+		if importSpecPath(imp) == importPath && imp.Name == nil {
+			return def
+		} else if importSpecPath(imp) == importPath {
+			return imp.Name.Name
+		}
+	}
+	return def
+}
+
+// identAt returns the identifier starting at offset off of s.
+func identAt(s string, off int) string {
+	end := off
+	for end < len(s) && (s[end] == '_' || s[end] >= '0' && s[end] <= '9' || s[end] >= 'a' && s[end] <= 'z' || s[end] >= 'A' && s[end] <= 'Z' || s[end] >= 0x80) {
+		end++
+	}
+	return s[off:end]
+}
+
+// lineStart returns the offset of the start of the line containing off.
+func lineStart(src []byte, off int) int {
+	return bytes.LastIndexByte(src[:off], '\n') + 1
+}
+
+// lineEnd returns the offset just past the newline ending the line that
+// contains off.
+func lineEnd(src []byte, off int) int {
+	if i := bytes.IndexByte(src[off:], '\n'); i >= 0 {
+		return off + i + 1
+	}
+	return len(src)
+}
+
+// contains reports whether list contains s.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
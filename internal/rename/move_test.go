@@ -0,0 +1,148 @@
+package rename
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const moveTriggersSource = `package triggers
+
+import (
+	q "example.com/obs/queries"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var SlowRequests = trigger.Trigger{
+	Name:      "Slow requests",
+	Query:     q.Latency,
+	Threshold: trigger.GreaterThan(1000),
+}
+`
+
+// writeMoveWorkspace writes a module with queries and triggers packages,
+// where only Latency is used by the triggers package.
+func writeMoveWorkspace(t *testing.T) string {
+	t.Helper()
+	root := writeWorkspace(t)
+	require.NoError(t, os.WriteFile(filepath.Join(root, "triggers", "triggers.go"), []byte(moveTriggersSource), 0644))
+	return root
+}
+
+func TestMove(t *testing.T) {
+	root := writeMoveWorkspace(t)
+
+	plan, err := NewMovePlan(root, "query", "Latency", filepath.Join(root, "shared"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "shared", "queries.go"), plan.Target)
+	assert.Equal(t, []string{
+		filepath.Join(root, "queries", "boards.go"),
+		filepath.Join(root, "queries", "queries.go"),
+		filepath.Join(root, "shared", "queries.go"),
+		filepath.Join(root, "triggers", "triggers.go"),
+	}, plan.Files)
+	require.NoError(t, plan.Apply())
+
+	assert.Equal(t, `package shared
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+// Latency is the p99 request latency.
+var Latency = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Calculations: []query.Calculation{query.P99("duration_ms")},
+}
+`, readFile(t, root, "shared/queries.go"))
+
+	queries := readFile(t, root, "queries/queries.go")
+	assert.NotContains(t, queries, "Latency = query.Query")
+	assert.Contains(t, queries, "var All = []query.Query{shared.Latency, Errors}")
+	assert.Contains(t, queries, `"example.com/obs/shared"`)
+	// The shadowing local is left alone
+	assert.Contains(t, queries, "\tLatency := query.Query{Dataset: \"other\"}\n")
+
+	boards := readFile(t, root, "queries/boards.go")
+	assert.Contains(t, boards, "board.QueryPanel(shared.Latency)")
+	assert.Contains(t, boards, `"example.com/obs/shared"`)
+
+	triggers := readFile(t, root, "triggers/triggers.go")
+	assert.Contains(t, triggers, "Query:     shared.Latency,")
+	assert.Contains(t, triggers, `"example.com/obs/shared"`)
+	assert.NotContains(t, triggers, "example.com/obs/queries", "unused import is removed")
+}
+
+func TestMove_QualifiesSourcePackage(t *testing.T) {
+	root := writeMoveWorkspace(t)
+
+	plan, err := NewMovePlan(root, "board", "Overview", filepath.Join(root, "boards"))
+	require.NoError(t, err)
+	require.NoError(t, plan.Apply())
+
+	boards := readFile(t, root, "boards/boards.go")
+	assert.Contains(t, boards, "package boards\n")
+	assert.Contains(t, boards, "board.QueryPanel(queries.Latency)")
+	assert.Contains(t, boards, `"example.com/obs/queries"`)
+	assert.Contains(t, boards, `"github.com/lex00/wetwire-honeycomb-go/board"`)
+
+	// Nothing is left in the source file but its package clause
+	assert.Equal(t, "package queries\n", readFile(t, root, "queries/boards.go"))
+}
+
+func TestMove_ExistingPackage(t *testing.T) {
+	root := writeMoveWorkspace(t)
+
+	plan, err := NewMovePlan(root, "trigger", "SlowRequests", filepath.Join(root, "queries"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "queries", "triggers.go"), plan.Target)
+	require.NoError(t, plan.Apply())
+
+	// Within the queries package the query is referenced unqualified
+	moved := readFile(t, root, "queries/triggers.go")
+	assert.Contains(t, moved, "package queries\n")
+	assert.Contains(t, moved, "Query:     Latency,")
+	assert.NotContains(t, moved, "example.com/obs/queries")
+	assert.Equal(t, "package triggers\n", readFile(t, root, "triggers/triggers.go"))
+}
+
+func TestMove_Errors(t *testing.T) {
+	root := writeMoveWorkspace(t)
+
+	// The queries package still uses Errors, and Errors' new package would
+	// import queries for Latency
+	cycle := `package triggers
+
+import (
+	q "example.com/obs/queries"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+var SlowRequests = trigger.Trigger{Name: "Slow", Query: q.Latency}
+
+var Failing = trigger.Trigger{Name: "Failing", Query: q.Errors}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(root, "triggers", "triggers.go"), []byte(cycle), 0644))
+
+	tests := map[string][]string{
+		"unknown type": {"dataset", "Latency", "shared"},
+		"not found":    {"query", "Missing", "shared"},
+		"same package": {"query", "Latency", "queries"},
+		"name in use":  {"trigger", "SlowRequests", "queries"},
+		"import cycle": {"query", "Latency", "triggers"},
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(root, "queries", "extra.go"), []byte("package queries\n\nvar SlowRequests = 1\n"), 0644))
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewMovePlan(root, args[0], args[1], filepath.Join(root, args[2]))
+			assert.Error(t, err)
+			if name == "import cycle" && err != nil {
+				assert.True(t, strings.Contains(err.Error(), "import cycle"), err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,567 @@
+// Package rename renames the Go variable of a resource and rewrites every
+// reference to it, so refactors do not break triggers, boards and SLOs that
+// use the resource.
+package rename
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/atomicfile"
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+	"github.com/lex00/wetwire-honeycomb-go/internal/lock"
+)
+
+// LockTypes maps the resource types that can be renamed to their build
+// output and lockfile keys.
+var LockTypes = map[string]string{
+	"query":   "queries",
+	"slo":     "slos",
+	"trigger": "triggers",
+	"board":   "boards",
+}
+
+// Plan is the set of edits a rename makes.
+type Plan struct {
+	// Type is the resource type, e.g. "query"
+	Type string
+
+	// Old and New are the Go variable names
+	Old, New string
+
+	// Files are the Go files with references to rewrite, sorted by path
+	Files []FileEdit
+
+	// Lockfiles are the lockfiles with an entry for the resource
+	Lockfiles []string
+
+	// content holds the original source of each file in Files
+	content map[string][]byte
+}
+
+// FileEdit is a Go file with references to rewrite.
+type FileEdit struct {
+	File string
+
+	// Offsets are the byte offsets of the identifiers to rewrite
+	Offsets []int
+}
+
+// References returns the total number of identifiers the plan rewrites,
+// including the declaration.
+func (p *Plan) References() int {
+	n := 0
+	for _, f := range p.Files {
+		n += len(f.Offsets)
+	}
+	return n
+}
+
+// NewPlan finds the resource of type typ declared as the Go variable oldName
+// under root and every reference to it in the Go files under root, and
+// checks that it can be renamed to newName.
+func NewPlan(root, typ, oldName, newName string) (*Plan, error) {
+	typ = strings.ToLower(typ)
+	lockType, ok := LockTypes[typ]
+	if !ok {
+		return nil, fmt.Errorf("unknown resource type %q (want query, slo, trigger or board)", typ)
+	}
+	if !token.IsIdentifier(newName) {
+		return nil, fmt.Errorf("%q is not a valid Go identifier", newName)
+	}
+	if oldName == newName {
+		return nil, fmt.Errorf("%s is already named %s", typ, newName)
+	}
+
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("resolve path: %w", err)
+	}
+	resources, err := discovery.DiscoverAll(root)
+	if err != nil {
+		return nil, fmt.Errorf("discovery failed: %w", err)
+	}
+	file, line, err := findResource(resources, typ, oldName)
+	if err != nil {
+		return nil, err
+	}
+
+	decl, err := parseDecl(file, oldName, line)
+	if err != nil {
+		return nil, err
+	}
+	if decl.scope[newName] {
+		return nil, fmt.Errorf("package %s already declares %s", decl.pkg, newName)
+	}
+
+	plan := &Plan{Type: typ, Old: oldName, New: newName, content: make(map[string][]byte)}
+	external := false
+	err = filepath.WalkDir(root, func(p string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != root && skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() == lock.FileName {
+			if lf, err := lock.Read(p); err == nil {
+				if _, ok := lf.Resources[lock.Key(lockType, oldName)]; ok {
+					plan.Lockfiles = append(plan.Lockfiles, p)
+				}
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") {
+			return nil
+		}
+
+		src, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, p, src, 0)
+		if err != nil {
+			// Files that do not parse cannot reference the resource safely
+			return nil
+		}
+
+		var offsets []int
+		if filepath.Dir(p) == decl.dir && f.Name.Name == decl.pkg {
+			offsets = packageReferences(fset, f, decl, p == file)
+			if p == file && decl.docOffset >= 0 {
+				offsets = append(offsets, decl.docOffset)
+				sort.Ints(offsets)
+			}
+		} else {
+			offsets = importedReferences(fset, f, decl)
+			external = external || len(offsets) > 0
+		}
+		if len(offsets) > 0 {
+			plan.Files = append(plan.Files, FileEdit{File: p, Offsets: offsets})
+			plan.content[p] = src
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk directory: %w", err)
+	}
+
+	if external && !token.IsExported(newName) {
+		return nil, fmt.Errorf("%s is used outside package %s, so %s must be exported", oldName, decl.pkg, newName)
+	}
+	sort.Slice(plan.Files, func(i, j int) bool { return plan.Files[i].File < plan.Files[j].File })
+	return plan, nil
+}
+
+// Apply rewrites the Go files and lockfiles of the plan.
+func (p *Plan) Apply() error {
+	for _, f := range p.Files {
+		src := p.content[f.File]
+		out := rewrite(src, f.Offsets, len(p.Old), p.New)
+
+		// Keep gofmt-clean files clean, since alignment may change
+		if formatted, err := format.Source(src); err == nil && bytes.Equal(formatted, src) {
+			if formatted, err := format.Source(out); err == nil {
+				out = formatted
+			}
+		}
+		if err := atomicfile.WriteFile(f.File, out, 0644); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range p.Lockfiles {
+		lf, err := lock.Read(path)
+		if err != nil {
+			return err
+		}
+		lf.Rename(LockTypes[p.Type], p.Old, p.New)
+		if err := lock.Write(path, lf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewrite replaces the identifier of length n at each offset in src.
+func rewrite(src []byte, offsets []int, n int, name string) []byte {
+	var out bytes.Buffer
+	last := 0
+	for _, off := range offsets {
+		out.Write(src[last:off])
+		out.WriteString(name)
+		last = off + n
+	}
+	out.Write(src[last:])
+	return out.Bytes()
+}
+
+// findResource returns the file and line of the resource of type typ with Go
+// variable name name.
+func findResource(resources *discovery.DiscoveredResources, typ, name string) (string, int, error) {
+	type location struct {
+		file string
+		line int
+	}
+	var found []location
+	add := func(n, file string, line int) {
+		if n != name {
+			return
+		}
+		for _, l := range found {
+			if l.file == file && l.line == line {
+				return
+			}
+		}
+		found = append(found, location{file, line})
+	}
+
+	switch typ {
+	case "query":
+		for _, q := range resources.Queries {
+			add(q.Name, q.File, q.Line)
+		}
+	case "slo":
+		for _, s := range resources.SLOs {
+			add(s.Name, s.File, s.Line)
+		}
+	case "trigger":
+		for _, t := range resources.Triggers {
+			add(t.Name, t.File, t.Line)
+		}
+	case "board":
+		for _, b := range resources.Boards {
+			add(b.Name, b.File, b.Line)
+		}
+	}
+
+	// Inline queries are discovered under the name of the resource that
+	// contains them; only declarations on their own line are candidates
+	var candidates []location
+	for _, l := range found {
+		if _, err := parseDecl(l.file, name, l.line); err == nil {
+			candidates = append(candidates, l)
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", 0, fmt.Errorf("%s %s not found", typ, name)
+	case 1:
+		return candidates[0].file, candidates[0].line, nil
+	default:
+		files := make([]string, 0, len(candidates))
+		for _, c := range candidates {
+			files = append(files, fmt.Sprintf("%s:%d", c.file, c.line))
+		}
+		return "", 0, fmt.Errorf("%s %s is declared more than once (%s); narrow the path", typ, name, strings.Join(files, ", "))
+	}
+}
+
+// declaration is the package-level variable being renamed.
+type declaration struct {
+	name string
+	dir  string
+	pkg  string
+
+	// importPath is the package's import path, if it is in a module
+	importPath string
+
+	// offset is the byte offset of the name in the declaring file
+	offset int
+
+	// docOffset is the byte offset of the name where it starts the doc
+	// comment of the declaration, or -1
+	docOffset int
+
+	// scope holds the package-level names of the package
+	scope map[string]bool
+}
+
+// parseDecl finds the package-level variable name declared at line of file.
+func parseDecl(file, name string, line int) (*declaration, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", file, err)
+	}
+
+	decl := &declaration{name: name, dir: filepath.Dir(file), pkg: f.Name.Name, offset: -1, docOffset: -1}
+	for _, d := range f.Decls {
+		gen, ok := d.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, s := range gen.Specs {
+			spec := s.(*ast.ValueSpec)
+			for _, ident := range spec.Names {
+				if ident.Name == name && fset.Position(ident.Pos()).Line == line {
+					decl.offset = fset.Position(ident.Pos()).Offset
+
+					// A lone declaration carries its doc comment on the GenDecl
+					doc := spec.Doc
+					if doc == nil && !gen.Lparen.IsValid() {
+						doc = gen.Doc
+					}
+					decl.docOffset = docOffset(fset, doc, name)
+				}
+			}
+		}
+	}
+	if decl.offset < 0 {
+		return nil, fmt.Errorf("%s:%d: %s is not a package-level variable", file, line, name)
+	}
+
+	decl.scope, err = packageScope(decl.dir, decl.pkg)
+	if err != nil {
+		return nil, err
+	}
+	decl.importPath = importPath(decl.dir)
+	return decl, nil
+}
+
+// docOffset returns the byte offset of name where it is the first word of
+// doc, as in "// Latency is the p99 latency.", or -1.
+func docOffset(fset *token.FileSet, doc *ast.CommentGroup, name string) int {
+	if doc == nil || !strings.HasPrefix(doc.List[0].Text, "//") {
+		return -1
+	}
+	c := doc.List[0]
+	text := strings.TrimLeft(c.Text[2:], " \t")
+	if !strings.HasPrefix(text, name) {
+		return -1
+	}
+	if r, _ := utf8.DecodeRuneInString(text[len(name):]); r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+		return -1
+	}
+	return fset.Position(c.Slash).Offset + len(c.Text) - len(text)
+}
+
+// packageScope returns the package-level names declared in the files of
+// package pkg in dir.
+func packageScope(dir, pkg string) (map[string]bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := make(map[string]bool)
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), filepath.Join(dir, e.Name()), nil, parser.SkipObjectResolution)
+		if err != nil || f.Name.Name != pkg {
+			continue
+		}
+		for _, d := range f.Decls {
+			switch d := d.(type) {
+			case *ast.FuncDecl:
+				if d.Recv == nil {
+					scope[d.Name.Name] = true
+				}
+			case *ast.GenDecl:
+				for _, s := range d.Specs {
+					switch s := s.(type) {
+					case *ast.ValueSpec:
+						for _, ident := range s.Names {
+							scope[ident.Name] = true
+						}
+					case *ast.TypeSpec:
+						scope[s.Name.Name] = true
+					}
+				}
+			}
+		}
+	}
+	return scope, nil
+}
+
+// packageReferences returns the offsets of the identifiers in f, a file of
+// the declaring package, that refer to the variable.
+func packageReferences(fset *token.FileSet, f *ast.File, decl *declaration, declaring bool) []int {
+	skip := nonReferences(f)
+
+	var offsets []int
+	ast.Inspect(f, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok || ident.Name != decl.name || skip[ident] {
+			return true
+		}
+
+		// Identifiers resolve within a file; references from other files of
+		// the package are unresolved, and anything else is a local shadowing
+		// the variable
+		if declaring {
+			if ident.Obj == nil || ident.Obj.Kind != ast.Var || fset.Position(ident.Obj.Pos()).Offset != decl.offset {
+				return true
+			}
+		} else if ident.Obj != nil {
+			return true
+		}
+		offsets = append(offsets, fset.Position(ident.Pos()).Offset)
+		return true
+	})
+	sort.Ints(offsets)
+	return offsets
+}
+
+// nonReferences returns the identifiers under n that name a field, method,
+// composite literal key or label rather than referring to a declaration in
+// scope.
+func nonReferences(n ast.Node) map[*ast.Ident]bool {
+	skip := make(map[*ast.Ident]bool)
+	ast.Inspect(n, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			skip[n.Sel] = true
+		case *ast.CompositeLit:
+			if _, isMap := n.Type.(*ast.MapType); isMap {
+				break
+			}
+			for _, elt := range n.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					if key, ok := kv.Key.(*ast.Ident); ok {
+						skip[key] = true
+					}
+				}
+			}
+		case *ast.Field:
+			for _, name := range n.Names {
+				skip[name] = true
+			}
+		case *ast.FuncDecl:
+			if n.Recv != nil {
+				skip[n.Name] = true
+			}
+		case *ast.LabeledStmt:
+			skip[n.Label] = true
+		case *ast.BranchStmt:
+			if n.Label != nil {
+				skip[n.Label] = true
+			}
+		}
+		return true
+	})
+	return skip
+}
+
+// importedReferences returns the offsets of the selectors in f that refer to
+// the variable through an import of its package.
+func importedReferences(fset *token.FileSet, f *ast.File, decl *declaration) []int {
+	var offsets []int
+	for _, sel := range importedSelectors(f, decl) {
+		offsets = append(offsets, fset.Position(sel.Sel.Pos()).Offset)
+	}
+	sort.Ints(offsets)
+	return offsets
+}
+
+// importedSelectors returns the selectors in f that refer to the variable
+// through an import of its package.
+func importedSelectors(f *ast.File, decl *declaration) []*ast.SelectorExpr {
+	if decl.importPath == "" || !token.IsExported(decl.name) {
+		return nil
+	}
+
+	names := make(map[string]bool)
+	for _, imp := range f.Imports {
+		if importSpecPath(imp) != decl.importPath {
+			continue
+		}
+		if name := importName(imp, decl.pkg); name != "_" && name != "." {
+			names[name] = true
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	var selectors []*ast.SelectorExpr
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != decl.name {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); ok && x.Obj == nil && names[x.Name] {
+			selectors = append(selectors, sel)
+		}
+		return true
+	})
+	return selectors
+}
+
+// importSpecPath returns the unquoted path of an import.
+func importSpecPath(imp *ast.ImportSpec) string {
+	p, _ := strconv.Unquote(imp.Path.Value)
+	return p
+}
+
+// importName returns the name an import is referred to by, given the name
+// of the imported package.
+func importName(imp *ast.ImportSpec, pkg string) string {
+	if imp.Name != nil {
+		return imp.Name.Name
+	}
+	return pkg
+}
+
+// importPath returns the import path of the package in dir, from the
+// nearest go.mod, or "" if dir is not in a module.
+func importPath(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if module := modulePath(filepath.Join(d, "go.mod")); module != "" {
+			rel, err := filepath.Rel(d, dir)
+			if err != nil {
+				return ""
+			}
+			if rel == "." {
+				return module
+			}
+			return path.Join(module, filepath.ToSlash(rel))
+		}
+		if filepath.Dir(d) == d {
+			return ""
+		}
+	}
+}
+
+// modulePath returns the module path declared in a go.mod file.
+func modulePath(gomod string) string {
+	f, err := os.Open(gomod)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if rest, ok := strings.CutPrefix(line, "module"); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+// skipDir reports whether the walk ignores directories named name.
+func skipDir(name string) bool {
+	return name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
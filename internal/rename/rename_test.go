@@ -0,0 +1,161 @@
+package rename
+
+import (
+	"go/format"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/lock"
+)
+
+const queriesSource = `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var (
+	// Latency is the p99 request latency.
+	Latency = query.Query{
+		Dataset:      "api",
+		TimeRange:    query.Hours(2),
+		Calculations: []query.Calculation{query.P99("duration_ms")},
+	}
+	Errors = query.Query{
+		Dataset:      "api",
+		TimeRange:    query.Hours(2),
+		Calculations: []query.Calculation{query.Count()},
+	}
+)
+
+// All lists every query.
+var All = []query.Query{Latency, Errors}
+
+func shadowed() query.Query {
+	Latency := query.Query{Dataset: "other"}
+	return Latency
+}
+`
+
+const boardsSource = `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/board"
+
+var Overview = board.Board{
+	Name:   "Overview",
+	Panels: []board.Panel{board.QueryPanel(Latency)},
+}
+`
+
+const triggersSource = `package triggers
+
+import (
+	q "example.com/obs/queries"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+// SlowRequests fires when the p99 latency is over a second.
+var SlowRequests = trigger.Trigger{
+	Name:      "Slow requests",
+	Query:     q.Latency,
+	Threshold: trigger.GreaterThan(1000),
+}
+`
+
+// writeWorkspace writes a module with queries and triggers packages.
+func writeWorkspace(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	files := map[string]string{
+		"go.mod":               "module example.com/obs\n\ngo 1.23\n",
+		"queries/queries.go":   queriesSource,
+		"queries/boards.go":    boardsSource,
+		"triggers/triggers.go": triggersSource,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	lf := &lock.Lockfile{Version: lock.Version, Resources: map[string]lock.Entry{
+		"queries/Latency": {Hash: "sha256:a"},
+		"queries/Errors":  {Hash: "sha256:b"},
+	}}
+	require.NoError(t, lock.Write(filepath.Join(root, lock.FileName), lf))
+	return root
+}
+
+func readFile(t *testing.T, root, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestRename(t *testing.T) {
+	root := writeWorkspace(t)
+
+	plan, err := NewPlan(root, "Query", "Latency", "APILatency")
+	require.NoError(t, err)
+	assert.Equal(t, 5, plan.References())
+	require.Len(t, plan.Files, 3)
+	assert.Equal(t, []string{filepath.Join(root, lock.FileName)}, plan.Lockfiles)
+
+	require.NoError(t, plan.Apply())
+
+	queries := readFile(t, root, "queries/queries.go")
+	assert.Contains(t, queries, "\t// APILatency is the p99 request latency.\n\tAPILatency = query.Query{\n")
+	assert.Contains(t, queries, "var All = []query.Query{APILatency, Errors}")
+	// Locals shadowing the variable are left alone
+	assert.Contains(t, queries, "\tLatency := query.Query{Dataset: \"other\"}\n\treturn Latency\n")
+	formatted, err := format.Source([]byte(queries))
+	require.NoError(t, err)
+	assert.Equal(t, string(formatted), queries)
+
+	assert.Contains(t, readFile(t, root, "queries/boards.go"), "board.QueryPanel(APILatency)")
+	triggers := readFile(t, root, "triggers/triggers.go")
+	assert.Contains(t, triggers, "Query:     q.APILatency,")
+	assert.Contains(t, triggers, `Name:      "Slow requests",`)
+
+	lf, err := lock.Read(filepath.Join(root, lock.FileName))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"queries/APILatency", "queries/Errors"}, lf.Keys())
+}
+
+func TestRename_Errors(t *testing.T) {
+	root := writeWorkspace(t)
+
+	tests := map[string][]string{
+		"unknown type":         {"dataset", "Latency", "X"},
+		"not found":            {"query", "Missing", "X"},
+		"wrong type":           {"trigger", "Latency", "X"},
+		"invalid name":         {"query", "Latency", "1x"},
+		"same name":            {"query", "Latency", "Latency"},
+		"name in use":          {"query", "Latency", "Errors"},
+		"unexported when used": {"query", "Latency", "latency"},
+	}
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewPlan(root, args[0], args[1], args[2])
+			assert.Error(t, err)
+		})
+	}
+
+	// Nothing was written
+	assert.Equal(t, queriesSource, readFile(t, root, "queries/queries.go"))
+}
+
+func TestRename_Trigger(t *testing.T) {
+	root := writeWorkspace(t)
+
+	plan, err := NewPlan(root, "trigger", "SlowRequests", "slowRequests")
+	require.NoError(t, err)
+	assert.Equal(t, 2, plan.References())
+	assert.Empty(t, plan.Lockfiles)
+	require.NoError(t, plan.Apply())
+
+	assert.Contains(t, readFile(t, root, "triggers/triggers.go"), "// slowRequests fires when the p99 latency is over a second.\nvar slowRequests = trigger.Trigger{")
+}
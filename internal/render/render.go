@@ -0,0 +1,157 @@
+// Package render writes human-readable CLI output: severities and statuses in
+// color when writing to a terminal, and tables with aligned columns.
+package render
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// EnvNoColor disables color when set to any value, following
+// https://no-color.org.
+const EnvNoColor = "NO_COLOR"
+
+// noColor is whether color is off whatever the environment (see
+// DisableColor).
+var noColor bool
+
+// DisableColor turns color off, or with false leaves it to ColorEnabled. The
+// --no-color flag sets it.
+func DisableColor(off bool) {
+	noColor = off
+}
+
+// Color is a terminal text color.
+type Color int
+
+// Colors used for CLI output.
+const (
+	None Color = iota
+	Red
+	Green
+	Yellow
+	Cyan
+	Bold
+)
+
+// codes are the ANSI escape codes of each color.
+var codes = map[Color]string{
+	Red:    "31",
+	Green:  "32",
+	Yellow: "33",
+	Cyan:   "36",
+	Bold:   "1",
+}
+
+// IsTerminal reports whether f is a terminal rather than a pipe or file.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// ColorEnabled reports whether output to w is colored: w is a terminal,
+// color is not disabled, EnvNoColor is unset and TERM is not "dumb".
+func ColorEnabled(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok || !IsTerminal(f) || noColor {
+		return false
+	}
+	if _, ok := os.LookupEnv(EnvNoColor); ok {
+		return false
+	}
+	return os.Getenv("TERM") != "dumb"
+}
+
+// SeverityColor returns the color of a lint severity: red for errors, yellow
+// for warnings and cyan for info.
+func SeverityColor(severity string) Color {
+	switch severity {
+	case "error":
+		return Red
+	case "warning":
+		return Yellow
+	case "info":
+		return Cyan
+	}
+	return None
+}
+
+// Renderer writes output to a writer, in color when it is enabled.
+type Renderer struct {
+	w     io.Writer
+	color bool
+}
+
+// New returns a Renderer for w, coloring output when ColorEnabled(w).
+func New(w io.Writer) *Renderer {
+	return &Renderer{w: w, color: ColorEnabled(w)}
+}
+
+// NewColor returns a Renderer for w that colors output when color is true.
+func NewColor(w io.Writer, color bool) *Renderer {
+	return &Renderer{w: w, color: color}
+}
+
+// Paint returns s in c, or s unchanged when color is disabled.
+func (r *Renderer) Paint(c Color, s string) string {
+	code, ok := codes[c]
+	if !r.color || !ok || s == "" {
+		return s
+	}
+	return "\x1b[" + code + "m" + s + "\x1b[0m"
+}
+
+// Printf writes formatted output.
+func (r *Renderer) Printf(format string, args ...any) {
+	fmt.Fprintf(r.w, format, args...)
+}
+
+// Cell is a table cell.
+type Cell struct {
+	Text  string
+	Color Color
+}
+
+// Plain returns uncolored cells of texts.
+func Plain(texts ...string) []Cell {
+	cells := make([]Cell, len(texts))
+	for i, text := range texts {
+		cells[i] = Cell{Text: text}
+	}
+	return cells
+}
+
+// Table writes rows after indent, padding each cell but the last of its row
+// to the widest cell of its column and separating cells by two spaces. The
+// last cell of a row does not widen its column, so a short row can carry a
+// long note. Widths are in terminal cells (see Width), so CJK text stays
+// aligned. Colors are applied after padding, so escape codes do not affect
+// alignment.
+func (r *Renderer) Table(indent string, rows [][]Cell) {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row[:max(len(row)-1, 0)] {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], Width(cell.Text))
+		}
+	}
+
+	for _, row := range rows {
+		var sb strings.Builder
+		sb.WriteString(indent)
+		for i, cell := range row {
+			if i > 0 {
+				sb.WriteString("  ")
+			}
+			sb.WriteString(r.Paint(cell.Color, cell.Text))
+			if i < len(row)-1 {
+				sb.WriteString(strings.Repeat(" ", widths[i]-Width(cell.Text)))
+			}
+		}
+		fmt.Fprintln(r.w, strings.TrimRight(sb.String(), " "))
+	}
+}
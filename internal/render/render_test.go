@@ -0,0 +1,89 @@
+package render
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaint(t *testing.T) {
+	var out bytes.Buffer
+	assert.Equal(t, "error", NewColor(&out, false).Paint(Red, "error"))
+
+	r := NewColor(&out, true)
+	assert.Equal(t, "\x1b[31merror\x1b[0m", r.Paint(Red, "error"))
+	assert.Equal(t, "plain", r.Paint(None, "plain"))
+	assert.Equal(t, "", r.Paint(Red, ""))
+}
+
+func TestColorEnabled(t *testing.T) {
+	assert.False(t, ColorEnabled(&bytes.Buffer{}), "buffers are not terminals")
+
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	if assert.NoError(t, err) {
+		defer f.Close()
+		assert.False(t, ColorEnabled(f), "files are not terminals")
+	}
+}
+
+func TestSeverityColor(t *testing.T) {
+	assert.Equal(t, Red, SeverityColor("error"))
+	assert.Equal(t, Yellow, SeverityColor("warning"))
+	assert.Equal(t, Cyan, SeverityColor("info"))
+	assert.Equal(t, None, SeverityColor(""))
+}
+
+func TestTable(t *testing.T) {
+	var out bytes.Buffer
+	NewColor(&out, false).Table("  ", [][]Cell{
+		Plain("TYPE", "NAME", "FILE"),
+		Plain("query", "Latency", "queries.go"),
+		Plain("trigger", "HighErrors", ""),
+		Plain("a note longer than any column"),
+	})
+
+	assert.Equal(t, ""+
+		"  TYPE     NAME        FILE\n"+
+		"  query    Latency     queries.go\n"+
+		"  trigger  HighErrors\n"+
+		"  a note longer than any column\n", out.String())
+}
+
+func TestTable_ColorDoesNotAffectAlignment(t *testing.T) {
+	var out bytes.Buffer
+	NewColor(&out, true).Table("", [][]Cell{
+		{{Text: "error", Color: Red}, {Text: "x"}},
+		{{Text: "info", Color: Cyan}, {Text: "y"}},
+	})
+
+	assert.Equal(t, ""+
+		"\x1b[31merror\x1b[0m  x\n"+
+		"\x1b[36minfo\x1b[0m   y\n", out.String())
+}
+
+func TestWidth(t *testing.T) {
+	assert.Equal(t, 0, Width(""))
+	assert.Equal(t, 7, Width("Latency"))
+	assert.Equal(t, 6, Width("クエリ"))
+	assert.Equal(t, 4, Width("ＡＢ"), "fullwidth forms")
+	assert.Equal(t, 4, Width("한국"))
+	assert.Equal(t, 4, Width("café"), "precomposed")
+	assert.Equal(t, 4, Width("cafe\u0301"), "combining accent")
+	assert.Equal(t, 2, Width("🔥"))
+}
+
+func TestTable_Japanese(t *testing.T) {
+	var out bytes.Buffer
+	NewColor(&out, false).Table("  ", [][]Cell{
+		Plain("種類", "名前", "ファイル"),
+		Plain("query", "レイテンシ", "queries.go"),
+		Plain("トリガー", "HighErrors", "triggers.go"),
+	})
+
+	assert.Equal(t, ""+
+		"  種類      名前        ファイル\n"+
+		"  query     レイテンシ  queries.go\n"+
+		"  トリガー  HighErrors  triggers.go\n", out.String())
+}
@@ -0,0 +1,36 @@
+package roundtrip
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+		actual   string
+		wantErr  string
+	}{
+		{"equal", `{"a": 1, "b": ["x"]}`, `{"b":["x"],"a":1.0}`, ""},
+		{"empty values ignored", `{"a": 1, "b": []}`, `{"a": 1, "c": null}`, ""},
+		{"changed value", `{"a": {"b": "x"}}`, `{"a": {"b": "y"}}`, `at "a.b"`},
+		{"missing key", `{"a": 1, "b": 2}`, `{"a": 1}`, `missing key "b"`},
+		{"arrays", `[1, 2]`, `[1, 3]`, `at "$[1]"`},
+		{"invalid", `{`, `{}`, "invalid expected JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CompareJSON([]byte(tt.expected), []byte(tt.actual))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
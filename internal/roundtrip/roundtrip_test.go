@@ -5,12 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"reflect"
 	"testing"
 
 	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
 	"github.com/lex00/wetwire-honeycomb-go/internal/serialize"
-	"github.com/lex00/wetwire-honeycomb-go/query"
 )
 
 // TestRoundTrip_BasicQuery tests round-trip conversion for a basic query.
@@ -65,7 +63,7 @@ func testRoundTrip(t *testing.T, fixtureFile string) {
 	discoveredQuery := queries[0]
 
 	// 5. Convert discovered query to query.Query
-	q := discoveredToQuery(discoveredQuery)
+	q := discoveredQuery.Query()
 
 	// 6. Build back to JSON
 	generatedJSON, err := serialize.ToJSON(q)
@@ -80,243 +78,13 @@ func testRoundTrip(t *testing.T, fixtureFile string) {
 	}
 
 	// 7. Compare original and generated JSON semantically
-	if err := compareJSON(originalData, generatedData); err != nil {
+	if err := CompareMaps(originalData, generatedData); err != nil {
 		t.Errorf("Round-trip comparison failed for %s:\n%v", fixtureFile, err)
 		t.Logf("Original JSON:\n%s", string(originalJSON))
 		t.Logf("Generated JSON:\n%s", string(generatedJSON))
 	}
 }
 
-// compareJSON performs semantic comparison of two JSON objects.
-// It ignores whitespace, key ordering, and float vs int differences.
-func compareJSON(expected, actual map[string]interface{}) error {
-	// Normalize both maps
-	normalizeMap(expected)
-	normalizeMap(actual)
-
-	// Compare each key in expected
-	for key, expectedVal := range expected {
-		actualVal, exists := actual[key]
-		if !exists {
-			return fmt.Errorf("missing key %q in actual JSON", key)
-		}
-
-		if err := compareValues(key, expectedVal, actualVal); err != nil {
-			return err
-		}
-	}
-
-	// Check for extra keys in actual
-	for key := range actual {
-		if _, exists := expected[key]; !exists {
-			return fmt.Errorf("unexpected key %q in actual JSON", key)
-		}
-	}
-
-	return nil
-}
-
-// compareValues compares two values semantically.
-func compareValues(path string, expected, actual interface{}) error {
-	// Normalize values
-	expected = normalizeValue(expected)
-	actual = normalizeValue(actual)
-
-	// Handle nil
-	if expected == nil && actual == nil {
-		return nil
-	}
-	if expected == nil || actual == nil {
-		return fmt.Errorf("at %q: expected %v, got %v", path, expected, actual)
-	}
-
-	// Get types
-	expectedType := reflect.TypeOf(expected)
-	actualType := reflect.TypeOf(actual)
-
-	// Handle numeric comparisons (allow float64 vs int)
-	if isNumeric(expected) && isNumeric(actual) {
-		if !numericEqual(expected, actual) {
-			return fmt.Errorf("at %q: expected %v, got %v", path, expected, actual)
-		}
-		return nil
-	}
-
-	// Types must match for non-numeric values
-	if expectedType != actualType {
-		return fmt.Errorf("at %q: type mismatch - expected %T, got %T", path, expected, actual)
-	}
-
-	// Compare based on type
-	switch exp := expected.(type) {
-	case map[string]interface{}:
-		act := actual.(map[string]interface{})
-		for key, val := range exp {
-			actVal, exists := act[key]
-			if !exists {
-				return fmt.Errorf("at %q: missing key %q", path, key)
-			}
-			if err := compareValues(path+"."+key, val, actVal); err != nil {
-				return err
-			}
-		}
-		// Check for extra keys
-		for key := range act {
-			if _, exists := exp[key]; !exists {
-				return fmt.Errorf("at %q: unexpected key %q", path, key)
-			}
-		}
-
-	case []interface{}:
-		act := actual.([]interface{})
-		if len(exp) != len(act) {
-			return fmt.Errorf("at %q: array length mismatch - expected %d, got %d", path, len(exp), len(act))
-		}
-		for i := range exp {
-			if err := compareValues(fmt.Sprintf("%s[%d]", path, i), exp[i], act[i]); err != nil {
-				return err
-			}
-		}
-
-	case string:
-		if exp != actual.(string) {
-			return fmt.Errorf("at %q: expected %q, got %q", path, exp, actual)
-		}
-
-	case bool:
-		if exp != actual.(bool) {
-			return fmt.Errorf("at %q: expected %v, got %v", path, exp, actual)
-		}
-
-	default:
-		if !reflect.DeepEqual(expected, actual) {
-			return fmt.Errorf("at %q: expected %v, got %v", path, expected, actual)
-		}
-	}
-
-	return nil
-}
-
-// normalizeMap normalizes a map by removing omitempty fields with zero values.
-func normalizeMap(m map[string]interface{}) {
-	for key, val := range m {
-		switch v := val.(type) {
-		case map[string]interface{}:
-			normalizeMap(v)
-			// Remove empty maps
-			if len(v) == 0 {
-				delete(m, key)
-			}
-		case []interface{}:
-			if len(v) == 0 {
-				delete(m, key)
-			} else {
-				for _, item := range v {
-					if itemMap, ok := item.(map[string]interface{}); ok {
-						normalizeMap(itemMap)
-					}
-				}
-			}
-		case nil:
-			delete(m, key)
-		}
-	}
-}
-
-// normalizeValue normalizes a value for comparison.
-func normalizeValue(v interface{}) interface{} {
-	switch val := v.(type) {
-	case float64:
-		// If the float is actually an integer, keep it as float64 for consistent comparison
-		return val
-	case map[string]interface{}:
-		normalizeMap(val)
-		return val
-	default:
-		return val
-	}
-}
-
-// isNumeric checks if a value is numeric.
-func isNumeric(v interface{}) bool {
-	switch v.(type) {
-	case int, int8, int16, int32, int64:
-		return true
-	case uint, uint8, uint16, uint32, uint64:
-		return true
-	case float32, float64:
-		return true
-	}
-	return false
-}
-
-// numericEqual compares two numeric values.
-func numericEqual(a, b interface{}) bool {
-	// Convert both to float64 for comparison
-	var aFloat, bFloat float64
-
-	switch v := a.(type) {
-	case int:
-		aFloat = float64(v)
-	case int64:
-		aFloat = float64(v)
-	case float64:
-		aFloat = v
-	}
-
-	switch v := b.(type) {
-	case int:
-		bFloat = float64(v)
-	case int64:
-		bFloat = float64(v)
-	case float64:
-		bFloat = v
-	}
-
-	return aFloat == bFloat
-}
-
-// discoveredToQuery converts a DiscoveredQuery to a query.Query.
-func discoveredToQuery(dq discovery.DiscoveredQuery) query.Query {
-	q := query.Query{
-		Dataset: dq.Dataset,
-		TimeRange: query.TimeRange{
-			TimeRange: dq.TimeRange.TimeRange,
-			StartTime: dq.TimeRange.StartTime,
-			EndTime:   dq.TimeRange.EndTime,
-		},
-		Breakdowns:        dq.Breakdowns,
-		FilterCombination: dq.FilterCombination,
-		Limit:             dq.Limit,
-		Granularity:       dq.Granularity,
-	}
-
-	for _, c := range dq.Calculations {
-		q.Calculations = append(q.Calculations, query.Calculation{
-			Op:     c.Op,
-			Column: c.Column,
-		})
-	}
-
-	for _, f := range dq.Filters {
-		q.Filters = append(q.Filters, query.Filter{
-			Column: f.Column,
-			Op:     f.Op,
-			Value:  f.Value,
-		})
-	}
-
-	for _, o := range dq.Orders {
-		q.Orders = append(q.Orders, query.Order{
-			Column: o.Column,
-			Op:     o.Op,
-			Order:  o.Order,
-		})
-	}
-
-	return q
-}
-
 // generateGoCode generates Go code from a parsed query JSON.
 // This is a copy of the logic from cmd/wetwire-honeycomb/main.go.
 func generateGoCode(pkg, name string, raw map[string]interface{}) string {
@@ -0,0 +1,269 @@
+// Package scoring scores the resources an agent generated in a scenario run
+// against the scenario's expected resources. Rather than counting resources,
+// it pairs each expected resource with a generated one and awards a point
+// for each key field the semantic differ finds equal: datasets,
+// calculations, thresholds, SLO targets and so on.
+package scoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lex00/wetwire-core-go/agent/scoring"
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/honeycomb"
+	"github.com/lex00/wetwire-honeycomb-go/internal/differ"
+)
+
+// Check is a key field of a resource type, scored as a point.
+type Check struct {
+	// Name is shown in reports, e.g. "threshold"
+	Name string
+
+	// Fields are the JSON fields of the check; a difference in any of
+	// them, or in a value nested under one, fails it
+	Fields []string
+}
+
+// Checks are the key fields scored for each resource type, besides the
+// point for the resource existing. Queries are compared with their
+// dataset added under "dataset".
+var Checks = map[string][]Check{
+	"query": {
+		{Name: "dataset", Fields: []string{"dataset"}},
+		{Name: "calculations", Fields: []string{"calculations"}},
+		{Name: "filters", Fields: []string{"filters", "filter_combination"}},
+		{Name: "breakdowns", Fields: []string{"breakdowns"}},
+		{Name: "time range", Fields: []string{"time_range", "granularity"}},
+	},
+	"slo": {
+		{Name: "dataset", Fields: []string{"dataset", "dataset_slugs"}},
+		{Name: "target", Fields: []string{"target_per_million"}},
+		{Name: "time period", Fields: []string{"time_period_days"}},
+	},
+	"trigger": {
+		{Name: "dataset", Fields: []string{"dataset"}},
+		{Name: "threshold", Fields: []string{"threshold"}},
+		{Name: "frequency", Fields: []string{"frequency"}},
+		{Name: "alert type", Fields: []string{"alert_type"}},
+	},
+	"board": {
+		{Name: "panels", Fields: []string{"panels"}},
+	},
+}
+
+// sections are the build output sections of each resource type, in
+// scoring order.
+var sections = []struct{ section, typ string }{
+	{honeycomb.SectionQueries, "query"},
+	{honeycomb.SectionSLOs, "slo"},
+	{honeycomb.SectionTriggers, "trigger"},
+	{honeycomb.SectionBoards, "board"},
+}
+
+// CheckResult is the outcome of a check on a pair of resources.
+type CheckResult struct {
+	Name  string `json:"name"`
+	Match bool   `json:"match"`
+
+	// Changes are the differences found by the differ, e.g.
+	// "threshold.value: 1 -> 5"
+	Changes []string `json:"changes,omitempty"`
+}
+
+// ResourceScore is the score of an expected resource.
+type ResourceScore struct {
+	Type     string `json:"type"`
+	Expected string `json:"expected"`
+
+	// Actual is the generated resource paired with Expected, "" if none
+	Actual string `json:"actual,omitempty"`
+
+	Points    int           `json:"points"`
+	MaxPoints int           `json:"max_points"`
+	Checks    []CheckResult `json:"checks,omitempty"`
+}
+
+// Result is the score of a scenario run.
+type Result struct {
+	Points    int             `json:"points"`
+	MaxPoints int             `json:"max_points"`
+	Resources []ResourceScore `json:"resources"`
+
+	// Extra are the generated resources paired with no expected one, as
+	// "type/name"
+	Extra []string `json:"extra,omitempty"`
+}
+
+// Ratio returns the share of the points awarded, 1 when none were possible.
+func (r *Result) Ratio() float64 {
+	if r.MaxPoints == 0 {
+		return 1
+	}
+	return float64(r.Points) / float64(r.MaxPoints)
+}
+
+// Rating returns the rating of the result on the agent scoring scale, for
+// the completeness dimension of a session score.
+func (r *Result) Rating() (scoring.Rating, string) {
+	notes := fmt.Sprintf("%d/%d artifact points", r.Points, r.MaxPoints)
+	switch ratio := r.Ratio(); {
+	case ratio >= 0.95:
+		return scoring.RatingExcellent, notes
+	case ratio >= 0.75:
+		return scoring.RatingGood, notes
+	case ratio >= 0.5:
+		return scoring.RatingPartial, notes
+	default:
+		return scoring.RatingNone, notes
+	}
+}
+
+// String returns a report of the result: the total, then each expected
+// resource that lost points and the checks it failed.
+func (r *Result) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Artifact score: %d/%d (%.0f%%)\n", r.Points, r.MaxPoints, 100*r.Ratio())
+	for _, res := range r.Resources {
+		switch {
+		case res.Actual == "":
+			fmt.Fprintf(&b, "  %s %s: missing\n", res.Type, res.Expected)
+		case res.Points < res.MaxPoints:
+			fmt.Fprintf(&b, "  %s %s (generated as %s): %d/%d\n", res.Type, res.Expected, res.Actual, res.Points, res.MaxPoints)
+			for _, c := range res.Checks {
+				for _, change := range c.Changes {
+					fmt.Fprintf(&b, "    %s\n", change)
+				}
+			}
+		}
+	}
+	for _, extra := range r.Extra {
+		fmt.Fprintf(&b, "  unexpected %s\n", extra)
+	}
+	return b.String()
+}
+
+// Compare scores the generated resources of actual against those of
+// expected, both build outputs: JSON objects with "queries", "slos",
+// "triggers" and "boards" sections keyed by Go variable name. An expected
+// resource is paired with the generated resource of the same type and name,
+// or else with the unpaired generated resource of its type that scores
+// highest. Each resource is worth a point for existing and one per check of
+// its type.
+func Compare(expected, actual []byte) (*Result, error) {
+	want, err := honeycomb.Unmarshal(expected)
+	if err != nil {
+		return nil, fmt.Errorf("parse expected resources: %w", err)
+	}
+	got, err := honeycomb.Unmarshal(actual)
+	if err != nil {
+		return nil, fmt.Errorf("parse generated resources: %w", err)
+	}
+
+	result := &Result{Resources: []ResourceScore{}}
+	for _, s := range sections {
+		wanted, generated := want.Section(s.section), got.Section(s.section)
+		paired := make(map[string]bool)
+		var unmatched []string
+		for _, name := range sortedKeys(wanted) {
+			if _, ok := generated[name]; !ok {
+				unmatched = append(unmatched, name)
+				continue
+			}
+			paired[name] = true
+			result.add(score(s.section, s.typ, name, name, wanted[name], generated[name]))
+		}
+
+		for _, name := range unmatched {
+			best := ResourceScore{Type: s.typ, Expected: name, MaxPoints: 1 + len(Checks[s.typ])}
+			for _, candidate := range sortedKeys(generated) {
+				if paired[candidate] {
+					continue
+				}
+				if rs := score(s.section, s.typ, name, candidate, wanted[name], generated[candidate]); rs.Points > best.Points {
+					best = rs
+				}
+			}
+			if best.Actual != "" {
+				paired[best.Actual] = true
+			}
+			result.add(best)
+		}
+
+		for _, name := range sortedKeys(generated) {
+			if !paired[name] {
+				result.Extra = append(result.Extra, s.typ+"/"+name)
+			}
+		}
+	}
+	return result, nil
+}
+
+// add records the score of an expected resource.
+func (r *Result) add(rs ResourceScore) {
+	r.Resources = append(r.Resources, rs)
+	r.Points += rs.Points
+	r.MaxPoints += rs.MaxPoints
+}
+
+// score scores the generated resource actualName against the expected
+// resource expectedName with the differ.
+func score(section, typ, expectedName, actualName string, expected, actual json.RawMessage) ResourceScore {
+	rs := ResourceScore{Type: typ, Expected: expectedName, Actual: actualName, Points: 1, MaxPoints: 1 + len(Checks[typ])}
+
+	// Compare both under one name, so the differ pairs them
+	wrap := func(raw json.RawMessage) []byte {
+		m := &honeycomb.Manifest{}
+		m.SetSection(section, map[string]json.RawMessage{expectedName: raw})
+		data, _ := m.Marshal()
+		return data
+	}
+	diff, err := differ.NewWithOptions(differ.Options{Normalize: true}).DiffData(wrap(expected), wrap(actual), coredomain.DiffOpts{IgnoreOrder: true})
+	var changes []string
+	if err != nil {
+		changes = []string{err.Error()}
+	} else {
+		for _, entry := range diff.Entries {
+			changes = append(changes, entry.Changes...)
+		}
+	}
+
+	for _, check := range Checks[typ] {
+		cr := CheckResult{Name: check.Name, Match: true}
+		for _, change := range changes {
+			if err != nil || touches(change, check.Fields) {
+				cr.Match = false
+				cr.Changes = append(cr.Changes, change)
+			}
+		}
+		if cr.Match {
+			rs.Points++
+		}
+		rs.Checks = append(rs.Checks, cr)
+	}
+	return rs
+}
+
+// touches reports whether a differ change, "path: old -> new", is to one
+// of fields or a value under it.
+func touches(change string, fields []string) bool {
+	path, _, _ := strings.Cut(change, ":")
+	for _, field := range fields {
+		if path == field || strings.HasPrefix(path, field+".") || strings.HasPrefix(path, field+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,110 @@
+package scoring
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lex00/wetwire-core-go/agent/scoring"
+)
+
+const expectedOutput = `{
+	"queries": {
+		"Latency": {"dataset": "api", "time_range": 7200, "calculations": [{"op": "P99", "column": "duration_ms"}], "breakdowns": ["endpoint"]},
+		"Errors": {"dataset": "api", "time_range": 3600, "calculations": [{"op": "COUNT"}], "filters": [{"column": "status_code", "op": ">=", "value": 500}]}
+	},
+	"slos": {
+		"Availability": {"name": "Availability", "dataset": "api", "target_per_million": 999000, "time_period_days": 30}
+	},
+	"triggers": {
+		"HighLatency": {"name": "High Latency", "dataset": "api", "threshold": {"op": ">", "value": 500}, "frequency": 300}
+	}
+}`
+
+func TestCompare_Identical(t *testing.T) {
+	result, err := Compare([]byte(expectedOutput), []byte(expectedOutput))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2 queries of 6 points, an SLO of 4 and a trigger of 5
+	if result.Points != 21 || result.MaxPoints != 21 || len(result.Extra) != 0 {
+		t.Errorf("expected 21/21, got %d/%d, extra %v", result.Points, result.MaxPoints, result.Extra)
+	}
+	if rating, _ := result.Rating(); rating != scoring.RatingExcellent {
+		t.Errorf("expected an excellent rating, got %v", rating)
+	}
+}
+
+func TestCompare_Differences(t *testing.T) {
+	actual := `{
+		"queries": {
+			"Latency": {"dataset": "prod", "time_range": 7200, "calculations": [{"op": "P99", "column": "duration_ms"}], "breakdowns": ["endpoint"]},
+			"ServerErrors": {"dataset": "api", "time_range": 3600, "calculations": [{"op": "COUNT"}], "filters": [{"column": "status_code", "op": ">=", "value": 500}]},
+			"Throughput": {"dataset": "api", "time_range": 3600, "calculations": [{"op": "COUNT"}]}
+		},
+		"slos": {
+			"APIAvailability": {"name": "API Availability", "dataset": "api", "target_per_million": 995000, "time_period_days": 30}
+		},
+		"triggers": {
+			"HighLatency": {"name": "High Latency", "dataset": "api", "threshold": {"op": ">", "value": 1000}, "frequency": 300}
+		}
+	}`
+	result, err := Compare([]byte(expectedOutput), []byte(actual))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	byName := make(map[string]ResourceScore)
+	for _, rs := range result.Resources {
+		byName[rs.Expected] = rs
+	}
+	tests := []struct {
+		expected, actual string
+		points           int
+		failed           string
+	}{
+		{"Latency", "Latency", 5, "dataset"},
+		// Paired by content rather than by name, over Throughput
+		{"Errors", "ServerErrors", 6, ""},
+		{"Availability", "APIAvailability", 3, "target"},
+		{"HighLatency", "HighLatency", 4, "threshold"},
+	}
+	for _, tt := range tests {
+		rs := byName[tt.expected]
+		if rs.Actual != tt.actual || rs.Points != tt.points {
+			t.Errorf("%s: expected %s with %d points, got %s with %d", tt.expected, tt.actual, tt.points, rs.Actual, rs.Points)
+		}
+		for _, c := range rs.Checks {
+			if !c.Match && c.Name != tt.failed {
+				t.Errorf("%s: unexpected failed check %s: %v", tt.expected, c.Name, c.Changes)
+			}
+		}
+	}
+	if len(result.Extra) != 1 || result.Extra[0] != "query/Throughput" {
+		t.Errorf("expected Throughput to be extra, got %v", result.Extra)
+	}
+	if !strings.Contains(result.String(), "threshold.value: 500 -> 1000") {
+		t.Errorf("expected the threshold change in the report:\n%s", result)
+	}
+}
+
+func TestCompare_Missing(t *testing.T) {
+	result, err := Compare([]byte(expectedOutput), []byte(`{"queries": {}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Points != 0 || result.MaxPoints != 21 {
+		t.Errorf("expected 0/21, got %d/%d", result.Points, result.MaxPoints)
+	}
+	if rating, _ := result.Rating(); rating != scoring.RatingNone {
+		t.Errorf("expected no rating, got %v", rating)
+	}
+	if !strings.Contains(result.String(), "slo Availability: missing") {
+		t.Errorf("expected the missing SLO in the report:\n%s", result)
+	}
+}
+
+func TestCompare_InvalidJSON(t *testing.T) {
+	if _, err := Compare([]byte(expectedOutput), []byte("not json")); err == nil {
+		t.Error("expected an error")
+	}
+}
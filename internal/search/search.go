@@ -0,0 +1,235 @@
+// Package search finds discovered resources whose metadata contains a term.
+package search
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+// Resource types a search can be restricted to.
+const (
+	TypeQuery   = "query"
+	TypeSLO     = "slo"
+	TypeTrigger = "trigger"
+	TypeBoard   = "board"
+)
+
+// Types lists the searchable resource types.
+var Types = []string{TypeQuery, TypeSLO, TypeTrigger, TypeBoard}
+
+// Result is a resource with at least one matching field.
+type Result struct {
+	Type    string  `json:"type"`
+	Name    string  `json:"name"`
+	File    string  `json:"file"`
+	Line    int     `json:"line"`
+	Matches []Match `json:"matches"`
+}
+
+// Match is a field of a resource whose value contains the term.
+type Match struct {
+	// Field names the metadata field, e.g. "dataset" or "filter value"
+	Field string `json:"field"`
+
+	// Value is the field value, or for a multi-line value such as a text
+	// panel, the first line containing the term
+	Value string `json:"value"`
+}
+
+// Options configure a search.
+type Options struct {
+	// Types restricts the search to these resource types (all when empty)
+	Types []string
+
+	// CaseSensitive disables case folding of the term and values
+	CaseSensitive bool
+}
+
+// Search returns the resources with names, datasets, columns, filter values,
+// descriptions or other metadata containing term, sorted by file and line.
+// Inline queries of triggers, SLOs and boards are discovered as queries of
+// their own, so they are searched as queries.
+func Search(resources *discovery.DiscoveredResources, term string, opts Options) ([]Result, error) {
+	for _, t := range opts.Types {
+		if !isType(t) {
+			return nil, fmt.Errorf("unknown resource type %q (want one of %s)", t, strings.Join(Types, ", "))
+		}
+	}
+
+	s := &searcher{term: term, opts: opts}
+	if s.wants(TypeQuery) {
+		seen := make(map[string]bool)
+		for _, q := range resources.Queries {
+			key := fmt.Sprintf("%s:%d:%s", q.File, q.Line, q.Name)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			s.add(TypeQuery, q.Name, q.File, q.Line, queryFields(q))
+		}
+	}
+	if s.wants(TypeSLO) {
+		for _, slo := range resources.SLOs {
+			s.add(TypeSLO, slo.Name, slo.File, slo.Line, sloFields(slo))
+		}
+	}
+	if s.wants(TypeTrigger) {
+		for _, t := range resources.Triggers {
+			s.add(TypeTrigger, t.Name, t.File, t.Line, triggerFields(t))
+		}
+	}
+	if s.wants(TypeBoard) {
+		for _, b := range resources.Boards {
+			s.add(TypeBoard, b.Name, b.File, b.Line, boardFields(b))
+		}
+	}
+
+	sort.SliceStable(s.results, func(i, j int) bool {
+		if s.results[i].File != s.results[j].File {
+			return s.results[i].File < s.results[j].File
+		}
+		return s.results[i].Line < s.results[j].Line
+	})
+	return s.results, nil
+}
+
+// searcher collects the results of one search.
+type searcher struct {
+	term    string
+	opts    Options
+	results []Result
+}
+
+// wants reports whether resources of type typ are searched.
+func (s *searcher) wants(typ string) bool {
+	if len(s.opts.Types) == 0 {
+		return true
+	}
+	for _, t := range s.opts.Types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// add records the resource if any of its fields match.
+func (s *searcher) add(typ, name, file string, line int, fields []Match) {
+	var matches []Match
+	for _, f := range fields {
+		if f.Value == "" || !s.contains(f.Value) {
+			continue
+		}
+		for _, text := range strings.Split(f.Value, "\n") {
+			if s.contains(text) {
+				matches = append(matches, Match{Field: f.Field, Value: strings.TrimSpace(text)})
+				break
+			}
+		}
+	}
+	if len(matches) > 0 {
+		s.results = append(s.results, Result{Type: typ, Name: name, File: file, Line: line, Matches: matches})
+	}
+}
+
+// contains reports whether value contains the term.
+func (s *searcher) contains(value string) bool {
+	if s.opts.CaseSensitive {
+		return strings.Contains(value, s.term)
+	}
+	return strings.Contains(strings.ToLower(value), strings.ToLower(s.term))
+}
+
+// queryFields returns the searchable metadata of a query.
+func queryFields(q discovery.DiscoveredQuery) []Match {
+	fields := []Match{{"name", q.Name}, {"dataset", q.Dataset}}
+	for _, b := range q.Breakdowns {
+		fields = append(fields, Match{"breakdown", b})
+	}
+	for _, c := range q.Calculations {
+		fields = append(fields, Match{"calculation column", c.Column}, Match{"calculation alias", c.Alias})
+	}
+	fields = append(fields, filterFields(q.Filters)...)
+	for _, o := range q.Orders {
+		fields = append(fields, Match{"order column", o.Column})
+	}
+	return fields
+}
+
+// filterFields returns the searchable columns and values of filters.
+func filterFields(filters []discovery.Filter) []Match {
+	var fields []Match
+	for _, f := range filters {
+		fields = append(fields, Match{"filter column", f.Column})
+		if f.Value != nil {
+			fields = append(fields, Match{"filter value", fmt.Sprint(f.Value)})
+		}
+	}
+	return fields
+}
+
+// sloFields returns the searchable metadata of an SLO.
+func sloFields(slo discovery.DiscoveredSLO) []Match {
+	fields := []Match{
+		{"name", slo.Name},
+		{"display name", slo.SLOName},
+		{"description", slo.Description},
+		{"dataset", slo.Dataset},
+		{"good events query", slo.GoodEventsQueryRef},
+		{"total events query", slo.TotalEventsQueryRef},
+	}
+	for _, a := range slo.BurnAlerts {
+		fields = append(fields, Match{"burn alert", a.Name})
+	}
+	return fields
+}
+
+// triggerFields returns the searchable metadata of a trigger.
+func triggerFields(t discovery.DiscoveredTrigger) []Match {
+	fields := []Match{
+		{"name", t.Name},
+		{"display name", t.TriggerName},
+		{"description", t.Description},
+		{"dataset", t.Dataset},
+		{"query", t.QueryRef},
+	}
+	for _, r := range t.Recipients {
+		fields = append(fields, Match{"recipient", r.Target})
+	}
+	return fields
+}
+
+// boardFields returns the searchable metadata of a board.
+func boardFields(b discovery.DiscoveredBoard) []Match {
+	fields := []Match{
+		{"name", b.Name},
+		{"display name", b.BoardName},
+		{"description", b.Description},
+	}
+	for _, p := range b.Panels {
+		fields = append(fields,
+			Match{"panel title", p.Title},
+			Match{"panel query", p.QueryRef},
+			Match{"panel text", p.Content},
+			Match{"panel SLO", p.SLOID},
+		)
+	}
+	for _, s := range b.Sections {
+		fields = append(fields, Match{"section", s.Title})
+	}
+	fields = append(fields, filterFields(b.PresetFilters)...)
+	return fields
+}
+
+// isType reports whether t is a searchable resource type.
+func isType(t string) bool {
+	for _, valid := range Types {
+		if t == valid {
+			return true
+		}
+	}
+	return false
+}
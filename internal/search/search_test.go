@@ -0,0 +1,101 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/discover"
+)
+
+func testResources() *discovery.DiscoveredResources {
+	latency := discovery.DiscoveredQuery{
+		Name:         "CheckoutLatency",
+		File:         "queries.go",
+		Line:         5,
+		Dataset:      "checkout",
+		Breakdowns:   []string{"endpoint"},
+		Calculations: []discovery.Calculation{{Op: "P99", Column: "duration_ms"}},
+		Filters:      []discovery.Filter{{Column: "service.name", Op: "=", Value: "checkout-api"}},
+	}
+	return &discovery.DiscoveredResources{
+		// Inline queries can be discovered more than once
+		Queries: []discovery.DiscoveredQuery{latency, latency},
+		SLOs: []discovery.DiscoveredSLO{
+			{Name: "Availability", File: "slos.go", Line: 3, Description: "Checkout succeeds", Dataset: "checkout"},
+		},
+		Triggers: []discovery.DiscoveredTrigger{
+			{Name: "Errors", File: "triggers.go", Line: 8, Dataset: "api", QueryRef: "ErrorCount",
+				Recipients: []discovery.DiscoveredRecipient{{Type: "slack", Target: "#checkout-oncall"}}},
+		},
+		Boards: []discovery.DiscoveredBoard{
+			{Name: "Overview", File: "boards.go", Line: 2, Panels: []discovery.DiscoveredPanel{{Type: "query", QueryRef: "CheckoutLatency"}}},
+		},
+	}
+}
+
+func TestSearch(t *testing.T) {
+	results, err := Search(testResources(), "checkout", Options{})
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	// Sorted by file
+	assert.Equal(t, []string{"boards.go", "queries.go", "slos.go", "triggers.go"},
+		[]string{results[0].File, results[1].File, results[2].File, results[3].File})
+
+	assert.Equal(t, []Match{{"panel query", "CheckoutLatency"}}, results[0].Matches)
+	assert.Equal(t, []Match{
+		{"name", "CheckoutLatency"},
+		{"dataset", "checkout"},
+		{"filter value", "checkout-api"},
+	}, results[1].Matches)
+	assert.Equal(t, []Match{{"description", "Checkout succeeds"}, {"dataset", "checkout"}}, results[2].Matches)
+	assert.Equal(t, []Match{{"recipient", "#checkout-oncall"}}, results[3].Matches)
+}
+
+func TestSearch_Columns(t *testing.T) {
+	results, err := Search(testResources(), "duration", Options{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "CheckoutLatency", results[0].Name)
+	assert.Equal(t, []Match{{"calculation column", "duration_ms"}}, results[0].Matches)
+}
+
+func TestSearch_MultilineValue(t *testing.T) {
+	resources := &discovery.DiscoveredResources{
+		Boards: []discovery.DiscoveredBoard{
+			{Name: "Overview", Panels: []discovery.DiscoveredPanel{{Type: "text", Content: "# Overview\n\n- Latency by endpoint\n- Errors"}}},
+		},
+	}
+	results, err := Search(resources, "latency", Options{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, []Match{{"panel text", "- Latency by endpoint"}}, results[0].Matches)
+}
+
+func TestSearch_Sections(t *testing.T) {
+	resources := &discovery.DiscoveredResources{
+		Boards: []discovery.DiscoveredBoard{
+			{Name: "Overview", Sections: []discovery.DiscoveredSection{{Title: "Latency"}, {Title: "Errors"}}},
+		},
+	}
+	results, err := Search(resources, "latency", Options{})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, []Match{{"section", "Latency"}}, results[0].Matches)
+}
+
+func TestSearch_Options(t *testing.T) {
+	results, err := Search(testResources(), "checkout", Options{Types: []string{TypeTrigger, TypeSLO}})
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+
+	results, err = Search(testResources(), "Checkout", Options{CaseSensitive: true})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, []Match{{"description", "Checkout succeeds"}}, results[2].Matches)
+
+	_, err = Search(testResources(), "x", Options{Types: []string{"dataset"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,261 @@
+package serialize
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/slo"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+// TestAPIFixtures checks that every field our serializers write is a field of
+// the Honeycomb API, with the same JSON type, by comparing payloads using
+// every serialized field against the response bodies of testdata/api. The
+// fixtures are synthetic: written by hand from the field tables of the
+// Honeycomb API reference, not captured from the API.
+// Fields the API does not have are listed as drift, with the reason they are
+// written anyway; a drift entry that no longer occurs fails the test too, so
+// the lists shrink as the payloads converge on the API.
+func TestAPIFixtures(t *testing.T) {
+	latency := query.Query{
+		Dataset:           "production",
+		TimeRange:         query.Hours(2),
+		Breakdowns:        []string{"http.route"},
+		Calculations:      []query.Calculation{query.P99("duration_ms"), query.Count()},
+		Filters:           []query.Filter{query.GTE("http.status_code", 500)},
+		FilterCombination: "AND",
+		Orders:            []query.Order{{Column: "duration_ms", Op: "P99", Order: "descending"}},
+		Limit:             100,
+		Granularity:       60,
+	}
+
+	tests := []struct {
+		name      string
+		serialize func() ([]byte, error)
+		drift     map[string]string
+	}{
+		{
+			name:      "query",
+			serialize: func() ([]byte, error) { return ToJSON(latency) },
+		},
+		{
+			name: "trigger",
+			serialize: func() ([]byte, error) {
+				return TriggerToJSON(trigger.Trigger{
+					Name:        "High Latency",
+					Description: "P99 latency is above 2s",
+					Dataset:     "production",
+					Query: query.Query{
+						Dataset:      "production",
+						TimeRange:    query.Minutes(15),
+						Breakdowns:   []string{"http.route"},
+						Calculations: []query.Calculation{query.P99("duration_ms")},
+						Filters:      []query.Filter{query.Equals("service.name", "api")},
+					},
+					QueryID:    "q1a2b3c4d5e",
+					Threshold:  trigger.GreaterThan(2000),
+					Frequency:  trigger.Minutes(15),
+					AlertType:  trigger.OnChange,
+					Recipients: []trigger.Recipient{trigger.SlackChannel("#alerts")},
+					Tags:       []trigger.Tag{{Key: "team", Value: "platform"}},
+				})
+			},
+			drift: map[string]string{
+				"dataset": "the API takes the dataset in the request path; apply reads it from here",
+			},
+		},
+		{
+			name: "slo",
+			serialize: func() ([]byte, error) {
+				return SLOToJSON(slo.SLO{
+					Name:        "API Availability",
+					Description: "Successful requests over 30 days",
+					Dataset:     "production",
+					SLI:         slo.SLI{Alias: "sli.api_availability"},
+					Target:      slo.Percentage(99.9),
+					TimePeriod:  slo.Days(30),
+					BurnAlerts:  []slo.BurnAlert{slo.FastBurn(2)},
+				})
+			},
+			drift: map[string]string{
+				"dataset":     "the API takes the dataset in the request path; apply reads it from here",
+				"burn_alerts": "apply strips burn alerts and creates each with the Burn Alerts API",
+			},
+		},
+		{
+			name: "board",
+			serialize: func() ([]byte, error) {
+				return BoardToJSON(board.Board{
+					Name:        "API Overview",
+					Description: "Latency and errors of the API",
+					Panels: []board.Panel{
+						board.QueryPanel(latency, board.WithTitle("Latency"), board.WithPosition(0, 0, 6, 4),
+							board.WithQueryStyle(board.StyleCombo), board.WithChartType(board.ChartLine), board.LogScale(), board.OmitMissingValues()),
+						board.TextPanel("## Runbook", board.WithPosition(6, 0, 6, 4)),
+						board.SLOPanelByID("s1a2b3c4d5e", board.WithPosition(0, 4, 6, 4)),
+					},
+					PresetFilters: []board.Filter{{Column: "service.name", Operation: "=", Value: "api"}},
+					Tags:          []board.Tag{{Key: "team", Value: "platform"}},
+				})
+			},
+			// Boards are written in the classic board format, not the
+			// flexible one the API now returns
+			drift: map[string]string{
+				"panels[].title":                  "flexible boards take the title from the query annotation",
+				"panels[].position.x":             "flexible boards name it x_coordinate",
+				"panels[].position.y":             "flexible boards name it y_coordinate",
+				"panels[].query":                  "flexible boards reference a saved query by query_panel.query_id",
+				"panels[].content":                "flexible boards nest it as text_panel.content",
+				"panels[].slo_id":                 "flexible boards nest it as slo_panel.slo_id",
+				"panels[].query_style":            "flexible boards nest it as query_panel.query_style",
+				"panels[].visualization_settings": "flexible boards nest it as query_panel.visualization_settings",
+				"preset_filters[].op":             "flexible boards take the column and an alias only",
+				"preset_filters[].value":          "flexible boards take the column and an alias only",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.serialize()
+			require.NoError(t, err)
+			var payload any
+			require.NoError(t, json.Unmarshal(data, &payload))
+
+			raw, err := os.ReadFile("testdata/api/" + tt.name + ".synthetic.json")
+			require.NoError(t, err)
+			var fixture any
+			require.NoError(t, json.Unmarshal(raw, &fixture))
+
+			seen := make(map[string]bool)
+			for _, path := range incompatibilities("", payload, apiShape(fixture)) {
+				if d, ok := driftOf(path, tt.drift); ok {
+					seen[d] = true
+					continue
+				}
+				t.Errorf("%s is not a field of the Honeycomb API %s (testdata/api/%s.synthetic.json); fix its name or type, or record it as drift", path, tt.name, tt.name)
+			}
+			for d := range tt.drift {
+				if !seen[d] {
+					t.Errorf("drift %s no longer occurs; remove it", d)
+				}
+			}
+		})
+	}
+}
+
+// driftOf returns the drift entry covering path: the path itself, or a field
+// containing it.
+func driftOf(path string, drift map[string]string) (string, bool) {
+	for d := range drift {
+		if path == d || strings.HasPrefix(path, d+".") || strings.HasPrefix(path, d+"[]") {
+			return d, true
+		}
+	}
+	return "", false
+}
+
+// apiShape merges the elements of each array of a decoded JSON value into
+// one, so a payload element is checked against the fields of every element
+// of the fixture, such as panels of different types.
+func apiShape(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = apiShape(e)
+		}
+		return v
+	case []any:
+		var merged any
+		for _, e := range v {
+			merged = mergeShape(merged, apiShape(e))
+		}
+		if merged == nil {
+			return []any{}
+		}
+		return []any{merged}
+	}
+	return v
+}
+
+// mergeShape merges the fields of two objects; of other values it keeps the
+// first one that is not null.
+func mergeShape(a, b any) any {
+	am, aok := a.(map[string]any)
+	bm, bok := b.(map[string]any)
+	if !aok || !bok {
+		if a == nil {
+			return b
+		}
+		return a
+	}
+	for k, v := range bm {
+		am[k] = mergeShape(am[k], v)
+	}
+	return am
+}
+
+// incompatibilities returns the sorted paths of the fields of payload the
+// fixture does not have, or has with another JSON type. A null fixture value
+// matches any type, and an empty fixture array any elements.
+func incompatibilities(path string, payload, fixture any) []string {
+	found := make(map[string]bool)
+	var walk func(path string, payload, fixture any)
+	walk = func(path string, payload, fixture any) {
+		if payload == nil || fixture == nil {
+			return
+		}
+		switch p := payload.(type) {
+		case map[string]any:
+			f, ok := fixture.(map[string]any)
+			if !ok {
+				found[path] = true
+				return
+			}
+			for k, v := range p {
+				child := k
+				if path != "" {
+					child = path + "." + k
+				}
+				fv, ok := f[k]
+				if !ok {
+					found[child] = true
+					continue
+				}
+				walk(child, v, fv)
+			}
+		case []any:
+			f, ok := fixture.([]any)
+			if !ok {
+				found[path] = true
+				return
+			}
+			if len(f) == 0 {
+				return
+			}
+			for _, e := range p {
+				walk(path+"[]", e, f[0])
+			}
+		default:
+			if reflect.TypeOf(p) != reflect.TypeOf(fixture) {
+				found[path] = true
+			}
+		}
+	}
+	walk(path, payload, fixture)
+
+	paths := make([]string, 0, len(found))
+	for p := range found {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
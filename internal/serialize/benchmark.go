@@ -0,0 +1,42 @@
+package serialize
+
+import (
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+// Stats describes the API payload of a query, for teams checking how close
+// their resources come to the Honeycomb API payload limits.
+type Stats struct {
+	// Bytes is the size of the compact JSON sent to the Query API
+	Bytes int `json:"bytes"`
+
+	// PrettyBytes is the size of the indented JSON written by build
+	PrettyBytes int `json:"pretty_bytes"`
+
+	// Calculations, Filters, Breakdowns and Orders count the elements of the
+	// payload that grow with the query
+	Calculations int `json:"calculations"`
+	Filters      int `json:"filters"`
+	Breakdowns   int `json:"breakdowns"`
+	Orders       int `json:"orders"`
+}
+
+// Benchmark serializes q and returns the size of its API payload.
+func Benchmark(q query.Query) (Stats, error) {
+	data, err := ToJSON(q)
+	if err != nil {
+		return Stats{}, err
+	}
+	pretty, err := ToJSONPretty(q)
+	if err != nil {
+		return Stats{}, err
+	}
+	return Stats{
+		Bytes:        len(data),
+		PrettyBytes:  len(pretty),
+		Calculations: len(q.Calculations),
+		Filters:      len(q.Filters),
+		Breakdowns:   len(q.Breakdowns),
+		Orders:       len(q.Orders),
+	}, nil
+}
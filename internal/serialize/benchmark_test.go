@@ -0,0 +1,39 @@
+package serialize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+func TestBenchmark(t *testing.T) {
+	q := query.Query{
+		Dataset:      "production",
+		TimeRange:    query.Hours(1),
+		Breakdowns:   []string{"endpoint", "service"},
+		Calculations: []query.Calculation{query.P99("duration_ms"), query.Count()},
+		Filters:      []query.Filter{query.GT("duration_ms", 500)},
+		Orders:       []query.Order{{Op: "COUNT", Order: "descending"}},
+	}
+
+	stats, err := Benchmark(q)
+	require.NoError(t, err)
+
+	data, err := ToJSON(q)
+	require.NoError(t, err)
+	assert.Equal(t, len(data), stats.Bytes)
+	assert.Greater(t, stats.PrettyBytes, stats.Bytes)
+	assert.Equal(t, 2, stats.Calculations)
+	assert.Equal(t, 1, stats.Filters)
+	assert.Equal(t, 2, stats.Breakdowns)
+	assert.Equal(t, 1, stats.Orders)
+}
+
+func TestBenchmark_Empty(t *testing.T) {
+	stats, err := Benchmark(query.Query{})
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Bytes: 2, PrettyBytes: 2}, stats)
+}
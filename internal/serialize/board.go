@@ -14,6 +14,7 @@ type boardJSON struct {
 	Description   string            `json:"description,omitempty"`
 	Panels        []panelJSON       `json:"panels,omitempty"`
 	PresetFilters []boardFilterJSON `json:"preset_filters,omitempty"`
+	Tags          []tagJSON         `json:"tags,omitempty"`
 }
 
 type panelJSON struct {
@@ -21,11 +22,28 @@ type panelJSON struct {
 	Title    string        `json:"title,omitempty"`
 	Position *positionJSON `json:"position,omitempty"`
 	// For query panels
-	Query *queryJSON `json:"query,omitempty"`
+	Query                 *queryJSON         `json:"query,omitempty"`
+	QueryStyle            string             `json:"query_style,omitempty"`
+	VisualizationSettings *visualizationJSON `json:"visualization_settings,omitempty"`
 	// For text panels
 	Content string `json:"content,omitempty"`
 	// For SLO panels
 	SLOID string `json:"slo_id,omitempty"`
+	// For sections
+	Panels []panelJSON `json:"panels,omitempty"`
+}
+
+// visualizationJSON holds the graph settings of a query panel, as a chart
+// per calculation of its query.
+type visualizationJSON struct {
+	Charts []chartJSON `json:"charts"`
+}
+
+type chartJSON struct {
+	ChartIndex        int    `json:"chart_index"`
+	ChartType         string `json:"chart_type,omitempty"`
+	LogScale          bool   `json:"log_scale,omitempty"`
+	OmitMissingValues bool   `json:"omit_missing_values,omitempty"`
 }
 
 type positionJSON struct {
@@ -35,6 +53,12 @@ type positionJSON struct {
 	Height int `json:"height"`
 }
 
+// tagJSON is a key-value tag on a board or trigger.
+type tagJSON struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
 type boardFilterJSON struct {
 	Column string `json:"column"`
 	Op     string `json:"op"`
@@ -57,6 +81,11 @@ type sloPanelAccessor interface {
 	Config() board.PanelConfig
 }
 
+type sectionPanelAccessor interface {
+	Panels() []board.Panel
+	Config() board.PanelConfig
+}
+
 // BoardToJSON serializes a Board to Honeycomb Board JSON format.
 func BoardToJSON(b board.Board) ([]byte, error) {
 	jb := toBoardJSON(b)
@@ -106,6 +135,14 @@ func toBoardJSON(b board.Board) boardJSON {
 		}
 	}
 
+	// Convert tags
+	if len(b.Tags) > 0 {
+		jb.Tags = make([]tagJSON, len(b.Tags))
+		for i, t := range b.Tags {
+			jb.Tags[i] = tagJSON{Key: t.Key, Value: t.Value}
+		}
+	}
+
 	return jb
 }
 
@@ -129,6 +166,8 @@ func toPanelJSON(p board.Panel) panelJSON {
 		q := panel.Query()
 		qj := toQueryJSON(q)
 		jp.Query = &qj
+		jp.QueryStyle = string(config.Graph.Style)
+		jp.VisualizationSettings = toVisualizationJSON(config.Graph, len(qj.Calculations))
 
 	case textPanelAccessor:
 		jp.Type = "text"
@@ -157,7 +196,112 @@ func toPanelJSON(p board.Panel) panelJSON {
 				Height: config.Position.Height,
 			}
 		}
+
+	case sectionPanelAccessor:
+		jp.Type = "section"
+		jp.Title = panel.Config().Title
+		for _, p := range panel.Panels() {
+			jp.Panels = append(jp.Panels, toPanelJSON(p))
+		}
 	}
 
 	return jp
 }
+
+// toVisualizationJSON returns the visualization settings of a query panel
+// with graph settings g and calculations calculations: the same chart for
+// each, as Honeycomb sets them per calculation. It returns nil when g sets
+// no chart setting.
+func toVisualizationJSON(g board.GraphSettings, calculations int) *visualizationJSON {
+	chart := chartJSON{
+		ChartType:         string(g.Chart()),
+		LogScale:          g.LogScale,
+		OmitMissingValues: g.OmitMissingValues,
+	}
+	if chart == (chartJSON{}) {
+		return nil
+	}
+	v := &visualizationJSON{Charts: make([]chartJSON, max(calculations, 1))}
+	for i := range v.Charts {
+		v.Charts[i] = chart
+		v.Charts[i].ChartIndex = i
+	}
+	return v
+}
+
+// fromVisualizationJSON returns the panel options of the query style and
+// the first chart of a query panel's visualization settings.
+func fromVisualizationJSON(style string, v *visualizationJSON) []board.PanelOption {
+	var opts []board.PanelOption
+	if style != "" {
+		opts = append(opts, board.WithQueryStyle(board.QueryStyle(style)))
+	}
+	if v == nil || len(v.Charts) == 0 {
+		return opts
+	}
+	chart := v.Charts[0]
+	if chart.ChartType != "" {
+		opts = append(opts, board.WithChartType(board.ChartType(chart.ChartType)))
+	}
+	if chart.LogScale {
+		opts = append(opts, board.LogScale())
+	}
+	if chart.OmitMissingValues {
+		opts = append(opts, board.OmitMissingValues())
+	}
+	return opts
+}
+
+// BoardFromJSON parses Board JSON, as BoardToJSON writes it, into a Board.
+// Panel queries have no dataset, since board JSON does not carry it.
+func BoardFromJSON(data []byte) (board.Board, error) {
+	var jb boardJSON
+	if err := json.Unmarshal(data, &jb); err != nil {
+		return board.Board{}, err
+	}
+
+	b := board.Board{
+		Name:        jb.Name,
+		Description: jb.Description,
+		Panels:      fromPanelsJSON(jb.Panels),
+	}
+	for _, f := range jb.PresetFilters {
+		b.PresetFilters = append(b.PresetFilters, board.Filter{Column: f.Column, Operation: f.Op, Value: f.Value})
+	}
+	for _, t := range jb.Tags {
+		b.Tags = append(b.Tags, board.Tag{Key: t.Key, Value: t.Value})
+	}
+	return b, nil
+}
+
+// fromPanelsJSON converts panels back to board panels, skipping panels of
+// unknown types.
+func fromPanelsJSON(panels []panelJSON) []board.Panel {
+	var result []board.Panel
+	for _, jp := range panels {
+		var opts []board.PanelOption
+		if jp.Title != "" {
+			opts = append(opts, board.WithTitle(jp.Title))
+		}
+		if pos := jp.Position; pos != nil {
+			opts = append(opts, board.WithPosition(pos.X, pos.Y, pos.Width, pos.Height))
+		}
+
+		switch jp.Type {
+		case "query":
+			var q query.Query
+			if jp.Query != nil {
+				q = fromQueryJSON(*jp.Query)
+			}
+			opts = append(opts, fromVisualizationJSON(jp.QueryStyle, jp.VisualizationSettings)...)
+			result = append(result, board.QueryPanel(q, opts...))
+		case "text":
+			result = append(result, board.TextPanel(jp.Content, opts...))
+		case "slo":
+			result = append(result, board.SLOPanelByID(jp.SLOID, opts...))
+		case "section":
+			result = append(result, board.Section(jp.Title, fromPanelsJSON(jp.Panels)...))
+		}
+	}
+	return result
+}
@@ -159,6 +159,105 @@ func TestBoardToJSON_WithPresetFilters(t *testing.T) {
 	assert.Equal(t, "api", filter["value"])
 }
 
+func TestBoardToJSON_WithTags(t *testing.T) {
+	b := board.Board{
+		Name: "Tagged Board",
+		Tags: []board.Tag{{Key: "team", Value: "platform"}},
+	}
+
+	data, err := BoardToJSON(b)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"tags":[{"key":"team","value":"platform"}]`)
+
+	data, err = BoardToJSON(board.Board{Name: "Untagged"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "tags")
+}
+
+func TestBoardToJSON_WithSections(t *testing.T) {
+	q := query.Query{
+		Dataset:      "production",
+		TimeRange:    query.Hours(2),
+		Calculations: []query.Calculation{query.P99("duration_ms")},
+	}
+
+	b := board.Board{
+		Name: "Service",
+		Panels: []board.Panel{
+			board.TextPanel("## Overview"),
+			board.Section("Latency",
+				board.QueryPanel(q, board.WithTitle("P99")),
+				board.SLOPanelByID("slo-1"),
+			),
+			board.Section("Errors"),
+		},
+	}
+
+	data, err := BoardToJSON(b)
+	require.NoError(t, err)
+
+	var result struct {
+		Panels []struct {
+			Type   string `json:"type"`
+			Title  string `json:"title"`
+			Panels []struct {
+				Type  string `json:"type"`
+				Title string `json:"title"`
+			} `json:"panels"`
+		} `json:"panels"`
+	}
+	require.NoError(t, json.Unmarshal(data, &result))
+	require.Len(t, result.Panels, 3)
+
+	latency := result.Panels[1]
+	assert.Equal(t, "section", latency.Type)
+	assert.Equal(t, "Latency", latency.Title)
+	require.Len(t, latency.Panels, 2)
+	assert.Equal(t, "query", latency.Panels[0].Type)
+	assert.Equal(t, "P99", latency.Panels[0].Title)
+	assert.Equal(t, "slo", latency.Panels[1].Type)
+
+	assert.Equal(t, "section", result.Panels[2].Type)
+	assert.Empty(t, result.Panels[2].Panels)
+}
+
+func TestBoardToJSON_GraphSettings(t *testing.T) {
+	q := query.Query{
+		Dataset:      "production",
+		TimeRange:    query.Hours(1),
+		Breakdowns:   []string{"http.route"},
+		Calculations: []query.Calculation{query.Count(), query.P99("duration_ms")},
+	}
+	b := board.Board{
+		Name: "Traffic",
+		Panels: []board.Panel{
+			board.QueryPanel(q, board.WithQueryStyle(board.StyleCombo), board.Stacked(), board.OmitMissingValues()),
+			board.QueryPanel(q, board.LogScale()),
+			board.QueryPanel(q),
+		},
+	}
+
+	data, err := BoardToJSON(b)
+	require.NoError(t, err)
+
+	var result map[string]any
+	require.NoError(t, json.Unmarshal(data, &result))
+	panels := result["panels"].([]any)
+
+	stacked := panels[0].(map[string]any)
+	assert.Equal(t, "combo", stacked["query_style"])
+	assert.Equal(t, map[string]any{"charts": []any{
+		map[string]any{"chart_index": float64(0), "chart_type": "stacked", "omit_missing_values": true},
+		map[string]any{"chart_index": float64(1), "chart_type": "stacked", "omit_missing_values": true},
+	}}, stacked["visualization_settings"], "every calculation should get the chart")
+
+	logScale := panels[1].(map[string]any)
+	assert.NotContains(t, logScale, "query_style")
+	assert.Equal(t, map[string]any{"chart_index": float64(0), "log_scale": true}, logScale["visualization_settings"].(map[string]any)["charts"].([]any)[0])
+
+	assert.NotContains(t, panels[2], "visualization_settings", "panels without settings keep Honeycomb's defaults")
+}
+
 func TestBoardToJSONPretty(t *testing.T) {
 	b := board.Board{
 		Name: "Test Board",
@@ -171,3 +270,31 @@ func TestBoardToJSONPretty(t *testing.T) {
 	assert.Contains(t, string(data), "\n")
 	assert.Contains(t, string(data), "  ")
 }
+
+func TestBoardFromJSON_RoundTrip(t *testing.T) {
+	latency := query.Query{
+		TimeRange:    query.Hours(2),
+		Calculations: []query.Calculation{query.P99("duration_ms")},
+	}
+	b := board.Board{
+		Name:        "API Overview",
+		Description: "Latency of the API",
+		Panels: []board.Panel{
+			board.TextPanel("## Runbook", board.WithPosition(0, 0, 12, 2)),
+			board.Section("Latency",
+				board.QueryPanel(latency, board.WithTitle("P99"), board.WithPosition(0, 2, 6, 4)),
+				board.QueryPanel(latency, board.WithQueryStyle(board.StyleGraph), board.WithChartType(board.ChartBar), board.LogScale(), board.OmitMissingValues()),
+				board.SLOPanelByID("s1", board.WithTitle("Availability")),
+			),
+		},
+		PresetFilters: []board.Filter{{Column: "service.name", Operation: "=", Value: "api"}},
+		Tags:          []board.Tag{{Key: "team", Value: "platform"}},
+	}
+
+	data, err := BoardToJSON(b)
+	require.NoError(t, err)
+	parsed, err := BoardFromJSON(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, b, parsed)
+}
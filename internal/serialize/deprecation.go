@@ -0,0 +1,149 @@
+package serialize
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CompatibilityVersion is the version of the Deprecations table, bumped
+// whenever entries are added or removed.
+const CompatibilityVersion = 2
+
+// Deprecation is an entry of the API compatibility table: a query field, or
+// some values of one, that the Honeycomb Query API deprecated.
+type Deprecation struct {
+	// Field is the JSON path of the field within a query, with [] for each
+	// element of an array, e.g. "calculations[].op"
+	Field string
+
+	// Applies reports whether the field is deprecated, given the object
+	// holding it; nil means any value is
+	Applies func(holder map[string]any) bool
+
+	// Use says what replaces the field or value
+	Use string
+
+	// URL is the Honeycomb documentation of the deprecation
+	URL string
+
+	// Version is the version of the table that added the entry
+	Version int
+}
+
+// Deprecations is the API compatibility table: the query fields and values
+// the Honeycomb API deprecated, checked in queries, trigger queries, SLIs
+// and board panels. An entry is only added with the Honeycomb documentation
+// announcing the deprecation as its URL. The table is empty: the entries of
+// version 1 had no such documentation and version 2 removed them.
+var Deprecations []Deprecation
+
+// DeprecatedField is a field of a serialized resource found in the
+// Deprecations table.
+type DeprecatedField struct {
+	// Path locates the field in the resource, e.g. "query.calculations[1].op"
+	Path string
+
+	// Value is the deprecated value
+	Value any
+
+	Deprecation
+}
+
+// String describes the field and its replacement.
+func (d DeprecatedField) String() string {
+	value, _ := json.Marshal(d.Value)
+	return fmt.Sprintf("%s %s is deprecated by the Honeycomb API; use %s (see %s)", d.Path, value, d.Use, d.URL)
+}
+
+// FindDeprecated returns the fields of a serialized resource of the given
+// build output section ("queries", "triggers", "slos" or "boards") that the
+// Deprecations table lists, in table order.
+func FindDeprecated(section string, data []byte) ([]DeprecatedField, error) {
+	var resource map[string]any
+	if err := json.Unmarshal(data, &resource); err != nil {
+		return nil, err
+	}
+
+	queries := make(map[string]map[string]any)
+	switch section {
+	case "queries":
+		queries[""] = resource
+	case "triggers":
+		if q, ok := resource["query"].(map[string]any); ok {
+			queries["query."] = q
+		}
+	case "slos":
+		if sli, ok := resource["sli"].(map[string]any); ok {
+			for _, key := range []string{"good_events", "total_events"} {
+				if q, ok := sli[key].(map[string]any); ok {
+					queries["sli."+key+"."] = q
+				}
+			}
+		}
+	case "boards":
+		panelQueries(resource, "", queries)
+	}
+
+	prefixes := make([]string, 0, len(queries))
+	for prefix := range queries {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	var found []DeprecatedField
+	for _, d := range Deprecations {
+		for _, prefix := range prefixes {
+			walkField(queries[prefix], prefix, strings.Split(d.Field, "."), func(path string, holder map[string]any, value any) {
+				if d.Applies == nil || d.Applies(holder) {
+					found = append(found, DeprecatedField{Path: path, Value: value, Deprecation: d})
+				}
+			})
+		}
+	}
+	return found, nil
+}
+
+// panelQueries adds the queries of the panels of a board or section to
+// queries, keyed by their path prefix.
+func panelQueries(holder map[string]any, prefix string, queries map[string]map[string]any) {
+	panels, _ := holder["panels"].([]any)
+	for i, p := range panels {
+		panel, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		path := fmt.Sprintf("%spanels[%d].", prefix, i)
+		if q, ok := panel["query"].(map[string]any); ok {
+			queries[path+"query."] = q
+		}
+		panelQueries(panel, path, queries)
+	}
+}
+
+// walkField calls fn with the path, holder and value of each field of obj at
+// the path segments, expanding "[]" segments to every array element.
+func walkField(obj map[string]any, prefix string, segments []string, fn func(path string, holder map[string]any, value any)) {
+	key, isArray := strings.CutSuffix(segments[0], "[]")
+	value, ok := obj[key]
+	if !ok {
+		return
+	}
+	if len(segments) == 1 {
+		fn(prefix+key, obj, value)
+		return
+	}
+	if !isArray {
+		if next, ok := value.(map[string]any); ok {
+			walkField(next, prefix+key+".", segments[1:], fn)
+		}
+		return
+	}
+	elems, _ := value.([]any)
+	for i, e := range elems {
+		if next, ok := e.(map[string]any); ok {
+			walkField(next, fmt.Sprintf("%s%s[%d].", prefix, key, i), segments[1:], fn)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package serialize
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDeprecations are table entries for the tests of the lookup; they are
+// not deprecations of the Honeycomb API.
+var testDeprecations = []Deprecation{
+	{
+		Field:   "calculations[].op",
+		Applies: func(c map[string]any) bool { return c["op"] == "COUNTDISTINCT" },
+		Use:     "COUNT_DISTINCT",
+		URL:     "https://example.com/calculations",
+		Version: 1,
+	},
+	{
+		Field: "filters[].value",
+		Applies: func(f map[string]any) bool {
+			_, isString := f["value"].(string)
+			return isString && (f["op"] == "in" || f["op"] == "not-in")
+		},
+		Use:     "a list of values instead of a comma-separated string",
+		URL:     "https://example.com/filters",
+		Version: 1,
+	},
+}
+
+// useDeprecations replaces the Deprecations table for the test.
+func useDeprecations(t *testing.T, table []Deprecation) {
+	t.Helper()
+	saved := Deprecations
+	Deprecations = table
+	t.Cleanup(func() { Deprecations = saved })
+}
+
+func TestFindDeprecated(t *testing.T) {
+	useDeprecations(t, testDeprecations)
+	tests := []struct {
+		name    string
+		section string
+		data    string
+		want    []string
+	}{
+		{
+			name:    "current query",
+			section: "queries",
+			data:    `{"calculations":[{"op":"COUNT_DISTINCT","column":"user.id"}],"filters":[{"column":"region","op":"in","value":["us","eu"]}]}`,
+		},
+		{
+			name:    "deprecated query fields",
+			section: "queries",
+			data:    `{"calculations":[{"op":"COUNT"},{"op":"COUNTDISTINCT","column":"user.id"}],"filters":[{"column":"region","op":"not-in","value":"us,eu"},{"column":"name","op":"=","value":"a,b"}]}`,
+			want:    []string{"calculations[1].op", "filters[0].value"},
+		},
+		{
+			name:    "trigger query",
+			section: "triggers",
+			data:    `{"name":"Users","query":{"calculations":[{"op":"COUNTDISTINCT","column":"user.id"}]}}`,
+			want:    []string{"query.calculations[0].op"},
+		},
+		{
+			name:    "SLI queries",
+			section: "slos",
+			data:    `{"name":"A","sli":{"good_events":{"filters":[{"column":"code","op":"in","value":"200,201"}]},"total_events":{"calculations":[{"op":"COUNTDISTINCT","column":"id"}]}}}`,
+			want:    []string{"sli.total_events.calculations[0].op", "sli.good_events.filters[0].value"},
+		},
+		{
+			name:    "board sections",
+			section: "boards",
+			data:    `{"name":"B","panels":[{"type":"text"},{"type":"section","panels":[{"type":"query","query":{"calculations":[{"op":"COUNTDISTINCT","column":"id"}]}}]}]}`,
+			want:    []string{"panels[1].panels[0].query.calculations[0].op"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := FindDeprecated(tt.section, []byte(tt.data))
+			require.NoError(t, err)
+			var paths []string
+			for _, d := range found {
+				paths = append(paths, d.Path)
+			}
+			assert.Equal(t, tt.want, paths)
+		})
+	}
+}
+
+func TestDeprecatedField_String(t *testing.T) {
+	useDeprecations(t, testDeprecations)
+	found, err := FindDeprecated("queries", []byte(`{"calculations":[{"op":"COUNTDISTINCT","column":"id"}]}`))
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, `calculations[0].op "COUNTDISTINCT" is deprecated by the Honeycomb API; use COUNT_DISTINCT (see https://example.com/calculations)`, found[0].String())
+}
+
+func TestDeprecations_Table(t *testing.T) {
+	for _, d := range Deprecations {
+		assert.NotEmpty(t, d.Field)
+		assert.NotEmpty(t, d.Use, d.Field)
+		assert.Contains(t, d.URL, "https://docs.honeycomb.io/", d.Field)
+		assert.True(t, d.Version >= 1 && d.Version <= CompatibilityVersion, "%s: version %d", d.Field, d.Version)
+	}
+}
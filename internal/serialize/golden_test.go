@@ -0,0 +1,24 @@
+package serialize_test
+
+import (
+	"testing"
+
+	fullstack "github.com/lex00/wetwire-honeycomb-go/examples/full_stack"
+	"github.com/lex00/wetwire-honeycomb-go/wetwiretest"
+)
+
+// TestGolden_FullStack pins the JSON of the full_stack example. Run with
+// -update after an intended serialization change and review the diff.
+func TestGolden_FullStack(t *testing.T) {
+	tests := map[string]any{
+		"slow_requests": fullstack.SlowRequests,
+		"performance":   fullstack.PerformanceBoard,
+		"availability":  fullstack.APIAvailability,
+		"high_latency":  fullstack.HighLatencyAlert,
+	}
+	for name, resource := range tests {
+		t.Run(name, func(t *testing.T) {
+			wetwiretest.AssertSerializesTo(t, resource, "testdata/"+name+".golden.json")
+		})
+	}
+}
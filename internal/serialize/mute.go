@@ -0,0 +1,32 @@
+package serialize
+
+import (
+	"encoding/json"
+
+	"github.com/lex00/wetwire-honeycomb-go/mute"
+)
+
+// muteJSON is the build output of a maintenance window. Honeycomb has no API
+// for muting triggers, so it is read back by apply rather than sent.
+type muteJSON struct {
+	Name     string   `json:"name,omitempty"`
+	Reason   string   `json:"reason,omitempty"`
+	Start    string   `json:"start"`
+	End      string   `json:"end"`
+	Triggers []string `json:"triggers"`
+}
+
+// MuteToJSON serializes a maintenance window with the Go variable names of
+// the triggers it selects.
+func MuteToJSON(w mute.Window, triggers []string) ([]byte, error) {
+	if triggers == nil {
+		triggers = []string{}
+	}
+	return json.Marshal(muteJSON{
+		Name:     w.Name,
+		Reason:   w.Reason,
+		Start:    w.Start,
+		End:      w.End,
+		Triggers: triggers,
+	})
+}
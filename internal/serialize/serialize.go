@@ -64,7 +64,10 @@ func ToJSONPretty(q query.Query) ([]byte, error) {
 	return result, nil
 }
 
+// toQueryJSON converts q, with its Top selection expanded into its order and
+// limit, to the JSON representation.
 func toQueryJSON(q query.Query) queryJSON {
+	q = q.ExpandTop()
 	jq := queryJSON{
 		TimeRange:         q.TimeRange.TimeRange,
 		StartTime:         q.TimeRange.StartTime,
@@ -112,3 +115,45 @@ func toQueryJSON(q query.Query) queryJSON {
 
 	return jq
 }
+
+// FromJSON parses Honeycomb Query JSON into a Query. The dataset is taken from
+// an optional "dataset" key, since Query JSON does not otherwise carry it.
+func FromJSON(data []byte) (query.Query, error) {
+	var jq struct {
+		queryJSON
+		Dataset string `json:"dataset"`
+	}
+	if err := json.Unmarshal(data, &jq); err != nil {
+		return query.Query{}, err
+	}
+
+	q := fromQueryJSON(jq.queryJSON)
+	q.Dataset = jq.Dataset
+	return q, nil
+}
+
+// fromQueryJSON converts the JSON representation back to a Query, without a
+// dataset.
+func fromQueryJSON(jq queryJSON) query.Query {
+	q := query.Query{
+		TimeRange: query.TimeRange{
+			TimeRange: jq.TimeRange,
+			StartTime: jq.StartTime,
+			EndTime:   jq.EndTime,
+		},
+		Breakdowns:        jq.Breakdowns,
+		FilterCombination: jq.FilterCombination,
+		Limit:             jq.Limit,
+		Granularity:       jq.Granularity,
+	}
+	for _, c := range jq.Calculations {
+		q.Calculations = append(q.Calculations, query.Calculation{Op: c.Op, Column: c.Column})
+	}
+	for _, f := range jq.Filters {
+		q.Filters = append(q.Filters, query.Filter{Column: f.Column, Op: f.Op, Value: f.Value})
+	}
+	for _, o := range jq.Orders {
+		q.Orders = append(q.Orders, query.Order{Column: o.Column, Op: o.Op, Order: o.Order})
+	}
+	return q
+}
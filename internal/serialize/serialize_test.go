@@ -218,6 +218,27 @@ func TestToJSON_AbsoluteTimeRange(t *testing.T) {
 	assert.Equal(t, float64(1704153600), result["end_time"])
 }
 
+func TestToJSON_TopN(t *testing.T) {
+	q := query.Query{
+		Dataset:    "production",
+		TimeRange:  query.Hours(1),
+		Breakdowns: []string{"http.route"},
+		Top:        query.TopN(10, query.P99("duration_ms")),
+	}
+
+	data, err := ToJSON(q)
+	require.NoError(t, err)
+
+	// The calculation, order and limit are written together
+	assert.JSONEq(t, `{
+		"time_range": 3600,
+		"breakdowns": ["http.route"],
+		"calculations": [{"op": "P99", "column": "duration_ms"}],
+		"orders": [{"op": "P99", "column": "duration_ms", "order": "descending"}],
+		"limit": 10
+	}`, string(data))
+}
+
 func TestToJSON_ComplexQuery(t *testing.T) {
 	q := query.Query{
 		Dataset:           "production",
@@ -252,3 +273,35 @@ func TestToJSON_ComplexQuery(t *testing.T) {
 	assert.Equal(t, float64(60), result["granularity"])
 	assert.Equal(t, "AND", result["filter_combination"])
 }
+
+func TestFromJSON_RoundTrip(t *testing.T) {
+	q := query.Query{
+		TimeRange:         query.Hours(2),
+		Breakdowns:        []string{"service"},
+		Calculations:      []query.Calculation{query.P99("duration_ms"), query.Count()},
+		Filters:           []query.Filter{query.GTE("status_code", float64(500))},
+		FilterCombination: "AND",
+		Orders:            []query.Order{{Op: "COUNT", Order: "descending"}},
+		Limit:             10,
+		Granularity:       60,
+	}
+
+	data, err := ToJSON(q)
+	require.NoError(t, err)
+
+	parsed, err := FromJSON(data)
+	require.NoError(t, err)
+	assert.Equal(t, q, parsed)
+}
+
+func TestFromJSON_Dataset(t *testing.T) {
+	q, err := FromJSON([]byte(`{"dataset":"api","start_time":100,"end_time":200}`))
+	require.NoError(t, err)
+	assert.Equal(t, "api", q.Dataset)
+	assert.Equal(t, query.TimeRange{StartTime: 100, EndTime: 200}, q.TimeRange)
+}
+
+func TestFromJSON_Invalid(t *testing.T) {
+	_, err := FromJSON([]byte(`{"time_range":"2h"}`))
+	assert.Error(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 
+	"github.com/lex00/wetwire-honeycomb-go/query"
 	"github.com/lex00/wetwire-honeycomb-go/slo"
 )
 
@@ -12,6 +13,7 @@ type sloJSON struct {
 	Name             string          `json:"name"`
 	Description      string          `json:"description,omitempty"`
 	Dataset          string          `json:"dataset,omitempty"`
+	DatasetSlugs     []string        `json:"dataset_slugs,omitempty"`
 	SLI              *sliJSON        `json:"sli,omitempty"`
 	TargetPerMillion int             `json:"target_per_million,omitempty"`
 	TimePeriodDays   int             `json:"time_period_days,omitempty"`
@@ -19,6 +21,7 @@ type sloJSON struct {
 }
 
 type sliJSON struct {
+	Alias       string     `json:"alias,omitempty"`
 	GoodEvents  *queryJSON `json:"good_events,omitempty"`
 	TotalEvents *queryJSON `json:"total_events,omitempty"`
 }
@@ -36,14 +39,22 @@ type recipientJSON struct {
 	Target string `json:"target"`
 }
 
-// SLOToJSON serializes an SLO to Honeycomb SLO JSON format.
+// SLOToJSON serializes an SLO to Honeycomb SLO JSON format. An SLO whose
+// SLI spans several datasets is written with dataset_slugs instead of a
+// dataset, and fails unless it passes slo.SLO.Validate.
 func SLOToJSON(s slo.SLO) ([]byte, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
 	js := toSLOJSON(s)
 	return json.Marshal(js)
 }
 
 // SLOToJSONPretty serializes an SLO to indented JSON format.
 func SLOToJSONPretty(s slo.SLO) ([]byte, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
 	js := toSLOJSON(s)
 	buf := new(bytes.Buffer)
 	enc := json.NewEncoder(buf)
@@ -66,9 +77,9 @@ func toSLOJSON(s slo.SLO) sloJSON {
 		Dataset:     s.Dataset,
 	}
 
-	// Convert target percentage to per-million
-	if s.Target.Percentage > 0 {
-		js.TargetPerMillion = int(s.Target.Percentage * 10000)
+	// Targets set as a percentage are rounded to parts per million
+	if perMillion := s.Target.ToPerMillion(); perMillion > 0 {
+		js.TargetPerMillion = perMillion
 	}
 
 	// Convert time period
@@ -76,21 +87,30 @@ func toSLOJSON(s slo.SLO) sloJSON {
 		js.TimePeriodDays = s.TimePeriod.Days
 	}
 
-	// Convert SLI
-	if s.SLI.GoodEvents.Dataset != "" || s.SLI.TotalEvents.Dataset != "" ||
-		len(s.SLI.GoodEvents.Calculations) > 0 || len(s.SLI.TotalEvents.Calculations) > 0 {
+	// An SLO across datasets is evaluated in the environment
+	if s.CrossDataset() {
+		js.Dataset = ""
+		js.DatasetSlugs = s.SLI.Datasets()
+	}
+
+	// Convert SLI; queries reduced to their dataset only name the datasets
+	if sliQuerySet(s.SLI.GoodEvents) || sliQuerySet(s.SLI.TotalEvents) {
 		goodEventsQuery := toQueryJSON(s.SLI.GoodEvents)
 		totalEventsQuery := toQueryJSON(s.SLI.TotalEvents)
 		js.SLI = &sliJSON{
+			Alias:       s.SLI.Alias,
 			GoodEvents:  &goodEventsQuery,
 			TotalEvents: &totalEventsQuery,
 		}
+	} else if s.SLI.Alias != "" {
+		js.SLI = &sliJSON{Alias: s.SLI.Alias}
 	}
 
-	// Convert burn alerts
+	// Convert burn alerts, expanding the SLO's default recipients
 	if len(s.BurnAlerts) > 0 {
 		js.BurnAlerts = make([]burnAlertJSON, len(s.BurnAlerts))
 		for i, ba := range s.BurnAlerts {
+			ba.Recipients = s.AlertRecipients(ba)
 			js.BurnAlerts[i] = toBurnAlertJSON(ba)
 		}
 	}
@@ -98,6 +118,11 @@ func toSLOJSON(s slo.SLO) sloJSON {
 	return js
 }
 
+// sliQuerySet reports whether an SLI query has more than a dataset.
+func sliQuerySet(q query.Query) bool {
+	return len(q.Calculations) > 0 || len(q.Filters) > 0 || len(q.Breakdowns) > 0
+}
+
 func toBurnAlertJSON(ba slo.BurnAlert) burnAlertJSON {
 	jba := burnAlertJSON{
 		Name:      ba.Name,
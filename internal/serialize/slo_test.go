@@ -47,6 +47,32 @@ func TestSLOToJSON_WithTarget(t *testing.T) {
 	assert.Equal(t, float64(999000), result["target_per_million"])
 }
 
+func TestSLOToJSON_TargetPerMillion(t *testing.T) {
+	tests := []struct {
+		name   string
+		target slo.Target
+		want   float64
+	}{
+		// 99.99 * 10000 is 999899.99... in floating point
+		{"percentage rounded", slo.Percentage(99.99), 999900},
+		{"per million", slo.PerMillion(999500), 999500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := SLOToJSON(slo.SLO{Name: "API Availability", Target: tt.target})
+			require.NoError(t, err)
+
+			var result map[string]interface{}
+			require.NoError(t, json.Unmarshal(data, &result))
+			assert.Equal(t, tt.want, result["target_per_million"])
+		})
+	}
+
+	_, err := SLOToJSON(slo.SLO{Name: "API Availability", Target: slo.Percentage(99.99995)})
+	assert.Error(t, err)
+}
+
 func TestSLOToJSON_WithTimePeriod(t *testing.T) {
 	s := slo.SLO{
 		Name:       "API Availability",
@@ -139,6 +165,30 @@ func TestSLOToJSON_WithBurnAlerts(t *testing.T) {
 	assert.Equal(t, float64(2.0), fastBurn["threshold"])
 }
 
+func TestSLOToJSON_DefaultRecipients(t *testing.T) {
+	fast := slo.FastBurn(2.0)
+	fast.Recipients = []slo.Recipient{{Type: "pagerduty", Target: "api-oncall"}}
+	s := slo.SLO{
+		Name:              "API Availability",
+		BurnAlerts:        []slo.BurnAlert{fast, slo.SlowBurn(5.0)},
+		DefaultRecipients: []slo.Recipient{{Type: "slack", Target: "#api"}},
+	}
+
+	data, err := SLOToJSON(s)
+	require.NoError(t, err)
+
+	var result struct {
+		BurnAlerts []burnAlertJSON `json:"burn_alerts"`
+	}
+	require.NoError(t, json.Unmarshal(data, &result))
+	require.Len(t, result.BurnAlerts, 2)
+	assert.Equal(t, []recipientJSON{{Type: "pagerduty", Target: "api-oncall"}}, result.BurnAlerts[0].Recipients)
+	assert.Equal(t, []recipientJSON{{Type: "slack", Target: "#api"}}, result.BurnAlerts[1].Recipients)
+
+	// The defaults are not part of the SLO itself
+	assert.NotContains(t, string(data), "default_recipients")
+}
+
 func TestSLOToJSON_Complete(t *testing.T) {
 	goodEvents := query.Query{
 		Dataset: "production",
@@ -193,3 +243,54 @@ func TestSLOToJSONPretty(t *testing.T) {
 	assert.Contains(t, string(data), "\n")
 	assert.Contains(t, string(data), "  ")
 }
+
+func TestSLOToJSON_CrossDataset(t *testing.T) {
+	s := slo.SLO{
+		Name: "Checkout Availability",
+		SLI: slo.SLI{
+			Alias: "sli.checkout_ok",
+			GoodEvents: query.Query{
+				Dataset:      "cdn-logs",
+				Calculations: []query.Calculation{query.Count()},
+				Filters:      []query.Filter{query.LT("status", 500)},
+			},
+			TotalEvents: query.Query{
+				Dataset:      "checkout",
+				Calculations: []query.Calculation{query.Count()},
+			},
+		},
+		Target: slo.Percentage(99.9),
+	}
+
+	data, err := SLOToJSON(s)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+
+	assert.NotContains(t, result, "dataset")
+	assert.Equal(t, []interface{}{"cdn-logs", "checkout"}, result["dataset_slugs"])
+	sli := result["sli"].(map[string]interface{})
+	assert.Equal(t, "sli.checkout_ok", sli["alias"])
+	assert.Contains(t, sli, "good_events")
+
+	s.SLI.Alias = ""
+	_, err = SLOToJSON(s)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SLI.Alias")
+}
+
+func TestSLOToJSON_AliasOnly(t *testing.T) {
+	s := slo.SLO{
+		Name: "Checkout Availability",
+		SLI: slo.SLI{
+			Alias:       "sli.checkout_ok",
+			GoodEvents:  query.Query{Dataset: "cdn-logs"},
+			TotalEvents: query.Query{Dataset: "checkout"},
+		},
+	}
+
+	data, err := SLOToJSON(s)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"Checkout Availability","dataset_slugs":["cdn-logs","checkout"],"sli":{"alias":"sli.checkout_ok"}}`, string(data))
+}
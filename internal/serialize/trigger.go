@@ -13,10 +13,13 @@ type triggerJSON struct {
 	Description string              `json:"description,omitempty"`
 	Dataset     string              `json:"dataset,omitempty"`
 	Query       *queryJSON          `json:"query,omitempty"`
+	QueryID     string              `json:"query_id,omitempty"`
 	Threshold   *thresholdJSON      `json:"threshold,omitempty"`
 	Frequency   int                 `json:"frequency,omitempty"`
+	AlertType   string              `json:"alert_type,omitempty"`
 	Recipients  []triggerRecipientJSON `json:"recipients,omitempty"`
 	Disabled    bool                `json:"disabled"`
+	Tags        []tagJSON           `json:"tags,omitempty"`
 }
 
 type thresholdJSON struct {
@@ -57,6 +60,8 @@ func toTriggerJSON(t trigger.Trigger) triggerJSON {
 		Name:        t.Name,
 		Description: t.Description,
 		Dataset:     t.Dataset,
+		QueryID:     t.QueryID,
+		AlertType:   string(t.AlertType),
 		Disabled:    t.Disabled,
 	}
 
@@ -90,5 +95,46 @@ func toTriggerJSON(t trigger.Trigger) triggerJSON {
 		}
 	}
 
+	// Convert tags
+	if len(t.Tags) > 0 {
+		jt.Tags = make([]tagJSON, len(t.Tags))
+		for i, tag := range t.Tags {
+			jt.Tags[i] = tagJSON{Key: tag.Key, Value: tag.Value}
+		}
+	}
+
 	return jt
 }
+
+// TriggerFromJSON parses Trigger JSON, as TriggerToJSON writes it, into a
+// Trigger. An embedded query takes the trigger's dataset.
+func TriggerFromJSON(data []byte) (trigger.Trigger, error) {
+	var jt triggerJSON
+	if err := json.Unmarshal(data, &jt); err != nil {
+		return trigger.Trigger{}, err
+	}
+
+	t := trigger.Trigger{
+		Name:        jt.Name,
+		Description: jt.Description,
+		Dataset:     jt.Dataset,
+		QueryID:     jt.QueryID,
+		Frequency:   trigger.Frequency{Seconds: jt.Frequency},
+		AlertType:   trigger.AlertType(jt.AlertType),
+		Disabled:    jt.Disabled,
+	}
+	if jt.Query != nil {
+		t.Query = fromQueryJSON(*jt.Query)
+		t.Query.Dataset = jt.Dataset
+	}
+	if jt.Threshold != nil {
+		t.Threshold = trigger.Threshold{Op: trigger.Op(jt.Threshold.Op), Value: jt.Threshold.Value}
+	}
+	for _, r := range jt.Recipients {
+		t.Recipients = append(t.Recipients, trigger.Recipient{Type: trigger.RecipientType(r.Type), Target: r.Target})
+	}
+	for _, tag := range jt.Tags {
+		t.Tags = append(t.Tags, trigger.Tag{Key: tag.Key, Value: tag.Value})
+	}
+	return t, nil
+}
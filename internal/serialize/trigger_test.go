@@ -59,6 +59,28 @@ func TestTriggerToJSON_WithQuery(t *testing.T) {
 	assert.Equal(t, float64(3600), querySpec["time_range"])
 }
 
+func TestTriggerToJSON_WithQueryID(t *testing.T) {
+	tr := trigger.Trigger{
+		Name:    "High Latency",
+		Dataset: "production",
+		QueryID: "wetwire-ref:queries/Latency",
+	}
+
+	data, err := TriggerToJSON(tr)
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	err = json.Unmarshal(data, &result)
+	require.NoError(t, err)
+
+	assert.Equal(t, "wetwire-ref:queries/Latency", result["query_id"])
+	assert.NotContains(t, result, "query")
+
+	data, err = TriggerToJSON(trigger.Trigger{Name: "No Query"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "query_id")
+}
+
 func TestTriggerToJSON_WithThreshold(t *testing.T) {
 	tr := trigger.Trigger{
 		Name:      "High Latency",
@@ -79,6 +101,27 @@ func TestTriggerToJSON_WithThreshold(t *testing.T) {
 	assert.Equal(t, float64(500), threshold["value"])
 }
 
+func TestTriggerToJSON_ThresholdOps(t *testing.T) {
+	tests := []struct {
+		threshold trigger.Threshold
+		want      string
+	}{
+		{trigger.GreaterThan(500), `{"op":">","value":500}`},
+		{trigger.GreaterThanOrEqual(99.5), `{"op":">=","value":99.5}`},
+		{trigger.LessThan(1), `{"op":"<","value":1}`},
+		{trigger.LessThanOrEqual(0), `{"op":"<=","value":0}`},
+	}
+
+	for _, tt := range tests {
+		data, err := TriggerToJSON(trigger.Trigger{Name: "T", Threshold: tt.threshold})
+		require.NoError(t, err)
+
+		var result map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(data, &result))
+		assert.JSONEq(t, tt.want, string(result["threshold"]))
+	}
+}
+
 func TestTriggerToJSON_WithFrequency(t *testing.T) {
 	tr := trigger.Trigger{
 		Name:      "High Latency",
@@ -123,6 +166,20 @@ func TestTriggerToJSON_WithRecipients(t *testing.T) {
 	assert.Equal(t, "#alerts", slack["target"])
 }
 
+func TestTriggerToJSON_WithTags(t *testing.T) {
+	tr := trigger.Trigger{
+		Name: "High Latency",
+		Tags: []trigger.Tag{
+			{Key: "team", Value: "platform"},
+			{Key: "severity", Value: "page"},
+		},
+	}
+
+	data, err := TriggerToJSON(tr)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"tags":[{"key":"team","value":"platform"},{"key":"severity","value":"page"}]`)
+}
+
 func TestTriggerToJSON_Complete(t *testing.T) {
 	q := query.Query{
 		Dataset:   "production",
@@ -175,6 +232,19 @@ func TestTriggerToJSON_Disabled(t *testing.T) {
 	assert.True(t, result["disabled"].(bool))
 }
 
+func TestTriggerToJSON_AlertType(t *testing.T) {
+	data, err := TriggerToJSON(trigger.Trigger{Name: "Every Time", AlertType: trigger.OnTrue})
+	require.NoError(t, err)
+
+	var result map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &result))
+	assert.Equal(t, "on_true", result["alert_type"])
+
+	data, err = TriggerToJSON(trigger.Trigger{Name: "Default"})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "alert_type", "Honeycomb's default is left to the API")
+}
+
 func TestTriggerToJSONPretty(t *testing.T) {
 	tr := trigger.Trigger{
 		Name: "Test Trigger",
@@ -186,3 +256,30 @@ func TestTriggerToJSONPretty(t *testing.T) {
 	assert.Contains(t, string(data), "\n")
 	assert.Contains(t, string(data), "  ")
 }
+
+func TestTriggerFromJSON_RoundTrip(t *testing.T) {
+	tr := trigger.Trigger{
+		Name:        "High Latency",
+		Description: "P99 above 2s",
+		Dataset:     "production",
+		Query: query.Query{
+			Dataset:      "production",
+			TimeRange:    query.Minutes(15),
+			Calculations: []query.Calculation{query.P99("duration_ms")},
+			Filters:      []query.Filter{query.Equals("service.name", "api")},
+		},
+		Threshold:  trigger.GreaterThan(2000),
+		Frequency:  trigger.Minutes(15),
+		AlertType:  trigger.OnTrue,
+		Recipients: []trigger.Recipient{trigger.SlackChannel("#alerts")},
+		Disabled:   true,
+		Tags:       []trigger.Tag{{Key: "team", Value: "platform"}},
+	}
+
+	data, err := TriggerToJSON(tr)
+	require.NoError(t, err)
+	parsed, err := TriggerFromJSON(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, tr, parsed)
+}
@@ -0,0 +1,307 @@
+// Package telemetry reports opt-in usage metrics of the CLI itself to an
+// OTLP endpoint, so that platform teams running wetwire-honeycomb internally
+// can see which commands are used and how long they take. Nothing is sent
+// unless EnvEndpoint is set.
+//
+// A report holds the command, its outcome and duration, the number of
+// resources of each type it discovered, and the CLI version, OS and
+// architecture. It never holds identifiers: no arguments, paths, resource or
+// dataset names, host or user names, or error messages.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by FromEnv.
+const (
+	// EnvEndpoint is the OTLP/HTTP endpoint metrics are sent to, e.g.
+	// https://api.honeycomb.io. Telemetry is off when it is not set.
+	EnvEndpoint = "WETWIRE_HONEYCOMB_TELEMETRY_ENDPOINT"
+
+	// EnvHeaders holds the headers of the requests as comma-separated
+	// key=value pairs, e.g. x-honeycomb-team=KEY, like
+	// OTEL_EXPORTER_OTLP_HEADERS.
+	EnvHeaders = "WETWIRE_HONEYCOMB_TELEMETRY_HEADERS"
+
+	// EnvDoNotTrack turns telemetry off when set to a true value, even with
+	// EnvEndpoint set.
+	EnvDoNotTrack = "DO_NOT_TRACK"
+)
+
+// ServiceName is the service.name of the reported metrics.
+const ServiceName = "wetwire-honeycomb"
+
+// Metric names.
+const (
+	MetricInvocations = "wetwire_honeycomb.command.invocations"
+	MetricDuration    = "wetwire_honeycomb.command.duration"
+	MetricResources   = "wetwire_honeycomb.command.resources"
+)
+
+// DefaultTimeout bounds the time a report may add to a command.
+const DefaultTimeout = 2 * time.Second
+
+// Usage is the usage of one command run.
+type Usage struct {
+	// Command is the command path without the program name, e.g.
+	// "slo backtest"
+	Command string
+
+	// Success reports whether the command succeeded
+	Success bool
+
+	// Start is when the command started
+	Start time.Time
+
+	// Duration is how long the command ran
+	Duration time.Duration
+
+	// Resources counts the discovered resources by type, e.g. "queries";
+	// nil when the command discovered none
+	Resources map[string]int
+
+	// Version is the CLI version
+	Version string
+}
+
+// Config is where usage is sent.
+type Config struct {
+	// Endpoint is the OTLP/HTTP endpoint; /v1/metrics is appended unless
+	// it ends with it already
+	Endpoint string
+
+	// Headers are added to every request
+	Headers map[string]string
+
+	// Timeout bounds each report; DefaultTimeout when zero
+	Timeout time.Duration
+}
+
+// FromEnv returns the Config of EnvEndpoint and EnvHeaders. ok is false when
+// telemetry is off: EnvEndpoint is not set or EnvDoNotTrack is.
+func FromEnv() (config Config, ok bool, err error) {
+	endpoint := os.Getenv(EnvEndpoint)
+	if endpoint == "" {
+		return Config{}, false, nil
+	}
+	if dnt, _ := strconv.ParseBool(os.Getenv(EnvDoNotTrack)); dnt {
+		return Config{}, false, nil
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Config{}, false, fmt.Errorf("%s: want an http or https URL, got %q", EnvEndpoint, endpoint)
+	}
+	headers, err := ParseHeaders(os.Getenv(EnvHeaders))
+	if err != nil {
+		return Config{}, false, fmt.Errorf("%s: %w", EnvHeaders, err)
+	}
+	return Config{Endpoint: endpoint, Headers: headers}, true, nil
+}
+
+// ParseHeaders parses comma-separated key=value pairs with URL-encoded
+// values, the format of OTEL_EXPORTER_OTLP_HEADERS.
+func ParseHeaders(s string) (map[string]string, error) {
+	headers := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("header %q: want key=value", strings.TrimSpace(pair))
+		}
+		decoded, err := url.QueryUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("header %q: %w", key, err)
+		}
+		headers[key] = decoded
+	}
+	return headers, nil
+}
+
+// URL returns the URL metrics are posted to.
+func (c Config) URL() string {
+	endpoint := strings.TrimRight(c.Endpoint, "/")
+	if strings.HasSuffix(endpoint, "/v1/metrics") {
+		return endpoint
+	}
+	return endpoint + "/v1/metrics"
+}
+
+// Send posts the metrics of u to the endpoint of c as OTLP JSON.
+func Send(ctx context.Context, c Config, u Usage) error {
+	body, err := Metrics(u)
+	if err != nil {
+		return err
+	}
+
+	timeout := c.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.URL(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range c.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("telemetry: %s returned %s", c.URL(), resp.Status)
+	}
+	return nil
+}
+
+// Metrics returns the OTLP JSON metrics request of u: a delta sum counting
+// the run, a gauge of its duration in milliseconds and a gauge per resource
+// type, all with the command and its outcome as attributes.
+func Metrics(u Usage) ([]byte, error) {
+	start := strconv.FormatInt(u.Start.UnixNano(), 10)
+	end := strconv.FormatInt(u.Start.Add(u.Duration).UnixNano(), 10)
+	outcome := "success"
+	if !u.Success {
+		outcome = "failure"
+	}
+	attrs := []attribute{stringAttr("command", u.Command), stringAttr("outcome", outcome)}
+
+	metrics := []metric{
+		{
+			Name: MetricInvocations,
+			Unit: "{invocation}",
+			Sum: &sum{
+				AggregationTemporality: temporalityDelta,
+				IsMonotonic:            true,
+				DataPoints:             []dataPoint{{Attributes: attrs, StartTimeUnixNano: start, TimeUnixNano: end, AsInt: "1"}},
+			},
+		},
+		{
+			Name:  MetricDuration,
+			Unit:  "ms",
+			Gauge: &gauge{DataPoints: []dataPoint{{Attributes: attrs, TimeUnixNano: end, AsDouble: ptr(float64(u.Duration) / float64(time.Millisecond))}}},
+		},
+	}
+
+	if len(u.Resources) > 0 {
+		types := make([]string, 0, len(u.Resources))
+		for t := range u.Resources {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		points := make([]dataPoint, len(types))
+		for i, t := range types {
+			points[i] = dataPoint{
+				Attributes:   append(attrs[:len(attrs):len(attrs)], stringAttr("resource.type", t)),
+				TimeUnixNano: end,
+				AsInt:        strconv.Itoa(u.Resources[t]),
+			}
+		}
+		metrics = append(metrics, metric{Name: MetricResources, Unit: "{resource}", Gauge: &gauge{DataPoints: points}})
+	}
+
+	return json.Marshal(metricsRequest{ResourceMetrics: []resourceMetrics{{
+		Resource: resource{Attributes: []attribute{
+			stringAttr("service.name", ServiceName),
+			stringAttr("service.version", u.Version),
+			stringAttr("os.type", runtime.GOOS),
+			stringAttr("host.arch", runtime.GOARCH),
+		}},
+		ScopeMetrics: []scopeMetrics{{
+			Scope:   scope{Name: "github.com/lex00/wetwire-honeycomb-go/internal/telemetry"},
+			Metrics: metrics,
+		}},
+	}}})
+}
+
+// OTLP JSON encoding of ExportMetricsServiceRequest, limited to what Metrics
+// writes. 64-bit integers are strings, as the protobuf JSON mapping requires.
+
+const temporalityDelta = 1
+
+type metricsRequest struct {
+	ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+}
+
+type resourceMetrics struct {
+	Resource     resource       `json:"resource"`
+	ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+}
+
+type resource struct {
+	Attributes []attribute `json:"attributes"`
+}
+
+type scopeMetrics struct {
+	Scope   scope    `json:"scope"`
+	Metrics []metric `json:"metrics"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type metric struct {
+	Name  string `json:"name"`
+	Unit  string `json:"unit,omitempty"`
+	Sum   *sum   `json:"sum,omitempty"`
+	Gauge *gauge `json:"gauge,omitempty"`
+}
+
+type sum struct {
+	AggregationTemporality int         `json:"aggregationTemporality"`
+	IsMonotonic            bool        `json:"isMonotonic"`
+	DataPoints             []dataPoint `json:"dataPoints"`
+}
+
+type gauge struct {
+	DataPoints []dataPoint `json:"dataPoints"`
+}
+
+type dataPoint struct {
+	Attributes        []attribute `json:"attributes"`
+	StartTimeUnixNano string      `json:"startTimeUnixNano,omitempty"`
+	TimeUnixNano      string      `json:"timeUnixNano"`
+	AsInt             string      `json:"asInt,omitempty"`
+	AsDouble          *float64    `json:"asDouble,omitempty"`
+}
+
+type attribute struct {
+	Key   string         `json:"key"`
+	Value attributeValue `json:"value"`
+}
+
+type attributeValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+func stringAttr(key, value string) attribute {
+	return attribute{Key: key, Value: attributeValue{StringValue: value}}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,125 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(EnvEndpoint, "")
+	t.Setenv(EnvDoNotTrack, "")
+	_, ok, err := FromEnv()
+	require.NoError(t, err)
+	assert.False(t, ok, "telemetry is opt-in")
+
+	t.Setenv(EnvEndpoint, "https://api.honeycomb.io")
+	t.Setenv(EnvHeaders, "x-honeycomb-team=abc, x-honeycomb-dataset=cli%20usage")
+	config, ok, err := FromEnv()
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "https://api.honeycomb.io/v1/metrics", config.URL())
+	assert.Equal(t, map[string]string{"x-honeycomb-team": "abc", "x-honeycomb-dataset": "cli usage"}, config.Headers)
+
+	t.Setenv(EnvDoNotTrack, "1")
+	_, ok, err = FromEnv()
+	require.NoError(t, err)
+	assert.False(t, ok, "DO_NOT_TRACK wins over the endpoint")
+
+	t.Setenv(EnvDoNotTrack, "")
+	t.Setenv(EnvEndpoint, "api.honeycomb.io")
+	_, ok, err = FromEnv()
+	assert.ErrorContains(t, err, "want an http or https URL")
+	assert.False(t, ok)
+
+	t.Setenv(EnvEndpoint, "http://localhost:4318")
+	t.Setenv(EnvHeaders, "x-honeycomb-team")
+	_, _, err = FromEnv()
+	assert.ErrorContains(t, err, "want key=value")
+}
+
+func TestConfig_URL(t *testing.T) {
+	assert.Equal(t, "http://localhost:4318/v1/metrics", Config{Endpoint: "http://localhost:4318/"}.URL())
+	assert.Equal(t, "http://collector/v1/metrics", Config{Endpoint: "http://collector/v1/metrics"}.URL())
+}
+
+func TestMetrics(t *testing.T) {
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	data, err := Metrics(Usage{
+		Command:   "build",
+		Success:   false,
+		Start:     start,
+		Duration:  1500 * time.Millisecond,
+		Resources: map[string]int{"queries": 12, "boards": 2},
+		Version:   "1.2.3",
+	})
+	require.NoError(t, err)
+
+	body := string(data)
+	assert.Contains(t, body, `{"key":"service.name","value":{"stringValue":"wetwire-honeycomb"}}`)
+	assert.Contains(t, body, `{"key":"service.version","value":{"stringValue":"1.2.3"}}`)
+	assert.Contains(t, body, `"name":"wetwire_honeycomb.command.invocations","unit":"{invocation}","sum":{"aggregationTemporality":1,"isMonotonic":true`)
+	assert.Contains(t, body, `"startTimeUnixNano":"1792141200000000000","timeUnixNano":"1792141201500000000","asInt":"1"`)
+	assert.Contains(t, body, `{"key":"command","value":{"stringValue":"build"}},{"key":"outcome","value":{"stringValue":"failure"}}`)
+	assert.Contains(t, body, `"asDouble":1500`)
+	assert.Contains(t, body, `{"key":"resource.type","value":{"stringValue":"boards"}}],"timeUnixNano":"1792141201500000000","asInt":"2"`)
+	assert.Contains(t, body, `{"key":"resource.type","value":{"stringValue":"queries"}}],"timeUnixNano":"1792141201500000000","asInt":"12"`)
+
+	var req metricsRequest
+	require.NoError(t, json.Unmarshal(data, &req))
+	metrics := req.ResourceMetrics[0].ScopeMetrics[0].Metrics
+	require.Len(t, metrics, 3)
+	for _, p := range metrics[2].Gauge.DataPoints {
+		assert.Len(t, p.Attributes, 3, "each resource point should carry its own type only")
+	}
+
+	data, err = Metrics(Usage{Command: "lint", Success: true, Start: start})
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), MetricResources, "commands discovering nothing have no resource counts")
+	assert.Contains(t, string(data), `"stringValue":"success"`)
+}
+
+func TestSend(t *testing.T) {
+	var path, team, contentType, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, team, contentType = r.URL.Path, r.Header.Get("x-honeycomb-team"), r.Header.Get("Content-Type")
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	config := Config{Endpoint: server.URL, Headers: map[string]string{"x-honeycomb-team": "abc"}}
+	require.NoError(t, Send(context.Background(), config, Usage{Command: "apply", Success: true, Start: time.Now()}))
+	assert.Equal(t, "/v1/metrics", path)
+	assert.Equal(t, "abc", team)
+	assert.Equal(t, "application/json", contentType)
+	assert.True(t, strings.HasPrefix(body, `{"resourceMetrics":[`))
+}
+
+func TestSend_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	err := Send(context.Background(), Config{Endpoint: server.URL}, Usage{Start: time.Now()})
+	assert.ErrorContains(t, err, "401 Unauthorized")
+
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+	begin := time.Now()
+	err = Send(context.Background(), Config{Endpoint: slow.URL, Timeout: 50 * time.Millisecond}, Usage{Start: time.Now()})
+	assert.Error(t, err)
+	assert.Less(t, time.Since(begin), time.Second, "a slow endpoint should not hold up the command")
+}
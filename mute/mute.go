@@ -0,0 +1,128 @@
+// Package mute declares maintenance windows: periods during which selected
+// triggers are silenced, so planned maintenance is encoded alongside the
+// alerts it would set off.
+//
+//	var DatabaseUpgrade = mute.Window{
+//		Name:     "Database upgrade",
+//		Reason:   "Postgres 16 upgrade, CHG-1234",
+//		Start:    "2026-11-07T02:00:00Z",
+//		End:      "2026-11-07T04:00:00Z",
+//		Triggers: []string{"HighLatency", "Database*"},
+//		Tags:     []string{"team=storage"},
+//	}
+//
+// Honeycomb has no schedule for disabling triggers, so a window takes effect
+// when apply runs: while it is active, apply sends its triggers disabled, and
+// the first apply after it ends enables them again. Run apply, for example
+// from a scheduled CI job, at the start and end of each window.
+package mute
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// Window is a maintenance window muting the triggers it selects.
+type Window struct {
+	// Name is the display name of the window
+	Name string
+
+	// Reason says why triggers are muted, e.g. a change ticket
+	Reason string
+
+	// Start is when the window begins, in RFC 3339 format
+	Start string
+
+	// End is when the window ends, in RFC 3339 format
+	End string
+
+	// Triggers are patterns, as in path.Match, selecting triggers by Go
+	// variable name or display name, e.g. "HighLatency" or "Database*"
+	Triggers []string
+
+	// Tags select the triggers whose tags match every selector: "key=value"
+	// for a tag with that value, or "key" for a tag with any value
+	Tags []string
+}
+
+// ErrNoSelection is returned by Window.Validate for a window selecting
+// triggers neither by name nor by tag.
+var ErrNoSelection = errors.New("window selects no triggers: set Triggers or Tags")
+
+// Bounds returns the parsed start and end of the window.
+func (w Window) Bounds() (start, end time.Time, err error) {
+	if start, err = time.Parse(time.RFC3339, w.Start); err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("start: %w", err)
+	}
+	if end, err = time.Parse(time.RFC3339, w.End); err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("end: %w", err)
+	}
+	return start, end, nil
+}
+
+// Validate checks that the window has a start before its end, valid name
+// patterns and a selection of triggers.
+func (w Window) Validate() error {
+	start, end, err := w.Bounds()
+	if err != nil {
+		return err
+	}
+	if !end.After(start) {
+		return fmt.Errorf("end %s is not after start %s", w.End, w.Start)
+	}
+	if len(w.Triggers) == 0 && len(w.Tags) == 0 {
+		return ErrNoSelection
+	}
+	for _, pattern := range w.Triggers {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("trigger pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Active reports whether now falls within the window, from its start
+// inclusive to its end exclusive. An invalid window is never active.
+func (w Window) Active(now time.Time) bool {
+	start, end, err := w.Bounds()
+	if err != nil {
+		return false
+	}
+	return !now.Before(start) && now.Before(end)
+}
+
+// Selects reports whether the window mutes the trigger with the given Go
+// variable name, display name and tags (as "key=value" pairs): when either
+// name matches one of Triggers, or Tags is set and every selector matches
+// one of tags.
+func (w Window) Selects(name, title string, tags []string) bool {
+	for _, pattern := range w.Triggers {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, title); ok && title != "" {
+			return true
+		}
+	}
+	if len(w.Tags) == 0 {
+		return false
+	}
+	for _, selector := range w.Tags {
+		key, value, hasValue := strings.Cut(selector, "=")
+		found := false
+		for _, tag := range tags {
+			k, v, _ := strings.Cut(tag, "=")
+			if k == key && (!hasValue || v == value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
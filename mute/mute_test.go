@@ -0,0 +1,70 @@
+package mute
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func upgradeWindow() Window {
+	return Window{
+		Name:     "Database upgrade",
+		Start:    "2026-11-07T02:00:00Z",
+		End:      "2026-11-07T04:00:00Z",
+		Triggers: []string{"HighLatency", "Database*"},
+		Tags:     []string{"team=storage"},
+	}
+}
+
+func TestWindow_Validate(t *testing.T) {
+	assert.NoError(t, upgradeWindow().Validate())
+
+	tests := []struct {
+		name   string
+		modify func(*Window)
+		want   string
+	}{
+		{"bad start", func(w *Window) { w.Start = "tomorrow" }, "start:"},
+		{"bad end", func(w *Window) { w.End = "2026-11-07" }, "end:"},
+		{"end before start", func(w *Window) { w.End = w.Start }, "is not after start"},
+		{"no selection", func(w *Window) { w.Triggers, w.Tags = nil, nil }, ErrNoSelection.Error()},
+		{"bad pattern", func(w *Window) { w.Triggers = []string{"High["} }, "trigger pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := upgradeWindow()
+			tt.modify(&w)
+			err := w.Validate()
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWindow_Active(t *testing.T) {
+	w := upgradeWindow()
+	assert.False(t, w.Active(time.Date(2026, 11, 7, 1, 59, 59, 0, time.UTC)))
+	assert.True(t, w.Active(time.Date(2026, 11, 7, 2, 0, 0, 0, time.UTC)))
+	assert.True(t, w.Active(time.Date(2026, 11, 7, 3, 0, 0, 0, time.FixedZone("CET", 3600))))
+	assert.False(t, w.Active(time.Date(2026, 11, 7, 4, 0, 0, 0, time.UTC)))
+
+	w.Start = "soon"
+	assert.False(t, w.Active(time.Date(2026, 11, 7, 3, 0, 0, 0, time.UTC)))
+}
+
+func TestWindow_Selects(t *testing.T) {
+	w := upgradeWindow()
+	assert.True(t, w.Selects("HighLatency", "High Latency", nil))
+	assert.True(t, w.Selects("DatabaseErrors", "", nil))
+	assert.True(t, w.Selects("Other", "Database connections", nil))
+	assert.True(t, w.Selects("Other", "", []string{"team=storage", "env=prod"}))
+	assert.False(t, w.Selects("Other", "", []string{"team=api"}))
+
+	w.Tags = []string{"team"}
+	assert.True(t, w.Selects("Other", "", []string{"team=api"}))
+
+	w.Tags = nil
+	assert.False(t, w.Selects("Other", "", []string{"team=storage"}))
+}
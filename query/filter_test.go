@@ -1,6 +1,7 @@
 package query
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -139,3 +140,52 @@ func TestNe(t *testing.T) {
 	assert.Equal(t, "!=", filter.Op)
 	assert.Equal(t, "500", filter.Value)
 }
+
+func TestValueConstructors(t *testing.T) {
+	assert.Equal(t, "api", StringValue("api"))
+	assert.Equal(t, 500.0, NumberValue(500))
+	assert.Equal(t, true, BoolValue(true))
+	assert.Equal(t, []any{"a", 1}, ListValue("a", 1))
+
+	filter := In("region", ListValue("us-east-1", "us-west-2"))
+	assert.NoError(t, filter.Validate())
+}
+
+func TestFilterValidate(t *testing.T) {
+	valid := []Filter{
+		Equals("status", "error"),
+		Equals("http.status_code", 500),
+		Equals("error", BoolValue(true)),
+		GT("duration_ms", NumberValue(250.5)),
+		LT("version", "2.0"),
+		Contains("message", "timeout"),
+		Exists("trace.parent_id"),
+		DoesNotExist("user.id"),
+		In("region", []any{"us-east-1", "eu-west-1"}),
+		{Column: "status_code", Op: "not-in", Value: []int{500, 503}},
+	}
+	for _, f := range valid {
+		assert.NoError(t, f.Validate(), "%s %s", f.Op, f.Column)
+	}
+
+	invalid := map[string]Filter{
+		"requires a value":           Equals("status", nil),
+		"takes no value":             {Column: "user.id", Op: "exists", Value: "x"},
+		"does not accept a list":     Equals("status", []string{"a"}),
+		"does not accept a number":   Contains("message", 42),
+		"does not accept a bool":     GT("duration_ms", true),
+		"does not accept a string":   {Column: "region", Op: "in", Value: "us-east-1"},
+		"at least one value":         In("region", []any{}),
+		"list element 1":             In("region", []any{"a", []string{"b"}}),
+		"unsupported value type":     Equals("payload", struct{ A int }{1}),
+		"unknown operator":           {Column: "status", Op: "like", Value: "x"},
+		"missing a column":           Equals("", "x"),
+		"unsupported value type []u": Equals("raw", []byte("x")),
+	}
+	for want, f := range invalid {
+		err := f.Validate()
+		if assert.Error(t, err, want) {
+			assert.Contains(t, err.Error(), strings.Fields(want)[0], want)
+		}
+	}
+}
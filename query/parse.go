@@ -0,0 +1,495 @@
+package query
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Parse reads a query written in a compact, SQL-like expression:
+//
+//	P99(duration_ms), COUNT WHERE status_code >= 500 GROUP BY service LAST 2h
+//
+// The expression starts with comma-separated calculations, OP or
+// OP(column), followed by optional clauses in any order:
+//
+//	FROM dataset
+//	WHERE filter [AND filter...]   or   WHERE filter [OR filter...]
+//	GROUP BY column[, column...]
+//	ORDER BY calculation|column [ASC|DESC][, ...]
+//	LIMIT n
+//	LAST duration                  e.g. 30m, 2h, 7d, 1w or 2 hours
+//	BETWEEN start AND end          UTC times, e.g. 2024-03-01T09:00:00Z
+//	GRANULARITY duration           e.g. 1m
+//
+// A filter is "column op [value]" with any operator in FilterOps. Values are
+// numbers, true or false, quoted strings or bare words; in and not-in take a
+// comma-separated list, optionally in parentheses. Keywords and calculation
+// operators are case-insensitive. Without LAST or BETWEEN the time range is
+// left unset. Query.String writes queries in this form.
+func Parse(s string) (Query, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return Query{}, err
+	}
+	p := &parser{tokens: tokens}
+	q, err := p.query()
+	if err != nil {
+		return Query{}, fmt.Errorf("parse query %q: %w", s, err)
+	}
+	return q, nil
+}
+
+// calculationOps maps the calculation operators to whether they take a
+// column.
+var calculationOps = map[string]bool{
+	"COUNT": false, "CONCURRENCY": false,
+	"COUNT_DISTINCT": true, "SUM": true, "AVG": true, "MAX": true, "MIN": true,
+	"P50": true, "P75": true, "P90": true, "P95": true, "P99": true, "P999": true,
+	"HEATMAP": true,
+	"RATE":    true, "RATE_SUM": true, "RATE_AVG": true, "RATE_MAX": true,
+}
+
+// durationUnits maps the units of LAST and GRANULARITY to their length in seconds.
+var durationUnits = map[string]int{
+	"s": 1, "sec": 1, "second": 1, "seconds": 1,
+	"m": 60, "min": 60, "minute": 60, "minutes": 60,
+	"h": 3600, "hour": 3600, "hours": 3600,
+	"d": 86400, "day": 86400, "days": 86400,
+	"w": 604800, "week": 604800, "weeks": 604800,
+}
+
+// exprToken is a word, quoted string, operator or punctuation mark of a query
+// expression.
+type exprToken struct {
+	text   string
+	pos    int
+	quoted bool
+}
+
+// lex splits a query expression into tokens.
+func lex(s string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("parse query %q: unterminated string at position %d", s, i+1)
+			}
+			tokens = append(tokens, exprToken{text: s[i+1 : i+1+end], pos: i, quoted: true})
+			i += end + 2
+		case c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, exprToken{text: s[i : i+1], pos: i})
+			i++
+		case c == '!' || c == '<' || c == '>' || c == '=':
+			n := 1
+			if i+1 < len(s) && s[i+1] == '=' && c != '=' {
+				n = 2
+			}
+			if s[i:i+n] == "!" {
+				return nil, fmt.Errorf("parse query %q: unexpected \"!\" at position %d", s, i+1)
+			}
+			tokens = append(tokens, exprToken{text: s[i : i+n], pos: i})
+			i += n
+		default:
+			start := i
+			for i < len(s) && !unicode.IsSpace(rune(s[i])) && !strings.ContainsRune(`"'(),!<>=`, rune(s[i])) {
+				i++
+			}
+			tokens = append(tokens, exprToken{text: s[start:i], pos: start})
+		}
+	}
+	return tokens, nil
+}
+
+// parser reads a Query from the tokens of an expression.
+type parser struct {
+	tokens []exprToken
+	next   int
+}
+
+// peek returns the next token without consuming it; at the end of the
+// expression its text is empty.
+func (p *parser) peek() exprToken {
+	if p.next < len(p.tokens) {
+		return p.tokens[p.next]
+	}
+	return exprToken{pos: -1}
+}
+
+// take consumes and returns the next token.
+func (p *parser) take() exprToken {
+	t := p.peek()
+	if p.next < len(p.tokens) {
+		p.next++
+	}
+	return t
+}
+
+// is reports whether the next token is the given unquoted word or mark,
+// ignoring case.
+func (p *parser) is(text string) bool {
+	t := p.peek()
+	return !t.quoted && t.pos >= 0 && strings.EqualFold(t.text, text)
+}
+
+// accept consumes the next token if it is the given word or mark.
+func (p *parser) accept(text string) bool {
+	if p.is(text) {
+		p.next++
+		return true
+	}
+	return false
+}
+
+// expect consumes the given word or mark, failing if it is not next.
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		return p.unexpected(strconv.Quote(text))
+	}
+	return nil
+}
+
+// unexpected returns the error for a next token that is not what was
+// wanted.
+func (p *parser) unexpected(want string) error {
+	t := p.peek()
+	if t.pos < 0 {
+		return fmt.Errorf("expected %s, got end of expression", want)
+	}
+	return fmt.Errorf("expected %s, got %q at position %d", want, t.text, t.pos+1)
+}
+
+// name consumes a column or dataset name.
+func (p *parser) name(what string) (string, error) {
+	t := p.peek()
+	if t.pos < 0 || t.text == "" || (!t.quoted && strings.ContainsAny(t.text, "(),!<>=")) || (!t.quoted && isKeyword(t.text)) {
+		return "", p.unexpected(what)
+	}
+	p.next++
+	return t.text, nil
+}
+
+// keywords start the clauses of an expression.
+var keywords = []string{"FROM", "WHERE", "GROUP", "ORDER", "LIMIT", "LAST", "BETWEEN", "GRANULARITY"}
+
+// isKeyword reports whether s starts a clause.
+func isKeyword(s string) bool {
+	return slices.Contains(keywords, strings.ToUpper(s))
+}
+
+// query reads the calculations and clauses of an expression.
+func (p *parser) query() (Query, error) {
+	var q Query
+	for {
+		c, err := p.calculation()
+		if err != nil {
+			return Query{}, err
+		}
+		q.Calculations = append(q.Calculations, c)
+		if !p.accept(",") {
+			break
+		}
+	}
+
+	seen := make(map[string]bool)
+	for p.peek().pos >= 0 {
+		t := p.peek()
+		keyword := strings.ToUpper(t.text)
+		if t.quoted || !isKeyword(keyword) {
+			return Query{}, p.unexpected("FROM, WHERE, GROUP BY, ORDER BY, LIMIT, LAST, BETWEEN or GRANULARITY")
+		}
+		if seen[keyword] {
+			return Query{}, fmt.Errorf("duplicate %s clause at position %d", keyword, t.pos+1)
+		}
+		seen[keyword] = true
+		p.next++
+
+		var err error
+		switch keyword {
+		case "FROM":
+			q.Dataset, err = p.name("a dataset")
+		case "WHERE":
+			err = p.where(&q)
+		case "GROUP":
+			err = p.groupBy(&q)
+		case "ORDER":
+			err = p.orderBy(&q)
+		case "LIMIT":
+			q.Limit, err = p.limit()
+		case "LAST":
+			q.TimeRange.TimeRange, err = p.duration()
+		case "BETWEEN":
+			q.TimeRange, err = p.between()
+		case "GRANULARITY":
+			q.Granularity, err = p.duration()
+		}
+		if err != nil {
+			return Query{}, err
+		}
+	}
+	if seen["LAST"] && seen["BETWEEN"] {
+		return Query{}, fmt.Errorf("LAST and BETWEEN cannot be combined")
+	}
+	return q, nil
+}
+
+// calculation reads OP or OP(column).
+func (p *parser) calculation() (Calculation, error) {
+	t := p.peek()
+	op := strings.ToUpper(t.text)
+	needsColumn, ok := calculationOps[op]
+	if t.quoted || !ok {
+		return Calculation{}, p.unexpected("a calculation")
+	}
+	p.next++
+
+	c := Calculation{Op: op}
+	if p.accept("(") {
+		column, err := p.name("a column")
+		if err != nil {
+			return Calculation{}, err
+		}
+		if err := p.expect(")"); err != nil {
+			return Calculation{}, err
+		}
+		if !needsColumn {
+			return Calculation{}, fmt.Errorf("%s takes no column, got %q", op, column)
+		}
+		c.Column = column
+	} else if needsColumn {
+		return Calculation{}, fmt.Errorf("%s requires a column, e.g. %s(duration_ms)", op, op)
+	}
+	return c, nil
+}
+
+// where reads the filters of a WHERE clause, joined by AND or OR.
+func (p *parser) where(q *Query) error {
+	joiner := ""
+	for {
+		f, err := p.filter()
+		if err != nil {
+			return err
+		}
+		q.Filters = append(q.Filters, f)
+
+		t := p.peek()
+		if !p.accept("AND") && !p.accept("OR") {
+			break
+		}
+		if next := strings.ToUpper(t.text); joiner == "" {
+			joiner = next
+		} else if next != joiner {
+			return fmt.Errorf("cannot mix AND and OR in a WHERE clause, at position %d", t.pos+1)
+		}
+	}
+
+	// AND is Honeycomb's default combination.
+	if joiner == "OR" {
+		q.FilterCombination = "OR"
+	}
+	return nil
+}
+
+// filter reads "column op [value]".
+func (p *parser) filter() (Filter, error) {
+	column, err := p.name("a column")
+	if err != nil {
+		return Filter{}, err
+	}
+
+	t := p.peek()
+	op := strings.ToLower(t.text)
+	if t.quoted || !slices.Contains(FilterOps, op) {
+		return Filter{}, p.unexpected("a filter operator")
+	}
+	p.next++
+
+	f := Filter{Column: column, Op: op}
+	switch op {
+	case "exists", "does-not-exist":
+	case "in", "not-in":
+		values, err := p.list()
+		if err != nil {
+			return Filter{}, err
+		}
+		f.Value = values
+	default:
+		f.Value, err = p.value()
+		if err != nil {
+			return Filter{}, err
+		}
+	}
+
+	if err := f.Validate(); err != nil {
+		return Filter{}, err
+	}
+	return f, nil
+}
+
+// list reads the comma-separated values of in and not-in.
+func (p *parser) list() ([]any, error) {
+	parens := p.accept("(")
+	var values []any
+	for {
+		v, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+		if !p.accept(",") {
+			break
+		}
+	}
+	if parens {
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+	}
+	return values, nil
+}
+
+// value reads a filter value: a quoted string, true or false, a number or a
+// bare word.
+func (p *parser) value() (any, error) {
+	t := p.peek()
+	if t.quoted {
+		p.next++
+		return t.text, nil
+	}
+	if t.pos < 0 || t.text == "" || strings.ContainsAny(t.text, "(),!<>=") || isKeyword(t.text) {
+		return nil, p.unexpected("a value")
+	}
+	p.next++
+	switch t.text {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	if n, err := strconv.ParseFloat(t.text, 64); err == nil {
+		return n, nil
+	}
+	return t.text, nil
+}
+
+// groupBy reads the breakdowns of a GROUP BY clause.
+func (p *parser) groupBy(q *Query) error {
+	if err := p.expect("BY"); err != nil {
+		return err
+	}
+	for {
+		column, err := p.name("a column")
+		if err != nil {
+			return err
+		}
+		q.Breakdowns = append(q.Breakdowns, column)
+		if !p.accept(",") {
+			return nil
+		}
+	}
+}
+
+// orderBy reads the orders of an ORDER BY clause. An item naming a
+// calculation orders by its result, anything else by a breakdown column.
+func (p *parser) orderBy(q *Query) error {
+	if err := p.expect("BY"); err != nil {
+		return err
+	}
+	for {
+		var o Order
+		t := p.peek()
+		op := strings.ToUpper(t.text)
+		needsColumn, isOp := calculationOps[op]
+		next := exprToken{pos: -1}
+		if p.next+1 < len(p.tokens) {
+			next = p.tokens[p.next+1]
+		}
+		if !t.quoted && isOp && (!needsColumn || (next.text == "(" && !next.quoted)) {
+			c, err := p.calculation()
+			if err != nil {
+				return err
+			}
+			o.Op, o.Column = c.Op, c.Column
+		} else {
+			column, err := p.name("a calculation or column")
+			if err != nil {
+				return err
+			}
+			o.Column = column
+		}
+
+		o.Order = "ascending"
+		if p.accept("DESC") {
+			o.Order = "descending"
+		} else {
+			p.accept("ASC")
+		}
+		q.Orders = append(q.Orders, o)
+		if !p.accept(",") {
+			return nil
+		}
+	}
+}
+
+// limit reads the row count of a LIMIT clause.
+func (p *parser) limit() (int, error) {
+	t := p.peek()
+	n, err := strconv.Atoi(t.text)
+	if t.quoted || err != nil || n <= 0 {
+		return 0, p.unexpected("a positive row count")
+	}
+	p.next++
+	return n, nil
+}
+
+// duration reads the length of a LAST or GRANULARITY clause, e.g. 2h or
+// 2 hours, in seconds.
+func (p *parser) duration() (int, error) {
+	t := p.peek()
+	if t.quoted || t.pos < 0 {
+		return 0, p.unexpected("a duration")
+	}
+	p.next++
+
+	digits := strings.TrimRightFunc(t.text, unicode.IsLetter)
+	unit := strings.ToLower(t.text[len(digits):])
+	n, err := strconv.Atoi(digits)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("expected a duration, got %q at position %d", t.text, t.pos+1)
+	}
+	if unit == "" {
+		u := p.peek()
+		if _, ok := durationUnits[strings.ToLower(u.text)]; !ok || u.quoted {
+			return 0, p.unexpected("a duration unit")
+		}
+		unit = strings.ToLower(p.take().text)
+	}
+	seconds, ok := durationUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("unknown duration unit %q at position %d: expected s, m, h, d or w", unit, t.pos+1)
+	}
+	return n * seconds, nil
+}
+
+// between reads the UTC start and end times of a BETWEEN clause.
+func (p *parser) between() (TimeRange, error) {
+	start, err := p.name("a start time")
+	if err != nil {
+		return TimeRange{}, err
+	}
+	if err := p.expect("AND"); err != nil {
+		return TimeRange{}, err
+	}
+	end, err := p.name("an end time")
+	if err != nil {
+		return TimeRange{}, err
+	}
+	return ParseAbsolute("UTC", start, end)
+}
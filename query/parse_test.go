@@ -0,0 +1,97 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	q, err := Parse("P99(duration_ms), COUNT WHERE status_code >= 500 GROUP BY service LAST 2h")
+	require.NoError(t, err)
+	assert.Equal(t, Query{
+		TimeRange:    Hours(2),
+		Calculations: []Calculation{P99("duration_ms"), Count()},
+		Filters:      []Filter{GTE("status_code", 500.0)},
+		Breakdowns:   []string{"service"},
+	}, q)
+}
+
+func TestParse_AllClauses(t *testing.T) {
+	q, err := Parse(`heatmap(duration_ms), count from api ` +
+		`where service.name = "checkout api" and error = true and region in (us-east-1, 'eu west') and trace.parent_id does-not-exist ` +
+		`group by http.route, http.method order by count desc, http.route limit 50 last 7 days`)
+	require.NoError(t, err)
+	assert.Equal(t, Query{
+		Dataset:      "api",
+		TimeRange:    Days(7),
+		Calculations: []Calculation{Heatmap("duration_ms"), Count()},
+		Filters: []Filter{
+			Equals("service.name", "checkout api"),
+			Equals("error", true),
+			In("region", []any{"us-east-1", "eu west"}),
+			DoesNotExist("trace.parent_id"),
+		},
+		Breakdowns: []string{"http.route", "http.method"},
+		Orders: []Order{
+			{Op: "COUNT", Order: "descending"},
+			{Column: "http.route", Order: "ascending"},
+		},
+		Limit: 50,
+	}, q)
+}
+
+func TestParse_OrFilters(t *testing.T) {
+	q, err := Parse("COUNT WHERE status_code = 500 OR status_code = 503")
+	require.NoError(t, err)
+	assert.Equal(t, "OR", q.FilterCombination)
+	assert.Len(t, q.Filters, 2)
+}
+
+func TestParse_OrderByCalculation(t *testing.T) {
+	q, err := Parse("P99(duration_ms) GROUP BY service ORDER BY P99(duration_ms) DESC")
+	require.NoError(t, err)
+	assert.Equal(t, []Order{{Op: "P99", Column: "duration_ms", Order: "descending"}}, q.Orders)
+}
+
+func TestParse_Durations(t *testing.T) {
+	tests := map[string]TimeRange{
+		"COUNT LAST 30m":        Minutes(30),
+		"COUNT LAST 90s":        Seconds(90),
+		"COUNT LAST 1w":         Days(7),
+		"COUNT LAST 24 hours":   Hours(24),
+		"COUNT LAST 1 DAY":      Days(1),
+		"count last 15 minutes": Minutes(15),
+	}
+	for expr, want := range tests {
+		q, err := Parse(expr)
+		require.NoError(t, err, expr)
+		assert.Equal(t, want, q.TimeRange, expr)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]string{
+		"":                                     "expected a calculation, got end of expression",
+		"MEDIAN(duration_ms)":                  `expected a calculation, got "MEDIAN"`,
+		"P99":                                  "P99 requires a column",
+		"COUNT(duration_ms)":                   `COUNT takes no column, got "duration_ms"`,
+		"COUNT WHERE status_code":              "expected a filter operator, got end of expression",
+		"COUNT WHERE status_code ~ 500":        `expected a filter operator, got "~"`,
+		"COUNT WHERE a = 1 AND b = 2 OR c = 3": "cannot mix AND and OR",
+		"COUNT WHERE error exists true":        `expected FROM, WHERE, GROUP BY, ORDER BY, LIMIT, LAST, BETWEEN or GRANULARITY, got "true"`,
+		"COUNT WHERE name contains 5":          `operator "contains" does not accept a number value`,
+		"COUNT GROUP service":                  `expected "BY", got "service"`,
+		"COUNT LIMIT ten":                      `expected a positive row count, got "ten"`,
+		"COUNT LAST 2y":                        `unknown duration unit "y"`,
+		"COUNT LAST 2h LAST 1h":                "duplicate LAST clause at position 15",
+		`COUNT WHERE name = "unterminated`:     "unterminated string at position 20",
+		"COUNT WHERE a ! 1":                    `unexpected "!" at position 15`,
+	}
+	for expr, want := range tests {
+		_, err := Parse(expr)
+		require.Error(t, err, expr)
+		assert.Contains(t, err.Error(), want, expr)
+	}
+}
@@ -1,6 +1,11 @@
 // Package query provides type-safe Honeycomb query declarations.
 package query
 
+import (
+	"errors"
+	"fmt"
+)
+
 // Query represents a complete Honeycomb query specification.
 type Query struct {
 	// Dataset is the name of the Honeycomb dataset to query
@@ -28,6 +33,10 @@ type Query struct {
 	// Limit restricts the number of results returned
 	Limit int `json:"limit,omitempty"`
 
+	// Top sets Orders and Limit together to keep the N groups with the
+	// highest value of a calculation (see TopN)
+	Top Top `json:"-"`
+
 	// Granularity is the time bucket size for time series queries (in seconds)
 	Granularity int `json:"granularity,omitempty"`
 }
@@ -43,3 +52,26 @@ type Order struct {
 	// Order is "ascending" or "descending"
 	Order string `json:"order"`
 }
+
+// Validate checks the query for values Honeycomb would reject or misread.
+// All problems found are returned, joined.
+func (q Query) Validate() error {
+	var errs []error
+	if err := q.TimeRange.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("time_range: %w", err))
+	}
+	for i, f := range q.Filters {
+		if err := f.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("filters[%d]: %w", i, err))
+		}
+	}
+	if err := q.ValidateOrders(); err != nil {
+		// Keep the list flat when ValidateOrders found several problems
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			errs = append(errs, joined.Unwrap()...)
+		} else {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -181,3 +181,17 @@ func TestCompleteQueryExample(t *testing.T) {
 	assert.Len(t, q.Calculations, 2)
 	assert.Len(t, q.Filters, 1)
 }
+
+func TestQueryValidate(t *testing.T) {
+	q := Query{
+		Dataset: "api",
+		Filters: []Filter{Equals("status", "error"), Exists("trace.id")},
+	}
+	assert.NoError(t, q.Validate())
+
+	q.Filters = append(q.Filters, Contains("message", 1), Filter{Column: "region", Op: "in", Value: "us"})
+	err := q.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "filters[2]")
+	assert.Contains(t, err.Error(), "filters[3]")
+}
@@ -0,0 +1,206 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DerivedColumn is a Honeycomb derived column: an expression evaluated for
+// every event of a dataset that queries use like any other column, by its
+// alias. Derived columns are created in the dataset's settings or through the
+// Honeycomb API; the JSON tags match the API.
+type DerivedColumn struct {
+	// Alias is the column name queries refer to
+	Alias string `json:"alias"`
+
+	// Expression is the derived column expression, e.g. IF(EQUALS($error, true), 100, 0)
+	Expression string `json:"expression"`
+
+	// Description provides additional context about the column
+	Description string `json:"description,omitempty"`
+}
+
+// Ratio describes the percentage of events that match a condition, such as
+// an error rate: the share of requests with error = true. Honeycomb has no
+// ratio calculation, so a ratio is expressed either as the AVG of a derived
+// column that is 100 for matching events and 0 otherwise (Query), or as a
+// pair of COUNT queries to divide (Queries).
+//
+//	errorRate := query.Ratio{
+//		Alias:     "error_rate",
+//		Dataset:   "api",
+//		TimeRange: query.Hours(2),
+//		Match:     []query.Filter{query.GTE("http.status_code", 500)},
+//	}
+type Ratio struct {
+	// Alias names the derived column marking matching events
+	Alias string
+
+	// Dataset is the Honeycomb dataset to query
+	Dataset string
+
+	// TimeRange specifies the time window for the queries
+	TimeRange TimeRange
+
+	// Match selects the events counted in the numerator; all must hold
+	Match []Filter
+
+	// Filters restrict the events of both numerator and denominator
+	Filters []Filter
+
+	// Breakdowns are the fields to group results by
+	Breakdowns []string
+
+	// Granularity is the time bucket size for time series queries (in seconds)
+	Granularity int
+}
+
+// DerivedColumn returns the derived column that is 100 for events matching
+// every Match filter and 0 for all others, so its AVG is the percentage of
+// matching events. It fails for filters that cannot be expressed, such as an
+// unknown operator.
+func (r Ratio) DerivedColumn() (DerivedColumn, error) {
+	if r.Alias == "" {
+		return DerivedColumn{}, fmt.Errorf("ratio is missing an alias")
+	}
+	if len(r.Match) == 0 {
+		return DerivedColumn{}, fmt.Errorf("ratio %s has no Match filters", r.Alias)
+	}
+
+	conditions := make([]string, len(r.Match))
+	for i, f := range r.Match {
+		condition, err := FilterExpression(f)
+		if err != nil {
+			return DerivedColumn{}, fmt.Errorf("ratio %s: %w", r.Alias, err)
+		}
+		conditions[i] = condition
+	}
+
+	condition := conditions[0]
+	if len(conditions) > 1 {
+		condition = "AND(" + strings.Join(conditions, ", ") + ")"
+	}
+
+	return DerivedColumn{
+		Alias:       r.Alias,
+		Expression:  fmt.Sprintf("IF(%s, 100, 0)", condition),
+		Description: "100 when " + describeFilters(r.Match) + ", otherwise 0. AVG is the percentage of matching events.",
+	}, nil
+}
+
+// Query returns the single query computing the ratio as a percentage: the
+// AVG of the derived column over the events selected by Filters.
+func (r Ratio) Query() Query {
+	return r.base([]Calculation{Avg(r.Alias)}, r.Filters)
+}
+
+// Queries returns the ratio as two COUNT queries, for datasets without the
+// derived column: matching counts the events selected by Filters and Match,
+// total those selected by Filters. The ratio is matching divided by total,
+// per breakdown group and time bucket.
+func (r Ratio) Queries() (matching, total Query) {
+	filters := append(append([]Filter(nil), r.Filters...), r.Match...)
+	return r.base([]Calculation{Count()}, filters), r.base([]Calculation{Count()}, r.Filters)
+}
+
+// base returns a query over the ratio's dataset, time range and breakdowns.
+func (r Ratio) base(calculations []Calculation, filters []Filter) Query {
+	q := Query{
+		Dataset:      r.Dataset,
+		TimeRange:    r.TimeRange,
+		Calculations: calculations,
+		Breakdowns:   r.Breakdowns,
+		Granularity:  r.Granularity,
+	}
+	if len(filters) > 0 {
+		q.Filters = filters
+	}
+	return q
+}
+
+// expressionFuncs maps filter operators to derived column functions.
+var expressionFuncs = map[string]string{
+	"=":           "EQUALS",
+	">":           "GT",
+	">=":          "GTE",
+	"<":           "LT",
+	"<=":          "LTE",
+	"contains":    "CONTAINS",
+	"exists":      "EXISTS",
+	"starts-with": "STARTS_WITH",
+	"in":          "IN",
+}
+
+// negatedOps maps negative filter operators to the operator they negate.
+var negatedOps = map[string]string{
+	"!=":               "=",
+	"does-not-contain": "contains",
+	"does-not-exist":   "exists",
+	"not-in":           "in",
+}
+
+// FilterExpression returns the derived column expression that is true for
+// events matching f, e.g. EQUALS($error, true) for query.Equals("error", true).
+func FilterExpression(f Filter) (string, error) {
+	if err := f.Validate(); err != nil {
+		return "", err
+	}
+
+	if op, ok := negatedOps[f.Op]; ok {
+		inner, err := FilterExpression(Filter{Column: f.Column, Op: op, Value: f.Value})
+		if err != nil {
+			return "", err
+		}
+		return "NOT(" + inner + ")", nil
+	}
+
+	args := []string{columnExpression(f.Column)}
+	switch f.Op {
+	case "exists":
+	case "in":
+		rv := reflect.ValueOf(f.Value)
+		for i := 0; i < rv.Len(); i++ {
+			args = append(args, literalExpression(rv.Index(i).Interface()))
+		}
+	default:
+		args = append(args, literalExpression(f.Value))
+	}
+	return expressionFuncs[f.Op] + "(" + strings.Join(args, ", ") + ")", nil
+}
+
+// plainColumn matches column names that can be referenced without quoting.
+var plainColumn = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// columnExpression returns the derived column reference to a column.
+func columnExpression(column string) string {
+	if plainColumn.MatchString(column) {
+		return "$" + column
+	}
+	return "$" + strconv.Quote(column)
+}
+
+// literalExpression returns a derived column literal for a filter value.
+func literalExpression(v any) string {
+	switch kindOf(v) {
+	case kindString:
+		return strconv.Quote(reflect.ValueOf(v).String())
+	case kindBool:
+		return strconv.FormatBool(reflect.ValueOf(v).Bool())
+	}
+	return fmt.Sprint(v)
+}
+
+// describeFilters returns a short human-readable form of filters.
+func describeFilters(filters []Filter) string {
+	parts := make([]string, len(filters))
+	for i, f := range filters {
+		parts[i] = f.Column + " " + f.Op
+		if f.Value != nil {
+			parts[i] += " " + fmt.Sprint(f.Value)
+		}
+	}
+	return strings.Join(parts, " and ")
+}
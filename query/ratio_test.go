@@ -0,0 +1,100 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func errorRate() Ratio {
+	return Ratio{
+		Alias:      "error_rate",
+		Dataset:    "api",
+		TimeRange:  Hours(2),
+		Match:      []Filter{GTE("http.status_code", 500)},
+		Filters:    []Filter{Equals("service.name", "checkout")},
+		Breakdowns: []string{"http.route"},
+	}
+}
+
+func TestRatio_DerivedColumn(t *testing.T) {
+	dc, err := errorRate().DerivedColumn()
+	require.NoError(t, err)
+	assert.Equal(t, "error_rate", dc.Alias)
+	assert.Equal(t, "IF(GTE($http.status_code, 500), 100, 0)", dc.Expression)
+	assert.Contains(t, dc.Description, "http.status_code >= 500")
+
+	r := errorRate()
+	r.Match = append(r.Match, Exists("error"))
+	dc, err = r.DerivedColumn()
+	require.NoError(t, err)
+	assert.Equal(t, "IF(AND(GTE($http.status_code, 500), EXISTS($error)), 100, 0)", dc.Expression)
+}
+
+func TestRatio_DerivedColumnErrors(t *testing.T) {
+	r := errorRate()
+	r.Alias = ""
+	_, err := r.DerivedColumn()
+	assert.Error(t, err)
+
+	r = errorRate()
+	r.Match = nil
+	_, err = r.DerivedColumn()
+	assert.Error(t, err)
+
+	r = errorRate()
+	r.Match = []Filter{{Column: "status", Op: "matches", Value: "5.."}}
+	_, err = r.DerivedColumn()
+	assert.Error(t, err)
+}
+
+func TestRatio_Query(t *testing.T) {
+	q := errorRate().Query()
+	assert.Equal(t, "api", q.Dataset)
+	assert.Equal(t, []Calculation{Avg("error_rate")}, q.Calculations)
+	assert.Equal(t, []Filter{Equals("service.name", "checkout")}, q.Filters)
+	assert.Equal(t, []string{"http.route"}, q.Breakdowns)
+}
+
+func TestRatio_Queries(t *testing.T) {
+	r := errorRate()
+	matching, total := r.Queries()
+
+	assert.Equal(t, []Calculation{Count()}, matching.Calculations)
+	assert.Equal(t, []Filter{Equals("service.name", "checkout"), GTE("http.status_code", 500)}, matching.Filters)
+	assert.Equal(t, []Calculation{Count()}, total.Calculations)
+	assert.Equal(t, []Filter{Equals("service.name", "checkout")}, total.Filters)
+
+	// The ratio's filters are not modified
+	assert.Len(t, r.Filters, 1)
+
+	r.Filters = nil
+	_, total = r.Queries()
+	assert.Nil(t, total.Filters)
+}
+
+func TestFilterExpression(t *testing.T) {
+	tests := []struct {
+		filter Filter
+		want   string
+	}{
+		{Equals("error", true), "EQUALS($error, true)"},
+		{NotEquals("status", "ok"), `NOT(EQUALS($status, "ok"))`},
+		{LT("duration_ms", 250.5), "LT($duration_ms, 250.5)"},
+		{Contains("message", `say "hi"`), `CONTAINS($message, "say \"hi\"")`},
+		{DoesNotExist("trace.parent_id"), "NOT(EXISTS($trace.parent_id))"},
+		{StartsWith("http.route", "/api"), `STARTS_WITH($http.route, "/api")`},
+		{In("region", ListValue("us-east-1", "eu-west-1")), `IN($region, "us-east-1", "eu-west-1")`},
+		{Filter{Column: "code", Op: "not-in", Value: []int{500, 503}}, "NOT(IN($code, 500, 503))"},
+		{Equals("user agent", "curl"), `EQUALS($"user agent", "curl")`},
+	}
+	for _, tt := range tests {
+		got, err := FilterExpression(tt.filter)
+		require.NoError(t, err, tt.want)
+		assert.Equal(t, tt.want, got)
+	}
+
+	_, err := FilterExpression(Exists(""))
+	assert.Error(t, err)
+}
@@ -0,0 +1,173 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// String returns the query as a one-line expression in the form Parse
+// reads, e.g.
+//
+//	P99(duration_ms), COUNT WHERE status_code >= 500 GROUP BY service LAST 2h
+//
+// Parse reads the result back to an equal query, except that orders without
+// a direction come back ascending and numbers come back as float64. A query
+// without calculations, which Honeycomb runs as COUNT, starts with its first
+// clause and does not parse. A Top selection is written as its order and
+// limit.
+func (q Query) String() string {
+	q = q.ExpandTop()
+	var parts []string
+
+	var calculations []string
+	for _, c := range q.Calculations {
+		calculations = append(calculations, formatCalculation(c.Op, c.Column))
+	}
+	if len(calculations) > 0 {
+		parts = append(parts, strings.Join(calculations, ", "))
+	}
+
+	if q.Dataset != "" {
+		parts = append(parts, "FROM "+formatName(q.Dataset))
+	}
+
+	if len(q.Filters) > 0 {
+		joiner := " AND "
+		if strings.EqualFold(q.FilterCombination, "OR") {
+			joiner = " OR "
+		}
+		filters := make([]string, len(q.Filters))
+		for i, f := range q.Filters {
+			filters[i] = formatFilter(f)
+		}
+		parts = append(parts, "WHERE "+strings.Join(filters, joiner))
+	}
+
+	if len(q.Breakdowns) > 0 {
+		breakdowns := make([]string, len(q.Breakdowns))
+		for i, b := range q.Breakdowns {
+			breakdowns[i] = formatName(b)
+		}
+		parts = append(parts, "GROUP BY "+strings.Join(breakdowns, ", "))
+	}
+
+	if len(q.Orders) > 0 {
+		orders := make([]string, len(q.Orders))
+		for i, o := range q.Orders {
+			if o.Op != "" {
+				orders[i] = formatCalculation(o.Op, o.Column)
+			} else {
+				orders[i] = formatName(o.Column)
+			}
+			if o.Order == "descending" {
+				orders[i] += " DESC"
+			}
+		}
+		parts = append(parts, "ORDER BY "+strings.Join(orders, ", "))
+	}
+
+	if q.Limit != 0 {
+		parts = append(parts, "LIMIT "+strconv.Itoa(q.Limit))
+	}
+	if q.TimeRange.TimeRange != 0 {
+		parts = append(parts, "LAST "+formatDuration(q.TimeRange.TimeRange))
+	}
+	if q.TimeRange.StartTime != 0 || q.TimeRange.EndTime != 0 {
+		parts = append(parts, "BETWEEN "+formatUnix(q.TimeRange.StartTime)+" AND "+formatUnix(q.TimeRange.EndTime))
+	}
+	if q.Granularity != 0 {
+		parts = append(parts, "GRANULARITY "+formatDuration(q.Granularity))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// formatCalculation writes OP or OP(column).
+func formatCalculation(op, column string) string {
+	if column == "" {
+		return op
+	}
+	return op + "(" + formatName(column) + ")"
+}
+
+// formatFilter writes "column op [value]".
+func formatFilter(f Filter) string {
+	s := formatName(f.Column) + " " + f.Op
+	switch kindOf(f.Value) {
+	case kindNone:
+		return s
+	case kindList:
+		rv := reflect.ValueOf(f.Value)
+		values := make([]string, rv.Len())
+		for i := range rv.Len() {
+			values[i] = formatValue(rv.Index(i).Interface())
+		}
+		return s + " (" + strings.Join(values, ", ") + ")"
+	}
+	return s + " " + formatValue(f.Value)
+}
+
+// formatValue writes a filter value so that Parse reads back its type:
+// strings that would read as a number, boolean or keyword are quoted.
+func formatValue(v any) string {
+	switch kindOf(v) {
+	case kindString:
+		s := reflect.ValueOf(v).String()
+		if _, err := strconv.ParseFloat(s, 64); err == nil || s == "true" || s == "false" {
+			return quote(s)
+		}
+		return formatName(s)
+	case kindNumber:
+		rv := reflect.ValueOf(v)
+		if rv.CanFloat() {
+			return strconv.FormatFloat(rv.Float(), 'g', -1, 64)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+// formatName writes a column, dataset or string value, quoted when it would
+// not read back as one word.
+func formatName(s string) string {
+	if s == "" || strings.ContainsFunc(s, func(r rune) bool {
+		return r <= ' ' || strings.ContainsRune(`"'(),!<>=`, r)
+	}) || isKeyword(s) || isJoiner(s) {
+		return quote(s)
+	}
+	return s
+}
+
+// isJoiner reports whether s is one of the words Parse reads inside a
+// clause.
+func isJoiner(s string) bool {
+	switch strings.ToUpper(s) {
+	case "AND", "OR", "BY", "ASC", "DESC":
+		return true
+	}
+	return false
+}
+
+// quote wraps s in double quotes, or in single quotes when it contains a
+// double quote.
+func quote(s string) string {
+	if strings.Contains(s, `"`) {
+		return "'" + s + "'"
+	}
+	return `"` + s + `"`
+}
+
+// formatDuration writes seconds in the largest unit that divides them, e.g.
+// 2h for 7200.
+func formatDuration(seconds int) string {
+	for _, unit := range []struct {
+		suffix  string
+		seconds int
+	}{{"w", 604800}, {"d", 86400}, {"h", 3600}, {"m", 60}} {
+		if seconds%unit.seconds == 0 {
+			return strconv.Itoa(seconds/unit.seconds) + unit.suffix
+		}
+	}
+	return strconv.Itoa(seconds) + "s"
+}
@@ -0,0 +1,90 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryString(t *testing.T) {
+	q := Query{
+		Dataset:      "api",
+		TimeRange:    Hours(2),
+		Calculations: []Calculation{P99("duration_ms"), Count()},
+		Filters:      []Filter{GTE("status_code", 500)},
+		Breakdowns:   []string{"service"},
+		Orders:       []Order{{Op: "P99", Column: "duration_ms", Order: "descending"}},
+		Limit:        100,
+		Granularity:  60,
+	}
+	assert.Equal(t, "P99(duration_ms), COUNT FROM api WHERE status_code >= 500 GROUP BY service "+
+		"ORDER BY P99(duration_ms) DESC LIMIT 100 LAST 2h GRANULARITY 1m", q.String())
+}
+
+func TestQueryString_Quoting(t *testing.T) {
+	q := Query{
+		Calculations: []Calculation{Count()},
+		Filters: []Filter{
+			Equals("service name", "checkout api"),
+			Equals("http.status_code", "500"),
+			Equals("enabled", "true"),
+			Equals("message", `say "hi"`),
+			In("region", []any{"us-east-1", 2, false}),
+			Exists("limit"),
+		},
+		FilterCombination: "OR",
+	}
+	assert.Equal(t, `COUNT WHERE "service name" = "checkout api" OR http.status_code = "500" OR enabled = "true" OR `+
+		`message = 'say "hi"' OR region in (us-east-1, 2, false) OR "limit" exists`, q.String())
+}
+
+func TestQueryString_TimeRanges(t *testing.T) {
+	tests := map[string]TimeRange{
+		"COUNT LAST 90s": Seconds(90),
+		"COUNT LAST 45m": Minutes(45),
+		"COUNT LAST 1d":  Hours(24),
+		"COUNT LAST 2w":  Days(14),
+		"COUNT BETWEEN 2024-03-01T09:00:00Z AND 2024-03-01T17:00:00Z": Absolute(
+			time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 17, 0, 0, 0, time.UTC)),
+	}
+	for want, tr := range tests {
+		assert.Equal(t, want, Query{Calculations: []Calculation{Count()}, TimeRange: tr}.String())
+	}
+}
+
+func TestQueryString_RoundTrip(t *testing.T) {
+	queries := []Query{
+		{
+			Dataset:      "api",
+			TimeRange:    Days(7),
+			Calculations: []Calculation{Heatmap("duration_ms"), Count(), CountDistinct("user.id")},
+			Filters: []Filter{
+				Equals("service.name", "checkout api"),
+				Equals("error", true),
+				In("region", []any{"us-east-1", "eu west"}),
+				DoesNotExist("trace.parent_id"),
+				Equals("code", "404"),
+			},
+			Breakdowns: []string{"http.route", "desc"},
+			Orders: []Order{
+				{Op: "COUNT", Order: "descending"},
+				{Column: "http.route", Order: "ascending"},
+			},
+			Limit: 50,
+		},
+		{
+			TimeRange:         Absolute(time.Unix(1709283600, 0), time.Unix(1709312400, 0)),
+			Calculations:      []Calculation{Avg("duration_ms")},
+			Filters:           []Filter{GT("duration_ms", 1000.5), StartsWith("name", "GET /")},
+			FilterCombination: "OR",
+			Granularity:       300,
+		},
+	}
+	for _, q := range queries {
+		parsed, err := Parse(q.String())
+		require.NoError(t, err, q.String())
+		assert.Equal(t, q, parsed, q.String())
+	}
+}
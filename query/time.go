@@ -1,6 +1,18 @@
 package query
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultRetention is Honeycomb's default data retention. Queries cannot
+// reach further back than the retention of the team's plan.
+const DefaultRetention = 60 * 24 * time.Hour
+
+// MaxTimeRange is the longest relative time range, in seconds, Honeycomb
+// runs a query over.
+const MaxTimeRange = 7 * 86400
 
 // TimeRange represents time parameters for a Honeycomb query.
 // Use either relative (TimeRange in seconds) or absolute (StartTime/EndTime).
@@ -65,3 +77,124 @@ func Last24Hours() TimeRange {
 func Last7Days() TimeRange {
 	return Days(7)
 }
+
+// absoluteLayouts are the accepted formats for AbsoluteIn and ParseAbsolute.
+var absoluteLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// ParseAbsolute creates an absolute time range from wall-clock times in the
+// named IANA time zone (e.g. "Europe/Berlin", "UTC"), such as
+// "2024-03-01 09:00". Times with an explicit offset (RFC 3339) keep it.
+func ParseAbsolute(zone, start, end string) (TimeRange, error) {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return TimeRange{}, fmt.Errorf("unknown time zone %q", zone)
+	}
+
+	startTime, err := parseInZone(start, loc)
+	if err != nil {
+		return TimeRange{}, err
+	}
+	endTime, err := parseInZone(end, loc)
+	if err != nil {
+		return TimeRange{}, err
+	}
+
+	tr := Absolute(startTime, endTime)
+	if tr.StartTime >= tr.EndTime {
+		return TimeRange{}, fmt.Errorf("start %q is not before end %q", start, end)
+	}
+	return tr, nil
+}
+
+// AbsoluteIn is like ParseAbsolute but panics if the zone or times are invalid.
+// It simplifies declaring fixed ranges in package-level variables:
+//
+//	TimeRange: query.AbsoluteIn("America/New_York", "2024-11-29 00:00", "2024-11-30 00:00")
+//
+// Build and lint convert the range statically and report invalid input
+// without running the code.
+func AbsoluteIn(zone, start, end string) TimeRange {
+	tr, err := ParseAbsolute(zone, start, end)
+	if err != nil {
+		panic("query.AbsoluteIn: " + err.Error())
+	}
+	return tr
+}
+
+// parseInZone parses a time in one of absoluteLayouts in the given location.
+func parseInZone(value string, loc *time.Location) (time.Time, error) {
+	for _, layout := range absoluteLayouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (expected e.g. \"2006-01-02 15:04\" or RFC 3339)", value)
+}
+
+// Validate checks the time range against the current time and
+// DefaultRetention. See ValidateAt.
+func (tr TimeRange) Validate() error {
+	return tr.ValidateAt(time.Now(), DefaultRetention)
+}
+
+// ValidateAt checks that the time range is well-formed and, when retention is
+// positive, that it lies within the retention window ending at now:
+//
+//   - no field is negative, and relative and absolute times are not all set
+//   - StartTime is before EndTime
+//   - the range does not start before now minus retention or after now
+//   - a relative range is not longer than retention
+func (tr TimeRange) ValidateAt(now time.Time, retention time.Duration) error {
+	if tr.TimeRange < 0 || tr.StartTime < 0 || tr.EndTime < 0 {
+		return errors.New("time range values must not be negative")
+	}
+	if tr.TimeRange > 0 && tr.StartTime > 0 && tr.EndTime > 0 {
+		return errors.New("time range sets time_range, start_time and end_time; use at most two")
+	}
+	if tr.StartTime > 0 && tr.EndTime > 0 && tr.StartTime >= tr.EndTime {
+		return fmt.Errorf("start_time %s is not before end_time %s", formatUnix(tr.StartTime), formatUnix(tr.EndTime))
+	}
+
+	if retention <= 0 {
+		return nil
+	}
+
+	if time.Duration(tr.TimeRange)*time.Second > retention {
+		return fmt.Errorf("time_range of %s exceeds the %s retention", formatDays(time.Duration(tr.TimeRange)*time.Second), formatDays(retention))
+	}
+
+	start := tr.StartTime
+	if start == 0 && tr.EndTime > 0 && tr.TimeRange > 0 {
+		start = tr.EndTime - tr.TimeRange
+	}
+	if start > 0 {
+		if oldest := now.Add(-retention); time.Unix(int64(start), 0).Before(oldest) {
+			return fmt.Errorf("start_time %s is outside the %s retention (oldest queryable: %s)", formatUnix(start), formatDays(retention), oldest.UTC().Format(time.RFC3339))
+		}
+		if time.Unix(int64(start), 0).After(now) {
+			return fmt.Errorf("start_time %s is in the future", formatUnix(start))
+		}
+	}
+	return nil
+}
+
+// formatUnix formats Unix seconds as an RFC 3339 UTC time.
+func formatUnix(sec int) string {
+	return time.Unix(int64(sec), 0).UTC().Format(time.RFC3339)
+}
+
+// formatDays formats a duration in days, e.g. "60 days".
+func formatDays(d time.Duration) string {
+	days := d.Hours() / 24
+	if days == float64(int(days)) {
+		return fmt.Sprintf("%d days", int(days))
+	}
+	return fmt.Sprintf("%.1f days", days)
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRelativeTimeRange(t *testing.T) {
@@ -67,3 +68,71 @@ func TestLast7Days(t *testing.T) {
 	assert.Equal(t, 0, tr.StartTime)
 	assert.Equal(t, 0, tr.EndTime)
 }
+
+func TestParseAbsolute(t *testing.T) {
+	tr, err := ParseAbsolute("America/New_York", "2024-11-29 00:00", "2024-11-30")
+	require.NoError(t, err)
+	ny, _ := time.LoadLocation("America/New_York")
+	assert.Equal(t, int(time.Date(2024, 11, 29, 0, 0, 0, 0, ny).Unix()), tr.StartTime)
+	assert.Equal(t, int(time.Date(2024, 11, 30, 0, 0, 0, 0, ny).Unix()), tr.EndTime)
+	// 05:00 UTC is midnight in New York (EST)
+	assert.Equal(t, int(time.Date(2024, 11, 29, 5, 0, 0, 0, time.UTC).Unix()), tr.StartTime)
+
+	// Explicit offsets win over the zone
+	tr, err = ParseAbsolute("Asia/Tokyo", "2024-01-01T00:00:00Z", "2024-01-01T01:00:00Z")
+	require.NoError(t, err)
+	assert.Equal(t, int(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Unix()), tr.StartTime)
+
+	_, err = ParseAbsolute("Mars/Olympus", "2024-01-01", "2024-01-02")
+	assert.ErrorContains(t, err, "unknown time zone")
+	_, err = ParseAbsolute("UTC", "01/02/2024", "2024-01-02")
+	assert.ErrorContains(t, err, "invalid time")
+	_, err = ParseAbsolute("UTC", "2024-01-02", "2024-01-01")
+	assert.ErrorContains(t, err, "not before")
+}
+
+func TestAbsoluteIn(t *testing.T) {
+	tr := AbsoluteIn("UTC", "2024-01-01", "2024-01-02")
+	assert.Equal(t, 86400, tr.EndTime-tr.StartTime)
+
+	assert.Panics(t, func() { AbsoluteIn("UTC", "2024-01-02", "2024-01-01") })
+}
+
+func TestTimeRangeValidateAt(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	day := 86400
+	unix := func(daysAgo int) int { return int(now.Unix()) - daysAgo*day }
+
+	valid := []TimeRange{
+		Hours(2),
+		Days(60),
+		{StartTime: unix(7), EndTime: unix(6)},
+		{StartTime: unix(7), TimeRange: day},
+		{EndTime: unix(1), TimeRange: day},
+	}
+	for _, tr := range valid {
+		assert.NoError(t, tr.ValidateAt(now, DefaultRetention), "%+v", tr)
+	}
+
+	invalid := map[string]TimeRange{
+		"not before":        {StartTime: unix(6), EndTime: unix(7)},
+		"at most two":       {TimeRange: day, StartTime: unix(7), EndTime: unix(6)},
+		"negative":          {TimeRange: -1},
+		"exceeds the 60":    Days(61),
+		"outside the 60":    {StartTime: unix(90), EndTime: unix(89)},
+		"in the future":     {StartTime: unix(-1), EndTime: unix(-2) + 1},
+		"outside retention": {EndTime: unix(59), TimeRange: 2 * day},
+	}
+	for want, tr := range invalid {
+		err := tr.ValidateAt(now, DefaultRetention)
+		if assert.Error(t, err, want) && want != "outside retention" {
+			assert.Contains(t, err.Error(), want)
+		}
+	}
+
+	// Retention is configurable, and disabled when not positive
+	old := TimeRange{StartTime: unix(90), EndTime: unix(89)}
+	assert.NoError(t, old.ValidateAt(now, 400*24*time.Hour))
+	assert.NoError(t, old.ValidateAt(now, 0))
+	assert.Error(t, TimeRange{StartTime: unix(6), EndTime: unix(7)}.ValidateAt(now, 0))
+}
@@ -0,0 +1,108 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// MaxLimit is the largest limit Honeycomb accepts for a query.
+const MaxLimit = 1000
+
+// Top is a top-N selection, Honeycomb's "limit by": the N groups of the
+// breakdowns with the highest value of a calculation. Set as Query.Top, it
+// orders the query descending by the calculation and limits it to N.
+type Top struct {
+	// N is the number of groups to keep, the query's limit
+	N int
+
+	// Calculation ranks the groups
+	Calculation Calculation
+}
+
+// TopN selects the n groups with the highest value of c, setting the order
+// and limit of a query together:
+//
+//	Breakdowns: []string{"http.route"},
+//	Top:        query.TopN(10, query.P99("duration_ms")),
+//
+// c is added to the query's calculations when they do not include it.
+func TopN(n int, c Calculation) Top {
+	return Top{N: n, Calculation: c}
+}
+
+// IsZero reports whether t selects nothing, as when Query.Top is not set.
+func (t Top) IsZero() bool {
+	return t == Top{}
+}
+
+// Order returns the order of the selection: descending by its calculation.
+func (t Top) Order() Order {
+	return Order{Op: t.Calculation.Op, Column: t.Calculation.Column, Order: "descending"}
+}
+
+// ExpandTop returns q with its Top selection applied: the calculation is
+// added when missing, the order set when q has no orders and the limit when
+// q has none. Orders and a limit that disagree with Top are kept, for
+// ValidateOrders to report. The serializer writes queries expanded.
+func (q Query) ExpandTop() Query {
+	t := q.Top
+	if t.IsZero() {
+		return q
+	}
+	if !slices.Contains(q.Calculations, t.Calculation) {
+		q.Calculations = append(slices.Clone(q.Calculations), t.Calculation)
+	}
+	if len(q.Orders) == 0 {
+		q.Orders = []Order{t.Order()}
+	}
+	if q.Limit == 0 {
+		q.Limit = t.N
+	}
+	return q
+}
+
+// ValidateOrders checks the orders, limit and Top selection of q for values
+// Honeycomb would reject or that contradict each other: an order on a
+// calculation the query does not compute, or on a column it does not break
+// down by, a limit out of range, or orders and a limit set next to a Top that
+// disagree with it. All problems found are returned, joined.
+func (q Query) ValidateOrders() error {
+	var errs []error
+	if t := q.Top; !t.IsZero() {
+		if t.N <= 0 || t.N > MaxLimit {
+			errs = append(errs, fmt.Errorf("top: N must be between 1 and %d, got %d", MaxLimit, t.N))
+		}
+		if q.Limit != 0 && q.Limit != t.N {
+			errs = append(errs, fmt.Errorf("top: limit %d contradicts TopN(%d, ...): remove the limit", q.Limit, t.N))
+		}
+		if len(q.Orders) > 0 && q.Orders[0] != t.Order() {
+			errs = append(errs, fmt.Errorf("top: orders must start with %s descending, which TopN sets: remove the orders", formatCalculation(t.Calculation.Op, t.Calculation.Column)))
+		}
+	}
+
+	q = q.ExpandTop()
+	if q.Limit < 0 || q.Limit > MaxLimit {
+		errs = append(errs, fmt.Errorf("limit: must be between 1 and %d, or 0 for none, got %d", MaxLimit, q.Limit))
+	}
+	for i, o := range q.Orders {
+		switch {
+		case o.Op == "HEATMAP":
+			errs = append(errs, fmt.Errorf("orders[%d]: HEATMAP results cannot be ordered", i))
+		case o.Op != "":
+			if !slices.Contains(q.Calculations, Calculation{Op: o.Op, Column: o.Column}) {
+				errs = append(errs, fmt.Errorf("orders[%d]: %s is not a calculation of the query", i, formatCalculation(o.Op, o.Column)))
+			}
+		case o.Column != "":
+			if !slices.Contains(q.Breakdowns, o.Column) {
+				errs = append(errs, fmt.Errorf("orders[%d]: column %q is not a breakdown of the query", i, o.Column))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("orders[%d]: no calculation or column to order by", i))
+		}
+		if o.Order != "" && o.Order != "ascending" && o.Order != "descending" {
+			errs = append(errs, fmt.Errorf("orders[%d]: order must be \"ascending\" or \"descending\", got %q", i, o.Order))
+		}
+	}
+	return errors.Join(errs...)
+}
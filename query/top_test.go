@@ -0,0 +1,132 @@
+package query
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopN_ExpandTop(t *testing.T) {
+	q := Query{
+		Breakdowns:   []string{"http.route"},
+		Calculations: []Calculation{Count()},
+		Top:          TopN(10, P99("duration_ms")),
+	}
+
+	expanded := q.ExpandTop()
+	assert.Equal(t, []Calculation{Count(), P99("duration_ms")}, expanded.Calculations)
+	assert.Equal(t, []Order{{Op: "P99", Column: "duration_ms", Order: "descending"}}, expanded.Orders)
+	assert.Equal(t, 10, expanded.Limit)
+	assert.Len(t, q.Calculations, 1, "the original query is not modified")
+
+	// Expanding is idempotent, and a query without Top is unchanged
+	assert.Equal(t, expanded, expanded.ExpandTop())
+	assert.Equal(t, Query{Limit: 5}, Query{Limit: 5}.ExpandTop())
+
+	assert.Equal(t, "COUNT, P99(duration_ms) GROUP BY http.route ORDER BY P99(duration_ms) DESC LIMIT 10", q.String())
+}
+
+func TestQuery_ValidateOrders(t *testing.T) {
+	base := Query{
+		Breakdowns:   []string{"http.route"},
+		Calculations: []Calculation{P99("duration_ms"), Heatmap("duration_ms")},
+	}
+
+	tests := []struct {
+		name   string
+		modify func(q *Query)
+		errs   []string
+	}{
+		{
+			name: "valid top-N",
+			modify: func(q *Query) {
+				q.Top = TopN(10, P99("duration_ms"))
+			},
+		},
+		{
+			name: "valid orders and limit",
+			modify: func(q *Query) {
+				q.Orders = []Order{{Op: "P99", Column: "duration_ms", Order: "descending"}, {Column: "http.route", Order: "ascending"}}
+				q.Limit = 1000
+			},
+		},
+		{
+			name: "top conflicts with limit and orders",
+			modify: func(q *Query) {
+				q.Top = TopN(10, P99("duration_ms"))
+				q.Limit = 20
+				q.Orders = []Order{{Column: "http.route", Order: "ascending"}}
+			},
+			errs: []string{
+				"top: limit 20 contradicts TopN(10, ...): remove the limit",
+				"top: orders must start with P99(duration_ms) descending, which TopN sets: remove the orders",
+			},
+		},
+		{
+			name: "top N out of range",
+			modify: func(q *Query) {
+				q.Top = TopN(0, P99("duration_ms"))
+			},
+			errs: []string{"top: N must be between 1 and 1000, got 0"},
+		},
+		{
+			name: "top by heatmap",
+			modify: func(q *Query) {
+				q.Top = TopN(10, Heatmap("duration_ms"))
+			},
+			errs: []string{"orders[0]: HEATMAP results cannot be ordered"},
+		},
+		{
+			name: "order on a calculation the query does not compute",
+			modify: func(q *Query) {
+				q.Orders = []Order{{Op: "AVG", Column: "duration_ms", Order: "descending"}}
+			},
+			errs: []string{"orders[0]: AVG(duration_ms) is not a calculation of the query"},
+		},
+		{
+			name: "order on a column that is not a breakdown",
+			modify: func(q *Query) {
+				q.Orders = []Order{{Column: "service.name", Order: "up"}}
+			},
+			errs: []string{
+				`orders[0]: column "service.name" is not a breakdown of the query`,
+				`orders[0]: order must be "ascending" or "descending", got "up"`,
+			},
+		},
+		{
+			name: "limit out of range",
+			modify: func(q *Query) {
+				q.Limit = 5000
+			},
+			errs: []string{"limit: must be between 1 and 1000, or 0 for none, got 5000"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := base
+			tt.modify(&q)
+			err := q.ValidateOrders()
+			if len(tt.errs) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			var got []string
+			for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+				got = append(got, e.Error())
+			}
+			assert.Equal(t, tt.errs, got)
+		})
+	}
+
+	// Validate reports the same problems
+	q := base
+	q.Limit = -1
+	assert.ErrorContains(t, q.Validate(), "limit: must be between 1 and 1000, or 0 for none, got -1")
+
+	// An unset limit is valid
+	q.Limit = 0
+	assert.NoError(t, q.Validate())
+}
@@ -0,0 +1,165 @@
+package query
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Typed filter value constructors. Filter.Value accepts any value for
+// convenience, but only strings, numbers, booleans and (for in/not-in) lists
+// of those serialize to a filter Honeycomb understands. Using these makes the
+// intended type explicit:
+//
+//	query.Equals("http.status_code", query.NumberValue(500))
+//	query.In("region", query.ListValue("us-east-1", "us-west-2"))
+
+// StringValue returns a string filter value.
+func StringValue(s string) any {
+	return s
+}
+
+// NumberValue returns a numeric filter value.
+func NumberValue(n float64) any {
+	return n
+}
+
+// BoolValue returns a boolean filter value.
+func BoolValue(b bool) any {
+	return b
+}
+
+// ListValue returns a list filter value for the in and not-in operators.
+func ListValue(values ...any) []any {
+	return values
+}
+
+// FilterOps lists the filter operators supported by Honeycomb.
+var FilterOps = []string{
+	"=", "!=", ">", ">=", "<", "<=",
+	"contains", "does-not-contain",
+	"exists", "does-not-exist",
+	"starts-with",
+	"in", "not-in",
+}
+
+// valueKind classifies a filter value.
+type valueKind int
+
+const (
+	kindNone valueKind = iota
+	kindString
+	kindNumber
+	kindBool
+	kindList
+	kindInvalid
+)
+
+func (k valueKind) String() string {
+	switch k {
+	case kindNone:
+		return "no value"
+	case kindString:
+		return "string"
+	case kindNumber:
+		return "number"
+	case kindBool:
+		return "bool"
+	case kindList:
+		return "list"
+	}
+	return "unsupported type"
+}
+
+// kindOf returns the kind of a filter value.
+func kindOf(v any) valueKind {
+	if v == nil {
+		return kindNone
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return kindString
+	case reflect.Bool:
+		return kindBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return kindNumber
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			// []byte serializes as a base64 string
+			return kindInvalid
+		}
+		return kindList
+	}
+	return kindInvalid
+}
+
+// Validate reports whether the filter's operator is supported and its value
+// has a type the operator accepts:
+//
+//   - exists and does-not-exist take no value
+//   - in and not-in take a non-empty list of strings, numbers or booleans
+//   - >, >=, < and <= take a number or string
+//   - contains, does-not-contain and starts-with take a string
+//   - = and != take a string, number or boolean
+func (f Filter) Validate() error {
+	if f.Column == "" {
+		return fmt.Errorf("filter is missing a column")
+	}
+
+	kind := kindOf(f.Value)
+	if kind == kindInvalid {
+		return fmt.Errorf("filter on %q: unsupported value type %T", f.Column, f.Value)
+	}
+
+	var allowed []valueKind
+	switch f.Op {
+	case "exists", "does-not-exist":
+		allowed = []valueKind{kindNone}
+	case "in", "not-in":
+		if kind == kindList {
+			return validateList(f)
+		}
+		allowed = []valueKind{kindList}
+	case ">", ">=", "<", "<=":
+		allowed = []valueKind{kindNumber, kindString}
+	case "contains", "does-not-contain", "starts-with":
+		allowed = []valueKind{kindString}
+	case "=", "!=":
+		allowed = []valueKind{kindString, kindNumber, kindBool}
+	default:
+		return fmt.Errorf("filter on %q: unknown operator %q", f.Column, f.Op)
+	}
+
+	for _, k := range allowed {
+		if k == kind {
+			return nil
+		}
+	}
+	if kind == kindNone {
+		return fmt.Errorf("filter on %q: operator %q requires a value", f.Column, f.Op)
+	}
+	if allowed[0] == kindNone {
+		return fmt.Errorf("filter on %q: operator %q takes no value, got %v", f.Column, f.Op, kind)
+	}
+	return fmt.Errorf("filter on %q: operator %q does not accept a %v value", f.Column, f.Op, kind)
+}
+
+// validateList checks the elements of an in/not-in filter value.
+func validateList(f Filter) error {
+	rv := reflect.ValueOf(f.Value)
+	if rv.Len() == 0 {
+		return fmt.Errorf("filter on %q: operator %q requires at least one value", f.Column, f.Op)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		elem := rv.Index(i).Interface()
+		switch kindOf(elem) {
+		case kindString, kindNumber, kindBool:
+		default:
+			return fmt.Errorf("filter on %q: list element %d has unsupported type %T", f.Column, i, elem)
+		}
+	}
+	return nil
+}
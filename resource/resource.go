@@ -0,0 +1,190 @@
+// Package resource lets other packages add resource kinds to
+// wetwire-honeycomb, such as a company-internal Runbook type, without
+// modifying this module.
+//
+// A kind is registered once, usually from an init function, and names the Go
+// type its declarations use. Package-level variables of that type are then
+// discovered next to queries, boards, SLOs and triggers: list and graph show
+// them, build writes them to their own section of the output, and lint runs
+// the kind's checks.
+//
+//	func init() {
+//		resource.Register(resource.Kind{
+//			Name: "runbook",
+//			Type: "runbook.Runbook",
+//			Lint: lintRunbook,
+//		})
+//	}
+//
+// Discovery reads source code rather than running it, so only literal field
+// values are seen (see Resource.Fields). Kinds are registered in the process
+// running wetwire-honeycomb: build a binary that imports the package
+// registering them and runs domain.CreateRootCommand.
+package resource
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Kind describes a custom resource type.
+type Kind struct {
+	// Name is the singular name shown by list and accepted by build --type,
+	// e.g. "runbook"
+	Name string
+
+	// Section is the key of the kind's section in the build output
+	// (default: Name followed by "s")
+	Section string
+
+	// Type is the Go type declarations of the kind are discovered by, as
+	// written in source with its package name, e.g. "runbook.Runbook"
+	Type string
+
+	// Serialize converts a resource to the value written to the build output.
+	// When nil, the resource's Fields are written.
+	Serialize func(r Resource) (any, error)
+
+	// Lint reports issues with a resource. Finding rules can be disabled with
+	// lint --disable like built-in rules.
+	Lint func(r Resource) []Finding
+}
+
+// Resource is a discovered declaration of a custom kind.
+type Resource struct {
+	// Kind is the Name of the resource's kind
+	Kind string
+
+	// Name is the Go variable name
+	Name string
+
+	// Package is the package name the resource is declared in
+	Package string
+
+	// File is the absolute path of the file declaring the resource
+	File string
+
+	// Line is the line of the declaration
+	Line int
+
+	// Fields holds the literal fields of the declaration, keyed by field name:
+	// strings, int64 and float64 numbers, bools, []any for slices and
+	// map[string]any for nested structs and maps. An identifier such as
+	// HighLatency or queries.HighLatency is stored as its name and listed in
+	// Refs. Fields set by function calls or expressions are left out.
+	Fields map[string]any
+
+	// Refs are the identifiers the declaration refers to. Graph draws an edge
+	// to each that names another resource.
+	Refs []string
+}
+
+// String returns the string value of a field, or "" when it is not a string.
+func (r Resource) String(field string) string {
+	s, _ := r.Fields[field].(string)
+	return s
+}
+
+// Severity is the severity of a lint finding.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Finding is an issue reported by a kind's Lint function.
+type Finding struct {
+	// Rule identifies the check, e.g. "RUNBOOK001"
+	Rule string
+
+	Severity Severity
+	Message  string
+}
+
+// builtin are the names and sections of the built-in resource types.
+var builtin = map[string]bool{
+	"query": true, "queries": true,
+	"board": true, "boards": true,
+	"slo": true, "slos": true,
+	"trigger": true, "triggers": true,
+	"burn_alert": true, "burn_alerts": true,
+	"schema": true, "schemas": true,
+	"mute": true, "mutes": true,
+}
+
+var (
+	mu    sync.RWMutex
+	kinds = make(map[string]Kind)
+)
+
+// Register adds a resource kind. It panics if the kind has no name or type,
+// or if its name, section or type is already used, as registration happens
+// at init time where a conflict is a programming error.
+func Register(k Kind) {
+	if err := register(k); err != nil {
+		panic(err)
+	}
+}
+
+func register(k Kind) error {
+	if k.Name == "" || k.Type == "" {
+		return fmt.Errorf("resource: kind must have a Name and a Type")
+	}
+	if !strings.Contains(k.Type, ".") {
+		return fmt.Errorf("resource: kind %s: Type %q must be qualified by its package name", k.Name, k.Type)
+	}
+	if k.Section == "" {
+		k.Section = k.Name + "s"
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, key := range []string{k.Name, k.Section} {
+		if builtin[key] {
+			return fmt.Errorf("resource: kind %s: %q is a built-in resource type", k.Name, key)
+		}
+	}
+	for _, other := range kinds {
+		if other.Name == k.Name || other.Section == k.Section || other.Name == k.Section || other.Section == k.Name {
+			return fmt.Errorf("resource: kind %s conflicts with registered kind %s", k.Name, other.Name)
+		}
+		if other.Type == k.Type {
+			return fmt.Errorf("resource: kind %s: type %s is already registered by kind %s", k.Name, k.Type, other.Name)
+		}
+	}
+	kinds[k.Name] = k
+	return nil
+}
+
+// Kinds returns the registered kinds sorted by name, with Section set.
+func Kinds() []Kind {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	result := make([]Kind, 0, len(kinds))
+	for _, k := range kinds {
+		result = append(result, k)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Lookup returns the registered kind named name.
+func Lookup(name string) (Kind, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	k, ok := kinds[name]
+	return k, ok
+}
+
+// Unregister removes the kind named name, so tests can register kinds of
+// their own.
+func Unregister(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	delete(kinds, name)
+}
@@ -0,0 +1,60 @@
+package resource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegister(t *testing.T) {
+	t.Cleanup(func() {
+		Unregister("runbook")
+		Unregister("playbook")
+	})
+
+	Register(Kind{Name: "runbook", Type: "runbook.Runbook"})
+	Register(Kind{Name: "playbook", Section: "playbook_library", Type: "ops.Playbook"})
+
+	k, ok := Lookup("runbook")
+	require.True(t, ok)
+	assert.Equal(t, "runbooks", k.Section)
+
+	kinds := Kinds()
+	require.Len(t, kinds, 2)
+	assert.Equal(t, "playbook", kinds[0].Name)
+	assert.Equal(t, "playbook_library", kinds[0].Section)
+	assert.Equal(t, "runbook", kinds[1].Name)
+
+	_, ok = Lookup("missing")
+	assert.False(t, ok)
+}
+
+func TestRegister_Invalid(t *testing.T) {
+	Register(Kind{Name: "runbook", Type: "runbook.Runbook"})
+	t.Cleanup(func() { Unregister("runbook") })
+
+	tests := map[string]Kind{
+		"no name":          {Type: "x.X"},
+		"no type":          {Name: "x"},
+		"unqualified type": {Name: "x", Type: "X"},
+		"built-in name":    {Name: "board", Type: "x.Board"},
+		"built-in section": {Name: "q", Section: "queries", Type: "x.Q"},
+		"duplicate name":   {Name: "runbook", Type: "x.Runbook"},
+		"section as name":  {Name: "runbooks", Type: "x.Runbooks"},
+		"duplicate type":   {Name: "guide", Type: "runbook.Runbook"},
+	}
+	for name, k := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Panics(t, func() { Register(k) })
+		})
+	}
+	assert.Len(t, Kinds(), 1)
+}
+
+func TestResource_String(t *testing.T) {
+	r := Resource{Fields: map[string]any{"Title": "Restart", "Steps": int64(3)}}
+	assert.Equal(t, "Restart", r.String("Title"))
+	assert.Equal(t, "", r.String("Steps"))
+	assert.Equal(t, "", r.String("Missing"))
+}
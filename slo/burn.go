@@ -29,12 +29,13 @@ const (
 	BudgetRate AlertType = "budget_rate"
 )
 
-// Recipient represents a notification target for burn alerts.
+// Recipient represents a notification target for burn alerts. Targets take
+// the formats of trigger recipients (see trigger.Recipient.Validate).
 type Recipient struct {
 	// Type is the recipient type (slack, pagerduty, email, webhook)
 	Type string
 
-	// Target is the destination (channel, service ID, email address, URL)
+	// Target is the destination (channel, routing key, email address, URL)
 	Target string
 }
 
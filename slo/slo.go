@@ -1,7 +1,13 @@
 // Package slo provides type-safe Honeycomb SLO declarations.
 package slo
 
-import "github.com/lex00/wetwire-honeycomb-go/query"
+import (
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
 
 // SLO represents a complete Honeycomb SLO specification.
 type SLO struct {
@@ -11,13 +17,15 @@ type SLO struct {
 	// Description provides additional context about the SLO
 	Description string
 
-	// Dataset is the Honeycomb dataset this SLO measures
+	// Dataset is the Honeycomb dataset this SLO measures. An SLO whose SLI
+	// queries use different datasets spans all of them; Dataset may then be
+	// left empty.
 	Dataset string
 
 	// SLI defines the Service Level Indicator (good/total events)
 	SLI SLI
 
-	// Target is the SLO target percentage
+	// Target is the SLO target, as a percentage or in parts per million
 	Target Target
 
 	// TimePeriod is the rolling window for SLO calculation
@@ -25,6 +33,19 @@ type SLO struct {
 
 	// BurnAlerts are alerts triggered by error budget consumption
 	BurnAlerts []BurnAlert
+
+	// DefaultRecipients are notified by burn alerts that have no Recipients
+	// of their own
+	DefaultRecipients []Recipient
+}
+
+// AlertRecipients returns the recipients of a burn alert: its own Recipients,
+// or the SLO's DefaultRecipients when it has none.
+func (s SLO) AlertRecipients(alert BurnAlert) []Recipient {
+	if len(alert.Recipients) > 0 {
+		return alert.Recipients
+	}
+	return s.DefaultRecipients
 }
 
 // SLI represents a Service Level Indicator definition.
@@ -34,12 +55,116 @@ type SLI struct {
 
 	// TotalEvents is the query that counts all events
 	TotalEvents query.Query
+
+	// Alias is the derived column Honeycomb evaluates the SLI with: true for
+	// good events, false for bad ones and null for events outside the SLI.
+	// Required when GoodEvents and TotalEvents use different datasets, where
+	// it must be an environment-wide derived column available in each.
+	Alias string
+}
+
+// Datasets returns the datasets of the SLI queries, sorted and without
+// duplicates.
+func (sli SLI) Datasets() []string {
+	var datasets []string
+	for _, q := range []query.Query{sli.GoodEvents, sli.TotalEvents} {
+		if q.Dataset != "" && !slices.Contains(datasets, q.Dataset) {
+			datasets = append(datasets, q.Dataset)
+		}
+	}
+	slices.Sort(datasets)
+	return datasets
+}
+
+// CrossDataset reports whether the SLI queries use different datasets, e.g.
+// CDN logs for good events and application spans for all events.
+func (s SLO) CrossDataset() bool {
+	return len(s.SLI.Datasets()) > 1
 }
 
-// Target represents an SLO target percentage.
+// Validate checks the target of the SLO (see Target.Validate) and its
+// dataset combination: an SLO spanning several datasets needs an SLI Alias,
+// and its Dataset, when set, must be one of them.
+func (s SLO) Validate() error {
+	if err := s.Target.Validate(); err != nil {
+		return fmt.Errorf("target: %w", err)
+	}
+	if !s.CrossDataset() {
+		return nil
+	}
+	datasets := s.SLI.Datasets()
+	if s.SLI.Alias == "" {
+		return fmt.Errorf("SLI spans datasets %v: set SLI.Alias to an environment-wide derived column", datasets)
+	}
+	if s.Dataset != "" && !slices.Contains(datasets, s.Dataset) {
+		return fmt.Errorf("dataset %q is not one of the SLI datasets %v", s.Dataset, datasets)
+	}
+	return nil
+}
+
+// Target represents an SLO target. Honeycomb stores targets in parts per
+// million (target_per_million), so a Target set as a percentage is rounded
+// to the nearest part per million; set one of Percentage and PerMillion.
 type Target struct {
 	// Percentage is the target value (e.g., 99.9 for 99.9%)
 	Percentage float64
+
+	// PerMillion is the target in parts per million (e.g., 999000 for 99.9%)
+	PerMillion int
+}
+
+// ToPerMillion returns the target in parts per million: PerMillion when
+// set, otherwise Percentage rounded to the nearest part per million, so
+// that 99.99 is 999900 rather than the 999899 of truncating
+// 99.99 * 10000.
+func (t Target) ToPerMillion() int {
+	if t.PerMillion != 0 {
+		return t.PerMillion
+	}
+	return percentPerMillion(t.Percentage)
+}
+
+// ToPercentage returns the target as a percentage: Percentage when set,
+// otherwise PerMillion converted, e.g. 99.95 for 999500.
+func (t Target) ToPercentage() float64 {
+	if t.Percentage != 0 {
+		return t.Percentage
+	}
+	return float64(t.PerMillion) / 10000
+}
+
+// Validate checks that the target is within 0-100% and that Honeycomb can
+// store it without changing it: a percentage finer than one part per
+// million (e.g. 99.99995) would be rounded, so the error suggests the nearest
+// targets below and above it (99.9999 and 100), and a Target setting both
+// fields must set them to the same value. A zero Target is unset and
+// valid.
+func (t Target) Validate() error {
+	if t.Percentage < 0 || t.Percentage > 100 {
+		return fmt.Errorf("percentage %g is out of range (must be 0-100)", t.Percentage)
+	}
+	if t.PerMillion < 0 || t.PerMillion > 1000000 {
+		return fmt.Errorf("%d per million is out of range (must be 0-1000000)", t.PerMillion)
+	}
+	if t.Percentage == 0 {
+		return nil
+	}
+	perMillion := percentPerMillion(t.Percentage)
+	if math.Abs(t.Percentage*10000-float64(perMillion)) > 1e-6 {
+		below, above := int(math.Floor(t.Percentage*10000)), int(math.Ceil(t.Percentage*10000))
+		return fmt.Errorf("percentage %g is finer than one part per million; use Percentage(%g) (PerMillion(%d)) below it or Percentage(%g) (PerMillion(%d)) above it",
+			t.Percentage, float64(below)/10000, below, float64(above)/10000, above)
+	}
+	if t.PerMillion != 0 && t.PerMillion != perMillion {
+		return fmt.Errorf("percentage %g (%d per million) and %d per million disagree; set one of them", t.Percentage, perMillion, t.PerMillion)
+	}
+	return nil
+}
+
+// percentPerMillion converts a percentage to parts per million, rounded to
+// the nearest part.
+func percentPerMillion(p float64) int {
+	return int(math.Round(p * 10000))
 }
 
 // TimePeriod represents a rolling time window for SLO calculation.
@@ -51,11 +176,23 @@ type TimePeriod struct {
 	Hours int
 }
 
+// QueryRange returns the longest relative time range, in seconds, the SLI
+// queries of an SLO over p should cover: p, at most query.MaxTimeRange.
+func (p TimePeriod) QueryRange() int {
+	return min(p.Days*86400+p.Hours*3600, query.MaxTimeRange)
+}
+
 // Percentage creates a Target with the specified percentage.
 func Percentage(p float64) Target {
 	return Target{Percentage: p}
 }
 
+// PerMillion creates a Target with the specified parts per million, the
+// unit of Honeycomb's target_per_million (e.g. 999000 for 99.9%).
+func PerMillion(n int) Target {
+	return Target{PerMillion: n}
+}
+
 // Days creates a TimePeriod with the specified number of days.
 func Days(d int) TimePeriod {
 	return TimePeriod{Days: d}
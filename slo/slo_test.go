@@ -88,6 +88,56 @@ func TestPercentage_Builder(t *testing.T) {
 	}
 }
 
+func TestTarget_Conversion(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     Target
+		perMillion int
+		percentage float64
+	}{
+		{"percentage", Percentage(99.9), 999000, 99.9},
+		{"percentage rounded", Percentage(99.99), 999900, 99.99},
+		{"per million", PerMillion(999500), 999500, 99.95},
+		{"unset", Target{}, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.perMillion, tt.target.ToPerMillion())
+			assert.Equal(t, tt.percentage, tt.target.ToPercentage())
+			assert.NoError(t, tt.target.Validate())
+		})
+	}
+}
+
+func TestTarget_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  Target
+		wantErr string
+	}{
+		{"percentage over 100", Percentage(105), "out of range"},
+		{"negative per million", PerMillion(-1), "out of range"},
+		{"finer than per million", Percentage(99.99995), "use Percentage(99.9999) (PerMillion(999999)) below it or Percentage(100) (PerMillion(1000000)) above it"},
+		{"finer than per million, rounding down", Percentage(99.95001), "use Percentage(99.95) (PerMillion(999500)) below it or Percentage(99.9501) (PerMillion(999501)) above it"},
+		{"fields disagree", Target{Percentage: 99.9, PerMillion: 999500}, "disagree"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.target.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+
+	assert.NoError(t, Target{Percentage: 99.9, PerMillion: 999000}.Validate())
+
+	err := SLO{Name: "API", Target: Percentage(101)}.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "target: ")
+}
+
 func TestDays_Builder(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -122,6 +172,20 @@ func TestSLO_WithBurnAlerts(t *testing.T) {
 	require.Len(t, s.BurnAlerts, 2)
 }
 
+func TestSLO_AlertRecipients(t *testing.T) {
+	oncall := []Recipient{{Type: "pagerduty", Target: "api-oncall"}}
+	team := []Recipient{{Type: "slack", Target: "#api"}}
+	s := SLO{DefaultRecipients: team}
+
+	assert.Equal(t, team, s.AlertRecipients(SlowBurn(5.0)))
+
+	fast := FastBurn(2.0)
+	fast.Recipients = oncall
+	assert.Equal(t, oncall, s.AlertRecipients(fast))
+
+	assert.Empty(t, SLO{}.AlertRecipients(SlowBurn(5.0)))
+}
+
 func TestSLO_Complete(t *testing.T) {
 	goodEvents := query.Query{
 		Dataset:   "production",
@@ -163,3 +227,51 @@ func TestSLO_Complete(t *testing.T) {
 	assert.Equal(t, 30, s.TimePeriod.Days)
 	require.Len(t, s.BurnAlerts, 2)
 }
+
+func TestTimePeriod_QueryRange(t *testing.T) {
+	assert.Equal(t, 86400, Days(1).QueryRange())
+	assert.Equal(t, 12*3600, TimePeriod{Hours: 12}.QueryRange())
+	assert.Equal(t, 7*86400, Days(30).QueryRange(), "queries cover at most 7 days")
+}
+
+func TestSLO_CrossDataset(t *testing.T) {
+	s := SLO{
+		Name: "Checkout Availability",
+		SLI: SLI{
+			GoodEvents:  query.Query{Dataset: "cdn-logs", Calculations: []query.Calculation{query.Count()}},
+			TotalEvents: query.Query{Dataset: "checkout", Calculations: []query.Calculation{query.Count()}},
+		},
+	}
+
+	assert.True(t, s.CrossDataset())
+	assert.Equal(t, []string{"cdn-logs", "checkout"}, s.SLI.Datasets())
+
+	err := s.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SLI.Alias")
+
+	s.SLI.Alias = "sli.checkout_ok"
+	assert.NoError(t, s.Validate())
+
+	s.Dataset = "payments"
+	err = s.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `"payments"`)
+
+	s.Dataset = "checkout"
+	assert.NoError(t, s.Validate())
+}
+
+func TestSLO_SingleDataset(t *testing.T) {
+	s := SLO{
+		Dataset: "production",
+		SLI: SLI{
+			GoodEvents:  query.Query{Dataset: "production"},
+			TotalEvents: query.Query{Dataset: "production"},
+		},
+	}
+
+	assert.False(t, s.CrossDataset())
+	assert.Equal(t, []string{"production"}, s.SLI.Datasets())
+	assert.NoError(t, s.Validate())
+}
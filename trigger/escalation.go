@@ -0,0 +1,125 @@
+package trigger
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+// Escalation is a chain of triggers on one query whose levels cross
+// increasingly severe thresholds, e.g. a warning notifying Slack and a
+// critical level paging PagerDuty. It is declared once and expanded at build
+// time into a trigger per level (see Triggers), named after the escalation
+// and the level: "High Latency (warning)", "High Latency (critical)".
+type Escalation struct {
+	// Name is the display name the level triggers are named after
+	Name string
+
+	// Description provides additional context about the escalation; every
+	// level trigger gets it
+	Description string
+
+	// Dataset is the Honeycomb dataset the triggers monitor
+	Dataset string
+
+	// Query is the query every level evaluates
+	Query query.Query
+
+	// Frequency is how often each level evaluates
+	Frequency Frequency
+
+	// AlertType is when a firing level notifies its recipients
+	AlertType AlertType
+
+	// Levels are the escalation levels, least severe first
+	Levels []Level
+
+	// Disabled disables every level
+	Disabled bool
+
+	// Tags are added to every level trigger, after its severity tag
+	Tags []Tag
+}
+
+// Level is a level of an Escalation.
+type Level struct {
+	// Name is the severity of the level, e.g. "warning" or "critical". It
+	// suffixes the trigger name and is its SeverityTag.
+	Name string
+
+	// Threshold is the condition that fires the level. Each level's
+	// threshold must use the same operator as the first and be crossed only
+	// after the previous level's.
+	Threshold Threshold
+
+	// Recipients are notified when the level fires
+	Recipients []Recipient
+}
+
+// SeverityTag is the key of the tag holding the level name of a trigger
+// expanded from an Escalation.
+const SeverityTag = "severity"
+
+// EscalationName returns the display name of the trigger of level in the
+// escalation named name.
+func EscalationName(name, level string) string {
+	return fmt.Sprintf("%s (%s)", name, level)
+}
+
+// Triggers expands the escalation into a trigger per level, in level order.
+func (e Escalation) Triggers() []Trigger {
+	triggers := make([]Trigger, len(e.Levels))
+	for i, l := range e.Levels {
+		triggers[i] = Trigger{
+			Name:        EscalationName(e.Name, l.Name),
+			Description: e.Description,
+			Dataset:     e.Dataset,
+			Query:       e.Query,
+			Threshold:   l.Threshold,
+			Frequency:   e.Frequency,
+			AlertType:   e.AlertType,
+			Recipients:  l.Recipients,
+			Disabled:    e.Disabled,
+			Tags:        append([]Tag{{Key: SeverityTag, Value: l.Name}}, e.Tags...),
+		}
+	}
+	return triggers
+}
+
+// ErrNoLevels is returned by Escalation.Validate for an escalation without
+// levels.
+var ErrNoLevels = errors.New("escalation has no levels")
+
+// Validate checks that the escalation has levels with distinct names whose
+// thresholds escalate: all use the first level's operator, and each is
+// crossed only after the previous one, e.g. > 500 then > 1000, or < 10 then
+// < 5.
+func (e Escalation) Validate() error {
+	if len(e.Levels) == 0 {
+		return ErrNoLevels
+	}
+	seen := make(map[string]bool)
+	for i, l := range e.Levels {
+		if l.Name == "" {
+			return fmt.Errorf("level %d has no name", i+1)
+		}
+		if seen[l.Name] {
+			return fmt.Errorf("level %q is declared twice", l.Name)
+		}
+		seen[l.Name] = true
+		if i == 0 {
+			continue
+		}
+
+		prev := e.Levels[i-1]
+		if l.Threshold.Op != prev.Threshold.Op {
+			return fmt.Errorf("level %q uses %s, but level %q uses %s; every level must compare the same way", l.Name, l.Threshold.Op, prev.Name, prev.Threshold.Op)
+		}
+		rising := l.Threshold.Op == GT || l.Threshold.Op == GTE
+		if (rising && l.Threshold.Value <= prev.Threshold.Value) || (!rising && l.Threshold.Value >= prev.Threshold.Value) {
+			return fmt.Errorf("level %q threshold %s %g does not escalate level %q threshold %s %g", l.Name, l.Threshold.Op, l.Threshold.Value, prev.Name, prev.Threshold.Op, prev.Threshold.Value)
+		}
+	}
+	return nil
+}
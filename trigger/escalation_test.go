@@ -0,0 +1,74 @@
+package trigger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
+
+func latencyEscalation() Escalation {
+	return Escalation{
+		Name:      "High Latency",
+		Dataset:   "api",
+		Query:     query.Query{Dataset: "api", Calculations: []query.Calculation{query.P99("duration_ms")}},
+		Frequency: Minutes(5),
+		AlertType: OnChange,
+		Levels: []Level{
+			{Name: "warning", Threshold: GreaterThan(500), Recipients: []Recipient{SlackChannel("#alerts")}},
+			{Name: "critical", Threshold: GreaterThan(1000), Recipients: []Recipient{PagerDutyService("0123456789abcdef0123456789abcdef")}},
+		},
+		Tags: []Tag{{Key: "team", Value: "api"}},
+	}
+}
+
+func TestEscalation_Triggers(t *testing.T) {
+	triggers := latencyEscalation().Triggers()
+	require.Len(t, triggers, 2)
+
+	assert.Equal(t, "High Latency (warning)", triggers[0].Name)
+	assert.Equal(t, GreaterThan(500), triggers[0].Threshold)
+	assert.Equal(t, []Recipient{SlackChannel("#alerts")}, triggers[0].Recipients)
+	assert.Equal(t, []Tag{{Key: SeverityTag, Value: "warning"}, {Key: "team", Value: "api"}}, triggers[0].Tags)
+
+	assert.Equal(t, "High Latency (critical)", triggers[1].Name)
+	assert.Equal(t, GreaterThan(1000), triggers[1].Threshold)
+	assert.Equal(t, PagerDuty, triggers[1].Recipients[0].Type)
+	for _, tr := range triggers {
+		assert.Equal(t, "api", tr.Dataset)
+		assert.Equal(t, 300, tr.Frequency.Seconds)
+		assert.Equal(t, OnChange, tr.AlertType)
+		assert.Equal(t, "P99", tr.Query.Calculations[0].Op)
+	}
+}
+
+func TestEscalation_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		levels  []Level
+		wantErr string
+	}{
+		{"rising", latencyEscalation().Levels, ""},
+		{"falling", []Level{{Name: "low", Threshold: LessThan(10)}, {Name: "empty", Threshold: LessThan(1)}}, ""},
+		{"single level", []Level{{Name: "page", Threshold: GreaterThan(1)}}, ""},
+		{"no levels", nil, "escalation has no levels"},
+		{"unnamed level", []Level{{Threshold: GreaterThan(1)}}, "level 1 has no name"},
+		{"repeated level", []Level{{Name: "page", Threshold: GreaterThan(1)}, {Name: "page", Threshold: GreaterThan(2)}}, `level "page" is declared twice`},
+		{"mixed operators", []Level{{Name: "warning", Threshold: GreaterThan(1)}, {Name: "critical", Threshold: LessThan(2)}}, `level "critical" uses <, but level "warning" uses >`},
+		{"not escalating", []Level{{Name: "warning", Threshold: GreaterThan(5)}, {Name: "critical", Threshold: GreaterThan(5)}}, `level "critical" threshold > 5 does not escalate level "warning" threshold > 5`},
+		{"falling not escalating", []Level{{Name: "low", Threshold: LessThanOrEqual(1)}, {Name: "empty", Threshold: LessThanOrEqual(10)}}, `level "empty" threshold <= 10 does not escalate`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Escalation{Levels: tt.levels}.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -1,11 +1,18 @@
 package trigger
 
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"strings"
+)
+
 // Recipient represents a notification target for triggers.
 type Recipient struct {
 	// Type is the recipient type (slack, pagerduty, email, webhook)
 	Type RecipientType
 
-	// Target is the destination (channel, service ID, email address, URL)
+	// Target is the destination (channel, routing key, email address, URL)
 	Target string
 }
 
@@ -31,9 +38,10 @@ func SlackChannel(channel string) Recipient {
 	return Recipient{Type: Slack, Target: channel}
 }
 
-// PagerDutyService creates a Recipient for a PagerDuty service.
-func PagerDutyService(serviceID string) Recipient {
-	return Recipient{Type: PagerDuty, Target: serviceID}
+// PagerDutyService creates a Recipient for a PagerDuty service, identified by
+// the integration (routing) key of the service's Honeycomb integration.
+func PagerDutyService(routingKey string) Recipient {
+	return Recipient{Type: PagerDuty, Target: routingKey}
 }
 
 // EmailAddress creates a Recipient for an email address.
@@ -45,3 +53,60 @@ func EmailAddress(email string) Recipient {
 func WebhookURL(url string) Recipient {
 	return Recipient{Type: Webhook, Target: url}
 }
+
+// PagerDutyKeyLength is the length of a PagerDuty integration (routing) key.
+const PagerDutyKeyLength = 32
+
+// Validate checks the format of the recipient's target for its type, without
+// calling any API:
+//
+//   - slack targets are channels starting with '#'
+//   - email targets are a bare address such as "team@example.com"
+//   - pagerduty targets are a 32 character integration (routing) key
+//   - webhook targets are https URLs
+//
+// Targets containing a ${VAR} reference are only resolved at build time and
+// are not checked.
+func (r Recipient) Validate() error {
+	if r.Target == "" {
+		return fmt.Errorf("%s recipient is missing a target", r.Type)
+	}
+	if strings.Contains(r.Target, "${") {
+		return nil
+	}
+
+	switch r.Type {
+	case Slack:
+		name, ok := strings.CutPrefix(r.Target, "#")
+		if !ok || name == "" || strings.ContainsAny(name, " \t\n#") {
+			return fmt.Errorf("slack target %q is not a channel like \"#alerts\"", r.Target)
+		}
+	case Email:
+		addr, err := mail.ParseAddress(r.Target)
+		if err != nil || addr.Address != r.Target {
+			return fmt.Errorf("email target %q is not an address like \"team@example.com\"", r.Target)
+		}
+	case PagerDuty:
+		if len(r.Target) != PagerDutyKeyLength || !isAlphanumeric(r.Target) {
+			return fmt.Errorf("pagerduty target %q is not a %d character integration key", r.Target, PagerDutyKeyLength)
+		}
+	case Webhook:
+		u, err := url.Parse(r.Target)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("webhook target %q is not an https URL", r.Target)
+		}
+	default:
+		return fmt.Errorf("unknown recipient type %q", r.Type)
+	}
+	return nil
+}
+
+// isAlphanumeric reports whether s holds only ASCII letters and digits.
+func isAlphanumeric(s string) bool {
+	for _, c := range s {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
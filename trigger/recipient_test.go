@@ -114,3 +114,39 @@ func TestMultipleRecipients(t *testing.T) {
 		assert.Equal(t, expectedTypes[i], r.Type)
 	}
 }
+
+func TestRecipient_Validate(t *testing.T) {
+	tests := []struct {
+		name      string
+		recipient Recipient
+		wantErr   string
+	}{
+		{"slack channel", SlackChannel("#alerts"), ""},
+		{"slack without hash", SlackChannel("alerts"), `slack target "alerts" is not a channel`},
+		{"slack with space", SlackChannel("#on call"), "is not a channel"},
+		{"slack hash only", SlackChannel("#"), "is not a channel"},
+		{"email", EmailAddress("team@example.com"), ""},
+		{"email without at", EmailAddress("team.example.com"), `email target "team.example.com" is not an address`},
+		{"email with name", EmailAddress("Team <team@example.com>"), "is not an address"},
+		{"pagerduty key", PagerDutyService("0123456789abcdef0123456789ABCDEF"), ""},
+		{"pagerduty service name", PagerDutyService("api-team"), `pagerduty target "api-team" is not a 32 character integration key`},
+		{"pagerduty short key", PagerDutyService("0123456789abcdef0123456789abcde"), "integration key"},
+		{"webhook", WebhookURL("https://example.com/webhook"), ""},
+		{"webhook http", WebhookURL("http://example.com/webhook"), `webhook target "http://example.com/webhook" is not an https URL`},
+		{"webhook without host", WebhookURL("https:///webhook"), "is not an https URL"},
+		{"env reference", PagerDutyService("${PAGERDUTY_KEY}"), ""},
+		{"missing target", SlackChannel(""), "slack recipient is missing a target"},
+		{"unknown type", Recipient{Type: "sms", Target: "+15555550100"}, `unknown recipient type "sms"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.recipient.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
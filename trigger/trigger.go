@@ -1,7 +1,12 @@
 // Package trigger provides type-safe Honeycomb trigger declarations.
 package trigger
 
-import "github.com/lex00/wetwire-honeycomb-go/query"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lex00/wetwire-honeycomb-go/query"
+)
 
 // Trigger represents a complete Honeycomb trigger specification.
 type Trigger struct {
@@ -17,17 +22,37 @@ type Trigger struct {
 	// Query is the query that defines the metric to monitor
 	Query query.Query
 
+	// QueryID is the ID of a saved Honeycomb query the trigger evaluates. The
+	// build output sets it to a placeholder apply resolves (see build --refs).
+	QueryID string
+
 	// Threshold defines the condition that fires the trigger
 	Threshold Threshold
 
 	// Frequency is how often the trigger evaluates
 	Frequency Frequency
 
+	// AlertType is when a firing trigger notifies: OnChange (Honeycomb's
+	// default when empty) or OnTrue
+	AlertType AlertType
+
 	// Recipients are the notification targets when the trigger fires
 	Recipients []Recipient
 
 	// Disabled indicates whether the trigger is active
 	Disabled bool
+
+	// Tags are key-value metadata for organizing triggers
+	Tags []Tag
+}
+
+// Tag represents a key-value metadata pair for triggers.
+type Tag struct {
+	// Key is the tag name
+	Key string
+
+	// Value is the tag value
+	Value string
 }
 
 // Threshold represents a trigger threshold condition.
@@ -56,12 +81,81 @@ const (
 	LTE Op = "<="
 )
 
+// AlertType is when a trigger notifies its recipients.
+type AlertType string
+
+const (
+	// OnChange notifies when the trigger starts and stops firing
+	OnChange AlertType = "on_change"
+
+	// OnTrue notifies on every evaluation that fires, for as long as the
+	// threshold is crossed
+	OnTrue AlertType = "on_true"
+)
+
+// Validate checks that the alert type is OnChange, OnTrue or empty (which
+// Honeycomb treats as OnChange).
+func (a AlertType) Validate() error {
+	switch a {
+	case "", OnChange, OnTrue:
+		return nil
+	}
+	return fmt.Errorf("unknown alert type %q: use trigger.OnChange or trigger.OnTrue", string(a))
+}
+
 // Frequency represents how often a trigger evaluates.
 type Frequency struct {
 	// Seconds is the evaluation interval in seconds
 	Seconds int
 }
 
+// MaxDurationFactor is how many times its frequency Honeycomb lets a
+// trigger's query duration span.
+const MaxDurationFactor = 4
+
+// MaxQueryDuration is the longest query duration, in seconds, Honeycomb
+// evaluates a trigger over, whatever its frequency.
+const MaxQueryDuration = 86400
+
+// QueryWindow returns the shortest and longest query durations, in seconds,
+// that fit a trigger evaluated every frequencySeconds: from the frequency,
+// so no events go unchecked, to MaxDurationFactor times it and at most
+// MaxQueryDuration. A zero frequency bounds the duration by
+// MaxQueryDuration alone.
+func QueryWindow(frequencySeconds int) (shortest, longest int) {
+	if frequencySeconds <= 0 {
+		return 0, MaxQueryDuration
+	}
+	return min(frequencySeconds, MaxQueryDuration), min(MaxDurationFactor*frequencySeconds, MaxQueryDuration)
+}
+
+// CheckQueryDuration checks the duration of a trigger's query (its relative
+// time range) against the trigger's frequency, both in seconds. Honeycomb
+// rejects durations over MaxDurationFactor times the frequency; durations
+// shorter than the frequency are accepted but leave events between two
+// evaluations unchecked, and are returned as ErrDurationGap. Zero values are
+// not checked.
+func CheckQueryDuration(durationSeconds, frequencySeconds int) error {
+	if durationSeconds <= 0 || frequencySeconds <= 0 {
+		return nil
+	}
+	if durationSeconds > MaxDurationFactor*frequencySeconds {
+		// Frequencies are whole minutes
+		minFrequency := (durationSeconds + MaxDurationFactor*60 - 1) / (MaxDurationFactor * 60) * 60
+		return fmt.Errorf("query duration %ds is more than %d times the frequency %ds (at most %ds); lengthen the frequency to at least %ds or shorten the duration",
+			durationSeconds, MaxDurationFactor, frequencySeconds, MaxDurationFactor*frequencySeconds, minFrequency)
+	}
+	if durationSeconds < frequencySeconds {
+		return fmt.Errorf("%w (%ds < %ds): %ds of events between evaluations are never checked",
+			ErrDurationGap, durationSeconds, frequencySeconds, frequencySeconds-durationSeconds)
+	}
+	return nil
+}
+
+// ErrDurationGap is returned by CheckQueryDuration for a query duration
+// shorter than the trigger's frequency.
+var ErrDurationGap = errors.New("query duration is shorter than the frequency")
+
 // GreaterThan creates a Threshold with the > operator.
 func GreaterThan(value float64) Threshold {
 	return Threshold{Op: GT, Value: value}
@@ -203,3 +203,32 @@ func TestTrigger_Disabled(t *testing.T) {
 
 	assert.True(t, tr.Disabled)
 }
+
+func TestCheckQueryDuration(t *testing.T) {
+	assert.NoError(t, CheckQueryDuration(3600, 900), "4x the frequency is allowed")
+	assert.NoError(t, CheckQueryDuration(900, 900))
+	assert.NoError(t, CheckQueryDuration(0, 900), "unset durations are not checked")
+	assert.NoError(t, CheckQueryDuration(3600, 0), "unset frequencies are not checked")
+
+	err := CheckQueryDuration(7200, 900)
+	assert.EqualError(t, err, "query duration 7200s is more than 4 times the frequency 900s (at most 3600s); lengthen the frequency to at least 1800s or shorten the duration")
+	assert.NotErrorIs(t, err, ErrDurationGap)
+
+	err = CheckQueryDuration(300, 900)
+	assert.ErrorIs(t, err, ErrDurationGap)
+	assert.EqualError(t, err, "query duration is shorter than the frequency (300s < 900s): 600s of events between evaluations are never checked")
+}
+
+func TestQueryWindow(t *testing.T) {
+	shortest, longest := QueryWindow(900)
+	assert.Equal(t, 900, shortest)
+	assert.Equal(t, 3600, longest)
+
+	shortest, longest = QueryWindow(43200)
+	assert.Equal(t, 43200, shortest)
+	assert.Equal(t, MaxQueryDuration, longest, "no trigger evaluates more than a day")
+
+	shortest, longest = QueryWindow(0)
+	assert.Equal(t, 0, shortest)
+	assert.Equal(t, MaxQueryDuration, longest)
+}
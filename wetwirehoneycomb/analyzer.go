@@ -0,0 +1,139 @@
+// Package wetwirehoneycomb runs the wetwire-honeycomb lint rules as a
+// golang.org/x/tools/go/analysis Analyzer, so go vet and golangci-lint report
+// the WHC findings of query, board, SLO and trigger declarations with their
+// file and line, without running the wetwire-honeycomb CLI.
+//
+// With go vet, build the vet tool of cmd/wetwirehoneycomb:
+//
+//	go vet -vettool=$(which wetwirehoneycomb) ./...
+//
+// With golangci-lint, load Analyzer through a plugin; see New.
+package wetwirehoneycomb
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+)
+
+// modulePath is the import path prefix of the packages declarations come from
+const modulePath = "github.com/lex00/wetwire-honeycomb-go/"
+
+// Analyzer reports the findings of every wetwire-honeycomb lint rule in the
+// Go files of a package. Each diagnostic's category is its rule code, e.g.
+// WHC004, and its message starts with it.
+var Analyzer = &analysis.Analyzer{
+	Name: "wetwirehoneycomb",
+	Doc: `report wetwire-honeycomb lint findings
+
+The WHC rules of wetwire-honeycomb lint check the queries, boards, SLOs and
+triggers a package declares, as "wetwire-honeycomb lint" does. Rules that
+compare resources see the package and the packages below it.`,
+	URL: "https://lex00.github.io/wetwire-honeycomb-go/lint-rules/",
+	Run: run,
+}
+
+// disabled holds the rule codes of the -disable flag
+var disabled string
+
+func init() {
+	Analyzer.Flags.StringVar(&disabled, "disable", "", "comma-separated rule codes to skip, e.g. WHC004,WHC026")
+}
+
+// New returns the analyzers of a golangci-lint plugin, for the Go plugin
+// system of golangci-lint: a main package exporting
+//
+//	func New(conf any) ([]*analysis.Analyzer, error) {
+//		return wetwirehoneycomb.New(conf)
+//	}
+//
+// built with -buildmode=plugin. conf holds the plugin's settings; a
+// "disable" list of rule codes is applied as the -disable flag is.
+func New(conf any) ([]*analysis.Analyzer, error) {
+	settings, _ := conf.(map[string]any)
+	if codes, ok := settings["disable"]; ok {
+		list, ok := codes.([]any)
+		if !ok {
+			return nil, fmt.Errorf("wetwirehoneycomb: disable must be a list of rule codes, got %T", codes)
+		}
+		names := make([]string, 0, len(list))
+		for _, code := range list {
+			names = append(names, fmt.Sprint(code))
+		}
+		if err := Analyzer.Flags.Set("disable", strings.Join(names, ",")); err != nil {
+			return nil, err
+		}
+	}
+	return []*analysis.Analyzer{Analyzer}, nil
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	// Only packages declaring resources are linted
+	if !importsModule(pass.Files) {
+		return nil, nil
+	}
+
+	files := make(map[string]*token.File, len(pass.Files))
+	names := make([]string, 0, len(pass.Files))
+	for _, f := range pass.Files {
+		tf := pass.Fset.File(f.Pos())
+		if tf == nil || strings.HasSuffix(tf.Name(), "_test.go") {
+			continue
+		}
+		name, err := filepath.Abs(tf.Name())
+		if err != nil {
+			return nil, err
+		}
+		files[name] = tf
+		names = append(names, name)
+	}
+
+	issues, err := domain.LintFiles(names, splitCodes(disabled))
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range issues {
+		tf, ok := files[issue.File]
+		if !ok || issue.Line < 1 || issue.Line > tf.LineCount() {
+			continue
+		}
+		pass.Report(analysis.Diagnostic{
+			Pos:      tf.LineStart(issue.Line),
+			Category: issue.Rule,
+			Message:  issue.Rule + ": " + issue.Message,
+		})
+	}
+	return nil, nil
+}
+
+// importsModule reports whether one of files imports a wetwire-honeycomb-go
+// package.
+func importsModule(files []*ast.File) bool {
+	for _, f := range files {
+		for _, spec := range f.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err == nil && strings.HasPrefix(path, modulePath) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitCodes returns the non-empty rule codes of a comma-separated list.
+func splitCodes(list string) []string {
+	var codes []string
+	for _, code := range strings.Split(list, ",") {
+		if code = strings.TrimSpace(code); code != "" {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
@@ -0,0 +1,97 @@
+package wetwirehoneycomb
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// runAnalyzer runs Analyzer on the package of one file with content, and
+// returns the diagnostics as "line: message".
+func runAnalyzer(t *testing.T, content string) []string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "obs.go")
+	if err := os.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, file, nil, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Failed to parse test file: %v", err)
+	}
+
+	var diagnostics []string
+	pass := &analysis.Pass{
+		Analyzer: Analyzer,
+		Fset:     fset,
+		Files:    []*ast.File{f},
+		Report: func(d analysis.Diagnostic) {
+			if !strings.HasPrefix(d.Message, d.Category+": ") {
+				t.Errorf("expected the message to start with the category %s, got %q", d.Category, d.Message)
+			}
+			diagnostics = append(diagnostics, strings.Split(fset.Position(d.Pos).String(), ":")[1]+": "+d.Message)
+		},
+	}
+	if _, err := Analyzer.Run(pass); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	return diagnostics
+}
+
+func TestAnalyzer(t *testing.T) {
+	content := `package obs
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Latency = query.Query{
+	Dataset:      "api",
+	Calculations: []query.Calculation{query.P99("duration_ms")},
+}
+`
+	diagnostics := runAnalyzer(t, content)
+	if len(diagnostics) == 0 || diagnostics[0] != "5: WHC002: Query is missing time_range" {
+		t.Errorf("expected WHC002 on line 5, got %q", diagnostics)
+	}
+
+	if err := Analyzer.Flags.Set("disable", "WHC002, WHC026"); err != nil {
+		t.Fatal(err)
+	}
+	defer Analyzer.Flags.Set("disable", "")
+	for _, d := range runAnalyzer(t, content) {
+		if strings.Contains(d, "WHC002") || strings.Contains(d, "WHC026") {
+			t.Errorf("expected the disabled rules to be skipped, got %q", d)
+		}
+	}
+}
+
+func TestAnalyzer_OtherPackages(t *testing.T) {
+	diagnostics := runAnalyzer(t, `package obs
+
+import "fmt"
+
+var Greeting = fmt.Sprint("hello")
+`)
+	if len(diagnostics) != 0 {
+		t.Errorf("expected packages without declarations to be skipped, got %q", diagnostics)
+	}
+}
+
+func TestNew(t *testing.T) {
+	defer Analyzer.Flags.Set("disable", "")
+	analyzers, err := New(map[string]any{"disable": []any{"WHC004", "WHC026"}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if len(analyzers) != 1 || analyzers[0] != Analyzer || disabled != "WHC004,WHC026" {
+		t.Errorf("expected Analyzer with two disabled rules, got %v %q", analyzers, disabled)
+	}
+	if _, err := New(map[string]any{"disable": "WHC004"}); err == nil {
+		t.Error("expected an error for a disable setting that is not a list")
+	}
+}
@@ -0,0 +1,18 @@
+// Command wetwirehoneycomb is a go vet tool running the wetwire-honeycomb
+// lint rules:
+//
+//	go install github.com/lex00/wetwire-honeycomb-go/wetwirehoneycomb/cmd/wetwirehoneycomb
+//	go vet -vettool=$(which wetwirehoneycomb) ./...
+//
+// Rules are skipped with -wetwirehoneycomb.disable=WHC004,WHC026.
+package main
+
+import (
+	"golang.org/x/tools/go/analysis/unitchecker"
+
+	"github.com/lex00/wetwire-honeycomb-go/wetwirehoneycomb"
+)
+
+func main() {
+	unitchecker.Main(wetwirehoneycomb.Analyzer)
+}
@@ -0,0 +1,16 @@
+module github.com/lex00/wetwire-honeycomb-go/wetwirehoneycomb
+
+go 1.23.0
+
+// github.com/lex00/wetwire-honeycomb-go resolves to the checkout the analyzer
+// lives in through a Go workspace; create it at the repository root with
+// go work init . ./wetwirehoneycomb
+require golang.org/x/tools v0.28.0
+
+require (
+	github.com/inconshreveable/mousetrap v1.1.0 // indirect
+	github.com/lex00/wetwire-core-go v1.20.0 // indirect
+	github.com/spf13/cobra v1.10.2 // indirect
+	github.com/spf13/pflag v1.0.9 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,51 @@
+package wetwiretest
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lex00/wetwire-honeycomb-go/internal/roundtrip"
+)
+
+// update is set by running the tests with -update. Test packages that import
+// wetwiretest get the flag and must not declare their own.
+var update = flag.Bool("update", false, "rewrite golden files with the current output")
+
+// Golden compares got, a JSON document, with the golden file at path and
+// fails the test unless they are semantically equal: whitespace, key order,
+// int vs float and empty or null fields are ignored. Running the tests with
+// -update writes got to path instead, creating its directory if needed.
+func Golden(t testing.TB, got []byte, path string) bool {
+	t.Helper()
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Errorf("update golden file: %v", err)
+			return false
+		}
+		data := append(bytes.TrimSpace(got), '\n')
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Errorf("update golden file: %v", err)
+			return false
+		}
+		return true
+	}
+
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		t.Errorf("golden file %s does not exist; run go test with -update to create it", path)
+		return false
+	} else if err != nil {
+		t.Errorf("read golden file: %v", err)
+		return false
+	}
+
+	if err := roundtrip.CompareJSON(want, got); err != nil {
+		t.Errorf("output does not match %s: %v\ngot:\n%s\nwant:\n%s", path, err, bytes.TrimSpace(got), bytes.TrimSpace(want))
+		return false
+	}
+	return true
+}
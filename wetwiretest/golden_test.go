@@ -0,0 +1,43 @@
+package wetwiretest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGolden(t *testing.T) {
+	dir := t.TempDir()
+	golden := writeFile(t, dir, "x.golden.json", `{"name": "Overview", "panels": [{"type": "query", "position": 1}]}`)
+
+	r := &recorder{TB: t}
+	// Numbers, key order and empty fields are compared semantically
+	assert.True(t, Golden(r, []byte(`{"panels":[{"position":1.0,"type":"query"}],"name":"Overview","tags":[]}`), golden))
+	assert.Empty(t, r.errors)
+
+	assert.False(t, Golden(r, []byte(`{"name": "Overview", "panels": []}`), golden))
+	require.Len(t, r.errors, 1)
+	assert.Contains(t, r.errors[0], `missing key "panels"`)
+
+	r = &recorder{TB: t}
+	assert.False(t, Golden(r, []byte(`{}`), filepath.Join(dir, "missing.golden.json")))
+	require.Len(t, r.errors, 1)
+	assert.Contains(t, r.errors[0], "-update")
+}
+
+func TestGolden_Update(t *testing.T) {
+	*update = true
+	defer func() { *update = false }()
+
+	path := filepath.Join(t.TempDir(), "testdata", "x.golden.json")
+	r := &recorder{TB: t}
+	assert.True(t, Golden(r, []byte("{\"name\": \"Overview\"}\n\n"), path))
+	assert.Empty(t, r.errors)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"name\": \"Overview\"}\n", string(data))
+}
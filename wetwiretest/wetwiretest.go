@@ -0,0 +1,85 @@
+// Package wetwiretest provides test assertions for packages that declare
+// Honeycomb queries, boards, SLOs and triggers with wetwire-honeycomb-go.
+//
+// Use it from ordinary Go tests to keep observability code lint-clean and
+// its generated JSON under review:
+//
+//	func TestQueries(t *testing.T) {
+//		wetwiretest.AssertLintClean(t, ".")
+//		wetwiretest.AssertSerializesTo(t, SlowRequests, "testdata/slow_requests.json")
+//	}
+//
+// Expected JSON files are golden files: run the tests with -update to write
+// the current output to them, then review the change with git diff.
+package wetwiretest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	coredomain "github.com/lex00/wetwire-core-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/board"
+	"github.com/lex00/wetwire-honeycomb-go/domain"
+	"github.com/lex00/wetwire-honeycomb-go/internal/serialize"
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/slo"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+// AssertLintClean runs the lint rules over the resources under path, as the
+// lint command does, and fails the test for each issue found. Rules can be
+// skipped by code, e.g. "WHC024".
+func AssertLintClean(t testing.TB, path string, disable ...string) bool {
+	t.Helper()
+
+	ctx := coredomain.NewContext(context.Background(), path)
+	result, err := (&domain.HoneycombDomain{}).Linter().Lint(ctx, path, domain.LintOpts{Disable: disable})
+	if err != nil {
+		t.Errorf("lint %s: %v", path, err)
+		return false
+	}
+	for _, e := range result.Errors {
+		t.Errorf("%s", e.String())
+	}
+	return len(result.Errors) == 0
+}
+
+// AssertSerializesTo serializes v, a query.Query, board.Board, slo.SLO or
+// trigger.Trigger, and compares the JSON with the file at path as Golden
+// does, so -update rewrites the file.
+func AssertSerializesTo(t testing.TB, v any, path string) bool {
+	t.Helper()
+
+	got, err := Serialize(v)
+	if err != nil {
+		t.Errorf("serialize: %v", err)
+		return false
+	}
+	return Golden(t, got, path)
+}
+
+// Serialize returns the indented Honeycomb JSON for v, a query.Query,
+// board.Board, slo.SLO or trigger.Trigger, or a pointer to one.
+func Serialize(v any) ([]byte, error) {
+	switch r := v.(type) {
+	case query.Query:
+		return serialize.ToJSONPretty(r)
+	case *query.Query:
+		return serialize.ToJSONPretty(*r)
+	case board.Board:
+		return serialize.BoardToJSONPretty(r)
+	case *board.Board:
+		return serialize.BoardToJSONPretty(*r)
+	case slo.SLO:
+		return serialize.SLOToJSONPretty(r)
+	case *slo.SLO:
+		return serialize.SLOToJSONPretty(*r)
+	case trigger.Trigger:
+		return serialize.TriggerToJSONPretty(r)
+	case *trigger.Trigger:
+		return serialize.TriggerToJSONPretty(*r)
+	default:
+		return nil, fmt.Errorf("unsupported type %T (want query.Query, board.Board, slo.SLO or trigger.Trigger)", v)
+	}
+}
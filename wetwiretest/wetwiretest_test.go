@@ -0,0 +1,128 @@
+package wetwiretest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lex00/wetwire-honeycomb-go/query"
+	"github.com/lex00/wetwire-honeycomb-go/trigger"
+)
+
+// recorder is a testing.TB that records failures instead of failing.
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+var latency = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Breakdowns:   []string{"endpoint"},
+	Calculations: []query.Calculation{query.P99("duration_ms"), query.Heatmap("duration_ms")},
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestAssertLintClean(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "queries.go", `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Latency = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Calculations: []query.Calculation{query.P99("duration_ms"), query.Heatmap("duration_ms")},
+}
+`)
+
+	r := &recorder{TB: t}
+	assert.True(t, AssertLintClean(r, dir))
+	assert.Empty(t, r.errors)
+}
+
+func TestAssertLintClean_Issues(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "queries.go", `package queries
+
+import "github.com/lex00/wetwire-honeycomb-go/query"
+
+var Latency = query.Query{
+	Dataset:      "api",
+	TimeRange:    query.Hours(2),
+	Calculations: []query.Calculation{query.P99("duration_ms")},
+}
+`)
+
+	r := &recorder{TB: t}
+	assert.False(t, AssertLintClean(r, dir))
+	require.Len(t, r.errors, 1)
+	assert.Contains(t, r.errors[0], "WHC024")
+
+	// Disabled rules are skipped
+	r = &recorder{TB: t}
+	assert.True(t, AssertLintClean(r, dir, "WHC024"))
+	assert.Empty(t, r.errors)
+}
+
+func TestAssertSerializesTo(t *testing.T) {
+	dir := t.TempDir()
+	// Key order and whitespace do not matter
+	golden := writeFile(t, dir, "latency.json", `{"time_range": 7200, "breakdowns": ["endpoint"],
+		"calculations": [{"op": "P99", "column": "duration_ms"}, {"op": "HEATMAP", "column": "duration_ms"}]}`)
+
+	r := &recorder{TB: t}
+	assert.True(t, AssertSerializesTo(r, latency, golden))
+	assert.True(t, AssertSerializesTo(r, &latency, golden))
+	assert.Empty(t, r.errors)
+
+	changed := latency
+	changed.Breakdowns = []string{"service"}
+	assert.False(t, AssertSerializesTo(r, changed, golden))
+	require.Len(t, r.errors, 1)
+	assert.Contains(t, r.errors[0], `does not match`)
+	assert.Contains(t, r.errors[0], `at "breakdowns[0]": expected "endpoint", got "service"`)
+}
+
+func TestAssertSerializesTo_Errors(t *testing.T) {
+	dir := t.TempDir()
+	invalid := writeFile(t, dir, "invalid.json", "{")
+
+	tests := map[string]struct {
+		v    any
+		path string
+	}{
+		"missing file":     {latency, filepath.Join(dir, "missing.json")},
+		"invalid JSON":     {latency, invalid},
+		"unsupported type": {"latency", invalid},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			assert.False(t, AssertSerializesTo(r, tt.v, tt.path))
+			assert.Len(t, r.errors, 1)
+		})
+	}
+}
+
+func TestSerialize(t *testing.T) {
+	data, err := Serialize(trigger.Trigger{Name: "Slow", Query: latency})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"name": "Slow"`)
+}